@@ -0,0 +1,90 @@
+// ABOUTME: Alerts command and the hook that reports failing feeds after a fetch
+// ABOUTME: Sends through the webhook, ntfy topic, or SMTP server in config.json
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/alert"
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/models"
+)
+
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Report feeds that keep failing",
+	Long: `Get told when feeds stop working instead of finding out months later.
+
+After each fetch, digest sends an alert when a feed's consecutive error
+count reaches "error_threshold" (default 5), or, if "run_failures" is set,
+when at least that many feeds fail in one run. Configure in config.json:
+
+  "alerts": {
+    "error_threshold": 5,
+    "run_failures": 10,
+    "webhook": "https://hooks.slack.com/services/...",
+    "ntfy": "https://ntfy.sh/my-digest-alerts",
+    "email": {"to": ["me@example.com"], "smtp_host": "smtp.example.com", "username": "me@example.com"}
+  }
+
+The SMTP password and an ntfy access token come from the secrets store:
+  digest secrets set smtp/password
+  digest secrets set ntfy/token`,
+}
+
+var alertsTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a sample alert to every configured channel",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgAlerts := cfg.GetAlerts()
+		if !cfgAlerts.Enabled() {
+			return fmt.Errorf("no alert channels configured; add \"alerts\" to %s", config.GetConfigPath())
+		}
+		sample := models.NewFeed("https://example.com/feed.xml")
+		title := "Example Feed (test alert)"
+		msg := "this is a test alert from 'digest alerts test'"
+		sample.Title, sample.LastError = &title, &msg
+		sample.ErrorCount = cfgAlerts.Threshold()
+		if sample.ErrorCount == 0 {
+			sample.ErrorCount = alert.DefaultErrorThreshold
+			cfgAlerts.ErrorThreshold = sample.ErrorCount
+		}
+
+		a := alert.Evaluate(cfgAlerts, profileName, []*models.Feed{sample}, 1, time.Now())
+		if err := alert.Send(cmd.Context(), cfgAlerts, a); err != nil {
+			return fmt.Errorf("failed to send test alert: %w", err)
+		}
+		color.Green("Test alert sent")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(alertsCmd)
+	alertsCmd.AddCommand(alertsTestCmd)
+}
+
+// raiseAlerts sends an alert if the feeds that failed in a fetch warrant
+// one. Delivery problems are reported on errOut but never fail the fetch.
+func raiseAlerts(ctx context.Context, errOut io.Writer, failedIDs []string, attempted int) {
+	cfgAlerts := cfg.GetAlerts()
+	a, err := alert.Check(ctx, store, cfgAlerts, profileName, failedIDs, attempted)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: could not check alerts: %v\n", err)
+		return
+	}
+	if a == nil {
+		return
+	}
+	if err := alert.Send(ctx, cfgAlerts, a); err != nil {
+		fmt.Fprintf(errOut, "warning: could not send alert: %v\n", err)
+	}
+}
@@ -0,0 +1,134 @@
+// ABOUTME: Feed archive commands for parking dead feeds so syncs skip them
+// ABOUTME: Archives feeds by URL or by inactivity, and archives inactive feeds after a fetch
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+var feedArchiveCmd = &cobra.Command{
+	Use:   "archive [url...]",
+	Short: "Archive feeds so syncs skip them",
+	Long: `Archive feeds that have stopped publishing or keep failing. Archived
+feeds keep their entries but are left out of 'digest fetch' until
+unarchived; 'digest fetch <url>' still syncs one explicitly.
+
+With --inactive, archive every feed that has had no new entries, or has
+failed every sync, for inactive_days (90 unless set in config.json).
+'digest fetch' does this on its own after each full sync.
+
+Examples:
+  digest feed archive https://example.com/feed.xml
+  digest feed archive --inactive`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		inactive, _ := cmd.Flags().GetBool("inactive")
+		if inactive == (len(args) > 0) {
+			return fmt.Errorf("give feed URLs or --inactive")
+		}
+
+		now := time.Now()
+		if inactive {
+			archived, err := archiveInactive(ctx, cfg.GetInactiveAfter(), now)
+			if err != nil {
+				return err
+			}
+			if len(archived) == 0 {
+				fmt.Println("No inactive feeds")
+				return nil
+			}
+			faint := color.New(color.Faint).SprintFunc()
+			for _, f := range archived {
+				fmt.Printf("Archived %s %s\n", f.Feed.GetDisplayName(), faint("("+f.Reason+")"))
+			}
+			return nil
+		}
+
+		for _, url := range args {
+			feed, err := store.GetFeedByURL(ctx, url)
+			if err != nil {
+				return fmt.Errorf("feed not found: %s", url)
+			}
+			if feed.IsArchived() {
+				fmt.Printf("Already archived: %s\n", url)
+				continue
+			}
+			if err := feedsync.ArchiveFeed(ctx, store, feed, now); err != nil {
+				return err
+			}
+			fmt.Printf("Archived feed: %s\n", url)
+		}
+		return nil
+	},
+}
+
+var feedUnarchiveCmd = &cobra.Command{
+	Use:   "unarchive <url>...",
+	Short: "Return archived feeds to syncing",
+	Long: `Return archived feeds to syncing. An unarchived feed is never archived
+automatically again; archive it by hand if it goes quiet for good.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		for _, url := range args {
+			feed, err := store.GetFeedByURL(ctx, url)
+			if err != nil {
+				return fmt.Errorf("feed not found: %s", url)
+			}
+			wasArchived := feed.IsArchived()
+			if err := feedsync.UnarchiveFeed(ctx, store, feed); err != nil {
+				return err
+			}
+			if wasArchived {
+				fmt.Printf("Unarchived feed: %s\n", url)
+			} else {
+				fmt.Printf("Not archived, now kept active: %s\n", url)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	feedCmd.AddCommand(feedArchiveCmd)
+	feedCmd.AddCommand(feedUnarchiveCmd)
+
+	feedArchiveCmd.Flags().Bool("inactive", false, "archive every inactive feed")
+
+	feedArchiveCmd.ValidArgsFunction = feedURLArgs
+	feedUnarchiveCmd.ValidArgsFunction = feedURLArgs
+}
+
+// archiveInactive archives every inactive feed and returns them.
+func archiveInactive(ctx context.Context, after time.Duration, now time.Time) ([]feedsync.InactiveFeed, error) {
+	inactive, err := feedsync.FindInactive(ctx, store, after, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range inactive {
+		if err := feedsync.ArchiveFeed(ctx, store, f.Feed, now); err != nil {
+			return nil, err
+		}
+	}
+	return inactive, nil
+}
+
+// reportArchived tells the user which feeds a fetch archived and how to
+// bring them back.
+func reportArchived(w io.Writer, archived []feedsync.InactiveFeed) {
+	faint := color.New(color.Faint).SprintFunc()
+	fmt.Fprintf(w, "Archived %d inactive feed(s):\n", len(archived))
+	for _, f := range archived {
+		fmt.Fprintf(w, "  %s %s\n", f.Feed.GetDisplayName(), faint("("+f.Reason+")"))
+	}
+	fmt.Fprintln(w, faint("Bring one back with 'digest feed unarchive <url>'."))
+}
@@ -0,0 +1,87 @@
+// ABOUTME: Audit command for reviewing MCP tool calls made by agents
+// ABOUTME: tail prints the most recent records from the audit log in the data directory
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/audit"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Review MCP tool calls",
+	Long: `Review what MCP agents did. Every tool call made through 'digest mcp' is
+recorded with its tool name, profile, a hash of its arguments, result status,
+and duration. Arguments themselves are never stored.`,
+}
+
+var auditTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Show the most recent MCP tool calls",
+	Long: `Show the most recent MCP tool calls, oldest first.
+
+--quiet prints only the tool names.
+--porcelain prints one tab-separated record per call:
+  time (RFC 3339, UTC), tool, status, duration_ms, profile, args_hash, error`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, _ := cmd.Flags().GetInt("lines")
+		mode := getOutputMode(cmd)
+		out := cmd.OutOrStdout()
+
+		records, err := audit.Tail(audit.Path(cfg.GetDataDir()), n)
+		if err != nil {
+			return err
+		}
+
+		switch mode {
+		case outputQuiet:
+			for _, r := range records {
+				fmt.Fprintln(out, r.Tool)
+			}
+			return nil
+		case outputPorcelain:
+			for _, r := range records {
+				writePorcelain(out, porcelainTime(&r.Time), r.Tool, r.Status,
+					strconv.FormatInt(r.DurationMS, 10), r.Profile, r.ArgsHash, r.Error)
+			}
+			return nil
+		}
+
+		if len(records) == 0 {
+			fmt.Fprintln(out, "No MCP tool calls recorded.")
+			return nil
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+
+		for _, r := range records {
+			mark := green("v")
+			if r.Status != audit.StatusOK {
+				mark = red("x")
+			}
+			line := fmt.Sprintf("%s %s %-16s %6dms  %s", faint(r.Time.Local().Format("2006-01-02 15:04:05")),
+				mark, r.Tool, r.DurationMS, faint(r.Profile+" "+r.ArgsHash))
+			if r.Error != "" {
+				line += " " + red(r.Error)
+			}
+			fmt.Fprintln(out, line)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditTailCmd)
+	auditTailCmd.Flags().IntP("lines", "n", 20, "number of calls to show (0 for all)")
+	addOutputFlags(auditTailCmd, "print only tool names")
+}
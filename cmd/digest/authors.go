@@ -0,0 +1,351 @@
+// ABOUTME: Authors commands for seeing who writes most across feeds and following or muting writers
+// ABOUTME: Normalizes entry author fields into an index; followed authors drive 'digest list --followed'
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/authors"
+	"github.com/harper/digest/internal/storage"
+)
+
+var authorsCmd = &cobra.Command{
+	Use:   "authors",
+	Short: "Show the most frequent authors across feeds",
+	Long: `List the authors of stored entries, most prolific first. Author fields
+are normalized so "Jane Doe", "JANE DOE", and "jane@example.com (Jane Doe)"
+count as one writer, and co-authors are credited separately.
+
+Followed authors are marked with *. Use 'digest list --followed' to read
+their entries, or 'digest list --author <name>' for anyone; names match
+fuzzily, so "j doe" or "jnae doe" find Jane Doe.
+
+--quiet prints only author names.
+--porcelain prints one tab-separated record per author:
+  name, entries, unread, feeds, following (1/0), latest (RFC 3339, UTC)`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		mode := getOutputMode(cmd)
+		out := cmd.OutOrStdout()
+		limit, _ := cmd.Flags().GetInt("limit")
+		sinceValue, _ := cmd.Flags().GetString("since")
+		query, _ := cmd.Flags().GetString("search")
+
+		filter := &storage.EntryFilter{}
+		if err := applySince(filter, sinceValue); err != nil {
+			return err
+		}
+		entries, err := store.ListEntries(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		following, err := loadFollows()
+		if err != nil {
+			return err
+		}
+
+		index := authors.Index(entries)
+		if query != "" {
+			kept := index[:0]
+			for _, a := range index {
+				if authors.Match(query, a.Name) {
+					kept = append(kept, a)
+				}
+			}
+			index = kept
+		}
+		if limit > 0 && len(index) > limit {
+			index = index[:limit]
+		}
+
+		switch mode {
+		case outputQuiet:
+			for _, a := range index {
+				fmt.Fprintln(out, a.Name)
+			}
+			return nil
+		case outputPorcelain:
+			for _, a := range index {
+				writePorcelain(out, a.Name, strconv.Itoa(a.Entries), strconv.Itoa(a.Unread),
+					strconv.Itoa(len(a.FeedIDs)), porcelainBool(isFollowed(following, a.Name)), porcelainTime(a.Latest))
+			}
+			return nil
+		}
+
+		if len(index) == 0 {
+			fmt.Println("No authors found")
+			return nil
+		}
+
+		faint := color.New(color.Faint).SprintFunc()
+		yellow := color.New(color.FgYellow).SprintFunc()
+		for _, a := range index {
+			marker := " "
+			if isFollowed(following, a.Name) {
+				marker = yellow("*")
+			}
+			feeds := "feed"
+			if len(a.FeedIDs) != 1 {
+				feeds = "feeds"
+			}
+			fmt.Printf("%s %s %s\n", marker, a.Name,
+				faint(fmt.Sprintf("(%d entries, %d unread, %d %s)", a.Entries, a.Unread, len(a.FeedIDs), feeds)))
+		}
+		return nil
+	},
+}
+
+var authorsFollowCmd = &cobra.Command{
+	Use:   "follow <name>",
+	Short: "Follow an author",
+	Long: `Follow an author so 'digest list --followed' shows their entries from
+any feed. The name is matched fuzzily against entry authors.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		following, err := loadFollows()
+		if err != nil {
+			return err
+		}
+
+		// Prefer the indexed spelling when the name matches exactly one author
+		entries, err := store.ListEntries(cmd.Context(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		var matches []authors.Author
+		for _, a := range authors.Index(entries) {
+			if authors.Match(name, a.Name) {
+				matches = append(matches, a)
+			}
+		}
+		if len(matches) == 1 {
+			name = matches[0].Name
+		}
+
+		following, changed := authors.Follow(following, name)
+		if !changed {
+			fmt.Printf("Already following %s\n", name)
+			return nil
+		}
+		if err := saveFollows(following); err != nil {
+			return err
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+		fmt.Printf("%s Following %s\n", green("v"), name)
+		switch len(matches) {
+		case 0:
+			fmt.Println(faint("  No entries by this author yet"))
+		case 1:
+		default:
+			names := make([]string, 0, len(matches))
+			for _, a := range matches {
+				names = append(names, a.Name)
+			}
+			fmt.Println(faint("  Also matches: " + strings.Join(names, ", ")))
+		}
+		return nil
+	},
+}
+
+var authorsUnfollowCmd = &cobra.Command{
+	Use:   "unfollow <name>",
+	Short: "Stop following an author",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		following, err := loadFollows()
+		if err != nil {
+			return err
+		}
+		following, changed := authors.Unfollow(following, name)
+		if !changed {
+			return fmt.Errorf("not following %s", name)
+		}
+		if err := saveFollows(following); err != nil {
+			return err
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Unfollowed %s\n", green("v"), name)
+		return nil
+	},
+	ValidArgsFunction: followedAuthorArgs,
+}
+
+var authorsFollowingCmd = &cobra.Command{
+	Use:   "following",
+	Short: "List followed authors",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		following, err := loadFollows()
+		if err != nil {
+			return err
+		}
+		if len(following) == 0 {
+			fmt.Println("Not following anyone. Follow an author with 'digest authors follow <name>'")
+			return nil
+		}
+		for _, name := range following {
+			fmt.Fprintln(cmd.OutOrStdout(), name)
+		}
+		return nil
+	},
+}
+
+var authorsMuteCmd = &cobra.Command{
+	Use:   "mute <name>",
+	Short: "Mute an author",
+	Long: `Mute an author: their unread entries are marked read now, and 'digest
+triage' marks later ones read before it starts. The name is matched
+fuzzily against entry authors. Entries kept unread are left alone.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		ids, err := muteAuthor(cmd.Context(), name)
+		if err != nil {
+			return err
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Muted %s, marking %d entry(s) read\n", green("v"), name, len(ids))
+		return nil
+	},
+}
+
+var authorsUnmuteCmd = &cobra.Command{
+	Use:   "unmute <name>",
+	Short: "Stop muting an author",
+	Long:  "Stop muting an author. Entries already marked read stay read.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		muted, err := loadMuted()
+		if err != nil {
+			return err
+		}
+		muted, changed := authors.Unfollow(muted, name)
+		if !changed {
+			return fmt.Errorf("not muting %s", name)
+		}
+		if err := saveMuted(muted); err != nil {
+			return err
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Unmuted %s\n", green("v"), name)
+		return nil
+	},
+	ValidArgsFunction: mutedAuthorArgs,
+}
+
+var authorsMutedCmd = &cobra.Command{
+	Use:   "muted",
+	Short: "List muted authors",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		muted, err := loadMuted()
+		if err != nil {
+			return err
+		}
+		if len(muted) == 0 {
+			fmt.Println("Not muting anyone. Mute an author with 'digest authors mute <name>' or m in 'digest triage'")
+			return nil
+		}
+		for _, name := range muted {
+			fmt.Fprintln(cmd.OutOrStdout(), name)
+		}
+		return nil
+	},
+}
+
+// followsPath returns the active profile's followed-authors file.
+func followsPath() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return authors.FollowsPath(profileDir), nil
+}
+
+func loadFollows() ([]string, error) {
+	path, err := followsPath()
+	if err != nil {
+		return nil, err
+	}
+	return authors.LoadFollows(path)
+}
+
+func saveFollows(names []string) error {
+	path, err := followsPath()
+	if err != nil {
+		return err
+	}
+	return authors.SaveFollows(path, names)
+}
+
+func loadMuted() ([]string, error) {
+	path, err := followsPath()
+	if err != nil {
+		return nil, err
+	}
+	return authors.LoadMuted(path)
+}
+
+func saveMuted(names []string) error {
+	path, err := followsPath()
+	if err != nil {
+		return err
+	}
+	return authors.SaveMuted(path, names)
+}
+
+// isFollowed reports whether name is followed, using the same equivalence as Follow.
+func isFollowed(following []string, name string) bool {
+	for _, f := range following {
+		if authors.Key(f) == authors.Key(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// followedAuthorArgs completes the names of followed authors.
+func followedAuthorArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	following, err := loadFollows()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return following, cobra.ShellCompDirectiveNoFileComp
+}
+
+// mutedAuthorArgs completes the names of muted authors.
+func mutedAuthorArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	muted, err := loadMuted()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return muted, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(authorsCmd)
+	authorsCmd.AddCommand(authorsFollowCmd)
+	authorsCmd.AddCommand(authorsUnfollowCmd)
+	authorsCmd.AddCommand(authorsFollowingCmd)
+	authorsCmd.AddCommand(authorsMuteCmd)
+	authorsCmd.AddCommand(authorsUnmuteCmd)
+	authorsCmd.AddCommand(authorsMutedCmd)
+
+	authorsCmd.Flags().IntP("limit", "n", 20, "max authors to show (0 for all)")
+	authorsCmd.Flags().String("since", "", "only count entries since a date such as week, '2 weeks', or a range from..until")
+	authorsCmd.Flags().StringP("search", "s", "", "only authors fuzzily matching this name")
+	addOutputFlags(authorsCmd, "print only author names")
+	_ = authorsCmd.RegisterFlagCompletionFunc("since", cobra.FixedCompletions(sinceCompletions, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,183 @@
+// ABOUTME: Bookmarks commands pushing kept entries to Linkding or Raindrop.io and importing bookmarks back
+// ABOUTME: Uses the service, tags, and folder tag mapping in config.json and the bookmarks/token secret
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/bookmarks"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+var bookmarksCmd = &cobra.Command{
+	Use:   "bookmarks",
+	Short: "Push kept entries to Linkding or Raindrop.io",
+	Long: `Save entries worth keeping to a bookmark service, and optionally bring
+the service's bookmarks back into digest. Configure in config.json:
+
+  "bookmarks": {
+    "service": "linkding",
+    "url": "https://links.example.com",
+    "tags": ["from-digest"],
+    "folder_tags": {"Go": ["golang"], "Security": ["security", "infosec"]}
+  }
+
+For Raindrop.io use "service": "raindrop" and, to push somewhere other
+than Unsorted, "collection" with the collection's ID. Entries are tagged
+with "tags" plus the tags of their feed's OPML folder.
+
+The API token comes from the secrets store:
+  digest secrets set bookmarks/token`,
+}
+
+var bookmarksPushCmd = &cobra.Command{
+	Use:   "push [entry-id...]",
+	Short: "Bookmark kept entries not pushed yet",
+	Long: `Bookmark every entry kept with 'digest keep-unread' that hasn't been pushed
+before, or the entries given by ID. Pushed entries are remembered, so running
+push again only sends what's new; --again sends the given entries even if
+they were pushed already.
+
+Examples:
+  digest bookmarks push
+  digest bookmarks push --dry-run
+  digest bookmarks push abc123 --again`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		again, _ := cmd.Flags().GetBool("again")
+
+		bcfg := cfg.GetBookmarks()
+		client, err := bookmarks.New(bcfg)
+		if err != nil {
+			return err
+		}
+		path, err := bookmarkStatePath()
+		if err != nil {
+			return err
+		}
+		state, err := bookmarks.LoadState(path)
+		if err != nil {
+			return err
+		}
+
+		var entries []*models.Entry
+		if len(args) > 0 {
+			for _, ref := range args {
+				entry, err := store.GetEntryByPrefix(ctx, ref)
+				if err != nil {
+					return fmt.Errorf("failed to find entry %s: %w", ref, err)
+				}
+				entries = append(entries, entry)
+			}
+		} else {
+			all, err := store.ListEntries(ctx, &storage.EntryFilter{})
+			if err != nil {
+				return fmt.Errorf("failed to list entries: %w", err)
+			}
+			for _, e := range all {
+				if e.KeepUnread {
+					entries = append(entries, e)
+				}
+			}
+		}
+
+		feeds, err := store.ListFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list feeds: %w", err)
+		}
+		folderOf := make(map[string]string)
+		for _, f := range opmlDoc.AllFeeds() {
+			folderOf[f.URL] = f.Folder
+		}
+		feedFolder := make(map[string]string, len(feeds))
+		for _, f := range feeds {
+			feedFolder[f.ID] = folderOf[f.URL]
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+		pushed := 0
+		for _, e := range entries {
+			if _, done := state.Pushed[e.ID]; done && !(again && len(args) > 0) {
+				continue
+			}
+			b, err := bookmarks.FromEntry(e, bcfg.TagsFor(feedFolder[e.FeedID]))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Skipping: %v\n", err)
+				continue
+			}
+			if dryRun {
+				fmt.Printf("Would bookmark: %s %s\n", e.GetTitle(), faint(b.URL))
+				pushed++
+				continue
+			}
+			if err := client.Add(ctx, b); err != nil {
+				// Keep what was pushed so far from being sent twice
+				if saveErr := bookmarks.SaveState(path, state); saveErr != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", saveErr)
+				}
+				return fmt.Errorf("failed to bookmark %s: %w", b.URL, err)
+			}
+			state.Pushed[e.ID] = b.Added
+			fmt.Printf("%s Bookmarked: %s\n", green("v"), e.GetTitle())
+			pushed++
+		}
+
+		if dryRun {
+			fmt.Printf("%d bookmark(s) would be added to %s\n", pushed, bcfg.Service)
+			return nil
+		}
+		if err := bookmarks.SaveState(path, state); err != nil {
+			return err
+		}
+		if pushed == 0 {
+			fmt.Println("Nothing new to bookmark.")
+		}
+		return nil
+	},
+}
+
+var bookmarksImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import bookmarks as entries of a bookmarks feed",
+	Long: `Bring the service's bookmarks into digest as unread entries of a feed named
+after the service, such as "bookmarks:linkding", so they can be read,
+searched, and listed like any other entry. Bookmarks imported before are
+skipped. 'digest fetch' leaves the feed alone; run import again to pick up
+new bookmarks.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		bcfg := cfg.GetBookmarks()
+		client, err := bookmarks.New(bcfg)
+		if err != nil {
+			return err
+		}
+		marks, err := client.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list bookmarks: %w", err)
+		}
+		added, err := bookmarks.Import(ctx, store, bcfg.Service, marks)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d new bookmark(s) of %d into %s\n", added, len(marks), bookmarks.FeedURL(bcfg.Service))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bookmarksCmd)
+	bookmarksCmd.AddCommand(bookmarksPushCmd)
+	bookmarksCmd.AddCommand(bookmarksImportCmd)
+
+	bookmarksPushCmd.Flags().Bool("dry-run", false, "show what would be bookmarked without sending anything")
+	bookmarksPushCmd.Flags().Bool("again", false, "push the given entries even if they were pushed before")
+	bookmarksPushCmd.ValidArgsFunction = entryIDListArgs
+}
@@ -0,0 +1,273 @@
+// ABOUTME: Check-links command that probes stored entry links for link rot
+// ABOUTME: Flags 404/410 links, reports rot per feed, and can attach Wayback Machine copies of dead pages
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/archive"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/linkcheck"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+var checkLinksCmd = &cobra.Command{
+	Use:   "check-links",
+	Short: "Find broken links in stored entries",
+	Long: `Probe the links of stored entries and report link rot per feed. A link
+is broken when the site answers 404 Not Found or 410 Gone; timeouts and
+server errors are counted separately since they are often temporary.
+
+Requests are spread out: --concurrency bounds how many are in flight and
+--delay spaces out requests to the same site. Sites' robots.txt rules and
+Crawl-delay are honored too; a link robots.txt disallows counts as an
+error. A link shared by several entries is checked once.
+
+For broken links, digest can look up the most recent Wayback Machine copy
+and store it on the entry as its archive link (shown by 'digest read').
+It asks first; --archive does it without asking.
+
+--quiet prints only the broken links.
+--porcelain prints one tab-separated record per broken or failed link:
+  status (broken or error), HTTP code, entry ID, feed URL, link, archive URL
+
+Examples:
+  digest check-links --since month
+  digest check-links -c Tech --archive
+  digest check-links --concurrency 4 --delay 2s`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		mode := getOutputMode(cmd)
+		out := cmd.OutOrStdout()
+		since, _ := cmd.Flags().GetString("since")
+		feedFilter, _ := cmd.Flags().GetString("feed")
+		category, _ := cmd.Flags().GetString("category")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		delay, _ := cmd.Flags().GetDuration("delay")
+		archiveBroken, _ := cmd.Flags().GetBool("archive")
+
+		if concurrency <= 0 {
+			return fmt.Errorf("--concurrency must be positive")
+		}
+		filter := &storage.EntryFilter{}
+		if err := applySince(filter, since); err != nil {
+			return err
+		}
+		if feedFilter != "" {
+			feed, err := store.GetFeedByURLOrPrefix(ctx, feedFilter)
+			if err != nil {
+				return fmt.Errorf("failed to find feed: %w", err)
+			}
+			filter.FeedID = &feed.ID
+		}
+		if category != "" {
+			for _, opmlFeed := range opmlDoc.FeedsInFolder(category) {
+				if feed, err := store.GetFeedByURL(ctx, opmlFeed.URL); err == nil {
+					filter.FeedIDs = append(filter.FeedIDs, feed.ID)
+				}
+			}
+			if len(filter.FeedIDs) == 0 {
+				return fmt.Errorf("no synced feeds found in category %q", category)
+			}
+		}
+
+		entries, err := store.ListEntries(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		feeds, err := store.ListFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list feeds: %w", err)
+		}
+		feedsByID := make(map[string]*models.Feed, len(feeds))
+		for _, feed := range feeds {
+			feedsByID[feed.ID] = feed
+		}
+
+		byID := make(map[string]*models.Entry, len(entries))
+		localLinks := make(map[string]bool)
+		var targets []linkcheck.Target
+		for _, entry := range entries {
+			if entry.Link == nil || *entry.Link == "" {
+				continue
+			}
+			byID[entry.ID] = entry
+			if feed := feedsByID[entry.FeedID]; feed != nil && feed.LocalNetwork {
+				localLinks[*entry.Link] = true
+			}
+			targets = append(targets, linkcheck.Target{EntryID: entry.ID, FeedID: entry.FeedID, Link: *entry.Link})
+		}
+		if len(targets) == 0 {
+			if mode == outputNormal {
+				fmt.Println("No entry links to check")
+			}
+			return nil
+		}
+
+		checker := linkcheck.New(concurrency, delay, false)
+		// Links from feeds marked local_network may point at private addresses
+		checker.Probe = func(ctx context.Context, link string) (int, error) {
+			return fetch.ProbePage(ctx, link, fetch.PageOptions{AllowLocalNetwork: localLinks[link]})
+		}
+		var progress func(done, total int)
+		if mode == outputNormal {
+			progress = func(done, total int) {
+				fmt.Fprintf(os.Stderr, "\rChecking links... %d/%d", done, total)
+			}
+		}
+		results := checker.Check(ctx, targets, progress)
+		if progress != nil {
+			fmt.Fprintln(os.Stderr)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var broken []linkcheck.Result
+		for _, r := range results {
+			if r.Status == linkcheck.StatusBroken {
+				broken = append(broken, r)
+			}
+		}
+
+		if mode == outputNormal {
+			printLinkRot(results, feedsByID, byID)
+			if len(broken) == 0 {
+				return nil
+			}
+			if !archiveBroken {
+				fmt.Printf("\nLook up Wayback Machine copies of %d broken links? (y/N): ", len(broken))
+				var confirm string
+				fmt.Scanln(&confirm)
+				if confirm != "y" && confirm != "Y" {
+					return nil
+				}
+			}
+			found, err := attachArchives(ctx, store, broken, byID)
+			if err != nil {
+				return err
+			}
+			green := color.New(color.FgGreen).SprintFunc()
+			fmt.Printf("%s Found archived copies for %d of %d broken links\n", green("v"), found, len(broken))
+			return nil
+		}
+
+		if archiveBroken && len(broken) > 0 {
+			if _, err := attachArchives(ctx, store, broken, byID); err != nil {
+				return err
+			}
+		}
+		if mode == outputQuiet {
+			for _, r := range broken {
+				fmt.Fprintln(out, r.Link)
+			}
+			return nil
+		}
+		for _, r := range results {
+			if r.Status == linkcheck.StatusOK {
+				continue
+			}
+			feedURL, archiveURL := "", ""
+			if feed := feedsByID[r.FeedID]; feed != nil {
+				feedURL = feed.URL
+			}
+			if entry := byID[r.EntryID]; entry.ArchiveURL != nil {
+				archiveURL = *entry.ArchiveURL
+			}
+			writePorcelain(out, string(r.Status), strconv.Itoa(r.Code), r.EntryID, feedURL, r.Link, archiveURL)
+		}
+		return nil
+	},
+}
+
+// printLinkRot prints the per-feed summary followed by each broken link.
+func printLinkRot(results []linkcheck.Result, feedsByID map[string]*models.Feed, byID map[string]*models.Entry) {
+	faint := color.New(color.Faint).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	feedName := func(id string) string {
+		if feed := feedsByID[id]; feed != nil {
+			return feed.GetDisplayName()
+		}
+		return id
+	}
+
+	fmt.Println(bold("Link rot by feed"))
+	for _, rot := range linkcheck.Summarize(results) {
+		line := fmt.Sprintf("  %-40s %3d broken of %3d (%.0f%%)", feedName(rot.FeedID), rot.Broken, rot.Checked, rot.Percent())
+		if rot.Errors > 0 {
+			line += faint(fmt.Sprintf(", %d unreachable", rot.Errors))
+		}
+		fmt.Println(line)
+	}
+
+	var printed bool
+	for _, r := range results {
+		if r.Status != linkcheck.StatusBroken {
+			continue
+		}
+		if !printed {
+			fmt.Printf("\n%s\n", bold("Broken links"))
+			printed = true
+		}
+		entry := byID[r.EntryID]
+		fmt.Printf("  %s %s\n", red(strconv.Itoa(r.Code)), entry.GetTitle())
+		fmt.Printf("      %s\n", faint(r.Link))
+		if entry.ArchiveURL != nil {
+			fmt.Printf("      %s %s\n", faint("archive:"), *entry.ArchiveURL)
+		}
+	}
+}
+
+// attachArchives stores the closest Wayback Machine snapshot on each broken
+// entry that doesn't have an archive link yet, and returns how many now have one.
+func attachArchives(ctx context.Context, s storage.Store, broken []linkcheck.Result, byID map[string]*models.Entry) (int, error) {
+	client := archive.New()
+	found := 0
+	for _, r := range broken {
+		entry := byID[r.EntryID]
+		if entry.ArchiveURL != nil {
+			found++
+			continue
+		}
+		snapshot, err := client.Closest(ctx, r.Link)
+		if err != nil {
+			if ctx.Err() != nil {
+				return found, ctx.Err()
+			}
+			continue
+		}
+		entry.ArchiveURL = &snapshot
+		if err := s.UpdateEntry(ctx, entry); err != nil {
+			return found, fmt.Errorf("failed to save archive link: %w", err)
+		}
+		found++
+	}
+	return found, nil
+}
+
+func init() {
+	rootCmd.AddCommand(checkLinksCmd)
+
+	checkLinksCmd.Flags().String("since", "", "only entries since a date such as month, '30 days ago', or a range from..until")
+	checkLinksCmd.Flags().StringP("feed", "f", "", "only entries from this feed URL or prefix")
+	checkLinksCmd.Flags().StringP("category", "c", "", "only entries from this folder")
+	checkLinksCmd.Flags().Int("concurrency", linkcheck.DefaultConcurrency, "links to check at once")
+	checkLinksCmd.Flags().Duration("delay", linkcheck.DefaultHostDelay, "minimum time between requests to the same site")
+	checkLinksCmd.Flags().Bool("archive", false, "attach Wayback Machine copies to broken entries without asking")
+	addOutputFlags(checkLinksCmd, "print only the broken links")
+	checkLinksCmd.MarkFlagsMutuallyExclusive("feed", "category")
+	_ = checkLinksCmd.RegisterFlagCompletionFunc("feed", feedURLFlag)
+	_ = checkLinksCmd.RegisterFlagCompletionFunc("category", folderFlag)
+	_ = checkLinksCmd.RegisterFlagCompletionFunc("since", cobra.FixedCompletions(sinceCompletions, cobra.ShellCompDirectiveNoFileComp))
+}
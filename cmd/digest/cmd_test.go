@@ -5,6 +5,8 @@ package main
 
 import (
 	"testing"
+
+	"github.com/spf13/cobra"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -40,6 +42,9 @@ func TestFeedAddCommand(t *testing.T) {
 	if feedAddCmd.Flags().Lookup("no-discover") == nil {
 		t.Error("expected --no-discover flag to exist")
 	}
+	if feedAddCmd.Flags().Lookup("ignore-robots") == nil {
+		t.Error("expected --ignore-robots flag to exist")
+	}
 }
 
 func TestFeedListCommand(t *testing.T) {
@@ -99,14 +104,23 @@ func TestListCommand(t *testing.T) {
 }
 
 func TestReadCommand(t *testing.T) {
-	if readCmd.Use != "read <entry-id>" {
-		t.Errorf("expected Use to be 'read <entry-id>', got %q", readCmd.Use)
+	if readCmd.Use != "read [entry-id]" {
+		t.Errorf("expected Use to be 'read [entry-id]', got %q", readCmd.Use)
+	}
+	if readCmd.ValidArgsFunction == nil {
+		t.Error("expected read command to complete entry IDs")
 	}
 
 	// Check flags exist
 	if readCmd.Flags().Lookup("no-mark") == nil {
 		t.Error("expected --no-mark flag to exist")
 	}
+	if readCmd.Flags().Lookup("format") == nil {
+		t.Error("expected --format flag to exist")
+	}
+	if len(readCmd.Aliases) == 0 || readCmd.Aliases[0] != "show" {
+		t.Errorf("expected read to be aliased as show, got %v", readCmd.Aliases)
+	}
 }
 
 func TestMarkReadCommand(t *testing.T) {
@@ -130,6 +144,9 @@ func TestFetchCommand(t *testing.T) {
 	if fetchCmd.Use != "fetch [url]" {
 		t.Errorf("expected Use to be 'fetch [url]', got %q", fetchCmd.Use)
 	}
+	if !fetchCmd.HasAlias("sync") {
+		t.Error("expected fetch command to have a 'sync' alias")
+	}
 
 	// Check flags exist
 	if fetchCmd.Flags().Lookup("force") == nil {
@@ -137,6 +154,12 @@ func TestFetchCommand(t *testing.T) {
 	}
 }
 
+func TestDoctorCommand(t *testing.T) {
+	if doctorCmd.Flags().Lookup("fix") == nil {
+		t.Error("expected --fix flag to exist")
+	}
+}
+
 func TestFolderCommand(t *testing.T) {
 	if folderCmd.Use != "folder" {
 		t.Errorf("expected Use to be 'folder', got %q", folderCmd.Use)
@@ -198,6 +221,28 @@ func TestCommandRegistration(t *testing.T) {
 		"version",
 		"install-skill",
 		"profile",
+		"stats",
+		"doctor",
+		"maintenance",
+		"audit",
+		"listen",
+		"serve",
+		"trending",
+		"authors",
+		"publish",
+		"check-links",
+		"debug",
+		"reprocess",
+		"next",
+		"session",
+		"unread",
+		"bookmarks",
+		"deliver",
+		"team",
+		"share-digest",
+		"digests",
+		"init",
+		"self-update",
 	}
 
 	for _, expected := range expectedCommands {
@@ -268,3 +313,77 @@ func TestFolderSubcommands(t *testing.T) {
 		}
 	}
 }
+
+func TestDynamicCompletionRegistered(t *testing.T) {
+	for _, cmd := range []*cobra.Command{feedRemoveCmd, feedMoveCmd, fetchCmd, openCmd, discussCmd, keepUnreadCmd, markReadCmd, markUnreadCmd, profileRemoveCmd, profileSetDefaultCmd} {
+		if cmd.ValidArgsFunction == nil {
+			t.Errorf("expected %q to have dynamic argument completion", cmd.CommandPath())
+		}
+	}
+	if _, ok := feedAddCmd.GetFlagCompletionFunc("folder"); !ok {
+		t.Error("expected --folder on feed add to have completion")
+	}
+	if _, ok := rootCmd.GetFlagCompletionFunc("profile"); !ok {
+		t.Error("expected --profile to have completion")
+	}
+}
+
+func TestFeedMoveArgsNoCompletionPastFolder(t *testing.T) {
+	got, directive := feedMoveArgs(feedMoveCmd, []string{"https://example.com/feed", "Tech"}, "")
+	if len(got) != 0 {
+		t.Errorf("expected no completions after two args, got %v", got)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("expected NoFileComp directive, got %v", directive)
+	}
+}
+
+func TestMaintenanceSubcommands(t *testing.T) {
+	found := false
+	for _, cmd := range maintenanceCmd.Commands() {
+		if cmd.Name() == "reindex" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected maintenance subcommand \"reindex\" to be registered")
+	}
+}
+
+func TestDebugSubcommands(t *testing.T) {
+	names := make(map[string]bool)
+	for _, cmd := range debugCmd.Commands() {
+		names[cmd.Name()] = true
+	}
+	for _, want := range []string{"snapshots", "replay"} {
+		if !names[want] {
+			t.Errorf("expected debug subcommand %q to be registered", want)
+		}
+	}
+}
+
+func TestAuditSubcommands(t *testing.T) {
+	found := false
+	for _, cmd := range auditCmd.Commands() {
+		if cmd.Name() == "tail" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected audit subcommand \"tail\" to be registered")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
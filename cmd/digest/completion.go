@@ -0,0 +1,307 @@
+// ABOUTME: Dynamic shell completion for feed URLs, folders, labels, entry IDs, and profiles
+// ABOUTME: Opens profile storage on demand since completion bypasses PersistentPreRunE
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+// completionEntryLimit caps how many entry IDs are offered for completion.
+const completionEntryLimit = 50
+
+// completionIDLength is how many characters of an entry ID are completed.
+const completionIDLength = 8
+
+// withCompletionStore opens the active profile's storage for a completion request.
+// Errors are swallowed so a broken config never breaks the user's shell.
+func withCompletionStore(cmd *cobra.Command, fn func(ctx context.Context, s storage.Store) []string) []string {
+	c, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	profile := profileName
+	if !cmd.Flags().Changed("profile") {
+		profile = c.GetDefaultProfile()
+	}
+	if err := c.ApplyProfile(profile); err != nil {
+		return nil
+	}
+	s, err := c.OpenProfileStorage(profile)
+	if err != nil {
+		return nil
+	}
+	defer s.Close()
+	return fn(cmd.Context(), s)
+}
+
+// loadCompletionOPML loads the active profile's OPML document for a completion request.
+func loadCompletionOPML(cmd *cobra.Command) *opml.Document {
+	path := opmlPath
+	if path == "" {
+		c, err := config.Load()
+		if err != nil {
+			return nil
+		}
+		profile := profileName
+		if !cmd.Flags().Changed("profile") {
+			profile = c.GetDefaultProfile()
+		}
+		dir, err := c.ProfileDataDir(profile)
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(dir, "feeds.opml")
+	}
+	doc, err := opml.ParseFile(path)
+	if err != nil {
+		return nil
+	}
+	return doc
+}
+
+// completeFeedURLs offers subscribed feed URLs, described by their titles.
+func completeFeedURLs(cmd *cobra.Command, toComplete string) []string {
+	return withCompletionStore(cmd, func(ctx context.Context, s storage.Store) []string {
+		feeds, err := s.ListFeeds(ctx)
+		if err != nil {
+			return nil
+		}
+		var out []string
+		for _, feed := range feeds {
+			if strings.HasPrefix(feed.URL, toComplete) {
+				out = append(out, feed.URL+"\t"+feed.GetDisplayName())
+			}
+		}
+		return out
+	})
+}
+
+// completeFolders offers folder names from the OPML file.
+func completeFolders(cmd *cobra.Command, toComplete string) []string {
+	doc := loadCompletionOPML(cmd)
+	if doc == nil {
+		return nil
+	}
+	var out []string
+	for _, folder := range doc.Folders() {
+		if strings.HasPrefix(strings.ToLower(folder), strings.ToLower(toComplete)) {
+			out = append(out, folder)
+		}
+	}
+	return out
+}
+
+// completeLabels offers the labels on feeds.
+func completeLabels(cmd *cobra.Command, toComplete string) []string {
+	doc := loadCompletionOPML(cmd)
+	if doc == nil {
+		return nil
+	}
+	var out []string
+	for _, label := range doc.Labels() {
+		if strings.HasPrefix(strings.ToLower(label), strings.ToLower(toComplete)) {
+			out = append(out, label)
+		}
+	}
+	return out
+}
+
+// completeEntryIDs offers recent entry ID prefixes, described by their titles.
+// When unreadOnly is set only unread entries are offered.
+func completeEntryIDs(cmd *cobra.Command, toComplete string, unreadOnly bool) []string {
+	return withCompletionStore(cmd, func(ctx context.Context, s storage.Store) []string {
+		limit := completionEntryLimit
+		filter := &storage.EntryFilter{Limit: &limit}
+		if unreadOnly {
+			filter.UnreadOnly = &unreadOnly
+		}
+		entries, err := s.ListEntries(ctx, filter)
+		if err != nil {
+			return nil
+		}
+		var out []string
+		for _, entry := range entries {
+			id := entry.ID
+			if len(id) > completionIDLength {
+				id = id[:completionIDLength]
+			}
+			if !strings.HasPrefix(id, toComplete) {
+				continue
+			}
+			title := "Untitled"
+			if entry.Title != nil && *entry.Title != "" {
+				title = *entry.Title
+			}
+			out = append(out, id+"\t"+title)
+		}
+		return out
+	})
+}
+
+// completeProfiles offers profile directory names from the data directory.
+func completeProfiles(toComplete string) []string {
+	c, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	dirEntries, err := os.ReadDir(c.GetDataDir())
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range dirEntries {
+		if e.IsDir() && strings.HasPrefix(e.Name(), toComplete) {
+			out = append(out, e.Name())
+		}
+	}
+	return out
+}
+
+// feedURLArgs completes the first positional argument with feed URLs.
+func feedURLArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeFeedURLs(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// feedMoveArgs completes a feed URL followed by a destination folder.
+func feedMoveArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeFeedURLs(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return completeFolders(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// labelFeedArgs completes a feed URL followed by labels, for 'label add'
+// and 'label remove'.
+func labelFeedArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeFeedURLs(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeLabels(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// labelArgs completes the first positional argument with label names.
+func labelArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeLabels(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// feedPriorityArgs completes a feed URL followed by a priority.
+func feedPriorityArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeFeedURLs(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return models.Priorities, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// feedMergeArgs completes both positional arguments of 'feed merge' with feed URLs.
+func feedMergeArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) >= 2 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeFeedURLs(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// feedIdentityArgs completes a feed URL followed by an identity strategy.
+func feedIdentityArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeFeedURLs(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return []string{"auto", "guid", "link", "hash"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// entryIDArgs completes the first positional argument with entry IDs.
+func entryIDArgs(unreadOnly bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeEntryIDs(cmd, toComplete, unreadOnly), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// entryIDListArgs completes entry IDs for every argument of commands that
+// take several, leaving out those already given.
+func entryIDListArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	candidates := completeEntryIDs(cmd, toComplete, false)
+	out := candidates[:0]
+	for _, c := range candidates {
+		value, _, _ := strings.Cut(c, "\t")
+		if !slices.Contains(args, value) {
+			out = append(out, c)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// openArgs completes entry IDs for 'digest open', or feed URLs with --feed.
+// Every argument is completed since several may be given.
+func openArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var candidates []string
+	if feedMode, _ := cmd.Flags().GetBool("feed"); feedMode {
+		candidates = completeFeedURLs(cmd, toComplete)
+	} else {
+		candidates = completeEntryIDs(cmd, toComplete, false)
+	}
+	out := candidates[:0]
+	for _, c := range candidates {
+		value, _, _ := strings.Cut(c, "\t")
+		if !slices.Contains(args, value) {
+			out = append(out, c)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// folderFlag completes a --folder style flag with folder names.
+func folderFlag(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeFolders(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// labelFlag completes a --label flag with label names.
+func labelFlag(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeLabels(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// feedURLFlag completes a --feed style flag with feed URLs.
+func feedURLFlag(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeFeedURLs(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// profileFlag completes the --profile flag with profile names.
+func profileFlag(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeProfiles(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// profileArgs completes the first positional argument with profile names.
+func profileArgs(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProfiles(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Date flag parsing shared by commands that filter entries by time
+// ABOUTME: Resolves periods, relative dates, and from..until ranges in the configured timezone
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/timeutil"
+)
+
+// sinceCompletions are suggested for --since flags.
+var sinceCompletions = []string{"today", "yesterday", "week", "month", "last week", "last month"}
+
+// parseDateFlag resolves a date flag value such as "week", "2024-01-15", or
+// "3 days ago" in the user's timezone.
+func parseDateFlag(flag, value string) (time.Time, error) {
+	loc, err := userLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := timeutil.ParseDate(value, time.Now().In(loc))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s: %w", flag, err)
+	}
+	return t, nil
+}
+
+// parseSinceRange resolves a --since value, which may also be a range
+// "from..until" such as "2024-01-01..2024-02-01".
+func parseSinceRange(value string) (timeutil.Range, error) {
+	loc, err := userLocation()
+	if err != nil {
+		return timeutil.Range{}, err
+	}
+	r, err := timeutil.ParseRange(value, time.Now().In(loc))
+	if err != nil {
+		return timeutil.Range{}, fmt.Errorf("invalid --since: %w", err)
+	}
+	return r, nil
+}
+
+// applySince narrows filter to a --since value. An empty value leaves the
+// filter alone.
+func applySince(filter *storage.EntryFilter, value string) error {
+	if value == "" {
+		return nil
+	}
+	r, err := parseSinceRange(value)
+	if err != nil {
+		return err
+	}
+	if !r.Since.IsZero() {
+		filter.Since = &r.Since
+	}
+	if !r.Until.IsZero() {
+		filter.Until = &r.Until
+	}
+	return nil
+}
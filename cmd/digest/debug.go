@@ -0,0 +1,178 @@
+// ABOUTME: Debug commands for looking into how feeds were fetched and parsed
+// ABOUTME: snapshots lists a feed's stored raw responses; replay reparses one and can import missing entries
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/snapshot"
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Inspect how feeds were fetched and parsed",
+	Long: `Tools for tracking down feed problems, such as an entry that never showed up.
+
+They work from raw response snapshots, which 'digest fetch' keeps when
+"feed_snapshots" in config.json is set to how many responses to keep per feed.`,
+}
+
+var debugSnapshotsCmd = &cobra.Command{
+	Use:   "snapshots <url-or-id>",
+	Short: "List a feed's stored raw responses",
+	Long: `List the raw responses kept for a feed, newest first, numbered for
+'digest debug replay --snapshot'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		feed, err := store.GetFeedByURLOrPrefix(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to find feed: %w", err)
+		}
+		dir, err := snapshotDir()
+		if err != nil {
+			return err
+		}
+		snapshots, err := snapshot.List(dir, feed.ID)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			fmt.Printf("No snapshots of %s%s\n", feed.URL, snapshotsHint())
+			return nil
+		}
+		faint := color.New(color.Faint).SprintFunc()
+		for i, snap := range snapshots {
+			fmt.Printf("%d  %s  %s\n", i+1, snap.FetchedAt.Local().Format(time.DateTime), faint(formatBytes(snap.Size)))
+		}
+		return nil
+	},
+}
+
+var debugReplayCmd = &cobra.Command{
+	Use:   "replay <url-or-id>",
+	Short: "Reparse a stored raw response of a feed",
+	Long: `Parse a feed's stored raw response again with the current parser and
+list its items, marking the ones missing from the store. Use it to find
+out why an entry never showed up, or to pick up entries a parser fix now
+reads. The newest snapshot is replayed unless --snapshot picks an older
+one by its number in 'digest debug snapshots'.
+
+--import stores the missing items as a sync would, without touching the
+feed's fetch state.
+
+--porcelain prints one tab-separated record per item:
+  status (stored/missing/imported), key, published, title, link`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		n, _ := cmd.Flags().GetInt("snapshot")
+		apply, _ := cmd.Flags().GetBool("import")
+		mode := getOutputMode(cmd)
+
+		feed, err := store.GetFeedByURLOrPrefix(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to find feed: %w", err)
+		}
+		dir, err := snapshotDir()
+		if err != nil {
+			return err
+		}
+		snapshots, err := snapshot.List(dir, feed.ID)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			return fmt.Errorf("no snapshots of %s%s", feed.URL, snapshotsHint())
+		}
+		if n < 1 || n > len(snapshots) {
+			return fmt.Errorf("--snapshot must be between 1 and %d", len(snapshots))
+		}
+		snap := snapshots[n-1]
+		body, err := snapshot.Read(snap.Path)
+		if err != nil {
+			return err
+		}
+
+		var opts feedsync.Options
+		if apply {
+			dates, err := cfg.GetDatePolicy()
+			if err != nil {
+				return err
+			}
+			thumbs, err := thumbnailDir()
+			if err != nil {
+				return err
+			}
+			opts = feedsync.Options{Dates: dates, Images: cfg.GetImageOptions(thumbs)}
+		}
+		result, err := feedsync.Replay(ctx, store, feed, body, opts, apply)
+		if err != nil {
+			return err
+		}
+
+		missing := "missing"
+		if apply {
+			missing = "imported"
+		}
+		if mode == outputPorcelain {
+			out := cmd.OutOrStdout()
+			for _, e := range result.Entries {
+				status := "stored"
+				if !e.Stored {
+					status = missing
+				}
+				writePorcelain(out, status, e.Key, porcelainTime(e.PublishedAt), e.Title, e.Link)
+			}
+			return nil
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		yellow := color.New(color.FgYellow).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+		fmt.Printf("%s %s\n", result.Title, faint("(snapshot of "+snap.FetchedAt.Local().Format(time.DateTime)+")"))
+		for _, e := range result.Entries {
+			mark := green("v")
+			if !e.Stored {
+				mark = yellow("+")
+			}
+			title := e.Title
+			if title == "" {
+				title = e.Link
+			}
+			fmt.Printf("  %s %s %s\n", mark, title, faint(e.Key))
+		}
+		fmt.Println()
+		switch {
+		case apply:
+			fmt.Printf("%d item(s), %d imported\n", len(result.Entries), result.NewEntries)
+		case result.Missing() > 0:
+			fmt.Printf("%d item(s), %d missing; run with --import to store them\n", len(result.Entries), result.Missing())
+		default:
+			fmt.Printf("%d item(s), all stored\n", len(result.Entries))
+		}
+		return nil
+	},
+}
+
+// snapshotsHint explains how to turn snapshots on when they are off.
+func snapshotsHint() string {
+	if cfg.FeedSnapshots > 0 {
+		return ""
+	}
+	return " (set \"feed_snapshots\" in config.json to keep them)"
+}
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugSnapshotsCmd)
+	debugCmd.AddCommand(debugReplayCmd)
+	debugReplayCmd.Flags().IntP("snapshot", "n", 1, "snapshot to replay, 1 being the newest")
+	debugReplayCmd.Flags().Bool("import", false, "store the missing items")
+	debugReplayCmd.Flags().Bool("porcelain", false, "stable tab-separated output for scripts")
+}
@@ -0,0 +1,194 @@
+// ABOUTME: Deliver command and the hook that posts new entries to Telegram and Matrix after a fetch
+// ABOUTME: Sends to the targets in config.json with tokens from the secrets store
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/deliver"
+)
+
+var deliverCmd = &cobra.Command{
+	Use:   "deliver",
+	Short: "Post new entries to Telegram or Matrix",
+	Long: `Post new entries, or a daily digest, to Telegram chats and Matrix rooms.
+
+After each fetch, digest posts every target the unread entries of
+high-priority feeds ('digest feed priority') stored since it was last
+posted to. Configure targets in config.json:
+
+  "deliver": {
+    "targets": [
+      {"name": "phone", "type": "telegram", "chat_id": "123456789"},
+      {"name": "team", "type": "matrix", "homeserver": "https://matrix.org",
+       "room": "!abc123:matrix.org", "folders": ["Work"], "mode": "daily", "hour": 9}
+    ]
+  }
+
+"folders" routes only entries of feeds in those OPML folders to a target.
+"mode": "daily" sends one digest a day, from "hour" (default 8) in the
+configured timezone, instead of a message per fetch, and covers every feed
+whatever its priority. Messages list at most
+"batch_size" entries (default 10), grouped by folder, as Markdown.
+
+Each target's token is a secret named after it:
+  digest secrets set deliver/phone    # Telegram bot token from @BotFather
+  digest secrets set deliver/team     # Matrix access token
+
+A target's first run only marks where it starts, so the existing backlog
+isn't posted. Run 'digest deliver' to post what's due now without fetching.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		dcfg := cfg.GetDeliver()
+		if len(dcfg.Targets) == 0 {
+			return fmt.Errorf("no delivery targets configured; add \"deliver\" to %s", config.GetConfigPath())
+		}
+		now, err := deliverNow()
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			src, err := deliver.Collect(ctx, store, opmlDoc)
+			if err != nil {
+				return err
+			}
+			path, err := deliverStatePath()
+			if err != nil {
+				return err
+			}
+			state, err := deliver.LoadState(path)
+			if err != nil {
+				return err
+			}
+			for _, t := range dcfg.Targets {
+				msgs, _ := deliver.Due(t, state[t.Name], src, now)
+				fmt.Printf("%s: %d message(s)\n", t.Name, len(msgs))
+				for _, m := range msgs {
+					fmt.Println()
+					fmt.Print(m.Markdown())
+				}
+			}
+			return nil
+		}
+
+		results, err := runDelivery(ctx, dcfg, now)
+		if err != nil {
+			return err
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+		failed := 0
+		for _, r := range results {
+			if r.Err != nil {
+				fmt.Printf("%s %s: %v\n", red("x"), r.Target, r.Err)
+				failed++
+				continue
+			}
+			fmt.Printf("%s %s: %d entries in %d message(s)\n", green("v"), r.Target, r.Entries, r.Messages)
+		}
+		if failed > 0 {
+			// Each failure was reported above
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d delivery target(s) failed", failed, len(results))
+		}
+		return nil
+	},
+}
+
+var deliverTestCmd = &cobra.Command{
+	Use:   "test [target]",
+	Short: "Send a sample message to every target, or one",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dcfg := cfg.GetDeliver()
+		sample := deliver.Message{
+			Title: "digest test message",
+			Sections: []deliver.Section{{
+				Folder: "Example",
+				Items:  []deliver.Item{{Title: "This is a test from 'digest deliver test'", Link: "https://example.com/", Feed: "Example Feed"}},
+			}},
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		sent := 0
+		for _, t := range dcfg.Targets {
+			if len(args) > 0 && t.Name != args[0] {
+				continue
+			}
+			if err := t.Validate(); err != nil {
+				return err
+			}
+			if err := deliver.Send(cmd.Context(), t, sample); err != nil {
+				return fmt.Errorf("failed to send to %s: %w", t.Name, err)
+			}
+			fmt.Printf("%s Test message sent to %s\n", green("v"), t.Name)
+			sent++
+		}
+		if sent == 0 {
+			if len(args) > 0 {
+				return fmt.Errorf("no delivery target named %q", args[0])
+			}
+			return fmt.Errorf("no delivery targets configured; add \"deliver\" to %s", config.GetConfigPath())
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deliverCmd)
+	deliverCmd.AddCommand(deliverTestCmd)
+	deliverCmd.Flags().Bool("dry-run", false, "print the messages due without sending them")
+}
+
+// deliverNow returns the current time in the configured timezone, which
+// daily targets' hours are in.
+func deliverNow() (time.Time, error) {
+	loc, err := userLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().In(loc), nil
+}
+
+// runDelivery sends every target what's due at now.
+func runDelivery(ctx context.Context, dcfg deliver.Config, now time.Time) ([]deliver.Result, error) {
+	path, err := deliverStatePath()
+	if err != nil {
+		return nil, err
+	}
+	src, err := deliver.Collect(ctx, store, opmlDoc)
+	if err != nil {
+		return nil, err
+	}
+	return deliver.Run(ctx, dcfg, src, path, now)
+}
+
+// deliverEntries posts new entries to the delivery targets after a fetch.
+// Delivery problems are reported on errOut but never fail the fetch.
+func deliverEntries(ctx context.Context, errOut io.Writer) {
+	dcfg := cfg.GetDeliver()
+	if len(dcfg.Targets) == 0 {
+		return
+	}
+	now, err := deliverNow()
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: could not deliver entries: %v\n", err)
+		return
+	}
+	results, err := runDelivery(ctx, dcfg, now)
+	if err == nil {
+		err = deliver.Errors(results)
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: could not deliver entries: %v\n", err)
+	}
+}
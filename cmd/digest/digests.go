@@ -0,0 +1,218 @@
+// ABOUTME: Digests commands browsing the dated digests generated after each day's first fetch
+// ABOUTME: Lists the history, prints one day's digest as Markdown or HTML, and generates today's on demand
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/digests"
+	"github.com/harper/digest/internal/render"
+	"github.com/harper/digest/internal/timeutil"
+)
+
+var digestsCmd = &cobra.Command{
+	Use:   "digests",
+	Short: "Browse the daily digest history",
+	Long: `Keep a dated digest of each day's new entries, as Markdown and HTML.
+
+With "digests" in config.json, the first fetch from "hour" (default 7) in
+the configured timezone writes that day's digest: every entry first seen
+since the previous day's digest. Days with nothing new get no digest.
+
+  "digests": {"hour": 7, "template": "brief", "folders": ["Tech"]}
+
+"template" renders the Markdown document (default "markdown"; see
+'digest export --template'), and "folders" limits digests to those OPML
+folders. Running 'digest digests generate' again the same day only
+rewrites the digest when its entries changed.
+
+Digests are also MCP resources, at digest://digests and
+digest://digests/2025-01-15.
+
+Examples:
+  digest digests                     # the history
+  digest digests show                # the latest digest
+  digest digests show yesterday --html > yesterday.html
+  digest digests generate            # today's digest now, whatever the hour`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listDigests()
+	},
+}
+
+var digestsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List generated digests",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listDigests()
+	},
+}
+
+var digestsShowCmd = &cobra.Command{
+	Use:   "show [date]",
+	Short: "Print a day's digest, the latest by default",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asHTML, _ := cmd.Flags().GetBool("html")
+		dir, err := digestsDir()
+		if err != nil {
+			return err
+		}
+		h, err := digests.Load(dir)
+		if err != nil {
+			return err
+		}
+		if len(h) == 0 {
+			return fmt.Errorf("no digests yet; generate one with 'digest digests generate'")
+		}
+		date := h[len(h)-1].Date
+		if len(args) > 0 {
+			now, err := digestNow()
+			if err != nil {
+				return err
+			}
+			day, err := timeutil.ParseDate(args[0], now)
+			if err != nil {
+				return err
+			}
+			date = day.Format(digests.DateLayout)
+		}
+		format := digests.Markdown
+		if asHTML {
+			format = digests.HTML
+		}
+		data, err := digests.Read(dir, date, format)
+		if errors.Is(err, digests.ErrNotFound) {
+			return fmt.Errorf("no digest for %s", date)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
+var digestsGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate today's digest now",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := digestGenerator()
+		if err != nil {
+			return err
+		}
+		now, err := digestNow()
+		if err != nil {
+			return err
+		}
+		rec, wrote, err := g.Generate(cmd.Context(), now)
+		if err != nil {
+			return err
+		}
+		faint := color.New(color.Faint).SprintFunc()
+		switch {
+		case wrote:
+			green := color.New(color.FgGreen).SprintFunc()
+			fmt.Printf("%s Digest for %s: %d entry(s)\n", green("v"), rec.Date, rec.Entries)
+			for _, format := range []string{digests.Markdown, digests.HTML} {
+				path, _ := digests.Path(g.Dir, rec.Date, format)
+				fmt.Printf("  %s\n", faint(path))
+			}
+		case rec.Entries == 0:
+			fmt.Printf("No new entries since %s\n", rec.Since.Format("January 2 15:04"))
+		default:
+			fmt.Printf("Digest for %s is up to date %s\n", rec.Date, faint(fmt.Sprintf("(%d entry(s))", rec.Entries)))
+		}
+		return nil
+	},
+}
+
+func listDigests() error {
+	dir, err := digestsDir()
+	if err != nil {
+		return err
+	}
+	h, err := digests.Load(dir)
+	if err != nil {
+		return err
+	}
+	if len(h) == 0 {
+		if _, on := cfg.GetDigests(); !on {
+			fmt.Printf("No digests yet. Add \"digests\": {} to %s to write one daily.\n", config.GetConfigPath())
+		} else {
+			fmt.Println("No digests yet. One is written after the first fetch of the day.")
+		}
+		return nil
+	}
+	faint := color.New(color.Faint).SprintFunc()
+	for i := len(h) - 1; i >= 0; i-- {
+		r := h[i]
+		fmt.Printf("%s  %3d entry(s)  %s\n", r.Date, r.Entries, faint("generated "+r.GeneratedAt.Format("Jan 2 15:04")))
+	}
+	return nil
+}
+
+// digestNow returns the current time in the configured timezone, which
+// digests are dated and scheduled in.
+func digestNow() (time.Time, error) {
+	loc, err := userLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().In(loc), nil
+}
+
+// digestGenerator writes the active profile's digests with the configured
+// template.
+func digestGenerator() (*digests.Generator, error) {
+	dir, err := digestsDir()
+	if err != nil {
+		return nil, err
+	}
+	dcfg, _ := cfg.GetDigests()
+	tmpl, err := render.Load(config.TemplatesDir(), dcfg.TemplateName())
+	if err != nil {
+		return nil, err
+	}
+	return &digests.Generator{Dir: dir, Store: store, OPML: opmlDoc, Config: dcfg, Template: tmpl}, nil
+}
+
+// generateDigest writes the day's digest after a fetch once it's due.
+// Problems are reported on errOut but never fail the fetch.
+func generateDigest(ctx context.Context, errOut io.Writer) {
+	if _, on := cfg.GetDigests(); !on {
+		return
+	}
+	g, err := digestGenerator()
+	if err == nil {
+		var now time.Time
+		if now, err = digestNow(); err == nil {
+			_, _, err = g.GenerateDue(ctx, now)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: could not generate digest: %v\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(digestsCmd)
+	digestsCmd.AddCommand(digestsListCmd)
+	digestsCmd.AddCommand(digestsShowCmd)
+	digestsCmd.AddCommand(digestsGenerateCmd)
+
+	digestsShowCmd.Flags().Bool("html", false, "print the HTML page instead of Markdown")
+}
@@ -0,0 +1,107 @@
+// ABOUTME: Discuss command for reading or opening an entry's comment thread
+// ABOUTME: Prints comments from the thread's feed, or opens the thread page in the browser
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/discussion"
+)
+
+var discussCmd = &cobra.Command{
+	Use:   "discuss <entry-id>",
+	Short: "Read an entry's comment thread",
+	Long: `Print the comments on an entry, such as a Hacker News or Reddit post or a
+blog article with a comments feed.
+
+Threads come from the feed (RSS <comments> and wfw:commentRss, Atom replies
+links); Hacker News and Reddit threads are also recognized from their URLs,
+and read through hnrss.org and Reddit's own feeds. --open opens the thread
+page in the browser instead.
+
+Examples:
+  digest discuss abc123
+  digest discuss abc123 --limit 5
+  digest discuss abc123 --open`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		open, _ := cmd.Flags().GetBool("open")
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit < 0 {
+			return fmt.Errorf("--limit must not be negative")
+		}
+
+		entry, err := store.GetEntryByPrefix(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to find entry %s: %w", args[0], err)
+		}
+		thread := discussion.Thread(entry)
+		feedURL := discussion.FeedURL(entry)
+		if thread == "" && feedURL == "" {
+			return fmt.Errorf("entry %s has no discussion link", shortID(entry.ID))
+		}
+
+		if open {
+			if thread == "" {
+				return fmt.Errorf("entry %s has a comments feed but no thread page to open", shortID(entry.ID))
+			}
+			if err := openHTTPLink(thread); err != nil {
+				return fmt.Errorf("failed to open browser: %w", err)
+			}
+			fmt.Printf("%s Opened %s\n", color.New(color.FgGreen).Sprint("v"), thread)
+			return nil
+		}
+		if feedURL == "" {
+			return fmt.Errorf("no comments feed for entry %s; use --open to view the thread at %s", shortID(entry.ID), thread)
+		}
+
+		feed, err := store.GetFeed(ctx, entry.FeedID)
+		if err != nil {
+			return fmt.Errorf("failed to get feed: %w", err)
+		}
+		comments, err := discussion.Fetch(ctx, feedURL, limit, feed.LocalNetwork)
+		if err != nil {
+			return fmt.Errorf("failed to fetch comments: %w", err)
+		}
+
+		bold := color.New(color.Bold).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+		fmt.Println(bold(entry.GetTitle()))
+		if thread != "" {
+			fmt.Println(faint(thread))
+		}
+		if len(comments) == 0 {
+			fmt.Println("\nNo comments yet")
+			return nil
+		}
+		for _, c := range comments {
+			fmt.Println()
+			byline := c.Author
+			if byline == "" {
+				byline = "anonymous"
+			}
+			if c.Published != nil {
+				byline += " · " + c.Published.Format("Jan 2 15:04")
+			}
+			fmt.Println(faint(byline))
+			for _, line := range strings.Split(content.ToText(c.Content), "\n") {
+				fmt.Println("  " + line)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(discussCmd)
+	discussCmd.Flags().Bool("open", false, "open the thread page in the browser")
+	discussCmd.Flags().Int("limit", 20, "most comments to show (0 for all)")
+	discussCmd.ValidArgsFunction = entryIDArgs(false)
+}
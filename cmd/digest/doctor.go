@@ -0,0 +1,331 @@
+// ABOUTME: Doctor command that checks the active profile's data for integrity problems
+// ABOUTME: Runs backend checks plus OPML/store divergence and stale lock detection, with --fix for safe repairs
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/runlock"
+	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check data integrity and repair safe problems",
+	Long: `Check the active profile's data for problems:
+
+  schema       SQLite schema version and migrated columns
+  integrity    SQLite quick_check
+  fts          Full-text search index matches entries
+  orphans      Entries (or markdown directories) with no feed
+  feeds        Markdown feed registry parses cleanly
+  frontmatter  Markdown entry files parse and sit under the right feed
+  opml         Feeds in OPML and storage agree
+  lock         No stale sync lock left by a crashed run
+  inactive     No feeds quiet or failing for inactive_days
+  dates        No entries dated in the future or before the web
+
+With --fix, problems that can be repaired without losing data are repaired:
+migrations are re-run, the search index is rebuilt, orphaned SQLite entries
+are deleted, unreadable markdown entries are renamed to *.invalid, feeds
+missing from OPML or storage are added to the other, stale locks are
+cleared, inactive feeds are archived, and implausible publish dates are
+replaced with the time the entry was fetched. Exits non-zero if any problem remains.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		fix, _ := cmd.Flags().GetBool("fix")
+
+		results := []storage.CheckResult{{
+			Name:   "config",
+			OK:     true,
+			Detail: fmt.Sprintf("%s backend, profile %q", cfg.GetBackend(), profileName),
+		}}
+
+		if d, ok := store.(storage.Doctor); ok {
+			storeResults, err := d.Diagnose(ctx, fix)
+			if err != nil {
+				return fmt.Errorf("failed to check storage: %w", err)
+			}
+			results = append(results, storeResults...)
+		}
+
+		opmlResult, err := checkOPMLDivergence(ctx, fix)
+		if err != nil {
+			return err
+		}
+		results = append(results, opmlResult)
+
+		lockResult, err := checkSyncLock(fix)
+		if err != nil {
+			return err
+		}
+		results = append(results, lockResult)
+
+		inactiveResult, err := checkInactiveFeeds(ctx, fix)
+		if err != nil {
+			return err
+		}
+		results = append(results, inactiveResult)
+
+		datesResult, err := checkEntryDates(ctx, fix)
+		if err != nil {
+			return err
+		}
+		results = append(results, datesResult)
+
+		if problems := printCheckResults(results, fix); problems > 0 {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return fmt.Errorf("%d problem(s) found", problems)
+		}
+		return nil
+	},
+}
+
+// printCheckResults prints one line per check and returns the number of unresolved problems.
+func printCheckResults(results []storage.CheckResult, fix bool) int {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	problems := 0
+	for _, r := range results {
+		mark := green("v")
+		if !r.OK {
+			mark = red("x")
+			problems++
+		}
+		line := fmt.Sprintf("%s %-12s %s", mark, r.Name, r.Detail)
+		if !r.OK && r.Fixable && !fix {
+			line += " " + faint("(fixable with --fix)")
+		}
+		fmt.Println(line)
+	}
+	return problems
+}
+
+// checkOPMLDivergence compares feed URLs in the OPML file against storage.
+// The fix adds each side's missing feeds to the other; nothing is removed.
+func checkOPMLDivergence(ctx context.Context, fix bool) (storage.CheckResult, error) {
+	result := storage.CheckResult{Name: "opml"}
+
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	opmlFeeds := opmlDoc.AllFeeds()
+	inOPML := make(map[string]bool, len(opmlFeeds))
+	for _, f := range opmlFeeds {
+		inOPML[f.URL] = true
+	}
+
+	inStore := make(map[string]bool, len(feeds))
+	var missingFromOPML []*models.Feed
+	for _, feed := range feeds {
+		inStore[feed.URL] = true
+		if !inOPML[feed.URL] {
+			missingFromOPML = append(missingFromOPML, feed)
+		}
+	}
+
+	var missingFromStore []string
+	for _, f := range opmlFeeds {
+		if !inStore[f.URL] {
+			missingFromStore = append(missingFromStore, f.URL)
+		}
+	}
+
+	if len(missingFromOPML) == 0 && len(missingFromStore) == 0 {
+		result.OK = true
+		result.Detail = fmt.Sprintf("%d feeds in sync", len(feeds))
+		return result, nil
+	}
+
+	var parts []string
+	if len(missingFromOPML) > 0 {
+		parts = append(parts, fmt.Sprintf("%d feed(s) missing from OPML", len(missingFromOPML)))
+	}
+	if len(missingFromStore) > 0 {
+		parts = append(parts, fmt.Sprintf("%d feed(s) missing from storage", len(missingFromStore)))
+	}
+	result.Detail = strings.Join(parts, ", ")
+	result.Fixable = true
+	if !fix {
+		return result, nil
+	}
+
+	for _, feed := range missingFromOPML {
+		title := ""
+		if feed.Title != nil {
+			title = *feed.Title
+		}
+		if err := opmlDoc.AddFeed(feed.URL, title, feed.Folder); err != nil {
+			return result, fmt.Errorf("failed to add %s to OPML: %w", feed.URL, err)
+		}
+	}
+	if len(missingFromOPML) > 0 {
+		if err := saveOPML(); err != nil {
+			return result, err
+		}
+	}
+
+	for _, f := range opmlFeeds {
+		if inStore[f.URL] {
+			continue
+		}
+		feed := models.NewFeed(f.URL)
+		if f.Title != "" {
+			title := f.Title
+			feed.Title = &title
+		}
+		feed.Folder = f.Folder
+		if err := store.CreateFeed(ctx, feed); err != nil {
+			return result, fmt.Errorf("failed to add %s to storage: %w", f.URL, err)
+		}
+	}
+
+	result.OK, result.Fixed = true, true
+	result.Detail = "added " + result.Detail
+	return result, nil
+}
+
+// checkSyncLock reports a sync lock left behind by a run that didn't release it.
+func checkSyncLock(fix bool) (storage.CheckResult, error) {
+	result := storage.CheckResult{Name: "lock"}
+
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return result, fmt.Errorf("invalid profile: %w", err)
+	}
+	path := runlock.Path(profileDir)
+
+	holder := runlock.ReadHolder(path)
+	if holder == nil {
+		result.OK = true
+		result.Detail = "no sync running"
+		return result, nil
+	}
+
+	if runlock.Held(path) {
+		result.OK = true
+		result.Detail = fmt.Sprintf("sync running (pid %d)", holder.PID)
+		return result, nil
+	}
+
+	result.Fixable = true
+	result.Detail = fmt.Sprintf("stale lock left by pid %d", holder.PID)
+	if !fix {
+		return result, nil
+	}
+
+	// Taking and releasing the lock clears the stale record
+	lock, err := runlock.Acquire(path, 0)
+	if err != nil {
+		return result, err
+	}
+	if err := lock.Release(); err != nil {
+		return result, fmt.Errorf("failed to clear stale lock: %w", err)
+	}
+	result.OK, result.Fixed = true, true
+	result.Detail = fmt.Sprintf("cleared stale lock left by pid %d", holder.PID)
+	return result, nil
+}
+
+// checkInactiveFeeds reports feeds that have gone quiet or keep failing.
+// The fix archives them, as the next full fetch would.
+func checkInactiveFeeds(ctx context.Context, fix bool) (storage.CheckResult, error) {
+	result := storage.CheckResult{Name: "inactive"}
+
+	after := cfg.GetInactiveAfter()
+	if after <= 0 {
+		result.OK = true
+		result.Detail = "detection off (inactive_days is negative)"
+		return result, nil
+	}
+	now := time.Now()
+	inactive, err := feedsync.FindInactive(ctx, store, after, now)
+	if err != nil {
+		return result, err
+	}
+	if len(inactive) == 0 {
+		result.OK = true
+		result.Detail = "no inactive feeds"
+		return result, nil
+	}
+
+	names := make([]string, 0, len(inactive))
+	for _, f := range inactive {
+		names = append(names, fmt.Sprintf("%s (%s)", f.Feed.GetDisplayName(), f.Reason))
+	}
+	result.Detail = fmt.Sprintf("%d inactive feed(s): %s", len(inactive), strings.Join(names, ", "))
+	result.Fixable = true
+	if !fix {
+		return result, nil
+	}
+
+	for _, f := range inactive {
+		if err := feedsync.ArchiveFeed(ctx, store, f.Feed, now); err != nil {
+			return result, err
+		}
+	}
+	result.OK, result.Fixed = true, true
+	result.Detail = fmt.Sprintf("archived %d inactive feed(s)", len(inactive))
+	return result, nil
+}
+
+// checkEntryDates reports entries stored with implausible publish dates,
+// such as those fetched before dates were checked. The fix gives them the
+// time they were fetched, as new entries get.
+func checkEntryDates(ctx context.Context, fix bool) (storage.CheckResult, error) {
+	result := storage.CheckResult{Name: "dates"}
+
+	policy, err := cfg.GetDatePolicy()
+	if err != nil {
+		return result, err
+	}
+	entries, err := store.ListEntries(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to list entries: %w", err)
+	}
+	var bogus []*models.Entry
+	for _, entry := range entries {
+		if entry.PublishedAt != nil && policy.Implausible(*entry.PublishedAt, entry.CreatedAt) {
+			bogus = append(bogus, entry)
+		}
+	}
+	if len(bogus) == 0 {
+		result.OK = true
+		result.Detail = fmt.Sprintf("%d entries with plausible dates", len(entries))
+		return result, nil
+	}
+
+	result.Detail = fmt.Sprintf("%d entries dated in the future or before 1990", len(bogus))
+	result.Fixable = true
+	if !fix {
+		return result, nil
+	}
+	for _, entry := range bogus {
+		policy.Apply(entry)
+		if err := store.UpdateEntry(ctx, entry); err != nil {
+			return result, fmt.Errorf("failed to update entry: %w", err)
+		}
+	}
+	result.OK, result.Fixed = true, true
+	result.Detail = fmt.Sprintf("redated %d entries to when they were fetched", len(bogus))
+	return result, nil
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("fix", false, "repair problems that can be fixed without losing data")
+}
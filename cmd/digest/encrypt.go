@@ -0,0 +1,257 @@
+// ABOUTME: Commands that convert a profile's SQLite database to and from encrypted storage
+// ABOUTME: Also installs the terminal passphrase prompt used when opening an encrypted profile
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/runlock"
+	"github.com/harper/digest/internal/storage"
+)
+
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the profile's database with a passphrase",
+	Long: `Replace the profile's SQLite database with an encrypted copy.
+
+The whole database is sealed with AES-256-GCM under a key derived from your
+passphrase. While digest runs it is decrypted into memory only, and changes
+are written back encrypted every 30 seconds and on exit. Only one digest
+process can have an encrypted profile open at a time.
+
+digest asks for the passphrase when it opens the profile. Set
+DIGEST_PASSPHRASE instead for cron jobs and 'digest mcp', or use --remember
+to keep it in the secrets store (see 'digest secrets'), which also works on
+a profile that is already encrypted.
+
+There is no way to recover a forgotten passphrase.`,
+	Args: cobra.NoArgs,
+	RunE: runEncrypt,
+}
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Turn an encrypted database back into a plain SQLite file",
+	Args:  cobra.NoArgs,
+	RunE:  runDecrypt,
+}
+
+func init() {
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
+
+	encryptCmd.Flags().Bool("remember", false, "save the passphrase in the secrets store")
+
+	config.PassphrasePrompt = promptPassphrase
+}
+
+// encryptionPaths loads config and returns the active profile's directory,
+// refusing backends and states that can't be converted.
+func encryptionPaths(cmd *cobra.Command) (profileDir string, err error) {
+	cfg, err = config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cmd.Flags().Changed("profile") {
+		profileName = cfg.GetDefaultProfile()
+	}
+	if err := cfg.ApplyProfile(profileName); err != nil {
+		return "", err
+	}
+	if cfg.GetBackend() != "sqlite" {
+		return "", fmt.Errorf("encryption is only available for the sqlite backend")
+	}
+	if err := cfg.MigrateToProfileLayout(); err != nil {
+		return "", fmt.Errorf("failed to migrate to profile layout: %w", err)
+	}
+	profileDir, err = cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	if runlock.Held(runlock.Path(profileDir)) {
+		return "", fmt.Errorf("a sync is running for this profile; try again when it finishes")
+	}
+	return profileDir, nil
+}
+
+func runEncrypt(cmd *cobra.Command, args []string) error {
+	profileDir, err := encryptionPaths(cmd)
+	if err != nil {
+		return err
+	}
+	remember, _ := cmd.Flags().GetBool("remember")
+	plainPath := filepath.Join(profileDir, "digest.db")
+	encPath := filepath.Join(profileDir, storage.EncryptedDBFilename)
+	if _, err := os.Stat(encPath); err == nil {
+		if remember {
+			return rememberPassphrase(encPath)
+		}
+		return fmt.Errorf("profile %q is already encrypted", profileName)
+	}
+	if _, err := os.Stat(plainPath); err != nil {
+		return fmt.Errorf("no database to encrypt at %s", plainPath)
+	}
+
+	passphrase, err := newPassphrase()
+	if err != nil {
+		return err
+	}
+	if err := storage.EncryptSQLiteFile(cmd.Context(), plainPath, encPath, passphrase); err != nil {
+		return fmt.Errorf("failed to encrypt database: %w", err)
+	}
+
+	// Make sure the copy opens before the original goes
+	enc, err := storage.NewEncryptedSQLiteStore(encPath, passphrase)
+	if err != nil {
+		os.Remove(encPath)
+		return fmt.Errorf("encrypted copy failed to open, original kept: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to close encrypted database: %w", err)
+	}
+
+	for _, name := range []string{"digest.db", "digest.db-wal", "digest.db-shm"} {
+		if err := os.Remove(filepath.Join(profileDir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove plaintext %s: %w", name, err)
+		}
+	}
+
+	if remember {
+		if err := savePassphrase(encPath, passphrase); err != nil {
+			return err
+		}
+	}
+
+	color.Green("Encrypted %s", encPath)
+	faint := color.New(color.Faint).SprintFunc()
+	fmt.Println(faint("Plaintext database removed. Backups and snapshots made earlier may still hold it."))
+	return nil
+}
+
+func runDecrypt(cmd *cobra.Command, args []string) error {
+	profileDir, err := encryptionPaths(cmd)
+	if err != nil {
+		return err
+	}
+	plainPath := filepath.Join(profileDir, "digest.db")
+	encPath := filepath.Join(profileDir, storage.EncryptedDBFilename)
+	if _, err := os.Stat(encPath); err != nil {
+		return fmt.Errorf("profile %q is not encrypted", profileName)
+	}
+	if _, err := os.Stat(plainPath); err == nil {
+		return fmt.Errorf("%s already exists; move it aside first", plainPath)
+	}
+
+	passphrase, err := cfg.Passphrase(encPath)
+	if err != nil {
+		return err
+	}
+	if err := storage.DecryptSQLiteFile(encPath, plainPath, passphrase); err != nil {
+		return fmt.Errorf("failed to decrypt database: %w", err)
+	}
+
+	plain, err := storage.NewSQLiteStore(plainPath)
+	if err != nil {
+		os.Remove(plainPath)
+		return fmt.Errorf("decrypted copy failed to open, encrypted database kept: %w", err)
+	}
+	if err := plain.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+	if err := os.Remove(encPath); err != nil {
+		return fmt.Errorf("failed to remove encrypted database: %w", err)
+	}
+	_ = os.Remove(encPath + ".lock")
+	if provider, err := cfg.Secrets(); err == nil {
+		_ = provider.Delete(config.PassphraseSecret(encPath))
+	}
+
+	color.Green("Decrypted to %s", plainPath)
+	return nil
+}
+
+// rememberPassphrase checks the passphrase of an already encrypted database
+// and saves it.
+func rememberPassphrase(encPath string) error {
+	passphrase, err := cfg.Passphrase(encPath)
+	if err != nil {
+		return err
+	}
+	enc, err := storage.NewEncryptedSQLiteStore(encPath, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to close encrypted database: %w", err)
+	}
+	if err := savePassphrase(encPath, passphrase); err != nil {
+		return err
+	}
+	color.Green("Saved the passphrase for %s", encPath)
+	return nil
+}
+
+func savePassphrase(encPath, passphrase string) error {
+	provider, err := cfg.Secrets()
+	if err != nil {
+		return err
+	}
+	if err := provider.Set(config.PassphraseSecret(encPath), passphrase); err != nil {
+		return fmt.Errorf("failed to save passphrase: %w", err)
+	}
+	faint := color.New(color.Faint).SprintFunc()
+	fmt.Println(faint("Passphrase saved to the secrets store"))
+	return nil
+}
+
+// newPassphrase reads a new passphrase from DIGEST_PASSPHRASE, or asks for
+// it twice on the terminal.
+func newPassphrase() (string, error) {
+	if p := os.Getenv(config.PassphraseEnv); p != "" {
+		return p, nil
+	}
+	first, err := readPassword("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if first == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	second, err := readPassword("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if first != second {
+		return "", fmt.Errorf("passphrases don't match")
+	}
+	return first, nil
+}
+
+// promptPassphrase asks for an encrypted database's passphrase on the
+// terminal.
+func promptPassphrase(path string) (string, error) {
+	return readPassword(fmt.Sprintf("Passphrase for %s: ", path))
+}
+
+// readPassword prompts on stderr and reads a line from the terminal without
+// echoing it.
+func readPassword(prompt string) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("no terminal to ask for a passphrase; set %s", config.PassphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(p), nil
+}
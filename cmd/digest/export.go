@@ -1,15 +1,27 @@
 // ABOUTME: Export command for exporting data in various formats
-// ABOUTME: Supports OPML, YAML, and Markdown export formats
+// ABOUTME: Supports OPML, YAML, Markdown, iCalendar (event feeds), and Anki flashcard export formats
 
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
+
+	"github.com/harper/digest/internal/cards"
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/events"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/render"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/summary"
 )
 
 var exportCmd = &cobra.Command{
@@ -21,23 +33,84 @@ Formats:
   opml     - OPML feed list (default)
   yaml     - Full data export in YAML
   markdown - Human-readable Markdown
+  ics      - iCalendar of upcoming events announced by event feeds
+  anki     - Anki flashcards of entries worth remembering
+
+--template renders entries with a Go template instead: a file path, or a
+name looked up as <name>.tmpl in the templates directory next to
+config.json, falling back to a built-in. Markdown export uses the
+"markdown" template, so templates/markdown.tmpl changes its format.
+--print-template writes a built-in to start from. The "brief" built-in lists
+one line per story, with near-identical titles from several feeds
+collapsed into one line naming them all. --since limits markdown and
+templates to recent entries.
+
+The ics format reads event dates out of entries from the feeds chosen with
+--feed or --category (e.g. a folder of meetup and conference feeds) and
+lists the ones that haven't happened yet.
+
+The anki format makes a card for each entry kept unread with
+'digest keep-unread' and each entry an agent has summarized: the title on
+the front, the summary (or the opening of the article) and link on the back,
+tagged with the feed's folder. Import the file in Anki with File > Import; cards keep their IDs, so
+importing a later export updates them instead of adding duplicates. --deck
+names the deck to import into.
 
 Examples:
   digest export              # OPML to stdout
   digest export --format yaml > backup.yaml
-  digest export --format markdown > reading-list.md`,
+  digest export --format markdown > reading-list.md
+  digest export --format ics --category Events > events.ics
+  digest export --format anki --deck Reading > digest-cards.txt
+  digest export --print-template markdown > ~/.config/digest/templates/weekly.tmpl
+  digest export --template weekly --since week
+  digest export --template brief --since today`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		format, _ := cmd.Flags().GetString("format")
+		feedFilter, _ := cmd.Flags().GetString("feed")
+		category, _ := cmd.Flags().GetString("category")
+		templateName, _ := cmd.Flags().GetString("template")
+		printTemplate, _ := cmd.Flags().GetString("print-template")
+		since, _ := cmd.Flags().GetString("since")
+		deck, _ := cmd.Flags().GetString("deck")
+
+		if format != "ics" && (feedFilter != "" || category != "") {
+			return fmt.Errorf("--feed and --category only apply to --format ics")
+		}
+		if printTemplate != "" {
+			text, ok := render.Builtin(printTemplate)
+			if !ok {
+				return fmt.Errorf("no built-in template %q", printTemplate)
+			}
+			fmt.Print(text)
+			return nil
+		}
+		if templateName != "" {
+			if cmd.Flags().Changed("format") {
+				return fmt.Errorf("--template replaces --format")
+			}
+			return exportTemplate(cmd.Context(), templateName, since)
+		}
+		if since != "" && format != "markdown" && format != "md" && format != "anki" {
+			return fmt.Errorf("--since only applies to markdown, anki, and templates")
+		}
+		if cmd.Flags().Changed("deck") && format != "anki" {
+			return fmt.Errorf("--deck only applies to --format anki")
+		}
 
 		switch format {
 		case "opml", "":
 			return opmlDoc.Write(os.Stdout)
 		case "yaml":
-			return exportYAML()
+			return exportYAML(cmd.Context())
 		case "markdown", "md":
-			return exportMarkdown()
+			return exportTemplate(cmd.Context(), "markdown", since)
+		case "ics":
+			return exportICS(cmd.Context(), feedFilter, category)
+		case "anki":
+			return exportAnki(cmd.Context(), deck, since)
 		default:
-			return fmt.Errorf("unknown format: %s (use opml, yaml, or markdown)", format)
+			return fmt.Errorf("unknown format: %s (use opml, yaml, markdown, ics, or anki)", format)
 		}
 	},
 }
@@ -76,23 +149,17 @@ type YAMLEntry struct {
 	Content     string `yaml:"content,omitempty"`
 }
 
-func exportYAML() error {
-	feeds, err := store.ListFeeds()
+func exportYAML(ctx context.Context) error {
+	feeds, err := store.ListFeeds(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list feeds: %w", err)
 	}
 
-	entries, err := store.ListEntries(nil)
-	if err != nil {
-		return fmt.Errorf("failed to list entries: %w", err)
-	}
-
 	export := YAMLExport{
 		Version:    "1.0",
 		ExportedAt: time.Now().Format(time.RFC3339),
 		Tool:       "digest",
 		Feeds:      make([]YAMLFeed, 0, len(feeds)),
-		Entries:    make([]YAMLEntry, 0, len(entries)),
 	}
 
 	for _, feed := range feeds {
@@ -114,7 +181,20 @@ func exportYAML() error {
 		export.Feeds = append(export.Feeds, yf)
 	}
 
-	for _, entry := range entries {
+	// Entries are streamed after the rest, one at a time, so an export
+	// never holds the whole archive; the document reads the same as one
+	// encoded in a piece.
+	if err := encodeYAML(os.Stdout, export, ""); err != nil {
+		return err
+	}
+	started := false
+	return store.EachEntry(ctx, nil, func(entry *models.Entry) error {
+		if !started {
+			started = true
+			if _, err := io.WriteString(os.Stdout, "entries:\n"); err != nil {
+				return err
+			}
+		}
 		ye := YAMLEntry{
 			ID:     entry.ID,
 			FeedID: entry.FeedID,
@@ -139,109 +219,190 @@ func exportYAML() error {
 		if entry.Content != nil {
 			ye.Content = *entry.Content
 		}
-		export.Entries = append(export.Entries, ye)
-	}
+		return encodeYAML(os.Stdout, []YAMLEntry{ye}, "  ")
+	})
+}
 
-	encoder := yaml.NewEncoder(os.Stdout)
+// encodeYAML writes v as YAML with each non-blank line indented by indent,
+// so it can be nested under a key already written.
+func encodeYAML(w io.Writer, v interface{}, indent string) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
 	encoder.SetIndent(2)
-	return encoder.Encode(export)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	for _, line := range bytes.SplitAfter(buf.Bytes(), []byte("\n")) {
+		if len(line) > 1 {
+			if _, err := io.WriteString(w, indent); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func exportMarkdown() error {
-	feeds, err := store.ListFeeds()
+// exportTemplate renders entries, optionally only those since a date, with
+// the named template: a user template from the templates directory, a file
+// path, or a built-in.
+func exportTemplate(ctx context.Context, name, since string) error {
+	tmpl, err := render.Load(config.TemplatesDir(), name)
 	if err != nil {
-		return fmt.Errorf("failed to list feeds: %w", err)
+		return err
 	}
 
-	entries, err := store.ListEntries(nil)
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list feeds: %w", err)
+	}
+	filter := &storage.EntryFilter{}
+	if err := applySince(filter, since); err != nil {
+		return err
+	}
+	entries, err := store.ListEntries(ctx, filter)
 	if err != nil {
 		return fmt.Errorf("failed to list entries: %w", err)
 	}
+	folders := make(map[string]string)
+	for _, f := range opmlDoc.AllFeeds() {
+		folders[f.URL] = f.Folder
+	}
 
-	// Group entries by feed
-	entriesByFeed := make(map[string][]*struct {
-		Title       string
-		Link        string
-		Author      string
-		PublishedAt string
-		Read        bool
-	})
+	digest := render.NewDigest("digest", time.Now(), feeds, entries, folders)
+	return render.Execute(os.Stdout, tmpl, digest)
+}
 
-	for _, entry := range entries {
-		title := "Untitled"
-		if entry.Title != nil {
-			title = *entry.Title
-		}
-		link := ""
-		if entry.Link != nil {
-			link = *entry.Link
+// eventFeedIDs resolves the feeds designated as event sources by URL or
+// prefix, or by OPML folder. Exactly one of feedFilter and category is set.
+func eventFeedIDs(ctx context.Context, s storage.Store, doc *opml.Document, feedFilter, category string) ([]string, error) {
+	switch {
+	case feedFilter != "" && category != "":
+		return nil, fmt.Errorf("cannot use --feed and --category together")
+	case feedFilter != "":
+		feed, err := s.GetFeedByURL(ctx, feedFilter)
+		if err != nil {
+			feed, err = s.GetFeedByPrefix(ctx, feedFilter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find feed: %w", err)
+			}
 		}
-		author := ""
-		if entry.Author != nil {
-			author = *entry.Author
+		return []string{feed.ID}, nil
+	case category != "":
+		var ids []string
+		for _, opmlFeed := range doc.FeedsInFolder(category) {
+			if feed, err := s.GetFeedByURL(ctx, opmlFeed.URL); err == nil {
+				ids = append(ids, feed.ID)
+			}
 		}
-		publishedAt := ""
-		if entry.PublishedAt != nil {
-			publishedAt = entry.PublishedAt.Format("January 2, 2006")
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no synced feeds found in category %q", category)
 		}
-
-		entriesByFeed[entry.FeedID] = append(entriesByFeed[entry.FeedID], &struct {
-			Title       string
-			Link        string
-			Author      string
-			PublishedAt string
-			Read        bool
-		}{
-			Title:       title,
-			Link:        link,
-			Author:      author,
-			PublishedAt: publishedAt,
-			Read:        entry.Read,
-		})
+		return ids, nil
+	default:
+		return nil, fmt.Errorf("choose the event feeds with --feed or --category")
 	}
+}
 
-	// Write header
-	fmt.Printf("# Feed Entries Export - %s\n\n", time.Now().Format("January 2, 2006"))
-	fmt.Printf("Generated: %s\n\n", time.Now().Format(time.RFC3339))
-
-	// Write by feed
+// upcomingEvents extracts the not-yet-past events from the given feeds.
+func upcomingEvents(ctx context.Context, s storage.Store, feedIDs []string, now time.Time) ([]events.Event, error) {
+	entries, err := s.ListEntries(ctx, &storage.EntryFilter{FeedIDs: feedIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+	feeds, err := s.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	feedNames := make(map[string]string, len(feeds))
 	for _, feed := range feeds {
-		feedTitle := feed.URL
-		if feed.Title != nil {
-			feedTitle = *feed.Title
-		}
-
-		feedEntries := entriesByFeed[feed.ID]
-		if len(feedEntries) == 0 {
-			continue
-		}
+		feedNames[feed.ID] = feed.GetDisplayName()
+	}
+	return events.Upcoming(entries, feedNames, now), nil
+}
 
-		fmt.Printf("## %s\n\n", feedTitle)
+func exportICS(ctx context.Context, feedFilter, category string) error {
+	feedIDs, err := eventFeedIDs(ctx, store, opmlDoc, feedFilter, category)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	upcoming, err := upcomingEvents(ctx, store, feedIDs, now)
+	if err != nil {
+		return err
+	}
+	name := "digest events"
+	if category != "" {
+		name += ": " + category
+	}
+	return events.WriteICS(os.Stdout, name, upcoming, now)
+}
 
-		for _, entry := range feedEntries {
-			readStatus := ""
-			if entry.Read {
-				readStatus = " [read]"
-			}
-			fmt.Printf("### %s%s\n\n", entry.Title, readStatus)
+// exportAnki writes flashcards for the entries kept unread or summarized,
+// optionally only those since a date.
+func exportAnki(ctx context.Context, deck, since string) error {
+	filter := &storage.EntryFilter{}
+	if err := applySince(filter, since); err != nil {
+		return err
+	}
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list feeds: %w", err)
+	}
+	dir, err := summaryDir()
+	if err != nil {
+		return err
+	}
+	feedByID := make(map[string]*models.Feed, len(feeds))
+	for _, f := range feeds {
+		feedByID[f.ID] = f
+	}
+	folders := make(map[string]string)
+	for _, f := range opmlDoc.AllFeeds() {
+		folders[f.URL] = f.Folder
+	}
 
-			if entry.Author != "" {
-				fmt.Printf("- **Author:** %s\n", entry.Author)
-			}
-			if entry.PublishedAt != "" {
-				fmt.Printf("- **Published:** %s\n", entry.PublishedAt)
-			}
-			if entry.Link != "" {
-				fmt.Printf("- **Link:** %s\n", entry.Link)
-			}
-			fmt.Println()
+	// Only summarized and kept entries become cards, so the rest are
+	// passed over without being held
+	var deckCards []cards.Card
+	err = store.EachEntry(ctx, filter, func(e *models.Entry) error {
+		src := cards.Source{Entry: e}
+		if s, ok := summary.Latest(dir, e.ID); ok {
+			src.Summary = s.Text
+		} else if !e.KeepUnread {
+			return nil
 		}
+		if f := feedByID[e.FeedID]; f != nil {
+			src.Feed = f.GetDisplayName()
+			src.Folder = folders[f.URL]
+		}
+		deckCards = append(deckCards, cards.FromEntry(src))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
 	}
-
-	return nil
+	if len(deckCards) == 0 {
+		return fmt.Errorf("no entries to make cards from; keep entries with 'digest keep-unread' or summarize them first")
+	}
+	return cards.WriteAnki(os.Stdout, deck, deckCards)
 }
 
 func init() {
 	rootCmd.AddCommand(exportCmd)
-	exportCmd.Flags().StringP("format", "f", "opml", "output format: opml, yaml, or markdown")
+	exportCmd.Flags().StringP("format", "f", "opml", "output format: opml, yaml, markdown, ics, or anki")
+	exportCmd.Flags().String("feed", "", "event feed URL or prefix (ics only)")
+	exportCmd.Flags().StringP("category", "c", "", "folder of event feeds (ics only)")
+	exportCmd.Flags().String("template", "", "render entries with this template name or file")
+	exportCmd.Flags().String("print-template", "", "print a built-in template to start your own from")
+	exportCmd.Flags().String("since", "", "only export entries since a date (markdown, anki, and templates)")
+	exportCmd.Flags().String("deck", "digest", "Anki deck to import the cards into (anki only)")
+	_ = exportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"opml", "yaml", "markdown", "ics", "anki"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = exportCmd.RegisterFlagCompletionFunc("feed", feedURLFlag)
+	_ = exportCmd.RegisterFlagCompletionFunc("category", folderFlag)
 }
@@ -4,17 +4,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/harper/digest/internal/config"
 	"github.com/harper/digest/internal/discover"
+	"github.com/harper/digest/internal/favicon"
+	"github.com/harper/digest/internal/feedurl"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/hooks"
+	"github.com/harper/digest/internal/models"
 	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/tui"
 )
 
 var feedCmd = &cobra.Command{
 	Use:     "feed",
-	Aliases: []string{"f"},
+	Aliases: []string{"f", "feeds"},
 	Short:   "Manage RSS/Atom feeds",
 	Long:    "Add, list, and remove RSS/Atom feeds from your subscriptions",
 }
@@ -22,84 +34,34 @@ var feedCmd = &cobra.Command{
 var feedAddCmd = &cobra.Command{
 	Use:   "add <url>",
 	Short: "Add a new RSS/Atom feed",
-	Long:  "Add a new feed to your subscriptions. Automatically discovers feed URLs from HTML pages.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		inputURL := args[0]
-		folder, _ := cmd.Flags().GetString("folder")
-		title, _ := cmd.Flags().GetString("title")
-		noDiscover, _ := cmd.Flags().GetBool("no-discover")
-		localNetwork, _ := cmd.Flags().GetBool("local")
-
-		var feedURL, feedTitle string
-
-		if noDiscover {
-			// Skip discovery, use URL as-is
-			feedURL = inputURL
-			feedTitle = title
-		} else {
-			// Discover feed from URL
-			fmt.Printf("Discovering feed at %s...\n", inputURL)
-			discovered, err := discover.Discover(inputURL, localNetwork)
-			if err != nil {
-				return fmt.Errorf("could not find feed at %s: %w", inputURL, err)
-			}
-
-			feedURL = discovered.URL
-			if title != "" {
-				feedTitle = title
-			} else {
-				feedTitle = discovered.Title
-			}
-
-			// Inform user if URL changed
-			if feedURL != inputURL {
-				fmt.Printf("Found feed: %s\n", feedURL)
-			}
-		}
-
-		// Check if feed already exists
-		existingFeed, err := store.GetFeedByURL(feedURL)
-		if err == nil && existingFeed != nil {
-			return fmt.Errorf("feed already exists: %s", feedURL)
-		}
-
-		// Create new feed
-		feed := storage.NewFeed(feedURL)
-		feed.Folder = folder
-		feed.LocalNetwork = localNetwork
-		if feedTitle != "" {
-			feed.Title = &feedTitle
-		}
-
-		// Save to storage
-		if err := store.CreateFeed(feed); err != nil {
-			return fmt.Errorf("failed to create feed: %w", err)
-		}
-
-		// Add to OPML for import/export compatibility
-		opmlTitle := feedTitle
-		if opmlTitle == "" {
-			opmlTitle = feedURL
-		}
-		if err := opmlDoc.AddFeed(feedURL, opmlTitle, folder); err != nil {
-			// Non-fatal: OPML is for import/export, SQLite is source of truth
-			fmt.Printf("Note: Could not add to OPML: %v\n", err)
-		} else {
-			if err := saveOPML(); err != nil {
-				fmt.Printf("Note: Could not save OPML: %v\n", err)
-			}
-		}
-
-		if folder != "" {
-			fmt.Printf("Added feed to folder '%s': %s\n", folder, feedTitle)
-		} else {
-			fmt.Printf("Added feed: %s\n", feedTitle)
-		}
-		fmt.Printf("Feed ID: %s\n", feed.ID)
-
-		return nil
-	},
+	Long: `Add a new feed to your subscriptions. Automatically discovers feed URLs from HTML pages.
+When a site offers several feeds, its posts are picked over its comments
+and Atom over RSS. Discovery gives up after 20 seconds.
+
+Sites behind bot protection (Cloudflare's "Just a moment..." page and the
+like) are retried with browser-like headers, and feeds that only answer
+those are synced with them too. --browser sends them from the start.
+
+The feed URL is cleaned up first: tracking parameters such as utm_source
+are dropped, and an http:// URL is switched to https:// when the feed is
+also served there. If you already follow the same feed under a slightly
+different URL (http vs https, www, a trailing slash), digest offers to
+merge the two: the existing feed moves to the new URL and keeps its
+entries and read state.
+
+Everything a feed publishes arrives unread on its first sync. To skip a
+long history, --backfill-days marks entries older than that many days
+read, and --backfill-limit imports only the newest entries. Both default
+to "backfill" in config.json.
+
+With --monitor the URL is watched as a web page instead: each sync
+compares the page's text with the last check and adds an entry showing
+the diff when it changed. "monitor_min_words" in config.json sets how many
+words must change before that happens, to quiet pages with small churn.
+
+With --from-clipboard the URL is read from the system clipboard instead.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runFeedAdd,
 }
 
 var feedListCmd = &cobra.Command{
@@ -108,7 +70,8 @@ var feedListCmd = &cobra.Command{
 	Short:   "List all feeds",
 	Long:    "List all subscribed feeds with their folders",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		feeds, err := store.ListFeeds()
+		ctx := cmd.Context()
+		feeds, err := store.ListFeeds(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to list feeds: %w", err)
 		}
@@ -118,21 +81,28 @@ var feedListCmd = &cobra.Command{
 			return nil
 		}
 
-		fmt.Printf("Found %d feed(s):\n\n", len(feeds))
+		fmt.Printf("Found %d feed(s):\n", len(feeds))
+		rows := make([][]string, 0, len(feeds))
 		for _, feed := range feeds {
 			title := feed.URL
 			if feed.Title != nil {
 				title = *feed.Title
 			}
-
-			if feed.Folder != "" {
-				fmt.Printf("[%s] %s\n", feed.Folder, title)
-			} else {
-				fmt.Printf("%s\n", title)
+			if feed.LastError != nil {
+				title += " " + tui.ErrorStyle.Render("!")
 			}
-			fmt.Printf("  URL: %s\n", feed.URL)
-			fmt.Printf("  ID: %s\n\n", feed.ID)
+			if feed.Monitor {
+				title += " " + tui.DimStyle.Render("(monitor)")
+			}
+			if feed.IsArchived() {
+				title += " " + tui.DimStyle.Render("(archived)")
+			}
+			if feed.Priority != models.PriorityNormal {
+				title += " " + tui.DimStyle.Render("("+feed.Priority+")")
+			}
+			rows = append(rows, []string{tui.DimStyle.Render(shortID(feed.ID)), feed.Folder, title, tui.DimStyle.Render(feed.URL)})
 		}
+		fmt.Println(tui.Table([]string{"ID", "Folder", "Title", "URL"}, rows))
 
 		return nil
 	},
@@ -144,18 +114,27 @@ var feedRemoveCmd = &cobra.Command{
 	Long:  "Remove a feed from your subscriptions",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		url := args[0]
 
 		// Get feed from storage
-		feed, err := store.GetFeedByURL(url)
+		feed, err := store.GetFeedByURL(ctx, url)
 		if err != nil {
 			return fmt.Errorf("feed not found: %s", url)
 		}
 
 		// Delete from storage (cascade deletes entries)
-		if err := store.DeleteFeed(feed.ID); err != nil {
+		if err := store.DeleteFeed(ctx, feed.ID); err != nil {
 			return fmt.Errorf("failed to delete feed: %w", err)
 		}
+		if provider, err := cfg.FeedSecrets(feed); err == nil && provider != nil {
+			if err := config.ClearFeedPassword(provider, feed); err != nil {
+				fmt.Printf("Note: Could not delete stored password: %v\n", err)
+			}
+		}
+		if dir, err := iconDir(); err == nil {
+			favicon.Remove(dir, feed.ID)
+		}
 
 		// Remove from OPML
 		if err := opmlDoc.RemoveFeed(url); err != nil {
@@ -178,18 +157,19 @@ var feedMoveCmd = &cobra.Command{
 	Long:  "Move a feed to a different category/folder. Use empty quotes \"\" for root level.",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		url := args[0]
 		newFolder := args[1]
 
 		// Get feed from storage
-		feed, err := store.GetFeedByURL(url)
+		feed, err := store.GetFeedByURL(ctx, url)
 		if err != nil {
 			return fmt.Errorf("feed not found: %s", url)
 		}
 
 		// Update folder
 		feed.Folder = newFolder
-		if err := store.UpdateFeed(feed); err != nil {
+		if err := store.UpdateFeed(ctx, feed); err != nil {
 			return fmt.Errorf("failed to update feed: %w", err)
 		}
 
@@ -211,15 +191,487 @@ var feedMoveCmd = &cobra.Command{
 	},
 }
 
+var feedAuthCmd = &cobra.Command{
+	Use:   "auth <url>",
+	Short: "Set or clear a feed's login",
+	Long: `Set the HTTP basic auth username and password sent when fetching a
+protected feed. The password is read from the terminal, or stdin when piped,
+and kept in the secrets store rather than the database (see 'digest secrets').
+
+Examples:
+  digest feed auth https://example.com/private.xml --username me
+  digest feed auth https://example.com/private.xml --clear`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		username, _ := cmd.Flags().GetString("username")
+		clear, _ := cmd.Flags().GetBool("clear")
+		if clear == (username != "") {
+			return fmt.Errorf("use either --username or --clear")
+		}
+
+		feed, err := store.GetFeedByURL(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("feed not found: %s", args[0])
+		}
+		provider, err := cfg.Secrets()
+		if err != nil {
+			return err
+		}
+
+		if clear {
+			if err := config.ClearFeedPassword(provider, feed); err != nil {
+				return err
+			}
+			feed.AuthUsername = nil
+		} else {
+			password, err := readSecret(fmt.Sprintf("Password for %s: ", username))
+			if err != nil {
+				return err
+			}
+			if err := config.SetFeedPassword(provider, feed, password); err != nil {
+				return err
+			}
+			feed.AuthUsername = &username
+		}
+		if err := store.UpdateFeed(ctx, feed); err != nil {
+			return fmt.Errorf("failed to update feed: %w", err)
+		}
+
+		if clear {
+			fmt.Printf("Cleared login for %s\n", feed.URL)
+		} else {
+			fmt.Printf("Saved login for %s\n", feed.URL)
+		}
+		return nil
+	},
+}
+
+var feedIdentityCmd = &cobra.Command{
+	Use:   "identity <url> [auto|guid|link|hash]",
+	Short: "Show or set how a feed's entries are told apart",
+	Long: `Show or set the field that tells a feed's entries apart between syncs,
+so a feed without stable GUIDs doesn't duplicate its entries:
+
+  auto  GUIDs, switching to link or hash when the feed regenerates them (default)
+  guid  GUIDs only, never switched automatically
+  link  entry links
+  hash  title and publish date
+
+Each falls back to the next when an entry lacks the field.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		feed, err := store.GetFeedByURL(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("feed not found: %s", args[0])
+		}
+		if len(args) == 1 {
+			fmt.Println(identityName(feed.Identity))
+			return nil
+		}
+
+		identity, err := models.ParseIdentity(args[1])
+		if err != nil {
+			return err
+		}
+		feed.Identity = identity
+		if err := store.UpdateFeed(ctx, feed); err != nil {
+			return fmt.Errorf("failed to update feed: %w", err)
+		}
+		fmt.Printf("Matching entries of %s by %s\n", feed.URL, identityName(identity))
+		return nil
+	},
+}
+
+var feedPriorityCmd = &cobra.Command{
+	Use:   "priority <url> [high|normal|low]",
+	Short: "Show or set a feed's priority",
+	Long: `Show or set a feed's priority:
+
+  high    listed first in unread listings and 'digest next', and the only
+          feeds whose entries are pushed to delivery targets as they arrive
+  normal  the default
+  low     listed after everything else`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		feed, err := store.GetFeedByURLOrPrefix(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("feed not found: %s", args[0])
+		}
+		if len(args) == 1 {
+			fmt.Println(models.PriorityName(feed.Priority))
+			return nil
+		}
+
+		priority, err := models.ParsePriority(args[1])
+		if err != nil {
+			return err
+		}
+		feed.Priority = priority
+		if err := store.UpdateFeed(ctx, feed); err != nil {
+			return fmt.Errorf("failed to update feed: %w", err)
+		}
+		fmt.Printf("Set %s to %s priority\n", feed.GetDisplayName(), models.PriorityName(priority))
+		return nil
+	},
+}
+
+// identityName names an identity strategy for display.
+func identityName(identity string) string {
+	if identity == models.IdentityAuto {
+		return "auto"
+	}
+	return identity
+}
+
+var feedMergeCmd = &cobra.Command{
+	Use:   "merge <src> <dst>",
+	Short: "Merge one feed into another",
+	Long: `Move every entry of <src> into <dst> and remove <src>. Use this when a
+feed changed URL and you ended up following it twice.
+
+Entries already in <dst> (same GUID or same link) are not copied, but if
+you had read them under <src> they are marked read in <dst>. Feeds can be
+given by URL or ID prefix.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		src, err := store.GetFeedByURLOrPrefix(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("source feed not found: %s", args[0])
+		}
+		dst, err := store.GetFeedByURLOrPrefix(ctx, args[1])
+		if err != nil {
+			return fmt.Errorf("destination feed not found: %s", args[1])
+		}
+
+		summary, err := storage.MergeFeeds(ctx, store, src.ID, dst.ID)
+		if err != nil {
+			return err
+		}
+		if dir, err := iconDir(); err == nil {
+			favicon.Remove(dir, src.ID)
+		}
+		if err := opmlDoc.RemoveFeed(src.URL); err != nil {
+			fmt.Printf("Note: Could not remove from OPML: %v\n", err)
+		} else if err := saveOPML(); err != nil {
+			fmt.Printf("Note: Could not save OPML: %v\n", err)
+		}
+
+		fmt.Printf("Merged %s into %s\n", src.GetDisplayName(), dst.GetDisplayName())
+		fmt.Printf("  %d entries moved, %d duplicates folded\n", summary.Moved, summary.Duplicates)
+		return nil
+	},
+}
+
+// addOptions controls how subscribe adds a feed.
+type addOptions struct {
+	folder         string
+	title          string
+	noDiscover     bool
+	localNetwork   bool
+	ignoreRobots   bool
+	browser        bool   // Request the feed with browser-like headers
+	monitor        bool   // Watch the URL as a web page for changes
+	priority       string // models.PriorityHigh, PriorityNormal, or PriorityLow
+	allowDuplicate bool
+	yes            bool // Merge into a near-duplicate feed without asking
+	backfillDays   *int // Overrides the configured backfill days when set
+	backfillLimit  *int // Overrides the configured backfill limit when set
+}
+
+// addFlags registers the flags shared by "feed add" and "add".
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("folder", "f", "", "folder to organize feed in")
+	cmd.Flags().StringP("title", "t", "", "feed title (defaults to discovered title)")
+	cmd.Flags().Bool("no-discover", false, "skip feed discovery and use URL as-is")
+	cmd.Flags().Bool("local", false, "allow fetching from local network (private IP) addresses")
+	cmd.Flags().Bool("ignore-robots", false, "probe common feed paths even if robots.txt disallows it")
+	cmd.Flags().Bool("browser", false, "request the feed with browser-like headers, for sites whose bot protection blocks feed readers")
+	cmd.Flags().Bool("monitor", false, "watch the URL as a web page, adding an entry with the diff whenever its text changes")
+	cmd.Flags().Bool("allow-duplicate", false, "add the feed even if the same feed is followed under another URL")
+	cmd.Flags().BoolP("yes", "y", false, "merge into a near-duplicate feed without asking")
+	cmd.Flags().Bool("from-clipboard", false, "read the URL from the clipboard")
+	cmd.Flags().Int("backfill-days", 0, "on the first sync, mark entries published more than N days ago read (0 = all unread)")
+	cmd.Flags().Int("backfill-limit", 0, "on the first sync, import only the N newest entries (0 = all)")
+	cmd.Flags().String("priority", "normal", "feed priority: high, normal, or low")
+	cmd.MarkFlagsMutuallyExclusive("allow-duplicate", "yes")
+	_ = cmd.RegisterFlagCompletionFunc("folder", folderFlag)
+	_ = cmd.RegisterFlagCompletionFunc("priority", cobra.FixedCompletions(models.Priorities, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func runFeedAdd(cmd *cobra.Command, args []string) error {
+	fromClipboard, _ := cmd.Flags().GetBool("from-clipboard")
+	var inputURL string
+	switch {
+	case fromClipboard && len(args) > 0:
+		return fmt.Errorf("give a URL or --from-clipboard, not both")
+	case fromClipboard:
+		u, err := clipboardURL()
+		if err != nil {
+			return err
+		}
+		inputURL = u
+	case len(args) == 1:
+		inputURL = args[0]
+	default:
+		return fmt.Errorf("a feed or site URL is required (or use --from-clipboard)")
+	}
+
+	var opts addOptions
+	opts.folder, _ = cmd.Flags().GetString("folder")
+	opts.title, _ = cmd.Flags().GetString("title")
+	opts.noDiscover, _ = cmd.Flags().GetBool("no-discover")
+	opts.localNetwork, _ = cmd.Flags().GetBool("local")
+	opts.ignoreRobots, _ = cmd.Flags().GetBool("ignore-robots")
+	opts.browser, _ = cmd.Flags().GetBool("browser")
+	opts.monitor, _ = cmd.Flags().GetBool("monitor")
+	opts.allowDuplicate, _ = cmd.Flags().GetBool("allow-duplicate")
+	opts.yes, _ = cmd.Flags().GetBool("yes")
+	priority, _ := cmd.Flags().GetString("priority")
+	priority, err := models.ParsePriority(priority)
+	if err != nil {
+		return err
+	}
+	opts.priority = priority
+	if cmd.Flags().Changed("backfill-days") {
+		days, _ := cmd.Flags().GetInt("backfill-days")
+		if days < 0 {
+			return fmt.Errorf("--backfill-days must not be negative")
+		}
+		opts.backfillDays = &days
+	}
+	if cmd.Flags().Changed("backfill-limit") {
+		limit, _ := cmd.Flags().GetInt("backfill-limit")
+		if limit < 0 {
+			return fmt.Errorf("--backfill-limit must not be negative")
+		}
+		opts.backfillLimit = &limit
+	}
+	return subscribe(cmd.Context(), inputURL, opts)
+}
+
+// subscribe discovers the feed behind inputURL and adds it to the store and
+// the OPML file.
+func subscribe(ctx context.Context, inputURL string, opts addOptions) error {
+	folder, title := opts.folder, opts.title
+	noDiscover, localNetwork, ignoreRobots := opts.noDiscover, opts.localNetwork, opts.ignoreRobots
+	browser := opts.browser
+	allowDuplicate, yes := opts.allowDuplicate, opts.yes
+	backfill, err := cfg.GetBackfill()
+	if err != nil {
+		return err
+	}
+	if opts.backfillDays != nil {
+		backfill.Days = *opts.backfillDays
+	}
+	if opts.backfillLimit != nil {
+		backfill.Limit = *opts.backfillLimit
+	}
+
+	var feedURL, feedTitle string
+
+	if noDiscover || opts.monitor {
+		// Skip discovery, use URL as-is
+		feedURL = inputURL
+		feedTitle = title
+	} else {
+		// Discover feed from URL
+		fmt.Printf("Discovering feed at %s...\n", inputURL)
+		discovered, err := discover.DiscoverContext(ctx, inputURL, discover.Options{
+			AllowLocalNetwork: localNetwork,
+			IgnoreRobots:      ignoreRobots,
+			Browser:           browser,
+		})
+		if errors.Is(err, fetch.ErrBotProtection) {
+			fmt.Println("If you know the site's feed URL, it may still work: digest feed add <feed-url> --no-discover --browser")
+		}
+		if err != nil {
+			return fmt.Errorf("could not find feed at %s: %w", inputURL, err)
+		}
+		if discovered.Browser && !browser {
+			fmt.Println("The site only answered browser-like requests; the feed will be fetched that way")
+			browser = true
+		}
+
+		feedURL = discovered.URL
+		if title != "" {
+			feedTitle = title
+		} else {
+			feedTitle = discovered.Title
+		}
+
+		// Inform user if URL changed
+		if feedURL != inputURL {
+			fmt.Printf("Found feed: %s\n", feedURL)
+		}
+	}
+
+	canonical, err := feedurl.Canonical(feedURL)
+	if err != nil {
+		return err
+	}
+	if !localNetwork {
+		canonical = upgradeScheme(ctx, canonical)
+	}
+	if canonical != feedURL {
+		fmt.Printf("Using %s\n", canonical)
+		feedURL = canonical
+	}
+
+	// Check if feed already exists
+	existingFeed, err := store.GetFeedByURL(ctx, feedURL)
+	if err == nil && existingFeed != nil {
+		return fmt.Errorf("feed already exists: %s", feedURL)
+	}
+
+	if !allowDuplicate {
+		duplicate, err := findDuplicateFeed(ctx, feedURL)
+		if err != nil {
+			return err
+		}
+		if duplicate != nil {
+			fmt.Printf("You already follow this feed as %s\n", duplicate.URL)
+			if !yes {
+				fmt.Printf("Merge it, switching the existing feed to %s? (y/N): ", feedURL)
+				var confirm string
+				fmt.Scanln(&confirm)
+				if confirm != "y" && confirm != "Y" {
+					fmt.Println("Not added. Use --allow-duplicate to follow both.")
+					return nil
+				}
+			}
+			return mergeFeedURL(ctx, duplicate, feedURL)
+		}
+	}
+
+	runner, err := hookRunner(os.Stderr)
+	if err != nil {
+		return err
+	}
+	hooked, err := runner.PreAddFeed(ctx, hooks.Feed{URL: feedURL, Title: feedTitle, Folder: folder})
+	if err != nil {
+		return err
+	}
+	feedTitle, folder = hooked.Title, hooked.Folder
+
+	// Create new feed
+	feed := storage.NewFeed(feedURL)
+	feed.Folder = folder
+	feed.LocalNetwork = localNetwork
+	feed.Browser = browser
+	feed.Monitor = opts.monitor
+	feed.Priority = opts.priority
+	feed.BackfillDays, feed.BackfillLimit = backfill.Days, backfill.Limit
+	if feedTitle != "" {
+		feed.Title = &feedTitle
+	}
+
+	// Save to storage
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		return fmt.Errorf("failed to create feed: %w", err)
+	}
+
+	// Add to OPML for import/export compatibility
+	opmlTitle := feedTitle
+	if opmlTitle == "" {
+		opmlTitle = feedURL
+	}
+	if err := opmlDoc.AddFeed(feedURL, opmlTitle, folder); err != nil {
+		// Non-fatal: OPML is for import/export, SQLite is source of truth
+		fmt.Printf("Note: Could not add to OPML: %v\n", err)
+	} else {
+		if err := saveOPML(); err != nil {
+			fmt.Printf("Note: Could not save OPML: %v\n", err)
+		}
+	}
+
+	switch {
+	case opts.monitor:
+		fmt.Printf("Watching page: %s\n", feedURL)
+	case folder != "":
+		fmt.Printf("Added feed to folder '%s': %s\n", folder, feedTitle)
+	default:
+		fmt.Printf("Added feed: %s\n", feedTitle)
+	}
+	fmt.Printf("Feed ID: %s\n", feed.ID)
+
+	return nil
+}
+
+// upgradeScheme returns the https:// form of an http:// feed URL when the
+// feed answers there too, and the URL unchanged otherwise.
+func upgradeScheme(ctx context.Context, feedURL string) string {
+	if !strings.HasPrefix(feedURL, "http://") {
+		return feedURL
+	}
+	secure := "https://" + strings.TrimPrefix(feedURL, "http://")
+	code, err := fetch.Probe(ctx, secure, false)
+	if err != nil || code != http.StatusOK {
+		return feedURL
+	}
+	return secure
+}
+
+// findDuplicateFeed returns the stored feed that is a near-duplicate of
+// feedURL (same feed under another scheme, www, or trailing slash), or nil.
+func findDuplicateFeed(ctx context.Context, feedURL string) (*models.Feed, error) {
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	key := feedurl.Key(feedURL)
+	for _, feed := range feeds {
+		if feedurl.Key(feed.URL) == key {
+			return feed, nil
+		}
+	}
+	return nil, nil
+}
+
+// mergeFeedURL moves an existing feed to newURL, keeping its entries, read
+// state, and folder. Cached fetch headers belong to the old URL and are cleared.
+func mergeFeedURL(ctx context.Context, feed *models.Feed, newURL string) error {
+	oldURL := feed.URL
+	feed.URL = newURL
+	feed.ETag = nil
+	feed.LastModified = nil
+	if err := store.UpdateFeed(ctx, feed); err != nil {
+		return fmt.Errorf("failed to update feed: %w", err)
+	}
+
+	if err := opmlDoc.SetFeedURL(oldURL, newURL); err != nil {
+		fmt.Printf("Note: Could not update OPML: %v\n", err)
+	} else if err := saveOPML(); err != nil {
+		fmt.Printf("Note: Could not save OPML: %v\n", err)
+	}
+
+	fmt.Printf("Merged into existing feed: %s\n", feed.GetDisplayName())
+	fmt.Printf("Feed ID: %s\n", feed.ID)
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(feedCmd)
 	feedCmd.AddCommand(feedAddCmd)
 	feedCmd.AddCommand(feedListCmd)
 	feedCmd.AddCommand(feedRemoveCmd)
 	feedCmd.AddCommand(feedMoveCmd)
-
-	feedAddCmd.Flags().StringP("folder", "f", "", "folder to organize feed in")
-	feedAddCmd.Flags().StringP("title", "t", "", "feed title (defaults to discovered title)")
-	feedAddCmd.Flags().Bool("no-discover", false, "skip feed discovery and use URL as-is")
-	feedAddCmd.Flags().Bool("local", false, "allow fetching from local network (private IP) addresses")
+	feedCmd.AddCommand(feedMergeCmd)
+	feedCmd.AddCommand(feedAuthCmd)
+	feedCmd.AddCommand(feedIdentityCmd)
+	feedCmd.AddCommand(feedPriorityCmd)
+
+	addFlags(feedAddCmd)
+	feedAuthCmd.Flags().String("username", "", "username to send")
+	feedAuthCmd.Flags().Bool("clear", false, "remove the username and password")
+
+	feedRemoveCmd.ValidArgsFunction = feedURLArgs
+	feedAuthCmd.ValidArgsFunction = feedURLArgs
+	feedIdentityCmd.ValidArgsFunction = feedIdentityArgs
+	feedPriorityCmd.ValidArgsFunction = feedPriorityArgs
+	feedMoveCmd.ValidArgsFunction = feedMoveArgs
+	feedMergeCmd.ValidArgsFunction = feedMergeArgs
 }
@@ -4,187 +4,389 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
-	"github.com/harper/digest/internal/fetch"
 	"github.com/harper/digest/internal/models"
-	"github.com/harper/digest/internal/parse"
-	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/runlock"
+	"github.com/harper/digest/internal/score"
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/harper/digest/internal/syncer"
 )
 
 var fetchCmd = &cobra.Command{
-	Use:   "fetch [url]",
-	Short: "Fetch new entries from feeds",
+	Use:     "fetch [url]",
+	Aliases: []string{"sync"},
+	Short:   "Fetch new entries from feeds",
 	Long: `Fetch new entries from all subscribed feeds or a specific feed by URL.
 
 Uses HTTP caching headers (ETag, Last-Modified) to avoid re-fetching unchanged content.
-Use --force to ignore cache headers and fetch unconditionally.`,
+Use --force to ignore cache headers and per-feed sync intervals. DNS answers
+and validated bodies are still reused within one run, and the summary says
+how often that saved a lookup or a download.
+Paused and archived feeds are skipped unless fetched explicitly by URL.
+After syncing every feed, feeds with no new entries or only errors for
+inactive_days (90 unless set in config.json) are archived; see
+'digest feed archive'. Then the points and comment counts of recent unread
+Hacker News and Lobsters posts are refreshed, at most hourly per post.
+Folders with an unread budget ("unread_budgets" in config.json, e.g.
+{"News": 200}) that now have more unread entries have their oldest unread
+entries marked read, skipping entries kept with 'digest keep-unread'.
+
+--quiet prints nothing on success and only failures to stderr.
+--porcelain prints one tab-separated record per feed:
+  status (ok/cached/skipped/error/archived), url, new_entries, detail
+then one "budget" record per folder trimmed to its unread budget:
+  budget, folder, marked_read, budget
+then a "cache" record when any request was made:
+  cache, dns_hits, dns_lookups, body_hits, body_requests
+followed by a final summary record:
+  summary, synced, new_entries, cached, skipped, errors
+
+--jobs fetches several feeds at once ("sync_concurrency" in config.json sets
+the default); entries are still stored one feed at a time.
+
+Only one sync runs per profile at a time. Use --wait to queue behind a
+running sync instead of exiting immediately.
+
+Ctrl-C stops the sync cleanly: no more feeds start, fetches in flight are
+canceled, feeds already fetched are stored whole, and the summary covers
+what finished. The feeds left over are recorded as skipped (interrupted)
+and archiving, scores, budgets, and post_sync hooks wait for the next sync.
+Press Ctrl-C again to quit at once.
+
+Exit status is 0 when every attempted feed synced, 2 when some feeds
+failed, 3 when all of them failed, 4 when another sync is already
+running, 130 when interrupted, and 1 for any other error.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		force, _ := cmd.Flags().GetBool("force")
-
-		// Get all feeds from storage
-		feeds, err := store.ListFeeds()
+		wait, _ := cmd.Flags().GetDuration("wait")
+		jobs := cfg.GetSyncConcurrency()
+		if cmd.Flags().Changed("jobs") {
+			jobs, _ = cmd.Flags().GetInt("jobs")
+		}
+		mode := getOutputMode(cmd)
+		icons, err := iconDir()
+		if err != nil {
+			return err
+		}
+		thumbs, err := thumbnailDir()
 		if err != nil {
-			return fmt.Errorf("failed to list feeds: %w", err)
+			return err
+		}
+		snaps, err := snapshotDir()
+		if err != nil {
+			return err
+		}
+		scorePolicy, refreshScores, err := cfg.GetScorePolicy()
+		if err != nil {
+			return err
+		}
+		runner, err := hookRunner(cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		opts, err := cfg.GetSyncOptions(thumbs, snaps, runner, cmd.ErrOrStderr())
+		if err != nil {
+			return err
 		}
 
-		if len(feeds) == 0 {
-			fmt.Println("No feeds found. Add a feed with 'digest feed add <url>'")
-			return nil
+		lock, err := acquireSyncLock(cmd, wait)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+		if lock.Stale != nil && mode != outputPorcelain {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Recovered stale sync lock left by pid %d\n", lock.Stale.PID)
 		}
 
-		// Filter to specific URL if provided
+		out := cmd.OutOrStdout()
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+
+		engine := &syncer.Engine{
+			Store: store,
+			Options: func(feed *models.Feed) (feedsync.Options, error) {
+				o := opts
+				var err error
+				o.Secrets, err = cfg.FeedSecrets(feed)
+				return o, err
+			},
+			Force:       force,
+			Concurrency: jobs,
+			IconDir:     icons,
+			Source:      "cli",
+		}
 		if len(args) == 1 {
-			targetURL := args[0]
-			filtered := []*models.Feed{}
-			for _, feed := range feeds {
-				if feed.URL == targetURL {
-					filtered = append(filtered, feed)
-					break
+			engine.URL = args[0]
+		}
+		// syncing is set while a "Syncing <feed>... " line awaits its result
+		syncing := false
+		engine.Progress = func(ev syncer.Event) {
+			feed := ev.Feed
+			displayName := feedDisplayName(feed)
+			if ev.Skipped != "" {
+				switch mode {
+				case outputNormal:
+					if syncing {
+						fmt.Println(faint("(interrupted)"))
+						syncing = false
+					} else if ev.Skipped != syncer.Interrupted {
+						// The summary counts the feeds an interrupt left unsynced
+						fmt.Printf("Skipping %s %s\n", displayName, faint("("+ev.Skipped+")"))
+					}
+				case outputPorcelain:
+					writePorcelain(out, "skipped", feed.URL, "0", ev.Skipped)
 				}
+				return
+			}
+			// Feeds synced one at a time show which one is in progress;
+			// concurrent ones finish in any order, so each prints whole
+			if mode == outputNormal && (ev.Done == (jobs > 1)) {
+				fmt.Printf("Syncing %s... ", displayName)
+			}
+			syncing = mode == outputNormal && jobs <= 1 && !ev.Done
+			if !ev.Done {
+				return
 			}
-			if len(filtered) == 0 {
-				return fmt.Errorf("feed not found: %s", targetURL)
+
+			if ev.Err != nil {
+				switch mode {
+				case outputNormal:
+					fmt.Printf("%s %s\n", red("x"), ev.Err.Error())
+				case outputQuiet:
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", feed.URL, ev.Err)
+				case outputPorcelain:
+					writePorcelain(out, "error", feed.URL, "0", ev.Err.Error())
+				}
+				return
+			}
+
+			result := ev.Result
+			newCount, wasCached := result.NewEntries, result.WasCached
+			switch mode {
+			case outputNormal:
+				if wasCached {
+					fmt.Printf("%s (cached)\n", faint("-"))
+				} else if newCount > 0 {
+					fmt.Printf("%s %d new\n", green("v"), newCount)
+				} else {
+					fmt.Printf("%s no new entries\n", green("v"))
+				}
+				if result.Identity != "" {
+					fmt.Printf("  %s\n", faint("GUIDs changed since the last sync; now matching entries by "+result.Identity))
+				}
+				if result.Duplicates > 0 {
+					fmt.Printf("  %s\n", faint(fmt.Sprintf("%d republished duplicate(s) of recent entries skipped", result.Duplicates)))
+				}
+				if result.Filtered > 0 {
+					fmt.Printf("  %s\n", faint(fmt.Sprintf("%d new item(s) dropped by ingest scripts", result.Filtered)))
+				}
+				if result.Truncated {
+					fmt.Printf("  %s\n", faint("feed too large to read whole; synced its first entries only"))
+				}
+			case outputPorcelain:
+				status := "ok"
+				if wasCached {
+					status = "cached"
+				}
+				writePorcelain(out, status, feed.URL, strconv.Itoa(newCount), "")
 			}
-			feeds = filtered
 		}
 
-		// Sync each feed
-		totalNew := 0
-		totalCached := 0
-		totalErrors := 0
+		report, err := engine.Sync(ctx)
+		if errors.Is(err, syncer.ErrNoFeeds) {
+			if mode == outputNormal {
+				fmt.Println("No feeds found. Add a feed with 'digest feed add <url>'")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		now, run := report.Started, report.Run
+		totalNew, totalCached, totalSkipped, totalErrors := report.New, report.Cached, report.Skipped, report.Errors
+		attempted := report.Attempted()
 
-		green := color.New(color.FgGreen).SprintFunc()
-		red := color.New(color.FgRed).SprintFunc()
-		faint := color.New(color.Faint).SprintFunc()
+		// An interrupted sync only reports what it finished; the steps that
+		// act on every feed wait for a sync that reaches them all
+		interrupted := ctx.Err() != nil
+		var archived []feedsync.InactiveFeed
+		var scored score.Result
+		var trimmed []feedsync.BudgetResult
+		if !interrupted {
+			raiseAlerts(ctx, cmd.ErrOrStderr(), report.Failed, attempted)
+			deliverEntries(ctx, cmd.ErrOrStderr())
+			generateDigest(ctx, cmd.ErrOrStderr())
 
-		for _, feed := range feeds {
-			displayName := feedDisplayName(feed)
-			fmt.Printf("Syncing %s... ", displayName)
+			// Archive dead feeds only on full syncs, so every feed had its chance
+			if len(args) == 0 {
+				archived, err = archiveInactive(ctx, cfg.GetInactiveAfter(), now)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not archive inactive feeds: %v\n", err)
+				}
+			}
+
+			if refreshScores {
+				scored, err = score.Refresh(ctx, store, score.New(), scorePolicy, time.Now())
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not refresh scores: %v\n", err)
+				}
+			}
 
-			newCount, wasCached, err := syncFeed(feed, force)
+			trimmed, err = feedsync.EnforceBudgets(ctx, store, feedsync.Budgets(opmlDoc, cfg.UnreadBudgets))
 			if err != nil {
-				fmt.Printf("%s %s\n", red("x"), err.Error())
-				totalErrors++
-				continue
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not enforce unread budgets: %v\n", err)
 			}
+		}
+
+		cache := report.Cache
+		dnsLookups := cache.DNSHits + cache.DNSMisses
+		bodyRequests := cache.BodyHits + cache.BodyMisses
 
-			if wasCached {
-				fmt.Printf("%s (cached)\n", faint("-"))
-				totalCached++
-			} else if newCount > 0 {
-				fmt.Printf("%s %d new\n", green("v"), newCount)
-				totalNew += newCount
-			} else {
-				fmt.Printf("%s no new entries\n", green("v"))
+		switch mode {
+		case outputNormal:
+			fmt.Println()
+			fmt.Printf("Summary: %d feed(s) synced\n", attempted)
+			if totalNew > 0 {
+				fmt.Printf("  %s %d new entries\n", green("v"), totalNew)
+			}
+			if totalCached > 0 {
+				fmt.Printf("  %s %d cached (not modified)\n", faint("-"), totalCached)
+			}
+			if skipped := totalSkipped - report.Interrupted; skipped > 0 {
+				fmt.Printf("  %s %d skipped (paused, archived, bookmarks, or not due)\n", faint("-"), skipped)
+			}
+			if report.Interrupted > 0 {
+				fmt.Printf("  %s %d not synced (interrupted)\n", red("x"), report.Interrupted)
 			}
+			if totalErrors > 0 {
+				fmt.Printf("  %s %d errors\n", red("x"), totalErrors)
+			}
+			if scored.Updated > 0 {
+				fmt.Printf("  %s %d scores refreshed\n", faint("-"), scored.Updated)
+			}
+			if cache.BodyHits > 0 || cache.DNSHits > 0 {
+				fmt.Printf("  %s\n", faint(fmt.Sprintf("HTTP cache: %d of %d bodies reused (%.0f%%), %d of %d DNS lookups cached (%.0f%%)",
+					cache.BodyHits, bodyRequests, cache.BodyHitRate()*100,
+					cache.DNSHits, dnsLookups, cache.DNSHitRate()*100)))
+			}
+			if len(archived) > 0 {
+				fmt.Println()
+				reportArchived(out, archived)
+			}
+			if len(trimmed) > 0 {
+				fmt.Println()
+				reportBudgets(out, trimmed)
+			}
+		case outputPorcelain:
+			for _, f := range archived {
+				writePorcelain(out, "archived", f.Feed.URL, "0", f.Reason)
+			}
+			for _, b := range trimmed {
+				writePorcelain(out, "budget", b.Folder, strconv.Itoa(len(b.Marked)), strconv.Itoa(b.Max))
+			}
+			if dnsLookups > 0 || bodyRequests > 0 {
+				writePorcelain(out, "cache", strconv.Itoa(cache.DNSHits), strconv.Itoa(dnsLookups),
+					strconv.Itoa(cache.BodyHits), strconv.Itoa(bodyRequests))
+			}
+			writePorcelain(out, "summary", strconv.Itoa(attempted), strconv.Itoa(totalNew),
+				strconv.Itoa(totalCached), strconv.Itoa(totalSkipped), strconv.Itoa(totalErrors))
 		}
 
-		// Print summary
-		fmt.Println()
-		fmt.Printf("Summary: %d feed(s) synced\n", len(feeds))
-		if totalNew > 0 {
-			fmt.Printf("  %s %d new entries\n", green("v"), totalNew)
+		run.Archived = len(archived)
+		run.Finish(time.Now())
+		if path, err := lastSyncPath(); err == nil {
+			if err := feedsync.SaveRun(path, run); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not save the sync summary: %v\n", err)
+			}
 		}
-		if totalCached > 0 {
-			fmt.Printf("  %s %d cached (not modified)\n", faint("-"), totalCached)
+		if interrupted {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return &codedError{
+				code: exitInterrupted,
+				err:  fmt.Errorf("sync interrupted; %d of %d feed(s) synced", attempted, attempted+report.Interrupted),
+			}
 		}
-		if totalErrors > 0 {
-			fmt.Printf("  %s %d errors\n", red("x"), totalErrors)
+		runner.PostSync(ctx, run)
+
+		if err := syncFailure(totalErrors, attempted); err != nil {
+			// Per-feed errors were already reported above; main prints the one-line
+			// summary, so skip cobra's usage text and duplicate error line
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return err
 		}
 
 		return nil
 	},
 }
 
-// syncFeed fetches and processes a single feed, returning the count of new entries
-func syncFeed(feed *models.Feed, force bool) (newCount int, wasCached bool, err error) {
-	// Get cache headers from feed (skip if force)
-	var etag, lastModified *string
-	if !force {
-		etag = feed.ETag
-		lastModified = feed.LastModified
-	}
+// budgetExamples is how many marked entries reportBudgets names per folder.
+const budgetExamples = 3
 
-	// Fetch the feed
-	result, err := fetch.Fetch(context.Background(), feed.URL, etag, lastModified, feed.LocalNetwork)
-	if err != nil {
-		// Update error state
-		if updateErr := store.UpdateFeedError(feed.ID, err.Error()); updateErr != nil {
-			return 0, false, fmt.Errorf("fetch failed (%v) and error update failed: %w", err, updateErr)
+// reportBudgets tells the user which folders a fetch trimmed to their unread
+// budgets and names the newest entries it marked read.
+func reportBudgets(w io.Writer, trimmed []feedsync.BudgetResult) {
+	faint := color.New(color.Faint).SprintFunc()
+	for _, b := range trimmed {
+		folder := b.Folder
+		if folder == "" {
+			folder = "(no folder)"
+		}
+		fmt.Fprintf(w, "%s was over its unread budget of %d; marked the oldest %d of %d unread as read:\n",
+			folder, b.Max, len(b.Marked), b.Unread)
+		shown := b.Marked[max(len(b.Marked)-budgetExamples, 0):]
+		for i := len(shown) - 1; i >= 0; i-- {
+			fmt.Fprintf(w, "  %s %s\n", shortID(shown[i].ID), shown[i].GetTitle())
+		}
+		if more := len(b.Marked) - len(shown); more > 0 {
+			fmt.Fprintf(w, "  %s\n", faint(fmt.Sprintf("and %d older", more)))
 		}
-		return 0, false, err
-	}
-
-	// Handle 304 Not Modified
-	if result.NotModified {
-		return 0, true, nil
 	}
+	fmt.Fprintln(w, faint("Keep an entry out of this with 'digest keep-unread <id>'."))
+}
 
-	// Parse the feed
-	parsed, err := parse.Parse(result.Body)
+// acquireSyncLock takes the active profile's run lock so overlapping syncs
+// (e.g. two cron jobs) don't insert the same entries twice.
+func acquireSyncLock(cmd *cobra.Command, wait time.Duration) (*runlock.Lock, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
 	if err != nil {
-		errMsg := fmt.Sprintf("failed to parse feed: %v", err)
-		if updateErr := store.UpdateFeedError(feed.ID, errMsg); updateErr != nil {
-			return 0, false, fmt.Errorf("parse failed (%v) and error update failed: %w", err, updateErr)
-		}
-		return 0, false, fmt.Errorf("failed to parse feed: %w", err)
+		return nil, fmt.Errorf("invalid profile: %w", err)
 	}
-
-	// Update feed title if empty and persist
-	titleUpdated := false
-	if feed.Title == nil || *feed.Title == "" {
-		feed.Title = &parsed.Title
-		titleUpdated = true
+	lock, err := runlock.Acquire(runlock.Path(profileDir), wait)
+	if errors.Is(err, runlock.ErrLocked) {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return nil, &codedError{code: exitLocked, err: err}
 	}
+	return lock, err
+}
 
-	// Process entries
-	newCount = 0
-	for _, parsedEntry := range parsed.Entries {
-		// Check if entry already exists
-		exists, err := store.EntryExists(feed.ID, parsedEntry.GUID)
-		if err != nil {
-			return newCount, false, fmt.Errorf("failed to check entry existence: %w", err)
-		}
-
-		if exists {
-			continue
-		}
-
-		// Create new entry
-		entry := storage.NewEntry(feed.ID, parsedEntry.GUID, parsedEntry.Title)
-		entry.Link = &parsedEntry.Link
-		entry.Author = &parsedEntry.Author
-		entry.PublishedAt = parsedEntry.PublishedAt
-		entry.Content = &parsedEntry.Content
-
-		if err := store.CreateEntry(entry); err != nil {
-			return newCount, false, fmt.Errorf("failed to create entry: %w", err)
-		}
-
-		newCount++
+// syncFailure returns an error carrying the sync exit code when any of the
+// attempted feeds failed, or nil when they all succeeded.
+func syncFailure(failed, attempted int) error {
+	if failed == 0 {
+		return nil
 	}
-
-	// Update feed fetch state
-	fetchedAt := time.Now()
-	if err := store.UpdateFeedFetchState(feed.ID, &result.ETag, &result.LastModified, fetchedAt); err != nil {
-		return newCount, false, fmt.Errorf("failed to update feed state: %w", err)
+	code := exitPartialFailure
+	if failed >= attempted {
+		code = exitTotalFailure
 	}
-
-	// If title was updated, persist
-	if titleUpdated {
-		if err := store.UpdateFeed(feed); err != nil {
-			return newCount, false, fmt.Errorf("failed to update feed title: %w", err)
-		}
+	return &codedError{
+		code: code,
+		err:  fmt.Errorf("%d of %d feed(s) failed to sync", failed, attempted),
 	}
-
-	return newCount, false, nil
 }
 
 // feedDisplayName returns a human-readable name for the feed
@@ -198,4 +400,8 @@ func feedDisplayName(feed *models.Feed) string {
 func init() {
 	rootCmd.AddCommand(fetchCmd)
 	fetchCmd.Flags().BoolP("force", "f", false, "ignore cache headers and force fetch")
+	fetchCmd.Flags().Duration("wait", 0, "wait up to this long for a running sync to finish (e.g. 30s, 5m)")
+	fetchCmd.Flags().IntP("jobs", "j", 1, "fetch this many feeds at once (default sync_concurrency from config, or 1)")
+	addOutputFlags(fetchCmd, "only report failures, on stderr")
+	fetchCmd.ValidArgsFunction = feedURLArgs
 }
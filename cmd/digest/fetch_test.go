@@ -4,6 +4,8 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/harper/digest/internal/models"
@@ -49,6 +51,40 @@ func TestFeedDisplayName(t *testing.T) {
 	}
 }
 
+func TestSyncFailureExitCodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		failed    int
+		attempted int
+		want      int
+	}{
+		{"all succeeded", 0, 5, exitOK},
+		{"nothing attempted", 0, 0, exitOK},
+		{"some failed", 2, 5, exitPartialFailure},
+		{"all failed", 5, 5, exitTotalFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := syncFailure(tt.failed, tt.attempted)
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exitCode(syncFailure(%d, %d)) = %d, want %d", tt.failed, tt.attempted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCodeUnwrapsCodedError(t *testing.T) {
+	if got := exitCode(errors.New("boom")); got != exitError {
+		t.Errorf("expected plain errors to exit %d, got %d", exitError, got)
+	}
+
+	wrapped := fmt.Errorf("context: %w", syncFailure(1, 3))
+	if got := exitCode(wrapped); got != exitPartialFailure {
+		t.Errorf("expected wrapped coded error to exit %d, got %d", exitPartialFailure, got)
+	}
+}
+
 // Helper function
 func stringPtr(s string) *string {
 	return &s
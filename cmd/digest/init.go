@@ -0,0 +1,260 @@
+// ABOUTME: Init command that subscribes new users to curated OPML starter packs in one step
+// ABOUTME: Packs come bundled or from a packs index; each creates its folders and skips feeds already followed
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/feedurl"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/hooks"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/packs"
+	"github.com/harper/digest/internal/storage"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Get started with a starter pack of feeds",
+	Long: `Subscribe to a curated starter pack of feeds in one step, creating the
+pack's folders. Feeds you already follow are skipped, so packs can be
+combined or run again. Without --pack, lists the packs on offer.
+
+Packs bundled with digest: tech, golang, and news. More can come from a
+packs index, a JSON file set as "packs_index" in config.json or given
+with --index:
+
+  {"packs": [{"name": "rust", "description": "Rust blogs", "url": "rust.opml"}]}
+
+Pack URLs are relative to the index. A bundled pack wins over an index
+pack of the same name.`,
+	Example: `  digest init
+  digest init --pack tech
+  digest init --pack golang,news
+  digest init --index https://example.com/packs.json --pack rust`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		names, _ := cmd.Flags().GetStringSlice("pack")
+		indexURL, _ := cmd.Flags().GetString("index")
+		if indexURL == "" {
+			indexURL = cfg.PacksIndex
+		}
+
+		bundled, err := packs.Bundled()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return listPacks(ctx, bundled, indexURL)
+		}
+
+		// Fetch the index only when a pack isn't bundled
+		var indexed []packs.Pack
+		for _, name := range names {
+			if _, ok := packs.Find(bundled, name); ok || indexed != nil {
+				continue
+			}
+			if indexURL == "" {
+				return fmt.Errorf("unknown pack %q (bundled packs: %s)", name, packNames(bundled))
+			}
+			if indexed, err = fetchPackIndex(ctx, indexURL); err != nil {
+				return err
+			}
+		}
+
+		feeds, err := store.ListFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list feeds: %w", err)
+		}
+		following := make(map[string]bool, len(feeds))
+		for _, f := range feeds {
+			following[feedurl.Key(f.URL)] = true
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+		total := 0
+		for _, name := range names {
+			doc, err := loadPack(ctx, name, indexed)
+			if err != nil {
+				return err
+			}
+			added, skipped, err := subscribePack(ctx, doc, following)
+			if err != nil {
+				return err
+			}
+			total += added
+			fmt.Printf("%s Pack %s: subscribed to %d feed(s) in %s\n", green("v"), name, added, strings.Join(packFolders(doc), ", "))
+			if skipped > 0 {
+				fmt.Println(faint(fmt.Sprintf("  %d feed(s) already followed", skipped)))
+			}
+		}
+		if total > 0 {
+			if err := saveOPML(); err != nil {
+				fmt.Printf("Note: Could not save OPML: %v\n", err)
+			}
+			fmt.Println("Run 'digest fetch' to download their entries.")
+		}
+		return nil
+	},
+}
+
+// listPacks prints the bundled packs and, when an index is set, its packs.
+func listPacks(ctx context.Context, bundled []packs.Pack, indexURL string) error {
+	fmt.Println("Starter packs:")
+	for _, p := range bundled {
+		fmt.Printf("  %-10s %s\n", p.Name, p.Description)
+	}
+	if indexURL != "" {
+		indexed, err := fetchPackIndex(ctx, indexURL)
+		if err != nil {
+			fmt.Printf("Note: %v\n", err)
+		}
+		for _, p := range indexed {
+			if _, ok := packs.Find(bundled, p.Name); !ok {
+				fmt.Printf("  %-10s %s\n", p.Name, p.Description)
+			}
+		}
+	}
+	fmt.Println()
+	fmt.Println("Subscribe with 'digest init --pack <name>'; combine packs with commas.")
+	return nil
+}
+
+// fetchPackIndex downloads and parses the packs index at indexURL.
+func fetchPackIndex(ctx context.Context, indexURL string) ([]packs.Pack, error) {
+	result, err := fetch.Fetch(ctx, indexURL, nil, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch packs index: %w", err)
+	}
+	return packs.ParseIndex(result.Body, indexURL)
+}
+
+// loadPack returns the feeds of the pack called name: bundled, or else
+// fetched from its entry in the index.
+func loadPack(ctx context.Context, name string, indexed []packs.Pack) (*opml.Document, error) {
+	doc, ok, err := packs.LoadBundled(name)
+	if err != nil || ok {
+		return doc, err
+	}
+	p, ok := packs.Find(indexed, name)
+	if !ok {
+		return nil, fmt.Errorf("unknown pack %q", name)
+	}
+	result, err := fetch.Fetch(ctx, p.URL, nil, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pack %s: %w", name, err)
+	}
+	doc, err = opml.Parse(bytes.NewReader(result.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pack %s: %w", name, err)
+	}
+	return doc, nil
+}
+
+// subscribePack adds the pack's feeds to the store and the OPML document
+// in the pack's folders, skipping feeds already followed. following is
+// keyed by feedurl.Key and updated as feeds are added.
+func subscribePack(ctx context.Context, doc *opml.Document, following map[string]bool) (added, skipped int, err error) {
+	backfill, err := cfg.GetBackfill()
+	if err != nil {
+		return 0, 0, err
+	}
+	runner, err := hookRunner(os.Stderr)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, pf := range doc.AllFeeds() {
+		feedURL, err := feedurl.Canonical(pf.URL)
+		if err != nil {
+			return added, skipped, err
+		}
+		key := feedurl.Key(feedURL)
+		if following[key] {
+			skipped++
+			continue
+		}
+
+		hooked, err := runner.PreAddFeed(ctx, hooks.Feed{URL: feedURL, Title: pf.Title, Folder: pf.Folder})
+		if err != nil {
+			fmt.Printf("Note: Skipped %s: %v\n", feedURL, err)
+			continue
+		}
+
+		feed := storage.NewFeed(feedURL)
+		feed.Folder = hooked.Folder
+		feed.BackfillDays, feed.BackfillLimit = backfill.Days, backfill.Limit
+		title := hooked.Title
+		if title != "" {
+			feed.Title = &title
+		} else {
+			title = feedURL
+		}
+		if err := store.CreateFeed(ctx, feed); err != nil {
+			return added, skipped, fmt.Errorf("failed to create feed: %w", err)
+		}
+		if err := opmlDoc.AddFeed(feedURL, title, hooked.Folder); err != nil {
+			// Non-fatal: OPML is for import/export, the store is the source of truth
+			fmt.Printf("Note: Could not add to OPML: %v\n", err)
+		}
+		following[key] = true
+		added++
+	}
+	return added, skipped, nil
+}
+
+// packFolders returns the folders a pack's feeds go into, in pack order.
+func packFolders(doc *opml.Document) []string {
+	var folders []string
+	seen := make(map[string]bool)
+	for _, f := range doc.AllFeeds() {
+		folder := f.Folder
+		if folder == "" {
+			folder = "(no folder)"
+		}
+		if !seen[folder] {
+			seen[folder] = true
+			folders = append(folders, folder)
+		}
+	}
+	return folders
+}
+
+// packNames joins the names of packs for messages.
+func packNames(list []packs.Pack) string {
+	names := make([]string, len(list))
+	for i, p := range list {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// packArgs completes the bundled pack names.
+func packArgs(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	bundled, err := packs.Bundled()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, p := range bundled {
+		names = append(names, p.Name+"\t"+p.Description)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringSlice("pack", nil, "starter pack(s) to subscribe to, such as tech, golang, or news")
+	initCmd.Flags().String("index", "", "URL of a packs index (default: packs_index in config.json)")
+	_ = initCmd.RegisterFlagCompletionFunc("pack", packArgs)
+}
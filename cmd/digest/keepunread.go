@@ -0,0 +1,57 @@
+// ABOUTME: Keep-unread command for pinning entries to come back to
+// ABOUTME: Kept entries stay unread through bulk marking, pruning, and marking on read, open, or list
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var keepUnreadCmd = &cobra.Command{
+	Use:   "keep-unread <entry-id>...",
+	Short: "Keep entries unread to come back to them",
+	Long: `Pin entries unread, a lighter alternative to starring for "come back to
+this". Kept entries are marked unread if they were read, and then stay
+unread: 'digest mark-read --before', bulk_mark_read, pruning to a feed's
+max entries, and the marking done by 'digest read', 'digest open', and
+'digest list --mark-read' all skip them.
+
+Marking a kept entry read by ID releases it, as does --release.
+'digest list --kept' shows what you've kept.
+
+Examples:
+  digest keep-unread abc123
+  digest keep-unread abc123 def456
+  digest keep-unread abc123 --release`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		release, _ := cmd.Flags().GetBool("release")
+		green := color.New(color.FgGreen).SprintFunc()
+
+		for _, ref := range args {
+			entry, err := store.GetEntryByPrefix(ctx, ref)
+			if err != nil {
+				return fmt.Errorf("failed to find entry %s: %w", ref, err)
+			}
+			if err := store.SetEntryKeepUnread(ctx, entry.ID, !release); err != nil {
+				return fmt.Errorf("failed to update entry %s: %w", ref, err)
+			}
+			if release {
+				fmt.Printf("%s Released: %s\n", green("v"), entry.GetTitle())
+			} else {
+				fmt.Printf("%s Keeping unread: %s\n", green("v"), entry.GetTitle())
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(keepUnreadCmd)
+	keepUnreadCmd.Flags().Bool("release", false, "stop keeping the entries unread")
+	keepUnreadCmd.ValidArgsFunction = entryIDListArgs
+}
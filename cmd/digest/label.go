@@ -0,0 +1,155 @@
+// ABOUTME: Label commands for grouping feeds beyond folders; a feed can carry several labels
+// ABOUTME: Adds, removes, renames, and lists labels, which live in the OPML file as feed categories
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/opml"
+)
+
+var labelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Manage feed labels",
+	Long: `Label feeds to group them beyond folders. A feed sits in one folder but
+can carry any number of labels, such as "Go" and "Newsletters". Labels are
+matched ignoring case, filter 'digest list --label', and are exported in
+OPML as each feed's category.`,
+}
+
+var labelAddCmd = &cobra.Command{
+	Use:               "add <feed> <label>...",
+	Short:             "Add labels to a feed",
+	Long:              "Add labels to a feed, given by URL or ID prefix, keeping any it already has",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: labelFeedArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return changeFeedLabels(cmd, args[0], args[1:], opmlDoc.LabelFeed, "Labelled")
+	},
+}
+
+var labelRemoveCmd = &cobra.Command{
+	Use:               "remove <feed> <label>...",
+	Aliases:           []string{"rm"},
+	Short:             "Remove labels from a feed",
+	Long:              "Remove labels from a feed, given by URL or ID prefix",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: labelFeedArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return changeFeedLabels(cmd, args[0], args[1:], opmlDoc.UnlabelFeed, "Unlabelled")
+	},
+}
+
+var labelRenameCmd = &cobra.Command{
+	Use:               "rename <label> <new-label>",
+	Short:             "Rename a label on every feed",
+	Long:              "Rename a label on every feed carrying it, merging it into the new label where a feed has both",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: labelArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newLabel, err := opml.CheckLabel(args[1])
+		if err != nil {
+			return err
+		}
+		n := opmlDoc.RenameLabel(args[0], newLabel)
+		if n == 0 {
+			return fmt.Errorf("no feeds are labelled %q", args[0])
+		}
+		if err := saveOPML(); err != nil {
+			return fmt.Errorf("failed to save OPML: %w", err)
+		}
+		fmt.Printf("Renamed %s to %s on %d feed(s)\n", args[0], newLabel, n)
+		return nil
+	},
+}
+
+var labelDeleteCmd = &cobra.Command{
+	Use:               "delete <label>",
+	Short:             "Remove a label from every feed",
+	Long:              "Remove a label from every feed carrying it. The feeds themselves are kept.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: labelArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n := opmlDoc.DeleteLabel(args[0])
+		if n == 0 {
+			return fmt.Errorf("no feeds are labelled %q", args[0])
+		}
+		if err := saveOPML(); err != nil {
+			return fmt.Errorf("failed to save OPML: %w", err)
+		}
+		fmt.Printf("Deleted %s from %d feed(s)\n", args[0], n)
+		return nil
+	},
+}
+
+var labelListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List all labels",
+	Long:    "List all labels and the feeds carrying each",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		labels := opmlDoc.Labels()
+
+		if len(labels) == 0 {
+			fmt.Println("No labels found. Label a feed with 'digest label add <feed> <label>'")
+			return nil
+		}
+
+		fmt.Printf("Found %d label(s):\n\n", len(labels))
+		for _, label := range labels {
+			feeds := opmlDoc.FeedsWithLabel(label)
+			titles := make([]string, 0, len(feeds))
+			for _, feed := range feeds {
+				titles = append(titles, feed.Title)
+			}
+			fmt.Printf("%s (%d feed(s)): %s\n", label, len(feeds), strings.Join(titles, ", "))
+		}
+
+		return nil
+	},
+}
+
+// changeFeedLabels applies change, opmlDoc.LabelFeed or UnlabelFeed, to a
+// feed for each label and saves the OPML file if anything changed.
+func changeFeedLabels(cmd *cobra.Command, ref string, labels []string, change func(url, label string) (bool, error), verb string) error {
+	feed, err := store.GetFeedByURLOrPrefix(cmd.Context(), ref)
+	if err != nil {
+		return fmt.Errorf("feed not found: %s", ref)
+	}
+	var changed []string
+	for _, label := range labels {
+		label, err := opml.CheckLabel(label)
+		if err != nil {
+			return err
+		}
+		ok, err := change(feed.URL, label)
+		if err != nil {
+			return fmt.Errorf("failed to label feed: %w", err)
+		}
+		if ok {
+			changed = append(changed, label)
+		}
+	}
+	if len(changed) == 0 {
+		fmt.Println("Nothing to change")
+		return nil
+	}
+	if err := saveOPML(); err != nil {
+		return fmt.Errorf("failed to save OPML: %w", err)
+	}
+	fmt.Printf("%s %s: %s\n", verb, feed.GetDisplayName(), strings.Join(changed, ", "))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(labelCmd)
+	labelCmd.AddCommand(labelAddCmd)
+	labelCmd.AddCommand(labelRemoveCmd)
+	labelCmd.AddCommand(labelRenameCmd)
+	labelCmd.AddCommand(labelDeleteCmd)
+	labelCmd.AddCommand(labelListCmd)
+}
@@ -1,43 +1,123 @@
 // ABOUTME: List command for viewing feed entries with filtering options
-// ABOUTME: Displays entries with read status, title, and published date using color formatting
+// ABOUTME: Displays entries with read status, title, and relative published date in selectable columns
 
 package main
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/harper/digest/internal/authors"
+	"github.com/harper/digest/internal/models"
 	"github.com/harper/digest/internal/storage"
 	"github.com/harper/digest/internal/timeutil"
+	"github.com/harper/digest/internal/tui"
 )
 
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls", "l"},
 	Short:   "List feed entries",
-	Long:    "List feed entries with optional filtering by feed and read status",
+	Long: `List feed entries with optional filtering by feed and read status.
+
+--author matches entry authors fuzzily ("j doe" or "jnae doe" find Jane
+Doe); --followed shows entries by the authors you follow with
+'digest authors follow'.
+
+Dates show how long ago an entry was published ("2h ago"); --absolute
+shows the full timestamp instead. --columns picks what each line shows,
+in order, from: id, status, title, date, seen, feed, author, link, score,
+comments (default: id,status,title,date). "seen" is when digest first
+fetched the entry; "score" and "comments" are an aggregator post's points
+and comment count ("97c"), as last refreshed by 'digest fetch'.
+
+--first-seen sorts and applies date filters by when entries were first
+fetched rather than by publish date, so old posts a feed backfills don't
+flood --today; its default columns show that time. --new shows only the
+entries each feed's latest sync brought in.
+
+--by-score sorts Hacker News and Lobsters posts by their current points,
+highest first, with unscored entries after them; its default columns
+show the score. --min-score keeps only posts with at least that many
+points.
+
+Unread listings show entries of high-priority feeds first and low-priority
+feeds last (see 'digest feed priority'); --all lists by date alone.
+
+--since takes a date ("2024-01-15"), a period ("week", "last month"), a
+relative date ("3 days ago", "2 weeks", "last monday"), or a range
+("2024-01-01..2024-02-01", end exclusive).
+
+--mark-read marks the listed entries read once they're shown, so a
+headline skim counts as having seen them; "list_marks_read": true in
+config.json makes that the default and --no-mark skips it. Entries kept
+with 'digest keep-unread' stay unread; --kept lists them, and the status
+column shows them as "k".
+
+--quiet prints only entry IDs, one per line.
+--porcelain prints one tab-separated record per entry:
+  id, read (1/0), published_at (RFC 3339, UTC), feed_id, link, title`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		all, _ := cmd.Flags().GetBool("all")
 		feedFilter, _ := cmd.Flags().GetString("feed")
 		category, _ := cmd.Flags().GetString("category")
+		label, _ := cmd.Flags().GetString("label")
 		limit, _ := cmd.Flags().GetInt("limit")
 		offset, _ := cmd.Flags().GetInt("offset")
 		today, _ := cmd.Flags().GetBool("today")
 		yesterday, _ := cmd.Flags().GetBool("yesterday")
 		week, _ := cmd.Flags().GetBool("week")
+		since, _ := cmd.Flags().GetString("since")
+		absolute, _ := cmd.Flags().GetBool("absolute")
+		columnsValue, _ := cmd.Flags().GetString("columns")
+		authorQuery, _ := cmd.Flags().GetString("author")
+		followed, _ := cmd.Flags().GetBool("followed")
+		firstSeen, _ := cmd.Flags().GetBool("first-seen")
+		newOnly, _ := cmd.Flags().GetBool("new")
+		byScore, _ := cmd.Flags().GetBool("by-score")
+		minScore, _ := cmd.Flags().GetInt("min-score")
+		kept, _ := cmd.Flags().GetBool("kept")
+		markFlag, _ := cmd.Flags().GetBool("mark-read")
+		noMark, _ := cmd.Flags().GetBool("no-mark")
+		markRead := (cfg.ListMarksRead || markFlag) && !noMark
+		mode := getOutputMode(cmd)
 
-		// Build entry filter
+		// Build entry filter; no column shows content, so it stays unread
 		filter := &storage.EntryFilter{
-			Limit:  &limit,
-			Offset: &offset,
+			Limit:      &limit,
+			Offset:     &offset,
+			FirstSeen:  firstSeen,
+			LatestSync: newOnly,
+			ByScore:    byScore,
+			KeptOnly:   kept,
+			NoContent:  true,
+		}
+		if cmd.Flags().Changed("min-score") {
+			filter.MinScore = &minScore
+		}
+		if !cmd.Flags().Changed("columns") {
+			switch {
+			case byScore:
+				columnsValue = scoreListColumns
+			case firstSeen:
+				columnsValue = firstSeenListColumns
+			}
 		}
 
-		// Set unreadOnly based on --all flag
+		// Set unreadOnly based on --all flag; unread listings put
+		// high-priority feeds first
 		if !all {
 			unreadOnly := true
 			filter.UnreadOnly = &unreadOnly
+			filter.ByPriority = true
 		}
 
 		if feedFilter != "" && category != "" {
@@ -46,10 +126,10 @@ var listCmd = &cobra.Command{
 
 		if feedFilter != "" {
 			// Try exact URL match first
-			feed, err := store.GetFeedByURL(feedFilter)
+			feed, err := store.GetFeedByURL(ctx, feedFilter)
 			if err != nil {
 				// Try prefix match
-				feed, err = store.GetFeedByPrefix(feedFilter)
+				feed, err = store.GetFeedByPrefix(ctx, feedFilter)
 				if err != nil {
 					return fmt.Errorf("failed to find feed: %w", err)
 				}
@@ -66,7 +146,7 @@ var listCmd = &cobra.Command{
 
 			// Get feed IDs from storage
 			for _, opmlFeed := range categoryFeeds {
-				storageFeed, err := store.GetFeedByURL(opmlFeed.URL)
+				storageFeed, err := store.GetFeedByURL(ctx, opmlFeed.URL)
 				if err != nil {
 					continue // Skip feeds not in storage
 				}
@@ -78,70 +158,283 @@ var listCmd = &cobra.Command{
 			}
 		}
 
+		if label != "" {
+			labelFeeds := opmlDoc.FeedsWithLabel(label)
+			if len(labelFeeds) == 0 {
+				return fmt.Errorf("no feeds are labelled %q", label)
+			}
+			for _, opmlFeed := range labelFeeds {
+				storageFeed, err := store.GetFeedByURL(ctx, opmlFeed.URL)
+				if err != nil {
+					continue // Skip feeds not in storage
+				}
+				filter.FeedIDs = append(filter.FeedIDs, storageFeed.ID)
+			}
+			if len(filter.FeedIDs) == 0 {
+				return fmt.Errorf("no synced feeds are labelled %q", label)
+			}
+		}
+
 		// Calculate date filters based on smart view flags
+		loc, err := userLocation()
+		if err != nil {
+			return err
+		}
 		if today {
-			s := timeutil.StartOfToday()
+			s := timeutil.StartOfTodayIn(loc)
 			filter.Since = &s
 		} else if yesterday {
-			s := timeutil.StartOfYesterday()
-			u := timeutil.EndOfYesterday()
+			s := timeutil.StartOfYesterdayIn(loc)
+			u := timeutil.StartOfTodayIn(loc)
 			filter.Since = &s
 			filter.Until = &u
 		} else if week {
-			s := timeutil.StartOfWeek()
+			s := timeutil.StartOfWeekIn(loc)
 			filter.Since = &s
+		} else if err := applySince(filter, since); err != nil {
+			return err
+		}
+
+		// Author matching is fuzzy, so it can't be pushed into the store query;
+		// fetch everything that passes the other filters and page afterwards
+		var authorQueries []string
+		if authorQuery != "" {
+			authorQueries = append(authorQueries, authorQuery)
+		}
+		if followed {
+			following, err := loadFollows()
+			if err != nil {
+				return err
+			}
+			if len(following) == 0 {
+				return fmt.Errorf("not following any authors; use 'digest authors follow <name>'")
+			}
+			authorQueries = append(authorQueries, following...)
+		}
+		if len(authorQueries) > 0 {
+			filter.Limit = nil
+			filter.Offset = nil
 		}
 
 		// List entries
-		entries, err := store.ListEntries(filter)
+		entries, err := store.ListEntries(ctx, filter)
 		if err != nil {
 			return fmt.Errorf("failed to list entries: %w", err)
 		}
+		if len(authorQueries) > 0 {
+			entries = filterByAuthor(entries, authorQueries)
+			entries = entries[min(offset, len(entries)):]
+			if limit > 0 && len(entries) > limit {
+				entries = entries[:limit]
+			}
+		}
+
+		switch mode {
+		case outputQuiet:
+			for _, entry := range entries {
+				fmt.Fprintln(cmd.OutOrStdout(), entry.ID)
+			}
+			if markRead {
+				_, err := markListedRead(ctx, entries)
+				return err
+			}
+			return nil
+		case outputPorcelain:
+			for _, entry := range entries {
+				link := ""
+				if entry.Link != nil {
+					link = *entry.Link
+				}
+				title := ""
+				if entry.Title != nil {
+					title = *entry.Title
+				}
+				writePorcelain(cmd.OutOrStdout(), entry.ID, porcelainBool(entry.Read),
+					porcelainTime(entry.PublishedAt), entry.FeedID, link, title)
+			}
+			if markRead {
+				_, err := markListedRead(ctx, entries)
+				return err
+			}
+			return nil
+		}
 
 		if len(entries) == 0 {
 			fmt.Println("No entries found")
 			return nil
 		}
 
-		// Create color functions
-		faint := color.New(color.Faint).SprintFunc()
+		columns, err := parseListColumns(columnsValue)
+		if err != nil {
+			return err
+		}
+		feedNames := map[string]string{}
+		if slices.Contains(columns, "feed") {
+			feeds, err := store.ListFeeds(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list feeds: %w", err)
+			}
+			for _, feed := range feeds {
+				feedNames[feed.ID] = feed.GetDisplayName()
+			}
+		}
 
-		// Display entries
+		now := time.Now().In(loc)
 		for _, entry := range entries {
-			// ID (first 8 chars, faint) - with bounds check for safety
-			idShort := entry.ID
-			if len(idShort) > 8 {
-				idShort = idShort[:8]
+			fmt.Println(formatListLine(entry, columns, feedNames, now, absolute))
+		}
+
+		if markRead {
+			marked, err := markListedRead(ctx, entries)
+			if err != nil {
+				return err
+			}
+			if marked > 0 {
+				fmt.Println(color.New(color.Faint).Sprintf("\nMarked %d entries as read", marked))
 			}
-			fmt.Print(faint(idShort))
-			fmt.Print(" ")
+		}
+
+		return nil
+	},
+}
+
+// markListedRead marks the unread entries among those listed as read,
+// except those kept unread, returning how many it marked.
+func markListedRead(ctx context.Context, entries []*models.Entry) (int, error) {
+	marked := 0
+	for _, entry := range entries {
+		if entry.Read || entry.KeepUnread {
+			continue
+		}
+		if err := store.MarkEntryRead(ctx, entry.ID); err != nil {
+			return marked, fmt.Errorf("failed to mark entry as read: %w", err)
+		}
+		marked++
+	}
+	return marked, nil
+}
+
+// shortID returns the first 8 characters of an ID, enough to look it up by prefix.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// listColumnNames are the columns 'digest list' can show.
+var listColumnNames = []string{"id", "status", "title", "date", "seen", "feed", "author", "link", "score", "comments"}
+
+// defaultListColumns is what 'digest list' shows without --columns.
+const defaultListColumns = "id,status,title,date"
+
+// firstSeenListColumns is what 'digest list --first-seen' shows without --columns.
+const firstSeenListColumns = "id,status,title,seen"
 
-			// Read status (checkmark or space)
+// scoreListColumns is what 'digest list --by-score' shows without --columns.
+const scoreListColumns = "id,status,score,comments,title"
+
+// parseListColumns splits a --columns value and checks each name.
+func parseListColumns(value string) ([]string, error) {
+	var columns []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(listColumnNames, name) {
+			return nil, fmt.Errorf("unknown column %q: use %s", name, strings.Join(listColumnNames, ", "))
+		}
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("--columns needs at least one of: %s", strings.Join(listColumnNames, ", "))
+	}
+	return columns, nil
+}
+
+// formatListLine renders one entry as the chosen columns separated by
+// spaces. Empty optional columns are left out; the status, score, and
+// comments columns are fixed width so titles line up.
+func formatListLine(entry *models.Entry, columns []string, feedNames map[string]string, now time.Time, absolute bool) string {
+	faint := color.New(color.Faint).SprintFunc()
+
+	parts := make([]string, 0, len(columns))
+	for _, column := range columns {
+		switch column {
+		case "id":
+			parts = append(parts, faint(shortID(entry.ID)))
+		case "status":
+			switch {
+			case entry.Read:
+				parts = append(parts, "v")
+			case entry.KeepUnread:
+				parts = append(parts, "k")
+			default:
+				parts = append(parts, " ")
+			}
+		case "title":
 			if entry.Read {
-				fmt.Print("v ")
+				parts = append(parts, entry.GetTitle())
 			} else {
-				fmt.Print("  ")
+				parts = append(parts, tui.UnreadStyle.Render(entry.GetTitle()))
 			}
-
-			// Title
-			title := "Untitled"
-			if entry.Title != nil {
-				title = *entry.Title
+		case "date":
+			if entry.PublishedAt == nil {
+				continue
 			}
-			fmt.Print(title)
-
-			// Published date (RFC822 format, faint)
-			if entry.PublishedAt != nil {
-				dateStr := entry.PublishedAt.Format("02 Jan 06 15:04 MST")
-				fmt.Print(" ")
-				fmt.Print(faint(dateStr))
+			parts = append(parts, faint(formatListTime(*entry.PublishedAt, now, absolute)))
+		case "seen":
+			parts = append(parts, faint(formatListTime(entry.CreatedAt, now, absolute)))
+		case "feed":
+			if name := feedNames[entry.FeedID]; name != "" {
+				parts = append(parts, faint(name))
 			}
-
-			fmt.Println()
+		case "author":
+			if entry.Author != nil && *entry.Author != "" {
+				parts = append(parts, *entry.Author)
+			}
+		case "link":
+			if entry.Link != nil && *entry.Link != "" {
+				parts = append(parts, faint(*entry.Link))
+			}
+		case "score":
+			value := "-"
+			if entry.Score != nil {
+				value = strconv.Itoa(*entry.Score)
+			}
+			parts = append(parts, fmt.Sprintf("%5s", value))
+		case "comments":
+			value := "-"
+			if entry.CommentCount != nil {
+				value = strconv.Itoa(*entry.CommentCount) + "c"
+			}
+			parts = append(parts, faint(fmt.Sprintf("%5s", value)))
 		}
+	}
+	return strings.Join(parts, " ")
+}
 
-		return nil
-	},
+// formatListTime renders t relative to now, or in full when absolute.
+func formatListTime(t, now time.Time, absolute bool) string {
+	if absolute {
+		return t.In(now.Location()).Format("02 Jan 06 15:04 MST")
+	}
+	return timeutil.Ago(t, now)
+}
+
+// filterByAuthor keeps the entries with an author matching any of the queries.
+func filterByAuthor(entries []*models.Entry, queries []string) []*models.Entry {
+	var kept []*models.Entry
+	for _, entry := range entries {
+		for _, q := range queries {
+			if authors.MatchEntry(q, entry) {
+				kept = append(kept, entry)
+				break
+			}
+		}
+	}
+	return kept
 }
 
 func init() {
@@ -150,12 +443,33 @@ func init() {
 	listCmd.Flags().BoolP("all", "a", false, "show all entries including read")
 	listCmd.Flags().StringP("feed", "f", "", "filter by feed URL or prefix")
 	listCmd.Flags().StringP("category", "c", "", "filter by feed category/folder")
+	listCmd.Flags().StringP("label", "l", "", "filter by feed label")
 	listCmd.Flags().IntP("limit", "n", 20, "max entries to show")
 	listCmd.Flags().IntP("offset", "o", 0, "number of entries to skip (for pagination)")
 	listCmd.Flags().Bool("today", false, "show only today's entries")
 	listCmd.Flags().Bool("yesterday", false, "show only yesterday's entries")
 	listCmd.Flags().Bool("week", false, "show only this week's entries")
+	listCmd.Flags().String("since", "", "show entries since a date such as '3 days ago', or a range from..until")
+	listCmd.Flags().Bool("absolute", false, "show full timestamps instead of relative times like '2h ago'")
+	listCmd.Flags().String("columns", defaultListColumns, "comma-separated columns to show: "+strings.Join(listColumnNames, ", "))
+	listCmd.Flags().String("author", "", "filter by author name (fuzzy)")
+	listCmd.Flags().Bool("followed", false, "show only entries by followed authors")
+	listCmd.Flags().Bool("first-seen", false, "sort and filter by when entries were first fetched instead of published")
+	listCmd.Flags().Bool("new", false, "show only entries brought in by each feed's latest sync")
+	listCmd.Flags().Bool("by-score", false, "sort Hacker News and Lobsters posts by current points")
+	listCmd.Flags().Int("min-score", 0, "show only aggregator posts with at least this many points")
+	listCmd.Flags().Bool("kept", false, "show only entries kept unread with 'digest keep-unread'")
+	listCmd.Flags().Bool("mark-read", false, "mark the listed entries as read")
+	listCmd.Flags().Bool("no-mark", false, "don't mark the listed entries as read, even with list_marks_read set")
+	addOutputFlags(listCmd, "print only entry IDs")
+	_ = listCmd.RegisterFlagCompletionFunc("feed", feedURLFlag)
+	_ = listCmd.RegisterFlagCompletionFunc("category", folderFlag)
+	_ = listCmd.RegisterFlagCompletionFunc("label", labelFlag)
+	_ = listCmd.RegisterFlagCompletionFunc("columns", cobra.FixedCompletions(listColumnNames, cobra.ShellCompDirectiveNoFileComp))
+	_ = listCmd.RegisterFlagCompletionFunc("since", cobra.FixedCompletions(sinceCompletions, cobra.ShellCompDirectiveNoFileComp))
 
-	listCmd.MarkFlagsMutuallyExclusive("today", "yesterday", "week")
-	listCmd.MarkFlagsMutuallyExclusive("feed", "category")
+	listCmd.MarkFlagsMutuallyExclusive("today", "yesterday", "week", "since")
+	listCmd.MarkFlagsMutuallyExclusive("feed", "category", "label")
+	listCmd.MarkFlagsMutuallyExclusive("by-score", "first-seen")
+	listCmd.MarkFlagsMutuallyExclusive("mark-read", "no-mark")
 }
@@ -0,0 +1,83 @@
+// ABOUTME: Tests for the list command's column selection and date display
+// ABOUTME: Verifies column parsing and how each column renders for an entry
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestParseListColumns(t *testing.T) {
+	columns, err := parseListColumns(" Title, date ,feed,")
+	if err != nil {
+		t.Fatalf("parseListColumns: %v", err)
+	}
+	if len(columns) != 3 || columns[0] != "title" || columns[1] != "date" || columns[2] != "feed" {
+		t.Errorf("unexpected columns %v", columns)
+	}
+	for _, bad := range []string{"", ",", "title,rating"} {
+		if _, err := parseListColumns(bad); err == nil {
+			t.Errorf("expected parseListColumns(%q) to fail", bad)
+		}
+	}
+}
+
+func TestFormatListLine(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	now := time.Date(2025, 3, 12, 15, 30, 0, 0, time.UTC)
+	published := now.Add(-2 * time.Hour)
+	author := "Jane Doe"
+	entry := models.NewEntry("feed-1", "guid", "Hello World")
+	entry.ID = "abcdef1234567890"
+	entry.PublishedAt = &published
+	entry.Author = &author
+	feedNames := map[string]string{"feed-1": "Example Blog"}
+
+	columns, _ := parseListColumns(defaultListColumns)
+	if got, want := formatListLine(entry, columns, feedNames, now, false), "abcdef12   Hello World 2h ago"; got != want {
+		t.Errorf("default columns = %q, want %q", got, want)
+	}
+
+	entry.KeepUnread = true
+	if got, want := formatListLine(entry, columns, feedNames, now, false), "abcdef12 k Hello World 2h ago"; got != want {
+		t.Errorf("kept unread = %q, want %q", got, want)
+	}
+
+	entry.KeepUnread = false
+	entry.MarkRead()
+	if got, want := formatListLine(entry, columns, feedNames, now, true), "abcdef12 v Hello World 12 Mar 25 13:30 UTC"; got != want {
+		t.Errorf("absolute dates = %q, want %q", got, want)
+	}
+
+	columns, _ = parseListColumns("date,feed,author,title,link")
+	if got, want := formatListLine(entry, columns, feedNames, now, false), "2h ago Example Blog Jane Doe Hello World"; got != want {
+		t.Errorf("chosen columns = %q, want %q", got, want)
+	}
+
+	// A backfilled post: published long ago, first seen minutes ago
+	old := now.AddDate(-3, 0, 0)
+	entry.PublishedAt = &old
+	entry.CreatedAt = now.Add(-5 * time.Minute)
+	columns, _ = parseListColumns(firstSeenListColumns)
+	if got, want := formatListLine(entry, columns, feedNames, now, false), "abcdef12 v Hello World 5m ago"; got != want {
+		t.Errorf("first seen columns = %q, want %q", got, want)
+	}
+
+	columns, _ = parseListColumns(scoreListColumns)
+	if got, want := formatListLine(entry, columns, feedNames, now, false), "abcdef12 v     -     - Hello World"; got != want {
+		t.Errorf("unscored columns = %q, want %q", got, want)
+	}
+	points, comments := 318, 97
+	entry.Score, entry.CommentCount = &points, &comments
+	if got, want := formatListLine(entry, columns, feedNames, now, false), "abcdef12 v   318   97c Hello World"; got != want {
+		t.Errorf("score columns = %q, want %q", got, want)
+	}
+}
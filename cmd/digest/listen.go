@@ -0,0 +1,314 @@
+// ABOUTME: Listen command that turns recent entries into a spoken audio briefing
+// ABOUTME: Builds a script from titles and leads, synthesizes it via the configured TTS backend, and can publish a podcast feed
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/feedout"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/stories"
+	"github.com/harper/digest/internal/tts"
+)
+
+// leadWords is roughly how much of each article is read out after its title.
+const leadWords = 60
+
+// podcastFeedName is the RSS file written next to the episodes in --podcast mode.
+const podcastFeedName = "podcast.xml"
+
+var listenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Generate an audio briefing of recent entries",
+	Long: `Read recent entries aloud: builds a short script of each entry's feed,
+title, and opening lines, then synthesizes it with the text-to-speech
+backend configured under "tts" in config.json. When several feeds carry
+the same story under a near-identical title, it's read once with every
+feed named.
+
+Backends:
+  command  pipe the script to a local program, e.g. piper or espeak-ng
+  openai   call an OpenAI-compatible /v1/audio/speech endpoint (writes MP3)
+
+With --podcast, the audio is saved into a directory along with a
+podcast.xml RSS feed listing every briefing there, so a podcast app can
+subscribe to it.
+
+Examples:
+  digest listen --since today
+  digest listen --since week --category Tech -o tech.mp3
+  digest listen --script            # print the script without synthesizing
+  digest listen --podcast ~/Podcasts/digest --base-url https://example.com/digest/`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		since, _ := cmd.Flags().GetString("since")
+		feedFilter, _ := cmd.Flags().GetString("feed")
+		category, _ := cmd.Flags().GetString("category")
+		all, _ := cmd.Flags().GetBool("all")
+		limit, _ := cmd.Flags().GetInt("limit")
+		output, _ := cmd.Flags().GetString("output")
+		podcastDir, _ := cmd.Flags().GetString("podcast")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		scriptOnly, _ := cmd.Flags().GetBool("script")
+
+		filter := &storage.EntryFilter{Limit: &limit}
+		if err := applySince(filter, since); err != nil {
+			return err
+		}
+		if !all {
+			unreadOnly := true
+			filter.UnreadOnly = &unreadOnly
+		}
+		if feedFilter != "" && category != "" {
+			return fmt.Errorf("cannot use --feed and --category together")
+		}
+		if feedFilter != "" {
+			feed, err := store.GetFeedByURL(ctx, feedFilter)
+			if err != nil {
+				feed, err = store.GetFeedByPrefix(ctx, feedFilter)
+				if err != nil {
+					return fmt.Errorf("failed to find feed: %w", err)
+				}
+			}
+			filter.FeedID = &feed.ID
+		}
+		if category != "" {
+			for _, opmlFeed := range opmlDoc.FeedsInFolder(category) {
+				if feed, err := store.GetFeedByURL(ctx, opmlFeed.URL); err == nil {
+					filter.FeedIDs = append(filter.FeedIDs, feed.ID)
+				}
+			}
+			if len(filter.FeedIDs) == 0 {
+				return fmt.Errorf("no synced feeds found in category %q", category)
+			}
+		}
+
+		entries, err := store.ListEntries(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No entries to read")
+			return nil
+		}
+
+		feeds, err := store.ListFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list feeds: %w", err)
+		}
+		feedNames := make(map[string]string, len(feeds))
+		for _, feed := range feeds {
+			feedNames[feed.ID] = feed.GetDisplayName()
+		}
+
+		now := time.Now()
+		script := buildBriefing(now, entries, feedNames)
+		if scriptOnly {
+			fmt.Fprintln(cmd.OutOrStdout(), script)
+			return nil
+		}
+
+		ttsCfg := cfg.GetTTS()
+		synth, err := tts.New(ttsCfg)
+		if err != nil {
+			return err
+		}
+
+		episodeName := "digest-" + now.Format("2006-01-02") + ".mp3"
+		if podcastDir != "" {
+			podcastDir = config.ExpandPath(podcastDir)
+			if err := os.MkdirAll(podcastDir, 0755); err != nil {
+				return fmt.Errorf("failed to create podcast directory: %w", err)
+			}
+			output = filepath.Join(podcastDir, episodeName)
+		} else if output == "" {
+			output = episodeName
+		}
+
+		faint := color.New(color.Faint).SprintFunc()
+		fmt.Printf("Synthesizing %d entries %s\n", len(entries), faint("("+ttsCfg.Backend+")"))
+		if err := synth.Synthesize(ctx, script, output); err != nil {
+			return err
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Wrote briefing to %s\n", green("v"), output)
+
+		if podcastDir != "" {
+			feedPath, err := writePodcastFeed(podcastDir, baseURL)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s Updated podcast feed %s\n", green("v"), feedPath)
+		}
+		return nil
+	},
+}
+
+// buildBriefing writes the spoken script: an intro, then each entry's feed,
+// title, and lead, then a sign-off. A story several feeds covered is read
+// once, naming all of them.
+func buildBriefing(now time.Time, entries []*models.Entry, feedNames map[string]string) string {
+	groups := stories.Group(entries)
+	var b strings.Builder
+	noun := "articles"
+	if len(groups) == 1 {
+		noun = "article"
+	}
+	fmt.Fprintf(&b, "Here is your digest for %s. %d %s.\n\n", now.Format("Monday, January 2"), len(groups), noun)
+
+	for _, group := range groups {
+		entry := group[0]
+		title := "Untitled"
+		if entry.Title != nil && *entry.Title != "" {
+			title = *entry.Title
+		}
+		sources := make([]string, len(group))
+		for i, e := range group {
+			sources[i] = feedNames[e.FeedID]
+		}
+		line := fmt.Sprintf("From %s: %s.", spokenList(sources), strings.TrimRight(title, ".!? "))
+		if entry.Content != nil {
+			if lead := leadText(*entry.Content, leadWords); lead != "" {
+				line += " " + lead
+			}
+		}
+		b.WriteString(line)
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("That's everything for now.")
+	return b.String()
+}
+
+// spokenList joins names the way they're said aloud: "A", "A and B",
+// "A, B, and C".
+func spokenList(names []string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+}
+
+// leadText returns roughly the first n words of an article as plain text,
+// cut back to the last full sentence when there is one.
+func leadText(raw string, n int) string {
+	words := strings.Fields(content.ToText(raw))
+	if len(words) == 0 {
+		return ""
+	}
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+	lead := strings.Join(words[:n], " ")
+	if i := strings.LastIndexAny(lead, ".!?"); i > len(lead)/2 {
+		return lead[:i+1]
+	}
+	return lead + "..."
+}
+
+// podcastChannel lists every briefing in dir as a podcast channel, newest
+// first. Enclosure URLs are baseURL plus the file name, or file:// URLs when
+// no base URL is given.
+func podcastChannel(dir, baseURL string) (feedout.Channel, error) {
+	ch := feedout.Channel{
+		Title:       "digest briefings",
+		Link:        baseURL,
+		Description: "Audio briefings generated by digest",
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "digest-*.mp3"))
+	if err != nil {
+		return ch, fmt.Errorf("failed to list briefings: %w", err)
+	}
+	// Newest first; the date in the name sorts lexically
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return ch, fmt.Errorf("failed to resolve podcast directory: %w", err)
+	}
+
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(path)
+		url := "file://" + filepath.Join(absDir, name)
+		if baseURL != "" {
+			url = strings.TrimRight(baseURL, "/") + "/" + name
+		}
+		date := strings.TrimSuffix(strings.TrimPrefix(name, "digest-"), ".mp3")
+		title := "Briefing " + date
+		if d, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+			title = "Briefing for " + d.Format("Monday, January 2, 2006")
+		}
+		ch.Items = append(ch.Items, feedout.Item{
+			Title:     title,
+			GUID:      name,
+			Published: info.ModTime(),
+			Enclosure: &feedout.Enclosure{URL: url, Length: info.Size(), Type: "audio/mpeg"},
+		})
+	}
+	return ch, nil
+}
+
+// writePodcastFeed regenerates podcast.xml for the briefings in dir and
+// returns its path.
+func writePodcastFeed(dir, baseURL string) (string, error) {
+	ch, err := podcastChannel(dir, baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	feedPath := filepath.Join(dir, podcastFeedName)
+	f, err := os.Create(feedPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create podcast feed: %w", err)
+	}
+	if err := feedout.Write(f, ch); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write podcast feed: %w", err)
+	}
+	return feedPath, nil
+}
+
+func init() {
+	rootCmd.AddCommand(listenCmd)
+
+	listenCmd.Flags().String("since", "today", "include entries since a date such as today, week, '3 days ago', or a range from..until")
+	listenCmd.Flags().StringP("feed", "f", "", "only entries from this feed URL or prefix")
+	listenCmd.Flags().StringP("category", "c", "", "only entries from this folder")
+	listenCmd.Flags().BoolP("all", "a", false, "include entries already read")
+	listenCmd.Flags().IntP("limit", "n", 20, "max entries to include")
+	listenCmd.Flags().StringP("output", "o", "", "audio file to write (default digest-YYYY-MM-DD.mp3)")
+	listenCmd.Flags().String("podcast", "", "save into this directory and update its podcast.xml feed")
+	listenCmd.Flags().String("base-url", "", "URL the podcast directory is served from, for enclosure links")
+	listenCmd.Flags().Bool("script", false, "print the briefing script instead of synthesizing audio")
+	_ = listenCmd.RegisterFlagCompletionFunc("feed", feedURLFlag)
+	_ = listenCmd.RegisterFlagCompletionFunc("category", folderFlag)
+	_ = listenCmd.RegisterFlagCompletionFunc("since", cobra.FixedCompletions(sinceCompletions, cobra.ShellCompDirectiveNoFileComp))
+	listenCmd.MarkFlagsMutuallyExclusive("output", "podcast")
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Tests for the listen command
+// ABOUTME: Covers briefing script construction and podcast feed generation
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestListenCommand(t *testing.T) {
+	for _, name := range []string{"since", "feed", "category", "output", "podcast", "base-url", "script"} {
+		if listenCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to exist", name)
+		}
+	}
+}
+
+func TestBuildBriefing(t *testing.T) {
+	title := "Go 1.30 released!"
+	repost := "go 1.30 released"
+	body := "<p>The Go team shipped a release. It has generics improvements and faster builds.</p>"
+	entries := []*models.Entry{
+		{FeedID: "f1", Title: &title, Content: &body},
+		{FeedID: "f2"},
+		{FeedID: "f3", Title: &repost},
+		{FeedID: "f4", Title: &repost},
+	}
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.Local)
+
+	script := buildBriefing(now, entries, map[string]string{"f1": "Go Blog", "f2": "Other", "f3": "Hacker News", "f4": "Lobsters"})
+
+	for _, want := range []string{
+		"Here is your digest for Thursday, October 15. 2 articles.",
+		"From Go Blog, Hacker News, and Lobsters: Go 1.30 released. The Go team shipped a release.",
+		"From Other: Untitled.",
+		"That's everything for now.",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestLeadText(t *testing.T) {
+	long := strings.Repeat("Short sentence here. ", 30)
+	lead := leadText(long, 10)
+	if !strings.HasSuffix(lead, ".") || len(strings.Fields(lead)) > 10 {
+		t.Errorf("expected lead cut at a sentence within 10 words, got %q", lead)
+	}
+	if got := leadText("", 10); got != "" {
+		t.Errorf("expected empty lead for empty content, got %q", got)
+	}
+}
+
+func TestWritePodcastFeed(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"digest-2026-10-14.mp3", "digest-2026-10-15.mp3", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("audio"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	feedPath, err := writePodcastFeed(dir, "https://example.com/digest/")
+	if err != nil {
+		t.Fatalf("writePodcastFeed: %v", err)
+	}
+	data, err := os.ReadFile(feedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err := gofeed.NewParser().ParseString(string(data))
+	if err != nil {
+		t.Fatalf("podcast feed doesn't parse: %v", err)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("expected 2 episodes, got %d", len(feed.Items))
+	}
+	if feed.Items[0].Title != "Briefing for Thursday, October 15, 2026" {
+		t.Errorf("expected newest episode first, got %q", feed.Items[0].Title)
+	}
+	if url := feed.Items[0].Enclosures[0].URL; url != "https://example.com/digest/digest-2026-10-15.mp3" {
+		t.Errorf("unexpected enclosure URL %q", url)
+	}
+}
@@ -1,16 +1,48 @@
 // ABOUTME: Entry point for digest CLI
-// ABOUTME: Initializes and executes root command
+// ABOUTME: Initializes and executes root command and maps errors to exit codes
 
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 )
 
+// Exit codes. Anything other than these is a bug.
+const (
+	exitOK             = 0   // Success
+	exitError          = 1   // Usage, config, or storage error
+	exitPartialFailure = 2   // Sync finished but some feeds failed
+	exitTotalFailure   = 3   // Sync finished and every attempted feed failed
+	exitLocked         = 4   // Another sync holds the run lock
+	exitInterrupted    = 130 // Stopped by Ctrl-C or SIGTERM
+)
+
+// codedError carries a specific process exit code up to main.
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// exitCode returns the process exit code for an error returned by Execute.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return exitError
+}
+
 func main() {
 	if err := Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,139 @@
+// ABOUTME: Maintenance commands for storage upkeep after bulk imports or prunes
+// ABOUTME: reindex rebuilds and optimizes the search index; compact vacuums, trims journals, and drops stale caches
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/snapshot"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/thumbnail"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Storage maintenance tasks",
+	Long:  "Rebuild indexes and reclaim disk space for the active profile",
+}
+
+var maintenanceReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the search index and reclaim space",
+	Long: `Rebuild the full-text search index from scratch, optimize it, and vacuum
+the database to reclaim free pages. Useful after bulk imports, prunes, or
+if search results look wrong.
+
+The markdown backend searches files directly and has no index to rebuild.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, ok := store.(storage.Reindexer)
+		if !ok {
+			fmt.Printf("The %s backend has no search index; nothing to rebuild\n", cfg.GetBackend())
+			return nil
+		}
+
+		result, err := r.Reindex(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to reindex: %w", err)
+		}
+
+		fmt.Printf("Rebuilt search index for %d entries\n", result.Entries)
+		fmt.Printf("Size: %s -> %s (%s reclaimed)\n",
+			formatBytes(result.BytesBefore), formatBytes(result.BytesAfter), formatBytes(result.Reclaimed()))
+		return nil
+	},
+}
+
+var maintenanceCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Reclaim space in the store",
+	Long: `Vacuum the SQLite database, or for the markdown backend in sync-safe mode
+drop this machine's read state journal events that later changes have
+superseded. Other machines' journals are left for them to compact.
+
+Cached lead images of entries that no longer exist, such as pruned
+entries or those of removed feeds, are deleted too, as are the raw
+response snapshots of removed feeds.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := store.Compact(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to compact: %w", err)
+		}
+		fmt.Println("Compacted storage")
+
+		removed, err := pruneThumbnails(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			fmt.Printf("Removed %d cached thumbnail(s) of deleted entries\n", removed)
+		}
+
+		removed, err = pruneSnapshots(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			fmt.Printf("Removed the snapshots of %d deleted feed(s)\n", removed)
+		}
+		return nil
+	},
+}
+
+// pruneThumbnails deletes cached lead images whose entries are gone.
+func pruneThumbnails(ctx context.Context) (int, error) {
+	dir, err := thumbnailDir()
+	if err != nil {
+		return 0, err
+	}
+	entries, err := store.ListEntries(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list entries: %w", err)
+	}
+	ids := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		ids[e.ID] = true
+	}
+	return thumbnail.Prune(dir, func(id string) bool { return ids[id] })
+}
+
+// pruneSnapshots deletes raw response snapshots whose feeds are gone.
+func pruneSnapshots(ctx context.Context) (int, error) {
+	dir, err := snapshotDir()
+	if err != nil {
+		return 0, err
+	}
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	ids := make(map[string]bool, len(feeds))
+	for _, f := range feeds {
+		ids[f.ID] = true
+	}
+	return snapshot.Prune(dir, func(id string) bool { return ids[id] })
+}
+
+// formatBytes renders a byte count with a binary unit suffix, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+	maintenanceCmd.AddCommand(maintenanceReindexCmd)
+	maintenanceCmd.AddCommand(maintenanceCompactCmd)
+}
@@ -5,19 +5,21 @@ package main
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/spf13/cobra"
-
-	"github.com/harper/digest/internal/timeutil"
 )
 
 var markReadCmd = &cobra.Command{
 	Use:   "mark-read [entry-id]",
 	Short: "Mark entries as read",
-	Long:  "Mark a single entry as read by ID, or use --before to mark all entries older than a date",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Mark a single entry as read by ID, or use --before to mark all entries
+older than a date.
+
+--before skips entries kept unread with 'digest keep-unread'; marking one
+read by ID releases it.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		before, _ := cmd.Flags().GetString("before")
 
 		// Single entry mode
@@ -29,21 +31,27 @@ var markReadCmd = &cobra.Command{
 			entryRef := args[0]
 
 			// Get entry by ID or prefix
-			entry, err := store.GetEntry(entryRef)
+			entry, err := store.GetEntry(ctx, entryRef)
 			if err != nil {
 				// Try prefix match
-				entry, err = store.GetEntryByPrefix(entryRef)
+				entry, err = store.GetEntryByPrefix(ctx, entryRef)
 				if err != nil {
 					return fmt.Errorf("entry not found: %s", entryRef)
 				}
 			}
 
-			if entry.Read {
+			if entry.Read && !entry.KeepUnread {
 				fmt.Println("Entry is already marked as read")
 				return nil
 			}
 
-			if err := store.MarkEntryRead(entry.ID); err != nil {
+			// Marking a kept entry read by hand releases it
+			if entry.KeepUnread {
+				if err := store.SetEntryKeepUnread(ctx, entry.ID, false); err != nil {
+					return fmt.Errorf("failed to release entry: %w", err)
+				}
+			}
+			if err := store.MarkEntryRead(ctx, entry.ID); err != nil {
 				return fmt.Errorf("failed to mark entry as read: %w", err)
 			}
 
@@ -61,18 +69,13 @@ var markReadCmd = &cobra.Command{
 		}
 
 		// Parse the period
-		cutoff, ok := timeutil.ParsePeriod(before)
-		if !ok {
-			// Try parsing as ISO date
-			parsed, err := time.Parse("2006-01-02", before)
-			if err != nil {
-				return fmt.Errorf("invalid period %q: use yesterday, week, month, or YYYY-MM-DD", before)
-			}
-			cutoff = parsed
+		cutoff, err := parseDateFlag("before", before)
+		if err != nil {
+			return err
 		}
 
 		// Mark entries as read
-		count, err := store.MarkEntriesReadBefore(cutoff)
+		count, err := store.MarkEntriesReadBefore(ctx, cutoff)
 		if err != nil {
 			return fmt.Errorf("failed to mark entries as read: %w", err)
 		}
@@ -90,5 +93,6 @@ var markReadCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(markReadCmd)
 
-	markReadCmd.Flags().StringP("before", "b", "", "mark entries older than: yesterday, week, month, or YYYY-MM-DD")
+	markReadCmd.Flags().StringP("before", "b", "", "mark entries older than: yesterday, week, month, YYYY-MM-DD, or a relative date like '2 weeks'")
+	markReadCmd.ValidArgsFunction = entryIDArgs(true)
 }
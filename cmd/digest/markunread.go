@@ -15,13 +15,14 @@ var markUnreadCmd = &cobra.Command{
 	Long:  "Mark a single entry as unread by ID",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		entryRef := args[0]
 
 		// Get entry by ID or prefix
-		entry, err := store.GetEntry(entryRef)
+		entry, err := store.GetEntry(ctx, entryRef)
 		if err != nil {
 			// Try prefix match
-			entry, err = store.GetEntryByPrefix(entryRef)
+			entry, err = store.GetEntryByPrefix(ctx, entryRef)
 			if err != nil {
 				return fmt.Errorf("entry not found: %s", entryRef)
 			}
@@ -32,7 +33,7 @@ var markUnreadCmd = &cobra.Command{
 			return nil
 		}
 
-		if err := store.MarkEntryUnread(entry.ID); err != nil {
+		if err := store.MarkEntryUnread(ctx, entry.ID); err != nil {
 			return fmt.Errorf("failed to mark entry as unread: %w", err)
 		}
 
@@ -47,4 +48,5 @@ var markUnreadCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(markUnreadCmd)
+	markUnreadCmd.ValidArgsFunction = entryIDArgs(false)
 }
@@ -21,17 +21,51 @@ query entries, manage subscriptions, and more through structured tools.
 
 The server communicates via JSON-RPC on stdin/stdout.
 Supports --profile / -p to set the default profile for the session.
-All tools accept an optional "profile" parameter to target a different profile per call.`,
+All tools accept an optional "profile" parameter to target a different profile per call.
+
+Use --folder and --feed to expose only part of your subscriptions, for
+example to a shared agent. Feeds outside the scope are hidden from every
+tool and resource, and feeds can't be added or moved outside it.
+
+Use --read-only to register only the query tools (list_feeds, get_feed,
+list_entries, get_entry, list_profiles) for untrusted agents, or when
+another process owns writes.
+
+Use --user to serve a team user (see 'digest team'): tools see and change
+that user's own read state, and a reader gets the query and marking tools
+but none that add, remove, edit, or sync feeds.
+
+Examples:
+  digest mcp --read-only
+  digest mcp --user alice
+  digest mcp --folder Public
+  digest mcp --folder Public --feed https://example.com/feed.xml`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		folders, _ := cmd.Flags().GetStringArray("folder")
+		feeds, _ := cmd.Flags().GetStringArray("feed")
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+
+		opts := []mcp.Option{mcp.WithScope(mcp.Scope{Folders: folders, Feeds: feeds})}
+		if readOnly {
+			opts = append(opts, mcp.WithReadOnly())
+		}
+		if user, _ := cmd.Flags().GetString("user"); user != "" {
+			t := cfg.GetTeam()
+			if !t.Has(user) {
+				return fmt.Errorf("%s is not on the team; add them with 'digest team add %s'", user, user)
+			}
+			opts = append(opts, mcp.WithTeamUser(user, t.RoleOf(user)))
+		}
+
 		// Create MCP server with config and default profile
-		server, err := mcp.NewServer(cfg, profileName)
+		server, err := mcp.NewServer(cfg, profileName, opts...)
 		if err != nil {
 			return fmt.Errorf("failed to create MCP server: %w", err)
 		}
 		defer server.Close()
 
 		// Start serving on stdio
-		if err := server.ServeStdio(); err != nil {
+		if err := server.ServeStdio(cmd.Context()); err != nil {
 			return fmt.Errorf("MCP server error: %w", err)
 		}
 
@@ -41,4 +75,10 @@ All tools accept an optional "profile" parameter to target a different profile p
 
 func init() {
 	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.Flags().Bool("read-only", false, "only register query tools; reject all mutations")
+	mcpCmd.Flags().StringArray("folder", nil, "only expose feeds in this folder (repeatable)")
+	mcpCmd.Flags().StringArray("feed", nil, "only expose this feed URL (repeatable)")
+	mcpCmd.Flags().String("user", "", "serve this team user's read state, with their role")
+	_ = mcpCmd.RegisterFlagCompletionFunc("folder", folderFlag)
+	_ = mcpCmd.RegisterFlagCompletionFunc("feed", feedURLFlag)
 }
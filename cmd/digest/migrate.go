@@ -50,6 +50,9 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	if err := cfg.ApplyProfile(profileName); err != nil {
+		return err
+	}
 
 	sourceBackend := cfg.GetBackend()
 	targetBackend := migrateTo
@@ -106,7 +109,7 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 
 	// Run migration
-	summary, err := storage.MigrateData(src, dst)
+	summary, err := storage.MigrateData(cmd.Context(), src, dst)
 	if err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
@@ -123,6 +126,9 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 		fmt.Printf(" and \"data_dir\": %q", migrateDataDir)
 	}
 	fmt.Println()
+	if path, err := cfg.ProfileConfigPath(profileName); err == nil {
+		fmt.Printf("  (or set \"backend\" in %s to switch only this profile)\n", path)
+	}
 
 	return nil
 }
@@ -0,0 +1,187 @@
+// ABOUTME: Next command serving unread entries one at a time, like working through an inbox
+// ABOUTME: Picks the next entry in the configured queue order, shows it, and marks it read
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/queue"
+	"github.com/harper/digest/internal/storage"
+)
+
+var nextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Read the next unread article",
+	Long: `Show the next unread article and mark it read, so running 'digest next'
+over and over works through the unread entries until none are left.
+
+--order picks which entry comes next:
+  oldest       the longest-waiting entry (default)
+  score        the most Hacker News or Lobsters points, then the oldest
+  round-robin  the oldest entry of the feed read from least recently,
+               so one busy feed can't crowd out the rest
+
+Whatever the order, entries of high-priority feeds come first and those of
+low-priority feeds last (see 'digest feed priority').
+
+Set "next_order" in config.json to change the default. Entries kept unread
+are skipped. --no-mark shows the next entry without taking it off the queue.
+The article is shown as 'digest read' shows it, with the same flags.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		noMark, _ := cmd.Flags().GetBool("no-mark")
+		q, err := newReadingQueue(cmd)
+		if err != nil {
+			return err
+		}
+		entry, unread, err := q.next(cmd.Context(), nil)
+		if err != nil {
+			return err
+		}
+
+		faint := color.New(color.Faint).SprintFunc()
+		if entry == nil {
+			fmt.Println("Nothing left to read.")
+			return nil
+		}
+		if err := showEntry(cmd, entry); err != nil {
+			return err
+		}
+
+		left := queued(unread)
+		if !noMark {
+			left--
+		}
+		fmt.Println(faint(fmt.Sprintf("%d unread left in the queue", left)))
+		return nil
+	},
+}
+
+// readingQueue is the unread entries 'digest next' and 'digest session'
+// serve, as chosen by the flags addQueueFlags adds.
+type readingQueue struct {
+	filter storage.EntryFilter
+	order  string
+}
+
+// newReadingQueue returns the reading queue the flags of cmd pick out.
+func newReadingQueue(cmd *cobra.Command) (*readingQueue, error) {
+	ctx := cmd.Context()
+	order, _ := cmd.Flags().GetString("order")
+	feedFilter, _ := cmd.Flags().GetString("feed")
+	category, _ := cmd.Flags().GetString("category")
+
+	if order == "" {
+		configured, err := cfg.GetNextOrder()
+		if err != nil {
+			return nil, err
+		}
+		order = configured
+	} else if err := queue.ValidateOrder(order); err != nil {
+		return nil, err
+	}
+
+	filter, err := feedScopeFilter(ctx, feedFilter, category)
+	if err != nil {
+		return nil, err
+	}
+	return &readingQueue{filter: filter, order: order}, nil
+}
+
+// feedScopeFilter returns a filter keeping the entries of one feed (URL or
+// ID prefix) or of the feeds in a category, when either is given.
+func feedScopeFilter(ctx context.Context, feedFilter, category string) (storage.EntryFilter, error) {
+	var filter storage.EntryFilter
+	if feedFilter != "" {
+		feed, err := store.GetFeedByURLOrPrefix(ctx, feedFilter)
+		if err != nil {
+			return filter, fmt.Errorf("failed to find feed: %w", err)
+		}
+		filter.FeedID = &feed.ID
+	}
+	if category != "" {
+		for _, opmlFeed := range opmlDoc.FeedsInFolder(category) {
+			if feed, err := store.GetFeedByURL(ctx, opmlFeed.URL); err == nil {
+				filter.FeedIDs = append(filter.FeedIDs, feed.ID)
+			}
+		}
+		if len(filter.FeedIDs) == 0 {
+			return filter, fmt.Errorf("no synced feeds found in category %q", category)
+		}
+	}
+	return filter, nil
+}
+
+// next returns the entry to read next, or nil if the queue is empty, along
+// with the unread entries of the queue. Entries in skip, and those snoozed
+// in triage, are passed over.
+func (q *readingQueue) next(ctx context.Context, skip map[string]bool) (*models.Entry, []*models.Entry, error) {
+	snoozes, err := loadSnoozes()
+	if err != nil {
+		return nil, nil, err
+	}
+	snoozed := snoozes.Active(time.Now())
+
+	filter := q.filter
+	var lastRead map[string]time.Time
+	if q.order == queue.RoundRobin {
+		all, err := store.ListEntries(ctx, &filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list entries: %w", err)
+		}
+		lastRead = queue.LastRead(all)
+	}
+	unreadOnly := true
+	filter.UnreadOnly = &unreadOnly
+	unread, err := store.ListEntries(ctx, &filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	candidates := make([]*models.Entry, 0, len(unread))
+	for _, e := range unread {
+		if !skip[e.ID] && !snoozed[e.ID] {
+			candidates = append(candidates, e)
+		}
+	}
+	return queue.Next(candidates, lastRead, queue.Priorities(feeds), q.order), unread, nil
+}
+
+// queued counts the entries 'digest next' would serve.
+func queued(unread []*models.Entry) int {
+	n := 0
+	for _, e := range unread {
+		if !e.Read && !e.KeepUnread {
+			n++
+		}
+	}
+	return n
+}
+
+// addQueueFlags adds the flags newReadingQueue reads.
+func addQueueFlags(cmd *cobra.Command) {
+	cmd.Flags().String("order", "", "queue order: "+strings.Join(queue.Orders, ", ")+" (default from config, else oldest)")
+	cmd.Flags().StringP("feed", "f", "", "only read entries of this feed (URL or ID prefix)")
+	cmd.Flags().StringP("category", "c", "", "only read entries of feeds in this category/folder")
+	_ = cmd.RegisterFlagCompletionFunc("order", cobra.FixedCompletions(queue.Orders, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func init() {
+	rootCmd.AddCommand(nextCmd)
+
+	addQueueFlags(nextCmd)
+	addReadFlags(nextCmd)
+}
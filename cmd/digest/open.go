@@ -1,62 +1,140 @@
-// ABOUTME: Open command for launching entry links in browser
-// ABOUTME: Opens the entry's link and marks the entry as read
+// ABOUTME: Open command for launching entry links or feed websites in the browser
+// ABOUTME: Opens one or more entries' links and marks them read unless configured otherwise
 
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os/exec"
 	"runtime"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
 )
 
 var openCmd = &cobra.Command{
-	Use:   "open <entry-prefix>",
-	Short: "Open entry link in browser and mark as read",
-	Long:  "Open an entry's link in your default browser and mark the entry as read by providing its ID prefix (minimum 6 characters)",
-	Args:  cobra.ExactArgs(1),
+	Use:   "open <entry-prefix>...",
+	Short: "Open entry links in browser and mark as read",
+	Long: `Open entries' links in your default browser and mark the entries as read,
+by ID prefix. Several entries can be opened at once; all are looked up
+before any is opened.
+
+Opening marks entries read unless --no-mark is given or "open_marks_read"
+is false in config.json.
+
+With --feed, the arguments are feeds (URL or ID prefix) and each feed's
+website is opened instead: the home page of its latest entry's site, or of
+the feed URL when it has no entries.
+
+Examples:
+  digest open abc123
+  digest open abc123 def456 --no-mark
+  digest open --feed https://example.com/feed.xml`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get entry by prefix
-		entry, err := store.GetEntryByPrefix(args[0])
-		if err != nil {
-			return fmt.Errorf("failed to find entry: %w", err)
+		ctx := cmd.Context()
+		feedMode, _ := cmd.Flags().GetBool("feed")
+		noMark, _ := cmd.Flags().GetBool("no-mark")
+		green := color.New(color.FgGreen).SprintFunc()
+
+		if feedMode {
+			for _, ref := range args {
+				feed, err := store.GetFeedByURLOrPrefix(ctx, ref)
+				if err != nil {
+					return fmt.Errorf("failed to find feed %s: %w", ref, err)
+				}
+				home, err := feedHomePage(ctx, store, feed)
+				if err != nil {
+					return err
+				}
+				if err := openBrowser(home); err != nil {
+					return fmt.Errorf("failed to open browser: %w", err)
+				}
+				fmt.Printf("%s Opened %s\n", green("v"), home)
+			}
+			return nil
 		}
 
-		// Check that link is not nil/empty
-		if entry.Link == nil || *entry.Link == "" {
-			return fmt.Errorf("entry has no link")
+		// Resolve every entry first so a typo doesn't leave half the tabs open
+		entries := make([]*models.Entry, 0, len(args))
+		links := make([]string, 0, len(args))
+		for _, ref := range args {
+			entry, err := store.GetEntryByPrefix(ctx, ref)
+			if err != nil {
+				return fmt.Errorf("failed to find entry %s: %w", ref, err)
+			}
+			link, err := entryBrowserLink(entry)
+			if err != nil {
+				return fmt.Errorf("entry %s: %w", ref, err)
+			}
+			entries = append(entries, entry)
+			links = append(links, link)
 		}
 
-		// Validate URL format and scheme for security
-		parsedURL, err := url.Parse(*entry.Link)
-		if err != nil {
-			return fmt.Errorf("entry has malformed link: %w", err)
-		}
-		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-			return fmt.Errorf("entry link must be http or https, got: %s", parsedURL.Scheme)
+		markRead := cfg.GetOpenMarksRead() && !noMark
+		for i, entry := range entries {
+			if err := openBrowser(links[i]); err != nil {
+				return fmt.Errorf("failed to open browser: %w", err)
+			}
+			if !markRead || entry.KeepUnread {
+				fmt.Printf("%s Opened: %s\n", green("v"), entry.GetTitle())
+				continue
+			}
+			if err := store.MarkEntryRead(ctx, entry.ID); err != nil {
+				return fmt.Errorf("failed to mark entry as read: %w", err)
+			}
+			fmt.Printf("%s Opened and marked as read: %s\n", green("v"), entry.GetTitle())
 		}
 
-		// Open browser with validated URL
-		if err := openBrowser(parsedURL.String()); err != nil {
-			return fmt.Errorf("failed to open browser: %w", err)
-		}
+		return nil
+	},
+}
 
-		// Mark as read
-		if err := store.MarkEntryRead(entry.ID); err != nil {
-			return fmt.Errorf("failed to mark entry as read: %w", err)
-		}
+// entryBrowserLink returns the entry's link after checking it is safe to
+// hand to the browser.
+func entryBrowserLink(entry *models.Entry) (string, error) {
+	// Check that link is not nil/empty
+	if entry.Link == nil || *entry.Link == "" {
+		return "", fmt.Errorf("entry has no link")
+	}
 
-		// Print confirmation with title
-		title := "Untitled"
-		if entry.Title != nil {
-			title = *entry.Title
+	// Validate URL format and scheme for security
+	parsedURL, err := url.Parse(*entry.Link)
+	if err != nil {
+		return "", fmt.Errorf("entry has malformed link: %w", err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return "", fmt.Errorf("entry link must be http or https, got: %s", parsedURL.Scheme)
+	}
+	return parsedURL.String(), nil
+}
+
+// feedHomePage guesses a feed's website. Feeds are often served from a
+// different host than the site (a CDN or feed proxy), so the latest entry's
+// link is preferred over the feed URL.
+func feedHomePage(ctx context.Context, s storage.Store, feed *models.Feed) (string, error) {
+	limit := 1
+	base := feed.URL
+	entries, err := s.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID, Limit: &limit})
+	if err != nil {
+		return "", fmt.Errorf("failed to list entries: %w", err)
+	}
+	if len(entries) > 0 {
+		if link, err := entryBrowserLink(entries[0]); err == nil {
+			base = link
 		}
-		fmt.Printf("v Opened and marked as read: %s\n", title)
+	}
 
-		return nil
-	},
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("cannot find a website for feed %s", feed.URL)
+	}
+	return u.Scheme + "://" + u.Host + "/", nil
 }
 
 // openBrowser opens a URL in the default browser for the current platform
@@ -87,4 +165,8 @@ func openBrowser(urlStr string) error {
 
 func init() {
 	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().Bool("no-mark", false, "don't mark the entries as read")
+	openCmd.Flags().Bool("feed", false, "open the websites of feeds (by URL or ID prefix) instead of entries")
+	openCmd.MarkFlagsMutuallyExclusive("feed", "no-mark")
+	openCmd.ValidArgsFunction = openArgs
 }
@@ -0,0 +1,60 @@
+// ABOUTME: Tests for the open command's link checks and feed website lookup
+// ABOUTME: Verifies unsafe links are refused and feed home pages prefer the entries' site
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestEntryBrowserLink(t *testing.T) {
+	entry := models.NewEntry("feed", "guid", "Title")
+	if _, err := entryBrowserLink(entry); err == nil {
+		t.Error("expected an error for an entry without a link")
+	}
+	for _, bad := range []string{"javascript:alert(1)", "file:///etc/passwd"} {
+		entry.Link = &bad
+		if _, err := entryBrowserLink(entry); err == nil {
+			t.Errorf("expected %q to be refused", bad)
+		}
+	}
+	good := "https://example.com/post"
+	entry.Link = &good
+	if link, err := entryBrowserLink(entry); err != nil || link != good {
+		t.Errorf("entryBrowserLink = %q, %v", link, err)
+	}
+}
+
+func TestFeedHomePage(t *testing.T) {
+	s, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	feed := models.NewFeed("https://feeds.example.net/blog/rss")
+	if err := s.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	home, err := feedHomePage(ctx, s, feed)
+	if err != nil || home != "https://feeds.example.net/" {
+		t.Errorf("expected the feed host without entries, got %q (%v)", home, err)
+	}
+
+	entry := models.NewEntry(feed.ID, "guid", "Post")
+	link := "https://blog.example.com/2025/03/post.html"
+	entry.Link = &link
+	if err := s.CreateEntry(ctx, entry); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+	home, err = feedHomePage(ctx, s, feed)
+	if err != nil || home != "https://blog.example.com/" {
+		t.Errorf("expected the entries' site, got %q (%v)", home, err)
+	}
+}
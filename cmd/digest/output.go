@@ -0,0 +1,68 @@
+// ABOUTME: Output mode flags shared by scriptable commands (list, fetch, stats)
+// ABOUTME: Porcelain output is stable, tab-separated, and uncolored for shell pipelines
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// outputMode selects how a command reports its results.
+type outputMode int
+
+const (
+	outputNormal    outputMode = iota // Human-readable, colored output
+	outputQuiet                       // Minimal output; only what a script needs
+	outputPorcelain                   // Stable tab-separated records
+)
+
+// addOutputFlags registers --quiet and --porcelain on cmd.
+func addOutputFlags(cmd *cobra.Command, quietHelp string) {
+	cmd.Flags().BoolP("quiet", "q", false, quietHelp)
+	cmd.Flags().Bool("porcelain", false, "stable tab-separated output for scripts")
+	cmd.MarkFlagsMutuallyExclusive("quiet", "porcelain")
+}
+
+// getOutputMode reads the output mode selected by addOutputFlags.
+func getOutputMode(cmd *cobra.Command) outputMode {
+	if porcelain, _ := cmd.Flags().GetBool("porcelain"); porcelain {
+		return outputPorcelain
+	}
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		return outputQuiet
+	}
+	return outputNormal
+}
+
+// porcelainReplacer flattens characters that would break a tab-separated record.
+var porcelainReplacer = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// writePorcelain writes one tab-separated record. Field order is part of the
+// porcelain contract: new fields may be appended, existing ones never move.
+func writePorcelain(w io.Writer, fields ...string) {
+	for i, f := range fields {
+		fields[i] = porcelainReplacer.Replace(f)
+	}
+	fmt.Fprintln(w, strings.Join(fields, "\t"))
+}
+
+// porcelainTime formats an optional timestamp as RFC 3339 in UTC, or "" if unset.
+func porcelainTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// porcelainBool formats a flag as "1" or "0".
+func porcelainBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
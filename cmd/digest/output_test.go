@@ -0,0 +1,47 @@
+// ABOUTME: Tests for the shared --quiet/--porcelain output helpers
+// ABOUTME: Verifies record escaping, timestamp formatting, and flag wiring
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestWritePorcelainFlattensSeparators(t *testing.T) {
+	var buf bytes.Buffer
+	writePorcelain(&buf, "abc", "a\ttitle\nwith breaks", "")
+
+	want := "abc\ta title with breaks\t\n"
+	if buf.String() != want {
+		t.Errorf("writePorcelain() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPorcelainTime(t *testing.T) {
+	if got := porcelainTime(nil); got != "" {
+		t.Errorf("expected empty string for nil time, got %q", got)
+	}
+
+	ts := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	if got := porcelainTime(&ts); got != "2024-03-01T14:30:00Z" {
+		t.Errorf("expected UTC RFC 3339, got %q", got)
+	}
+}
+
+func TestOutputFlags(t *testing.T) {
+	for _, cmd := range []*cobra.Command{listCmd, fetchCmd, statsCmd} {
+		if cmd.Flags().Lookup("quiet") == nil {
+			t.Errorf("expected %q to have --quiet", cmd.Name())
+		}
+		if cmd.Flags().Lookup("porcelain") == nil {
+			t.Errorf("expected %q to have --porcelain", cmd.Name())
+		}
+		if mode := getOutputMode(cmd); mode != outputNormal {
+			t.Errorf("expected %q to default to normal output, got %v", cmd.Name(), mode)
+		}
+	}
+}
@@ -1,22 +1,34 @@
 // ABOUTME: Profile management commands for isolated feed collections
-// ABOUTME: Handles listing and removing named profiles
+// ABOUTME: Handles creating, listing, switching, and removing named profiles
 
 package main
 
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/opml"
 )
 
 var profileCmd = &cobra.Command{
 	Use:   "profile",
 	Short: "Manage feed profiles",
-	Long:  "List and manage isolated feed collection profiles",
+	Long: `Create, list, switch, and remove isolated feed collection profiles.
+
+Each profile has its own storage, OPML file, and icon cache under
+<data-dir>/<profile>. Pick one per command with --profile, or make it the
+default with 'digest profile switch'.
+
+Settings in <data-dir>/<profile>/config.json override the shared
+config.json for that profile, setting by setting, so a work profile can use
+its own backend, timezone, or alerts. data_dir and default_profile are
+always shared. Commands that change settings, such as 'digest team add',
+save them to the active profile's file.`,
 }
 
 var profileListCmd = &cobra.Command{
@@ -72,6 +84,58 @@ var profileListCmd = &cobra.Command{
 	},
 }
 
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new empty profile",
+	Long:  "Create a profile with its own empty storage and OPML file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		if err := config.ValidateProfileName(name); err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		profileDir, err := cfg.ProfileDataDir(name)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(profileDir); err == nil {
+			return fmt.Errorf("profile %q already exists", name)
+		}
+
+		// Opening storage creates the profile directory and initializes the backend
+		s, err := cfg.OpenProfileStorage(name)
+		if err != nil {
+			return fmt.Errorf("failed to initialize storage: %w", err)
+		}
+		if err := s.Close(); err != nil {
+			return fmt.Errorf("failed to close storage: %w", err)
+		}
+
+		doc := opml.NewDocument("digest feeds")
+		if err := doc.WriteFile(filepath.Join(profileDir, "feeds.opml")); err != nil {
+			return fmt.Errorf("failed to write OPML: %w", err)
+		}
+
+		fmt.Printf("Created profile: %s\n", name)
+
+		if switchTo, _ := cmd.Flags().GetBool("switch"); switchTo {
+			cfg.DefaultProfile = name
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			fmt.Printf("Default profile set to %q\n", name)
+		}
+		return nil
+	},
+}
+
 var profileRemoveCmd = &cobra.Command{
 	Use:   "remove <name>",
 	Short: "Remove a profile and all its data",
@@ -125,10 +189,11 @@ var profileRemoveCmd = &cobra.Command{
 }
 
 var profileSetDefaultCmd = &cobra.Command{
-	Use:   "set-default <name>",
-	Short: "Set the default profile",
-	Long:  "Set which profile is used when --profile is not specified",
-	Args:  cobra.ExactArgs(1),
+	Use:     "set-default <name>",
+	Aliases: []string{"switch"},
+	Short:   "Set the default profile",
+	Long:    "Set which profile is used when --profile is not specified",
+	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
@@ -162,9 +227,14 @@ var profileSetDefaultCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileCreateCmd)
 	profileCmd.AddCommand(profileListCmd)
 	profileCmd.AddCommand(profileRemoveCmd)
 	profileCmd.AddCommand(profileSetDefaultCmd)
 
+	profileCreateCmd.Flags().Bool("switch", false, "make the new profile the default")
 	profileRemoveCmd.Flags().BoolP("yes", "y", false, "skip confirmation prompt")
+
+	profileRemoveCmd.ValidArgsFunction = profileArgs
+	profileSetDefaultCmd.ValidArgsFunction = profileArgs
 }
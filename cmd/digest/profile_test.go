@@ -1,10 +1,15 @@
 // ABOUTME: Tests for profile management commands
-// ABOUTME: Verifies profile list and remove command structure and flags
+// ABOUTME: Verifies profile command structure, and that create sets up storage, OPML, and the default
 
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/opml"
 )
 
 func TestProfileCommand(t *testing.T) {
@@ -22,8 +27,91 @@ func TestProfileListCommand(t *testing.T) {
 	}
 }
 
+func TestProfileCreateCommand(t *testing.T) {
+	if profileCreateCmd.Use != "create <name>" {
+		t.Errorf("expected Use to be 'create <name>', got %q", profileCreateCmd.Use)
+	}
+	if profileCreateCmd.Flags().Lookup("switch") == nil {
+		t.Error("expected profile create command to have a --switch flag")
+	}
+}
+
+func TestProfileSwitchAlias(t *testing.T) {
+	cmd, _, err := profileCmd.Find([]string{"switch"})
+	if err != nil {
+		t.Fatalf("expected 'profile switch' to resolve: %v", err)
+	}
+	if cmd != profileSetDefaultCmd {
+		t.Errorf("expected 'profile switch' to be set-default, got %q", cmd.Name())
+	}
+}
+
 func TestProfileRemoveCommand(t *testing.T) {
 	if profileRemoveCmd.Use != "remove <name>" {
 		t.Errorf("expected Use to be 'remove <name>', got %q", profileRemoveCmd.Use)
 	}
 }
+
+// profileTestConfig points config.json and the data directory at temp
+// directories and returns the data directory.
+func profileTestConfig(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dataDir := t.TempDir()
+	if err := (&config.Config{Backend: "sqlite", DataDir: dataDir}).Save(); err != nil {
+		t.Fatal(err)
+	}
+	return dataDir
+}
+
+func TestProfileCreate(t *testing.T) {
+	dataDir := profileTestConfig(t)
+
+	if err := profileCreateCmd.RunE(profileCreateCmd, []string{"work"}); err != nil {
+		t.Fatalf("profile create: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "work", "digest.db")); err != nil {
+		t.Errorf("expected the profile's database: %v", err)
+	}
+	doc, err := opml.ParseFile(filepath.Join(dataDir, "work", "feeds.opml"))
+	if err != nil {
+		t.Fatalf("expected the profile's OPML file: %v", err)
+	}
+	if len(doc.AllFeeds()) != 0 {
+		t.Errorf("expected an empty OPML file, got %d feeds", len(doc.AllFeeds()))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GetDefaultProfile() == "work" {
+		t.Error("expected create without --switch to leave the default alone")
+	}
+
+	if err := profileCreateCmd.RunE(profileCreateCmd, []string{"work"}); err == nil {
+		t.Error("expected creating an existing profile to fail")
+	}
+	if err := profileCreateCmd.RunE(profileCreateCmd, []string{"../escape"}); err == nil {
+		t.Error("expected an invalid profile name to fail")
+	}
+}
+
+func TestProfileCreateSwitch(t *testing.T) {
+	profileTestConfig(t)
+	if err := profileCreateCmd.Flags().Set("switch", "true"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = profileCreateCmd.Flags().Set("switch", "false") })
+
+	if err := profileCreateCmd.RunE(profileCreateCmd, []string{"personal"}); err != nil {
+		t.Fatalf("profile create --switch: %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.GetDefaultProfile(); got != "personal" {
+		t.Errorf("expected --switch to save personal as the default, got %q", got)
+	}
+}
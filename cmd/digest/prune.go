@@ -0,0 +1,162 @@
+// ABOUTME: Prune command that trims feeds to their max_entries retention limit
+// ABOUTME: Shows per-feed counts and the space to be reclaimed, and with --dry-run deletes nothing
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune [url-or-id]",
+	Short: "Delete entries beyond each feed's max_entries",
+	Long: `Delete the oldest entries of feeds that have more than their max_entries
+(set with the MCP update_feed tool). Syncs prune each synced feed the same
+way; this prunes every feed, or just the one given, at once.
+Entries kept unread are never pruned.
+
+Before deleting, prune lists each feed with how many entries go and the
+space they take: markdown files for the markdown backend, rows and search
+index rows for SQLite, whose file shrinks only after 'digest maintenance
+compact'. --dry-run stops there, and --verbose names every entry.
+
+--quiet prints only the number of entries pruned.
+--porcelain prints one tab-separated record per feed:
+  feed, url, entries, files, fts_rows, bytes
+an "entry" record per entry with --verbose:
+  entry, id, feed_url, title
+then a final summary record:
+  summary, feeds, entries, files, fts_rows, bytes`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		mode := getOutputMode(cmd)
+		out := cmd.OutOrStdout()
+
+		var feeds []*models.Feed
+		if len(args) == 1 {
+			feed, err := store.GetFeedByURLOrPrefix(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to find feed: %w", err)
+			}
+			feeds = []*models.Feed{feed}
+		} else {
+			var err error
+			if feeds, err = store.ListFeeds(ctx); err != nil {
+				return fmt.Errorf("failed to list feeds: %w", err)
+			}
+		}
+
+		// Don't delete entries out from under a running sync
+		if !dryRun {
+			lock, err := acquireSyncLock(cmd, 0)
+			if err != nil {
+				return err
+			}
+			defer lock.Release()
+		}
+
+		plan, err := feedsync.PlanPrune(ctx, store, feeds)
+		if err != nil {
+			return err
+		}
+		if sizer, ok := store.(storage.Footprinter); ok {
+			if err := plan.Measure(ctx, sizer); err != nil {
+				return err
+			}
+		}
+
+		deleted := 0
+		if !dryRun {
+			if deleted, err = plan.Apply(ctx, store); err != nil {
+				return err
+			}
+		}
+
+		switch mode {
+		case outputQuiet:
+			fmt.Fprintln(out, plan.Entries)
+		case outputPorcelain:
+			for _, f := range plan.Feeds {
+				writePorcelain(out, "feed", f.URL, strconv.Itoa(f.Entries), strconv.Itoa(f.Files),
+					strconv.Itoa(f.FTSRows), strconv.FormatInt(f.Bytes, 10))
+				if verbose {
+					for _, e := range f.Pruned {
+						writePorcelain(out, "entry", e.ID, f.URL, e.GetTitle())
+					}
+				}
+			}
+			writePorcelain(out, "summary", strconv.Itoa(len(plan.Feeds)), strconv.Itoa(plan.Entries),
+				strconv.Itoa(plan.Files), strconv.Itoa(plan.FTSRows), strconv.FormatInt(plan.Bytes, 10))
+		default:
+			reportPrunePlan(out, plan, verbose, dryRun, deleted)
+		}
+		return nil
+	},
+	ValidArgsFunction: feedURLArgs,
+}
+
+// reportPrunePlan prints a prune plan feed by feed, then what it deleted or
+// would delete.
+func reportPrunePlan(w io.Writer, plan *feedsync.PrunePlan, verbose, dryRun bool, deleted int) {
+	faint := color.New(color.Faint).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	if len(plan.Feeds) == 0 {
+		fmt.Fprintln(w, "Nothing to prune; every feed is within its max_entries")
+		return
+	}
+
+	for _, f := range plan.Feeds {
+		detail := fmt.Sprintf("%d of %d entries over the limit of %d", f.Entries, f.Stored, f.MaxEntries)
+		if plan.Measured {
+			detail += ", " + formatBytes(f.Bytes)
+		}
+		if f.KeptUnread > 0 {
+			detail += fmt.Sprintf("; %d kept unread stay", f.KeptUnread)
+		}
+		fmt.Fprintf(w, "%s %s\n", f.Title, faint("("+detail+")"))
+		if verbose {
+			for _, e := range f.Pruned {
+				fmt.Fprintf(w, "  %s %s\n", shortID(e.ID), e.GetTitle())
+			}
+		}
+	}
+	fmt.Fprintln(w)
+
+	reclaimed := ""
+	if plan.Measured {
+		switch {
+		case plan.Files > 0:
+			reclaimed = fmt.Sprintf(": %d markdown file(s), %s", plan.Files, formatBytes(plan.Bytes))
+		case plan.FTSRows > 0:
+			reclaimed = fmt.Sprintf(" and %d search index row(s): about %s", plan.FTSRows, formatBytes(plan.Bytes))
+		}
+	}
+	if dryRun {
+		fmt.Fprintf(w, "Would delete %d entries from %d feed(s)%s\n", plan.Entries, len(plan.Feeds), reclaimed)
+		fmt.Fprintln(w, faint("Run without --dry-run to delete them."))
+		return
+	}
+	fmt.Fprintf(w, "%s Deleted %d entries from %d feed(s)%s\n", green("v"), deleted, len(plan.Feeds), reclaimed)
+	if plan.FTSRows > 0 {
+		fmt.Fprintln(w, faint("Run 'digest maintenance compact' to return the space to disk."))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().Bool("dry-run", false, "show what would be deleted without deleting anything")
+	pruneCmd.Flags().Bool("verbose", false, "list every entry to be deleted")
+	addOutputFlags(pruneCmd, "print only the number of entries pruned")
+}
@@ -0,0 +1,177 @@
+// ABOUTME: Publish commands that share parts of the reading setup publicly
+// ABOUTME: 'publish blogroll' renders opted-in OPML folders as an OPML file and an HTML page
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/blogroll"
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/favicon"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish parts of your reading setup",
+	Long:  "Render shareable views of your subscriptions, such as a public blogroll.",
+}
+
+var publishBlogrollCmd = &cobra.Command{
+	Use:   "blogroll",
+	Short: "Publish a blogroll of selected folders",
+	Long: `Write a public blogroll: blogroll.opml for feed readers and blogroll.html
+for people, covering only the folders you opt in.
+
+Choose folders with --folder (repeatable), or set them once in config.json
+so 'digest serve' can publish the same blogroll:
+
+  "blogroll": {"title": "What I read", "folders": ["Friends", "Tech"]}
+
+Feeds that use HTTP credentials, embed credentials in their URL, or are
+allowed to reach the local network are never published.
+
+Examples:
+  digest publish blogroll --folder Friends -o ~/site/public
+  digest publish blogroll                     # folders from config.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		folders, _ := cmd.Flags().GetStringArray("folder")
+		title, _ := cmd.Flags().GetString("title")
+		outDir, _ := cmd.Flags().GetString("output")
+
+		settings := cfg.GetBlogroll()
+		if len(folders) == 0 {
+			folders = settings.Folders
+		}
+		if title == "" {
+			title = settings.Title
+		}
+
+		icons, err := iconDir()
+		if err != nil {
+			return err
+		}
+		b, err := buildBlogroll(ctx, store, opmlDoc, title, folders, icons)
+		if err != nil {
+			return err
+		}
+
+		outDir = config.ExpandPath(outDir)
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		opmlPath := filepath.Join(outDir, blogroll.OPMLFile)
+		if err := writeFileWith(opmlPath, func(f *os.File) error { return blogroll.WriteOPML(f, b) }); err != nil {
+			return err
+		}
+		htmlPath := filepath.Join(outDir, blogroll.HTMLFile)
+		if err := writeFileWith(htmlPath, func(f *os.File) error { return blogroll.WriteHTML(f, b, blogroll.OPMLFile) }); err != nil {
+			return err
+		}
+
+		count := 0
+		for _, folder := range b.Folders {
+			count += len(folder.Feeds)
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+		fmt.Printf("%s Published %d feeds from %d folders %s\n", green("v"), count, len(b.Folders), faint("("+title+")"))
+		fmt.Printf("  %s\n  %s\n", opmlPath, htmlPath)
+		return nil
+	},
+}
+
+// buildBlogroll builds the blogroll for folders, leaving out private feeds,
+// with the icons cached in iconDir inlined.
+func buildBlogroll(ctx context.Context, s storage.Store, doc *opml.Document, title string, folders []string, iconDir string) (*blogroll.Blogroll, error) {
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("no folders selected: pass --folder or set \"blogroll\": {\"folders\": [...]} in config.json")
+	}
+	feeds, err := s.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	private := make(map[string]bool)
+	ids := make(map[string]string, len(feeds))
+	for _, feed := range feeds {
+		if privateFeed(feed) {
+			private[feed.URL] = true
+		}
+		ids[feed.URL] = feed.ID
+	}
+	isPrivate := func(feedURL string) bool {
+		if private[feedURL] {
+			return true
+		}
+		u, err := url.Parse(feedURL)
+		return err != nil || u.User != nil
+	}
+	b, err := blogroll.Build(doc, title, folders, isPrivate, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if iconDir == "" {
+		return b, nil
+	}
+	for i := range b.Folders {
+		for j := range b.Folders[i].Feeds {
+			f := &b.Folders[i].Feeds[j]
+			if id, ok := ids[f.FeedURL]; ok {
+				if path := favicon.Path(iconDir, id); path != "" {
+					f.Icon, _ = favicon.DataURL(path)
+				}
+			}
+		}
+	}
+	return b, nil
+}
+
+// privateFeed reports feeds that must never be published: ones behind a
+// login, with credentials in their URL, or on the local network.
+func privateFeed(feed *models.Feed) bool {
+	if feed.AuthUsername != nil || feed.AuthPassword != nil || feed.LocalNetwork {
+		return true
+	}
+	u, err := url.Parse(feed.URL)
+	return err != nil || u.User != nil
+}
+
+// writeFileWith creates path and fills it with write.
+func writeFileWith(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+	publishCmd.AddCommand(publishBlogrollCmd)
+
+	publishBlogrollCmd.Flags().StringArray("folder", nil, "folder to publish (repeatable; default from config)")
+	publishBlogrollCmd.Flags().String("title", "", "blogroll title (default from config, or \"Blogroll\")")
+	publishBlogrollCmd.Flags().StringP("output", "o", ".", "directory to write blogroll.opml and blogroll.html into")
+	_ = publishBlogrollCmd.RegisterFlagCompletionFunc("folder", folderFlag)
+	_ = publishBlogrollCmd.MarkFlagDirname("output")
+}
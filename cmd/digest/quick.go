@@ -0,0 +1,342 @@
+// ABOUTME: Quick-add commands that subscribe to a URL from the clipboard or a digest:// link
+// ABOUTME: Also installs an x-scheme-handler so browsers and share menus can hand URLs to digest
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// handlerDesktopFile is the name of the Linux desktop entry for digest:// links.
+const handlerDesktopFile = "digest-url-handler.desktop"
+
+// handlerAppName is the name of the macOS applet for digest:// links.
+const handlerAppName = "Digest URL Handler.app"
+
+// bookmarklet sends the current page to digest through the URL handler.
+const bookmarklet = "javascript:location.href='digest://add?url='+encodeURIComponent(location.href)"
+
+var clipboardURLPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+var addCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a new RSS/Atom feed (same as 'feed add')",
+	Long: `Add a new feed to your subscriptions. This is a shortcut for 'digest feed add'
+and takes the same flags.
+
+With --from-clipboard the URL is read from the system clipboard instead.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runFeedAdd,
+}
+
+var quickCmd = &cobra.Command{
+	Use:   "quick [url | digest://add?url=...]",
+	Short: "Subscribe to the URL on the clipboard, or one handed over by a digest:// link",
+	Long: `Subscribe without any questions. With no argument the URL is read from the
+clipboard; otherwise the argument is a site or feed URL, or a digest:// link
+such as:
+
+  digest://add?url=https%3A%2F%2Fexample.com&folder=Tech
+
+The feed is discovered as with 'digest feed add'. A near-duplicate of a
+feed you already follow is merged into it rather than added twice.
+
+Run 'digest quick --install-handler' once to register digest as the handler
+for digest:// links (Linux desktops via xdg-mime, macOS via a small applet).
+Browsers then pass such links straight to 'digest quick', so a bookmarklet
+or share shortcut can subscribe to the page you are looking at.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		install, _ := cmd.Flags().GetBool("install-handler")
+		if install {
+			if len(args) > 0 {
+				return fmt.Errorf("--install-handler takes no arguments")
+			}
+			return installURLHandler()
+		}
+
+		var raw string
+		if len(args) == 1 {
+			raw = args[0]
+		} else {
+			u, err := clipboardURL()
+			if err != nil {
+				return err
+			}
+			raw = u
+		}
+
+		target, err := parseQuickTarget(raw)
+		if err != nil {
+			return err
+		}
+		if folder, _ := cmd.Flags().GetString("folder"); folder != "" {
+			target.folder = folder
+		}
+
+		return subscribe(cmd.Context(), target.url, addOptions{
+			folder: target.folder,
+			title:  target.title,
+			yes:    true,
+		})
+	},
+}
+
+// quickTarget is what 'digest quick' should subscribe to.
+type quickTarget struct {
+	url    string
+	folder string
+	title  string
+}
+
+// parseQuickTarget accepts a plain http(s) URL or a digest://add link with
+// url and optional folder and title query parameters.
+func parseQuickTarget(raw string) (quickTarget, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return quickTarget{}, fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return quickTarget{url: raw}, nil
+	case "digest":
+	default:
+		return quickTarget{}, fmt.Errorf("not a web or digest:// URL: %s", raw)
+	}
+
+	// digest://add?url=... parses with "add" as the host; digest:add?url=...
+	// leaves it in the opaque part.
+	action := u.Host
+	if action == "" {
+		action = u.Opaque
+	}
+	action = strings.Trim(action+u.Path, "/")
+	if action != "add" {
+		return quickTarget{}, fmt.Errorf("unsupported digest:// action %q (expected digest://add?url=...)", action)
+	}
+
+	q := u.Query()
+	target := quickTarget{
+		url:    strings.TrimSpace(q.Get("url")),
+		folder: q.Get("folder"),
+		title:  q.Get("title"),
+	}
+	if target.url == "" {
+		return quickTarget{}, fmt.Errorf("digest://add link has no url parameter")
+	}
+	if t, err := url.Parse(target.url); err != nil || (t.Scheme != "http" && t.Scheme != "https") {
+		return quickTarget{}, fmt.Errorf("digest://add url must be an http(s) URL: %s", target.url)
+	}
+	return target, nil
+}
+
+// clipboardURL reads the clipboard and returns the first URL in it.
+func clipboardURL() (string, error) {
+	text, err := readClipboard()
+	if err != nil {
+		return "", err
+	}
+	u := firstURL(text)
+	if u == "" {
+		return "", fmt.Errorf("no URL found on the clipboard")
+	}
+	fmt.Printf("Using URL from clipboard: %s\n", u)
+	return u, nil
+}
+
+// firstURL returns the first http(s) or digest:// URL in text, or "" if
+// there is none.
+func firstURL(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(strings.ToLower(text), "digest:") {
+		return strings.Fields(text)[0]
+	}
+	return strings.TrimRight(clipboardURLPattern.FindString(text), ".,;:!?)")
+}
+
+// readClipboard returns the text on the system clipboard using the
+// platform's clipboard tool.
+func readClipboard() (string, error) {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbpaste"}}
+	case "windows":
+		candidates = [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-paste", "--no-newline"})
+		}
+		candidates = append(candidates,
+			[]string{"xclip", "-selection", "clipboard", "-o"},
+			[]string{"xsel", "--clipboard", "--output"},
+		)
+	default:
+		return "", fmt.Errorf("reading the clipboard is not supported on %s", runtime.GOOS)
+	}
+
+	var tried []string
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			tried = append(tried, c[0])
+			continue
+		}
+		var stderr bytes.Buffer
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read clipboard with %s: %w %s", c[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return string(out), nil
+	}
+	return "", fmt.Errorf("no clipboard tool found (tried %s)", strings.Join(tried, ", "))
+}
+
+// installURLHandler registers digest as the handler for digest:// links.
+func installURLHandler() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the digest executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		err = installMacHandler(exe)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		err = installDesktopHandler(exe)
+	default:
+		return fmt.Errorf("installing a URL handler is not supported on %s", runtime.GOOS)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("Add this bookmarklet to your browser to subscribe to the page you are on:")
+	fmt.Printf("  %s\n", bookmarklet)
+	return nil
+}
+
+// installDesktopHandler writes a desktop entry for x-scheme-handler/digest
+// and makes it the default with xdg-mime.
+func installDesktopHandler(exe string) error {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	dir := filepath.Join(dataHome, "applications")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, handlerDesktopFile)
+	if err := os.WriteFile(path, []byte(desktopEntry(exe)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+
+	if _, err := exec.LookPath("xdg-mime"); err != nil {
+		fmt.Println("xdg-mime not found; register the entry for x-scheme-handler/digest with your desktop manually.")
+		return nil
+	}
+	if out, err := exec.Command("xdg-mime", "default", handlerDesktopFile, "x-scheme-handler/digest").CombinedOutput(); err != nil {
+		return fmt.Errorf("xdg-mime failed: %w %s", err, strings.TrimSpace(string(out)))
+	}
+	fmt.Println("Registered digest:// links with xdg-mime")
+	return nil
+}
+
+// desktopEntry returns the desktop entry that runs 'digest quick' for
+// digest:// links.
+func desktopEntry(exe string) string {
+	return fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Digest
+Comment=Subscribe to feeds with digest
+Exec=%s quick %%u
+Terminal=false
+NoDisplay=true
+MimeType=x-scheme-handler/digest;
+`, desktopExecQuote(exe))
+}
+
+// desktopExecQuote quotes a path for the Exec key of a desktop entry.
+func desktopExecQuote(s string) string {
+	if !strings.ContainsAny(s, " \t\"'\\$`") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// installMacHandler compiles a small AppleScript applet that claims the
+// digest:// scheme and passes links to 'digest quick'.
+func installMacHandler(exe string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+	dir := filepath.Join(home, "Applications")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	app := filepath.Join(dir, handlerAppName)
+	if err := os.RemoveAll(app); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", app, err)
+	}
+
+	script := fmt.Sprintf(`on open location theURL
+	do shell script quoted form of %q & " quick " & quoted form of theURL
+end open location
+`, exe)
+	if out, err := exec.Command("osacompile", "-o", app, "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("osacompile failed: %w %s", err, strings.TrimSpace(string(out)))
+	}
+
+	plist := filepath.Join(app, "Contents", "Info.plist")
+	urlTypes := `[{"CFBundleURLName":"Digest","CFBundleURLSchemes":["digest"]}]`
+	if out, err := exec.Command("plutil", "-insert", "CFBundleURLTypes", "-json", urlTypes, plist).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to register the digest scheme in %s: %w %s", plist, err, strings.TrimSpace(string(out)))
+	}
+	if out, err := exec.Command("plutil", "-replace", "LSUIElement", "-bool", "true", plist).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update %s: %w %s", plist, err, strings.TrimSpace(string(out)))
+	}
+
+	lsregister := "/System/Library/Frameworks/CoreServices.framework/Frameworks/LaunchServices.framework/Support/lsregister"
+	if out, err := exec.Command(lsregister, "-f", app).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to register %s: %w %s", app, err, strings.TrimSpace(string(out)))
+	}
+	fmt.Printf("Installed %s for digest:// links\n", app)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(quickCmd)
+
+	addFlags(addCmd)
+
+	quickCmd.Flags().StringP("folder", "f", "", "folder to organize feed in")
+	quickCmd.Flags().Bool("install-handler", false, "register digest as the handler for digest:// links")
+	_ = quickCmd.RegisterFlagCompletionFunc("folder", folderFlag)
+}
@@ -0,0 +1,80 @@
+// ABOUTME: Tests for quick-add parsing of digest:// links and clipboard text
+// ABOUTME: Verifies URL extraction and the desktop entry written for the scheme handler
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseQuickTarget(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    quickTarget
+		wantErr bool
+	}{
+		{raw: "https://example.com/blog", want: quickTarget{url: "https://example.com/blog"}},
+		{raw: "  http://example.com  ", want: quickTarget{url: "http://example.com"}},
+		{
+			raw:  "digest://add?url=https%3A%2F%2Fexample.com%2Ffeed.xml&folder=Tech&title=Example",
+			want: quickTarget{url: "https://example.com/feed.xml", folder: "Tech", title: "Example"},
+		},
+		{raw: "digest://add/?url=https://example.com", want: quickTarget{url: "https://example.com"}},
+		{raw: "digest:add?url=https://example.com", want: quickTarget{url: "https://example.com"}},
+		{raw: "DIGEST://add?url=https://example.com", want: quickTarget{url: "https://example.com"}},
+		{raw: "digest://remove?url=https://example.com", wantErr: true},
+		{raw: "digest://add", wantErr: true},
+		{raw: "digest://add?url=file:///etc/passwd", wantErr: true},
+		{raw: "ftp://example.com", wantErr: true},
+		{raw: "example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseQuickTarget(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseQuickTarget(%q) = %+v, want error", tt.raw, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseQuickTarget(%q): %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseQuickTarget(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFirstURL(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/post\n":                     "https://example.com/post",
+		"Check this out: https://example.com/a (great).": "https://example.com/a",
+		"digest://add?url=https://example.com":           "digest://add?url=https://example.com",
+		"no links here":                                  "",
+		"":                                               "",
+	}
+	for text, want := range tests {
+		if got := firstURL(text); got != want {
+			t.Errorf("firstURL(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestDesktopEntry(t *testing.T) {
+	entry := desktopEntry("/usr/local/bin/digest")
+	for _, want := range []string{
+		"Exec=/usr/local/bin/digest quick %u\n",
+		"MimeType=x-scheme-handler/digest;\n",
+	} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("desktop entry missing %q:\n%s", want, entry)
+		}
+	}
+
+	entry = desktopEntry("/Users/me/My Tools/digest")
+	if !strings.Contains(entry, `Exec="/Users/me/My Tools/digest" quick %u`) {
+		t.Errorf("expected a quoted Exec path:\n%s", entry)
+	}
+}
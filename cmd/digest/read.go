@@ -1,107 +1,311 @@
 // ABOUTME: Read command for viewing article content
-// ABOUTME: Displays full article details with plain text formatting and marks as read
+// ABOUTME: Displays full article details with terminal-formatted Markdown and marks as read
 
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 
 	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/discussion"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/timeutil"
+	"github.com/harper/digest/internal/tui"
 )
 
+// pickerEntryLimit caps how many entries the interactive picker loads.
+const pickerEntryLimit = 200
+
 var readCmd = &cobra.Command{
-	Use:   "read <entry-id>",
-	Short: "Read an article",
-	Long:  "Display the full content of an article and mark it as read",
-	Args:  cobra.ExactArgs(1),
+	Use:     "read [entry-id]",
+	Aliases: []string{"show"},
+	Short:   "Read an article",
+	Long: `Display the full content of an article and mark it as read.
+
+With no entry ID, opens an interactive fuzzy picker over unread entries.
+
+Content is shown as Markdown by default, formatted for the terminal when
+output is a terminal (--plain prints the Markdown source).
+
+Articles longer than the terminal open in $PAGER, or in a built-in pager
+when PAGER is unset: / searches, n and N step through matches, [ and ]
+select links, o opens the selected link (or the article) in the browser,
+and q quits. --no-pager prints directly. Use --format text
+for plain text, html for the HTML as published, or raw for exactly what the
+feed stored.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		entryRef := args[0]
-		noMark, _ := cmd.Flags().GetBool("no-mark")
+		ctx := cmd.Context()
+		format, _ := cmd.Flags().GetString("format")
+		if _, err := content.Render("", format, cfg.GetContentOptions()); err != nil {
+			return err
+		}
+
+		var entryRef string
+		if len(args) == 1 {
+			entryRef = args[0]
+		} else {
+			ref, err := pickEntry(ctx)
+			if errors.Is(err, tui.ErrPickerCancelled) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			entryRef = ref
+		}
 
 		// Get entry by ID or prefix
-		entry, err := store.GetEntry(entryRef)
+		entry, err := store.GetEntry(ctx, entryRef)
 		if err != nil {
 			// Try prefix match
-			entry, err = store.GetEntryByPrefix(entryRef)
+			entry, err = store.GetEntryByPrefix(ctx, entryRef)
 			if err != nil {
 				return fmt.Errorf("entry not found: %s", entryRef)
 			}
 		}
 
-		// Get feed for context
-		feed, err := store.GetFeed(entry.FeedID)
-		if err != nil {
-			return fmt.Errorf("failed to get feed: %w", err)
-		}
+		return showEntry(cmd, entry)
+	},
+}
 
-		// Color helpers
-		bold := color.New(color.Bold).SprintFunc()
-		faint := color.New(color.Faint).SprintFunc()
-		cyan := color.New(color.FgCyan).SprintFunc()
+// showEntry prints an entry, through a pager if it's long, and marks it
+// read as set by the read flags of cmd (see addReadFlags).
+func showEntry(cmd *cobra.Command, entry *models.Entry) error {
+	ctx := cmd.Context()
+	noMark, _ := cmd.Flags().GetBool("no-mark")
+	format, _ := cmd.Flags().GetString("format")
+	plain, _ := cmd.Flags().GetBool("plain")
+	noPager, _ := cmd.Flags().GetBool("no-pager")
+	opts := cfg.GetContentOptions()
+	if reader, _ := cmd.Flags().GetBool("reader"); reader {
+		opts.ReaderView = true
+	}
 
-		// Display article header
-		fmt.Println(strings.Repeat("-", 60))
+	// Get feed for context
+	feed, err := store.GetFeed(ctx, entry.FeedID)
+	if err != nil {
+		return fmt.Errorf("failed to get feed: %w", err)
+	}
 
-		// Title
-		title := "Untitled"
-		if entry.Title != nil {
-			title = *entry.Title
-		}
-		fmt.Printf("%s\n\n", bold(title))
+	// Color helpers
+	bold := color.New(color.Bold).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
 
-		// Feed
-		feedTitle := feed.URL
-		if feed.Title != nil {
-			feedTitle = *feed.Title
-		}
-		fmt.Printf("%s %s\n", faint("Feed:"), feedTitle)
+	// Build the article so it can go to the terminal or a pager
+	var b strings.Builder
+	b.WriteString(strings.Repeat("-", 60) + "\n")
 
-		// Author
-		if entry.Author != nil && *entry.Author != "" {
-			fmt.Printf("%s %s\n", faint("Author:"), *entry.Author)
+	// Title
+	title := "Untitled"
+	if entry.Title != nil {
+		title = *entry.Title
+	}
+	fmt.Fprintf(&b, "%s\n\n", bold(title))
+
+	// Feed
+	feedTitle := feed.URL
+	if feed.Title != nil {
+		feedTitle = *feed.Title
+	}
+	fmt.Fprintf(&b, "%s %s\n", faint("Feed:"), feedTitle)
+
+	// Author
+	if entry.Author != nil && *entry.Author != "" {
+		fmt.Fprintf(&b, "%s %s\n", faint("Author:"), *entry.Author)
+	}
+
+	// Published date
+	if entry.PublishedAt != nil {
+		fmt.Fprintf(&b, "%s %s", faint("Published:"), entry.PublishedAt.Format("Mon, 02 Jan 2006 15:04 MST"))
+		if entry.ClaimedPublishedAt != nil {
+			fmt.Fprint(&b, " ", faint("(feed claimed "+entry.ClaimedPublishedAt.Format("02 Jan 2006")+")"))
 		}
+		fmt.Fprintln(&b)
+	}
 
-		// Published date
-		if entry.PublishedAt != nil {
-			fmt.Printf("%s %s\n", faint("Published:"), entry.PublishedAt.Format("Mon, 02 Jan 2006 15:04 MST"))
+	// Link
+	if entry.Link != nil {
+		fmt.Fprintf(&b, "%s %s\n", faint("Link:"), cyan(*entry.Link))
+	}
+	if entry.ArchiveURL != nil {
+		fmt.Fprintf(&b, "%s %s\n", faint("Archive:"), cyan(*entry.ArchiveURL))
+	}
+	if entry.ImageURL != nil {
+		fmt.Fprintf(&b, "%s %s\n", faint("Image:"), cyan(*entry.ImageURL))
+	}
+	if thread := discussion.Thread(entry); thread != "" {
+		fmt.Fprintf(&b, "%s %s\n", faint("Discussion:"), cyan(thread))
+	}
+	if entry.Score != nil && entry.CommentCount != nil && entry.ScoredAt != nil {
+		fmt.Fprintf(&b, "%s %d points, %d comments %s\n", faint("Score:"), *entry.Score, *entry.CommentCount,
+			faint("("+timeutil.Ago(*entry.ScoredAt, time.Now())+")"))
+	}
+
+	b.WriteString(strings.Repeat("-", 60) + "\n")
+
+	// Content
+	tty := isatty.IsTerminal(os.Stdout.Fd())
+	width, height, _ := term.GetSize(os.Stdout.Fd())
+	if entry.Content != nil && *entry.Content != "" {
+		rendered, err := content.Render(*entry.Content, format, opts)
+		if err != nil {
+			return err
+		}
+		if format == content.FormatMarkdown && !plain && tty {
+			if styled, err := tui.RenderMarkdown(rendered, min(width, 100)); err == nil {
+				rendered = styled
+			}
 		}
+		fmt.Fprintf(&b, "\n%s\n", rendered)
+	} else {
+		b.WriteString("\n(No content available)\n")
+	}
+	b.WriteString("\n")
 
-		// Link
+	// Page articles taller than the terminal, like less -F
+	article := b.String()
+	if !noPager && tty && isatty.IsTerminal(os.Stdin.Fd()) && strings.Count(article, "\n") >= height {
+		link := ""
 		if entry.Link != nil {
-			fmt.Printf("%s %s\n", faint("Link:"), cyan(*entry.Link))
+			link = *entry.Link
 		}
+		if err := pageText(title, article, link); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(article)
+	}
 
-		fmt.Println(strings.Repeat("-", 60))
+	// Mark as read unless --no-mark flag is set or the entry is kept unread
+	if !noMark && !entry.Read && !entry.KeepUnread {
+		if err := store.MarkEntryRead(ctx, entry.ID); err != nil {
+			return fmt.Errorf("failed to mark entry as read: %w", err)
+		}
+		fmt.Printf("%s\n", faint("Marked as read"))
+	}
 
-		// Content
-		if entry.Content != nil && *entry.Content != "" {
-			// Convert HTML to markdown for plain text display
-			markdown := content.ToMarkdown(*entry.Content)
-			fmt.Printf("\n%s\n", markdown)
-		} else {
-			fmt.Println("\n(No content available)")
+	return nil
+}
+
+// pageText shows text through $PAGER, run by the shell like git does, or
+// the built-in pager when PAGER is unset. link is what the built-in pager
+// opens when no other link is selected.
+func pageText(title, text, link string) error {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		return tui.Page(title, text, link, openHTTPLink)
+	}
+
+	c := exec.Command("sh", "-c", pager)
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", pager)
+	}
+	c.Stdin = strings.NewReader(text)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		// Keep colors and exit at once when the text fits, as git does
+		c.Env = append(c.Env, "LESS=FRX")
+	}
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("pager failed: %w", err)
+	}
+	return nil
+}
+
+// openHTTPLink opens an http or https link in the browser.
+func openHTTPLink(link string) error {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("not a web link: %s", link)
+	}
+	return openBrowser(u.String())
+}
+
+// pickEntry opens the fuzzy picker over unread entries (or recent ones if all are read).
+func pickEntry(ctx context.Context) (string, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return "", fmt.Errorf("entry ID required when not running in a terminal")
+	}
+
+	limit := pickerEntryLimit
+	unreadOnly := true
+	entries, err := store.ListEntries(ctx, &storage.EntryFilter{UnreadOnly: &unreadOnly, Limit: &limit})
+	if err != nil {
+		return "", fmt.Errorf("failed to list entries: %w", err)
+	}
+	if len(entries) == 0 {
+		entries, err = store.ListEntries(ctx, &storage.EntryFilter{Limit: &limit})
+		if err != nil {
+			return "", fmt.Errorf("failed to list entries: %w", err)
 		}
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no entries found. Run 'digest fetch' first")
+	}
 
-		fmt.Println()
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list feeds: %w", err)
+	}
+	feedNames := make(map[string]string, len(feeds))
+	for _, feed := range feeds {
+		feedNames[feed.ID] = feed.GetDisplayName()
+	}
+	feedIcons := feedIconColors(feeds)
 
-		// Mark as read unless --no-mark flag is set
-		if !noMark && !entry.Read {
-			if err := store.MarkEntryRead(entry.ID); err != nil {
-				return fmt.Errorf("failed to mark entry as read: %w", err)
-			}
-			fmt.Printf("%s\n", faint("Marked as read"))
+	items := make([]tui.PickerItem, 0, len(entries))
+	for _, entry := range entries {
+		title := "Untitled"
+		if entry.Title != nil && *entry.Title != "" {
+			title = *entry.Title
 		}
+		detail := feedNames[entry.FeedID]
+		if entry.PublishedAt != nil {
+			detail += " · " + entry.PublishedAt.Format("Jan 02")
+		}
+		items = append(items, tui.PickerItem{ID: entry.ID, Title: title, Detail: detail, Icon: feedIcons[entry.FeedID]})
+	}
 
-		return nil
-	},
+	title := "Read which article?"
+	if overall, err := store.GetOverallStats(ctx); err == nil {
+		if progress, err := readingProgress(ctx, overall.UnreadCount); err == nil {
+			title += "  " + tui.DimStyle.Render(progress.Summary())
+		}
+	}
+	return tui.Pick(title, items)
 }
 
 func init() {
 	rootCmd.AddCommand(readCmd)
 
-	readCmd.Flags().Bool("no-mark", false, "don't mark the article as read")
+	addReadFlags(readCmd)
+	readCmd.ValidArgsFunction = entryIDArgs(false)
+}
+
+// addReadFlags adds the flags showEntry reads.
+func addReadFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-mark", false, "don't mark the article as read")
+	cmd.Flags().Bool("reader", false, "strip page boilerplate such as navigation, ads, and share bars")
+	cmd.Flags().Bool("no-pager", false, "print long articles directly instead of through a pager")
+	cmd.Flags().Bool("plain", false, "print Markdown as-is instead of formatting it for the terminal")
+	cmd.Flags().String("format", content.FormatMarkdown, "content format: "+strings.Join(content.Formats, ", "))
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(content.Formats, cobra.ShellCompDirectiveNoFileComp))
 }
@@ -0,0 +1,128 @@
+// ABOUTME: Reprocess command that re-derives stored entries after parser improvements
+// ABOUTME: Reparses each feed's raw snapshots and updates its entries in place without refetching
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/snapshot"
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+var reprocessCmd = &cobra.Command{
+	Use:   "reprocess",
+	Short: "Update stored entries with the current parser",
+	Long: `Parse each feed's raw response snapshots again with the current parser
+and update the entries they contain in place: title, link, author,
+content, dates, comment links, lead image, and feed extensions. Read
+state, archive links, and scores are kept, and nothing is fetched.
+Snapshots are kept when "feed_snapshots" is set in config.json; entries
+in none of them only get a lead image from their stored content if they
+have none.
+
+Reprocessing never adds entries; 'digest debug replay --import' does.
+
+Examples:
+  digest reprocess --dry-run
+  digest reprocess --feed https://example.com/feed.xml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		feedFilter, _ := cmd.Flags().GetString("feed")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		var feeds []*models.Feed
+		if feedFilter != "" {
+			feed, err := store.GetFeedByURLOrPrefix(ctx, feedFilter)
+			if err != nil {
+				return fmt.Errorf("failed to find feed: %w", err)
+			}
+			feeds = []*models.Feed{feed}
+		} else {
+			var err error
+			if feeds, err = store.ListFeeds(ctx); err != nil {
+				return fmt.Errorf("failed to list feeds: %w", err)
+			}
+		}
+		dates, err := cfg.GetDatePolicy()
+		if err != nil {
+			return err
+		}
+		dir, err := snapshotDir()
+		if err != nil {
+			return err
+		}
+
+		if !dryRun {
+			lock, err := acquireSyncLock(cmd, 0)
+			if err != nil {
+				return err
+			}
+			defer lock.Release()
+		}
+
+		faint := color.New(color.Faint).SprintFunc()
+		updated, failed := 0, 0
+		for _, feed := range feeds {
+			if feed.Monitor {
+				// Monitored pages have no entries to reparse
+				continue
+			}
+			snapshots, err := snapshot.List(dir, feed.ID)
+			if err != nil {
+				return err
+			}
+			// Oldest first, so the newest response has the last word
+			var bodies [][]byte
+			for i := len(snapshots) - 1; i >= 0; i-- {
+				body, err := snapshot.Read(snapshots[i].Path)
+				if err != nil {
+					return err
+				}
+				bodies = append(bodies, body)
+			}
+
+			result, err := feedsync.Reprocess(ctx, store, feed, bodies, dates, !dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to reprocess %s: %w", feed.URL, err)
+			}
+			updated += result.Updated
+			failed += result.Failed
+			if result.Updated == 0 && result.Failed == 0 && feedFilter == "" {
+				continue
+			}
+			detail := fmt.Sprintf("%d snapshot(s)", result.Snapshots)
+			if result.Failed > 0 {
+				detail += fmt.Sprintf(", %d unparseable", result.Failed)
+			}
+			fmt.Printf("%s: %d entries updated %s\n", feedDisplayName(feed), result.Updated, faint("("+detail+")"))
+		}
+
+		switch {
+		case dryRun:
+			fmt.Printf("%d entries would be updated (dry run)\n", updated)
+		case updated == 0:
+			fmt.Println("All entries are up to date")
+		default:
+			fmt.Printf("Updated %d entries\n", updated)
+		}
+		if failed > 0 {
+			fmt.Println(faint(fmt.Sprintf("%d snapshot(s) couldn't be parsed; see 'digest debug replay'", failed)))
+		}
+		if cfg.FeedSnapshots <= 0 {
+			fmt.Println(faint("Set \"feed_snapshots\" in config.json to keep raw responses to reprocess."))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reprocessCmd)
+	reprocessCmd.Flags().String("feed", "", "only reprocess this feed (URL or ID prefix)")
+	reprocessCmd.Flags().Bool("dry-run", false, "report what would change without writing")
+}
@@ -4,15 +4,34 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/harper/digest/internal/badge"
+	"github.com/harper/digest/internal/bookmarks"
 	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/deliver"
+	"github.com/harper/digest/internal/digests"
+	"github.com/harper/digest/internal/favicon"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/hooks"
+	"github.com/harper/digest/internal/models"
 	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/share"
+	"github.com/harper/digest/internal/snapshot"
 	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/summary"
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/harper/digest/internal/thumbnail"
 )
 
 var (
@@ -41,11 +60,13 @@ Data stored locally. Configure backend via config.json.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Skip storage init for commands that don't need it
 		switch cmd.Name() {
-		case "setup", "migrate", "version", "help", "completion":
+		case "setup", "migrate", "encrypt", "decrypt", "version", "self-update", "help", "completion",
+			cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			// Completion requests open storage themselves once flags are parsed
 			return nil
 		}
 		// Profile subcommands don't need storage
-		if cmd.Parent() != nil && cmd.Parent().Name() == "profile" {
+		if cmd.Parent() != nil && (cmd.Parent().Name() == "profile" || cmd.Parent().Name() == "self-update") {
 			return nil
 		}
 
@@ -60,6 +81,20 @@ Data stored locally. Configure backend via config.json.`,
 		if !cmd.Flags().Changed("profile") {
 			profileName = cfg.GetDefaultProfile()
 		}
+		if err := cfg.ApplyProfile(profileName); err != nil {
+			return err
+		}
+		transport, err := cfg.GetTransportOptions()
+		if err != nil {
+			return err
+		}
+		fetch.Configure(transport)
+
+		// Status bars run 'digest unread' constantly; answer from the cached
+		// counts without opening storage when there are some
+		if cmd.Name() == "unread" && unreadCached(cmd) {
+			return nil
+		}
 
 		// Migrate flat-layout data files into "default" profile subdirectory (idempotent)
 		if err := cfg.MigrateToProfileLayout(); err != nil {
@@ -94,21 +129,67 @@ Data stored locally. Configure backend via config.json.`,
 	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
 		if store != nil {
-			if err := store.Close(); err != nil {
-				return fmt.Errorf("failed to close storage: %w", err)
+			// Keep the status bar badge in step with whatever this command
+			// read, marked, or synced; a stale badge is no reason to fail
+			if path, err := unreadCountsPath(); err == nil {
+				_, _ = badge.Refresh(cmd.Context(), store, opmlDoc, path)
 			}
 		}
-		return nil
+		return closeStore()
 	},
 }
 
+// closeStore closes the storage opened for the command, if it is still
+// open, writing out anything it holds pending.
+func closeStore() error {
+	if store == nil {
+		return nil
+	}
+	err := store.Close()
+	store = nil
+	if err != nil {
+		return fmt.Errorf("failed to close storage: %w", err)
+	}
+	return nil
+}
+
+// Execute runs the command line. The first Ctrl-C or SIGTERM cancels the
+// command's context so it can stop cleanly, such as a sync reporting what
+// it finished; a second one kills the process as usual.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+		signal.Stop(signals)
+		fmt.Fprintln(os.Stderr, "\nInterrupted; finishing up (press Ctrl-C again to quit now)")
+		cancel()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	// A failed command skips PersistentPostRunE, but its storage still
+	// needs closing so pending writes aren't lost
+	if closeErr := closeStore(); err == nil {
+		err = closeErr
+	}
+	var coded *codedError
+	if err != nil && ctx.Err() != nil && !errors.As(err, &coded) {
+		err = &codedError{exitInterrupted, err}
+	}
+	return err
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&opmlPath, "opml", "", "OPML file path (default: <data-dir>/<profile>/feeds.opml)")
 	rootCmd.PersistentFlags().StringVarP(&profileName, "profile", "p", "default", "profile name (e.g., work, personal). Profiles keep separate sets of feeds. Omit for default profile")
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", profileFlag)
 }
 
 func saveOPML() error {
@@ -121,6 +202,139 @@ func saveOPML() error {
 	return nil
 }
 
+// hookRunner returns the runner of the active profile's hooks. Failures of
+// hooks that can't stop a command are reported to errOut.
+func hookRunner(errOut io.Writer) (*hooks.Runner, error) {
+	conf, err := cfg.GetHooks()
+	if err != nil {
+		return nil, err
+	}
+	return &hooks.Runner{Config: conf, Profile: profileName, Warn: errOut}, nil
+}
+
+// userLocation returns the timezone periods like "today" start in: the
+// configured timezone, or local time.
+func userLocation() (*time.Location, error) {
+	if cfg == nil {
+		return time.Local, nil
+	}
+	loc, err := cfg.GetLocation()
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone in config: %w", err)
+	}
+	return loc, nil
+}
+
+// iconDir returns the favicon cache directory for the active profile.
+func iconDir() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return favicon.CacheDir(profileDir), nil
+}
+
+// feedIconColors maps feed IDs to the colors of their cached icons, for
+// marking feeds in the TUI. Feeds without a decodable icon are left out.
+func feedIconColors(feeds []*models.Feed) map[string]string {
+	colors := make(map[string]string)
+	dir, err := iconDir()
+	if err != nil {
+		return colors
+	}
+	for _, feed := range feeds {
+		if path := favicon.Path(dir, feed.ID); path != "" {
+			if c, ok := favicon.Color(path); ok {
+				colors[feed.ID] = c
+			}
+		}
+	}
+	return colors
+}
+
+// summaryDir returns the entry summary cache directory for the active profile.
+func summaryDir() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return summary.CacheDir(profileDir), nil
+}
+
+// thumbnailDir returns the lead image cache directory for the active profile.
+func thumbnailDir() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return thumbnail.CacheDir(profileDir), nil
+}
+
+// shareDir returns the shared digest pages directory for the active profile.
+func shareDir() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return share.Dir(profileDir), nil
+}
+
+// digestsDir returns the daily digest history directory for the active profile.
+func digestsDir() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return digests.Dir(profileDir), nil
+}
+
+// snapshotDir returns the raw feed snapshot directory for the active profile.
+func snapshotDir() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return snapshot.Dir(profileDir), nil
+}
+
+// lastSyncPath returns the last sync summary file for the active profile.
+func lastSyncPath() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return feedsync.RunPath(profileDir), nil
+}
+
+// bookmarkStatePath returns where the active profile records the entries
+// pushed to the bookmark service.
+func bookmarkStatePath() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return bookmarks.StatePath(profileDir), nil
+}
+
+// deliverStatePath returns where the active profile records what each
+// delivery target was sent.
+func deliverStatePath() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return deliver.StatePath(profileDir), nil
+}
+
+// unreadCountsPath returns the cached unread counts file for the active profile.
+func unreadCountsPath() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return badge.Path(profileDir), nil
+}
+
 // GetDefaultOPMLPath returns the default OPML file path for the default profile.
 func GetDefaultOPMLPath() string {
 	cfg, err := config.Load()
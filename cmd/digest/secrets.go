@@ -0,0 +1,178 @@
+// ABOUTME: Secrets commands for keeping feed passwords and API keys out of config and storage
+// ABOUTME: Stores, removes, and migrates secrets through the configured keychain, file, or env provider
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/secrets"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage stored passwords and API keys",
+	Long: `Keep feed passwords, API keys, and database passphrases out of plaintext.
+
+Secrets go to the OS keychain (macOS Keychain, or GNOME Keyring/KWallet
+through secret-tool on Linux) when one is available, and to secrets.json
+next to config.json, readable only by you, otherwise. Choose one with
+"secrets_backend" in config.json: auto, keyring, file, or env.
+
+An environment variable DIGEST_SECRET_<NAME> overrides any stored secret,
+with the name upper-cased and punctuation turned into underscores; for
+example DIGEST_SECRET_TTS_API_KEY for "tts/api-key".
+
+Names in use:
+  feed/<feed id>   a feed's basic auth password (set with 'digest feed auth')
+  tts/api-key      the OpenAI key for 'digest listen'
+  bookmarks/token  the Linkding or Raindrop.io token for 'digest bookmarks'
+  deliver/<name>   a Telegram bot or Matrix access token for 'digest deliver'
+  slack/signing-secret
+                   the Slack app's signing secret for 'digest serve'
+  team/<user>      a team user's API token (set with 'digest team')`,
+}
+
+var secretsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where secrets are stored",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := cfg.Secrets()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Secrets: %s\n", provider.Name())
+
+		feeds, err := store.ListFeeds(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list feeds: %w", err)
+		}
+		var stored, plain int
+		for _, feed := range feeds {
+			if feed.AuthPassword == nil {
+				continue
+			}
+			if _, ok := secrets.RefName(*feed.AuthPassword); ok {
+				stored++
+			} else {
+				plain++
+			}
+		}
+		fmt.Printf("Feed passwords: %d stored as secrets, %d in plaintext\n", stored, plain)
+		if plain > 0 {
+			faint := color.New(color.Faint).SprintFunc()
+			fmt.Println(faint("Run 'digest secrets migrate' to move plaintext passwords into the secrets store."))
+		}
+		return nil
+	},
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Store a secret",
+	Long: `Store a secret, read from the terminal without echo, or from stdin
+when piped.
+
+Examples:
+  digest secrets set tts/api-key
+  pass show openai | digest secrets set tts/api-key`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := cfg.Secrets()
+		if err != nil {
+			return err
+		}
+		value, err := readSecret(fmt.Sprintf("Value for %s: ", args[0]))
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return fmt.Errorf("secret cannot be empty")
+		}
+		if err := provider.Set(args[0], value); err != nil {
+			if errors.Is(err, secrets.ErrReadOnly) {
+				return fmt.Errorf("the env secrets backend can't store secrets; export %s instead", secrets.EnvName(args[0]))
+			}
+			return fmt.Errorf("failed to store secret: %w", err)
+		}
+		fmt.Printf("Stored %s\n", args[0])
+		return nil
+	},
+}
+
+var secretsDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a stored secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := cfg.Secrets()
+		if err != nil {
+			return err
+		}
+		if err := provider.Delete(args[0]); err != nil {
+			if errors.Is(err, secrets.ErrNotFound) {
+				return fmt.Errorf("no stored secret named %s", args[0])
+			}
+			return fmt.Errorf("failed to delete secret: %w", err)
+		}
+		fmt.Printf("Deleted %s\n", args[0])
+		return nil
+	},
+}
+
+var secretsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move plaintext feed passwords into the secrets store",
+	Long: `Move feed passwords saved in the database or _feeds.yaml into the
+secrets store, leaving a reference in their place. Every command does this
+when it opens a profile; run it to see the count, or after switching away
+from the env backend.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := cfg.Secrets()
+		if err != nil {
+			return err
+		}
+		if _, ok := provider.(secrets.Env); ok {
+			return fmt.Errorf("the env secrets backend can't store secrets; choose keyring or file")
+		}
+
+		moved, err := cfg.MigrateFeedPasswords(cmd.Context(), store)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Moved %d feed passwords to the secrets store\n", moved)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsStatusCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsDeleteCmd)
+	secretsCmd.AddCommand(secretsMigrateCmd)
+}
+
+// readSecret reads a value from the terminal without echo, or the first
+// line of stdin when it isn't a terminal.
+func readSecret(prompt string) (string, error) {
+	if term.IsTerminal(os.Stdin.Fd()) {
+		return readPassword(prompt)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
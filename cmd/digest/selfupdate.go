@@ -0,0 +1,191 @@
+// ABOUTME: Self-update command that installs the latest GitHub release over the running binary
+// ABOUTME: Verifies the archive's checksum, and its signature when a release key is built in
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/update"
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update digest to the latest release",
+	Long: `Download the latest digest release from GitHub and replace this binary
+with it.
+
+The release archive must match its entry in the release's checksums.txt,
+and checksums.txt.sig must verify against the release key built into
+digest (-X main.ReleaseKey=<base64 ed25519 key>); releases without one are
+refused. Release builds without a key refuse to update at all; development
+builds without one check the checksum only.
+
+Releases come from the stable channel unless "update_channel" in
+config.json or --channel says edge, which includes prereleases. See
+'digest self-update channel' to switch for good.
+
+Development builds don't know their version, so they only update with
+--force. Installs that came from a package manager are better updated
+with it instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		force, _ := cmd.Flags().GetBool("force")
+		channel, err := updateChannel(cmd)
+		if err != nil {
+			return err
+		}
+
+		client := update.NewClient()
+		latest, err := client.Latest(ctx, channel)
+		if err != nil {
+			return err
+		}
+		if !force {
+			if !update.IsRelease(Version) {
+				return fmt.Errorf("this is a development build (%s); use --force to install %s", Version, latest.Tag)
+			}
+			if !update.Newer(latest.Tag, Version) {
+				fmt.Printf("digest %s is the latest on the %s channel\n", Version, channel)
+				return nil
+			}
+		}
+
+		fmt.Printf("Downloading digest %s...\n", latest.Tag)
+		if err := installRelease(ctx, client, latest); err != nil {
+			return err
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Updated digest %s -> %s\n", green("v"), Version, latest.Tag)
+		return nil
+	},
+}
+
+var selfUpdateChannelCmd = &cobra.Command{
+	Use:   "channel [stable|edge]",
+	Short: "Show or set the release channel",
+	Long: `Show the release channel self-update and 'digest version --check'
+follow, or set it in config.json. stable follows full releases; edge also
+takes prereleases.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: update.Channels,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if len(args) == 0 {
+			channel, err := conf.GetUpdateChannel()
+			if err != nil {
+				return err
+			}
+			fmt.Println(channel)
+			return nil
+		}
+		channel, err := update.ParseChannel(args[0])
+		if err != nil {
+			return err
+		}
+		conf.UpdateChannel = channel
+		if channel == update.ChannelStable {
+			conf.UpdateChannel = ""
+		}
+		if err := conf.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Following the %s channel\n", green("v"), channel)
+		return nil
+	},
+}
+
+// updateChannel returns the channel from --channel, or else the config.
+// Update commands skip storage, so they load the config themselves.
+func updateChannel(cmd *cobra.Command) (string, error) {
+	if flag, _ := cmd.Flags().GetString("channel"); flag != "" {
+		return update.ParseChannel(flag)
+	}
+	conf, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	return conf.GetUpdateChannel()
+}
+
+// installRelease downloads this platform's archive of release, verifies
+// it, and puts its binary in place of the running one.
+func installRelease(ctx context.Context, client *update.Client, release *update.Release) error {
+	name := update.AssetName(release.Tag, runtime.GOOS, runtime.GOARCH)
+	asset, ok := release.Asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no build for %s/%s", release.Tag, runtime.GOOS, runtime.GOARCH)
+	}
+	sumsAsset, ok := release.Asset(update.ChecksumsFile)
+	if !ok {
+		return fmt.Errorf("release %s has no %s; not installing an unverified binary", release.Tag, update.ChecksumsFile)
+	}
+	sums, err := client.Download(ctx, sumsAsset)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ReleaseKey != "":
+		sigAsset, ok := release.Asset(update.SignatureFile)
+		if !ok {
+			return fmt.Errorf("release %s is not signed (no %s)", release.Tag, update.SignatureFile)
+		}
+		sig, err := client.Download(ctx, sigAsset)
+		if err != nil {
+			return err
+		}
+		if err := update.VerifySignature(sums, sig, ReleaseKey); err != nil {
+			return err
+		}
+	case update.IsRelease(Version):
+		// Releases are always built with a key; one without was built by hand
+		return fmt.Errorf("digest %s was built without a release key, so %s can't be verified; reinstall from a release", Version, release.Tag)
+	default:
+		faint := color.New(color.Faint).SprintFunc()
+		fmt.Println(faint("This development build has no release key; checking the checksum only"))
+	}
+
+	archive, err := client.Download(ctx, asset)
+	if err != nil {
+		return err
+	}
+	if err := update.VerifyChecksum(archive, name, update.ParseChecksums(sums)); err != nil {
+		return err
+	}
+	binary, err := update.ExtractBinary(archive, runtime.GOOS)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("can't find the running binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return update.Replace(exe, binary)
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.AddCommand(selfUpdateChannelCmd)
+
+	selfUpdateCmd.Flags().String("channel", "", "release channel to update from: stable or edge (default: update_channel in config.json)")
+	selfUpdateCmd.Flags().Bool("force", false, "install the latest release even if it isn't newer, or this is a development build")
+	_ = selfUpdateCmd.RegisterFlagCompletionFunc("channel", cobra.FixedCompletions(update.Channels, cobra.ShellCompDirectiveNoFileComp))
+}
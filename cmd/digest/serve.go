@@ -0,0 +1,504 @@
+// ABOUTME: Serve command that publishes digest's own entries as an RSS feed over HTTP
+// ABOUTME: Lets other devices and readers subscribe to unread or recent entries and audio briefings
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/blogroll"
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/events"
+	"github.com/harper/digest/internal/favicon"
+	"github.com/harper/digest/internal/feedout"
+	"github.com/harper/digest/internal/hooks"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/share"
+	"github.com/harper/digest/internal/slack"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/team"
+)
+
+// maxServeLimit caps the ?limit= query parameter.
+const maxServeLimit = 500
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve entries as an RSS feed",
+	Long: `Run a small HTTP server that republishes digest's entries as RSS, so
+other devices and readers can subscribe to what digest has collected.
+
+Endpoints:
+  /feed.xml     unread entries (or all recent entries with --all)
+  /podcast.xml  audio briefings from 'digest listen --podcast' (with --audio)
+  /audio/       the briefing MP3 files (with --audio)
+  /events.ics   upcoming events from event feeds, as an iCalendar feed
+  /icons/{id}   a feed's cached favicon, for the feed IDs /api/feeds lists
+  /blogroll.opml, /blogroll.html
+                the public blogroll, when "blogroll" folders are set in config.json
+  /slack        Slack slash commands, when the slack/signing-secret secret is set
+  /share/       read-only pages from 'digest share-digest', until they expire
+  /api/         per-user read state and stats, in team mode (see 'digest team')
+
+/feed.xml accepts ?category=<folder> and ?limit=<n> to narrow a single
+subscription, e.g. /feed.xml?category=Tech&limit=20. /events.ics needs
+?category=<folder> or ?feed=<url> to pick the feeds that announce events,
+e.g. /events.ics?category=Events for a calendar app to subscribe to.
+
+The server binds to localhost by default. Use --addr 0.0.0.0:8080 to reach
+it from other devices on your network; there is no authentication unless
+team users are set up with 'digest team add', and /slack only answers
+requests signed with the Slack app's secret. Shared digest pages need no
+token; their unguessable link is the only key.
+
+In team mode every other request needs a user's token, as
+"Authorization: Bearer <token>" or ?token=<token>, and sees that user's
+own read state:
+  GET  /api/entries                 unread entries as JSON (?all=1, ?limit=<n>)
+  POST /api/entries/{id}/read       mark an entry read (also /unread)
+  POST /api/entries/{id}/keep       keep an entry unread (DELETE to release)
+  GET  /api/stats                   the user's unread, read, and kept counts
+  GET  /api/feeds                   the subscriptions as JSON, with their icon URLs
+  POST /api/feeds                   add a feed from {"url", "title", "folder"} (admins)
+  DELETE /api/feeds/{id}            remove a feed and its entries (admins)
+
+Point a Slack slash command such as /digest at /slack so a team can ask
+the instance "/digest unread", "/digest unread Tech", or "/digest search
+kubernetes" from chat.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		since, _ := cmd.Flags().GetString("since")
+		all, _ := cmd.Flags().GetBool("all")
+		limit, _ := cmd.Flags().GetInt("limit")
+		audioDir, _ := cmd.Flags().GetString("audio")
+
+		fs := &feedServer{store: store, opml: opmlDoc, since: since, all: all, limit: limit, blogroll: cfg.GetBlogroll(), slackSecret: cfg.GetSlackSigningSecret(), team: cfg.GetTeam(), opmlPath: opmlPath}
+		sharePages, err := shareDir()
+		if err != nil {
+			return err
+		}
+		fs.shareDir = sharePages
+		if fs.iconDir, err = iconDir(); err != nil {
+			return err
+		}
+		if fs.team.Enabled() {
+			if _, err := storage.ForUser(store, fs.team.Users[0]); err != nil {
+				return fmt.Errorf("team mode: %w", err)
+			}
+			backfill, err := cfg.GetBackfill()
+			if err != nil {
+				return err
+			}
+			runner, err := hookRunner(cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			fs.backfill, fs.hooks = backfill, runner
+		}
+		if err := fs.applySince(&storage.EntryFilter{}); err != nil {
+			return err
+		}
+		if audioDir != "" {
+			fs.audioDir = config.ExpandPath(audioDir)
+			if info, err := os.Stat(fs.audioDir); err != nil || !info.IsDir() {
+				return fmt.Errorf("audio directory %s does not exist", fs.audioDir)
+			}
+		}
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		srv := &http.Server{Handler: fs.routes(), ReadHeaderTimeout: 10 * time.Second}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Serving http://%s/feed.xml\n", green("v"), listener.Addr())
+		if fs.audioDir != "" {
+			fmt.Printf("%s Serving http://%s/podcast.xml\n", green("v"), listener.Addr())
+		}
+		if len(fs.blogroll.Folders) > 0 {
+			fmt.Printf("%s Serving http://%s/%s\n", green("v"), listener.Addr(), blogroll.HTMLFile)
+		}
+		if fs.team.Enabled() {
+			fmt.Printf("%s Team mode: requests need a token for one of %d user(s)\n", green("v"), len(fs.team.Users))
+		}
+		if fs.slackSecret != "" {
+			fmt.Printf("%s Serving Slack commands at http://%s/slack\n", green("v"), listener.Addr())
+		}
+
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	},
+}
+
+// feedServer answers the RSS endpoints for 'digest serve'.
+type feedServer struct {
+	store    storage.Store
+	opml     *opml.Document
+	since    string
+	all      bool
+	limit    int
+	audioDir string
+	blogroll config.BlogrollConfig
+	// slackSecret enables /slack when set.
+	slackSecret string
+	// team, when it has users, requires a token and serves each user's
+	// own read state.
+	team team.Config
+	// opmlPath is where admins' feed changes are saved; opmlMu guards opml
+	// against them.
+	opmlPath string
+	opmlMu   sync.RWMutex
+	// backfill applies to feeds admins add, and hooks' pre_add_feed vets
+	// them.
+	backfill config.BackfillConfig
+	hooks    *hooks.Runner
+	// iconDir holds the feeds' cached favicons, served at /icons/ and
+	// removed with the feeds admins remove.
+	iconDir string
+	// shareDir holds the pages from 'digest share-digest'.
+	shareDir string
+}
+
+// teamUserKey carries the requesting teamUser in team mode.
+type teamUserKey struct{}
+
+// teamUser is the team member a request came from, their role, and their
+// view of the store.
+type teamUser struct {
+	name  string
+	role  team.Role
+	store storage.Store
+}
+
+func (fs *feedServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /feed.xml", fs.readingOPML(fs.handleFeed))
+	if fs.team.Enabled() {
+		mux.HandleFunc("GET /api/entries", fs.handleAPIEntries)
+		mux.HandleFunc("POST /api/entries/{id}/read", fs.handleAPIState)
+		mux.HandleFunc("POST /api/entries/{id}/unread", fs.handleAPIState)
+		mux.HandleFunc("POST /api/entries/{id}/keep", fs.handleAPIState)
+		mux.HandleFunc("DELETE /api/entries/{id}/keep", fs.handleAPIState)
+		mux.HandleFunc("GET /api/stats", fs.handleAPIStats)
+		mux.HandleFunc("GET /api/feeds", fs.readingOPML(fs.handleAPIFeeds))
+		mux.HandleFunc("POST /api/feeds", fs.handleAPIAddFeed)
+		mux.HandleFunc("DELETE /api/feeds/{id}", fs.handleAPIRemoveFeed)
+	}
+	mux.HandleFunc("GET /events.ics", fs.readingOPML(fs.handleEvents))
+	mux.HandleFunc("GET /icons/{id}", fs.handleIcon)
+	if fs.audioDir != "" {
+		mux.HandleFunc("GET /podcast.xml", fs.handlePodcast)
+		mux.Handle("GET /audio/", http.StripPrefix("/audio/", http.FileServer(http.Dir(fs.audioDir))))
+	}
+	if len(fs.blogroll.Folders) > 0 {
+		mux.HandleFunc("GET /"+blogroll.OPMLFile, fs.readingOPML(fs.handleBlogroll))
+		mux.HandleFunc("GET /"+blogroll.HTMLFile, fs.readingOPML(fs.handleBlogroll))
+	}
+	if !fs.team.Enabled() {
+		if fs.slackSecret != "" {
+			mux.Handle("POST /slack", fs.slackHandler())
+		}
+		mux.HandleFunc("GET /share/{token}", fs.handleShare)
+		return mux
+	}
+
+	// Slack signs its own requests and shared pages are public behind
+	// their token; everything else needs a user's token
+	outer := http.NewServeMux()
+	if fs.slackSecret != "" {
+		outer.Handle("POST /slack", fs.slackHandler())
+	}
+	outer.HandleFunc("GET /share/{token}", fs.handleShare)
+	outer.Handle("/", fs.requireToken(mux))
+	return outer
+}
+
+func (fs *feedServer) slackHandler() http.Handler {
+	h := &slack.Handler{Store: fs.store, OPML: fs.opml, SigningSecret: fs.slackSecret}
+	return fs.readingOPML(h.ServeHTTP)
+}
+
+// readingOPML holds the OPML read lock while h runs, so an admin adding or
+// removing a feed can't change the document under it.
+func (fs *feedServer) readingOPML(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fs.opmlMu.RLock()
+		defer fs.opmlMu.RUnlock()
+		h(w, r)
+	}
+}
+
+// requireToken lets through requests carrying a team user's token, with
+// that user's view of the store attached.
+func (fs *feedServer) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := fs.team.Authenticate(requestToken(r))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="digest"`)
+			http.Error(w, "a valid token is required", http.StatusUnauthorized)
+			return
+		}
+		view, err := storage.ForUser(fs.store, user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), teamUserKey{}, teamUser{name: user, role: fs.team.RoleOf(user), store: view})))
+	})
+}
+
+// requestToken returns the request's bearer token, or its ?token= for
+// clients such as feed readers that can't set headers.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// storeFor returns the store as the requesting user sees it: their own
+// view in team mode, the shared store otherwise.
+func (fs *feedServer) storeFor(r *http.Request) storage.Store {
+	if u, ok := r.Context().Value(teamUserKey{}).(teamUser); ok {
+		return u.store
+	}
+	return fs.store
+}
+
+// applySince narrows filter to --since, resolved afresh for each request so
+// "today" moves with the clock; an empty value means no date limit.
+func (fs *feedServer) applySince(filter *storage.EntryFilter) error {
+	return applySince(filter, fs.since)
+}
+
+func (fs *feedServer) handleFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	store := fs.storeFor(r)
+
+	limit := fs.limit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxServeLimit)
+	}
+
+	filter := &storage.EntryFilter{Limit: &limit}
+	if !fs.all {
+		unreadOnly := true
+		filter.UnreadOnly = &unreadOnly
+	}
+	if err := fs.applySince(filter); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	title := "digest: unread"
+	if fs.all {
+		title = "digest: recent"
+	}
+	if category := r.URL.Query().Get("category"); category != "" {
+		for _, opmlFeed := range fs.opml.FeedsInFolder(category) {
+			if feed, err := store.GetFeedByURL(ctx, opmlFeed.URL); err == nil {
+				filter.FeedIDs = append(filter.FeedIDs, feed.ID)
+			}
+		}
+		if len(filter.FeedIDs) == 0 {
+			http.Error(w, fmt.Sprintf("no synced feeds in category %q", category), http.StatusNotFound)
+			return
+		}
+		title += " in " + category
+	}
+
+	entries, err := store.ListEntries(ctx, filter)
+	if err != nil {
+		http.Error(w, "failed to list entries", http.StatusInternalServerError)
+		return
+	}
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		http.Error(w, "failed to list feeds", http.StatusInternalServerError)
+		return
+	}
+	feedNames := make(map[string]string, len(feeds))
+	for _, feed := range feeds {
+		feedNames[feed.ID] = feed.GetDisplayName()
+	}
+
+	ch := feedout.Channel{
+		Title:       title,
+		Link:        "http://" + r.Host + "/feed.xml",
+		Description: "Entries collected by digest",
+	}
+	for _, entry := range entries {
+		item := feedout.Item{GUID: entry.ID, Published: entry.CreatedAt}
+		item.Title = "Untitled"
+		if entry.Title != nil && *entry.Title != "" {
+			item.Title = *entry.Title
+		}
+		if name := feedNames[entry.FeedID]; name != "" {
+			item.Title = name + ": " + item.Title
+		}
+		if entry.Link != nil {
+			item.Link = *entry.Link
+		}
+		if entry.Author != nil {
+			item.Author = *entry.Author
+		}
+		if entry.PublishedAt != nil {
+			item.Published = *entry.PublishedAt
+		}
+		if entry.Content != nil {
+			item.Description = content.ToHTML(*entry.Content)
+		}
+		ch.Items = append(ch.Items, item)
+	}
+
+	writeFeed(w, ch)
+}
+
+func (fs *feedServer) handlePodcast(w http.ResponseWriter, r *http.Request) {
+	ch, err := podcastChannel(fs.audioDir, "http://"+r.Host+"/audio/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ch.Link = "http://" + r.Host + "/podcast.xml"
+	writeFeed(w, ch)
+}
+
+func (fs *feedServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	feedFilter := r.URL.Query().Get("feed")
+	category := r.URL.Query().Get("category")
+	if (feedFilter == "") == (category == "") {
+		http.Error(w, "pass exactly one of ?category=<folder> or ?feed=<url>", http.StatusBadRequest)
+		return
+	}
+
+	feedIDs, err := eventFeedIDs(ctx, fs.store, fs.opml, feedFilter, category)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	now := time.Now()
+	upcoming, err := upcomingEvents(ctx, fs.store, feedIDs, now)
+	if err != nil {
+		http.Error(w, "failed to list events", http.StatusInternalServerError)
+		return
+	}
+
+	name := "digest events"
+	if category != "" {
+		name += ": " + category
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err := events.WriteICS(w, name, upcoming, now); err != nil {
+		http.Error(w, "failed to write calendar", http.StatusInternalServerError)
+	}
+}
+
+func (fs *feedServer) handleBlogroll(w http.ResponseWriter, r *http.Request) {
+	b, err := buildBlogroll(r.Context(), fs.store, fs.opml, fs.blogroll.Title, fs.blogroll.Folders, fs.iconDir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, ".opml") {
+		w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+		err = blogroll.WriteOPML(w, b)
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = blogroll.WriteHTML(w, b, blogroll.OPMLFile)
+	}
+	if err != nil {
+		http.Error(w, "failed to write blogroll", http.StatusInternalServerError)
+	}
+}
+
+// handleIcon answers a feed's cached favicon. Icons come from the feeds'
+// sites, so they're served inert: an SVG opened directly can't run script
+// on this origin.
+func (fs *feedServer) handleIcon(w http.ResponseWriter, r *http.Request) {
+	feed, err := fs.storeFor(r).GetFeed(r.Context(), r.PathValue("id"))
+	if err != nil || fs.iconDir == "" {
+		http.NotFound(w, r)
+		return
+	}
+	path := favicon.Path(fs.iconDir, feed.ID)
+	if path == "" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "max-age=86400")
+	http.ServeFile(w, r, path)
+}
+
+// handleShare answers a page from 'digest share-digest' until it expires.
+func (fs *feedServer) handleShare(w http.ResponseWriter, r *http.Request) {
+	_, path, err := share.Open(fs.shareDir, r.PathValue("token"), time.Now())
+	if errors.Is(err, share.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to open shared digest", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	http.ServeFile(w, r, path)
+}
+
+func writeFeed(w http.ResponseWriter, ch feedout.Channel) {
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	if err := feedout.Write(w, ch); err != nil {
+		http.Error(w, "failed to write feed", http.StatusInternalServerError)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().String("since", "", "only entries since a date such as week, '3 days ago', or a range from..until")
+	serveCmd.Flags().BoolP("all", "a", false, "include entries already read")
+	serveCmd.Flags().IntP("limit", "n", 50, "max entries per feed request")
+	serveCmd.Flags().String("audio", "", "directory of 'digest listen --podcast' briefings to serve")
+	_ = serveCmd.RegisterFlagCompletionFunc("since", cobra.FixedCompletions(sinceCompletions, cobra.ShellCompDirectiveNoFileComp))
+	_ = serveCmd.MarkFlagDirname("audio")
+}
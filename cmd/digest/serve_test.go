@@ -0,0 +1,463 @@
+// ABOUTME: Tests for the serve command's RSS endpoints
+// ABOUTME: Runs the handlers against a temp SQLite store and parses the output as a subscriber would
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/share"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/team"
+)
+
+func TestServeFeed(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	title := "Example Blog"
+	feed.Title = &title
+	if err := s.CreateFeed(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+	unread := storage.NewEntry(feed.ID, "unread-guid", "Fresh Post")
+	body := "<p>Hello</p>"
+	unread.Content = &body
+	read := storage.NewEntry(feed.ID, "read-guid", "Old Post")
+	for _, e := range []*models.Entry{unread, read} {
+		if err := s.CreateEntry(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.MarkEntryRead(ctx, read.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := opml.NewDocument("test")
+	if err := doc.AddFeed(feed.URL, title, "Tech"); err != nil {
+		t.Fatal(err)
+	}
+
+	audioDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(audioDir, "digest-2026-10-15.mp3"), []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer((&feedServer{store: s, opml: doc, limit: 50, audioDir: audioDir}).routes())
+	defer srv.Close()
+
+	parsed := fetchTestFeed(t, srv.URL+"/feed.xml")
+	if len(parsed.Items) != 1 || parsed.Items[0].Title != "Example Blog: Fresh Post" {
+		t.Fatalf("expected only the unread entry, got %+v", parsed.Items)
+	}
+	if parsed.Items[0].Description != "<p>Hello</p>" {
+		t.Errorf("expected HTML content in description, got %q", parsed.Items[0].Description)
+	}
+
+	parsed = fetchTestFeed(t, srv.URL+"/feed.xml?category=Tech")
+	if len(parsed.Items) != 1 {
+		t.Errorf("expected category filter to keep the Tech entry, got %d", len(parsed.Items))
+	}
+
+	resp, err := http.Get(srv.URL + "/feed.xml?category=Nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown category, got %d", resp.StatusCode)
+	}
+
+	podcast := fetchTestFeed(t, srv.URL+"/podcast.xml")
+	if len(podcast.Items) != 1 || podcast.Items[0].Enclosures[0].URL != srv.URL+"/audio/digest-2026-10-15.mp3" {
+		t.Fatalf("unexpected podcast feed: %+v", podcast.Items)
+	}
+	resp, err = http.Get(podcast.Items[0].Enclosures[0].URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected enclosure to be served, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeEvents(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	feed := storage.NewFeed("https://example.com/events.xml")
+	if err := s.CreateFeed(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+	next := time.Now().AddDate(1, 0, 0)
+	upcoming := storage.NewEntry(feed.ID, "upcoming-guid", "Meetup on "+next.Format("January 2, 2006")+" at 7pm")
+	past := storage.NewEntry(feed.ID, "past-guid", "Meetup on January 5, 2001")
+	for _, e := range []*models.Entry{upcoming, past} {
+		if err := s.CreateEntry(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	doc := opml.NewDocument("test")
+	if err := doc.AddFeed(feed.URL, "Meetups", "Events"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer((&feedServer{store: s, opml: doc, limit: 50}).routes())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events.ics?category=Events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("expected text/calendar, got %q", ct)
+	}
+	if n := strings.Count(string(body), "BEGIN:VEVENT"); n != 1 {
+		t.Fatalf("expected only the upcoming event, got %d:\n%s", n, body)
+	}
+	if !strings.Contains(string(body), "UID:"+upcoming.ID+"@digest") {
+		t.Errorf("expected the upcoming entry's event, got:\n%s", body)
+	}
+
+	resp, err = http.Get(srv.URL + "/events.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 without a feed selection, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeBlogroll(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	doc := opml.NewDocument("test")
+	public := storage.NewFeed("https://friend.example.com/feed.xml")
+	private := storage.NewFeed("https://members.example.com/feed.xml")
+	user := "me"
+	private.AuthUsername = &user
+	for _, feed := range []*models.Feed{public, private} {
+		if err := s.CreateFeed(ctx, feed); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.AddFeed(feed.URL, feed.URL, "Friends"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := doc.AddFeed("https://work.example.com/feed.xml", "Work", "Work"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not opted in: no blogroll routes
+	srv := httptest.NewServer((&feedServer{store: s, opml: doc, limit: 50}).routes())
+	resp, err := http.Get(srv.URL + "/blogroll.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	srv.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected no blogroll without opted-in folders, got %d", resp.StatusCode)
+	}
+
+	icons := t.TempDir()
+	if err := os.WriteFile(filepath.Join(icons, public.ID+".png"), []byte("\x89PNG\r\n\x1a\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fs := &feedServer{store: s, opml: doc, limit: 50, iconDir: icons}
+	fs.blogroll.Title = "What I read"
+	fs.blogroll.Folders = []string{"Friends"}
+	srv = httptest.NewServer(fs.routes())
+	defer srv.Close()
+
+	resp, err = http.Get(srv.URL + "/blogroll.opml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	published, err := opml.Parse(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("parse blogroll.opml: %v", err)
+	}
+	feeds := published.AllFeeds()
+	if len(feeds) != 1 || feeds[0].URL != public.URL {
+		t.Errorf("expected only the public Friends feed, got %+v", feeds)
+	}
+
+	resp, err = http.Get(srv.URL + "/blogroll.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "<h1>What I read</h1>") || strings.Contains(string(body), "members.example.com") {
+		t.Errorf("unexpected blogroll page:\n%s", body)
+	}
+	if !strings.Contains(string(body), `<img class="icon" src="data:image/png;base64,`) {
+		t.Errorf("expected the cached icon inlined in the blogroll page:\n%s", body)
+	}
+}
+
+func TestServeIcons(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	withIcon := storage.NewFeed("https://example.com/feed.xml")
+	without := storage.NewFeed("https://plain.example.com/feed.xml")
+	for _, feed := range []*models.Feed{withIcon, without} {
+		if err := s.CreateFeed(ctx, feed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	icons := t.TempDir()
+	if err := os.WriteFile(filepath.Join(icons, withIcon.ID+".svg"), []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := team.Config{Users: []string{"alice"}, Tokens: map[string]string{"alice": "a-token"}}
+	fs := &feedServer{store: s, opml: opml.NewDocument("test"), limit: 50, team: tm, iconDir: icons}
+	srv := httptest.NewServer(fs.routes())
+	defer srv.Close()
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := get("/icons/" + withIcon.ID); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected icons to need a token in team mode, got %s", resp.Status)
+	}
+	resp := get("/icons/" + withIcon.ID + "?token=a-token")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("expected the cached SVG, got %s %q", resp.Status, resp.Header.Get("Content-Type"))
+	}
+	if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") {
+		t.Errorf("expected icons served under a locked-down CSP, got %q", csp)
+	}
+	for _, id := range []string{without.ID, "no-such-feed"} {
+		if resp := get("/icons/" + id + "?token=a-token"); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("/icons/%s: expected 404, got %s", id, resp.Status)
+		}
+	}
+
+	resp, err = http.Get(srv.URL + "/api/feeds?token=a-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feeds []apiFeed
+	err = json.NewDecoder(resp.Body).Decode(&feeds)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range feeds {
+		want := ""
+		if f.ID == withIcon.ID {
+			want = "/icons/" + withIcon.ID
+		}
+		if f.Icon != want {
+			t.Errorf("feed %s: expected icon %q, got %q", f.URL, want, f.Icon)
+		}
+	}
+}
+
+func TestServeShare(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	live, _ := share.NewToken()
+	expired, _ := share.NewToken()
+	if err := share.Publish(dir, share.Share{Token: live, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}, []byte("<h1>Shared</h1>")); err != nil {
+		t.Fatal(err)
+	}
+	if err := share.Publish(dir, share.Share{Token: expired, CreatedAt: now, ExpiresAt: now.Add(-time.Second)}, []byte("<h1>Old</h1>")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Shared pages need no team token
+	tm := team.Config{Users: []string{"alice"}, Tokens: map[string]string{"alice": "a-token"}}
+	fs := &feedServer{opml: opml.NewDocument("test"), team: tm, shareDir: dir}
+	srv := httptest.NewServer(fs.routes())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/share/" + live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "<h1>Shared</h1>" {
+		t.Fatalf("expected the shared page, got %s %q", resp.Status, body)
+	}
+	if got := resp.Header.Get("X-Robots-Tag"); !strings.Contains(got, "noindex") {
+		t.Errorf("expected shared pages to ask not to be indexed, got %q", got)
+	}
+
+	for _, token := range []string{expired, "nope"} {
+		resp, err := http.Get(srv.URL + "/share/" + token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected 404 for %q, got %s", token, resp.Status)
+		}
+	}
+}
+
+func TestServeTeam(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	if err := s.CreateFeed(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+	entry := storage.NewEntry(feed.ID, "guid", "Shared Post")
+	if err := s.CreateEntry(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := team.Config{
+		Users:  []string{"alice", "bob"},
+		Roles:  map[string]team.Role{"bob": team.RoleAdmin},
+		Tokens: map[string]string{"alice": "a-token", "bob": "b-token"},
+	}
+	fs := &feedServer{store: s, opml: opml.NewDocument("test"), limit: 50, team: tm}
+	srv := httptest.NewServer(fs.routes())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %s", resp.Status)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/entries/"+entry.ID+"/read", nil)
+	req.Header.Set("Authorization", "Bearer a-token")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("mark read: %s", resp.Status)
+	}
+
+	if items := fetchTestFeed(t, srv.URL+"/feed.xml?token=a-token").Items; len(items) != 0 {
+		t.Errorf("alice read the post, but her feed has %d item(s)", len(items))
+	}
+	if items := fetchTestFeed(t, srv.URL+"/feed.xml?token=b-token").Items; len(items) != 1 {
+		t.Errorf("bob hasn't read the post, but his feed has %d item(s)", len(items))
+	}
+	if owner, _ := s.GetEntry(ctx, entry.ID); owner.Read {
+		t.Error("alice's read changed the owner's state")
+	}
+
+	// Only admins manage feeds
+	addFeed := func(token string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/feeds", strings.NewReader(`{"url": "https://other.example/feed.xml", "folder": "News"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := addFeed("a-token"); code != http.StatusForbidden {
+		t.Errorf("reader adding a feed: got %d, want 403", code)
+	}
+	if code := addFeed("b-token"); code != http.StatusCreated {
+		t.Fatalf("admin adding a feed: got %d, want 201", code)
+	}
+	if _, err := s.GetFeedByURL(ctx, "https://other.example/feed.xml"); err != nil {
+		t.Errorf("expected the admin's feed in the store: %v", err)
+	}
+	if feeds := fs.opml.FeedsInFolder("News"); len(feeds) != 1 {
+		t.Errorf("expected the admin's feed in the OPML News folder, got %v", feeds)
+	}
+	if code := addFeed("b-token"); code != http.StatusConflict {
+		t.Errorf("adding the same feed again: got %d, want 409", code)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/api/stats", nil)
+	req.Header.Set("Authorization", "Bearer b-token")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats team.Stats
+	err = json.NewDecoder(resp.Body).Decode(&stats)
+	resp.Body.Close()
+	if err != nil || stats.User != "bob" || stats.Unread != 1 || stats.Read != 0 {
+		t.Errorf("bob's stats = %+v, %v", stats, err)
+	}
+}
+
+func fetchTestFeed(t *testing.T, url string) *gofeed.Feed {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: %s", url, resp.Status)
+	}
+	feed, err := gofeed.NewParser().Parse(resp.Body)
+	if err != nil {
+		t.Fatalf("parse %s: %v", url, err)
+	}
+	return feed
+}
@@ -0,0 +1,274 @@
+// ABOUTME: Team mode JSON API for 'digest serve': each user's entries, read state, stats, and feeds
+// ABOUTME: Handlers run behind requireToken with the user's own view; only admins change feeds
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/favicon"
+	"github.com/harper/digest/internal/feedurl"
+	"github.com/harper/digest/internal/hooks"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/team"
+)
+
+// apiEntry is an entry as the JSON API returns it.
+type apiEntry struct {
+	ID          string     `json:"id"`
+	FeedID      string     `json:"feed_id"`
+	Feed        string     `json:"feed"`
+	Title       string     `json:"title"`
+	Link        string     `json:"link,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Read        bool       `json:"read"`
+	Kept        bool       `json:"kept"`
+}
+
+func (fs *feedServer) handleAPIEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	store := fs.storeFor(r)
+
+	limit := fs.limit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxServeLimit)
+	}
+	filter := &storage.EntryFilter{Limit: &limit}
+	if r.URL.Query().Get("all") == "" {
+		unreadOnly := true
+		filter.UnreadOnly = &unreadOnly
+	}
+
+	entries, err := store.ListEntries(ctx, filter)
+	if err != nil {
+		http.Error(w, "failed to list entries", http.StatusInternalServerError)
+		return
+	}
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		http.Error(w, "failed to list feeds", http.StatusInternalServerError)
+		return
+	}
+	feedNames := make(map[string]string, len(feeds))
+	for _, feed := range feeds {
+		feedNames[feed.ID] = feed.GetDisplayName()
+	}
+
+	out := make([]apiEntry, 0, len(entries))
+	for _, e := range entries {
+		item := apiEntry{
+			ID:          e.ID,
+			FeedID:      e.FeedID,
+			Feed:        feedNames[e.FeedID],
+			Title:       e.GetTitle(),
+			PublishedAt: e.PublishedAt,
+			Read:        e.Read,
+			Kept:        e.KeepUnread,
+		}
+		if e.Link != nil {
+			item.Link = *e.Link
+		}
+		out = append(out, item)
+	}
+	writeJSON(w, out)
+}
+
+// handleAPIState marks an entry read or unread, or keeps or releases it,
+// by the last part of the path and the method.
+func (fs *feedServer) handleAPIState(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	store := fs.storeFor(r)
+
+	entry, err := store.GetEntryByIDOrPrefix(ctx, r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "entry not found", http.StatusNotFound)
+		return
+	}
+	switch path.Base(r.URL.Path) {
+	case "read":
+		err = store.MarkEntryRead(ctx, entry.ID)
+	case "unread":
+		err = store.MarkEntryUnread(ctx, entry.ID)
+	case "keep":
+		err = store.SetEntryKeepUnread(ctx, entry.ID, r.Method != http.MethodDelete)
+	}
+	if err != nil {
+		http.Error(w, "failed to update entry", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (fs *feedServer) handleAPIStats(w http.ResponseWriter, r *http.Request) {
+	user, _ := r.Context().Value(teamUserKey{}).(teamUser)
+	stats, err := team.UserStats(r.Context(), fs.store, user.name)
+	if err != nil {
+		http.Error(w, "failed to count entries", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+// apiFeed is a feed as the JSON API returns it.
+type apiFeed struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Folder string `json:"folder,omitempty"`
+	Unread int    `json:"unread"`
+	// Icon is the /icons/ URL of the feed's favicon, when one is cached.
+	Icon string `json:"icon,omitempty"`
+}
+
+func (fs *feedServer) handleAPIFeeds(w http.ResponseWriter, r *http.Request) {
+	stats, err := fs.storeFor(r).GetFeedStats(r.Context())
+	if err != nil {
+		http.Error(w, "failed to list feeds", http.StatusInternalServerError)
+		return
+	}
+	folders := make(map[string]string)
+	for _, f := range fs.opml.AllFeeds() {
+		folders[f.URL] = f.Folder
+	}
+	out := make([]apiFeed, 0, len(stats))
+	for _, row := range stats {
+		feed := apiFeed{ID: row.FeedID, URL: row.FeedURL, Title: row.FeedURL, Folder: folders[row.FeedURL], Unread: row.UnreadCount}
+		if row.FeedTitle != nil && *row.FeedTitle != "" {
+			feed.Title = *row.FeedTitle
+		}
+		if fs.iconDir != "" && favicon.Path(fs.iconDir, row.FeedID) != "" {
+			feed.Icon = "/icons/" + row.FeedID
+		}
+		out = append(out, feed)
+	}
+	writeJSON(w, out)
+}
+
+// requireAdmin answers 403 and returns false unless the request comes from
+// a user allowed to add and remove feeds.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if u, ok := r.Context().Value(teamUserKey{}).(teamUser); ok && u.role.CanManageFeeds() {
+		return true
+	}
+	http.Error(w, "only admins can add or remove feeds", http.StatusForbidden)
+	return false
+}
+
+// handleAPIAddFeed subscribes to a feed URL as given, without discovery;
+// its entries arrive with the next sync.
+func (fs *feedServer) handleAPIAddFeed(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	ctx := r.Context()
+	var req struct {
+		URL    string `json:"url"`
+		Title  string `json:"title"`
+		Folder string `json:"folder"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil || req.URL == "" {
+		http.Error(w, `expected a JSON body with "url"`, http.StatusBadRequest)
+		return
+	}
+	feedURL, err := feedurl.Canonical(req.URL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fs.opmlMu.Lock()
+	defer fs.opmlMu.Unlock()
+	if _, err := fs.store.GetFeedByURL(ctx, feedURL); err == nil {
+		http.Error(w, "feed already exists: "+feedURL, http.StatusConflict)
+		return
+	}
+	if fs.hooks != nil {
+		hooked, err := fs.hooks.PreAddFeed(ctx, hooks.Feed{URL: feedURL, Title: req.Title, Folder: req.Folder})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		req.Title, req.Folder = hooked.Title, hooked.Folder
+	}
+	feed := storage.NewFeed(feedURL)
+	feed.Folder = req.Folder
+	feed.BackfillDays, feed.BackfillLimit = fs.backfill.Days, fs.backfill.Limit
+	if req.Title != "" {
+		feed.Title = &req.Title
+	}
+	if err := fs.store.CreateFeed(ctx, feed); err != nil {
+		http.Error(w, "failed to create feed", http.StatusInternalServerError)
+		return
+	}
+	opmlTitle := req.Title
+	if opmlTitle == "" {
+		opmlTitle = feedURL
+	}
+	if err := fs.opml.AddFeed(feedURL, opmlTitle, req.Folder); err == nil {
+		fs.saveOPML()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, apiFeed{ID: feed.ID, URL: feedURL, Title: opmlTitle, Folder: req.Folder})
+}
+
+func (fs *feedServer) handleAPIRemoveFeed(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	ctx := r.Context()
+
+	fs.opmlMu.Lock()
+	defer fs.opmlMu.Unlock()
+	feed, err := fs.store.GetFeedByURLOrPrefix(ctx, r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "feed not found", http.StatusNotFound)
+		return
+	}
+	if err := fs.store.DeleteFeed(ctx, feed.ID); err != nil {
+		http.Error(w, "failed to delete feed", http.StatusInternalServerError)
+		return
+	}
+	if provider, err := cfg.FeedSecrets(feed); err == nil && provider != nil {
+		_ = config.ClearFeedPassword(provider, feed)
+	}
+	if fs.iconDir != "" {
+		favicon.Remove(fs.iconDir, feed.ID)
+	}
+	if err := fs.opml.RemoveFeed(feed.URL); err == nil {
+		fs.saveOPML()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// saveOPML writes the subscriptions after an admin's change. The store is
+// the source of truth, so a failure is only logged. The caller holds
+// opmlMu.
+func (fs *feedServer) saveOPML() {
+	if fs.opmlPath == "" {
+		return
+	}
+	if err := fs.opml.WriteFile(fs.opmlPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save OPML: %v\n", err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "failed to write response", http.StatusInternalServerError)
+	}
+}
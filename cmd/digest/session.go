@@ -0,0 +1,240 @@
+// ABOUTME: Session command for time-boxed reading, serving unread entries until the time is up
+// ABOUTME: Ends with what was read, the backlog left, and bulk actions that would shrink it
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// staleAfter is how old unread entries are before a session suggests
+// marking them read in bulk.
+const staleAfter = 14 * 24 * time.Hour
+
+// crowdingShare is the share of the backlog one feed must hold before a
+// session suggests clearing it.
+const crowdingShare = 0.25
+
+// minSuggested is the fewest entries a bulk action is suggested for.
+const minSuggested = 10
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Read for a set time, one article at a time",
+	Long: `Serve unread articles one at a time, as 'digest next' does, until the
+time budget runs out. Each article is marked read as it's shown. After each,
+press Enter for the next, u to put it back as unread, or q to stop early.
+
+When the session ends it prints how many articles were read, the backlog
+left, and bulk actions that would clear what a few more sessions won't: old
+entries to mark read and feeds that crowd out the rest. Time-boxing beats
+trying to read everything; stop when the time is up.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		minutes, _ := cmd.Flags().GetInt("minutes")
+		noMark, _ := cmd.Flags().GetBool("no-mark")
+		if minutes <= 0 {
+			return fmt.Errorf("--minutes must be positive")
+		}
+		budget := time.Duration(minutes) * time.Minute
+		q, err := newReadingQueue(cmd)
+		if err != nil {
+			return err
+		}
+
+		faint := color.New(color.Faint).SprintFunc()
+		bold := color.New(color.Bold).SprintFunc()
+		in := bufio.NewReader(cmd.InOrStdin())
+		start := time.Now()
+		read, putBack := 0, 0
+		served := make(map[string]bool)
+
+	loop:
+		for {
+			if time.Since(start) >= budget {
+				fmt.Println(bold("Time's up."))
+				break
+			}
+			entry, _, err := q.next(ctx, served)
+			if err != nil {
+				return err
+			}
+			if entry == nil {
+				fmt.Println("Nothing left to read.")
+				break
+			}
+			if err := showEntry(cmd, entry); err != nil {
+				return err
+			}
+			served[entry.ID] = true
+			if !noMark {
+				read++
+			}
+
+			left := budget - time.Since(start)
+			if left <= 0 {
+				fmt.Println(bold("Time's up."))
+				break
+			}
+			fmt.Print(faint(fmt.Sprintf("%s left · Enter: next · u: put back unread · q: stop ", formatLeft(left))))
+			line, err := in.ReadString('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+			if !isatty.IsTerminal(os.Stdin.Fd()) {
+				// Piped input isn't echoed, so end the prompt's line
+				fmt.Println()
+			}
+			switch strings.ToLower(strings.TrimSpace(line)) {
+			case "u":
+				if !noMark {
+					if err := store.MarkEntryUnread(ctx, entry.ID); err != nil {
+						return fmt.Errorf("failed to mark entry as unread: %w", err)
+					}
+					read--
+					putBack++
+				}
+			case "q":
+				break loop
+			}
+			if errors.Is(err, io.EOF) {
+				// Input closed; nobody is left to read the next one
+				break
+			}
+		}
+
+		_, unread, err := q.next(ctx, nil)
+		if err != nil {
+			return err
+		}
+		feeds, err := store.ListFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list feeds: %w", err)
+		}
+
+		took := time.Since(start)
+		fmt.Println()
+		fmt.Printf("%s %s: %d read", bold("Session over after"), formatLeft(took), read)
+		if putBack > 0 {
+			fmt.Printf(", %d put back", putBack)
+		}
+		fmt.Println()
+		backlog := queued(unread)
+		fmt.Printf("Backlog: %d unread\n", backlog)
+		if read > 0 && backlog > 0 {
+			sessions := (backlog + read - 1) / read
+			fmt.Println(faint(fmt.Sprintf("At this pace that's about %d more %d-minute session(s)", sessions, minutes)))
+		}
+		if overall, err := store.GetOverallStats(ctx); err == nil {
+			if progress, err := readingProgress(ctx, overall.UnreadCount); err == nil {
+				fmt.Println(faint(progress.Summary()))
+			}
+		}
+
+		scoped := q.filter.FeedID != nil || len(q.filter.FeedIDs) > 0
+		if advice := sessionAdvice(unread, feeds, time.Now(), scoped); len(advice) > 0 {
+			fmt.Println()
+			fmt.Println(bold("Suggested bulk actions:"))
+			for _, a := range advice {
+				fmt.Printf("  %s  %s\n", a.command, faint("# "+a.reason))
+			}
+		}
+		return nil
+	},
+}
+
+// bulkAction is a command a session suggests for shrinking the backlog.
+type bulkAction struct {
+	command string
+	reason  string
+}
+
+// sessionAdvice suggests bulk actions for the unread entries left after a
+// session: marking read the ones older than staleAfter, unless the session
+// was scoped to some feeds (mark-read --before isn't), and clearing a feed
+// holding at least crowdingShare of them.
+func sessionAdvice(unread []*models.Entry, feeds []*models.Feed, now time.Time, scoped bool) []bulkAction {
+	var advice []bulkAction
+	perFeed := make(map[string]int)
+	stale, total := 0, 0
+	for _, e := range unread {
+		if e.Read || e.KeepUnread {
+			continue
+		}
+		total++
+		perFeed[e.FeedID]++
+		if now.Sub(waitingSince(e)) > staleAfter {
+			stale++
+		}
+	}
+	if !scoped && stale >= minSuggested {
+		advice = append(advice, bulkAction{
+			command: `digest mark-read --before "2 weeks"`,
+			reason:  fmt.Sprintf("%d unread entries are over two weeks old", stale),
+		})
+	}
+
+	byID := make(map[string]*models.Feed, len(feeds))
+	for _, f := range feeds {
+		byID[f.ID] = f
+	}
+	ids := make([]string, 0, len(perFeed))
+	for id := range perFeed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if perFeed[ids[i]] != perFeed[ids[j]] {
+			return perFeed[ids[i]] > perFeed[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > 1 {
+		n := perFeed[ids[0]]
+		if feed := byID[ids[0]]; feed != nil && n >= minSuggested && float64(n) >= crowdingShare*float64(total) {
+			advice = append(advice, bulkAction{
+				command: fmt.Sprintf("digest list --feed %s --mark-read -n %d", feed.URL, n),
+				reason:  fmt.Sprintf("skim and clear %s, %d of the %d unread", feed.GetDisplayName(), n, total),
+			})
+		}
+	}
+	return advice
+}
+
+// waitingSince returns when an entry was published, or first stored if it
+// has no publish date.
+func waitingSince(e *models.Entry) time.Time {
+	if e.PublishedAt != nil {
+		return *e.PublishedAt
+	}
+	return e.CreatedAt
+}
+
+// formatLeft formats a duration in whole minutes, or seconds under one.
+func formatLeft(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+
+	sessionCmd.Flags().IntP("minutes", "m", 20, "time budget for the session")
+	addQueueFlags(sessionCmd)
+	addReadFlags(sessionCmd)
+}
@@ -0,0 +1,66 @@
+// ABOUTME: Tests for the bulk actions a reading session suggests
+// ABOUTME: Covers stale entries, a feed crowding out the rest, and scoped sessions
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestSessionAdvice(t *testing.T) {
+	now := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+	busy := models.NewFeed("https://busy.example.com/feed.xml")
+	quiet := models.NewFeed("https://quiet.example.com/feed.xml")
+	feeds := []*models.Feed{busy, quiet}
+
+	entries := func(feed *models.Feed, n int, age time.Duration) []*models.Entry {
+		var out []*models.Entry
+		for i := range n {
+			e := models.NewEntry(feed.ID, fmt.Sprintf("%s-%d", feed.ID, i), "Post")
+			published := now.Add(-age)
+			e.PublishedAt = &published
+			out = append(out, e)
+		}
+		return out
+	}
+	unread := append(entries(busy, 12, time.Hour), entries(quiet, 10, 30*24*time.Hour)...)
+
+	advice := sessionAdvice(unread, feeds, now, false)
+	if len(advice) != 2 {
+		t.Fatalf("expected two suggestions, got %+v", advice)
+	}
+	if !strings.Contains(advice[0].command, "mark-read --before") || !strings.Contains(advice[0].reason, "10 unread") {
+		t.Errorf("unexpected stale suggestion %+v", advice[0])
+	}
+	if advice[1].command != "digest list --feed https://busy.example.com/feed.xml --mark-read -n 12" {
+		t.Errorf("unexpected feed suggestion %+v", advice[1])
+	}
+
+	// mark-read --before isn't limited to the session's feeds
+	if advice := sessionAdvice(unread, feeds, now, true); len(advice) != 1 || strings.Contains(advice[0].command, "--before") {
+		t.Errorf("expected only the feed suggestion when scoped, got %+v", advice)
+	}
+
+	// A small backlog needs no bulk action
+	if advice := sessionAdvice(unread[:5], feeds, now, false); len(advice) != 0 {
+		t.Errorf("expected no suggestions, got %+v", advice)
+	}
+}
+
+func TestFormatLeft(t *testing.T) {
+	tests := map[time.Duration]string{
+		45 * time.Second:                "45s",
+		time.Minute:                     "1m",
+		19*time.Minute + 59*time.Second: "19m",
+	}
+	for d, want := range tests {
+		if got := formatLeft(d); got != want {
+			t.Errorf("formatLeft(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// ABOUTME: Share-digest command that publishes a read-only digest page behind an unguessable link
+// ABOUTME: Pages are served by 'digest serve' at /share/<token> until they expire, or written out for static hosting
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/render"
+	"github.com/harper/digest/internal/share"
+	"github.com/harper/digest/internal/storage"
+)
+
+var shareDigestCmd = &cobra.Command{
+	Use:   "share-digest",
+	Short: "Share a read-only digest page with people who don't use digest",
+	Long: `Render the entries since a date as a read-only web page behind an
+unguessable link, for sharing with people who don't use digest.
+
+The page is a snapshot: titles, links, and short excerpts of the entries
+as they are now, grouped by feed. Read state, folders, and feeds that need
+a login or sit on the local network are never shown.
+
+By default the page is kept with the profile and 'digest serve' answers it
+at /share/<token> until it expires, without needing a team token. With
+--output the page is written to a directory instead, named by its token,
+for uploading to any static host; the host can't enforce the expiry, so
+delete the file when you're done sharing.
+
+Examples:
+  digest share-digest                          # today's entries, for 7 days
+  digest share-digest --since week --expires 2w --category Tech
+  digest share-digest --base-url https://digest.example.com
+  digest share-digest -o ~/site/public/d --base-url https://example.com/d
+  digest share-digest --list
+  digest share-digest --revoke <token>`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, _ := cmd.Flags().GetBool("list")
+		revoke, _ := cmd.Flags().GetString("revoke")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		baseURL = strings.TrimSuffix(baseURL, "/")
+
+		dir, err := shareDir()
+		if err != nil {
+			return err
+		}
+		if _, err := share.Prune(dir, time.Now()); err != nil {
+			return err
+		}
+		if list {
+			return listShares(dir, baseURL)
+		}
+		if revoke != "" {
+			if err := share.Revoke(dir, revoke); err != nil {
+				if errors.Is(err, share.ErrNotFound) {
+					return fmt.Errorf("no share %s (it may have expired)", revoke)
+				}
+				return err
+			}
+			fmt.Printf("Revoked %s\n", revoke)
+			return nil
+		}
+		return publishShare(cmd, dir, baseURL)
+	},
+}
+
+func publishShare(cmd *cobra.Command, dir, baseURL string) error {
+	ctx := cmd.Context()
+	since, _ := cmd.Flags().GetString("since")
+	expiresIn, _ := cmd.Flags().GetString("expires")
+	title, _ := cmd.Flags().GetString("title")
+	category, _ := cmd.Flags().GetString("category")
+	outDir, _ := cmd.Flags().GetString("output")
+
+	lifetime, err := share.ParseExpiry(expiresIn)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if title == "" {
+		title = "Digest, " + now.Format("January 2, 2006")
+	}
+
+	filter := &storage.EntryFilter{}
+	if err := applySince(filter, since); err != nil {
+		return err
+	}
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list feeds: %w", err)
+	}
+	inFolder := make(map[string]bool)
+	if category != "" {
+		for _, f := range opmlDoc.FeedsInFolder(category) {
+			inFolder[f.URL] = true
+		}
+		if len(inFolder) == 0 {
+			return fmt.Errorf("no feeds found in category %q", category)
+		}
+	}
+	var shared []*models.Feed
+	for _, f := range feeds {
+		if privateFeed(f) || (category != "" && !inFolder[f.URL]) {
+			continue
+		}
+		shared = append(shared, f)
+		filter.FeedIDs = append(filter.FeedIDs, f.ID)
+	}
+	if len(shared) == 0 {
+		return fmt.Errorf("no feeds to share")
+	}
+	entries, err := store.ListEntries(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	token, err := share.NewToken()
+	if err != nil {
+		return err
+	}
+	expires := now.Add(lifetime)
+	var page bytes.Buffer
+	if err := share.WriteHTML(&page, render.NewDigest(title, now, shared, entries, nil), expires); err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+	if outDir != "" {
+		outDir = config.ExpandPath(outDir)
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		path := filepath.Join(outDir, token+".html")
+		if err := os.WriteFile(path, page.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("%s Shared %d entry(s) %s\n", green("v"), len(entries), faint("("+title+")"))
+		fmt.Printf("  %s\n", path)
+		if baseURL != "" {
+			fmt.Printf("  %s/%s.html\n", baseURL, token)
+		}
+		fmt.Println(faint("Static hosts can't expire the page; delete it after " + expires.Format("January 2") + "."))
+		return nil
+	}
+
+	s := share.Share{Token: token, Title: title, Since: since, Entries: len(entries), CreatedAt: now, ExpiresAt: expires}
+	if err := share.Publish(dir, s, page.Bytes()); err != nil {
+		return err
+	}
+	fmt.Printf("%s Shared %d entry(s) until %s %s\n", green("v"), len(entries), expires.Format("January 2 15:04"), faint("("+title+")"))
+	fmt.Printf("  %s\n", shareURL(baseURL, token))
+	fmt.Println(faint("Anyone with the link can read the page while 'digest serve' is running."))
+	return nil
+}
+
+func listShares(dir, baseURL string) error {
+	shares, err := share.List(dir)
+	if err != nil {
+		return err
+	}
+	if len(shares) == 0 {
+		fmt.Println("No shared digests. Share one with 'digest share-digest'.")
+		return nil
+	}
+	faint := color.New(color.Faint).SprintFunc()
+	for _, s := range shares {
+		fmt.Printf("%s  %d entry(s), expires %s\n", s.Title, s.Entries, s.ExpiresAt.Format("January 2 15:04"))
+		fmt.Printf("  %s\n", faint(shareURL(baseURL, s.Token)))
+	}
+	return nil
+}
+
+// shareURL is where 'digest serve' at baseURL answers the share; without
+// a base URL it assumes serve's default address.
+func shareURL(baseURL, token string) string {
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:8080"
+	}
+	return baseURL + "/share/" + token
+}
+
+func init() {
+	rootCmd.AddCommand(shareDigestCmd)
+
+	shareDigestCmd.Flags().String("since", "today", "share entries since a date such as today, week, or '3 days ago'")
+	shareDigestCmd.Flags().String("expires", "7d", "how long the link works, such as 12h, 7d, or 2w")
+	shareDigestCmd.Flags().String("title", "", "page title (default \"Digest, <date>\")")
+	shareDigestCmd.Flags().StringP("category", "c", "", "only share feeds in this folder")
+	shareDigestCmd.Flags().StringP("output", "o", "", "write the page to this directory for static hosting instead")
+	shareDigestCmd.Flags().String("base-url", "", "public address of 'digest serve' or the static host, for printed links")
+	shareDigestCmd.Flags().Bool("list", false, "list shared digests that haven't expired")
+	shareDigestCmd.Flags().String("revoke", "", "delete a shared digest by its token")
+	_ = shareDigestCmd.RegisterFlagCompletionFunc("category", folderFlag)
+	shareDigestCmd.MarkFlagsMutuallyExclusive("list", "revoke")
+}
@@ -0,0 +1,130 @@
+// ABOUTME: State commands for moving read state between machines without a sync backend
+// ABOUTME: export writes a compact feed URL/GUID file; import applies it to the active profile
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/storage"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Export and import read state",
+	Long: `Copy which entries you've read from one machine to another.
+
+The state file lists every entry's read flag and read time by feed URL and
+entry GUID, so it applies to another store that follows the same feeds even
+though entry IDs differ. It holds no content and stays small.`,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Write the read state of every entry",
+	Long: `Write the read state of every entry to a file, or to stdout.
+
+Examples:
+  digest state export state.json
+  digest state export | ssh laptop digest state import -`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := storage.ExportState(cmd.Context(), store)
+		if err != nil {
+			return fmt.Errorf("failed to export state: %w", err)
+		}
+
+		if len(args) == 0 || args[0] == "-" {
+			return storage.WriteState(os.Stdout, state)
+		}
+
+		f, err := os.Create(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", args[0], err)
+		}
+		if err := storage.WriteState(f, state); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", args[0], err)
+		}
+
+		count := 0
+		for _, entries := range state.Feeds {
+			count += len(entries)
+		}
+		fmt.Printf("Exported read state for %d entries in %d feeds to %s\n", count, len(state.Feeds), args[0])
+		return nil
+	},
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Apply read state from another machine",
+	Long: `Apply a file written by 'digest state export' ("-" reads stdin).
+
+Feeds are matched by URL (http/https, www, and trailing-slash variants
+count as the same feed) and entries by GUID. By default entries are only
+marked read, never unread, so importing can't undo local progress; use
+--exact to make the local state match the file. Entries not fetched here
+yet are skipped; run 'digest fetch' and import again to pick them up.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exact, _ := cmd.Flags().GetBool("exact")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", args[0], err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		state, err := storage.ReadState(r)
+		if err != nil {
+			return err
+		}
+
+		summary, err := storage.ImportState(cmd.Context(), store, state, storage.StateImportOptions{
+			Exact:  exact,
+			DryRun: dryRun,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import state: %w", err)
+		}
+
+		verb := "Marked"
+		if dryRun {
+			verb = "Would mark"
+		}
+		fmt.Printf("%s %d entries read", verb, summary.MarkedRead)
+		if exact {
+			fmt.Printf(" and %d unread", summary.MarkedUnread)
+		}
+		fmt.Printf(" (%d unchanged)\n", summary.Unchanged)
+		if summary.Missing > 0 {
+			faint := color.New(color.Faint).SprintFunc()
+			fmt.Println(faint(fmt.Sprintf("Skipped %d entries not in this store (%d feeds not followed here)",
+				summary.Missing, summary.MissingFeeds)))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateExportCmd)
+	stateCmd.AddCommand(stateImportCmd)
+
+	stateImportCmd.Flags().Bool("exact", false, "also mark entries unread to match the file")
+	stateImportCmd.Flags().Bool("dry-run", false, "show what would change without changing it")
+}
@@ -0,0 +1,186 @@
+// ABOUTME: Stats command showing entry and unread counts overall and per feed
+// ABOUTME: Supports --quiet (unread count only) and --porcelain for status bars and scripts
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/goals"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/harper/digest/internal/timeutil"
+	"github.com/harper/digest/internal/tui"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show feed and entry statistics",
+	Long: `Show total feeds, entries, and unread counts, plus a per-feed breakdown.
+
+--quiet prints only the total unread count.
+--porcelain prints one tab-separated record per feed:
+  url, entries, unread, error_count, last_fetched_at (RFC 3339, UTC), title,
+  status (active/inactive/archived), last_entry_at (RFC 3339, UTC)
+
+Feeds with no new entries or only errors for inactive_days are marked
+inactive; 'digest fetch' archives them.
+
+The reading streak counts the days in a row the daily reading goal was met
+(any read, without a goal). Set goals in config.json:
+  "goals": {"daily_reads": 10, "max_unread": 50}`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		mode := getOutputMode(cmd)
+		out := cmd.OutOrStdout()
+
+		overall, err := store.GetOverallStats(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get stats: %w", err)
+		}
+
+		if mode == outputQuiet {
+			fmt.Fprintln(out, overall.UnreadCount)
+			return nil
+		}
+
+		feedStats, err := store.GetFeedStats(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get feed stats: %w", err)
+		}
+
+		now := time.Now()
+		inactive, err := feedsync.FindInactive(ctx, store, cfg.GetInactiveAfter(), now)
+		if err != nil {
+			return err
+		}
+		inactiveIDs := make(map[string]bool, len(inactive))
+		for _, f := range inactive {
+			inactiveIDs[f.Feed.ID] = true
+		}
+		status := func(row storage.FeedStatsRow) string {
+			switch {
+			case row.ArchivedAt != nil:
+				return "archived"
+			case inactiveIDs[row.FeedID]:
+				return "inactive"
+			default:
+				return "active"
+			}
+		}
+
+		if mode == outputPorcelain {
+			for _, row := range feedStats {
+				title := ""
+				if row.FeedTitle != nil {
+					title = *row.FeedTitle
+				}
+				writePorcelain(out, row.FeedURL, strconv.Itoa(row.EntryCount), strconv.Itoa(row.UnreadCount),
+					strconv.Itoa(row.ErrorCount), porcelainTime(row.LastFetchedAt), title,
+					status(row), porcelainTime(row.LastEntryAt))
+			}
+			return nil
+		}
+
+		fmt.Printf("%s %d  %s %d  %s %s\n",
+			tui.DimStyle.Render("Feeds"), overall.TotalFeeds,
+			tui.DimStyle.Render("Entries"), overall.TotalEntries,
+			tui.DimStyle.Render("Unread"), tui.Badge(overall.UnreadCount))
+
+		progress, err := readingProgress(ctx, overall.UnreadCount)
+		if err != nil {
+			return err
+		}
+		printGoals(progress)
+
+		if len(feedStats) == 0 {
+			return nil
+		}
+
+		rows := make([][]string, 0, len(feedStats))
+		for _, row := range feedStats {
+			name := row.FeedURL
+			if row.FeedTitle != nil && *row.FeedTitle != "" {
+				name = *row.FeedTitle
+			}
+			if s := status(row); s != "active" {
+				name += " " + tui.DimStyle.Render("("+s+")")
+			}
+			errors := ""
+			if row.ErrorCount > 0 {
+				errors = tui.ErrorStyle.Render(strconv.Itoa(row.ErrorCount))
+			}
+			fetched := tui.DimStyle.Render("never")
+			if row.LastFetchedAt != nil {
+				fetched = timeutil.Ago(*row.LastFetchedAt, now)
+			}
+			newest := tui.DimStyle.Render("never")
+			if row.LastEntryAt != nil {
+				newest = timeutil.Ago(*row.LastEntryAt, now)
+			}
+			rows = append(rows, []string{name, tui.Badge(row.UnreadCount), strconv.Itoa(row.EntryCount), errors, fetched, newest})
+		}
+		fmt.Println()
+		fmt.Println(tui.Table([]string{"Feed", "Unread", "Entries", "Errors", "Fetched", "Newest"}, rows, 1, 2, 3))
+
+		return nil
+	},
+}
+
+// readingProgress works out where the reading goals stand, given the
+// number of unread entries.
+func readingProgress(ctx context.Context, unread int) (goals.Progress, error) {
+	loc, err := userLocation()
+	if err != nil {
+		return goals.Progress{}, err
+	}
+	// Only read entries count, and only when they were read
+	var read []*models.Entry
+	err = store.EachEntry(ctx, &storage.EntryFilter{NoContent: true}, func(e *models.Entry) error {
+		if e.ReadAt != nil {
+			read = append(read, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return goals.Progress{}, fmt.Errorf("failed to list entries: %w", err)
+	}
+	return goals.Compute(read, unread, cfg.GetGoals(), time.Now().In(loc)), nil
+}
+
+// printGoals prints the reading streak and goals, with what would meet
+// the ones not met yet.
+func printGoals(p goals.Progress) {
+	met := func(ok bool, s string) string {
+		if ok {
+			return s
+		}
+		return tui.ErrorStyle.Render(s)
+	}
+	today := strconv.Itoa(p.ReadToday)
+	if p.DailyReads > 0 {
+		today = met(p.TodayMet, fmt.Sprintf("%d/%d", p.ReadToday, p.DailyReads))
+	}
+	fmt.Printf("%s %d day(s) %s  %s %s",
+		tui.DimStyle.Render("Streak"), p.Streak, tui.DimStyle.Render(fmt.Sprintf("(best %d)", p.LongestStreak)),
+		tui.DimStyle.Render("Read today"), today)
+	if p.MaxUnread > 0 {
+		fmt.Printf("  %s %s", tui.DimStyle.Render("Unread goal"), met(p.InboxMet, fmt.Sprintf("%d/%d", p.Unread, p.MaxUnread)))
+	}
+	fmt.Println()
+	for _, nudge := range p.Nudges() {
+		fmt.Println(tui.DimStyle.Render(nudge))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	addOutputFlags(statsCmd, "print only the unread count")
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Sync status command showing what the last sync of the profile did
+// ABOUTME: Reads the summary 'digest fetch' and sync_feeds save, so nothing is fetched again
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/harper/digest/internal/timeutil"
+)
+
+var syncStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show what the last sync did",
+	Long: `Show the last sync of the profile, whether run by 'digest fetch' or the
+sync_feeds MCP tool: when it ran, how long it took, and each feed's result.
+Nothing is fetched.
+
+--porcelain prints a "run" record:
+  run, started_at, duration_ms, source
+then one record per feed as 'digest fetch --porcelain' does:
+  status (ok/cached/skipped/error), url, new_entries, detail
+followed by the summary record:
+  summary, synced, new_entries, cached, skipped, errors`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := lastSyncPath()
+		if err != nil {
+			return err
+		}
+		run, err := feedsync.LoadRun(path)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return fmt.Errorf("no sync recorded yet; run 'digest fetch'")
+		}
+
+		if getOutputMode(cmd) == outputPorcelain {
+			out := cmd.OutOrStdout()
+			writePorcelain(out, "run", porcelainTime(&run.StartedAt), strconv.FormatInt(run.DurationMS, 10), run.Source)
+			for _, f := range run.Feeds {
+				writePorcelain(out, f.Status, f.URL, strconv.Itoa(f.NewEntries), f.Detail)
+			}
+			writePorcelain(out, "summary", strconv.Itoa(run.Synced), strconv.Itoa(run.NewEntries),
+				strconv.Itoa(run.Cached), strconv.Itoa(run.Skipped), strconv.Itoa(run.Errors))
+			return nil
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+		faint := color.New(color.Faint).SprintFunc()
+		fmt.Printf("Last sync %s (%s) via %s, took %s\n",
+			run.StartedAt.Local().Format(time.DateTime), timeutil.Ago(run.StartedAt, time.Now()),
+			run.Source, run.Duration().Round(10*time.Millisecond))
+		for _, f := range run.Feeds {
+			switch f.Status {
+			case feedsync.RunError:
+				fmt.Printf("  %s %s %s\n", red("x"), f.Title, f.Detail)
+			case feedsync.RunSkipped:
+				fmt.Printf("  %s %s %s\n", faint("-"), f.Title, faint("("+f.Detail+")"))
+			case feedsync.RunCached:
+				fmt.Printf("  %s %s %s\n", faint("-"), f.Title, faint("(cached)"))
+			default:
+				truncated := ""
+				if f.Truncated {
+					truncated = " " + faint("(truncated: feed too large)")
+				}
+				fmt.Printf("  %s %s %d new%s\n", green("v"), f.Title, f.NewEntries, truncated)
+			}
+		}
+		fmt.Println()
+		fmt.Printf("%d feed(s) synced: %d new entries, %d cached, %d skipped, %d errors\n",
+			run.Synced, run.NewEntries, run.Cached, run.Skipped, run.Errors)
+		if run.Archived > 0 {
+			fmt.Println(faint(fmt.Sprintf("%d inactive feed(s) archived", run.Archived)))
+		}
+		return nil
+	},
+}
+
+func init() {
+	fetchCmd.AddCommand(syncStatusCmd)
+	syncStatusCmd.Flags().Bool("porcelain", false, "stable tab-separated output for scripts")
+}
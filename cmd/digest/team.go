@@ -0,0 +1,241 @@
+// ABOUTME: Team commands adding users who share this instance through 'digest serve'
+// ABOUTME: Each user gets an API token in the secrets store and their own read state
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/secrets"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/team"
+)
+
+var teamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Share this instance with other users",
+	Long: `Let several people share one set of subscriptions through 'digest serve',
+each with their own read and keep-unread state.
+
+Each user has a role. Readers read entries and mark them read, unread,
+or kept; admins can also add and remove feeds. Users are readers unless
+added with --role admin or changed with 'digest team role'. The role
+applies both to the /api/ endpoints and to 'digest mcp --user'.
+
+Once a user is added, 'digest serve' asks every request for a token, sent
+as "Authorization: Bearer <token>" or, for feed readers, as ?token=<token>.
+Each user's /feed.xml shows what they haven't read, and the /api/
+endpoints mark entries read for them alone. Your own CLI reading stays
+separate from every user's.
+
+Team mode needs the sqlite storage backend.
+
+Examples:
+  digest team add alice
+  digest team add bob --role admin
+  digest team role alice admin
+  digest team list
+  digest team token alice     # issue a new token, revoking the old one
+  digest team remove alice`,
+}
+
+var teamAddCmd = &cobra.Command{
+	Use:   "add <user>",
+	Short: "Add a user and print their API token",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user := args[0]
+		roleName, _ := cmd.Flags().GetString("role")
+		role, err := team.ParseRole(roleName)
+		if err != nil {
+			return err
+		}
+		if !storage.ValidUserName(user) {
+			return fmt.Errorf("user names are lowercase letters, digits, - and _, up to 32 characters")
+		}
+		if _, err := storage.ForUser(store, user); err != nil {
+			if errors.Is(err, storage.ErrNoUserState) {
+				return fmt.Errorf("%w; set \"backend\": \"sqlite\" in config.json (see 'digest migrate')", err)
+			}
+			return err
+		}
+		if cfg.GetTeam().Has(user) {
+			return fmt.Errorf("%s is already on the team; use 'digest team token %s' for a new token", user, user)
+		}
+		token, err := issueTeamToken(user)
+		if err != nil {
+			return err
+		}
+		if cfg.Team == nil {
+			cfg.Team = &team.Config{}
+		}
+		cfg.Team.Users = append(cfg.Team.Users, user)
+		setTeamRole(user, role)
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Added %s as %s\n", green("v"), user, role)
+		printTeamToken(token)
+		return nil
+	},
+}
+
+var teamTokenCmd = &cobra.Command{
+	Use:   "token <user>",
+	Short: "Issue a user a new API token",
+	Long:  "Issue a user a new API token. Their old token stops working.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user := args[0]
+		if !cfg.GetTeam().Has(user) {
+			return fmt.Errorf("%s is not on the team", user)
+		}
+		token, err := issueTeamToken(user)
+		if err != nil {
+			return err
+		}
+		printTeamToken(token)
+		return nil
+	},
+}
+
+var teamRoleCmd = &cobra.Command{
+	Use:   "role <user> <reader|admin>",
+	Short: "Change a user's role",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user := args[0]
+		if !cfg.GetTeam().Has(user) {
+			return fmt.Errorf("%s is not on the team", user)
+		}
+		role, err := team.ParseRole(args[1])
+		if err != nil {
+			return err
+		}
+		setTeamRole(user, role)
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Printf("%s is now %s\n", user, role)
+		return nil
+	},
+}
+
+var teamRemoveCmd = &cobra.Command{
+	Use:   "remove <user>",
+	Short: "Remove a user, their token, and their read state",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user := args[0]
+		if !cfg.GetTeam().Has(user) {
+			return fmt.Errorf("%s is not on the team", user)
+		}
+		cfg.Team.Users = slices.DeleteFunc(cfg.Team.Users, func(u string) bool { return u == user })
+		setTeamRole(user, team.RoleReader)
+		if len(cfg.Team.Users) == 0 {
+			cfg.Team = nil
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		if provider, err := cfg.Secrets(); err == nil {
+			if err := provider.Delete(config.TeamTokenSecret(user)); err != nil && !errors.Is(err, secrets.ErrNotFound) && !errors.Is(err, secrets.ErrReadOnly) {
+				return fmt.Errorf("failed to delete token: %w", err)
+			}
+		}
+		if us, ok := store.(storage.UserStore); ok {
+			if err := us.DeleteUserState(cmd.Context(), user); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Removed %s\n", user)
+		return nil
+	},
+}
+
+var teamListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List users with their read statistics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t := cfg.GetTeam()
+		if !t.Enabled() {
+			fmt.Println("No team users. Add one with 'digest team add <user>'.")
+			return nil
+		}
+		faint := color.New(color.Faint).SprintFunc()
+		for _, user := range t.Users {
+			stats, err := team.UserStats(cmd.Context(), store, user)
+			if err != nil {
+				return err
+			}
+			line := fmt.Sprintf("%-16s %-7s %d unread, %d read, %d kept", user, t.RoleOf(user), stats.Unread, stats.Read, stats.Kept)
+			if t.Tokens[user] == "" {
+				line += faint("  (no token; run 'digest team token " + user + "')")
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+// setTeamRole records user's role in cfg; readers, the default, aren't
+// listed. The caller saves cfg.
+func setTeamRole(user string, role team.Role) {
+	if role == team.RoleReader {
+		delete(cfg.Team.Roles, user)
+		if len(cfg.Team.Roles) == 0 {
+			cfg.Team.Roles = nil
+		}
+		return
+	}
+	if cfg.Team.Roles == nil {
+		cfg.Team.Roles = map[string]team.Role{}
+	}
+	cfg.Team.Roles[user] = role
+}
+
+// issueTeamToken stores a fresh API token for user and returns it.
+func issueTeamToken(user string) (string, error) {
+	provider, err := cfg.Secrets()
+	if err != nil {
+		return "", err
+	}
+	token, err := team.NewToken()
+	if err != nil {
+		return "", err
+	}
+	if err := provider.Set(config.TeamTokenSecret(user), token); err != nil {
+		if errors.Is(err, secrets.ErrReadOnly) {
+			return "", fmt.Errorf("the env secrets backend can't store tokens; use the keyring or file backend")
+		}
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+	return token, nil
+}
+
+func printTeamToken(token string) {
+	faint := color.New(color.Faint).SprintFunc()
+	fmt.Printf("Token: %s\n", token)
+	fmt.Println(faint("Shown once. Send it as \"Authorization: Bearer <token>\" or add ?token=<token> to feed URLs."))
+}
+
+func init() {
+	rootCmd.AddCommand(teamCmd)
+	teamCmd.AddCommand(teamAddCmd)
+	teamCmd.AddCommand(teamTokenCmd)
+	teamCmd.AddCommand(teamRoleCmd)
+	teamCmd.AddCommand(teamRemoveCmd)
+	teamCmd.AddCommand(teamListCmd)
+
+	teamAddCmd.Flags().String("role", string(team.RoleReader), "reader or admin")
+	_ = teamAddCmd.RegisterFlagCompletionFunc("role", cobra.FixedCompletions([]string{string(team.RoleReader), string(team.RoleAdmin)}, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,157 @@
+// ABOUTME: Trending command that shows the keywords and names spiking across recent entries
+// ABOUTME: TF-IDF over a date range against earlier entries, overall or broken down by folder
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/trends"
+)
+
+var trendingCmd = &cobra.Command{
+	Use:   "trending",
+	Short: "Show trending topics across recent entries",
+	Long: `Extract the keywords and named entities trending across entries in a
+date range. Terms are scored with TF-IDF against the entries from the four
+preceding periods, so words that are always common rank low and new ones
+rank high. A term must appear in at least two entries to be reported.
+
+--quiet prints only the terms.
+--porcelain prints one tab-separated record per topic:
+  folder, term, kind (keyword or entity), score, entries
+The folder field is empty for overall topics.
+
+Examples:
+  digest trending                      # This week
+  digest trending --since month --by-folder
+  digest trending --since 2026-10-01 --until 2026-10-08 -c Tech`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		mode := getOutputMode(cmd)
+		out := cmd.OutOrStdout()
+		sinceValue, _ := cmd.Flags().GetString("since")
+		untilValue, _ := cmd.Flags().GetString("until")
+		category, _ := cmd.Flags().GetString("category")
+		limit, _ := cmd.Flags().GetInt("limit")
+		byFolder, _ := cmd.Flags().GetBool("by-folder")
+
+		window, err := parseSinceRange(sinceValue)
+		if err != nil {
+			return err
+		}
+		if window.Since.IsZero() {
+			return fmt.Errorf("invalid --since: the range needs a start")
+		}
+		since, until := window.Since, time.Now()
+		if !window.Until.IsZero() {
+			if untilValue != "" {
+				return fmt.Errorf("--since is a range, so --until must be omitted")
+			}
+			until = window.Until
+		}
+		if untilValue != "" {
+			until, err = parseDateFlag("until", untilValue)
+			if err != nil {
+				return err
+			}
+		}
+		if !until.After(since) {
+			return fmt.Errorf("--until must be after --since")
+		}
+		if limit <= 0 {
+			return fmt.Errorf("--limit must be positive")
+		}
+
+		folders := make(map[string]string)
+		for _, feed := range opmlDoc.AllFeeds() {
+			folders[feed.URL] = feed.Folder
+		}
+		docs, err := trends.Collect(ctx, store, func(url string) string { return folders[url] }, since, until)
+		if err != nil {
+			return err
+		}
+		if category != "" {
+			kept := docs[:0]
+			for _, doc := range docs {
+				if doc.Folder == category {
+					kept = append(kept, doc)
+				}
+			}
+			docs = kept
+			byFolder = false
+		}
+		report := trends.Analyze(docs, trends.Options{Limit: limit, ByFolder: byFolder})
+
+		switch mode {
+		case outputQuiet:
+			for _, topic := range report.Topics {
+				fmt.Fprintln(out, topic.Term)
+			}
+			return nil
+		case outputPorcelain:
+			writeTopics := func(folder string, topics []trends.Topic) {
+				for _, topic := range topics {
+					writePorcelain(out, folder, topic.Term, topic.Kind,
+						strconv.FormatFloat(topic.Score, 'f', 2, 64), strconv.Itoa(topic.Entries))
+				}
+			}
+			writeTopics("", report.Topics)
+			for _, f := range report.Folders {
+				writeTopics(folderLabel(f.Folder), f.Topics)
+			}
+			return nil
+		}
+
+		if len(report.Topics) == 0 {
+			fmt.Printf("No trending topics in %d entries since %s\n", report.Entries, since.Format("Jan 2"))
+			return nil
+		}
+
+		faint := color.New(color.Faint).SprintFunc()
+		bold := color.New(color.Bold).SprintFunc()
+		printTopics := func(topics []trends.Topic) {
+			for i, topic := range topics {
+				fmt.Printf("%2d. %s %s\n", i+1, topic.Term, faint(fmt.Sprintf("(%d entries)", topic.Entries)))
+			}
+		}
+
+		fmt.Printf("%s %s\n\n", bold("Trending since "+since.Format("Jan 2")), faint(fmt.Sprintf("(%d entries)", report.Entries)))
+		printTopics(report.Topics)
+		for _, f := range report.Folders {
+			if len(f.Topics) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s %s\n", bold(folderLabel(f.Folder)), faint(fmt.Sprintf("(%d entries)", f.Entries)))
+			printTopics(f.Topics)
+		}
+		return nil
+	},
+}
+
+// folderLabel names the root level for display.
+func folderLabel(folder string) string {
+	if folder == "" {
+		return "Uncategorized"
+	}
+	return folder
+}
+
+func init() {
+	rootCmd.AddCommand(trendingCmd)
+
+	trendingCmd.Flags().String("since", "week", "start of the range, such as week, '3 days ago', or a whole range from..until")
+	trendingCmd.Flags().String("until", "", "end of the range, exclusive, such as yesterday or YYYY-MM-DD (default now)")
+	trendingCmd.Flags().StringP("category", "c", "", "only analyze feeds in this folder")
+	trendingCmd.Flags().IntP("limit", "n", 10, "topics to show overall and per folder")
+	trendingCmd.Flags().Bool("by-folder", false, "add a per-folder breakdown")
+	addOutputFlags(trendingCmd, "print only the terms")
+	_ = trendingCmd.RegisterFlagCompletionFunc("since", cobra.FixedCompletions(sinceCompletions, cobra.ShellCompDirectiveNoFileComp))
+	_ = trendingCmd.RegisterFlagCompletionFunc("category", folderFlag)
+}
@@ -0,0 +1,265 @@
+// ABOUTME: Triage command for clearing a backlog headline by headline with single-key actions
+// ABOUTME: Reads, stars, opens, snoozes, mutes authors, or skips each unread entry as it's shown
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/authors"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/queue"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/timeutil"
+	"github.com/harper/digest/internal/tui"
+)
+
+// defaultTriageLimit is how many headlines one triage walks through.
+const defaultTriageLimit = 500
+
+var triageCmd = &cobra.Command{
+	Use:   "triage",
+	Short: "Clear unread headlines one key at a time",
+	Long: `Walk through unread headlines one at a time, deciding each with a single
+key, to clear hundreds of entries quickly without reading them:
+
+  r, Enter  mark read
+  s         star: keep it unread, pinned, to read later
+  o         open the link in the browser (marked read as 'digest open' does)
+  z         snooze: hide it from triage and 'digest next' for --snooze
+  m         mute the author: mark all their unread entries read, and those
+            of later triages too ('digest authors unmute' undoes it)
+  Space, n  skip it for now
+  q, Esc    stop
+
+Headlines come newest first, high-priority feeds first. Entries already
+starred or snoozed are left out. Where the catch-up prompt summarizes a
+backlog, triage is for deciding it headline by headline.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		limit, _ := cmd.Flags().GetInt("limit")
+		snoozeFor, _ := cmd.Flags().GetDuration("snooze")
+		feedFilter, _ := cmd.Flags().GetString("feed")
+		category, _ := cmd.Flags().GetString("category")
+		if limit <= 0 {
+			return fmt.Errorf("--limit must be positive")
+		}
+		if snoozeFor <= 0 {
+			return fmt.Errorf("--snooze must be positive")
+		}
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("triage needs an interactive terminal")
+		}
+
+		filter, err := feedScopeFilter(ctx, feedFilter, category)
+		if err != nil {
+			return err
+		}
+		unreadOnly := true
+		filter.UnreadOnly = &unreadOnly
+		filter.ByPriority = true
+		filter.NoContent = true
+
+		snoozes, err := loadSnoozes()
+		if err != nil {
+			return err
+		}
+		muted, err := loadMuted()
+		if err != nil {
+			return err
+		}
+		feeds, err := store.ListFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list feeds: %w", err)
+		}
+		feedNames := make(map[string]string, len(feeds))
+		for _, f := range feeds {
+			feedNames[f.ID] = f.GetDisplayName()
+		}
+		feedIcons := feedIconColors(feeds)
+
+		faint := color.New(color.Faint).SprintFunc()
+		now := time.Now()
+		snoozed := snoozes.Active(now)
+		entries := make(map[string]*models.Entry)
+		var items []tui.TriageItem
+		var mutedIDs []string
+		err = store.EachEntry(ctx, &filter, func(e *models.Entry) error {
+			if e.KeepUnread || snoozed[e.ID] {
+				return nil
+			}
+			if isMuted(muted, e) {
+				mutedIDs = append(mutedIDs, e.ID)
+				return nil
+			}
+			if len(items) >= limit {
+				return nil
+			}
+			entries[e.ID] = e
+			item := tui.TriageItem{ID: e.ID, Title: e.GetTitle(), Feed: feedNames[e.FeedID], FeedIcon: feedIcons[e.FeedID]}
+			if e.Author != nil {
+				item.Author = *e.Author
+			}
+			if e.PublishedAt != nil {
+				item.Date = timeutil.Ago(*e.PublishedAt, now)
+			}
+			items = append(items, item)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		for _, id := range mutedIDs {
+			if err := store.MarkEntryRead(ctx, id); err != nil {
+				return fmt.Errorf("failed to mark entry as read: %w", err)
+			}
+		}
+		if len(mutedIDs) > 0 {
+			fmt.Println(faint(fmt.Sprintf("Marked %d entry(s) by muted authors read", len(mutedIDs))))
+		}
+		if len(items) == 0 {
+			fmt.Println("Nothing to triage.")
+			return nil
+		}
+
+		apply := func(item tui.TriageItem, action tui.TriageAction) ([]string, error) {
+			entry := entries[item.ID]
+			switch action {
+			case tui.TriageRead:
+				return nil, store.MarkEntryRead(ctx, entry.ID)
+			case tui.TriageStar:
+				return nil, store.SetEntryKeepUnread(ctx, entry.ID, true)
+			case tui.TriageOpen:
+				link, err := entryBrowserLink(entry)
+				if err != nil {
+					return nil, err
+				}
+				if err := openBrowser(link); err != nil {
+					return nil, fmt.Errorf("failed to open browser: %w", err)
+				}
+				if cfg.GetOpenMarksRead() {
+					return nil, store.MarkEntryRead(ctx, entry.ID)
+				}
+			case tui.TriageSnooze:
+				snoozes[entry.ID] = time.Now().Add(snoozeFor)
+				return nil, saveSnoozes(snoozes)
+			case tui.TriageMute:
+				if item.Author == "" {
+					return nil, fmt.Errorf("no author to mute")
+				}
+				return muteAuthor(ctx, item.Author)
+			}
+			return nil, nil
+		}
+
+		final, err := tui.Triage(items, apply)
+		if err != nil {
+			return err
+		}
+
+		var parts []string
+		total := 0
+		for _, a := range tui.TriageActions {
+			if n := final.Counts()[a]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", n, a))
+				total += n
+			}
+		}
+		if total == 0 {
+			fmt.Println("Nothing triaged.")
+			return nil
+		}
+		fmt.Printf("Triaged %d of %d headline(s): %s\n", total, len(items), strings.Join(parts, ", "))
+		if n := final.Others(); n > 0 {
+			fmt.Println(faint(fmt.Sprintf("%d more by muted authors marked read", n)))
+		}
+		return nil
+	},
+}
+
+// muteAuthor adds name to the muted authors and marks every unread entry
+// by them read, returning those entries' IDs.
+func muteAuthor(ctx context.Context, name string) ([]string, error) {
+	muted, err := loadMuted()
+	if err != nil {
+		return nil, err
+	}
+	if muted, changed := authors.Follow(muted, name); changed {
+		if err := saveMuted(muted); err != nil {
+			return nil, err
+		}
+	}
+
+	unreadOnly := true
+	var ids []string
+	err = store.EachEntry(ctx, &storage.EntryFilter{UnreadOnly: &unreadOnly, NoContent: true}, func(e *models.Entry) error {
+		if !e.KeepUnread && authors.MatchEntry(name, e) {
+			ids = append(ids, e.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+	for _, id := range ids {
+		if err := store.MarkEntryRead(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to mark entry as read: %w", err)
+		}
+	}
+	return ids, nil
+}
+
+// isMuted reports whether a muted author wrote e.
+func isMuted(muted []string, e *models.Entry) bool {
+	for _, name := range muted {
+		if authors.MatchEntry(name, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// snoozePath returns the active profile's snoozed-entries file.
+func snoozePath() (string, error) {
+	profileDir, err := cfg.ProfileDataDir(profileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid profile: %w", err)
+	}
+	return queue.SnoozePath(profileDir), nil
+}
+
+func loadSnoozes() (queue.Snoozes, error) {
+	path, err := snoozePath()
+	if err != nil {
+		return nil, err
+	}
+	return queue.LoadSnoozes(path)
+}
+
+func saveSnoozes(s queue.Snoozes) error {
+	path, err := snoozePath()
+	if err != nil {
+		return err
+	}
+	return queue.SaveSnoozes(path, s, time.Now())
+}
+
+func init() {
+	rootCmd.AddCommand(triageCmd)
+
+	triageCmd.Flags().Int("limit", defaultTriageLimit, "most headlines to walk through")
+	triageCmd.Flags().Duration("snooze", queue.DefaultSnooze, "how long z hides an entry for")
+	triageCmd.Flags().StringP("feed", "f", "", "only triage entries of this feed (URL or ID prefix)")
+	triageCmd.Flags().StringP("category", "c", "", "only triage entries of feeds in this category/folder")
+	_ = triageCmd.RegisterFlagCompletionFunc("feed", feedURLFlag)
+	_ = triageCmd.RegisterFlagCompletionFunc("category", folderFlag)
+}
@@ -0,0 +1,93 @@
+// ABOUTME: Unread command printing a short unread count for status bars like tmux, starship, and waybar
+// ABOUTME: Answers from counts cached after every command that touches entries, so it stays fast
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/badge"
+)
+
+var unreadCmd = &cobra.Command{
+	Use:   "unread",
+	Short: "Print the unread count for a status bar",
+	Long: `Print the number of unread entries, formatted for a status bar. The count
+comes from a small cache that 'digest fetch', the MCP sync_feeds tool, and
+every command that reads or marks entries keep up to date, so storage isn't
+opened and the command returns in a few milliseconds.
+
+--format fills in these placeholders:
+  {count}         unread in --folder, or in total without one
+  {count:Name}    unread in the folder called Name
+  {total}         unread in total
+  {folder}        the --folder name
+
+Examples:
+  tmux      set -g status-right '#(digest unread --format " {count}" --hide-zero)'
+  starship  [custom.digest]
+            command = "digest unread --hide-zero"
+            when = true
+  waybar    "exec": "digest unread --format '{\"text\": \"{count}\", \"tooltip\": \"{count:Work} in Work\"}'"
+
+--refresh recounts from storage instead of trusting the cache.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		folder, _ := cmd.Flags().GetString("folder")
+		hideZero, _ := cmd.Flags().GetBool("hide-zero")
+
+		path, err := unreadCountsPath()
+		if err != nil {
+			return err
+		}
+		var counts *badge.Counts
+		if store != nil {
+			// No cache yet, or --refresh: count and cache for next time
+			counts, err = badge.Refresh(cmd.Context(), store, opmlDoc, path)
+		} else {
+			counts, err = badge.Load(path)
+		}
+		if err != nil {
+			return err
+		}
+		if folder != "" {
+			if _, ok := counts.Folders[folder]; !ok {
+				return fmt.Errorf("no folder %q", folder)
+			}
+		}
+
+		if hideZero && counts.Count(folder) == 0 {
+			return nil
+		}
+		fmt.Println(counts.Format(format, folder))
+		return nil
+	},
+}
+
+// unreadCached reports whether 'digest unread' can answer from the cached
+// counts of the active profile, without opening storage.
+func unreadCached(cmd *cobra.Command) bool {
+	if refresh, _ := cmd.Flags().GetBool("refresh"); refresh {
+		return false
+	}
+	path, err := unreadCountsPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+func init() {
+	rootCmd.AddCommand(unreadCmd)
+
+	unreadCmd.Flags().String("format", badge.DefaultFormat, "output template with {count}, {count:Folder}, {total}, and {folder}")
+	unreadCmd.Flags().String("folder", "", "count only the feeds in this OPML folder")
+	unreadCmd.Flags().Bool("hide-zero", false, "print nothing when there are no unread entries")
+	unreadCmd.Flags().Bool("refresh", false, "recount from storage and update the cache")
+	_ = unreadCmd.RegisterFlagCompletionFunc("folder", folderFlag)
+}
@@ -1,12 +1,15 @@
 // ABOUTME: Version command for digest CLI
-// ABOUTME: Displays version, commit, and build date information
+// ABOUTME: Displays version, commit, and build date information, and checks for newer releases
 
 package main
 
 import (
 	"fmt"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+
+	"github.com/harper/digest/internal/update"
 )
 
 // Version information set via ldflags at build time
@@ -14,19 +17,54 @@ var (
 	Version   = "dev"
 	Commit    = "unknown"
 	BuildDate = "unknown"
+
+	// ReleaseKey is the base64 ed25519 public key release checksums are
+	// signed with. Release builds without one refuse to self-update.
+	ReleaseKey = ""
 )
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
-	Long:  "Print the version, commit hash, and build date of digest.",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Print the version, commit hash, and build date of digest.
+
+With --check, also look up the latest release on the configured channel
+and warn when this build is older.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Printf("digest %s\n", Version)
 		fmt.Printf("  commit:  %s\n", Commit)
 		fmt.Printf("  built:   %s\n", BuildDate)
+
+		check, _ := cmd.Flags().GetBool("check")
+		if !check {
+			return nil
+		}
+		channel, err := updateChannel(cmd)
+		if err != nil {
+			return err
+		}
+		latest, err := update.NewClient().Latest(cmd.Context(), channel)
+		if err != nil {
+			return err
+		}
+		yellow := color.New(color.FgYellow).SprintFunc()
+		switch {
+		case !update.IsRelease(Version):
+			fmt.Printf("  latest:  %s (%s); this is a development build\n", latest.Tag, channel)
+		case update.Newer(latest.Tag, Version):
+			fmt.Printf("  latest:  %s (%s)\n", latest.Tag, channel)
+			fmt.Println(yellow(fmt.Sprintf("digest %s is out of date; run 'digest self-update' for %s", Version, latest.Tag)))
+		default:
+			fmt.Printf("  latest:  %s (%s); up to date\n", latest.Tag, channel)
+		}
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().Bool("check", false, "check GitHub for a newer release")
+	versionCmd.Flags().String("channel", "", "release channel to check: stable or edge (default: update_channel in config.json)")
+	_ = versionCmd.RegisterFlagCompletionFunc("channel", cobra.FixedCompletions(update.Channels, cobra.ShellCompDirectiveNoFileComp))
 }
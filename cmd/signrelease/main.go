@@ -0,0 +1,37 @@
+// ABOUTME: Release helper that signs checksums.txt for self-update to verify
+// ABOUTME: Run by GoReleaser with the ed25519 key in DIGEST_RELEASE_SIGNING_KEY; writes the base64 signature
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/harper/digest/internal/update"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: signrelease <checksums.txt> <checksums.txt.sig>")
+		os.Exit(2)
+	}
+	if err := run(os.Args[1], os.Args[2], os.Getenv("DIGEST_RELEASE_SIGNING_KEY")); err != nil {
+		fmt.Fprintf(os.Stderr, "signrelease: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(checksumsPath, sigPath, key string) error {
+	if key == "" {
+		return fmt.Errorf("DIGEST_RELEASE_SIGNING_KEY is not set")
+	}
+	checksums, err := os.ReadFile(checksumsPath)
+	if err != nil {
+		return err
+	}
+	sig, err := update.Sign(checksums, key)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sigPath, sig, 0644)
+}
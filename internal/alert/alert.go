@@ -0,0 +1,351 @@
+// ABOUTME: Feed failure alerts sent by webhook, ntfy, or email after a sync run
+// ABOUTME: Fires when a feed reaches a run of consecutive errors or a run has too many failures
+
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// DefaultErrorThreshold is how many syncs in a row a feed must fail before
+// it is reported, when ErrorThreshold is unset.
+const DefaultErrorThreshold = 5
+
+// DefaultSMTPPort is the submission port used when Email.Port is unset.
+const DefaultSMTPPort = 587
+
+// sendTimeout bounds each delivery so a dead endpoint can't stall a sync.
+const sendTimeout = 15 * time.Second
+
+// Config selects when alerts fire and where they go. Nothing is sent
+// unless at least one of Webhook, Ntfy, or Email is set.
+type Config struct {
+	// ErrorThreshold reports a feed when its consecutive error count
+	// reaches this many. Default 5; negative turns it off.
+	ErrorThreshold int `json:"error_threshold,omitempty"`
+
+	// RunFailures reports every failing feed when at least this many fail
+	// in one sync run. Zero turns it off.
+	RunFailures int `json:"run_failures,omitempty"`
+
+	// Webhook receives the alert as a JSON POST. The payload's "text"
+	// field makes it work as a Slack or Mattermost incoming webhook.
+	Webhook string `json:"webhook,omitempty"`
+
+	// Ntfy is an ntfy topic URL, e.g. "https://ntfy.sh/my-digest-alerts".
+	Ntfy string `json:"ntfy,omitempty"`
+
+	// NtfyToken is an ntfy access token for protected topics. It is read
+	// from the secrets store, never from config.json.
+	NtfyToken string `json:"-"`
+
+	// Email sends alerts through an SMTP server.
+	Email *EmailConfig `json:"email,omitempty"`
+}
+
+// EmailConfig describes an SMTP server and recipients.
+type EmailConfig struct {
+	To       []string `json:"to"`
+	From     string   `json:"from,omitempty"`
+	Host     string   `json:"smtp_host"`
+	Port     int      `json:"smtp_port,omitempty"`
+	Username string   `json:"username,omitempty"`
+
+	// Password is read from the secrets store, never from config.json.
+	Password string `json:"-"`
+}
+
+// Enabled reports whether any delivery channel is configured.
+func (c Config) Enabled() bool {
+	return c.Webhook != "" || c.Ntfy != "" || (c.Email != nil && len(c.Email.To) > 0)
+}
+
+// Threshold returns the consecutive error count that triggers an alert, or
+// 0 if per-feed alerts are off.
+func (c Config) Threshold() int {
+	switch {
+	case c.ErrorThreshold < 0:
+		return 0
+	case c.ErrorThreshold == 0:
+		return DefaultErrorThreshold
+	default:
+		return c.ErrorThreshold
+	}
+}
+
+// Alert describes failing feeds after a sync run.
+type Alert struct {
+	Text      string        `json:"text"`
+	Summary   string        `json:"summary"`
+	Profile   string        `json:"profile"`
+	Attempted int           `json:"attempted"`
+	Failed    int           `json:"failed"`
+	Feeds     []FailingFeed `json:"feeds"`
+	Time      time.Time     `json:"time"`
+}
+
+// FailingFeed is one feed in an alert.
+type FailingFeed struct {
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+	ErrorCount int    `json:"error_count"`
+	LastError  string `json:"last_error,omitempty"`
+}
+
+// Check reloads the feeds that failed in a sync run, so their error counts
+// include this run, and returns the alert to send, or nil if none is due.
+func Check(ctx context.Context, store storage.Store, cfg Config, profile string, failedIDs []string, attempted int) (*Alert, error) {
+	if !cfg.Enabled() || len(failedIDs) == 0 {
+		return nil, nil
+	}
+	failed := make([]*models.Feed, 0, len(failedIDs))
+	for _, id := range failedIDs {
+		feed, err := store.GetFeed(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("reload failed feed: %w", err)
+		}
+		failed = append(failed, feed)
+	}
+	return Evaluate(cfg, profile, failed, attempted, time.Now()), nil
+}
+
+// Evaluate decides whether failed, the feeds that failed in a run of
+// attempted feeds, warrant an alert. A feed is reported on the run its
+// error count reaches the threshold, not on every failure after, so a dead
+// feed alerts once until it recovers and fails again.
+func Evaluate(cfg Config, profile string, failed []*models.Feed, attempted int, now time.Time) *Alert {
+	var report []*models.Feed
+	var summary string
+	if cfg.RunFailures > 0 && len(failed) >= cfg.RunFailures {
+		report = failed
+		summary = fmt.Sprintf("digest: %d of %d feeds failed to sync", len(failed), attempted)
+	} else if threshold := cfg.Threshold(); threshold > 0 {
+		for _, feed := range failed {
+			if feed.ErrorCount == threshold {
+				report = append(report, feed)
+			}
+		}
+		if len(report) == 1 {
+			summary = fmt.Sprintf("digest: %s has failed %d syncs in a row", report[0].GetDisplayName(), threshold)
+		} else {
+			summary = fmt.Sprintf("digest: %d feeds have failed %d syncs in a row", len(report), threshold)
+		}
+	}
+	if len(report) == 0 {
+		return nil
+	}
+	if profile != "" && profile != "default" {
+		summary += " (profile " + profile + ")"
+	}
+
+	a := &Alert{
+		Summary:   summary,
+		Profile:   profile,
+		Attempted: attempted,
+		Failed:    len(failed),
+		Time:      now.UTC(),
+	}
+	for _, feed := range report {
+		f := FailingFeed{URL: feed.URL, Title: feed.GetDisplayName(), ErrorCount: feed.ErrorCount}
+		if feed.LastError != nil {
+			f.LastError = *feed.LastError
+		}
+		a.Feeds = append(a.Feeds, f)
+	}
+	a.Text = a.body()
+	return a
+}
+
+// body renders the alert as plain text: the summary, then one paragraph per
+// feed.
+func (a *Alert) body() string {
+	var b strings.Builder
+	b.WriteString(a.Summary)
+	b.WriteString("\n")
+	for _, f := range a.Feeds {
+		fmt.Fprintf(&b, "\n%s\n", f.Title)
+		if f.Title != f.URL {
+			fmt.Fprintf(&b, "  %s\n", f.URL)
+		}
+		fmt.Fprintf(&b, "  %d errors in a row", f.ErrorCount)
+		if f.LastError != "" {
+			fmt.Fprintf(&b, ": %s", f.LastError)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Send delivers a to every configured channel, returning the errors of
+// any that failed.
+func Send(ctx context.Context, cfg Config, a *Alert) error {
+	client := &http.Client{Timeout: sendTimeout, Transport: fetch.Transport()}
+	var errs []error
+	if cfg.Webhook != "" {
+		if err := sendWebhook(ctx, client, cfg.Webhook, a); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if cfg.Ntfy != "" {
+		if err := sendNtfy(ctx, client, cfg.Ntfy, cfg.NtfyToken, a); err != nil {
+			errs = append(errs, fmt.Errorf("ntfy: %w", err))
+		}
+	}
+	if cfg.Email != nil && len(cfg.Email.To) > 0 {
+		if err := sendEmail(ctx, *cfg.Email, a); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sendWebhook(ctx context.Context, client *http.Client, url string, a *Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(client, req)
+}
+
+func sendNtfy(ctx context.Context, client *http.Client, topicURL, token string, a *Alert) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, topicURL, strings.NewReader(a.Text))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", headerText(a.Summary))
+	req.Header.Set("Tags", "warning")
+	req.Header.Set("Priority", "high")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return do(client, req)
+}
+
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// headerText makes s safe as a header value: feed titles end up in the
+// summary, so line breaks are flattened to keep them from adding headers,
+// and non-ASCII text is RFC 2047 encoded, which ntfy decodes too.
+func headerText(s string) string {
+	s = strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+	return mime.QEncoding.Encode("utf-8", s)
+}
+
+func sendEmail(ctx context.Context, cfg EmailConfig, a *Alert) error {
+	if cfg.Host == "" {
+		return fmt.Errorf("smtp_host is not set")
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = DefaultSMTPPort
+	}
+	from := cfg.From
+	if from == "" {
+		from = cfg.Username
+	}
+	if from == "" {
+		return fmt.Errorf("set from or username")
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerText(a.Summary))
+	fmt.Fprintf(&msg, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(a.Text, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		// PlainAuth refuses to send the password unless the connection is
+		// TLS (SendMail upgrades with STARTTLS) or to localhost
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	return sendMail(ctx, addr, cfg.Host, auth, from, cfg.To, msg.Bytes())
+}
+
+// sendMail is smtp.SendMail bounded by sendTimeout, which covers the whole
+// exchange, not just the dial, so a server that stops answering can't
+// stall a sync.
+func sendMail(ctx context.Context, addr, host string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	dialer := net.Dialer{Timeout: sendTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("%s doesn't support AUTH", host)
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
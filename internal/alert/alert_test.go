@@ -0,0 +1,185 @@
+// ABOUTME: Tests for sync failure alerts
+// ABOUTME: Checks when alerts fire and what webhook, ntfy, and SMTP endpoints receive
+
+package alert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func failingFeed(url string, count int) *models.Feed {
+	feed := models.NewFeed(url)
+	feed.ErrorCount = count
+	msg := "HTTP 404"
+	feed.LastError = &msg
+	return feed
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	webhook := Config{Webhook: "https://example.com/hook"}
+
+	if a := Evaluate(webhook, "default", []*models.Feed{failingFeed("https://a.example/feed", 4)}, 10, now); a != nil {
+		t.Errorf("expected no alert below the threshold, got %q", a.Summary)
+	}
+
+	a := Evaluate(webhook, "default", []*models.Feed{
+		failingFeed("https://a.example/feed", 5),
+		failingFeed("https://b.example/feed", 9),
+	}, 10, now)
+	if a == nil {
+		t.Fatal("expected an alert when a feed reaches the threshold")
+	}
+	if len(a.Feeds) != 1 || a.Feeds[0].URL != "https://a.example/feed" {
+		t.Errorf("expected only the feed crossing the threshold, got %+v", a.Feeds)
+	}
+	if !strings.Contains(a.Text, "HTTP 404") {
+		t.Errorf("expected the last error in the text, got %q", a.Text)
+	}
+
+	off := Config{Webhook: "https://example.com/hook", ErrorThreshold: -1}
+	if a := Evaluate(off, "default", []*models.Feed{failingFeed("https://a.example/feed", 5)}, 10, now); a != nil {
+		t.Error("expected no alert with the threshold turned off")
+	}
+
+	run := Config{Webhook: "https://example.com/hook", RunFailures: 2}
+	a = Evaluate(run, "work", []*models.Feed{
+		failingFeed("https://a.example/feed", 1),
+		failingFeed("https://b.example/feed", 1),
+	}, 10, now)
+	if a == nil || len(a.Feeds) != 2 {
+		t.Fatalf("expected a run alert listing both feeds, got %+v", a)
+	}
+	if !strings.Contains(a.Summary, "2 of 10") || !strings.Contains(a.Summary, "profile work") {
+		t.Errorf("unexpected summary %q", a.Summary)
+	}
+}
+
+func TestSendWebhookAndNtfy(t *testing.T) {
+	var hook Alert
+	var ntfyBody, ntfyTitle, ntfyAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hook":
+			if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+				t.Errorf("decode webhook: %v", err)
+			}
+		case "/topic":
+			body, _ := io.ReadAll(r.Body)
+			ntfyBody, ntfyTitle, ntfyAuth = string(body), r.Header.Get("Title"), r.Header.Get("Authorization")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := Config{Webhook: server.URL + "/hook", Ntfy: server.URL + "/topic", NtfyToken: "tk_1"}
+	a := Evaluate(cfg, "default", []*models.Feed{failingFeed("https://a.example/feed", 5)}, 3, time.Now())
+	if err := Send(context.Background(), cfg, a); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if hook.Text == "" || len(hook.Feeds) != 1 || hook.Feeds[0].ErrorCount != 5 {
+		t.Errorf("unexpected webhook payload %+v", hook)
+	}
+	if ntfyTitle != a.Summary || !strings.Contains(ntfyBody, "https://a.example/feed") || ntfyAuth != "Bearer tk_1" {
+		t.Errorf("unexpected ntfy request: title %q, auth %q, body %q", ntfyTitle, ntfyAuth, ntfyBody)
+	}
+
+	cfg.Webhook = server.URL + "/missing"
+	if err := Send(context.Background(), cfg, a); err == nil || !strings.Contains(err.Error(), "webhook") {
+		t.Errorf("expected a webhook error, got %v", err)
+	}
+}
+
+func TestHeaderText(t *testing.T) {
+	got := headerText("1 feed failing: Evil\r\nBcc: victim@example.com")
+	if strings.ContainsAny(got, "\r\n") {
+		t.Errorf("line break survived: %q", got)
+	}
+	if got := headerText("Café feed failing"); !strings.HasPrefix(got, "=?utf-8?q?") {
+		t.Errorf("non-ASCII text not encoded: %q", got)
+	}
+	if got := headerText("plain"); got != "plain" {
+		t.Errorf("ASCII text changed: %q", got)
+	}
+}
+
+// fakeSMTP accepts one message and sends its DATA on the returned channel.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "220 fake ESMTP\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"):
+				fmt.Fprint(conn, "250 fake\r\n")
+			case cmd == "DATA":
+				fmt.Fprint(conn, "354 go ahead\r\n")
+				var msg strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					msg.WriteString(l)
+				}
+				data <- msg.String()
+				fmt.Fprint(conn, "250 queued\r\n")
+			case cmd == "QUIT":
+				fmt.Fprint(conn, "221 bye\r\n")
+				return
+			default:
+				fmt.Fprint(conn, "250 ok\r\n")
+			}
+		}
+	}()
+	return ln.Addr().String(), data
+}
+
+func TestSendEmail(t *testing.T) {
+	addr, data := fakeSMTP(t)
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, _ := strconv.Atoi(port)
+	cfg := Config{Email: &EmailConfig{Host: host, Port: portNum, From: "digest@example.com", To: []string{"me@example.com"}}}
+
+	a := &Alert{Summary: "Evil\r\nBcc: victim@example.com", Text: "body", Time: time.Now()}
+	if err := Send(context.Background(), cfg, a); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	msg := <-data
+	if strings.Contains(msg, "\r\nBcc:") {
+		t.Errorf("summary injected a header:\n%s", msg)
+	}
+	if !strings.Contains(msg, "Subject: Evil Bcc: victim@example.com\r\n") {
+		t.Errorf("unexpected subject in:\n%s", msg)
+	}
+}
@@ -0,0 +1,153 @@
+// ABOUTME: Wayback Machine client that snapshots entry links for safekeeping
+// ABOUTME: Requests a capture via Save Page Now and falls back to the closest existing snapshot
+
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+// Default Wayback Machine endpoints.
+const (
+	DefaultSaveURL      = "https://web.archive.org/save/"
+	DefaultAvailableURL = "https://archive.org/wayback/available"
+)
+
+// requestTimeout bounds a single request; Save Page Now can take a while to capture a page.
+const requestTimeout = 2 * time.Minute
+
+// ErrNoSnapshot is returned when the page could not be captured and no earlier snapshot exists.
+var ErrNoSnapshot = errors.New("no archive snapshot available")
+
+// Client requests snapshots from the Wayback Machine.
+type Client struct {
+	SaveURL      string
+	AvailableURL string
+	HTTP         *http.Client
+}
+
+// New returns a Client for the public Wayback Machine.
+func New() *Client {
+	return &Client{
+		SaveURL:      DefaultSaveURL,
+		AvailableURL: DefaultAvailableURL,
+		HTTP:         &http.Client{Timeout: requestTimeout, Transport: fetch.Transport()},
+	}
+}
+
+// Snapshot asks the Wayback Machine to capture link and returns the snapshot URL.
+// When the capture fails (Save Page Now is rate limited and often refuses pages),
+// the closest existing snapshot is returned instead; the capture error is
+// reported only if there is none.
+func (c *Client) Snapshot(ctx context.Context, link string) (string, error) {
+	if err := checkLink(link); err != nil {
+		return "", err
+	}
+
+	snapshot, saveErr := c.save(ctx, link)
+	if saveErr == nil {
+		return snapshot, nil
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	snapshot, err := c.Closest(ctx, link)
+	if err != nil {
+		if errors.Is(err, ErrNoSnapshot) {
+			return "", fmt.Errorf("capture %s: %w", link, saveErr)
+		}
+		return "", err
+	}
+	return snapshot, nil
+}
+
+// Closest returns the most recent existing snapshot of link, or ErrNoSnapshot.
+func (c *Client) Closest(ctx context.Context, link string) (string, error) {
+	if err := checkLink(link); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.AvailableURL+"?url="+url.QueryEscape(link), nil)
+	if err != nil {
+		return "", fmt.Errorf("build availability request: %w", err)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("query wayback availability: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("wayback availability returned %s", resp.Status)
+	}
+
+	var body struct {
+		ArchivedSnapshots struct {
+			Closest *struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+				Status    string `json:"status"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode wayback availability: %w", err)
+	}
+	closest := body.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.URL == "" {
+		return "", ErrNoSnapshot
+	}
+	return strings.Replace(closest.URL, "http://", "https://", 1), nil
+}
+
+// save requests a fresh capture. Save Page Now answers with the snapshot
+// either in Content-Location or by redirecting to it.
+func (c *Client) save(ctx context.Context, link string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.SaveURL+link, nil)
+	if err != nil {
+		return "", fmt.Errorf("build save request: %w", err)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request wayback capture: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("wayback capture returned %s", resp.Status)
+	}
+	if loc := resp.Header.Get("Content-Location"); loc != "" {
+		if u, err := resp.Request.URL.Parse(loc); err == nil && isSnapshot(u) {
+			return u.String(), nil
+		}
+	}
+	if isSnapshot(resp.Request.URL) {
+		return resp.Request.URL.String(), nil
+	}
+	return "", fmt.Errorf("wayback capture returned no snapshot location")
+}
+
+// isSnapshot reports whether u points at a /web/<timestamp>/ capture.
+func isSnapshot(u *url.URL) bool {
+	return strings.HasPrefix(u.Path, "/web/")
+}
+
+// checkLink rejects links the Wayback Machine can't archive.
+func checkLink(link string) error {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("cannot archive %q: not an http(s) URL", link)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Tests for the Wayback Machine snapshot client
+// ABOUTME: Uses a fake Wayback server to cover captures, redirects, and the availability fallback
+
+package archive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestClient points a Client at a fake Wayback server.
+func newTestClient(srv *httptest.Server) *Client {
+	c := New()
+	c.SaveURL = srv.URL + "/save/"
+	c.AvailableURL = srv.URL + "/wayback/available"
+	return c
+}
+
+func TestSnapshotContentLocation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/save/https://example.com/post") {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Location", "/web/20240101000000/https://example.com/post")
+	}))
+	defer srv.Close()
+
+	got, err := newTestClient(srv).Snapshot(context.Background(), "https://example.com/post")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if want := srv.URL + "/web/20240101000000/https://example.com/post"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestSnapshotRedirect(t *testing.T) {
+	// A plain handler rather than a ServeMux, which would clean the "//" in the target
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/save/") {
+			http.Redirect(w, r, "http://"+r.Host+"/web/20240202000000/https://example.com/post", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	got, err := newTestClient(srv).Snapshot(context.Background(), "https://example.com/post")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if !strings.HasSuffix(got, "/web/20240202000000/https://example.com/post") {
+		t.Errorf("expected the redirect target, got %s", got)
+	}
+}
+
+func TestSnapshotFallsBackToClosest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/save/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	})
+	mux.HandleFunc("/wayback/available", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("url") != "https://example.com/post" {
+			t.Errorf("unexpected availability query %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"archived_snapshots":{"closest":{"available":true,"status":"200","url":"http://web.archive.org/web/2023/https://example.com/post"}}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	got, err := newTestClient(srv).Snapshot(context.Background(), "https://example.com/post")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if got != "https://web.archive.org/web/2023/https://example.com/post" {
+		t.Errorf("expected the closest snapshot over https, got %s", got)
+	}
+}
+
+func TestSnapshotNoneAvailable(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/save/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	})
+	mux.HandleFunc("/wayback/available", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"archived_snapshots":{}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := newTestClient(srv)
+	_, err := c.Snapshot(context.Background(), "https://example.com/post")
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("expected the capture error to be reported, got %v", err)
+	}
+	if _, err := c.Closest(context.Background(), "https://example.com/post"); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("expected ErrNoSnapshot, got %v", err)
+	}
+}
+
+func TestSnapshotRejectsNonHTTP(t *testing.T) {
+	for _, link := range []string{"", "mailto:a@example.com", "file:///etc/passwd", "/relative"} {
+		if _, err := New().Snapshot(context.Background(), link); err == nil {
+			t.Errorf("expected %q to be rejected", link)
+		}
+	}
+}
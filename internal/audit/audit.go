@@ -0,0 +1,124 @@
+// ABOUTME: Append-only audit log of MCP tool calls
+// ABOUTME: Records tool name, argument hash, result status, and timing as JSON lines
+
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName is the audit log file name inside the data directory.
+const FileName = "audit.log"
+
+// Result statuses recorded for each call.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// maxLineSize bounds a single record when reading the log back.
+const maxLineSize = 64 * 1024
+
+// Record is one tool invocation.
+type Record struct {
+	Time       time.Time `json:"time"`
+	Tool       string    `json:"tool"`
+	Profile    string    `json:"profile,omitempty"`
+	ArgsHash   string    `json:"args_hash"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// Path returns the audit log path for a data directory.
+func Path(dataDir string) string {
+	return filepath.Join(dataDir, FileName)
+}
+
+// Log appends records to an audit log file. It is safe for concurrent use.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New returns a Log writing to path. The file is created on first append.
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// Append writes a record as a single JSON line.
+func (l *Log) Append(r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// HashArgs returns a short stable hash of tool arguments. Arguments are hashed
+// rather than stored so credentials and content never land in the log, while
+// identical calls can still be matched up.
+func HashArgs(args any) string {
+	// encoding/json sorts map keys, so equal arguments hash equally
+	data, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Tail returns the last n records in the log, oldest first. A missing log
+// yields no records. Lines that don't parse are skipped.
+func Tail(path string, n int) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+		if n > 0 && len(records) > n {
+			records = records[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Tests for the MCP tool call audit log
+// ABOUTME: Covers appending, tailing, malformed lines, and argument hashing
+
+package audit
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAppendAndTail(t *testing.T) {
+	path := Path(t.TempDir())
+	log := New(path)
+
+	for _, tool := range []string{"list_feeds", "add_feed", "remove_feed"} {
+		err := log.Append(Record{Time: time.Now(), Tool: tool, Status: StatusOK, DurationMS: 5})
+		if err != nil {
+			t.Fatalf("Append(%s): %v", tool, err)
+		}
+	}
+
+	records, err := Tail(path, 2)
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Tool != "add_feed" || records[1].Tool != "remove_feed" {
+		t.Errorf("expected the last two records oldest first, got %s, %s", records[0].Tool, records[1].Tool)
+	}
+
+	all, err := Tail(path, 0)
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected n=0 to return all 3 records, got %d", len(all))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat audit log: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected audit log mode 0600, got %o", perm)
+	}
+}
+
+func TestTailMissingLog(t *testing.T) {
+	records, err := Tail(Path(t.TempDir()), 10)
+	if err != nil {
+		t.Fatalf("expected no error for a missing log, got %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+}
+
+func TestTailSkipsMalformedLines(t *testing.T) {
+	path := Path(t.TempDir())
+	content := `{"tool":"list_feeds","status":"ok"}
+not json
+{"tool":"get_entry","status":"error","error":"entry not found"}
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := Tail(path, 10)
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 valid records, got %d", len(records))
+	}
+	if records[1].Error != "entry not found" {
+		t.Errorf("expected error to round-trip, got %q", records[1].Error)
+	}
+}
+
+func TestHashArgs(t *testing.T) {
+	a := HashArgs(map[string]any{"url": "https://example.com/feed.xml", "folder": "Tech"})
+	b := HashArgs(map[string]any{"folder": "Tech", "url": "https://example.com/feed.xml"})
+	c := HashArgs(map[string]any{"url": "https://example.com/other.xml", "folder": "Tech"})
+
+	if a != b {
+		t.Errorf("expected equal arguments to hash equally, got %s and %s", a, b)
+	}
+	if a == c {
+		t.Errorf("expected different arguments to hash differently")
+	}
+	if len(a) != 12 {
+		t.Errorf("expected a 12 character hash, got %q", a)
+	}
+}
@@ -0,0 +1,224 @@
+// ABOUTME: Author normalization, indexing, and fuzzy matching across feeds
+// ABOUTME: Cleans RSS author fields into names so the same writer is recognized wherever they publish
+
+package authors
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/harper/digest/internal/models"
+)
+
+var (
+	// "jane@example.com (Jane Doe)", the RSS 2.0 managingEditor style
+	emailWithName = regexp.MustCompile(`^\S+@\S+\s*\(([^)]+)\)$`)
+	// "Jane Doe <jane@example.com>"
+	nameWithEmail = regexp.MustCompile(`^(.+?)\s*<\S+@\S+>$`)
+	// "By Jane Doe", "Posted by Jane Doe"
+	byline = regexp.MustCompile(`(?i)^(?:posted\s+|written\s+)?by\s+`)
+	// Separators between co-authors
+	coauthors = regexp.MustCompile(`\s*(?:,|;|&|\band\b)\s*`)
+)
+
+// Normalize turns a raw author field into clean author names, splitting
+// co-authors. Email addresses are dropped in favour of the name beside them.
+func Normalize(raw string) []string {
+	raw = strings.Join(strings.Fields(raw), " ")
+	if raw == "" {
+		return nil
+	}
+	if m := emailWithName.FindStringSubmatch(raw); m != nil {
+		raw = m[1]
+	} else if m := nameWithEmail.FindStringSubmatch(raw); m != nil {
+		raw = m[1]
+	}
+	raw = byline.ReplaceAllString(raw, "")
+
+	var names []string
+	for _, part := range coauthors.Split(raw, -1) {
+		part = strings.Trim(part, ` "'.`)
+		if part == "" || Key(part) == "" {
+			continue
+		}
+		names = append(names, part)
+	}
+	return names
+}
+
+// Key is the comparison form of a name: lowercase letters and digits with
+// single spaces, so "JANE  DOE" and "Jane Doe." index together.
+func Key(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '@':
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// Author is one writer's activity across all feeds.
+type Author struct {
+	Name    string     `json:"name"`
+	Entries int        `json:"entries"`
+	Unread  int        `json:"unread"`
+	FeedIDs []string   `json:"feed_ids"`
+	Latest  *time.Time `json:"latest,omitempty"`
+}
+
+// Index groups entries by normalized author, most prolific first. Each
+// author is shown under the spelling used most often.
+func Index(entries []*models.Entry) []Author {
+	type agg struct {
+		author   Author
+		spelling map[string]int
+		feeds    map[string]bool
+	}
+	byKey := make(map[string]*agg)
+	var order []string
+
+	for _, entry := range entries {
+		if entry.Author == nil {
+			continue
+		}
+		for _, name := range Normalize(*entry.Author) {
+			key := Key(name)
+			a, ok := byKey[key]
+			if !ok {
+				a = &agg{spelling: make(map[string]int), feeds: make(map[string]bool)}
+				byKey[key] = a
+				order = append(order, key)
+			}
+			a.spelling[name]++
+			a.author.Entries++
+			if !entry.Read {
+				a.author.Unread++
+			}
+			if !a.feeds[entry.FeedID] {
+				a.feeds[entry.FeedID] = true
+				a.author.FeedIDs = append(a.author.FeedIDs, entry.FeedID)
+			}
+			if entry.PublishedAt != nil && (a.author.Latest == nil || entry.PublishedAt.After(*a.author.Latest)) {
+				latest := *entry.PublishedAt
+				a.author.Latest = &latest
+			}
+		}
+	}
+
+	result := make([]Author, 0, len(order))
+	for _, key := range order {
+		a := byKey[key]
+		best := ""
+		for name, n := range a.spelling {
+			if best == "" || n > a.spelling[best] || (n == a.spelling[best] && name < best) {
+				best = name
+			}
+		}
+		a.author.Name = best
+		result = append(result, a.author)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Entries != result[j].Entries {
+			return result[i].Entries > result[j].Entries
+		}
+		return Key(result[i].Name) < Key(result[j].Name)
+	})
+	return result
+}
+
+// Match reports whether query fuzzily names the author. Every word of the
+// query must match a word of the name by prefix ("j smith"), or within a
+// small edit distance ("jon smith" for "John Smith").
+func Match(query, name string) bool {
+	queryWords := strings.Fields(Key(query))
+	nameWords := strings.Fields(Key(name))
+	if len(queryWords) == 0 || len(nameWords) == 0 {
+		return false
+	}
+	for _, q := range queryWords {
+		found := false
+		for _, n := range nameWords {
+			if strings.HasPrefix(n, q) || withinTypo(q, n) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchEntry reports whether any author of the entry matches query.
+func MatchEntry(query string, entry *models.Entry) bool {
+	if entry.Author == nil {
+		return false
+	}
+	for _, name := range Normalize(*entry.Author) {
+		if Match(query, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinTypo allows one edit (insertion, deletion, substitution, or swap of
+// neighbours) when the longer word has four or more letters, and two at eight
+// or more. Shorter words must match exactly or by prefix.
+func withinTypo(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	allowed := 0
+	switch n := max(len(ra), len(rb)); {
+	case n >= 8:
+		allowed = 2
+	case n >= 4:
+		allowed = 1
+	}
+	if allowed == 0 {
+		return false
+	}
+	diff := len(ra) - len(rb)
+	if diff < -allowed || diff > allowed {
+		return false
+	}
+	return editDistance(ra, rb) <= allowed
+}
+
+// editDistance is the optimal string alignment distance: Levenshtein plus
+// transposition of adjacent runes, the most common typo in names.
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,154 @@
+// ABOUTME: Tests for author normalization, indexing, fuzzy matching, and follows
+// ABOUTME: Covers RSS email formats, co-author splitting, spelling variants, and typo tolerance
+
+package authors
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"Jane Doe", []string{"Jane Doe"}},
+		{"  Jane   Doe ", []string{"Jane Doe"}},
+		{"jane@example.com (Jane Doe)", []string{"Jane Doe"}},
+		{"Jane Doe <jane@example.com>", []string{"Jane Doe"}},
+		{"By Jane Doe", []string{"Jane Doe"}},
+		{"Jane Doe and John Smith", []string{"Jane Doe", "John Smith"}},
+		{"Jane Doe, John Smith & Ann Lee", []string{"Jane Doe", "John Smith", "Ann Lee"}},
+		{"Alexander Andrews", []string{"Alexander Andrews"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	now := time.Now()
+	entry := func(feedID, author string, read bool) *models.Entry {
+		e := models.NewEntry(feedID, author, "post")
+		e.Author = &author
+		e.Read = read
+		e.PublishedAt = &now
+		return e
+	}
+
+	index := Index([]*models.Entry{
+		entry("f1", "Jane Doe", false),
+		entry("f2", "JANE DOE", true),
+		entry("f1", "jane@example.com (Jane Doe)", false),
+		entry("f1", "John Smith and Jane Doe", false),
+		{FeedID: "f1"}, // no author
+	})
+
+	if len(index) != 2 {
+		t.Fatalf("expected 2 authors, got %+v", index)
+	}
+	jane := index[0]
+	if jane.Name != "Jane Doe" || jane.Entries != 4 || jane.Unread != 3 {
+		t.Errorf("unexpected Jane Doe entry: %+v", jane)
+	}
+	if !reflect.DeepEqual(jane.FeedIDs, []string{"f1", "f2"}) {
+		t.Errorf("expected both feeds, got %v", jane.FeedIDs)
+	}
+	if index[1].Name != "John Smith" || index[1].Entries != 1 {
+		t.Errorf("unexpected second author: %+v", index[1])
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query, name string
+		want        bool
+	}{
+		{"jane", "Jane Doe", true},
+		{"doe", "Jane Doe", true},
+		{"j doe", "Jane Doe", true},
+		{"jnae doe", "Jane Doe", true},
+		{"jon smith", "John Smith", true},
+		{"Christopher", "Christofer Lee", true},
+		{"jane smith", "Jane Doe", false},
+		{"bob", "Rob Pike", false},
+		{"", "Jane Doe", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.query, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.query, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFollows(t *testing.T) {
+	path := FollowsPath(t.TempDir())
+
+	names, err := LoadFollows(path)
+	if err != nil || len(names) != 0 {
+		t.Fatalf("expected empty follows for a missing file, got %v, %v", names, err)
+	}
+
+	names, changed := Follow(names, "Jane Doe")
+	if !changed {
+		t.Fatal("expected first follow to change the list")
+	}
+	names, _ = Follow(names, "Adam Ant")
+	if _, changed := Follow(names, "jane  doe"); changed {
+		t.Error("expected an equivalent name not to be added twice")
+	}
+	if err := SaveFollows(path, names); err != nil {
+		t.Fatalf("SaveFollows: %v", err)
+	}
+
+	loaded, err := LoadFollows(path)
+	if err != nil {
+		t.Fatalf("LoadFollows: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, []string{"Adam Ant", "Jane Doe"}) {
+		t.Errorf("expected sorted follows, got %v", loaded)
+	}
+
+	loaded, changed = Unfollow(loaded, "JANE DOE")
+	if !changed || !reflect.DeepEqual(loaded, []string{"Adam Ant"}) {
+		t.Errorf("expected Jane Doe removed, got %v", loaded)
+	}
+	if filepath.Base(path) != FollowsFileName {
+		t.Errorf("unexpected follows path %s", path)
+	}
+}
+
+func TestMuted(t *testing.T) {
+	path := FollowsPath(t.TempDir())
+	if err := SaveFollows(path, []string{"Jane Doe"}); err != nil {
+		t.Fatalf("SaveFollows: %v", err)
+	}
+
+	muted, _ := Follow(nil, "Spam Bot")
+	if err := SaveMuted(path, muted); err != nil {
+		t.Fatalf("SaveMuted: %v", err)
+	}
+	loaded, err := LoadMuted(path)
+	if err != nil || !reflect.DeepEqual(loaded, []string{"Spam Bot"}) {
+		t.Errorf("LoadMuted = %v, %v", loaded, err)
+	}
+
+	// Each list is saved without losing the other
+	if err := SaveFollows(path, []string{"Adam Ant"}); err != nil {
+		t.Fatalf("SaveFollows: %v", err)
+	}
+	if loaded, _ := LoadMuted(path); !reflect.DeepEqual(loaded, []string{"Spam Bot"}) {
+		t.Errorf("SaveFollows dropped the muted authors, got %v", loaded)
+	}
+	if loaded, _ := LoadFollows(path); !reflect.DeepEqual(loaded, []string{"Adam Ant"}) {
+		t.Errorf("expected follows kept, got %v", loaded)
+	}
+}
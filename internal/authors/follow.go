@@ -0,0 +1,113 @@
+// ABOUTME: Per-profile lists of followed and muted authors
+// ABOUTME: Stored as JSON in the profile data directory; names are matched fuzzily against entries
+
+package authors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/harperreed/mdstore"
+)
+
+// FollowsFileName is the followed-authors file inside a profile data directory.
+const FollowsFileName = "authors.json"
+
+type followsFile struct {
+	Following []string `json:"following"`
+	Muted     []string `json:"muted,omitempty"`
+}
+
+// FollowsPath returns the followed-authors file for a profile data directory.
+func FollowsPath(profileDir string) string {
+	return filepath.Join(profileDir, FollowsFileName)
+}
+
+// LoadFollows reads the followed authors. A missing file means nobody is followed.
+func LoadFollows(path string) ([]string, error) {
+	f, err := readFollows(path)
+	return f.Following, err
+}
+
+// SaveFollows writes the followed authors, sorted, keeping the muted ones.
+func SaveFollows(path string, names []string) error {
+	f, err := readFollows(path)
+	if err != nil {
+		return err
+	}
+	f.Following = sortedNames(names)
+	return writeFollows(path, f)
+}
+
+// LoadMuted reads the muted authors. A missing file means nobody is muted.
+func LoadMuted(path string) ([]string, error) {
+	f, err := readFollows(path)
+	return f.Muted, err
+}
+
+// SaveMuted writes the muted authors, sorted, keeping the followed ones.
+func SaveMuted(path string, names []string) error {
+	f, err := readFollows(path)
+	if err != nil {
+		return err
+	}
+	f.Muted = sortedNames(names)
+	return writeFollows(path, f)
+}
+
+func readFollows(path string) (followsFile, error) {
+	var f followsFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return f, fmt.Errorf("read followed authors: %w", err)
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("parse followed authors: %w", err)
+	}
+	return f, nil
+}
+
+func writeFollows(path string, f followsFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode followed authors: %w", err)
+	}
+	if err := mdstore.AtomicWrite(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write followed authors: %w", err)
+	}
+	return nil
+}
+
+func sortedNames(names []string) []string {
+	sorted := append([]string(nil), names...)
+	sort.Slice(sorted, func(i, j int) bool { return Key(sorted[i]) < Key(sorted[j]) })
+	return sorted
+}
+
+// Follow adds name to the list unless an equivalent name is already there.
+// It reports whether the list changed. It serves the muted list as well.
+func Follow(names []string, name string) ([]string, bool) {
+	for _, n := range names {
+		if Key(n) == Key(name) {
+			return names, false
+		}
+	}
+	return append(names, name), true
+}
+
+// Unfollow removes the names equivalent to name, reporting whether any were removed.
+func Unfollow(names []string, name string) ([]string, bool) {
+	kept := names[:0:0]
+	for _, n := range names {
+		if Key(n) != Key(name) {
+			kept = append(kept, n)
+		}
+	}
+	return kept, len(kept) != len(names)
+}
@@ -0,0 +1,138 @@
+// ABOUTME: Cached unread counts for status bar badges, kept in the profile data directory
+// ABOUTME: Written whenever entries change so 'digest unread' can answer without opening storage
+
+package badge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/harperreed/mdstore"
+
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+// FileName is the cached unread counts inside a profile data directory.
+const FileName = "unread.json"
+
+// DefaultFormat prints the bare unread count.
+const DefaultFormat = "{count}"
+
+// Counts are the unread counts at the time they were cached.
+type Counts struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Total     int       `json:"total"`
+	// Folders maps each OPML folder to the unread count of its feeds.
+	Folders map[string]int `json:"folders,omitempty"`
+}
+
+// Path returns where the counts of the profile in profileDir are cached.
+func Path(profileDir string) string {
+	return filepath.Join(profileDir, FileName)
+}
+
+// Compute counts the unread entries in store, in total and per folder of doc.
+func Compute(ctx context.Context, store storage.Store, doc *opml.Document) (*Counts, error) {
+	stats, err := store.GetFeedStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get feed stats: %w", err)
+	}
+	perFeed := make(map[string]int, len(stats))
+	c := &Counts{UpdatedAt: time.Now().UTC(), Folders: make(map[string]int)}
+	for _, s := range stats {
+		perFeed[s.FeedURL] = s.UnreadCount
+		c.Total += s.UnreadCount
+	}
+	if doc != nil {
+		for _, folder := range doc.Folders() {
+			n := 0
+			for _, f := range doc.FeedsInFolder(folder) {
+				n += perFeed[f.URL]
+			}
+			c.Folders[folder] = n
+		}
+	}
+	return c, nil
+}
+
+// Refresh recomputes the counts and caches them at path.
+func Refresh(ctx context.Context, store storage.Store, doc *opml.Document, path string) (*Counts, error) {
+	c, err := Compute(ctx, store, doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := Save(path, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save caches c at path, replacing the previous counts.
+func Save(path string, c *Counts) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encode unread counts: %w", err)
+	}
+	if err := mdstore.AtomicWrite(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write unread counts: %w", err)
+	}
+	return nil
+}
+
+// Load reads the cached counts, or returns nil if none are cached yet.
+func Load(path string) (*Counts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read unread counts: %w", err)
+	}
+	var c Counts
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse unread counts: %w", err)
+	}
+	return &c, nil
+}
+
+// Count returns the unread count of folder, or the total if folder is empty.
+func (c *Counts) Count(folder string) int {
+	if folder == "" {
+		return c.Total
+	}
+	return c.Folders[folder]
+}
+
+var placeholder = regexp.MustCompile(`\{(count|total|folder)(?::([^}]+))?\}`)
+
+// Format fills in the placeholders of tmpl:
+//
+//	{count}         unread in folder, or in total without one
+//	{count:Name}    unread in the folder called Name
+//	{total}         unread in total
+//	{folder}        the folder name
+//
+// Anything else is printed as is.
+func (c *Counts) Format(tmpl, folder string) string {
+	return placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		parts := placeholder.FindStringSubmatch(m)
+		switch parts[1] {
+		case "count":
+			if parts[2] != "" {
+				return strconv.Itoa(c.Folders[parts[2]])
+			}
+			return strconv.Itoa(c.Count(folder))
+		case "total":
+			return strconv.Itoa(c.Total)
+		default:
+			return folder
+		}
+	})
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Tests for cached unread counts
+// ABOUTME: Covers counting per folder, the cache round trip, and format placeholders
+
+package badge
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestComputeAndCache(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	doc := opml.NewDocument("test")
+	unread := map[string]int{"https://a.example/feed": 3, "https://b.example/feed": 2, "https://c.example/feed": 1}
+	folders := map[string]string{"https://a.example/feed": "Work", "https://b.example/feed": "Fun"}
+	for url, n := range unread {
+		feed := storage.NewFeed(url)
+		if err := store.CreateFeed(ctx, feed); err != nil {
+			t.Fatalf("CreateFeed: %v", err)
+		}
+		for i := 0; i <= n; i++ {
+			entry := storage.NewEntry(feed.ID, url+string(rune('a'+i)), "Post")
+			if err := store.CreateEntry(ctx, entry); err != nil {
+				t.Fatalf("CreateEntry: %v", err)
+			}
+			if i == n {
+				if err := store.MarkEntryRead(ctx, entry.ID); err != nil {
+					t.Fatalf("MarkEntryRead: %v", err)
+				}
+			}
+		}
+		if err := doc.AddFeed(url, "", folders[url]); err != nil {
+			t.Fatalf("AddFeed: %v", err)
+		}
+	}
+	if err := doc.AddFolder("Empty"); err != nil {
+		t.Fatalf("AddFolder: %v", err)
+	}
+
+	path := Path(t.TempDir())
+	if c, err := Load(path); err != nil || c != nil {
+		t.Fatalf("Load before caching = %v, %v; want nil", c, err)
+	}
+	if _, err := Refresh(ctx, store, doc, path); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	c, err := Load(path)
+	if err != nil || c == nil {
+		t.Fatalf("Load: %v, %v", c, err)
+	}
+	if c.Total != 6 || c.Count("Work") != 3 || c.Count("Fun") != 2 || c.Count("") != 6 {
+		t.Errorf("unexpected counts %+v", c)
+	}
+	if n, ok := c.Folders["Empty"]; !ok || n != 0 {
+		t.Errorf("empty folder = %d, %v; want 0, true", n, ok)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	c := &Counts{Total: 12, Folders: map[string]int{"Work": 4, "Fun": 8}}
+	tests := []struct {
+		tmpl, folder, want string
+	}{
+		{DefaultFormat, "", "12"},
+		{DefaultFormat, "Work", "4"},
+		{"{folder}: {count}/{total}", "Fun", "Fun: 8/12"},
+		{"W{count:Work} F{count:Fun} X{count:Nope}", "", "W4 F8 X0"},
+		{`{"text": "{count}"}`, "", `{"text": "12"}`},
+		{"{unknown}", "", "{unknown}"},
+	}
+	for _, tt := range tests {
+		if got := c.Format(tt.tmpl, tt.folder); got != tt.want {
+			t.Errorf("Format(%q, %q) = %q, want %q", tt.tmpl, tt.folder, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// ABOUTME: Public blogroll built from opted-in OPML folders
+// ABOUTME: Renders the shared reading list as OPML for readers and as a standalone HTML page
+
+package blogroll
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/opml"
+)
+
+// File names used when the blogroll is written to a directory or served.
+const (
+	OPMLFile = "blogroll.opml"
+	HTMLFile = "blogroll.html"
+)
+
+// Feed is one published subscription.
+type Feed struct {
+	Title   string
+	FeedURL string
+	SiteURL string
+	// Icon is the site's icon as a data:image URL, or "" for none. The
+	// page inlines it so it needs no other files.
+	Icon string
+}
+
+// Folder is a published OPML folder and its feeds.
+type Folder struct {
+	Name  string
+	Feeds []Feed
+}
+
+// Blogroll is the public reading list.
+type Blogroll struct {
+	Title   string
+	Folders []Folder
+	Updated time.Time
+}
+
+// Build collects the feeds of the named folders, in the order given. private
+// reports feeds that must never be published, such as ones behind a login
+// or on the local network; it may be nil. An unknown folder is an error so a
+// typo doesn't silently publish an empty list.
+func Build(doc *opml.Document, title string, folders []string, private func(feedURL string) bool, now time.Time) (*Blogroll, error) {
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("no folders selected: the blogroll only publishes folders you opt in")
+	}
+	known := make(map[string]bool)
+	for _, name := range doc.Folders() {
+		known[name] = true
+	}
+
+	b := &Blogroll{Title: title, Updated: now}
+	for _, name := range folders {
+		if !known[name] {
+			return nil, fmt.Errorf("folder %q not found", name)
+		}
+		folder := Folder{Name: name}
+		for _, f := range doc.FeedsInFolder(name) {
+			if private != nil && private(f.URL) {
+				continue
+			}
+			title := f.Title
+			if title == "" {
+				title = f.URL
+			}
+			folder.Feeds = append(folder.Feeds, Feed{Title: title, FeedURL: f.URL, SiteURL: siteURL(f.URL)})
+		}
+		b.Folders = append(b.Folders, folder)
+	}
+	return b, nil
+}
+
+// siteURL guesses a feed's home page as the root of its host.
+func siteURL(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/"
+}
+
+// WriteOPML writes the blogroll as an OPML subscription list.
+func WriteOPML(w io.Writer, b *Blogroll) error {
+	doc := opml.NewDocument(b.Title)
+	for _, folder := range b.Folders {
+		outline := opml.Outline{Text: folder.Name, Title: folder.Name}
+		for _, f := range folder.Feeds {
+			outline.Children = append(outline.Children, opml.Outline{
+				Text:    f.Title,
+				Title:   f.Title,
+				Type:    "rss",
+				XMLURL:  f.FeedURL,
+				HTMLURL: f.SiteURL,
+			})
+		}
+		doc.Outlines = append(doc.Outlines, outline)
+	}
+	return doc.Write(w)
+}
+
+var pageTemplate = template.Must(template.New("blogroll").Funcs(template.FuncMap{
+	"anchor": func(name string) string {
+		return strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	},
+	// icon trusts only inline images; anything else is dropped
+	"icon": func(src string) template.URL {
+		if !strings.HasPrefix(src, "data:image/") {
+			return ""
+		}
+		return template.URL(src)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="alternate" type="text/x-opml" title="{{.Title}}" href="{{.OPMLHref}}">
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+li { margin: 0.25rem 0; }
+.icon { width: 16px; height: 16px; vertical-align: -0.15em; margin-right: 0.35em; }
+.feed { font-size: 0.85em; color: #666; }
+footer { margin-top: 2rem; font-size: 0.85em; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>What I read. Subscribe to everything at once with the <a href="{{.OPMLHref}}">OPML file</a>.</p>
+{{range .Folders}}
+<h2 id="{{anchor .Name}}">{{.Name}}</h2>
+<ul>
+{{- range .Feeds}}
+<li>{{with icon .Icon}}<img class="icon" src="{{.}}" alt="">{{end}}{{if .SiteURL}}<a href="{{.SiteURL}}">{{.Title}}</a>{{else}}{{.Title}}{{end}} <a class="feed" href="{{.FeedURL}}">feed</a></li>
+{{- end}}
+</ul>
+{{end}}
+<footer>Updated {{.Updated.Format "January 2, 2006"}} with digest.</footer>
+</body>
+</html>
+`))
+
+// WriteHTML writes the blogroll as a standalone HTML page that links to
+// the OPML file at opmlHref.
+func WriteHTML(w io.Writer, b *Blogroll, opmlHref string) error {
+	data := struct {
+		*Blogroll
+		OPMLHref string
+	}{b, opmlHref}
+	if err := pageTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render blogroll: %w", err)
+	}
+	return nil
+}
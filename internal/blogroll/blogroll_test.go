@@ -0,0 +1,115 @@
+// ABOUTME: Tests for blogroll building and rendering
+// ABOUTME: Covers folder opt-in, private feed exclusion, and the OPML and HTML outputs
+
+package blogroll
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/opml"
+)
+
+func testDoc(t *testing.T) *opml.Document {
+	t.Helper()
+	doc := opml.NewDocument("mine")
+	for _, f := range []struct{ url, title, folder string }{
+		{"https://friend.example.com/feed.xml", "Friend <3", "Friends"},
+		{"https://secret.example.com/feed.xml", "Members Only", "Friends"},
+		{"https://news.example.com/rss", "News", "News"},
+		{"https://work.example.com/feed", "Work", "Work"},
+	} {
+		if err := doc.AddFeed(f.url, f.title, f.folder); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return doc
+}
+
+func TestBuild(t *testing.T) {
+	private := func(url string) bool { return strings.Contains(url, "secret") }
+	b, err := Build(testDoc(t), "My Blogroll", []string{"News", "Friends"}, private, time.Now())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(b.Folders) != 2 || b.Folders[0].Name != "News" || b.Folders[1].Name != "Friends" {
+		t.Fatalf("expected the selected folders in order, got %+v", b.Folders)
+	}
+	friends := b.Folders[1].Feeds
+	if len(friends) != 1 || friends[0].Title != "Friend <3" {
+		t.Fatalf("expected the private feed to be left out, got %+v", friends)
+	}
+	if friends[0].SiteURL != "https://friend.example.com/" {
+		t.Errorf("unexpected site URL %q", friends[0].SiteURL)
+	}
+
+	if _, err := Build(testDoc(t), "x", nil, nil, time.Now()); err == nil {
+		t.Error("expected an error when no folders are selected")
+	}
+	if _, err := Build(testDoc(t), "x", []string{"Freinds"}, nil, time.Now()); err == nil {
+		t.Error("expected an error for an unknown folder")
+	}
+}
+
+func TestWriteOPMLAndHTML(t *testing.T) {
+	b, err := Build(testDoc(t), "My Blogroll", []string{"Friends"}, nil, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := WriteOPML(&out, b); err != nil {
+		t.Fatalf("WriteOPML: %v", err)
+	}
+	doc, err := opml.Parse(&out)
+	if err != nil {
+		t.Fatalf("published OPML doesn't parse: %v", err)
+	}
+	feeds := doc.AllFeeds()
+	if doc.Title != "My Blogroll" || len(feeds) != 2 || feeds[0].Folder != "Friends" {
+		t.Errorf("unexpected published OPML: %+v", feeds)
+	}
+	if doc.Outlines[0].Children[0].HTMLURL != "https://friend.example.com/" {
+		t.Errorf("expected htmlUrl on published feeds")
+	}
+
+	out.Reset()
+	if err := WriteHTML(&out, b, OPMLFile); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	page := out.String()
+	for _, want := range []string{
+		"<title>My Blogroll</title>",
+		`href="blogroll.opml"`,
+		`<h2 id="friends">Friends</h2>`,
+		`<a href="https://friend.example.com/">Friend &lt;3</a>`,
+		"Updated October 15, 2026",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected page to contain %q", want)
+		}
+	}
+}
+
+func TestWriteHTMLIcons(t *testing.T) {
+	b, err := Build(testDoc(t), "My Blogroll", []string{"Friends"}, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Folders[0].Feeds[0].Icon = "data:image/png;base64,iVBORw0K"
+	b.Folders[0].Feeds[1].Icon = "javascript:alert(1)"
+
+	var out bytes.Buffer
+	if err := WriteHTML(&out, b, OPMLFile); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	page := out.String()
+	if !strings.Contains(page, `<img class="icon" src="data:image/png;base64,iVBORw0K" alt="">`) {
+		t.Errorf("expected the inline icon on the page:\n%s", page)
+	}
+	if strings.Contains(page, "javascript:") || strings.Count(page, "<img") != 1 {
+		t.Errorf("expected only data:image icons to be shown:\n%s", page)
+	}
+}
@@ -0,0 +1,249 @@
+// ABOUTME: Bookmark service integration pushing kept entries to Linkding or Raindrop.io
+// ABOUTME: Tracks what was pushed and imports bookmarks back as entries of a feed sync leaves alone
+
+package bookmarks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harperreed/mdstore"
+
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// Supported bookmark services.
+const (
+	ServiceLinkding = "linkding"
+	ServiceRaindrop = "raindrop"
+)
+
+// Services lists the supported bookmark services.
+var Services = []string{ServiceLinkding, ServiceRaindrop}
+
+// StateFileName records which entries were pushed, inside a profile data
+// directory.
+const StateFileName = "bookmarks.json"
+
+// requestTimeout bounds each call to the bookmark service.
+const requestTimeout = 30 * time.Second
+
+// Config selects the bookmark service and how pushed entries are tagged.
+type Config struct {
+	// Service is "linkding" or "raindrop".
+	Service string `json:"service"`
+
+	// URL is the Linkding server, such as "https://links.example.com".
+	// For Raindrop it overrides the API address and is normally unset.
+	URL string `json:"url,omitempty"`
+
+	// Collection is the Raindrop collection ID bookmarks are pushed to and
+	// imported from. Zero pushes to Unsorted and imports from every
+	// collection.
+	Collection int `json:"collection,omitempty"`
+
+	// Tags are added to every pushed bookmark.
+	Tags []string `json:"tags,omitempty"`
+
+	// FolderTags maps an OPML folder to tags for bookmarks of its feeds'
+	// entries, such as {"Go": ["golang", "programming"]}.
+	FolderTags map[string][]string `json:"folder_tags,omitempty"`
+
+	// Token is the service's API token. It is read from the secrets store,
+	// never from config.json.
+	Token string `json:"-"`
+}
+
+// Validate checks that the service is known and has what it needs.
+func (c Config) Validate() error {
+	switch c.Service {
+	case ServiceLinkding:
+		if c.URL == "" {
+			return fmt.Errorf("bookmarks: linkding needs the server's url")
+		}
+	case ServiceRaindrop:
+	case "":
+		return fmt.Errorf("bookmarks: no service configured; set bookmarks.service to %s", strings.Join(Services, " or "))
+	default:
+		return fmt.Errorf("bookmarks: unknown service %q (use %s)", c.Service, strings.Join(Services, " or "))
+	}
+	if c.Token == "" {
+		return fmt.Errorf("bookmarks: no API token; store one with 'digest secrets set bookmarks/token'")
+	}
+	return nil
+}
+
+// TagsFor returns the tags for a bookmark of an entry in folder: the
+// configured tags, then the folder's, without duplicates.
+func (c Config) TagsFor(folder string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, t := range append(append([]string{}, c.Tags...), c.FolderTags[folder]...) {
+		if t != "" && !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// Bookmark is one saved link.
+type Bookmark struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Added       time.Time `json:"added"`
+}
+
+// Client talks to a bookmark service.
+type Client interface {
+	// Add saves a bookmark.
+	Add(ctx context.Context, b Bookmark) error
+	// List returns the saved bookmarks, newest first.
+	List(ctx context.Context) ([]Bookmark, error)
+}
+
+// New returns a client for the configured service.
+func New(cfg Config) (Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Timeout: requestTimeout, Transport: fetch.Transport()}
+	switch cfg.Service {
+	case ServiceLinkding:
+		return &linkding{base: strings.TrimSuffix(cfg.URL, "/"), token: cfg.Token, http: httpClient}, nil
+	default:
+		base := defaultRaindropAPI
+		if cfg.URL != "" {
+			base = strings.TrimSuffix(cfg.URL, "/")
+		}
+		return &raindrop{base: base, token: cfg.Token, collection: cfg.Collection, http: httpClient}, nil
+	}
+}
+
+// FromEntry makes the bookmark pushed for an entry.
+func FromEntry(e *models.Entry, tags []string) (Bookmark, error) {
+	if e.Link == nil || *e.Link == "" {
+		return Bookmark{}, fmt.Errorf("entry %s has no link to bookmark", e.ID)
+	}
+	b := Bookmark{URL: *e.Link, Tags: tags, Added: time.Now().UTC()}
+	if e.Title != nil {
+		b.Title = *e.Title
+	}
+	return b, nil
+}
+
+// State records which entries were pushed, so each is pushed once.
+type State struct {
+	// Pushed maps entry IDs to when they were pushed.
+	Pushed map[string]time.Time `json:"pushed"`
+}
+
+// StatePath returns where the push state of the profile in profileDir is kept.
+func StatePath(profileDir string) string {
+	return filepath.Join(profileDir, StateFileName)
+}
+
+// LoadState reads the push state, or an empty one if nothing was pushed yet.
+func LoadState(path string) (*State, error) {
+	s := &State{Pushed: make(map[string]time.Time)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("read bookmark state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parse bookmark state: %w", err)
+	}
+	if s.Pushed == nil {
+		s.Pushed = make(map[string]time.Time)
+	}
+	return s, nil
+}
+
+// SaveState writes the push state.
+func SaveState(path string, s *State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode bookmark state: %w", err)
+	}
+	if err := mdstore.AtomicWrite(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write bookmark state: %w", err)
+	}
+	return nil
+}
+
+// FeedURL returns the URL of the feed bookmarks from service are imported
+// into. Sync skips it; only Import adds to it.
+func FeedURL(service string) string {
+	return models.BookmarksScheme + ":" + service
+}
+
+// Import adds the bookmarks not imported before as unread entries of the
+// service's bookmarks feed, creating the feed if needed, and returns how
+// many were added.
+func Import(ctx context.Context, store storage.Store, service string, marks []Bookmark) (int, error) {
+	url := FeedURL(service)
+	feed, err := store.GetFeedByURL(ctx, url)
+	if err != nil {
+		feed = models.NewFeed(url)
+		title := serviceTitle(service) + " bookmarks"
+		feed.Title = &title
+		if err := store.CreateFeed(ctx, feed); err != nil {
+			return 0, fmt.Errorf("create bookmarks feed: %w", err)
+		}
+	}
+
+	added := 0
+	for _, b := range marks {
+		exists, err := store.EntryExists(ctx, feed.ID, b.URL)
+		if err != nil {
+			return added, fmt.Errorf("check bookmark: %w", err)
+		}
+		if exists {
+			continue
+		}
+		title := b.Title
+		if title == "" {
+			title = b.URL
+		}
+		entry := models.NewEntry(feed.ID, b.URL, title)
+		link := b.URL
+		entry.Link = &link
+		if !b.Added.IsZero() {
+			at := b.Added
+			entry.PublishedAt = &at
+		}
+		if b.Description != "" {
+			description := b.Description
+			entry.Content = &description
+		}
+		if err := store.CreateEntry(ctx, entry); err != nil {
+			return added, fmt.Errorf("store bookmark: %w", err)
+		}
+		added++
+	}
+	return added, nil
+}
+
+// serviceTitle returns the service's name as its makers write it.
+func serviceTitle(service string) string {
+	switch service {
+	case ServiceRaindrop:
+		return "Raindrop.io"
+	case ServiceLinkding:
+		return "Linkding"
+	}
+	return service
+}
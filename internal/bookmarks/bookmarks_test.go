@@ -0,0 +1,198 @@
+// ABOUTME: Tests for the bookmark service integration
+// ABOUTME: Covers the Linkding and Raindrop clients, tag mapping, push state, and importing
+
+package bookmarks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestLinkding(t *testing.T) {
+	var added map[string]any
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/bookmarks/":
+			_ = json.NewDecoder(r.Body).Decode(&added)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		case r.URL.Query().Get("offset") == "":
+			fmt.Fprintf(w, `{"next": "%s/api/bookmarks/?limit=100&offset=100", "results": [
+				{"url": "https://a.example", "title": "A", "tag_names": ["x"], "date_added": "2026-05-01T10:00:00.123456Z"}]}`, srv.URL)
+		default:
+			fmt.Fprint(w, `{"next": null, "results": [{"url": "https://b.example", "title": "B", "description": "Bee", "date_added": "2026-04-01T10:00:00Z"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := New(Config{Service: ServiceLinkding, URL: srv.URL + "/", Token: "secret"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := client.Add(context.Background(), Bookmark{URL: "https://c.example", Title: "C", Tags: []string{"go"}}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if added["url"] != "https://c.example" || added["title"] != "C" || fmt.Sprint(added["tag_names"]) != "[go]" {
+		t.Errorf("unexpected bookmark sent: %v", added)
+	}
+
+	marks, err := client.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(marks) != 2 || marks[0].URL != "https://a.example" || marks[1].Description != "Bee" || marks[0].Added.IsZero() {
+		t.Errorf("unexpected bookmarks %+v", marks)
+	}
+
+	bad, _ := New(Config{Service: ServiceLinkding, URL: srv.URL, Token: "wrong"})
+	if err := bad.Add(context.Background(), Bookmark{URL: "https://c.example"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestRaindrop(t *testing.T) {
+	var added map[string]any
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost {
+			_ = json.NewDecoder(r.Body).Decode(&added)
+			fmt.Fprint(w, `{"result": true}`)
+			return
+		}
+		pages = append(pages, r.URL.Path+"?page="+r.URL.Query().Get("page"))
+		var items []string
+		if r.URL.Query().Get("page") == "0" {
+			for i := 0; i < raindropPageSize; i++ {
+				items = append(items, fmt.Sprintf(`{"link": "https://example.com/%d", "title": "T%d", "created": "2026-05-01T10:00:00Z"}`, i, i))
+			}
+		} else {
+			items = append(items, `{"link": "https://example.com/last", "title": "Last", "excerpt": "Ex", "tags": ["y"], "created": "2026-04-01T10:00:00Z"}`)
+		}
+		fmt.Fprintf(w, `{"result": true, "items": [%s]}`, strings.Join(items, ","))
+	}))
+	defer srv.Close()
+
+	client, err := New(Config{Service: ServiceRaindrop, URL: srv.URL, Collection: 42, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := client.Add(context.Background(), Bookmark{URL: "https://c.example", Title: "C"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if added["link"] != "https://c.example" || fmt.Sprint(added["collection"]) != "map[$id:42]" {
+		t.Errorf("unexpected bookmark sent: %v", added)
+	}
+
+	marks, err := client.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(marks) != raindropPageSize+1 || marks[raindropPageSize].Description != "Ex" {
+		t.Errorf("got %d bookmarks, last %+v", len(marks), marks[len(marks)-1])
+	}
+	if strings.Join(pages, " ") != "/rest/v1/raindrops/42?page=0 /rest/v1/raindrops/42?page=1" {
+		t.Errorf("unexpected pages fetched: %v", pages)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{}, "no service"},
+		{Config{Service: "pinboard", Token: "t"}, "unknown service"},
+		{Config{Service: ServiceLinkding, Token: "t"}, "url"},
+		{Config{Service: ServiceRaindrop}, "no API token"},
+		{Config{Service: ServiceRaindrop, Token: "t"}, ""},
+	}
+	for _, tt := range tests {
+		err := tt.cfg.Validate()
+		if (tt.want == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.cfg, err, tt.want)
+		}
+	}
+}
+
+func TestTagsFor(t *testing.T) {
+	cfg := Config{Tags: []string{"digest", "go"}, FolderTags: map[string][]string{"Go": {"go", "golang"}}}
+	if got := strings.Join(cfg.TagsFor("Go"), " "); got != "digest go golang" {
+		t.Errorf("TagsFor(Go) = %q", got)
+	}
+	if got := strings.Join(cfg.TagsFor(""), " "); got != "digest go" {
+		t.Errorf("TagsFor('') = %q", got)
+	}
+}
+
+func TestState(t *testing.T) {
+	path := StatePath(t.TempDir())
+	s, err := LoadState(path)
+	if err != nil || len(s.Pushed) != 0 {
+		t.Fatalf("LoadState before saving = %+v, %v", s, err)
+	}
+	s.Pushed["abc"] = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	if err := SaveState(path, s); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+	if s, err = LoadState(path); err != nil || !s.Pushed["abc"].Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("LoadState = %+v, %v", s, err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	marks := []Bookmark{
+		{URL: "https://a.example", Title: "A", Added: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{URL: "https://b.example", Description: "Bee"},
+	}
+	if n, err := Import(ctx, store, ServiceLinkding, marks); err != nil || n != 2 {
+		t.Fatalf("Import = %d, %v; want 2", n, err)
+	}
+	if n, err := Import(ctx, store, ServiceLinkding, marks); err != nil || n != 0 {
+		t.Fatalf("second Import = %d, %v; want 0", n, err)
+	}
+
+	feed, err := store.GetFeedByURL(ctx, "bookmarks:linkding")
+	if err != nil {
+		t.Fatalf("bookmarks feed: %v", err)
+	}
+	if !feed.IsBookmarks() || feed.GetDisplayName() != "Linkding bookmarks" {
+		t.Errorf("unexpected feed %+v", feed)
+	}
+	entries, err := store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("entries = %d, %v", len(entries), err)
+	}
+	for _, e := range entries {
+		if e.Read || e.Link == nil || *e.Link != e.GUID {
+			t.Errorf("unexpected entry %+v", e)
+		}
+		if e.GUID == "https://b.example" && (e.GetTitle() != "https://b.example" || e.Content == nil || *e.Content != "Bee") {
+			t.Errorf("untitled bookmark stored as %q / %v", e.GetTitle(), e.Content)
+		}
+	}
+}
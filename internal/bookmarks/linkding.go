@@ -0,0 +1,112 @@
+// ABOUTME: Linkding REST API client for adding and listing bookmarks
+// ABOUTME: Authenticates with an API token and follows the list's pagination links
+
+package bookmarks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// linkdingPageSize is how many bookmarks each list request asks for.
+const linkdingPageSize = 100
+
+type linkding struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+type linkdingBookmark struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	TagNames    []string  `json:"tag_names"`
+	DateAdded   time.Time `json:"date_added"`
+}
+
+type linkdingPage struct {
+	Next    *string            `json:"next"`
+	Results []linkdingBookmark `json:"results"`
+}
+
+// Add creates a bookmark. Linkding updates the existing bookmark instead
+// when the URL is already saved.
+func (l *linkding) Add(ctx context.Context, b Bookmark) error {
+	body, err := json.Marshal(map[string]any{
+		"url":         b.URL,
+		"title":       b.Title,
+		"description": b.Description,
+		"tag_names":   nonNil(b.Tags),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.base+"/api/bookmarks/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return l.do(req, nil)
+}
+
+func (l *linkding) List(ctx context.Context) ([]Bookmark, error) {
+	var marks []Bookmark
+	next := fmt.Sprintf("%s/api/bookmarks/?limit=%d", l.base, linkdingPageSize)
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page linkdingPage
+		if err := l.do(req, &page); err != nil {
+			return nil, err
+		}
+		for _, r := range page.Results {
+			marks = append(marks, Bookmark{URL: r.URL, Title: r.Title, Description: r.Description, Tags: r.TagNames, Added: r.DateAdded})
+		}
+		next = ""
+		if page.Next != nil {
+			next = *page.Next
+		}
+	}
+	return marks, nil
+}
+
+func (l *linkding) do(req *http.Request, out any) error {
+	req.Header.Set("Authorization", "Token "+l.token)
+	return do(l.http, req, out)
+}
+
+// do sends req and decodes a JSON response into out, if not nil.
+func do(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, bytes.TrimSpace(detail))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s response: %w", req.URL.Host, err)
+	}
+	return nil
+}
+
+// nonNil returns tags, or an empty list the API accepts in place of null.
+func nonNil(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Spaced-repetition cards made from entries worth remembering
+// ABOUTME: Writes Anki's tab-separated import format with stable GUIDs so re-imports update cards
+
+package cards
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/models"
+)
+
+// excerptWords is how much of the article the back of a card shows when
+// the entry has no summary.
+const excerptWords = 60
+
+// Card is one spaced-repetition card. Front and Back are HTML.
+type Card struct {
+	// GUID identifies the card across exports, so importing again updates
+	// it instead of adding a duplicate.
+	GUID  string
+	Front string
+	Back  string
+	Tags  []string
+}
+
+// Source is an entry to make a card from, with what's known about it.
+type Source struct {
+	Entry *models.Entry
+	// Feed is the feed's display name.
+	Feed string
+	// Folder is the feed's OPML folder, or empty.
+	Folder string
+	// Summary is the entry's summary, or empty to use an excerpt of its content.
+	Summary string
+}
+
+// FromEntry makes a card asking for the gist of an entry: the title and
+// feed on the front, the summary or an excerpt and the link on the back.
+func FromEntry(src Source) Card {
+	e := src.Entry
+	title := models.DefaultEntryTitle
+	if e.Title != nil && strings.TrimSpace(*e.Title) != "" {
+		title = strings.TrimSpace(*e.Title)
+	}
+	front := html.EscapeString(title)
+	if src.Feed != "" {
+		front += "<br><small>" + html.EscapeString(src.Feed) + "</small>"
+	}
+
+	gist := src.Summary
+	if gist == "" && e.Content != nil {
+		gist = excerpt(content.ToText(*e.Content), excerptWords)
+	}
+	var back []string
+	if gist != "" {
+		back = append(back, paragraphs(gist))
+	}
+	if e.Link != nil && *e.Link != "" {
+		link := html.EscapeString(*e.Link)
+		back = append(back, `<a href="`+link+`">`+link+`</a>`)
+	}
+
+	tags := []string{"digest"}
+	if t := tag(src.Folder); t != "" {
+		tags = append(tags, t)
+	}
+	return Card{
+		GUID:  "digest-" + e.ID,
+		Front: front,
+		Back:  strings.Join(back, "<br><br>"),
+		Tags:  tags,
+	}
+}
+
+// WriteAnki writes cards as an Anki text import: tab-separated guid, front,
+// back, and tags columns for the Basic note type, headed by the directives
+// that tell Anki so. deck names the deck to import into; empty leaves the
+// choice to the import dialog.
+func WriteAnki(w io.Writer, deck string, cards []Card) error {
+	headers := []string{
+		"#separator:tab",
+		"#html:true",
+		"#notetype:Basic",
+		"#guid column:1",
+		"#tags column:4",
+	}
+	if deck != "" {
+		headers = append(headers, "#deck:"+deck)
+	}
+	for _, h := range headers {
+		if _, err := fmt.Fprintln(w, h); err != nil {
+			return err
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = '\t'
+	for _, c := range cards {
+		if err := cw.Write([]string{c.GUID, c.Front, c.Back, strings.Join(c.Tags, " ")}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// excerpt returns the first n words of text, marked as cut if there were more.
+func excerpt(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:n], " ") + "…"
+}
+
+// paragraphs escapes text for HTML, keeping its line breaks.
+func paragraphs(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = html.EscapeString(strings.TrimSpace(l))
+	}
+	return strings.Join(lines, "<br>")
+}
+
+var nonTag = regexp.MustCompile(`[^\p{L}\p{N}_-]+`)
+
+// tag turns a folder name into an Anki tag, which can't contain spaces.
+func tag(folder string) string {
+	return strings.Trim(nonTag.ReplaceAllString(folder, "_"), "_")
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Tests for spaced-repetition cards
+// ABOUTME: Covers card fronts and backs, excerpts, tags, and the Anki import file
+
+package cards
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestFromEntry(t *testing.T) {
+	e := models.NewEntry("feed", "guid", "Why <b> tags matter")
+	e.ID = "abc"
+	link := "https://example.com/post?a=1&b=2"
+	e.Link = &link
+	body := "<p>" + strings.Repeat("word ", 80) + "</p>"
+	e.Content = &body
+
+	c := FromEntry(Source{Entry: e, Feed: "Example", Folder: "Deep Reads"})
+	if c.GUID != "digest-abc" {
+		t.Errorf("GUID = %q", c.GUID)
+	}
+	if c.Front != "Why &lt;b&gt; tags matter<br><small>Example</small>" {
+		t.Errorf("Front = %q", c.Front)
+	}
+	if !strings.HasPrefix(c.Back, strings.Repeat("word ", 59)+"word…<br><br>") || !strings.Contains(c.Back, `href="https://example.com/post?a=1&amp;b=2"`) {
+		t.Errorf("Back = %q", c.Back)
+	}
+	if strings.Join(c.Tags, " ") != "digest Deep_Reads" {
+		t.Errorf("Tags = %q", c.Tags)
+	}
+
+	c = FromEntry(Source{Entry: e, Summary: "First point.\nSecond & last."})
+	if !strings.HasPrefix(c.Back, "First point.<br>Second &amp; last.<br><br><a ") {
+		t.Errorf("Back with summary = %q", c.Back)
+	}
+}
+
+func TestWriteAnki(t *testing.T) {
+	var buf bytes.Buffer
+	cards := []Card{
+		{GUID: "digest-1", Front: "One", Back: "Plain", Tags: []string{"digest"}},
+		{GUID: "digest-2", Front: `Say "hi"`, Back: "tab\there", Tags: []string{"digest", "Work"}},
+	}
+	if err := WriteAnki(&buf, "Reading", cards); err != nil {
+		t.Fatalf("WriteAnki: %v", err)
+	}
+	want := "#separator:tab\n#html:true\n#notetype:Basic\n#guid column:1\n#tags column:4\n#deck:Reading\n" +
+		"digest-1\tOne\tPlain\tdigest\n" +
+		"digest-2\t\"Say \"\"hi\"\"\"\t\"tab\there\"\tdigest Work\n"
+	if buf.String() != want {
+		t.Errorf("WriteAnki wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -4,14 +4,34 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/harper/digest/internal/alert"
+	"github.com/harper/digest/internal/bookmarks"
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/deliver"
+	"github.com/harper/digest/internal/digests"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/goals"
+	"github.com/harper/digest/internal/hooks"
+	"github.com/harper/digest/internal/queue"
+	"github.com/harper/digest/internal/score"
+	"github.com/harper/digest/internal/script"
 	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/harper/digest/internal/team"
+	"github.com/harper/digest/internal/timeutil"
+	"github.com/harper/digest/internal/tts"
+	"github.com/harper/digest/internal/update"
 	"github.com/harperreed/mdstore"
 )
 
@@ -27,8 +47,278 @@ type Config struct {
 
 	// DefaultProfile is the profile used when --profile is not specified.
 	DefaultProfile string `json:"default_profile,omitempty"`
+
+	// SyncSafe makes the markdown backend safe to share between machines
+	// with Syncthing or Dropbox: read state goes to an append-only journal
+	// per machine instead of rewriting entry files.
+	SyncSafe bool `json:"sync_safe,omitempty"`
+
+	// DeviceID names this machine's read state journal in sync-safe mode.
+	// Defaults to the hostname; set it if two machines share a hostname.
+	DeviceID string `json:"device_id,omitempty"`
+
+	// SecretsBackend selects where feed passwords, API keys, and database
+	// passphrases are stored: "auto" (default) uses the OS keychain when one
+	// is available and secrets.json next to this file otherwise; "keyring",
+	// "file", and "env" force one. DIGEST_SECRET_* variables always win.
+	SecretsBackend string `json:"secrets_backend,omitempty"`
+
+	// OpenMarksRead controls whether 'digest open' marks entries read.
+	// Defaults to true.
+	OpenMarksRead *bool `json:"open_marks_read,omitempty"`
+
+	// ListMarksRead makes 'digest list' and the list_entries MCP tool mark
+	// the entries they return as read, for skimming headlines. --no-mark
+	// and mark_read=false override it per call.
+	ListMarksRead bool `json:"list_marks_read,omitempty"`
+
+	// NextOrder is the order 'digest next' serves unread entries in:
+	// "oldest" (the default), "score", or "round-robin".
+	NextOrder string `json:"next_order,omitempty"`
+
+	// Goals sets a daily reading goal and an unread ceiling, tracked with a
+	// reading streak in 'digest stats' and digest://goals.
+	Goals *goals.Config `json:"goals,omitempty"`
+
+	// InactiveDays is how long a feed can go without new entries, or keep
+	// failing without a successful fetch, before 'digest fetch' archives it.
+	// Defaults to 90; negative turns automatic archival off.
+	InactiveDays int `json:"inactive_days,omitempty"`
+
+	// UnreadBudgets caps the unread entries in OPML folders, keyed by folder
+	// name ("" for feeds outside any folder). After each sync, a folder over
+	// its budget has its oldest unread entries marked read.
+	UnreadBudgets map[string]int `json:"unread_budgets,omitempty"`
+
+	// Dates bounds the publish dates accepted from feeds.
+	Dates *DatesConfig `json:"dates,omitempty"`
+
+	// Images controls how entries' lead images are found and kept.
+	Images *ImagesConfig `json:"images,omitempty"`
+
+	// Duplicates controls how entries a feed republishes unchanged under
+	// a new GUID are skipped.
+	Duplicates *DuplicatesConfig `json:"duplicates,omitempty"`
+
+	// FeedSnapshots is how many raw responses of each feed 'digest fetch'
+	// keeps on disk, gzip-compressed, for 'digest debug replay'. Zero
+	// keeps none.
+	FeedSnapshots int `json:"feed_snapshots,omitempty"`
+
+	// MonitorMinWords is how many words of a monitored page must change
+	// before its feed gets an entry. Zero reports any change.
+	MonitorMinWords int `json:"monitor_min_words,omitempty"`
+
+	// Backfill limits how much of a newly added feed's history arrives
+	// unread on its first sync. 'digest feed add' and add_feed can
+	// override it per feed.
+	Backfill *BackfillConfig `json:"backfill,omitempty"`
+
+	// Scores controls how 'digest fetch' refreshes Hacker News and Lobsters
+	// points and comment counts.
+	Scores *ScoresConfig `json:"scores,omitempty"`
+
+	// HTTP tunes how feed fetches reuse connections.
+	HTTP *HTTPConfig `json:"http,omitempty"`
+
+	// Timezone is the IANA timezone (e.g. "America/New_York") that periods
+	// like "today" and "week" start in. Defaults to the machine's local time.
+	Timezone string `json:"timezone,omitempty"`
+
+	// MCPLimits caps how much an MCP agent can change in one server session.
+	MCPLimits *MCPLimits `json:"mcp_limits,omitempty"`
+
+	// Content configures how entry HTML is converted to Markdown.
+	Content *ContentConfig `json:"content,omitempty"`
+
+	// TTS selects the text-to-speech backend for 'digest listen'.
+	TTS *tts.Config `json:"tts,omitempty"`
+
+	// Alerts reports feeds that keep failing to sync by webhook, ntfy, or
+	// email.
+	Alerts *alert.Config `json:"alerts,omitempty"`
+
+	// Blogroll opts folders into the public blogroll from 'digest publish
+	// blogroll' and 'digest serve'.
+	Blogroll *BlogrollConfig `json:"blogroll,omitempty"`
+
+	// Bookmarks pushes kept entries to Linkding or Raindrop.io with
+	// 'digest bookmarks'.
+	Bookmarks *bookmarks.Config `json:"bookmarks,omitempty"`
+
+	// Deliver posts new entries or daily digests to Telegram chats and
+	// Matrix rooms.
+	Deliver *deliver.Config `json:"deliver,omitempty"`
+
+	// Digests generates a dated digest document each day after a fetch,
+	// kept as a browsable history.
+	Digests *digests.Config `json:"digests,omitempty"`
+
+	// Team lists the users sharing this instance through 'digest serve',
+	// each with their own read state.
+	Team *team.Config `json:"team,omitempty"`
+
+	// PacksIndex is the URL of a JSON index of starter packs that
+	// 'digest init --pack' offers besides the bundled ones.
+	PacksIndex string `json:"packs_index,omitempty"`
+
+	// UpdateChannel is the release channel 'digest self-update' and
+	// 'digest version --check' follow: "stable" (default) or "edge",
+	// which includes prereleases.
+	UpdateChannel string `json:"update_channel,omitempty"`
+
+	// Hooks runs executables on pre_add_feed, on_new_entry, and post_sync,
+	// each given the event as JSON on stdin.
+	Hooks *hooks.Config `json:"hooks,omitempty"`
+
+	// IngestScripts are Starlark files whose transform(entry, feed) runs
+	// on each new entry during sync, in order, to rewrite, tag, or drop it.
+	IngestScripts []string `json:"ingest_scripts,omitempty"`
+
+	// SyncConcurrency is how many feeds a sync fetches at once. Default 1.
+	SyncConcurrency int `json:"sync_concurrency,omitempty"`
+
+	// profile and shared are set by ApplyProfile: the profile whose own
+	// settings are layered on, and the shared file's settings under them.
+	profile string
+	shared  map[string]json.RawMessage
+}
+
+// BlogrollConfig selects what the public blogroll shares. Nothing is
+// published unless Folders is set.
+type BlogrollConfig struct {
+	// Title heads the page and the OPML file. Default "Blogroll".
+	Title string `json:"title,omitempty"`
+
+	// Folders are the OPML folders to publish, in page order.
+	Folders []string `json:"folders,omitempty"`
+}
+
+// DefaultBlogrollTitle titles the blogroll when none is configured.
+const DefaultBlogrollTitle = "Blogroll"
+
+// ContentConfig tunes HTML to Markdown conversion. Unset fields keep the
+// defaults from content.DefaultOptions.
+type ContentConfig struct {
+	// Tables renders HTML tables as Markdown tables. Default true.
+	Tables *bool `json:"tables,omitempty"`
+
+	// CodeLanguages labels code blocks with their detected language. Default true.
+	CodeLanguages *bool `json:"code_languages,omitempty"`
+
+	// Footnotes is "links" (default), "markdown", or "drop".
+	Footnotes string `json:"footnotes,omitempty"`
+
+	// ReaderView strips page boilerplate such as navigation and share bars.
+	ReaderView bool `json:"reader_view,omitempty"`
+}
+
+// DatesConfig bounds plausible entry publish dates. An entry dated outside
+// them gets the time it was first fetched instead, and keeps the feed's date
+// as its claimed date.
+type DatesConfig struct {
+	// MaxFuture is how far past the fetch time a date may be, as a
+	// duration. Default "24h".
+	MaxFuture string `json:"max_future,omitempty"`
+
+	// Earliest is the oldest plausible date, as YYYY-MM-DD. Default
+	// 1990-01-01.
+	Earliest string `json:"earliest,omitempty"`
+
+	// Keep stores feed dates as published, however implausible.
+	Keep bool `json:"keep,omitempty"`
+}
+
+// DuplicatesConfig controls how sync spots entries a feed republishes under
+// a new GUID: a new entry with the same title, link, and content as one of
+// the feed's entries first stored within the window is skipped.
+type DuplicatesConfig struct {
+	// Window is how far back to look, as a duration. Default "720h".
+	Window string `json:"window,omitempty"`
+
+	// Keep stores republished copies as new entries.
+	Keep bool `json:"keep,omitempty"`
+}
+
+// ImagesConfig controls entry lead images. The image a feed gives is always
+// stored; these add fallbacks and offline copies.
+type ImagesConfig struct {
+	// OpenGraph fetches the article page of new entries without a feed
+	// image and uses its og:image.
+	OpenGraph bool `json:"open_graph,omitempty"`
+
+	// Cache downloads lead images into the profile's thumbnails directory.
+	Cache bool `json:"cache,omitempty"`
+}
+
+// ScoresConfig tunes aggregator score refreshes. Unset fields keep the
+// defaults from score.DefaultPolicy.
+type ScoresConfig struct {
+	// Interval is how long a score stays fresh, as a duration. Default "1h".
+	Interval string `json:"interval,omitempty"`
+
+	// MaxAge stops refreshing entries first seen longer ago, as a
+	// duration. Default "72h".
+	MaxAge string `json:"max_age,omitempty"`
+
+	// Off turns score refreshes off.
+	Off bool `json:"off,omitempty"`
+}
+
+// HTTPConfig tunes the transport shared by every request of a sync. Unset
+// fields keep the defaults from fetch.DefaultTransportOptions.
+type HTTPConfig struct {
+	// MaxIdleConns caps idle keep-alive connections across all hosts.
+	// Default 100.
+	MaxIdleConns int `json:"max_idle_conns,omitempty"`
+
+	// MaxIdleConnsPerHost caps idle connections kept per host. Default 8.
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty"`
+
+	// MaxConnsPerHost caps concurrent connections per host. Default 16;
+	// negative removes the limit.
+	MaxConnsPerHost int `json:"max_conns_per_host,omitempty"`
+
+	// IdleTimeout closes connections idle this long, as a duration.
+	// Default "90s".
+	IdleTimeout string `json:"idle_timeout,omitempty"`
+
+	// HTTP2 negotiates HTTP/2 with servers that offer it. Default true.
+	HTTP2 *bool `json:"http2,omitempty"`
+}
+
+// BackfillConfig is the default first-sync backfill for new feeds. Zero
+// fields leave that limit off.
+type BackfillConfig struct {
+	// Days marks entries published more than this many days before the
+	// first sync as read.
+	Days int `json:"days,omitempty"`
+
+	// Limit imports only this many of the newest entries.
+	Limit int `json:"limit,omitempty"`
+}
+
+// MCPLimits caps MCP mutations so an agent misfire can't add or remove
+// hundreds of feeds before a human notices. Zero fields use the defaults;
+// a negative value removes that limit.
+type MCPLimits struct {
+	// AddFeedsPerHour is the most add_feed calls allowed in any hour.
+	AddFeedsPerHour int `json:"add_feeds_per_hour,omitempty"`
+
+	// RemoveFeedsPerHour is the most remove_feed calls allowed in any hour.
+	RemoveFeedsPerHour int `json:"remove_feeds_per_hour,omitempty"`
+
+	// BulkMarkReadMax is the most entries a single bulk_mark_read may mark.
+	BulkMarkReadMax int `json:"bulk_mark_read_max,omitempty"`
 }
 
+// Default MCP limits, used for any MCPLimits field left at zero.
+const (
+	DefaultAddFeedsPerHour    = 20
+	DefaultRemoveFeedsPerHour = 10
+	DefaultBulkMarkReadMax    = 500
+)
+
 // defaultDBFilename is the SQLite database filename used for existing-user detection.
 const defaultDBFilename = "digest.db"
 
@@ -57,6 +347,439 @@ func (c *Config) GetDefaultProfile() string {
 	return c.DefaultProfile
 }
 
+// GetOpenMarksRead reports whether opening an entry marks it read, defaulting to true.
+func (c *Config) GetOpenMarksRead() bool {
+	return c.OpenMarksRead == nil || *c.OpenMarksRead
+}
+
+// GetDeviceID returns the configured device ID, defaulting to the hostname.
+func (c *Config) GetDeviceID() string {
+	if c.DeviceID != "" {
+		return c.DeviceID
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "localhost"
+	}
+	return host
+}
+
+// GetInactiveAfter returns how long a feed must be quiet or failing before
+// it counts as inactive, or 0 if inactive feeds aren't detected.
+func (c *Config) GetInactiveAfter() time.Duration {
+	switch {
+	case c.InactiveDays < 0:
+		return 0
+	case c.InactiveDays == 0:
+		return feedsync.DefaultInactiveAfter
+	default:
+		return time.Duration(c.InactiveDays) * 24 * time.Hour
+	}
+}
+
+// GetDatePolicy returns the configured publish date bounds.
+func (c *Config) GetDatePolicy() (feedsync.DatePolicy, error) {
+	var policy feedsync.DatePolicy
+	if c.Dates == nil {
+		return policy, nil
+	}
+	policy.Keep = c.Dates.Keep
+	if c.Dates.MaxFuture != "" {
+		d, err := time.ParseDuration(c.Dates.MaxFuture)
+		if err != nil || d <= 0 {
+			return policy, fmt.Errorf("invalid dates.max_future %q: want a positive duration like \"48h\"", c.Dates.MaxFuture)
+		}
+		policy.MaxFuture = d
+	}
+	if c.Dates.Earliest != "" {
+		t, err := time.Parse(time.DateOnly, c.Dates.Earliest)
+		if err != nil {
+			return policy, fmt.Errorf("invalid dates.earliest %q: want YYYY-MM-DD", c.Dates.Earliest)
+		}
+		policy.Earliest = t
+	}
+	return policy, nil
+}
+
+// GetDuplicatePolicy returns which new entries sync skips as republished
+// copies of stored ones.
+func (c *Config) GetDuplicatePolicy() (feedsync.DuplicatePolicy, error) {
+	var policy feedsync.DuplicatePolicy
+	if c.Duplicates == nil {
+		return policy, nil
+	}
+	policy.Keep = c.Duplicates.Keep
+	if c.Duplicates.Window != "" {
+		d, err := time.ParseDuration(c.Duplicates.Window)
+		if err != nil || d <= 0 {
+			return policy, fmt.Errorf("invalid duplicates.window %q: want a positive duration like \"168h\"", c.Duplicates.Window)
+		}
+		policy.Window = d
+	}
+	return policy, nil
+}
+
+// GetImageOptions returns how sync handles lead images, caching them in
+// cacheDir when caching is on.
+func (c *Config) GetImageOptions(cacheDir string) feedsync.ImageOptions {
+	var opts feedsync.ImageOptions
+	if c.Images == nil {
+		return opts
+	}
+	opts.OpenGraph = c.Images.OpenGraph
+	if c.Images.Cache {
+		opts.CacheDir = cacheDir
+	}
+	return opts
+}
+
+// GetSnapshotOptions returns how sync keeps raw feed responses, storing
+// them in dir when snapshots are on.
+func (c *Config) GetSnapshotOptions(dir string) feedsync.SnapshotOptions {
+	if c.FeedSnapshots <= 0 {
+		return feedsync.SnapshotOptions{}
+	}
+	return feedsync.SnapshotOptions{Dir: dir, Keep: c.FeedSnapshots}
+}
+
+// GetMonitorOptions returns which page changes monitor feeds report.
+func (c *Config) GetMonitorOptions() feedsync.MonitorOptions {
+	return feedsync.MonitorOptions{MinWords: c.MonitorMinWords}
+}
+
+// GetNextOrder returns the order 'digest next' serves unread entries in.
+func (c *Config) GetNextOrder() (string, error) {
+	if c.NextOrder == "" {
+		return queue.Oldest, nil
+	}
+	if err := queue.ValidateOrder(c.NextOrder); err != nil {
+		return "", fmt.Errorf("next_order: %w", err)
+	}
+	return c.NextOrder, nil
+}
+
+// GetHooks returns the configured hooks with ~ expanded in their commands.
+func (c *Config) GetHooks() (hooks.Config, error) {
+	if c.Hooks == nil {
+		return hooks.Config{}, nil
+	}
+	if err := c.Hooks.Validate(); err != nil {
+		return hooks.Config{}, fmt.Errorf("hooks: %w", err)
+	}
+	expand := func(list []hooks.Hook) []hooks.Hook {
+		out := make([]hooks.Hook, len(list))
+		for i, h := range list {
+			h.Command = ExpandPath(h.Command)
+			out[i] = h
+		}
+		return out
+	}
+	return hooks.Config{
+		PreAddFeed: expand(c.Hooks.PreAddFeed),
+		OnNewEntry: expand(c.Hooks.OnNewEntry),
+		PostSync:   expand(c.Hooks.PostSync),
+	}, nil
+}
+
+// GetSyncConcurrency returns how many feeds a sync fetches at once.
+func (c *Config) GetSyncConcurrency() int {
+	return max(c.SyncConcurrency, 1)
+}
+
+// GetIngestScripts loads the configured ingest scripts, with ~ expanded in
+// their paths.
+func (c *Config) GetIngestScripts() ([]*script.Script, error) {
+	paths := make([]string, len(c.IngestScripts))
+	for i, p := range c.IngestScripts {
+		paths[i] = ExpandPath(p)
+	}
+	scripts, err := script.LoadAll(paths)
+	if err != nil {
+		return nil, fmt.Errorf("ingest_scripts: %w", err)
+	}
+	return scripts, nil
+}
+
+// GetSyncOptions returns the options every feed of one sync shares: date
+// and duplicate policies, lead images cached in thumbDir, snapshots kept in
+// snapDir, monitor options, the ingest scripts, and runner's on_new_entry
+// hooks. Scripts report problems to warn. Feeds' Secrets are left unset;
+// see FeedSecrets.
+func (c *Config) GetSyncOptions(thumbDir, snapDir string, runner *hooks.Runner, warn io.Writer) (feedsync.Options, error) {
+	dates, err := c.GetDatePolicy()
+	if err != nil {
+		return feedsync.Options{}, err
+	}
+	duplicates, err := c.GetDuplicatePolicy()
+	if err != nil {
+		return feedsync.Options{}, err
+	}
+	scripts, err := c.GetIngestScripts()
+	if err != nil {
+		return feedsync.Options{}, err
+	}
+	opts := feedsync.Options{
+		Dates:      dates,
+		Images:     c.GetImageOptions(thumbDir),
+		Snapshots:  c.GetSnapshotOptions(snapDir),
+		Duplicates: duplicates,
+		Monitor:    c.GetMonitorOptions(),
+	}
+	if runner != nil && len(runner.Config.OnNewEntry) > 0 {
+		opts.NewEntry = runner.NewEntry
+	}
+	if len(scripts) > 0 {
+		opts.Filter = script.Chain(scripts, warn)
+	}
+	return opts, nil
+}
+
+// GetUpdateChannel returns the release channel self-update follows.
+func (c *Config) GetUpdateChannel() (string, error) {
+	channel, err := update.ParseChannel(c.UpdateChannel)
+	if err != nil {
+		return "", fmt.Errorf("update_channel: %w", err)
+	}
+	return channel, nil
+}
+
+// GetGoals returns the reading goals; the zero value sets none.
+func (c *Config) GetGoals() goals.Config {
+	if c.Goals == nil {
+		return goals.Config{}
+	}
+	return *c.Goals
+}
+
+// GetScorePolicy returns how 'digest fetch' refreshes aggregator scores,
+// and false when refreshes are off.
+func (c *Config) GetScorePolicy() (score.Policy, bool, error) {
+	policy := score.DefaultPolicy()
+	if c.Scores == nil {
+		return policy, true, nil
+	}
+	if c.Scores.Interval != "" {
+		d, err := time.ParseDuration(c.Scores.Interval)
+		if err != nil || d <= 0 {
+			return policy, false, fmt.Errorf("invalid scores.interval %q: want a positive duration like \"30m\"", c.Scores.Interval)
+		}
+		policy.Interval = d
+	}
+	if c.Scores.MaxAge != "" {
+		d, err := time.ParseDuration(c.Scores.MaxAge)
+		if err != nil || d <= 0 {
+			return policy, false, fmt.Errorf("invalid scores.max_age %q: want a positive duration like \"48h\"", c.Scores.MaxAge)
+		}
+		policy.MaxAge = d
+	}
+	return policy, !c.Scores.Off, nil
+}
+
+// GetTransportOptions returns the settings for fetch's shared transport.
+func (c *Config) GetTransportOptions() (fetch.TransportOptions, error) {
+	opts := fetch.DefaultTransportOptions()
+	if c.HTTP == nil {
+		return opts, nil
+	}
+	if c.HTTP.MaxIdleConns < 0 || c.HTTP.MaxIdleConnsPerHost < 0 {
+		return opts, fmt.Errorf("invalid http: max_idle_conns and max_idle_conns_per_host must not be negative")
+	}
+	if c.HTTP.MaxIdleConns > 0 {
+		opts.MaxIdleConns = c.HTTP.MaxIdleConns
+	}
+	if c.HTTP.MaxIdleConnsPerHost > 0 {
+		opts.MaxIdleConnsPerHost = c.HTTP.MaxIdleConnsPerHost
+	}
+	switch {
+	case c.HTTP.MaxConnsPerHost < 0:
+		opts.MaxConnsPerHost = 0
+	case c.HTTP.MaxConnsPerHost > 0:
+		opts.MaxConnsPerHost = c.HTTP.MaxConnsPerHost
+	}
+	if c.HTTP.IdleTimeout != "" {
+		d, err := time.ParseDuration(c.HTTP.IdleTimeout)
+		if err != nil || d <= 0 {
+			return opts, fmt.Errorf("invalid http.idle_timeout %q: want a positive duration like \"90s\"", c.HTTP.IdleTimeout)
+		}
+		opts.IdleConnTimeout = d
+	}
+	opts.DisableHTTP2 = c.HTTP.HTTP2 != nil && !*c.HTTP.HTTP2
+	return opts, nil
+}
+
+// GetBackfill returns the default first-sync backfill for new feeds.
+func (c *Config) GetBackfill() (BackfillConfig, error) {
+	if c.Backfill == nil {
+		return BackfillConfig{}, nil
+	}
+	if c.Backfill.Days < 0 || c.Backfill.Limit < 0 {
+		return BackfillConfig{}, fmt.Errorf("invalid backfill: days and limit must not be negative")
+	}
+	return *c.Backfill, nil
+}
+
+// GetLocation returns the configured timezone, defaulting to local time.
+func (c *Config) GetLocation() (*time.Location, error) {
+	return timeutil.LoadLocation(c.Timezone)
+}
+
+// GetMCPLimits returns the configured MCP limits with defaults filled in.
+func (c *Config) GetMCPLimits() MCPLimits {
+	limits := MCPLimits{}
+	if c.MCPLimits != nil {
+		limits = *c.MCPLimits
+	}
+	if limits.AddFeedsPerHour == 0 {
+		limits.AddFeedsPerHour = DefaultAddFeedsPerHour
+	}
+	if limits.RemoveFeedsPerHour == 0 {
+		limits.RemoveFeedsPerHour = DefaultRemoveFeedsPerHour
+	}
+	if limits.BulkMarkReadMax == 0 {
+		limits.BulkMarkReadMax = DefaultBulkMarkReadMax
+	}
+	return limits
+}
+
+// GetContentOptions returns the configured content conversion options.
+func (c *Config) GetContentOptions() content.Options {
+	opts := content.DefaultOptions()
+	if c.Content == nil {
+		return opts
+	}
+	if c.Content.Tables != nil {
+		opts.Tables = *c.Content.Tables
+	}
+	if c.Content.CodeLanguages != nil {
+		opts.CodeLanguages = *c.Content.CodeLanguages
+	}
+	if c.Content.Footnotes != "" {
+		opts.Footnotes = c.Content.Footnotes
+	}
+	opts.ReaderView = c.Content.ReaderView
+	return opts
+}
+
+// GetTTS returns the configured text-to-speech settings, or an empty Config
+// if none are set. An OpenAI key not in the environment is looked up in the
+// secrets provider.
+func (c *Config) GetTTS() tts.Config {
+	if c.TTS == nil {
+		return tts.Config{}
+	}
+	t := *c.TTS
+	if t.Backend == tts.BackendOpenAI && os.Getenv(t.KeyEnv()) == "" {
+		if p, err := c.Secrets(); err == nil {
+			t.APIKey, _ = p.Get(TTSAPIKeySecret)
+		}
+	}
+	return t
+}
+
+// GetAlerts returns the alert settings, with the ntfy token and SMTP
+// password filled in from the secrets store when those channels are used.
+func (c *Config) GetAlerts() alert.Config {
+	if c.Alerts == nil {
+		return alert.Config{}
+	}
+	a := *c.Alerts
+	if a.Ntfy == "" && a.Email == nil {
+		return a
+	}
+	p, err := c.Secrets()
+	if err != nil {
+		return a
+	}
+	if a.Ntfy != "" {
+		a.NtfyToken, _ = p.Get(NtfyTokenSecret)
+	}
+	if a.Email != nil {
+		email := *a.Email
+		email.Password, _ = p.Get(SMTPPasswordSecret)
+		a.Email = &email
+	}
+	return a
+}
+
+// GetBookmarks returns the bookmark service settings, with the API token
+// filled in from the secrets store.
+func (c *Config) GetBookmarks() bookmarks.Config {
+	if c.Bookmarks == nil {
+		return bookmarks.Config{}
+	}
+	b := *c.Bookmarks
+	if p, err := c.Secrets(); err == nil {
+		b.Token, _ = p.Get(BookmarksTokenSecret)
+	}
+	return b
+}
+
+// GetDeliver returns the delivery targets, with each target's token filled
+// in from the secrets store.
+func (c *Config) GetDeliver() deliver.Config {
+	if c.Deliver == nil {
+		return deliver.Config{}
+	}
+	d := deliver.Config{Targets: append([]deliver.Target(nil), c.Deliver.Targets...)}
+	p, err := c.Secrets()
+	if err != nil {
+		return d
+	}
+	for i := range d.Targets {
+		d.Targets[i].Token, _ = p.Get(DeliverTokenSecret(d.Targets[i].Name))
+	}
+	return d
+}
+
+// GetDigests returns the daily digest schedule, and false when scheduled
+// digests are off.
+func (c *Config) GetDigests() (digests.Config, bool) {
+	if c.Digests == nil {
+		return digests.Config{}, false
+	}
+	return *c.Digests, true
+}
+
+// GetTeam returns the team's users, with each user's API token filled in
+// from the secrets store.
+func (c *Config) GetTeam() team.Config {
+	if c.Team == nil {
+		return team.Config{}
+	}
+	t := team.Config{Users: append([]string(nil), c.Team.Users...), Roles: maps.Clone(c.Team.Roles), Tokens: map[string]string{}}
+	p, err := c.Secrets()
+	if err != nil {
+		return t
+	}
+	for _, user := range t.Users {
+		t.Tokens[user], _ = p.Get(TeamTokenSecret(user))
+	}
+	return t
+}
+
+// GetSlackSigningSecret returns the Slack app's signing secret, or "" when
+// none is stored.
+func (c *Config) GetSlackSigningSecret() string {
+	p, err := c.Secrets()
+	if err != nil {
+		return ""
+	}
+	secret, _ := p.Get(SlackSigningSecret)
+	return secret
+}
+
+// GetBlogroll returns the blogroll settings with the default title filled in.
+func (c *Config) GetBlogroll() BlogrollConfig {
+	b := BlogrollConfig{Title: DefaultBlogrollTitle}
+	if c.Blogroll != nil {
+		b.Folders = c.Blogroll.Folders
+		if c.Blogroll.Title != "" {
+			b.Title = c.Blogroll.Title
+		}
+	}
+	return b
+}
+
 // ExpandPath expands a leading ~ to the user's home directory.
 func ExpandPath(path string) string {
 	if path == "" {
@@ -110,10 +833,21 @@ func (c *Config) ProfileDataDir(profile string) (string, error) {
 func (c *Config) openStore(backend, dataDir string) (storage.Store, error) {
 	switch backend {
 	case "sqlite":
+		encPath := filepath.Join(dataDir, storage.EncryptedDBFilename)
+		if fileExists(encPath) {
+			passphrase, err := c.Passphrase(encPath)
+			if err != nil {
+				return nil, err
+			}
+			return storage.NewEncryptedSQLiteStore(encPath, passphrase)
+		}
 		dbPath := filepath.Join(dataDir, "digest.db")
 		return storage.NewSQLiteStore(dbPath)
 	case "markdown":
-		return storage.NewMarkdownStore(dataDir)
+		return storage.NewMarkdownStoreWithOptions(dataDir, storage.MarkdownOptions{
+			SyncSafe: c.SyncSafe,
+			DeviceID: c.GetDeviceID(),
+		})
 	default:
 		return nil, fmt.Errorf("unknown backend: %q", backend)
 	}
@@ -136,7 +870,15 @@ func (c *Config) OpenProfileStorage(profile string) (storage.Store, error) {
 		return nil, fmt.Errorf("failed to create profile directory: %w", err)
 	}
 
-	return c.openStore(c.GetBackend(), profileDir)
+	store, err := c.openStore(c.GetBackend(), profileDir)
+	if err != nil {
+		return nil, err
+	}
+	// Passwords saved by older versions stay in plaintext no longer than
+	// the next open. Failing that is no reason to refuse the store;
+	// 'digest secrets status' reports what's left.
+	_, _ = c.MigrateFeedPasswords(context.Background(), store)
+	return store, nil
 }
 
 // MigrateToProfileLayout moves flat-layout data files from the data dir root
@@ -147,7 +889,7 @@ func (c *Config) MigrateToProfileLayout() error {
 	dataDir := c.GetDataDir()
 
 	// Detect flat layout by checking for known data files at root
-	knownFiles := []string{"digest.db", "feeds.opml", "_feeds.yaml"}
+	knownFiles := []string{"digest.db", storage.EncryptedDBFilename, "feeds.opml", "_feeds.yaml"}
 	needsMigration := false
 	for _, name := range knownFiles {
 		if fileExists(filepath.Join(dataDir, name)) {
@@ -169,6 +911,7 @@ func (c *Config) MigrateToProfileLayout() error {
 	// Move known files (SQLite DB + sidecars, OPML, markdown feed registry)
 	filesToMove := []string{
 		"digest.db", "digest.db-wal", "digest.db-shm",
+		storage.EncryptedDBFilename,
 		"feeds.opml",
 		"_feeds.yaml",
 	}
@@ -225,6 +968,11 @@ func GetConfigPath() string {
 	return filepath.Join(configDir, "digest", "config.json")
 }
 
+// TemplatesDir returns the directory user templates are loaded from.
+func TemplatesDir() string {
+	return filepath.Join(filepath.Dir(GetConfigPath()), "templates")
+}
+
 // Load reads config from disk.
 func Load() (*Config, error) {
 	path := GetConfigPath()
@@ -247,8 +995,12 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
-// Save writes config to disk.
+// Save writes config to disk: to config.json, or after ApplyProfile to the
+// profile's own settings file.
 func (c *Config) Save() error {
+	if c.profile != "" {
+		return c.saveProfile()
+	}
 	path := GetConfigPath()
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
@@ -4,14 +4,21 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/hooks"
 	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/score"
+	"github.com/harper/digest/internal/secrets"
 )
 
 func TestGetConfigPath(t *testing.T) {
@@ -148,6 +155,157 @@ func TestExplicitBackend(t *testing.T) {
 	}
 }
 
+func TestGetOpenMarksRead(t *testing.T) {
+	if !(&Config{}).GetOpenMarksRead() {
+		t.Error("expected open to mark entries read by default")
+	}
+	off := false
+	if (&Config{OpenMarksRead: &off}).GetOpenMarksRead() {
+		t.Error("expected open_marks_read=false to be honored")
+	}
+}
+
+func TestGetInactiveAfter(t *testing.T) {
+	if got := (&Config{}).GetInactiveAfter(); got != 90*24*time.Hour {
+		t.Errorf("expected 90 days by default, got %v", got)
+	}
+	if got := (&Config{InactiveDays: 30}).GetInactiveAfter(); got != 30*24*time.Hour {
+		t.Errorf("expected 30 days, got %v", got)
+	}
+	if got := (&Config{InactiveDays: -1}).GetInactiveAfter(); got != 0 {
+		t.Errorf("expected detection off for negative inactive_days, got %v", got)
+	}
+}
+
+func TestGetDatePolicy(t *testing.T) {
+	policy, err := (&Config{}).GetDatePolicy()
+	if err != nil || policy.MaxFuture != 0 || !policy.Earliest.IsZero() || policy.Keep {
+		t.Errorf("expected the default policy, got %+v (%v)", policy, err)
+	}
+	policy, err = (&Config{Dates: &DatesConfig{MaxFuture: "48h", Earliest: "2000-01-01", Keep: true}}).GetDatePolicy()
+	if err != nil {
+		t.Fatalf("GetDatePolicy: %v", err)
+	}
+	if policy.MaxFuture != 48*time.Hour || !policy.Earliest.Equal(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)) || !policy.Keep {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+	if _, err := (&Config{Dates: &DatesConfig{MaxFuture: "soon"}}).GetDatePolicy(); err == nil {
+		t.Error("expected an error for a bad max_future")
+	}
+	if _, err := (&Config{Dates: &DatesConfig{Earliest: "1/1/2000"}}).GetDatePolicy(); err == nil {
+		t.Error("expected an error for a bad earliest date")
+	}
+}
+
+func TestGetDuplicatePolicy(t *testing.T) {
+	policy, err := (&Config{}).GetDuplicatePolicy()
+	if err != nil || policy.Window != 0 || policy.Keep {
+		t.Errorf("expected the default policy, got %+v (%v)", policy, err)
+	}
+	policy, err = (&Config{Duplicates: &DuplicatesConfig{Window: "168h", Keep: true}}).GetDuplicatePolicy()
+	if err != nil || policy.Window != 168*time.Hour || !policy.Keep {
+		t.Errorf("unexpected policy %+v (%v)", policy, err)
+	}
+	if _, err := (&Config{Duplicates: &DuplicatesConfig{Window: "-1h"}}).GetDuplicatePolicy(); err == nil {
+		t.Error("expected an error for a negative window")
+	}
+}
+
+func TestGetNextOrder(t *testing.T) {
+	if order, err := (&Config{}).GetNextOrder(); err != nil || order != "oldest" {
+		t.Errorf("expected oldest by default, got %q (%v)", order, err)
+	}
+	if order, err := (&Config{NextOrder: "round-robin"}).GetNextOrder(); err != nil || order != "round-robin" {
+		t.Errorf("unexpected order %q (%v)", order, err)
+	}
+	if _, err := (&Config{NextOrder: "random"}).GetNextOrder(); err == nil {
+		t.Error("expected an error for an unknown order")
+	}
+}
+
+func TestGetImageOptions(t *testing.T) {
+	if opts := (&Config{}).GetImageOptions("/thumbs"); opts.OpenGraph || opts.CacheDir != "" {
+		t.Errorf("expected feed images only by default, got %+v", opts)
+	}
+	opts := (&Config{Images: &ImagesConfig{OpenGraph: true, Cache: true}}).GetImageOptions("/thumbs")
+	if !opts.OpenGraph || opts.CacheDir != "/thumbs" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+}
+
+func TestGetScorePolicy(t *testing.T) {
+	policy, on, err := (&Config{}).GetScorePolicy()
+	if err != nil || !on || policy != score.DefaultPolicy() {
+		t.Errorf("expected the default policy, got %+v, %v (%v)", policy, on, err)
+	}
+	policy, on, err = (&Config{Scores: &ScoresConfig{Interval: "30m", MaxAge: "24h"}}).GetScorePolicy()
+	if err != nil || !on || policy.Interval != 30*time.Minute || policy.MaxAge != 24*time.Hour {
+		t.Errorf("unexpected policy %+v, %v (%v)", policy, on, err)
+	}
+	if _, on, _ := (&Config{Scores: &ScoresConfig{Off: true}}).GetScorePolicy(); on {
+		t.Error("expected refreshes to be off")
+	}
+	if _, _, err := (&Config{Scores: &ScoresConfig{Interval: "-1h"}}).GetScorePolicy(); err == nil {
+		t.Error("expected an error for a negative interval")
+	}
+}
+
+func TestGetBackfill(t *testing.T) {
+	backfill, err := (&Config{}).GetBackfill()
+	if err != nil || backfill != (BackfillConfig{}) {
+		t.Errorf("expected no backfill limits by default, got %+v (%v)", backfill, err)
+	}
+	backfill, err = (&Config{Backfill: &BackfillConfig{Days: 14, Limit: 50}}).GetBackfill()
+	if err != nil || backfill.Days != 14 || backfill.Limit != 50 {
+		t.Errorf("unexpected backfill %+v (%v)", backfill, err)
+	}
+	if _, err := (&Config{Backfill: &BackfillConfig{Limit: -1}}).GetBackfill(); err == nil {
+		t.Error("expected an error for a negative limit")
+	}
+}
+
+func TestGetSnapshotOptions(t *testing.T) {
+	if opts := (&Config{}).GetSnapshotOptions("/snaps"); opts.Keep != 0 || opts.Dir != "" {
+		t.Errorf("expected snapshots off by default, got %+v", opts)
+	}
+	if opts := (&Config{FeedSnapshots: 3}).GetSnapshotOptions("/snaps"); opts.Keep != 3 || opts.Dir != "/snaps" {
+		t.Errorf("unexpected snapshot options %+v", opts)
+	}
+}
+
+func TestGetTransportOptions(t *testing.T) {
+	opts, err := (&Config{}).GetTransportOptions()
+	if err != nil || opts != fetch.DefaultTransportOptions() {
+		t.Errorf("expected the default transport, got %+v (%v)", opts, err)
+	}
+	off := false
+	opts, err = (&Config{HTTP: &HTTPConfig{MaxIdleConnsPerHost: 4, MaxConnsPerHost: -1, IdleTimeout: "30s", HTTP2: &off}}).GetTransportOptions()
+	if err != nil || opts.MaxIdleConnsPerHost != 4 || opts.MaxConnsPerHost != 0 ||
+		opts.IdleConnTimeout != 30*time.Second || !opts.DisableHTTP2 || opts.MaxIdleConns != 100 {
+		t.Errorf("unexpected transport %+v (%v)", opts, err)
+	}
+	if _, err := (&Config{HTTP: &HTTPConfig{IdleTimeout: "soon"}}).GetTransportOptions(); err == nil {
+		t.Error("expected an error for an invalid idle timeout")
+	}
+	if _, err := (&Config{HTTP: &HTTPConfig{MaxIdleConns: -1}}).GetTransportOptions(); err == nil {
+		t.Error("expected an error for a negative idle connection limit")
+	}
+}
+
+func TestGetLocation(t *testing.T) {
+	loc, err := (&Config{}).GetLocation()
+	if err != nil || loc != time.Local {
+		t.Errorf("expected local time by default, got %v (%v)", loc, err)
+	}
+	loc, err = (&Config{Timezone: "Asia/Tokyo"}).GetLocation()
+	if err != nil || loc.String() != "Asia/Tokyo" {
+		t.Errorf("expected Asia/Tokyo, got %v (%v)", loc, err)
+	}
+	if _, err := (&Config{Timezone: "Nowhere/Special"}).GetLocation(); err == nil {
+		t.Error("expected an error for an unknown timezone")
+	}
+}
+
 func TestDefaultDataDir(t *testing.T) {
 	cfg := &Config{}
 	dataDir := cfg.GetDataDir()
@@ -683,12 +841,12 @@ func TestProfileIsolation(t *testing.T) {
 		URL:       "https://example.com/feed.xml",
 		CreatedAt: time.Now(),
 	}
-	if err := workStore.CreateFeed(feed); err != nil {
+	if err := workStore.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("failed to create feed in work: %v", err)
 	}
 
 	// Verify feed exists in work but not in personal
-	workFeeds, err := workStore.ListFeeds()
+	workFeeds, err := workStore.ListFeeds(context.Background())
 	if err != nil {
 		t.Fatalf("failed to list work feeds: %v", err)
 	}
@@ -696,7 +854,7 @@ func TestProfileIsolation(t *testing.T) {
 		t.Errorf("expected 1 work feed, got %d", len(workFeeds))
 	}
 
-	personalFeeds, err := personalStore.ListFeeds()
+	personalFeeds, err := personalStore.ListFeeds(context.Background())
 	if err != nil {
 		t.Fatalf("failed to list personal feeds: %v", err)
 	}
@@ -811,3 +969,178 @@ func TestDefaultFirstRunConfig_SQLiteInProfileLayout(t *testing.T) {
 		t.Errorf("expected backend 'sqlite' for existing SQLite user in profile layout, got %q", cfg.Backend)
 	}
 }
+
+func TestGetMCPLimitsDefaults(t *testing.T) {
+	cfg := &Config{}
+	limits := cfg.GetMCPLimits()
+	if limits.AddFeedsPerHour != DefaultAddFeedsPerHour || limits.RemoveFeedsPerHour != DefaultRemoveFeedsPerHour || limits.BulkMarkReadMax != DefaultBulkMarkReadMax {
+		t.Errorf("expected default limits, got %+v", limits)
+	}
+
+	cfg.MCPLimits = &MCPLimits{AddFeedsPerHour: 5, BulkMarkReadMax: -1}
+	limits = cfg.GetMCPLimits()
+	if limits.AddFeedsPerHour != 5 {
+		t.Errorf("expected configured add limit 5, got %d", limits.AddFeedsPerHour)
+	}
+	if limits.RemoveFeedsPerHour != DefaultRemoveFeedsPerHour {
+		t.Errorf("expected default remove limit, got %d", limits.RemoveFeedsPerHour)
+	}
+	if limits.BulkMarkReadMax != -1 {
+		t.Errorf("expected negative bulk limit to be kept as unlimited, got %d", limits.BulkMarkReadMax)
+	}
+}
+
+func TestGetContentOptions(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.GetContentOptions(); got != content.DefaultOptions() {
+		t.Errorf("expected default content options, got %+v", got)
+	}
+
+	tables := false
+	cfg.Content = &ContentConfig{Tables: &tables, Footnotes: content.FootnotesMarkdown, ReaderView: true}
+	got := cfg.GetContentOptions()
+	if got.Tables {
+		t.Error("expected tables to be disabled")
+	}
+	if !got.CodeLanguages {
+		t.Error("expected unset code_languages to keep the default")
+	}
+	if got.Footnotes != content.FootnotesMarkdown || !got.ReaderView {
+		t.Errorf("expected configured footnotes and reader view, got %+v", got)
+	}
+}
+
+func TestGetDeviceID(t *testing.T) {
+	if got := (&Config{DeviceID: "work-laptop"}).GetDeviceID(); got != "work-laptop" {
+		t.Errorf("expected the configured device ID, got %q", got)
+	}
+	if (&Config{}).GetDeviceID() == "" {
+		t.Error("expected a hostname default")
+	}
+}
+
+func TestOpenStorageUsesEncryptedDatabase(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Backend: "sqlite", DataDir: dir, SecretsBackend: "file"}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "")
+	PassphrasePrompt = nil
+
+	// A plain database is opened without asking for anything
+	store, err := cfg.OpenProfileStorage("default")
+	if err != nil {
+		t.Fatalf("open plain store: %v", err)
+	}
+	store.Close()
+
+	encPath := filepath.Join(dir, "default", "digest.db.enc")
+	if err := os.WriteFile(encPath, []byte("not really encrypted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.OpenProfileStorage("default"); err == nil || !strings.Contains(err.Error(), PassphraseEnv) {
+		t.Errorf("expected an error naming %s, got %v", PassphraseEnv, err)
+	}
+
+	var asked string
+	PassphrasePrompt = func(path string) (string, error) {
+		asked = path
+		return "secret", nil
+	}
+	defer func() { PassphrasePrompt = nil }()
+	if _, err := cfg.OpenProfileStorage("default"); err == nil {
+		t.Error("expected a damaged encrypted file to fail to open")
+	}
+	if asked != encPath {
+		t.Errorf("expected a prompt for %s, got %q", encPath, asked)
+	}
+
+	// A saved passphrase is used without prompting
+	provider, err := cfg.Secrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Set(PassphraseSecret(encPath), "saved"); err != nil {
+		t.Fatal(err)
+	}
+	asked = ""
+	if p, err := cfg.Passphrase(encPath); err != nil || p != "saved" {
+		t.Errorf("expected the saved passphrase, got %q (%v)", p, err)
+	}
+	if asked != "" {
+		t.Error("expected no prompt with a saved passphrase")
+	}
+}
+
+func TestOpenProfileStorageMigratesFeedPasswords(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{Backend: "sqlite", DataDir: dir, SecretsBackend: "file"}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	ctx := context.Background()
+
+	store, err := cfg.OpenProfileStorage("default")
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	// As an older version saved it
+	feed := models.NewFeed("https://example.com/private.xml")
+	username, password := "reader", "hunter2"
+	feed.AuthUsername, feed.AuthPassword = &username, &password
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	store.Close()
+
+	store, err = cfg.OpenProfileStorage("default")
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer store.Close()
+	got, err := store.GetFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	if got.AuthPassword == nil || *got.AuthPassword != secrets.Ref(FeedPasswordSecret(feed.ID)) {
+		t.Fatalf("stored password = %v, want a secret reference", got.AuthPassword)
+	}
+	provider, err := cfg.Secrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := provider.Get(FeedPasswordSecret(feed.ID)); err != nil || secret != password {
+		t.Errorf("secret = %q, %v; want the password", secret, err)
+	}
+}
+
+func TestGetSyncOptions(t *testing.T) {
+	cfg := &Config{
+		Images:        &ImagesConfig{Cache: true},
+		FeedSnapshots: 3,
+		Hooks:         &hooks.Config{OnNewEntry: []hooks.Hook{{Command: "true"}}},
+	}
+	conf, err := cfg.GetHooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := cfg.GetSyncOptions("/thumbs", "/snaps", &hooks.Runner{Config: conf}, io.Discard)
+	if err != nil {
+		t.Fatalf("GetSyncOptions: %v", err)
+	}
+	if opts.Images.CacheDir != "/thumbs" || opts.Snapshots.Dir != "/snaps" || opts.Snapshots.Keep != 3 {
+		t.Errorf("images %+v, snapshots %+v", opts.Images, opts.Snapshots)
+	}
+	if opts.NewEntry == nil {
+		t.Error("on_new_entry hooks should set NewEntry")
+	}
+	if opts.Filter != nil {
+		t.Error("Filter set without ingest scripts")
+	}
+
+	// Without hooks there's nothing to call per entry
+	opts, err = (&Config{}).GetSyncOptions("", "", &hooks.Runner{}, io.Discard)
+	if err != nil {
+		t.Fatalf("GetSyncOptions: %v", err)
+	}
+	if opts.NewEntry != nil {
+		t.Error("NewEntry set without on_new_entry hooks")
+	}
+}
@@ -0,0 +1,45 @@
+// ABOUTME: Passphrase lookup for encrypted SQLite databases
+// ABOUTME: Reads DIGEST_PASSPHRASE, then the secrets provider, then asks through a prompt hook the CLI installs
+
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// PassphraseEnv names the environment variable holding the database
+// passphrase, for non-interactive use such as cron jobs and the MCP server.
+const PassphraseEnv = "DIGEST_PASSPHRASE"
+
+// PassphrasePrompt asks the user for the passphrase of the encrypted
+// database at path. It is nil unless a caller with a terminal sets it.
+var PassphrasePrompt func(path string) (string, error)
+
+// PassphraseSecret names the secret holding the passphrase for the
+// encrypted database at path.
+func PassphraseSecret(path string) string {
+	return "passphrase:" + path
+}
+
+// Passphrase returns the passphrase for the encrypted database at path,
+// from the environment, the secrets provider, or by prompting.
+func (c *Config) Passphrase(path string) (string, error) {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return p, nil
+	}
+	// A keychain that can't be reached just means asking instead
+	if provider, err := c.Secrets(); err == nil {
+		if p, err := provider.Get(PassphraseSecret(path)); err == nil {
+			return p, nil
+		}
+	}
+	if PassphrasePrompt == nil {
+		return "", fmt.Errorf("%s is encrypted; set %s or save the passphrase with 'digest encrypt --remember'", path, PassphraseEnv)
+	}
+	p, err := PassphrasePrompt(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,144 @@
+// ABOUTME: Per-profile settings layered over the shared config.json
+// ABOUTME: Loads <data-dir>/<profile>/config.json on top of the shared file and saves a profile's changes back to it
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/harperreed/mdstore"
+)
+
+// ProfileConfigFile names a profile's own settings file in its data
+// directory. Settings there override config.json's for that profile.
+const ProfileConfigFile = "config.json"
+
+// sharedOnly are the settings a profile can't override: they locate the
+// profiles themselves.
+var sharedOnly = []string{"data_dir", "default_profile"}
+
+// ProfileConfigPath returns the settings file of a profile.
+func (c *Config) ProfileConfigPath(profile string) (string, error) {
+	dir, err := c.ProfileDataDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ProfileConfigFile), nil
+}
+
+// ApplyProfile layers the profile's own settings file, if it has one, over
+// c. Settings merge field by field, so a profile that sets "images":
+// {"open_graph": true} keeps the shared file's other image settings; maps
+// such as unread_budgets merge key by key. data_dir and default_profile
+// always come from the shared file.
+//
+// Afterwards Save writes the settings that differ from the shared file
+// to the profile's file and leaves config.json alone.
+func (c *Config) ApplyProfile(profile string) error {
+	path, err := c.ProfileConfigPath(profile)
+	if err != nil {
+		return err
+	}
+	shared, err := fields(c)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		dataDir, defaultProfile := c.DataDir, c.DefaultProfile
+		if err := json.Unmarshal(data, c); err != nil {
+			return fmt.Errorf("invalid profile settings %s: %w", path, err)
+		}
+		c.DataDir, c.DefaultProfile = dataDir, defaultProfile
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read profile settings: %w", err)
+	}
+	c.profile, c.shared = profile, shared
+	return nil
+}
+
+// ForProfile returns the settings of another profile: the shared file's,
+// with that profile's own layered over them.
+func (c *Config) ForProfile(profile string) (*Config, error) {
+	shared := c.shared
+	if shared == nil {
+		var err error
+		if shared, err = fields(c); err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.Marshal(shared)
+	if err != nil {
+		return nil, err
+	}
+	var out Config
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if err := out.ApplyProfile(profile); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// saveProfile writes the settings that differ from the shared file to the
+// profile's own file.
+func (c *Config) saveProfile() error {
+	current, err := fields(c)
+	if err != nil {
+		return err
+	}
+	overrides := make(map[string]json.RawMessage)
+	for key, value := range current {
+		if !bytes.Equal(c.shared[key], value) {
+			overrides[key] = value
+		}
+	}
+	// A shared setting the profile cleared has to be cleared explicitly
+	for key := range c.shared {
+		if _, ok := current[key]; !ok {
+			overrides[key] = json.RawMessage("null")
+		}
+	}
+	for _, key := range sharedOnly {
+		delete(overrides, key)
+	}
+
+	path, err := c.ProfileConfigPath(c.profile)
+	if err != nil {
+		return err
+	}
+	if len(overrides) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := mdstore.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return mdstore.AtomicWrite(path, data)
+}
+
+// fields returns c's settings keyed by their JSON names, in the compact
+// form json.Marshal gives them so equal settings compare equal.
+func fields(c *Config) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]json.RawMessage
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,145 @@
+// ABOUTME: Tests for per-profile settings layered over config.json
+// ABOUTME: Covers merging a profile's file, saving only its overrides, and reading another profile's settings
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeProfileConfig writes a profile's own settings file under c's data directory.
+func writeProfileConfig(t *testing.T, c *Config, profile, body string) {
+	t.Helper()
+	path, err := c.ProfileConfigPath(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	dataDir := t.TempDir()
+	c := &Config{
+		DataDir:       dataDir,
+		Timezone:      "UTC",
+		Images:        &ImagesConfig{Cache: true},
+		UnreadBudgets: map[string]int{"News": 50},
+	}
+	writeProfileConfig(t, c, "work", `{
+		"timezone": "Asia/Tokyo",
+		"backend": "markdown",
+		"images": {"open_graph": true},
+		"unread_budgets": {"Tech": 10},
+		"data_dir": "/elsewhere"
+	}`)
+
+	if err := c.ApplyProfile("work"); err != nil {
+		t.Fatalf("ApplyProfile: %v", err)
+	}
+	if c.Timezone != "Asia/Tokyo" || c.GetBackend() != "markdown" {
+		t.Errorf("expected the profile's timezone and backend, got %q, %q", c.Timezone, c.GetBackend())
+	}
+	if !c.Images.Cache || !c.Images.OpenGraph {
+		t.Errorf("expected image settings to merge, got %+v", c.Images)
+	}
+	if c.UnreadBudgets["News"] != 50 || c.UnreadBudgets["Tech"] != 10 {
+		t.Errorf("expected budgets to merge by folder, got %v", c.UnreadBudgets)
+	}
+	if c.DataDir != dataDir {
+		t.Errorf("expected data_dir to stay shared, got %q", c.DataDir)
+	}
+
+	// A profile without a settings file keeps the shared ones
+	other := &Config{DataDir: dataDir, Timezone: "UTC"}
+	if err := other.ApplyProfile("personal"); err != nil || other.Timezone != "UTC" {
+		t.Errorf("expected shared settings for a profile without its own, got %q, %v", other.Timezone, err)
+	}
+
+	writeProfileConfig(t, c, "broken", `{"timezone":`)
+	if err := (&Config{DataDir: dataDir}).ApplyProfile("broken"); err == nil {
+		t.Error("expected an error for an invalid profile settings file")
+	}
+}
+
+func TestSaveAfterApplyProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	shared := &Config{DataDir: t.TempDir(), Timezone: "UTC", SyncConcurrency: 4}
+	if err := shared.Save(); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ApplyProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	c.Timezone = "Europe/Berlin"
+	c.ListMarksRead = true
+	c.DefaultProfile = "work"
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	after, err := os.ReadFile(GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("expected config.json untouched, got:\n%s", after)
+	}
+	path, _ := c.ProfileConfigPath("work")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected the profile's settings file: %v", err)
+	}
+	var saved map[string]any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || saved["timezone"] != "Europe/Berlin" || saved["list_marks_read"] != true {
+		t.Errorf("expected only the profile's overrides saved, got %v", saved)
+	}
+
+	// Undoing the overrides removes the file
+	c.Timezone, c.ListMarksRead = "UTC", false
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected a profile with no overrides to have no settings file")
+	}
+}
+
+func TestForProfile(t *testing.T) {
+	c := &Config{DataDir: t.TempDir(), Timezone: "UTC"}
+	writeProfileConfig(t, c, "work", `{"timezone": "Asia/Tokyo"}`)
+	writeProfileConfig(t, c, "personal", `{"list_marks_read": true}`)
+	if err := c.ApplyProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	personal, err := c.ForProfile("personal")
+	if err != nil {
+		t.Fatalf("ForProfile: %v", err)
+	}
+	if personal.Timezone != "UTC" || !personal.ListMarksRead {
+		t.Errorf("expected shared settings plus personal's own, got %q, %v", personal.Timezone, personal.ListMarksRead)
+	}
+	if c.Timezone != "Asia/Tokyo" || c.ListMarksRead {
+		t.Errorf("expected work's settings unchanged, got %q, %v", c.Timezone, c.ListMarksRead)
+	}
+}
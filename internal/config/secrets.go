@@ -0,0 +1,146 @@
+// ABOUTME: Secrets provider selection and the names digest stores secrets under
+// ABOUTME: Moves feed passwords out of storage, leaving a "secret:" reference in their place
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/secrets"
+	"github.com/harper/digest/internal/storage"
+)
+
+// TTSAPIKeySecret names the secret holding the text-to-speech API key, used
+// when its environment variable is unset.
+const TTSAPIKeySecret = "tts/api-key"
+
+// BookmarksTokenSecret names the secret holding the bookmark service's API
+// token.
+const BookmarksTokenSecret = "bookmarks/token"
+
+// SlackSigningSecret names the secret holding the Slack app's signing secret
+// that 'digest serve' checks slash commands against.
+const SlackSigningSecret = "slack/signing-secret"
+
+// DeliverTokenSecret names the secret holding a delivery target's bot or
+// access token.
+func DeliverTokenSecret(target string) string {
+	return "deliver/" + target
+}
+
+// TeamTokenSecret names the secret holding a team user's API token.
+func TeamTokenSecret(user string) string {
+	return "team/" + user
+}
+
+// Secrets used by alert delivery.
+const (
+	NtfyTokenSecret    = "ntfy/token"
+	SMTPPasswordSecret = "smtp/password"
+)
+
+// Secrets opens the configured secrets provider.
+func (c *Config) Secrets() (secrets.Provider, error) {
+	return secrets.Open(c.SecretsBackend, SecretsPath())
+}
+
+// SecretsPath returns the file secrets are kept in when no OS keychain is
+// used.
+func SecretsPath() string {
+	return filepath.Join(filepath.Dir(GetConfigPath()), "secrets.json")
+}
+
+// FeedPasswordSecret names the secret holding a feed's basic auth password.
+func FeedPasswordSecret(feedID string) string {
+	return "feed/" + feedID
+}
+
+// FeedSecrets returns the provider holding feed's password, or nil when
+// the feed has no password stored as a secret, so syncs only open secrets
+// for the feeds that need them.
+func (c *Config) FeedSecrets(feed *models.Feed) (secrets.Provider, error) {
+	if feed.AuthPassword == nil {
+		return nil, nil
+	}
+	if _, ok := secrets.RefName(*feed.AuthPassword); !ok {
+		return nil, nil
+	}
+	provider, err := c.Secrets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets for %s: %w", feed.URL, err)
+	}
+	return provider, nil
+}
+
+// SetFeedPassword stores password in p and points the feed at it. A
+// read-only provider (the env backend) leaves the password on the feed, as
+// there is nowhere else to put it. The caller saves the feed.
+func SetFeedPassword(p secrets.Provider, feed *models.Feed, password string) error {
+	name := FeedPasswordSecret(feed.ID)
+	err := p.Set(name, password)
+	if errors.Is(err, secrets.ErrReadOnly) {
+		feed.AuthPassword = &password
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("store feed password: %w", err)
+	}
+	ref := secrets.Ref(name)
+	feed.AuthPassword = &ref
+	return nil
+}
+
+// ClearFeedPassword removes the feed's password and any secret holding it.
+// The caller saves the feed.
+func ClearFeedPassword(p secrets.Provider, feed *models.Feed) error {
+	if feed.AuthPassword != nil {
+		if name, ok := secrets.RefName(*feed.AuthPassword); ok && p != nil {
+			if err := p.Delete(name); err != nil && !errors.Is(err, secrets.ErrNotFound) {
+				return fmt.Errorf("delete feed password: %w", err)
+			}
+		}
+	}
+	feed.AuthPassword = nil
+	return nil
+}
+
+// MigrateFeedPasswords moves plaintext feed passwords in store into the
+// secrets store, leaving a reference in their place, and returns how many
+// moved. The secrets provider is only opened when a feed needs it; the env
+// backend can't store secrets, so under it passwords stay where they are.
+func (c *Config) MigrateFeedPasswords(ctx context.Context, store storage.Store) (int, error) {
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	var provider secrets.Provider
+	moved := 0
+	for _, feed := range feeds {
+		if feed.AuthPassword == nil {
+			continue
+		}
+		if _, ok := secrets.RefName(*feed.AuthPassword); ok {
+			continue
+		}
+		if provider == nil {
+			if provider, err = c.Secrets(); err != nil {
+				return moved, err
+			}
+			if _, ok := provider.(secrets.Env); ok {
+				return moved, secrets.ErrReadOnly
+			}
+		}
+		if err := SetFeedPassword(provider, feed, *feed.AuthPassword); err != nil {
+			return moved, fmt.Errorf("%s: %w", feed.URL, err)
+		}
+		if err := store.UpdateFeed(ctx, feed); err != nil {
+			return moved, fmt.Errorf("failed to update %s: %w", feed.URL, err)
+		}
+		moved++
+	}
+	return moved, nil
+}
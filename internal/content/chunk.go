@@ -0,0 +1,98 @@
+// ABOUTME: Helpers for returning part of a long Markdown article
+// ABOUTME: Selects a heading's section, the first N paragraphs, or a character window
+
+package content
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Section returns the part of a Markdown document under the first heading
+// whose text contains name (case-insensitive), up to the next heading of the
+// same or higher level. It reports false if no heading matches.
+func Section(markdown, name string) (string, bool) {
+	lines := strings.Split(markdown, "\n")
+	want := strings.ToLower(strings.TrimSpace(name))
+
+	start, level := -1, 0
+	for i, line := range lines {
+		l, text := headingLevel(line)
+		if l == 0 {
+			continue
+		}
+		if start < 0 {
+			if strings.Contains(strings.ToLower(text), want) {
+				start, level = i, l
+			}
+			continue
+		}
+		if l <= level {
+			return strings.TrimSpace(strings.Join(lines[start:i], "\n")), true
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n")), true
+}
+
+// headingLevel returns the ATX heading level of a line and its text, or 0 if
+// the line isn't a heading.
+func headingLevel(line string) (int, string) {
+	trimmed := strings.TrimLeft(line, " ")
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, ""
+	}
+	rest := trimmed[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#"))
+}
+
+// Paragraphs returns the first n blank-line-separated blocks of text and
+// reports whether anything was left out.
+func Paragraphs(markdown string, n int) (string, bool) {
+	var blocks []string
+	for _, block := range strings.Split(markdown, "\n\n") {
+		if strings.TrimSpace(block) != "" {
+			blocks = append(blocks, strings.TrimSpace(block))
+		}
+	}
+	if n <= 0 || len(blocks) <= n {
+		return strings.Join(blocks, "\n\n"), false
+	}
+	return strings.Join(blocks[:n], "\n\n"), true
+}
+
+// Window returns up to maxLength characters of text starting at offset,
+// counted in runes. When the window ends mid-text it is pulled back to the
+// last whitespace so words aren't split, unless that would discard more than
+// a fifth of it. It returns the chunk and the offset just past it, which
+// equals the text length once everything has been returned. A maxLength of
+// zero or less means no limit.
+func Window(text string, offset, maxLength int) (string, int) {
+	runes := []rune(text)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(runes) {
+		return "", len(runes)
+	}
+	end := len(runes)
+	if maxLength > 0 && offset+maxLength < end {
+		end = offset + maxLength
+		for cut := end; cut > offset+maxLength*4/5; cut-- {
+			if unicode.IsSpace(runes[cut-1]) {
+				end = cut
+				break
+			}
+		}
+	}
+	return string(runes[offset:end]), end
+}
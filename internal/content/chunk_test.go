@@ -0,0 +1,85 @@
+// ABOUTME: Tests for partial article helpers
+// ABOUTME: Covers heading sections, leading paragraphs, and character windows
+
+package content
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const chunkDoc = `Intro paragraph.
+
+## Background
+
+Some history.
+
+### Details
+
+Fine print.
+
+## Results
+
+It worked.`
+
+func TestSection(t *testing.T) {
+	got, ok := Section(chunkDoc, "background")
+	if !ok {
+		t.Fatal("expected Background section to be found")
+	}
+	if !strings.Contains(got, "Fine print.") {
+		t.Errorf("expected subsections to be included, got %q", got)
+	}
+	if strings.Contains(got, "It worked.") {
+		t.Errorf("expected section to stop at the next sibling heading, got %q", got)
+	}
+
+	got, ok = Section(chunkDoc, "Results")
+	if !ok || !strings.HasSuffix(got, "It worked.") {
+		t.Errorf("expected last section to run to the end, got %q (%v)", got, ok)
+	}
+
+	if _, ok := Section(chunkDoc, "Missing"); ok {
+		t.Error("expected no match for an unknown heading")
+	}
+	if _, ok := Section("#hashtag\n\ntext", "hashtag"); ok {
+		t.Error("expected a hashtag not to count as a heading")
+	}
+}
+
+func TestParagraphs(t *testing.T) {
+	got, truncated := Paragraphs(chunkDoc, 2)
+	if got != "Intro paragraph.\n\n## Background" || !truncated {
+		t.Errorf("unexpected first paragraphs %q (truncated=%v)", got, truncated)
+	}
+
+	_, truncated = Paragraphs("one\n\ntwo", 5)
+	if truncated {
+		t.Error("expected no truncation when asking for more paragraphs than exist")
+	}
+}
+
+func TestWindow(t *testing.T) {
+	text := "alpha beta gamma delta"
+
+	chunk, next := Window(text, 0, 13)
+	if chunk != "alpha beta " || next != 11 {
+		t.Errorf("expected window to end at a word boundary, got %q next=%d", chunk, next)
+	}
+
+	chunk, next = Window(text, next, 0)
+	if chunk != "gamma delta" || next != utf8.RuneCountInString(text) {
+		t.Errorf("expected the rest of the text, got %q next=%d", chunk, next)
+	}
+
+	chunk, next = Window(text, 100, 10)
+	if chunk != "" || next != len(text) {
+		t.Errorf("expected empty chunk past the end, got %q next=%d", chunk, next)
+	}
+
+	chunk, _ = Window("héllo wörld", 0, 4)
+	if chunk != "héll" {
+		t.Errorf("expected rune-based window, got %q", chunk)
+	}
+}
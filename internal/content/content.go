@@ -6,8 +6,6 @@ package content
 import (
 	"regexp"
 	"strings"
-
-	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
 )
 
 // htmlTagPattern matches common HTML tags
@@ -24,25 +22,8 @@ func IsHTML(content string) bool {
 	return htmlTagPattern.MatchString(content)
 }
 
-// ToMarkdown converts HTML content to Markdown
+// ToMarkdown converts HTML content to Markdown with DefaultOptions.
 // If the content doesn't appear to be HTML, returns it unchanged
 func ToMarkdown(content string) string {
-	if content == "" {
-		return content
-	}
-
-	if !IsHTML(content) {
-		return content
-	}
-
-	markdown, err := htmltomarkdown.ConvertString(content)
-	if err != nil {
-		// If conversion fails, return original content
-		return content
-	}
-
-	// Clean up excessive whitespace
-	markdown = strings.TrimSpace(markdown)
-
-	return markdown
+	return ToMarkdownWith(content, DefaultOptions())
 }
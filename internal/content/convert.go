@@ -0,0 +1,426 @@
+// ABOUTME: Configurable HTML to Markdown conversion for feed entries
+// ABOUTME: Handles tables, code block languages, footnotes, and an optional reader view cleanup
+
+package content
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/table"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Footnote handling modes.
+const (
+	// FootnotesLinks leaves footnote references as ordinary links.
+	FootnotesLinks = "links"
+	// FootnotesMarkdown rewrites footnotes as Markdown [^n] references and definitions.
+	FootnotesMarkdown = "markdown"
+	// FootnotesDrop removes footnote references and the footnote list.
+	FootnotesDrop = "drop"
+)
+
+// Options configures HTML to Markdown conversion.
+type Options struct {
+	// Tables renders <table> as Markdown tables instead of flattening them.
+	Tables bool
+	// CodeLanguages detects code block languages from common syntax
+	// highlighter markup and adds them to the fence info string.
+	CodeLanguages bool
+	// Footnotes is one of FootnotesLinks, FootnotesMarkdown, or FootnotesDrop.
+	Footnotes string
+	// ReaderView keeps only the main article and drops navigation, ads,
+	// share buttons, and similar page boilerplate before converting.
+	ReaderView bool
+}
+
+// DefaultOptions returns the conversion options used by ToMarkdown.
+func DefaultOptions() Options {
+	return Options{
+		Tables:        true,
+		CodeLanguages: true,
+		Footnotes:     FootnotesLinks,
+	}
+}
+
+// ValidateFootnotes reports whether mode is a known footnote handling mode.
+func ValidateFootnotes(mode string) error {
+	switch mode {
+	case FootnotesLinks, FootnotesMarkdown, FootnotesDrop, "":
+		return nil
+	default:
+		return fmt.Errorf("unknown footnote mode: %s (use %s, %s, or %s)", mode, FootnotesLinks, FootnotesMarkdown, FootnotesDrop)
+	}
+}
+
+// ToMarkdownWith converts HTML content to Markdown using opts. Content that
+// doesn't look like HTML is returned unchanged, as is content that fails to
+// convert.
+func ToMarkdownWith(content string, opts Options) string {
+	if content == "" || !IsHTML(content) {
+		return content
+	}
+
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+
+	if opts.ReaderView {
+		doc = readerView(doc)
+	}
+	if opts.CodeLanguages {
+		tagCodeLanguages(doc)
+	}
+	var notes []string
+	switch opts.Footnotes {
+	case FootnotesMarkdown:
+		notes = rewriteFootnotes(doc, opts)
+	case FootnotesDrop:
+		rewriteFootnotes(doc, opts)
+	}
+
+	plugins := []converter.Plugin{base.NewBasePlugin(), commonmark.NewCommonmarkPlugin()}
+	if opts.Tables {
+		plugins = append(plugins, table.NewTablePlugin())
+	}
+	conv := converter.NewConverter(converter.WithPlugins(plugins...))
+
+	out, err := conv.ConvertNode(doc)
+	if err != nil {
+		return content
+	}
+	markdown := strings.TrimSpace(string(out))
+
+	if opts.Footnotes == FootnotesMarkdown {
+		markdown = footnoteRefPattern.ReplaceAllString(markdown, "[^$1]")
+		for i, note := range notes {
+			markdown += fmt.Sprintf("\n\n[^%d]: %s", i+1, note)
+		}
+	}
+	return markdown
+}
+
+// Code languages
+
+// languageClassPatterns pull a language name out of the class attribute
+// conventions used by common syntax highlighters.
+var languageClassPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?:^|\s)(?:language|lang)-([\w+#.-]+)`),     // Prism, highlight.js, Hugo, CommonMark
+	regexp.MustCompile(`(?:^|\s)brush:\s*([\w+#.-]+)`),              // SyntaxHighlighter
+	regexp.MustCompile(`(?:^|\s)highlight-(?:source-)?([\w+#.-]+)`), // GitHub, Rouge
+	regexp.MustCompile(`(?:^|\s)sourceCode\s+([\w+#.-]+)`),          // Pandoc
+}
+
+// tagCodeLanguages finds the language of each <pre> block and records it as
+// a language-* class, which the converter turns into the fence info string.
+func tagCodeLanguages(doc *html.Node) {
+	walkElements(doc, func(n *html.Node) bool {
+		if n.DataAtom != atom.Pre {
+			return true
+		}
+		if lang := codeLanguage(n); lang != "" {
+			setAttr(n, "class", "language-"+lang)
+			if code := firstChildElement(n, atom.Code); code != nil {
+				setAttr(code, "class", "language-"+lang)
+			}
+		}
+		return false
+	})
+}
+
+// codeLanguage looks for a language on a <pre>, its <code> child, and the
+// wrapper elements highlighters put around them.
+func codeLanguage(pre *html.Node) string {
+	candidates := []*html.Node{firstChildElement(pre, atom.Code), pre}
+	for p := pre.Parent; p != nil && len(candidates) < 5; p = p.Parent {
+		candidates = append(candidates, p)
+	}
+	for _, n := range candidates {
+		if n == nil || n.Type != html.ElementNode {
+			continue
+		}
+		for _, key := range []string{"data-lang", "data-language"} {
+			if v := strings.TrimSpace(getAttr(n, key)); v != "" {
+				return strings.ToLower(v)
+			}
+		}
+		class := getAttr(n, "class")
+		for _, re := range languageClassPatterns {
+			if m := re.FindStringSubmatch(class); m != nil && !isHighlighterNoise(m[1]) {
+				return strings.ToLower(m[1])
+			}
+		}
+	}
+	return ""
+}
+
+// isHighlighterNoise filters class suffixes that aren't languages, such as
+// Rouge's "highlight-plaintext" or a bare "highlight-table".
+func isHighlighterNoise(lang string) bool {
+	switch strings.ToLower(lang) {
+	case "plaintext", "text", "none", "nohighlight", "table", "source":
+		return true
+	}
+	return false
+}
+
+// Footnotes
+
+// footnoteRefPattern matches the placeholders left where footnote references
+// were. Letters and digits only, so the converter won't escape them.
+var footnoteRefPattern = regexp.MustCompile(`DIGESTFNREF(\d+)END`)
+
+// rewriteFootnotes finds footnote lists and the links pointing into them.
+// References are replaced with numbered placeholders (or removed when
+// opts.Footnotes is FootnotesDrop) and the lists are removed. It returns the
+// footnote texts converted to Markdown, in order.
+func rewriteFootnotes(doc *html.Node, opts Options) []string {
+	var containers []*html.Node
+	walkElements(doc, func(n *html.Node) bool {
+		if isFootnoteContainer(n) {
+			containers = append(containers, n)
+			return false
+		}
+		return true
+	})
+	if len(containers) == 0 {
+		return nil
+	}
+
+	// Number footnote definitions in document order
+	numbers := map[string]int{}
+	var defs []*html.Node
+	for _, c := range containers {
+		walkElements(c, func(n *html.Node) bool {
+			if n.DataAtom == atom.Li {
+				if id := getAttr(n, "id"); id != "" {
+					defs = append(defs, n)
+					numbers[id] = len(defs)
+				}
+				return false
+			}
+			return true
+		})
+	}
+
+	// Replace references outside the containers
+	var refs []*html.Node
+	walkElements(doc, func(n *html.Node) bool {
+		if isFootnoteContainer(n) {
+			return false
+		}
+		if n.DataAtom == atom.A {
+			if href := getAttr(n, "href"); strings.HasPrefix(href, "#") && numbers[href[1:]] > 0 {
+				refs = append(refs, n)
+			}
+			return false
+		}
+		return true
+	})
+	for _, a := range refs {
+		target := a
+		if p := a.Parent; p != nil && p.DataAtom == atom.Sup && onlyElementChild(p) == a {
+			target = p
+		}
+		if opts.Footnotes == FootnotesMarkdown {
+			n := numbers[strings.TrimPrefix(getAttr(a, "href"), "#")]
+			placeholder := &html.Node{Type: html.TextNode, Data: "DIGESTFNREF" + strconv.Itoa(n) + "END"}
+			target.Parent.InsertBefore(placeholder, target)
+		}
+		target.Parent.RemoveChild(target)
+	}
+
+	var notes []string
+	if opts.Footnotes == FootnotesMarkdown {
+		noteOpts := opts
+		noteOpts.Footnotes = FootnotesLinks
+		noteOpts.ReaderView = false
+		for _, li := range defs {
+			removeBacklinks(li)
+			var b strings.Builder
+			for c := li.FirstChild; c != nil; c = c.NextSibling {
+				_ = html.Render(&b, c)
+			}
+			note := ToMarkdownWith("<div>"+b.String()+"</div>", noteOpts)
+			notes = append(notes, strings.Join(strings.Fields(note), " "))
+		}
+	}
+
+	for _, c := range containers {
+		if c.Parent != nil {
+			c.Parent.RemoveChild(c)
+		}
+	}
+	return notes
+}
+
+// isFootnoteContainer reports whether n wraps a list of footnotes, by the
+// class names and ARIA roles that Markdown renderers and CMSes emit.
+func isFootnoteContainer(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if role := getAttr(n, "role"); role == "doc-endnotes" || role == "doc-footnotes" {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Section, atom.Div, atom.Ol, atom.Aside:
+		class := strings.ToLower(getAttr(n, "class"))
+		return strings.Contains(class, "footnotes") || strings.Contains(class, "endnotes")
+	}
+	return false
+}
+
+// removeBacklinks drops the "return to text" links inside a footnote.
+func removeBacklinks(li *html.Node) {
+	var backlinks []*html.Node
+	walkElements(li, func(n *html.Node) bool {
+		if n.DataAtom == atom.A && strings.HasPrefix(getAttr(n, "href"), "#") {
+			backlinks = append(backlinks, n)
+			return false
+		}
+		return true
+	})
+	for _, a := range backlinks {
+		a.Parent.RemoveChild(a)
+	}
+}
+
+// Reader view
+
+// boilerplateTags are dropped entirely in reader view.
+var boilerplateTags = map[atom.Atom]bool{
+	atom.Nav: true, atom.Aside: true, atom.Form: true, atom.Script: true,
+	atom.Style: true, atom.Noscript: true, atom.Iframe: true, atom.Button: true,
+}
+
+// boilerplatePattern matches class and id tokens that mark ads, share bars,
+// newsletter prompts, and other non-article furniture.
+var boilerplatePattern = regexp.MustCompile(`(?i)^(ads?|advert\w*|sponsor\w*|promo\w*|share|sharing|social|newsletter|subscribe|related|comments?|sidebar|cookies?|banner|popup|modal|breadcrumbs?|nav|navbar|menu)$`)
+
+// readerView narrows doc to its main content and strips boilerplate. The
+// first <main>, else the first <article>, else <body> becomes the root.
+func readerView(doc *html.Node) *html.Node {
+	root := findElement(doc, atom.Main)
+	if root == nil {
+		root = findElement(doc, atom.Article)
+	}
+	if root == nil {
+		root = doc
+	}
+
+	var drop []*html.Node
+	walkElements(root, func(n *html.Node) bool {
+		if n == root {
+			return true
+		}
+		if boilerplateTags[n.DataAtom] || hasBoilerplateName(n) {
+			drop = append(drop, n)
+			return false
+		}
+		// Page-level header and footer are chrome; inside an article they may hold the byline
+		if (n.DataAtom == atom.Header || n.DataAtom == atom.Footer) && root == doc {
+			drop = append(drop, n)
+			return false
+		}
+		return true
+	})
+	for _, n := range drop {
+		n.Parent.RemoveChild(n)
+	}
+	return root
+}
+
+// hasBoilerplateName reports whether any class or id token of n looks like
+// page furniture rather than content.
+func hasBoilerplateName(n *html.Node) bool {
+	names := getAttr(n, "class") + " " + getAttr(n, "id")
+	for _, token := range strings.FieldsFunc(names, func(r rune) bool { return r == ' ' || r == '-' || r == '_' }) {
+		if boilerplatePattern.MatchString(token) {
+			return true
+		}
+	}
+	return false
+}
+
+// DOM helpers
+
+// walkElements calls fn on every element under n in document order. When fn
+// returns false the element's children are skipped.
+func walkElements(n *html.Node, fn func(*html.Node) bool) {
+	if n.Type == html.ElementNode && !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		walkElements(c, fn)
+		c = next
+	}
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walkElements(n, func(el *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if el.DataAtom == a {
+			found = el
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func firstChildElement(n *html.Node, a atom.Atom) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			return c
+		}
+	}
+	return nil
+}
+
+// onlyElementChild returns n's single element child, ignoring whitespace,
+// or nil if it has any other content.
+func onlyElementChild(n *html.Node) *html.Node {
+	var only *html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode && strings.TrimSpace(c.Data) == "":
+		case c.Type == html.ElementNode && only == nil:
+			only = c
+		default:
+			return nil
+		}
+	}
+	return only
+}
+
+func getAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
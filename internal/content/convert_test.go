@@ -0,0 +1,104 @@
+// ABOUTME: Tests for configurable HTML to Markdown conversion
+// ABOUTME: Covers tables, code languages, footnote modes, and reader view
+
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToMarkdownWithTables(t *testing.T) {
+	input := "<table><tr><th>Name</th><th>Score</th></tr><tr><td>Ada</td><td>10</td></tr></table>"
+
+	got := ToMarkdownWith(input, Options{Tables: true})
+	if !strings.Contains(got, "| Name | Score |") || !strings.Contains(got, "| Ada  | 10    |") {
+		t.Errorf("expected a Markdown table, got:\n%s", got)
+	}
+
+	got = ToMarkdownWith(input, Options{})
+	if strings.Contains(got, "|") {
+		t.Errorf("expected tables to be flattened when disabled, got:\n%s", got)
+	}
+}
+
+func TestToMarkdownWithCodeLanguages(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"prism class", `<pre><code class="language-go">x := 1</code></pre>`, "```go\n"},
+		{"syntaxhighlighter brush", `<pre class="brush: python">print(1)</pre>`, "```python\n"},
+		{"github wrapper", `<div class="highlight highlight-source-rust"><pre>fn main() {}</pre></div>`, "```rust\n"},
+		{"data attribute", `<pre data-lang="Ruby"><code>puts 1</code></pre>`, "```ruby\n"},
+		{"pandoc", `<pre class="sourceCode haskell"><code>main = pure ()</code></pre>`, "```haskell\n"},
+		{"rouge plaintext", `<div class="highlight-plaintext"><pre>plain</pre></div>`, "```\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToMarkdownWith(tt.input, Options{CodeLanguages: true})
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("expected fence %q, got:\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+const footnoteHTML = `<p>Claim one<sup id="fnref:1"><a href="#fn:1">1</a></sup> and two<sup><a href="#fn:2">2</a></sup>.</p>
+<div class="footnotes"><ol>
+<li id="fn:1"><p>First <em>source</em>. <a href="#fnref:1" class="reversefootnote">↩</a></p></li>
+<li id="fn:2"><p>Second source.</p></li>
+</ol></div>`
+
+func TestToMarkdownWithFootnotes(t *testing.T) {
+	got := ToMarkdownWith(footnoteHTML, Options{Footnotes: FootnotesMarkdown})
+	want := "Claim one[^1] and two[^2].\n\n[^1]: First *source*.\n\n[^2]: Second source."
+	if got != want {
+		t.Errorf("markdown footnotes:\n%q\nwant\n%q", got, want)
+	}
+
+	got = ToMarkdownWith(footnoteHTML, Options{Footnotes: FootnotesDrop})
+	if got != "Claim one and two." {
+		t.Errorf("expected footnotes to be dropped, got %q", got)
+	}
+
+	got = ToMarkdownWith(footnoteHTML, Options{Footnotes: FootnotesLinks})
+	if !strings.Contains(got, "(#fn:1)") || !strings.Contains(got, "Second source.") {
+		t.Errorf("expected footnotes left as links, got:\n%s", got)
+	}
+
+	if err := ValidateFootnotes("endnotes"); err == nil {
+		t.Error("expected an unknown footnote mode to be rejected")
+	}
+}
+
+func TestToMarkdownWithReaderView(t *testing.T) {
+	input := `<html><body>
+<header><a href="/">Site</a></header>
+<nav><a href="/about">About</a></nav>
+<article>
+<h1>Real Title</h1>
+<p>Real content.</p>
+<div class="share-buttons"><a href="#">Tweet</a></div>
+<div id="newsletter-signup"><p>Subscribe now!</p></div>
+<aside>Related posts</aside>
+</article>
+<footer>Copyright</footer>
+</body></html>`
+
+	got := ToMarkdownWith(input, Options{ReaderView: true})
+	if !strings.Contains(got, "Real Title") || !strings.Contains(got, "Real content.") {
+		t.Errorf("expected article content to survive, got:\n%s", got)
+	}
+	for _, junk := range []string{"About", "Tweet", "Subscribe", "Related", "Copyright", "Site"} {
+		if strings.Contains(got, junk) {
+			t.Errorf("expected %q to be removed in reader view, got:\n%s", junk, got)
+		}
+	}
+
+	got = ToMarkdownWith(input, Options{})
+	if !strings.Contains(got, "Copyright") {
+		t.Errorf("expected boilerplate to be kept without reader view, got:\n%s", got)
+	}
+}
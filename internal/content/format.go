@@ -0,0 +1,144 @@
+// ABOUTME: Renders entry content as Markdown, plain text, HTML, or the stored original
+// ABOUTME: Lets callers skip the Markdown conversion when they want another representation
+
+package content
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// Output formats accepted by Render.
+const (
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+	FormatHTML     = "html"
+	FormatRaw      = "raw"
+)
+
+// Formats lists the accepted formats, for flag help and completion.
+var Formats = []string{FormatMarkdown, FormatText, FormatHTML, FormatRaw}
+
+// Render converts stored entry content to the requested format, using opts
+// for Markdown conversion. An empty format means Markdown.
+func Render(content, format string, opts Options) (string, error) {
+	switch format {
+	case FormatMarkdown, "":
+		if err := ValidateFootnotes(opts.Footnotes); err != nil {
+			return "", err
+		}
+		return ToMarkdownWith(content, opts), nil
+	case FormatText:
+		return ToText(content), nil
+	case FormatHTML:
+		return ToHTML(content), nil
+	case FormatRaw:
+		return content, nil
+	default:
+		return "", fmt.Errorf("unknown format: %s (use %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// ToHTML returns HTML content unchanged and wraps plain text paragraphs in
+// escaped <p> elements.
+func ToHTML(content string) string {
+	if content == "" || IsHTML(content) {
+		return content
+	}
+	var b strings.Builder
+	for _, para := range strings.Split(content, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// whitespace matches runs of whitespace, which HTML renders as one space.
+var whitespace = regexp.MustCompile(`\s+`)
+
+// blockElements start a new paragraph in plain text output.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "blockquote": true,
+	"pre": true, "ul": true, "ol": true, "table": true, "tr": true, "figure": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true,
+}
+
+// ToText strips HTML markup, keeping paragraph breaks and list items, and
+// decodes entities. Content that isn't HTML is returned unchanged.
+func ToText(content string) string {
+	if content == "" || !IsHTML(content) {
+		return content
+	}
+	doc, err := xhtml.Parse(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+
+	var b strings.Builder
+	var walk func(n *xhtml.Node, pre bool)
+	walk = func(n *xhtml.Node, pre bool) {
+		switch n.Type {
+		case xhtml.TextNode:
+			if pre {
+				b.WriteString(n.Data)
+			} else {
+				b.WriteString(whitespace.ReplaceAllString(n.Data, " "))
+			}
+			return
+		case xhtml.ElementNode:
+			switch n.Data {
+			case "script", "style", "head":
+				return
+			case "br":
+				b.WriteString("\n")
+				return
+			case "li":
+				b.WriteString("\n- ")
+			case "td", "th":
+				b.WriteString(" ")
+			}
+			if blockElements[n.Data] {
+				b.WriteString("\n\n")
+			}
+			pre = pre || n.Data == "pre"
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, pre)
+		}
+		if n.Type == xhtml.ElementNode && blockElements[n.Data] {
+			b.WriteString("\n\n")
+		}
+	}
+	walk(doc, false)
+
+	return tidyText(b.String())
+}
+
+// tidyText trims trailing spaces and collapses runs of blank lines.
+func tidyText(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		out = append(out, strings.TrimLeft(line, " "))
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Tests for rendering entry content in different formats
+// ABOUTME: Covers plain text extraction, HTML wrapping, and format selection
+
+package content
+
+import "testing"
+
+func TestToText(t *testing.T) {
+	input := `<h2>Title</h2><p>First &amp; <b>bold</b> paragraph.</p><ul><li>one</li><li>two</li></ul><script>alert(1)</script><p>Line<br>break</p>`
+	want := "Title\n\nFirst & bold paragraph.\n\n- one\n- two\n\nLine\nbreak"
+	if got := ToText(input); got != want {
+		t.Errorf("ToText() =\n%q\nwant\n%q", got, want)
+	}
+
+	if got := ToText("already plain"); got != "already plain" {
+		t.Errorf("expected plain text to pass through, got %q", got)
+	}
+}
+
+func TestToHTML(t *testing.T) {
+	if got := ToHTML("<p>kept</p>"); got != "<p>kept</p>" {
+		t.Errorf("expected HTML to pass through, got %q", got)
+	}
+	want := "<p>a &lt; b</p>\n<p>second<br>\nline</p>"
+	if got := ToHTML("a < b\n\nsecond\nline"); got != want {
+		t.Errorf("ToHTML() = %q, want %q", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	input := "<p>Hello <em>world</em></p>"
+	tests := map[string]string{
+		"":             "Hello *world*",
+		FormatMarkdown: "Hello *world*",
+		FormatText:     "Hello world",
+		FormatHTML:     input,
+		FormatRaw:      input,
+	}
+	for format, want := range tests {
+		got, err := Render(input, format, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Render(%q): %v", format, err)
+		}
+		if got != want {
+			t.Errorf("Render(%q) = %q, want %q", format, got, want)
+		}
+	}
+
+	if _, err := Render(input, "pdf", DefaultOptions()); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,449 @@
+// ABOUTME: Delivery of new entries and daily digests to Telegram chats and Matrix rooms
+// ABOUTME: Routes entries to targets by folder, batches them into messages, and remembers what was sent
+
+package deliver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harperreed/mdstore"
+
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+// Target types.
+const (
+	TypeTelegram = "telegram"
+	TypeMatrix   = "matrix"
+)
+
+// Delivery modes.
+const (
+	// ModeEntries posts the entries each sync brings in.
+	ModeEntries = "entries"
+	// ModeDaily posts one digest a day of the entries stored since the last.
+	ModeDaily = "daily"
+)
+
+// DefaultBatchSize is how many entries one message lists when BatchSize is
+// unset.
+const DefaultBatchSize = 10
+
+// DefaultDailyHour is the hour of the day daily digests go out from when
+// Hour is unset.
+const DefaultDailyHour = 8
+
+// StateFileName records what each target was sent, inside a profile data
+// directory.
+const StateFileName = "deliver.json"
+
+// sendTimeout bounds each message so a dead endpoint can't stall a sync.
+const sendTimeout = 15 * time.Second
+
+// Config lists where entries are delivered.
+type Config struct {
+	Targets []Target `json:"targets"`
+}
+
+// Target is one chat or room entries are posted to.
+type Target struct {
+	// Name identifies the target; its token is the "deliver/<name>" secret.
+	Name string `json:"name"`
+
+	// Type is "telegram" or "matrix".
+	Type string `json:"type"`
+
+	// ChatID is the Telegram chat, such as "-1001234567890" or "@channel".
+	ChatID string `json:"chat_id,omitempty"`
+
+	// Homeserver is the Matrix server, such as "https://matrix.org", and
+	// Room the room ID, such as "!abc123:matrix.org".
+	Homeserver string `json:"homeserver,omitempty"`
+	Room       string `json:"room,omitempty"`
+
+	// Folders limits the target to entries of feeds in these OPML
+	// folders; empty takes every feed.
+	Folders []string `json:"folders,omitempty"`
+
+	// Mode is "entries" (default) or "daily". Entries mode pushes only
+	// the entries of high-priority feeds; daily digests take every feed.
+	Mode string `json:"mode,omitempty"`
+
+	// Hour is the hour of the day, in the configured timezone, from which
+	// a daily digest is sent. Default 8.
+	Hour *int `json:"hour,omitempty"`
+
+	// BatchSize is how many entries one message lists. Default 10.
+	BatchSize int `json:"batch_size,omitempty"`
+
+	// Token is the bot or access token. It is read from the secrets store,
+	// never from config.json.
+	Token string `json:"-"`
+}
+
+// Validate checks that the target has what its type needs.
+func (t Target) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("deliver: a target has no name")
+	}
+	switch t.Type {
+	case TypeTelegram:
+		if t.ChatID == "" {
+			return fmt.Errorf("deliver: target %q needs a chat_id", t.Name)
+		}
+	case TypeMatrix:
+		if t.Homeserver == "" || t.Room == "" {
+			return fmt.Errorf("deliver: target %q needs a homeserver and room", t.Name)
+		}
+	default:
+		return fmt.Errorf("deliver: target %q has unknown type %q (use telegram or matrix)", t.Name, t.Type)
+	}
+	switch t.Mode {
+	case "", ModeEntries, ModeDaily:
+	default:
+		return fmt.Errorf("deliver: target %q has unknown mode %q (use entries or daily)", t.Name, t.Mode)
+	}
+	if t.Token == "" {
+		return fmt.Errorf("deliver: no token for target %q; store one with 'digest secrets set deliver/%s'", t.Name, t.Name)
+	}
+	return nil
+}
+
+func (t Target) mode() string {
+	if t.Mode == "" {
+		return ModeEntries
+	}
+	return t.Mode
+}
+
+func (t Target) hour() int {
+	if t.Hour == nil {
+		return DefaultDailyHour
+	}
+	return *t.Hour
+}
+
+func (t Target) batchSize() int {
+	if t.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return t.BatchSize
+}
+
+// routes reports whether entries of feeds in folder go to the target.
+func (t Target) routes(folder string) bool {
+	if len(t.Folders) == 0 {
+		return true
+	}
+	for _, f := range t.Folders {
+		if f == folder {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetState is what a target was last sent.
+type TargetState struct {
+	// Cursor is when the entries last posted were gathered; entries stored
+	// after it are new to the target.
+	Cursor time.Time `json:"cursor"`
+}
+
+// State is every target's TargetState, by name.
+type State map[string]TargetState
+
+// StatePath returns where the delivery state of the profile in profileDir
+// is kept.
+func StatePath(profileDir string) string {
+	return filepath.Join(profileDir, StateFileName)
+}
+
+// LoadState reads the delivery state, or an empty one if nothing was sent yet.
+func LoadState(path string) (State, error) {
+	s := make(State)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("read delivery state: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse delivery state: %w", err)
+	}
+	return s, nil
+}
+
+// SaveState writes the delivery state.
+func SaveState(path string, s State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode delivery state: %w", err)
+	}
+	if err := mdstore.AtomicWrite(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write delivery state: %w", err)
+	}
+	return nil
+}
+
+// Item is one entry in a message.
+type Item struct {
+	Title  string
+	Link   string
+	Feed   string
+	Folder string
+}
+
+// Section is a message's items from one folder.
+type Section struct {
+	Folder string
+	Items  []Item
+}
+
+// Message is one post to a target.
+type Message struct {
+	Title    string
+	Sections []Section
+}
+
+// Count returns how many entries the message lists.
+func (m Message) Count() int {
+	n := 0
+	for _, s := range m.Sections {
+		n += len(s.Items)
+	}
+	return n
+}
+
+// Source is what Due picks entries from.
+type Source struct {
+	Entries []*models.Entry
+	Feeds   []*models.Feed
+	// Folders maps feed URLs to their OPML folder.
+	Folders map[string]string
+}
+
+// Collect gathers the entries, feeds, and folders Due picks from.
+func Collect(ctx context.Context, store storage.Store, doc *opml.Document) (Source, error) {
+	entries, err := store.ListEntries(ctx, nil)
+	if err != nil {
+		return Source{}, fmt.Errorf("list entries: %w", err)
+	}
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return Source{}, fmt.Errorf("list feeds: %w", err)
+	}
+	folders := make(map[string]string)
+	if doc != nil {
+		for _, f := range doc.AllFeeds() {
+			folders[f.URL] = f.Folder
+		}
+	}
+	return Source{Entries: entries, Feeds: feeds, Folders: folders}, nil
+}
+
+// Due returns the messages due to target t at now, and the state to record
+// once they're sent. A target's first check only starts its cursor, so
+// the backlog isn't posted. Daily targets are due once a day from their hour
+// in now's location; entries targets are only pushed high-priority feeds'
+// entries.
+func Due(t Target, st TargetState, src Source, now time.Time) ([]Message, TargetState) {
+	next := TargetState{Cursor: now}
+	if st.Cursor.IsZero() {
+		return nil, next
+	}
+	var title string
+	if t.mode() == ModeDaily {
+		last := st.Cursor.In(now.Location())
+		sameDay := last.Year() == now.Year() && last.YearDay() == now.YearDay()
+		if now.Hour() < t.hour() || (sameDay && last.Hour() >= t.hour()) {
+			return nil, st
+		}
+		title = "digest for " + now.Format("Monday, January 2")
+	}
+
+	feeds := make(map[string]*models.Feed, len(src.Feeds))
+	for _, f := range src.Feeds {
+		feeds[f.ID] = f
+	}
+	var items []Item
+	var published []time.Time
+	for _, e := range src.Entries {
+		if e.Read || !e.CreatedAt.After(st.Cursor) || e.CreatedAt.After(now) {
+			continue
+		}
+		feed := feeds[e.FeedID]
+		if feed == nil || (t.mode() == ModeEntries && feed.Priority != models.PriorityHigh) {
+			continue
+		}
+		folder := src.Folders[feed.URL]
+		if !t.routes(folder) {
+			continue
+		}
+		it := Item{Title: e.GetTitle(), Feed: feed.GetDisplayName(), Folder: folder}
+		if e.Link != nil {
+			it.Link = *e.Link
+		}
+		items = append(items, it)
+		at := e.CreatedAt
+		if e.PublishedAt != nil {
+			at = *e.PublishedAt
+		}
+		published = append(published, at)
+	}
+	if len(items) == 0 {
+		return nil, next
+	}
+
+	// Group by folder, newest first within one
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ia, ib := items[order[a]], items[order[b]]
+		if ia.Folder != ib.Folder {
+			return ia.Folder < ib.Folder
+		}
+		return published[order[a]].After(published[order[b]])
+	})
+	sorted := make([]Item, len(items))
+	for i, idx := range order {
+		sorted[i] = items[idx]
+	}
+	if title == "" {
+		title = fmt.Sprintf("%d new entr%s", len(sorted), plural(len(sorted)))
+	}
+	return batch(title, sorted, t.batchSize()), next
+}
+
+// batch splits sorted items into messages of at most size items, numbering
+// the titles when there's more than one.
+func batch(title string, items []Item, size int) []Message {
+	var msgs []Message
+	for start := 0; start < len(items); start += size {
+		end := min(start+size, len(items))
+		m := Message{Title: title}
+		for _, it := range items[start:end] {
+			if n := len(m.Sections); n == 0 || m.Sections[n-1].Folder != it.Folder {
+				m.Sections = append(m.Sections, Section{Folder: it.Folder})
+			}
+			last := &m.Sections[len(m.Sections)-1]
+			last.Items = append(last.Items, it)
+		}
+		msgs = append(msgs, m)
+	}
+	if len(msgs) > 1 {
+		for i := range msgs {
+			msgs[i].Title = fmt.Sprintf("%s (%d/%d)", title, i+1, len(msgs))
+		}
+	}
+	return msgs
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
+
+// Send posts m to target t.
+func Send(ctx context.Context, t Target, m Message) error {
+	client := &http.Client{Timeout: sendTimeout, Transport: fetch.Transport()}
+	switch t.Type {
+	case TypeTelegram:
+		return sendTelegram(ctx, client, t, m)
+	case TypeMatrix:
+		return sendMatrix(ctx, client, t, m)
+	}
+	return fmt.Errorf("deliver: unknown target type %q", t.Type)
+}
+
+// Result is what Run did for one target.
+type Result struct {
+	Target   string
+	Messages int
+	Entries  int
+	Err      error
+}
+
+// Run sends every target the messages due at now, records what was sent in
+// the state at statePath, and returns what happened per target. A target
+// that fails keeps its cursor, so its entries are retried next time.
+func Run(ctx context.Context, cfg Config, src Source, statePath string, now time.Time) ([]Result, error) {
+	if len(cfg.Targets) == 0 {
+		return nil, nil
+	}
+	state, err := LoadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, t := range cfg.Targets {
+		r := Result{Target: t.Name}
+		if err := t.Validate(); err != nil {
+			r.Err = err
+			results = append(results, r)
+			continue
+		}
+		msgs, next := Due(t, state[t.Name], src, now)
+		for _, m := range msgs {
+			if err := Send(ctx, t, m); err != nil {
+				r.Err = err
+				break
+			}
+			r.Messages++
+			r.Entries += m.Count()
+		}
+		if r.Err == nil {
+			state[t.Name] = next
+		}
+		results = append(results, r)
+	}
+	return results, SaveState(statePath, state)
+}
+
+// Errors joins the errors of results.
+func Errors(results []Result) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Target, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Markdown renders m as Markdown.
+func (m Message) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**\n", m.Title)
+	for _, s := range m.Sections {
+		b.WriteString("\n")
+		if s.Folder != "" {
+			fmt.Fprintf(&b, "**%s**\n", s.Folder)
+		}
+		for _, it := range s.Items {
+			if it.Link != "" {
+				fmt.Fprintf(&b, "- [%s](%s) · %s\n", it.Title, it.Link, it.Feed)
+			} else {
+				fmt.Fprintf(&b, "- %s · %s\n", it.Title, it.Feed)
+			}
+		}
+	}
+	return b.String()
+}
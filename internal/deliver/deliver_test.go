@@ -0,0 +1,197 @@
+// ABOUTME: Tests for delivering entries to Telegram and Matrix
+// ABOUTME: Covers what's due per mode, folder routing, batching, formatting, and sending
+
+package deliver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func testSource(base time.Time) Source {
+	work := models.NewFeed("https://work.example/feed")
+	workTitle := "Work Blog"
+	work.Title = &workTitle
+	fun := models.NewFeed("https://fun.example/feed")
+	work.Priority, fun.Priority = models.PriorityHigh, models.PriorityHigh
+
+	entry := func(feed *models.Feed, title string, stored time.Time) *models.Entry {
+		e := models.NewEntry(feed.ID, title, title)
+		link := "https://example.com/" + title
+		e.Link = &link
+		e.CreatedAt = stored
+		return e
+	}
+	read := entry(fun, "read", base.Add(time.Minute))
+	read.Read = true
+	return Source{
+		Entries: []*models.Entry{
+			entry(work, "old", base.Add(-time.Hour)),
+			entry(work, "w1", base.Add(time.Minute)),
+			entry(work, "w2", base.Add(2*time.Minute)),
+			entry(fun, "f1", base.Add(3*time.Minute)),
+			read,
+		},
+		Feeds:   []*models.Feed{work, fun},
+		Folders: map[string]string{work.URL: "Work", fun.URL: "Fun"},
+	}
+}
+
+func TestDueEntries(t *testing.T) {
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	src := testSource(base)
+	now := base.Add(time.Hour)
+	target := Target{Name: "t", Type: TypeTelegram}
+
+	// The first check starts the cursor without posting the backlog
+	msgs, next := Due(target, TargetState{}, src, now)
+	if len(msgs) != 0 || !next.Cursor.Equal(now) {
+		t.Fatalf("first check: %d messages, cursor %v", len(msgs), next.Cursor)
+	}
+
+	msgs, next = Due(target, TargetState{Cursor: base}, src, now)
+	if len(msgs) != 1 || msgs[0].Title != "3 new entries" || !next.Cursor.Equal(now) {
+		t.Fatalf("got %+v", msgs)
+	}
+	if got := msgs[0].Sections; len(got) != 2 || got[0].Folder != "Fun" || got[1].Folder != "Work" || got[1].Items[0].Title != "w2" {
+		t.Errorf("unexpected sections %+v", got)
+	}
+
+	routed := Target{Name: "t", Type: TypeTelegram, Folders: []string{"Work"}, BatchSize: 1}
+	msgs, _ = Due(routed, TargetState{Cursor: base}, src, now)
+	if len(msgs) != 2 || msgs[0].Title != "2 new entries (1/2)" || msgs[1].Sections[0].Items[0].Title != "w1" {
+		t.Errorf("routed and batched: %+v", msgs)
+	}
+
+	if msgs, next := Due(target, TargetState{Cursor: now}, src, now); len(msgs) != 0 || !next.Cursor.Equal(now) {
+		t.Errorf("nothing new: %+v", msgs)
+	}
+}
+
+func TestDuePriority(t *testing.T) {
+	base := time.Date(2026, 5, 1, 7, 0, 0, 0, time.UTC)
+	src := testSource(base)
+	src.Feeds[1].Priority = models.PriorityNormal
+
+	// Only high-priority feeds are pushed as entries arrive
+	msgs, _ := Due(Target{Name: "t", Type: TypeTelegram}, TargetState{Cursor: base}, src, base.Add(time.Hour))
+	if len(msgs) != 1 || msgs[0].Count() != 2 || msgs[0].Sections[0].Folder != "Work" {
+		t.Fatalf("entries mode: %+v", msgs)
+	}
+
+	// Daily digests take every feed
+	hour := 8
+	daily := Target{Name: "t", Type: TypeTelegram, Mode: ModeDaily, Hour: &hour}
+	if msgs, _ := Due(daily, TargetState{Cursor: base}, src, base.Add(time.Hour)); len(msgs) != 1 || msgs[0].Count() != 3 {
+		t.Errorf("daily mode: %+v", msgs)
+	}
+}
+
+func TestDueDaily(t *testing.T) {
+	base := time.Date(2026, 5, 1, 7, 0, 0, 0, time.UTC)
+	src := testSource(base)
+	hour := 9
+	target := Target{Name: "t", Type: TypeMatrix, Mode: ModeDaily, Hour: &hour}
+	st := TargetState{Cursor: base}
+
+	// Before the hour nothing is due and the cursor stays put
+	msgs, next := Due(target, st, src, base.Add(time.Hour))
+	if len(msgs) != 0 || !next.Cursor.Equal(base) {
+		t.Fatalf("before the hour: %d messages, cursor %v", len(msgs), next.Cursor)
+	}
+
+	sendAt := base.Add(3 * time.Hour)
+	msgs, next = Due(target, st, src, sendAt)
+	if len(msgs) != 1 || msgs[0].Title != "digest for Friday, May 1" || msgs[0].Count() != 3 {
+		t.Fatalf("at the hour: %+v", msgs)
+	}
+
+	// Sent once a day
+	if msgs, _ := Due(target, next, src, sendAt.Add(5*time.Hour)); len(msgs) != 0 {
+		t.Errorf("second send the same day: %+v", msgs)
+	}
+}
+
+func TestTelegramText(t *testing.T) {
+	m := Message{Title: "3 new entries", Sections: []Section{{
+		Folder: "Dev-Ops",
+		Items:  []Item{{Title: "v1.2 (beta)!", Link: "https://example.com/a_(b)", Feed: "Blog"}},
+	}}}
+	want := "*3 new entries*\n\n*Dev\\-Ops*\n• [v1\\.2 \\(beta\\)\\!](https://example.com/a_(b\\)) · Blog\n"
+	if got := telegramText(m); got != want {
+		t.Errorf("telegramText() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSendAndRun(t *testing.T) {
+	var telegram, matrix []map[string]any
+	var matrixAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.URL.Path == "/botsecret/sendMessage":
+			telegram = append(telegram, body)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/"):
+			matrixAuth = r.Header.Get("Authorization")
+			matrix = append(matrix, body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"description": "chat not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer srv.Close()
+	telegramAPI = srv.URL
+	t.Cleanup(func() { telegramAPI = "https://api.telegram.org" })
+
+	cfg := Config{Targets: []Target{
+		{Name: "phone", Type: TypeTelegram, ChatID: "42", Token: "secret"},
+		{Name: "team", Type: TypeMatrix, Homeserver: srv.URL, Room: "!room:example.org", Folders: []string{"Fun"}, Token: "mx"},
+		{Name: "broken", Type: TypeTelegram, ChatID: "1", Token: "wrong"},
+		{Name: "untokened", Type: TypeMatrix, Homeserver: srv.URL, Room: "!r:x"},
+	}}
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	src := testSource(base)
+	path := StatePath(t.TempDir())
+	if err := SaveState(path, State{"phone": {Cursor: base}, "team": {Cursor: base}, "broken": {Cursor: base}}); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+
+	now := base.Add(time.Hour)
+	results, err := Run(context.Background(), cfg, src, path, now)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 4 || results[0].Entries != 3 || results[1].Entries != 1 || results[2].Err == nil || results[3].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if !strings.Contains(results[2].Err.Error(), "chat not found") || strings.Contains(results[2].Err.Error(), "wrong") {
+		t.Errorf("broken target error = %v", results[2].Err)
+	}
+	if len(telegram) != 1 || telegram[0]["chat_id"] != "42" || telegram[0]["parse_mode"] != "MarkdownV2" {
+		t.Errorf("telegram got %+v", telegram)
+	}
+	if len(matrix) != 1 || matrixAuth != "Bearer mx" || !strings.Contains(matrix[0]["formatted_body"].(string), `<a href="https://example.com/f1">f1</a>`) {
+		t.Errorf("matrix got %+v with %q", matrix, matrixAuth)
+	}
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if !state["phone"].Cursor.Equal(now) || !state["broken"].Cursor.Equal(base) {
+		t.Errorf("state after run %+v; failed targets should keep their cursor", state)
+	}
+	if err := Errors(results); err == nil || !strings.Contains(err.Error(), "broken:") {
+		t.Errorf("Errors() = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+// ABOUTME: Matrix client-server API sender posting messages to a room
+// ABOUTME: Sends Markdown as the plain body and the same message as HTML for clients that render it
+
+package deliver
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func sendMatrix(ctx context.Context, client *http.Client, t Target, m Message) error {
+	body, err := json.Marshal(map[string]string{
+		"msgtype":        "m.text",
+		"body":           m.Markdown(),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": matrixHTML(m),
+	})
+	if err != nil {
+		return err
+	}
+	txn := make([]byte, 8)
+	if _, err := rand.Read(txn); err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/digest-%s",
+		strings.TrimSuffix(t.Homeserver, "/"), url.PathEscape(t.Room), hex.EncodeToString(txn))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var reply struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = json.Unmarshal(data, &reply)
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, reply.Error)
+	}
+	return nil
+}
+
+// matrixHTML renders m as the HTML subset Matrix clients display.
+func matrixHTML(m Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<strong>%s</strong>", html.EscapeString(m.Title))
+	for _, s := range m.Sections {
+		if s.Folder != "" {
+			fmt.Fprintf(&b, "<br><strong>%s</strong>", html.EscapeString(s.Folder))
+		}
+		b.WriteString("<ul>")
+		for _, it := range s.Items {
+			title := html.EscapeString(it.Title)
+			if it.Link != "" {
+				title = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(it.Link), title)
+			}
+			fmt.Fprintf(&b, "<li>%s · %s</li>", title, html.EscapeString(it.Feed))
+		}
+		b.WriteString("</ul>")
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Telegram Bot API sender posting messages in MarkdownV2
+// ABOUTME: Escapes Telegram's reserved characters so titles can't break the formatting
+
+package deliver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// telegramAPI is the Bot API address; tests point it at a local server.
+var telegramAPI = "https://api.telegram.org"
+
+// telegramReserved are the characters MarkdownV2 needs escaped in text.
+const telegramReserved = "_*[]()~`>#+-=|{}.!\\"
+
+func sendTelegram(ctx context.Context, client *http.Client, t Target, m Message) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  t.ChatID,
+		"text":                     telegramText(m),
+		"parse_mode":               "MarkdownV2",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", telegramAPI, t.Token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL carries the token; don't let it reach logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var reply struct {
+			Description string `json:"description"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = json.Unmarshal(data, &reply)
+		return fmt.Errorf("telegram returned %s: %s", resp.Status, reply.Description)
+	}
+	return nil
+}
+
+// telegramText renders m in Telegram's MarkdownV2.
+func telegramText(m Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n", telegramEscape(m.Title))
+	for _, s := range m.Sections {
+		b.WriteString("\n")
+		if s.Folder != "" {
+			fmt.Fprintf(&b, "*%s*\n", telegramEscape(s.Folder))
+		}
+		for _, it := range s.Items {
+			title := telegramEscape(it.Title)
+			if it.Link != "" {
+				link := strings.NewReplacer(`\`, `\\`, `)`, `\)`).Replace(it.Link)
+				title = fmt.Sprintf("[%s](%s)", title, link)
+			}
+			fmt.Fprintf(&b, "• %s · %s\n", title, telegramEscape(it.Feed))
+		}
+	}
+	return b.String()
+}
+
+// telegramEscape escapes MarkdownV2's reserved characters in s.
+func telegramEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(telegramReserved, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
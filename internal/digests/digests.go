@@ -0,0 +1,327 @@
+// ABOUTME: Dated digest documents generated on a schedule and kept as a browsable history
+// ABOUTME: Renders each day's new entries as Markdown and HTML, skipping digests that wouldn't change
+
+package digests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/harperreed/mdstore"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/render"
+	"github.com/harper/digest/internal/storage"
+)
+
+// DateLayout names a digest by the day it was generated.
+const DateLayout = "2006-01-02"
+
+// DefaultHour is the hour of the day digests are generated from when Hour
+// is unset.
+const DefaultHour = 7
+
+// DefaultTemplate renders the Markdown document when Template is unset.
+const DefaultTemplate = "markdown"
+
+// Document formats, which are also the files' extensions.
+const (
+	Markdown = "md"
+	HTML     = "html"
+)
+
+// indexFile lists the generated digests inside the digests directory.
+const indexFile = "index.json"
+
+// ErrNotFound means there's no digest for the date.
+var ErrNotFound = errors.New("no digest for that date")
+
+// Config schedules the daily digest.
+type Config struct {
+	// Hour is the hour of the day, in the configured timezone, from which
+	// the day's digest is generated. Default 7.
+	Hour *int `json:"hour,omitempty"`
+
+	// Template renders the Markdown document: a template in the templates
+	// directory or a built-in. Default "markdown".
+	Template string `json:"template,omitempty"`
+
+	// Folders limits digests to feeds in these OPML folders; empty takes
+	// every feed.
+	Folders []string `json:"folders,omitempty"`
+}
+
+func (c Config) hour() int {
+	if c.Hour == nil {
+		return DefaultHour
+	}
+	return *c.Hour
+}
+
+// TemplateName returns the template that renders the Markdown document.
+func (c Config) TemplateName() string {
+	if c.Template == "" {
+		return DefaultTemplate
+	}
+	return c.Template
+}
+
+// Record is one generated digest.
+type Record struct {
+	Date        string    `json:"date"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Since and Until bound when the digest's entries were first stored.
+	Since   time.Time `json:"since"`
+	Until   time.Time `json:"until"`
+	Entries int       `json:"entries"`
+	// Fingerprint identifies the entries and template, so a digest that
+	// would come out the same isn't written again.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// History is the generated digests, oldest first.
+type History []Record
+
+// Get returns the digest for date.
+func (h History) Get(date string) (Record, bool) {
+	for _, r := range h {
+		if r.Date == date {
+			return r, true
+		}
+	}
+	return Record{}, false
+}
+
+// Dir returns where the digests of the profile in profileDir are kept.
+func Dir(profileDir string) string {
+	return filepath.Join(profileDir, "digests")
+}
+
+// Load reads the history in dir, or an empty one if no digest was
+// generated yet.
+func Load(dir string) (History, error) {
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read digest history: %w", err)
+	}
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("parse digest history: %w", err)
+	}
+	slices.SortFunc(h, func(a, b Record) int { return cmpDate(a.Date, b.Date) })
+	return h, nil
+}
+
+func cmpDate(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func save(dir string, h History) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode digest history: %w", err)
+	}
+	if err := mdstore.AtomicWrite(filepath.Join(dir, indexFile), append(data, '\n')); err != nil {
+		return fmt.Errorf("write digest history: %w", err)
+	}
+	return nil
+}
+
+// Due reports whether the day's digest is due at now: from the configured
+// hour, in now's location, once a day.
+func Due(c Config, h History, now time.Time) bool {
+	if now.Hour() < c.hour() {
+		return false
+	}
+	_, done := h.Get(now.Format(DateLayout))
+	return !done
+}
+
+// Path returns the file of the digest for date in format, after checking
+// date is one.
+func Path(dir, date, format string) (string, error) {
+	if _, err := time.Parse(DateLayout, date); err != nil {
+		return "", fmt.Errorf("invalid digest date %q (want YYYY-MM-DD)", date)
+	}
+	if format != Markdown && format != HTML {
+		return "", fmt.Errorf("unknown digest format %q (use md or html)", format)
+	}
+	return filepath.Join(dir, date+"."+format), nil
+}
+
+// Read returns the digest for date in format.
+func Read(dir, date, format string) ([]byte, error) {
+	path, err := Path(dir, date, format)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read digest: %w", err)
+	}
+	return data, nil
+}
+
+// Generator writes digests for one profile.
+type Generator struct {
+	Dir      string
+	Store    storage.Store
+	OPML     *opml.Document
+	Config   Config
+	Template *template.Template
+}
+
+// Generate writes the digest dated now's day: the entries first stored
+// since the previous day's digest, or in the last day if there is none.
+// It reports false, writing nothing, when there are no entries or the
+// day's digest already has exactly these entries.
+func (g *Generator) Generate(ctx context.Context, now time.Time) (Record, bool, error) {
+	h, err := Load(g.Dir)
+	if err != nil {
+		return Record{}, false, err
+	}
+	date := now.Format(DateLayout)
+	since := now.Add(-24 * time.Hour)
+	for _, r := range h {
+		if r.Date < date {
+			since = r.Until
+		}
+	}
+
+	feeds, filter, heads, err := g.collect(ctx, since, now)
+	if err != nil {
+		return Record{}, false, err
+	}
+	rec := Record{Date: date, GeneratedAt: now, Since: since, Until: now, Entries: len(heads), Fingerprint: fingerprint(g.Template.Name(), heads)}
+	if old, ok := h.Get(date); ok && old.Fingerprint == rec.Fingerprint {
+		return old, false, nil
+	}
+	if len(heads) == 0 {
+		return rec, false, nil
+	}
+	// Bodies are only read once the digest is known to need writing
+	filter.NoContent = false
+	entries, err := g.Store.ListEntries(ctx, filter)
+	if err != nil {
+		return Record{}, false, fmt.Errorf("list entries: %w", err)
+	}
+
+	folders := make(map[string]string)
+	for _, f := range g.OPML.AllFeeds() {
+		folders[f.URL] = f.Folder
+	}
+	d := render.NewDigest("Digest for "+now.Format("Monday, January 2, 2006"), now, feeds, entries, folders)
+	var md, page bytes.Buffer
+	if err := render.Execute(&md, g.Template, d); err != nil {
+		return Record{}, false, err
+	}
+	footer := fmt.Sprintf("%d entr%s first seen %s to %s. Generated with digest.", len(entries), plural(len(entries)), since.Format("January 2 15:04"), now.Format("January 2 15:04"))
+	if err := render.WritePage(&page, d, footer); err != nil {
+		return Record{}, false, err
+	}
+
+	if err := os.MkdirAll(g.Dir, 0700); err != nil {
+		return Record{}, false, fmt.Errorf("create digests directory: %w", err)
+	}
+	for format, data := range map[string][]byte{Markdown: md.Bytes(), HTML: page.Bytes()} {
+		path, _ := Path(g.Dir, date, format)
+		if err := mdstore.AtomicWrite(path, data); err != nil {
+			return Record{}, false, fmt.Errorf("write digest: %w", err)
+		}
+	}
+	h = slices.DeleteFunc(h, func(r Record) bool { return r.Date == date })
+	if err := save(g.Dir, append(h, rec)); err != nil {
+		return Record{}, false, err
+	}
+	return rec, true, nil
+}
+
+// GenerateDue generates the day's digest if it's due at now, for running
+// after every fetch.
+func (g *Generator) GenerateDue(ctx context.Context, now time.Time) (Record, bool, error) {
+	h, err := Load(g.Dir)
+	if err != nil {
+		return Record{}, false, err
+	}
+	if !Due(g.Config, h, now) {
+		return Record{}, false, nil
+	}
+	return g.Generate(ctx, now)
+}
+
+// collect returns the feeds the digest covers, the filter for their
+// entries first stored from since until until, so consecutive digests
+// never overlap, and those entries without their content.
+func (g *Generator) collect(ctx context.Context, since, until time.Time) ([]*models.Feed, *storage.EntryFilter, []*models.Entry, error) {
+	feeds, err := g.Store.ListFeeds(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("list feeds: %w", err)
+	}
+	if len(g.Config.Folders) > 0 {
+		in := make(map[string]bool)
+		for _, folder := range g.Config.Folders {
+			for _, f := range g.OPML.FeedsInFolder(folder) {
+				in[f.URL] = true
+			}
+		}
+		feeds = slices.DeleteFunc(feeds, func(f *models.Feed) bool { return !in[f.URL] })
+		if len(feeds) == 0 {
+			return nil, nil, nil, nil
+		}
+	}
+	filter := &storage.EntryFilter{FirstSeen: true, Since: &since, Until: &until, NoContent: true}
+	for _, f := range feeds {
+		filter.FeedIDs = append(filter.FeedIDs, f.ID)
+	}
+	heads, err := g.Store.ListEntries(ctx, filter)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("list entries: %w", err)
+	}
+	return feeds, filter, heads, nil
+}
+
+// fingerprint identifies a digest by its template and entries.
+func fingerprint(tmpl string, entries []*models.Entry) string {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID + "\x00" + e.GetTitle()
+	}
+	slices.Sort(ids)
+	sum := sha256.New()
+	sum.Write([]byte(tmpl))
+	for _, id := range ids {
+		sum.Write([]byte{0})
+		sum.Write([]byte(id))
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Tests for scheduled digests and their history
+// ABOUTME: Covers when a digest is due, consecutive windows, skipping unchanged digests, and reading them back
+
+package digests
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/render"
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestDue(t *testing.T) {
+	hour := 9
+	c := Config{Hour: &hour}
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	if Due(c, nil, day.Add(8*time.Hour)) {
+		t.Error("expected nothing due before the hour")
+	}
+	if !Due(c, nil, day.Add(10*time.Hour)) {
+		t.Error("expected the day's digest due after the hour")
+	}
+	h := History{{Date: "2025-01-15"}}
+	if Due(c, h, day.Add(20*time.Hour)) {
+		t.Error("expected one digest a day")
+	}
+	if !Due(c, h, day.Add(34*time.Hour)) {
+		t.Error("expected the next day's digest due")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+	day1 := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	add := func(guid, title string, stored time.Time) {
+		t.Helper()
+		e := storage.NewEntry(feed.ID, guid, title)
+		e.CreatedAt = stored
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("old", "Too Old", day1.Add(-48*time.Hour))
+	add("a", "First Post", day1.Add(-time.Hour))
+
+	tmpl, err := render.Load("", DefaultTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "digests")
+	g := &Generator{Dir: dir, Store: store, OPML: opml.NewDocument("test"), Template: tmpl}
+
+	rec, wrote, err := g.Generate(ctx, day1)
+	if err != nil || !wrote {
+		t.Fatalf("Generate = %+v, %v, %v", rec, wrote, err)
+	}
+	if rec.Date != "2025-01-15" || rec.Entries != 1 {
+		t.Errorf("expected one entry from the last day, got %+v", rec)
+	}
+	md, err := Read(dir, "2025-01-15", Markdown)
+	if err != nil || !strings.Contains(string(md), "First Post") || strings.Contains(string(md), "Too Old") {
+		t.Errorf("unexpected Markdown digest (%v):\n%s", err, md)
+	}
+	if page, err := Read(dir, "2025-01-15", HTML); err != nil || !strings.Contains(string(page), "First Post") {
+		t.Errorf("unexpected HTML digest (%v):\n%s", err, page)
+	}
+
+	// Nothing changed, so the day's digest isn't written again
+	if _, wrote, err := g.Generate(ctx, day1.Add(time.Hour)); err != nil || wrote {
+		t.Errorf("expected an unchanged digest to be skipped, got wrote=%v err=%v", wrote, err)
+	}
+
+	// The next day's digest starts where the last one ended
+	day2 := day1.Add(24 * time.Hour)
+	add("b", "Second Post", day2.Add(-time.Hour))
+	rec, wrote, err = g.Generate(ctx, day2)
+	if err != nil || !wrote || rec.Entries != 1 || !rec.Since.Equal(day1) {
+		t.Fatalf("Generate day 2 = %+v, %v, %v", rec, wrote, err)
+	}
+	md, _ = Read(dir, "2025-01-16", Markdown)
+	if !strings.Contains(string(md), "Second Post") || strings.Contains(string(md), "First Post") {
+		t.Errorf("expected only the new entry on day 2:\n%s", md)
+	}
+
+	h, err := Load(dir)
+	if err != nil || len(h) != 2 || h[0].Date != "2025-01-15" {
+		t.Fatalf("Load = %+v, %v", h, err)
+	}
+	if _, err := Read(dir, "2025-01-17", Markdown); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing day, got %v", err)
+	}
+	if _, err := Read(dir, "../index", Markdown); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an invalid date to be rejected, got %v", err)
+	}
+}
@@ -1,20 +1,30 @@
 // ABOUTME: Feed discovery package for finding RSS/Atom feeds from URLs
-// ABOUTME: Supports direct feeds, HTML link headers, and common path probing
+// ABOUTME: Supports direct feeds, HTML link headers, and concurrent common path probing
 
 package discover
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/harper/digest/internal/fetch"
 	"github.com/harper/digest/internal/parse"
+	"github.com/mmcdole/gofeed"
 	"golang.org/x/net/html"
 )
 
+// defaultTimeout bounds a whole discovery when Options.Timeout is unset.
+const defaultTimeout = 20 * time.Second
+
 // Common feed paths to probe when other discovery methods fail
 var commonFeedPaths = []string{
 	"/feed.xml",
@@ -37,8 +47,22 @@ var (
 
 // DiscoveredFeed represents a feed found during discovery
 type DiscoveredFeed struct {
-	URL   string // Absolute URL of the feed
-	Title string // Feed title (from content or link element)
+	URL    string // Absolute URL of the feed
+	Title  string // Feed title (from content or link element)
+	Format string // "atom", "rss", or "json"
+	// Browser is set when the site only answered browser-like requests,
+	// so the feed should be synced with browser headers too
+	Browser bool
+
+	comments bool // advertised as a comments feed by the page linking it
+}
+
+// Options controls discovery behavior.
+type Options struct {
+	AllowLocalNetwork bool          // Allow fetching from private/local network IPs
+	IgnoreRobots      bool          // Probe common paths even when robots.txt disallows them
+	Timeout           time.Duration // Deadline for the whole discovery; zero means 20s
+	Browser           bool          // Send browser-like headers from the start
 }
 
 // Discover attempts to find an RSS/Atom feed from the given URL.
@@ -47,8 +71,24 @@ type DiscoveredFeed struct {
 //  2. Parse URL as HTML and extract <link rel="alternate"> headers
 //  3. Probe common feed URL patterns
 //
+// When several feeds turn up, the best is returned: see rank. A page behind
+// bot protection is retried as a browser (see discoverBlocked), and if it
+// stays blocked the error wraps fetch.ErrBotProtection.
 // Returns the discovered feed, or an error if none found.
 func Discover(inputURL string, allowLocalNetwork bool) (*DiscoveredFeed, error) {
+	return DiscoverWithOptions(inputURL, Options{AllowLocalNetwork: allowLocalNetwork})
+}
+
+// DiscoverWithOptions is like Discover but accepts discovery options.
+// Common path probing honors robots.txt and Crawl-delay unless IgnoreRobots is set;
+// the URL the user provided and the feed links it advertises are always fetched.
+func DiscoverWithOptions(inputURL string, opts Options) (*DiscoveredFeed, error) {
+	return DiscoverContext(context.Background(), inputURL, opts)
+}
+
+// DiscoverContext is like DiscoverWithOptions but stops when ctx is done.
+// Advertised feed links are verified, and common paths probed, concurrently.
+func DiscoverContext(ctx context.Context, inputURL string, opts Options) (*DiscoveredFeed, error) {
 	parsedURL, err := url.Parse(inputURL)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
@@ -58,49 +98,159 @@ func Discover(inputURL string, allowLocalNetwork bool) (*DiscoveredFeed, error)
 		return nil, fmt.Errorf("%w: missing scheme or host", ErrInvalidURL)
 	}
 
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	// Strategy 1: Try direct feed
-	feed, body, err := tryDirectFeed(inputURL, allowLocalNetwork)
-	if err != nil {
+	var found *DiscoveredFeed
+	feed, body, err := tryDirectFeed(ctx, inputURL, opts)
+	switch {
+	case errors.Is(err, fetch.ErrBotProtection):
+		found, err = discoverBlocked(ctx, parsedURL, inputURL, opts, err)
+	case err != nil:
 		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	default:
+		found, err = discoverPage(ctx, parsedURL, feed, body, opts)
 	}
-	if feed != nil {
-		return feed, nil
+
+	if errors.Is(err, ErrNoFeedFound) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w (gave up after %s)", ErrNoFeedFound, timeout)
 	}
+	return found, err
+}
 
+// discoverPage finds the feed for a fetched page: the page itself when it is
+// a feed, else the best feed it links to, else the best at a common path.
+func discoverPage(ctx context.Context, parsedURL *url.URL, feed *DiscoveredFeed, body []byte, opts Options) (*DiscoveredFeed, error) {
 	// Strategy 2: Extract feed links from HTML
-	feeds, err := extractFeedLinks(body, parsedURL)
-	if err == nil && len(feeds) > 0 {
-		// Verify the first discovered link is a valid feed
-		for _, candidate := range feeds {
-			verifiedFeed, _, verifyErr := tryDirectFeed(candidate.URL, allowLocalNetwork)
-			if verifyErr == nil && verifiedFeed != nil {
-				// Use title from HTML link if feed doesn't have one
-				if verifiedFeed.Title == "" && candidate.Title != "" {
-					verifiedFeed.Title = candidate.Title
-				}
-				return verifiedFeed, nil
+	if feed == nil {
+		candidates, err := extractFeedLinks(body, parsedURL)
+		if err == nil && len(candidates) > 0 {
+			if verified := verifyCandidates(ctx, candidates, opts); len(verified) > 0 {
+				feed = rank(verified)[0]
 			}
 		}
 	}
 
 	// Strategy 3: Probe common paths
-	feed, err = probeCommonPaths(parsedURL, allowLocalNetwork)
-	if err == nil && feed != nil {
-		return feed, nil
+	if feed == nil {
+		feed, _ = probeCommonPaths(ctx, parsedURL, opts)
+	}
+
+	if feed == nil {
+		return nil, ErrNoFeedFound
 	}
+	feed.Browser = opts.Browser
+	return feed, nil
+}
 
-	return nil, ErrNoFeedFound
+// discoverBlocked handles a page that answered with a bot-protection
+// challenge. Some protection only turns away clients that don't look like
+// browsers, so the page is retried with browser-like headers. Sites that
+// challenge browsers too often still exempt their feeds for feed readers,
+// so common paths are probed as a last resort. blocked is the original
+// error, returned with an explanation when nothing gets through.
+func discoverBlocked(ctx context.Context, parsedURL *url.URL, inputURL string, opts Options, blocked error) (*DiscoveredFeed, error) {
+	if !opts.Browser {
+		browserOpts := opts
+		browserOpts.Browser = true
+		feed, body, err := tryDirectFeed(ctx, inputURL, browserOpts)
+		if err == nil {
+			return discoverPage(ctx, parsedURL, feed, body, browserOpts)
+		}
+	}
+	if feed, err := probeCommonPaths(ctx, parsedURL, opts); err == nil {
+		return feed, nil
+	}
+	return nil, fmt.Errorf("%w: the page stayed blocked with browser-like headers and no common feed path got through", blocked)
 }
 
 // tryDirectFeed attempts to fetch and parse the URL as an RSS/Atom feed.
 // Returns the feed if successful, or nil if the content is not a valid feed.
 // Also returns the raw body for use in HTML parsing if it's not a feed.
-func tryDirectFeed(feedURL string, allowLocalNetwork bool) (*DiscoveredFeed, []byte, error) {
-	result, err := fetch.Fetch(context.Background(), feedURL, nil, nil, allowLocalNetwork)
+// A bot-protection challenge page is an ErrBotProtection error, even when
+// it is served as 200 OK.
+func tryDirectFeed(ctx context.Context, feedURL string, opts Options) (*DiscoveredFeed, []byte, error) {
+	// Read as a feed so one too large to hold whole is still found; its
+	// first items are enough to name it
+	result, err := fetch.FetchWith(ctx, feedURL, nil, nil, opts.AllowLocalNetwork, fetch.RequestOptions{
+		Browser:  opts.Browser,
+		ReadBody: readFeed,
+	})
 	if err != nil {
 		return nil, nil, err
 	}
+	feed, body, err := parseDiscovered(feedURL, result)
+	if feed == nil && err == nil {
+		if provider := fetch.ChallengePage(body); provider != "" {
+			return nil, nil, fmt.Errorf("%w (%s)", fetch.ErrBotProtection, provider)
+		}
+	}
+	return feed, body, err
+}
+
+// readFeed reads a response that may be a feed under the sync caps.
+func readFeed(r io.Reader) ([]byte, bool, error) {
+	return parse.ReadFeed(r, parse.DefaultStreamLimits)
+}
+
+// tryProbeFeed checks a guessed feed URL with a HEAD request and fetches it
+// only if it exists, politely, honoring robots.txt unless disabled.
+func tryProbeFeed(ctx context.Context, feedURL string, opts Options) (*DiscoveredFeed, error) {
+	pageOpts := fetch.PageOptions{
+		AllowLocalNetwork: opts.AllowLocalNetwork,
+		IgnoreRobots:      opts.IgnoreRobots,
+		Browser:           opts.Browser,
+	}
+	code, err := fetch.ProbePage(ctx, feedURL, pageOpts)
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", code)
+	}
+	result, err := fetch.FetchPage(ctx, feedURL, pageOpts)
+	if err != nil {
+		return nil, err
+	}
+	feed, _, err := parseDiscovered(feedURL, result)
+	return feed, err
+}
 
+// verifyCandidates fetches the feed links a page advertises concurrently and
+// returns those that parse as feeds, in the page's order.
+func verifyCandidates(ctx context.Context, candidates []DiscoveredFeed, opts Options) []*DiscoveredFeed {
+	found := make([]*DiscoveredFeed, len(candidates))
+	var wg sync.WaitGroup
+	for i, candidate := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			feed, _, err := tryDirectFeed(ctx, candidate.URL, opts)
+			if err != nil || feed == nil {
+				return
+			}
+			// Use title from HTML link if feed doesn't have one
+			if feed.Title == "" {
+				feed.Title = candidate.Title
+			}
+			// The link's title may be the only sign it's a comments feed
+			if isCommentsFeed(&candidate) {
+				feed.comments = true
+			}
+			found[i] = feed
+		}()
+	}
+	wg.Wait()
+	return compact(found)
+}
+
+// parseDiscovered parses a fetch result as a feed, returning the body when it is not one.
+func parseDiscovered(feedURL string, result *fetch.Result) (*DiscoveredFeed, []byte, error) {
 	// Try to parse as a feed
 	parsed, parseErr := parse.Parse(result.Body)
 	if parseErr != nil {
@@ -109,11 +259,24 @@ func tryDirectFeed(feedURL string, allowLocalNetwork bool) (*DiscoveredFeed, []b
 	}
 
 	return &DiscoveredFeed{
-		URL:   feedURL,
-		Title: parsed.Title,
+		URL:    feedURL,
+		Title:  parsed.Title,
+		Format: feedFormat(result.Body),
 	}, result.Body, nil
 }
 
+// feedFormat names the format of a feed body that parsed.
+func feedFormat(body []byte) string {
+	switch gofeed.DetectFeedType(bytes.NewReader(body)) {
+	case gofeed.FeedTypeAtom:
+		return "atom"
+	case gofeed.FeedTypeJSON:
+		return "json"
+	default:
+		return "rss"
+	}
+}
+
 // extractFeedLinks parses HTML and returns feed URLs from <link rel="alternate"> elements
 func extractFeedLinks(htmlBody []byte, baseURL *url.URL) ([]DiscoveredFeed, error) {
 	doc, err := html.Parse(strings.NewReader(string(htmlBody)))
@@ -161,22 +324,76 @@ func extractFeedLinks(htmlBody []byte, baseURL *url.URL) ([]DiscoveredFeed, erro
 }
 
 // probeCommonPaths tries common feed URL patterns against the base URL
-func probeCommonPaths(baseURL *url.URL, allowLocalNetwork bool) (*DiscoveredFeed, error) {
+// concurrently and returns the best feed among those that exist.
+func probeCommonPaths(ctx context.Context, baseURL *url.URL, opts Options) (*DiscoveredFeed, error) {
 	// Build base URL without path
 	probeBase := &url.URL{
 		Scheme: baseURL.Scheme,
 		Host:   baseURL.Host,
 	}
 
-	for _, path := range commonFeedPaths {
-		probeURL := probeBase.String() + path
-		feed, _, err := tryDirectFeed(probeURL, allowLocalNetwork)
-		if err == nil && feed != nil {
-			return feed, nil
+	found := make([]*DiscoveredFeed, len(commonFeedPaths))
+	var wg sync.WaitGroup
+	for i, path := range commonFeedPaths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			feed, err := tryProbeFeed(ctx, probeBase.String()+path, opts)
+			if err == nil && feed != nil {
+				found[i] = feed
+			}
+		}()
+	}
+	wg.Wait()
+
+	feeds := compact(found)
+	if len(feeds) == 0 {
+		return nil, ErrNoFeedFound
+	}
+	return rank(feeds)[0], nil
+}
+
+// rank orders feeds best first: a site's posts before its comments, then
+// Atom before RSS and JSON Feed since Atom dates and IDs are more reliable.
+// Otherwise feeds keep the order they were found in.
+func rank(feeds []*DiscoveredFeed) []*DiscoveredFeed {
+	score := func(f *DiscoveredFeed) int {
+		s := 0
+		if f.comments || isCommentsFeed(f) {
+			s += 2
 		}
+		if f.Format != "atom" {
+			s++
+		}
+		return s
 	}
+	sort.SliceStable(feeds, func(i, j int) bool {
+		return score(feeds[i]) < score(feeds[j])
+	})
+	return feeds
+}
 
-	return nil, ErrNoFeedFound
+// isCommentsFeed reports whether a feed looks like a site's comment feed
+// rather than its posts, as WordPress's /comments/feed/ is.
+func isCommentsFeed(f *DiscoveredFeed) bool {
+	u, err := url.Parse(f.URL)
+	path := f.URL
+	if err == nil {
+		path = u.Path
+	}
+	return strings.Contains(strings.ToLower(path), "comment") ||
+		strings.Contains(strings.ToLower(f.Title), "comment")
+}
+
+// compact drops the nils from a slice of results.
+func compact(found []*DiscoveredFeed) []*DiscoveredFeed {
+	var feeds []*DiscoveredFeed
+	for _, f := range found {
+		if f != nil {
+			feeds = append(feeds, f)
+		}
+	}
+	return feeds
 }
 
 // resolveURL resolves a potentially relative URL against a base URL
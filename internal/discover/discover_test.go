@@ -4,9 +4,17 @@
 package discover
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
 )
 
 const testRSSFeed = `<?xml version="1.0" encoding="UTF-8"?>
@@ -138,13 +146,17 @@ func TestDiscover_HTMLWithFeedLink(t *testing.T) {
 		t.Fatal("expected feed, got nil")
 	}
 
-	expectedURL := server.URL + "/feed.xml"
+	// Atom is preferred over RSS when a page advertises both
+	expectedURL := server.URL + "/atom.xml"
 	if feed.URL != expectedURL {
 		t.Errorf("expected URL %s, got %s", expectedURL, feed.URL)
 	}
 
-	if feed.Title != "Test Feed" {
-		t.Errorf("expected title 'Test Feed', got '%s'", feed.Title)
+	if feed.Title != "Test Atom Feed" {
+		t.Errorf("expected title 'Test Atom Feed', got '%s'", feed.Title)
+	}
+	if feed.Format != "atom" {
+		t.Errorf("expected format atom, got %q", feed.Format)
 	}
 }
 
@@ -208,6 +220,37 @@ func TestDiscover_ProbeCommonPaths(t *testing.T) {
 	}
 }
 
+func TestDiscover_ProbeRespectsRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(testHTMLNoFeedLinks))
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /rss.xml\n"))
+		case "/rss.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(testRSSFeed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	_, err := Discover(server.URL, false)
+	if err != ErrNoFeedFound {
+		t.Fatalf("expected ErrNoFeedFound when robots.txt disallows probing, got: %v", err)
+	}
+
+	feed, err := DiscoverWithOptions(server.URL, Options{IgnoreRobots: true})
+	if err != nil {
+		t.Fatalf("expected no error with IgnoreRobots, got: %v", err)
+	}
+	if feed.URL != server.URL+"/rss.xml" {
+		t.Errorf("expected URL %s, got %s", server.URL+"/rss.xml", feed.URL)
+	}
+}
+
 func TestDiscover_NoFeedFound(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
@@ -251,7 +294,7 @@ func TestExtractFeedLinks_MultipleFeeds(t *testing.T) {
 	defer server.Close()
 
 	// Fetch the HTML
-	result, _, err := tryDirectFeed(server.URL, false)
+	result, _, err := tryDirectFeed(context.Background(), server.URL, Options{})
 	if result != nil {
 		t.Fatal("expected HTML page, not a feed")
 	}
@@ -633,9 +676,9 @@ func TestDiscover_ProbeAllPathsFail(t *testing.T) {
 }
 
 func TestDiscover_ProbeServerError(t *testing.T) {
-	requestCount := 0
+	var requestCount atomic.Int32
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestCount++
+		requestCount.Add(1)
 		if r.URL.Path == "/" {
 			w.Header().Set("Content-Type", "text/html")
 			w.Write([]byte(testHTMLNoFeedLinks))
@@ -653,8 +696,8 @@ func TestDiscover_ProbeServerError(t *testing.T) {
 	}
 
 	// Should have attempted to probe common paths
-	if requestCount < 2 {
-		t.Errorf("expected multiple probe attempts, got %d requests", requestCount)
+	if requestCount.Load() < 2 {
+		t.Errorf("expected multiple probe attempts, got %d requests", requestCount.Load())
 	}
 }
 
@@ -727,3 +770,197 @@ func TestDiscover_FeedLinkPointsTo404(t *testing.T) {
 		t.Errorf("expected URL %s, got %s", expectedURL, feed.URL)
 	}
 }
+
+func TestDiscover_PrefersPostsOverComments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head>
+  <link rel="alternate" type="application/rss+xml" title="Site &raquo; Comments Feed" href="/comments/feed/">
+  <link rel="alternate" type="application/atom+xml" title="Site Atom" href="/feed/atom/">
+  <link rel="alternate" type="application/rss+xml" title="Site" href="/feed/">
+</head></html>`))
+		case "/comments/feed/":
+			w.Write([]byte(strings.Replace(testAtomFeed, "Test Atom Feed", "Comments on Site", 1)))
+		case "/feed/atom/":
+			w.Write([]byte(testAtomFeed))
+		case "/feed/":
+			w.Write([]byte(testRSSFeed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	feed, err := Discover(server.URL, false)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if feed.URL != server.URL+"/feed/atom/" {
+		t.Errorf("expected the Atom posts feed, got %s", feed.URL)
+	}
+}
+
+func TestRank(t *testing.T) {
+	feeds := rank([]*DiscoveredFeed{
+		{URL: "https://example.com/comments/feed", Format: "atom"},
+		{URL: "https://example.com/rss.xml", Format: "rss"},
+		{URL: "https://example.com/feed.json", Format: "json"},
+		{URL: "https://example.com/atom.xml", Format: "atom"},
+		{URL: "https://example.com/replies", Title: "Latest Comments", Format: "rss"},
+	})
+	var got []string
+	for _, f := range feeds {
+		got = append(got, f.URL)
+	}
+	want := []string{
+		"https://example.com/atom.xml",
+		"https://example.com/rss.xml",
+		"https://example.com/feed.json",
+		"https://example.com/comments/feed",
+		"https://example.com/replies",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("rank() = %v, want %v", got, want)
+	}
+}
+
+func TestDiscover_ProbesWithHeadFirst(t *testing.T) {
+	var mu sync.Mutex
+	var gets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			gets = append(gets, r.URL.Path)
+			mu.Unlock()
+		}
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(testHTMLNoFeedLinks))
+		case "/rss.xml":
+			w.Write([]byte(testRSSFeed))
+		case "/atom.xml":
+			w.Write([]byte(testAtomFeed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	feed, err := DiscoverWithOptions(server.URL, Options{IgnoreRobots: true})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if feed.URL != server.URL+"/atom.xml" {
+		t.Errorf("expected the Atom feed ranked first, got %s", feed.URL)
+	}
+
+	// Missing paths are ruled out by HEAD without fetching a body
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range gets {
+		if path != "/" && path != "/rss.xml" && path != "/atom.xml" {
+			t.Errorf("expected no GET for missing path %s", path)
+		}
+	}
+}
+
+func TestDiscover_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(testHTMLNoFeedLinks))
+			return
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := DiscoverWithOptions(server.URL, Options{IgnoreRobots: true, Timeout: 200 * time.Millisecond})
+	if !errors.Is(err, ErrNoFeedFound) {
+		t.Fatalf("expected ErrNoFeedFound, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "gave up") {
+		t.Errorf("expected the error to mention the deadline, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected probing to stop at the deadline, took %s", elapsed)
+	}
+}
+
+// challenge answers like Cloudflare does for a client it won't let through.
+func challenge(w http.ResponseWriter) {
+	w.Header().Set("Cf-Mitigated", "challenge")
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte(`<html><head><title>Just a moment...</title></head></html>`))
+}
+
+func TestDiscover_BotProtectionRetriesAsBrowser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("User-Agent"), "Mozilla/") {
+			challenge(w)
+			return
+		}
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(testHTMLWithRelativeFeedLink))
+		case "/feed.xml":
+			w.Write([]byte(testRSSFeed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	feed, err := Discover(server.URL+"/", false)
+	if err != nil {
+		t.Fatalf("expected browser headers to get through, got: %v", err)
+	}
+	if feed.URL != server.URL+"/feed.xml" || !feed.Browser {
+		t.Errorf("expected the feed found as a browser, got %+v", feed)
+	}
+}
+
+func TestDiscover_BotProtectionExemptFeedPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+			w.Write([]byte(testRSSFeed))
+		case "/robots.txt":
+			http.NotFound(w, r)
+		default:
+			challenge(w)
+		}
+	}))
+	defer server.Close()
+
+	feed, err := Discover(server.URL, false)
+	if err != nil {
+		t.Fatalf("expected the unprotected feed path to be found, got: %v", err)
+	}
+	if feed.URL != server.URL+"/feed" || feed.Browser {
+		t.Errorf("expected /feed without browser headers, got %+v", feed)
+	}
+}
+
+func TestDiscover_BotProtectionBlocked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A challenge served as 200 OK, to everyone
+		w.Write([]byte(`<html><script src="/cdn-cgi/challenge-platform/scripts/jsd/main.js"></script></html>`))
+	}))
+	defer server.Close()
+
+	_, err := Discover(server.URL, false)
+	if !errors.Is(err, fetch.ErrBotProtection) {
+		t.Fatalf("expected ErrBotProtection, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "Cloudflare") {
+		t.Errorf("expected the error to name Cloudflare, got: %v", err)
+	}
+}
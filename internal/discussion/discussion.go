@@ -0,0 +1,102 @@
+// ABOUTME: Finds and fetches the comment threads of entries from HN, Reddit, and blogs
+// ABOUTME: Derives comment feeds for known sites and reads comments from them
+
+package discussion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/parse"
+)
+
+// ErrNoFeed is returned when there's no feed to read an entry's comments from.
+var ErrNoFeed = errors.New("no comments feed")
+
+// redditThread matches Reddit comment thread paths.
+var redditThread = regexp.MustCompile(`^/r/[^/]+/comments/[^/]+`)
+
+// Comment is one comment of a thread.
+type Comment struct {
+	Author    string
+	Published *time.Time
+	Link      string
+	Content   string // As the feed gave it, usually HTML
+}
+
+// Thread returns the web page of an entry's discussion: its discussion URL,
+// or its own link when that is a Hacker News or Reddit thread. It returns ""
+// when the entry has none.
+func Thread(e *models.Entry) string {
+	if e.DiscussionURL != nil && *e.DiscussionURL != "" {
+		return *e.DiscussionURL
+	}
+	if e.Link != nil && threadFeed(*e.Link) != "" {
+		return *e.Link
+	}
+	return ""
+}
+
+// FeedURL returns a feed of an entry's comments: the one the feed named, or
+// one derived from a Hacker News or Reddit thread. It returns "" when there
+// is none.
+func FeedURL(e *models.Entry) string {
+	if e.CommentsFeedURL != nil && *e.CommentsFeedURL != "" {
+		return *e.CommentsFeedURL
+	}
+	return threadFeed(Thread(e))
+}
+
+// threadFeed returns the comments feed of a known site's thread page.
+func threadFeed(thread string) string {
+	u, err := url.Parse(thread)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch {
+	case host == "news.ycombinator.com" && u.Path == "/item" && u.Query().Get("id") != "":
+		return "https://hnrss.org/item?id=" + url.QueryEscape(u.Query().Get("id"))
+	case (host == "reddit.com" || host == "old.reddit.com") && redditThread.MatchString(u.Path):
+		return "https://www.reddit.com" + strings.TrimSuffix(u.Path, "/") + "/.rss"
+	}
+	return ""
+}
+
+// Fetch reads the comments in a comments feed, in feed order, at most limit
+// of them (0 for all).
+func Fetch(ctx context.Context, feedURL string, limit int, allowLocalNetwork bool) ([]Comment, error) {
+	result, err := fetch.Fetch(ctx, feedURL, nil, nil, allowLocalNetwork)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parse.Parse(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse comments feed: %w", err)
+	}
+
+	comments := make([]Comment, 0, len(parsed.Entries))
+	for _, e := range parsed.Entries {
+		if limit > 0 && len(comments) == limit {
+			break
+		}
+		content := e.Content
+		if content == "" {
+			content = e.Title
+		}
+		comments = append(comments, Comment{
+			Author:    e.Author,
+			Published: e.PublishedAt,
+			Link:      e.Link,
+			Content:   content,
+		})
+	}
+	return comments, nil
+}
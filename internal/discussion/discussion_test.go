@@ -0,0 +1,81 @@
+// ABOUTME: Tests for finding and fetching entry comment threads
+// ABOUTME: Covers thread and feed derivation for HN and Reddit, and reading comments via httptest
+
+package discussion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func entryWith(link, discussionURL, commentsFeed string) *models.Entry {
+	e := models.NewEntry("feed", "guid", "Title")
+	if link != "" {
+		e.Link = &link
+	}
+	if discussionURL != "" {
+		e.DiscussionURL = &discussionURL
+	}
+	if commentsFeed != "" {
+		e.CommentsFeedURL = &commentsFeed
+	}
+	return e
+}
+
+func TestThreadAndFeedURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		entry      *models.Entry
+		wantThread string
+		wantFeed   string
+	}{
+		{"none", entryWith("https://example.com/post", "", ""), "", ""},
+		{"hacker news", entryWith("https://example.com/post", "https://news.ycombinator.com/item?id=42", ""),
+			"https://news.ycombinator.com/item?id=42", "https://hnrss.org/item?id=42"},
+		{"reddit link", entryWith("https://old.reddit.com/r/golang/comments/abc123/some_title/", "", ""),
+			"https://old.reddit.com/r/golang/comments/abc123/some_title/", "https://www.reddit.com/r/golang/comments/abc123/some_title/.rss"},
+		{"reddit listing isn't a thread", entryWith("https://www.reddit.com/r/golang/", "", ""), "", ""},
+		{"blog comments feed", entryWith("https://example.com/post", "https://example.com/post#comments", "https://example.com/post/feed"),
+			"https://example.com/post#comments", "https://example.com/post/feed"},
+		{"feed only", entryWith("https://example.com/post", "", "https://example.com/post/feed"), "", "https://example.com/post/feed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Thread(tt.entry); got != tt.wantThread {
+				t.Errorf("Thread() = %q, want %q", got, tt.wantThread)
+			}
+			if got := FeedURL(tt.entry); got != tt.wantFeed {
+				t.Errorf("FeedURL() = %q, want %q", got, tt.wantFeed)
+			}
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Comments</title>
+<item><title>Re: Post</title><link>https://example.com/c/1</link><author>a@example.com (Ann)</author><description>&lt;p&gt;First!&lt;/p&gt;</description><pubDate>Mon, 05 Jan 2026 10:00:00 GMT</pubDate></item>
+<item><title>Title only</title><link>https://example.com/c/2</link></item>
+<item><title>Third</title><description>Cut by the limit</description></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	comments, err := Fetch(context.Background(), server.URL, 2, true)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(comments))
+	}
+	if c := comments[0]; c.Author != "Ann" || c.Content != "<p>First!</p>" || c.Published == nil || c.Link != "https://example.com/c/1" {
+		t.Errorf("unexpected first comment %+v", c)
+	}
+	if comments[1].Content != "Title only" {
+		t.Errorf("expected the title to stand in for missing content, got %q", comments[1].Content)
+	}
+}
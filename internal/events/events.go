@@ -0,0 +1,239 @@
+// ABOUTME: Event extraction for feeds that announce meetups, talks, and releases
+// ABOUTME: Finds the event date in an entry's markup or text so upcoming events can be exported as a calendar
+
+package events
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/models"
+)
+
+// timedDuration is the length given to events that have a start time but no
+// end; calendars render zero-length events poorly.
+const timedDuration = time.Hour
+
+// Event is an upcoming event found in an entry.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+const monthPattern = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)`
+
+var (
+	// <time datetime="2026-10-21T19:00"> and hCalendar dtstart markup
+	datetimeAttr = regexp.MustCompile(`(?i)<time[^>]*\sdatetime="([^"]+)"`)
+	// October 21, 2026 / Oct. 21st / Oct 21 2026
+	monthDay = regexp.MustCompile(`(?i)\b` + monthPattern + `\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4}))?`)
+	// 21 October 2026 / 21st Oct
+	dayMonth = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+` + monthPattern + `\b\.?(?:,?\s+(\d{4}))?`)
+	// 2026-10-21
+	isoDate = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	// 7pm / 7:30 PM / 19:00, looked for just after a date
+	clock = regexp.MustCompile(`(?i)\b(\d{1,2})(?::(\d{2}))?\s*([ap])\.?m\b\.?|\b(\d{1,2}):(\d{2})\b`)
+)
+
+// clockWindow is how far past a date a start time is looked for.
+const clockWindow = 32
+
+// Extract finds the start of the event an entry announces. Machine-readable
+// <time datetime> markup wins; otherwise the first written date in the title
+// or text that does not fall before the publish date is used. Dates without a
+// year take the next occurrence after the publish date. A start time written
+// right after the date is honoured; without one the event is all-day.
+func Extract(title, raw string, published time.Time, loc *time.Location) (start time.Time, allDay bool, ok bool) {
+	if loc == nil {
+		loc = time.Local
+	}
+	published = published.In(loc)
+	publishedDay := time.Date(published.Year(), published.Month(), published.Day(), 0, 0, 0, 0, loc)
+
+	for _, m := range datetimeAttr.FindAllStringSubmatch(raw, -1) {
+		if t, allDay, ok := parseDatetime(m[1], loc); ok && !t.Before(publishedDay) {
+			return t, allDay, true
+		}
+	}
+
+	text := title + "\n" + content.ToText(raw)
+	type candidate struct {
+		pos  int
+		end  int
+		date time.Time
+	}
+	var candidates []candidate
+	add := func(pos, end, year int, month time.Month, day int) {
+		if day < 1 || day > 31 || month < time.January || month > time.December {
+			return
+		}
+		if year == 0 {
+			year = publishedDay.Year()
+			if time.Date(year, month, day, 0, 0, 0, 0, loc).Before(publishedDay) {
+				year++
+			}
+		}
+		d := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		if d.Day() != day {
+			return // e.g. February 30
+		}
+		candidates = append(candidates, candidate{pos: pos, end: end, date: d})
+	}
+
+	for _, m := range monthDay.FindAllStringSubmatchIndex(text, -1) {
+		day, _ := strconv.Atoi(text[m[4]:m[5]])
+		add(m[0], m[1], optionalYear(text, m[6], m[7]), monthOf(text[m[2]:m[3]]), day)
+	}
+	for _, m := range dayMonth.FindAllStringSubmatchIndex(text, -1) {
+		day, _ := strconv.Atoi(text[m[2]:m[3]])
+		add(m[0], m[1], optionalYear(text, m[6], m[7]), monthOf(text[m[4]:m[5]]), day)
+	}
+	for _, m := range isoDate.FindAllStringSubmatchIndex(text, -1) {
+		year, _ := strconv.Atoi(text[m[2]:m[3]])
+		month, _ := strconv.Atoi(text[m[4]:m[5]])
+		day, _ := strconv.Atoi(text[m[6]:m[7]])
+		add(m[0], m[1], year, time.Month(month), day)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].pos < candidates[j].pos })
+
+	for _, c := range candidates {
+		if c.date.Before(publishedDay) {
+			continue
+		}
+		tail := text[c.end:min(len(text), c.end+clockWindow)]
+		if hour, minute, ok := parseClock(tail); ok {
+			return c.date.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute), false, true
+		}
+		return c.date, true, true
+	}
+	return time.Time{}, false, false
+}
+
+// Upcoming extracts events from entries and keeps those starting on or after
+// the start of now's day, soonest first. feedNames prefixes each summary with
+// the feed's name when present.
+func Upcoming(entries []*models.Entry, feedNames map[string]string, now time.Time) []Event {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var events []Event
+	for _, entry := range entries {
+		title := "Untitled"
+		if entry.Title != nil && *entry.Title != "" {
+			title = *entry.Title
+		}
+		raw := ""
+		if entry.Content != nil {
+			raw = *entry.Content
+		}
+		published := entry.CreatedAt
+		if entry.PublishedAt != nil {
+			published = *entry.PublishedAt
+		}
+
+		start, allDay, ok := Extract(title, raw, published, now.Location())
+		if !ok || start.Before(today) {
+			continue
+		}
+
+		ev := Event{UID: entry.ID + "@digest", Summary: title, Start: start, AllDay: allDay}
+		if name := feedNames[entry.FeedID]; name != "" {
+			ev.Summary = name + ": " + title
+		}
+		if allDay {
+			ev.End = start.AddDate(0, 0, 1)
+		} else {
+			ev.End = start.Add(timedDuration)
+		}
+		if entry.Link != nil {
+			ev.URL = *entry.Link
+		}
+		ev.Description = strings.TrimSpace(content.ToText(raw))
+		events = append(events, ev)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events
+}
+
+func monthOf(name string) time.Month {
+	return months[strings.ToLower(name)[:3]]
+}
+
+func optionalYear(text string, start, end int) int {
+	if start < 0 {
+		return 0
+	}
+	year, _ := strconv.Atoi(text[start:end])
+	return year
+}
+
+// parseDatetime reads the datetime attribute of a <time> element.
+func parseDatetime(value string, loc *time.Location) (time.Time, bool, bool) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), false, true
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, false, true
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, true, true
+	}
+	return time.Time{}, false, false
+}
+
+// parseClock reads a start time at the beginning of s, allowing a short
+// connector such as "at" or "from" before it.
+func parseClock(s string) (hour, minute int, ok bool) {
+	m := clock.FindStringSubmatchIndex(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	gap := strings.ToLower(strings.Trim(s[:m[0]], " ,.-–@\t\n"))
+	switch gap {
+	case "", "at", "from", "starting at", "doors at", "doors":
+	default:
+		return 0, 0, false
+	}
+
+	if m[2] >= 0 {
+		hour, _ = strconv.Atoi(s[m[2]:m[3]])
+		if m[4] >= 0 {
+			minute, _ = strconv.Atoi(s[m[4]:m[5]])
+		}
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		pm := strings.EqualFold(s[m[6]:m[7]], "p")
+		if hour == 12 {
+			hour = 0
+		}
+		if pm {
+			hour += 12
+		}
+	} else {
+		hour, _ = strconv.Atoi(s[m[8]:m[9]])
+		minute, _ = strconv.Atoi(s[m[10]:m[11]])
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
@@ -0,0 +1,181 @@
+// ABOUTME: Tests for event date extraction and iCalendar output
+// ABOUTME: Covers written and machine-readable dates, year inference, start times, and RFC 5545 formatting
+
+package events
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestExtract(t *testing.T) {
+	published := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		title   string
+		raw     string
+		want    time.Time
+		allDay  bool
+		missing bool
+	}{
+		{
+			name:   "written date with year",
+			title:  "Go meetup",
+			raw:    "<p>Join us on October 21, 2026 for talks.</p>",
+			want:   time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC),
+			allDay: true,
+		},
+		{
+			name:  "start time after the date",
+			title: "Go meetup",
+			raw:   "<p>Thursday, Oct. 22nd at 7:30 PM in the usual spot.</p>",
+			want:  time.Date(2026, 10, 22, 19, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "day before month with 24 hour clock",
+			title: "Workshop 5 November 2026, 14:00",
+			want:  time.Date(2026, 11, 5, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "missing year rolls into next year",
+			title:  "Winter party Jan 9",
+			want:   time.Date(2027, 1, 9, 0, 0, 0, 0, time.UTC),
+			allDay: true,
+		},
+		{
+			name:  "time element wins over text",
+			title: "Recap of September 3",
+			raw:   `<p>Next one: <time datetime="2026-11-12T18:00:00Z">soon</time></p>`,
+			want:  time.Date(2026, 11, 12, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "past dates are skipped",
+			title:  "Release",
+			raw:    "<p>After the 2026-09-01 beta, the final ships 2026-10-30.</p>",
+			want:   time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC),
+			allDay: true,
+		},
+		{
+			name:    "no date",
+			title:   "Thoughts on testing",
+			raw:     "<p>Nothing scheduled here.</p>",
+			missing: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allDay, ok := Extract(tt.title, tt.raw, published, time.UTC)
+			if tt.missing {
+				if ok {
+					t.Fatalf("expected no event, got %v", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("expected an event date")
+			}
+			if !got.Equal(tt.want) || allDay != tt.allDay {
+				t.Errorf("got %v (all-day %v), want %v (all-day %v)", got, allDay, tt.want, tt.allDay)
+			}
+		})
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	published := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(id, title string) *models.Entry {
+		e := models.NewEntry("feed1", id, title)
+		e.ID = id
+		e.PublishedAt = &published
+		link := "https://example.com/" + id
+		e.Link = &link
+		return e
+	}
+
+	events := Upcoming([]*models.Entry{
+		entry("later", "Conference November 20, 2026"),
+		entry("past", "Meetup October 10, 2026"),
+		entry("today", "Hack night October 15 at 6pm"),
+		entry("none", "Blog post"),
+	}, map[string]string{"feed1": "Events"}, now)
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 upcoming events, got %d: %+v", len(events), events)
+	}
+	if events[0].UID != "today@digest" || events[1].UID != "later@digest" {
+		t.Errorf("expected soonest first, got %s then %s", events[0].UID, events[1].UID)
+	}
+	if events[0].Summary != "Events: Hack night October 15 at 6pm" {
+		t.Errorf("unexpected summary %q", events[0].Summary)
+	}
+	if events[0].AllDay || !events[0].End.Equal(events[0].Start.Add(time.Hour)) {
+		t.Errorf("expected a one hour timed event, got %+v", events[0])
+	}
+	if !events[1].AllDay || !events[1].End.Equal(events[1].Start.AddDate(0, 0, 1)) {
+		t.Errorf("expected an all-day event ending the next day, got %+v", events[1])
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 10, 21, 19, 0, 0, 0, time.UTC)
+	events := []Event{
+		{
+			UID:         "abc@digest",
+			Summary:     "Meetup; talks, pizza",
+			Description: "Line one\nLine two " + strings.Repeat("é", 60),
+			URL:         "https://example.com/meetup",
+			Start:       start,
+			End:         start.Add(time.Hour),
+		},
+		{
+			UID:     "def@digest",
+			Summary: "Conference",
+			Start:   time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2026, 11, 21, 0, 0, 0, 0, time.UTC),
+			AllDay:  true,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, "digest events", events, now); err != nil {
+		t.Fatalf("WriteICS: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:digest events\r\n",
+		"DTSTAMP:20261015T120000Z\r\n",
+		"DTSTART:20261021T190000Z\r\n",
+		"DTEND:20261021T200000Z\r\n",
+		`SUMMARY:Meetup\; talks\, pizza` + "\r\n",
+		"DTSTART;VALUE=DATE:20261120\r\n",
+		"DTEND;VALUE=DATE:20261121\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 2 {
+		t.Errorf("expected 2 events")
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line longer than %d octets: %q", maxLineOctets, line)
+		}
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, `DESCRIPTION:Line one\nLine two `+strings.Repeat("é", 60)) {
+		t.Errorf("expected description to unfold intact")
+	}
+}
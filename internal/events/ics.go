@@ -0,0 +1,94 @@
+// ABOUTME: iCalendar (RFC 5545) writer for extracted events
+// ABOUTME: Emits a VCALENDAR with CRLF line endings, escaped text, and folded long lines
+
+package events
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLineOctets is the RFC 5545 line length limit, excluding the CRLF.
+const maxLineOctets = 75
+
+// WriteICS writes events as an iCalendar document named name. now stamps
+// each event's DTSTAMP.
+func WriteICS(w io.Writer, name string, events []Event, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) {
+		bw.WriteString(fold(s))
+		bw.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//digest//events//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if name != "" {
+		line("X-WR-CALNAME:" + escapeText(name))
+	}
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, ev := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + escapeText(ev.UID))
+		line("DTSTAMP:" + stamp)
+		if ev.AllDay {
+			line("DTSTART;VALUE=DATE:" + ev.Start.Format("20060102"))
+			if !ev.End.IsZero() {
+				line("DTEND;VALUE=DATE:" + ev.End.Format("20060102"))
+			}
+		} else {
+			line("DTSTART:" + ev.Start.UTC().Format("20060102T150405Z"))
+			if !ev.End.IsZero() {
+				line("DTEND:" + ev.End.UTC().Format("20060102T150405Z"))
+			}
+		}
+		line("SUMMARY:" + escapeText(ev.Summary))
+		if ev.Description != "" {
+			line("DESCRIPTION:" + escapeText(ev.Description))
+		}
+		if ev.URL != "" {
+			line("URL:" + ev.URL)
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	return nil
+}
+
+// escapeText escapes a TEXT value: backslashes, commas, semicolons, and newlines.
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// fold splits a content line into 75-octet pieces joined by CRLF and a
+// space, never splitting a UTF-8 sequence.
+func fold(s string) string {
+	if len(s) <= maxLineOctets {
+		return s
+	}
+	var b strings.Builder
+	limit := maxLineOctets
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > limit {
+			b.WriteString("\r\n ")
+			width = 0
+			limit = maxLineOctets - 1 // the leading space counts
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	return b.String()
+}
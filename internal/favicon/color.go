@@ -0,0 +1,85 @@
+// ABOUTME: Reduces a cached icon to a single color for displays that can't draw images
+// ABOUTME: Decodes PNG, GIF, JPEG and PNG-in-ICO icons and averages their opaque pixels
+
+package favicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+)
+
+// Color returns the average color of the icon at path as "#rrggbb", so a
+// terminal can show a feed's mark where it can't show the icon itself.
+// It reports false for icons it can't decode, such as SVG, WebP and
+// bitmap .ico files, and for fully transparent ones.
+func Color(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	if filepath.Ext(path) == ".ico" {
+		if data = icoPNG(data); data == nil {
+			return "", false
+		}
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", false
+	}
+
+	var r, g, b, n uint64
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 0x80 {
+				continue
+			}
+			r, g, b, n = r+uint64(c.R), g+uint64(c.G), b+uint64(c.B), n+1
+		}
+	}
+	if n == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("#%02x%02x%02x", r/n, g/n, b/n), true
+}
+
+// icoPNG returns the largest PNG image embedded in ICO data, or nil if it
+// holds only bitmaps.
+func icoPNG(data []byte) []byte {
+	const headerSize, entrySize = 6, 16
+	if len(data) < headerSize || binary.LittleEndian.Uint16(data[2:]) != 1 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+
+	var best []byte
+	for i := 0; i < count; i++ {
+		start := headerSize + i*entrySize
+		if start+entrySize > len(data) {
+			break
+		}
+		entry := data[start:]
+		size := binary.LittleEndian.Uint32(entry[8:])
+		offset := binary.LittleEndian.Uint32(entry[12:])
+		if uint64(offset)+uint64(size) > uint64(len(data)) {
+			continue
+		}
+		img := data[offset : offset+size]
+		if bytes.HasPrefix(img, pngSignature) && len(img) > len(best) {
+			best = img
+		}
+	}
+	return best
+}
+
+// pngSignature starts every PNG file, including those embedded in ICOs.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
@@ -0,0 +1,79 @@
+// ABOUTME: Tests for reducing cached icons to a single color
+// ABOUTME: Covers PNG files, PNGs embedded in ICOs, transparency, and undecodable icons
+
+package favicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// encodePNG returns a 2x2 PNG with one transparent pixel and three in c.
+func encodePNG(t *testing.T, c color.NRGBA) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.SetNRGBA(0, 0, c)
+	img.SetNRGBA(1, 0, c)
+	img.SetNRGBA(0, 1, c)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// wrapICO wraps a PNG in a single-entry ICO container.
+func wrapICO(pngData []byte) []byte {
+	data := make([]byte, 6+16)
+	binary.LittleEndian.PutUint16(data[2:], 1)
+	binary.LittleEndian.PutUint16(data[4:], 1)
+	binary.LittleEndian.PutUint32(data[6+8:], uint32(len(pngData)))
+	binary.LittleEndian.PutUint32(data[6+12:], uint32(len(data)))
+	return append(data, pngData...)
+}
+
+func writeIcon(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestColor_PNG(t *testing.T) {
+	path := writeIcon(t, "feed.png", encodePNG(t, color.NRGBA{R: 0xff, G: 0x66, A: 0xff}))
+	got, ok := Color(path)
+	if !ok || got != "#ff6600" {
+		t.Errorf("expected #ff6600, got %q, %v", got, ok)
+	}
+}
+
+func TestColor_ICOWithPNG(t *testing.T) {
+	path := writeIcon(t, "feed.ico", wrapICO(encodePNG(t, color.NRGBA{B: 0xcc, A: 0xff})))
+	got, ok := Color(path)
+	if !ok || got != "#0000cc" {
+		t.Errorf("expected #0000cc, got %q, %v", got, ok)
+	}
+}
+
+func TestColor_Undecodable(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"bitmap.ico":      []byte("\x00\x00\x01\x00\x01\x00"),
+		"icon.svg":        []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`),
+		"transparent.png": encodePNG(t, color.NRGBA{}),
+	} {
+		if got, ok := Color(writeIcon(t, name, data)); ok {
+			t.Errorf("%s: expected no color, got %q", name, got)
+		}
+	}
+	if _, ok := Color(filepath.Join(t.TempDir(), "missing.png")); ok {
+		t.Error("expected no color for a missing file")
+	}
+}
@@ -0,0 +1,239 @@
+// ABOUTME: Favicon discovery and on-disk caching for subscribed feeds
+// ABOUTME: Finds a site's icon via <link rel="icon"> or /favicon.ico and stores it per feed ID
+
+package favicon
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harperreed/mdstore"
+	"golang.org/x/net/html"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+// RefreshInterval is how long a cached icon (or a recorded miss) is trusted before refetching.
+const RefreshInterval = 7 * 24 * time.Hour
+
+// missSuffix marks feeds whose site had no usable icon, so we don't retry every sync.
+const missSuffix = ".none"
+
+// ErrNoIcon is returned when a site has no usable icon.
+var ErrNoIcon = errors.New("no favicon found")
+
+// iconExtensions maps detected content types to file extensions.
+var iconExtensions = map[string]string{
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+	"image/png":                ".png",
+	"image/gif":                ".gif",
+	"image/jpeg":               ".jpg",
+	"image/webp":               ".webp",
+	"image/svg+xml":            ".svg",
+}
+
+// maxInlineSize caps the icons DataURL embeds, so a large touch icon
+// doesn't bloat the page it's inlined in.
+const maxInlineSize = 32 << 10
+
+// iconTypes maps cached icon extensions back to their content types.
+var iconTypes = map[string]string{
+	".ico":  "image/x-icon",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".jpg":  "image/jpeg",
+	".webp": "image/webp",
+	".svg":  "image/svg+xml",
+}
+
+// CacheDir returns the icon cache directory inside a profile data directory.
+func CacheDir(profileDir string) string {
+	return filepath.Join(profileDir, "icons")
+}
+
+// Path returns the cached icon file for a feed, or "" if none is cached.
+func Path(dir, feedID string) string {
+	matches, err := filepath.Glob(filepath.Join(dir, feedID+".*"))
+	if err != nil {
+		return ""
+	}
+	for _, m := range matches {
+		if !strings.HasSuffix(m, missSuffix) {
+			return m
+		}
+	}
+	return ""
+}
+
+// DataURL returns the cached icon at path as a data: URL, for pages that
+// must stand alone such as the published blogroll. Icons over 32KB or of an
+// unknown type report false.
+func DataURL(path string) (string, bool) {
+	contentType, ok := iconTypes[filepath.Ext(path)]
+	if !ok {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 || len(data) > maxInlineSize {
+		return "", false
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), true
+}
+
+// NeedsRefresh reports whether the feed's icon is missing or older than RefreshInterval.
+// A recorded miss counts as fresh until it expires.
+func NeedsRefresh(dir, feedID string) bool {
+	matches, err := filepath.Glob(filepath.Join(dir, feedID+".*"))
+	if err != nil || len(matches) == 0 {
+		return true
+	}
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err == nil && time.Since(info.ModTime()) < RefreshInterval {
+			return false
+		}
+	}
+	return true
+}
+
+// Refresh fetches the feed's icon if the cached copy is missing or stale.
+// It returns the cached icon path, which is "" when the site has no icon.
+func Refresh(ctx context.Context, dir, feedID, feedURL string, allowLocalNetwork bool) (string, error) {
+	if !NeedsRefresh(dir, feedID) {
+		return Path(dir, feedID), nil
+	}
+	path, err := Fetch(ctx, dir, feedID, feedURL, allowLocalNetwork)
+	if errors.Is(err, ErrNoIcon) {
+		return "", nil
+	}
+	return path, err
+}
+
+// Fetch downloads the icon for the site hosting feedURL and stores it as <dir>/<feedID>.<ext>.
+// When no icon can be found, a miss marker is recorded and ErrNoIcon is returned.
+// If ctx is canceled first, the cache is left as it was and ctx's error returned.
+func Fetch(ctx context.Context, dir, feedID, feedURL string, allowLocalNetwork bool) (string, error) {
+	parsed, err := url.Parse(feedURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid feed URL: %w", err)
+	}
+	site := &url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: "/"}
+
+	if err := mdstore.EnsureDir(dir); err != nil {
+		return "", fmt.Errorf("failed to create icon directory: %w", err)
+	}
+
+	for _, candidate := range candidateURLs(ctx, site, allowLocalNetwork) {
+		result, err := fetch.Fetch(ctx, candidate, nil, nil, allowLocalNetwork)
+		if err != nil || len(result.Body) == 0 {
+			continue
+		}
+		ext := iconExtension(candidate, result.Body)
+		if ext == "" {
+			continue
+		}
+
+		Remove(dir, feedID)
+		path := filepath.Join(dir, feedID+ext)
+		if err := mdstore.AtomicWrite(path, result.Body); err != nil {
+			return "", fmt.Errorf("failed to write icon: %w", err)
+		}
+		return path, nil
+	}
+
+	// A canceled fetch found nothing because it stopped looking; keep the
+	// cached icon and don't record a miss for it
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	Remove(dir, feedID)
+	if err := mdstore.AtomicWrite(filepath.Join(dir, feedID+missSuffix), nil); err != nil {
+		return "", fmt.Errorf("failed to record missing icon: %w", err)
+	}
+	return "", ErrNoIcon
+}
+
+// Remove deletes any cached icon or miss marker for a feed.
+func Remove(dir, feedID string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, feedID+".*"))
+	for _, m := range matches {
+		_ = os.Remove(m)
+	}
+}
+
+// candidateURLs returns icon URLs declared by the site's homepage, followed by /favicon.ico.
+func candidateURLs(ctx context.Context, site *url.URL, allowLocalNetwork bool) []string {
+	var candidates []string
+	if result, err := fetch.Fetch(ctx, site.String(), nil, nil, allowLocalNetwork); err == nil {
+		candidates = append(candidates, extractIconLinks(result.Body, site)...)
+	}
+	fallback := site.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()
+	return append(candidates, fallback)
+}
+
+// extractIconLinks parses HTML and returns icon URLs from <link rel="icon"> style elements
+func extractIconLinks(htmlBody []byte, baseURL *url.URL) []string {
+	doc, err := html.Parse(strings.NewReader(string(htmlBody)))
+	if err != nil {
+		return nil
+	}
+
+	var icons []string
+	var findLinks func(*html.Node)
+	findLinks = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "link" {
+			var rel, href string
+			for _, attr := range n.Attr {
+				switch attr.Key {
+				case "rel":
+					rel = strings.ToLower(attr.Val)
+				case "href":
+					href = attr.Val
+				}
+			}
+			if href != "" && isIconRel(rel) {
+				if ref, err := url.Parse(href); err == nil {
+					icons = append(icons, baseURL.ResolveReference(ref).String())
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			findLinks(c)
+		}
+	}
+
+	findLinks(doc)
+	return icons
+}
+
+// isIconRel checks if a rel attribute value names an icon
+func isIconRel(rel string) bool {
+	for _, token := range strings.Fields(rel) {
+		if token == "icon" || token == "apple-touch-icon" {
+			return true
+		}
+	}
+	return false
+}
+
+// iconExtension returns a file extension for icon data, or "" if it isn't an image.
+func iconExtension(iconURL string, body []byte) string {
+	contentType := http.DetectContentType(body)
+	if ext, ok := iconExtensions[contentType]; ok {
+		return ext
+	}
+	// SVG sniffs as text/xml or text/plain, so fall back to the URL and content
+	if strings.HasSuffix(strings.ToLower(iconURL), ".svg") && strings.Contains(string(body), "<svg") {
+		return ".svg"
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+// ABOUTME: Tests for favicon discovery and caching
+// ABOUTME: Uses httptest servers to exercise link-based icons, /favicon.ico fallback, and misses
+
+package favicon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pngIcon is a minimal PNG header, enough for content sniffing.
+var pngIcon = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestFetch_LinkIcon(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><link rel="shortcut icon" href="/static/icon.png"></head></html>`))
+		case "/static/icon.png":
+			w.Write(pngIcon)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path, err := Fetch(context.Background(), dir, "feed-1", server.URL+"/feed.xml", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Base(path) != "feed-1.png" {
+		t.Errorf("expected feed-1.png, got %s", path)
+	}
+	if got := Path(dir, "feed-1"); got != path {
+		t.Errorf("expected Path to return %s, got %s", path, got)
+	}
+	if NeedsRefresh(dir, "feed-1") {
+		t.Error("expected freshly fetched icon not to need refresh")
+	}
+}
+
+func TestFetch_FallbackFaviconICO(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>No icon links</title></head></html>`))
+		case "/favicon.ico":
+			w.Write([]byte("\x00\x00\x01\x00\x01\x00"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path, err := Fetch(context.Background(), dir, "feed-2", server.URL+"/rss", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Ext(path) != ".ico" {
+		t.Errorf("expected .ico icon, got %s", path)
+	}
+}
+
+func TestFetch_NoIconRecordsMiss(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	_, err := Fetch(context.Background(), dir, "feed-3", server.URL+"/feed", false)
+	if !errors.Is(err, ErrNoIcon) {
+		t.Fatalf("expected ErrNoIcon, got %v", err)
+	}
+	if Path(dir, "feed-3") != "" {
+		t.Error("expected no icon path for a miss")
+	}
+	if NeedsRefresh(dir, "feed-3") {
+		t.Error("expected a recent miss to suppress refetching")
+	}
+
+	// Refresh treats a miss as success with no icon
+	path, err := Refresh(context.Background(), dir, "feed-3", server.URL+"/feed", false)
+	if err != nil || path != "" {
+		t.Errorf("expected empty path and nil error, got %q, %v", path, err)
+	}
+}
+
+func TestNeedsRefresh_Stale(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "feed-4.png")
+	if err := os.WriteFile(path, pngIcon, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if NeedsRefresh(dir, "feed-4") {
+		t.Error("expected new icon to be fresh")
+	}
+
+	old := time.Now().Add(-RefreshInterval - time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if !NeedsRefresh(dir, "feed-4") {
+		t.Error("expected old icon to need refresh")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "feed-5.ico")
+	if err := os.WriteFile(path, []byte("icon"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	Remove(dir, "feed-5")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected icon to be removed")
+	}
+}
+
+func TestFetch_CanceledKeepsCachedIcon(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pngIcon)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "feed-6.png")
+	if err := os.WriteFile(path, pngIcon, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Fetch(ctx, dir, "feed-6", server.URL+"/feed", false); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got := Path(dir, "feed-6"); got != path {
+		t.Errorf("expected cached icon %s to survive, got %q", path, got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "feed-6"+missSuffix)); !os.IsNotExist(err) {
+		t.Error("expected no miss recorded for a canceled fetch")
+	}
+}
+
+func TestDataURL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "feed-7.png")
+	if err := os.WriteFile(path, pngIcon, 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := DataURL(path)
+	if !ok || !strings.HasPrefix(got, "data:image/png;base64,") {
+		t.Errorf("expected a PNG data URL, got %q, %v", got, ok)
+	}
+
+	big := filepath.Join(dir, "feed-8.png")
+	if err := os.WriteFile(big, make([]byte, maxInlineSize+1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := DataURL(big); ok {
+		t.Error("expected an icon over the inline limit to be refused")
+	}
+}
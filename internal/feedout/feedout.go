@@ -0,0 +1,116 @@
+// ABOUTME: Writes RSS 2.0 feeds of digest's own output
+// ABOUTME: Used for personal podcast feeds and for re-publishing curated entries
+
+package feedout
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Channel is an RSS channel and its items.
+type Channel struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []Item
+}
+
+// Item is one RSS item. Enclosure is optional.
+type Item struct {
+	Title       string
+	Link        string
+	GUID        string
+	Description string
+	Author      string
+	Published   time.Time
+	Enclosure   *Enclosure
+}
+
+// Enclosure attaches a media file, such as an audio briefing, to an item.
+type Enclosure struct {
+	URL    string
+	Length int64
+	Type   string
+}
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Generator     string    `xml:"generator"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	GUID        *rssGUID      `xml:"guid,omitempty"`
+	Description string        `xml:"description,omitempty"`
+	Author      string        `xml:"author,omitempty"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// Write encodes ch as an RSS 2.0 document.
+func Write(w io.Writer, ch Channel) error {
+	doc := rssDoc{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         ch.Title,
+			Link:          ch.Link,
+			Description:   ch.Description,
+			Generator:     "digest",
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, it := range ch.Items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			Description: it.Description,
+			Author:      it.Author,
+		}
+		if it.GUID != "" {
+			item.GUID = &rssGUID{Value: it.GUID}
+		}
+		if !it.Published.IsZero() {
+			item.PubDate = it.Published.UTC().Format(time.RFC1123Z)
+		}
+		if it.Enclosure != nil {
+			item.Enclosure = &rssEnclosure{URL: it.Enclosure.URL, Length: it.Enclosure.Length, Type: it.Enclosure.Type}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("write feed: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encode feed: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,58 @@
+// ABOUTME: Tests for RSS output
+// ABOUTME: Round-trips generated feeds through the same parser digest uses for subscriptions
+
+package feedout
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	published := time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC)
+	ch := Channel{
+		Title:       "My Digest",
+		Link:        "http://localhost:8080/",
+		Description: "Curated by digest",
+		Items: []Item{
+			{
+				Title:       "Morning briefing & news",
+				GUID:        "briefing-2026-10-15",
+				Description: "<p>Five stories</p>",
+				Published:   published,
+				Enclosure:   &Enclosure{URL: "http://localhost:8080/audio/digest-2026-10-15.mp3", Length: 1234, Type: "audio/mpeg"},
+			},
+			{Title: "Plain item", Link: "https://example.com/post"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, ch); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	feed, err := gofeed.NewParser().ParseString(buf.String())
+	if err != nil {
+		t.Fatalf("generated feed doesn't parse: %v\n%s", err, buf.String())
+	}
+	if feed.Title != "My Digest" || len(feed.Items) != 2 {
+		t.Fatalf("unexpected feed: %q with %d items", feed.Title, len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	if first.Title != "Morning briefing & news" || first.GUID != "briefing-2026-10-15" {
+		t.Errorf("unexpected first item: %q %q", first.Title, first.GUID)
+	}
+	if first.PublishedParsed == nil || !first.PublishedParsed.Equal(published) {
+		t.Errorf("expected published %v, got %v", published, first.PublishedParsed)
+	}
+	if len(first.Enclosures) != 1 || first.Enclosures[0].Type != "audio/mpeg" || first.Enclosures[0].Length != "1234" {
+		t.Errorf("unexpected enclosures: %+v", first.Enclosures)
+	}
+	if feed.Items[1].Link != "https://example.com/post" {
+		t.Errorf("expected link to round-trip, got %q", feed.Items[1].Link)
+	}
+}
@@ -0,0 +1,104 @@
+// ABOUTME: Feed URL canonicalization and near-duplicate detection
+// ABOUTME: Cleans URLs at add time and matches http/https, www, and trailing-slash variants of the same feed
+
+package feedurl
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only identify where a link was shared.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"igshid":  true,
+	"yclid":   true,
+}
+
+// isTracking reports whether a query parameter is a tracking parameter.
+func isTracking(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// Canonical cleans a feed URL without changing which document it fetches:
+// the scheme and host are lowercased, default ports and fragments dropped,
+// and tracking parameters removed. Remaining query parameters are sorted.
+// The path, including any trailing slash, is left alone since servers
+// differ on whether /feed and /feed/ are the same.
+func Canonical(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("invalid feed URL: %w", err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("feed URL must use http or https scheme, got: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("feed URL must have a host")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u.Host = host
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for name := range query {
+			if isTracking(name) {
+				query.Del(name)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String(), nil
+}
+
+// Key identifies a feed regardless of scheme, a leading "www.", a trailing
+// slash, or tracking parameters. Two URLs with the same key are almost
+// certainly the same feed. Unparseable URLs are their own key.
+func Key(raw string) string {
+	canonical, err := Canonical(raw)
+	if err != nil {
+		return raw
+	}
+	u, _ := url.Parse(canonical)
+	host := strings.TrimPrefix(u.Host, "www.")
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	key := host + path
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// FindDuplicate returns the first of existing that is a near-duplicate of
+// raw, or "" if none is.
+func FindDuplicate(raw string, existing []string) string {
+	key := Key(raw)
+	for _, candidate := range existing {
+		if Key(candidate) == key {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Tests for feed URL canonicalization and near-duplicate detection
+// ABOUTME: Covers tracking parameter removal, host and port cleanup, and variant matching
+
+package feedurl
+
+import "testing"
+
+func TestCanonical(t *testing.T) {
+	cases := map[string]string{
+		"https://Example.COM/feed.xml":                                 "https://example.com/feed.xml",
+		"HTTP://example.com:80/feed/":                                  "http://example.com/feed/",
+		"https://example.com:443/feed":                                 "https://example.com/feed",
+		"https://example.com:8443/feed":                                "https://example.com:8443/feed",
+		"https://example.com":                                          "https://example.com/",
+		"https://example.com/feed?utm_source=tw&utm_medium=social":     "https://example.com/feed",
+		"https://example.com/feed?fbclid=abc&format=rss#top":           "https://example.com/feed?format=rss",
+		"https://example.com/rss?tag=go&cat=tech":                      "https://example.com/rss?cat=tech&tag=go",
+		"  https://example.com/feed.xml  ":                             "https://example.com/feed.xml",
+		"https://example.com/Feed.XML?UTM_Campaign=spring&id=7&gclid=": "https://example.com/Feed.XML?id=7",
+	}
+	for in, want := range cases {
+		got, err := Canonical(in)
+		if err != nil {
+			t.Errorf("Canonical(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, bad := range []string{"ftp://example.com/feed", "example.com/feed", "https:///feed"} {
+		if _, err := Canonical(bad); err == nil {
+			t.Errorf("expected Canonical(%q) to fail", bad)
+		}
+	}
+}
+
+func TestKeyMatchesVariants(t *testing.T) {
+	variants := []string{
+		"http://x.com/feed",
+		"https://x.com/feed/",
+		"https://www.x.com/feed",
+		"https://WWW.X.com/feed?utm_source=newsletter",
+	}
+	want := Key(variants[0])
+	for _, v := range variants[1:] {
+		if got := Key(v); got != want {
+			t.Errorf("Key(%q) = %q, want %q", v, got, want)
+		}
+	}
+
+	distinct := []string{
+		"https://x.com/feed.xml",
+		"https://blog.x.com/feed",
+		"https://x.com/feed?category=go",
+		"https://x.com:8080/feed",
+	}
+	for _, d := range distinct {
+		if Key(d) == want {
+			t.Errorf("expected %q to differ from %q", d, variants[0])
+		}
+	}
+}
+
+func TestFindDuplicate(t *testing.T) {
+	existing := []string{"https://a.com/rss", "http://www.x.com/feed/"}
+	if got := FindDuplicate("https://x.com/feed", existing); got != "http://www.x.com/feed/" {
+		t.Errorf("expected the www/http variant to be found, got %q", got)
+	}
+	if got := FindDuplicate("https://b.com/rss", existing); got != "" {
+		t.Errorf("expected no duplicate, got %q", got)
+	}
+}
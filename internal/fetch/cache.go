@@ -0,0 +1,198 @@
+// ABOUTME: Per-process cache of DNS answers and validated response bodies, with hit-rate metrics
+// ABOUTME: Keeps force-syncs and discovery bursts against one host from repeating lookups and downloads
+
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// dnsCacheTTL is how long a host's addresses are reused. The system
+	// resolver doesn't report record TTLs, so this is kept short.
+	dnsCacheTTL = 5 * time.Minute
+	// maxCachedBytes bounds the bodies kept for revalidation; the oldest
+	// are dropped first.
+	maxCachedBytes = 32 * 1024 * 1024
+)
+
+// CacheStats counts how often the cache saved work. A body hit is a request
+// without validators of its own that the server answered 304 Not Modified
+// to the cached copy's validators, so the body wasn't downloaded again.
+type CacheStats struct {
+	DNSHits    int
+	DNSMisses  int
+	BodyHits   int
+	BodyMisses int
+}
+
+// Sub returns the counts accumulated since prev was taken.
+func (s CacheStats) Sub(prev CacheStats) CacheStats {
+	return CacheStats{
+		DNSHits:    s.DNSHits - prev.DNSHits,
+		DNSMisses:  s.DNSMisses - prev.DNSMisses,
+		BodyHits:   s.BodyHits - prev.BodyHits,
+		BodyMisses: s.BodyMisses - prev.BodyMisses,
+	}
+}
+
+// DNSHitRate is the share of host lookups answered from the cache.
+func (s CacheStats) DNSHitRate() float64 {
+	return rate(s.DNSHits, s.DNSMisses)
+}
+
+// BodyHitRate is the share of cacheable requests answered from the cache.
+func (s CacheStats) BodyHitRate() float64 {
+	return rate(s.BodyHits, s.BodyMisses)
+}
+
+func rate(hits, misses int) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+type dnsEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+type bodyEntry struct {
+	body         []byte
+	etag         string
+	lastModified string
+}
+
+// Cache holds DNS answers per host and the last body fetched from each URL
+// along with its validators.
+type Cache struct {
+	mu     sync.Mutex
+	hosts  map[string]dnsEntry
+	bodies map[string]*bodyEntry
+	order  []string // body keys, oldest first
+	size   int
+	stats  CacheStats
+}
+
+// NewCache creates an empty cache.
+func NewCache() *Cache {
+	return &Cache{
+		hosts:  make(map[string]dnsEntry),
+		bodies: make(map[string]*bodyEntry),
+	}
+}
+
+// DefaultCache is the process-wide cache used by every fetch.
+var DefaultCache = NewCache()
+
+// Stats returns the counts of the process-wide cache.
+func Stats() CacheStats {
+	return DefaultCache.Stats()
+}
+
+// Stats returns the cache's counts so far.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// lookupIP resolves host, reusing an answer younger than dnsCacheTTL.
+// Failed lookups are not cached.
+func (c *Cache) lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	c.mu.Lock()
+	if entry, ok := c.hosts[host]; ok && time.Now().Before(entry.expires) {
+		c.stats.DNSHits++
+		c.mu.Unlock()
+		return entry.ips, nil
+	}
+	c.stats.DNSMisses++
+	c.mu.Unlock()
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.hosts[host] = dnsEntry{ips: ips, expires: time.Now().Add(dnsCacheTTL)}
+	c.mu.Unlock()
+	return ips, nil
+}
+
+// validators returns the cached body for key, if any, to revalidate.
+func (c *Cache) validators(key string) *bodyEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bodies[key]
+}
+
+// hit records that a cached body was revalidated and reused.
+func (c *Cache) hit() {
+	c.mu.Lock()
+	c.stats.BodyHits++
+	c.mu.Unlock()
+}
+
+// store remembers a freshly downloaded body under key when it came with
+// validators, and records the miss.
+func (c *Cache) store(key string, body []byte, etag, lastModified string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.BodyMisses++
+	if old, ok := c.bodies[key]; ok {
+		c.size -= len(old.body)
+		delete(c.bodies, key)
+		for i, k := range c.order {
+			if k == key {
+				c.order = append(c.order[:i], c.order[i+1:]...)
+				break
+			}
+		}
+	}
+	if (etag == "" && lastModified == "") || len(body) > maxCachedBytes {
+		return
+	}
+	c.bodies[key] = &bodyEntry{body: body, etag: etag, lastModified: lastModified}
+	c.order = append(c.order, key)
+	c.size += len(body)
+	for c.size > maxCachedBytes {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		c.size -= len(c.bodies[oldest].body)
+		delete(c.bodies, oldest)
+	}
+}
+
+// dialContext dials addr through the DNS cache, trying each address of the
+// host in turn.
+func dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		ips, err := DefaultCache.lookupIP(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no addresses for %s", host)
+		}
+		var firstErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return nil, firstErr
+	}
+}
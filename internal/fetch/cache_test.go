@@ -0,0 +1,135 @@
+// ABOUTME: Tests for the fetch cache of DNS answers and revalidated bodies.
+// ABOUTME: Checks that unconditional refetches reuse a 304-validated body and count the hits.
+
+package fetch_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+func etagServer(t *testing.T, downloads *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(downloads, 1)
+		w.Write([]byte("<rss>cached content</rss>"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch_ReusesRevalidatedBody(t *testing.T) {
+	var downloads int32
+	server := etagServer(t, &downloads)
+	before := fetch.Stats()
+
+	for i := 0; i < 2; i++ {
+		result, err := fetch.Fetch(context.Background(), server.URL, nil, nil, false)
+		if err != nil {
+			t.Fatalf("fetch %d: unexpected error: %v", i, err)
+		}
+		if result.NotModified {
+			t.Errorf("fetch %d: expected the body, got NotModified", i)
+		}
+		if string(result.Body) != "<rss>cached content</rss>" {
+			t.Errorf("fetch %d: unexpected body %q", i, result.Body)
+		}
+		if result.ETag != `"v1"` {
+			t.Errorf("fetch %d: expected ETag \"v1\", got %q", i, result.ETag)
+		}
+	}
+
+	if downloads != 1 {
+		t.Errorf("expected 1 download, got %d", downloads)
+	}
+	stats := fetch.Stats().Sub(before)
+	if stats.BodyHits != 1 || stats.BodyMisses != 1 {
+		t.Errorf("expected 1 body hit and 1 miss, got %+v", stats)
+	}
+	if rate := stats.BodyHitRate(); rate != 0.5 {
+		t.Errorf("expected body hit rate 0.5, got %v", rate)
+	}
+}
+
+func TestFetch_CallerValidatorsStillNotModified(t *testing.T) {
+	var downloads int32
+	server := etagServer(t, &downloads)
+
+	if _, err := fetch.Fetch(context.Background(), server.URL, nil, nil, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	etag := `"v1"`
+	result, err := fetch.Fetch(context.Background(), server.URL, &etag, nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.NotModified {
+		t.Error("expected NotModified=true when the caller sent its own ETag")
+	}
+	if len(result.Body) != 0 {
+		t.Errorf("expected empty body, got %d bytes", len(result.Body))
+	}
+}
+
+func TestFetch_CachesByCredentials(t *testing.T) {
+	var downloads int32
+	server := etagServer(t, &downloads)
+
+	for _, user := range []string{"alice", "bob"} {
+		creds := &fetch.Credentials{Username: user, Password: "secret"}
+		if _, err := fetch.FetchWithCredentials(context.Background(), server.URL, nil, nil, false, creds); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if downloads != 2 {
+		t.Errorf("expected each user's fetch to download, got %d downloads", downloads)
+	}
+}
+
+func TestFetch_CachesDNS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<rss></rss>"))
+	}))
+	defer server.Close()
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	before := fetch.Stats()
+
+	for i := 0; i < 2; i++ {
+		if _, err := fetch.Fetch(context.Background(), url, nil, nil, false); err != nil {
+			t.Fatalf("fetch %d: unexpected error: %v", i, err)
+		}
+	}
+
+	stats := fetch.Stats().Sub(before)
+	if stats.DNSHits == 0 {
+		t.Errorf("expected cached DNS lookups, got %+v", stats)
+	}
+	if stats.DNSMisses > 1 {
+		t.Errorf("expected localhost to be resolved at most once, got %+v", stats)
+	}
+}
+
+func TestCacheStats_HitRates(t *testing.T) {
+	var empty fetch.CacheStats
+	if empty.DNSHitRate() != 0 || empty.BodyHitRate() != 0 {
+		t.Error("expected zero hit rates with no lookups")
+	}
+	stats := fetch.CacheStats{DNSHits: 3, DNSMisses: 1, BodyHits: 1, BodyMisses: 3}
+	if stats.DNSHitRate() != 0.75 {
+		t.Errorf("expected DNS hit rate 0.75, got %v", stats.DNSHitRate())
+	}
+	if stats.BodyHitRate() != 0.25 {
+		t.Errorf("expected body hit rate 0.25, got %v", stats.BodyHitRate())
+	}
+}
@@ -0,0 +1,77 @@
+// ABOUTME: Detects bot-protection challenge pages (Cloudflare and similar) in HTTP responses
+// ABOUTME: Lets fetches fail with a clear "blocked by bot protection" error instead of a parse failure
+
+package fetch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrBotProtection is returned when a site answers with a bot-protection
+// challenge, such as Cloudflare's "Just a moment..." page, that a feed
+// reader can't pass.
+var ErrBotProtection = errors.New("blocked by bot protection")
+
+// maxChallengeSize is how much of an error response is read to look for
+// challenge markers.
+const maxChallengeSize = 64 * 1024
+
+// challengeMarkers are fragments of challenge pages, lowercased, with the
+// service each one identifies.
+var challengeMarkers = []struct {
+	marker   string
+	provider string
+}{
+	{"cf-chl-", "Cloudflare"},
+	{"challenge-platform", "Cloudflare"},
+	{"<title>just a moment...</title>", "Cloudflare"},
+	{"<title>attention required! | cloudflare</title>", "Cloudflare"},
+	{"ddos-guard", "DDoS-Guard"},
+	{"sucuri website firewall", "Sucuri"},
+	{"_incapsula_resource", "Imperva"},
+	{"enable javascript and cookies to continue", "a JavaScript challenge"},
+}
+
+// ChallengePage returns the bot-protection service whose challenge page body
+// is, or "" if it doesn't look like one.
+func ChallengePage(body []byte) string {
+	lower := bytes.ToLower(body)
+	for _, m := range challengeMarkers {
+		if bytes.Contains(lower, []byte(m.marker)) {
+			return m.provider
+		}
+	}
+	return ""
+}
+
+// challengeResponse returns the bot-protection service behind an error
+// response, or "". Only statuses challenges are served with are considered,
+// and only the start of the body is read.
+func challengeResponse(resp *http.Response) string {
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	default:
+		return ""
+	}
+	if resp.Header.Get("Cf-Mitigated") == "challenge" {
+		return "Cloudflare"
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxChallengeSize))
+	return ChallengePage(body)
+}
+
+// setBrowserHeaders makes a request look like it comes from a desktop
+// browser. Some bot protection turns away anything that identifies itself
+// as a feed reader but lets browsers through without a challenge.
+func setBrowserHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", strings.Join([]string{
+		"text/html", "application/xhtml+xml", "application/xml;q=0.9",
+		"application/rss+xml;q=0.9", "application/atom+xml;q=0.9", "*/*;q=0.8",
+	}, ","))
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+}
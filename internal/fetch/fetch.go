@@ -21,10 +21,28 @@ type Result struct {
 	ETag         string
 	LastModified string
 	NotModified  bool
+	// Truncated reports that ReadBody kept only part of the response.
+	Truncated bool
+}
+
+// Credentials holds HTTP basic auth credentials for protected feeds.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// RequestOptions adjusts how a URL is requested.
+type RequestOptions struct {
+	Credentials *Credentials // HTTP basic auth, sent when non-nil
+	Browser     bool         // Send browser-like headers instead of identifying as digest
+	// ReadBody, when set, reads the response body in place of the 10MB
+	// limit, and may keep only part of it. It must bound what it reads.
+	ReadBody func(io.Reader) (body []byte, truncated bool, err error)
 }
 
 var httpClient = &http.Client{
-	Timeout: 30 * time.Second,
+	Timeout:   30 * time.Second,
+	Transport: Transport(),
 }
 
 // isPrivateIP checks if an IP address is in a private range (excluding loopback for tests).
@@ -36,6 +54,24 @@ func isPrivateIP(ip net.IP) bool {
 	return ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
 }
 
+// checkPublicHost rejects hosts that resolve to a private IP range.
+func checkPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isPrivateIP(ip) {
+			return fmt.Errorf("access to private IP ranges is not allowed")
+		}
+		return nil
+	}
+	if ips, err := DefaultCache.lookupIP(ctx, host); err == nil {
+		for _, ip := range ips {
+			if isPrivateIP(ip) {
+				return fmt.Errorf("access to private IP ranges is not allowed")
+			}
+		}
+	}
+	return nil
+}
+
 // Fetch retrieves a URL with optional conditional request headers.
 // If etag is provided, sets If-None-Match header.
 // If lastModified is provided, sets If-Modified-Since header.
@@ -43,6 +79,22 @@ func isPrivateIP(ip net.IP) bool {
 // Returns error for non-200/304 status codes.
 // Includes SSRF protection by blocking private IP ranges and DoS protection via response size limit.
 func Fetch(ctx context.Context, urlStr string, etag, lastModified *string, allowLocalNetwork bool) (*Result, error) {
+	return FetchWithCredentials(ctx, urlStr, etag, lastModified, allowLocalNetwork, nil)
+}
+
+// FetchWithCredentials is like Fetch but sends HTTP basic auth when creds is non-nil.
+func FetchWithCredentials(ctx context.Context, urlStr string, etag, lastModified *string, allowLocalNetwork bool, creds *Credentials) (*Result, error) {
+	return FetchWith(ctx, urlStr, etag, lastModified, allowLocalNetwork, RequestOptions{Credentials: creds})
+}
+
+// FetchWith is like Fetch with the given request options. A response that
+// is a bot-protection challenge fails with ErrBotProtection.
+//
+// Bodies that come with validators are kept in DefaultCache. A request
+// without validators of its own, such as a forced sync or discovery, sends
+// the cached copy's, and a 304 answer returns the cached body as if it had
+// been downloaded.
+func FetchWith(ctx context.Context, urlStr string, etag, lastModified *string, allowLocalNetwork bool, opts RequestOptions) (*Result, error) {
 	// Parse URL for SSRF protection
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
@@ -51,12 +103,8 @@ func Fetch(ctx context.Context, urlStr string, etag, lastModified *string, allow
 
 	// SSRF protection: block private IP ranges (unless explicitly allowed)
 	if !allowLocalNetwork {
-		if ips, err := net.LookupIP(parsedURL.Hostname()); err == nil {
-			for _, ip := range ips {
-				if isPrivateIP(ip) {
-					return nil, fmt.Errorf("access to private IP ranges is not allowed")
-				}
-			}
+		if err := checkPublicHost(ctx, parsedURL.Hostname()); err != nil {
+			return nil, err
 		}
 	}
 
@@ -65,7 +113,15 @@ func Fetch(ctx context.Context, urlStr string, etag, lastModified *string, allow
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "digest/1.0 (RSS reader)")
+	if opts.Browser {
+		setBrowserHeaders(req)
+	} else {
+		req.Header.Set("User-Agent", "digest/1.0 (RSS reader)")
+	}
+
+	if opts.Credentials != nil {
+		req.SetBasicAuth(opts.Credentials.Username, opts.Credentials.Password)
+	}
 
 	if etag != nil && *etag != "" {
 		req.Header.Set("If-None-Match", *etag)
@@ -75,6 +131,22 @@ func Fetch(ctx context.Context, urlStr string, etag, lastModified *string, allow
 		req.Header.Set("If-Modified-Since", *lastModified)
 	}
 
+	cacheKey := urlStr
+	if opts.Credentials != nil {
+		cacheKey += "\x00" + opts.Credentials.Username
+	}
+	var cached *bodyEntry
+	if req.Header.Get("If-None-Match") == "" && req.Header.Get("If-Modified-Since") == "" {
+		if cached = DefaultCache.validators(cacheKey); cached != nil {
+			if cached.etag != "" {
+				req.Header.Set("If-None-Match", cached.etag)
+			}
+			if cached.lastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.lastModified)
+			}
+		}
+	}
+
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch URL: %w", err)
@@ -83,6 +155,14 @@ func Fetch(ctx context.Context, urlStr string, etag, lastModified *string, allow
 
 	// Handle 304 Not Modified
 	if resp.StatusCode == http.StatusNotModified {
+		if cached != nil {
+			DefaultCache.hit()
+			return &Result{
+				Body:         cached.body,
+				ETag:         cached.etag,
+				LastModified: cached.lastModified,
+			}, nil
+		}
 		return &Result{
 			NotModified: true,
 		}, nil
@@ -90,25 +170,104 @@ func Fetch(ctx context.Context, urlStr string, etag, lastModified *string, allow
 
 	// Handle non-200 status
 	if resp.StatusCode != http.StatusOK {
+		if provider := challengeResponse(resp); provider != "" {
+			return nil, fmt.Errorf("%w (%s, HTTP %d)", ErrBotProtection, provider, resp.StatusCode)
+		}
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	// Read response body with DoS protection (10MB limit)
-	limitedReader := io.LimitReader(resp.Body, MaxResponseSize+1)
+	body, truncated, err := readBody(resp.Body, opts.ReadBody)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		NotModified:  false,
+		Truncated:    truncated,
+	}
+	// A cut body can't stand in for the whole response on a later 304
+	if !truncated {
+		DefaultCache.store(cacheKey, body, result.ETag, result.LastModified)
+	}
+	return result, nil
+}
+
+// readBody reads a response body with read, or with DoS protection (10MB
+// limit) when read is nil.
+func readBody(r io.Reader, read func(io.Reader) ([]byte, bool, error)) ([]byte, bool, error) {
+	if read != nil {
+		body, truncated, err := read(r)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return body, truncated, nil
+	}
+
+	limitedReader := io.LimitReader(r, MaxResponseSize+1)
 	body, err := io.ReadAll(limitedReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, false, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// Check if response was truncated (exceeded limit)
 	if int64(len(body)) > MaxResponseSize {
-		return nil, fmt.Errorf("response too large (exceeds %d bytes)", MaxResponseSize)
+		return nil, false, fmt.Errorf("response too large (exceeds %d bytes)", MaxResponseSize)
 	}
+	return body, false, nil
+}
 
-	return &Result{
-		Body:         body,
-		ETag:         resp.Header.Get("ETag"),
-		LastModified: resp.Header.Get("Last-Modified"),
-		NotModified:  false,
-	}, nil
+// Probe checks whether a URL still resolves and returns the final HTTP status
+// code after redirects. It sends a HEAD request and retries with GET when the
+// server doesn't support HEAD; the body is never read. It has the same SSRF
+// protection as Fetch.
+func Probe(ctx context.Context, urlStr string, allowLocalNetwork bool) (int, error) {
+	return probeAs(ctx, urlStr, allowLocalNetwork, false)
+}
+
+// probeAs is Probe, sending browser-like headers when browser is set.
+func probeAs(ctx context.Context, urlStr string, allowLocalNetwork, browser bool) (int, error) {
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return 0, fmt.Errorf("unsupported URL scheme %q", parsedURL.Scheme)
+	}
+	if !allowLocalNetwork {
+		if err := checkPublicHost(ctx, parsedURL.Hostname()); err != nil {
+			return 0, err
+		}
+	}
+
+	code, err := probe(ctx, http.MethodHead, urlStr, browser)
+	if err != nil {
+		return 0, err
+	}
+	// Some servers reject HEAD outright; only a GET answer is conclusive
+	if code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented || code == http.StatusForbidden {
+		return probe(ctx, http.MethodGet, urlStr, browser)
+	}
+	return code, nil
+}
+
+func probe(ctx context.Context, method, urlStr string, browser bool) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if browser {
+		setBrowserHeaders(req)
+	} else {
+		req.Header.Set("User-Agent", "digest/1.0 (RSS reader)")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
 }
@@ -5,8 +5,11 @@ package fetch_test
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/harper/digest/internal/fetch"
@@ -196,6 +199,38 @@ func TestFetch_LargeResponseBody(t *testing.T) {
 	}
 }
 
+func TestFetchWith_ReadBody(t *testing.T) {
+	var validators []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validators = append(validators, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"cut"`)
+		w.Write([]byte("whole body"))
+	}))
+	defer server.Close()
+
+	firstWord := func(r io.Reader) ([]byte, bool, error) {
+		body, err := io.ReadAll(r)
+		before, _, cut := strings.Cut(string(body), " ")
+		return []byte(before), cut, err
+	}
+	result, err := fetch.FetchWith(context.Background(), server.URL, nil, nil, false, fetch.RequestOptions{ReadBody: firstWord})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Body) != "whole" || !result.Truncated {
+		t.Errorf("body = %q, truncated = %v; want the reader's cut", result.Body, result.Truncated)
+	}
+
+	// The cut body isn't cached, so a later fetch doesn't ask for a 304
+	result, err = fetch.Fetch(context.Background(), server.URL, nil, nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Body) != "whole body" || validators[1] != "" {
+		t.Errorf("second fetch sent If-None-Match %q and got %q; want no validators and the whole body", validators[1], result.Body)
+	}
+}
+
 // HTTP Status Code Tests
 
 func TestFetch_304WithCachingHeaders(t *testing.T) {
@@ -459,3 +494,136 @@ func TestResultStruct(t *testing.T) {
 		t.Error("expected NotModified true")
 	}
 }
+
+func TestFetchWithCredentials_BasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("<rss>private</rss>"))
+	}))
+	defer server.Close()
+
+	if _, err := fetch.Fetch(context.Background(), server.URL, nil, nil, false); err == nil {
+		t.Error("expected error without credentials")
+	}
+
+	creds := &fetch.Credentials{Username: "alice", Password: "secret"}
+	result, err := fetch.FetchWithCredentials(context.Background(), server.URL, nil, nil, false, creds)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Body) != "<rss>private</rss>" {
+		t.Errorf("expected private body, got %q", string(result.Body))
+	}
+}
+
+func TestFetch_BotProtection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mitigated":
+			w.Header().Set("Cf-Mitigated", "challenge")
+			w.WriteHeader(http.StatusForbidden)
+		case "/interstitial":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<html><head><title>Just a moment...</title></head><body></body></html>`))
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Forbidden"))
+		case "/browsers-only":
+			if !strings.HasPrefix(r.Header.Get("User-Agent"), "Mozilla/") || r.Header.Get("Accept-Language") == "" {
+				w.Header().Set("Cf-Mitigated", "challenge")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte("<rss>ok</rss>"))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	for _, path := range []string{"/mitigated", "/interstitial"} {
+		_, err := fetch.Fetch(ctx, server.URL+path, nil, nil, false)
+		if !errors.Is(err, fetch.ErrBotProtection) {
+			t.Errorf("%s: expected ErrBotProtection, got %v", path, err)
+		} else if !strings.Contains(err.Error(), "Cloudflare") {
+			t.Errorf("%s: expected the error to name Cloudflare, got %v", path, err)
+		}
+	}
+
+	_, err := fetch.Fetch(ctx, server.URL+"/forbidden", nil, nil, false)
+	if err == nil || errors.Is(err, fetch.ErrBotProtection) {
+		t.Errorf("expected a plain 403 error, got %v", err)
+	}
+
+	if _, err := fetch.Fetch(ctx, server.URL+"/browsers-only", nil, nil, false); !errors.Is(err, fetch.ErrBotProtection) {
+		t.Errorf("expected digest's own user agent to be challenged, got %v", err)
+	}
+	result, err := fetch.FetchWith(ctx, server.URL+"/browsers-only", nil, nil, false, fetch.RequestOptions{Browser: true})
+	if err != nil {
+		t.Fatalf("expected browser headers to get through, got %v", err)
+	}
+	if string(result.Body) != "<rss>ok</rss>" {
+		t.Errorf("unexpected body %q", result.Body)
+	}
+}
+
+func TestChallengePage(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`<script src="/cdn-cgi/challenge-platform/h/b/orchestrate/chl_page/v1"></script>`, "Cloudflare"},
+		{`<p>Checking your browser... DDoS-Guard</p>`, "DDoS-Guard"},
+		{`<p>Enable JavaScript and cookies to continue</p>`, "a JavaScript challenge"},
+		{`<rss><channel><title>Just a moment</title></channel></rss>`, ""},
+	}
+	for _, tt := range tests {
+		if got := fetch.ChallengePage([]byte(tt.body)); got != tt.want {
+			t.Errorf("ChallengePage(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestProbe_HeadFallsBackToGet(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		switch {
+		case r.URL.Path == "/gone":
+			w.WriteHeader(http.StatusGone)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	code, err := fetch.Probe(context.Background(), server.URL+"/post", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code != http.StatusOK {
+		t.Errorf("expected 200 after GET fallback, got %d", code)
+	}
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodGet {
+		t.Errorf("expected HEAD then GET, got %v", methods)
+	}
+
+	code, err = fetch.Probe(context.Background(), server.URL+"/gone", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code != http.StatusGone {
+		t.Errorf("expected 410, got %d", code)
+	}
+}
+
+func TestProbe_RejectsNonHTTP(t *testing.T) {
+	if _, err := fetch.Probe(context.Background(), "file:///etc/passwd", false); err == nil {
+		t.Error("expected a file URL to be rejected")
+	}
+}
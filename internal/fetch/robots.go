@@ -0,0 +1,350 @@
+// ABOUTME: robots.txt parsing and per-host policy caching for polite page fetching.
+// ABOUTME: Enforces Disallow/Allow rules and Crawl-delay for non-feed requests like article pages and discovery probes.
+
+package fetch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RobotsUserAgent is the product token matched against robots.txt User-agent lines.
+const RobotsUserAgent = "digest"
+
+const (
+	robotsCacheTTL = 24 * time.Hour
+	// robotsRetryTTL is how long an unreachable robots.txt keeps its host
+	// off limits before it's tried again.
+	robotsRetryTTL = time.Hour
+	maxCrawlDelay  = 30 * time.Second
+	maxRobotsSize  = 512 * 1024 // 512KB, per RFC 9309 recommendation
+)
+
+// ErrDisallowedByRobots is returned when robots.txt forbids fetching a URL.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// RobotsRules holds the rules from robots.txt that apply to digest.
+type RobotsRules struct {
+	allow      []string
+	disallow   []string
+	CrawlDelay time.Duration
+}
+
+// robotsGroup is a single User-agent group while parsing.
+type robotsGroup struct {
+	agents     []string
+	allow      []string
+	disallow   []string
+	crawlDelay time.Duration
+}
+
+// ParseRobots parses robots.txt content and returns the rules for userAgent.
+// A group naming userAgent takes precedence over the wildcard (*) group.
+func ParseRobots(body []byte, userAgent string) *RobotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive User-agent lines share one group
+			if current == nil || !lastWasAgent {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow":
+			if current != nil && value != "" {
+				current.allow = append(current.allow, value)
+			}
+		case "disallow":
+			if current != nil && value != "" {
+				current.disallow = append(current.disallow, value)
+			}
+		case "crawl-delay":
+			if current != nil {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					current.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+		lastWasAgent = false
+	}
+
+	userAgent = strings.ToLower(userAgent)
+	var specific, wildcard *robotsGroup
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" && wildcard == nil {
+				wildcard = g
+			} else if agent != "*" && strings.Contains(userAgent, agent) && specific == nil {
+				specific = g
+			}
+		}
+	}
+
+	chosen := specific
+	if chosen == nil {
+		chosen = wildcard
+	}
+	if chosen == nil {
+		return &RobotsRules{}
+	}
+
+	delay := chosen.crawlDelay
+	if delay > maxCrawlDelay {
+		delay = maxCrawlDelay
+	}
+	return &RobotsRules{
+		allow:      chosen.allow,
+		disallow:   chosen.disallow,
+		CrawlDelay: delay,
+	}
+}
+
+// Allowed reports whether the given path (including query) may be fetched.
+// The longest matching rule wins; Allow wins ties.
+func (r *RobotsRules) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	bestAllow := longestMatch(r.allow, path)
+	bestDisallow := longestMatch(r.disallow, path)
+	if bestDisallow < 0 {
+		return true
+	}
+	return bestAllow >= bestDisallow
+}
+
+// longestMatch returns the length of the longest pattern matching path, or -1.
+func longestMatch(patterns []string, path string) int {
+	best := -1
+	for _, p := range patterns {
+		if robotsPatternMatch(p, path) && len(p) > best {
+			best = len(p)
+		}
+	}
+	return best
+}
+
+// robotsPatternMatch matches a robots.txt path pattern supporting * and $ anchors.
+func robotsPatternMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for _, part := range parts[1:] {
+		idx := strings.Index(path[pos:], part)
+		if idx == -1 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+
+	if anchored {
+		last := parts[len(parts)-1]
+		return len(parts) > 1 && strings.HasSuffix(path, last) || pos == len(path)
+	}
+	return true
+}
+
+// disallowAll is the policy for a host whose robots.txt is unreachable.
+var disallowAll = &RobotsRules{disallow: []string{"/"}}
+
+// robotsEntry is a cached robots.txt decision for one host.
+type robotsEntry struct {
+	rules   *RobotsRules
+	expires time.Time
+	lastHit time.Time
+}
+
+// RobotsCache caches robots.txt rules per host and paces requests by Crawl-delay.
+type RobotsCache struct {
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+// NewRobotsCache creates an empty robots.txt cache.
+func NewRobotsCache() *RobotsCache {
+	return &RobotsCache{hosts: make(map[string]*robotsEntry)}
+}
+
+// DefaultRobots is the process-wide robots.txt cache used by FetchPage.
+var DefaultRobots = NewRobotsCache()
+
+// Rules returns the cached robots.txt rules for the URL's host, fetching them if needed.
+// As RFC 9309 asks, a missing robots.txt (HTTP 4xx) allows everything, while
+// an unreachable one (HTTP 5xx or a network error) disallows everything until
+// it's retried an hour later.
+func (c *RobotsCache) Rules(ctx context.Context, u *url.URL, allowLocalNetwork bool) *RobotsRules {
+	host := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	entry, ok := c.hosts[host]
+	if ok && entry.rules != nil && time.Now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.rules
+	}
+	c.mu.Unlock()
+
+	rules, ttl := fetchRobots(ctx, host, allowLocalNetwork)
+	// A canceled fetch says nothing about the host, so don't remember it
+	if ctx.Err() != nil {
+		return rules
+	}
+
+	c.mu.Lock()
+	if existing, ok := c.hosts[host]; ok {
+		existing.rules = rules
+		existing.expires = time.Now().Add(ttl)
+	} else {
+		c.hosts[host] = &robotsEntry{rules: rules, expires: time.Now().Add(ttl)}
+	}
+	c.mu.Unlock()
+
+	return rules
+}
+
+// fetchRobots downloads and parses host's robots.txt, returning the rules
+// and how long to trust them.
+func fetchRobots(ctx context.Context, host string, allowLocalNetwork bool) (*RobotsRules, time.Duration) {
+	robotsURL := host + "/robots.txt"
+	if !allowLocalNetwork {
+		u, err := url.Parse(robotsURL)
+		if err != nil || checkPublicHost(ctx, u.Hostname()) != nil {
+			// The page fetch itself fails with the SSRF error
+			return &RobotsRules{}, robotsCacheTTL
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return &RobotsRules{}, robotsCacheTTL
+	}
+	req.Header.Set("User-Agent", "digest/1.0 (RSS reader)")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return disallowAll, robotsRetryTTL
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500:
+		return disallowAll, robotsRetryTTL
+	case resp.StatusCode != http.StatusOK:
+		return &RobotsRules{}, robotsCacheTTL
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return disallowAll, robotsRetryTTL
+	}
+	return ParseRobots(body, RobotsUserAgent), robotsCacheTTL
+}
+
+// wait blocks until the host's Crawl-delay has elapsed since the previous request.
+func (c *RobotsCache) wait(ctx context.Context, u *url.URL, delay time.Duration) error {
+	host := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	entry, ok := c.hosts[host]
+	if !ok {
+		entry = &robotsEntry{}
+		c.hosts[host] = entry
+	}
+	var sleep time.Duration
+	if delay > 0 && !entry.lastHit.IsZero() {
+		sleep = time.Until(entry.lastHit.Add(delay))
+	}
+	// Reserve the next slot before releasing the lock so concurrent callers queue up
+	entry.lastHit = time.Now().Add(max(sleep, 0))
+	c.mu.Unlock()
+
+	if sleep <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(sleep)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// PageOptions controls how FetchPage treats a request.
+type PageOptions struct {
+	AllowLocalNetwork bool // Allow fetching from private/local network IPs
+	IgnoreRobots      bool // Skip robots.txt checks and Crawl-delay pacing
+	Browser           bool // Send browser-like headers (see RequestOptions)
+}
+
+// FetchPage retrieves a web page (not a subscribed feed) politely: it honors
+// robots.txt Disallow rules and Crawl-delay for the host unless IgnoreRobots is set.
+// Returns ErrDisallowedByRobots if the page may not be fetched.
+func FetchPage(ctx context.Context, urlStr string, opts PageOptions) (*Result, error) {
+	if err := politeWait(ctx, urlStr, opts); err != nil {
+		return nil, err
+	}
+	return FetchWith(ctx, urlStr, nil, nil, opts.AllowLocalNetwork, RequestOptions{Browser: opts.Browser})
+}
+
+// ProbePage is Probe with the same robots.txt and Crawl-delay handling as
+// FetchPage, for checking whether a guessed URL exists before fetching it.
+func ProbePage(ctx context.Context, urlStr string, opts PageOptions) (int, error) {
+	if err := politeWait(ctx, urlStr, opts); err != nil {
+		return 0, err
+	}
+	return probeAs(ctx, urlStr, opts.AllowLocalNetwork, opts.Browser)
+}
+
+// politeWait checks robots.txt for the URL and waits out the host's
+// Crawl-delay, unless opts.IgnoreRobots is set.
+func politeWait(ctx context.Context, urlStr string, opts PageOptions) error {
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if opts.IgnoreRobots {
+		return nil
+	}
+	rules := DefaultRobots.Rules(ctx, parsedURL, opts.AllowLocalNetwork)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !rules.Allowed(parsedURL.RequestURI()) {
+		return fmt.Errorf("%w: %s", ErrDisallowedByRobots, urlStr)
+	}
+	return DefaultRobots.wait(ctx, parsedURL, rules.CrawlDelay)
+}
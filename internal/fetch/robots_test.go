@@ -0,0 +1,201 @@
+// ABOUTME: Tests for robots.txt parsing, rule matching, and polite page fetching.
+// ABOUTME: Covers user-agent group selection, Allow/Disallow precedence, and per-host caching.
+
+package fetch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+func TestParseRobots_WildcardGroup(t *testing.T) {
+	body := []byte(`
+User-agent: *
+Disallow: /private/
+Allow: /private/public.html
+`)
+	rules := fetch.ParseRobots(body, fetch.RobotsUserAgent)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/blog/post", true},
+		{"/private/secret", false},
+		{"/private/public.html", true},
+	}
+	for _, tt := range tests {
+		if got := rules.Allowed(tt.path); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRobots_SpecificAgentWins(t *testing.T) {
+	body := []byte(`
+User-agent: *
+Disallow: /
+
+User-agent: Digest
+Disallow: /admin
+Crawl-delay: 2
+`)
+	rules := fetch.ParseRobots(body, fetch.RobotsUserAgent)
+
+	if !rules.Allowed("/feed.xml") {
+		t.Error("expected /feed.xml to be allowed for digest group")
+	}
+	if rules.Allowed("/admin/settings") {
+		t.Error("expected /admin/settings to be disallowed")
+	}
+	if rules.CrawlDelay != 2*time.Second {
+		t.Errorf("expected crawl delay 2s, got %v", rules.CrawlDelay)
+	}
+}
+
+func TestParseRobots_Wildcards(t *testing.T) {
+	body := []byte(`
+User-agent: *
+Disallow: /*.php$
+Disallow: /search*q=
+`)
+	rules := fetch.ParseRobots(body, fetch.RobotsUserAgent)
+
+	if rules.Allowed("/index.php") {
+		t.Error("expected /index.php to be disallowed")
+	}
+	if !rules.Allowed("/index.php/feed") {
+		t.Error("expected /index.php/feed to be allowed ($ anchor)")
+	}
+	if rules.Allowed("/search?q=go") {
+		t.Error("expected /search?q=go to be disallowed")
+	}
+}
+
+func TestParseRobots_EmptyDisallowAllowsAll(t *testing.T) {
+	rules := fetch.ParseRobots([]byte("User-agent: *\nDisallow:\n"), fetch.RobotsUserAgent)
+	if !rules.Allowed("/anything") {
+		t.Error("expected empty Disallow to allow everything")
+	}
+}
+
+func TestParseRobots_CrawlDelayCapped(t *testing.T) {
+	rules := fetch.ParseRobots([]byte("User-agent: *\nCrawl-delay: 3600\n"), fetch.RobotsUserAgent)
+	if rules.CrawlDelay > 30*time.Second {
+		t.Errorf("expected crawl delay to be capped, got %v", rules.CrawlDelay)
+	}
+}
+
+func TestFetchPage_HonorsRobots(t *testing.T) {
+	var robotsHits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			robotsHits.Add(1)
+			w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	_, err := fetch.FetchPage(ctx, server.URL+"/blocked/page", fetch.PageOptions{})
+	if !errors.Is(err, fetch.ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots, got %v", err)
+	}
+
+	result, err := fetch.FetchPage(ctx, server.URL+"/open", fetch.PageOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Body) != "ok" {
+		t.Errorf("expected body 'ok', got %q", string(result.Body))
+	}
+
+	if hits := robotsHits.Load(); hits != 1 {
+		t.Errorf("expected robots.txt to be fetched once per host, got %d", hits)
+	}
+
+	// Opting out skips the check entirely
+	if _, err := fetch.FetchPage(ctx, server.URL+"/blocked/page", fetch.PageOptions{IgnoreRobots: true}); err != nil {
+		t.Errorf("expected IgnoreRobots to bypass robots.txt, got %v", err)
+	}
+}
+
+func TestProbePage_HonorsRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+		case "/feed.xml":
+			if r.Method != http.MethodHead {
+				t.Errorf("expected a HEAD request, got %s", r.Method)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	if _, err := fetch.ProbePage(ctx, server.URL+"/blocked/feed.xml", fetch.PageOptions{}); !errors.Is(err, fetch.ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots, got %v", err)
+	}
+	code, err := fetch.ProbePage(ctx, server.URL+"/feed.xml", fetch.PageOptions{})
+	if err != nil || code != http.StatusOK {
+		t.Errorf("expected 200, got %d (%v)", code, err)
+	}
+	code, err = fetch.ProbePage(ctx, server.URL+"/missing.xml", fetch.PageOptions{})
+	if err != nil || code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d (%v)", code, err)
+	}
+}
+
+func TestFetchPage_MissingRobotsAllowsAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	if _, err := fetch.FetchPage(context.Background(), server.URL+"/article", fetch.PageOptions{}); err != nil {
+		t.Errorf("expected missing robots.txt to allow fetching, got %v", err)
+	}
+}
+
+func TestFetchPage_UnreachableRobotsDisallowsAll(t *testing.T) {
+	var pageHits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		pageHits.Add(1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	_, err := fetch.FetchPage(context.Background(), server.URL+"/article", fetch.PageOptions{})
+	if !errors.Is(err, fetch.ErrDisallowedByRobots) {
+		t.Fatalf("expected a 5xx robots.txt to disallow fetching, got %v", err)
+	}
+	if _, err := fetch.ProbePage(context.Background(), server.URL+"/feed.xml", fetch.PageOptions{}); !errors.Is(err, fetch.ErrDisallowedByRobots) {
+		t.Errorf("expected probes to be disallowed too, got %v", err)
+	}
+	if hits := pageHits.Load(); hits != 0 {
+		t.Errorf("expected no page requests, got %d", hits)
+	}
+}
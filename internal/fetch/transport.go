@@ -0,0 +1,82 @@
+// ABOUTME: Shared, tunable HTTP transport for every request digest makes during a sync
+// ABOUTME: Keeps connections alive across feeds on the same host and negotiates HTTP/2
+
+package fetch
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// TransportOptions tunes the shared transport's connection reuse.
+type TransportOptions struct {
+	// MaxIdleConns caps idle keep-alive connections across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle connections kept per host. Go's default
+	// of 2 drops connections when many feeds live on one host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps concurrent connections per host; 0 means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle this long.
+	IdleConnTimeout time.Duration
+	// DisableHTTP2 sticks to HTTP/1.1, for servers with broken HTTP/2.
+	DisableHTTP2 bool
+}
+
+// DefaultTransportOptions returns the transport settings used unless
+// Configure is called.
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 8,
+		MaxConnsPerHost:     16,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+var transport atomic.Pointer[http.Transport]
+
+func init() {
+	transport.Store(newTransport(DefaultTransportOptions()))
+}
+
+// newTransport builds a transport that resolves hosts through DefaultCache.
+func newTransport(opts TransportOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = dialContext(&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+	t.MaxIdleConns = opts.MaxIdleConns
+	t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	t.MaxConnsPerHost = opts.MaxConnsPerHost
+	t.IdleConnTimeout = opts.IdleConnTimeout
+	if opts.DisableHTTP2 {
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return t
+}
+
+// Configure replaces the shared transport with one built from opts. Call it
+// once at startup, before the first request; connections idle in the old
+// transport are closed.
+func Configure(opts TransportOptions) {
+	if old := transport.Swap(newTransport(opts)); old != nil {
+		old.CloseIdleConnections()
+	}
+}
+
+// Transport returns the shared transport, for clients outside this package
+// that should reuse the same connections.
+func Transport() http.RoundTripper {
+	return sharedTransport{}
+}
+
+// sharedTransport sends each request through the currently configured
+// transport, so clients made before Configure still pick it up.
+type sharedTransport struct{}
+
+func (sharedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return transport.Load().RoundTrip(req)
+}
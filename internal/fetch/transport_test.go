@@ -0,0 +1,71 @@
+// ABOUTME: Tests for the shared HTTP transport's connection reuse.
+// ABOUTME: Counts server-side connections across fetches to the same host.
+
+package fetch_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+func countingServer(t *testing.T, conns *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<rss></rss>"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(conns, 1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch_ReusesConnections(t *testing.T) {
+	var conns int32
+	server := countingServer(t, &conns)
+
+	for i := 0; i < 5; i++ {
+		if _, err := fetch.Fetch(context.Background(), server.URL+"/feed", nil, nil, false); err != nil {
+			t.Fatalf("fetch %d: unexpected error: %v", i, err)
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("expected 1 connection for 5 fetches, got %d", n)
+	}
+}
+
+func TestConfigure_AppliesToSharedClients(t *testing.T) {
+	t.Cleanup(func() { fetch.Configure(fetch.DefaultTransportOptions()) })
+	var conns int32
+	server := countingServer(t, &conns)
+
+	// A client made before Configure still goes through the new transport
+	client := &http.Client{Transport: fetch.Transport()}
+	opts := fetch.DefaultTransportOptions()
+	opts.IdleConnTimeout = time.Millisecond
+	fetch.Configure(opts)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("get %d: unexpected error: %v", i, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&conns); n != 2 {
+		t.Errorf("expected idle connections to time out between requests, got %d connections", n)
+	}
+}
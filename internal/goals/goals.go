@@ -0,0 +1,145 @@
+// ABOUTME: Reading goals and streaks worked out from when entries were read
+// ABOUTME: Tracks a daily reading goal, an unread ceiling, and how many days in a row the goal was met
+
+package goals
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// HistoryDays is how many days of reading Progress.Days covers.
+const HistoryDays = 14
+
+// Config holds the reading goals. Zero values mean no goal; without a daily
+// goal, any day with a read keeps the streak going.
+type Config struct {
+	// DailyReads is how many entries to read each day.
+	DailyReads int `json:"daily_reads,omitempty"`
+
+	// MaxUnread is how many unread entries the inbox may hold.
+	MaxUnread int `json:"max_unread,omitempty"`
+}
+
+// Day is how much was read on one day.
+type Day struct {
+	Date string `json:"date"`
+	Read int    `json:"read"`
+	Met  bool   `json:"met"`
+}
+
+// Progress is where the reading goals stand.
+type Progress struct {
+	// Date is today in the user's time zone, as YYYY-MM-DD.
+	Date       string `json:"date"`
+	ReadToday  int    `json:"read_today"`
+	DailyReads int    `json:"daily_reads_goal,omitempty"`
+	TodayMet   bool   `json:"today_met"`
+
+	Unread    int  `json:"unread"`
+	MaxUnread int  `json:"max_unread_goal,omitempty"`
+	InboxMet  bool `json:"inbox_met"`
+
+	// Streak counts the days in a row the daily goal was met, up to today,
+	// or up to yesterday while today's reading is still under way.
+	Streak        int `json:"streak"`
+	LongestStreak int `json:"longest_streak"`
+
+	// Days is the last HistoryDays days, oldest first.
+	Days []Day `json:"days"`
+}
+
+// Compute works out the goals' progress from entries of any read state and
+// the current unread count. Days run midnight to midnight in now's location.
+func Compute(entries []*models.Entry, unread int, cfg Config, now time.Time) Progress {
+	loc := now.Location()
+	reads := make(map[string]int)
+	var first time.Time
+	for _, e := range entries {
+		if e.ReadAt == nil {
+			continue
+		}
+		at := e.ReadAt.In(loc)
+		reads[at.Format(time.DateOnly)]++
+		if first.IsZero() || at.Before(first) {
+			first = at
+		}
+	}
+
+	need := max(cfg.DailyReads, 1)
+	today := startOfDay(now)
+	p := Progress{
+		Date:       today.Format(time.DateOnly),
+		ReadToday:  reads[today.Format(time.DateOnly)],
+		DailyReads: cfg.DailyReads,
+		Unread:     unread,
+		MaxUnread:  cfg.MaxUnread,
+		InboxMet:   cfg.MaxUnread <= 0 || unread <= cfg.MaxUnread,
+	}
+	p.TodayMet = p.ReadToday >= need
+
+	// Walk from the first read to today, tracking runs of met days
+	run := 0
+	if !first.IsZero() {
+		for day := startOfDay(first); !day.After(today); day = day.AddDate(0, 0, 1) {
+			if reads[day.Format(time.DateOnly)] >= need {
+				run++
+				p.LongestStreak = max(p.LongestStreak, run)
+			} else if !day.Equal(today) {
+				run = 0
+			}
+		}
+	}
+	p.Streak = run
+
+	for i := HistoryDays - 1; i >= 0; i-- {
+		date := today.AddDate(0, 0, -i).Format(time.DateOnly)
+		p.Days = append(p.Days, Day{Date: date, Read: reads[date], Met: reads[date] >= need})
+	}
+	return p
+}
+
+// startOfDay returns midnight at the start of t's day in t's location.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// Summary describes the progress in one line, such as
+// "5-day streak · 3/10 read today · 64 unread (goal 50)".
+func (p Progress) Summary() string {
+	parts := []string{fmt.Sprintf("%d-day streak", p.Streak)}
+	if p.DailyReads > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d read today", p.ReadToday, p.DailyReads))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d read today", p.ReadToday))
+	}
+	if p.MaxUnread > 0 {
+		parts = append(parts, fmt.Sprintf("%d unread (goal %d)", p.Unread, p.MaxUnread))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Nudges suggests what would meet the goals that aren't met yet, such as
+// keeping a streak alive or getting the inbox under its ceiling.
+func (p Progress) Nudges() []string {
+	var nudges []string
+	if !p.TodayMet {
+		left := max(p.DailyReads, 1) - p.ReadToday
+		switch {
+		case p.Streak > 0:
+			nudges = append(nudges, fmt.Sprintf("Read %d more today to make the streak %d days", left, p.Streak+1))
+		case p.DailyReads > 0:
+			nudges = append(nudges, fmt.Sprintf("Read %d more today to meet the daily goal of %d", left, p.DailyReads))
+		default:
+			nudges = append(nudges, "Read something today to start a streak")
+		}
+	}
+	if !p.InboxMet {
+		nudges = append(nudges, fmt.Sprintf("The inbox is %d over its goal of %d unread; mark old or low-value entries read", p.Unread-p.MaxUnread, p.MaxUnread))
+	}
+	return nudges
+}
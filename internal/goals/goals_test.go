@@ -0,0 +1,86 @@
+// ABOUTME: Tests for reading goals and streaks
+// ABOUTME: Covers streak counting across days, the inbox ceiling, and nudges
+
+package goals
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func readOn(times ...time.Time) []*models.Entry {
+	var entries []*models.Entry
+	for _, at := range times {
+		e := models.NewEntry("feed", at.String(), "Post")
+		e.Read = true
+		e.ReadAt = &at
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestCompute(t *testing.T) {
+	loc := time.FixedZone("test", -5*60*60)
+	now := time.Date(2026, 6, 10, 9, 0, 0, 0, loc)
+	day := func(daysAgo, hour int) time.Time {
+		return time.Date(2026, 6, 10-daysAgo, hour, 0, 0, 0, loc)
+	}
+
+	// Two reads a day for the last three days before today, a gap, then an
+	// older five-day run; 23:30 local is the next day in UTC
+	var times []time.Time
+	for d := 1; d <= 3; d++ {
+		times = append(times, day(d, 8), day(d, 23).Add(30*time.Minute))
+	}
+	for d := 5; d <= 9; d++ {
+		times = append(times, day(d, 12), day(d, 13))
+	}
+	times = append(times, day(0, 7))
+	entries := readOn(times...)
+
+	p := Compute(entries, 64, Config{DailyReads: 2, MaxUnread: 50}, now)
+	if p.Date != "2026-06-10" || p.ReadToday != 1 || p.TodayMet {
+		t.Errorf("today = %s, %d read, met %v", p.Date, p.ReadToday, p.TodayMet)
+	}
+	if p.Streak != 3 || p.LongestStreak != 5 {
+		t.Errorf("streak %d, longest %d; want 3 and 5", p.Streak, p.LongestStreak)
+	}
+	if p.InboxMet {
+		t.Error("64 unread should miss a goal of 50")
+	}
+	if len(p.Days) != HistoryDays || p.Days[len(p.Days)-1].Date != "2026-06-10" || !p.Days[len(p.Days)-2].Met {
+		t.Errorf("unexpected days %+v", p.Days)
+	}
+
+	nudges := p.Nudges()
+	if len(nudges) != 2 || !strings.Contains(nudges[0], "Read 1 more today to make the streak 4 days") || !strings.Contains(nudges[1], "14 over") {
+		t.Errorf("unexpected nudges %q", nudges)
+	}
+	if got := p.Summary(); got != "3-day streak · 1/2 read today · 64 unread (goal 50)" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	// Meeting today's goal extends the streak
+	p = Compute(append(entries, readOn(day(0, 8))...), 10, Config{DailyReads: 2, MaxUnread: 50}, now)
+	if p.Streak != 4 || !p.TodayMet || !p.InboxMet || len(p.Nudges()) != 0 {
+		t.Errorf("after meeting today's goal: %+v", p)
+	}
+
+	// Without a daily goal one read a day counts
+	if p := Compute(entries, 0, Config{}, now); p.Streak != 4 || !p.TodayMet {
+		t.Errorf("without goals: streak %d, today met %v", p.Streak, p.TodayMet)
+	}
+}
+
+func TestComputeNothingRead(t *testing.T) {
+	p := Compute(nil, 3, Config{}, time.Date(2026, 6, 10, 9, 0, 0, 0, time.UTC))
+	if p.Streak != 0 || p.LongestStreak != 0 || !p.InboxMet {
+		t.Errorf("unexpected progress %+v", p)
+	}
+	if nudges := p.Nudges(); len(nudges) != 1 || !strings.Contains(nudges[0], "start a streak") {
+		t.Errorf("unexpected nudges %q", nudges)
+	}
+}
@@ -0,0 +1,281 @@
+// ABOUTME: Lifecycle hooks that run user-configured executables with a JSON event on stdin
+// ABOUTME: pre_add_feed can veto or retitle a feed, on_new_entry can mark entries read, post_sync reports runs
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+// Events hooks run on.
+const (
+	// PreAddFeed runs before a feed is added. A hook that exits non-zero
+	// rejects the feed; one that prints a Reply can change its title or
+	// folder.
+	PreAddFeed = "pre_add_feed"
+	// OnNewEntry runs for each entry a sync brings in, before it is
+	// stored. A hook can print a Reply to mark it read or keep it unread.
+	OnNewEntry = "on_new_entry"
+	// PostSync runs after each sync run with its summary.
+	PostSync = "post_sync"
+)
+
+// DefaultTimeout is how long a hook may run when it sets no timeout.
+const DefaultTimeout = 30 * time.Second
+
+// maxOutput caps what is read of a hook's stdout.
+const maxOutput = 1 << 20
+
+// maxStderr is how much of a failing hook's stderr its error quotes.
+const maxStderr = 512
+
+// waitDelay is how long a timed-out hook's children may keep its output
+// open before digest stops waiting for them.
+const waitDelay = time.Second
+
+// Hook is an executable run on an event.
+type Hook struct {
+	// Command is the executable, as a path or a name looked up in PATH.
+	Command string `json:"command"`
+
+	// Args are passed to Command as they are, with no shell involved.
+	Args []string `json:"args,omitempty"`
+
+	// Timeout bounds each run, as a duration like "10s". Default 30s.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Config lists the hooks of each event, run in order.
+type Config struct {
+	PreAddFeed []Hook `json:"pre_add_feed,omitempty"`
+	OnNewEntry []Hook `json:"on_new_entry,omitempty"`
+	PostSync   []Hook `json:"post_sync,omitempty"`
+}
+
+// Validate checks that every hook has a command and a valid timeout.
+func (c Config) Validate() error {
+	for event, list := range map[string][]Hook{PreAddFeed: c.PreAddFeed, OnNewEntry: c.OnNewEntry, PostSync: c.PostSync} {
+		for i, h := range list {
+			if strings.TrimSpace(h.Command) == "" {
+				return fmt.Errorf("%s hook %d has no command", event, i+1)
+			}
+			if _, err := h.timeout(); err != nil {
+				return fmt.Errorf("%s hook %s: %w", event, h.Command, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (h Hook) timeout() (time.Duration, error) {
+	if h.Timeout == "" {
+		return DefaultTimeout, nil
+	}
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", h.Timeout)
+	}
+	return d, nil
+}
+
+// Feed is a feed as hooks see it.
+type Feed struct {
+	ID     string `json:"id,omitempty"`
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
+	Folder string `json:"folder,omitempty"`
+}
+
+// Entry is an entry as hooks see it.
+type Entry struct {
+	ID        string     `json:"id"`
+	GUID      string     `json:"guid"`
+	Title     string     `json:"title"`
+	Link      string     `json:"link,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	Published *time.Time `json:"published,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	Read      bool       `json:"read"`
+}
+
+// Payload is the JSON a hook reads on stdin. Event says which fields are set.
+type Payload struct {
+	Event   string        `json:"event"`
+	Profile string        `json:"profile"`
+	Feed    *Feed         `json:"feed,omitempty"`
+	Entry   *Entry        `json:"entry,omitempty"`
+	Run     *feedsync.Run `json:"run,omitempty"`
+}
+
+// Reply is the JSON a hook may print on stdout. Printing nothing changes
+// nothing.
+type Reply struct {
+	// Title and Folder replace a feed's in pre_add_feed.
+	Title  *string `json:"title,omitempty"`
+	Folder *string `json:"folder,omitempty"`
+
+	// Read marks a new entry read, and KeepUnread pins it unread, in
+	// on_new_entry.
+	Read       bool `json:"read,omitempty"`
+	KeepUnread bool `json:"keep_unread,omitempty"`
+}
+
+// Runner runs a profile's hooks.
+type Runner struct {
+	Config  Config
+	Profile string
+	// Warn receives failures of hooks that can't stop what they hook,
+	// those of on_new_entry and post_sync.
+	Warn io.Writer
+}
+
+// PreAddFeed runs the pre_add_feed hooks on a feed about to be added,
+// each seeing the changes of those before it. It returns the feed as the
+// hooks left it, or an error if one rejected it.
+func (r *Runner) PreAddFeed(ctx context.Context, feed Feed) (Feed, error) {
+	for _, h := range r.Config.PreAddFeed {
+		reply, err := r.run(ctx, h, Payload{Event: PreAddFeed, Profile: r.Profile, Feed: &feed})
+		if err != nil {
+			return feed, fmt.Errorf("feed rejected by hook: %w", err)
+		}
+		if reply.Title != nil {
+			feed.Title = *reply.Title
+		}
+		if reply.Folder != nil {
+			feed.Folder = *reply.Folder
+		}
+	}
+	return feed, nil
+}
+
+// NewEntry runs the on_new_entry hooks on an entry about to be stored and
+// applies their replies to it. It fits sync.Options.NewEntry.
+func (r *Runner) NewEntry(ctx context.Context, feed *models.Feed, entry *models.Entry) {
+	if len(r.Config.OnNewEntry) == 0 {
+		return
+	}
+	p := Payload{
+		Event:   OnNewEntry,
+		Profile: r.Profile,
+		Feed:    &Feed{ID: feed.ID, URL: feed.URL, Title: feed.GetDisplayName(), Folder: feed.Folder},
+		Entry: &Entry{
+			ID:        entry.ID,
+			GUID:      entry.GUID,
+			Title:     entry.GetTitle(),
+			Link:      deref(entry.Link),
+			Author:    deref(entry.Author),
+			Published: entry.PublishedAt,
+			Content:   deref(entry.Content),
+			Read:      entry.Read,
+		},
+	}
+	for _, h := range r.Config.OnNewEntry {
+		reply, err := r.run(ctx, h, p)
+		if err != nil {
+			r.warn(err)
+			continue
+		}
+		if reply.KeepUnread {
+			entry.KeepUnread = true
+			entry.Read, entry.ReadAt = false, nil
+		} else if reply.Read && !entry.Read {
+			entry.MarkRead()
+		}
+		p.Entry.Read = entry.Read
+	}
+}
+
+// PostSync runs the post_sync hooks with a finished run's summary.
+func (r *Runner) PostSync(ctx context.Context, run *feedsync.Run) {
+	for _, h := range r.Config.PostSync {
+		if _, err := r.run(ctx, h, Payload{Event: PostSync, Profile: r.Profile, Run: run}); err != nil {
+			r.warn(err)
+		}
+	}
+}
+
+// run runs one hook with p on stdin and decodes the reply it printed.
+func (r *Runner) run(ctx context.Context, h Hook, p Payload) (Reply, error) {
+	var reply Reply
+	timeout, err := h.timeout()
+	if err != nil {
+		return reply, err
+	}
+	input, err := json.Marshal(p)
+	if err != nil {
+		return reply, fmt.Errorf("encode %s payload: %w", p.Event, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, h.Command, h.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(), "DIGEST_HOOK_EVENT="+p.Event, "DIGEST_PROFILE="+p.Profile)
+	stdout := limitedBuffer{max: maxOutput}
+	stderr := limitedBuffer{max: maxOutput}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return reply, fmt.Errorf("%s timed out after %s", h.Command, timeout)
+		}
+		detail := strings.TrimSpace(stderr.String())
+		if len(detail) > maxStderr {
+			detail = detail[len(detail)-maxStderr:]
+		}
+		if detail != "" {
+			return reply, fmt.Errorf("%s: %w: %s", h.Command, err, detail)
+		}
+		return reply, fmt.Errorf("%s: %w", h.Command, err)
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) == 0 {
+		return reply, nil
+	}
+	if err := json.Unmarshal(out, &reply); err != nil {
+		return reply, fmt.Errorf("%s printed invalid JSON: %w", h.Command, err)
+	}
+	return reply, nil
+}
+
+func (r *Runner) warn(err error) {
+	if r.Warn != nil {
+		fmt.Fprintf(r.Warn, "warning: hook %v\n", err)
+	}
+}
+
+// limitedBuffer keeps the first max bytes written to it and drops the
+// rest, so a chatty hook can't exhaust memory.
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Tests for lifecycle hooks
+// ABOUTME: Runs small shell scripts as hooks to check payloads, replies, rejections, and timeouts
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+// script writes an executable shell script and returns a hook running it.
+func script(t *testing.T, body string) Hook {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return Hook{Command: path}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Config{PostSync: []Hook{{Command: "notify", Timeout: "5s"}}}).Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	bad := []Config{
+		{PreAddFeed: []Hook{{Command: " "}}},
+		{OnNewEntry: []Hook{{Command: "x", Timeout: "soon"}}},
+		{PostSync: []Hook{{Command: "x", Timeout: "-1s"}}},
+	}
+	for _, c := range bad {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", c)
+		}
+	}
+}
+
+func TestPreAddFeed(t *testing.T) {
+	retitle := script(t, `grep -q '"url":"https://example.com/feed"' || exit 3
+echo '{"title": "Example", "folder": "Blogs"}'`)
+	quiet := script(t, `cat > /dev/null`)
+	r := &Runner{Config: Config{PreAddFeed: []Hook{retitle, quiet}}, Profile: "work"}
+
+	feed, err := r.PreAddFeed(context.Background(), Feed{URL: "https://example.com/feed", Folder: "Inbox"})
+	if err != nil {
+		t.Fatalf("PreAddFeed: %v", err)
+	}
+	if feed.Title != "Example" || feed.Folder != "Blogs" {
+		t.Errorf("feed = %+v, want retitled into Blogs", feed)
+	}
+
+	reject := script(t, `echo "no tracking domains" >&2; exit 1`)
+	r.Config.PreAddFeed = []Hook{reject}
+	_, err = r.PreAddFeed(context.Background(), Feed{URL: "https://example.com/feed"})
+	if err == nil || !strings.Contains(err.Error(), "no tracking domains") {
+		t.Errorf("PreAddFeed error = %v, want the hook's reason", err)
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	// Marks sponsored posts read and checks the payload as it goes
+	filter := script(t, `payload=$(cat)
+[ "$DIGEST_HOOK_EVENT" = on_new_entry ] || exit 4
+[ "$DIGEST_PROFILE" = work ] || exit 5
+case "$payload" in
+  *'"title":"Sponsored'*) echo '{"read": true}' ;;
+esac`)
+	var warnings bytes.Buffer
+	r := &Runner{Config: Config{OnNewEntry: []Hook{filter}}, Profile: "work", Warn: &warnings}
+	feed := models.NewFeed("https://example.com/feed")
+
+	ad := models.NewEntry(feed.ID, "g1", "Sponsored: buy this")
+	r.NewEntry(context.Background(), feed, ad)
+	if !ad.Read || ad.ReadAt == nil {
+		t.Error("expected the sponsored entry to be marked read")
+	}
+	post := models.NewEntry(feed.ID, "g2", "A real post")
+	r.NewEntry(context.Background(), feed, post)
+	if post.Read {
+		t.Error("expected the post to stay unread")
+	}
+	if warnings.Len() > 0 {
+		t.Errorf("unexpected warnings: %s", warnings.String())
+	}
+
+	broken := script(t, `echo "not json"`)
+	keep := script(t, `echo '{"keep_unread": true}'`)
+	r.Config.OnNewEntry = []Hook{broken, keep}
+	entry := models.NewEntry(feed.ID, "g3", "Pin me")
+	r.NewEntry(context.Background(), feed, entry)
+	if !entry.KeepUnread {
+		t.Error("a failing hook should not stop the ones after it")
+	}
+	if !strings.Contains(warnings.String(), "invalid JSON") {
+		t.Errorf("warnings = %q, want the broken hook reported", warnings.String())
+	}
+}
+
+func TestPostSync(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "run.json")
+	save := script(t, `cat > "$1"`)
+	save.Args = []string{out}
+	slow := script(t, `sleep 5`)
+	slow.Timeout = "100ms"
+	var warnings bytes.Buffer
+	r := &Runner{Config: Config{PostSync: []Hook{slow, save}}, Profile: "default", Warn: &warnings}
+
+	run := &feedsync.Run{Source: "cli", Synced: 3, NewEntries: 7}
+	r.PostSync(context.Background(), run)
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook didn't write its payload: %v", err)
+	}
+	var p Payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if p.Event != PostSync || p.Profile != "default" || p.Run == nil || p.Run.NewEntries != 7 {
+		t.Errorf("payload = %+v", p)
+	}
+	if !strings.Contains(warnings.String(), "timed out") {
+		t.Errorf("warnings = %q, want the slow hook's timeout", warnings.String())
+	}
+}
@@ -0,0 +1,236 @@
+// ABOUTME: Bulk link checker that probes stored entry links for link rot
+// ABOUTME: Runs probes concurrently with per-host pacing and summarizes broken links per feed
+
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+// Default limits for a check run.
+const (
+	DefaultConcurrency = 8
+	DefaultHostDelay   = 500 * time.Millisecond
+)
+
+// Status classifies a probed link.
+type Status string
+
+const (
+	StatusOK     Status = "ok"     // The link answered without a gone status
+	StatusBroken Status = "broken" // 404 Not Found or 410 Gone
+	StatusError  Status = "error"  // Unreachable or a server error; may be transient
+)
+
+// Target is an entry link to check.
+type Target struct {
+	EntryID string
+	FeedID  string
+	Link    string
+}
+
+// Result is the outcome for one target.
+type Result struct {
+	Target
+	Status Status
+	Code   int
+	Err    error
+}
+
+// FeedRot summarizes link rot for one feed.
+type FeedRot struct {
+	FeedID  string
+	Checked int
+	Broken  int
+	Errors  int
+}
+
+// Percent returns the share of checked links that are broken.
+func (f FeedRot) Percent() float64 {
+	if f.Checked == 0 {
+		return 0
+	}
+	return 100 * float64(f.Broken) / float64(f.Checked)
+}
+
+// Checker probes links. Concurrency bounds requests in flight overall and
+// HostDelay spaces out requests to the same host.
+type Checker struct {
+	Concurrency int
+	HostDelay   time.Duration
+	Probe       func(ctx context.Context, link string) (int, error)
+
+	mu       sync.Mutex
+	nextSlot map[string]time.Time
+}
+
+// New returns a Checker that probes over HTTP, honoring each host's
+// robots.txt and Crawl-delay on top of hostDelay.
+func New(concurrency int, hostDelay time.Duration, allowLocalNetwork bool) *Checker {
+	return &Checker{
+		Concurrency: concurrency,
+		HostDelay:   hostDelay,
+		Probe: func(ctx context.Context, link string) (int, error) {
+			return fetch.ProbePage(ctx, link, fetch.PageOptions{AllowLocalNetwork: allowLocalNetwork})
+		},
+	}
+}
+
+// Classify maps an HTTP status code to a Status. Only 404 and 410 count as
+// broken; other failures may clear up on their own.
+func Classify(code int) Status {
+	switch {
+	case code == http.StatusNotFound || code == http.StatusGone:
+		return StatusBroken
+	case code >= 500 || code == 0 || code == http.StatusTooManyRequests:
+		return StatusError
+	default:
+		return StatusOK
+	}
+}
+
+// Check probes every target and returns results in target order. A link
+// shared by several entries is probed once. progress, when non-nil, is called
+// after each distinct link is probed.
+func (c *Checker) Check(ctx context.Context, targets []Target, progress func(done, total int)) []Result {
+	var links []string
+	seen := make(map[string]bool)
+	for _, t := range targets {
+		if !seen[t.Link] {
+			seen[t.Link] = true
+			links = append(links, t.Link)
+		}
+	}
+
+	type outcome struct {
+		code int
+		err  error
+	}
+	outcomes := make(map[string]outcome, len(links))
+	var outcomesMu sync.Mutex
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	done := 0
+	for range max(c.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				var o outcome
+				if o.err = c.wait(ctx, link); o.err == nil {
+					o.code, o.err = c.Probe(ctx, link)
+				}
+				outcomesMu.Lock()
+				outcomes[link] = o
+				done++
+				if progress != nil {
+					progress(done, len(links))
+				}
+				outcomesMu.Unlock()
+			}
+		}()
+	}
+	for _, link := range links {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- link
+	}
+	close(jobs)
+	wg.Wait()
+
+	results := make([]Result, len(targets))
+	for i, t := range targets {
+		r := Result{Target: t, Status: StatusError}
+		o, ok := outcomes[t.Link]
+		switch {
+		case !ok:
+			r.Err = ctx.Err()
+		case o.err != nil:
+			r.Err = o.err
+		default:
+			r.Code = o.code
+			r.Status = Classify(o.code)
+		}
+		results[i] = r
+	}
+	return results
+}
+
+// wait blocks until the link's host may be contacted again.
+func (c *Checker) wait(ctx context.Context, link string) error {
+	if c.HostDelay <= 0 {
+		return nil
+	}
+	host := link
+	if u, err := url.Parse(link); err == nil {
+		host = u.Host
+	}
+
+	c.mu.Lock()
+	if c.nextSlot == nil {
+		c.nextSlot = make(map[string]time.Time)
+	}
+	now := time.Now()
+	slot := c.nextSlot[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	// Reserve the slot before releasing the lock so concurrent probes queue up
+	c.nextSlot[host] = slot.Add(c.HostDelay)
+	c.mu.Unlock()
+
+	sleep := slot.Sub(now)
+	if sleep <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(sleep)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Summarize tallies results per feed, most broken links first.
+func Summarize(results []Result) []FeedRot {
+	byFeed := make(map[string]*FeedRot)
+	var order []string
+	for _, r := range results {
+		rot, ok := byFeed[r.FeedID]
+		if !ok {
+			rot = &FeedRot{FeedID: r.FeedID}
+			byFeed[r.FeedID] = rot
+			order = append(order, r.FeedID)
+		}
+		rot.Checked++
+		switch r.Status {
+		case StatusBroken:
+			rot.Broken++
+		case StatusError:
+			rot.Errors++
+		}
+	}
+
+	summary := make([]FeedRot, 0, len(order))
+	for _, id := range order {
+		summary = append(summary, *byFeed[id])
+	}
+	sort.SliceStable(summary, func(i, j int) bool {
+		if summary[i].Broken != summary[j].Broken {
+			return summary[i].Broken > summary[j].Broken
+		}
+		return summary[i].Percent() > summary[j].Percent()
+	})
+	return summary
+}
@@ -0,0 +1,124 @@
+// ABOUTME: Tests for the bulk link checker
+// ABOUTME: Covers status classification, de-duplication, per-host pacing, and per-feed summaries
+
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	cases := map[int]Status{
+		200: StatusOK,
+		403: StatusOK,
+		404: StatusBroken,
+		410: StatusBroken,
+		429: StatusError,
+		503: StatusError,
+	}
+	for code, want := range cases {
+		if got := Classify(code); got != want {
+			t.Errorf("Classify(%d) = %s, want %s", code, got, want)
+		}
+	}
+}
+
+func TestCheckAgainstServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(4, 0, true)
+	results := c.Check(context.Background(), []Target{
+		{EntryID: "a", FeedID: "f1", Link: srv.URL + "/fine"},
+		{EntryID: "b", FeedID: "f1", Link: srv.URL + "/missing"},
+		{EntryID: "c", FeedID: "f2", Link: srv.URL + "/gone"},
+	}, nil)
+
+	want := []Status{StatusOK, StatusBroken, StatusBroken}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("result %d (%s): got %s (code %d, err %v), want %s", i, r.Link, r.Status, r.Code, r.Err, want[i])
+		}
+	}
+}
+
+func TestCheckDeduplicatesAndPaces(t *testing.T) {
+	var mu sync.Mutex
+	var calls []time.Time // probes to example.com
+	probed := 0
+	c := &Checker{
+		Concurrency: 4,
+		HostDelay:   30 * time.Millisecond,
+		Probe: func(ctx context.Context, link string) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			probed++
+			if link == "https://down.example.com/" {
+				return 0, errors.New("connection refused")
+			}
+			calls = append(calls, time.Now())
+			return http.StatusOK, nil
+		},
+	}
+
+	results := c.Check(context.Background(), []Target{
+		{EntryID: "a", FeedID: "f", Link: "https://example.com/1"},
+		{EntryID: "b", FeedID: "f", Link: "https://example.com/1"},
+		{EntryID: "c", FeedID: "f", Link: "https://example.com/2"},
+		{EntryID: "d", FeedID: "f", Link: "https://example.com/3"},
+		{EntryID: "e", FeedID: "f", Link: "https://down.example.com/"},
+	}, nil)
+
+	if probed != 4 {
+		t.Fatalf("expected 4 distinct links probed, got %d", probed)
+	}
+	if results[1].Status != StatusOK || results[1].EntryID != "b" {
+		t.Errorf("expected the duplicate link to share the probe result, got %+v", results[1])
+	}
+	if results[4].Status != StatusError || results[4].Err == nil {
+		t.Errorf("expected an unreachable host to be an error, got %+v", results[4])
+	}
+
+	// Three probes to example.com need at least two host delays between them
+	first, last := calls[0], calls[0]
+	for _, c := range calls {
+		if c.Before(first) {
+			first = c
+		}
+		if c.After(last) {
+			last = c
+		}
+	}
+	if last.Sub(first) < 60*time.Millisecond {
+		t.Errorf("expected probes to the same host to be paced, spread was %s", last.Sub(first))
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]Result{
+		{Target: Target{FeedID: "quiet"}, Status: StatusOK},
+		{Target: Target{FeedID: "rotting"}, Status: StatusBroken},
+		{Target: Target{FeedID: "rotting"}, Status: StatusBroken},
+		{Target: Target{FeedID: "rotting"}, Status: StatusError},
+		{Target: Target{FeedID: "rotting"}, Status: StatusOK},
+	})
+	if len(summary) != 2 || summary[0].FeedID != "rotting" {
+		t.Fatalf("expected the rotting feed first, got %+v", summary)
+	}
+	if s := summary[0]; s.Checked != 4 || s.Broken != 2 || s.Errors != 1 || s.Percent() != 50 {
+		t.Errorf("unexpected tally %+v (%.0f%%)", s, s.Percent())
+	}
+}
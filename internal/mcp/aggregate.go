@@ -0,0 +1,213 @@
+// ABOUTME: count_entries and aggregate_entries tools that answer "how many" without paging through entries
+// ABOUTME: Count with list_entries' filters, overall or grouped by feed, day, or folder with unread counts
+
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type CountEntriesInput struct {
+	EntryQueryInput
+}
+
+type CountEntriesOutput struct {
+	Count   int            `json:"count"`
+	Filters map[string]any `json:"filters"`
+}
+
+type AggregateEntriesInput struct {
+	EntryQueryInput
+	GroupBy string `json:"group_by"`
+}
+
+// AggregateGroup counts the entries sharing a feed, day, or folder.
+type AggregateGroup struct {
+	// Key is the feed ID, the day as YYYY-MM-DD, or the folder name, empty
+	// for feeds outside any folder
+	Key string `json:"key"`
+	// Title is the feed's name when grouping by feed
+	Title  string `json:"title,omitempty"`
+	Count  int    `json:"count"`
+	Unread int    `json:"unread"`
+}
+
+type AggregateEntriesOutput struct {
+	GroupBy string           `json:"group_by"`
+	Groups  []AggregateGroup `json:"groups"`
+	Total   int              `json:"total"`
+	Filters map[string]any   `json:"filters"`
+}
+
+// aggregateGroupings are the group_by values aggregate_entries accepts.
+var aggregateGroupings = []string{"feed", "day", "folder"}
+
+func (s *Server) registerCountEntriesTool() {
+	props := entryQueryProperties()
+	props["profile"] = profileProperty
+	tool := mcp.Tool{
+		Name:        "count_entries",
+		Description: "Count the entries matching list_entries' filters without returning them. Use this to answer 'how many' questions, such as how many unread entries arrived today, instead of paging through list_entries. Use aggregate_entries for counts per feed, day, or folder.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: props,
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleCountEntries)
+}
+
+func (s *Server) registerAggregateEntriesTool() {
+	props := entryQueryProperties()
+	props["group_by"] = map[string]interface{}{
+		"type":        "string",
+		"enum":        aggregateGroupings,
+		"description": "How to group the entries: 'feed', 'day' (by published date, or first-seen date with first_seen, in tz), or 'folder' (the feed's OPML folder). Example: 'folder' with unread_only and since='week' for unread per folder this week",
+	}
+	props["profile"] = profileProperty
+	tool := mcp.Tool{
+		Name:        "aggregate_entries",
+		Description: "Count the entries matching list_entries' filters, grouped by feed, day, or folder, with each group's unread count. Feeds and folders come most entries first; days come oldest first. Use this for questions like 'how many unread per folder this week' without paging through entries.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: props,
+			Required:   []string{"group_by"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleAggregateEntries)
+}
+
+func (s *Server) handleCountEntries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input CountEntriesInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	query, err := s.parseEntryQuery(ctx, pc, input.EntryQueryInput)
+	if err != nil {
+		return nil, err
+	}
+
+	output := CountEntriesOutput{Filters: query.filters}
+	err = query.each(ctx, pc.store, func(*models.Entry) error {
+		output.Count++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+func (s *Server) handleAggregateEntries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input AggregateEntriesInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	if !slices.Contains(aggregateGroupings, input.GroupBy) {
+		return nil, fmt.Errorf("group_by must be one of feed, day, or folder, got %q", input.GroupBy)
+	}
+	query, err := s.parseEntryQuery(ctx, pc, input.EntryQueryInput)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := pc.store.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	feedByID := make(map[string]*models.Feed, len(feeds))
+	for _, feed := range feeds {
+		feedByID[feed.ID] = feed
+	}
+	folders := make(map[string]string)
+	if input.GroupBy == "folder" {
+		pc.opmlMu.RLock()
+		for _, f := range pc.opmlDoc.AllFeeds() {
+			folders[f.URL] = f.Folder
+		}
+		pc.opmlMu.RUnlock()
+	}
+
+	groups := make(map[string]*AggregateGroup)
+	total := 0
+	err = query.each(ctx, pc.store, func(entry *models.Entry) error {
+		var key string
+		switch input.GroupBy {
+		case "feed":
+			key = entry.FeedID
+		case "day":
+			key = entryDay(entry, query.filter.FirstSeen, query.loc)
+		case "folder":
+			if feed := feedByID[entry.FeedID]; feed != nil {
+				key = folders[feed.URL]
+			}
+		}
+		group := groups[key]
+		if group == nil {
+			group = &AggregateGroup{Key: key}
+			if feed := feedByID[key]; input.GroupBy == "feed" && feed != nil {
+				group.Title = feed.GetDisplayName()
+			}
+			groups[key] = group
+		}
+		group.Count++
+		if !entry.Read {
+			group.Unread++
+		}
+		total++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := AggregateEntriesOutput{GroupBy: input.GroupBy, Groups: []AggregateGroup{}, Total: total, Filters: query.filters}
+	for _, group := range groups {
+		output.Groups = append(output.Groups, *group)
+	}
+	slices.SortFunc(output.Groups, func(a, b AggregateGroup) int {
+		if input.GroupBy == "day" {
+			return cmp.Compare(a.Key, b.Key)
+		}
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Key, b.Key))
+	})
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// entryDay returns the day an entry falls on in loc: the day digest first
+// stored it with firstSeen, otherwise the day it was published, or first
+// stored if it has no date.
+func entryDay(entry *models.Entry, firstSeen bool, loc *time.Location) string {
+	at := entry.CreatedAt
+	if !firstSeen && entry.PublishedAt != nil {
+		at = *entry.PublishedAt
+	}
+	return at.In(loc).Format(time.DateOnly)
+}
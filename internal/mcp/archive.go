@@ -0,0 +1,110 @@
+// ABOUTME: archive_entry tool that snapshots an entry's link on the Wayback Machine
+// ABOUTME: Stores the snapshot URL on the entry so the article survives link rot
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type ArchiveEntryInput struct {
+	EntryID string `json:"entry_id"`
+	Refresh *bool  `json:"refresh,omitempty"`
+}
+
+type ArchiveEntryOutput struct {
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	ArchiveURL string      `json:"archive_url"`
+	Entry      EntryOutput `json:"entry"`
+}
+
+func (s *Server) registerArchiveEntryTool() {
+	tool := mcp.Tool{
+		Name:        "archive_entry",
+		Description: "Save a snapshot of an entry's link on the Wayback Machine (web.archive.org) and store the snapshot URL on the entry as archive_url, so the article can still be read if the original page disappears. If a fresh capture fails, the most recent existing snapshot is used. An entry that already has an archive_url is returned as-is unless refresh is true. Makes network requests and can take up to a minute.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"entry_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The entry ID or ID prefix. Example: 'abc12345'",
+				},
+				"refresh": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Request a new snapshot even if the entry is already archived. Default: false.",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"entry_id"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleArchiveEntry)
+}
+
+func (s *Server) handleArchiveEntry(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input ArchiveEntryInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	entry, err := pc.store.GetEntryByIDOrPrefix(ctx, input.EntryID)
+	if err != nil {
+		return nil, fmt.Errorf("entry not found: %s", input.EntryID)
+	}
+	if entry.Link == nil || *entry.Link == "" {
+		return nil, fmt.Errorf("entry %s has no link to archive", entry.ID)
+	}
+
+	refresh := input.Refresh != nil && *input.Refresh
+	message := "Entry already archived"
+	if entry.ArchiveURL == nil || refresh {
+		snapshot, err := s.snapshot(ctx, *entry.Link)
+		if err != nil {
+			return nil, fmt.Errorf("failed to archive entry: %w", err)
+		}
+		entry.ArchiveURL = &snapshot
+		if err := pc.store.UpdateEntry(ctx, entry); err != nil {
+			return nil, fmt.Errorf("failed to save archive URL: %w", err)
+		}
+		message = "Archived " + *entry.Link
+	}
+
+	output := ArchiveEntryOutput{
+		Success:    true,
+		Message:    message,
+		ArchiveURL: *entry.ArchiveURL,
+		Entry: EntryOutput{
+			ID:            entry.ID,
+			FeedID:        entry.FeedID,
+			Title:         entry.Title,
+			Link:          entry.Link,
+			Author:        entry.Author,
+			PublishedAt:   entry.PublishedAt,
+			Read:          entry.Read,
+			ReadAt:        entry.ReadAt,
+			ArchiveURL:    entry.ArchiveURL,
+			ImageURL:      entry.ImageURL,
+			DiscussionURL: entry.DiscussionURL,
+			Score:         entry.Score,
+			CommentCount:  entry.CommentCount,
+			KeepUnread:    entry.KeepUnread,
+			CreatedAt:     entry.CreatedAt,
+		},
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
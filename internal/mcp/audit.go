@@ -0,0 +1,44 @@
+// ABOUTME: Tool call auditing for the MCP server
+// ABOUTME: Middleware that records every tool invocation to the local audit log
+
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/harper/digest/internal/audit"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// auditTool wraps a tool handler so each call is recorded with its status and timing.
+func (s *Server) auditTool(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, req)
+
+		profile := extractProfile(req)
+		if profile == "" {
+			profile = s.defaultProfile
+		}
+		record := audit.Record{
+			Time:       start.UTC(),
+			Tool:       req.Params.Name,
+			Profile:    profile,
+			ArgsHash:   audit.HashArgs(req.GetArguments()),
+			Status:     audit.StatusOK,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			record.Status = audit.StatusError
+			record.Error = err.Error()
+		} else if result != nil && result.IsError {
+			record.Status = audit.StatusError
+		}
+
+		// A full disk or unwritable log shouldn't fail the agent's call
+		_ = s.auditLog.Append(record)
+		return result, err
+	}
+}
@@ -0,0 +1,142 @@
+// ABOUTME: get_changes tool that returns entries created, changed or deleted since an opaque cursor
+// ABOUTME: Lets external tools sync new entries and read state incrementally instead of re-listing
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/harper/digest/internal/storage"
+)
+
+// defaultChangesLimit is how many entries a page holds when limit isn't given.
+const defaultChangesLimit = 100
+
+type GetChangesInput struct {
+	Cursor *string `json:"cursor,omitempty"`
+	Limit  *int    `json:"limit,omitempty"`
+}
+
+// ChangedEntry is an entry in its current state with when it last changed.
+type ChangedEntry struct {
+	EntryOutput
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeletedEntryOutput is an entry that was removed, on its own or with its feed.
+type DeletedEntryOutput struct {
+	ID        string    `json:"id"`
+	FeedID    string    `json:"feed_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type GetChangesOutput struct {
+	Entries []ChangedEntry       `json:"entries"`
+	Deleted []DeletedEntryOutput `json:"deleted"`
+	Count   int                  `json:"count"`
+	Cursor  string               `json:"cursor"`
+	More    bool                 `json:"more"`
+}
+
+func (s *Server) registerGetChangesTool() {
+	tool := mcp.Tool{
+		Name:        "get_changes",
+		Description: "Return entries created or changed (read, unread, edited, moved) since a cursor, oldest change first, for syncing incrementally instead of re-listing everything. Omit cursor on the first call to get every entry; then pass back the returned cursor each time. While more is true, call again right away for the next page. Entries come in their current state without content; use get_entry for that. Entries removed since the cursor, on their own or with their feed, are listed under deleted by ID; drop them from your copy.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "The cursor from the previous get_changes call. Omit to start from the beginning.",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum entries per page. Default: 100.",
+				},
+				"profile": profileProperty,
+			},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleGetChanges)
+}
+
+func (s *Server) handleGetChanges(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input GetChangesInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	limit := defaultChangesLimit
+	if input.Limit != nil {
+		if *input.Limit <= 0 {
+			return nil, fmt.Errorf("limit must be positive, got %d", *input.Limit)
+		}
+		limit = *input.Limit
+	}
+	cursor := ""
+	if input.Cursor != nil {
+		cursor = *input.Cursor
+	}
+
+	changes, err := pc.store.GetChangesSince(ctx, cursor, limit)
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		return nil, fmt.Errorf("invalid cursor: pass one returned by get_changes, or omit it to start over")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes: %w", err)
+	}
+
+	output := GetChangesOutput{
+		Entries: make([]ChangedEntry, 0, len(changes.Entries)),
+		Deleted: make([]DeletedEntryOutput, 0, len(changes.Deleted)),
+		Count:   len(changes.Entries) + len(changes.Deleted),
+		Cursor:  changes.Cursor,
+		More:    changes.More,
+	}
+	for _, entry := range changes.Entries {
+		output.Entries = append(output.Entries, ChangedEntry{
+			EntryOutput: EntryOutput{
+				ID:            entry.ID,
+				FeedID:        entry.FeedID,
+				Title:         entry.Title,
+				Link:          entry.Link,
+				Author:        entry.Author,
+				PublishedAt:   entry.PublishedAt,
+				Read:          entry.Read,
+				ReadAt:        entry.ReadAt,
+				ArchiveURL:    entry.ArchiveURL,
+				ImageURL:      entry.ImageURL,
+				DiscussionURL: entry.DiscussionURL,
+				Score:         entry.Score,
+				CommentCount:  entry.CommentCount,
+				KeepUnread:    entry.KeepUnread,
+				CreatedAt:     entry.CreatedAt,
+			},
+			UpdatedAt: entry.UpdatedAt,
+		})
+	}
+
+	for _, deleted := range changes.Deleted {
+		output.Deleted = append(output.Deleted, DeletedEntryOutput{
+			ID:        deleted.ID,
+			FeedID:    deleted.FeedID,
+			DeletedAt: deleted.DeletedAt,
+		})
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
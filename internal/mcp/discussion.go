@@ -0,0 +1,123 @@
+// ABOUTME: get_discussion tool that reads the comment thread of an entry
+// ABOUTME: Returns comments from the thread's feed for HN, Reddit, and blogs with comment feeds
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/discussion"
+)
+
+// defaultDiscussionLimit is how many comments get_discussion returns when
+// limit isn't given.
+const defaultDiscussionLimit = 20
+
+type GetDiscussionInput struct {
+	EntryID string `json:"entry_id"`
+	Limit   *int   `json:"limit,omitempty"`
+}
+
+type CommentOutput struct {
+	Author      string     `json:"author,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Link        string     `json:"link,omitempty"`
+	Content     string     `json:"content"`
+}
+
+type GetDiscussionOutput struct {
+	EntryID       string          `json:"entry_id"`
+	DiscussionURL string          `json:"discussion_url,omitempty"`
+	CommentsFeed  string          `json:"comments_feed,omitempty"`
+	Comments      []CommentOutput `json:"comments"`
+	Count         int             `json:"count"`
+}
+
+func (s *Server) registerGetDiscussionTool() {
+	tool := mcp.Tool{
+		Name:        "get_discussion",
+		Description: "Read the comment thread of an entry, such as a Hacker News or Reddit post or a blog article with a comments feed. Returns the thread's web page as discussion_url and its comments, oldest or top first as the site orders them, in Markdown. Fails when the entry has no known comment thread. Makes a network request.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"entry_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The entry ID or ID prefix. Example: 'abc12345'",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum comments to return. Default: 20.",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"entry_id"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleGetDiscussion)
+}
+
+func (s *Server) handleGetDiscussion(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input GetDiscussionInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	limit := defaultDiscussionLimit
+	if input.Limit != nil {
+		if *input.Limit <= 0 {
+			return nil, fmt.Errorf("limit must be positive, got %d", *input.Limit)
+		}
+		limit = *input.Limit
+	}
+
+	entry, err := pc.store.GetEntryByIDOrPrefix(ctx, input.EntryID)
+	if err != nil {
+		return nil, fmt.Errorf("entry not found: %s", input.EntryID)
+	}
+	output := GetDiscussionOutput{
+		EntryID:       entry.ID,
+		DiscussionURL: discussion.Thread(entry),
+		CommentsFeed:  discussion.FeedURL(entry),
+		Comments:      []CommentOutput{},
+	}
+	if output.CommentsFeed == "" {
+		if output.DiscussionURL == "" {
+			return nil, fmt.Errorf("entry %s has no discussion link", entry.ID)
+		}
+		return nil, fmt.Errorf("entry %s has no comments feed; its thread is at %s", entry.ID, output.DiscussionURL)
+	}
+
+	feed, err := pc.store.GetFeed(ctx, entry.FeedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+	comments, err := discussion.Fetch(ctx, output.CommentsFeed, limit, feed.LocalNetwork)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	for _, c := range comments {
+		output.Comments = append(output.Comments, CommentOutput{
+			Author:      c.Author,
+			PublishedAt: c.Published,
+			Link:        c.Link,
+			Content:     content.ToMarkdown(c.Content),
+		})
+	}
+	output.Count = len(output.Comments)
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
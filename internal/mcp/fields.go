@@ -0,0 +1,60 @@
+// ABOUTME: Field projection for list_entries, so a headline listing returns only the fields asked for
+// ABOUTME: Validates field names against EntryOutput's JSON keys and always keeps the entry ID
+
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// entryFieldNames are the EntryOutput fields list_entries' fields option
+// can pick, by their JSON keys.
+var entryFieldNames = []string{
+	"id", "feed_id", "title", "link", "author", "published_at", "read", "read_at",
+	"archive_url", "image_url", "discussion_url", "score", "comment_count", "keep_unread", "created_at",
+}
+
+// checkEntryFields returns the fields asked for, lowercased, or an error
+// naming the first one EntryOutput doesn't have.
+func checkEntryFields(fields []string) ([]string, error) {
+	var checked []string
+	for _, name := range fields {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(entryFieldNames, name) {
+			return nil, fmt.Errorf("unknown field %q: use %s", name, strings.Join(entryFieldNames, ", "))
+		}
+		checked = append(checked, name)
+	}
+	return checked, nil
+}
+
+// projectEntries keeps only the named fields of each entry, plus its ID so
+// it can still be passed to get_entry. Fields an entry doesn't have stay
+// left out, as they are from a full listing.
+func projectEntries(entries []EntryOutput, fields []string) ([]map[string]json.RawMessage, error) {
+	projected := make([]map[string]json.RawMessage, 0, len(entries))
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode entry: %w", err)
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, fmt.Errorf("failed to encode entry: %w", err)
+		}
+		kept := map[string]json.RawMessage{"id": all["id"]}
+		for _, name := range fields {
+			if value, ok := all[name]; ok {
+				kept[name] = value
+			}
+		}
+		projected = append(projected, kept)
+	}
+	return projected, nil
+}
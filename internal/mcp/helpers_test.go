@@ -96,7 +96,7 @@ func TestParseDateString(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseDateString(tt.input)
+			result, err := parseDateString(tt.input, time.Local)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseDateString(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
 				return
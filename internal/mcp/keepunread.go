@@ -0,0 +1,90 @@
+// ABOUTME: keep_unread tool that pins entries unread to come back to
+// ABOUTME: Pinned entries are skipped by bulk_mark_read, pruning, and list_entries mark_read
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type KeepUnreadInput struct {
+	EntryID string `json:"entry_id"`
+	Keep    *bool  `json:"keep,omitempty"`
+}
+
+func (s *Server) registerKeepUnreadTool() {
+	tool := mcp.Tool{
+		Name:        "keep_unread",
+		Description: "Pin an entry unread to come back to it, a lighter alternative to starring. The entry is marked unread if it was read, and then bulk_mark_read, pruning to a feed's max entries, and list_entries mark_read all leave it unread. Marking it read with mark_read releases the pin, as does keep=false. Use list_entries with kept_only to see pinned entries. Returns the updated entry.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"entry_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The unique identifier of the entry to pin. Example: 'abc12345-6789-def0-1234-56789abcdef0'",
+				},
+				"keep": map[string]interface{}{
+					"type":        "boolean",
+					"description": "False releases the pin and leaves the entry's read state alone. Default: true.",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"entry_id"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleKeepUnread)
+}
+
+func (s *Server) handleKeepUnread(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input KeepUnreadInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	if _, err := pc.store.GetEntry(ctx, input.EntryID); err != nil {
+		return nil, fmt.Errorf("entry not found: %s", input.EntryID)
+	}
+
+	keep := input.Keep == nil || *input.Keep
+	if err := pc.store.SetEntryKeepUnread(ctx, input.EntryID, keep); err != nil {
+		return nil, fmt.Errorf("failed to update entry: %w", err)
+	}
+
+	entry, err := pc.store.GetEntry(ctx, input.EntryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload entry: %w", err)
+	}
+
+	output := EntryOutput{
+		ID:            entry.ID,
+		FeedID:        entry.FeedID,
+		Title:         entry.Title,
+		Link:          entry.Link,
+		Author:        entry.Author,
+		PublishedAt:   entry.PublishedAt,
+		Read:          entry.Read,
+		ReadAt:        entry.ReadAt,
+		ArchiveURL:    entry.ArchiveURL,
+		ImageURL:      entry.ImageURL,
+		DiscussionURL: entry.DiscussionURL,
+		Score:         entry.Score,
+		CommentCount:  entry.CommentCount,
+		KeepUnread:    entry.KeepUnread,
+		CreatedAt:     entry.CreatedAt,
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
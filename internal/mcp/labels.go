@@ -0,0 +1,337 @@
+// ABOUTME: Label tools for grouping feeds beyond folders, where a feed can carry several labels
+// ABOUTME: list_labels, label_feed, unlabel_feed, rename_label, and delete_label, kept in the OPML file
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/harper/digest/internal/opml"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type LabelFeedSummary struct {
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Folder string `json:"folder,omitempty"`
+}
+
+type LabelOutput struct {
+	Label     string             `json:"label"`
+	FeedCount int                `json:"feed_count"`
+	Feeds     []LabelFeedSummary `json:"feeds"`
+}
+
+type ListLabelsOutput struct {
+	Labels []LabelOutput `json:"labels"`
+	Count  int           `json:"count"`
+}
+
+type LabelFeedInput struct {
+	URL    string   `json:"url"`
+	Labels []string `json:"labels"`
+}
+
+type LabelFeedOutput struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	URL     string   `json:"url"`
+	Labels  []string `json:"labels"`
+}
+
+type RenameLabelInput struct {
+	Label    string `json:"label"`
+	NewLabel string `json:"new_label"`
+}
+
+type DeleteLabelInput struct {
+	Label string `json:"label"`
+}
+
+type ChangeLabelOutput struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Feeds   int    `json:"feeds"`
+}
+
+func (s *Server) registerListLabelsTool() {
+	tool := mcp.Tool{
+		Name:        "list_labels",
+		Description: "List the labels on feeds with the feeds carrying each. Labels group feeds across folders: a feed sits in one folder but can carry any number of labels, such as 'Go' and 'Newsletters'. Pass a label to list_entries, count_entries, or aggregate_entries to filter by it.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"profile": profileProperty,
+			},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleListLabels)
+}
+
+func (s *Server) registerLabelFeedTool() {
+	tool := mcp.Tool{
+		Name:        "label_feed",
+		Description: "Add labels to a feed, keeping any it already has. Labels are matched ignoring case and can't contain commas or slashes. OPML exports carry them as each feed's category. Returns the feed's labels.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"url": map[string]interface{}{
+					"type":        "string",
+					"description": "The feed URL to label. Example: 'https://go.dev/blog/feed.atom'",
+				},
+				"labels": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Labels to add. Example: ['Go', 'Newsletters']",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"url", "labels"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleLabelFeed)
+}
+
+func (s *Server) registerUnlabelFeedTool() {
+	tool := mcp.Tool{
+		Name:        "unlabel_feed",
+		Description: "Remove labels from a feed. Labels it doesn't have are ignored. Returns the feed's remaining labels.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"url": map[string]interface{}{
+					"type":        "string",
+					"description": "The feed URL to remove labels from. Example: 'https://go.dev/blog/feed.atom'",
+				},
+				"labels": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Labels to remove. Example: ['Newsletters']",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"url", "labels"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleUnlabelFeed)
+}
+
+func (s *Server) registerRenameLabelTool() {
+	tool := mcp.Tool{
+		Name:        "rename_label",
+		Description: "Rename a label on every feed carrying it. Renaming to a label a feed already has merges the two. Returns how many feeds changed.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"label": map[string]interface{}{
+					"type":        "string",
+					"description": "The label to rename. Example: 'golang'",
+				},
+				"new_label": map[string]interface{}{
+					"type":        "string",
+					"description": "Its new name. Example: 'Go'",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"label", "new_label"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleRenameLabel)
+}
+
+func (s *Server) registerDeleteLabelTool() {
+	tool := mcp.Tool{
+		Name:        "delete_label",
+		Description: "Remove a label from every feed carrying it. The feeds themselves are kept. Returns how many feeds changed.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"label": map[string]interface{}{
+					"type":        "string",
+					"description": "The label to delete. Example: 'Newsletters'",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"label"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleDeleteLabel)
+}
+
+func (s *Server) handleListLabels(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	pc.opmlMu.RLock()
+	labels := pc.opmlDoc.Labels()
+	feeds, _ := s.scope.filterOPML(pc.opmlDoc.AllFeeds(), nil)
+	pc.opmlMu.RUnlock()
+
+	output := ListLabelsOutput{Labels: []LabelOutput{}}
+	for _, label := range labels {
+		lo := LabelOutput{Label: label, Feeds: []LabelFeedSummary{}}
+		for _, feed := range feeds {
+			if slices.ContainsFunc(feed.Labels, func(l string) bool { return strings.EqualFold(l, label) }) {
+				lo.Feeds = append(lo.Feeds, LabelFeedSummary{URL: feed.URL, Title: feed.Title, Folder: feed.Folder})
+			}
+		}
+		// Labels only out-of-scope feeds carry stay hidden
+		if len(lo.Feeds) == 0 {
+			continue
+		}
+		lo.FeedCount = len(lo.Feeds)
+		output.Labels = append(output.Labels, lo)
+	}
+	output.Count = len(output.Labels)
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+func (s *Server) handleLabelFeed(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.changeFeedLabels(ctx, req, true)
+}
+
+func (s *Server) handleUnlabelFeed(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.changeFeedLabels(ctx, req, false)
+}
+
+// changeFeedLabels adds labels to a feed, or removes them, and saves the
+// OPML file if that changed anything.
+func (s *Server) changeFeedLabels(ctx context.Context, req mcp.CallToolRequest, add bool) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input LabelFeedInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	if len(input.Labels) == 0 {
+		return nil, fmt.Errorf("labels is required")
+	}
+	labels := make([]string, 0, len(input.Labels))
+	for _, label := range input.Labels {
+		label, err := opml.CheckLabel(label)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	// The scoped store hides feeds outside the scope
+	if _, err := pc.store.GetFeedByURL(ctx, input.URL); err != nil {
+		return nil, fmt.Errorf("feed not found: %s", input.URL)
+	}
+
+	pc.opmlMu.Lock()
+	defer pc.opmlMu.Unlock()
+	changed := 0
+	for _, label := range labels {
+		var ok bool
+		if add {
+			ok, err = pc.opmlDoc.LabelFeed(input.URL, label)
+		} else {
+			ok, err = pc.opmlDoc.UnlabelFeed(input.URL, label)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("feed not found in OPML: %s", input.URL)
+		}
+		if ok {
+			changed++
+		}
+	}
+	if changed > 0 {
+		if err := pc.opmlDoc.WriteFile(pc.opmlPath); err != nil {
+			return nil, fmt.Errorf("failed to write OPML file: %w", err)
+		}
+	}
+
+	output := LabelFeedOutput{Success: true, URL: input.URL, Labels: []string{}}
+	for _, feed := range pc.opmlDoc.AllFeeds() {
+		if feed.URL == input.URL && feed.Labels != nil {
+			output.Labels = feed.Labels
+		}
+	}
+	verb := "Added"
+	if !add {
+		verb = "Removed"
+	}
+	output.Message = fmt.Sprintf("%s %d label(s)", verb, changed)
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+func (s *Server) handleRenameLabel(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var input RenameLabelInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	newLabel, err := opml.CheckLabel(input.NewLabel)
+	if err != nil {
+		return nil, err
+	}
+	return s.relabelFeeds(req, input.Label, func(doc *opml.Document, url string) {
+		_, _ = doc.UnlabelFeed(url, input.Label)
+		_, _ = doc.LabelFeed(url, newLabel)
+	}, fmt.Sprintf("Renamed %s to %s", input.Label, newLabel))
+}
+
+func (s *Server) handleDeleteLabel(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var input DeleteLabelInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	return s.relabelFeeds(req, input.Label, func(doc *opml.Document, url string) {
+		_, _ = doc.UnlabelFeed(url, input.Label)
+	}, fmt.Sprintf("Deleted %s", input.Label))
+}
+
+// relabelFeeds applies change to every in-scope feed labelled label and
+// saves the OPML file. Feeds outside the scope keep the label.
+func (s *Server) relabelFeeds(req mcp.CallToolRequest, label string, change func(doc *opml.Document, url string), message string) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+	if label == "" {
+		return nil, fmt.Errorf("label is required")
+	}
+
+	pc.opmlMu.Lock()
+	defer pc.opmlMu.Unlock()
+	feeds, _ := s.scope.filterOPML(pc.opmlDoc.FeedsWithLabel(label), nil)
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("no feeds are labelled %q", label)
+	}
+	for _, feed := range feeds {
+		change(pc.opmlDoc, feed.URL)
+	}
+	if err := pc.opmlDoc.WriteFile(pc.opmlPath); err != nil {
+		return nil, fmt.Errorf("failed to write OPML file: %w", err)
+	}
+
+	output := ChangeLabelOutput{
+		Success: true,
+		Message: fmt.Sprintf("%s on %d feed(s)", message, len(feeds)),
+		Feeds:   len(feeds),
+	}
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
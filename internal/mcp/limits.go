@@ -0,0 +1,74 @@
+// ABOUTME: Per-session rate limits for MCP mutation tools
+// ABOUTME: Caps feed adds/removes per hour and the size of a single bulk_mark_read
+package mcp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/harper/digest/internal/config"
+)
+
+// limitWindow is the sliding window the per-hour limits count over.
+const limitWindow = time.Hour
+
+// WithLimits overrides the mutation limits from the config file.
+func WithLimits(limits config.MCPLimits) Option {
+	return func(s *Server) {
+		s.limits = limits
+	}
+}
+
+// rateLimiter counts successful calls per tool over a sliding window.
+// It lives for one server process, so limits reset when the session ends.
+type rateLimiter struct {
+	mu    sync.Mutex
+	calls map[string][]time.Time
+	now   func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		calls: make(map[string][]time.Time),
+		now:   time.Now,
+	}
+}
+
+// check returns an error if tool has already been called limit times within
+// the window. A negative limit means unlimited.
+func (r *rateLimiter) check(tool string, limit int) error {
+	if limit < 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recent := r.prune(tool)
+	if len(recent) < limit {
+		return nil
+	}
+	wait := recent[0].Add(limitWindow).Sub(r.now()).Round(time.Minute)
+	return fmt.Errorf("rate limit reached: %s is limited to %d calls per hour in this session; try again in %s or ask the user to raise mcp_limits in config.json", tool, limit, wait)
+}
+
+// record notes a successful call of tool.
+func (r *rateLimiter) record(tool string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[tool] = append(r.prune(tool), r.now())
+}
+
+// prune drops calls older than the window and returns the rest. Callers hold mu.
+func (r *rateLimiter) prune(tool string) []time.Time {
+	cutoff := r.now().Add(-limitWindow)
+	calls := r.calls[tool]
+	i := 0
+	for i < len(calls) && !calls[i].After(cutoff) {
+		i++
+	}
+	calls = calls[i:]
+	r.calls[tool] = calls
+	return calls
+}
@@ -0,0 +1,159 @@
+// ABOUTME: preview_feed tool that fetches and parses a feed without subscribing to it
+// ABOUTME: Returns the feed's title and latest entries so it can be judged before add_feed
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/feedurl"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/parse"
+)
+
+// defaultPreviewLimit is how many entries preview_feed returns when limit
+// isn't given.
+const defaultPreviewLimit = 10
+
+type PreviewFeedInput struct {
+	URL          string `json:"url"`
+	Limit        *int   `json:"limit,omitempty"`
+	LocalNetwork *bool  `json:"local_network,omitempty"`
+}
+
+type PreviewEntryOutput struct {
+	Title       string     `json:"title"`
+	Link        string     `json:"link,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Excerpt     string     `json:"excerpt,omitempty"`
+}
+
+type PreviewFeedOutput struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	// SubscribedAs is the ID of the feed when it is already subscribed,
+	// possibly under another scheme, www, or trailing slash.
+	SubscribedAs string               `json:"subscribed_as,omitempty"`
+	EntryCount   int                  `json:"entry_count"`
+	Entries      []PreviewEntryOutput `json:"entries"`
+}
+
+func (s *Server) registerPreviewFeedTool() {
+	tool := mcp.Tool{
+		Name:        "preview_feed",
+		Description: "Fetch and parse a feed without subscribing to it, to judge whether it is worth adding with add_feed. Returns the feed's title, how many entries it publishes, and its latest entries with a one-paragraph excerpt each. Nothing is stored. subscribed_as names the feed ID when the feed is already subscribed. The URL must be the feed itself, not a web page. Makes a network request.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"url": map[string]interface{}{
+					"type":        "string",
+					"description": "The RSS or Atom feed URL. Example: 'https://example.com/feed.xml'",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum entries to return, newest first. Default: 10.",
+				},
+				"local_network": map[string]interface{}{
+					"type":        "boolean",
+					"description": "If true, allows fetching from local network (private IP) addresses. Default: false",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"url"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handlePreviewFeed)
+}
+
+func (s *Server) handlePreviewFeed(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input PreviewFeedInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	canonical, err := feedurl.Canonical(input.URL)
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultPreviewLimit
+	if input.Limit != nil {
+		if *input.Limit <= 0 {
+			return nil, fmt.Errorf("limit must be positive, got %d", *input.Limit)
+		}
+		limit = *input.Limit
+	}
+	localNetwork := input.LocalNetwork != nil && *input.LocalNetwork
+
+	result, err := fetch.FetchPage(ctx, canonical, fetch.PageOptions{AllowLocalNetwork: localNetwork})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	parsed, err := parse.Parse(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed (is %s a web page rather than a feed?): %w", canonical, err)
+	}
+
+	output := PreviewFeedOutput{
+		URL:        canonical,
+		Title:      parsed.Title,
+		EntryCount: len(parsed.Entries),
+		Entries:    []PreviewEntryOutput{},
+	}
+	feeds, err := pc.store.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	for _, feed := range feeds {
+		if feedurl.Key(feed.URL) == feedurl.Key(canonical) {
+			output.SubscribedAs = feed.ID
+			break
+		}
+	}
+
+	for _, e := range newestParsed(parsed.Entries, limit) {
+		excerpt, _ := content.Paragraphs(content.ToMarkdown(e.Content), 1)
+		output.Entries = append(output.Entries, PreviewEntryOutput{
+			Title:       e.Title,
+			Link:        e.Link,
+			Author:      e.Author,
+			PublishedAt: e.PublishedAt,
+			Excerpt:     excerpt,
+		})
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// newestParsed returns up to limit entries, newest first. Undated entries
+// keep their feed order after the dated ones.
+func newestParsed(entries []parse.ParsedEntry, limit int) []parse.ParsedEntry {
+	sorted := make([]parse.ParsedEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].PublishedAt, sorted[j].PublishedAt
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.After(*b)
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
@@ -6,10 +6,17 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/harper/digest/internal/timeutil"
+	"github.com/harper/digest/internal/trends"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// promptTrendingLimit is how many trending topics the daily digest prompt lists.
+const promptTrendingLimit = 8
+
 func (s *Server) registerPrompts() {
 	s.registerDailyDigestPrompt()
 	s.registerCatchUpPrompt()
@@ -28,7 +35,7 @@ func (s *Server) registerDailyDigestPrompt() {
 }
 
 //nolint:funlen // Prompt handlers contain large template strings
-func (s *Server) handleDailyDigest(_ context.Context, _ mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+func (s *Server) handleDailyDigest(ctx context.Context, _ mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
 	template := `# Daily Digest
 
 ## Overview
@@ -107,15 +114,17 @@ Create a brief digest of key takeaways.
 **Summary structure:**
 - **Top Stories:** 2-3 most important items with key points
 - **Notable Updates:** 3-5 significant but not urgent items
-- **Trending Topics:** Themes or patterns across multiple entries
+- **Trending Topics:** Themes or patterns across multiple entries (see the trending_topics tool, or the list at the end of this prompt)
 - **Action Items:** Follow-ups, things to investigate, or share
 
+When several feeds cover the same story under near-identical titles, list it once with every source rather than once per feed, e.g. "Major Framework v5.0 Released (Framework Blog, Hacker News, Lobsters)".
+
 **Example summary:**
 
     Daily Digest - December 11, 2025
 
     Top Stories:
-    1. Major Framework v5.0 Released - Breaking changes to API, migration guide available
+    1. Major Framework v5.0 Released (Framework Blog, Hacker News, Lobsters) - Breaking changes to API, migration guide available
     2. Security Advisory: CVE-2025-1234 - Patch available, affects production systems
     3. Industry Analysis: AI Coding Tools Adoption Study - 67%% of devs now use daily
 
@@ -196,6 +205,7 @@ Generated concise summary with top 3 stories, trending topics, 1 action item
 5. Generate summary with key takeaways
 6. Mark entries read and clean up
 `
+	template += s.trendingSection(ctx)
 
 	return &mcp.GetPromptResult{
 		Description: "Daily digest workflow for today's feed entries",
@@ -211,6 +221,32 @@ Generated concise summary with top 3 stories, trending topics, 1 action item
 	}, nil
 }
 
+// trendingSection lists this week's trending topics for the daily digest
+// prompt, so the summary's Trending Topics section starts from real data.
+// It returns "" when there is nothing to report.
+func (s *Server) trendingSection(ctx context.Context) string {
+	pc, err := s.getProfile("")
+	if err != nil {
+		return ""
+	}
+	loc, err := pc.location(nil)
+	if err != nil {
+		return ""
+	}
+	report, err := s.trendingReport(ctx, pc, timeutil.StartOfWeekIn(loc), time.Now(), nil, trends.Options{Limit: promptTrendingLimit})
+	if err != nil || len(report.Topics) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n## Trending Topics This Week\n")
+	fmt.Fprintf(&b, "Computed by trending_topics from %d entries:\n", report.Entries)
+	for _, topic := range report.Topics {
+		fmt.Fprintf(&b, "- %s (%d entries)\n", topic.Term, topic.Entries)
+	}
+	return b.String()
+}
+
 func (s *Server) registerCatchUpPrompt() {
 	s.mcpServer.AddPrompt(
 		mcp.Prompt{
@@ -0,0 +1,110 @@
+// ABOUTME: prune_entries tool that trims feeds to their max_entries retention limit
+// ABOUTME: Returns the same per-feed plan as 'digest prune', and with dry_run deletes nothing
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/runlock"
+	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+type PruneEntriesInput struct {
+	Feed   *string `json:"feed,omitempty"`
+	DryRun *bool   `json:"dry_run,omitempty"`
+}
+
+// PruneEntriesOutput is the prune plan, plus whether it was carried out.
+type PruneEntriesOutput struct {
+	*feedsync.PrunePlan
+	DryRun  bool `json:"dry_run"`
+	Deleted int  `json:"deleted"`
+}
+
+func (s *Server) registerPruneEntriesTool() {
+	tool := mcp.Tool{
+		Name:        "prune_entries",
+		Description: "Delete the oldest entries of feeds holding more than their max_entries (see update_feed); syncs prune synced feeds the same way. Entries kept unread are never pruned. Returns the plan per feed: entries stored and to delete, entries kept unread past the limit, and the room they take (markdown files, search index rows, and bytes, approximate for SQLite; 'measured' is false when the store can't tell). Call with dry_run=true first to review the plan without deleting anything.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"feed": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional feed URL or ID prefix (min 6 chars) to prune only that feed. If omitted, prunes every feed with a max_entries.",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "If true, only return the plan without deleting anything. Default: false",
+				},
+				"profile": profileProperty,
+			},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handlePruneEntries)
+}
+
+func (s *Server) handlePruneEntries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input PruneEntriesInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	dryRun := input.DryRun != nil && *input.DryRun
+
+	var feeds []*models.Feed
+	if input.Feed != nil {
+		feed, err := pc.store.GetFeedByURLOrPrefix(ctx, *input.Feed)
+		if err != nil {
+			return nil, fmt.Errorf("feed not found: %s", *input.Feed)
+		}
+		feeds = []*models.Feed{feed}
+	} else if feeds, err = pc.store.ListFeeds(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	// Don't delete entries out from under a CLI or cron sync
+	if !dryRun {
+		lock, err := runlock.Acquire(pc.lockPath, 0)
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Release()
+	}
+
+	plan, err := feedsync.PlanPrune(ctx, pc.store, feeds)
+	if err != nil {
+		return nil, err
+	}
+	// The server's scope and team views can't measure; the store under them can
+	if sizer, ok := pc.base.(storage.Footprinter); ok {
+		if err := plan.Measure(ctx, sizer); err != nil {
+			return nil, err
+		}
+	}
+
+	output := PruneEntriesOutput{PrunePlan: plan, DryRun: dryRun}
+	if !dryRun {
+		output.Deleted, err = plan.Apply(ctx, pc.store)
+		if err != nil {
+			return nil, err
+		}
+		pc.refreshUnreadCounts(ctx)
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
@@ -0,0 +1,188 @@
+// ABOUTME: Entry filters shared by list_entries, count_entries, and aggregate_entries
+// ABOUTME: Parses dates and scores into a storage filter and applies fuzzy author matching after the query
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/harper/digest/internal/authors"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// EntryQueryInput holds the filters every entry query tool accepts.
+type EntryQueryInput struct {
+	FeedID     *string `json:"feed_id,omitempty"`
+	Label      *string `json:"label,omitempty"`
+	UnreadOnly *bool   `json:"unread_only,omitempty"`
+	Since      *string `json:"since,omitempty"`
+	Until      *string `json:"until,omitempty"`
+	Timezone   *string `json:"tz,omitempty"`
+	Author     *string `json:"author,omitempty"`
+	FirstSeen  bool    `json:"first_seen,omitempty"`
+	NewOnly    bool    `json:"new_only,omitempty"`
+	MinScore   *int    `json:"min_score,omitempty"`
+	KeptOnly   bool    `json:"kept_only,omitempty"`
+}
+
+// entryQuery is a parsed EntryQueryInput.
+type entryQuery struct {
+	// filter never loads content; a tool that needs it asks again.
+	filter *storage.EntryFilter
+	// author is matched fuzzily after the query, when set.
+	author string
+	// filters records the filters applied, for the tool's output.
+	filters map[string]any
+	// loc is the time zone dates were resolved in.
+	loc *time.Location
+}
+
+// entryQueryProperties returns the input schema for EntryQueryInput's
+// filters, for a tool to add its own to.
+func entryQueryProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"feed_id": map[string]interface{}{
+			"type":        "string",
+			"description": "Optional feed ID to filter entries. Only entries from this feed will be returned. Example: 'abc12345-1234-1234-1234-123456789abc'",
+		},
+		"label": map[string]interface{}{
+			"type":        "string",
+			"description": "Optional label to filter entries. Only entries from feeds carrying this label will be returned; see list_labels. Example: 'Newsletters'",
+		},
+		"unread_only": map[string]interface{}{
+			"type":        "boolean",
+			"description": "If true, returns only unread entries. If false or omitted, returns all entries. Example: true",
+		},
+		"since": map[string]interface{}{
+			"type":        "string",
+			"description": "Only return entries published on or after this date. Accepts 'today', 'yesterday', 'week', 'month', 'last week', ISO dates (YYYY-MM-DD), relative dates ('3 days ago', '2 weeks', 'last monday'), or a range 'from..until' (e.g. '2024-01-01..2024-02-01', until exclusive). Example: 'today' for today's entries",
+		},
+		"until": map[string]interface{}{
+			"type":        "string",
+			"description": "Only return entries published before this date. Accepts the same dates as since, but not ranges. Example: 'today' for yesterday and earlier",
+		},
+		"tz": tzProperty,
+		"author": map[string]interface{}{
+			"type":        "string",
+			"description": "Only return entries by this author, matched fuzzily across feeds: 'j doe' and 'jnae doe' both find 'Jane Doe'. Co-authors and 'email (Name)' fields are handled. Example: 'jane doe'",
+		},
+		"first_seen": map[string]interface{}{
+			"type":        "boolean",
+			"description": "If true, since, until, and the ordering use created_at, when digest first fetched each entry, instead of published_at. Example: true with since='today' for everything that arrived today",
+		},
+		"new_only": map[string]interface{}{
+			"type":        "boolean",
+			"description": "If true, returns only entries brought in by each feed's most recent sync. Example: true after sync_feeds to see what it found",
+		},
+		"min_score": map[string]interface{}{
+			"type":        "integer",
+			"description": "Only return aggregator posts with at least this many points; entries without a score are left out. Example: 100",
+		},
+		"kept_only": map[string]interface{}{
+			"type":        "boolean",
+			"description": "If true, returns only entries pinned with keep_unread. Example: true to see what was set aside to come back to",
+		},
+	}
+}
+
+// parseEntryQuery turns a tool's filters into a store filter, resolving
+// dates in the requested time zone and a label to its feeds.
+func (s *Server) parseEntryQuery(ctx context.Context, pc *profileContext, input EntryQueryInput) (*entryQuery, error) {
+	loc, err := pc.location(input.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	filter := &storage.EntryFilter{
+		FeedID:     input.FeedID,
+		UnreadOnly: input.UnreadOnly,
+		FirstSeen:  input.FirstSeen,
+		LatestSync: input.NewOnly,
+		MinScore:   input.MinScore,
+		KeptOnly:   input.KeptOnly,
+		NoContent:  true,
+	}
+	if input.Since != nil {
+		filter.Since, filter.Until, err = parseSinceRange(*input.Since, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since value: %w", err)
+		}
+		if filter.Until != nil && input.Until != nil {
+			return nil, fmt.Errorf("since is a range, so until must be omitted")
+		}
+	}
+	if input.Until != nil {
+		t, err := parseDateString(*input.Until, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until value: %w", err)
+		}
+		filter.Until = &t
+	}
+	if input.Label != nil && *input.Label != "" {
+		pc.opmlMu.RLock()
+		labelled := pc.opmlDoc.FeedsWithLabel(*input.Label)
+		pc.opmlMu.RUnlock()
+		for _, f := range labelled {
+			feed, err := pc.store.GetFeedByURL(ctx, f.URL)
+			if err != nil {
+				continue // Not synced, or outside the scope
+			}
+			filter.FeedIDs = append(filter.FeedIDs, feed.ID)
+		}
+		if len(filter.FeedIDs) == 0 {
+			return nil, fmt.Errorf("no feeds are labelled %q", *input.Label)
+		}
+	}
+
+	q := &entryQuery{filter: filter, filters: make(map[string]any), loc: loc}
+	if input.Author != nil {
+		q.author = *input.Author
+	}
+	if input.FeedID != nil {
+		q.filters["feed_id"] = *input.FeedID
+	}
+	if len(filter.FeedIDs) > 0 {
+		q.filters["label"] = *input.Label
+	}
+	if input.UnreadOnly != nil {
+		q.filters["unread_only"] = *input.UnreadOnly
+	}
+	if filter.Since != nil {
+		q.filters["since"] = *filter.Since
+	}
+	if filter.Until != nil {
+		q.filters["until"] = *filter.Until
+	}
+	if q.author != "" {
+		q.filters["author"] = q.author
+	}
+	if input.FirstSeen {
+		q.filters["first_seen"] = true
+	}
+	if input.NewOnly {
+		q.filters["new_only"] = true
+	}
+	if input.MinScore != nil {
+		q.filters["min_score"] = *input.MinScore
+	}
+	if input.KeptOnly {
+		q.filters["kept_only"] = true
+	}
+	return q, nil
+}
+
+// each calls fn with every entry the query matches, one at a time.
+func (q *entryQuery) each(ctx context.Context, store storage.Store, fn func(*models.Entry) error) error {
+	err := store.EachEntry(ctx, q.filter, func(entry *models.Entry) error {
+		if q.author != "" && !authors.MatchEntry(q.author, entry) {
+			return nil
+		}
+		return fn(entry)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+// ABOUTME: recommend_feeds tool that suggests new subscriptions from the user's own reading
+// ABOUTME: Profiles read feeds and topics, then discovers feeds on domains that read articles often link to
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/recommend"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/trends"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultRecommendLimit is how many candidate feeds are returned.
+	defaultRecommendLimit = 5
+	// discoveryAttemptFactor bounds network probing per call, as a multiple of the limit.
+	discoveryAttemptFactor = 3
+	// readingProfileSize is how many feeds and topics summarize the reading profile.
+	readingProfileSize = 5
+)
+
+type RecommendFeedsInput struct {
+	Since      *string `json:"since,omitempty"`
+	Timezone   *string `json:"tz,omitempty"`
+	Limit      *int    `json:"limit,omitempty"`
+	MinEntries *int    `json:"min_entries,omitempty"`
+	Discover   *bool   `json:"discover,omitempty"`
+}
+
+type ReadFeedOutput struct {
+	FeedID string `json:"feed_id"`
+	Title  string `json:"title"`
+	Read   int    `json:"read"`
+	Total  int    `json:"total"`
+}
+
+type FeedCandidate struct {
+	recommend.Domain
+	FeedURL       string `json:"feed_url,omitempty"`
+	FeedTitle     string `json:"feed_title,omitempty"`
+	DiscoverError string `json:"discover_error,omitempty"`
+}
+
+type RecommendFeedsOutput struct {
+	ReadEntries int              `json:"read_entries"`
+	TopFeeds    []ReadFeedOutput `json:"top_feeds"`
+	TopTopics   []trends.Topic   `json:"top_topics"`
+	Candidates  []FeedCandidate  `json:"candidates"`
+}
+
+func (s *Server) registerRecommendFeedsTool() {
+	tool := mcp.Tool{
+		Name:        "recommend_feeds",
+		Description: "Suggest new feeds based on what the user actually reads. Profiles the feeds and topics of read entries, counts the outside domains those articles link to (skipping sites already subscribed and big platforms like social networks), and runs feed discovery on the most linked domains. Returns candidates with the feed URL found, ready for add_feed. Discovery makes network requests; set discover=false for link analysis only.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"since": map[string]interface{}{
+					"type":        "string",
+					"description": "Only consider entries published since: 'today', 'yesterday', 'week', 'month', YYYY-MM-DD, a relative date like '2 weeks', or a range 'from..until'. Default: all read entries.",
+				},
+				"tz": tzProperty,
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of candidate feeds to return. Default: 5.",
+				},
+				"min_entries": map[string]interface{}{
+					"type":        "integer",
+					"description": "Only suggest domains linked from at least this many read entries. Default: 2.",
+				},
+				"discover": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Look up the feed on each candidate domain. Default: true.",
+				},
+				"profile": profileProperty,
+			},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleRecommendFeeds)
+}
+
+func (s *Server) handleRecommendFeeds(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input RecommendFeedsInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	limit := defaultRecommendLimit
+	if input.Limit != nil {
+		if *input.Limit <= 0 {
+			return nil, fmt.Errorf("limit must be positive, got %d", *input.Limit)
+		}
+		limit = *input.Limit
+	}
+	minEntries := 2
+	if input.MinEntries != nil {
+		minEntries = max(*input.MinEntries, 1)
+	}
+	discoverFeeds := input.Discover == nil || *input.Discover
+
+	filter := &storage.EntryFilter{}
+	if input.Since != nil {
+		loc, err := pc.location(input.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		filter.Since, filter.Until, err = parseSinceRange(*input.Since, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since value: %w", err)
+		}
+	}
+	entries, err := pc.store.ListEntries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+	feeds, err := pc.store.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	// Every site already subscribed to, by feed host and by the hosts its entries live on
+	subscribed := make(map[string]bool)
+	subscribedURLs := make(map[string]bool, len(feeds))
+	for _, feed := range feeds {
+		subscribed[recommend.Host(feed.URL)] = true
+		subscribedURLs[feed.URL] = true
+	}
+	readByFeed := make(map[string]*ReadFeedOutput)
+	var readDocs []trends.Document
+	readEntries := entries[:0:0]
+	for _, entry := range entries {
+		if entry.Link != nil {
+			subscribed[recommend.Host(*entry.Link)] = true
+		}
+		rf, ok := readByFeed[entry.FeedID]
+		if !ok {
+			rf = &ReadFeedOutput{FeedID: entry.FeedID}
+			readByFeed[entry.FeedID] = rf
+		}
+		rf.Total++
+		if !entry.Read {
+			continue
+		}
+		rf.Read++
+		readEntries = append(readEntries, entry)
+		text := ""
+		if entry.Title != nil {
+			text = *entry.Title + ".\n"
+		}
+		if entry.Content != nil {
+			text += content.ToText(*entry.Content)
+		}
+		readDocs = append(readDocs, trends.Document{ID: entry.ID, Text: text})
+	}
+
+	output := RecommendFeedsOutput{
+		ReadEntries: len(readEntries),
+		TopFeeds:    topReadFeeds(readByFeed, feeds),
+		TopTopics:   trends.Analyze(readDocs, trends.Options{Limit: readingProfileSize * 2}).Topics,
+		Candidates:  []FeedCandidate{},
+	}
+
+	attempts := 0
+	for _, domain := range recommend.OutboundDomains(readEntries, subscribed) {
+		if len(output.Candidates) >= limit || domain.Entries < minEntries {
+			break
+		}
+		candidate := FeedCandidate{Domain: domain}
+		if discoverFeeds {
+			if attempts >= limit*discoveryAttemptFactor {
+				break
+			}
+			attempts++
+			found, err := s.discoverFeed("https://" + domain.Domain + "/")
+			if err != nil {
+				continue
+			}
+			if subscribedURLs[found.URL] {
+				continue
+			}
+			candidate.FeedURL = found.URL
+			candidate.FeedTitle = found.Title
+		}
+		output.Candidates = append(output.Candidates, candidate)
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// topReadFeeds returns the feeds with the most read entries.
+func topReadFeeds(readByFeed map[string]*ReadFeedOutput, feeds []*models.Feed) []ReadFeedOutput {
+	names := make(map[string]string, len(feeds))
+	for _, feed := range feeds {
+		names[feed.ID] = feed.GetDisplayName()
+	}
+	top := make([]ReadFeedOutput, 0, len(readByFeed))
+	for _, rf := range readByFeed {
+		if rf.Read == 0 {
+			continue
+		}
+		rf.Title = names[rf.FeedID]
+		top = append(top, *rf)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Read != top[j].Read {
+			return top[i].Read > top[j].Read
+		}
+		return top[i].Title < top[j].Title
+	})
+	if len(top) > readingProfileSize {
+		top = top[:readingProfileSize]
+	}
+	return top
+}
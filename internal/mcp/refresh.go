@@ -0,0 +1,122 @@
+// ABOUTME: refresh_entry tool that refetches one entry to pick up corrections made after publication
+// ABOUTME: Updates the entry in place from its feed or article page and returns what changed
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type RefreshEntryInput struct {
+	EntryID string `json:"entry_id"`
+}
+
+type RefreshEntryOutput struct {
+	Success      bool        `json:"success"`
+	Message      string      `json:"message"`
+	Source       string      `json:"source"`
+	Changed      []string    `json:"changed"`
+	WordsAdded   int         `json:"words_added"`
+	WordsRemoved int         `json:"words_removed"`
+	Diff         string      `json:"diff,omitempty"`
+	Entry        EntryOutput `json:"entry"`
+}
+
+func (s *Server) registerRefreshEntryTool() {
+	tool := mcp.Tool{
+		Name:        "refresh_entry",
+		Description: "Refetch one entry and update it in place, for posts corrected or expanded after publication. The entry's feed is fetched again, bypassing the cache, and the entry's title, link, author, content, dates, and image are updated from its item. If the feed no longer carries the entry, its content is replaced with the article from the page it links to (source: \"page\"). Read state, archive links, and scores are kept. Returns the fields that changed, how many words of text were added and removed, and a unified diff of the text. Makes network requests.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"entry_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The entry ID or ID prefix. Example: 'abc12345'",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"entry_id"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleRefreshEntry)
+}
+
+func (s *Server) handleRefreshEntry(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input RefreshEntryInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	entry, err := pc.store.GetEntryByIDOrPrefix(ctx, input.EntryID)
+	if err != nil {
+		return nil, fmt.Errorf("entry not found: %s", input.EntryID)
+	}
+	feed, err := pc.store.GetFeed(ctx, entry.FeedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+	opts, err := s.syncOptions(pc)
+	if err != nil {
+		return nil, err
+	}
+	opts.Force = true
+	if opts.Secrets, err = pc.cfg.FeedSecrets(feed); err != nil {
+		return nil, err
+	}
+	result, err := feedsync.RefreshEntry(ctx, pc.store, feed, entry, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh entry: %w", err)
+	}
+
+	message := "Entry is up to date"
+	if len(result.Changed) > 0 {
+		message = fmt.Sprintf("Updated %d field(s) from the %s", len(result.Changed), result.Source)
+	}
+	changed := result.Changed
+	if changed == nil {
+		changed = []string{}
+	}
+
+	output := RefreshEntryOutput{
+		Success:      true,
+		Message:      message,
+		Source:       result.Source,
+		Changed:      changed,
+		WordsAdded:   result.WordsAdded,
+		WordsRemoved: result.WordsRemoved,
+		Diff:         result.Diff,
+		Entry: EntryOutput{
+			ID:            entry.ID,
+			FeedID:        entry.FeedID,
+			Title:         entry.Title,
+			Link:          entry.Link,
+			Author:        entry.Author,
+			PublishedAt:   entry.PublishedAt,
+			Read:          entry.Read,
+			ReadAt:        entry.ReadAt,
+			ArchiveURL:    entry.ArchiveURL,
+			ImageURL:      entry.ImageURL,
+			DiscussionURL: entry.DiscussionURL,
+			Score:         entry.Score,
+			CommentCount:  entry.CommentCount,
+			KeepUnread:    entry.KeepUnread,
+			CreatedAt:     entry.CreatedAt,
+		},
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
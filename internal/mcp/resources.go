@@ -6,10 +6,16 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/harper/digest/internal/digests"
+	"github.com/harper/digest/internal/goals"
+	"github.com/harper/digest/internal/models"
 	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
 	"github.com/harper/digest/internal/timeutil"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -37,8 +43,17 @@ func (s *Server) registerResources() {
 	s.registerEntriesUnreadResource()
 	s.registerEntriesTodayResource()
 
-	// Statistics resource
+	// Statistics resources
 	s.registerStatsResource()
+	s.registerFeedStatsResource()
+	s.registerLastSyncResource()
+	s.registerGoalsResource()
+
+	// Digests cover the whole profile, so a scoped server doesn't offer them
+	if s.scope.IsZero() {
+		s.registerDigestsResource()
+		s.registerDigestResource()
+	}
 }
 
 func (s *Server) registerFeedsResource() {
@@ -54,7 +69,7 @@ func (s *Server) registerFeedsResource() {
 			if err != nil {
 				return nil, fmt.Errorf("failed to get profile: %w", err)
 			}
-			feeds, err := pc.store.ListFeeds()
+			feeds, err := pc.store.ListFeeds(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to list feeds: %w", err)
 			}
@@ -131,7 +146,7 @@ func (s *Server) registerEntriesUnreadResource() {
 			}
 			unreadOnly := true
 			filter := &storage.EntryFilter{UnreadOnly: &unreadOnly}
-			entries, err := pc.store.ListEntries(filter)
+			entries, err := pc.store.ListEntries(ctx, filter)
 			if err != nil {
 				return nil, fmt.Errorf("failed to list unread entries: %w", err)
 			}
@@ -205,7 +220,7 @@ func (s *Server) registerEntriesTodayResource() {
 		mcp.Resource{
 			URI:         "digest://entries/today",
 			Name:        "Today's Entries",
-			Description: "List all feed entries published today (since midnight local time), regardless of read status",
+			Description: "List all feed entries published today (since midnight in the configured timezone), regardless of read status",
 			MIMEType:    "application/json",
 		},
 		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
@@ -213,11 +228,15 @@ func (s *Server) registerEntriesTodayResource() {
 			if err != nil {
 				return nil, fmt.Errorf("failed to get profile: %w", err)
 			}
-			// Calculate start of today (midnight local time) - consistent with CLI and timeutil
-			startOfDay := timeutil.StartOfToday()
+			// Start of today in the user's timezone - consistent with CLI and timeutil
+			loc, err := pc.location(nil)
+			if err != nil {
+				return nil, err
+			}
+			startOfDay := timeutil.StartOfTodayIn(loc)
 
 			filter := &storage.EntryFilter{Since: &startOfDay}
-			entries, err := pc.store.ListEntries(filter)
+			entries, err := pc.store.ListEntries(ctx, filter)
 			if err != nil {
 				return nil, fmt.Errorf("failed to list today's entries: %w", err)
 			}
@@ -299,7 +318,7 @@ func (s *Server) registerStatsResource() {
 			if err != nil {
 				return nil, fmt.Errorf("failed to get profile: %w", err)
 			}
-			stats, err := s.calculateStats(pc.store)
+			stats, err := s.calculateStats(ctx, pc.store)
 			if err != nil {
 				return nil, fmt.Errorf("failed to calculate stats: %w", err)
 			}
@@ -367,9 +386,9 @@ type SyncInfo struct {
 	FeedTitle     string     `json:"feed_title"`
 }
 
-func (s *Server) calculateStats(store storage.Store) (*StatsData, error) {
+func (s *Server) calculateStats(ctx context.Context, store storage.Store) (*StatsData, error) {
 	// Get overall stats
-	overallStats, err := store.GetOverallStats()
+	overallStats, err := store.GetOverallStats(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get overall stats: %w", err)
 	}
@@ -381,7 +400,7 @@ func (s *Server) calculateStats(store storage.Store) (*StatsData, error) {
 	}
 
 	// Get per-feed stats
-	feedStats, err := store.GetFeedStats()
+	feedStats, err := store.GetFeedStats(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feed stats: %w", err)
 	}
@@ -426,3 +445,382 @@ func (s *Server) calculateStats(store storage.Store) (*StatsData, error) {
 		LastSync: lastSync,
 	}, nil
 }
+
+// feedActivityDays is how far back the per-feed activity series reaches.
+const feedActivityDays = 90
+
+func (s *Server) registerFeedStatsResource() {
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			"digest://feed/{id}/stats",
+			"Feed Activity",
+			mcp.WithTemplateDescription("Activity for one feed: entries per day over the last 90 days (for sparklines), read rate, and average time from publish to read. The id may be a feed ID, ID prefix, or URL"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			pc, err := s.getProfile("")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get profile: %w", err)
+			}
+			ref := feedRefFromStatsURI(request)
+			if ref == "" {
+				return nil, fmt.Errorf("feed id is required")
+			}
+			feed, err := pc.store.GetFeedByURLOrPrefix(ctx, ref)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find feed: %w", err)
+			}
+			entries, err := pc.store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list entries: %w", err)
+			}
+
+			loc, err := pc.location(nil)
+			if err != nil {
+				return nil, err
+			}
+			activity := feedActivity(feed, entries, time.Now().In(loc), feedActivityDays)
+			resourceURI := "digest://feed/" + feed.ID + "/stats"
+			resourceData := ResourceData{
+				Metadata: ResourceMetadata{
+					Timestamp:   time.Now(),
+					Count:       activity.EntryCount,
+					ResourceURI: resourceURI,
+					Filters:     map[string]any{"days": feedActivityDays},
+				},
+				Data: activity,
+				Links: map[string]string{
+					"all_feeds": "digest://feeds",
+					"stats":     "digest://stats",
+				},
+			}
+
+			jsonBytes, err := json.MarshalIndent(resourceData, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal resource data: %w", err)
+			}
+
+			return []mcp.ResourceContents{
+				&mcp.TextResourceContents{
+					URI:      request.Params.URI,
+					MIMEType: "application/json",
+					Text:     string(jsonBytes),
+				},
+			}, nil
+		},
+	)
+}
+
+func (s *Server) registerLastSyncResource() {
+	s.mcpServer.AddResource(
+		mcp.Resource{
+			URI:         "digest://sync/last",
+			Name:        "Last Sync",
+			Description: "The last sync of the profile, by 'digest fetch' or sync_feeds: when it ran, how long it took, totals, and each feed's status, new entries, and error",
+			MIMEType:    "application/json",
+		},
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			pc, err := s.getProfile("")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get profile: %w", err)
+			}
+			run, err := feedsync.LoadRun(pc.runPath)
+			if err != nil {
+				return nil, err
+			}
+			if run == nil {
+				return nil, fmt.Errorf("no sync recorded yet; call sync_feeds")
+			}
+
+			resourceData := ResourceData{
+				Metadata: ResourceMetadata{
+					Timestamp:   time.Now(),
+					Count:       len(run.Feeds),
+					ResourceURI: "digest://sync/last",
+				},
+				Data: run,
+				Links: map[string]string{
+					"all_feeds": "digest://feeds",
+					"stats":     "digest://stats",
+				},
+			}
+
+			jsonBytes, err := json.MarshalIndent(resourceData, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal resource data: %w", err)
+			}
+
+			return []mcp.ResourceContents{
+				&mcp.TextResourceContents{
+					URI:      request.Params.URI,
+					MIMEType: "application/json",
+					Text:     string(jsonBytes),
+				},
+			}, nil
+		},
+	)
+}
+
+// GoalsData is the digest://goals resource: the reading goals' progress
+// and what would meet the ones not met yet.
+type GoalsData struct {
+	goals.Progress
+	Nudges []string `json:"nudges"`
+}
+
+func (s *Server) registerGoalsResource() {
+	s.mcpServer.AddResource(
+		mcp.Resource{
+			URI:         "digest://goals",
+			Name:        "Reading Goals",
+			Description: "Reading streak (days in a row the daily goal was met), today's reads against the daily goal, unread count against the inbox goal, the last 14 days of reading, and nudges toward goals not met yet. Goals are set in config.json",
+			MIMEType:    "application/json",
+		},
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			pc, err := s.getProfile("")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get profile: %w", err)
+			}
+			overall, err := pc.store.GetOverallStats(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get stats: %w", err)
+			}
+			var read []*models.Entry
+			err = pc.store.EachEntry(ctx, &storage.EntryFilter{NoContent: true}, func(e *models.Entry) error {
+				if e.ReadAt != nil {
+					read = append(read, e)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list entries: %w", err)
+			}
+			loc, err := pc.location(nil)
+			if err != nil {
+				return nil, err
+			}
+
+			progress := goals.Compute(read, overall.UnreadCount, pc.cfg.GetGoals(), time.Now().In(loc))
+			nudges := progress.Nudges()
+			if nudges == nil {
+				nudges = []string{}
+			}
+			resourceData := ResourceData{
+				Metadata: ResourceMetadata{
+					Timestamp:   time.Now(),
+					Count:       progress.Streak,
+					ResourceURI: "digest://goals",
+				},
+				Data: GoalsData{Progress: progress, Nudges: nudges},
+				Links: map[string]string{
+					"unread_entries": "digest://entries/unread",
+					"stats":          "digest://stats",
+				},
+			}
+
+			jsonBytes, err := json.MarshalIndent(resourceData, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal resource data: %w", err)
+			}
+
+			return []mcp.ResourceContents{
+				&mcp.TextResourceContents{
+					URI:      request.Params.URI,
+					MIMEType: "application/json",
+					Text:     string(jsonBytes),
+				},
+			}, nil
+		},
+	)
+}
+
+// feedRefFromStatsURI returns the {id} part of a digest://feed/{id}/stats URI,
+// preferring the value the template matcher extracted.
+func (s *Server) registerDigestsResource() {
+	s.mcpServer.AddResource(
+		mcp.Resource{
+			URI:         "digest://digests",
+			Name:        "Digest History",
+			Description: "The dated daily digests generated after each day's first fetch, newest first: date, entry count, and the window of first-seen times each covers. Read one at digest://digests/{date}",
+			MIMEType:    "application/json",
+		},
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			pc, err := s.getProfile("")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get profile: %w", err)
+			}
+			h, err := digests.Load(pc.digestsDir)
+			if err != nil {
+				return nil, err
+			}
+			newest := make([]digests.Record, 0, len(h))
+			links := map[string]string{}
+			for i := len(h) - 1; i >= 0; i-- {
+				newest = append(newest, h[i])
+				if len(links) == 0 {
+					links["latest"] = "digest://digests/" + h[i].Date
+				}
+			}
+
+			resourceData := ResourceData{
+				Metadata: ResourceMetadata{
+					Timestamp:   time.Now(),
+					Count:       len(newest),
+					ResourceURI: "digest://digests",
+				},
+				Data:  newest,
+				Links: links,
+			}
+
+			jsonBytes, err := json.MarshalIndent(resourceData, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal resource data: %w", err)
+			}
+
+			return []mcp.ResourceContents{
+				&mcp.TextResourceContents{
+					URI:      request.Params.URI,
+					MIMEType: "application/json",
+					Text:     string(jsonBytes),
+				},
+			}, nil
+		},
+	)
+}
+
+func (s *Server) registerDigestResource() {
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			"digest://digests/{date}",
+			"Daily Digest",
+			mcp.WithTemplateDescription("One day's digest as Markdown, such as digest://digests/2025-01-15: the entries first seen since the previous day's digest, grouped by feed"),
+			mcp.WithTemplateMIMEType("text/markdown"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			pc, err := s.getProfile("")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get profile: %w", err)
+			}
+			date := strings.TrimPrefix(request.Params.URI, "digest://digests/")
+			data, err := digests.Read(pc.digestsDir, date, digests.Markdown)
+			if errors.Is(err, digests.ErrNotFound) {
+				return nil, fmt.Errorf("no digest for %s; see digest://digests", date)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{
+				&mcp.TextResourceContents{
+					URI:      request.Params.URI,
+					MIMEType: "text/markdown",
+					Text:     string(data),
+				},
+			}, nil
+		},
+	)
+}
+
+func feedRefFromStatsURI(request mcp.ReadResourceRequest) string {
+	if id, ok := request.Params.Arguments["id"]; ok {
+		switch v := id.(type) {
+		case string:
+			return v
+		case []string:
+			if len(v) > 0 {
+				return v[0]
+			}
+		}
+	}
+	ref := strings.TrimPrefix(request.Params.URI, "digest://feed/")
+	return strings.TrimSuffix(ref, "/stats")
+}
+
+// FeedActivity is the activity summary for a single feed.
+type FeedActivity struct {
+	FeedID    string `json:"feed_id"`
+	FeedTitle string `json:"feed_title"`
+	FeedURL   string `json:"feed_url"`
+	Days      int    `json:"days"`
+	// EntryCount, ReadCount and ReadRate cover entries inside the window
+	EntryCount int     `json:"entry_count"`
+	ReadCount  int     `json:"read_count"`
+	ReadRate   float64 `json:"read_rate"`
+	// AvgTimeToRead is the mean delay between publish and read, omitted
+	// when nothing in the window has been read
+	AvgTimeToRead      string   `json:"avg_time_to_read,omitempty"`
+	AvgTimeToReadHours *float64 `json:"avg_time_to_read_hours,omitempty"`
+	// Sparkline holds one count per day, oldest first; Daily is the same
+	// series with dates attached
+	Sparkline []int           `json:"sparkline"`
+	Daily     []DailyActivity `json:"daily"`
+}
+
+// DailyActivity is the number of entries published on one day.
+type DailyActivity struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// feedActivity buckets a feed's entries into the last days local days ending
+// today and works out its read rate and average time to read. Entries without
+// a published date are placed by when digest first saw them.
+func feedActivity(feed *models.Feed, entries []*models.Entry, now time.Time, days int) *FeedActivity {
+	activity := &FeedActivity{
+		FeedID:    feed.ID,
+		FeedTitle: feed.GetDisplayName(),
+		FeedURL:   feed.URL,
+		Days:      days,
+		Sparkline: make([]int, days),
+		Daily:     make([]DailyActivity, days),
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, -(days - 1))
+	for i := range activity.Daily {
+		activity.Daily[i].Date = start.AddDate(0, 0, i).Format("2006-01-02")
+	}
+
+	var totalToRead time.Duration
+	var timedReads int
+	for _, entry := range entries {
+		published := entry.CreatedAt
+		if entry.PublishedAt != nil {
+			published = *entry.PublishedAt
+		}
+		published = published.In(now.Location())
+		if published.Before(start) {
+			continue
+		}
+		day := time.Date(published.Year(), published.Month(), published.Day(), 0, 0, 0, 0, now.Location())
+		// Count by calendar day so DST changes don't shift buckets
+		idx := 0
+		for d := start; d.Before(day); d = d.AddDate(0, 0, 1) {
+			idx++
+		}
+		if idx >= days {
+			continue
+		}
+		activity.Sparkline[idx]++
+		activity.Daily[idx].Count++
+		activity.EntryCount++
+
+		if entry.Read {
+			activity.ReadCount++
+			if entry.ReadAt != nil && entry.ReadAt.After(published) {
+				totalToRead += entry.ReadAt.Sub(published)
+				timedReads++
+			}
+		}
+	}
+
+	if activity.EntryCount > 0 {
+		activity.ReadRate = float64(activity.ReadCount) / float64(activity.EntryCount)
+	}
+	if timedReads > 0 {
+		avg := totalToRead / time.Duration(timedReads)
+		hours := avg.Hours()
+		activity.AvgTimeToRead = avg.Round(time.Minute).String()
+		activity.AvgTimeToReadHours = &hours
+	}
+	return activity
+}
@@ -0,0 +1,503 @@
+// ABOUTME: Restricts the MCP server to a subset of folders and feeds
+// ABOUTME: Wraps a profile's store so out-of-scope feeds and their entries are invisible to every tool and resource
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+// Scope limits which feeds the MCP server exposes. A feed is in scope when
+// its URL is listed in Feeds or its OPML folder is listed in Folders.
+// The zero Scope exposes everything.
+type Scope struct {
+	Folders []string
+	Feeds   []string
+}
+
+// IsZero reports whether the scope is unrestricted.
+func (sc Scope) IsZero() bool {
+	return len(sc.Folders) == 0 && len(sc.Feeds) == 0
+}
+
+// Allows reports whether a feed with the given URL, filed in folder, is in scope.
+// Folder names are matched case-insensitively.
+func (sc Scope) Allows(url, folder string) bool {
+	if sc.IsZero() {
+		return true
+	}
+	for _, f := range sc.Feeds {
+		if f == url {
+			return true
+		}
+	}
+	for _, f := range sc.Folders {
+		if strings.EqualFold(f, folder) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterOPML returns the OPML feeds and folders visible under the scope.
+// A folder is visible if it is in scope or holds at least one visible feed.
+func (sc Scope) filterOPML(feeds []opml.Feed, folders []string) ([]opml.Feed, []string) {
+	if sc.IsZero() {
+		return feeds, folders
+	}
+
+	visible := make([]opml.Feed, 0, len(feeds))
+	hasVisibleFeed := make(map[string]bool)
+	for _, f := range feeds {
+		if sc.Allows(f.URL, f.Folder) {
+			visible = append(visible, f)
+			hasVisibleFeed[f.Folder] = true
+		}
+	}
+
+	visibleFolders := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		if hasVisibleFeed[folder] || sc.Allows("", folder) {
+			visibleFolders = append(visibleFolders, folder)
+		}
+	}
+	return visible, visibleFolders
+}
+
+// checkPlacement returns an error if putting the feed in folder would take it outside the scope.
+func (sc Scope) checkPlacement(url, folder string) error {
+	if sc.Allows(url, folder) {
+		return nil
+	}
+	return fmt.Errorf("folder %s is outside this server's scope", formatFolder(folder))
+}
+
+// scopedStore wraps a Store so only in-scope feeds and their entries are visible.
+// Out-of-scope feeds and entries are reported as not found rather than forbidden,
+// so an agent can't probe for subscriptions it isn't allowed to see.
+//
+// Every Store method is implemented explicitly (no embedding) so that adding a
+// method to the interface fails to compile here instead of silently bypassing the scope.
+type scopedStore struct {
+	inner storage.Store
+	scope Scope
+	// folderOf returns a feed's OPML folder, or false if the feed isn't in the OPML file.
+	folderOf func(url string) (string, bool)
+}
+
+var _ storage.Store = (*scopedStore)(nil)
+
+func newScopedStore(inner storage.Store, scope Scope, folderOf func(url string) (string, bool)) *scopedStore {
+	return &scopedStore{inner: inner, scope: scope, folderOf: folderOf}
+}
+
+// allows reports whether the feed is in scope. The OPML folder is authoritative;
+// the stored folder is used for feeds missing from the OPML file.
+func (s *scopedStore) allows(feed *models.Feed) bool {
+	folder, ok := s.folderOf(feed.URL)
+	if !ok {
+		folder = feed.Folder
+	}
+	return s.scope.Allows(feed.URL, folder)
+}
+
+// visibleFeed passes a lookup result through only if the feed is in scope.
+func (s *scopedStore) visibleFeed(feed *models.Feed, err error) (*models.Feed, error) {
+	if err != nil {
+		return nil, err
+	}
+	if !s.allows(feed) {
+		return nil, fmt.Errorf("feed not found")
+	}
+	return feed, nil
+}
+
+// requireFeed returns an error unless the feed with the given ID exists and is in scope.
+func (s *scopedStore) requireFeed(ctx context.Context, id string) error {
+	_, err := s.visibleFeed(s.inner.GetFeed(ctx, id))
+	if err != nil {
+		return fmt.Errorf("feed not found: %s", id)
+	}
+	return nil
+}
+
+// visibleEntry passes a lookup result through only if the entry's feed is in scope.
+func (s *scopedStore) visibleEntry(ctx context.Context, entry *models.Entry, err error) (*models.Entry, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireFeed(ctx, entry.FeedID); err != nil {
+		return nil, fmt.Errorf("entry not found")
+	}
+	return entry, nil
+}
+
+// requireEntry returns an error unless the entry exists and its feed is in scope.
+func (s *scopedStore) requireEntry(ctx context.Context, id string) error {
+	entry, err := s.inner.GetEntry(ctx, id)
+	if _, err = s.visibleEntry(ctx, entry, err); err != nil {
+		return fmt.Errorf("entry not found: %s", id)
+	}
+	return nil
+}
+
+// visibleFeedIDs returns the IDs of all in-scope feeds.
+func (s *scopedStore) visibleFeedIDs(ctx context.Context) (map[string]bool, error) {
+	feeds, err := s.ListFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(feeds))
+	for _, feed := range feeds {
+		ids[feed.ID] = true
+	}
+	return ids, nil
+}
+
+func (s *scopedStore) Close() error {
+	return s.inner.Close()
+}
+
+func (s *scopedStore) CreateFeed(ctx context.Context, feed *models.Feed) error {
+	if !s.allows(feed) {
+		return fmt.Errorf("feed %s is outside this server's scope", feed.URL)
+	}
+	return s.inner.CreateFeed(ctx, feed)
+}
+
+func (s *scopedStore) GetFeed(ctx context.Context, id string) (*models.Feed, error) {
+	return s.visibleFeed(s.inner.GetFeed(ctx, id))
+}
+
+func (s *scopedStore) GetFeedByURL(ctx context.Context, url string) (*models.Feed, error) {
+	return s.visibleFeed(s.inner.GetFeedByURL(ctx, url))
+}
+
+func (s *scopedStore) GetFeedByPrefix(ctx context.Context, prefix string) (*models.Feed, error) {
+	return s.visibleFeed(s.inner.GetFeedByPrefix(ctx, prefix))
+}
+
+func (s *scopedStore) GetFeedByURLOrPrefix(ctx context.Context, ref string) (*models.Feed, error) {
+	return s.visibleFeed(s.inner.GetFeedByURLOrPrefix(ctx, ref))
+}
+
+func (s *scopedStore) ListFeeds(ctx context.Context) ([]*models.Feed, error) {
+	feeds, err := s.inner.ListFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]*models.Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if s.allows(feed) {
+			visible = append(visible, feed)
+		}
+	}
+	return visible, nil
+}
+
+func (s *scopedStore) UpdateFeed(ctx context.Context, feed *models.Feed) error {
+	if err := s.requireFeed(ctx, feed.ID); err != nil {
+		return err
+	}
+	return s.inner.UpdateFeed(ctx, feed)
+}
+
+func (s *scopedStore) DeleteFeed(ctx context.Context, id string) error {
+	if err := s.requireFeed(ctx, id); err != nil {
+		return err
+	}
+	return s.inner.DeleteFeed(ctx, id)
+}
+
+func (s *scopedStore) UpdateFeedFetchState(ctx context.Context, feedID string, etag, lastModified *string, fetchedAt time.Time) error {
+	if err := s.requireFeed(ctx, feedID); err != nil {
+		return err
+	}
+	return s.inner.UpdateFeedFetchState(ctx, feedID, etag, lastModified, fetchedAt)
+}
+
+func (s *scopedStore) UpdateFeedError(ctx context.Context, feedID string, errMsg string) error {
+	if err := s.requireFeed(ctx, feedID); err != nil {
+		return err
+	}
+	return s.inner.UpdateFeedError(ctx, feedID, errMsg)
+}
+
+func (s *scopedStore) CreateEntry(ctx context.Context, entry *models.Entry) error {
+	if err := s.requireFeed(ctx, entry.FeedID); err != nil {
+		return err
+	}
+	return s.inner.CreateEntry(ctx, entry)
+}
+
+func (s *scopedStore) GetEntry(ctx context.Context, id string) (*models.Entry, error) {
+	entry, err := s.inner.GetEntry(ctx, id)
+	return s.visibleEntry(ctx, entry, err)
+}
+
+func (s *scopedStore) GetEntryByPrefix(ctx context.Context, prefix string) (*models.Entry, error) {
+	entry, err := s.inner.GetEntryByPrefix(ctx, prefix)
+	return s.visibleEntry(ctx, entry, err)
+}
+
+func (s *scopedStore) GetEntryByIDOrPrefix(ctx context.Context, ref string) (*models.Entry, error) {
+	entry, err := s.inner.GetEntryByIDOrPrefix(ctx, ref)
+	return s.visibleEntry(ctx, entry, err)
+}
+
+// ListEntries narrows the filter to in-scope feeds before querying.
+func (s *scopedStore) ListEntries(ctx context.Context, filter *storage.EntryFilter) ([]*models.Entry, error) {
+	scoped, err := s.scopedFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if scoped == nil {
+		return []*models.Entry{}, nil
+	}
+	return s.inner.ListEntries(ctx, scoped)
+}
+
+// EachEntry narrows the filter to in-scope feeds like ListEntries.
+func (s *scopedStore) EachEntry(ctx context.Context, filter *storage.EntryFilter, fn func(*models.Entry) error) error {
+	scoped, err := s.scopedFilter(ctx, filter)
+	if err != nil || scoped == nil {
+		return err
+	}
+	return s.inner.EachEntry(ctx, scoped, fn)
+}
+
+// scopedFilter returns filter limited to in-scope feeds, or nil if it
+// leaves no feed to list.
+func (s *scopedStore) scopedFilter(ctx context.Context, filter *storage.EntryFilter) (*storage.EntryFilter, error) {
+	ids, err := s.visibleFeedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := storage.EntryFilter{}
+	if filter != nil {
+		scoped = *filter
+	}
+
+	var feedIDs []string
+	switch {
+	case scoped.FeedID != nil:
+		if ids[*scoped.FeedID] {
+			feedIDs = []string{*scoped.FeedID}
+		}
+	case len(scoped.FeedIDs) > 0:
+		for _, id := range scoped.FeedIDs {
+			if ids[id] {
+				feedIDs = append(feedIDs, id)
+			}
+		}
+	default:
+		for id := range ids {
+			feedIDs = append(feedIDs, id)
+		}
+	}
+
+	// An empty FeedIDs list means "all feeds" to the backends, so stop here
+	if len(feedIDs) == 0 {
+		return nil, nil
+	}
+	scoped.FeedID = nil
+	scoped.FeedIDs = feedIDs
+	return &scoped, nil
+}
+
+func (s *scopedStore) UpdateEntry(ctx context.Context, entry *models.Entry) error {
+	if err := s.requireEntry(ctx, entry.ID); err != nil {
+		return err
+	}
+	return s.inner.UpdateEntry(ctx, entry)
+}
+
+func (s *scopedStore) DeleteEntry(ctx context.Context, id string) error {
+	if err := s.requireEntry(ctx, id); err != nil {
+		return err
+	}
+	return s.inner.DeleteEntry(ctx, id)
+}
+
+func (s *scopedStore) MoveEntry(ctx context.Context, id, feedID string) error {
+	if err := s.requireEntry(ctx, id); err != nil {
+		return err
+	}
+	if err := s.requireFeed(ctx, feedID); err != nil {
+		return err
+	}
+	return s.inner.MoveEntry(ctx, id, feedID)
+}
+
+func (s *scopedStore) MarkEntryRead(ctx context.Context, id string) error {
+	if err := s.requireEntry(ctx, id); err != nil {
+		return err
+	}
+	return s.inner.MarkEntryRead(ctx, id)
+}
+
+func (s *scopedStore) MarkEntryUnread(ctx context.Context, id string) error {
+	if err := s.requireEntry(ctx, id); err != nil {
+		return err
+	}
+	return s.inner.MarkEntryUnread(ctx, id)
+}
+
+func (s *scopedStore) SetEntryKeepUnread(ctx context.Context, id string, keep bool) error {
+	if err := s.requireEntry(ctx, id); err != nil {
+		return err
+	}
+	return s.inner.SetEntryKeepUnread(ctx, id, keep)
+}
+
+// MarkEntriesReadBefore marks in-scope entries one by one, since the backends
+// can only bulk-update across every feed.
+func (s *scopedStore) MarkEntriesReadBefore(ctx context.Context, before time.Time) (int64, error) {
+	unreadOnly := true
+	entries, err := s.ListEntries(ctx, &storage.EntryFilter{UnreadOnly: &unreadOnly, Until: &before})
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, entry := range entries {
+		if entry.KeepUnread {
+			continue
+		}
+		if err := s.inner.MarkEntryRead(ctx, entry.ID); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// GetChangesSince drops out-of-scope entries from each page, so a page may
+// hold fewer than limit entries while More is still set. Deletions from a
+// feed that has since been removed are kept: with the feed gone there's no
+// telling its folder, and a client that synced its entries needs to drop them.
+func (s *scopedStore) GetChangesSince(ctx context.Context, cursor string, limit int) (*storage.ChangeSet, error) {
+	feeds, err := s.inner.ListFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible, exists := make(map[string]bool), make(map[string]bool, len(feeds))
+	for _, feed := range feeds {
+		exists[feed.ID] = true
+		if s.allows(feed) {
+			visible[feed.ID] = true
+		}
+	}
+	changes, err := s.inner.GetChangesSince(ctx, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	kept := changes.Entries[:0]
+	for _, entry := range changes.Entries {
+		if visible[entry.FeedID] {
+			kept = append(kept, entry)
+		}
+	}
+	changes.Entries = kept
+	keptDeleted := changes.Deleted[:0]
+	for _, deleted := range changes.Deleted {
+		if visible[deleted.FeedID] || !exists[deleted.FeedID] {
+			keptDeleted = append(keptDeleted, deleted)
+		}
+	}
+	changes.Deleted = keptDeleted
+	return changes, nil
+}
+
+func (s *scopedStore) EntryExists(ctx context.Context, feedID, guid string) (bool, error) {
+	if err := s.requireFeed(ctx, feedID); err != nil {
+		return false, err
+	}
+	return s.inner.EntryExists(ctx, feedID, guid)
+}
+
+func (s *scopedStore) CountUnreadEntries(ctx context.Context, feedID *string) (int, error) {
+	if feedID != nil {
+		if err := s.requireFeed(ctx, *feedID); err != nil {
+			return 0, err
+		}
+		return s.inner.CountUnreadEntries(ctx, feedID)
+	}
+
+	stats, err := s.GetFeedStats(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, row := range stats {
+		count += row.UnreadCount
+	}
+	return count, nil
+}
+
+func (s *scopedStore) GetFeedStats(ctx context.Context) ([]storage.FeedStatsRow, error) {
+	ids, err := s.visibleFeedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.inner.GetFeedStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]storage.FeedStatsRow, 0, len(stats))
+	for _, row := range stats {
+		if ids[row.FeedID] {
+			visible = append(visible, row)
+		}
+	}
+	return visible, nil
+}
+
+// GetOverallStats totals the in-scope feed stats.
+func (s *scopedStore) GetOverallStats(ctx context.Context) (*storage.OverallStats, error) {
+	stats, err := s.GetFeedStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overall := &storage.OverallStats{TotalFeeds: len(stats)}
+	for _, row := range stats {
+		overall.TotalEntries += row.EntryCount
+		overall.UnreadCount += row.UnreadCount
+	}
+	return overall, nil
+}
+
+func (s *scopedStore) Compact(ctx context.Context) error {
+	return s.inner.Compact(ctx)
+}
+
+// Search filters the backend's matches to in-scope feeds before applying the limit.
+func (s *scopedStore) Search(ctx context.Context, query string, limit int) ([]*models.Entry, error) {
+	ids, err := s.visibleFeedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// A negative limit means no limit to both backends
+	results, err := s.inner.Search(ctx, query, -1)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]*models.Entry, 0, len(results))
+	for _, entry := range results {
+		if !ids[entry.FeedID] {
+			continue
+		}
+		visible = append(visible, entry)
+		if limit > 0 && len(visible) == limit {
+			break
+		}
+	}
+	return visible, nil
+}
@@ -4,23 +4,53 @@
 package mcp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 
+	"github.com/harper/digest/internal/archive"
+	"github.com/harper/digest/internal/audit"
+	"github.com/harper/digest/internal/badge"
 	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/deliver"
+	"github.com/harper/digest/internal/digests"
+	"github.com/harper/digest/internal/discover"
+	"github.com/harper/digest/internal/favicon"
 	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/runlock"
+	"github.com/harper/digest/internal/snapshot"
 	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/summary"
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/harper/digest/internal/team"
+	"github.com/harper/digest/internal/thumbnail"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 // profileContext holds the store, OPML doc, and OPML path for a single profile.
 type profileContext struct {
-	store    storage.Store
-	opmlDoc  *opml.Document
-	opmlPath string
-	opmlMu   sync.RWMutex
+	name string
+	// cfg is the shared settings with the profile's own layered over them.
+	cfg   *config.Config
+	store storage.Store
+	// base is the profile's own store, without the server's scope or a
+	// team user's read state.
+	base        storage.Store
+	opmlDoc     *opml.Document
+	opmlPath    string
+	iconDir     string
+	summaryDir  string
+	thumbDir    string
+	snapDir     string
+	lockPath    string
+	runPath     string
+	badgePath   string
+	deliverPath string
+	digestsDir  string
+	opmlMu      sync.RWMutex
 }
 
 // Server wraps the MCP server with digest-specific context.
@@ -30,15 +60,90 @@ type Server struct {
 	defaultProfile string
 	profiles       map[string]*profileContext
 	profilesMu     sync.Mutex
+	scope          Scope
+	readOnly       bool
+	user           string
+	role           team.Role
+	auditLog       *audit.Log
+	limits         config.MCPLimits
+	limiter        *rateLimiter
+	discoverFeed   func(siteURL string) (*discover.DiscoveredFeed, error)
+	snapshot       func(ctx context.Context, link string) (string, error)
+}
+
+// Option configures optional Server behavior at startup.
+type Option func(*Server)
+
+// WithScope restricts every tool and resource to the feeds allowed by scope.
+func WithScope(scope Scope) Option {
+	return func(s *Server) {
+		s.scope = scope
+	}
+}
+
+// WithReadOnly registers only the query tools, so the server can't change
+// subscriptions or read state.
+func WithReadOnly() Option {
+	return func(s *Server) {
+		s.readOnly = true
+	}
+}
+
+// WithTeamUser serves a team user: every tool sees their own read state,
+// and a reader can't manage feeds, only read and mark entries.
+func WithTeamUser(user string, role team.Role) Option {
+	return func(s *Server) {
+		s.user = user
+		s.role = role
+	}
+}
+
+// feedFolder returns the OPML folder for a feed URL, or false if the feed isn't in the OPML file.
+func (pc *profileContext) feedFolder(url string) (string, bool) {
+	pc.opmlMu.RLock()
+	defer pc.opmlMu.RUnlock()
+	for _, f := range pc.opmlDoc.AllFeeds() {
+		if f.URL == url {
+			return f.Folder, true
+		}
+	}
+	return "", false
+}
+
+// unscopedStore returns the profile's own store, without the server's
+// scope or team user, for work that covers the whole profile.
+func (pc *profileContext) unscopedStore() storage.Store {
+	return pc.base
+}
+
+// refreshUnreadCounts recounts the profile's unread entries for
+// 'digest unread' after a tool changed them. The counts cover the whole
+// profile, whatever the server's scope; failing to cache them doesn't fail
+// the tool.
+func (pc *profileContext) refreshUnreadCounts(ctx context.Context) {
+	store := pc.unscopedStore()
+	pc.opmlMu.RLock()
+	defer pc.opmlMu.RUnlock()
+	_, _ = badge.Refresh(ctx, store, pc.opmlDoc, pc.badgePath)
 }
 
 // NewServer creates a new MCP server instance with a given config and default profile.
 // It eagerly loads the default profile to catch configuration errors at startup.
-func NewServer(cfg *config.Config, defaultProfile string) (*Server, error) {
+func NewServer(cfg *config.Config, defaultProfile string, opts ...Option) (*Server, error) {
 	s := &Server{
 		cfg:            cfg,
 		defaultProfile: defaultProfile,
 		profiles:       make(map[string]*profileContext),
+		auditLog:       audit.New(audit.Path(cfg.GetDataDir())),
+		limits:         cfg.GetMCPLimits(),
+		limiter:        newRateLimiter(),
+		discoverFeed: func(siteURL string) (*discover.DiscoveredFeed, error) {
+			return discover.DiscoverWithOptions(siteURL, discover.Options{})
+		},
+		snapshot: archive.New().Snapshot,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 
 	// Eagerly load the default profile to catch errors at startup
@@ -53,7 +158,9 @@ func NewServer(cfg *config.Config, defaultProfile string) (*Server, error) {
 		server.WithToolCapabilities(true),
 		server.WithResourceCapabilities(true, false),
 		server.WithPromptCapabilities(true),
+		server.WithToolHandlerMiddleware(s.auditTool),
 	)
+	s.mcpServer.EnableSampling()
 
 	// Register handlers
 	s.registerTools()
@@ -87,8 +194,12 @@ func (s *Server) getProfile(name string) (*profileContext, error) {
 		return pc, nil
 	}
 
-	// Open store for this profile
-	store, err := s.cfg.OpenProfileStorage(name)
+	// Open store for this profile, with its own settings
+	cfg, err := s.cfg.ForProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	store, err := cfg.OpenProfileStorage(name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open storage for profile %q: %w", name, err)
 	}
@@ -117,9 +228,32 @@ func (s *Server) getProfile(name string) (*profileContext, error) {
 	}
 
 	pc := &profileContext{
-		store:    store,
-		opmlDoc:  opmlDoc,
-		opmlPath: opmlPath,
+		name:        name,
+		cfg:         cfg,
+		store:       store,
+		base:        store,
+		opmlDoc:     opmlDoc,
+		opmlPath:    opmlPath,
+		iconDir:     favicon.CacheDir(profileDir),
+		summaryDir:  summary.CacheDir(profileDir),
+		thumbDir:    thumbnail.CacheDir(profileDir),
+		snapDir:     snapshot.Dir(profileDir),
+		lockPath:    runlock.Path(profileDir),
+		runPath:     feedsync.RunPath(profileDir),
+		badgePath:   badge.Path(profileDir),
+		deliverPath: deliver.StatePath(profileDir),
+		digestsDir:  digests.Dir(profileDir),
+	}
+	if s.user != "" {
+		view, err := storage.ForUser(store, s.user)
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to open %s's view of profile %q: %w", s.user, name, err)
+		}
+		pc.store = view
+	}
+	if !s.scope.IsZero() {
+		pc.store = newScopedStore(pc.store, s.scope, pc.feedFolder)
 	}
 	s.profiles[name] = pc
 	return pc, nil
@@ -132,7 +266,7 @@ func (s *Server) Close() error {
 
 	var firstErr error
 	for name, pc := range s.profiles {
-		if err := pc.store.Close(); err != nil && firstErr == nil {
+		if err := pc.base.Close(); err != nil && firstErr == nil {
 			firstErr = fmt.Errorf("failed to close store for profile %q: %w", name, err)
 		}
 	}
@@ -140,9 +274,14 @@ func (s *Server) Close() error {
 	return firstErr
 }
 
-// ServeStdio starts the MCP server on stdio
-func (s *Server) ServeStdio() error {
-	return server.ServeStdio(s.mcpServer)
+// ServeStdio serves MCP on stdio until stdin closes or ctx is canceled,
+// which also cancels tool calls in flight.
+func (s *Server) ServeStdio(ctx context.Context) error {
+	err := server.NewStdioServer(s.mcpServer).Listen(ctx, os.Stdin, os.Stdout)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
 }
 
 // registerTools is implemented in tools.go
@@ -8,24 +8,47 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/harper/digest/internal/audit"
+	"github.com/harper/digest/internal/badge"
 	"github.com/harper/digest/internal/config"
+	"github.com/harper/digest/internal/digests"
+	"github.com/harper/digest/internal/discover"
+	"github.com/harper/digest/internal/goals"
+	"github.com/harper/digest/internal/models"
 	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/render"
+	"github.com/harper/digest/internal/runlock"
+	"github.com/harper/digest/internal/secrets"
 	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/harper/digest/internal/team"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/require"
 )
 
 // testServer creates a test MCP server with a temp data directory and initial OPML content.
 // Returns the server, store (from the default profile), and OPML path.
-func testServer(t *testing.T) (*Server, storage.Store, string) { //nolint:unparam
+// profileConfig returns the default profile's settings, which the
+// handlers read.
+func profileConfig(t *testing.T, s *Server) *config.Config {
+	t.Helper()
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+	return pc.cfg
+}
+
+func testServer(t *testing.T, opts ...Option) (*Server, storage.Store, string) { //nolint:unparam
 	t.Helper()
 
 	// Create temp root data directory
@@ -37,10 +60,13 @@ func testServer(t *testing.T) (*Server, storage.Store, string) { //nolint:unpara
 		os.RemoveAll(tmpDir)
 	})
 
-	// Create config pointing at tmpDir with sqlite backend
+	// Create config pointing at tmpDir with sqlite backend, keeping any
+	// secrets in a file there rather than the real keychain
+	t.Setenv("XDG_CONFIG_HOME", tmpDir+"/config")
 	cfg := &config.Config{
-		Backend: "sqlite",
-		DataDir: tmpDir,
+		Backend:        "sqlite",
+		DataDir:        tmpDir,
+		SecretsBackend: "file",
 	}
 
 	// Create the default profile subdirectory
@@ -65,7 +91,7 @@ func testServer(t *testing.T) (*Server, storage.Store, string) { //nolint:unpara
 	}
 
 	// Create server
-	s, err := NewServer(cfg, "default")
+	s, err := NewServer(cfg, "default", opts...)
 	if err != nil {
 		t.Fatalf("NewServer: %v", err)
 	}
@@ -89,7 +115,7 @@ func TestHandleListFeeds(t *testing.T) {
 	feed := storage.NewFeed("https://example.com/feed.xml")
 	title := "Example Blog"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -122,7 +148,7 @@ func TestHandleListEntries(t *testing.T) {
 
 	// Create feed and entries
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -134,10 +160,10 @@ func TestHandleListEntries(t *testing.T) {
 	entry2.PublishedAt = &now
 	entry2.Read = true
 
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -179,17 +205,17 @@ func TestHandleMarkRead(t *testing.T) {
 
 	// Create feed and entry
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	entry := storage.NewEntry(feed.ID, "guid-1", "Test Entry")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
 	// Verify initially unread
-	got, _ := store.GetEntry(entry.ID)
+	got, _ := store.GetEntry(context.Background(), entry.ID)
 	if got.Read {
 		t.Error("expected entry to be unread initially")
 	}
@@ -215,10 +241,20 @@ func TestHandleMarkRead(t *testing.T) {
 	}
 
 	// Verify in store
-	got, _ = store.GetEntry(entry.ID)
+	got, _ = store.GetEntry(context.Background(), entry.ID)
 	if !got.Read {
 		t.Error("expected entry to be read in store")
 	}
+
+	// The status bar count follows
+	pc, err := s.getProfile("")
+	if err != nil {
+		t.Fatalf("getProfile: %v", err)
+	}
+	counts, err := badge.Load(pc.badgePath)
+	if err != nil || counts == nil || counts.Total != 0 {
+		t.Errorf("cached unread counts = %+v, %v; want 0 unread", counts, err)
+	}
 }
 
 func TestHandleMarkUnread(t *testing.T) {
@@ -226,7 +262,7 @@ func TestHandleMarkUnread(t *testing.T) {
 
 	// Create feed and read entry
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -234,7 +270,7 @@ func TestHandleMarkUnread(t *testing.T) {
 	entry.Read = true
 	now := time.Now()
 	entry.ReadAt = &now
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -266,7 +302,7 @@ func TestHandleGetEntry(t *testing.T) {
 	feed := storage.NewFeed("https://example.com/feed.xml")
 	title := "Test Feed"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -275,7 +311,7 @@ func TestHandleGetEntry(t *testing.T) {
 	entry.Content = &content
 	link := "https://example.com/post/1"
 	entry.Link = &link
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -312,12 +348,12 @@ func TestHandleGetEntryByPrefix(t *testing.T) {
 
 	// Create feed and entry
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	entry := storage.NewEntry(feed.ID, "guid-1", "Test Entry")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -345,7 +381,7 @@ func TestHandleBulkMarkRead(t *testing.T) {
 
 	// Create feed and entries
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -362,13 +398,13 @@ func TestHandleBulkMarkRead(t *testing.T) {
 	entry3 := storage.NewEntry(feed.ID, "guid-3", "Two days ago")
 	entry3.PublishedAt = &twoDaysAgo
 
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
-	if err := store.CreateEntry(entry3); err != nil {
+	if err := store.CreateEntry(context.Background(), entry3); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -424,13 +460,13 @@ func TestHandleRemoveFeed(t *testing.T) {
 
 	// Add a feed first
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Add some entries
 	entry := storage.NewEntry(feed.ID, "guid-1", "Test Entry")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -452,13 +488,13 @@ func TestHandleRemoveFeed(t *testing.T) {
 	}
 
 	// Verify feed is gone
-	_, err = store.GetFeed(feed.ID)
+	_, err = store.GetFeed(context.Background(), feed.ID)
 	if err == nil {
 		t.Error("expected error getting deleted feed")
 	}
 
 	// Verify entries are gone (cascade delete)
-	entries, _ := store.ListEntries(nil)
+	entries, _ := store.ListEntries(context.Background(), nil)
 	if len(entries) != 0 {
 		t.Errorf("expected 0 entries after cascade delete, got %d", len(entries))
 	}
@@ -593,7 +629,7 @@ func TestHandleMoveFeed(t *testing.T) {
 
 	// Add a feed first
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -623,7 +659,7 @@ func TestHandleMoveFeed_AlreadyInFolder(t *testing.T) {
 
 	// Add a feed first
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -673,14 +709,14 @@ func TestHandleListEntriesWithFilters(t *testing.T) {
 
 	// Create feed and entries
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	now := time.Now()
 	entry := storage.NewEntry(feed.ID, "guid-1", "Entry 1")
 	entry.PublishedAt = &now
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -708,7 +744,7 @@ func TestHandleListEntriesWithSinceUntil(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -730,6 +766,47 @@ func TestHandleListEntriesWithSinceUntil(t *testing.T) {
 	}
 }
 
+func TestHandleListEntriesNaturalDates(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	for guid, published := range map[string]time.Time{
+		"january":  time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC),
+		"february": time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC),
+		"recent":   time.Now().Add(-time.Hour),
+	} {
+		entry := storage.NewEntry(feed.ID, guid, guid)
+		entry.PublishedAt = &published
+		require.NoError(t, store.CreateEntry(ctx, entry))
+	}
+
+	list := func(args map[string]interface{}) []string {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleListEntries(ctx, req)
+		require.NoError(t, err)
+		var output ListEntriesOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		var titles []string
+		for _, e := range output.Entries {
+			titles = append(titles, *e.Title)
+		}
+		return titles
+	}
+
+	require.Equal(t, []string{"january"}, list(map[string]interface{}{"since": "2024-01-01..2024-02-01", "tz": "UTC"}))
+	require.Equal(t, []string{"recent"}, list(map[string]interface{}{"since": "3 days ago"}))
+	require.ElementsMatch(t, []string{"january", "february"}, list(map[string]interface{}{"since": "..2 weeks ago"}))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"since": "2024-01-01..2024-02-01", "until": "today"}
+	_, err := s.handleListEntries(ctx, req)
+	require.Error(t, err, "a since range and until together should be rejected")
+}
+
 func TestHandleListEntriesInvalidInput(t *testing.T) {
 	s, _, _ := testServer(t)
 
@@ -810,6 +887,43 @@ func TestHandleBulkMarkReadInvalidDate(t *testing.T) {
 	}
 }
 
+func TestHandleBulkMarkReadTimezone(t *testing.T) {
+	s, _, _ := testServer(t)
+
+	before := func(args map[string]interface{}) time.Time {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleBulkMarkRead(context.Background(), req)
+		require.NoError(t, err)
+		var output BulkMarkReadOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output.Before
+	}
+
+	// An explicit tz wins
+	got := before(map[string]interface{}{"before": "2024-01-15", "tz": "Asia/Tokyo"})
+	require.True(t, got.Equal(time.Date(2024, 1, 14, 15, 0, 0, 0, time.UTC)), "got %v", got)
+
+	// Otherwise the configured timezone applies
+	profileConfig(t, s).Timezone = "America/New_York"
+	got = before(map[string]interface{}{"before": "2024-01-15"})
+	require.True(t, got.Equal(time.Date(2024, 1, 15, 5, 0, 0, 0, time.UTC)), "got %v", got)
+
+	// Periods start at midnight in that timezone
+	got = before(map[string]interface{}{"before": "today", "tz": "Asia/Kolkata"})
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	local := got.In(kolkata)
+	require.Equal(t, 0, local.Hour())
+	require.Equal(t, 0, local.Minute())
+	require.Equal(t, time.Now().In(kolkata).Day(), local.Day())
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"before": "today", "tz": "Not/AZone"}
+	_, err := s.handleBulkMarkRead(context.Background(), req)
+	require.Error(t, err)
+}
+
 func TestHandleBulkMarkReadNoEntries(t *testing.T) {
 	s, _, _ := testServer(t)
 
@@ -843,16 +957,16 @@ func TestCalculateStats(t *testing.T) {
 	feed.Title = &title
 	now := time.Now()
 	feed.LastFetchedAt = &now
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	entry := storage.NewEntry(feed.ID, "guid-1", "Entry 1")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
-	stats, err := s.calculateStats(store)
+	stats, err := s.calculateStats(context.Background(), store)
 	if err != nil {
 		t.Fatalf("calculateStats: %v", err)
 	}
@@ -954,11 +1068,11 @@ func TestCalculateStatsWithUntitledFeed(t *testing.T) {
 	// No title set
 	now := time.Now()
 	feed.LastFetchedAt = &now
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
-	stats, err := s.calculateStats(store)
+	stats, err := s.calculateStats(context.Background(), store)
 	if err != nil {
 		t.Fatalf("calculateStats: %v", err)
 	}
@@ -980,11 +1094,11 @@ func TestCalculateStatsNoLastSync(t *testing.T) {
 	title := "Test Feed"
 	feed.Title = &title
 	// No LastFetchedAt
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
-	stats, err := s.calculateStats(store)
+	stats, err := s.calculateStats(context.Background(), store)
 	if err != nil {
 		t.Fatalf("calculateStats: %v", err)
 	}
@@ -1003,7 +1117,7 @@ func TestHandleAddFeedDuplicate(t *testing.T) {
 
 	// Add a feed first
 	feed := storage.NewFeed("https://newsite.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1016,6 +1130,43 @@ func TestHandleAddFeedDuplicate(t *testing.T) {
 	}
 }
 
+func TestHandleAddFeedNearDuplicate(t *testing.T) {
+	s, store, _ := testServer(t)
+
+	feed := storage.NewFeed("http://x.com/feed/")
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	for _, variant := range []string{"https://x.com/feed", "https://www.x.com/feed?utm_source=tw"} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"url": variant}
+		_, err := s.handleAddFeed(context.Background(), req)
+		if err == nil || !strings.Contains(err.Error(), "http://x.com/feed/") {
+			t.Errorf("expected %s to be reported as a duplicate of the existing feed, got %v", variant, err)
+		}
+	}
+}
+
+func TestHandleAddFeedStripsTracking(t *testing.T) {
+	s, _, _ := testServer(t)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"url": "https://Tracked.com/feed.xml?utm_source=newsletter#latest"}
+	result, err := s.handleAddFeed(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleAddFeed: %v", err)
+	}
+
+	var output FeedOutput
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if output.URL != "https://tracked.com/feed.xml" {
+		t.Errorf("expected the canonical URL to be stored, got %q", output.URL)
+	}
+}
+
 func TestHandleAddFeedWithTitle(t *testing.T) {
 	s, _, _ := testServer(t)
 
@@ -1064,6 +1215,57 @@ func TestHandleAddFeedWithFolder(t *testing.T) {
 	}
 }
 
+func TestHandleAddFeedBackfill(t *testing.T) {
+	s, store, _ := testServer(t)
+	profileConfig(t, s).Backfill = &config.BackfillConfig{Days: 14, Limit: 50}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{
+		"url":            "https://backfill-test.com/feed.xml",
+		"backfill_limit": float64(20),
+	}
+	result, err := s.handleAddFeed(context.Background(), req)
+	require.NoError(t, err)
+
+	var output FeedOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, 14, output.BackfillDays, "days should come from the config default")
+	require.Equal(t, 20, output.BackfillLimit, "limit should come from the input")
+
+	feed, err := store.GetFeedByURL(context.Background(), "https://backfill-test.com/feed.xml")
+	require.NoError(t, err)
+	require.Equal(t, 14, feed.BackfillDays)
+	require.Equal(t, 20, feed.BackfillLimit)
+
+	req.Params.Arguments = map[string]interface{}{
+		"url":           "https://backfill-bad.com/feed.xml",
+		"backfill_days": float64(-1),
+	}
+	_, err = s.handleAddFeed(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestHandleAddFeedMonitor(t *testing.T) {
+	s, store, _ := testServer(t)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{
+		"url":     "https://monitor-test.com/pricing",
+		"monitor": true,
+	}
+	result, err := s.handleAddFeed(context.Background(), req)
+	require.NoError(t, err)
+
+	var output FeedOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.True(t, output.Monitor)
+
+	feed, err := store.GetFeedByURL(context.Background(), "https://monitor-test.com/pricing")
+	require.NoError(t, err)
+	require.True(t, feed.Monitor)
+	require.Nil(t, feed.PageText)
+}
+
 func TestHandleRemoveFeedNotFound(t *testing.T) {
 	s, _, _ := testServer(t)
 
@@ -1080,12 +1282,12 @@ func TestHandleListEntriesWithFeedFilter(t *testing.T) {
 
 	// Create feed and entries
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	entry := storage.NewEntry(feed.ID, "guid-1", "Entry 1")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -1115,7 +1317,7 @@ func TestHandleListEntriesWithOffset(t *testing.T) {
 
 	// Create feed and multiple entries
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1124,7 +1326,7 @@ func TestHandleListEntriesWithOffset(t *testing.T) {
 		pub := now.Add(time.Duration(-i) * time.Hour)
 		entry := storage.NewEntry(feed.ID, "guid-"+string(rune('0'+i)), "Entry")
 		entry.PublishedAt = &pub
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry: %v", err)
 		}
 	}
@@ -1160,13 +1362,13 @@ func TestHandleGetEntryWithEmptyContent(t *testing.T) {
 	feed := storage.NewFeed("https://example.com/feed.xml")
 	title := "Test Feed"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	entry := storage.NewEntry(feed.ID, "guid-1", "Test Entry")
 	// No content set
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -1193,12 +1395,12 @@ func TestHandleGetEntryFeedWithoutTitle(t *testing.T) {
 	// Create feed without title
 	feed := storage.NewFeed("https://notitle.com/feed.xml")
 	// No title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	entry := storage.NewEntry(feed.ID, "guid-1", "Test Entry")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -1242,7 +1444,7 @@ func TestHandleSyncFeeds(t *testing.T) {
 
 	// Create feed with test server URL
 	feed := storage.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1266,14 +1468,57 @@ func TestHandleSyncFeeds(t *testing.T) {
 	}
 }
 
+func TestHandleSyncFeedsEnforcesUnreadBudgets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0">
+  <channel>
+    <title>Busy Feed</title>
+    <item><title>One</title><guid>g1</guid><pubDate>Mon, 02 Jan 2006 15:04:05 GMT</pubDate></item>
+    <item><title>Two</title><guid>g2</guid><pubDate>Tue, 03 Jan 2006 15:04:05 GMT</pubDate></item>
+    <item><title>Three</title><guid>g3</guid><pubDate>Wed, 04 Jan 2006 15:04:05 GMT</pubDate></item>
+  </channel>
+</rss>`))
+	}))
+	defer server.Close()
+
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+	feed := storage.NewFeed(server.URL)
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	require.NoError(t, pc.opmlDoc.AddFeed(feed.URL, "Busy Feed", "News"))
+	require.NoError(t, pc.opmlDoc.WriteFile(pc.opmlPath))
+	profileConfig(t, s).UnreadBudgets = map[string]int{"News": 1}
+
+	result, err := s.handleSyncFeeds(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	var output SyncFeedsOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+
+	require.Len(t, output.Budgets, 1)
+	trim := output.Budgets[0]
+	require.Equal(t, "News", trim.Folder)
+	require.Equal(t, 1, trim.Budget)
+	require.Equal(t, 3, trim.UnreadBefore)
+	require.Len(t, trim.MarkedRead, 2)
+
+	unread := true
+	left, err := store.ListEntries(ctx, &storage.EntryFilter{UnreadOnly: &unread})
+	require.NoError(t, err)
+	require.Len(t, left, 1)
+	require.Equal(t, "Three", left[0].GetTitle(), "the newest entry should stay unread")
+}
+
 func TestHandleSyncFeedsNoFeeds(t *testing.T) {
 	// Create server with new store (no feeds)
 	s, store, _ := testServer(t)
 
 	// Delete the feed that was created by testServer
-	feeds, _ := store.ListFeeds()
+	feeds, _ := store.ListFeeds(context.Background())
 	for _, feed := range feeds {
-		_ = store.DeleteFeed(feed.ID)
+		_ = store.DeleteFeed(context.Background(), feed.ID)
 	}
 
 	// Sync with no feeds
@@ -1284,6 +1529,24 @@ func TestHandleSyncFeedsNoFeeds(t *testing.T) {
 	}
 }
 
+func TestHandleSyncFeedsRunLockHeld(t *testing.T) {
+	s, store, _ := testServer(t)
+
+	feed := storage.NewFeed("https://example.com/locked.xml")
+	require.NoError(t, store.CreateFeed(context.Background(), feed))
+
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+
+	// Simulate a CLI sync holding the profile lock
+	lock, err := runlock.Acquire(pc.lockPath, 0)
+	require.NoError(t, err)
+	defer lock.Release()
+
+	_, err = s.handleSyncFeeds(context.Background(), mcp.CallToolRequest{})
+	require.ErrorIs(t, err, runlock.ErrLocked)
+}
+
 func TestHandleSyncFeedsWithURL(t *testing.T) {
 	// Create test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1301,7 +1564,7 @@ func TestHandleSyncFeedsWithURL(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1359,7 +1622,7 @@ func TestHandleSyncFeedsWithForce(t *testing.T) {
 	etag := `"force123"`
 	feed := storage.NewFeed(server.URL)
 	feed.ETag = &etag
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1373,6 +1636,52 @@ func TestHandleSyncFeedsWithForce(t *testing.T) {
 	}
 }
 
+func TestHandleSyncFeedsForceReportsCacheHits(t *testing.T) {
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"cache-v1"`)
+		if r.Header.Get("If-None-Match") == `"cache-v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0">
+  <channel>
+    <title>Cache Test Feed</title>
+    <item><title>One</title><guid>cache-1</guid></item>
+  </channel>
+</rss>`))
+	}))
+	defer server.Close()
+
+	s, store, _ := testServer(t)
+	feed := storage.NewFeed(server.URL + "/feed.xml")
+	require.NoError(t, store.CreateFeed(context.Background(), feed))
+
+	var output SyncFeedsOutput
+	for i := 0; i < 2; i++ {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"force": true}
+		result, err := s.handleSyncFeeds(context.Background(), req)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	}
+
+	// The second forced sync revalidated the first one's body instead of
+	// downloading it again
+	require.Equal(t, 1, downloads)
+	require.NotNil(t, output.Cache)
+	require.Equal(t, 1, output.Cache.BodyHits)
+	require.Equal(t, 1, output.Cache.BodyRequests)
+	require.Zero(t, output.TotalErrors)
+	require.Zero(t, output.TotalNew)
+}
+
 func TestHandleSyncFeedsCached(t *testing.T) {
 	etag := `"cached123"`
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1390,7 +1699,7 @@ func TestHandleSyncFeedsCached(t *testing.T) {
 	// Create feed with etag
 	feed := storage.NewFeed(server.URL)
 	feed.ETag = &etag
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1422,7 +1731,7 @@ func TestHandleSyncFeedsWithError(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1443,6 +1752,49 @@ func TestHandleSyncFeedsWithError(t *testing.T) {
 	}
 }
 
+func TestHandleSyncFeedsRecordsLastSync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	s, store, _ := testServer(t)
+
+	readLast := func() string {
+		resp := s.mcpServer.HandleMessage(context.Background(), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "resources/read",
+			"params": {"uri": "digest://sync/last"}
+		}`))
+		respJSON, err := json.Marshal(resp)
+		require.NoError(t, err)
+		return string(respJSON)
+	}
+	require.Contains(t, readLast(), "no sync recorded yet")
+
+	feed := storage.NewFeed(server.URL)
+	require.NoError(t, store.CreateFeed(context.Background(), feed))
+	_, err := s.handleSyncFeeds(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+	run, err := feedsync.LoadRun(pc.runPath)
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	require.Equal(t, "mcp", run.Source)
+	require.Equal(t, 1, run.Synced)
+	require.Equal(t, 1, run.Errors)
+	require.Len(t, run.Feeds, 1)
+	require.Equal(t, feedsync.RunError, run.Feeds[0].Status)
+	require.NotEmpty(t, run.Feeds[0].Detail)
+
+	respStr := readLast()
+	require.Contains(t, respStr, `\"source\": \"mcp\"`)
+	require.Contains(t, respStr, server.URL)
+}
+
 func TestSyncFeed_ParseError(t *testing.T) {
 	// Server that returns invalid content
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1455,7 +1807,7 @@ func TestSyncFeed_ParseError(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1494,7 +1846,7 @@ func TestSyncFeed_UpdatesTitle(t *testing.T) {
 	// Create feed without title
 	feed := storage.NewFeed(server.URL)
 	// No title set
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1506,7 +1858,7 @@ func TestSyncFeed_UpdatesTitle(t *testing.T) {
 	}
 
 	// Check title was updated
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed: %v", err)
 	}
@@ -1542,13 +1894,13 @@ func TestSyncFeed_WithExistingEntries(t *testing.T) {
 	feed := storage.NewFeed(server.URL)
 	title := "Test"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Create existing entry
 	entry := storage.NewEntry(feed.ID, "existing-guid", "Existing")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -1589,7 +1941,7 @@ func TestHandleSyncFeedsTitleDisplay(t *testing.T) {
 	feed := storage.NewFeed(server.URL)
 	title := "My Feed Title"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1632,7 +1984,7 @@ func TestHandleSyncFeedsURLFallbackTitle(t *testing.T) {
 	// Create feed WITHOUT title
 	feed := storage.NewFeed(server.URL)
 	// No title set
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1666,20 +2018,20 @@ func TestHandleRemoveFeedCascadeDelete(t *testing.T) {
 
 	// Add feed to store
 	feed := storage.NewFeed(url)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Add entries
 	for i := 0; i < 3; i++ {
 		entry := storage.NewEntry(feed.ID, "cascade-guid-"+string(rune('0'+i)), "Entry")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry: %v", err)
 		}
 	}
 
 	// Verify entries exist
-	entries, _ := store.ListEntries(&storage.EntryFilter{FeedID: &feed.ID})
+	entries, _ := store.ListEntries(context.Background(), &storage.EntryFilter{FeedID: &feed.ID})
 	if len(entries) != 3 {
 		t.Fatalf("expected 3 entries before delete, got %d", len(entries))
 	}
@@ -1693,7 +2045,7 @@ func TestHandleRemoveFeedCascadeDelete(t *testing.T) {
 	}
 
 	// Verify entries are gone
-	entries, _ = store.ListEntries(nil)
+	entries, _ = store.ListEntries(context.Background(), nil)
 	for _, e := range entries {
 		if e.FeedID == feed.ID {
 			t.Error("expected entries to be cascade deleted")
@@ -1709,7 +2061,7 @@ func TestHandleMoveFeedToEmptyFolder(t *testing.T) {
 
 	// Add feed to store
 	feed := storage.NewFeed(url)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1822,7 +2174,7 @@ func TestHandleListEntriesWithUntilFilter(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1849,12 +2201,12 @@ func TestHandleListEntriesWithUnreadOnly(t *testing.T) {
 
 	// Create feed and entry
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	entry := storage.NewEntry(feed.ID, "guid-unread", "Unread Entry")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -1876,6 +2228,323 @@ func TestHandleListEntriesWithUnreadOnly(t *testing.T) {
 	}
 }
 
+func TestHandleListEntriesFields(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	entry := storage.NewEntry(feed.ID, "guid-fields", "Headline")
+	link := "https://example.com/headline"
+	content := strings.Repeat("<p>body</p>", 1000)
+	entry.Link = &link
+	entry.Content = &content
+	if err := store.CreateEntry(ctx, entry); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"fields": []interface{}{"Title", "link"}}
+	result, err := s.handleListEntries(ctx, req)
+	if err != nil {
+		t.Fatalf("handleListEntries: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	var output struct {
+		Entries []map[string]any `json:"entries"`
+		Count   int              `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(text), &output); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if output.Count != 1 || len(output.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(output.Entries))
+	}
+	want := map[string]any{"id": entry.ID, "title": "Headline", "link": link}
+	if !reflect.DeepEqual(output.Entries[0], want) {
+		t.Errorf("entry = %v, want %v", output.Entries[0], want)
+	}
+	if strings.Contains(text, "body") {
+		t.Error("listing carries the entry's content")
+	}
+
+	req.Params.Arguments = map[string]interface{}{"fields": []interface{}{"content"}}
+	if _, err := s.handleListEntries(ctx, req); err == nil || !strings.Contains(err.Error(), `unknown field "content"`) {
+		t.Errorf("content field: err = %v, want unknown field", err)
+	}
+}
+
+func TestEntryFieldNamesMatchOutput(t *testing.T) {
+	var tags []string
+	typ := reflect.TypeOf(EntryOutput{})
+	for i := range typ.NumField() {
+		tags = append(tags, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+	}
+	if !reflect.DeepEqual(tags, entryFieldNames) {
+		t.Errorf("entryFieldNames = %v, EntryOutput has %v", entryFieldNames, tags)
+	}
+}
+
+func TestHandleListEntriesFirstSeen(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	now := time.Now()
+	recent := now.Add(-time.Hour)
+	backfilled := now.AddDate(-1, 0, 0)
+	fresh := storage.NewEntry(feed.ID, "guid-fresh", "Fresh")
+	fresh.PublishedAt = &recent
+	fresh.CreatedAt = now.Add(-2 * time.Hour)
+	old := storage.NewEntry(feed.ID, "guid-old", "Backfilled")
+	old.PublishedAt = &backfilled
+	old.CreatedAt = now
+	for _, e := range []*models.Entry{fresh, old} {
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+	}
+	if err := store.UpdateFeedFetchState(ctx, feed.ID, nil, nil, now); err != nil {
+		t.Fatalf("UpdateFeedFetchState: %v", err)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"first_seen": true, "since": "today"},
+		{"new_only": true},
+	} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleListEntries(ctx, req)
+		if err != nil {
+			t.Fatalf("handleListEntries(%v): %v", args, err)
+		}
+		var output ListEntriesOutput
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output); err != nil {
+			t.Fatalf("unmarshal output: %v", err)
+		}
+		if output.Entries[0].ID != old.ID {
+			t.Errorf("%v: expected the backfilled entry first, got %q", args, output.Entries[0].ID)
+		}
+	}
+}
+
+func TestHandleListEntriesByScore(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://news.ycombinator.com/rss")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	now := time.Now()
+	for title, points := range map[string]int{"Popular": 512, "Quiet": 8} {
+		e := storage.NewEntry(feed.ID, title, title)
+		comments := points / 4
+		e.Score, e.CommentCount, e.ScoredAt = &points, &comments, &now
+		require.NoError(t, store.CreateEntry(ctx, e))
+	}
+	require.NoError(t, store.CreateEntry(ctx, storage.NewEntry(feed.ID, "unscored", "Unscored")))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"by_score": true, "min_score": 100}
+	result, err := s.handleListEntries(ctx, req)
+	require.NoError(t, err)
+	var output ListEntriesOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Len(t, output.Entries, 1)
+	require.Equal(t, "Popular", *output.Entries[0].Title)
+	require.Equal(t, 512, *output.Entries[0].Score)
+	require.Equal(t, 128, *output.Entries[0].CommentCount)
+	require.Equal(t, true, output.Filters["by_score"])
+}
+
+func TestHandleListEntriesMarkRead(t *testing.T) {
+	s, store, _ := testServer(t, WithLimits(config.MCPLimits{BulkMarkReadMax: 3}))
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	for _, guid := range []string{"one", "two", "three", "four"} {
+		require.NoError(t, store.CreateEntry(ctx, storage.NewEntry(feed.ID, guid, guid)))
+	}
+
+	list := func(args map[string]interface{}) (ListEntriesOutput, error) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleListEntries(ctx, req)
+		if err != nil {
+			return ListEntriesOutput{}, err
+		}
+		var output ListEntriesOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output, nil
+	}
+	unreadCount := func() int {
+		t.Helper()
+		unread := true
+		entries, err := store.ListEntries(ctx, &storage.EntryFilter{UnreadOnly: &unread})
+		require.NoError(t, err)
+		return len(entries)
+	}
+
+	// More unread entries than bulk_mark_read allows are refused outright
+	_, err := list(map[string]interface{}{"mark_read": true})
+	require.ErrorContains(t, err, "more than the limit")
+	require.Equal(t, 4, unreadCount())
+
+	output, err := list(map[string]interface{}{"unread_only": true, "limit": 2, "mark_read": true})
+	require.NoError(t, err)
+	require.Equal(t, 2, output.MarkedRead)
+	require.False(t, output.Entries[0].Read, "entries should show their state from before the call")
+	require.Equal(t, 2, unreadCount())
+
+	// list_marks_read makes it the default; mark_read=false overrides it
+	profileConfig(t, s).ListMarksRead = true
+	output, err = list(map[string]interface{}{"unread_only": true, "mark_read": false})
+	require.NoError(t, err)
+	require.Zero(t, output.MarkedRead)
+	require.Equal(t, 2, unreadCount())
+	output, err = list(map[string]interface{}{"unread_only": true})
+	require.NoError(t, err)
+	require.Equal(t, 2, output.MarkedRead)
+	require.Zero(t, unreadCount())
+}
+
+func TestHandleListEntriesMarkReadOnReadOnlyServer(t *testing.T) {
+	s, store, _ := testServer(t, WithReadOnly())
+	ctx := context.Background()
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	require.NoError(t, store.CreateEntry(ctx, storage.NewEntry(feed.ID, "one", "One")))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"mark_read": true}
+	_, err := s.handleListEntries(ctx, req)
+	require.ErrorContains(t, err, "read-only")
+
+	// The config default doesn't apply to a read-only server
+	profileConfig(t, s).ListMarksRead = true
+	req.Params.Arguments = map[string]interface{}{}
+	result, err := s.handleListEntries(ctx, req)
+	require.NoError(t, err)
+	var output ListEntriesOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Zero(t, output.MarkedRead)
+}
+
+func TestHandleKeepUnread(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	twoDaysAgo := time.Now().Add(-48 * time.Hour)
+	kept := storage.NewEntry(feed.ID, "kept", "Kept")
+	kept.PublishedAt = &twoDaysAgo
+	kept.Read = true
+	other := storage.NewEntry(feed.ID, "other", "Other")
+	other.PublishedAt = &twoDaysAgo
+	require.NoError(t, store.CreateEntry(ctx, kept))
+	require.NoError(t, store.CreateEntry(ctx, other))
+
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}, out any) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := handler(ctx, req)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), out))
+	}
+
+	var entry EntryOutput
+	call(s.handleKeepUnread, map[string]interface{}{"entry_id": kept.ID}, &entry)
+	require.True(t, entry.KeepUnread)
+	require.False(t, entry.Read, "keeping an entry should mark it unread")
+
+	var listed ListEntriesOutput
+	call(s.handleListEntries, map[string]interface{}{"kept_only": true}, &listed)
+	require.Len(t, listed.Entries, 1)
+	require.Equal(t, kept.ID, listed.Entries[0].ID)
+
+	var bulk BulkMarkReadOutput
+	call(s.handleBulkMarkRead, map[string]interface{}{"before": "today"}, &bulk)
+	require.EqualValues(t, 1, bulk.Count)
+	got, err := store.GetEntry(ctx, kept.ID)
+	require.NoError(t, err)
+	require.False(t, got.Read, "bulk_mark_read should skip kept entries")
+
+	// Marking it read explicitly releases the pin
+	var marked EntryOutput
+	call(s.handleMarkRead, map[string]interface{}{"entry_id": kept.ID}, &marked)
+	require.True(t, marked.Read)
+	require.False(t, marked.KeepUnread)
+
+	call(s.handleKeepUnread, map[string]interface{}{"entry_id": kept.ID}, &entry)
+	var released EntryOutput
+	call(s.handleKeepUnread, map[string]interface{}{"entry_id": kept.ID, "keep": false}, &released)
+	require.False(t, released.KeepUnread)
+	require.False(t, released.Read, "releasing should leave the entry unread")
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": "missing"}
+	_, err = s.handleKeepUnread(ctx, req)
+	require.ErrorContains(t, err, "entry not found")
+}
+
+func TestHandleGetChanges(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	for _, guid := range []string{"one", "two"} {
+		require.NoError(t, store.CreateEntry(ctx, storage.NewEntry(feed.ID, guid, guid)))
+	}
+
+	call := func(args map[string]interface{}) GetChangesOutput {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleGetChanges(ctx, req)
+		require.NoError(t, err)
+		var output GetChangesOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output
+	}
+
+	first := call(map[string]interface{}{"limit": 1})
+	require.Equal(t, 1, first.Count)
+	require.True(t, first.More)
+	rest := call(map[string]interface{}{"cursor": first.Cursor})
+	require.Equal(t, 1, rest.Count)
+	require.False(t, rest.More)
+	require.NotEqual(t, first.Entries[0].ID, rest.Entries[0].ID)
+
+	require.NoError(t, store.MarkEntryRead(ctx, first.Entries[0].ID))
+	changed := call(map[string]interface{}{"cursor": rest.Cursor})
+	require.Equal(t, 1, changed.Count)
+	require.True(t, changed.Entries[0].Read)
+	require.False(t, changed.Entries[0].UpdatedAt.IsZero())
+	require.Empty(t, changed.Deleted)
+
+	require.NoError(t, store.DeleteEntry(ctx, first.Entries[0].ID))
+	removed := call(map[string]interface{}{"cursor": changed.Cursor})
+	require.Equal(t, 1, removed.Count)
+	require.Empty(t, removed.Entries)
+	require.Len(t, removed.Deleted, 1)
+	require.Equal(t, first.Entries[0].ID, removed.Deleted[0].ID)
+	require.Equal(t, feed.ID, removed.Deleted[0].FeedID)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"cursor": "bogus"}
+	_, err := s.handleGetChanges(ctx, req)
+	require.ErrorContains(t, err, "invalid cursor")
+}
+
 func TestHandleGetEntryMissingEntryID(t *testing.T) {
 	s, _, _ := testServer(t)
 
@@ -1965,7 +2634,7 @@ func TestHandleSyncFeedsMissingURL(t *testing.T) {
 
 	// Add a feed first
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1989,7 +2658,7 @@ func TestCalculateStatsWithMultipleFeeds(t *testing.T) {
 	title1 := "Feed 1"
 	feed1.Title = &title1
 	feed1.LastFetchedAt = &now
-	if err := store.CreateFeed(feed1); err != nil {
+	if err := store.CreateFeed(context.Background(), feed1); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -1998,23 +2667,23 @@ func TestCalculateStatsWithMultipleFeeds(t *testing.T) {
 	title2 := "Feed 2"
 	feed2.Title = &title2
 	feed2.LastFetchedAt = &yesterday
-	if err := store.CreateFeed(feed2); err != nil {
+	if err := store.CreateFeed(context.Background(), feed2); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Add entries
 	entry1 := storage.NewEntry(feed1.ID, "guid-1", "Entry 1")
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
 	entry2 := storage.NewEntry(feed2.ID, "guid-2", "Entry 2")
 	entry2.Read = true
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
-	stats, err := s.calculateStats(store)
+	stats, err := s.calculateStats(context.Background(), store)
 	if err != nil {
 		t.Fatalf("calculateStats: %v", err)
 	}
@@ -2050,11 +2719,11 @@ func TestCalculateStatsFeedWithErrors(t *testing.T) {
 	lastError := "some fetch error"
 	feed.LastError = &lastError
 	feed.ErrorCount = 3
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
-	stats, err := s.calculateStats(store)
+	stats, err := s.calculateStats(context.Background(), store)
 	if err != nil {
 		t.Fatalf("calculateStats: %v", err)
 	}
@@ -2081,7 +2750,7 @@ func TestHandleListEntriesAllOptionalFields(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2096,7 +2765,7 @@ func TestHandleListEntriesAllOptionalFields(t *testing.T) {
 	content := "Full content"
 	entry.Content = &content
 
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -2128,7 +2797,7 @@ func TestHandleBulkMarkReadWithEntries(t *testing.T) {
 
 	// Create feed and entries
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2138,7 +2807,7 @@ func TestHandleBulkMarkReadWithEntries(t *testing.T) {
 
 	entry := storage.NewEntry(feed.ID, "bulk-guid-1", "Old Entry")
 	entry.PublishedAt = &twoDaysAgo
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -2169,7 +2838,7 @@ func TestHandleBulkMarkReadWithDateString(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2199,7 +2868,7 @@ func TestHandleGetEntryWithAllFields(t *testing.T) {
 	feed := storage.NewFeed("https://example.com/feed.xml")
 	title := "Feed Title"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2215,7 +2884,7 @@ func TestHandleGetEntryWithAllFields(t *testing.T) {
 	entry.Content = &content
 	entry.Read = true
 	entry.ReadAt = &now
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -2278,12 +2947,12 @@ func TestHandleSyncFeedsMultipleWithMixedResults(t *testing.T) {
 
 	// Create two feeds
 	goodFeed := storage.NewFeed(goodServer.URL)
-	if err := store.CreateFeed(goodFeed); err != nil {
+	if err := store.CreateFeed(context.Background(), goodFeed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	badFeed := storage.NewFeed(badServer.URL)
-	if err := store.CreateFeed(badFeed); err != nil {
+	if err := store.CreateFeed(context.Background(), badFeed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2320,7 +2989,7 @@ func TestHandleMoveFeedSameFolderMessage(t *testing.T) {
 
 	// Add feed to store
 	feed := storage.NewFeed(url)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2356,18 +3025,18 @@ func TestCalculateStatsReturnStruct(t *testing.T) {
 	feed.ETag = &etag
 	lastModified := "Wed, 01 Jan 2025 00:00:00 GMT"
 	feed.LastModified = &lastModified
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Add an entry
 	entry := storage.NewEntry(feed.ID, "stats-test-guid", "Test Entry")
 	entry.PublishedAt = &now
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
-	stats, err := s.calculateStats(store)
+	stats, err := s.calculateStats(context.Background(), store)
 	if err != nil {
 		t.Fatalf("calculateStats: %v", err)
 	}
@@ -2427,7 +3096,7 @@ func TestHandleListFeedsWithAllOptionalFields(t *testing.T) {
 	lastError := "some error"
 	feed.LastError = &lastError
 	feed.ErrorCount = 2
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2469,7 +3138,7 @@ func TestHandleListEntriesOutputFormat(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2483,7 +3152,7 @@ func TestHandleListEntriesOutputFormat(t *testing.T) {
 	entry.PublishedAt = &now
 	entry.Read = true
 	entry.ReadAt = &now
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -2535,7 +3204,7 @@ func TestHandleMoveFeedNotInOPML(t *testing.T) {
 
 	// Create a feed that's in storage but NOT in OPML
 	feed := storage.NewFeed("https://notinopml.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2618,25 +3287,34 @@ func TestSyncFeedWithEntryAllFields(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Sync
-	newCount, wasCached, err := s.syncFeed(context.Background(), store, feed, false)
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"url": server.URL}
+	result, err := s.handleSyncFeeds(context.Background(), req)
 	if err != nil {
-		t.Fatalf("syncFeed: %v", err)
+		t.Fatalf("handleSyncFeeds: %v", err)
+	}
+	var output SyncFeedsOutput
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
 	}
 
-	if newCount != 1 {
-		t.Errorf("expected 1 new entry, got %d", newCount)
+	if len(output.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(output.Results))
+	}
+	if output.Results[0].NewEntries != 1 {
+		t.Errorf("expected 1 new entry, got %d", output.Results[0].NewEntries)
 	}
-	if wasCached {
+	if output.Results[0].WasCached {
 		t.Error("expected wasCached=false")
 	}
 
 	// Verify entry was created with all fields
-	entries, _ := store.ListEntries(&storage.EntryFilter{FeedID: &feed.ID})
+	entries, _ := store.ListEntries(context.Background(), &storage.EntryFilter{FeedID: &feed.ID})
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
 	}
@@ -2674,18 +3352,19 @@ func TestSyncFeedWithEmptyTitle(t *testing.T) {
 	feed := storage.NewFeed(server.URL)
 	emptyTitle := ""
 	feed.Title = &emptyTitle
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Sync (should update empty title)
-	_, _, err := s.syncFeed(context.Background(), store, feed, false)
-	if err != nil {
-		t.Fatalf("syncFeed: %v", err)
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"url": server.URL}
+	if _, err := s.handleSyncFeeds(context.Background(), req); err != nil {
+		t.Fatalf("handleSyncFeeds: %v", err)
 	}
 
 	// Verify title was updated
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed: %v", err)
 	}
@@ -2701,7 +3380,7 @@ func TestHandleGetEntryWithEmptyStringContent(t *testing.T) {
 	feed := storage.NewFeed("https://example.com/feed.xml")
 	title := "Test Feed"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2709,7 +3388,7 @@ func TestHandleGetEntryWithEmptyStringContent(t *testing.T) {
 	entry := storage.NewEntry(feed.ID, "empty-content-guid", "Empty Content Entry")
 	emptyContent := ""
 	entry.Content = &emptyContent
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -2768,7 +3447,7 @@ func TestResourceFeedsViaHandleMessage(t *testing.T) {
 	lastError := "some error"
 	feed.LastError = &lastError
 	feed.ErrorCount = 2
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2809,7 +3488,7 @@ func TestResourceEntriesUnreadViaHandleMessage(t *testing.T) {
 
 	// Create feed and unread entry
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2822,7 +3501,7 @@ func TestResourceEntriesUnreadViaHandleMessage(t *testing.T) {
 	entry.Author = &author
 	content := "Test content"
 	entry.Content = &content
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -2854,7 +3533,7 @@ func TestResourceEntriesTodayViaHandleMessage(t *testing.T) {
 
 	// Create feed and today's entry
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2869,7 +3548,7 @@ func TestResourceEntriesTodayViaHandleMessage(t *testing.T) {
 	entry.Content = &content
 	entry.Read = true
 	entry.ReadAt = &now
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -2896,6 +3575,84 @@ func TestResourceEntriesTodayViaHandleMessage(t *testing.T) {
 	}
 }
 
+func TestResourceGoals(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+	profileConfig(t, s).Goals = &goals.Config{DailyReads: 2, MaxUnread: 1}
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	for _, guid := range []string{"g1", "g2", "g3"} {
+		require.NoError(t, store.CreateEntry(ctx, storage.NewEntry(feed.ID, guid, "Entry "+guid)))
+	}
+	entries, err := store.ListEntries(ctx, &storage.EntryFilter{})
+	require.NoError(t, err)
+	require.NoError(t, store.MarkEntryRead(ctx, entries[0].ID))
+
+	resp := s.mcpServer.HandleMessage(ctx, []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "resources/read",
+		"params": {"uri": "digest://goals"}
+	}`))
+	respJSON, err := json.Marshal(resp)
+	require.NoError(t, err)
+	var envelope struct {
+		Result struct {
+			Contents []struct {
+				Text string `json:"text"`
+			} `json:"contents"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(respJSON, &envelope))
+	require.Len(t, envelope.Result.Contents, 1)
+
+	var data struct {
+		Data GoalsData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(envelope.Result.Contents[0].Text), &data))
+	require.Equal(t, 1, data.Data.ReadToday)
+	require.Equal(t, 2, data.Data.DailyReads)
+	require.False(t, data.Data.TodayMet)
+	require.Equal(t, 2, data.Data.Unread)
+	require.False(t, data.Data.InboxMet)
+	require.Len(t, data.Data.Days, goals.HistoryDays)
+	require.Len(t, data.Data.Nudges, 2)
+}
+
+func TestResourceDigests(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	require.NoError(t, store.CreateEntry(ctx, storage.NewEntry(feed.ID, "g1", "Digested Entry")))
+
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+	tmpl, err := render.Load("", digests.DefaultTemplate)
+	require.NoError(t, err)
+	g := &digests.Generator{Dir: pc.digestsDir, Store: store, OPML: pc.opmlDoc, Template: tmpl}
+	rec, wrote, err := g.Generate(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, wrote)
+
+	read := func(uri string) string {
+		resp := s.mcpServer.HandleMessage(ctx, []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "resources/read",
+			"params": {"uri": "`+uri+`"}
+		}`))
+		respJSON, err := json.Marshal(resp)
+		require.NoError(t, err)
+		return string(respJSON)
+	}
+	require.Contains(t, read("digest://digests"), "digest://digests/"+rec.Date)
+	require.Contains(t, read("digest://digests/"+rec.Date), "Digested Entry")
+	require.Contains(t, read("digest://digests/1999-01-01"), "no digest for 1999-01-01")
+}
+
 func TestResourceStatsViaHandleMessage(t *testing.T) {
 	s, store, _ := testServer(t)
 
@@ -2908,13 +3665,13 @@ func TestResourceStatsViaHandleMessage(t *testing.T) {
 	lastError := "stats test error"
 	feed.LastError = &lastError
 	feed.ErrorCount = 1
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Create entry
 	entry := storage.NewEntry(feed.ID, "stats-guid", "Stats Entry")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -2960,7 +3717,7 @@ func TestResourceFeedsWithAllFields(t *testing.T) {
 	lastError := "all fields error"
 	feed.LastError = &lastError
 	feed.ErrorCount = 5
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -2993,7 +3750,7 @@ func TestResourceEntriesWithAllFields(t *testing.T) {
 
 	// Create feed
 	feed := storage.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -3009,7 +3766,7 @@ func TestResourceEntriesWithAllFields(t *testing.T) {
 	entry.Content = &content
 	entry.Read = true
 	entry.ReadAt = &now
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -3165,3 +3922,1291 @@ func TestInvalidProfileNameReturnsError(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "invalid profile name")
 }
+
+func TestHandleGetFeed(t *testing.T) {
+	s, store, _ := testServer(t)
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	title := "Example Blog"
+	feed.Title = &title
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if err := store.UpdateFeedError(context.Background(), feed.ID, "timeout"); err != nil {
+		t.Fatalf("UpdateFeedError: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		entry := storage.NewEntry(feed.ID, fmt.Sprintf("guid-%d", i), fmt.Sprintf("Entry %d", i))
+		published := time.Now().Add(-time.Duration(i) * time.Hour)
+		entry.PublishedAt = &published
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+		if i == 0 {
+			require.NoError(t, store.MarkEntryRead(context.Background(), entry.ID))
+		}
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"feed": feed.ID[:8], "entry_limit": 2}
+	result, err := s.handleGetFeed(context.Background(), req)
+	require.NoError(t, err)
+
+	var output GetFeedOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+
+	require.Equal(t, feed.ID, output.ID)
+	require.Equal(t, "Tech", output.Folder)
+	require.True(t, output.InOPML)
+	require.Equal(t, 3, output.EntryCount)
+	require.Equal(t, 2, output.UnreadCount)
+	require.Len(t, output.RecentEntries, 2)
+	require.Equal(t, "Entry 0", *output.RecentEntries[0].Title)
+	require.Equal(t, 1, output.ErrorCount)
+	require.NotNil(t, output.LastError)
+	require.Equal(t, "timeout", *output.LastError)
+}
+
+func TestHandleGetFeedByURL(t *testing.T) {
+	s, store, _ := testServer(t)
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(context.Background(), feed))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"feed": "https://example.com/feed.xml"}
+	result, err := s.handleGetFeed(context.Background(), req)
+	require.NoError(t, err)
+
+	var output GetFeedOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, feed.ID, output.ID)
+	require.NotNil(t, output.RecentEntries)
+}
+
+func TestHandleGetFeedNotFound(t *testing.T) {
+	s, _, _ := testServer(t)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"feed": "https://nope.example.com/feed"}
+	_, err := s.handleGetFeed(context.Background(), req)
+	require.Error(t, err)
+
+	req.Params.Arguments = map[string]interface{}{"feed": "https://example.com/feed.xml", "entry_limit": -1}
+	_, err = s.handleGetFeed(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestHandleUpdateFeed(t *testing.T) {
+	s, store, opmlPath := testServer(t)
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(context.Background(), feed))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{
+		"feed":          feed.ID[:8],
+		"title":         "Renamed Blog",
+		"folder":        "News",
+		"paused":        true,
+		"sync_interval": "2h",
+		"max_entries":   50,
+		"identity":      "hash",
+		"priority":      "high",
+		"browser":       true,
+		"auth_username": "reader",
+		"auth_password": "hunter2",
+	}
+	result, err := s.handleUpdateFeed(context.Background(), req)
+	require.NoError(t, err)
+
+	var output UpdateFeedOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.True(t, output.Success)
+	require.Equal(t, "News", output.Feed.Folder)
+	require.True(t, output.Feed.Paused)
+	require.Equal(t, "2h0m0s", output.Feed.SyncInterval)
+	require.Equal(t, 50, output.Feed.MaxEntries)
+	require.Equal(t, models.PriorityHigh, output.Feed.Priority)
+	require.True(t, output.Feed.Browser)
+	require.True(t, output.Feed.HasAuth)
+	require.NotContains(t, result.Content[0].(mcp.TextContent).Text, "hunter2")
+
+	// Storage reflects the changes
+	stored, err := store.GetFeed(context.Background(), feed.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Renamed Blog", *stored.Title)
+	require.True(t, stored.Paused)
+	require.Equal(t, 2*time.Hour, stored.SyncInterval)
+	require.Equal(t, 50, stored.MaxEntries)
+	require.Equal(t, models.IdentityHash, stored.Identity)
+	require.Equal(t, models.PriorityHigh, stored.Priority)
+	require.True(t, stored.Browser)
+	// The password goes to the secrets store, not the database
+	require.Equal(t, secrets.Ref(config.FeedPasswordSecret(feed.ID)), *stored.AuthPassword)
+	provider, err := s.cfg.Secrets()
+	require.NoError(t, err)
+	password, err := secrets.Resolve(provider, *stored.AuthPassword)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", password)
+
+	// OPML reflects title and folder
+	doc, err := opml.ParseFile(opmlPath)
+	require.NoError(t, err)
+	newsFeeds := doc.FeedsInFolder("News")
+	require.Len(t, newsFeeds, 1)
+	require.Equal(t, "Renamed Blog", newsFeeds[0].Title)
+
+	// Clearing credentials removes both fields
+	req.Params.Arguments = map[string]interface{}{"feed": feed.URL, "auth_username": ""}
+	_, err = s.handleUpdateFeed(context.Background(), req)
+	require.NoError(t, err)
+	stored, err = store.GetFeed(context.Background(), feed.ID)
+	require.NoError(t, err)
+	require.Nil(t, stored.AuthUsername)
+	require.Nil(t, stored.AuthPassword)
+	_, err = provider.Get(config.FeedPasswordSecret(feed.ID))
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	// Archiving and unarchiving; an unarchived feed is kept active
+	req.Params.Arguments = map[string]interface{}{"feed": feed.URL, "archived": true}
+	result, err = s.handleUpdateFeed(context.Background(), req)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.NotNil(t, output.Feed.ArchivedAt)
+	req.Params.Arguments = map[string]interface{}{"feed": feed.URL, "archived": false}
+	_, err = s.handleUpdateFeed(context.Background(), req)
+	require.NoError(t, err)
+	stored, err = store.GetFeed(context.Background(), feed.ID)
+	require.NoError(t, err)
+	require.False(t, stored.IsArchived())
+	require.True(t, stored.KeepActive)
+}
+
+func TestHandlePruneEntries(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	feed.MaxEntries = 2
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	for i, guid := range []string{"g1", "g2", "g3", "g4"} {
+		published := time.Date(2006, 1, 2+i, 15, 4, 5, 0, time.UTC)
+		e := storage.NewEntry(feed.ID, guid, guid)
+		e.PublishedAt = &published
+		require.NoError(t, store.CreateEntry(ctx, e))
+	}
+
+	prune := func(dryRun bool) PruneEntriesOutput {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"feed": feed.ID[:8], "dry_run": dryRun}
+		result, err := s.handlePruneEntries(ctx, req)
+		require.NoError(t, err)
+		var output PruneEntriesOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output
+	}
+
+	// A dry run returns the plan and deletes nothing
+	plan := prune(true)
+	require.True(t, plan.DryRun)
+	require.Zero(t, plan.Deleted)
+	require.Equal(t, 2, plan.Entries)
+	require.True(t, plan.Measured)
+	require.Equal(t, 2, plan.FTSRows)
+	require.Positive(t, plan.Bytes)
+	require.Len(t, plan.Feeds, 1)
+	require.Equal(t, 4, plan.Feeds[0].Stored)
+	entries, err := store.ListEntries(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	done := prune(false)
+	require.False(t, done.DryRun)
+	require.Equal(t, 2, done.Deleted)
+	entries, err = store.ListEntries(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "g4", entries[0].GUID)
+
+	require.Empty(t, prune(true).Feeds)
+}
+
+func TestHandleUpdateFeedValidation(t *testing.T) {
+	s, store, _ := testServer(t)
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(context.Background(), feed))
+
+	tests := []map[string]interface{}{
+		{"feed": "https://nope.example.com/feed"},
+		{"feed": feed.URL, "sync_interval": "soon"},
+		{"feed": feed.URL, "sync_interval": "-1h"},
+		{"feed": feed.URL, "sync_interval": "500ms"},
+		{"feed": feed.URL, "max_entries": -5},
+		{"feed": feed.URL, "title": "   "},
+		{"feed": feed.URL, "auth_password": "orphan"},
+		{"feed": feed.URL, "identity": "title"},
+		{"feed": feed.URL, "priority": "urgent"},
+	}
+	for _, args := range tests {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		_, err := s.handleUpdateFeed(context.Background(), req)
+		require.Error(t, err, "args: %v", args)
+	}
+
+	// Nothing was persisted by the failed calls
+	stored, err := store.GetFeed(context.Background(), feed.ID)
+	require.NoError(t, err)
+	require.Zero(t, stored.MaxEntries)
+	require.Zero(t, stored.SyncInterval)
+}
+
+func TestHandleSyncFeedsSkipsPaused(t *testing.T) {
+	s, store, _ := testServer(t)
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	feed.Paused = true
+	require.NoError(t, store.CreateFeed(context.Background(), feed))
+
+	req := mcp.CallToolRequest{}
+	result, err := s.handleSyncFeeds(context.Background(), req)
+	require.NoError(t, err)
+
+	var output SyncFeedsOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, 1, output.TotalSkipped)
+	require.Equal(t, "paused", output.Results[0].Skipped)
+}
+
+// scopedTestServer returns a server scoped to the Tech folder, with one entry in the
+// in-scope Tech feed and one in an out-of-scope Private feed.
+func scopedTestServer(t *testing.T) (s *Server, inner storage.Store, visible, hidden *models.Entry) {
+	t.Helper()
+	s, store, _ := testServer(t, WithScope(Scope{Folders: []string{"tech"}}))
+	inner = store.(*scopedStore).inner
+	ctx := context.Background()
+
+	techFeed := storage.NewFeed("https://example.com/feed.xml")
+	privateFeed := storage.NewFeed("https://private.example.com/feed.xml")
+	privateFeed.Folder = "Private"
+	published := time.Now().Add(-time.Hour)
+	for _, feed := range []*models.Feed{techFeed, privateFeed} {
+		require.NoError(t, inner.CreateFeed(ctx, feed))
+		entry := storage.NewEntry(feed.ID, "guid-"+feed.ID, "Entry for "+feed.URL)
+		entry.PublishedAt = &published
+		require.NoError(t, inner.CreateEntry(ctx, entry))
+		if feed == techFeed {
+			visible = entry
+		} else {
+			hidden = entry
+		}
+	}
+	return s, inner, visible, hidden
+}
+
+func TestScopedServerHidesOutOfScopeFeeds(t *testing.T) {
+	s, inner, visible, hidden := scopedTestServer(t)
+	ctx := context.Background()
+
+	result, err := s.handleListEntries(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	var entries ListEntriesOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &entries))
+	require.Equal(t, 1, entries.Count)
+	require.Equal(t, visible.ID, entries.Entries[0].ID)
+
+	result, err = s.handleListFeeds(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	var feeds ListFeedsOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &feeds))
+	require.Equal(t, 1, feeds.Count)
+	require.Equal(t, []string{"Tech"}, feeds.Folders)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": hidden.ID}
+	_, err = s.handleGetEntry(ctx, req)
+	require.Error(t, err)
+
+	req.Params.Arguments = map[string]interface{}{"feed": "https://private.example.com/feed.xml"}
+	_, err = s.handleGetFeed(ctx, req)
+	require.Error(t, err)
+
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+	stats, err := s.calculateStats(ctx, pc.store)
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Summary.TotalFeeds)
+	require.Equal(t, 1, stats.Summary.UnreadCount)
+
+	// Bulk mark-read must not reach entries outside the scope
+	req.Params.Arguments = map[string]interface{}{"before": time.Now().Add(48 * time.Hour).Format("2006-01-02")}
+	_, err = s.handleBulkMarkRead(ctx, req)
+	require.NoError(t, err)
+	got, err := inner.GetEntry(ctx, hidden.ID)
+	require.NoError(t, err)
+	require.False(t, got.Read)
+	got, err = inner.GetEntry(ctx, visible.ID)
+	require.NoError(t, err)
+	require.True(t, got.Read)
+}
+
+func TestScopedServerChanges(t *testing.T) {
+	s, inner, visible, hidden := scopedTestServer(t)
+	ctx := context.Background()
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+
+	start, err := pc.store.GetChangesSince(ctx, "", 0)
+	require.NoError(t, err)
+	require.Len(t, start.Entries, 1)
+	require.Equal(t, visible.ID, start.Entries[0].ID)
+
+	// Deletions in another folder stay hidden; those of a removed feed
+	// can't be placed and are passed on
+	require.NoError(t, inner.DeleteEntry(ctx, hidden.ID))
+	changes, err := pc.store.GetChangesSince(ctx, start.Cursor, 0)
+	require.NoError(t, err)
+	require.Empty(t, changes.Deleted)
+	require.NoError(t, inner.DeleteFeed(ctx, visible.FeedID))
+	changes, err = pc.store.GetChangesSince(ctx, changes.Cursor, 0)
+	require.NoError(t, err)
+	require.Len(t, changes.Deleted, 1)
+	require.Equal(t, visible.ID, changes.Deleted[0].ID)
+}
+
+func TestScopedServerRejectsOutOfScopePlacement(t *testing.T) {
+	s, _, _, _ := scopedTestServer(t)
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"url": "https://new.example.com/feed.xml", "folder": "Private"}
+	_, err := s.handleAddFeed(ctx, req)
+	require.ErrorContains(t, err, "outside this server's scope")
+
+	req.Params.Arguments = map[string]interface{}{"url": "https://new.example.com/feed.xml", "folder": "Tech"}
+	_, err = s.handleAddFeed(ctx, req)
+	require.NoError(t, err)
+
+	req.Params.Arguments = map[string]interface{}{"url": "https://example.com/feed.xml", "folder": "Private"}
+	_, err = s.handleMoveFeed(ctx, req)
+	require.ErrorContains(t, err, "outside this server's scope")
+
+	req.Params.Arguments = map[string]interface{}{"url": "https://private.example.com/feed.xml"}
+	_, err = s.handleRemoveFeed(ctx, req)
+	require.Error(t, err)
+}
+
+func TestScopeAllows(t *testing.T) {
+	scope := Scope{Folders: []string{"Public"}, Feeds: []string{"https://one.example.com/feed.xml"}}
+
+	require.True(t, Scope{}.Allows("https://any.example.com/feed.xml", ""))
+	require.True(t, scope.Allows("https://two.example.com/feed.xml", "public"))
+	require.True(t, scope.Allows("https://one.example.com/feed.xml", "Private"))
+	require.False(t, scope.Allows("https://two.example.com/feed.xml", "Private"))
+	require.False(t, scope.Allows("https://two.example.com/feed.xml", ""))
+}
+
+func TestReadOnlyServerRegistersOnlyQueryTools(t *testing.T) {
+	s, _, _ := testServer(t, WithReadOnly())
+
+	tools := s.mcpServer.ListTools()
+	for _, name := range []string{"list_feeds", "get_feed", "preview_feed", "list_entries", "count_entries", "aggregate_entries", "list_labels", "get_entry", "get_changes", "get_discussion", "list_profiles", "summarize_with_client", "trending_topics", "recommend_feeds"} {
+		require.Contains(t, tools, name)
+	}
+	for _, name := range []string{"add_feed", "remove_feed", "move_feed", "update_feed", "label_feed", "unlabel_feed", "rename_label", "delete_label", "sync_feeds", "prune_entries", "mark_read", "mark_unread", "keep_unread", "bulk_mark_read", "archive_entry", "refresh_entry"} {
+		require.NotContains(t, tools, name)
+	}
+}
+
+func TestTeamReaderServer(t *testing.T) {
+	s, store, _ := testServer(t, WithTeamUser("alice", team.RoleReader))
+	ctx := context.Background()
+
+	tools := s.mcpServer.ListTools()
+	for _, name := range []string{"list_entries", "mark_read", "mark_unread", "keep_unread", "bulk_mark_read"} {
+		require.Contains(t, tools, name)
+	}
+	for _, name := range []string{"add_feed", "remove_feed", "move_feed", "update_feed", "label_feed", "delete_label", "sync_feeds", "archive_entry", "refresh_entry"} {
+		require.NotContains(t, tools, name)
+	}
+
+	pc, err := s.getProfile("default")
+	require.NoError(t, err)
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, pc.base.CreateFeed(ctx, feed))
+	entry := storage.NewEntry(feed.ID, "one", "One")
+	require.NoError(t, pc.base.CreateEntry(ctx, entry))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID}
+	_, err = s.handleMarkRead(ctx, req)
+	require.NoError(t, err)
+
+	got, err := store.GetEntry(ctx, entry.ID)
+	require.NoError(t, err)
+	require.True(t, got.Read, "alice's view should show her read")
+	owner, err := pc.base.GetEntry(ctx, entry.ID)
+	require.NoError(t, err)
+	require.False(t, owner.Read, "alice's read shouldn't change the owner's state")
+}
+
+func TestServerRegistersAllTools(t *testing.T) {
+	s, _, _ := testServer(t)
+
+	var names []string
+	for name := range s.mcpServer.ListTools() {
+		names = append(names, name)
+	}
+	require.ElementsMatch(t, []string{
+		"list_feeds", "get_feed", "preview_feed", "list_entries", "count_entries", "aggregate_entries",
+		"list_labels", "get_entry", "get_changes", "get_discussion", "list_profiles",
+		"summarize_with_client", "trending_topics", "recommend_feeds",
+		"mark_read", "mark_unread", "keep_unread", "bulk_mark_read",
+		"add_feed", "remove_feed", "move_feed", "update_feed", "label_feed", "unlabel_feed",
+		"rename_label", "delete_label", "sync_feeds", "prune_entries", "archive_entry", "refresh_entry",
+	}, names)
+}
+
+func TestAuditToolRecordsCalls(t *testing.T) {
+	s, _, _ := testServer(t)
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "list_feeds"
+	_, err := s.auditTool(s.handleListFeeds)(ctx, req)
+	require.NoError(t, err)
+
+	req = mcp.CallToolRequest{}
+	req.Params.Name = "get_entry"
+	req.Params.Arguments = map[string]interface{}{"entry_id": "missing1", "profile": "default"}
+	_, err = s.auditTool(s.handleGetEntry)(ctx, req)
+	require.Error(t, err)
+
+	records, err := audit.Tail(audit.Path(s.cfg.GetDataDir()), 10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	require.Equal(t, "list_feeds", records[0].Tool)
+	require.Equal(t, audit.StatusOK, records[0].Status)
+	require.Equal(t, "default", records[0].Profile)
+
+	require.Equal(t, "get_entry", records[1].Tool)
+	require.Equal(t, audit.StatusError, records[1].Status)
+	require.Contains(t, records[1].Error, "entry not found")
+	require.Equal(t, audit.HashArgs(req.GetArguments()), records[1].ArgsHash)
+}
+
+func TestAddAndRemoveFeedRateLimits(t *testing.T) {
+	s, _, _ := testServer(t, WithLimits(config.MCPLimits{AddFeedsPerHour: 2, RemoveFeedsPerHour: 1, BulkMarkReadMax: -1}))
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{}
+	for _, url := range []string{"https://one.example.com/feed.xml", "https://two.example.com/feed.xml"} {
+		req.Params.Arguments = map[string]interface{}{"url": url}
+		_, err := s.handleAddFeed(ctx, req)
+		require.NoError(t, err)
+	}
+	req.Params.Arguments = map[string]interface{}{"url": "https://three.example.com/feed.xml"}
+	_, err := s.handleAddFeed(ctx, req)
+	require.ErrorContains(t, err, "rate limit reached")
+
+	// Failed removals don't use up the quota
+	req.Params.Arguments = map[string]interface{}{"url": "https://missing.example.com/feed.xml"}
+	_, err = s.handleRemoveFeed(ctx, req)
+	require.ErrorContains(t, err, "feed not found")
+
+	req.Params.Arguments = map[string]interface{}{"url": "https://one.example.com/feed.xml"}
+	_, err = s.handleRemoveFeed(ctx, req)
+	require.NoError(t, err)
+	req.Params.Arguments = map[string]interface{}{"url": "https://two.example.com/feed.xml"}
+	_, err = s.handleRemoveFeed(ctx, req)
+	require.ErrorContains(t, err, "rate limit reached")
+
+	// Calls older than the window no longer count
+	s.limiter.now = func() time.Time { return time.Now().Add(limitWindow + time.Minute) }
+	_, err = s.handleRemoveFeed(ctx, req)
+	require.NoError(t, err)
+}
+
+func TestBulkMarkReadLimit(t *testing.T) {
+	s, store, _ := testServer(t, WithLimits(config.MCPLimits{BulkMarkReadMax: 2}))
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	old := time.Now().Add(-72 * time.Hour)
+	for i := 0; i < 3; i++ {
+		entry := storage.NewEntry(feed.ID, fmt.Sprintf("limit-guid-%d", i), "Old Entry")
+		entry.PublishedAt = &old
+		require.NoError(t, store.CreateEntry(ctx, entry))
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"before": "today"}
+	_, err := s.handleBulkMarkRead(ctx, req)
+	require.ErrorContains(t, err, "more than the limit of 2")
+
+	unread := true
+	entries, err := store.ListEntries(ctx, &storage.EntryFilter{UnreadOnly: &unread})
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "a rejected bulk_mark_read must not mark anything")
+
+	// Kept entries aren't marked, so they don't count toward the limit
+	for _, e := range entries[:2] {
+		require.NoError(t, store.SetEntryKeepUnread(ctx, e.ID, true))
+	}
+	_, err = s.handleBulkMarkRead(ctx, req)
+	require.NoError(t, err)
+}
+
+// fakeSampler answers sampling requests with a fixed summary and counts calls.
+type fakeSampler struct {
+	calls int
+	last  mcp.CreateMessageRequest
+}
+
+func (f *fakeSampler) CreateMessage(_ context.Context, req mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	f.calls++
+	f.last = req
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(" A short summary. ")},
+		Model:           "client-model",
+	}, nil
+}
+
+func TestSummarizeWithClientCachesResults(t *testing.T) {
+	s, store, _ := testServer(t)
+	sampler := &fakeSampler{}
+	ctx := s.mcpServer.WithContext(context.Background(), server.NewInProcessSession("test", sampler))
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	entry := storage.NewEntry(feed.ID, "summary-guid", "Long Read")
+	body := "<p>A very long article about feeds.</p>"
+	entry.Content = &body
+	require.NoError(t, store.CreateEntry(ctx, entry))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID, "max_words": 50}
+	result, err := s.handleSummarizeWithClient(ctx, req)
+	require.NoError(t, err)
+	var output SummarizeWithClientOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, "A short summary.", output.Summary)
+	require.Equal(t, "client-model", output.Model)
+	require.False(t, output.Cached)
+	require.Equal(t, 1, sampler.calls)
+	require.Contains(t, sampler.last.SystemPrompt, "50 words")
+
+	// Second call is served from the cache
+	result, err = s.handleSummarizeWithClient(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.True(t, output.Cached)
+	require.Equal(t, 1, sampler.calls)
+
+	// refresh bypasses the cache
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID, "max_words": 50, "refresh": true}
+	_, err = s.handleSummarizeWithClient(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 2, sampler.calls)
+}
+
+func TestSummarizeWithClientRequiresSampling(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	entry := storage.NewEntry(feed.ID, "summary-guid", "Long Read")
+	body := "<p>Article text.</p>"
+	entry.Content = &body
+	require.NoError(t, store.CreateEntry(ctx, entry))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID}
+	_, err := s.handleSummarizeWithClient(ctx, req)
+	require.ErrorContains(t, err, "client sampling failed")
+}
+
+func TestGetEntryChunking(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	entry := storage.NewEntry(feed.ID, "chunk-guid", "Long Read")
+	body := "Opening words here.\n\n## Method\n\nWe measured things.\n\n## Results\n\nThings were measured."
+	entry.Content = &body
+	require.NoError(t, store.CreateEntry(ctx, entry))
+
+	getEntry := func(args map[string]interface{}) GetEntryOutput {
+		t.Helper()
+		args["entry_id"] = entry.ID
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleGetEntry(ctx, req)
+		require.NoError(t, err)
+		var output GetEntryOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output
+	}
+
+	full := getEntry(map[string]interface{}{})
+	require.Equal(t, body, *full.Content)
+	require.False(t, full.Truncated)
+	require.Nil(t, full.NextOffset)
+
+	// Page through the article and reassemble it
+	var pieces []string
+	args := map[string]interface{}{"max_length": 25}
+	for i := 0; i < 10; i++ {
+		chunk := getEntry(args)
+		pieces = append(pieces, *chunk.Content)
+		if chunk.NextOffset == nil {
+			break
+		}
+		require.True(t, chunk.Truncated)
+		args = map[string]interface{}{"max_length": 25, "offset": *chunk.NextOffset}
+	}
+	require.Equal(t, body, strings.Join(pieces, ""))
+
+	section := getEntry(map[string]interface{}{"section": "method"})
+	require.Equal(t, "## Method\n\nWe measured things.", *section.Content)
+	require.True(t, section.Truncated)
+
+	first := getEntry(map[string]interface{}{"paragraphs": 1})
+	require.Equal(t, "Opening words here.", *first.Content)
+	require.True(t, first.Truncated)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID, "section": "Appendix"}
+	_, err := s.handleGetEntry(ctx, req)
+	require.ErrorContains(t, err, "no section matching")
+}
+
+func TestGetEntryFormats(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	entry := storage.NewEntry(feed.ID, "format-guid", "Formatted")
+	body := "<p>Hello <em>world</em></p>"
+	entry.Content = &body
+	require.NoError(t, store.CreateEntry(ctx, entry))
+
+	for format, want := range map[string]string{"markdown": "Hello *world*", "text": "Hello world", "html": body, "raw": body} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID, "format": format}
+		result, err := s.handleGetEntry(ctx, req)
+		require.NoError(t, err)
+		var output GetEntryOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		require.Equal(t, want, *output.Content, format)
+		require.Equal(t, format, output.Format)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID, "format": "pdf"}
+	_, err := s.handleGetEntry(ctx, req)
+	require.ErrorContains(t, err, "unknown format")
+
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID, "format": "text", "section": "Intro"}
+	_, err = s.handleGetEntry(ctx, req)
+	require.ErrorContains(t, err, "section requires markdown")
+}
+
+func TestTrendingTopics(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+
+	// Handlers reload the OPML from disk, so folders must be written out
+	now := time.Now()
+	for i, folder := range []string{"Tech", "News"} {
+		feed := storage.NewFeed(fmt.Sprintf("https://%d.example.com/feed.xml", i))
+		require.NoError(t, store.CreateFeed(ctx, feed))
+		require.NoError(t, pc.opmlDoc.AddFeed(feed.URL, folder, folder))
+		for j := 0; j < 2; j++ {
+			entry := storage.NewEntry(feed.ID, fmt.Sprintf("guid-%d-%d", i, j), fmt.Sprintf("WebAssembly %d from %s", j, folder))
+			entry.PublishedAt = &now
+			require.NoError(t, store.CreateEntry(ctx, entry))
+		}
+	}
+	require.NoError(t, pc.opmlDoc.WriteFile(pc.opmlPath))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"since": "month", "limit": 3}
+	result, err := s.handleTrendingTopics(ctx, req)
+	require.NoError(t, err)
+	var output TrendingTopicsOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, 4, output.Entries)
+	require.NotEmpty(t, output.Topics)
+	require.Equal(t, "webassembly", output.Topics[0].Term)
+	require.Len(t, output.Folders, 2)
+	require.LessOrEqual(t, len(output.Topics), 3)
+
+	req.Params.Arguments = map[string]interface{}{"since": "month", "folder": "News"}
+	result, err = s.handleTrendingTopics(ctx, req)
+	require.NoError(t, err)
+	output = TrendingTopicsOutput{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, 2, output.Entries)
+	require.Empty(t, output.Folders)
+
+	req.Params.Arguments = map[string]interface{}{"since": "today", "until": "month"}
+	_, err = s.handleTrendingTopics(ctx, req)
+	require.ErrorContains(t, err, "until must be after since")
+
+	prompt, err := s.handleDailyDigest(ctx, mcp.GetPromptRequest{})
+	require.NoError(t, err)
+	require.Contains(t, prompt.Messages[0].Content.(mcp.TextContent).Text, "Trending Topics This Week")
+}
+
+func TestListEntriesByPriority(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour)
+	for i, priority := range []string{models.PriorityLow, models.PriorityNormal, models.PriorityHigh} {
+		feed := storage.NewFeed(fmt.Sprintf("https://%d.example.com/feed.xml", i))
+		feed.Priority = priority
+		require.NoError(t, store.CreateFeed(ctx, feed))
+		entry := storage.NewEntry(feed.ID, "guid", models.PriorityName(priority))
+		// Low-priority entries are newest, so only the priority puts them last
+		published := base.Add(-time.Duration(i) * time.Minute)
+		entry.PublishedAt = &published
+		require.NoError(t, store.CreateEntry(ctx, entry))
+	}
+
+	titles := func(args map[string]interface{}) []string {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleListEntries(ctx, req)
+		require.NoError(t, err)
+		var output ListEntriesOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		var out []string
+		for _, e := range output.Entries {
+			out = append(out, *e.Title)
+		}
+		return out
+	}
+	require.Equal(t, []string{"high", "normal", "low"}, titles(map[string]interface{}{"unread_only": true}))
+	require.Equal(t, []string{"low", "normal", "high"}, titles(nil))
+}
+
+func TestLabelTools(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+
+	goBlog := storage.NewFeed("https://go.example.com/feed.xml")
+	weekly := storage.NewFeed("https://weekly.example.com/feed.xml")
+	for i, feed := range []*models.Feed{goBlog, weekly} {
+		require.NoError(t, store.CreateFeed(ctx, feed))
+		require.NoError(t, pc.opmlDoc.AddFeed(feed.URL, feed.URL, "Tech"))
+		require.NoError(t, store.CreateEntry(ctx, storage.NewEntry(feed.ID, fmt.Sprintf("guid-%d", i), "Post")))
+	}
+	require.NoError(t, pc.opmlDoc.WriteFile(pc.opmlPath))
+
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}, out any) error {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := handler(ctx, req)
+		if err != nil {
+			return err
+		}
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), out))
+		return nil
+	}
+
+	var labelled LabelFeedOutput
+	require.NoError(t, call(s.handleLabelFeed, map[string]interface{}{"url": goBlog.URL, "labels": []interface{}{"Go", "Newsletters"}}, &labelled))
+	require.Equal(t, []string{"Go", "Newsletters"}, labelled.Labels)
+	require.NoError(t, call(s.handleLabelFeed, map[string]interface{}{"url": weekly.URL, "labels": []interface{}{"newsletters"}}, &labelled))
+	require.ErrorContains(t, call(s.handleLabelFeed, map[string]interface{}{"url": weekly.URL, "labels": []interface{}{"a,b"}}, &labelled), "comma or slash")
+	require.ErrorContains(t, call(s.handleLabelFeed, map[string]interface{}{"url": "https://missing.example.com/feed.xml", "labels": []interface{}{"Go"}}, &labelled), "feed not found")
+
+	var labels ListLabelsOutput
+	require.NoError(t, call(s.handleListLabels, nil, &labels))
+	require.Equal(t, 2, labels.Count)
+	require.Equal(t, "Go", labels.Labels[0].Label)
+	require.Equal(t, 1, labels.Labels[0].FeedCount)
+	require.Equal(t, "Newsletters", labels.Labels[1].Label)
+	require.Equal(t, 2, labels.Labels[1].FeedCount)
+
+	// Labels filter entries and survive the OPML reload each call does
+	var listed ListEntriesOutput
+	require.NoError(t, call(s.handleListEntries, map[string]interface{}{"label": "go"}, &listed))
+	require.Equal(t, 1, listed.Count)
+	require.Equal(t, goBlog.ID, listed.Entries[0].FeedID)
+	require.Equal(t, "go", listed.Filters["label"])
+	var counted CountEntriesOutput
+	require.NoError(t, call(s.handleCountEntries, map[string]interface{}{"label": "Newsletters"}, &counted))
+	require.Equal(t, 2, counted.Count)
+	require.ErrorContains(t, call(s.handleCountEntries, map[string]interface{}{"label": "Nope"}, &counted), "no feeds are labelled")
+
+	var feed GetFeedOutput
+	require.NoError(t, call(s.handleGetFeed, map[string]interface{}{"feed": goBlog.URL}, &feed))
+	require.Equal(t, []string{"Go", "Newsletters"}, feed.Labels)
+
+	var changed ChangeLabelOutput
+	require.NoError(t, call(s.handleRenameLabel, map[string]interface{}{"label": "newsletters", "new_label": "Weekly"}, &changed))
+	require.Equal(t, 2, changed.Feeds)
+	require.NoError(t, call(s.handleDeleteLabel, map[string]interface{}{"label": "go"}, &changed))
+	require.Equal(t, 1, changed.Feeds)
+	require.NoError(t, call(s.handleUnlabelFeed, map[string]interface{}{"url": weekly.URL, "labels": []interface{}{"weekly"}}, &labelled))
+	require.Empty(t, labelled.Labels)
+	require.ErrorContains(t, call(s.handleDeleteLabel, map[string]interface{}{"label": "Go"}, &changed), "no feeds are labelled")
+
+	doc, err := opml.ParseFile(pc.opmlPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Weekly"}, doc.Labels())
+}
+
+func TestCountAndAggregateEntries(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+	pc, err := s.getProfile("")
+	require.NoError(t, err)
+
+	// Tech has three entries, one read; News has one from yesterday and
+	// an old one outside the last few days
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	old := now.AddDate(0, -2, 0)
+	feeds := map[string]*models.Feed{}
+	for i, folder := range []string{"Tech", "News"} {
+		feed := storage.NewFeed(fmt.Sprintf("https://%d.example.com/feed.xml", i))
+		require.NoError(t, store.CreateFeed(ctx, feed))
+		require.NoError(t, pc.opmlDoc.AddFeed(feed.URL, folder, folder))
+		feeds[folder] = feed
+	}
+	require.NoError(t, pc.opmlDoc.WriteFile(pc.opmlPath))
+	add := func(feed *models.Feed, guid string, published time.Time, read bool) {
+		entry := storage.NewEntry(feed.ID, guid, guid)
+		entry.PublishedAt = &published
+		require.NoError(t, store.CreateEntry(ctx, entry))
+		if read {
+			require.NoError(t, store.MarkEntryRead(ctx, entry.ID))
+		}
+	}
+	add(feeds["Tech"], "t1", now, false)
+	add(feeds["Tech"], "t2", now, true)
+	add(feeds["Tech"], "t3", yesterday, false)
+	add(feeds["News"], "n1", yesterday, false)
+	add(feeds["News"], "n2", old, false)
+
+	count := func(args map[string]interface{}) int {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleCountEntries(ctx, req)
+		require.NoError(t, err)
+		var output CountEntriesOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output.Count
+	}
+	require.Equal(t, 5, count(nil))
+	require.Equal(t, 3, count(map[string]interface{}{"unread_only": true, "since": "3 days ago"}))
+	require.Equal(t, 2, count(map[string]interface{}{"feed_id": feeds["News"].ID}))
+
+	aggregate := func(args map[string]interface{}) AggregateEntriesOutput {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := s.handleAggregateEntries(ctx, req)
+		require.NoError(t, err)
+		var output AggregateEntriesOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		return output
+	}
+	byFolder := aggregate(map[string]interface{}{"group_by": "folder", "since": "3 days ago"})
+	require.Equal(t, 4, byFolder.Total)
+	require.Equal(t, []AggregateGroup{
+		{Key: "Tech", Count: 3, Unread: 2},
+		{Key: "News", Count: 1, Unread: 1},
+	}, byFolder.Groups)
+
+	byFeed := aggregate(map[string]interface{}{"group_by": "feed", "unread_only": true})
+	require.ElementsMatch(t, []AggregateGroup{
+		{Key: feeds["News"].ID, Title: feeds["News"].GetDisplayName(), Count: 2, Unread: 2},
+		{Key: feeds["Tech"].ID, Title: feeds["Tech"].GetDisplayName(), Count: 2, Unread: 2},
+	}, byFeed.Groups)
+
+	byDay := aggregate(map[string]interface{}{"group_by": "day", "since": "3 days ago", "tz": "UTC"})
+	require.Equal(t, []AggregateGroup{
+		{Key: yesterday.UTC().Format(time.DateOnly), Count: 2, Unread: 2},
+		{Key: now.UTC().Format(time.DateOnly), Count: 2, Unread: 1},
+	}, byDay.Groups)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"group_by": "author"}
+	_, err = s.handleAggregateEntries(ctx, req)
+	require.ErrorContains(t, err, "group_by must be one of")
+}
+
+func TestListEntriesByAuthor(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	for i, author := range []string{"Jane Doe", "jane@example.com (Jane Doe)", "John Smith", "John Smith and Jane Doe"} {
+		entry := storage.NewEntry(feed.ID, fmt.Sprintf("author-%d", i), fmt.Sprintf("Post %d", i))
+		entry.Author = &author
+		require.NoError(t, store.CreateEntry(ctx, entry))
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"author": "jnae doe"}
+	result, err := s.handleListEntries(ctx, req)
+	require.NoError(t, err)
+	var output ListEntriesOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, 3, output.Count)
+	require.Equal(t, "jnae doe", output.Filters["author"])
+
+	req.Params.Arguments = map[string]interface{}{"author": "jane doe", "limit": 1, "offset": 2}
+	result, err = s.handleListEntries(ctx, req)
+	require.NoError(t, err)
+	output = ListEntriesOutput{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, 1, output.Count)
+}
+
+func TestRecommendFeeds(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	var probed []string
+	s.discoverFeed = func(siteURL string) (*discover.DiscoveredFeed, error) {
+		probed = append(probed, siteURL)
+		if siteURL == "https://nofeed.dev/" {
+			return nil, discover.ErrNoFeedFound
+		}
+		return &discover.DiscoveredFeed{URL: siteURL + "feed.xml", Title: "Found"}, nil
+	}
+
+	feed := storage.NewFeed("https://mine.example.com/feed.xml")
+	title := "Mine"
+	feed.Title = &title
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	bodies := []string{
+		`<p>WebAssembly <a href="https://rising.dev/a">a</a> <a href="https://nofeed.dev/x">x</a></p>`,
+		`<p>WebAssembly <a href="https://rising.dev/b">b</a> <a href="https://nofeed.dev/y">y</a></p>`,
+		`<p>Unread <a href="https://unread-only.dev/z">z</a></p>`,
+		`<p>Unread <a href="https://unread-only.dev/z">z</a></p>`,
+	}
+	for i, body := range bodies {
+		entry := storage.NewEntry(feed.ID, fmt.Sprintf("rec-%d", i), fmt.Sprintf("Post %d", i))
+		entry.Content = &body
+		link := fmt.Sprintf("https://mine.example.com/%d", i)
+		entry.Link = &link
+		require.NoError(t, store.CreateEntry(ctx, entry))
+		if i < 2 {
+			require.NoError(t, store.MarkEntryRead(ctx, entry.ID))
+		}
+	}
+
+	req := mcp.CallToolRequest{}
+	result, err := s.handleRecommendFeeds(ctx, req)
+	require.NoError(t, err)
+	var output RecommendFeedsOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, 2, output.ReadEntries)
+	require.Len(t, output.TopFeeds, 1)
+	require.Equal(t, "Mine", output.TopFeeds[0].Title)
+	require.Equal(t, 2, output.TopFeeds[0].Read)
+	require.Len(t, output.Candidates, 1)
+	require.Equal(t, "rising.dev", output.Candidates[0].Domain.Domain)
+	require.Equal(t, "https://rising.dev/feed.xml", output.Candidates[0].FeedURL)
+	require.NotContains(t, probed, "https://unread-only.dev/")
+
+	// Without discovery, every linked domain is a candidate and nothing is probed
+	probed = nil
+	req.Params.Arguments = map[string]interface{}{"discover": false}
+	result, err = s.handleRecommendFeeds(ctx, req)
+	require.NoError(t, err)
+	output = RecommendFeedsOutput{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Len(t, output.Candidates, 2)
+	require.Empty(t, probed)
+}
+
+func TestArchiveEntry(t *testing.T) {
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	var archived []string
+	s.snapshot = func(ctx context.Context, link string) (string, error) {
+		archived = append(archived, link)
+		return fmt.Sprintf("https://web.archive.org/web/%d/%s", len(archived), link), nil
+	}
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	entry := storage.NewEntry(feed.ID, "archive-1", "Worth Keeping")
+	link := "https://example.com/post"
+	entry.Link = &link
+	require.NoError(t, store.CreateEntry(ctx, entry))
+	bare := storage.NewEntry(feed.ID, "archive-2", "No Link")
+	require.NoError(t, store.CreateEntry(ctx, bare))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID[:8]}
+	result, err := s.handleArchiveEntry(ctx, req)
+	require.NoError(t, err)
+	var output ArchiveEntryOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, "https://web.archive.org/web/1/https://example.com/post", output.ArchiveURL)
+
+	stored, err := store.GetEntry(ctx, entry.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.ArchiveURL)
+	require.Equal(t, output.ArchiveURL, *stored.ArchiveURL)
+
+	// Already archived: no new request unless refresh is set
+	_, err = s.handleArchiveEntry(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID, "refresh": true}
+	_, err = s.handleArchiveEntry(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	stored, err = store.GetEntry(ctx, entry.ID)
+	require.NoError(t, err)
+	require.Equal(t, "https://web.archive.org/web/2/https://example.com/post", *stored.ArchiveURL)
+
+	req.Params.Arguments = map[string]interface{}{"entry_id": bare.ID}
+	_, err = s.handleArchiveEntry(ctx, req)
+	require.ErrorContains(t, err, "no link")
+}
+
+func TestHandleRefreshEntry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"unchanged"`)
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Corrections</title>
+<item><title>Post</title><guid>fix-1</guid><description>Now with the right number: 42.</description></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	feed := storage.NewFeed(server.URL)
+	etag := `"unchanged"`
+	feed.ETag = &etag
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	entry := storage.NewEntry(feed.ID, "fix-1", "Post")
+	old := "Now with the right number: 41."
+	entry.Content = &old
+	require.NoError(t, store.CreateEntry(ctx, entry))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID[:8]}
+	result, err := s.handleRefreshEntry(ctx, req)
+	require.NoError(t, err)
+	var output RefreshEntryOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, "feed", output.Source)
+	require.Equal(t, []string{"content"}, output.Changed)
+	require.Equal(t, 1, output.WordsAdded)
+	require.Equal(t, 1, output.WordsRemoved)
+	require.Contains(t, output.Diff, "+ Now with the right number: 42.")
+
+	stored, err := store.GetEntry(ctx, entry.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Now with the right number: 42.", *stored.Content)
+
+	result, err = s.handleRefreshEntry(ctx, req)
+	require.NoError(t, err)
+	output = RefreshEntryOutput{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Empty(t, output.Changed)
+	require.Equal(t, "Entry is up to date", output.Message)
+}
+
+func TestHandleGetDiscussion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Comments</title>
+<item><title>Re</title><author>a@example.com (Ann)</author><description>&lt;p&gt;Nice &lt;b&gt;post&lt;/b&gt;&lt;/p&gt;</description></item>
+<item><title>Re</title><description>Second</description></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	feed.LocalNetwork = true
+	require.NoError(t, store.CreateFeed(ctx, feed))
+
+	entry := storage.NewEntry(feed.ID, "blog-1", "With Comments")
+	thread := "https://example.com/post#comments"
+	commentsFeed := server.URL + "/comments"
+	entry.DiscussionURL, entry.CommentsFeedURL = &thread, &commentsFeed
+	require.NoError(t, store.CreateEntry(ctx, entry))
+	bare := storage.NewEntry(feed.ID, "blog-2", "No Comments")
+	require.NoError(t, store.CreateEntry(ctx, bare))
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"entry_id": entry.ID[:8], "limit": 1}
+	result, err := s.handleGetDiscussion(ctx, req)
+	require.NoError(t, err)
+	var output GetDiscussionOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, thread, output.DiscussionURL)
+	require.Equal(t, 1, output.Count)
+	require.Equal(t, "Ann", output.Comments[0].Author)
+	require.Equal(t, "Nice **post**", output.Comments[0].Content)
+
+	req.Params.Arguments = map[string]interface{}{"entry_id": bare.ID}
+	_, err = s.handleGetDiscussion(ctx, req)
+	require.ErrorContains(t, err, "no discussion link")
+}
+
+func TestHandlePreviewFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page.html" {
+			w.Write([]byte(`<html><body><p>Not a feed</p></body></html>`))
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Candidate</title>
+<item><title>Older</title><guid>1</guid><pubDate>Mon, 02 Mar 2026 10:00:00 GMT</pubDate><description>Old news</description></item>
+<item><title>Newer</title><guid>2</guid><pubDate>Tue, 03 Mar 2026 10:00:00 GMT</pubDate><description>&lt;p&gt;First &lt;b&gt;point&lt;/b&gt;&lt;/p&gt;&lt;p&gt;Second point&lt;/p&gt;</description></item>
+<item><title>Undated</title><guid>3</guid></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	s, store, _ := testServer(t)
+	ctx := context.Background()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"url": server.URL + "/feed.xml", "limit": 2, "local_network": true}
+	result, err := s.handlePreviewFeed(ctx, req)
+	require.NoError(t, err)
+	var output PreviewFeedOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+	require.Equal(t, "Candidate", output.Title)
+	require.Equal(t, 3, output.EntryCount)
+	require.Len(t, output.Entries, 2)
+	require.Equal(t, "Newer", output.Entries[0].Title)
+	require.Equal(t, "First **point**", output.Entries[0].Excerpt)
+	require.Equal(t, "Older", output.Entries[1].Title)
+	require.Empty(t, output.SubscribedAs)
+
+	// Previewing stores nothing
+	feeds, err := store.ListFeeds(ctx)
+	require.NoError(t, err)
+	require.Empty(t, feeds)
+	entries, err := store.ListEntries(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	feed := storage.NewFeed(server.URL + "/feed.xml")
+	require.NoError(t, store.CreateFeed(ctx, feed))
+	result, err = s.handlePreviewFeed(ctx, req)
+	require.NoError(t, err)
+	var subscribed PreviewFeedOutput
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &subscribed))
+	require.Equal(t, feed.ID, subscribed.SubscribedAs)
+
+	req.Params.Arguments = map[string]interface{}{"url": server.URL + "/page.html"}
+	_, err = s.handlePreviewFeed(ctx, req)
+	require.ErrorContains(t, err, "web page rather than a feed")
+}
+
+func TestFeedActivity(t *testing.T) {
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	now := time.Date(2025, 3, 15, 18, 0, 0, 0, time.UTC)
+	at := func(daysAgo int, hour int) *time.Time {
+		ts := time.Date(2025, 3, 15-daysAgo, hour, 0, 0, 0, time.UTC)
+		return &ts
+	}
+
+	read := storage.NewEntry(feed.ID, "read", "Read")
+	read.PublishedAt = at(0, 8)
+	read.Read = true
+	read.ReadAt = at(0, 14)
+	sameDay := storage.NewEntry(feed.ID, "same-day", "Same Day")
+	sameDay.PublishedAt = at(0, 9)
+	older := storage.NewEntry(feed.ID, "older", "Older")
+	older.PublishedAt = at(10, 12)
+	older.Read = true
+	older.ReadAt = at(8, 12)
+	undated := storage.NewEntry(feed.ID, "undated", "Undated")
+	undated.CreatedAt = *at(89, 1)
+	tooOld := storage.NewEntry(feed.ID, "too-old", "Too Old")
+	tooOld.PublishedAt = at(90, 23)
+
+	activity := feedActivity(feed, []*models.Entry{read, sameDay, older, undated, tooOld}, now, 90)
+
+	require.Len(t, activity.Sparkline, 90)
+	require.Equal(t, "2024-12-16", activity.Daily[0].Date)
+	require.Equal(t, "2025-03-15", activity.Daily[89].Date)
+	require.Equal(t, 2, activity.Sparkline[89])
+	require.Equal(t, 1, activity.Sparkline[79])
+	require.Equal(t, 1, activity.Sparkline[0])
+	require.Equal(t, 4, activity.EntryCount)
+	require.Equal(t, 2, activity.ReadCount)
+	require.InDelta(t, 0.5, activity.ReadRate, 0.001)
+	// (6h + 48h) / 2
+	require.NotNil(t, activity.AvgTimeToReadHours)
+	require.InDelta(t, 27.0, *activity.AvgTimeToReadHours, 0.001)
+	require.Equal(t, "27h0m0s", activity.AvgTimeToRead)
+
+	empty := feedActivity(feed, nil, now, 90)
+	require.Zero(t, empty.ReadRate)
+	require.Nil(t, empty.AvgTimeToReadHours)
+}
+
+func TestResourceFeedStatsViaHandleMessage(t *testing.T) {
+	s, store, _ := testServer(t)
+
+	feed := storage.NewFeed("https://example.com/feed.xml")
+	require.NoError(t, store.CreateFeed(context.Background(), feed))
+	entry := storage.NewEntry(feed.ID, "activity-guid", "Activity Entry")
+	now := time.Now()
+	entry.PublishedAt = &now
+	require.NoError(t, store.CreateEntry(context.Background(), entry))
+
+	read := func(uri string) string {
+		reqJSON := []byte(`{"jsonrpc": "2.0", "id": 5, "method": "resources/read", "params": {"uri": "` + uri + `"}}`)
+		resp := s.mcpServer.HandleMessage(context.Background(), reqJSON)
+		require.NotNil(t, resp)
+		respJSON, err := json.Marshal(resp)
+		require.NoError(t, err)
+		return string(respJSON)
+	}
+
+	respStr := read("digest://feed/" + feed.ID + "/stats")
+	for _, want := range []string{"sparkline", "read_rate", `\"entry_count\": 1`} {
+		if !strings.Contains(respStr, want) {
+			t.Errorf("expected response to contain %s, got %s", want, respStr)
+		}
+	}
+
+	// An ID prefix resolves the same feed
+	respStr = read("digest://feed/" + feed.ID[:8] + "/stats")
+	if !strings.Contains(respStr, feed.ID) {
+		t.Errorf("expected prefix lookup to find the feed, got %s", respStr)
+	}
+
+	respStr = read("digest://feed/nonexistent/stats")
+	if !strings.Contains(respStr, "error") {
+		t.Errorf("expected an error for an unknown feed, got %s", respStr)
+	}
+}
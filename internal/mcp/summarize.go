@@ -0,0 +1,189 @@
+// ABOUTME: summarize_with_client tool that asks the connected client's model for a summary
+// ABOUTME: Uses MCP sampling so no API keys are needed, and caches results per entry
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/summary"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultSummaryWords is the summary length when max_words isn't given.
+const defaultSummaryWords = 150
+
+// maxSummaryInputRunes caps how much article text is sent to the client's
+// model, so a huge article can't blow past its context window.
+const maxSummaryInputRunes = 20000
+
+type SummarizeWithClientInput struct {
+	EntryID  string `json:"entry_id"`
+	MaxWords *int   `json:"max_words,omitempty"`
+	Refresh  *bool  `json:"refresh,omitempty"`
+}
+
+type SummarizeWithClientOutput struct {
+	EntryID   string    `json:"entry_id"`
+	Title     *string   `json:"title,omitempty"`
+	Summary   string    `json:"summary"`
+	Model     string    `json:"model,omitempty"`
+	Cached    bool      `json:"cached"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (s *Server) registerSummarizeWithClientTool() {
+	tool := mcp.Tool{
+		Name:        "summarize_with_client",
+		Description: "Summarize an entry using the connected client's own model via MCP sampling, so digest needs no API keys. The client may ask the user to approve the request. Summaries are cached per entry and reused until the entry content changes. Requires a client that supports sampling.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"entry_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Entry ID or ID prefix (min 6 chars). Example: 'a1b2c3'",
+				},
+				"max_words": map[string]interface{}{
+					"type":        "integer",
+					"description": "Approximate summary length in words. Default: 150.",
+				},
+				"refresh": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Ignore any cached summary and ask the model again. Default: false.",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"entry_id"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleSummarizeWithClient)
+}
+
+func (s *Server) handleSummarizeWithClient(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input SummarizeWithClientInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	maxWords := defaultSummaryWords
+	if input.MaxWords != nil {
+		if *input.MaxWords <= 0 {
+			return nil, fmt.Errorf("max_words must be positive")
+		}
+		maxWords = *input.MaxWords
+	}
+
+	entry, err := pc.store.GetEntry(ctx, input.EntryID)
+	if err != nil {
+		entry, err = pc.store.GetEntryByPrefix(ctx, input.EntryID)
+		if err != nil {
+			return nil, fmt.Errorf("entry not found: %s", input.EntryID)
+		}
+	}
+	if entry.Content == nil || *entry.Content == "" {
+		return nil, fmt.Errorf("entry %s has no content to summarize", entry.ID)
+	}
+
+	markdown := content.ToMarkdownWith(*entry.Content, pc.cfg.GetContentOptions())
+	hash := summary.HashContent(markdown)
+
+	if input.Refresh == nil || !*input.Refresh {
+		if cached, ok := summary.Load(pc.summaryDir, entry.ID, hash, maxWords); ok {
+			return summarizeResult(SummarizeWithClientOutput{
+				EntryID:   entry.ID,
+				Title:     entry.Title,
+				Summary:   cached.Text,
+				Model:     cached.Model,
+				Cached:    true,
+				CreatedAt: cached.CreatedAt,
+			})
+		}
+	}
+
+	text, model, err := s.sampleSummary(ctx, entry.Title, markdown, maxWords)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &summary.Summary{
+		EntryID:     entry.ID,
+		ContentHash: hash,
+		MaxWords:    maxWords,
+		Model:       model,
+		Text:        text,
+		CreatedAt:   time.Now(),
+	}
+	// A failed cache write only costs a repeat request later
+	_ = summary.Save(pc.summaryDir, result)
+
+	return summarizeResult(SummarizeWithClientOutput{
+		EntryID:   entry.ID,
+		Title:     entry.Title,
+		Summary:   result.Text,
+		Model:     result.Model,
+		CreatedAt: result.CreatedAt,
+	})
+}
+
+// sampleSummary asks the client's model to summarize article text and returns
+// the summary and the model name the client reported.
+func (s *Server) sampleSummary(ctx context.Context, title *string, markdown string, maxWords int) (string, string, error) {
+	runes := []rune(markdown)
+	if len(runes) > maxSummaryInputRunes {
+		markdown = string(runes[:maxSummaryInputRunes]) + "\n\n[article truncated]"
+	}
+	prompt := markdown
+	if title != nil && *title != "" {
+		prompt = "# " + *title + "\n\n" + markdown
+	}
+
+	request := mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{{
+				Role:    mcp.RoleUser,
+				Content: mcp.NewTextContent(prompt),
+			}},
+			SystemPrompt: fmt.Sprintf("Summarize the following article in at most %d words. Reply with the summary only, in plain prose.", maxWords),
+			// Roughly two tokens per word leaves room for the model to finish its last sentence
+			MaxTokens: maxWords*2 + 64,
+		},
+	}
+
+	result, err := s.mcpServer.RequestSampling(ctx, request)
+	if err != nil {
+		return "", "", fmt.Errorf("client sampling failed (does your MCP client support sampling?): %w", err)
+	}
+
+	var text string
+	switch c := result.Content.(type) {
+	case mcp.TextContent:
+		text = c.Text
+	case *mcp.TextContent:
+		text = c.Text
+	default:
+		return "", "", fmt.Errorf("client returned non-text content for summary")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", "", fmt.Errorf("client returned an empty summary")
+	}
+	return text, result.Model, nil
+}
+
+func summarizeResult(output SummarizeWithClientOutput) (*mcp.CallToolResult, error) {
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
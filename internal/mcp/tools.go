@@ -6,17 +6,33 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/harper/digest/internal/alert"
+	"github.com/harper/digest/internal/authors"
 	"github.com/harper/digest/internal/config"
 	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/deliver"
+	"github.com/harper/digest/internal/digests"
+	"github.com/harper/digest/internal/favicon"
+	"github.com/harper/digest/internal/feedurl"
 	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/hooks"
 	"github.com/harper/digest/internal/models"
-	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/render"
+	"github.com/harper/digest/internal/runlock"
+	"github.com/harper/digest/internal/score"
 	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
+	"github.com/harper/digest/internal/syncer"
+	"github.com/harper/digest/internal/team"
+	"github.com/harper/digest/internal/thumbnail"
 	"github.com/harper/digest/internal/timeutil"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -30,13 +46,94 @@ type FeedOutput struct {
 	URL           string     `json:"url"`
 	Title         *string    `json:"title,omitempty"`
 	Folder        string     `json:"folder,omitempty"`
+	Labels        []string   `json:"labels,omitempty"`
 	LocalNetwork  bool       `json:"local_network,omitempty"`
+	Browser       bool       `json:"browser,omitempty"`
+	Monitor       bool       `json:"monitor,omitempty"`
 	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
 	LastError     *string    `json:"last_error,omitempty"`
 	ErrorCount    int        `json:"error_count"`
+	Paused        bool       `json:"paused,omitempty"`
+	ArchivedAt    *time.Time `json:"archived_at,omitempty"`
+	Identity      string     `json:"identity,omitempty"`
+	Priority      string     `json:"priority,omitempty"`
+	SyncInterval  string     `json:"sync_interval,omitempty"`
+	MaxEntries    int        `json:"max_entries,omitempty"`
+	BackfillDays  int        `json:"backfill_days,omitempty"`
+	BackfillLimit int        `json:"backfill_limit,omitempty"`
+	HasAuth       bool       `json:"has_auth,omitempty"`
+	IconPath      string     `json:"icon_path,omitempty"`
 	CreatedAt     time.Time  `json:"created_at"`
 }
 
+// newFeedOutput builds the FeedOutput for a stored feed. Credentials are never echoed back.
+func newFeedOutput(feed *models.Feed, folder, iconDir string) FeedOutput {
+	output := FeedOutput{
+		ID:            feed.ID,
+		URL:           feed.URL,
+		Title:         feed.Title,
+		Folder:        folder,
+		LocalNetwork:  feed.LocalNetwork,
+		Browser:       feed.Browser,
+		Monitor:       feed.Monitor,
+		LastFetchedAt: feed.LastFetchedAt,
+		LastError:     feed.LastError,
+		ErrorCount:    feed.ErrorCount,
+		Paused:        feed.Paused,
+		ArchivedAt:    feed.ArchivedAt,
+		Identity:      feed.Identity,
+		Priority:      feed.Priority,
+		MaxEntries:    feed.MaxEntries,
+		BackfillDays:  feed.BackfillDays,
+		BackfillLimit: feed.BackfillLimit,
+		HasAuth:       feed.HasAuth(),
+		IconPath:      favicon.Path(iconDir, feed.ID),
+		CreatedAt:     feed.CreatedAt,
+	}
+	if feed.SyncInterval > 0 {
+		output.SyncInterval = feed.SyncInterval.String()
+	}
+	return output
+}
+
+type GetFeedInput struct {
+	Feed       string `json:"feed"`
+	EntryLimit *int   `json:"entry_limit,omitempty"`
+}
+
+type GetFeedOutput struct {
+	FeedOutput
+	ETag          *string       `json:"etag,omitempty"`
+	LastModified  *string       `json:"last_modified,omitempty"`
+	InOPML        bool          `json:"in_opml"`
+	EntryCount    int           `json:"entry_count"`
+	UnreadCount   int           `json:"unread_count"`
+	RecentEntries []EntryOutput `json:"recent_entries"`
+}
+
+type UpdateFeedInput struct {
+	Feed         string  `json:"feed"`
+	Title        *string `json:"title,omitempty"`
+	Folder       *string `json:"folder,omitempty"`
+	Paused       *bool   `json:"paused,omitempty"`
+	Archived     *bool   `json:"archived,omitempty"`
+	Identity     *string `json:"identity,omitempty"`
+	Priority     *string `json:"priority,omitempty"`
+	SyncInterval *string `json:"sync_interval,omitempty"`
+	MaxEntries   *int    `json:"max_entries,omitempty"`
+	LocalNetwork *bool   `json:"local_network,omitempty"`
+	Browser      *bool   `json:"browser,omitempty"`
+	AuthUsername *string `json:"auth_username,omitempty"`
+	AuthPassword *string `json:"auth_password,omitempty"`
+}
+
+type UpdateFeedOutput struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message"`
+	Changed []string   `json:"changed"`
+	Feed    FeedOutput `json:"feed"`
+}
+
 type ListFeedsOutput struct {
 	Feeds   []FeedOutput `json:"feeds"`
 	Count   int          `json:"count"`
@@ -44,10 +141,15 @@ type ListFeedsOutput struct {
 }
 
 type AddFeedInput struct {
-	URL          string  `json:"url"`
-	Title        *string `json:"title,omitempty"`
-	Folder       *string `json:"folder,omitempty"`
-	LocalNetwork *bool   `json:"local_network,omitempty"`
+	URL           string  `json:"url"`
+	Title         *string `json:"title,omitempty"`
+	Folder        *string `json:"folder,omitempty"`
+	LocalNetwork  *bool   `json:"local_network,omitempty"`
+	Browser       *bool   `json:"browser,omitempty"`
+	Monitor       *bool   `json:"monitor,omitempty"`
+	Priority      *string `json:"priority,omitempty"`
+	BackfillDays  *int    `json:"backfill_days,omitempty"`
+	BackfillLimit *int    `json:"backfill_limit,omitempty"`
 }
 
 type RemoveFeedInput struct {
@@ -83,24 +185,66 @@ type SyncResult struct {
 	FeedTitle  string  `json:"feed_title"`
 	NewEntries int     `json:"new_entries"`
 	WasCached  bool    `json:"was_cached"`
+	Skipped    string  `json:"skipped,omitempty"`
 	Error      *string `json:"error,omitempty"`
+	// Identity is set when the feed's GUIDs proved unstable and it switched
+	// to matching entries by "link" or "hash".
+	Identity string `json:"identity,omitempty"`
+	// Truncated is set when the feed was too large to read whole and only
+	// its first entries were synced.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 type SyncFeedsOutput struct {
-	Results     []SyncResult `json:"results"`
-	TotalFeeds  int          `json:"total_feeds"`
-	TotalNew    int          `json:"total_new"`
-	TotalCached int          `json:"total_cached"`
-	TotalErrors int          `json:"total_errors"`
+	Results      []SyncResult   `json:"results"`
+	TotalFeeds   int            `json:"total_feeds"`
+	TotalNew     int            `json:"total_new"`
+	TotalCached  int            `json:"total_cached"`
+	TotalSkipped int            `json:"total_skipped"`
+	TotalErrors  int            `json:"total_errors"`
+	Archived     []ArchivedFeed `json:"archived,omitempty"`
+	// ScoresRefreshed counts Hacker News and Lobsters posts whose points
+	// and comment counts were refreshed after the sync
+	ScoresRefreshed int `json:"scores_refreshed,omitempty"`
+	// Budgets lists folders whose oldest unread entries were marked read to
+	// keep them within their configured unread budgets
+	Budgets []BudgetTrim `json:"budgets,omitempty"`
+	// Cache reports how many DNS lookups and downloads this sync saved by
+	// reusing earlier answers
+	Cache *CacheStats `json:"cache,omitempty"`
+}
+
+// CacheStats is the fetch cache's hit counts over one sync.
+type CacheStats struct {
+	DNSHits      int `json:"dns_hits"`
+	DNSLookups   int `json:"dns_lookups"`
+	BodyHits     int `json:"body_hits"`
+	BodyRequests int `json:"body_requests"`
+}
+
+// BudgetTrim is a folder trimmed to its unread budget at the end of a sync.
+type BudgetTrim struct {
+	Folder       string   `json:"folder"`
+	Budget       int      `json:"budget"`
+	UnreadBefore int      `json:"unread_before"`
+	MarkedRead   []string `json:"marked_read"`
+}
+
+// ArchivedFeed is a feed archived as inactive at the end of a sync.
+type ArchivedFeed struct {
+	FeedID    string `json:"feed_id"`
+	FeedTitle string `json:"feed_title"`
+	URL       string `json:"url"`
+	Reason    string `json:"reason"`
 }
 
 type ListEntriesInput struct {
-	FeedID     *string `json:"feed_id,omitempty"`
-	UnreadOnly *bool   `json:"unread_only,omitempty"`
-	Since      *string `json:"since,omitempty"`
-	Until      *string `json:"until,omitempty"`
-	Limit      *int    `json:"limit,omitempty"`
-	Offset     *int    `json:"offset,omitempty"`
+	EntryQueryInput
+	Limit    *int     `json:"limit,omitempty"`
+	Offset   *int     `json:"offset,omitempty"`
+	ByScore  bool     `json:"by_score,omitempty"`
+	MarkRead *bool    `json:"mark_read,omitempty"`
+	Fields   []string `json:"fields,omitempty"`
 }
 
 type EntryOutput struct {
@@ -112,13 +256,25 @@ type EntryOutput struct {
 	PublishedAt *time.Time `json:"published_at,omitempty"`
 	Read        bool       `json:"read"`
 	ReadAt      *time.Time `json:"read_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ArchiveURL  *string    `json:"archive_url,omitempty"`
+	ImageURL    *string    `json:"image_url,omitempty"`
+	// DiscussionURL is the entry's comment thread page
+	DiscussionURL *string `json:"discussion_url,omitempty"`
+	// Score and CommentCount are an aggregator post's points and comments
+	Score        *int `json:"score,omitempty"`
+	CommentCount *int `json:"comment_count,omitempty"`
+	// KeepUnread pins the entry unread against bulk and automatic marking
+	KeepUnread bool      `json:"keep_unread,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type ListEntriesOutput struct {
 	Entries []EntryOutput  `json:"entries"`
 	Count   int            `json:"count"`
 	Filters map[string]any `json:"filters"`
+	// MarkedRead counts the returned entries mark_read marked as read;
+	// Entries still show their read state from before
+	MarkedRead int `json:"marked_read,omitempty"`
 }
 
 type MarkReadInput struct {
@@ -130,7 +286,8 @@ type MarkUnreadInput struct {
 }
 
 type BulkMarkReadInput struct {
-	Before string `json:"before"`
+	Before   string  `json:"before"`
+	Timezone *string `json:"tz,omitempty"`
 }
 
 type BulkMarkReadOutput struct {
@@ -140,9 +297,20 @@ type BulkMarkReadOutput struct {
 }
 
 type GetEntryInput struct {
-	EntryID string `json:"entry_id"`
+	EntryID    string  `json:"entry_id"`
+	MaxLength  *int    `json:"max_length,omitempty"`
+	Offset     *int    `json:"offset,omitempty"`
+	Section    *string `json:"section,omitempty"`
+	Paragraphs *int    `json:"paragraphs,omitempty"`
+	Format     *string `json:"format,omitempty"`
+	ReaderView *bool   `json:"reader_view,omitempty"`
 }
 
+// defaultEntryMaxLength is how many characters of content get_entry returns
+// when the caller doesn't pass max_length, so huge articles can't flood the
+// client's context. Callers page through the rest with next_offset.
+const defaultEntryMaxLength = 40000
+
 type GetEntryOutput struct {
 	ID          string     `json:"id"`
 	FeedID      string     `json:"feed_id"`
@@ -151,10 +319,31 @@ type GetEntryOutput struct {
 	Link        *string    `json:"link,omitempty"`
 	Author      *string    `json:"author,omitempty"`
 	PublishedAt *time.Time `json:"published_at,omitempty"`
-	Content     *string    `json:"content,omitempty"`
-	Read        bool       `json:"read"`
-	ReadAt      *time.Time `json:"read_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
+	// ClaimedPublishedAt is the feed's own, implausible date when
+	// published_at is the fetch time standing in for it
+	ClaimedPublishedAt *time.Time `json:"claimed_published_at,omitempty"`
+	Content            *string    `json:"content,omitempty"`
+	Format             string     `json:"format,omitempty"`
+	Truncated          bool       `json:"truncated"`
+	TotalLength        int        `json:"total_length,omitempty"`
+	NextOffset         *int       `json:"next_offset,omitempty"`
+	Read               bool       `json:"read"`
+	ReadAt             *time.Time `json:"read_at,omitempty"`
+	ArchiveURL         *string    `json:"archive_url,omitempty"`
+	ImageURL           *string    `json:"image_url,omitempty"`
+	// ImagePath is the cached copy of the image, when images are cached
+	ImagePath       string  `json:"image_path,omitempty"`
+	DiscussionURL   *string `json:"discussion_url,omitempty"`
+	CommentsFeedURL *string `json:"comments_feed_url,omitempty"`
+	Score           *int    `json:"score,omitempty"`
+	CommentCount    *int    `json:"comment_count,omitempty"`
+	// ScoredAt is when Score and CommentCount were last refreshed
+	ScoredAt   *time.Time `json:"scored_at,omitempty"`
+	KeepUnread bool       `json:"keep_unread,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Extensions are the feed's namespaced elements for the entry, by
+	// prefix then element name, such as extensions.itunes.duration
+	Extensions models.Extensions `json:"extensions,omitempty"`
 }
 
 type ProfileInfo struct {
@@ -174,6 +363,22 @@ var profileProperty = map[string]interface{}{
 	"description": "Target profile name. Defaults to the server's startup profile if omitted.",
 }
 
+// tzProperty is the optional timezone parameter shared by tools that take
+// date filters.
+var tzProperty = map[string]interface{}{
+	"type":        "string",
+	"description": "IANA timezone for resolving periods and dates, so 'today' starts at the user's midnight. Example: 'America/New_York'. Defaults to the timezone in config.json, or the server's local time.",
+}
+
+// location resolves a tool's tz argument, falling back to the profile's
+// configured timezone and then the server's local time.
+func (pc *profileContext) location(tz *string) (*time.Location, error) {
+	if tz != nil && *tz != "" {
+		return timeutil.LoadLocation(*tz)
+	}
+	return pc.cfg.GetLocation()
+}
+
 // extractProfile returns the profile name from the request arguments,
 // or empty string if not specified (which resolves to default).
 func extractProfile(req mcp.CallToolRequest) string {
@@ -192,17 +397,49 @@ func extractProfile(req mcp.CallToolRequest) string {
 // Tool registration
 
 func (s *Server) registerTools() {
+	// Query tools
 	s.registerListFeedsTool()
-	s.registerAddFeedTool()
-	s.registerRemoveFeedTool()
-	s.registerMoveFeedTool()
-	s.registerSyncFeedsTool()
+	s.registerGetFeedTool()
+	s.registerPreviewFeedTool()
 	s.registerListEntriesTool()
+	s.registerCountEntriesTool()
+	s.registerAggregateEntriesTool()
 	s.registerGetEntryTool()
+	s.registerGetChangesTool()
+	s.registerGetDiscussionTool()
+	s.registerListLabelsTool()
+	s.registerListProfilesTool()
+	s.registerSummarizeWithClientTool()
+	s.registerTrendingTopicsTool()
+	s.registerRecommendFeedsTool()
+
+	if s.readOnly {
+		return
+	}
+
+	// Read state tools
 	s.registerMarkReadTool()
 	s.registerMarkUnreadTool()
+	s.registerKeepUnreadTool()
 	s.registerBulkMarkReadTool()
-	s.registerListProfilesTool()
+
+	if s.role == team.RoleReader {
+		return
+	}
+
+	// Subscription and content tools
+	s.registerAddFeedTool()
+	s.registerRemoveFeedTool()
+	s.registerMoveFeedTool()
+	s.registerUpdateFeedTool()
+	s.registerLabelFeedTool()
+	s.registerUnlabelFeedTool()
+	s.registerRenameLabelTool()
+	s.registerDeleteLabelTool()
+	s.registerSyncFeedsTool()
+	s.registerPruneEntriesTool()
+	s.registerArchiveEntryTool()
+	s.registerRefreshEntryTool()
 }
 
 func (s *Server) registerListFeedsTool() {
@@ -219,10 +456,33 @@ func (s *Server) registerListFeedsTool() {
 	s.mcpServer.AddTool(tool, s.handleListFeeds)
 }
 
+func (s *Server) registerGetFeedTool() {
+	tool := mcp.Tool{
+		Name:        "get_feed",
+		Description: "Get full details for a single feed: metadata, OPML folder, fetch state, its last error and how many syncs in a row have failed, entry and unread counts, and its most recent entries. Accepts the feed URL or an ID prefix (min 6 chars). Use this instead of list_feeds plus list_entries when inspecting one feed.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"feed": map[string]interface{}{
+					"type":        "string",
+					"description": "Feed URL or ID prefix. Example: 'https://example.com/feed.xml' or 'a1b2c3'",
+				},
+				"entry_limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of recent entries to include. Default: 10. Use 0 to omit entries.",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"feed"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleGetFeed)
+}
+
 func (s *Server) registerAddFeedTool() {
 	tool := mcp.Tool{
 		Name:        "add_feed",
-		Description: "Add a new RSS/Atom feed to the subscription list. The feed is added to both the database and the OPML file. Optionally specify a title and folder for organization. If no title is provided, it will be fetched from the feed on first sync. Returns the created feed with its unique ID.",
+		Description: "Add a new RSS/Atom feed to the subscription list. The feed is added to both the database and the OPML file. Optionally specify a title and folder for organization. If no title is provided, it will be fetched from the feed on first sync. Set monitor to watch a web page instead: each sync then adds an entry with a diff of the page's text whenever it changes. Everything the feed publishes arrives unread on its first sync; backfill_days and backfill_limit trim that history, defaulting to the backfill setting in config.json. Returns the created feed with its unique ID.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -242,6 +502,27 @@ func (s *Server) registerAddFeedTool() {
 					"type":        "boolean",
 					"description": "If true, allows fetching from local network (private IP) addresses. Use for feeds hosted on LAN servers. Default: false",
 				},
+				"browser": map[string]interface{}{
+					"type":        "boolean",
+					"description": "If true, the feed is requested with browser-like headers, for sites whose bot protection turns away feed readers. Default: false",
+				},
+				"monitor": map[string]interface{}{
+					"type":        "boolean",
+					"description": "If true, url is watched as a web page rather than read as a feed: the first sync records its text, and later syncs add an entry showing the diff whenever the text changes. Default: false",
+				},
+				"priority": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"high", "normal", "low"},
+					"description": "Feed priority. Unread listings show high-priority feeds first and low-priority feeds last, and only high-priority feeds' entries are pushed to delivery targets as they arrive. Default: 'normal'",
+				},
+				"backfill_days": map[string]interface{}{
+					"type":        "integer",
+					"description": "On the first sync, mark entries published more than this many days ago as read; 0 leaves them all unread. Defaults to the configured backfill. Example: 14",
+				},
+				"backfill_limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "On the first sync, import only this many of the newest entries; 0 imports all. Defaults to the configured backfill. Example: 20",
+				},
 				"profile": profileProperty,
 			},
 			Required: []string{"url"},
@@ -292,10 +573,79 @@ func (s *Server) registerMoveFeedTool() {
 	s.mcpServer.AddTool(tool, s.handleMoveFeed)
 }
 
+func (s *Server) registerUpdateFeedTool() {
+	tool := mcp.Tool{
+		Name:        "update_feed",
+		Description: "Edit a feed's subscription settings in one call: title, folder, pause state, archive state, entry identity, priority, sync interval, maximum retained entries, local network access, browser-like requests, and HTTP basic auth. Only the fields you pass are changed. Changes are validated and saved to both the database and the OPML file. Returns the updated feed and the list of changed fields.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"feed": map[string]interface{}{
+					"type":        "string",
+					"description": "Feed URL or ID prefix. Example: 'https://example.com/feed.xml' or 'a1b2c3'",
+				},
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "New display title. Example: 'My Favorite Blog'",
+				},
+				"folder": map[string]interface{}{
+					"type":        "string",
+					"description": "Target folder. Use empty string '' for root level. Example: 'Tech Blogs'",
+				},
+				"paused": map[string]interface{}{
+					"type":        "boolean",
+					"description": "If true, the feed is skipped by sync_feeds until resumed.",
+				},
+				"archived": map[string]interface{}{
+					"type":        "boolean",
+					"description": "If true, archive the feed as dead so sync_feeds skips it. If false, unarchive it and never archive it automatically again.",
+				},
+				"identity": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"auto", "guid", "link", "hash"},
+					"description": "How entries are told apart across syncs: 'guid', 'link', or 'hash' (title + publish date). 'auto' uses GUIDs and switches to link or hash when the feed regenerates them.",
+				},
+				"priority": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"high", "normal", "low"},
+					"description": "Feed priority: unread listings and the reading queue put 'high' feeds first and 'low' feeds last, and only 'high' feeds' entries are pushed to delivery targets as they arrive.",
+				},
+				"sync_interval": map[string]interface{}{
+					"type":        "string",
+					"description": "Minimum time between syncs as a duration. Use '0' to sync every time. Example: '30m', '6h'",
+				},
+				"max_entries": map[string]interface{}{
+					"type":        "integer",
+					"description": "Keep at most this many entries; older ones are pruned on sync. Use 0 for unlimited.",
+				},
+				"local_network": map[string]interface{}{
+					"type":        "boolean",
+					"description": "If true, allows fetching from local network (private IP) addresses.",
+				},
+				"browser": map[string]interface{}{
+					"type":        "boolean",
+					"description": "If true, the feed is requested with browser-like headers. Use when syncs fail with 'blocked by bot protection'.",
+				},
+				"auth_username": map[string]interface{}{
+					"type":        "string",
+					"description": "HTTP basic auth username. Use empty string '' to remove credentials.",
+				},
+				"auth_password": map[string]interface{}{
+					"type":        "string",
+					"description": "HTTP basic auth password. Never returned in output.",
+				},
+				"profile": profileProperty,
+			},
+			Required: []string{"feed"},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleUpdateFeed)
+}
+
 func (s *Server) registerSyncFeedsTool() {
 	tool := mcp.Tool{
 		Name:        "sync_feeds",
-		Description: "Fetch new entries from RSS/Atom feeds. If url is provided, syncs only that specific feed. Otherwise, syncs all subscribed feeds. Uses HTTP caching headers (ETag, Last-Modified) to avoid unnecessary downloads. Set force=true to ignore cache and fetch unconditionally. Returns a summary of new entries, cached responses, and any errors.",
+		Description: "Fetch new entries from RSS/Atom feeds. If url is provided, syncs only that specific feed. Otherwise, syncs all subscribed feeds. Uses HTTP caching headers (ETag, Last-Modified) to avoid unnecessary downloads. Set force=true to ignore cache and fetch unconditionally; bodies downloaded earlier in the same server process are still revalidated and reused when unchanged, and 'cache' reports the DNS lookups and downloads saved. Paused and archived feeds are skipped unless synced by url. After a full sync, feeds with no new entries or only errors for the configured inactive_days (default 90) are archived and listed under 'archived'. Then the points and comment counts of recent unread Hacker News and Lobsters posts are refreshed, at most hourly per post. Folders over their configured unread_budgets have their oldest unread entries (other than ones pinned with keep_unread) marked read, listed under 'budgets' with the entry IDs marked. Returns a summary of new entries, cached responses, and any errors.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -315,38 +665,35 @@ func (s *Server) registerSyncFeedsTool() {
 }
 
 func (s *Server) registerListEntriesTool() {
+	props := entryQueryProperties()
+	props["limit"] = map[string]interface{}{
+		"type":        "integer",
+		"description": "Maximum number of entries to return. If omitted, returns all matching entries. Example: 50",
+	}
+	props["offset"] = map[string]interface{}{
+		"type":        "integer",
+		"description": "Number of entries to skip for pagination. Use with limit for paging through results. Example: 20 to skip first 20 entries",
+	}
+	props["by_score"] = map[string]interface{}{
+		"type":        "boolean",
+		"description": "If true, orders Hacker News and Lobsters posts by their current points, highest first, with entries that have no score after them. Scores are refreshed by sync_feeds. Example: true with unread_only for the most discussed unread posts",
+	}
+	props["fields"] = map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Optional fields to return for each entry; id is always included. Any of: " + strings.Join(entryFieldNames, ", ") + ". If omitted, returns every field. Example: ['title', 'link'] to skim headlines",
+	}
+	props["mark_read"] = map[string]interface{}{
+		"type":        "boolean",
+		"description": "If true, marks the returned entries as read once they're listed; the response still shows their earlier read state and counts them in marked_read. Defaults to the list_marks_read config setting. Not available on a read-only server. Example: true with unread_only and limit=20 to skim and clear the newest headlines",
+	}
+	props["profile"] = profileProperty
 	tool := mcp.Tool{
 		Name:        "list_entries",
-		Description: "Retrieve feed entries with optional filtering. Use 'since' with values like 'today', 'yesterday', 'week', 'month' to get recent entries (e.g., since='today' for today's entries). Filter by feed_id for a specific feed, unread_only for unread entries, and limit to control results. All filters are optional and can be combined. Returns entries sorted by published date (newest first). Use get_entry to read full article content.",
+		Description: "Retrieve feed entries with optional filtering. Use 'since' with values like 'today', 'yesterday', 'week', 'month' to get recent entries (e.g., since='today' for today's entries). Filter by feed_id for a specific feed, unread_only for unread entries, and limit to control results. All filters are optional and can be combined. Returns entries sorted by published date (newest first), or with first_seen by when digest first fetched them, which keeps posts a feed backfills out of 'today'. With unread_only, entries of high-priority feeds come first and low-priority feeds last. new_only returns just what each feed's latest sync brought in. by_score ranks Hacker News and Lobsters posts by their current points instead, and min_score drops posts below a threshold. kept_only returns entries pinned with keep_unread. fields returns only the named fields of each entry, plus its id, to keep long headline listings small. mark_read marks the returned entries as read in the same call, for skimming headlines; entries pinned with keep_unread stay unread. Use get_entry to read full article content.",
 		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"feed_id": map[string]interface{}{
-					"type":        "string",
-					"description": "Optional feed ID to filter entries. Only entries from this feed will be returned. Example: 'abc12345-1234-1234-1234-123456789abc'",
-				},
-				"unread_only": map[string]interface{}{
-					"type":        "boolean",
-					"description": "If true, returns only unread entries. If false or omitted, returns all entries. Example: true",
-				},
-				"since": map[string]interface{}{
-					"type":        "string",
-					"description": "Only return entries published on or after this date. Accepts shortcuts: 'today', 'yesterday', 'week', 'month', or ISO date (YYYY-MM-DD). Example: 'today' for today's entries",
-				},
-				"until": map[string]interface{}{
-					"type":        "string",
-					"description": "Only return entries published before this date. Accepts: 'today', 'yesterday', 'week', 'month', or ISO date (YYYY-MM-DD). Example: 'today' for yesterday and earlier",
-				},
-				"limit": map[string]interface{}{
-					"type":        "integer",
-					"description": "Maximum number of entries to return. If omitted, returns all matching entries. Example: 50",
-				},
-				"offset": map[string]interface{}{
-					"type":        "integer",
-					"description": "Number of entries to skip for pagination. Use with limit for paging through results. Example: 20 to skip first 20 entries",
-				},
-				"profile": profileProperty,
-			},
+			Type:       "object",
+			Properties: props,
 		},
 	}
 	s.mcpServer.AddTool(tool, s.handleListEntries)
@@ -355,7 +702,7 @@ func (s *Server) registerListEntriesTool() {
 func (s *Server) registerGetEntryTool() {
 	tool := mcp.Tool{
 		Name:        "get_entry",
-		Description: "Get the full details of a single entry including its content. Content is converted from HTML to Markdown for better readability. Use this after list_entries to read the full article. Supports both full entry IDs and ID prefixes (first 8 characters).",
+		Description: "Get the full details of a single entry including its content. Content is converted from HTML to Markdown by default; pass format for plain text, HTML, or the raw stored content. Use this after list_entries to read the full article. Supports both full entry IDs and ID prefixes (first 8 characters). Long articles are cut at 40000 characters by default; when truncated is true, call again with offset set to next_offset for the rest, or narrow the content with section or paragraphs. extensions holds the feed's namespaced elements for the entry (dc:, media:, itunes:, or any custom namespace) by prefix then element name, each with its value, attrs, and children.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -363,6 +710,31 @@ func (s *Server) registerGetEntryTool() {
 					"type":        "string",
 					"description": "The entry ID or ID prefix. Example: 'abc12345' (prefix) or 'abc12345-1234-1234-1234-123456789abc' (full)",
 				},
+				"max_length": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum characters of content to return. Default: 40000. Use 0 for the full content.",
+				},
+				"offset": map[string]interface{}{
+					"type":        "integer",
+					"description": "Character offset to start from, usually the next_offset of a previous call. Default: 0.",
+				},
+				"section": map[string]interface{}{
+					"type":        "string",
+					"description": "Return only the section under the first heading containing this text (case-insensitive). Markdown format only. Example: 'Conclusion'",
+				},
+				"format": map[string]interface{}{
+					"type":        "string",
+					"enum":        content.Formats,
+					"description": "Content format: markdown (default), text (plain text, no markup), html (HTML as published), or raw (exactly as stored).",
+				},
+				"reader_view": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Strip page boilerplate (navigation, ads, share bars, newsletter prompts) before converting to markdown. Defaults to the user's config.",
+				},
+				"paragraphs": map[string]interface{}{
+					"type":        "integer",
+					"description": "Return only the first N paragraphs. Example: 3",
+				},
 				"profile": profileProperty,
 			},
 			Required: []string{"entry_id"},
@@ -374,7 +746,7 @@ func (s *Server) registerGetEntryTool() {
 func (s *Server) registerMarkReadTool() {
 	tool := mcp.Tool{
 		Name:        "mark_read",
-		Description: "Mark an entry as read by its ID. Sets the read flag to true and records the current timestamp. Returns the updated entry. Use list_entries to find entry IDs.",
+		Description: "Mark an entry as read by its ID. Sets the read flag to true and records the current timestamp, releasing the entry if it was pinned with keep_unread. Returns the updated entry. Use list_entries to find entry IDs.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -412,14 +784,15 @@ func (s *Server) registerMarkUnreadTool() {
 func (s *Server) registerBulkMarkReadTool() {
 	tool := mcp.Tool{
 		Name:        "bulk_mark_read",
-		Description: "Mark all entries older than a specified period as read. Use this to catch up on older content. Accepts period names (yesterday, week, month) or ISO 8601 dates (YYYY-MM-DD). Returns the count of entries marked as read.",
+		Description: "Mark all entries older than a specified period as read. Use this to catch up on older content. Accepts period names (yesterday, week, month), ISO 8601 dates (YYYY-MM-DD), or relative dates ('3 days ago', '2 weeks', 'last monday'). Entries pinned with keep_unread are skipped. Returns the count of entries marked as read.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"before": map[string]interface{}{
 					"type":        "string",
-					"description": "Mark entries published before this date/period as read. Accepts: 'yesterday', 'week', 'month', or YYYY-MM-DD. Example: 'yesterday' or '2024-01-15'",
+					"description": "Mark entries published before this date/period as read. Accepts: 'yesterday', 'week', 'month', YYYY-MM-DD, or a relative date like '3 days ago'. Example: 'yesterday', '2024-01-15', or '2 weeks'",
 				},
+				"tz":      tzProperty,
 				"profile": profileProperty,
 			},
 			Required: []string{"before"},
@@ -442,7 +815,7 @@ func (s *Server) registerListProfilesTool() {
 
 // Handler implementations
 
-func (s *Server) handleListFeeds(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleListFeeds(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -450,12 +823,11 @@ func (s *Server) handleListFeeds(_ context.Context, req mcp.CallToolRequest) (*m
 
 	// Get all feeds from OPML
 	pc.opmlMu.RLock()
-	opmlFeeds := pc.opmlDoc.AllFeeds()
-	folders := pc.opmlDoc.Folders()
+	opmlFeeds, folders := s.scope.filterOPML(pc.opmlDoc.AllFeeds(), pc.opmlDoc.Folders())
 	pc.opmlMu.RUnlock()
 
 	// Get all feeds from storage
-	storedFeeds, err := pc.store.ListFeeds()
+	storedFeeds, err := pc.store.ListFeeds(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list feeds: %w", err)
 	}
@@ -476,18 +848,13 @@ func (s *Server) handleListFeeds(_ context.Context, req mcp.CallToolRequest) (*m
 
 		// Add storage info if available
 		if storedFeed, exists := storedFeedMap[opmlFeed.URL]; exists {
-			output.ID = storedFeed.ID
-			output.Title = storedFeed.Title
-			output.LocalNetwork = storedFeed.LocalNetwork
-			output.LastFetchedAt = storedFeed.LastFetchedAt
-			output.LastError = storedFeed.LastError
-			output.ErrorCount = storedFeed.ErrorCount
-			output.CreatedAt = storedFeed.CreatedAt
+			output = newFeedOutput(storedFeed, opmlFeed.Folder, pc.iconDir)
 		} else {
 			// Feed in OPML but not in storage
 			title := opmlFeed.Title
 			output.Title = &title
 		}
+		output.Labels = opmlFeed.Labels
 
 		feedOutputs = append(feedOutputs, output)
 	}
@@ -506,7 +873,121 @@ func (s *Server) handleListFeeds(_ context.Context, req mcp.CallToolRequest) (*m
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-func (s *Server) handleAddFeed(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleGetFeed(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input GetFeedInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	if input.Feed == "" {
+		return nil, fmt.Errorf("feed is required")
+	}
+
+	limit := 10
+	if input.EntryLimit != nil {
+		if *input.EntryLimit < 0 {
+			return nil, fmt.Errorf("entry_limit must be non-negative, got %d", *input.EntryLimit)
+		}
+		limit = *input.EntryLimit
+	}
+
+	feed, err := pc.store.GetFeedByURLOrPrefix(ctx, input.Feed)
+	if err != nil {
+		return nil, fmt.Errorf("feed not found: %s", input.Feed)
+	}
+
+	// Find folder in OPML
+	pc.opmlMu.RLock()
+	folder := ""
+	var labels []string
+	inOPML := false
+	for _, opmlFeed := range pc.opmlDoc.AllFeeds() {
+		if opmlFeed.URL == feed.URL {
+			folder = opmlFeed.Folder
+			labels = opmlFeed.Labels
+			inOPML = true
+			break
+		}
+	}
+	pc.opmlMu.RUnlock()
+
+	total, err := countEntries(ctx, pc, feed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	}
+	unread, err := pc.store.CountUnreadEntries(ctx, &feed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread entries: %w", err)
+	}
+
+	// ListEntries is sorted newest first, so these are the most recent
+	var recent []*models.Entry
+	if limit > 0 {
+		recent, err = pc.store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID, Limit: &limit, NoContent: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list entries: %w", err)
+		}
+	}
+	entryOutputs := make([]EntryOutput, 0, len(recent))
+	for _, entry := range recent {
+		entryOutputs = append(entryOutputs, EntryOutput{
+			ID:            entry.ID,
+			FeedID:        entry.FeedID,
+			Title:         entry.Title,
+			Link:          entry.Link,
+			Author:        entry.Author,
+			PublishedAt:   entry.PublishedAt,
+			Read:          entry.Read,
+			ReadAt:        entry.ReadAt,
+			ArchiveURL:    entry.ArchiveURL,
+			ImageURL:      entry.ImageURL,
+			DiscussionURL: entry.DiscussionURL,
+			Score:         entry.Score,
+			CommentCount:  entry.CommentCount,
+			KeepUnread:    entry.KeepUnread,
+			CreatedAt:     entry.CreatedAt,
+		})
+	}
+
+	output := GetFeedOutput{
+		FeedOutput:    newFeedOutput(feed, folder, pc.iconDir),
+		ETag:          feed.ETag,
+		LastModified:  feed.LastModified,
+		InOPML:        inOPML,
+		EntryCount:    total,
+		UnreadCount:   unread,
+		RecentEntries: entryOutputs,
+	}
+	output.Labels = labels
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// countEntries counts a feed's entries. The server's scope and team views
+// can't count without loading every entry; the store under them can, and
+// a feed's total is the same in every view.
+func countEntries(ctx context.Context, pc *profileContext, feedID string) (int, error) {
+	if counter, ok := pc.base.(storage.EntryCounter); ok {
+		return counter.CountEntries(ctx, &feedID)
+	}
+	count := 0
+	err := pc.store.EachEntry(ctx, &storage.EntryFilter{FeedID: &feedID, NoContent: true}, func(*models.Entry) error {
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func (s *Server) handleAddFeed(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -517,23 +998,82 @@ func (s *Server) handleAddFeed(_ context.Context, req mcp.CallToolRequest) (*mcp
 		return nil, fmt.Errorf("invalid input: %w", err)
 	}
 
-	// Validate URL format
-	parsedURL, err := url.Parse(input.URL)
+	// Validate the URL and drop tracking parameters, port noise, and fragments
+	canonical, err := feedurl.Canonical(input.URL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid feed URL: %w", err)
+		return nil, err
 	}
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return nil, fmt.Errorf("feed URL must use http or https scheme, got: %s", parsedURL.Scheme)
+	input.URL = canonical
+
+	folder := ""
+	if input.Folder != nil {
+		folder = *input.Folder
 	}
-	if parsedURL.Host == "" {
-		return nil, fmt.Errorf("feed URL must have a host")
+	if err := s.scope.checkPlacement(input.URL, folder); err != nil {
+		return nil, err
+	}
+	if err := s.limiter.check("add_feed", s.limits.AddFeedsPerHour); err != nil {
+		return nil, err
+	}
+	backfill, err := pc.cfg.GetBackfill()
+	if err != nil {
+		return nil, err
+	}
+	priority := models.PriorityNormal
+	if input.Priority != nil {
+		if priority, err = models.ParsePriority(*input.Priority); err != nil {
+			return nil, err
+		}
+	}
+	if input.BackfillDays != nil {
+		if *input.BackfillDays < 0 {
+			return nil, fmt.Errorf("backfill_days must be non-negative, got %d", *input.BackfillDays)
+		}
+		backfill.Days = *input.BackfillDays
+	}
+	if input.BackfillLimit != nil {
+		if *input.BackfillLimit < 0 {
+			return nil, fmt.Errorf("backfill_limit must be non-negative, got %d", *input.BackfillLimit)
+		}
+		backfill.Limit = *input.BackfillLimit
 	}
 
-	// Check if feed already exists
-	existingFeed, err := pc.store.GetFeedByURL(input.URL)
+	// Check if feed already exists, including under another scheme, www, or trailing slash
+	existingFeed, err := pc.store.GetFeedByURL(ctx, input.URL)
 	if err == nil && existingFeed != nil {
 		return nil, fmt.Errorf("feed already exists: %s", input.URL)
 	}
+	feeds, err := pc.store.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	for _, feed := range feeds {
+		if feedurl.Key(feed.URL) == feedurl.Key(input.URL) {
+			return nil, fmt.Errorf("feed already exists as %s", feed.URL)
+		}
+	}
+
+	runner, err := s.hookRunner(pc)
+	if err != nil {
+		return nil, err
+	}
+	givenTitle := ""
+	if input.Title != nil {
+		givenTitle = *input.Title
+	}
+	hooked, err := runner.PreAddFeed(ctx, hooks.Feed{URL: input.URL, Title: givenTitle, Folder: folder})
+	if err != nil {
+		return nil, err
+	}
+	if hooked.Title != givenTitle {
+		input.Title = &hooked.Title
+	}
+	if hooked.Folder != folder {
+		if err := s.scope.checkPlacement(input.URL, hooked.Folder); err != nil {
+			return nil, err
+		}
+		folder = hooked.Folder
+	}
 
 	// Create feed in storage
 	feed := storage.NewFeed(input.URL)
@@ -543,20 +1083,26 @@ func (s *Server) handleAddFeed(_ context.Context, req mcp.CallToolRequest) (*mcp
 	if input.LocalNetwork != nil && *input.LocalNetwork {
 		feed.LocalNetwork = true
 	}
+	if input.Browser != nil && *input.Browser {
+		feed.Browser = true
+	}
+	if input.Monitor != nil && *input.Monitor {
+		feed.Monitor = true
+	}
+	feed.Folder = folder
+	feed.Priority = priority
+	feed.BackfillDays, feed.BackfillLimit = backfill.Days, backfill.Limit
 
-	if err := pc.store.CreateFeed(feed); err != nil {
+	if err := pc.store.CreateFeed(ctx, feed); err != nil {
 		return nil, fmt.Errorf("failed to create feed: %w", err)
 	}
+	s.limiter.record("add_feed")
 
 	// Add to OPML
 	title := input.URL
 	if input.Title != nil {
 		title = *input.Title
 	}
-	folder := ""
-	if input.Folder != nil {
-		folder = *input.Folder
-	}
 
 	pc.opmlMu.Lock()
 	if err := pc.opmlDoc.AddFeed(input.URL, title, folder); err != nil {
@@ -572,13 +1118,17 @@ func (s *Server) handleAddFeed(_ context.Context, req mcp.CallToolRequest) (*mcp
 	pc.opmlMu.Unlock()
 
 	output := FeedOutput{
-		ID:           feed.ID,
-		URL:          feed.URL,
-		Title:        feed.Title,
-		Folder:       folder,
-		LocalNetwork: feed.LocalNetwork,
-		ErrorCount:   feed.ErrorCount,
-		CreatedAt:    feed.CreatedAt,
+		ID:            feed.ID,
+		URL:           feed.URL,
+		Title:         feed.Title,
+		Folder:        folder,
+		LocalNetwork:  feed.LocalNetwork,
+		Browser:       feed.Browser,
+		Monitor:       feed.Monitor,
+		ErrorCount:    feed.ErrorCount,
+		BackfillDays:  feed.BackfillDays,
+		BackfillLimit: feed.BackfillLimit,
+		CreatedAt:     feed.CreatedAt,
 	}
 
 	jsonBytes, err := json.MarshalIndent(output, "", "  ")
@@ -589,7 +1139,7 @@ func (s *Server) handleAddFeed(_ context.Context, req mcp.CallToolRequest) (*mcp
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-func (s *Server) handleRemoveFeed(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleRemoveFeed(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -600,16 +1150,22 @@ func (s *Server) handleRemoveFeed(_ context.Context, req mcp.CallToolRequest) (*
 		return nil, fmt.Errorf("invalid input: %w", err)
 	}
 
+	if err := s.limiter.check("remove_feed", s.limits.RemoveFeedsPerHour); err != nil {
+		return nil, err
+	}
+
 	// Get feed to get ID
-	feed, err := pc.store.GetFeedByURL(input.URL)
+	feed, err := pc.store.GetFeedByURL(ctx, input.URL)
 	if err != nil {
 		return nil, fmt.Errorf("feed not found: %s", input.URL)
 	}
 
 	// Delete from storage (cascade deletes entries)
-	if err := pc.store.DeleteFeed(feed.ID); err != nil {
+	if err := pc.store.DeleteFeed(ctx, feed.ID); err != nil {
 		return nil, fmt.Errorf("failed to delete feed: %w", err)
 	}
+	s.limiter.record("remove_feed")
+	favicon.Remove(pc.iconDir, feed.ID)
 
 	// Remove from OPML
 	pc.opmlMu.Lock()
@@ -639,7 +1195,7 @@ func (s *Server) handleRemoveFeed(_ context.Context, req mcp.CallToolRequest) (*
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-func (s *Server) handleMoveFeed(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleMoveFeed(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -663,9 +1219,12 @@ func (s *Server) handleMoveFeed(_ context.Context, req mcp.CallToolRequest) (*mc
 	}
 
 	// Verify feed exists
-	if _, err := pc.store.GetFeedByURL(input.URL); err != nil {
+	if _, err := pc.store.GetFeedByURL(ctx, input.URL); err != nil {
 		return nil, fmt.Errorf("feed not found: %s", input.URL)
 	}
+	if err := s.scope.checkPlacement(input.URL, input.Folder); err != nil {
+		return nil, err
+	}
 
 	// Find current folder for the feed
 	pc.opmlMu.RLock()
@@ -730,6 +1289,210 @@ func (s *Server) handleMoveFeed(_ context.Context, req mcp.CallToolRequest) (*mc
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
+func (s *Server) handleUpdateFeed(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input UpdateFeedInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	if input.Feed == "" {
+		return nil, fmt.Errorf("feed is required")
+	}
+
+	feed, err := pc.store.GetFeedByURLOrPrefix(ctx, input.Feed)
+	if err != nil {
+		return nil, fmt.Errorf("feed not found: %s", input.Feed)
+	}
+
+	// Validate everything before changing anything
+	if input.Title != nil && strings.TrimSpace(*input.Title) == "" {
+		return nil, fmt.Errorf("title must not be empty")
+	}
+	var interval time.Duration
+	if input.SyncInterval != nil {
+		interval, err = time.ParseDuration(*input.SyncInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sync_interval: %w", err)
+		}
+		if interval < 0 {
+			return nil, fmt.Errorf("sync_interval must be non-negative, got %s", *input.SyncInterval)
+		}
+		// Intervals are stored in whole seconds
+		if interval > 0 && interval < time.Second {
+			return nil, fmt.Errorf("sync_interval must be 0 or at least 1s, got %s", *input.SyncInterval)
+		}
+	}
+	if input.MaxEntries != nil && *input.MaxEntries < 0 {
+		return nil, fmt.Errorf("max_entries must be non-negative, got %d", *input.MaxEntries)
+	}
+	var identity string
+	if input.Identity != nil {
+		if identity, err = models.ParseIdentity(*input.Identity); err != nil {
+			return nil, err
+		}
+	}
+	var priority string
+	if input.Priority != nil {
+		if priority, err = models.ParsePriority(*input.Priority); err != nil {
+			return nil, err
+		}
+	}
+	if input.AuthPassword != nil && input.AuthUsername == nil && !feed.HasAuth() {
+		return nil, fmt.Errorf("auth_password requires auth_username")
+	}
+	if input.Folder != nil {
+		if err := s.scope.checkPlacement(feed.URL, *input.Folder); err != nil {
+			return nil, err
+		}
+	}
+
+	var changed []string
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		feed.Title = &title
+		changed = append(changed, "title")
+	}
+	if input.Paused != nil {
+		feed.Paused = *input.Paused
+		changed = append(changed, "paused")
+	}
+	if input.Archived != nil {
+		if *input.Archived {
+			now := time.Now()
+			feed.ArchivedAt = &now
+			feed.KeepActive = false
+		} else {
+			feed.ArchivedAt = nil
+			feed.KeepActive = true
+		}
+		changed = append(changed, "archived")
+	}
+	if input.Identity != nil {
+		feed.Identity = identity
+		changed = append(changed, "identity")
+	}
+	if input.Priority != nil {
+		feed.Priority = priority
+		changed = append(changed, "priority")
+	}
+	if input.SyncInterval != nil {
+		feed.SyncInterval = interval
+		changed = append(changed, "sync_interval")
+	}
+	if input.MaxEntries != nil {
+		feed.MaxEntries = *input.MaxEntries
+		changed = append(changed, "max_entries")
+	}
+	if input.LocalNetwork != nil {
+		feed.LocalNetwork = *input.LocalNetwork
+		changed = append(changed, "local_network")
+	}
+	if input.Browser != nil {
+		feed.Browser = *input.Browser
+		changed = append(changed, "browser")
+	}
+	if input.AuthUsername != nil || input.AuthPassword != nil {
+		provider, err := pc.cfg.Secrets()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open secrets: %w", err)
+		}
+		if input.AuthUsername != nil {
+			if *input.AuthUsername == "" {
+				feed.AuthUsername = nil
+				if err := config.ClearFeedPassword(provider, feed); err != nil {
+					return nil, err
+				}
+			} else {
+				username := *input.AuthUsername
+				feed.AuthUsername = &username
+			}
+			changed = append(changed, "auth_username")
+		}
+		if input.AuthPassword != nil && feed.HasAuth() {
+			if err := config.SetFeedPassword(provider, feed, *input.AuthPassword); err != nil {
+				return nil, err
+			}
+			changed = append(changed, "auth_password")
+		}
+	}
+	if input.Folder != nil {
+		feed.Folder = *input.Folder
+		changed = append(changed, "folder")
+	}
+
+	if err := pc.store.UpdateFeed(ctx, feed); err != nil {
+		return nil, fmt.Errorf("failed to update feed: %w", err)
+	}
+
+	// Mirror title and folder into OPML
+	pc.opmlMu.Lock()
+	folder := ""
+	inOPML := false
+	for _, opmlFeed := range pc.opmlDoc.AllFeeds() {
+		if opmlFeed.URL == feed.URL {
+			folder = opmlFeed.Folder
+			inOPML = true
+			break
+		}
+	}
+	opmlChanged := false
+	if !inOPML {
+		// Re-add feeds missing from OPML so the subscription list stays in sync
+		if input.Folder != nil {
+			folder = *input.Folder
+		}
+		if err := pc.opmlDoc.AddFeed(feed.URL, feed.GetDisplayName(), folder); err != nil {
+			pc.opmlMu.Unlock()
+			return nil, fmt.Errorf("failed to add feed to OPML: %w", err)
+		}
+		opmlChanged = true
+	} else {
+		if input.Title != nil {
+			if err := pc.opmlDoc.RenameFeed(feed.URL, *feed.Title); err != nil {
+				pc.opmlMu.Unlock()
+				return nil, fmt.Errorf("failed to rename feed in OPML: %w", err)
+			}
+			opmlChanged = true
+		}
+		if input.Folder != nil && *input.Folder != folder {
+			if err := pc.opmlDoc.MoveFeed(feed.URL, *input.Folder); err != nil {
+				pc.opmlMu.Unlock()
+				return nil, fmt.Errorf("failed to move feed in OPML: %w", err)
+			}
+			folder = *input.Folder
+			opmlChanged = true
+		}
+	}
+	if opmlChanged {
+		if err := pc.opmlDoc.WriteFile(pc.opmlPath); err != nil {
+			pc.opmlMu.Unlock()
+			return nil, fmt.Errorf("failed to write OPML file: %w", err)
+		}
+	}
+	pc.opmlMu.Unlock()
+
+	if changed == nil {
+		changed = []string{}
+	}
+	output := UpdateFeedOutput{
+		Success: true,
+		Message: fmt.Sprintf("Updated %d setting(s) for '%s'", len(changed), feed.GetDisplayName()),
+		Changed: changed,
+		Feed:    newFeedOutput(feed, folder, pc.iconDir),
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
 func (s *Server) handleSyncFeeds(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
@@ -746,73 +1509,147 @@ func (s *Server) handleSyncFeeds(ctx context.Context, req mcp.CallToolRequest) (
 		force = *input.Force
 	}
 
-	// Get feeds to sync
-	feeds, err := pc.store.ListFeeds()
+	// Don't overlap with a CLI or cron sync of the same profile
+	lock, err := runlock.Acquire(pc.lockPath, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list feeds: %w", err)
+		return nil, err
 	}
+	defer lock.Release()
 
-	if len(feeds) == 0 {
+	opts, err := s.syncOptions(pc)
+	if err != nil {
+		return nil, err
+	}
+	engine := &syncer.Engine{
+		Store: pc.store,
+		Options: func(feed *models.Feed) (feedsync.Options, error) {
+			o := opts
+			var err error
+			o.Secrets, err = pc.cfg.FeedSecrets(feed)
+			return o, err
+		},
+		Force:       force,
+		Concurrency: pc.cfg.GetSyncConcurrency(),
+		IconDir:     pc.iconDir,
+		Source:      "mcp",
+	}
+	if input.URL != nil {
+		engine.URL = *input.URL
+	}
+	report, err := engine.Sync(ctx)
+	if errors.Is(err, syncer.ErrNoFeeds) {
 		return nil, fmt.Errorf("no feeds found. Add a feed first using add_feed")
 	}
+	if err != nil {
+		return nil, err
+	}
+	now, run := report.Started, report.Run
 
-	// Filter to specific URL if provided
-	if input.URL != nil {
-		filtered := []*models.Feed{}
-		for _, feed := range feeds {
-			if feed.URL == *input.URL {
-				filtered = append(filtered, feed)
-				break
-			}
+	results := make([]SyncResult, 0, len(report.Outcomes))
+	for _, o := range report.Outcomes {
+		result := SyncResult{FeedID: o.Feed.ID, FeedTitle: o.Feed.URL, Skipped: o.Skipped}
+		if o.Feed.Title != nil {
+			result.FeedTitle = *o.Feed.Title
 		}
-		if len(filtered) == 0 {
-			return nil, fmt.Errorf("feed not found: %s", *input.URL)
+		switch {
+		case o.Err != nil:
+			errMsg := o.Err.Error()
+			result.Error = &errMsg
+		case o.Result != nil:
+			result.NewEntries = o.Result.NewEntries
+			result.WasCached = o.Result.WasCached
+			result.Identity = o.Result.Identity
+			result.Truncated = o.Result.Truncated
 		}
-		feeds = filtered
+		results = append(results, result)
+	}
+
+	// A canceled call, such as the server shutting down, keeps what it
+	// synced but leaves the steps that act on every feed to the next sync
+	if ctx.Err() != nil {
+		run.Finish(time.Now())
+		_ = feedsync.SaveRun(pc.runPath, run)
+		return nil, fmt.Errorf("sync interrupted after %d of %d feed(s): %w",
+			report.Attempted(), report.Attempted()+report.Interrupted, ctx.Err())
 	}
 
-	// Sync each feed
-	results := make([]SyncResult, 0, len(feeds))
-	totalNew := 0
-	totalCached := 0
-	totalErrors := 0
+	s.raiseAlerts(ctx, pc, report.Failed, report.Attempted())
+	s.deliverEntries(ctx, pc)
+	s.generateDigest(ctx, pc)
 
-	for _, feed := range feeds {
-		result := SyncResult{
-			FeedID: feed.ID,
-			FeedTitle: func() string {
-				if feed.Title != nil {
-					return *feed.Title
-				}
-				return feed.URL
-			}(),
+	output := SyncFeedsOutput{
+		Results:      results,
+		TotalFeeds:   len(report.Outcomes),
+		TotalNew:     report.New,
+		TotalCached:  report.Cached,
+		TotalSkipped: report.Skipped,
+		TotalErrors:  report.Errors,
+	}
+	if cache := report.Cache; cache != (fetch.CacheStats{}) {
+		output.Cache = &CacheStats{
+			DNSHits:      cache.DNSHits,
+			DNSLookups:   cache.DNSHits + cache.DNSMisses,
+			BodyHits:     cache.BodyHits,
+			BodyRequests: cache.BodyHits + cache.BodyMisses,
 		}
+	}
 
-		newCount, wasCached, err := s.syncFeed(ctx, pc.store, feed, force)
+	// Archive dead feeds only on full syncs, so every feed had its chance
+	if input.URL == nil {
+		inactive, err := feedsync.FindInactive(ctx, pc.store, pc.cfg.GetInactiveAfter(), now)
 		if err != nil {
-			errMsg := err.Error()
-			result.Error = &errMsg
-			totalErrors++
-		} else {
-			result.NewEntries = newCount
-			result.WasCached = wasCached
-			totalNew += newCount
-			if wasCached {
-				totalCached++
+			return nil, err
+		}
+		for _, f := range inactive {
+			if err := feedsync.ArchiveFeed(ctx, pc.store, f.Feed, now); err != nil {
+				return nil, err
 			}
+			output.Archived = append(output.Archived, ArchivedFeed{
+				FeedID:    f.Feed.ID,
+				FeedTitle: f.Feed.GetDisplayName(),
+				URL:       f.Feed.URL,
+				Reason:    f.Reason,
+			})
 		}
+	}
 
-		results = append(results, result)
+	scorePolicy, refreshScores, err := pc.cfg.GetScorePolicy()
+	if err != nil {
+		return nil, err
+	}
+	if refreshScores {
+		scored, err := score.Refresh(ctx, pc.store, score.New(), scorePolicy, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		output.ScoresRefreshed = scored.Updated
 	}
 
-	output := SyncFeedsOutput{
-		Results:     results,
-		TotalFeeds:  len(feeds),
-		TotalNew:    totalNew,
-		TotalCached: totalCached,
-		TotalErrors: totalErrors,
+	pc.opmlMu.RLock()
+	budgets := feedsync.Budgets(pc.opmlDoc, pc.cfg.UnreadBudgets)
+	pc.opmlMu.RUnlock()
+	trimmed, err := feedsync.EnforceBudgets(ctx, pc.store, budgets)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range trimmed {
+		trim := BudgetTrim{Folder: b.Folder, Budget: b.Max, UnreadBefore: b.Unread}
+		for _, e := range b.Marked {
+			trim.MarkedRead = append(trim.MarkedRead, e.ID)
+		}
+		output.Budgets = append(output.Budgets, trim)
 	}
 
+	run.Archived = len(output.Archived)
+	run.Finish(time.Now())
+	// The summary only feeds digest://sync/last; failing to save it
+	// shouldn't fail a sync that worked
+	_ = feedsync.SaveRun(pc.runPath, run)
+	if runner, err := s.hookRunner(pc); err == nil {
+		runner.PostSync(ctx, run)
+	}
+	pc.refreshUnreadCounts(ctx)
+
 	jsonBytes, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal output: %w", err)
@@ -821,7 +1658,7 @@ func (s *Server) handleSyncFeeds(ctx context.Context, req mcp.CallToolRequest) (
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-func (s *Server) handleListEntries(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleListEntries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -832,21 +1669,9 @@ func (s *Server) handleListEntries(_ context.Context, req mcp.CallToolRequest) (
 		return nil, fmt.Errorf("invalid input: %w", err)
 	}
 
-	// Parse since/until date strings
-	var since, until *time.Time
-	if input.Since != nil {
-		t, err := parseDateString(*input.Since)
-		if err != nil {
-			return nil, fmt.Errorf("invalid since value: %w", err)
-		}
-		since = &t
-	}
-	if input.Until != nil {
-		t, err := parseDateString(*input.Until)
-		if err != nil {
-			return nil, fmt.Errorf("invalid until value: %w", err)
-		}
-		until = &t
+	query, err := s.parseEntryQuery(ctx, pc, input.EntryQueryInput)
+	if err != nil {
+		return nil, err
 	}
 	if input.Offset != nil && *input.Offset < 0 {
 		return nil, fmt.Errorf("offset must be non-negative, got %d", *input.Offset)
@@ -854,66 +1679,113 @@ func (s *Server) handleListEntries(_ context.Context, req mcp.CallToolRequest) (
 	if input.Limit != nil && *input.Limit < 0 {
 		return nil, fmt.Errorf("limit must be non-negative, got %d", *input.Limit)
 	}
+	markRead := pc.cfg.ListMarksRead && !s.readOnly
+	if input.MarkRead != nil {
+		markRead = *input.MarkRead
+	}
+	if markRead && s.readOnly {
+		return nil, fmt.Errorf("mark_read is not available: this server is read-only")
+	}
+	fields, err := checkEntryFields(input.Fields)
+	if err != nil {
+		return nil, err
+	}
 
-	// Build filter and list entries
-	filter := &storage.EntryFilter{
-		FeedID:     input.FeedID,
-		UnreadOnly: input.UnreadOnly,
-		Since:      since,
-		Until:      until,
-		Limit:      input.Limit,
-		Offset:     input.Offset,
+	// Listings never show content, so it's left in the store; get_entry
+	// reads it. Fuzzy author matching happens after the query, so paging
+	// does too.
+	filter := query.filter
+	filter.ByScore = input.ByScore
+	filter.ByPriority = filter.UnreadOnly != nil && *filter.UnreadOnly
+	authorFilter := query.author != ""
+	if !authorFilter {
+		filter.Limit = input.Limit
+		filter.Offset = input.Offset
 	}
 
-	entries, err := pc.store.ListEntries(filter)
+	entries, err := pc.store.ListEntries(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list entries: %w", err)
 	}
+	if authorFilter {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if authors.MatchEntry(query.author, entry) {
+				kept = append(kept, entry)
+			}
+		}
+		entries = kept
+		if input.Offset != nil {
+			entries = entries[min(*input.Offset, len(entries)):]
+		}
+		if input.Limit != nil && *input.Limit > 0 && len(entries) > *input.Limit {
+			entries = entries[:*input.Limit]
+		}
+	}
 
 	// Build output
 	entryOutputs := make([]EntryOutput, 0, len(entries))
 	for _, entry := range entries {
 		entryOutputs = append(entryOutputs, EntryOutput{
-			ID:          entry.ID,
-			FeedID:      entry.FeedID,
-			Title:       entry.Title,
-			Link:        entry.Link,
-			Author:      entry.Author,
-			PublishedAt: entry.PublishedAt,
-			Read:        entry.Read,
-			ReadAt:      entry.ReadAt,
-			CreatedAt:   entry.CreatedAt,
+			ID:            entry.ID,
+			FeedID:        entry.FeedID,
+			Title:         entry.Title,
+			Link:          entry.Link,
+			Author:        entry.Author,
+			PublishedAt:   entry.PublishedAt,
+			Read:          entry.Read,
+			ReadAt:        entry.ReadAt,
+			ArchiveURL:    entry.ArchiveURL,
+			ImageURL:      entry.ImageURL,
+			DiscussionURL: entry.DiscussionURL,
+			Score:         entry.Score,
+			CommentCount:  entry.CommentCount,
+			KeepUnread:    entry.KeepUnread,
+			CreatedAt:     entry.CreatedAt,
 		})
 	}
 
 	// Build applied filters
-	filters := make(map[string]any)
-	if input.FeedID != nil {
-		filters["feed_id"] = *input.FeedID
-	}
-	if input.UnreadOnly != nil {
-		filters["unread_only"] = *input.UnreadOnly
-	}
-	if since != nil {
-		filters["since"] = *since
-	}
-	if until != nil {
-		filters["until"] = *until
-	}
+	filters := query.filters
 	if input.Limit != nil {
 		filters["limit"] = *input.Limit
 	}
 	if input.Offset != nil {
 		filters["offset"] = *input.Offset
 	}
+	if input.ByScore {
+		filters["by_score"] = true
+	}
+	if len(fields) > 0 {
+		filters["fields"] = fields
+	}
 
 	output := ListEntriesOutput{
 		Entries: entryOutputs,
 		Count:   len(entryOutputs),
 		Filters: filters,
 	}
+	if markRead {
+		marked, err := s.markListedRead(ctx, pc.store, entries)
+		if err != nil {
+			return nil, err
+		}
+		pc.refreshUnreadCounts(ctx)
+		output.MarkedRead = marked
+	}
 
-	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	var body any = output
+	if len(fields) > 0 {
+		projected, err := projectEntries(output.Entries, fields)
+		if err != nil {
+			return nil, err
+		}
+		body = struct {
+			ListEntriesOutput
+			Entries []map[string]json.RawMessage `json:"entries"`
+		}{output, projected}
+	}
+	jsonBytes, err := json.MarshalIndent(body, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal output: %w", err)
 	}
@@ -921,7 +1793,7 @@ func (s *Server) handleListEntries(_ context.Context, req mcp.CallToolRequest) (
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-func (s *Server) handleGetEntry(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleGetEntry(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -933,17 +1805,17 @@ func (s *Server) handleGetEntry(_ context.Context, req mcp.CallToolRequest) (*mc
 	}
 
 	// Get entry by ID or prefix
-	entry, err := pc.store.GetEntry(input.EntryID)
+	entry, err := pc.store.GetEntry(ctx, input.EntryID)
 	if err != nil {
 		// Try prefix match
-		entry, err = pc.store.GetEntryByPrefix(input.EntryID)
+		entry, err = pc.store.GetEntryByPrefix(ctx, input.EntryID)
 		if err != nil {
 			return nil, fmt.Errorf("entry not found: %s", input.EntryID)
 		}
 	}
 
 	// Get feed for context
-	feed, err := pc.store.GetFeed(entry.FeedID)
+	feed, err := pc.store.GetFeed(ctx, entry.FeedID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feed: %w", err)
 	}
@@ -953,25 +1825,49 @@ func (s *Server) handleGetEntry(_ context.Context, req mcp.CallToolRequest) (*mc
 		feedTitle = *feed.Title
 	}
 
-	// Convert content to markdown if HTML
-	var contentPtr *string
-	if entry.Content != nil && *entry.Content != "" {
-		markdown := content.ToMarkdown(*entry.Content)
-		contentPtr = &markdown
-	}
-
 	output := GetEntryOutput{
-		ID:          entry.ID,
-		FeedID:      entry.FeedID,
-		FeedTitle:   feedTitle,
-		Title:       entry.Title,
-		Link:        entry.Link,
-		Author:      entry.Author,
-		PublishedAt: entry.PublishedAt,
-		Content:     contentPtr,
-		Read:        entry.Read,
-		ReadAt:      entry.ReadAt,
-		CreatedAt:   entry.CreatedAt,
+		ID:                 entry.ID,
+		FeedID:             entry.FeedID,
+		FeedTitle:          feedTitle,
+		Title:              entry.Title,
+		Link:               entry.Link,
+		Author:             entry.Author,
+		PublishedAt:        entry.PublishedAt,
+		ClaimedPublishedAt: entry.ClaimedPublishedAt,
+		Read:               entry.Read,
+		ReadAt:             entry.ReadAt,
+		ArchiveURL:         entry.ArchiveURL,
+		ImageURL:           entry.ImageURL,
+		ImagePath:          thumbnail.Path(pc.thumbDir, entry.ID),
+		DiscussionURL:      entry.DiscussionURL,
+		CommentsFeedURL:    entry.CommentsFeedURL,
+		Score:              entry.Score,
+		CommentCount:       entry.CommentCount,
+		ScoredAt:           entry.ScoredAt,
+		KeepUnread:         entry.KeepUnread,
+		CreatedAt:          entry.CreatedAt,
+		Extensions:         entry.Extensions,
+	}
+
+	format := content.FormatMarkdown
+	if input.Format != nil && *input.Format != "" {
+		format = *input.Format
+	}
+
+	// Convert content to the requested format, then cut it down to what was asked for
+	if entry.Content != nil && *entry.Content != "" {
+		opts := pc.cfg.GetContentOptions()
+		if input.ReaderView != nil {
+			opts.ReaderView = *input.ReaderView
+		}
+		rendered, err := content.Render(*entry.Content, format, opts)
+		if err != nil {
+			return nil, err
+		}
+		output.Format = format
+		if err := selectEntryContent(&output, rendered, format, input); err != nil {
+			return nil, err
+		}
 	}
 
 	jsonBytes, err := json.MarshalIndent(output, "", "  ")
@@ -982,7 +1878,7 @@ func (s *Server) handleGetEntry(_ context.Context, req mcp.CallToolRequest) (*mc
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-func (s *Server) handleMarkRead(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleMarkRead(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -994,31 +1890,46 @@ func (s *Server) handleMarkRead(_ context.Context, req mcp.CallToolRequest) (*mc
 	}
 
 	// Verify entry exists
-	if _, err := pc.store.GetEntry(input.EntryID); err != nil {
+	existing, err := pc.store.GetEntry(ctx, input.EntryID)
+	if err != nil {
 		return nil, fmt.Errorf("entry not found: %s", input.EntryID)
 	}
 
+	// Marking a kept entry read on purpose releases it
+	if existing.KeepUnread {
+		if err := pc.store.SetEntryKeepUnread(ctx, input.EntryID, false); err != nil {
+			return nil, fmt.Errorf("failed to release entry: %w", err)
+		}
+	}
+
 	// Mark as read
-	if err := pc.store.MarkEntryRead(input.EntryID); err != nil {
+	if err := pc.store.MarkEntryRead(ctx, input.EntryID); err != nil {
 		return nil, fmt.Errorf("failed to mark entry as read: %w", err)
 	}
+	pc.refreshUnreadCounts(ctx)
 
 	// Reload entry to get updated read_at
-	entry, err := pc.store.GetEntry(input.EntryID)
+	entry, err := pc.store.GetEntry(ctx, input.EntryID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to reload entry: %w", err)
 	}
 
 	output := EntryOutput{
-		ID:          entry.ID,
-		FeedID:      entry.FeedID,
-		Title:       entry.Title,
-		Link:        entry.Link,
-		Author:      entry.Author,
-		PublishedAt: entry.PublishedAt,
-		Read:        entry.Read,
-		ReadAt:      entry.ReadAt,
-		CreatedAt:   entry.CreatedAt,
+		ID:            entry.ID,
+		FeedID:        entry.FeedID,
+		Title:         entry.Title,
+		Link:          entry.Link,
+		Author:        entry.Author,
+		PublishedAt:   entry.PublishedAt,
+		Read:          entry.Read,
+		ReadAt:        entry.ReadAt,
+		ArchiveURL:    entry.ArchiveURL,
+		ImageURL:      entry.ImageURL,
+		DiscussionURL: entry.DiscussionURL,
+		Score:         entry.Score,
+		CommentCount:  entry.CommentCount,
+		KeepUnread:    entry.KeepUnread,
+		CreatedAt:     entry.CreatedAt,
 	}
 
 	jsonBytes, err := json.MarshalIndent(output, "", "  ")
@@ -1029,7 +1940,7 @@ func (s *Server) handleMarkRead(_ context.Context, req mcp.CallToolRequest) (*mc
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-func (s *Server) handleMarkUnread(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleMarkUnread(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -1041,31 +1952,38 @@ func (s *Server) handleMarkUnread(_ context.Context, req mcp.CallToolRequest) (*
 	}
 
 	// Verify entry exists
-	if _, err := pc.store.GetEntry(input.EntryID); err != nil {
+	if _, err := pc.store.GetEntry(ctx, input.EntryID); err != nil {
 		return nil, fmt.Errorf("entry not found: %s", input.EntryID)
 	}
 
 	// Mark as unread
-	if err := pc.store.MarkEntryUnread(input.EntryID); err != nil {
+	if err := pc.store.MarkEntryUnread(ctx, input.EntryID); err != nil {
 		return nil, fmt.Errorf("failed to mark entry as unread: %w", err)
 	}
+	pc.refreshUnreadCounts(ctx)
 
 	// Reload entry to get updated state
-	entry, err := pc.store.GetEntry(input.EntryID)
+	entry, err := pc.store.GetEntry(ctx, input.EntryID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to reload entry: %w", err)
 	}
 
 	output := EntryOutput{
-		ID:          entry.ID,
-		FeedID:      entry.FeedID,
-		Title:       entry.Title,
-		Link:        entry.Link,
-		Author:      entry.Author,
-		PublishedAt: entry.PublishedAt,
-		Read:        entry.Read,
-		ReadAt:      entry.ReadAt,
-		CreatedAt:   entry.CreatedAt,
+		ID:            entry.ID,
+		FeedID:        entry.FeedID,
+		Title:         entry.Title,
+		Link:          entry.Link,
+		Author:        entry.Author,
+		PublishedAt:   entry.PublishedAt,
+		Read:          entry.Read,
+		ReadAt:        entry.ReadAt,
+		ArchiveURL:    entry.ArchiveURL,
+		ImageURL:      entry.ImageURL,
+		DiscussionURL: entry.DiscussionURL,
+		Score:         entry.Score,
+		CommentCount:  entry.CommentCount,
+		KeepUnread:    entry.KeepUnread,
+		CreatedAt:     entry.CreatedAt,
 	}
 
 	jsonBytes, err := json.MarshalIndent(output, "", "  ")
@@ -1076,92 +1994,95 @@ func (s *Server) handleMarkUnread(_ context.Context, req mcp.CallToolRequest) (*
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-// syncFeed is a helper that fetches and processes a single feed
-// Returns (newCount, wasCached, error)
-func (s *Server) syncFeed(ctx context.Context, store storage.Store, feed *models.Feed, force bool) (int, bool, error) {
-	// Get cache headers from feed (skip if force)
-	var etag, lastModified *string
-	if !force {
-		etag = feed.ETag
-		lastModified = feed.LastModified
+// raiseAlerts sends a failing-feeds alert if the sync warrants one. Stdout
+// carries the MCP protocol, so delivery problems go to stderr.
+func (s *Server) raiseAlerts(ctx context.Context, pc *profileContext, failedIDs []string, attempted int) {
+	cfg := pc.cfg.GetAlerts()
+	a, err := alert.Check(ctx, pc.store, cfg, pc.name, failedIDs, attempted)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not check alerts: %v\n", err)
+		return
+	}
+	if a == nil {
+		return
 	}
+	if err := alert.Send(ctx, cfg, a); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not send alert: %v\n", err)
+	}
+}
 
-	// Fetch the feed
-	result, err := fetch.Fetch(ctx, feed.URL, etag, lastModified, feed.LocalNetwork)
+// deliverEntries posts new entries to the delivery targets after a sync,
+// across the whole profile whatever the server's scope. Stdout carries the
+// MCP protocol, so delivery problems go to stderr.
+func (s *Server) deliverEntries(ctx context.Context, pc *profileContext) {
+	cfg := pc.cfg.GetDeliver()
+	if len(cfg.Targets) == 0 {
+		return
+	}
+	loc, err := pc.cfg.GetLocation()
 	if err != nil {
-		// Update error state
-		if updateErr := store.UpdateFeedError(feed.ID, err.Error()); updateErr != nil {
-			return 0, false, fmt.Errorf("fetch failed (%v) and error update failed: %w", err, updateErr)
+		loc = time.Local
+	}
+	pc.opmlMu.RLock()
+	src, err := deliver.Collect(ctx, pc.unscopedStore(), pc.opmlDoc)
+	pc.opmlMu.RUnlock()
+	if err == nil {
+		var results []deliver.Result
+		results, err = deliver.Run(ctx, cfg, src, pc.deliverPath, time.Now().In(loc))
+		if err == nil {
+			err = deliver.Errors(results)
 		}
-		return 0, false, err
 	}
-
-	// Handle 304 Not Modified
-	if result.NotModified {
-		return 0, true, nil
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not deliver entries: %v\n", err)
 	}
+}
 
-	// Parse the feed
-	parsed, err := parse.Parse(result.Body)
+// generateDigest writes the day's digest after a sync once it's due,
+// across the whole profile whatever the server's scope. Problems go to
+// stderr.
+func (s *Server) generateDigest(ctx context.Context, pc *profileContext) {
+	dcfg, on := pc.cfg.GetDigests()
+	if !on {
+		return
+	}
+	loc, err := pc.cfg.GetLocation()
 	if err != nil {
-		errMsg := fmt.Sprintf("failed to parse feed: %v", err)
-		if updateErr := store.UpdateFeedError(feed.ID, errMsg); updateErr != nil {
-			return 0, false, fmt.Errorf("parse failed (%v) and error update failed: %w", err, updateErr)
-		}
-		return 0, false, fmt.Errorf("failed to parse feed: %w", err)
+		loc = time.Local
 	}
-
-	// Update feed title if empty and persist
-	titleUpdated := false
-	if feed.Title == nil || *feed.Title == "" {
-		feed.Title = &parsed.Title
-		titleUpdated = true
+	tmpl, err := render.Load(config.TemplatesDir(), dcfg.TemplateName())
+	if err == nil {
+		pc.opmlMu.RLock()
+		g := &digests.Generator{Dir: pc.digestsDir, Store: pc.unscopedStore(), OPML: pc.opmlDoc, Config: dcfg, Template: tmpl}
+		_, _, err = g.GenerateDue(ctx, time.Now().In(loc))
+		pc.opmlMu.RUnlock()
 	}
-
-	// Process entries
-	newCount := 0
-	for _, parsedEntry := range parsed.Entries {
-		// Check if entry already exists
-		exists, err := store.EntryExists(feed.ID, parsedEntry.GUID)
-		if err != nil {
-			return newCount, false, fmt.Errorf("failed to check entry existence: %w", err)
-		}
-
-		if exists {
-			continue
-		}
-
-		// Create new entry
-		entry := storage.NewEntry(feed.ID, parsedEntry.GUID, parsedEntry.Title)
-		entry.Link = &parsedEntry.Link
-		entry.Author = &parsedEntry.Author
-		entry.PublishedAt = parsedEntry.PublishedAt
-		entry.Content = &parsedEntry.Content
-
-		if err := store.CreateEntry(entry); err != nil {
-			return newCount, false, fmt.Errorf("failed to create entry: %w", err)
-		}
-
-		newCount++
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not generate digest: %v\n", err)
 	}
+}
 
-	// Update feed fetch state
-	fetchedAt := time.Now()
-	if err := store.UpdateFeedFetchState(feed.ID, &result.ETag, &result.LastModified, fetchedAt); err != nil {
-		return newCount, false, fmt.Errorf("failed to update feed state: %w", err)
+// syncOptions returns the options the profile's feeds share during one
+// sync; each feed adds its own Secrets.
+func (s *Server) syncOptions(pc *profileContext) (feedsync.Options, error) {
+	runner, err := s.hookRunner(pc)
+	if err != nil {
+		return feedsync.Options{}, err
 	}
+	return pc.cfg.GetSyncOptions(pc.thumbDir, pc.snapDir, runner, os.Stderr)
+}
 
-	// If title was updated, persist
-	if titleUpdated {
-		if err := store.UpdateFeed(feed); err != nil {
-			return newCount, false, fmt.Errorf("failed to update feed title: %w", err)
-		}
+// hookRunner returns the runner of the profile's hooks. Failures of hooks
+// that can't stop a tool call go to stderr, like other background warnings.
+func (s *Server) hookRunner(pc *profileContext) (*hooks.Runner, error) {
+	conf, err := pc.cfg.GetHooks()
+	if err != nil {
+		return nil, err
 	}
-
-	return newCount, false, nil
+	return &hooks.Runner{Config: conf, Profile: pc.name, Warn: os.Stderr}, nil
 }
 
-func (s *Server) handleBulkMarkRead(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleBulkMarkRead(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pc, err := s.getProfile(extractProfile(req))
 	if err != nil {
 		return nil, err
@@ -1173,16 +2094,32 @@ func (s *Server) handleBulkMarkRead(_ context.Context, req mcp.CallToolRequest)
 	}
 
 	// Parse the before date
-	cutoff, err := parseDateString(input.Before)
+	loc, err := pc.location(input.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	cutoff, err := parseDateString(input.Before, loc)
 	if err != nil {
 		return nil, fmt.Errorf("invalid before value: %w", err)
 	}
 
+	// Refuse sweeping calls up front rather than marking a partial batch
+	if limit := s.limits.BulkMarkReadMax; limit >= 0 {
+		pending, err := countUnreadBefore(ctx, pc.store, cutoff, limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count unread entries: %w", err)
+		}
+		if pending > limit {
+			return nil, fmt.Errorf("bulk_mark_read would mark more than the limit of %d entries per call; use an earlier before date, mark entries individually, or ask the user to raise mcp_limits in config.json", limit)
+		}
+	}
+
 	// Mark entries as read
-	count, err := pc.store.MarkEntriesReadBefore(cutoff)
+	count, err := pc.store.MarkEntriesReadBefore(ctx, cutoff)
 	if err != nil {
 		return nil, fmt.Errorf("failed to mark entries as read: %w", err)
 	}
+	pc.refreshUnreadCounts(ctx)
 
 	output := BulkMarkReadOutput{
 		Count:  count,
@@ -1203,24 +2140,125 @@ func (s *Server) handleBulkMarkRead(_ context.Context, req mcp.CallToolRequest)
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
 
-// parseDateString parses a date string that can be a period name or ISO date.
-func parseDateString(s string) (time.Time, error) {
-	// Try period name first
-	if t, ok := timeutil.ParsePeriod(s); ok {
-		return t, nil
+// markListedRead marks the unread entries list_entries returned as read,
+// except those kept unread.
+// It refuses a page with more unread entries than bulk_mark_read may mark,
+// before marking any, so an unbounded listing can't clear the whole store.
+func (s *Server) markListedRead(ctx context.Context, store storage.Store, entries []*models.Entry) (int, error) {
+	var unread []*models.Entry
+	for _, entry := range entries {
+		if !entry.Read && !entry.KeepUnread {
+			unread = append(unread, entry)
+		}
+	}
+	if limit := s.limits.BulkMarkReadMax; limit >= 0 && len(unread) > limit {
+		return 0, fmt.Errorf("mark_read would mark %d entries, more than the limit of %d per call; pass a smaller limit, or ask the user to raise mcp_limits in config.json", len(unread), limit)
+	}
+	for _, entry := range unread {
+		if err := store.MarkEntryRead(ctx, entry.ID); err != nil {
+			return 0, fmt.Errorf("failed to mark entry as read: %w", err)
+		}
+	}
+	return len(unread), nil
+}
+
+// errCounted stops an entry walk once a count has its answer.
+var errCounted = errors.New("counted enough entries")
+
+// countUnreadBefore counts the unread entries published before cutoff that
+// bulk_mark_read would mark, stopping at most. It walks entries without
+// their content, so a large backlog costs no memory.
+func countUnreadBefore(ctx context.Context, store storage.Store, cutoff time.Time, most int) (int, error) {
+	unread := true
+	count := 0
+	err := store.EachEntry(ctx, &storage.EntryFilter{UnreadOnly: &unread, Until: &cutoff, NoContent: true}, func(e *models.Entry) error {
+		if e.KeepUnread {
+			return nil
+		}
+		if count++; count >= most {
+			return errCounted
+		}
+		return nil
+	})
+	if errors.Is(err, errCounted) {
+		err = nil
+	}
+	return count, err
+}
+
+// selectEntryContent narrows rendered content to the section, paragraphs,
+// and character window requested in input and records it on output.
+func selectEntryContent(output *GetEntryOutput, markdown, format string, input GetEntryInput) error {
+	if input.Section != nil && *input.Section != "" {
+		if format != content.FormatMarkdown {
+			return fmt.Errorf("section requires markdown format")
+		}
+		section, ok := content.Section(markdown, *input.Section)
+		if !ok {
+			return fmt.Errorf("no section matching %q in entry %s", *input.Section, output.ID)
+		}
+		markdown = section
+		output.Truncated = true
+	}
+	if input.Paragraphs != nil {
+		if *input.Paragraphs <= 0 {
+			return fmt.Errorf("paragraphs must be positive")
+		}
+		var cut bool
+		markdown, cut = content.Paragraphs(markdown, *input.Paragraphs)
+		output.Truncated = output.Truncated || cut
 	}
 
-	// Try ISO date format
-	if t, err := time.Parse("2006-01-02", s); err == nil {
-		return t, nil
+	maxLength := defaultEntryMaxLength
+	if input.MaxLength != nil {
+		if *input.MaxLength < 0 {
+			return fmt.Errorf("max_length must not be negative")
+		}
+		maxLength = *input.MaxLength
+	}
+	offset := 0
+	if input.Offset != nil {
+		if *input.Offset < 0 {
+			return fmt.Errorf("offset must not be negative")
+		}
+		offset = *input.Offset
 	}
 
-	// Try RFC3339
-	if t, err := time.Parse(time.RFC3339, s); err == nil {
-		return t, nil
+	chunk, next := content.Window(markdown, offset, maxLength)
+	total := utf8.RuneCountInString(markdown)
+	output.Content = &chunk
+	output.TotalLength = total
+	if next < total {
+		output.Truncated = true
+		output.NextOffset = &next
+	}
+	if offset > 0 {
+		output.Truncated = true
 	}
+	return nil
+}
+
+// parseDateString parses a date expression: a period name, ISO date, or
+// relative phrase like "3 days ago". Periods and plain dates start at
+// midnight in loc.
+func parseDateString(s string, loc *time.Location) (time.Time, error) {
+	return timeutil.ParseDate(s, time.Now().In(loc))
+}
 
-	return time.Time{}, fmt.Errorf("cannot parse date: use yesterday, week, month, today, or YYYY-MM-DD format")
+// parseSinceRange parses a since value, which may also be a range
+// "from..until". until is nil unless the range closes.
+func parseSinceRange(s string, loc *time.Location) (since, until *time.Time, err error) {
+	r, err := timeutil.ParseRange(s, time.Now().In(loc))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !r.Since.IsZero() {
+		since = &r.Since
+	}
+	if !r.Until.IsZero() {
+		until = &r.Until
+	}
+	return since, until, nil
 }
 
 // formatFolder returns a human-readable folder name for messages
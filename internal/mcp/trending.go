@@ -0,0 +1,160 @@
+// ABOUTME: trending_topics tool that surfaces the keywords and names spiking across recent entries
+// ABOUTME: Runs TF-IDF over a date range against earlier entries, overall and per folder
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/harper/digest/internal/trends"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultTrendingLimit is how many topics are returned when limit isn't given.
+const defaultTrendingLimit = 10
+
+type TrendingTopicsInput struct {
+	Since    *string `json:"since,omitempty"`
+	Until    *string `json:"until,omitempty"`
+	Timezone *string `json:"tz,omitempty"`
+	Folder   *string `json:"folder,omitempty"`
+	Limit    *int    `json:"limit,omitempty"`
+	ByFolder *bool   `json:"by_folder,omitempty"`
+}
+
+type TrendingTopicsOutput struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	trends.Report
+}
+
+func (s *Server) registerTrendingTopicsTool() {
+	tool := mcp.Tool{
+		Name:        "trending_topics",
+		Description: "Find the keywords and named entities trending across entries in a date range. Terms are scored with TF-IDF against the entries from the four preceding periods, so perennial words rank low and new ones rank high. Only terms mentioned by at least two entries are reported. Includes a per-folder breakdown and example entry IDs for each topic. Use this for the 'Trending Topics' section of a digest.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"since": map[string]interface{}{
+					"type":        "string",
+					"description": "Start of the range: 'today', 'yesterday', 'week', 'month', 'last week', YYYY-MM-DD, a relative date like '3 days ago', or a whole range 'from..until'. Default: 'week'.",
+				},
+				"until": map[string]interface{}{
+					"type":        "string",
+					"description": "End of the range (exclusive), same formats as since. Default: now.",
+				},
+				"tz": tzProperty,
+				"folder": map[string]interface{}{
+					"type":        "string",
+					"description": "Only analyze feeds in this folder.",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Topics to return overall and per folder. Default: 10.",
+				},
+				"by_folder": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Include a per-folder breakdown. Default: true unless folder is set.",
+				},
+				"profile": profileProperty,
+			},
+		},
+	}
+	s.mcpServer.AddTool(tool, s.handleTrendingTopics)
+}
+
+func (s *Server) handleTrendingTopics(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pc, err := s.getProfile(extractProfile(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var input TrendingTopicsInput
+	if err := req.BindArguments(&input); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	sinceValue := "week"
+	if input.Since != nil {
+		sinceValue = *input.Since
+	}
+	loc, err := pc.location(input.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	sinceAt, untilAt, err := parseSinceRange(sinceValue, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since value: %w", err)
+	}
+	if sinceAt == nil {
+		return nil, fmt.Errorf("invalid since value: the range needs a start")
+	}
+	since := *sinceAt
+	until := time.Now()
+	if untilAt != nil {
+		if input.Until != nil {
+			return nil, fmt.Errorf("since is a range, so until must be omitted")
+		}
+		until = *untilAt
+	}
+	if input.Until != nil {
+		until, err = parseDateString(*input.Until, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until value: %w", err)
+		}
+	}
+	if !until.After(since) {
+		return nil, fmt.Errorf("until must be after since")
+	}
+
+	opts := trends.Options{Limit: defaultTrendingLimit, ByFolder: input.Folder == nil}
+	if input.Limit != nil {
+		if *input.Limit <= 0 {
+			return nil, fmt.Errorf("limit must be positive, got %d", *input.Limit)
+		}
+		opts.Limit = *input.Limit
+	}
+	if input.ByFolder != nil {
+		opts.ByFolder = *input.ByFolder
+	}
+
+	report, err := s.trendingReport(ctx, pc, since, until, input.Folder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := json.MarshalIndent(TrendingTopicsOutput{Since: since, Until: until, Report: report}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// trendingReport collects a profile's entries and analyzes them, optionally
+// restricted to one folder.
+func (s *Server) trendingReport(ctx context.Context, pc *profileContext, since, until time.Time, folder *string, opts trends.Options) (trends.Report, error) {
+	pc.opmlMu.RLock()
+	folders := make(map[string]string)
+	for _, feed := range pc.opmlDoc.AllFeeds() {
+		folders[feed.URL] = feed.Folder
+	}
+	pc.opmlMu.RUnlock()
+
+	docs, err := trends.Collect(ctx, pc.store, func(url string) string { return folders[url] }, since, until)
+	if err != nil {
+		return trends.Report{}, err
+	}
+	if folder != nil {
+		kept := docs[:0]
+		for _, doc := range docs {
+			if doc.Folder == *folder {
+				kept = append(kept, doc)
+			}
+		}
+		docs = kept
+	}
+	return trends.Analyze(docs, opts), nil
+}
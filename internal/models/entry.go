@@ -4,6 +4,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,7 +24,59 @@ type Entry struct {
 	Content     *string
 	Read        bool
 	ReadAt      *time.Time
+	ArchiveURL  *string
 	CreatedAt   time.Time
+	// ClaimedPublishedAt is the publish date the feed gave when it was
+	// implausible and PublishedAt was replaced; nil otherwise.
+	ClaimedPublishedAt *time.Time
+	// UpdatedAt is when the store last wrote the entry or its read state.
+	// Stores set it; it is zero for entries untouched since before changes
+	// were tracked.
+	UpdatedAt time.Time
+	// ImageURL is the entry's lead image: the feed's media image, or else
+	// the first image in its content.
+	ImageURL *string
+	// DiscussionURL is the web page of the entry's comment thread, such as
+	// its Hacker News item.
+	DiscussionURL *string
+	// CommentsFeedURL is a feed of the entry's comments.
+	CommentsFeedURL *string
+	// Score and CommentCount are the entry's points and comment count on
+	// the aggregator it came from, such as Hacker News or Lobsters, as of
+	// ScoredAt. They are nil for entries that aren't aggregator posts.
+	Score        *int
+	CommentCount *int
+	ScoredAt     *time.Time
+	// KeepUnread pins the entry unread: bulk marking, pruning, and marking
+	// on read, open, or list skip it until it's marked read explicitly.
+	KeepUnread bool
+	// Extensions are the item's namespaced elements (dc:, media:, itunes:,
+	// and any other namespace) as the feed gave them, or nil.
+	Extensions Extensions
+	// ContentHash fingerprints the entry's content, so a feed republishing
+	// the same post under a new GUID can be recognized.
+	ContentHash string
+}
+
+// Extensions holds feed extension elements by namespace prefix, then by
+// element name, such as Extensions["itunes"]["duration"].
+type Extensions map[string]map[string][]Extension
+
+// Extension is one extension element with its text, attributes, and
+// nested elements.
+type Extension struct {
+	Name     string                 `json:"name" yaml:"name"`
+	Value    string                 `json:"value,omitempty" yaml:"value,omitempty"`
+	Attrs    map[string]string      `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+	Children map[string][]Extension `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// Get returns the first prefix:name element, and false if there is none.
+func (x Extensions) Get(prefix, name string) (Extension, bool) {
+	if elems := x[prefix][name]; len(elems) > 0 {
+		return elems[0], true
+	}
+	return Extension{}, false
 }
 
 // NewEntry creates a new Entry with the given feedID, guid, and title
@@ -61,3 +114,49 @@ func (e *Entry) GetTitle() string {
 	}
 	return DefaultEntryTitle
 }
+
+// TagPrefix and TagName are where an entry's tags are kept among its
+// extensions, as Extensions["digest"]["tag"] elements.
+const (
+	TagPrefix = "digest"
+	TagName   = "tag"
+)
+
+// Tags returns the tags ingest scripts gave the entry.
+func (e *Entry) Tags() []string {
+	var tags []string
+	for _, x := range e.Extensions[TagPrefix][TagName] {
+		tags = append(tags, x.Value)
+	}
+	return tags
+}
+
+// SetTags replaces the entry's tags, dropping blanks and repeats.
+func (e *Entry) SetTags(tags []string) {
+	var elems []Extension
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		elems = append(elems, Extension{Name: TagName, Value: t})
+	}
+	if len(elems) == 0 {
+		if e.Extensions[TagPrefix] != nil {
+			delete(e.Extensions[TagPrefix], TagName)
+			if len(e.Extensions[TagPrefix]) == 0 {
+				delete(e.Extensions, TagPrefix)
+			}
+		}
+		return
+	}
+	if e.Extensions == nil {
+		e.Extensions = Extensions{}
+	}
+	if e.Extensions[TagPrefix] == nil {
+		e.Extensions[TagPrefix] = map[string][]Extension{}
+	}
+	e.Extensions[TagPrefix][TagName] = elems
+}
@@ -6,6 +6,7 @@ package models
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,19 +14,101 @@ import (
 
 const DefaultFeedTitle = "Untitled Feed"
 
+// BookmarksScheme prefixes the URLs of feeds holding bookmarks imported from
+// a bookmark service, such as "bookmarks:linkding". They aren't fetched.
+const BookmarksScheme = "bookmarks"
+
+// Entry identity strategies decide which field tells a feed's entries apart
+// across syncs. Each falls back to the next when its field is empty: guid,
+// then link, then a hash of title and publish date.
+const (
+	IdentityAuto = ""     // GUIDs, switching to link or hash if they prove unstable
+	IdentityGUID = "guid" // GUIDs, never switched automatically
+	IdentityLink = "link" // Entry links
+	IdentityHash = "hash" // Hash of title and publish date
+)
+
+// ParseIdentity validates an identity strategy name, accepting "auto" for
+// IdentityAuto.
+func ParseIdentity(s string) (string, error) {
+	switch s {
+	case "auto", IdentityAuto:
+		return IdentityAuto, nil
+	case IdentityGUID, IdentityLink, IdentityHash:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown identity %q: use auto, guid, link, or hash", s)
+}
+
+// Feed priorities order unread listings and the 'digest next' queue, and
+// only high-priority feeds' entries are pushed as they arrive.
+const (
+	PriorityNormal = ""     // The default
+	PriorityHigh   = "high" // Listed first and pushed to delivery targets
+	PriorityLow    = "low"  // Listed after everything else
+)
+
+// Priorities lists the priority names, highest first.
+var Priorities = []string{PriorityHigh, "normal", PriorityLow}
+
+// ParsePriority validates a priority name, accepting "normal" for
+// PriorityNormal.
+func ParsePriority(s string) (string, error) {
+	switch s {
+	case "normal", PriorityNormal:
+		return PriorityNormal, nil
+	case PriorityHigh, PriorityLow:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown priority %q: use high, normal, or low", s)
+}
+
+// PriorityRank orders priorities: 0 for high, 1 for normal, 2 for low.
+func PriorityRank(priority string) int {
+	switch priority {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return 2
+	}
+	return 1
+}
+
+// PriorityName names a priority for display.
+func PriorityName(priority string) string {
+	if priority == PriorityNormal {
+		return "normal"
+	}
+	return priority
+}
+
 // Feed represents an RSS/Atom feed subscription
 type Feed struct {
-	ID            string     // Unique identifier for the feed
-	URL           string     // Feed URL
-	Title         *string    // Feed title (from RSS/Atom metadata)
-	Folder        string     // Folder for organization (empty = root)
-	ETag          *string    // HTTP ETag header for conditional requests
-	LastModified  *string    // HTTP Last-Modified header for conditional requests
-	LastFetchedAt *time.Time // Timestamp of last successful fetch
-	LastError     *string    // Last error message (if any)
-	ErrorCount    int        // Consecutive error count for backoff strategy
-	LocalNetwork  bool       // Allow fetching from private/local network IPs
-	CreatedAt     time.Time  // Feed creation timestamp
+	ID            string        // Unique identifier for the feed
+	URL           string        // Feed URL
+	Title         *string       // Feed title (from RSS/Atom metadata)
+	Folder        string        // Folder for organization (empty = root)
+	ETag          *string       // HTTP ETag header for conditional requests
+	LastModified  *string       // HTTP Last-Modified header for conditional requests
+	LastFetchedAt *time.Time    // Timestamp of last successful fetch
+	LastError     *string       // Last error message (if any)
+	ErrorCount    int           // Consecutive error count for backoff strategy
+	LocalNetwork  bool          // Allow fetching from private/local network IPs
+	Browser       bool          // Send browser-like headers, for sites whose bot protection turns away feed readers
+	Monitor       bool          // Watch URL as a web page, adding an entry whenever its text changes
+	PageText      *string       // Text of a monitored page at the last check
+	Paused        bool          // Skip this feed during sync until resumed
+	ArchivedAt    *time.Time    // When the feed was archived as dead (nil = active)
+	KeepActive    bool          // Never archive automatically; set by a manual unarchive
+	SyncInterval  time.Duration // Minimum time between syncs (0 = every sync)
+	MaxEntries    int           // Maximum entries to keep; oldest are pruned (0 = unlimited)
+	Identity      string        // Entry identity strategy (IdentityAuto, IdentityGUID, ...)
+	Priority      string        // PriorityHigh, PriorityNormal, or PriorityLow
+	BackfillDays  int           // First sync marks entries published more than this many days ago read (0 = off)
+	BackfillLimit int           // First sync imports only this many of the newest entries (0 = all)
+	AuthUsername  *string       // HTTP basic auth username
+	AuthPassword  *string       // HTTP basic auth password
+	CreatedAt     time.Time     // Feed creation timestamp
 }
 
 // NewFeed creates a new Feed instance with a generated ID and timestamp
@@ -63,6 +146,34 @@ func (f *Feed) GetDisplayName() string {
 	return f.URL
 }
 
+// HasAuth reports whether the feed has HTTP basic auth credentials configured
+func (f *Feed) HasAuth() bool {
+	return f.AuthUsername != nil && *f.AuthUsername != ""
+}
+
+// IsBookmarks reports whether the feed holds imported bookmarks rather than
+// being fetched.
+func (f *Feed) IsBookmarks() bool {
+	return strings.HasPrefix(f.URL, BookmarksScheme+":")
+}
+
+// IsArchived reports whether the feed has been archived.
+func (f *Feed) IsArchived() bool {
+	return f.ArchivedAt != nil
+}
+
+// IsDue reports whether the feed should be synced at the given time,
+// honoring the paused flag and the per-feed sync interval
+func (f *Feed) IsDue(now time.Time) bool {
+	if f.Paused {
+		return false
+	}
+	if f.SyncInterval <= 0 || f.LastFetchedAt == nil {
+		return true
+	}
+	return now.Sub(*f.LastFetchedAt) >= f.SyncInterval
+}
+
 // ValidateFeedURL checks that a URL is valid for use as a feed URL.
 // Returns the parsed URL if valid, or an error describing the problem.
 func ValidateFeedURL(urlStr string) (*url.URL, error) {
@@ -370,6 +370,21 @@ func TestFeed_LocalNetworkDefault(t *testing.T) {
 	}
 }
 
+func TestParsePriority(t *testing.T) {
+	for in, want := range map[string]string{"high": PriorityHigh, "normal": PriorityNormal, "": PriorityNormal, "low": PriorityLow} {
+		got, err := ParsePriority(in)
+		if err != nil || got != want {
+			t.Errorf("ParsePriority(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("expected an error for an unknown priority")
+	}
+	if !(PriorityRank(PriorityHigh) < PriorityRank(PriorityNormal) && PriorityRank(PriorityNormal) < PriorityRank(PriorityLow)) {
+		t.Error("expected high to rank before normal and normal before low")
+	}
+}
+
 // Helper functions for tests
 func stringPtr(s string) *string {
 	return &s
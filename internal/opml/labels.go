@@ -0,0 +1,137 @@
+// ABOUTME: Labels that group feeds beyond folders, so one feed can sit in several groups
+// ABOUTME: Stored in each feed outline's category attribute, so OPML exports carry them as tags
+
+package opml
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CheckLabel trims a label and checks it can be stored: OPML separates a
+// feed's categories with commas, and a slash would make it a category
+// path rather than a tag.
+func CheckLabel(label string) (string, error) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return "", fmt.Errorf("label can't be empty")
+	}
+	if strings.ContainsAny(label, ",/") {
+		return "", fmt.Errorf("label %q can't contain a comma or slash", label)
+	}
+	return label, nil
+}
+
+// Labels returns every label on the document's feeds, sorted. Labels are
+// matched ignoring case, so each appears once, spelled as it first does.
+func (d *Document) Labels() []string {
+	var labels []string
+	for _, feed := range d.AllFeeds() {
+		for _, label := range feed.Labels {
+			if !containsLabel(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	slices.SortFunc(labels, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return labels
+}
+
+// FeedsWithLabel returns the feeds carrying label, ignoring case.
+func (d *Document) FeedsWithLabel(label string) []Feed {
+	var feeds []Feed
+	for _, feed := range d.AllFeeds() {
+		if containsLabel(feed.Labels, label) {
+			feeds = append(feeds, feed)
+		}
+	}
+	return feeds
+}
+
+// LabelFeed adds label to a feed, reporting false if it already had it.
+func (d *Document) LabelFeed(url, label string) (bool, error) {
+	outline := d.feedOutline(url)
+	if outline == nil {
+		return false, fmt.Errorf("feed not found: %s", url)
+	}
+	labels := splitLabels(outline.Category)
+	if containsLabel(labels, label) {
+		return false, nil
+	}
+	outline.Category = strings.Join(append(labels, label), ",")
+	return true, nil
+}
+
+// UnlabelFeed removes label from a feed, reporting false if it didn't
+// have it.
+func (d *Document) UnlabelFeed(url, label string) (bool, error) {
+	outline := d.feedOutline(url)
+	if outline == nil {
+		return false, fmt.Errorf("feed not found: %s", url)
+	}
+	labels := splitLabels(outline.Category)
+	kept := slices.DeleteFunc(slices.Clone(labels), func(l string) bool { return strings.EqualFold(l, label) })
+	if len(kept) == len(labels) {
+		return false, nil
+	}
+	outline.Category = strings.Join(kept, ",")
+	return true, nil
+}
+
+// RenameLabel renames label on every feed carrying it, merging it into
+// newLabel where a feed has both, and returns how many feeds it changed.
+func (d *Document) RenameLabel(label, newLabel string) int {
+	feeds := d.FeedsWithLabel(label)
+	for _, feed := range feeds {
+		_, _ = d.UnlabelFeed(feed.URL, label)
+		_, _ = d.LabelFeed(feed.URL, newLabel)
+	}
+	return len(feeds)
+}
+
+// DeleteLabel removes label from every feed and returns how many feeds
+// carried it.
+func (d *Document) DeleteLabel(label string) int {
+	feeds := d.FeedsWithLabel(label)
+	for _, feed := range feeds {
+		_, _ = d.UnlabelFeed(feed.URL, label)
+	}
+	return len(feeds)
+}
+
+// feedOutline returns the outline of the feed at url, or nil.
+func (d *Document) feedOutline(url string) *Outline {
+	var find func(outlines []Outline) *Outline
+	find = func(outlines []Outline) *Outline {
+		for i := range outlines {
+			if outlines[i].XMLURL == url {
+				return &outlines[i]
+			}
+			if o := find(outlines[i].Children); o != nil {
+				return o
+			}
+		}
+		return nil
+	}
+	return find(d.Outlines)
+}
+
+// splitLabels returns the labels in a category attribute. Categories
+// other readers wrote as paths are kept as they are.
+func splitLabels(category string) []string {
+	var labels []string
+	for _, label := range strings.Split(category, ",") {
+		if label = strings.TrimSpace(label); label != "" && !containsLabel(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// containsLabel reports whether labels holds label, ignoring case.
+func containsLabel(labels []string, label string) bool {
+	return slices.ContainsFunc(labels, func(l string) bool { return strings.EqualFold(l, label) })
+}
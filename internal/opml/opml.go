@@ -4,13 +4,26 @@
 package opml
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/harper/digest/internal/xmlguard"
 )
 
+// Limits bounds the OPML documents Parse accepts, so a hostile file
+// can't exhaust memory or hang whoever loads it. Real subscription lists
+// are a small fraction of each.
+var Limits = xmlguard.Limits{
+	MaxBytes:    16 * 1024 * 1024,
+	MaxDepth:    50,
+	MaxElements: 50000,
+}
+
 // Document represents an OPML document with a title and hierarchical outlines
 type Document struct {
 	Title    string
@@ -25,6 +38,8 @@ type Outline struct {
 	Title    string
 	Type     string
 	XMLURL   string
+	HTMLURL  string
+	Category string // A feed's labels, comma separated, as OPML 2.0 holds tags
 	Children []Outline
 }
 
@@ -33,6 +48,8 @@ type Feed struct {
 	URL    string
 	Title  string
 	Folder string
+	// Labels group the feed beyond its one folder
+	Labels []string
 }
 
 // XML structs for parsing and writing OPML files
@@ -56,6 +73,8 @@ type outlineXML struct {
 	Title    string       `xml:"title,attr,omitempty"`
 	Type     string       `xml:"type,attr,omitempty"`
 	XMLURL   string       `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string       `xml:"htmlUrl,attr,omitempty"`
+	Category string       `xml:"category,attr,omitempty"`
 	Children []outlineXML `xml:"outline,omitempty"`
 }
 
@@ -70,8 +89,16 @@ func NewDocument(title string) *Document {
 
 // Parse reads OPML data from an io.Reader and returns a Document
 func Parse(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(Limits.MaxBytes)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OPML: %w", err)
+	}
+	if err := xmlguard.Check(data, Limits); err != nil {
+		return nil, fmt.Errorf("failed to decode OPML: %w", err)
+	}
+
 	var opml opmlXML
-	decoder := xml.NewDecoder(r)
+	decoder := xmlguard.NewDecoder(bytes.NewReader(data))
 	if err := decoder.Decode(&opml); err != nil {
 		return nil, fmt.Errorf("failed to decode OPML: %w", err)
 	}
@@ -153,6 +180,7 @@ func (d *Document) FeedsInFolder(folder string) []Feed {
 					URL:    outline.XMLURL,
 					Title:  getOutlineTitle(outline),
 					Folder: "",
+					Labels: splitLabels(outline.Category),
 				})
 			}
 		}
@@ -166,6 +194,7 @@ func (d *Document) FeedsInFolder(folder string) []Feed {
 							URL:    child.XMLURL,
 							Title:  getOutlineTitle(child),
 							Folder: folder,
+							Labels: splitLabels(child.Category),
 						})
 					}
 				}
@@ -262,12 +291,55 @@ func (d *Document) MoveFeed(url, newFolder string) error {
 		return fmt.Errorf("failed to remove feed: %w", err)
 	}
 
-	// Add to new location (use existing title)
+	// Add to new location (use existing title and labels)
 	d.addFeedInternal(url, feed.Title, newFolder)
+	d.feedOutline(url).Category = strings.Join(feed.Labels, ",")
 
 	return nil
 }
 
+// RenameFeed sets the display title of a feed
+func (d *Document) RenameFeed(url, title string) error {
+	for i := range d.Outlines {
+		if d.Outlines[i].XMLURL == url {
+			d.Outlines[i].Text = title
+			d.Outlines[i].Title = title
+			return nil
+		}
+		for j := range d.Outlines[i].Children {
+			if d.Outlines[i].Children[j].XMLURL == url {
+				d.Outlines[i].Children[j].Text = title
+				d.Outlines[i].Children[j].Title = title
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("feed not found: %s", url)
+}
+
+// SetFeedURL points a feed at a new URL, keeping its title and folder
+func (d *Document) SetFeedURL(oldURL, newURL string) error {
+	d.ensureURLIndex()
+	if oldURL != newURL && d.feedURLs[newURL] {
+		return fmt.Errorf("feed already exists: %s", newURL)
+	}
+	for i := range d.Outlines {
+		if d.Outlines[i].XMLURL == oldURL {
+			d.Outlines[i].XMLURL = newURL
+			d.rebuildURLIndex()
+			return nil
+		}
+		for j := range d.Outlines[i].Children {
+			if d.Outlines[i].Children[j].XMLURL == oldURL {
+				d.Outlines[i].Children[j].XMLURL = newURL
+				d.rebuildURLIndex()
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("feed not found: %s", oldURL)
+}
+
 // addFeedInternal adds a feed without checking for duplicates
 func (d *Document) addFeedInternal(url, title, folder string) {
 	d.ensureURLIndex()
@@ -392,6 +464,8 @@ func convertOutlineFromXML(x outlineXML) Outline {
 		Title:    x.Title,
 		Type:     x.Type,
 		XMLURL:   x.XMLURL,
+		HTMLURL:  x.HTMLURL,
+		Category: x.Category,
 		Children: make([]Outline, len(x.Children)),
 	}
 
@@ -408,6 +482,8 @@ func convertOutlineToXML(o Outline) outlineXML {
 		Title:    o.Title,
 		Type:     o.Type,
 		XMLURL:   o.XMLURL,
+		HTMLURL:  o.HTMLURL,
+		Category: o.Category,
 		Children: make([]outlineXML, len(o.Children)),
 	}
 
@@ -427,6 +503,7 @@ func collectFeeds(outline Outline, folder string) []Feed {
 			URL:    outline.XMLURL,
 			Title:  getOutlineTitle(outline),
 			Folder: folder,
+			Labels: splitLabels(outline.Category),
 		})
 	}
 
@@ -5,9 +5,13 @@ package opml
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/harper/digest/internal/xmlguard"
 )
 
 func TestParseOPML(t *testing.T) {
@@ -933,3 +937,196 @@ func TestOPML_RoundTripIntegrity(t *testing.T) {
 		}
 	})
 }
+
+func TestOPML_RenameFeed(t *testing.T) {
+	doc := NewDocument("Rename Test")
+	doc.AddFeed("https://example.com/feed1", "Feed 1", "Tech")
+	doc.AddFeed("https://example.com/feed2", "Feed 2", "")
+
+	if err := doc.RenameFeed("https://example.com/feed1", "Renamed"); err != nil {
+		t.Fatalf("RenameFeed() error = %v", err)
+	}
+	if err := doc.RenameFeed("https://example.com/feed2", "Root Renamed"); err != nil {
+		t.Fatalf("RenameFeed() error = %v", err)
+	}
+
+	titles := map[string]string{}
+	for _, f := range doc.AllFeeds() {
+		titles[f.URL] = f.Title
+	}
+	if titles["https://example.com/feed1"] != "Renamed" {
+		t.Errorf("expected nested feed to be renamed, got %q", titles["https://example.com/feed1"])
+	}
+	if titles["https://example.com/feed2"] != "Root Renamed" {
+		t.Errorf("expected root feed to be renamed, got %q", titles["https://example.com/feed2"])
+	}
+
+	if err := doc.RenameFeed("https://example.com/missing", "X"); err == nil {
+		t.Error("expected error for missing feed")
+	}
+}
+
+func TestOPML_SetFeedURL(t *testing.T) {
+	doc := NewDocument("URL Test")
+	doc.AddFeed("http://example.com/feed/", "Feed 1", "Tech")
+	doc.AddFeed("https://other.com/feed", "Feed 2", "")
+
+	if err := doc.SetFeedURL("http://example.com/feed/", "https://example.com/feed"); err != nil {
+		t.Fatalf("SetFeedURL() error = %v", err)
+	}
+	feeds := doc.FeedsInFolder("Tech")
+	if len(feeds) != 1 || feeds[0].URL != "https://example.com/feed" || feeds[0].Title != "Feed 1" {
+		t.Errorf("expected the feed to keep its title and folder under the new URL, got %+v", feeds)
+	}
+	if err := doc.AddFeed("http://example.com/feed/", "Again", ""); err != nil {
+		t.Errorf("expected the old URL to be free after SetFeedURL, got %v", err)
+	}
+
+	if err := doc.SetFeedURL("https://example.com/feed", "https://other.com/feed"); err == nil {
+		t.Error("expected error when the new URL is already subscribed")
+	}
+	if err := doc.SetFeedURL("https://example.com/missing", "https://example.com/x"); err == nil {
+		t.Error("expected error for missing feed")
+	}
+}
+
+func TestOPML_HTMLURLRoundTrip(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<opml version="2.0"><head><title>Sites</title></head><body>
+<outline text="Blog" type="rss" xmlUrl="https://example.com/feed.xml" htmlUrl="https://example.com/"/>
+</body></opml>`
+
+	doc, err := Parse(bytes.NewBufferString(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.Outlines[0].HTMLURL != "https://example.com/" {
+		t.Fatalf("expected htmlUrl to be parsed, got %q", doc.Outlines[0].HTMLURL)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`htmlUrl="https://example.com/"`)) {
+		t.Errorf("expected htmlUrl to survive a round trip, got:\n%s", buf.String())
+	}
+}
+
+func TestOPML_Labels(t *testing.T) {
+	doc := NewDocument("Labels Test")
+	doc.AddFeed("https://go.dev/feed", "Go Blog", "Tech")
+	doc.AddFeed("https://news.example.com/feed", "Weekly", "")
+
+	for _, label := range []string{"Go", "Newsletters"} {
+		if _, err := doc.LabelFeed("https://go.dev/feed", label); err != nil {
+			t.Fatalf("LabelFeed() error = %v", err)
+		}
+	}
+	doc.LabelFeed("https://news.example.com/feed", "newsletters")
+	if added, _ := doc.LabelFeed("https://go.dev/feed", "go"); added {
+		t.Error("expected labels to match ignoring case")
+	}
+	if _, err := doc.LabelFeed("https://example.com/missing", "Go"); err == nil {
+		t.Error("expected error for missing feed")
+	}
+
+	if got := doc.Labels(); strings.Join(got, "|") != "Go|Newsletters" {
+		t.Errorf("Labels() = %v, want [Go Newsletters]", got)
+	}
+	if got := doc.FeedsWithLabel("NEWSLETTERS"); len(got) != 2 {
+		t.Errorf("expected both feeds labelled Newsletters, got %+v", got)
+	}
+
+	// Labels survive moving to another folder and a round trip as categories
+	if err := doc.MoveFeed("https://go.dev/feed", ""); err != nil {
+		t.Fatalf("MoveFeed() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `category="Go,Newsletters"`) {
+		t.Errorf("expected labels written as a category attribute, got:\n%s", buf.String())
+	}
+	parsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := parsed.FeedsWithLabel("Go"); len(got) != 1 || got[0].URL != "https://go.dev/feed" {
+		t.Errorf("expected the Go label to survive a round trip, got %+v", got)
+	}
+
+	if n := doc.RenameLabel("newsletters", "Go"); n != 2 {
+		t.Errorf("RenameLabel() changed %d feeds, want 2", n)
+	}
+	feeds := doc.FeedsWithLabel("Go")
+	if len(feeds) != 2 || len(feeds[0].Labels) != 1 || len(feeds[1].Labels) != 1 {
+		t.Errorf("expected the renamed label merged into Go, got %+v", feeds)
+	}
+	if removed, _ := doc.UnlabelFeed("https://go.dev/feed", "go"); !removed {
+		t.Error("expected UnlabelFeed() to remove the label")
+	}
+	if n := doc.DeleteLabel("Go"); n != 1 {
+		t.Errorf("DeleteLabel() changed %d feeds, want 1", n)
+	}
+	if got := doc.Labels(); len(got) != 0 {
+		t.Errorf("expected no labels left, got %v", got)
+	}
+}
+
+func TestCheckLabel(t *testing.T) {
+	if got, err := CheckLabel("  Go "); err != nil || got != "Go" {
+		t.Errorf("CheckLabel() = %q, %v; want Go", got, err)
+	}
+	for _, label := range []string{"", "  ", "a,b", "a/b"} {
+		if _, err := CheckLabel(label); err == nil {
+			t.Errorf("CheckLabel(%q) expected an error", label)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := map[string]string{
+		"deep": `<opml><body>` + strings.Repeat(`<outline text="x">`, Limits.MaxDepth) +
+			strings.Repeat(`</outline>`, Limits.MaxDepth) + `</body></opml>`,
+		"many":   `<opml><body>` + strings.Repeat(`<outline xmlUrl="https://example.com/f"/>`, Limits.MaxElements) + `</body></opml>`,
+		"entity": `<!DOCTYPE opml [<!ENTITY a "aaaaaaaa">]><opml><head><title>&a;</title></head></opml>`,
+		"xxe":    `<!DOCTYPE opml [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><opml><head><title>&xxe;</title></head></opml>`,
+		"large":  `<opml><head><title>` + strings.Repeat("x", Limits.MaxBytes) + `</title></head></opml>`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(data))
+			if !errors.Is(err, xmlguard.ErrUnsafe) {
+				t.Errorf("Parse() error = %v, want unsafe XML rejected", err)
+			}
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(`<?xml version="1.0"?><opml version="2.0"><head><title>T</title></head><body>` +
+		`<outline text="Tech"><outline type="rss" text="A" xmlUrl="https://a.example/feed"/></outline>` +
+		`<outline type="rss" text="B" xmlUrl="https://b.example/feed" htmlUrl="https://b.example"/></body></opml>`)
+	f.Add(`<opml><body><outline><outline><outline xmlUrl="x"/></outline></outline></body></opml>`)
+	f.Add(`<!DOCTYPE opml [<!ENTITY a "b">]><opml/>`)
+	f.Fuzz(func(t *testing.T, data string) {
+		doc, err := Parse(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		// Anything Parse accepts must survive a round trip.
+		var buf bytes.Buffer
+		if err := doc.Write(&buf); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		again, err := Parse(&buf)
+		if err != nil {
+			t.Fatalf("re-Parse() error = %v\n%s", err, buf.String())
+		}
+		if got, want := len(again.AllFeeds()), len(doc.AllFeeds()); got != want {
+			t.Errorf("round trip kept %d feeds, want %d", got, want)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+// ABOUTME: Curated OPML starter packs that subscribe new users to a topic's feeds in one step
+// ABOUTME: Packs are bundled with digest or listed in a JSON packs index fetched from a URL
+
+package packs
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/harper/digest/internal/opml"
+)
+
+//go:embed builtin/*.opml
+var builtins embed.FS
+
+// Ext is the file extension of bundled packs.
+const Ext = ".opml"
+
+// Pack is a starter pack: an OPML file of feeds grouped into folders.
+type Pack struct {
+	// Name is what 'digest init --pack' takes, such as "golang".
+	Name string `json:"name"`
+
+	// Description says what the pack covers.
+	Description string `json:"description,omitempty"`
+
+	// URL is where an index pack's OPML file is fetched from; empty for
+	// bundled packs.
+	URL string `json:"url,omitempty"`
+}
+
+// Bundled returns the packs bundled with digest, sorted by name.
+func Bundled() ([]Pack, error) {
+	files, err := fs.Glob(builtins, "builtin/*"+Ext)
+	if err != nil {
+		return nil, fmt.Errorf("list bundled packs: %w", err)
+	}
+	var packs []Pack
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), Ext)
+		doc, err := loadBundled(name)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, Pack{Name: name, Description: doc.Title})
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name < packs[j].Name })
+	return packs, nil
+}
+
+// LoadBundled returns the feeds of the bundled pack called name. The bool
+// reports whether there is one.
+func LoadBundled(name string) (*opml.Document, bool, error) {
+	if strings.ContainsAny(name, "/.") {
+		return nil, false, nil
+	}
+	if _, err := fs.Stat(builtins, "builtin/"+name+Ext); err != nil {
+		return nil, false, nil
+	}
+	doc, err := loadBundled(name)
+	if err != nil {
+		return nil, true, err
+	}
+	return doc, true, nil
+}
+
+func loadBundled(name string) (*opml.Document, error) {
+	data, err := builtins.ReadFile("builtin/" + name + Ext)
+	if err != nil {
+		return nil, fmt.Errorf("read pack %s: %w", name, err)
+	}
+	doc, err := opml.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse pack %s: %w", name, err)
+	}
+	return doc, nil
+}
+
+// index is the JSON a packs index serves.
+type index struct {
+	Packs []Pack `json:"packs"`
+}
+
+// ParseIndex reads a packs index fetched from base:
+//
+//	{"packs": [{"name": "rust", "description": "...", "url": "rust.opml"}]}
+//
+// Relative pack URLs are resolved against base.
+func ParseIndex(data []byte, base string) ([]Pack, error) {
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parse packs index: %w", err)
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid packs index URL: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	packs := make([]Pack, 0, len(idx.Packs))
+	for _, p := range idx.Packs {
+		p.Name = strings.TrimSpace(p.Name)
+		if p.Name == "" {
+			return nil, fmt.Errorf("packs index has a pack without a name")
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("packs index lists %s twice", p.Name)
+		}
+		seen[p.Name] = true
+		if p.URL == "" {
+			return nil, fmt.Errorf("pack %s has no url", p.Name)
+		}
+		ref, err := url.Parse(p.URL)
+		if err != nil {
+			return nil, fmt.Errorf("pack %s has an invalid url: %w", p.Name, err)
+		}
+		resolved := baseURL.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			return nil, fmt.Errorf("pack %s url must be http or https", p.Name)
+		}
+		p.URL = resolved.String()
+		packs = append(packs, p)
+	}
+	return packs, nil
+}
+
+// Find returns the pack called name among packs.
+func Find(packs []Pack, name string) (Pack, bool) {
+	for _, p := range packs {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Pack{}, false
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Tests for starter packs
+// ABOUTME: Covers the bundled packs' contents and parsing of packs indexes
+
+package packs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBundled(t *testing.T) {
+	packs, err := Bundled()
+	if err != nil {
+		t.Fatalf("Bundled: %v", err)
+	}
+	for _, name := range []string{"golang", "news", "tech"} {
+		p, ok := Find(packs, name)
+		if !ok {
+			t.Fatalf("pack %s not bundled", name)
+		}
+		if p.Description == "" {
+			t.Errorf("pack %s has no description", name)
+		}
+
+		doc, ok, err := LoadBundled(name)
+		if err != nil || !ok {
+			t.Fatalf("LoadBundled(%s) = %v, %v", name, ok, err)
+		}
+		feeds := doc.AllFeeds()
+		if len(feeds) == 0 {
+			t.Errorf("pack %s has no feeds", name)
+		}
+		for _, f := range feeds {
+			if !strings.HasPrefix(f.URL, "https://") {
+				t.Errorf("pack %s feed %s is not https", name, f.URL)
+			}
+			if f.Folder == "" {
+				t.Errorf("pack %s feed %s is outside a folder", name, f.URL)
+			}
+		}
+	}
+
+	for _, name := range []string{"missing", "../builtin/tech", ""} {
+		if _, ok, _ := LoadBundled(name); ok {
+			t.Errorf("LoadBundled(%q) found a pack", name)
+		}
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	data := []byte(`{"packs": [
+		{"name": "rust", "description": "Rust", "url": "rust.opml"},
+		{"name": "art", "url": "https://other.example/art.opml"}
+	]}`)
+	packs, err := ParseIndex(data, "https://packs.example/index.json")
+	if err != nil {
+		t.Fatalf("ParseIndex: %v", err)
+	}
+	if len(packs) != 2 {
+		t.Fatalf("got %d packs, want 2", len(packs))
+	}
+	if packs[0].URL != "https://packs.example/rust.opml" {
+		t.Errorf("relative URL resolved to %s", packs[0].URL)
+	}
+	if packs[1].URL != "https://other.example/art.opml" {
+		t.Errorf("absolute URL became %s", packs[1].URL)
+	}
+
+	bad := map[string]string{
+		"not json": `packs`,
+		"no name":  `{"packs": [{"url": "a.opml"}]}`,
+		"no url":   `{"packs": [{"name": "a"}]}`,
+		"twice":    `{"packs": [{"name": "a", "url": "a.opml"}, {"name": "a", "url": "b.opml"}]}`,
+		"file url": `{"packs": [{"name": "a", "url": "file:///etc/passwd"}]}`,
+	}
+	for name, data := range bad {
+		if _, err := ParseIndex([]byte(data), "https://packs.example/index.json"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -4,12 +4,31 @@
 package parse
 
 import (
+	"bytes"
 	"strings"
 	"time"
 
 	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/atom"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"github.com/mmcdole/gofeed/rss"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/thumbnail"
+	"github.com/harper/digest/internal/xmlguard"
 )
 
+// Limits bounds the XML feeds Parse accepts, so a hostile feed can't
+// exhaust memory or stall a sync. They sit well above anything a real
+// feed needs. gofeed itself only knows the HTML entities and never
+// resolves external ones; with entity declarations refused up front,
+// nothing in a feed expands beyond its own size.
+var Limits = xmlguard.Limits{
+	MaxBytes:    10 * 1024 * 1024,
+	MaxDepth:    100,
+	MaxElements: 250000,
+}
+
 // ParsedFeed represents a normalized feed structure
 type ParsedFeed struct {
 	Title   string
@@ -25,11 +44,34 @@ type ParsedEntry struct {
 	PublishedAt *time.Time
 	Content     string
 	Categories  []string
+	// Image is the entry's lead image URL, or "" if it has none
+	Image string
+	// Discussion is the web page of the entry's comment thread, from RSS
+	// <comments> or an Atom replies link
+	Discussion string
+	// CommentsFeed is a feed of the entry's comments, from wfw:commentRss
+	// or an Atom replies link
+	CommentsFeed string
+	// Extensions are the item's namespaced elements, or nil if it has none
+	Extensions models.Extensions
 }
 
+// Keys under which the translators below keep comment links in Item.Custom.
+const (
+	customDiscussion   = "digest:discussion"
+	customCommentsFeed = "digest:comments_feed"
+)
+
 // Parse parses RSS or Atom feed data and returns a normalized ParsedFeed
 func Parse(data []byte) (*ParsedFeed, error) {
+	if looksLikeXML(data) {
+		if err := xmlguard.Check(data, Limits); err != nil {
+			return nil, err
+		}
+	}
 	parser := gofeed.NewParser()
+	parser.RSSTranslator = &rssTranslator{}
+	parser.AtomTranslator = &atomTranslator{}
 	feed, err := parser.ParseString(string(data))
 	if err != nil {
 		return nil, err
@@ -74,9 +116,184 @@ func Parse(data []byte) (*ParsedFeed, error) {
 
 		// Clean up content - remove HTML tags if needed
 		entry.Content = strings.TrimSpace(entry.Content)
+		entry.Image = leadImage(item, entry.Content)
+		entry.Discussion = item.Custom[customDiscussion]
+		entry.CommentsFeed = item.Custom[customCommentsFeed]
+		if entry.CommentsFeed == "" {
+			entry.CommentsFeed = extensionValue(item.Extensions, "wfw", "commentRss")
+		}
+		entry.Extensions = convertExtensions(item.Extensions)
 
 		parsed.Entries = append(parsed.Entries, entry)
 	}
 
 	return parsed, nil
 }
+
+// leadImage picks an item's lead image: a media:thumbnail or image
+// media:content, then the image gofeed found (iTunes art, image enclosures,
+// or the first image in RSS content), then the first image in the content.
+// looksLikeXML reports whether data starts like an XML document rather
+// than a JSON feed, whose HTML content would confuse the structure check.
+func looksLikeXML(data []byte) bool {
+	data = bytes.TrimLeft(data, "\xef\xbb\xbf \t\r\n")
+	return len(data) > 0 && data[0] == '<'
+}
+
+func leadImage(item *gofeed.Item, content string) string {
+	if image := mediaImage(item.Extensions["media"]); image != "" {
+		return thumbnail.Resolve(image, item.Link)
+	}
+	if item.Image != nil {
+		if image := thumbnail.Resolve(item.Image.URL, item.Link); image != "" {
+			return image
+		}
+	}
+	for _, enc := range item.Enclosures {
+		if strings.HasPrefix(enc.Type, "image/") {
+			if image := thumbnail.Resolve(enc.URL, item.Link); image != "" {
+				return image
+			}
+		}
+	}
+	return thumbnail.FromHTML(content, item.Link)
+}
+
+// mediaImage returns the first Media RSS thumbnail or image content URL,
+// looking inside media:group too.
+func mediaImage(media map[string][]ext.Extension) string {
+	for _, thumb := range media["thumbnail"] {
+		if thumb.Attrs["url"] != "" {
+			return thumb.Attrs["url"]
+		}
+	}
+	for _, c := range media["content"] {
+		if isImageContent(c) {
+			return c.Attrs["url"]
+		}
+		if image := mediaImage(c.Children); image != "" {
+			return image
+		}
+	}
+	for _, group := range media["group"] {
+		if image := mediaImage(group.Children); image != "" {
+			return image
+		}
+	}
+	return ""
+}
+
+// isImageContent reports whether a media:content element is an image.
+func isImageContent(c ext.Extension) bool {
+	if c.Attrs["url"] == "" {
+		return false
+	}
+	return c.Attrs["medium"] == "image" || strings.HasPrefix(c.Attrs["type"], "image/")
+}
+
+// rssTranslator is gofeed's RSS translator keeping each item's <comments>
+// link, which the default drops.
+type rssTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *rssTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	translated, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+	// Items are translated one for one, in order
+	if source, ok := feed.(*rss.Feed); ok && len(source.Items) == len(translated.Items) {
+		for i, item := range source.Items {
+			setCustom(translated.Items[i], customDiscussion, strings.TrimSpace(item.Comments))
+		}
+	}
+	return translated, nil
+}
+
+// atomTranslator is gofeed's Atom translator keeping each entry's replies
+// links (RFC 4685), which the default drops: an HTML one is the discussion
+// page, any other a comments feed.
+type atomTranslator struct {
+	gofeed.DefaultAtomTranslator
+}
+
+func (t *atomTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	translated, err := t.DefaultAtomTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+	if source, ok := feed.(*atom.Feed); ok && len(source.Entries) == len(translated.Items) {
+		for i, entry := range source.Entries {
+			for _, link := range entry.Links {
+				if link.Rel != "replies" || link.Href == "" {
+					continue
+				}
+				key := customCommentsFeed
+				if link.Type == "text/html" {
+					key = customDiscussion
+				}
+				if translated.Items[i].Custom[key] == "" {
+					setCustom(translated.Items[i], key, link.Href)
+				}
+			}
+		}
+	}
+	return translated, nil
+}
+
+// setCustom records a non-empty value in an item's custom fields.
+func setCustom(item *gofeed.Item, key, value string) {
+	if value == "" {
+		return
+	}
+	if item.Custom == nil {
+		item.Custom = make(map[string]string)
+	}
+	item.Custom[key] = value
+}
+
+// convertExtensions copies gofeed's extension elements into the model's
+// form, returning nil when there are none.
+func convertExtensions(extensions ext.Extensions) models.Extensions {
+	if len(extensions) == 0 {
+		return nil
+	}
+	converted := make(models.Extensions, len(extensions))
+	for prefix, elements := range extensions {
+		converted[prefix] = convertElements(elements)
+	}
+	return converted
+}
+
+func convertElements(elements map[string][]ext.Extension) map[string][]models.Extension {
+	if len(elements) == 0 {
+		return nil
+	}
+	converted := make(map[string][]models.Extension, len(elements))
+	for name, list := range elements {
+		for _, e := range list {
+			var attrs map[string]string
+			if len(e.Attrs) > 0 {
+				attrs = e.Attrs
+			}
+			converted[name] = append(converted[name], models.Extension{
+				Name:     e.Name,
+				Value:    strings.TrimSpace(e.Value),
+				Attrs:    attrs,
+				Children: convertElements(e.Children),
+			})
+		}
+	}
+	return converted
+}
+
+// extensionValue returns the text of the first prefix:name element.
+func extensionValue(extensions ext.Extensions, prefix, name string) string {
+	for _, e := range extensions[prefix][name] {
+		if v := strings.TrimSpace(e.Value); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -4,8 +4,12 @@
 package parse
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/harper/digest/internal/xmlguard"
 )
 
 const rss20XML = `<?xml version="1.0" encoding="UTF-8"?>
@@ -177,3 +181,211 @@ func TestParse_Atom(t *testing.T) {
 		t.Errorf("entry2.Content = %q, want %q (fallback to summary)", entry2.Content, "Second entry summary")
 	}
 }
+
+const imagesXML = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/">
+  <channel>
+    <title>Images</title>
+    <item>
+      <title>Thumbnail</title>
+      <link>https://example.com/thumb</link>
+      <media:thumbnail url="https://cdn.example.com/thumb.jpg"/>
+      <description>&lt;img src="/inline.jpg"&gt;</description>
+    </item>
+    <item>
+      <title>Group</title>
+      <link>https://example.com/group</link>
+      <media:group>
+        <media:content url="https://cdn.example.com/video.mp4" type="video/mp4"/>
+        <media:content url="https://cdn.example.com/still.png" medium="image"/>
+      </media:group>
+    </item>
+    <item>
+      <title>Enclosure</title>
+      <link>https://example.com/enclosure</link>
+      <enclosure url="https://cdn.example.com/photo.webp" length="100" type="image/webp"/>
+    </item>
+    <item>
+      <title>Inline</title>
+      <link>https://example.com/posts/inline</link>
+      <description>&lt;p&gt;&lt;img src="figure.png"&gt;&lt;/p&gt;</description>
+    </item>
+    <item>
+      <title>None</title>
+      <link>https://example.com/none</link>
+      <description>Just words</description>
+    </item>
+  </channel>
+</rss>`
+
+func TestParse_LeadImage(t *testing.T) {
+	feed, err := Parse([]byte(imagesXML))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := map[string]string{
+		"Thumbnail": "https://cdn.example.com/thumb.jpg",
+		"Group":     "https://cdn.example.com/still.png",
+		"Enclosure": "https://cdn.example.com/photo.webp",
+		"Inline":    "https://example.com/posts/figure.png",
+		"None":      "",
+	}
+	for _, entry := range feed.Entries {
+		if entry.Image != want[entry.Title] {
+			t.Errorf("%s: Image = %q, want %q", entry.Title, entry.Image, want[entry.Title])
+		}
+	}
+
+	// Atom entries rely on the first image in their content
+	atom, err := Parse([]byte(`<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>A</title>
+<entry><id>1</id><title>Pic</title><link href="https://example.com/a/1"/><updated>2006-01-02T15:04:05Z</updated>
+<content type="html">&lt;img src="/hero.jpg"&gt;</content></entry></feed>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := atom.Entries[0].Image; got != "https://example.com/hero.jpg" {
+		t.Errorf("atom Image = %q, want %q", got, "https://example.com/hero.jpg")
+	}
+}
+
+func TestParse_Extensions(t *testing.T) {
+	feed, err := Parse([]byte(`<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+  xmlns:media="http://search.yahoo.com/mrss/" xmlns:dc="http://purl.org/dc/elements/1.1/"
+  xmlns:ex="https://example.com/ns">
+  <channel>
+    <title>Podcast</title>
+    <item>
+      <title>Episode 1</title>
+      <link>https://example.com/ep1</link>
+      <dc:creator>Jane Smith</dc:creator>
+      <itunes:duration> 42:00 </itunes:duration>
+      <media:group>
+        <media:content url="https://example.com/ep1.mp4" medium="video"/>
+      </media:group>
+      <ex:rating scale="5">4</ex:rating>
+    </item>
+    <item>
+      <title>Plain</title>
+      <link>https://example.com/plain</link>
+    </item>
+  </channel>
+</rss>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	x := feed.Entries[0].Extensions
+	if d, ok := x.Get("itunes", "duration"); !ok || d.Value != "42:00" {
+		t.Errorf("itunes:duration = %+v, %v", d, ok)
+	}
+	if c, ok := x.Get("dc", "creator"); !ok || c.Value != "Jane Smith" {
+		t.Errorf("dc:creator = %+v, %v", c, ok)
+	}
+	group, ok := x.Get("media", "group")
+	if !ok || len(group.Children["content"]) != 1 || group.Children["content"][0].Attrs["url"] != "https://example.com/ep1.mp4" {
+		t.Errorf("media:group = %+v, %v", group, ok)
+	}
+	if r, ok := x.Get("ex", "rating"); !ok || r.Value != "4" || r.Attrs["scale"] != "5" {
+		t.Errorf("ex:rating = %+v, %v", r, ok)
+	}
+	if plain := feed.Entries[1].Extensions; plain != nil {
+		t.Errorf("expected no extensions on a plain item, got %+v", plain)
+	}
+}
+
+func TestParse_Discussion(t *testing.T) {
+	feed, err := Parse([]byte(`<?xml version="1.0"?>
+<rss version="2.0" xmlns:wfw="http://wellformedweb.org/CommentAPI/">
+  <channel>
+    <title>Discussions</title>
+    <item>
+      <title>HN</title>
+      <link>https://example.com/story</link>
+      <comments>https://news.ycombinator.com/item?id=1</comments>
+    </item>
+    <item>
+      <title>Blog</title>
+      <link>https://example.com/post</link>
+      <comments>https://example.com/post#comments</comments>
+      <wfw:commentRss>https://example.com/post/feed/</wfw:commentRss>
+    </item>
+    <item>
+      <title>Plain</title>
+      <link>https://example.com/plain</link>
+    </item>
+  </channel>
+</rss>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := [][2]string{
+		{"https://news.ycombinator.com/item?id=1", ""},
+		{"https://example.com/post#comments", "https://example.com/post/feed/"},
+		{"", ""},
+	}
+	for i, entry := range feed.Entries {
+		if entry.Discussion != want[i][0] || entry.CommentsFeed != want[i][1] {
+			t.Errorf("%s: got (%q, %q), want %q", entry.Title, entry.Discussion, entry.CommentsFeed, want[i])
+		}
+	}
+
+	atom, err := Parse([]byte(`<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>A</title>
+<entry><id>1</id><title>Threaded</title><link href="https://example.com/a/1"/><updated>2006-01-02T15:04:05Z</updated>
+<link rel="replies" type="application/atom+xml" href="https://example.com/a/1/comments.xml"/>
+<link rel="replies" type="text/html" href="https://example.com/a/1#comments"/>
+</entry></feed>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if e := atom.Entries[0]; e.Discussion != "https://example.com/a/1#comments" || e.CommentsFeed != "https://example.com/a/1/comments.xml" {
+		t.Errorf("atom: got (%q, %q)", e.Discussion, e.CommentsFeed)
+	}
+	if atom.Entries[0].Link != "https://example.com/a/1" {
+		t.Errorf("atom link = %q, replies links must not replace it", atom.Entries[0].Link)
+	}
+}
+
+func TestParse_Limits(t *testing.T) {
+	tests := map[string]string{
+		"deep": `<rss><channel><item><description>` + strings.Repeat("<div>", Limits.MaxDepth) +
+			`</description></item></channel></rss>`,
+		"many":   `<rss><channel>` + strings.Repeat("<item/>", Limits.MaxElements) + `</channel></rss>`,
+		"entity": `<!DOCTYPE rss [<!ENTITY a "aaaaaaaa"><!ENTITY b "&a;&a;&a;&a;">]><rss><channel><title>&b;</title></channel></rss>`,
+		"xxe":    `<?xml version="1.0"?><!DOCTYPE rss [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><rss><channel><title>&xxe;</title></channel></rss>`,
+		"large":  `<rss><channel><title>` + strings.Repeat("x", Limits.MaxBytes) + `</title></channel></rss>`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(data))
+			if !errors.Is(err, xmlguard.ErrUnsafe) {
+				t.Errorf("Parse() error = %v, want unsafe XML rejected", err)
+			}
+		})
+	}
+
+	// JSON feeds carry HTML in strings, which isn't structure
+	jsonFeed := `{"version":"https://jsonfeed.org/version/1.1","title":"J","items":[{"id":"1","content_html":"` +
+		strings.Repeat("<p>", Limits.MaxDepth+1) + `"}]}`
+	if _, err := Parse([]byte(jsonFeed)); err != nil {
+		t.Errorf("Parse() JSON feed error = %v", err)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add([]byte(rss20XML))
+	f.Add([]byte(atomXML))
+	f.Add([]byte(imagesXML))
+	f.Add([]byte(`{"version":"https://jsonfeed.org/version/1.1","title":"J","items":[{"id":"1","url":"https://example.com/1"}]}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		feed, err := Parse(data)
+		if err != nil {
+			return
+		}
+		for _, e := range feed.Entries {
+			if e.GUID == "" && e.Link != "" {
+				t.Errorf("entry with link %q has no GUID", e.Link)
+			}
+		}
+	})
+}
@@ -0,0 +1,187 @@
+// ABOUTME: Reads feed bodies in chunks, stopping at an item or size cap so huge feeds don't spike memory
+// ABOUTME: Cuts oversized XML feeds after their last complete item and closes the elements left open
+
+package parse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/harper/digest/internal/xmlguard"
+)
+
+// StreamLimits caps how much of a feed ReadFeed keeps. A zero field is
+// unlimited.
+type StreamLimits struct {
+	MaxItems int
+	MaxBytes int
+}
+
+// DefaultStreamLimits keeps a feed's first 5000 items or 8MB, whichever
+// ends first, which leaves the result inside Limits.
+var DefaultStreamLimits = StreamLimits{MaxItems: 5000, MaxBytes: 8 * 1024 * 1024}
+
+// ErrFeedTooLarge is returned for a feed over the byte cap that can't be
+// cut down to whole items.
+var ErrFeedTooLarge = errors.New("feed too large")
+
+// readChunk is how much ReadFeed reads at a time.
+const readChunk = 64 * 1024
+
+// ReadFeed reads a feed body from r, stopping once it holds l.MaxItems
+// items or l.MaxBytes bytes, so memory stays near the cap however large
+// the feed is. A feed cut short ends after its last complete item, with
+// the elements still open closed, so it parses like a shorter feed;
+// truncated reports the cut. Feeds list their newest items first, so the
+// items dropped are the oldest. JSON feeds and XML feeds without a
+// complete item inside the byte cap can't be cut and fail with
+// ErrFeedTooLarge.
+func ReadFeed(r io.Reader, l StreamLimits) (body []byte, truncated bool, err error) {
+	s := &feedStream{r: r, limits: l}
+	for !s.eof && len(bytes.TrimLeft(s.buf, "\xef\xbb\xbf \t\r\n")) == 0 {
+		if err := s.fill(); err != nil {
+			return nil, false, err
+		}
+	}
+	if !looksLikeXML(s.buf) {
+		for !s.eof {
+			if s.over() {
+				return nil, false, fmt.Errorf("%w: more than %d bytes", ErrFeedTooLarge, l.MaxBytes)
+			}
+			if err := s.fill(); err != nil {
+				return nil, false, err
+			}
+		}
+		if s.over() {
+			return nil, false, fmt.Errorf("%w: more than %d bytes", ErrFeedTooLarge, l.MaxBytes)
+		}
+		return s.buf, false, nil
+	}
+	return s.readXML()
+}
+
+// feedStream is a feed being read.
+type feedStream struct {
+	r      io.Reader
+	limits StreamLimits
+	buf    []byte
+	eof    bool
+
+	open  xmlguard.Stack // elements open at the scan position
+	items int            // complete items so far
+	cut   int            // end of the last complete item, or 0
+	kept  []string       // elements open at cut
+}
+
+// fill reads the next chunk of the feed.
+func (s *feedStream) fill() error {
+	if s.eof {
+		return nil
+	}
+	chunk := make([]byte, readChunk)
+	n, err := io.ReadFull(s.r, chunk)
+	s.buf = append(s.buf, chunk[:n]...)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		s.eof = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read feed: %w", err)
+	}
+	return nil
+}
+
+// over reports whether more than the byte cap has been read, though not
+// necessarily scanned.
+func (s *feedStream) over() bool {
+	return s.limits.MaxBytes > 0 && len(s.buf) > s.limits.MaxBytes
+}
+
+// readXML scans the feed's markup as it arrives, noting where each
+// top-level item ends, until the feed ends or a cap is reached.
+func (s *feedStream) readXML() ([]byte, bool, error) {
+	pos := 0
+	for {
+		m, ok := xmlguard.Next(s.buf, pos)
+		if ok && s.limits.MaxBytes > 0 && m.End > s.limits.MaxBytes {
+			return s.truncate()
+		}
+		if !ok {
+			if s.over() {
+				return s.truncate()
+			}
+			if s.eof {
+				// Whatever is left is text or malformed; the parser decides
+				return s.buf, false, nil
+			}
+			if err := s.fill(); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+		switch m.Kind {
+		case xmlguard.StartTag, xmlguard.EmptyTag:
+			item := isItemName(m.Name) && s.outerItem() < 0
+			if item && s.limits.MaxItems > 0 && s.items == s.limits.MaxItems {
+				return s.truncate()
+			}
+			if m.Nests() {
+				s.open.Push(m.Name)
+				if s.open.Depth() > Limits.MaxDepth {
+					return nil, false, fmt.Errorf("%w: nested more than %d elements deep", xmlguard.ErrUnsafe, Limits.MaxDepth)
+				}
+			} else if item {
+				s.itemEnded(m.End)
+			}
+		case xmlguard.EndTag:
+			outer := s.outerItem()
+			s.open.Pop(m.Name)
+			if outer >= 0 && s.open.Depth() <= outer {
+				s.itemEnded(m.End)
+			}
+		}
+		pos = m.End
+	}
+}
+
+// outerItem returns where the outermost open item sits in the open
+// elements, or -1 if no item is open. Items nested inside it, like an
+// entry quoted in another's source, aren't the feed's own.
+func (s *feedStream) outerItem() int {
+	for i, open := range s.open.Names() {
+		if isItemName(open) {
+			return i
+		}
+	}
+	return -1
+}
+
+// itemEnded records that an item ended at end.
+func (s *feedStream) itemEnded(end int) {
+	s.items++
+	s.cut = end
+	s.kept = append(s.kept[:0], s.open.Names()...)
+}
+
+// truncate ends the feed after its last complete item.
+func (s *feedStream) truncate() ([]byte, bool, error) {
+	if s.items == 0 {
+		return nil, false, fmt.Errorf("%w: no complete item in the first %d bytes", ErrFeedTooLarge, s.limits.MaxBytes)
+	}
+	body := s.buf[:s.cut:s.cut]
+	for i := len(s.kept) - 1; i >= 0; i-- {
+		body = append(body, "</"+s.kept[i]+">"...)
+	}
+	return body, true, nil
+}
+
+// isItemName reports whether name is an RSS item or Atom entry element.
+func isItemName(name string) bool {
+	if n := strings.IndexByte(name, ':'); n >= 0 {
+		name = name[n+1:]
+	}
+	return name == "item" || name == "entry"
+}
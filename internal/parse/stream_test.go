@@ -0,0 +1,154 @@
+// ABOUTME: Tests for reading feeds in chunks under item and size caps
+// ABOUTME: Covers whole feeds, cuts at the item and byte caps, JSON feeds, and stopping the read early
+
+package parse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// rssItems returns an RSS feed of n items, each padded with pad bytes.
+func rssItems(n, pad int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><rss version="2.0"><channel><title>Big</title>`)
+	for i := range n {
+		fmt.Fprintf(&b, "<item><guid>g%d</guid><title>Item %d</title><description><![CDATA[<p>%s</p>]]></description></item>\n",
+			i, i, strings.Repeat("x", pad))
+	}
+	b.WriteString(`</channel></rss>`)
+	return b.String()
+}
+
+func TestReadFeed_Whole(t *testing.T) {
+	feed := rssItems(3, 10)
+	body, truncated, err := ReadFeed(strings.NewReader(feed), StreamLimits{MaxItems: 3, MaxBytes: len(feed)})
+	if err != nil {
+		t.Fatalf("ReadFeed: %v", err)
+	}
+	if truncated || string(body) != feed {
+		t.Errorf("ReadFeed changed a feed within its limits (truncated=%v)", truncated)
+	}
+}
+
+func TestReadFeed_ItemCap(t *testing.T) {
+	body, truncated, err := ReadFeed(strings.NewReader(rssItems(10, 10)), StreamLimits{MaxItems: 3})
+	if err != nil {
+		t.Fatalf("ReadFeed: %v", err)
+	}
+	if !truncated {
+		t.Error("expected truncated")
+	}
+	parsed, err := Parse(body)
+	if err != nil {
+		t.Fatalf("Parse of cut feed: %v\n%s", err, body)
+	}
+	if len(parsed.Entries) != 3 || parsed.Entries[2].GUID != "g2" || parsed.Title != "Big" {
+		t.Errorf("cut feed = %q with %d entries, want Big with g0-g2", parsed.Title, len(parsed.Entries))
+	}
+}
+
+func TestReadFeed_ByteCap(t *testing.T) {
+	atom := `<feed xmlns="http://www.w3.org/2005/Atom"><title>A</title>` +
+		strings.Repeat(`<entry><id>e</id><title>T</title><content type="html">`+strings.Repeat("y", 1000)+`</content></entry>`, 200) +
+		`</feed>`
+	counter := &countingReader{r: strings.NewReader(atom)}
+	body, truncated, err := ReadFeed(counter, StreamLimits{MaxBytes: 5000})
+	if err != nil {
+		t.Fatalf("ReadFeed: %v", err)
+	}
+	if !truncated || len(body) > 5000 {
+		t.Fatalf("truncated = %v, %d bytes; want a cut under 5000", truncated, len(body))
+	}
+	parsed, err := Parse(body)
+	if err != nil {
+		t.Fatalf("Parse of cut feed: %v\n%s", err, body)
+	}
+	if n := len(parsed.Entries); n == 0 || n >= 200 {
+		t.Errorf("cut feed has %d entries", n)
+	}
+	if counter.n >= len(atom) {
+		t.Error("ReadFeed read the whole feed instead of stopping at the cap")
+	}
+}
+
+func TestReadFeed_TooLarge(t *testing.T) {
+	noItem := `<rss><channel><title>` + strings.Repeat("z", 2000) + `</title></channel></rss>`
+	if _, _, err := ReadFeed(strings.NewReader(noItem), StreamLimits{MaxBytes: 1000}); !errors.Is(err, ErrFeedTooLarge) {
+		t.Errorf("feed without an item under the cap: err = %v, want ErrFeedTooLarge", err)
+	}
+
+	json := `{"version":"https://jsonfeed.org/version/1.1","title":"` + strings.Repeat("j", 2000) + `"}`
+	if _, _, err := ReadFeed(strings.NewReader(json), StreamLimits{MaxBytes: 1000}); !errors.Is(err, ErrFeedTooLarge) {
+		t.Errorf("JSON feed over the cap: err = %v, want ErrFeedTooLarge", err)
+	}
+	body, truncated, err := ReadFeed(strings.NewReader(json), StreamLimits{MaxBytes: 4000})
+	if err != nil || truncated || string(body) != json {
+		t.Errorf("JSON feed under the cap: truncated = %v, err = %v", truncated, err)
+	}
+}
+
+func TestReadFeed_NestedItems(t *testing.T) {
+	// An entry quoted inside another doesn't count as one of the feed's own
+	feed := `<feed><entry><id>1</id><source><entry><id>q</id></entry></source></entry><entry><id>2</id></entry></feed>`
+	body, truncated, err := ReadFeed(strings.NewReader(feed), StreamLimits{MaxItems: 1})
+	if err != nil {
+		t.Fatalf("ReadFeed: %v", err)
+	}
+	want := `<feed><entry><id>1</id><source><entry><id>q</id></entry></source></entry></feed>`
+	if !truncated || string(body) != want {
+		t.Errorf("ReadFeed = %q, want %q", body, want)
+	}
+}
+
+func TestReadFeed_LenientMarkup(t *testing.T) {
+	// RSS <link> shares its name with a void HTML element, and a stray end
+	// tag closes nothing; neither may lose track of the open elements
+	feed := `<rss><channel><title>L</title></item>` +
+		`<item><title>1</title><link>https://example.com/1</link></item>` +
+		`<item><title>2</title><link>https://example.com/2</link></item></channel></rss>`
+	body, truncated, err := ReadFeed(strings.NewReader(feed), StreamLimits{MaxItems: 1})
+	if err != nil {
+		t.Fatalf("ReadFeed: %v", err)
+	}
+	if !truncated || !strings.HasSuffix(string(body), `<link>https://example.com/1</link></item></channel></rss>`) {
+		t.Errorf("ReadFeed = %q", body)
+	}
+}
+
+func TestReadFeed_ReadError(t *testing.T) {
+	r := io.MultiReader(strings.NewReader("<rss>"), &failingReader{})
+	if _, _, err := ReadFeed(r, DefaultStreamLimits); err == nil {
+		t.Error("expected the read error")
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func FuzzReadFeed(f *testing.F) {
+	f.Add([]byte(rssItems(3, 5)), 2, 300)
+	f.Add([]byte(atomXML), 1, 0)
+	f.Fuzz(func(t *testing.T, data []byte, items, size int) {
+		body, truncated, err := ReadFeed(bytes.NewReader(data), StreamLimits{MaxItems: items, MaxBytes: size})
+		if err == nil && !truncated && !bytes.Equal(body, data) {
+			t.Error("untruncated body differs from the feed")
+		}
+	})
+}
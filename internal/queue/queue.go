@@ -0,0 +1,116 @@
+// ABOUTME: Reading queue order for 'digest next', which serves unread entries one at a time
+// ABOUTME: Picks high-priority feeds first, then oldest first, by aggregator score, or taking turns between feeds
+
+package queue
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// Queue orders.
+const (
+	// Oldest serves the longest-waiting entry first.
+	Oldest = "oldest"
+	// Score serves the entry with the most aggregator points first, then
+	// the oldest of the unscored ones.
+	Score = "score"
+	// RoundRobin takes turns between feeds: it serves the oldest entry of
+	// the feed read from least recently, so one busy feed can't crowd out
+	// the rest.
+	RoundRobin = "round-robin"
+)
+
+// Orders lists the queue orders.
+var Orders = []string{Oldest, Score, RoundRobin}
+
+// ValidateOrder checks that order is one of Orders.
+func ValidateOrder(order string) error {
+	for _, o := range Orders {
+		if order == o {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown order %q (want %s)", order, strings.Join(Orders, ", "))
+}
+
+// Next returns the entry to read next from unread in the given order, or nil
+// if there is none. Entries kept unread are skipped, since reading them
+// doesn't take them off the queue. lastRead maps feed IDs to when an entry
+// of the feed was last read; only RoundRobin uses it, and feeds missing
+// from it go first. priorities maps feed IDs to their priority: whatever
+// the order, high-priority feeds are served first and low-priority feeds
+// last, and feeds missing from it count as normal.
+func Next(unread []*models.Entry, lastRead map[string]time.Time, priorities map[string]string, order string) *models.Entry {
+	var next *models.Entry
+	for _, e := range unread {
+		if e.Read || e.KeepUnread {
+			continue
+		}
+		if next == nil {
+			next = e
+			continue
+		}
+		if a, b := models.PriorityRank(priorities[e.FeedID]), models.PriorityRank(priorities[next.FeedID]); a != b {
+			if a < b {
+				next = e
+			}
+			continue
+		}
+		if before(e, next, lastRead, order) {
+			next = e
+		}
+	}
+	return next
+}
+
+// LastRead returns when an entry of each feed was last read, from entries
+// of any read state.
+func LastRead(entries []*models.Entry) map[string]time.Time {
+	last := make(map[string]time.Time)
+	for _, e := range entries {
+		if e.ReadAt != nil && e.ReadAt.After(last[e.FeedID]) {
+			last[e.FeedID] = *e.ReadAt
+		}
+	}
+	return last
+}
+
+// Priorities maps the IDs of feeds to their priority, for Next.
+func Priorities(feeds []*models.Feed) map[string]string {
+	priorities := make(map[string]string, len(feeds))
+	for _, f := range feeds {
+		priorities[f.ID] = f.Priority
+	}
+	return priorities
+}
+
+// before reports whether a comes before b in order.
+func before(a, b *models.Entry, lastRead map[string]time.Time, order string) bool {
+	switch order {
+	case Score:
+		if (a.Score == nil) != (b.Score == nil) {
+			return a.Score != nil
+		}
+		if a.Score != nil && *a.Score != *b.Score {
+			return *a.Score > *b.Score
+		}
+	case RoundRobin:
+		if la, lb := lastRead[a.FeedID], lastRead[b.FeedID]; !la.Equal(lb) {
+			return la.Before(lb)
+		}
+	}
+	return waiting(a).Before(waiting(b))
+}
+
+// waiting returns when an entry was published, or first stored if it has
+// no publish date.
+func waiting(e *models.Entry) time.Time {
+	if e.PublishedAt != nil {
+		return *e.PublishedAt
+	}
+	return e.CreatedAt
+}
@@ -0,0 +1,130 @@
+// ABOUTME: Tests for reading queue order
+// ABOUTME: Covers oldest-first, score, and round-robin picks, feed priority, kept entries, and snoozes
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(id, feed string, hoursAgo int, score *int) *models.Entry {
+		e := models.NewEntry(feed, id, id)
+		e.ID = id
+		published := base.Add(-time.Duration(hoursAgo) * time.Hour)
+		e.PublishedAt = &published
+		e.Score = score
+		return e
+	}
+	points := func(n int) *int { return &n }
+
+	busyOld := entry("busy-old", "busy", 10, nil)
+	busyNew := entry("busy-new", "busy", 1, points(300))
+	quiet := entry("quiet", "quiet", 5, points(20))
+	kept := entry("kept", "quiet", 48, nil)
+	kept.KeepUnread = true
+	unread := []*models.Entry{busyNew, quiet, busyOld, kept}
+
+	tests := []struct {
+		order    string
+		lastRead map[string]time.Time
+		want     string
+	}{
+		{Oldest, nil, "busy-old"},
+		{Score, nil, "busy-new"},
+		{RoundRobin, nil, "busy-old"},
+		{RoundRobin, map[string]time.Time{"busy": base}, "quiet"},
+		{RoundRobin, map[string]time.Time{"busy": base, "quiet": base.Add(time.Minute)}, "busy-old"},
+	}
+	for _, tt := range tests {
+		got := Next(unread, tt.lastRead, nil, tt.order)
+		if got == nil || got.ID != tt.want {
+			t.Errorf("Next(%s, %v) = %v, want %s", tt.order, tt.lastRead, got, tt.want)
+		}
+	}
+
+	if got := Next([]*models.Entry{kept}, nil, nil, Oldest); got != nil {
+		t.Errorf("Next over kept entries = %s, want nil", got.ID)
+	}
+
+	// Priority comes before every order
+	for _, order := range Orders {
+		if got := Next(unread, nil, map[string]string{"quiet": models.PriorityHigh}, order); got == nil || got.ID != "quiet" {
+			t.Errorf("Next(%s) with a high-priority feed = %v, want quiet", order, got)
+		}
+		if got := Next(unread, nil, map[string]string{"busy": models.PriorityLow}, order); got == nil || got.ID != "quiet" {
+			t.Errorf("Next(%s) with a low-priority feed = %v, want quiet", order, got)
+		}
+	}
+}
+
+func TestPriorities(t *testing.T) {
+	high := models.NewFeed("https://high.example/feed")
+	high.Priority = models.PriorityHigh
+	normal := models.NewFeed("https://normal.example/feed")
+
+	priorities := Priorities([]*models.Feed{high, normal})
+	if priorities[high.ID] != models.PriorityHigh || priorities[normal.ID] != models.PriorityNormal {
+		t.Errorf("Priorities = %v", priorities)
+	}
+}
+
+func TestLastRead(t *testing.T) {
+	earlier := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	a := models.NewEntry("a", "1", "one")
+	a.ReadAt = &earlier
+	b := models.NewEntry("a", "2", "two")
+	b.ReadAt = &later
+	c := models.NewEntry("b", "3", "three")
+
+	last := LastRead([]*models.Entry{a, b, c})
+	if !last["a"].Equal(later) {
+		t.Errorf("last read of a = %v, want %v", last["a"], later)
+	}
+	if _, ok := last["b"]; ok {
+		t.Errorf("feed b was never read but has %v", last["b"])
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	for _, order := range Orders {
+		if err := ValidateOrder(order); err != nil {
+			t.Errorf("ValidateOrder(%q) = %v", order, err)
+		}
+	}
+	if err := ValidateOrder("newest"); err == nil {
+		t.Error("expected an error for an unknown order")
+	}
+}
+
+func TestSnoozes(t *testing.T) {
+	path := SnoozePath(t.TempDir())
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := LoadSnoozes(path)
+	if err != nil || len(s) != 0 {
+		t.Fatalf("expected no snoozes for a missing file, got %v, %v", s, err)
+	}
+	s["later"] = now.Add(time.Hour)
+	s["over"] = now.Add(-time.Hour)
+	if active := s.Active(now); !active["later"] || active["over"] {
+		t.Errorf("Active = %v, want only later", active)
+	}
+
+	// Expired snoozes aren't kept
+	if err := SaveSnoozes(path, s, now); err != nil {
+		t.Fatalf("SaveSnoozes: %v", err)
+	}
+	loaded, err := LoadSnoozes(path)
+	if err != nil {
+		t.Fatalf("LoadSnoozes: %v", err)
+	}
+	if len(loaded) != 1 || !loaded["later"].Equal(now.Add(time.Hour)) {
+		t.Errorf("LoadSnoozes = %v", loaded)
+	}
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Snoozed entries, kept off the reading queue and out of triage until a set time
+// ABOUTME: Stored as JSON in the profile data directory, by entry ID; expired snoozes are dropped on save
+
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harperreed/mdstore"
+)
+
+// SnoozeFileName is the snoozed-entries file inside a profile data directory.
+const SnoozeFileName = "snoozed.json"
+
+// DefaultSnooze is how long an entry is snoozed for when no time is given.
+const DefaultSnooze = 24 * time.Hour
+
+// Snoozes maps the IDs of snoozed entries to when they come back.
+type Snoozes map[string]time.Time
+
+// SnoozePath returns the snoozed-entries file for a profile data directory.
+func SnoozePath(profileDir string) string {
+	return filepath.Join(profileDir, SnoozeFileName)
+}
+
+// LoadSnoozes reads the snoozed entries. A missing file means none are snoozed.
+func LoadSnoozes(path string) (Snoozes, error) {
+	s := make(Snoozes)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("read snoozed entries: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse snoozed entries: %w", err)
+	}
+	return s, nil
+}
+
+// SaveSnoozes writes the entries still snoozed at now.
+func SaveSnoozes(path string, s Snoozes, now time.Time) error {
+	kept := make(Snoozes, len(s))
+	for id, until := range s {
+		if until.After(now) {
+			kept[id] = until
+		}
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode snoozed entries: %w", err)
+	}
+	if err := mdstore.AtomicWrite(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write snoozed entries: %w", err)
+	}
+	return nil
+}
+
+// Active returns the IDs of the entries still snoozed at now, in the form
+// Next's callers pass over.
+func (s Snoozes) Active(now time.Time) map[string]bool {
+	active := make(map[string]bool, len(s))
+	for id, until := range s {
+		if until.After(now) {
+			active[id] = true
+		}
+	}
+	return active
+}
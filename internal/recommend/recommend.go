@@ -0,0 +1,155 @@
+// ABOUTME: Feed recommendations from what the user actually reads
+// ABOUTME: Counts the domains read articles link out to, skipping subscribed sites and big platforms
+
+package recommend
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// maxSamples caps the example links kept per domain.
+const maxSamples = 3
+
+// platforms are domains linked constantly that aren't worth suggesting as
+// feeds: social networks, video hosts, shorteners, and reference sites.
+var platforms = map[string]bool{
+	"amazon.com": true, "apple.com": true, "bit.ly": true, "bsky.app": true, "facebook.com": true,
+	"google.com": true, "instagram.com": true, "linkedin.com": true, "mastodon.social": true,
+	"t.co": true, "threads.net": true, "tiktok.com": true, "twitter.com": true, "vimeo.com": true,
+	"wikipedia.org": true, "x.com": true, "youtu.be": true, "youtube.com": true,
+}
+
+// Domain is a site that read articles link to.
+type Domain struct {
+	Domain  string   `json:"domain"`
+	Links   int      `json:"links"`
+	Entries int      `json:"entries"`
+	Feeds   int      `json:"feeds"`
+	Samples []string `json:"samples"`
+}
+
+// Host returns the comparable host of a URL: lowercase, without "www." or
+// a port. It returns "" for URLs that aren't absolute http(s).
+func Host(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// isPlatform reports whether host is, or is a subdomain of, a platform.
+func isPlatform(host string) bool {
+	for h := host; h != ""; {
+		if platforms[h] {
+			return true
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	return false
+}
+
+// OutboundDomains ranks the domains linked from the entries' content by how
+// many entries and feeds link to them. Hosts in exclude (the user's own
+// subscriptions) and the entries' own sites are skipped.
+func OutboundDomains(entries []*models.Entry, exclude map[string]bool) []Domain {
+	type agg struct {
+		domain  Domain
+		entries map[string]bool
+		feeds   map[string]bool
+	}
+	byHost := make(map[string]*agg)
+
+	for _, entry := range entries {
+		if entry.Content == nil {
+			continue
+		}
+		own := ""
+		if entry.Link != nil {
+			own = Host(*entry.Link)
+		}
+		for _, link := range Links(*entry.Content) {
+			host := Host(link)
+			if host == "" || host == own || exclude[host] || isPlatform(host) {
+				continue
+			}
+			a, ok := byHost[host]
+			if !ok {
+				a = &agg{domain: Domain{Domain: host}, entries: make(map[string]bool), feeds: make(map[string]bool)}
+				byHost[host] = a
+			}
+			a.domain.Links++
+			a.entries[entry.ID] = true
+			a.feeds[entry.FeedID] = true
+			if len(a.domain.Samples) < maxSamples && !contains(a.domain.Samples, link) {
+				a.domain.Samples = append(a.domain.Samples, link)
+			}
+		}
+	}
+
+	domains := make([]Domain, 0, len(byHost))
+	for _, a := range byHost {
+		a.domain.Entries = len(a.entries)
+		a.domain.Feeds = len(a.feeds)
+		domains = append(domains, a.domain)
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		a, b := domains[i], domains[j]
+		if a.Entries != b.Entries {
+			return a.Entries > b.Entries
+		}
+		if a.Feeds != b.Feeds {
+			return a.Feeds > b.Feeds
+		}
+		if a.Links != b.Links {
+			return a.Links > b.Links
+		}
+		return a.Domain < b.Domain
+	})
+	return domains
+}
+
+// Links returns the absolute http(s) hrefs of the anchors in an HTML fragment.
+func Links(fragment string) []string {
+	var links []string
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "a" || !hasAttr {
+				continue
+			}
+			for {
+				key, val, more := z.TagAttr()
+				if string(key) == "href" && Host(string(val)) != "" {
+					links = append(links, string(val))
+				}
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
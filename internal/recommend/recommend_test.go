@@ -0,0 +1,67 @@
+// ABOUTME: Tests for outbound link analysis behind feed recommendations
+// ABOUTME: Covers link extraction, host normalization, exclusions, and ranking
+
+package recommend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestLinks(t *testing.T) {
+	got := Links(`<p>See <a href="https://a.example.com/post">this</a>, <a href="/relative">that</a>,
+		<a href="mailto:x@example.com">mail</a> and <a class="x" href="http://b.example.org">b</a>.</p>`)
+	want := []string{"https://a.example.com/post", "http://b.example.org"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Links = %v, want %v", got, want)
+	}
+}
+
+func TestHost(t *testing.T) {
+	tests := map[string]string{
+		"https://WWW.Example.com:8080/x": "example.com",
+		"http://blog.example.com":        "blog.example.com",
+		"ftp://example.com":              "",
+		"/relative":                      "",
+	}
+	for in, want := range tests {
+		if got := Host(in); got != want {
+			t.Errorf("Host(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOutboundDomains(t *testing.T) {
+	entry := func(id, feedID, link, body string) *models.Entry {
+		e := models.NewEntry(feedID, id, id)
+		e.ID = id
+		e.Link = &link
+		e.Content = &body
+		return e
+	}
+	entries := []*models.Entry{
+		entry("e1", "f1", "https://mine.example.com/1",
+			`<a href="https://rising.dev/a">a</a> <a href="https://rising.dev/b">b</a> <a href="https://mine.example.com/old">self</a>`),
+		entry("e2", "f2", "https://other.example.com/2",
+			`<a href="https://www.rising.dev/c">c</a> <a href="https://once.dev/x">x</a> <a href="https://twitter.com/someone">tweet</a>`),
+		entry("e3", "f2", "https://other.example.com/3",
+			`<a href="https://subscribed.example.net/post">sub</a> <a href="https://m.youtube.com/watch">video</a>`),
+	}
+
+	domains := OutboundDomains(entries, map[string]bool{"subscribed.example.net": true})
+	if len(domains) != 2 {
+		t.Fatalf("expected rising.dev and once.dev only, got %+v", domains)
+	}
+	top := domains[0]
+	if top.Domain != "rising.dev" || top.Links != 3 || top.Entries != 2 || top.Feeds != 2 {
+		t.Errorf("unexpected top domain: %+v", top)
+	}
+	if len(top.Samples) != maxSamples {
+		t.Errorf("expected %d samples, got %v", maxSamples, top.Samples)
+	}
+	if domains[1].Domain != "once.dev" {
+		t.Errorf("expected once.dev second, got %+v", domains[1])
+	}
+}
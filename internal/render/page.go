@@ -0,0 +1,75 @@
+// ABOUTME: Standalone HTML page for a digest, for people reading it in a browser
+// ABOUTME: Shows titles, links, and plain-text excerpts grouped by feed, never the entries' own markup
+
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/harper/digest/internal/content"
+)
+
+// excerptLength caps each entry's excerpt on the page.
+const excerptLength = 280
+
+var pageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"excerpt": func(html string) string {
+		r := []rune(strings.Join(strings.Fields(content.ToText(html)), " "))
+		if len(r) <= excerptLength {
+			return string(r)
+		}
+		return strings.TrimSpace(string(r[:excerptLength-1])) + "…"
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<meta name="referrer" content="no-referrer">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 42rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+article { margin: 1.25rem 0; }
+h3 { margin: 0; font-size: 1.05em; }
+.meta { font-size: 0.85em; color: #666; }
+p { margin: 0.25rem 0; }
+footer { margin-top: 2rem; font-size: 0.85em; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- range .Feeds}}
+<h2>{{.Title}}</h2>
+{{- range .Entries}}
+<article>
+<h3>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h3>
+<div class="meta">{{if .Author}}{{.Author}} · {{end}}{{if .Published}}{{.Published.Format "January 2, 2006"}}{{end}}{{if .Discussion}} · <a href="{{.Discussion}}">discussion</a>{{end}}</div>
+{{- with excerpt .Content}}
+<p>{{.}}</p>
+{{- end}}
+</article>
+{{- end}}
+{{- else}}
+<p>Nothing new in this digest.</p>
+{{- end}}
+<footer>{{.Footer}}</footer>
+</body>
+</html>
+`))
+
+// WritePage writes d as a standalone HTML page ending with footer. Entries
+// show only their titles, links, and short plain-text excerpts.
+func WritePage(w io.Writer, d *Digest, footer string) error {
+	data := struct {
+		*Digest
+		Footer string
+	}{d, footer}
+	if err := pageTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("render page: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// ABOUTME: Go template rendering for digests and exports, with user templates overriding built-ins
+// ABOUTME: Builds the feed and entry data templates see and loads templates by name or path
+
+package render
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/discussion"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/stories"
+)
+
+//go:embed builtin/*.tmpl
+var builtins embed.FS
+
+// Ext is the file extension of template files.
+const Ext = ".tmpl"
+
+// Digest is the data a template renders.
+type Digest struct {
+	Title     string
+	Generated time.Time
+	// Feeds are the feeds with entries, in the order given, each with its
+	// entries in the order given.
+	Feeds []Feed
+	// Entries are every entry, in the order given.
+	Entries []Entry
+	// Stories are the entries with near-identical titles from different
+	// feeds collapsed into one, in the order each first appears.
+	Stories []Story
+}
+
+// Story is an entry and the same story from other feeds.
+type Story struct {
+	Entry
+	// Feeds are the titles of every feed that covered the story, the
+	// entry's own first.
+	Feeds []string
+	// Others are the same story from the other feeds.
+	Others []Entry
+}
+
+// Feed is a feed and its entries.
+type Feed struct {
+	ID      string
+	URL     string
+	Title   string
+	Folder  string
+	Entries []Entry
+}
+
+// Entry is one entry as templates see it; missing fields are empty.
+type Entry struct {
+	ID         string
+	Title      string
+	Link       string
+	Author     string
+	Feed       string // Title of the entry's feed
+	Published  *time.Time
+	Read       bool
+	Image      string // Lead image URL
+	Discussion string // Comment thread page, such as the Hacker News item
+	Score      *int   // Aggregator points, nil for other entries
+	Comments   *int   // Aggregator comment count, nil for other entries
+	Content    string // As stored, usually HTML; see the markdown and text functions
+}
+
+// NewDigest groups entries under their feeds. Feeds without entries are
+// left out, and entries of feeds not given go only into Entries. folders
+// maps feed URLs to OPML folders and may be nil.
+func NewDigest(title string, generated time.Time, feeds []*models.Feed, entries []*models.Entry, folders map[string]string) *Digest {
+	d := &Digest{Title: title, Generated: generated}
+	names := make(map[string]string, len(feeds))
+	for _, f := range feeds {
+		names[f.ID] = feedTitle(f)
+	}
+
+	byFeed := make(map[string][]Entry)
+	for _, e := range entries {
+		entry := newEntry(e, names[e.FeedID])
+		d.Entries = append(d.Entries, entry)
+		byFeed[e.FeedID] = append(byFeed[e.FeedID], entry)
+	}
+
+	for _, group := range stories.Group(entries) {
+		story := Story{Entry: newEntry(group[0], names[group[0].FeedID])}
+		story.Feeds = append(story.Feeds, story.Feed)
+		for _, e := range group[1:] {
+			other := newEntry(e, names[e.FeedID])
+			story.Feeds = append(story.Feeds, other.Feed)
+			story.Others = append(story.Others, other)
+		}
+		d.Stories = append(d.Stories, story)
+	}
+
+	for _, f := range feeds {
+		if len(byFeed[f.ID]) == 0 {
+			continue
+		}
+		d.Feeds = append(d.Feeds, Feed{
+			ID:      f.ID,
+			URL:     f.URL,
+			Title:   names[f.ID],
+			Folder:  folders[f.URL],
+			Entries: byFeed[f.ID],
+		})
+	}
+	return d
+}
+
+func feedTitle(f *models.Feed) string {
+	if f.Title != nil && *f.Title != "" {
+		return *f.Title
+	}
+	return f.URL
+}
+
+func newEntry(e *models.Entry, feed string) Entry {
+	entry := Entry{
+		ID:        e.ID,
+		Title:     e.GetTitle(),
+		Feed:      feed,
+		Published: e.PublishedAt,
+		Read:      e.Read,
+		Score:     e.Score,
+		Comments:  e.CommentCount,
+	}
+	if e.Link != nil {
+		entry.Link = *e.Link
+	}
+	if e.ImageURL != nil {
+		entry.Image = *e.ImageURL
+	}
+	entry.Discussion = discussion.Thread(e)
+	if e.Author != nil {
+		entry.Author = *e.Author
+	}
+	if e.Content != nil {
+		entry.Content = *e.Content
+	}
+	return entry
+}
+
+// funcs are the functions templates can call beyond the text/template
+// built-ins.
+var funcs = template.FuncMap{
+	// date formats a time or *time.Time with a Go layout; nil is empty
+	"date": func(layout string, t any) string {
+		switch v := t.(type) {
+		case time.Time:
+			return v.Format(layout)
+		case *time.Time:
+			if v != nil {
+				return v.Format(layout)
+			}
+		}
+		return ""
+	},
+	"join":     func(sep string, s []string) string { return strings.Join(s, sep) },
+	"markdown": content.ToMarkdown,
+	"text":     content.ToText,
+	// truncate cuts s to at most n characters, ending in "…" when cut
+	"truncate": func(n int, s string) string {
+		r := []rune(s)
+		if len(r) <= n {
+			return s
+		}
+		return strings.TrimSpace(string(r[:max(n-1, 0)])) + "…"
+	},
+}
+
+// Load finds the template called name: a file path if name has a path
+// separator or the template extension, else name.tmpl in dir, else the
+// built-in template of that name.
+func Load(dir, name string) (*template.Template, error) {
+	if strings.ContainsRune(name, os.PathSeparator) || strings.HasSuffix(name, Ext) {
+		return parseFile(name)
+	}
+	if dir != "" {
+		path := filepath.Join(dir, name+Ext)
+		if _, err := os.Stat(path); err == nil {
+			return parseFile(path)
+		}
+	}
+	data, err := builtins.ReadFile("builtin/" + name + Ext)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no template %q in %s and no built-in of that name", name, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parse(name, string(data))
+}
+
+// Builtin returns the source of a built-in template, for copying into the
+// templates directory as a starting point.
+func Builtin(name string) (string, bool) {
+	data, err := builtins.ReadFile("builtin/" + name + Ext)
+	return string(data), err == nil
+}
+
+func parseFile(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+	return parse(filepath.Base(path), string(data))
+}
+
+func parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Execute renders d with tmpl to w.
+func Execute(w io.Writer, tmpl *template.Template, d *Digest) error {
+	if err := tmpl.Execute(w, d); err != nil {
+		return fmt.Errorf("render template %s: %w", tmpl.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// ABOUTME: Tests for template rendering of digests and exports
+// ABOUTME: Verifies grouping by feed, template lookup order, and the template functions
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func testDigest() *Digest {
+	blog := models.NewFeed("https://example.com/feed.xml")
+	title := "Example Blog"
+	blog.Title = &title
+	quiet := models.NewFeed("https://quiet.example.com/feed.xml")
+
+	published := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	first := models.NewEntry(blog.ID, "1", "First Post")
+	link := "https://example.com/first"
+	body := "<p>Hello <b>world</b>, this is a post.</p>"
+	first.Link, first.Content, first.PublishedAt = &link, &body, &published
+	second := models.NewEntry(blog.ID, "2", "Second Post")
+	second.MarkRead()
+
+	generated := time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC)
+	return NewDigest("digest", generated, []*models.Feed{blog, quiet}, []*models.Entry{first, second},
+		map[string]string{blog.URL: "Tech"})
+}
+
+func TestNewDigest(t *testing.T) {
+	d := testDigest()
+	if len(d.Feeds) != 1 {
+		t.Fatalf("expected only the feed with entries, got %d feeds", len(d.Feeds))
+	}
+	feed := d.Feeds[0]
+	if feed.Title != "Example Blog" || feed.Folder != "Tech" || len(feed.Entries) != 2 {
+		t.Errorf("unexpected feed %+v", feed)
+	}
+	if len(d.Entries) != 2 || d.Entries[0].Title != "First Post" || d.Entries[0].Feed != "Example Blog" {
+		t.Errorf("unexpected entries %+v", d.Entries)
+	}
+}
+
+func TestBuiltinMarkdown(t *testing.T) {
+	tmpl, err := Load(t.TempDir(), "markdown")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var out strings.Builder
+	if err := Execute(&out, tmpl, testDigest()); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := `# Feed Entries Export - January 6, 2026
+
+Generated: 2026-01-06T09:00:00Z
+
+## Example Blog
+
+### First Post
+
+- **Published:** January 5, 2026
+- **Link:** https://example.com/first
+
+### Second Post [read]
+
+
+`
+	if out.String() != want {
+		t.Errorf("markdown export =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestBuiltinBrief(t *testing.T) {
+	feed := func(title string) *models.Feed {
+		f := models.NewFeed("https://" + strings.ToLower(title) + ".example.com/feed.xml")
+		f.Title = &title
+		return f
+	}
+	alpha, beta, gamma := feed("Alpha"), feed("Beta"), feed("Gamma")
+	link := "https://example.com/go-release"
+	first := models.NewEntry(alpha.ID, "1", "Go 1.26 Released with Generic Methods")
+	first.Link = &link
+	entries := []*models.Entry{
+		first,
+		models.NewEntry(beta.ID, "2", "Unrelated News"),
+		models.NewEntry(gamma.ID, "3", "Go 1.26 released, with generic methods"),
+	}
+	d := NewDigest("digest", time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC), []*models.Feed{alpha, beta, gamma}, entries, nil)
+
+	if len(d.Stories) != 2 || len(d.Stories[0].Others) != 1 || d.Stories[0].Others[0].Feed != "Gamma" {
+		t.Fatalf("expected the two Go posts collapsed into one story, got %+v", d.Stories)
+	}
+
+	tmpl, err := Load(t.TempDir(), "brief")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var out strings.Builder
+	if err := Execute(&out, tmpl, d); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := `# Brief - January 6, 2026
+
+- [Go 1.26 Released with Generic Methods](https://example.com/go-release) — Alpha, Gamma
+- Unrelated News — Beta
+`
+	if out.String() != want {
+		t.Errorf("brief =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestLoadPrefersUserTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "markdown.tmpl"), []byte(`{{len .Entries}} entries`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "brief.tmpl")
+	brief := `{{range .Entries}}{{date "Jan 2" .Published}}|{{text .Content | truncate 12}}|{{.Link}}
+{{end}}`
+	if err := os.WriteFile(path, []byte(brief), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{"markdown", "2 entries"},
+		{path, "Jan 5|Hello world…|https://example.com/first\n||\n"},
+	} {
+		tmpl, err := Load(dir, tt.name)
+		if err != nil {
+			t.Fatalf("Load(%q): %v", tt.name, err)
+		}
+		var out strings.Builder
+		if err := Execute(&out, tmpl, testDigest()); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("Load(%q) rendered %q, want %q", tt.name, out.String(), tt.want)
+		}
+	}
+
+	if _, err := Load(dir, "missing"); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.tmpl"), []byte(`{{.Nope`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, "broken"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -0,0 +1,58 @@
+// ABOUTME: Unix run lock using a non-blocking syscall.Flock on the lock file.
+// ABOUTME: The kernel drops the lock when the holder exits, so leftover content means a stale holder.
+
+//go:build !windows
+
+package runlock
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// lockFile opens and flocks path. Content left behind by a holder that never
+// released is reported as stale.
+func lockFile(path string) (*os.File, *Holder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, nil, errHeld
+		}
+		return nil, nil, err
+	}
+
+	data, _ := io.ReadAll(f)
+	return f, parseHolder(data), nil
+}
+
+// unlockFile clears the holder record and drops the flock. The file itself is
+// left in place; removing it would let two processes lock different inodes.
+func unlockFile(f *os.File, _ string) error {
+	truncErr := f.Truncate(0)
+	unlockErr := syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	closeErr := f.Close()
+	return errors.Join(truncErr, unlockErr, closeErr)
+}
+
+// Held reports whether a live process holds the lock at path, without
+// disturbing it or any stale holder record.
+func Held(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return errors.Is(err, syscall.EWOULDBLOCK)
+	}
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return false
+}
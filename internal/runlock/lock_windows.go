@@ -0,0 +1,79 @@
+// ABOUTME: Windows run lock using an O_CREATE|O_EXCL lock file.
+// ABOUTME: A lock file whose recorded PID is no longer running is treated as stale and replaced.
+
+//go:build windows
+
+package runlock
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// emptyLockGrace is how long an empty lock file is trusted before it's treated as stale.
+const emptyLockGrace = time.Minute
+
+// lockFile exclusively creates path. An existing file whose holder process
+// is gone is removed and reported as stale.
+func lockFile(path string) (*os.File, *Holder, error) {
+	var stale *Holder
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
+		if err == nil {
+			return f, stale, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, nil, err
+		}
+
+		h := ReadHolder(path)
+		if h == nil {
+			// A holder that died between creating and writing the file
+			info, statErr := os.Stat(path)
+			if statErr != nil || time.Since(info.ModTime()) < emptyLockGrace {
+				return nil, nil, errHeld
+			}
+			h = &Holder{}
+		} else if processAlive(h.PID) {
+			return nil, nil, errHeld
+		}
+		stale = h
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, errHeld
+}
+
+// unlockFile closes and removes the lock file.
+func unlockFile(f *os.File, path string) error {
+	closeErr := f.Close()
+	removeErr := os.Remove(path)
+	return errors.Join(closeErr, removeErr)
+}
+
+// processAlive reports whether a process with pid exists. On Windows
+// os.FindProcess opens a handle and fails for exited processes.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// Held reports whether a live process holds the lock at path, without
+// disturbing it or any stale holder record.
+func Held(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	h := ReadHolder(path)
+	if h == nil {
+		return time.Since(info.ModTime()) < emptyLockGrace
+	}
+	return processAlive(h.PID)
+}
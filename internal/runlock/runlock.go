@@ -0,0 +1,128 @@
+// ABOUTME: Single-instance run lock so overlapping syncs don't race on the same profile
+// ABOUTME: Records the holder's PID and start time; platform locking lives in lock_unix.go and lock_windows.go
+
+package runlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the lock file name inside a profile data directory.
+const FileName = "sync.lock"
+
+// retryInterval is how often Acquire polls while waiting for the lock.
+const retryInterval = 250 * time.Millisecond
+
+// ErrLocked is returned when another process holds the lock.
+var ErrLocked = errors.New("another sync is already running")
+
+// errHeld is returned by the platform lock functions when the lock is taken.
+var errHeld = errors.New("lock held")
+
+// Holder describes the process recorded in a lock file.
+type Holder struct {
+	PID     int
+	Started time.Time
+}
+
+// Lock is a held run lock. Call Release when done.
+type Lock struct {
+	f    *os.File
+	path string
+
+	// Stale is set when a previous holder exited without releasing the lock,
+	// e.g. after a crash or kill -9.
+	Stale *Holder
+}
+
+// Path returns the lock file path for a profile data directory.
+func Path(profileDir string) string {
+	return filepath.Join(profileDir, FileName)
+}
+
+// Acquire takes the lock at path, waiting up to wait for another holder to
+// finish. A zero wait tries once. The returned error wraps ErrLocked when the
+// lock is still held, naming the holder when it is known.
+func Acquire(path string, wait time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		f, stale, err := lockFile(path)
+		if err == nil {
+			l := &Lock{f: f, path: path, Stale: stale}
+			if err := l.writeHolder(); err != nil {
+				_ = l.Release()
+				return nil, err
+			}
+			return l, nil
+		}
+		if !errors.Is(err, errHeld) {
+			return nil, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		if !time.Now().Before(deadline) {
+			if h := ReadHolder(path); h != nil {
+				return nil, fmt.Errorf("%w (pid %d since %s)", ErrLocked, h.PID, h.Started.Format(time.RFC3339))
+			}
+			return nil, ErrLocked
+		}
+		time.Sleep(min(retryInterval, time.Until(deadline)))
+	}
+}
+
+// Release unlocks and clears the lock file so the next holder won't see it as stale.
+func (l *Lock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := unlockFile(l.f, l.path)
+	l.f = nil
+	return err
+}
+
+// writeHolder records this process as the lock holder.
+func (l *Lock) writeHolder() error {
+	if err := l.f.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	content := fmt.Sprintf("%d %s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	if _, err := l.f.WriteAt([]byte(content), 0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// ReadHolder returns the holder recorded in the lock file at path, or nil if
+// the file is missing, empty, or unreadable.
+func ReadHolder(path string) *Holder {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return parseHolder(data)
+}
+
+// parseHolder parses "<pid> <rfc3339>" lock file content.
+func parseHolder(data []byte) *Holder {
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return nil
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil || pid <= 0 {
+		return nil
+	}
+	h := &Holder{PID: pid}
+	if len(fields) > 1 {
+		h.Started, _ = time.Parse(time.RFC3339, fields[1])
+	}
+	return h
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Tests for the single-instance run lock
+// ABOUTME: Covers contention, waiting, release, and stale holder detection
+
+package runlock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireAndRelease(t *testing.T) {
+	path := Path(t.TempDir())
+
+	l, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.Stale != nil {
+		t.Errorf("expected no stale holder on first acquire, got %+v", l.Stale)
+	}
+
+	h := ReadHolder(path)
+	if h == nil || h.PID != os.Getpid() {
+		t.Errorf("expected lock file to record this process, got %+v", h)
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if ReadHolder(path) != nil {
+		t.Error("expected holder record to be cleared on release")
+	}
+
+	// Released lock can be taken again and isn't reported stale
+	l2, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("reacquire failed: %v", err)
+	}
+	defer l2.Release()
+	if l2.Stale != nil {
+		t.Errorf("expected clean release not to look stale, got %+v", l2.Stale)
+	}
+}
+
+func TestAcquireContended(t *testing.T) {
+	path := Path(t.TempDir())
+
+	l, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer l.Release()
+
+	_, err = Acquire(path, 0)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if !Held(path) {
+		t.Error("expected Held to report the live lock")
+	}
+}
+
+func TestAcquireWaitsForRelease(t *testing.T) {
+	path := Path(t.TempDir())
+
+	l, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		l.Release()
+	}()
+
+	l2, err := Acquire(path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected wait to succeed once released, got %v", err)
+	}
+	l2.Release()
+}
+
+func TestAcquireDetectsStaleHolder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+
+	// Simulate a holder that was killed without releasing
+	if err := os.WriteFile(path, []byte("999999 2024-01-02T03:04:05Z\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if Held(path) {
+		t.Error("expected stale lock not to be reported as held")
+	}
+	if ReadHolder(path) == nil {
+		t.Error("expected Held not to clear the stale record")
+	}
+
+	l, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("expected stale lock to be taken over, got %v", err)
+	}
+	defer l.Release()
+
+	if l.Stale == nil || l.Stale.PID != 999999 {
+		t.Fatalf("expected stale holder pid 999999, got %+v", l.Stale)
+	}
+	if !l.Stale.Started.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected stale start time %v", l.Stale.Started)
+	}
+}
@@ -0,0 +1,287 @@
+// ABOUTME: Hacker News and Lobsters client for the points and comment counts of aggregator posts
+// ABOUTME: Finds an entry's post on a supported site and refreshes its stored score
+
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/discussion"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// Default API endpoints; an item's ID is appended to each.
+const (
+	DefaultHackerNewsURL = "https://hacker-news.firebaseio.com/v0/item/"
+	DefaultLobstersURL   = "https://lobste.rs/s/"
+)
+
+// requestTimeout bounds a single API request.
+const requestTimeout = 15 * time.Second
+
+// maxConsecutiveFailures ends a refresh early when the APIs keep failing,
+// as they do offline, rather than waiting out every request's timeout.
+const maxConsecutiveFailures = 3
+
+// Supported aggregator sites.
+const (
+	HackerNews = "hackernews"
+	Lobsters   = "lobsters"
+)
+
+// ErrNotFound is returned when the site has no such item, such as a
+// deleted post.
+var ErrNotFound = errors.New("item not found")
+
+// lobstersStory matches Lobsters story paths and captures the short ID.
+var lobstersStory = regexp.MustCompile(`^/s/([a-z0-9]+)`)
+
+// Item is a post on an aggregator site.
+type Item struct {
+	Site string
+	ID   string
+}
+
+// Stats are an item's current points and comment count.
+type Stats struct {
+	Score    int
+	Comments int
+}
+
+// Find returns the aggregator post an entry belongs to, from its discussion
+// thread or its own link. It reports false for entries that aren't posts on
+// a supported site.
+func Find(e *models.Entry) (Item, bool) {
+	if item, ok := parseItem(discussion.Thread(e)); ok {
+		return item, true
+	}
+	if e.Link != nil {
+		return parseItem(*e.Link)
+	}
+	return Item{}, false
+}
+
+// parseItem recognizes Hacker News item and Lobsters story URLs.
+func parseItem(link string) (Item, bool) {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return Item{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch {
+	case host == "news.ycombinator.com" && u.Path == "/item":
+		if id := u.Query().Get("id"); id != "" && strings.Trim(id, "0123456789") == "" {
+			return Item{Site: HackerNews, ID: id}, true
+		}
+	case host == "lobste.rs":
+		if m := lobstersStory.FindStringSubmatch(u.Path); m != nil {
+			return Item{Site: Lobsters, ID: m[1]}, true
+		}
+	}
+	return Item{}, false
+}
+
+// Client reads item stats from the Hacker News and Lobsters APIs.
+type Client struct {
+	HackerNewsURL string
+	LobstersURL   string
+	HTTP          *http.Client
+}
+
+// New returns a Client for the public Hacker News and Lobsters APIs.
+func New() *Client {
+	return &Client{
+		HackerNewsURL: DefaultHackerNewsURL,
+		LobstersURL:   DefaultLobstersURL,
+		HTTP:          &http.Client{Timeout: requestTimeout, Transport: fetch.Transport()},
+	}
+}
+
+// Fetch returns an item's current points and comment count.
+func (c *Client) Fetch(ctx context.Context, item Item) (Stats, error) {
+	switch item.Site {
+	case HackerNews:
+		// Hacker News calls the whole comment tree "descendants"
+		var body struct {
+			Score       int  `json:"score"`
+			Descendants int  `json:"descendants"`
+			Deleted     bool `json:"deleted"`
+			Dead        bool `json:"dead"`
+		}
+		found, err := c.get(ctx, c.HackerNewsURL+item.ID+".json", &body)
+		if err != nil {
+			return Stats{}, err
+		}
+		if !found || body.Deleted || body.Dead {
+			return Stats{}, ErrNotFound
+		}
+		return Stats{Score: body.Score, Comments: body.Descendants}, nil
+	case Lobsters:
+		var body struct {
+			Score        int `json:"score"`
+			CommentCount int `json:"comment_count"`
+		}
+		found, err := c.get(ctx, c.LobstersURL+item.ID+".json", &body)
+		if err != nil {
+			return Stats{}, err
+		}
+		if !found {
+			return Stats{}, ErrNotFound
+		}
+		return Stats{Score: body.Score, Comments: body.CommentCount}, nil
+	default:
+		return Stats{}, fmt.Errorf("unsupported site %q", item.Site)
+	}
+}
+
+// get decodes a JSON response into v. It reports false when the site has
+// no such item: a 404, or the "null" Hacker News answers for unknown IDs.
+func (c *Client) get(ctx context.Context, endpoint string, v any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("build score request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("request score: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("score request returned %s", resp.Status)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return false, fmt.Errorf("decode score: %w", err)
+	}
+	if string(raw) == "null" {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("decode score: %w", err)
+	}
+	return true, nil
+}
+
+// Default refresh policy.
+const (
+	DefaultInterval = time.Hour
+	DefaultMaxAge   = 72 * time.Hour
+	DefaultLimit    = 100
+)
+
+// Policy decides which entries a refresh rescores.
+type Policy struct {
+	// Interval is how long a score stays fresh before it's fetched again.
+	Interval time.Duration
+
+	// MaxAge stops rescoring entries first seen longer ago than this;
+	// their scores have mostly settled by then.
+	MaxAge time.Duration
+
+	// Limit caps the API requests one refresh makes.
+	Limit int
+}
+
+// DefaultPolicy returns the policy used when none is configured.
+func DefaultPolicy() Policy {
+	return Policy{Interval: DefaultInterval, MaxAge: DefaultMaxAge, Limit: DefaultLimit}
+}
+
+// Result summarizes a refresh.
+type Result struct {
+	Updated int
+	Failed  int
+}
+
+// Refresh fetches current scores for unread aggregator entries first seen
+// within the policy's MaxAge whose score is missing or older than its
+// Interval, stalest first, and stores them. Failures for single items are
+// counted rather than returned so one bad post doesn't stop the rest;
+// several in a row end the refresh.
+func Refresh(ctx context.Context, store storage.Store, client *Client, policy Policy, now time.Time) (Result, error) {
+	var result Result
+	unread := true
+	since := now.Add(-policy.MaxAge)
+	entries, err := store.ListEntries(ctx, &storage.EntryFilter{
+		UnreadOnly: &unread,
+		Since:      &since,
+		FirstSeen:  true,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	type candidate struct {
+		entry *models.Entry
+		item  Item
+	}
+	var due []candidate
+	for _, e := range entries {
+		if e.ScoredAt != nil && now.Sub(*e.ScoredAt) < policy.Interval {
+			continue
+		}
+		if item, ok := Find(e); ok {
+			due = append(due, candidate{e, item})
+		}
+	}
+	// Never-scored entries come first, then the stalest
+	staleness := func(c candidate) time.Time {
+		if c.entry.ScoredAt == nil {
+			return time.Time{}
+		}
+		return *c.entry.ScoredAt
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return staleness(due[i]).Before(staleness(due[j]))
+	})
+	if policy.Limit > 0 && len(due) > policy.Limit {
+		due = due[:policy.Limit]
+	}
+
+	failing := 0
+	for _, c := range due {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if failing == maxConsecutiveFailures {
+			break
+		}
+		scoredAt := now
+		stats, err := client.Fetch(ctx, c.item)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			result.Failed++
+			failing++
+			continue
+		}
+		failing = 0
+		// Deleted posts keep no score but wait out the interval like scored ones
+		c.entry.ScoredAt = &scoredAt
+		if err == nil {
+			c.entry.Score = &stats.Score
+			c.entry.CommentCount = &stats.Comments
+			result.Updated++
+		} else {
+			result.Failed++
+		}
+		if err := store.UpdateEntry(ctx, c.entry); err != nil {
+			return result, fmt.Errorf("failed to save score for %s: %w", c.entry.ID, err)
+		}
+	}
+	return result, nil
+}
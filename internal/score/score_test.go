@@ -0,0 +1,197 @@
+// ABOUTME: Tests for aggregator score lookups and refreshes
+// ABOUTME: Uses httptest stand-ins for the Hacker News and Lobsters APIs
+
+package score
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestFind(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name   string
+		entry  models.Entry
+		want   Item
+		wantOK bool
+	}{
+		{"hn discussion", models.Entry{Link: str("https://example.com/post"), DiscussionURL: str("https://news.ycombinator.com/item?id=42")}, Item{HackerNews, "42"}, true},
+		{"hn link", models.Entry{Link: str("https://news.ycombinator.com/item?id=7")}, Item{HackerNews, "7"}, true},
+		{"lobsters discussion", models.Entry{Link: str("https://example.com/post"), DiscussionURL: str("https://lobste.rs/s/abc123/some_title")}, Item{Lobsters, "abc123"}, true},
+		{"lobsters link", models.Entry{Link: str("https://lobste.rs/s/xyz789")}, Item{Lobsters, "xyz789"}, true},
+		{"blog comments", models.Entry{Link: str("https://example.com/post"), DiscussionURL: str("https://example.com/post#comments")}, Item{}, false},
+		{"hn front page", models.Entry{Link: str("https://news.ycombinator.com/news")}, Item{}, false},
+		{"no link", models.Entry{}, Item{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Find(&tt.entry)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Find() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// newTestClient serves canned API answers for HN item 42, Lobsters story
+// abc123, and nothing else.
+func newTestClient(t *testing.T) (*Client, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/hn/42.json":
+			w.Write([]byte(`{"id":42,"score":318,"descendants":97,"type":"story"}`))
+		case "/hn/404.json":
+			w.Write([]byte(`null`))
+		case "/lobsters/abc123.json":
+			w.Write([]byte(`{"short_id":"abc123","score":41,"comment_count":12}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := New()
+	client.HackerNewsURL = server.URL + "/hn/"
+	client.LobstersURL = server.URL + "/lobsters/"
+	return client, &requests
+}
+
+func TestFetch(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	stats, err := client.Fetch(ctx, Item{HackerNews, "42"})
+	if err != nil || stats != (Stats{Score: 318, Comments: 97}) {
+		t.Errorf("hacker news: got %+v (%v)", stats, err)
+	}
+	stats, err = client.Fetch(ctx, Item{Lobsters, "abc123"})
+	if err != nil || stats != (Stats{Score: 41, Comments: 12}) {
+		t.Errorf("lobsters: got %+v (%v)", stats, err)
+	}
+	if _, err := client.Fetch(ctx, Item{HackerNews, "404"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a null item, got %v", err)
+	}
+	if _, err := client.Fetch(ctx, Item{Lobsters, "gone"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing story, got %v", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+	feed := models.NewFeed("https://news.ycombinator.com/rss")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	addEntry := func(guid, thread string, seen time.Time, read bool) *models.Entry {
+		t.Helper()
+		e := models.NewEntry(feed.ID, guid, guid)
+		e.DiscussionURL = &thread
+		e.CreatedAt = seen
+		e.Read = read
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+		return e
+	}
+	hn := addEntry("hn", "https://news.ycombinator.com/item?id=42", now.Add(-time.Hour), false)
+	lobsters := addEntry("lobsters", "https://lobste.rs/s/abc123/title", now.Add(-time.Hour), false)
+	addEntry("read", "https://news.ycombinator.com/item?id=42", now.Add(-time.Hour), true)
+	addEntry("old", "https://news.ycombinator.com/item?id=42", now.Add(-30*24*time.Hour), false)
+	addEntry("blog", "https://example.com/post#comments", now.Add(-time.Hour), false)
+	addEntry("deleted", "https://news.ycombinator.com/item?id=404", now.Add(-time.Hour), false)
+
+	client, requests := newTestClient(t)
+	result, err := Refresh(ctx, store, client, DefaultPolicy(), now)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if result != (Result{Updated: 2, Failed: 1}) || *requests != 3 {
+		t.Errorf("expected 2 updated and 1 failed in 3 requests, got %+v in %d", result, *requests)
+	}
+
+	for id, want := range map[string]Stats{hn.ID: {318, 97}, lobsters.ID: {41, 12}} {
+		got, err := store.GetEntry(ctx, id)
+		if err != nil {
+			t.Fatalf("GetEntry: %v", err)
+		}
+		if got.Score == nil || *got.Score != want.Score || got.CommentCount == nil || *got.CommentCount != want.Comments {
+			t.Errorf("entry %s: got score %v and %v comments, want %+v", got.GUID, got.Score, got.CommentCount, want)
+		}
+		if got.ScoredAt == nil || !got.ScoredAt.Equal(now) {
+			t.Errorf("entry %s: ScoredAt = %v, want %v", got.GUID, got.ScoredAt, now)
+		}
+	}
+
+	// Fresh scores aren't fetched again until the interval passes
+	*requests = 0
+	if _, err := Refresh(ctx, store, client, DefaultPolicy(), now.Add(time.Minute)); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if *requests != 0 {
+		t.Errorf("expected no requests for freshly scored entries, got %d", *requests)
+	}
+	*requests = 0
+	if _, err := Refresh(ctx, store, client, DefaultPolicy(), now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if *requests != 3 {
+		t.Errorf("expected stale scores to be refreshed, got %d requests", *requests)
+	}
+}
+
+func TestRefreshStopsWhenAPIsFail(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+	feed := models.NewFeed("https://news.ycombinator.com/rss")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	for i := range 10 {
+		link := fmt.Sprintf("https://news.ycombinator.com/item?id=%d", i+1)
+		e := models.NewEntry(feed.ID, link, link)
+		e.Link = &link
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer server.Close()
+	client := New()
+	client.HackerNewsURL = server.URL + "/"
+
+	result, err := Refresh(ctx, store, client, DefaultPolicy(), time.Now())
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if requests != maxConsecutiveFailures || result.Failed != maxConsecutiveFailures {
+		t.Errorf("expected the refresh to give up after %d failures, got %d requests and %+v", maxConsecutiveFailures, requests, result)
+	}
+}
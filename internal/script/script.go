@@ -0,0 +1,296 @@
+// ABOUTME: Starlark ingest scripts that rewrite, tag, or drop new entries during sync
+// ABOUTME: Scripts define transform(entry, feed) and run sandboxed with a step budget and no file or network access
+
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// FuncName is the function every ingest script defines.
+const FuncName = "transform"
+
+// MaxSteps bounds the work one call of a script's transform may do, so a
+// runaway loop fails that entry instead of hanging the sync.
+const MaxSteps = 1_000_000
+
+// fileOptions are the Starlark dialect scripts are written in: the
+// standard one, plus while loops and top-level control flow.
+var fileOptions = &syntax.FileOptions{While: true, TopLevelControl: true, GlobalReassign: true}
+
+// Script is a loaded ingest script.
+type Script struct {
+	// Path is the file the script was loaded from.
+	Path      string
+	transform starlark.Callable
+}
+
+// Load reads, compiles, and runs the top level of the script at path,
+// which must define transform(entry, feed).
+func Load(path string) (*Script, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	return Compile(path, src)
+}
+
+// Compile is like Load with the script's source given.
+func Compile(path string, src []byte) (*Script, error) {
+	thread := &starlark.Thread{Name: path, Print: func(*starlark.Thread, string) {}}
+	thread.SetMaxExecutionSteps(MaxSteps)
+	globals, err := starlark.ExecFileOptions(fileOptions, thread, path, src, predeclared)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, describe(err))
+	}
+	fn, ok := globals[FuncName].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s: no %s(entry, feed) function", path, FuncName)
+	}
+	return &Script{Path: path, transform: fn}, nil
+}
+
+// LoadAll loads the scripts at paths, in order.
+func LoadAll(paths []string) ([]*Script, error) {
+	scripts := make([]*Script, 0, len(paths))
+	for _, path := range paths {
+		s, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, s)
+	}
+	return scripts, nil
+}
+
+// Apply runs the script on a new entry. The script sees the entry as a
+// dict it may change; changes to title, author, content, read,
+// keep_unread, and tags are copied back, unless it fails. It returns false
+// if the script returned False to drop the entry.
+func (s *Script) Apply(ctx context.Context, feed *models.Feed, entry *models.Entry) (bool, error) {
+	thread := &starlark.Thread{Name: s.Path, Print: func(*starlark.Thread, string) {}}
+	thread.SetMaxExecutionSteps(MaxSteps)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	d := entryDict(entry)
+	result, err := starlark.Call(thread, s.transform, starlark.Tuple{d, feedDict(feed)}, nil)
+	if err != nil {
+		return true, fmt.Errorf("%s: %w", s.Path, describe(err))
+	}
+	if result == starlark.False {
+		return false, nil
+	}
+	if err := applyDict(entry, d); err != nil {
+		return true, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	return true, nil
+}
+
+// Chain runs scripts in order as a sync.Options.Filter: an entry a script
+// drops isn't given to the rest. A script that fails leaves the entry
+// unchanged and is reported to warn, so one bad script can't stop a sync.
+func Chain(scripts []*Script, warn io.Writer) func(ctx context.Context, feed *models.Feed, entry *models.Entry) bool {
+	return func(ctx context.Context, feed *models.Feed, entry *models.Entry) bool {
+		for _, s := range scripts {
+			keep, err := s.Apply(ctx, feed, entry)
+			if err != nil {
+				if warn != nil {
+					fmt.Fprintf(warn, "warning: script %v\n", err)
+				}
+				continue
+			}
+			if !keep {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// describe adds the Starlark backtrace to script errors, which otherwise
+// say only what went wrong and not where.
+func describe(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+// predeclared are the names scripts can use beyond Starlark's builtins.
+var predeclared = starlark.StringDict{
+	"json":    json.Module,
+	"matches": starlark.NewBuiltin("matches", matches),
+}
+
+// matches reports whether the regular expression pattern matches s.
+func matches(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, s string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &pattern, "s", &s); err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.Bool(re.MatchString(s)), nil
+}
+
+// entryDict returns the dict a script sees an entry as.
+func entryDict(e *models.Entry) *starlark.Dict {
+	published := starlark.Value(starlark.None)
+	if e.PublishedAt != nil {
+		published = starlark.String(e.PublishedAt.UTC().Format(time.RFC3339))
+	}
+	tags := e.Tags()
+	tagValues := make([]starlark.Value, len(tags))
+	for i, t := range tags {
+		tagValues[i] = starlark.String(t)
+	}
+
+	d := starlark.NewDict(10)
+	set := func(k string, v starlark.Value) { _ = d.SetKey(starlark.String(k), v) }
+	set("id", starlark.String(e.ID))
+	set("guid", starlark.String(e.GUID))
+	set("title", starlark.String(e.GetTitle()))
+	set("link", starlark.String(deref(e.Link)))
+	set("author", starlark.String(deref(e.Author)))
+	set("content", starlark.String(deref(e.Content)))
+	set("published", published)
+	set("read", starlark.Bool(e.Read))
+	set("keep_unread", starlark.Bool(e.KeepUnread))
+	set("tags", starlark.NewList(tagValues))
+	return d
+}
+
+// feedDict returns the frozen dict a script sees a feed as.
+func feedDict(f *models.Feed) *starlark.Dict {
+	d := starlark.NewDict(4)
+	set := func(k string, v starlark.Value) { _ = d.SetKey(starlark.String(k), v) }
+	set("id", starlark.String(f.ID))
+	set("url", starlark.String(f.URL))
+	set("title", starlark.String(f.GetDisplayName()))
+	set("folder", starlark.String(f.Folder))
+	d.Freeze()
+	return d
+}
+
+// applyDict copies the fields a script may change from d back to e.
+func applyDict(e *models.Entry, d *starlark.Dict) error {
+	str := func(key string) (*string, error) {
+		v, found, _ := d.Get(starlark.String(key))
+		if !found {
+			return nil, nil
+		}
+		s, ok := starlark.AsString(v)
+		if !ok {
+			return nil, fmt.Errorf("entry[%q] must be a string, not %s", key, v.Type())
+		}
+		return &s, nil
+	}
+	boolean := func(key string) (bool, bool, error) {
+		v, found, _ := d.Get(starlark.String(key))
+		if !found {
+			return false, false, nil
+		}
+		b, ok := v.(starlark.Bool)
+		if !ok {
+			return false, false, fmt.Errorf("entry[%q] must be a bool, not %s", key, v.Type())
+		}
+		return bool(b), true, nil
+	}
+
+	title, err := str("title")
+	if err != nil {
+		return err
+	}
+	author, err := str("author")
+	if err != nil {
+		return err
+	}
+	content, err := str("content")
+	if err != nil {
+		return err
+	}
+	read, hasRead, err := boolean("read")
+	if err != nil {
+		return err
+	}
+	keep, hasKeep, err := boolean("keep_unread")
+	if err != nil {
+		return err
+	}
+	tags, err := tagList(d)
+	if err != nil {
+		return err
+	}
+
+	if title != nil && *title != e.GetTitle() {
+		e.Title = title
+	}
+	if author != nil {
+		e.Author = author
+	}
+	if content != nil && *content != deref(e.Content) {
+		e.Content = content
+	}
+	if hasKeep && keep {
+		e.KeepUnread = true
+		e.Read, e.ReadAt = false, nil
+	} else if hasRead && read && !e.Read {
+		e.MarkRead()
+	}
+	e.SetTags(tags)
+	return nil
+}
+
+// tagList returns the tags a script left on an entry.
+func tagList(d *starlark.Dict) ([]string, error) {
+	v, found, _ := d.Get(starlark.String("tags"))
+	if !found || v == starlark.None {
+		return nil, nil
+	}
+	iterable, ok := v.(starlark.Iterable)
+	if !ok {
+		return nil, fmt.Errorf(`entry["tags"] must be a list of strings, not %s`, v.Type())
+	}
+	var tags []string
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var x starlark.Value
+	for iter.Next(&x) {
+		s, ok := starlark.AsString(x)
+		if !ok {
+			return nil, fmt.Errorf(`entry["tags"] must be a list of strings, not of %s`, x.Type())
+		}
+		tags = append(tags, s)
+	}
+	return tags, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
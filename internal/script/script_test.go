@@ -0,0 +1,131 @@
+// ABOUTME: Tests for Starlark ingest scripts
+// ABOUTME: Checks rewrites, tags, drops, the step budget, and that failing scripts leave entries alone
+
+package script
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func compile(t *testing.T, src string) *Script {
+	t.Helper()
+	s, err := Compile("test.star", []byte(src))
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	return s
+}
+
+func TestApply(t *testing.T) {
+	s := compile(t, `
+def transform(entry, feed):
+    if matches(r"(?i)^sponsored", entry["title"]):
+        return False
+    entry["title"] = entry["title"].removeprefix("[Blog] ")
+    if "go" in entry["content"].lower().split():
+        entry["tags"].append("golang")
+    if feed["folder"] == "Later":
+        entry["read"] = True
+`)
+	feed := models.NewFeed("https://example.com/feed")
+	feed.Folder = "Later"
+
+	ad := models.NewEntry(feed.ID, "g1", "Sponsored: buy this")
+	if keep, err := s.Apply(context.Background(), feed, ad); err != nil || keep {
+		t.Errorf("Apply(ad) = %v, %v; want dropped", keep, err)
+	}
+
+	post := models.NewEntry(feed.ID, "g2", "[Blog] Why Go")
+	content := "A post about Go generics"
+	post.Content = &content
+	keep, err := s.Apply(context.Background(), feed, post)
+	if err != nil || !keep {
+		t.Fatalf("Apply(post) = %v, %v; want kept", keep, err)
+	}
+	if post.GetTitle() != "Why Go" {
+		t.Errorf("title = %q, want the prefix removed", post.GetTitle())
+	}
+	if tags := post.Tags(); len(tags) != 1 || tags[0] != "golang" {
+		t.Errorf("tags = %v, want [golang]", tags)
+	}
+	if !post.Read || post.ReadAt == nil {
+		t.Error("expected the post to be marked read")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for name, src := range map[string]string{
+		"syntax":      "def transform(entry, feed)\n    pass\n",
+		"no function": "x = 1\n",
+		"no load":     `load("other.star", "f")` + "\ndef transform(entry, feed):\n    pass\n",
+	} {
+		if _, err := Compile("bad.star", []byte(src)); err == nil {
+			t.Errorf("%s: Compile succeeded, want an error", name)
+		}
+	}
+}
+
+func TestStepBudget(t *testing.T) {
+	s := compile(t, `
+def transform(entry, feed):
+    while True:
+        pass
+`)
+	feed := models.NewFeed("https://example.com/feed")
+	entry := models.NewEntry(feed.ID, "g1", "Loop")
+	keep, err := s.Apply(context.Background(), feed, entry)
+	if err == nil || !keep {
+		t.Errorf("Apply = %v, %v; want the entry kept with an error", keep, err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	bad := compile(t, `
+def transform(entry, feed):
+    entry["title"] = "changed"
+    entry["tags"] = "not a list"
+`)
+	tag := compile(t, `
+def transform(entry, feed):
+    entry["tags"] = entry["tags"] + ["seen"]
+`)
+	drop := compile(t, `
+def transform(entry, feed):
+    return "seen" not in entry["tags"]
+`)
+	var warnings bytes.Buffer
+	filter := Chain([]*Script{bad, tag, drop}, &warnings)
+	feed := models.NewFeed("https://example.com/feed")
+	entry := models.NewEntry(feed.ID, "g1", "Original")
+
+	if filter(context.Background(), feed, entry) {
+		t.Error("expected the last script to drop the entry")
+	}
+	if entry.GetTitle() != "Original" {
+		t.Errorf("title = %q, a failing script shouldn't change the entry", entry.GetTitle())
+	}
+	if !strings.Contains(warnings.String(), `entry["tags"] must be a list`) {
+		t.Errorf("warnings = %q, want the bad script reported", warnings.String())
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tag.star")
+	if err := os.WriteFile(path, []byte("def transform(entry, feed):\n    pass\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	scripts, err := LoadAll([]string{path})
+	if err != nil || len(scripts) != 1 || scripts[0].Path != path {
+		t.Errorf("LoadAll = %v, %v", scripts, err)
+	}
+	if _, err := LoadAll([]string{filepath.Join(t.TempDir(), "missing.star")}); err == nil {
+		t.Error("expected an error for a missing script")
+	}
+}
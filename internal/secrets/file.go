@@ -0,0 +1,128 @@
+// ABOUTME: File-backed secrets provider for machines without an OS keychain
+// ABOUTME: Keeps secrets in a JSON file that must be readable only by its owner
+
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/harperreed/mdstore"
+)
+
+// File stores secrets in a JSON object of names to values. The file is
+// written with mode 0600, and refused if anyone else can read it.
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFile returns a provider for the secrets file at path. The file is
+// created on the first Set.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+func (f *File) Name() string { return "file " + f.path }
+
+func (f *File) Get(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	all, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := all[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (f *File) Set(name, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	all, err := f.load()
+	if err != nil {
+		return err
+	}
+	all[name] = value
+	return f.save(all)
+}
+
+func (f *File) Delete(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	all, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[name]; !ok {
+		return ErrNotFound
+	}
+	delete(all, name)
+	return f.save(all)
+}
+
+// Names lists the stored secret names, sorted.
+func (f *File) Names() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	all, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *File) load() (map[string]string, error) {
+	info, err := os.Stat(f.path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stat secrets file: %w", err)
+	}
+	// Windows has no Unix permission bits to check
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("secrets file %s is readable by other users; run chmod 600 on it", f.path)
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("read secrets file: %w", err)
+	}
+	all := map[string]string{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parse secrets file %s: %w", f.path, err)
+	}
+	return all, nil
+}
+
+func (f *File) save(all map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("create secrets directory: %w", err)
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode secrets: %w", err)
+	}
+	if err := mdstore.AtomicWrite(f.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write secrets file: %w", err)
+	}
+	// AtomicWrite's temp files are private already; make sure of it
+	if err := os.Chmod(f.path, 0600); err != nil {
+		return fmt.Errorf("restrict secrets file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// ABOUTME: OS keychain secrets provider using the macOS security tool or Linux secret-tool
+// ABOUTME: Shells out rather than linking platform libraries, so builds stay pure Go
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// KeyringService is the service name digest's keychain items are filed under.
+const KeyringService = "digest"
+
+// Keyring stores secrets in the login keychain on macOS and in the Secret
+// Service (GNOME Keyring, KWallet) on Linux and the BSDs.
+type Keyring struct {
+	service string
+}
+
+// NewKeyring returns a provider for digest's items in the OS keychain.
+func NewKeyring() *Keyring {
+	return &Keyring{service: KeyringService}
+}
+
+func (k *Keyring) Name() string {
+	if runtime.GOOS == "darwin" {
+		return "macOS keychain"
+	}
+	return "Secret Service keyring"
+}
+
+// Available reports whether a keychain can be reached from this process.
+func (k *Keyring) Available() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "windows":
+		return false
+	default:
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return false
+		}
+		// Secret Service is a D-Bus API; without a session bus (SSH, cron)
+		// secret-tool would hang or fail
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+			return true
+		}
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+			if _, err := os.Stat(filepath.Join(dir, "bus")); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (k *Keyring) Get(name string) (string, error) {
+	if runtime.GOOS == "darwin" {
+		out, err := run("", "security", "find-generic-password", "-s", k.service, "-a", name, "-w")
+		if err != nil {
+			return "", notFoundOr(err, 44)
+		}
+		return strings.TrimSuffix(out, "\n"), nil
+	}
+	out, err := run("", "secret-tool", "lookup", "service", k.service, "account", name)
+	if err != nil {
+		// secret-tool exits 1 with no output for a missing item; a message
+		// on stderr means something else went wrong
+		var te *toolError
+		if errors.As(err, &te) {
+			return "", fmt.Errorf("keyring: %w", err)
+		}
+		return "", notFoundOr(err, 1)
+	}
+	return out, nil
+}
+
+func (k *Keyring) Set(name, value string) error {
+	if runtime.GOOS == "darwin" {
+		// Pass the value on stdin through interactive mode so it never
+		// shows up in the process list
+		cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -w %s\n",
+			securityQuote(k.service), securityQuote(name), securityQuote(k.service+": "+name), securityQuote(value))
+		if _, err := run(cmd, "security", "-i"); err != nil {
+			return fmt.Errorf("store secret in keychain: %w", err)
+		}
+		return nil
+	}
+	if _, err := run(value, "secret-tool", "store", "--label", k.service+": "+name,
+		"service", k.service, "account", name); err != nil {
+		return fmt.Errorf("store secret in keyring: %w", err)
+	}
+	return nil
+}
+
+func (k *Keyring) Delete(name string) error {
+	if runtime.GOOS == "darwin" {
+		if _, err := run("", "security", "delete-generic-password", "-s", k.service, "-a", name); err != nil {
+			return notFoundOr(err, 44)
+		}
+		return nil
+	}
+	// secret-tool clear succeeds whether or not the item existed
+	if _, err := k.Get(name); err != nil {
+		return err
+	}
+	if _, err := run("", "secret-tool", "clear", "service", k.service, "account", name); err != nil {
+		return fmt.Errorf("delete secret from keyring: %w", err)
+	}
+	return nil
+}
+
+// run executes a keychain tool with stdin and returns its stdout. Errors
+// carry the tool's stderr.
+func run(stdin, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", &toolError{err: err, msg: msg}
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+type toolError struct {
+	err error
+	msg string
+}
+
+func (e *toolError) Error() string { return e.msg }
+func (e *toolError) Unwrap() error { return e.err }
+
+// notFoundOr maps the tool's "no such item" exit code to ErrNotFound.
+func notFoundOr(err error, notFoundCode int) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == notFoundCode {
+		return ErrNotFound
+	}
+	return fmt.Errorf("keychain: %w", err)
+}
+
+// securityQuote quotes an argument for the security tool's interactive mode.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
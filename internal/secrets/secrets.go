@@ -0,0 +1,182 @@
+// ABOUTME: Secrets provider abstraction for passwords and tokens kept out of config and storage
+// ABOUTME: Chains the environment, the OS keychain, and a private file, and resolves "secret:" references
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned when a provider has no secret by the given name.
+var ErrNotFound = errors.New("secret not found")
+
+// ErrReadOnly is returned by providers that can't store secrets.
+var ErrReadOnly = errors.New("secret provider is read-only")
+
+// Provider stores secrets by name.
+type Provider interface {
+	// Name describes where secrets are kept, for status output.
+	Name() string
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+}
+
+// RefPrefix marks a stored value as a reference to a secret rather than the
+// secret itself, e.g. a feed password of "secret:feed/<id>".
+const RefPrefix = "secret:"
+
+// Ref returns the reference to store in place of the secret called name.
+func Ref(name string) string {
+	return RefPrefix + name
+}
+
+// RefName returns the secret name a reference points at, or false if value
+// is not a reference.
+func RefName(value string) (string, bool) {
+	if !strings.HasPrefix(value, RefPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(value, RefPrefix), true
+}
+
+// Resolve returns value, or the secret it refers to if it is a reference.
+func Resolve(p Provider, value string) (string, error) {
+	name, ok := RefName(value)
+	if !ok {
+		return value, nil
+	}
+	if p == nil {
+		return "", fmt.Errorf("secret %q is referenced but no secrets provider is configured", name)
+	}
+	secret, err := p.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("look up secret %q: %w", name, err)
+	}
+	return secret, nil
+}
+
+// Backends accepted by Open.
+const (
+	BackendAuto    = "auto"
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+	BackendEnv     = "env"
+)
+
+// Open returns the provider for a configured backend. Every backend but
+// "env" also reads DIGEST_SECRET_* variables first, so automation can
+// override stored secrets. "auto" (or "") uses the OS keychain when one is
+// reachable and the file at filePath otherwise.
+func Open(backend, filePath string) (Provider, error) {
+	env := Env{}
+	switch backend {
+	case "", BackendAuto:
+		if kr := NewKeyring(); kr.Available() {
+			return Chain{env, kr}, nil
+		}
+		return Chain{env, NewFile(filePath)}, nil
+	case BackendKeyring:
+		kr := NewKeyring()
+		if !kr.Available() {
+			return nil, fmt.Errorf("no OS keychain available (macOS security or Linux secret-tool with a session bus)")
+		}
+		return Chain{env, kr}, nil
+	case BackendFile:
+		return Chain{env, NewFile(filePath)}, nil
+	case BackendEnv:
+		return env, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %q (use %s, %s, %s, or %s)",
+			backend, BackendAuto, BackendKeyring, BackendFile, BackendEnv)
+	}
+}
+
+// Chain looks secrets up in each provider in turn and stores them in the
+// first one that can.
+type Chain []Provider
+
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, p := range c {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ", then ")
+}
+
+func (c Chain) Get(name string) (string, error) {
+	for _, p := range c {
+		v, err := p.Get(name)
+		if err == nil {
+			return v, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return "", err
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (c Chain) Set(name, value string) error {
+	for _, p := range c {
+		err := p.Set(name, value)
+		if errors.Is(err, ErrReadOnly) {
+			continue
+		}
+		return err
+	}
+	return ErrReadOnly
+}
+
+// Delete removes the secret from every provider that has it.
+func (c Chain) Delete(name string) error {
+	deleted := false
+	for _, p := range c {
+		err := p.Delete(name)
+		switch {
+		case err == nil:
+			deleted = true
+		case errors.Is(err, ErrNotFound), errors.Is(err, ErrReadOnly):
+		default:
+			return err
+		}
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Env reads secrets from DIGEST_SECRET_<NAME> environment variables.
+type Env struct{}
+
+// EnvName returns the variable that holds the secret called name: upper
+// case, with anything but letters and digits replaced by underscores.
+func EnvName(name string) string {
+	var b strings.Builder
+	b.WriteString("DIGEST_SECRET_")
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func (Env) Name() string { return "environment" }
+
+func (Env) Get(name string) (string, error) {
+	if v := os.Getenv(EnvName(name)); v != "" {
+		return v, nil
+	}
+	return "", ErrNotFound
+}
+
+func (Env) Set(string, string) error { return ErrReadOnly }
+
+func (Env) Delete(string) error { return ErrReadOnly }
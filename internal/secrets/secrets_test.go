@@ -0,0 +1,127 @@
+// ABOUTME: Tests for the secrets providers, chaining, and secret references
+// ABOUTME: Covers the env and file providers; the OS keychain needs a desktop session
+
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("feed/abc-123"); got != "DIGEST_SECRET_FEED_ABC_123" {
+		t.Errorf("unexpected env name %q", got)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "secrets.json")
+	f := NewFile(path)
+
+	if _, err := f.Get("token"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from an empty file, got %v", err)
+	}
+	if err := f.Set("token", "s3cret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, err := NewFile(path).Get("token"); err != nil || v != "s3cret" {
+		t.Errorf("expected s3cret from a fresh provider, got %q (%v)", v, err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+		}
+
+		if err := os.Chmod(path, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Get("token"); err == nil {
+			t.Error("expected a world-readable secrets file to be refused")
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.Delete("token"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := f.Delete("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestChainPrefersEnvAndWritesToFirstWritable(t *testing.T) {
+	f := NewFile(filepath.Join(t.TempDir(), "secrets.json"))
+	chain := Chain{Env{}, f}
+
+	if err := chain.Set("api", "from-file"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, _ := f.Get("api"); v != "from-file" {
+		t.Errorf("expected the file to hold the secret, got %q", v)
+	}
+
+	t.Setenv(EnvName("api"), "from-env")
+	if v, err := chain.Get("api"); err != nil || v != "from-env" {
+		t.Errorf("expected the environment to win, got %q (%v)", v, err)
+	}
+
+	if err := chain.Delete("api"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := f.Get("api"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the file copy deleted, got %v", err)
+	}
+	if err := chain.Delete("api"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound when nothing writable has it, got %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	f := NewFile(filepath.Join(t.TempDir(), "secrets.json"))
+	if err := f.Set("feed/1", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := Resolve(f, "plain"); err != nil || v != "plain" {
+		t.Errorf("expected plain values passed through, got %q (%v)", v, err)
+	}
+	if v, err := Resolve(f, Ref("feed/1")); err != nil || v != "hunter2" {
+		t.Errorf("expected the referenced secret, got %q (%v)", v, err)
+	}
+	if _, err := Resolve(f, Ref("feed/2")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing secret, got %v", err)
+	}
+	if _, err := Resolve(nil, Ref("feed/1")); err == nil {
+		t.Error("expected an error resolving without a provider")
+	}
+}
+
+func TestOpenBackends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	if _, err := Open("vault", path); err == nil {
+		t.Error("expected an unknown backend to fail")
+	}
+	p, err := Open(BackendEnv, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Set("x", "y"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected the env backend to be read-only, got %v", err)
+	}
+	p, err = Open(BackendFile, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Set("x", "y"); err != nil {
+		t.Errorf("Set: %v", err)
+	}
+}
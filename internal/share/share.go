@@ -0,0 +1,213 @@
+// ABOUTME: Read-only digest pages shared with non-users behind unguessable links
+// ABOUTME: Renders a snapshot of entries as HTML and keeps it, with its expiry, in the profile data directory
+
+package share
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/render"
+)
+
+// tokenBytes is how much randomness goes into a share token.
+const tokenBytes = 16
+
+// indexFile lists the shares inside the shares directory.
+const indexFile = "shares.json"
+
+// ErrNotFound means no live share has the token: it never existed, was
+// revoked, or has expired.
+var ErrNotFound = errors.New("share not found")
+
+var tokenPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Share is one published digest page.
+type Share struct {
+	Token     string    `json:"token"`
+	Title     string    `json:"title"`
+	Since     string    `json:"since,omitempty"`
+	Entries   int       `json:"entries"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the share has expired at now.
+func (s Share) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// Dir returns where the shares of the profile in profileDir are kept.
+func Dir(profileDir string) string {
+	return filepath.Join(profileDir, "shares")
+}
+
+// NewToken returns a random token for a share's URL.
+func NewToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidToken reports whether s has the form of a share token, so it can
+// name a file safely.
+func ValidToken(s string) bool {
+	return tokenPattern.MatchString(s)
+}
+
+// ParseExpiry parses how long a share lives: a Go duration such as "12h",
+// or a whole number of days or weeks such as "7d" or "2w".
+func ParseExpiry(s string) (time.Duration, error) {
+	var d time.Duration
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		days, err := strconv.Atoi(n)
+		if err != nil {
+			return 0, fmt.Errorf("invalid expiry %q", s)
+		}
+		d = time.Duration(days) * 24 * time.Hour
+	} else if n, ok := strings.CutSuffix(s, "w"); ok {
+		weeks, err := strconv.Atoi(n)
+		if err != nil {
+			return 0, fmt.Errorf("invalid expiry %q", s)
+		}
+		d = time.Duration(weeks) * 7 * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid expiry %q (use a duration such as 12h, 7d, or 2w)", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("expiry must be positive, got %q", s)
+	}
+	return d, nil
+}
+
+// List returns the shares in dir, newest first, expired ones included.
+func List(dir string) ([]Share, error) {
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read shares: %w", err)
+	}
+	var shares []Share
+	if err := json.Unmarshal(data, &shares); err != nil {
+		return nil, fmt.Errorf("parse shares: %w", err)
+	}
+	slices.SortFunc(shares, func(a, b Share) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return shares, nil
+}
+
+func save(dir string, shares []Share) error {
+	data, err := json.MarshalIndent(shares, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode shares: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, indexFile), data, 0600); err != nil {
+		return fmt.Errorf("write shares: %w", err)
+	}
+	return nil
+}
+
+func pagePath(dir, token string) string {
+	return filepath.Join(dir, token+".html")
+}
+
+// Publish keeps page as the share's snapshot in dir and records the share.
+func Publish(dir string, s Share, page []byte) error {
+	if !ValidToken(s.Token) {
+		return fmt.Errorf("invalid share token %q", s.Token)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create shares directory: %w", err)
+	}
+	shares, err := List(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(pagePath(dir, s.Token), page, 0600); err != nil {
+		return fmt.Errorf("write share page: %w", err)
+	}
+	return save(dir, append(shares, s))
+}
+
+// Open returns the live share with token and the path of its page.
+func Open(dir, token string, now time.Time) (Share, string, error) {
+	if !ValidToken(token) {
+		return Share{}, "", ErrNotFound
+	}
+	shares, err := List(dir)
+	if err != nil {
+		return Share{}, "", err
+	}
+	for _, s := range shares {
+		if s.Token == token && !s.Expired(now) {
+			return s, pagePath(dir, token), nil
+		}
+	}
+	return Share{}, "", ErrNotFound
+}
+
+// Revoke deletes the share with token, live or expired.
+func Revoke(dir, token string) error {
+	shares, err := List(dir)
+	if err != nil {
+		return err
+	}
+	n := len(shares)
+	shares = slices.DeleteFunc(shares, func(s Share) bool { return s.Token == token })
+	if len(shares) == n {
+		return ErrNotFound
+	}
+	if err := os.Remove(pagePath(dir, token)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove share page: %w", err)
+	}
+	return save(dir, shares)
+}
+
+// Prune deletes the shares that have expired at now and returns how many.
+func Prune(dir string, now time.Time) (int, error) {
+	shares, err := List(dir)
+	if err != nil {
+		return 0, err
+	}
+	var live []Share
+	for _, s := range shares {
+		if !s.Expired(now) {
+			live = append(live, s)
+			continue
+		}
+		if err := os.Remove(pagePath(dir, s.Token)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, fmt.Errorf("remove share page: %w", err)
+		}
+	}
+	if len(live) == len(shares) {
+		return 0, nil
+	}
+	return len(shares) - len(live), save(dir, live)
+}
+
+// WriteHTML writes d as a standalone read-only page that says when it
+// expires.
+func WriteHTML(w io.Writer, d *render.Digest, expires time.Time) error {
+	footer := fmt.Sprintf("Shared %s with digest. This page expires %s.", d.Generated.Format("January 2, 2006"), expires.Format("January 2, 2006 15:04 MST"))
+	if err := render.WritePage(w, d, footer); err != nil {
+		return fmt.Errorf("failed to render shared digest: %w", err)
+	}
+	return nil
+}
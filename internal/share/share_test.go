@@ -0,0 +1,113 @@
+// ABOUTME: Tests for shared digest pages
+// ABOUTME: Covers expiry parsing, publishing, opening, revoking, pruning, and the rendered page
+
+package share
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/render"
+)
+
+func TestParseExpiry(t *testing.T) {
+	tests := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for in, want := range tests {
+		got, err := ParseExpiry(in)
+		if err != nil || got != want {
+			t.Errorf("ParseExpiry(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "soon", "0d", "-1h", "xd"} {
+		if _, err := ParseExpiry(in); err == nil {
+			t.Errorf("ParseExpiry(%q) should fail", in)
+		}
+	}
+}
+
+func TestPublishOpenRevoke(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	live, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ValidToken(live) {
+		t.Fatalf("NewToken gave an invalid token %q", live)
+	}
+	old, _ := NewToken()
+	if err := Publish(dir, Share{Token: live, Title: "Today", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}, []byte("live page")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := Publish(dir, Share{Token: old, Title: "Last week", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute)}, []byte("old page")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	s, path, err := Open(dir, live, now)
+	if err != nil || s.Title != "Today" {
+		t.Fatalf("Open(live) = %+v, %v", s, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "live page" {
+		t.Errorf("expected the live page, got %q", data)
+	}
+	for _, token := range []string{old, "../shares", strings.Repeat("0", 32)} {
+		if _, _, err := Open(dir, token, now); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Open(%q) = %v, want ErrNotFound", token, err)
+		}
+	}
+
+	shares, err := List(dir)
+	if err != nil || len(shares) != 2 || shares[0].Token != live {
+		t.Fatalf("List = %+v, %v; want both, newest first", shares, err)
+	}
+
+	n, err := Prune(dir, now)
+	if err != nil || n != 1 {
+		t.Fatalf("Prune = %d, %v; want 1", n, err)
+	}
+	if _, err := os.Stat(pagePath(dir, old)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the expired page to be removed, got %v", err)
+	}
+
+	if err := Revoke(dir, live); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, _, err := Open(dir, live, now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a revoked share to be gone, got %v", err)
+	}
+	if err := Revoke(dir, live); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revoke twice = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	title, link, body := "Feed & Friends", "https://example.com/post", "<p>Hello <b>world</b><script>alert(1)</script></p>"
+	entryTitle := "A <post>"
+	feeds := []*models.Feed{{ID: "f1", URL: "https://example.com/feed", Title: &title}}
+	entries := []*models.Entry{{ID: "e1", FeedID: "f1", Title: &entryTitle, Link: &link, Content: &body}}
+	d := render.NewDigest("Harper's digest", time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), feeds, entries, nil)
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, d, time.Date(2026, 10, 22, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	page := buf.String()
+	for _, want := range []string{"Feed &amp; Friends", `<a href="https://example.com/post">A &lt;post&gt;</a>`, "Hello world", "expires October 22, 2026", "noindex"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected page to contain %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<script>") || strings.Contains(page, "<b>") {
+		t.Errorf("expected entry markup to be stripped:\n%s", page)
+	}
+}
@@ -0,0 +1,250 @@
+// ABOUTME: Slack slash command handler answering unread counts and searches from the store
+// ABOUTME: Verifies Slack's request signature so only the workspace's app can query the instance
+
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/badge"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+// maxResults is how many entries one reply lists.
+const maxResults = 10
+
+// maxBody caps the request body; slash command payloads are small.
+const maxBody = 64 << 10
+
+// maxSkew is how old a request's timestamp may be before it's refused as a
+// possible replay.
+const maxSkew = 5 * time.Minute
+
+// Handler answers Slack slash commands such as "/digest unread" and
+// "/digest search kubernetes".
+type Handler struct {
+	Store storage.Store
+	OPML  *opml.Document
+	// SigningSecret is the Slack app's signing secret.
+	SigningSecret string
+	// Now returns the current time; nil means time.Now.
+	Now func() time.Time
+}
+
+// Reply is the JSON body Slack posts back into the conversation.
+type Reply struct {
+	// ResponseType is "ephemeral", shown only to the user who asked.
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+func (h *Handler) now() time.Time {
+	if h.Now != nil {
+		return h.Now()
+	}
+	return time.Now()
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	if err := Verify(h.SigningSecret, r.Header, body, h.now()); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	text := h.Answer(r.Context(), form.Get("command"), form.Get("text"))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(Reply{ResponseType: "ephemeral", Text: text})
+}
+
+// Verify checks Slack's v0 signature of body against secret and refuses
+// requests stamped more than maxSkew from now.
+func Verify(secret string, header http.Header, body []byte, now time.Time) error {
+	stamp := header.Get("X-Slack-Request-Timestamp")
+	sig := header.Get("X-Slack-Signature")
+	if stamp == "" || sig == "" {
+		return fmt.Errorf("missing Slack signature")
+	}
+	secs, err := strconv.ParseInt(stamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid Slack timestamp")
+	}
+	if skew := now.Sub(time.Unix(secs, 0)); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("stale Slack request")
+	}
+	if !hmac.Equal([]byte(sig), []byte(Sign(secret, stamp, body))) {
+		return fmt.Errorf("bad Slack signature")
+	}
+	return nil
+}
+
+// Sign returns the v0 signature Slack sends for body at timestamp stamp.
+func Sign(secret, stamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", stamp)
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Answer returns the reply to the slash command's text, in Slack's mrkdwn.
+func (h *Handler) Answer(ctx context.Context, command, text string) string {
+	if command == "" {
+		command = "/digest"
+	}
+	verb, rest, _ := strings.Cut(strings.TrimSpace(text), " ")
+	rest = strings.TrimSpace(rest)
+	var reply string
+	var err error
+	switch strings.ToLower(verb) {
+	case "unread":
+		reply, err = h.unread(ctx, rest)
+	case "search":
+		if rest == "" {
+			return fmt.Sprintf("Usage: `%s search <words>`", command)
+		}
+		reply, err = h.search(ctx, rest)
+	default:
+		return usage(command)
+	}
+	if err != nil {
+		return "Sorry, that failed: " + escape(err.Error())
+	}
+	return reply
+}
+
+func usage(command string) string {
+	return fmt.Sprintf("Ask digest about its feeds:\n"+
+		"• `%[1]s unread`: unread counts by folder and the newest unread entries\n"+
+		"• `%[1]s unread <folder>`: the same for one folder\n"+
+		"• `%[1]s search <words>`: entries matching the words", command)
+}
+
+// unread reports the unread counts and newest unread entries, overall or
+// in one folder.
+func (h *Handler) unread(ctx context.Context, folder string) (string, error) {
+	counts, err := badge.Compute(ctx, h.Store, h.OPML)
+	if err != nil {
+		return "", err
+	}
+	unreadOnly := true
+	limit := maxResults
+	filter := &storage.EntryFilter{UnreadOnly: &unreadOnly, Limit: &limit}
+
+	var b strings.Builder
+	if folder != "" {
+		n, ok := counts.Folders[folder]
+		if !ok {
+			return fmt.Sprintf("No folder called *%s*.", escape(folder)), nil
+		}
+		for _, f := range h.OPML.FeedsInFolder(folder) {
+			if feed, err := h.Store.GetFeedByURL(ctx, f.URL); err == nil {
+				filter.FeedIDs = append(filter.FeedIDs, feed.ID)
+			}
+		}
+		fmt.Fprintf(&b, "*%d unread* in %s", n, escape(folder))
+		if len(filter.FeedIDs) == 0 || n == 0 {
+			return b.String(), nil
+		}
+	} else {
+		fmt.Fprintf(&b, "*%d unread*", counts.Total)
+		names := make([]string, 0, len(counts.Folders))
+		for name, n := range counts.Folders {
+			if n > 0 {
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if counts.Folders[names[i]] != counts.Folders[names[j]] {
+				return counts.Folders[names[i]] > counts.Folders[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		for _, name := range names {
+			fmt.Fprintf(&b, " · %s %d", escape(name), counts.Folders[name])
+		}
+		if counts.Total == 0 {
+			return b.String(), nil
+		}
+	}
+
+	entries, err := h.Store.ListEntries(ctx, filter)
+	if err != nil {
+		return "", fmt.Errorf("list entries: %w", err)
+	}
+	if err := h.list(ctx, &b, "Newest", entries); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// search lists the entries matching query.
+func (h *Handler) search(ctx context.Context, query string) (string, error) {
+	entries, err := h.Store.Search(ctx, query, maxResults)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("Nothing matches _%s_.", escape(query)), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d match(es)* for _%s_", len(entries), escape(query))
+	if err := h.list(ctx, &b, "", entries); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// list writes one line per entry: its linked title and feed, and whether
+// it's been read.
+func (h *Handler) list(ctx context.Context, b *strings.Builder, heading string, entries []*models.Entry) error {
+	feeds, err := h.Store.ListFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("list feeds: %w", err)
+	}
+	names := make(map[string]string, len(feeds))
+	for _, f := range feeds {
+		names[f.ID] = f.GetDisplayName()
+	}
+	if heading != "" {
+		fmt.Fprintf(b, "\n%s:", heading)
+	}
+	for _, e := range entries {
+		title := escape(e.GetTitle())
+		if e.Link != nil && *e.Link != "" {
+			title = fmt.Sprintf("<%s|%s>", *e.Link, strings.ReplaceAll(title, "|", "¦"))
+		}
+		fmt.Fprintf(b, "\n• %s · %s", title, escape(names[e.FeedID]))
+		if e.Read {
+			b.WriteString(" _(read)_")
+		}
+	}
+	return nil
+}
+
+// escape escapes the characters Slack's mrkdwn treats as control characters.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,144 @@
+// ABOUTME: Tests for the Slack slash command handler
+// ABOUTME: Covers signature checks and the unread and search replies against a temp SQLite store
+
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+const testSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+func newTestHandler(t *testing.T, now time.Time) *Handler {
+	t.Helper()
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	doc := opml.NewDocument("test")
+	posts := map[string][]string{
+		"https://work.example/feed": {"Kubernetes upgrade notes", "Quarterly <planning> & goals"},
+		"https://fun.example/feed":  {"Sourdough starter"},
+	}
+	folders := map[string]string{"https://work.example/feed": "Work", "https://fun.example/feed": "Fun"}
+	for feedURL, titles := range posts {
+		feed := storage.NewFeed(feedURL)
+		if err := store.CreateFeed(ctx, feed); err != nil {
+			t.Fatalf("CreateFeed: %v", err)
+		}
+		for _, title := range titles {
+			entry := storage.NewEntry(feed.ID, feedURL+"#"+title, title)
+			link := feedURL + "/" + strconv.Itoa(len(title))
+			entry.Link = &link
+			if err := store.CreateEntry(ctx, entry); err != nil {
+				t.Fatalf("CreateEntry: %v", err)
+			}
+		}
+		if err := doc.AddFeed(feedURL, "", folders[feedURL]); err != nil {
+			t.Fatalf("AddFeed: %v", err)
+		}
+	}
+	return &Handler{Store: store, OPML: doc, SigningSecret: testSecret, Now: func() time.Time { return now }}
+}
+
+func slashRequest(t *testing.T, h *Handler, text string, stamp time.Time, secret string) *httptest.ResponseRecorder {
+	t.Helper()
+	body := url.Values{"command": {"/digest"}, "text": {text}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+	ts := strconv.FormatInt(stamp.Unix(), 10)
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", Sign(secret, ts, []byte(body)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func replyText(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	var r Reply
+	if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+		t.Fatalf("decode reply: %v", err)
+	}
+	if r.ResponseType != "ephemeral" {
+		t.Errorf("response_type = %q, want ephemeral", r.ResponseType)
+	}
+	return r.Text
+}
+
+func TestSignature(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	h := newTestHandler(t, now)
+
+	if rec := slashRequest(t, h, "unread", now, "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: status = %d, want 401", rec.Code)
+	}
+	if rec := slashRequest(t, h, "unread", now.Add(-10*time.Minute), testSecret); rec.Code != http.StatusUnauthorized {
+		t.Errorf("stale request: status = %d, want 401", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader("text=unread")))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned request: status = %d, want 401", rec.Code)
+	}
+	if text := replyText(t, slashRequest(t, h, "", now, testSecret)); !strings.Contains(text, "/digest search") {
+		t.Errorf("empty text should reply with usage, got %q", text)
+	}
+}
+
+func TestUnread(t *testing.T) {
+	now := time.Now()
+	h := newTestHandler(t, now)
+
+	text := replyText(t, slashRequest(t, h, "unread", now, testSecret))
+	if !strings.HasPrefix(text, "*3 unread* · Work 2 · Fun 1") {
+		t.Errorf("unexpected counts line: %q", text)
+	}
+	if !strings.Contains(text, "Quarterly &lt;planning&gt; &amp; goals") {
+		t.Errorf("titles should be escaped for mrkdwn: %q", text)
+	}
+	if !strings.Contains(text, "<https://fun.example/feed/17|Sourdough starter>") {
+		t.Errorf("entries should link to their posts: %q", text)
+	}
+
+	text = replyText(t, slashRequest(t, h, "unread Fun", now, testSecret))
+	if !strings.HasPrefix(text, "*1 unread* in Fun") || strings.Contains(text, "Kubernetes") {
+		t.Errorf("folder reply should only cover Fun: %q", text)
+	}
+	if text := replyText(t, slashRequest(t, h, "unread Nope", now, testSecret)); !strings.Contains(text, "No folder called") {
+		t.Errorf("unknown folder: %q", text)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	now := time.Now()
+	h := newTestHandler(t, now)
+
+	text := replyText(t, slashRequest(t, h, "search kubernetes", now, testSecret))
+	if !strings.Contains(text, "*1 match(es)*") || !strings.Contains(text, "Kubernetes upgrade notes") {
+		t.Errorf("search reply: %q", text)
+	}
+	if text := replyText(t, slashRequest(t, h, "search zebra", now, testSecret)); !strings.Contains(text, "Nothing matches") {
+		t.Errorf("empty search reply: %q", text)
+	}
+	if text := replyText(t, slashRequest(t, h, "search", now, testSecret)); !strings.Contains(text, "Usage") {
+		t.Errorf("bare search should show usage: %q", text)
+	}
+}
@@ -0,0 +1,159 @@
+// ABOUTME: On-disk archive of the last raw responses fetched for each feed, gzip-compressed
+// ABOUTME: Lets entries be reprocessed after parser changes and "entry missing" reports be debugged
+
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harperreed/mdstore"
+)
+
+// fileExt ends every snapshot file name; the rest is the fetch time.
+const fileExt = ".gz"
+
+// timeLayout names snapshot files so they sort by fetch time.
+const timeLayout = "20060102T150405.000000000Z"
+
+// Snapshot is one stored response.
+type Snapshot struct {
+	Path string
+	// FetchedAt is when the response was received.
+	FetchedAt time.Time
+	// Size is the compressed size on disk, in bytes.
+	Size int64
+}
+
+// Dir returns the snapshot directory inside a profile data directory.
+func Dir(profileDir string) string {
+	return filepath.Join(profileDir, "snapshots")
+}
+
+// Save stores body as the feed's snapshot fetched at at, then deletes all
+// but the newest keep snapshots of the feed. A body identical to the newest
+// snapshot isn't stored again. It returns the snapshot's path.
+func Save(dir, feedID string, body []byte, at time.Time, keep int) (string, error) {
+	feedDir := filepath.Join(dir, feedID)
+	existing, err := List(dir, feedID)
+	if err != nil {
+		return "", err
+	}
+	if len(existing) > 0 {
+		if newest, err := Read(existing[0].Path); err == nil && bytes.Equal(newest, body) {
+			return existing[0].Path, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return "", fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := mdstore.EnsureDir(feedDir); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	path := filepath.Join(feedDir, at.UTC().Format(timeLayout)+fileExt)
+	if err := mdstore.AtomicWrite(path, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	all, err := List(dir, feedID)
+	if err != nil {
+		return path, err
+	}
+	for i := keep; i < len(all); i++ {
+		if err := os.Remove(all[i].Path); err != nil {
+			return path, fmt.Errorf("failed to remove old snapshot: %w", err)
+		}
+	}
+	return path, nil
+}
+
+// List returns a feed's snapshots, newest first.
+func List(dir, feedID string) ([]Snapshot, error) {
+	files, err := os.ReadDir(filepath.Join(dir, feedID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+	var snapshots []Snapshot
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		at, err := time.Parse(timeLayout, strings.TrimSuffix(name, fileExt))
+		if err != nil {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{
+			Path:      filepath.Join(dir, feedID, name),
+			FetchedAt: at,
+			Size:      info.Size(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].FetchedAt.After(snapshots[j].FetchedAt)
+	})
+	return snapshots, nil
+}
+
+// Read returns the raw response stored in a snapshot file.
+func Read(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer zr.Close()
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return body, nil
+}
+
+// Prune deletes the snapshots of feeds for which keep returns false, such
+// as removed feeds. It returns how many feeds' snapshots it deleted.
+func Prune(dir string, keep func(feedID string) bool) (int, error) {
+	feeds, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+	removed := 0
+	for _, f := range feeds {
+		if !f.IsDir() || keep(f.Name()) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, f.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove snapshots: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
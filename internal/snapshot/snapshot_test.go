@@ -0,0 +1,87 @@
+// ABOUTME: Tests for the raw feed snapshot archive
+// ABOUTME: Covers saving with rotation, skipping unchanged bodies, reading back, and pruning removed feeds
+
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveRotatesAndReads(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		body := []byte(fmt.Sprintf("<rss>version %d</rss>", i))
+		if _, err := Save(dir, "feed-1", body, start.Add(time.Duration(i)*time.Hour), 3); err != nil {
+			t.Fatalf("Save %d: %v", i, err)
+		}
+	}
+
+	snapshots, err := List(dir, "feed-1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("expected the newest 3 snapshots kept, got %d", len(snapshots))
+	}
+	if !snapshots[0].FetchedAt.Equal(start.Add(4 * time.Hour)) {
+		t.Errorf("expected newest first, got %v", snapshots[0].FetchedAt)
+	}
+	body, err := Read(snapshots[0].Path)
+	if err != nil || string(body) != "<rss>version 4</rss>" {
+		t.Errorf("Read = %q (%v)", body, err)
+	}
+	if snapshots[0].Size == 0 {
+		t.Error("expected the compressed size to be reported")
+	}
+}
+
+func TestSaveSkipsUnchangedBody(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first, err := Save(dir, "feed-1", []byte("<rss/>"), at, 5)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	second, err := Save(dir, "feed-1", []byte("<rss/>"), at.Add(time.Hour), 5)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if second != first {
+		t.Errorf("expected the unchanged body to reuse %s, got %s", first, second)
+	}
+	if snapshots, _ := List(dir, "feed-1"); len(snapshots) != 1 {
+		t.Errorf("expected 1 snapshot, got %d", len(snapshots))
+	}
+}
+
+func TestListMissingFeed(t *testing.T) {
+	snapshots, err := List(t.TempDir(), "nope")
+	if err != nil || snapshots != nil {
+		t.Errorf("expected no snapshots, got %v (%v)", snapshots, err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Now()
+	for _, id := range []string{"kept", "removed"} {
+		if _, err := Save(dir, id, []byte("<rss/>"), at, 1); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	removed, err := Prune(dir, func(id string) bool { return id == "kept" })
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune = %d (%v), want 1", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "removed")); !os.IsNotExist(err) {
+		t.Error("expected the removed feed's snapshots deleted")
+	}
+	if snapshots, _ := List(dir, "kept"); len(snapshots) != 1 {
+		t.Error("expected the kept feed's snapshot left alone")
+	}
+}
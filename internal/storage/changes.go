@@ -0,0 +1,145 @@
+// ABOUTME: Change tracking that lets external tools sync entries incrementally
+// ABOUTME: Encodes opaque cursors over the change order and pages entries changed or deleted after one
+
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// ErrInvalidCursor is returned for a cursor this store didn't hand out.
+var ErrInvalidCursor = errors.New("invalid changes cursor")
+
+// ChangeSet is a page of entries created, changed or deleted after a cursor.
+type ChangeSet struct {
+	// Entries are in their current state, in the order they changed.
+	Entries []*models.Entry
+
+	// Deleted are the entries removed since the cursor, in the order they
+	// were removed. An entry is never in both lists.
+	Deleted []DeletedEntry
+
+	// Cursor marks the last change returned; pass it to the next call. It
+	// is the given cursor when nothing has changed.
+	Cursor string
+
+	// More reports that the limit left out changes past Cursor.
+	More bool
+}
+
+// DeletedEntry records an entry that was removed, on its own or with its feed.
+type DeletedEntry struct {
+	ID        string
+	FeedID    string
+	DeletedAt time.Time
+}
+
+// changeTime is the time recorded for a write. It is in UTC so stored
+// change times and cursors compare the same way whatever the local zone.
+func changeTime() time.Time {
+	return time.Now().UTC()
+}
+
+// changeCursor is a position in the change order: changes are ordered by
+// sequence number, then change time, then entry ID. SQLite numbers its
+// changes in commit order and leaves the time zero; the markdown store has
+// no sequence and orders by time alone.
+type changeCursor struct {
+	seq int64
+	at  time.Time
+	id  string
+}
+
+// before reports whether c comes before o in the change order.
+func (c changeCursor) before(o changeCursor) bool {
+	if c.seq != o.seq {
+		return c.seq < o.seq
+	}
+	if !c.at.Equal(o.at) {
+		return c.at.Before(o.at)
+	}
+	return c.id < o.id
+}
+
+// encode returns the opaque form of a position.
+func (c changeCursor) encode() string {
+	raw := strconv.FormatInt(c.seq, 10) + "|" + c.at.UTC().Format(time.RFC3339Nano) + "|" + c.id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor from encode. The empty cursor is the start,
+// before every change.
+func decodeCursor(cursor string) (*changeCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return nil, ErrInvalidCursor
+	}
+	seq, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &changeCursor{seq: seq, at: at.UTC(), id: parts[2]}, nil
+}
+
+// change is one entry or deletion at its position in the change order.
+type change struct {
+	pos     changeCursor
+	entry   *models.Entry
+	deleted *DeletedEntry
+}
+
+// pageChanges returns the changes after cursor, ordered, up to limit (0
+// for no limit).
+func pageChanges(changes []change, cursor string, limit int) (*ChangeSet, error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var later []change
+	for _, c := range changes {
+		if after == nil || after.before(c.pos) {
+			later = append(later, c)
+		}
+	}
+	return newChangeSet(later, cursor, limit), nil
+}
+
+// newChangeSet builds a page from changes past the cursor, which may run
+// past limit to show there are more.
+func newChangeSet(changes []change, cursor string, limit int) *ChangeSet {
+	sort.Slice(changes, func(i, j int) bool { return changes[i].pos.before(changes[j].pos) })
+	set := &ChangeSet{Cursor: cursor}
+	if limit > 0 && len(changes) > limit {
+		changes = changes[:limit]
+		set.More = true
+	}
+	for _, c := range changes {
+		if c.entry != nil {
+			set.Entries = append(set.Entries, c.entry)
+		} else {
+			set.Deleted = append(set.Deleted, *c.deleted)
+		}
+	}
+	if n := len(changes); n > 0 {
+		set.Cursor = changes[n-1].pos.encode()
+	}
+	return set
+}
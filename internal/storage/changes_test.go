@@ -0,0 +1,250 @@
+// ABOUTME: Tests for incremental change tracking across storage backends
+// ABOUTME: Verifies cursors page through new entries and pick up read state changes and deletions
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestGetChangesSince(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		open func(t *testing.T) Store
+	}{
+		{"sqlite", func(t *testing.T) Store { return newTestStore(t) }},
+		{"sqlite user view", func(t *testing.T) Store {
+			store := newTestStore(t)
+			t.Cleanup(func() { store.Close() })
+			view, err := store.ForUser("alice")
+			if err != nil {
+				t.Fatalf("ForUser: %v", err)
+			}
+			return view
+		}},
+		{"markdown", func(t *testing.T) Store { return newTestMarkdownStore(t) }},
+		{"markdown sync-safe", func(t *testing.T) Store {
+			store, err := NewMarkdownStoreWithOptions(t.TempDir(), MarkdownOptions{SyncSafe: true, DeviceID: "laptop"})
+			if err != nil {
+				t.Fatalf("open store: %v", err)
+			}
+			return store
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.open(t)
+			defer store.Close()
+			checkChanges(t, store)
+		})
+	}
+}
+
+func checkChanges(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed failed: %v", err)
+	}
+	var entries []*models.Entry
+	for _, guid := range []string{"one", "two", "three"} {
+		entry := models.NewEntry(feed.ID, guid, guid)
+		entry.PublishedAt = &entry.CreatedAt
+		if err := store.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	first, err := store.GetChangesSince(ctx, "", 2)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+	if len(first.Entries) != 2 || !first.More || first.Cursor == "" {
+		t.Fatalf("expected a first page of 2 with more, got %d (more=%v)", len(first.Entries), first.More)
+	}
+	rest, err := store.GetChangesSince(ctx, first.Cursor, 2)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+	if len(rest.Entries) != 1 || rest.More {
+		t.Fatalf("expected the last entry alone, got %d (more=%v)", len(rest.Entries), rest.More)
+	}
+	seen := map[string]bool{}
+	for _, e := range append(first.Entries, rest.Entries...) {
+		seen[e.ID] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("expected every entry once across pages, got %d distinct", len(seen))
+	}
+
+	idle, err := store.GetChangesSince(ctx, rest.Cursor, 0)
+	if err != nil || len(idle.Entries) != 0 || idle.Cursor != rest.Cursor {
+		t.Fatalf("expected no changes and the same cursor, got %d entries (%v)", len(idle.Entries), err)
+	}
+
+	if err := store.MarkEntryRead(ctx, entries[0].ID); err != nil {
+		t.Fatalf("MarkEntryRead failed: %v", err)
+	}
+	changed, err := store.GetChangesSince(ctx, rest.Cursor, 0)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+	if len(changed.Entries) != 1 || changed.Entries[0].ID != entries[0].ID || !changed.Entries[0].Read {
+		t.Fatalf("expected the entry just read, got %d entries", len(changed.Entries))
+	}
+
+	// A bulk update stamps every entry with the same time; paging one at a
+	// time must still reach each of them
+	if _, err := store.MarkEntriesReadBefore(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("MarkEntriesReadBefore failed: %v", err)
+	}
+	cursor, bulk := changed.Cursor, map[string]bool{}
+	for range 3 {
+		page, err := store.GetChangesSince(ctx, cursor, 1)
+		if err != nil {
+			t.Fatalf("GetChangesSince failed: %v", err)
+		}
+		for _, e := range page.Entries {
+			bulk[e.ID] = true
+		}
+		cursor = page.Cursor
+	}
+	if len(bulk) != 2 || bulk[entries[0].ID] {
+		t.Errorf("expected the two bulk-read entries, got %v", bulk)
+	}
+
+	// Deleting an entry, and then its feed, reports each entry once as deleted
+	if err := store.DeleteEntry(ctx, entries[1].ID); err != nil {
+		t.Fatalf("DeleteEntry failed: %v", err)
+	}
+	removed, err := store.GetChangesSince(ctx, cursor, 0)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+	if len(removed.Entries) != 0 || len(removed.Deleted) != 1 || removed.Deleted[0].ID != entries[1].ID || removed.Deleted[0].FeedID != feed.ID {
+		t.Fatalf("expected the deleted entry alone, got %d entries and %+v", len(removed.Entries), removed.Deleted)
+	}
+	if removed.Deleted[0].DeletedAt.IsZero() {
+		t.Error("expected a deletion time")
+	}
+	if err := store.DeleteFeed(ctx, feed.ID); err != nil {
+		t.Fatalf("DeleteFeed failed: %v", err)
+	}
+	gone, err := store.GetChangesSince(ctx, removed.Cursor, 0)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+	if len(gone.Deleted) != 2 || gone.Deleted[0].ID == entries[1].ID || gone.Deleted[1].ID == entries[1].ID {
+		t.Fatalf("expected the feed's two remaining entries deleted, got %+v", gone.Deleted)
+	}
+	if all, err := store.GetChangesSince(ctx, "", 0); err != nil || len(all.Entries) != 0 || len(all.Deleted) != 3 {
+		t.Fatalf("expected only deletions from the start, got %+v (%v)", all, err)
+	}
+
+	if _, err := store.GetChangesSince(ctx, "not-a-cursor", 0); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestGetChangesSince_CommitOrder(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed failed: %v", err)
+	}
+	first := models.NewEntry(feed.ID, "first", "First")
+	if err := store.CreateEntry(ctx, first); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	page, err := store.GetChangesSince(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+
+	// A write whose clock reads earlier than the page, as one that started
+	// before it and committed after would, still lands past its cursor
+	late := models.NewEntry(feed.ID, "late", "Late")
+	if err := store.CreateEntry(ctx, late); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	if _, err := store.db.Exec("UPDATE entries SET updated_at = ? WHERE id = ?", time.Time{}, late.ID); err != nil {
+		t.Fatalf("backdate entry: %v", err)
+	}
+	later, err := store.GetChangesSince(ctx, page.Cursor, 0)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+	if len(later.Entries) != 1 || later.Entries[0].ID != late.ID {
+		t.Fatalf("expected the late entry after the cursor, got %d entries", len(later.Entries))
+	}
+
+	// An entry deleted and created again is no longer reported deleted
+	if err := store.DeleteEntry(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteEntry failed: %v", err)
+	}
+	if err := store.CreateEntry(ctx, first); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	again, err := store.GetChangesSince(ctx, later.Cursor, 0)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+	if len(again.Deleted) != 0 || len(again.Entries) != 1 || again.Entries[0].ID != first.ID {
+		t.Fatalf("expected the recreated entry and no deletion, got %d entries and %+v", len(again.Entries), again.Deleted)
+	}
+
+	// Deleting the latest change still moves past its cursor
+	if err := store.DeleteEntry(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteEntry failed: %v", err)
+	}
+	removed, err := store.GetChangesSince(ctx, again.Cursor, 0)
+	if err != nil {
+		t.Fatalf("GetChangesSince failed: %v", err)
+	}
+	if len(removed.Deleted) != 1 || removed.Deleted[0].ID != first.ID {
+		t.Fatalf("expected the entry just deleted, got %+v", removed.Deleted)
+	}
+}
+
+func TestGetChangesSince_MigratedEntries(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed failed: %v", err)
+	}
+	entry := models.NewEntry(feed.ID, "old", "Old")
+	if err := store.CreateEntry(ctx, entry); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+	// Simulate an entry stored before change tracking
+	if _, err := store.db.Exec("UPDATE entries SET updated_at = NULL"); err != nil {
+		t.Fatalf("clear updated_at: %v", err)
+	}
+	if err := store.migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	changes, err := store.GetChangesSince(ctx, "", 0)
+	if err != nil || len(changes.Entries) != 1 || !changes.Entries[0].UpdatedAt.IsZero() {
+		t.Fatalf("expected the migrated entry at the zero time, got %+v (%v)", changes, err)
+	}
+	if err := store.MarkEntryRead(ctx, entry.ID); err != nil {
+		t.Fatalf("MarkEntryRead failed: %v", err)
+	}
+	later, err := store.GetChangesSince(ctx, changes.Cursor, 0)
+	if err != nil || len(later.Entries) != 1 {
+		t.Fatalf("expected the read entry after the migrated cursor, got %+v (%v)", later, err)
+	}
+}
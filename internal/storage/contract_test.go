@@ -0,0 +1,64 @@
+// ABOUTME: Runs the storage contract suite against every backend
+// ABOUTME: Keeps SQLite, per-user views, Markdown, and encrypted SQLite behaving the same
+
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/storage/storagetest"
+)
+
+func TestContract(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		open storagetest.Opener
+	}{
+		{"sqlite", func(t *testing.T) storage.Store {
+			s, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+			if err != nil {
+				t.Fatalf("NewSQLiteStore: %v", err)
+			}
+			return s
+		}},
+		{"sqlite-user", func(t *testing.T) storage.Store {
+			s, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+			if err != nil {
+				t.Fatalf("NewSQLiteStore: %v", err)
+			}
+			t.Cleanup(func() { s.Close() })
+			view, err := storage.ForUser(s, "alice")
+			if err != nil {
+				t.Fatalf("ForUser: %v", err)
+			}
+			return view
+		}},
+		{"encrypted", func(t *testing.T) storage.Store {
+			s, err := storage.NewEncryptedSQLiteStore(filepath.Join(t.TempDir(), "digest.db.enc"), "passphrase")
+			if err != nil {
+				t.Fatalf("NewEncryptedSQLiteStore: %v", err)
+			}
+			return s
+		}},
+		{"markdown", func(t *testing.T) storage.Store {
+			s, err := storage.NewMarkdownStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewMarkdownStore: %v", err)
+			}
+			return s
+		}},
+		{"markdown-sync-safe", func(t *testing.T) storage.Store {
+			s, err := storage.NewMarkdownStoreWithOptions(t.TempDir(), storage.MarkdownOptions{SyncSafe: true, DeviceID: "laptop"})
+			if err != nil {
+				t.Fatalf("NewMarkdownStoreWithOptions: %v", err)
+			}
+			return s
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			storagetest.Run(t, tt.open)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Integrity check and maintenance types shared by the storage backends
+// ABOUTME: Backends implementing Doctor, Reindexer, or Footprinter support digest doctor, maintenance, and prune
+
+package storage
+
+import (
+	"context"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// CheckResult is the outcome of a single integrity check.
+type CheckResult struct {
+	Name    string // Short check name, e.g. "schema" or "fts"
+	OK      bool   // True if no problem was found (or it was repaired)
+	Detail  string // Human-readable summary of what was found
+	Fixable bool   // True if Diagnose(true) can repair the problem
+	Fixed   bool   // True if the problem was repaired during this run
+}
+
+// Doctor is implemented by stores that can verify their own integrity.
+// With fix set, problems that can be repaired without losing data are repaired.
+type Doctor interface {
+	Diagnose(ctx context.Context, fix bool) ([]CheckResult, error)
+}
+
+// ReindexResult reports the outcome of rebuilding a store's search index.
+type ReindexResult struct {
+	Entries     int   // Entries in the rebuilt index
+	BytesBefore int64 // On-disk size before maintenance
+	BytesAfter  int64 // On-disk size after maintenance
+}
+
+// Reclaimed returns the bytes freed by maintenance, or 0 if the store grew.
+func (r *ReindexResult) Reclaimed() int64 {
+	return max(r.BytesBefore-r.BytesAfter, 0)
+}
+
+// Reindexer is implemented by stores with a search index that can be rebuilt.
+type Reindexer interface {
+	Reindex(ctx context.Context) (*ReindexResult, error)
+}
+
+// Footprint is the room some entries take up in a store.
+type Footprint struct {
+	Files   int   // Entry files, for stores keeping one per entry
+	FTSRows int   // Rows in the search index
+	Bytes   int64 // Bytes on disk; approximate for databases
+}
+
+// Footprinter is implemented by stores that can tell what deleting
+// entries would reclaim.
+type Footprinter interface {
+	EntryFootprint(ctx context.Context, entries []*models.Entry) (*Footprint, error)
+}
+
+// EntryCounter is implemented by stores that can count entries without
+// loading them.
+type EntryCounter interface {
+	// CountEntries counts entries, optionally filtered by feedID.
+	CountEntries(ctx context.Context, feedID *string) (int, error)
+}
@@ -0,0 +1,302 @@
+// ABOUTME: Tests for the storage integrity checks (Diagnose) on both backends
+// ABOUTME: Corrupts stores in targeted ways and verifies detection and safe repair
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// checkByName returns the named result, failing the test if it's missing.
+func checkByName(t *testing.T, results []CheckResult, name string) CheckResult {
+	t.Helper()
+	for _, r := range results {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("no %q check in results %+v", name, results)
+	return CheckResult{}
+}
+
+func TestSQLiteDiagnose_Healthy(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if err := store.CreateEntry(context.Background(), models.NewEntry(feed.ID, "guid-1", "Hello")); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+
+	results, err := store.Diagnose(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	for _, r := range results {
+		if !r.OK {
+			t.Errorf("expected %s check to pass, got %q", r.Name, r.Detail)
+		}
+	}
+}
+
+func TestSQLiteDiagnose_MissingEntryColumn(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	if _, err := store.db.Exec("ALTER TABLE entries DROP COLUMN archive_url"); err != nil {
+		t.Fatal(err)
+	}
+	results, err := store.Diagnose(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	if r := checkByName(t, results, "schema"); r.OK || !strings.Contains(r.Detail, "entries.archive_url") {
+		t.Errorf("expected missing entries.archive_url to be reported, got %+v", r)
+	}
+
+	if _, err := store.Diagnose(context.Background(), true); err != nil {
+		t.Fatalf("Diagnose(fix): %v", err)
+	}
+	missing, err := store.missingColumns(context.Background(), "entries", entryColumnMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("expected the fix to restore entry columns, still missing %v", missing)
+	}
+}
+
+func TestSQLiteDiagnose_SchemaVersion(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	if _, err := store.db.Exec("PRAGMA user_version = 0"); err != nil {
+		t.Fatal(err)
+	}
+	results, err := store.Diagnose(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	if r := checkByName(t, results, "schema"); r.OK || !r.Fixable {
+		t.Errorf("expected fixable schema problem, got %+v", r)
+	}
+
+	results, err = store.Diagnose(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Diagnose(fix): %v", err)
+	}
+	if r := checkByName(t, results, "schema"); !r.OK || !r.Fixed {
+		t.Errorf("expected schema to be fixed, got %+v", r)
+	}
+	if v, _ := store.schemaVersion(context.Background()); v != SchemaVersion {
+		t.Errorf("expected schema version %d after fix, got %d", SchemaVersion, v)
+	}
+
+	// A newer schema is reported but never downgraded
+	if _, err := store.db.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatal(err)
+	}
+	results, _ = store.Diagnose(context.Background(), true)
+	if r := checkByName(t, results, "schema"); r.OK || r.Fixable {
+		t.Errorf("expected unfixable newer-schema problem, got %+v", r)
+	}
+}
+
+func TestSQLiteDiagnose_FTSRebuild(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	entry := models.NewEntry(feed.ID, "guid-1", "Searchable title")
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+
+	// Drop the entry from the index behind the triggers' back
+	if _, err := store.db.Exec(`INSERT INTO entries_fts(entries_fts, rowid, title, content)
+		SELECT 'delete', rowid, title, content FROM entries`); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := store.Diagnose(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	if r := checkByName(t, results, "fts"); !r.Fixed {
+		t.Fatalf("expected fts to be rebuilt, got %+v", r)
+	}
+
+	found, err := store.Search(context.Background(), "Searchable", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(found) != 1 {
+		t.Errorf("expected rebuilt index to find the entry, got %d results", len(found))
+	}
+}
+
+func TestSQLiteDiagnose_OrphanedEntries(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	// Insert an entry for a missing feed on a connection without FK enforcement
+	ctx := context.Background()
+	conn, err := store.db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, `INSERT INTO entries (id, feed_id, guid, created_at)
+		VALUES ('orphan-1', 'missing-feed', 'g', CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	results, err := store.Diagnose(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	if r := checkByName(t, results, "orphans"); r.OK || !r.Fixable {
+		t.Errorf("expected fixable orphan problem, got %+v", r)
+	}
+
+	if _, err := store.Diagnose(context.Background(), true); err != nil {
+		t.Fatalf("Diagnose(fix): %v", err)
+	}
+	if _, err := store.GetEntry(context.Background(), "orphan-1"); err == nil {
+		t.Error("expected orphaned entry to be deleted")
+	}
+}
+
+func TestMarkdownDiagnose_QuarantinesBadFrontmatter(t *testing.T) {
+	store := newTestMarkdownStore(t)
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if err := store.CreateEntry(context.Background(), models.NewEntry(feed.ID, "guid-1", "Good")); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+
+	slug, err := store.feedSlugByID(context.Background(), feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badPath := filepath.Join(store.feedDirPath(slug), "broken.md")
+	if err := os.WriteFile(badPath, []byte("---\nid: [unterminated\n---\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := store.Diagnose(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	if r := checkByName(t, results, "frontmatter"); r.OK || !r.Fixable {
+		t.Errorf("expected fixable frontmatter problem, got %+v", r)
+	}
+
+	results, err = store.Diagnose(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Diagnose(fix): %v", err)
+	}
+	if r := checkByName(t, results, "frontmatter"); !r.Fixed {
+		t.Errorf("expected frontmatter to be fixed, got %+v", r)
+	}
+	if _, err := os.Stat(badPath + invalidEntrySuffix); err != nil {
+		t.Errorf("expected bad file to be kept with %s suffix: %v", invalidEntrySuffix, err)
+	}
+
+	// Good entries are untouched
+	entries, err := store.ListEntries(context.Background(), nil)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("expected 1 good entry after repair, got %d (%v)", len(entries), err)
+	}
+}
+
+func TestMarkdownDiagnose_OrphanedDirectory(t *testing.T) {
+	store := newTestMarkdownStore(t)
+
+	orphanDir := filepath.Join(store.dataDir, "old-feed")
+	if err := os.MkdirAll(orphanDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(orphanDir, "entry.md"), []byte("---\nid: x\n---\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := store.Diagnose(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	r := checkByName(t, results, "orphans")
+	if r.OK || r.Fixable {
+		t.Errorf("expected unfixable orphan report, got %+v", r)
+	}
+	if _, err := os.Stat(orphanDir); err != nil {
+		t.Error("expected orphaned directory to be left in place")
+	}
+}
+
+func TestSQLiteReindex(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := store.CreateEntry(context.Background(), models.NewEntry(feed.ID, fmt.Sprintf("guid-%d", i), "Reindexed title")); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+	}
+
+	// Wipe the index so only a rebuild can make search work again
+	if _, err := store.db.Exec("INSERT INTO entries_fts(entries_fts) VALUES('delete-all')"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.Reindex(context.Background())
+	if err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+	if result.Entries != 3 {
+		t.Errorf("expected 3 entries reindexed, got %d", result.Entries)
+	}
+	if result.BytesBefore == 0 || result.BytesAfter == 0 {
+		t.Errorf("expected sizes to be measured, got %+v", result)
+	}
+
+	found, err := store.Search(context.Background(), "Reindexed", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(found) != 3 {
+		t.Errorf("expected 3 search results after reindex, got %d", len(found))
+	}
+}
+
+func TestReindexResultReclaimed(t *testing.T) {
+	if got := (&ReindexResult{BytesBefore: 100, BytesAfter: 40}).Reclaimed(); got != 60 {
+		t.Errorf("expected 60 bytes reclaimed, got %d", got)
+	}
+	if got := (&ReindexResult{BytesBefore: 40, BytesAfter: 100}).Reclaimed(); got != 0 {
+		t.Errorf("expected growth to report 0 reclaimed, got %d", got)
+	}
+}
@@ -0,0 +1,472 @@
+// ABOUTME: Encrypted SQLite store that keeps the database decrypted only in memory
+// ABOUTME: The file on disk is AES-256-GCM sealed with a scrypt-derived key and rewritten after changes
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/harperreed/mdstore"
+	"golang.org/x/crypto/scrypt"
+	"modernc.org/sqlite"
+	"modernc.org/sqlite/vfs"
+
+	"github.com/harper/digest/internal/runlock"
+)
+
+// EncryptedDBFilename is the encrypted database's name in a profile
+// directory, used in place of digest.db.
+const EncryptedDBFilename = "digest.db.enc"
+
+// encryptedMagic starts every encrypted database file and versions the format:
+// magic, 16-byte scrypt salt, 12-byte GCM nonce, then the sealed database.
+const encryptedMagic = "DIGESTENC1"
+
+const (
+	saltSize = 16
+	keySize  = 32
+
+	// scrypt cost parameters, as recommended for interactive logins.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// encryptedFlushInterval is how often an open encrypted store writes
+// changes back to disk, so a crash loses at most this much.
+const encryptedFlushInterval = 30 * time.Second
+
+// ErrWrongPassphrase is returned when an encrypted database can't be opened
+// with the given passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase or damaged encrypted database")
+
+// serializer and restorer are implemented by modernc.org/sqlite connections.
+type serializer interface {
+	Serialize() ([]byte, error)
+}
+
+type restorer interface {
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// EncryptedSQLiteStore is a SQLiteStore whose database is loaded into memory
+// from an encrypted file and sealed back to it after changes and on Close.
+// While open it holds a lock on the file, so only one digest process at a
+// time can use an encrypted profile.
+type EncryptedSQLiteStore struct {
+	*SQLiteStore
+
+	encPath string
+	key     []byte
+	salt    []byte
+	lock    *runlock.Lock
+
+	flushMu sync.Mutex
+	flushed int64 // total_changes() at the last flush
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Compile-time check that EncryptedSQLiteStore implements Store.
+var _ Store = (*EncryptedSQLiteStore)(nil)
+
+// NewEncryptedSQLiteStore opens the encrypted database at encPath with
+// passphrase, creating it if it doesn't exist.
+func NewEncryptedSQLiteStore(encPath, passphrase string) (*EncryptedSQLiteStore, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("encrypted database needs a passphrase")
+	}
+	if err := os.MkdirAll(filepath.Dir(encPath), 0700); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+
+	lock, err := runlock.Acquire(encPath+".lock", 10*time.Second)
+	if err != nil {
+		if errors.Is(err, runlock.ErrLocked) {
+			return nil, fmt.Errorf("encrypted database is open in another digest process: %w", err)
+		}
+		return nil, fmt.Errorf("lock encrypted database: %w", err)
+	}
+
+	store, err := openEncrypted(encPath, passphrase, lock)
+	if err != nil {
+		_ = lock.Release()
+		return nil, err
+	}
+	return store, nil
+}
+
+func openEncrypted(encPath, passphrase string, lock *runlock.Lock) (*EncryptedSQLiteStore, error) {
+	var plain, salt, key []byte
+	data, err := os.ReadFile(encPath)
+	switch {
+	case err == nil:
+		salt, key, plain, err = openSealed(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	case os.IsNotExist(err):
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("generate salt: %w", err)
+		}
+		if key, err = deriveKey(passphrase, salt); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("read encrypted database: %w", err)
+	}
+
+	db, err := openMemoryDB(plain)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := newSQLiteStoreFromDB(db, encPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &EncryptedSQLiteStore{
+		SQLiteStore: inner,
+		encPath:     encPath,
+		key:         key,
+		salt:        salt,
+		lock:        lock,
+		flushed:     -1, // Write once even if nothing changes, so a new file exists
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	if err := s.Flush(context.Background()); err != nil {
+		inner.Close()
+		return nil, err
+	}
+	go s.flushLoop()
+	return s, nil
+}
+
+// openMemoryDB opens an in-memory database holding plain, or an empty one.
+// The pool is pinned to one connection, since each connection to :memory:
+// is a separate database.
+func openMemoryDB(plain []byte) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", ":memory:?_pragma=foreign_keys(ON)")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if plain != nil {
+		if err := restoreInto(context.Background(), db, plain); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("load decrypted database: %w", err)
+		}
+	}
+	return db, nil
+}
+
+// restoreInto copies the database image plain into db with SQLite's backup
+// API. The driver's Deserialize frees memory it doesn't own, so the image is
+// read instead through a read-only VFS over a buffer in this process; the
+// plaintext never reaches disk.
+func restoreInto(ctx context.Context, db *sql.DB, plain []byte) error {
+	// Serialized WAL databases say so in their header, which an in-memory
+	// database can't honor; mark the image as rollback-journal.
+	if len(plain) > 19 {
+		plain[18], plain[19] = 1, 1
+	}
+
+	name, fsys, err := vfs.New(imageFS(plain))
+	if err != nil {
+		return fmt.Errorf("register in-memory database: %w", err)
+	}
+	defer fsys.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(dc any) error {
+		r, ok := dc.(restorer)
+		if !ok {
+			return fmt.Errorf("sqlite driver does not support restoring backups")
+		}
+		backup, err := r.NewRestore("file:" + imageName + "?mode=ro&vfs=" + name)
+		if err != nil {
+			return err
+		}
+		for {
+			more, err := backup.Step(-1)
+			if err != nil {
+				backup.Finish()
+				return err
+			}
+			if !more {
+				return backup.Finish()
+			}
+		}
+	})
+}
+
+// imageName is the one file an imageFS holds.
+const imageName = "digest.db"
+
+// imageFS is a file system holding a database image as its one file, for
+// SQLite to read through a VFS.
+type imageFS []byte
+
+func (f imageFS) Open(name string) (fs.File, error) {
+	if name != imageName {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &imageFile{Reader: bytes.NewReader(f), size: int64(len(f))}, nil
+}
+
+// imageFile is an open imageFS file; the VFS seeks before every read.
+type imageFile struct {
+	*bytes.Reader
+	size int64
+}
+
+func (f *imageFile) Stat() (fs.FileInfo, error) { return imageInfo(f.size), nil }
+func (f *imageFile) Close() error               { return nil }
+
+type imageInfo int64
+
+func (i imageInfo) Name() string       { return imageName }
+func (i imageInfo) Size() int64        { return int64(i) }
+func (i imageInfo) Mode() fs.FileMode  { return 0400 }
+func (i imageInfo) ModTime() time.Time { return time.Time{} }
+func (i imageInfo) IsDir() bool        { return false }
+func (i imageInfo) Sys() any           { return nil }
+
+// withSerializer runs fn on a raw driver connection from db.
+func withSerializer(ctx context.Context, db *sql.DB, fn func(serializer) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(dc any) error {
+		c, ok := dc.(serializer)
+		if !ok {
+			return fmt.Errorf("sqlite driver does not support serialization")
+		}
+		return fn(c)
+	})
+}
+
+// Flush seals the database to disk if it changed since the last flush.
+func (s *EncryptedSQLiteStore) Flush(ctx context.Context) error {
+	return s.flush(ctx, false)
+}
+
+func (s *EncryptedSQLiteStore) flush(ctx context.Context, force bool) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	var changes int64
+	if err := s.db.QueryRowContext(ctx, "SELECT total_changes()").Scan(&changes); err != nil {
+		return fmt.Errorf("check for changes: %w", err)
+	}
+	if !force && changes == s.flushed {
+		return nil
+	}
+
+	var plain []byte
+	if err := withSerializer(ctx, s.db, func(c serializer) error {
+		var err error
+		plain, err = c.Serialize()
+		return err
+	}); err != nil {
+		return fmt.Errorf("serialize database: %w", err)
+	}
+	sealed, err := seal(plain, s.salt, s.key)
+	if err != nil {
+		return err
+	}
+	if err := mdstore.AtomicWrite(s.encPath, sealed); err != nil {
+		return fmt.Errorf("write encrypted database: %w", err)
+	}
+	s.flushed = changes
+	return nil
+}
+
+// flushLoop writes changes back periodically until Close.
+func (s *EncryptedSQLiteStore) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(encryptedFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+			if err := s.Flush(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not save encrypted database: %v\n", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Close seals the database to disk, closes it, and releases the file lock.
+func (s *EncryptedSQLiteStore) Close() error {
+	close(s.stop)
+	<-s.done
+	flushErr := s.Flush(context.Background())
+	closeErr := s.SQLiteStore.Close()
+	lockErr := s.lock.Release()
+	return errors.Join(flushErr, closeErr, lockErr)
+}
+
+// Reindex rebuilds the search index and saves the result right away, since
+// rebuilding and vacuuming don't count as changes.
+func (s *EncryptedSQLiteStore) Reindex(ctx context.Context) (*ReindexResult, error) {
+	result, err := s.SQLiteStore.Reindex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.flush(ctx, true); err != nil {
+		return nil, err
+	}
+	result.BytesAfter = s.fileSize()
+	return result, nil
+}
+
+// EncryptSQLiteFile writes an encrypted copy of the plaintext database at
+// plainPath to encPath. The plaintext file is left for the caller to remove.
+func EncryptSQLiteFile(ctx context.Context, plainPath, encPath, passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("encrypted database needs a passphrase")
+	}
+	plain, err := NewSQLiteStore(plainPath)
+	if err != nil {
+		return err
+	}
+	defer plain.Close()
+
+	var data []byte
+	if err := withSerializer(ctx, plain.db, func(c serializer) error {
+		var err error
+		data, err = c.Serialize()
+		return err
+	}); err != nil {
+		return fmt.Errorf("serialize database: %w", err)
+	}
+	if len(data) > 19 {
+		data[18], data[19] = 1, 1
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generate salt: %w", err)
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	sealed, err := seal(data, salt, key)
+	if err != nil {
+		return err
+	}
+	if err := mdstore.AtomicWrite(encPath, sealed); err != nil {
+		return fmt.Errorf("write encrypted database: %w", err)
+	}
+	return nil
+}
+
+// DecryptSQLiteFile writes a plaintext copy of the encrypted database at
+// encPath to plainPath. The encrypted file is left for the caller to remove.
+func DecryptSQLiteFile(encPath, plainPath, passphrase string) error {
+	data, err := os.ReadFile(encPath)
+	if err != nil {
+		return fmt.Errorf("read encrypted database: %w", err)
+	}
+	_, _, plain, err := openSealed(data, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := mdstore.AtomicWrite(plainPath, plain); err != nil {
+		return fmt.Errorf("write database: %w", err)
+	}
+	return nil
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	return key, nil
+}
+
+// seal encrypts plain with a fresh nonce and prepends the file header.
+func seal(plain, salt, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	header := make([]byte, 0, len(encryptedMagic)+len(salt)+len(nonce))
+	header = append(header, encryptedMagic...)
+	header = append(header, salt...)
+	header = append(header, nonce...)
+	// The header is authenticated too, so a swapped salt or nonce is caught
+	return gcm.Seal(header, nonce, plain, header), nil
+}
+
+// openSealed checks the header, derives the key, and decrypts.
+func openSealed(data []byte, passphrase string) (salt, key, plain []byte, err error) {
+	headerLen := len(encryptedMagic) + saltSize + 12
+	if len(data) < headerLen || string(data[:len(encryptedMagic)]) != encryptedMagic {
+		return nil, nil, nil, fmt.Errorf("not a digest encrypted database")
+	}
+	salt = data[len(encryptedMagic) : len(encryptedMagic)+saltSize]
+	nonce := data[len(encryptedMagic)+saltSize : headerLen]
+
+	key, err = deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	plain, err = gcm.Open(nil, nonce, data[headerLen:], data[:headerLen])
+	if err != nil {
+		return nil, nil, nil, ErrWrongPassphrase
+	}
+	return append([]byte(nil), salt...), key, plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return gcm, nil
+}
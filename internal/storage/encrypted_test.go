@@ -0,0 +1,107 @@
+// ABOUTME: Tests for the encrypted SQLite store and plaintext conversion
+// ABOUTME: Verifies data survives reopening, nothing readable reaches disk, and wrong passphrases fail
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestEncryptedSQLiteStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	encPath := filepath.Join(t.TempDir(), EncryptedDBFilename)
+
+	store, err := NewEncryptedSQLiteStore(encPath, "correct horse")
+	if err != nil {
+		t.Fatalf("NewEncryptedSQLiteStore: %v", err)
+	}
+	feed := models.NewFeed("https://secret.example.com/private-feed")
+	mustNoErr(t, store.CreateFeed(ctx, feed))
+	entry := models.NewEntry(feed.ID, "guid-1", "Confidential Post")
+	mustNoErr(t, store.CreateEntry(ctx, entry))
+	mustNoErr(t, store.Close())
+
+	data, err := os.ReadFile(encPath)
+	if err != nil {
+		t.Fatalf("read encrypted file: %v", err)
+	}
+	for _, secret := range []string{"secret.example.com", "Confidential", "SQLite format"} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("encrypted file contains %q", secret)
+		}
+	}
+
+	if _, err := NewEncryptedSQLiteStore(encPath, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	// Loading the decrypted image mustn't put it anywhere on disk
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	t.Setenv("XDG_RUNTIME_DIR", tmp)
+	store, err = NewEncryptedSQLiteStore(encPath, "correct horse")
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer store.Close()
+	if leftover, _ := os.ReadDir(tmp); len(leftover) > 0 {
+		t.Errorf("reopening wrote %d temporary file(s)", len(leftover))
+	}
+	got, err := store.GetEntry(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("GetEntry after reopen: %v", err)
+	}
+	if got.GetTitle() != "Confidential Post" {
+		t.Errorf("unexpected title %q", got.GetTitle())
+	}
+	results, err := store.Search(ctx, "Confidential", 10)
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected search to find the entry, got %d results (%v)", len(results), err)
+	}
+}
+
+func TestEncryptAndDecryptSQLiteFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "digest.db")
+	encPath := filepath.Join(dir, EncryptedDBFilename)
+
+	plain, err := NewSQLiteStore(plainPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	feed := models.NewFeed("https://example.com/feed")
+	mustNoErr(t, plain.CreateFeed(ctx, feed))
+	mustNoErr(t, plain.Close())
+
+	mustNoErr(t, EncryptSQLiteFile(ctx, plainPath, encPath, "pass"))
+	enc, err := NewEncryptedSQLiteStore(encPath, "pass")
+	if err != nil {
+		t.Fatalf("open encrypted copy: %v", err)
+	}
+	if _, err := enc.GetFeed(ctx, feed.ID); err != nil {
+		t.Errorf("feed missing from encrypted copy: %v", err)
+	}
+	mustNoErr(t, enc.Close())
+
+	restored := filepath.Join(dir, "restored.db")
+	if err := DecryptSQLiteFile(encPath, restored, "nope"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+	mustNoErr(t, DecryptSQLiteFile(encPath, restored, "pass"))
+	back, err := NewSQLiteStore(restored)
+	if err != nil {
+		t.Fatalf("open decrypted copy: %v", err)
+	}
+	defer back.Close()
+	if _, err := back.GetFeed(ctx, feed.ID); err != nil {
+		t.Errorf("feed missing from decrypted copy: %v", err)
+	}
+}
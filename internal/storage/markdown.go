@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,17 +20,45 @@ import (
 // MarkdownStore provides file-based storage for digest data using markdown files and YAML.
 type MarkdownStore struct {
 	dataDir string
+	journal *stateJournal // Read state journal, nil unless sync-safe
 }
 
 // Compile-time check that MarkdownStore implements Store.
 var _ Store = (*MarkdownStore)(nil)
 
+// MarkdownOptions configures a MarkdownStore.
+type MarkdownOptions struct {
+	// SyncSafe keeps read state in an append-only journal per machine under
+	// _state/ instead of rewriting entry files, so two machines sharing the
+	// data directory through Syncthing or Dropbox never write the same file.
+	// The journals are merged on load, latest change winning.
+	SyncSafe bool
+
+	// DeviceID names this machine's journal. Required with SyncSafe and
+	// must differ between the machines sharing the directory.
+	DeviceID string
+}
+
 // NewMarkdownStore creates a new markdown-backed store rooted at dataDir.
 func NewMarkdownStore(dataDir string) (*MarkdownStore, error) {
+	return NewMarkdownStoreWithOptions(dataDir, MarkdownOptions{})
+}
+
+// NewMarkdownStoreWithOptions creates a markdown-backed store rooted at
+// dataDir with the given options.
+func NewMarkdownStoreWithOptions(dataDir string, opts MarkdownOptions) (*MarkdownStore, error) {
 	if err := mdstore.EnsureDir(dataDir); err != nil {
 		return nil, fmt.Errorf("create data directory: %w", err)
 	}
-	return &MarkdownStore{dataDir: dataDir}, nil
+	s := &MarkdownStore{dataDir: dataDir}
+	if opts.SyncSafe {
+		journal, err := newStateJournal(dataDir, opts.DeviceID)
+		if err != nil {
+			return nil, err
+		}
+		s.journal = journal
+	}
+	return s, nil
 }
 
 // Close releases resources. For MarkdownStore this is a no-op.
@@ -59,6 +88,20 @@ type feedEntry struct {
 	LastError     *string `yaml:"last_error,omitempty"`
 	ErrorCount    int     `yaml:"error_count,omitempty"`
 	LocalNetwork  bool    `yaml:"local_network,omitempty"`
+	Browser       bool    `yaml:"browser,omitempty"`
+	Monitor       bool    `yaml:"monitor,omitempty"`
+	PageText      *string `yaml:"page_text,omitempty"`
+	Paused        bool    `yaml:"paused,omitempty"`
+	ArchivedAt    *string `yaml:"archived_at,omitempty"`
+	KeepActive    bool    `yaml:"keep_active,omitempty"`
+	SyncInterval  string  `yaml:"sync_interval,omitempty"`
+	MaxEntries    int     `yaml:"max_entries,omitempty"`
+	Identity      string  `yaml:"identity,omitempty"`
+	Priority      string  `yaml:"priority,omitempty"`
+	BackfillDays  int     `yaml:"backfill_days,omitempty"`
+	BackfillLimit int     `yaml:"backfill_limit,omitempty"`
+	AuthUsername  *string `yaml:"auth_username,omitempty"`
+	AuthPassword  *string `yaml:"auth_password,omitempty"`
 	CreatedAt     string  `yaml:"created_at"`
 	Slug          string  `yaml:"slug"`
 }
@@ -71,16 +114,36 @@ func (e *feedEntry) toModel() (*models.Feed, error) {
 	}
 
 	feed := &models.Feed{
-		ID:           e.ID,
-		URL:          e.URL,
-		Title:        e.Title,
-		Folder:       e.Folder,
-		ETag:         e.ETag,
-		LastModified: e.LastModified,
-		LastError:    e.LastError,
-		ErrorCount:   e.ErrorCount,
-		LocalNetwork: e.LocalNetwork,
-		CreatedAt:    createdAt,
+		ID:            e.ID,
+		URL:           e.URL,
+		Title:         e.Title,
+		Folder:        e.Folder,
+		ETag:          e.ETag,
+		LastModified:  e.LastModified,
+		LastError:     e.LastError,
+		ErrorCount:    e.ErrorCount,
+		LocalNetwork:  e.LocalNetwork,
+		Browser:       e.Browser,
+		Monitor:       e.Monitor,
+		PageText:      e.PageText,
+		Paused:        e.Paused,
+		KeepActive:    e.KeepActive,
+		MaxEntries:    e.MaxEntries,
+		Identity:      e.Identity,
+		Priority:      e.Priority,
+		BackfillDays:  e.BackfillDays,
+		BackfillLimit: e.BackfillLimit,
+		AuthUsername:  e.AuthUsername,
+		AuthPassword:  e.AuthPassword,
+		CreatedAt:     createdAt,
+	}
+
+	if e.SyncInterval != "" {
+		d, err := time.ParseDuration(e.SyncInterval)
+		if err != nil {
+			return nil, fmt.Errorf("parse feed sync_interval %q: %w", e.SyncInterval, err)
+		}
+		feed.SyncInterval = d
 	}
 
 	if e.LastFetchedAt != nil {
@@ -91,23 +154,47 @@ func (e *feedEntry) toModel() (*models.Feed, error) {
 		feed.LastFetchedAt = &t
 	}
 
+	if e.ArchivedAt != nil {
+		t, err := mdstore.ParseTime(*e.ArchivedAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse feed archived_at %q: %w", *e.ArchivedAt, err)
+		}
+		feed.ArchivedAt = &t
+	}
+
 	return feed, nil
 }
 
 // fromFeedModel converts a models.Feed to a feedEntry with a computed slug.
 func fromFeedModel(f *models.Feed, slug string) feedEntry {
 	entry := feedEntry{
-		ID:           f.ID,
-		URL:          f.URL,
-		Title:        f.Title,
-		Folder:       f.Folder,
-		ETag:         f.ETag,
-		LastModified: f.LastModified,
-		LastError:    f.LastError,
-		ErrorCount:   f.ErrorCount,
-		LocalNetwork: f.LocalNetwork,
-		CreatedAt:    mdstore.FormatTime(f.CreatedAt.UTC()),
-		Slug:         slug,
+		ID:            f.ID,
+		URL:           f.URL,
+		Title:         f.Title,
+		Folder:        f.Folder,
+		ETag:          f.ETag,
+		LastModified:  f.LastModified,
+		LastError:     f.LastError,
+		ErrorCount:    f.ErrorCount,
+		LocalNetwork:  f.LocalNetwork,
+		Browser:       f.Browser,
+		Monitor:       f.Monitor,
+		PageText:      f.PageText,
+		Paused:        f.Paused,
+		KeepActive:    f.KeepActive,
+		MaxEntries:    f.MaxEntries,
+		Identity:      f.Identity,
+		Priority:      f.Priority,
+		BackfillDays:  f.BackfillDays,
+		BackfillLimit: f.BackfillLimit,
+		AuthUsername:  f.AuthUsername,
+		AuthPassword:  f.AuthPassword,
+		CreatedAt:     mdstore.FormatTime(f.CreatedAt.UTC()),
+		Slug:          slug,
+	}
+
+	if f.SyncInterval > 0 {
+		entry.SyncInterval = f.SyncInterval.String()
 	}
 
 	if f.LastFetchedAt != nil {
@@ -115,11 +202,20 @@ func fromFeedModel(f *models.Feed, slug string) feedEntry {
 		entry.LastFetchedAt = &s
 	}
 
+	if f.ArchivedAt != nil {
+		s := mdstore.FormatTime(f.ArchivedAt.UTC())
+		entry.ArchivedAt = &s
+	}
+
 	return entry
 }
 
-// readFeeds reads the _feeds.yaml file.
-func (s *MarkdownStore) readFeeds() ([]feedEntry, error) {
+// readFeeds reads the _feeds.yaml file. Every store operation starts here,
+// so this is where a cancelled context is first noticed.
+func (s *MarkdownStore) readFeeds(ctx context.Context) ([]feedEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	var entries []feedEntry
 	if err := mdstore.ReadYAML(s.feedsFilePath(), &entries); err != nil {
 		return nil, fmt.Errorf("read feeds file: %w", err)
@@ -158,8 +254,8 @@ func (s *MarkdownStore) feedSlugForModel(f *models.Feed) string {
 }
 
 // feedSlugByID finds the slug for a feed by its ID from the feed registry.
-func (s *MarkdownStore) feedSlugByID(feedID string) (string, error) {
-	entries, err := s.readFeeds()
+func (s *MarkdownStore) feedSlugByID(ctx context.Context, feedID string) (string, error) {
+	entries, err := s.readFeeds(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -180,9 +276,23 @@ type entryFrontmatter struct {
 	Link        *string `yaml:"link,omitempty"`
 	Author      *string `yaml:"author,omitempty"`
 	PublishedAt *string `yaml:"published_at,omitempty"`
-	Read        bool    `yaml:"read"`
-	ReadAt      *string `yaml:"read_at,omitempty"`
-	CreatedAt   string  `yaml:"created_at"`
+	// ClaimedPublishedAt is the feed's own date when published_at replaced it
+	ClaimedPublishedAt *string `yaml:"claimed_published_at,omitempty"`
+	Read               bool    `yaml:"read"`
+	ReadAt             *string `yaml:"read_at,omitempty"`
+	ArchiveURL         *string `yaml:"archive_url,omitempty"`
+	ImageURL           *string `yaml:"image_url,omitempty"`
+	DiscussionURL      *string `yaml:"discussion_url,omitempty"`
+	CommentsFeedURL    *string `yaml:"comments_feed_url,omitempty"`
+	Score              *int    `yaml:"score,omitempty"`
+	CommentCount       *int    `yaml:"comment_count,omitempty"`
+	ScoredAt           *string `yaml:"scored_at,omitempty"`
+	KeepUnread         bool    `yaml:"keep_unread,omitempty"`
+	ContentHash        string  `yaml:"content_hash,omitempty"`
+	CreatedAt          string  `yaml:"created_at"`
+	UpdatedAt          *string `yaml:"updated_at,omitempty"`
+	// Extensions are the item's namespaced feed elements
+	Extensions models.Extensions `yaml:"extensions,omitempty"`
 }
 
 // toModel converts an entryFrontmatter (plus body content) to a models.Entry.
@@ -193,14 +303,24 @@ func (fm *entryFrontmatter) toModel(content string) (*models.Entry, error) {
 	}
 
 	entry := &models.Entry{
-		ID:        fm.ID,
-		FeedID:    fm.FeedID,
-		GUID:      fm.GUID,
-		Title:     fm.Title,
-		Link:      fm.Link,
-		Author:    fm.Author,
-		Read:      fm.Read,
-		CreatedAt: createdAt,
+		ID:         fm.ID,
+		FeedID:     fm.FeedID,
+		GUID:       fm.GUID,
+		Title:      fm.Title,
+		Link:       fm.Link,
+		Author:     fm.Author,
+		Read:       fm.Read,
+		ArchiveURL: fm.ArchiveURL,
+		ImageURL:   fm.ImageURL,
+		CreatedAt:  createdAt,
+
+		DiscussionURL:   fm.DiscussionURL,
+		CommentsFeedURL: fm.CommentsFeedURL,
+		Score:           fm.Score,
+		CommentCount:    fm.CommentCount,
+		KeepUnread:      fm.KeepUnread,
+		ContentHash:     fm.ContentHash,
+		Extensions:      fm.Extensions,
 	}
 
 	if content != "" {
@@ -223,20 +343,54 @@ func (fm *entryFrontmatter) toModel(content string) (*models.Entry, error) {
 		entry.ReadAt = &t
 	}
 
+	if fm.ClaimedPublishedAt != nil {
+		t, err := mdstore.ParseTime(*fm.ClaimedPublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse entry claimed_published_at %q: %w", *fm.ClaimedPublishedAt, err)
+		}
+		entry.ClaimedPublishedAt = &t
+	}
+
+	if fm.ScoredAt != nil {
+		t, err := mdstore.ParseTime(*fm.ScoredAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse entry scored_at %q: %w", *fm.ScoredAt, err)
+		}
+		entry.ScoredAt = &t
+	}
+
+	if fm.UpdatedAt != nil {
+		t, err := mdstore.ParseTime(*fm.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse entry updated_at %q: %w", *fm.UpdatedAt, err)
+		}
+		entry.UpdatedAt = t
+	}
+
 	return entry, nil
 }
 
 // fromEntryModel converts a models.Entry to an entryFrontmatter.
 func fromEntryModel(e *models.Entry) entryFrontmatter {
 	fm := entryFrontmatter{
-		ID:        e.ID,
-		FeedID:    e.FeedID,
-		GUID:      e.GUID,
-		Title:     e.Title,
-		Link:      e.Link,
-		Author:    e.Author,
-		Read:      e.Read,
-		CreatedAt: mdstore.FormatTime(e.CreatedAt.UTC()),
+		ID:         e.ID,
+		FeedID:     e.FeedID,
+		GUID:       e.GUID,
+		Title:      e.Title,
+		Link:       e.Link,
+		Author:     e.Author,
+		Read:       e.Read,
+		ArchiveURL: e.ArchiveURL,
+		ImageURL:   e.ImageURL,
+		CreatedAt:  mdstore.FormatTime(e.CreatedAt.UTC()),
+
+		DiscussionURL:   e.DiscussionURL,
+		CommentsFeedURL: e.CommentsFeedURL,
+		Score:           e.Score,
+		CommentCount:    e.CommentCount,
+		KeepUnread:      e.KeepUnread,
+		ContentHash:     e.ContentHash,
+		Extensions:      e.Extensions,
 	}
 
 	if e.PublishedAt != nil {
@@ -244,11 +398,26 @@ func fromEntryModel(e *models.Entry) entryFrontmatter {
 		fm.PublishedAt = &s
 	}
 
+	if e.ClaimedPublishedAt != nil {
+		s := mdstore.FormatTime(e.ClaimedPublishedAt.UTC())
+		fm.ClaimedPublishedAt = &s
+	}
+
 	if e.ReadAt != nil {
 		s := mdstore.FormatTime(e.ReadAt.UTC())
 		fm.ReadAt = &s
 	}
 
+	if e.ScoredAt != nil {
+		s := mdstore.FormatTime(e.ScoredAt.UTC())
+		fm.ScoredAt = &s
+	}
+
+	if !e.UpdatedAt.IsZero() {
+		s := mdstore.FormatTime(e.UpdatedAt.UTC())
+		fm.UpdatedAt = &s
+	}
+
 	return fm
 }
 
@@ -288,8 +457,10 @@ func readEntryFile(path string) (*models.Entry, error) {
 	return fm.toModel(content)
 }
 
-// writeEntryFile writes an entry to a markdown file with frontmatter.
+// writeEntryFile writes an entry to a markdown file with frontmatter,
+// stamping it as changed now.
 func writeEntryFile(path string, e *models.Entry) error {
+	e.UpdatedAt = changeTime()
 	fm := fromEntryModel(e)
 
 	body := ""
@@ -316,7 +487,7 @@ func findEntryFile(feedDir string, entryID string) (string, error) {
 	}
 
 	for _, de := range dirEntries {
-		if de.IsDir() || !strings.HasSuffix(de.Name(), ".md") {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".md") || isSyncConflict(de.Name()) {
 			continue
 		}
 		fp := filepath.Join(feedDir, de.Name())
@@ -333,17 +504,27 @@ func findEntryFile(feedDir string, entryID string) (string, error) {
 
 // readAllEntries reads all entries from a feed directory.
 func readAllEntries(feedDir string) ([]*models.Entry, error) {
+	var entries []*models.Entry
+	err := walkEntryFiles(feedDir, func(_ string, entry *models.Entry) {
+		entries = append(entries, entry)
+	})
+	return entries, err
+}
+
+// walkEntryFiles calls fn with each entry file in a feed directory and the
+// entry read from it.
+func walkEntryFiles(feedDir string, fn func(path string, entry *models.Entry)) error {
 	dirEntries, err := os.ReadDir(feedDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			return nil
 		}
-		return nil, fmt.Errorf("read feed directory: %w", err)
+		return fmt.Errorf("read feed directory: %w", err)
 	}
 
-	var entries []*models.Entry
 	for _, de := range dirEntries {
-		if de.IsDir() || !strings.HasSuffix(de.Name(), ".md") {
+		// Conflict copies from file sync tools duplicate an entry's ID
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".md") || isSyncConflict(de.Name()) {
 			continue
 		}
 		fp := filepath.Join(feedDir, de.Name())
@@ -352,7 +533,49 @@ func readAllEntries(feedDir string) ([]*models.Entry, error) {
 			// Skip malformed files
 			continue
 		}
+		fn(fp, entry)
+	}
+	return nil
+}
+
+// readEntry reads an entry file with the read state journal applied.
+func (s *MarkdownStore) readEntry(path string) (*models.Entry, error) {
+	entry, err := readEntryFile(path)
+	if err != nil || s.journal == nil {
+		return entry, err
+	}
+	if err := s.journal.apply(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// readEntries reads a feed directory with the read state journal applied.
+func (s *MarkdownStore) readEntries(feedDir string) ([]*models.Entry, error) {
+	entries, err := readAllEntries(feedDir)
+	if err != nil || s.journal == nil {
+		return entries, err
+	}
+	if err := s.journal.apply(entries...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// readEntryHeads is readEntries with each entry's content dropped as soon
+// as it is read, noting the file each came from in paths.
+func (s *MarkdownStore) readEntryHeads(feedDir string, paths map[string]string) ([]*models.Entry, error) {
+	var entries []*models.Entry
+	err := walkEntryFiles(feedDir, func(path string, entry *models.Entry) {
+		entry.Content = nil
+		paths[entry.ID] = path
 		entries = append(entries, entry)
+	})
+	if err != nil || s.journal == nil {
+		return entries, err
+	}
+	if err := s.journal.apply(entries...); err != nil {
+		return nil, err
 	}
 	return entries, nil
 }
@@ -0,0 +1,125 @@
+// ABOUTME: Append-only record of deleted entries so markdown change feeds can report them
+// ABOUTME: Each machine appends to its own file under _deleted/, like the sync-safe state journal
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harperreed/mdstore"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// deletionsDirName is the directory under the data directory that holds
+// the deleted entry records.
+const deletionsDirName = "_deleted"
+
+// localDevice names the deletions file of a store that isn't sync-safe,
+// which only one machine writes.
+const localDevice = "local"
+
+// deletionEvent is one line of a deletions file.
+type deletionEvent struct {
+	Time   time.Time `json:"t"`
+	ID     string    `json:"id"`
+	FeedID string    `json:"feed"`
+}
+
+// deletionsPath returns this machine's deletions file.
+func (s *MarkdownStore) deletionsPath() string {
+	device := localDevice
+	if s.journal != nil {
+		device = s.journal.device
+	}
+	return filepath.Join(s.dataDir, deletionsDirName, device+".jsonl")
+}
+
+// recordDeletions appends entries that were just deleted to this machine's
+// deletions file.
+func (s *MarkdownStore) recordDeletions(entries ...*models.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	now := changeTime()
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(&deletionEvent{Time: now, ID: entry.ID, FeedID: entry.FeedID})
+		if err != nil {
+			return fmt.Errorf("encode deletion: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	path := s.deletionsPath()
+	if err := mdstore.EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("create deletions directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("open deletions: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("append to deletions: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("append to deletions: %w", err)
+	}
+	return nil
+}
+
+// readDeletions returns every machine's deleted entries, keeping the latest
+// deletion of an entry deleted more than once. Lines that don't parse, such
+// as a last line still being written or synced, are skipped.
+func (s *MarkdownStore) readDeletions() (map[string]*DeletedEntry, error) {
+	files, err := filepath.Glob(filepath.Join(s.dataDir, deletionsDirName, "*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("list deletions: %w", err)
+	}
+	deleted := make(map[string]*DeletedEntry)
+	for _, path := range files {
+		if isSyncConflict(filepath.Base(path)) {
+			continue
+		}
+		if err := readDeletionsFile(path, deleted); err != nil {
+			return nil, err
+		}
+	}
+	return deleted, nil
+}
+
+// readDeletionsFile adds the deletions recorded in one file to deleted.
+func readDeletionsFile(path string, deleted map[string]*DeletedEntry) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("open deletions: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e deletionEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || strings.TrimSpace(e.ID) == "" {
+			continue
+		}
+		if cur := deleted[e.ID]; cur == nil || e.Time.After(cur.DeletedAt) {
+			deleted[e.ID] = &DeletedEntry{ID: e.ID, FeedID: e.FeedID, DeletedAt: e.Time.UTC()}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read deletions %s: %w", path, err)
+	}
+	return nil
+}
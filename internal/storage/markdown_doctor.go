@@ -0,0 +1,165 @@
+// ABOUTME: Integrity checks for MarkdownStore: feed registry, entry frontmatter, orphaned directories
+// ABOUTME: Unparseable entry files are quarantined with an .invalid suffix rather than deleted
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// invalidEntrySuffix is appended to entry files that fail to parse when repairing.
+const invalidEntrySuffix = ".invalid"
+
+// Compile-time check that MarkdownStore implements Doctor.
+var _ Doctor = (*MarkdownStore)(nil)
+
+// Diagnose runs the markdown integrity checks, repairing what it can when fix is set.
+func (s *MarkdownStore) Diagnose(ctx context.Context, fix bool) ([]CheckResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+	defer cancel()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	feeds, err := s.readFeeds(ctx)
+	if err != nil {
+		// Everything else depends on the registry, so stop here
+		return []CheckResult{{Name: "feeds", Detail: err.Error()}}, nil
+	}
+
+	results := []CheckResult{s.checkFeedRegistry(feeds)}
+
+	frontmatter, err := s.checkFrontmatter(ctx, feeds, fix)
+	if err != nil {
+		return results, err
+	}
+	results = append(results, frontmatter)
+
+	orphans, err := s.checkOrphanedDirs(feeds)
+	if err != nil {
+		return results, err
+	}
+	return append(results, orphans), nil
+}
+
+// checkFeedRegistry verifies every feed in _feeds.yaml parses and is unique.
+func (s *MarkdownStore) checkFeedRegistry(feeds []feedEntry) CheckResult {
+	result := CheckResult{Name: "feeds"}
+
+	var problems []string
+	seenID := make(map[string]bool)
+	seenURL := make(map[string]bool)
+	for _, fe := range feeds {
+		if _, err := fe.toModel(); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", fe.URL, err))
+		}
+		if seenID[fe.ID] {
+			problems = append(problems, "duplicate feed id "+fe.ID)
+		}
+		if seenURL[fe.URL] {
+			problems = append(problems, "duplicate feed url "+fe.URL)
+		}
+		seenID[fe.ID] = true
+		seenURL[fe.URL] = true
+	}
+
+	if len(problems) == 0 {
+		result.OK = true
+		result.Detail = fmt.Sprintf("%d feeds", len(feeds))
+		return result
+	}
+	result.Detail = strings.Join(problems, "; ")
+	return result
+}
+
+// checkFrontmatter verifies every entry file parses and belongs to its feed.
+// With fix set, bad files are renamed so they're ignored but kept for inspection.
+func (s *MarkdownStore) checkFrontmatter(ctx context.Context, feeds []feedEntry, fix bool) (CheckResult, error) {
+	result := CheckResult{Name: "frontmatter"}
+
+	var bad []string
+	checked := 0
+	for _, fe := range feeds {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		feedDir := s.feedDirPath(fe.Slug)
+		dirEntries, err := os.ReadDir(feedDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return result, fmt.Errorf("read feed directory: %w", err)
+		}
+		for _, de := range dirEntries {
+			if de.IsDir() || !strings.HasSuffix(de.Name(), ".md") {
+				continue
+			}
+			checked++
+			fp := filepath.Join(feedDir, de.Name())
+			entry, err := readEntryFile(fp)
+			if err == nil && entry.FeedID == fe.ID {
+				continue
+			}
+			bad = append(bad, fp)
+		}
+	}
+
+	if len(bad) == 0 {
+		result.OK = true
+		result.Detail = fmt.Sprintf("%d entry files valid", checked)
+		return result, nil
+	}
+
+	result.Fixable = true
+	result.Detail = fmt.Sprintf("%d of %d entry files are unreadable or filed under the wrong feed", len(bad), checked)
+	if fix {
+		for _, fp := range bad {
+			if err := os.Rename(fp, fp+invalidEntrySuffix); err != nil {
+				return result, fmt.Errorf("quarantine %s: %w", fp, err)
+			}
+		}
+		result.OK, result.Fixed = true, true
+		result.Detail = fmt.Sprintf("renamed %d bad entry files to *%s", len(bad), invalidEntrySuffix)
+	}
+	return result, nil
+}
+
+// checkOrphanedDirs finds directories of entry files that no feed points at.
+// These are reported but left alone, since they may hold the only copy of old entries.
+func (s *MarkdownStore) checkOrphanedDirs(feeds []feedEntry) (CheckResult, error) {
+	result := CheckResult{Name: "orphans"}
+
+	known := make(map[string]bool, len(feeds))
+	for _, fe := range feeds {
+		known[fe.Slug] = true
+	}
+
+	dirEntries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return result, fmt.Errorf("read data directory: %w", err)
+	}
+
+	var orphans []string
+	for _, de := range dirEntries {
+		if !de.IsDir() || known[de.Name()] {
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(s.dataDir, de.Name(), "*.md"))
+		if len(matches) > 0 {
+			orphans = append(orphans, de.Name())
+		}
+	}
+
+	if len(orphans) == 0 {
+		result.OK = true
+		result.Detail = "no orphaned feed directories"
+		return result, nil
+	}
+	result.Detail = "entry directories with no feed: " + strings.Join(orphans, ", ")
+	return result, nil
+}
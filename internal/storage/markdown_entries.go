@@ -4,9 +4,13 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"time"
@@ -17,8 +21,8 @@ import (
 )
 
 // CreateEntry stores a new entry.
-func (s *MarkdownStore) CreateEntry(entry *models.Entry) error {
-	slug, err := s.feedSlugByID(entry.FeedID)
+func (s *MarkdownStore) CreateEntry(ctx context.Context, entry *models.Entry) error {
+	slug, err := s.feedSlugByID(ctx, entry.FeedID)
 	if err != nil {
 		return fmt.Errorf("create entry: %w", err)
 	}
@@ -35,8 +39,8 @@ func (s *MarkdownStore) CreateEntry(entry *models.Entry) error {
 }
 
 // GetEntry retrieves an entry by ID.
-func (s *MarkdownStore) GetEntry(id string) (*models.Entry, error) {
-	entries, err := s.readFeeds()
+func (s *MarkdownStore) GetEntry(ctx context.Context, id string) (*models.Entry, error) {
+	entries, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -47,26 +51,32 @@ func (s *MarkdownStore) GetEntry(id string) (*models.Entry, error) {
 		if err != nil {
 			continue
 		}
-		return readEntryFile(fp)
+		return s.readEntry(fp)
 	}
 	return nil, fmt.Errorf("entry not found")
 }
 
 // GetEntryByPrefix finds an entry by ID prefix (min 6 chars).
-func (s *MarkdownStore) GetEntryByPrefix(prefix string) (*models.Entry, error) {
+func (s *MarkdownStore) GetEntryByPrefix(ctx context.Context, prefix string) (*models.Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	if len(prefix) < 6 {
 		return nil, fmt.Errorf("prefix must be at least 6 characters")
 	}
 
-	entries, err := s.readFeeds()
+	entries, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var matches []*models.Entry
 	for _, fe := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		feedDir := s.feedDirPath(fe.Slug)
-		feedEntries, err := readAllEntries(feedDir)
+		feedEntries, err := s.readEntries(feedDir)
 		if err != nil {
 			continue
 		}
@@ -86,27 +96,103 @@ func (s *MarkdownStore) GetEntryByPrefix(prefix string) (*models.Entry, error) {
 	return matches[0], nil
 }
 
-// ListEntries returns entries matching the filter, sorted by published date.
-func (s *MarkdownStore) ListEntries(filter *EntryFilter) ([]*models.Entry, error) {
-	feeds, err := s.readFeeds()
+// ListEntries returns entries matching the filter, sorted by published date,
+// or by when they were first seen when the filter asks for it.
+func (s *MarkdownStore) ListEntries(ctx context.Context, filter *EntryFilter) ([]*models.Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	var paths map[string]string
+	if filter != nil && filter.NoContent {
+		paths = make(map[string]string)
+	}
+	return s.listEntries(ctx, filter, paths)
+}
+
+// EachEntry calls fn with each entry ListEntries would return. It sorts
+// the entries without their content, then reads each one's file again as
+// fn needs it, so at most one feed directory is in memory at a time.
+func (s *MarkdownStore) EachEntry(ctx context.Context, filter *EntryFilter, fn func(*models.Entry) error) error {
+	listCtx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	paths := make(map[string]string)
+	heads, err := s.listEntries(listCtx, filter, paths)
+	if err != nil {
+		return err
+	}
+	for _, head := range heads {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := head
+		if filter == nil || !filter.NoContent {
+			entry, err = s.readEntry(paths[head.ID])
+			if errors.Is(err, fs.ErrNotExist) {
+				// Deleted or moved since the listing
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listEntries lists entries matching the filter. When paths is non-nil,
+// entries are read without their content and paths records each one's file.
+func (s *MarkdownStore) listEntries(ctx context.Context, filter *EntryFilter, paths map[string]string) ([]*models.Entry, error) {
+	feeds, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	feedSlugs := s.selectFeedSlugs(feeds, filter)
 
-	allEntries := s.collectEntries(feedSlugs)
+	allEntries, err := s.collectEntries(ctx, feedSlugs, paths)
+	if err != nil {
+		return nil, err
+	}
 
 	// Apply filters
 	if filter != nil {
+		if filter.LatestSync {
+			allEntries = latestSyncEntries(allEntries, feeds)
+		}
 		allEntries = applyEntryFilters(allEntries, filter)
 	}
 
-	// Sort by published date, newest first
+	// Sort by date, newest first
+	entryTime := entryPublishedTime
+	if filter != nil && filter.FirstSeen {
+		entryTime = entryFirstSeenTime
+	}
+	var ranks map[string]int
+	if filter != nil && filter.ByPriority {
+		ranks = make(map[string]int, len(feeds))
+		for _, f := range feeds {
+			ranks[f.ID] = models.PriorityRank(f.Priority)
+		}
+	}
 	sort.Slice(allEntries, func(i, j int) bool {
-		ti := entryPublishedTime(allEntries[i])
-		tj := entryPublishedTime(allEntries[j])
-		return ti.After(tj)
+		if a, b := ranks[allEntries[i].FeedID], ranks[allEntries[j].FeedID]; a != b {
+			return a < b
+		}
+		if filter != nil && filter.ByScore {
+			if a, b := allEntries[i].Score, allEntries[j].Score; (a == nil) != (b == nil) {
+				return a != nil
+			} else if a != nil && *a != *b {
+				return *a > *b
+			}
+		}
+		if a, b := entryTime(allEntries[i]), entryTime(allEntries[j]); !a.Equal(b) {
+			return a.After(b)
+		}
+		return allEntries[i].ID > allEntries[j].ID
 	})
 
 	allEntries = applyPagination(allEntries, filter)
@@ -144,19 +230,30 @@ func (s *MarkdownStore) selectFeedSlugs(feeds []feedEntry, filter *EntryFilter)
 	return feedSlugs
 }
 
-// collectEntries reads all entries from the given feed slugs.
-// Feeds whose directories cannot be read are silently skipped.
-func (s *MarkdownStore) collectEntries(feedSlugs map[string]bool) []*models.Entry {
+// collectEntries reads all entries from the given feed slugs, without
+// their content when paths is non-nil (see readEntryHeads).
+// Feeds whose directories cannot be read are silently skipped; a cancelled
+// context stops the scan.
+func (s *MarkdownStore) collectEntries(ctx context.Context, feedSlugs map[string]bool, paths map[string]string) ([]*models.Entry, error) {
 	var allEntries []*models.Entry
 	for slug := range feedSlugs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		feedDir := s.feedDirPath(slug)
-		entries, err := readAllEntries(feedDir)
+		var entries []*models.Entry
+		var err error
+		if paths != nil {
+			entries, err = s.readEntryHeads(feedDir, paths)
+		} else {
+			entries, err = s.readEntries(feedDir)
+		}
 		if err != nil {
 			continue
 		}
 		allEntries = append(allEntries, entries...)
 	}
-	return allEntries
+	return allEntries, nil
 }
 
 // applyPagination applies limit and offset from the filter to the entry slice.
@@ -178,24 +275,49 @@ func applyPagination(entries []*models.Entry, filter *EntryFilter) []*models.Ent
 	return entries
 }
 
+// latestSyncEntries keeps the entries stored by their feed's most recent
+// successful sync, which share its fetch time.
+func latestSyncEntries(entries []*models.Entry, feeds []feedEntry) []*models.Entry {
+	lastFetched := make(map[string]time.Time, len(feeds))
+	for _, fe := range feeds {
+		if fe.LastFetchedAt == nil {
+			continue
+		}
+		if t, err := mdstore.ParseTime(*fe.LastFetchedAt); err == nil {
+			lastFetched[fe.ID] = t
+		}
+	}
+	var result []*models.Entry
+	for _, e := range entries {
+		if t, ok := lastFetched[e.FeedID]; ok && !e.CreatedAt.Before(t) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
 // applyEntryFilters applies non-pagination filters to entries.
 func applyEntryFilters(entries []*models.Entry, filter *EntryFilter) []*models.Entry {
+	entryTime := entryPublishedTime
+	if filter.FirstSeen {
+		entryTime = entryFirstSeenTime
+	}
 	var result []*models.Entry
 	for _, e := range entries {
 		if filter.UnreadOnly != nil && *filter.UnreadOnly && e.Read {
 			continue
 		}
-		if filter.Since != nil {
-			pubTime := entryPublishedTime(e)
-			if !timeAfterOrEqual(pubTime, *filter.Since) {
-				continue
-			}
+		if filter.Since != nil && !timeAfterOrEqual(entryTime(e), *filter.Since) {
+			continue
 		}
-		if filter.Until != nil {
-			pubTime := entryPublishedTime(e)
-			if !timeBefore(pubTime, *filter.Until) {
-				continue
-			}
+		if filter.Until != nil && !timeBefore(entryTime(e), *filter.Until) {
+			continue
+		}
+		if filter.MinScore != nil && (e.Score == nil || *e.Score < *filter.MinScore) {
+			continue
+		}
+		if filter.KeptOnly && !e.KeepUnread {
+			continue
 		}
 		result = append(result, e)
 	}
@@ -210,9 +332,14 @@ func entryPublishedTime(e *models.Entry) time.Time {
 	return e.CreatedAt
 }
 
+// entryFirstSeenTime returns when the entry was first stored.
+func entryFirstSeenTime(e *models.Entry) time.Time {
+	return e.CreatedAt
+}
+
 // UpdateEntry updates an existing entry.
-func (s *MarkdownStore) UpdateEntry(entry *models.Entry) error {
-	slug, err := s.feedSlugByID(entry.FeedID)
+func (s *MarkdownStore) UpdateEntry(ctx context.Context, entry *models.Entry) error {
+	slug, err := s.feedSlugByID(ctx, entry.FeedID)
 	if err != nil {
 		return fmt.Errorf("update entry: %w", err)
 	}
@@ -223,12 +350,53 @@ func (s *MarkdownStore) UpdateEntry(entry *models.Entry) error {
 		return fmt.Errorf("entry not found: %s", entry.ID)
 	}
 
+	if s.journal != nil {
+		return s.updateEntryJournaled(fp, entry)
+	}
 	return writeEntryFile(fp, entry)
 }
 
+// updateEntryJournaled records a read state change in the journal and
+// rewrites the entry file only if something else about the entry changed.
+// The file keeps the read state it had, so read and unread never touch it.
+func (s *MarkdownStore) updateEntryJournaled(fp string, entry *models.Entry) error {
+	onDisk, err := readEntryFile(fp)
+	if err != nil {
+		return err
+	}
+	current := *onDisk
+	if err := s.journal.apply(&current); err != nil {
+		return err
+	}
+	if entry.Read != current.Read || !sameTime(entry.ReadAt, current.ReadAt) {
+		if err := s.journal.record(entry); err != nil {
+			return err
+		}
+	}
+
+	file := *entry
+	file.Read, file.ReadAt, file.UpdatedAt = onDisk.Read, onDisk.ReadAt, onDisk.UpdatedAt
+	if reflect.DeepEqual(fromEntryModel(&file), fromEntryModel(onDisk)) && reflect.DeepEqual(file.Content, onDisk.Content) {
+		return nil
+	}
+	if err := writeEntryFile(fp, &file); err != nil {
+		return err
+	}
+	entry.UpdatedAt = file.UpdatedAt
+	return nil
+}
+
+// sameTime reports whether two optional times are both unset or equal.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
 // DeleteEntry removes an entry.
-func (s *MarkdownStore) DeleteEntry(id string) error {
-	feeds, err := s.readFeeds()
+func (s *MarkdownStore) DeleteEntry(ctx context.Context, id string) error {
+	feeds, err := s.readFeeds(ctx)
 	if err != nil {
 		return err
 	}
@@ -242,14 +410,52 @@ func (s *MarkdownStore) DeleteEntry(id string) error {
 		if err := os.Remove(fp); err != nil {
 			return fmt.Errorf("delete entry file: %w", err)
 		}
+		return s.recordDeletions(&models.Entry{ID: id, FeedID: fe.ID})
+	}
+	return fmt.Errorf("entry not found: %s", id)
+}
+
+// MoveEntry reassigns an entry to another feed, keeping its ID and read state.
+func (s *MarkdownStore) MoveEntry(ctx context.Context, id, feedID string) error {
+	slug, err := s.feedSlugByID(ctx, feedID)
+	if err != nil {
+		return fmt.Errorf("move entry: %w", err)
+	}
+	feeds, err := s.readFeeds(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, fe := range feeds {
+		oldPath, err := findEntryFile(s.feedDirPath(fe.Slug), id)
+		if err != nil {
+			continue
+		}
+		entry, err := s.readEntry(oldPath)
+		if err != nil {
+			return err
+		}
+		entry.FeedID = feedID
+
+		feedDir := s.feedDirPath(slug)
+		if err := mdstore.EnsureDir(feedDir); err != nil {
+			return fmt.Errorf("create feed directory: %w", err)
+		}
+		// Write the new copy before removing the old one so a failure can't lose the entry
+		if err := writeEntryFile(filepath.Join(feedDir, entryFileName(entry)), entry); err != nil {
+			return err
+		}
+		if err := os.Remove(oldPath); err != nil {
+			return fmt.Errorf("remove moved entry file: %w", err)
+		}
 		return nil
 	}
 	return fmt.Errorf("entry not found: %s", id)
 }
 
 // MarkEntryRead marks an entry as read.
-func (s *MarkdownStore) MarkEntryRead(id string) error {
-	entry, err := s.GetEntry(id)
+func (s *MarkdownStore) MarkEntryRead(ctx context.Context, id string) error {
+	entry, err := s.GetEntry(ctx, id)
 	if err != nil {
 		return fmt.Errorf("entry not found: %s", id)
 	}
@@ -258,12 +464,12 @@ func (s *MarkdownStore) MarkEntryRead(id string) error {
 	entry.Read = true
 	entry.ReadAt = &now
 
-	return s.UpdateEntry(entry)
+	return s.UpdateEntry(ctx, entry)
 }
 
 // MarkEntryUnread marks an entry as unread.
-func (s *MarkdownStore) MarkEntryUnread(id string) error {
-	entry, err := s.GetEntry(id)
+func (s *MarkdownStore) MarkEntryUnread(ctx context.Context, id string) error {
+	entry, err := s.GetEntry(ctx, id)
 	if err != nil {
 		return fmt.Errorf("entry not found: %s", id)
 	}
@@ -271,12 +477,33 @@ func (s *MarkdownStore) MarkEntryUnread(id string) error {
 	entry.Read = false
 	entry.ReadAt = nil
 
-	return s.UpdateEntry(entry)
+	return s.UpdateEntry(ctx, entry)
 }
 
-// MarkEntriesReadBefore marks all unread entries before the given time as read.
-func (s *MarkdownStore) MarkEntriesReadBefore(before time.Time) (int64, error) {
-	feeds, err := s.readFeeds()
+// SetEntryKeepUnread pins an entry unread, marking it unread if it was
+// read, or releases the pin.
+func (s *MarkdownStore) SetEntryKeepUnread(ctx context.Context, id string, keep bool) error {
+	entry, err := s.GetEntry(ctx, id)
+	if err != nil {
+		return fmt.Errorf("entry not found: %s", id)
+	}
+
+	entry.KeepUnread = keep
+	if keep {
+		entry.Read = false
+		entry.ReadAt = nil
+	}
+
+	return s.UpdateEntry(ctx, entry)
+}
+
+// MarkEntriesReadBefore marks all unread entries before the given time as
+// read, except those kept unread.
+func (s *MarkdownStore) MarkEntriesReadBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	feeds, err := s.readFeeds(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -285,20 +512,28 @@ func (s *MarkdownStore) MarkEntriesReadBefore(before time.Time) (int64, error) {
 	var count int64
 
 	for _, fe := range feeds {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
 		feedDir := s.feedDirPath(fe.Slug)
-		entries, err := readAllEntries(feedDir)
+		entries, err := s.readEntries(feedDir)
 		if err != nil {
 			continue
 		}
 
+		var marked []*models.Entry
 		for _, entry := range entries {
-			if entry.Read {
+			if entry.Read || entry.KeepUnread {
 				continue
 			}
 			pubTime := entryPublishedTime(entry)
 			if pubTime.Before(before) {
 				entry.Read = true
 				entry.ReadAt = &now
+				if s.journal != nil {
+					marked = append(marked, entry)
+					continue
+				}
 				fp, findErr := findEntryFile(feedDir, entry.ID)
 				if findErr != nil {
 					continue
@@ -309,20 +544,59 @@ func (s *MarkdownStore) MarkEntriesReadBefore(before time.Time) (int64, error) {
 				count++
 			}
 		}
+		if len(marked) > 0 {
+			if err := s.journal.record(marked...); err != nil {
+				return count, err
+			}
+			count += int64(len(marked))
+		}
 	}
 
 	return count, nil
 }
 
+// GetChangesSince returns entries created, changed or deleted after
+// cursor, up to limit (0 for no limit). Every entry file is read, so this
+// costs as much as listing everything; it saves the caller the work, not
+// the store.
+func (s *MarkdownStore) GetChangesSince(ctx context.Context, cursor string, limit int) (*ChangeSet, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	feeds, err := s.readFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.collectEntries(ctx, s.selectFeedSlugs(feeds, nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := s.readDeletions()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]change, 0, len(entries)+len(deleted))
+	for _, e := range entries {
+		// An entry deleted and then fetched again is back, not deleted
+		delete(deleted, e.ID)
+		changes = append(changes, change{pos: changeCursor{at: e.UpdatedAt, id: e.ID}, entry: e})
+	}
+	for _, d := range deleted {
+		changes = append(changes, change{pos: changeCursor{at: d.DeletedAt, id: d.ID}, deleted: d})
+	}
+	return pageChanges(changes, cursor, limit)
+}
+
 // EntryExists checks if an entry exists with the given feed_id and guid.
-func (s *MarkdownStore) EntryExists(feedID, guid string) (bool, error) {
-	slug, err := s.feedSlugByID(feedID)
+func (s *MarkdownStore) EntryExists(ctx context.Context, feedID, guid string) (bool, error) {
+	slug, err := s.feedSlugByID(ctx, feedID)
 	if err != nil {
 		return false, err
 	}
 
 	feedDir := s.feedDirPath(slug)
-	entries, err := readAllEntries(feedDir)
+	entries, err := s.readEntries(feedDir)
 	if err != nil {
 		return false, err
 	}
@@ -336,19 +610,25 @@ func (s *MarkdownStore) EntryExists(feedID, guid string) (bool, error) {
 }
 
 // CountUnreadEntries counts unread entries, optionally filtered by feedID.
-func (s *MarkdownStore) CountUnreadEntries(feedID *string) (int, error) {
-	feeds, err := s.readFeeds()
+func (s *MarkdownStore) CountUnreadEntries(ctx context.Context, feedID *string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	feeds, err := s.readFeeds(ctx)
 	if err != nil {
 		return 0, err
 	}
 
 	count := 0
 	for _, fe := range feeds {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
 		if feedID != nil && fe.ID != *feedID {
 			continue
 		}
 		feedDir := s.feedDirPath(fe.Slug)
-		entries, err := readAllEntries(feedDir)
+		entries, err := s.readEntries(feedDir)
 		if err != nil {
 			continue
 		}
@@ -361,29 +641,72 @@ func (s *MarkdownStore) CountUnreadEntries(feedID *string) (int, error) {
 	return count, nil
 }
 
+// CountEntries counts entry files, optionally only those of feedID,
+// without reading them.
+func (s *MarkdownStore) CountEntries(ctx context.Context, feedID *string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	feeds, err := s.readFeeds(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, fe := range feeds {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if feedID != nil && fe.ID != *feedID {
+			continue
+		}
+		dirEntries, err := os.ReadDir(s.feedDirPath(fe.Slug))
+		if err != nil {
+			continue
+		}
+		for _, de := range dirEntries {
+			if !de.IsDir() && strings.HasSuffix(de.Name(), ".md") && !isSyncConflict(de.Name()) {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
 // GetFeedStats retrieves statistics for all feeds.
-func (s *MarkdownStore) GetFeedStats() ([]FeedStatsRow, error) {
-	feedEntries, err := s.readFeeds()
+func (s *MarkdownStore) GetFeedStats(ctx context.Context) ([]FeedStatsRow, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	feedEntries, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var stats []FeedStatsRow
 	for _, fe := range feedEntries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		feed, err := fe.toModel()
 		if err != nil {
 			continue
 		}
 
 		feedDir := s.feedDirPath(fe.Slug)
-		entries, _ := readAllEntries(feedDir)
+		entries, _ := s.readEntries(feedDir)
 
 		entryCount := len(entries)
 		unreadCount := 0
+		var lastEntryAt *time.Time
 		for _, e := range entries {
 			if !e.Read {
 				unreadCount++
 			}
+			if lastEntryAt == nil || e.CreatedAt.After(*lastEntryAt) {
+				created := e.CreatedAt
+				lastEntryAt = &created
+			}
 		}
 
 		stats = append(stats, FeedStatsRow{
@@ -395,14 +718,19 @@ func (s *MarkdownStore) GetFeedStats() ([]FeedStatsRow, error) {
 			LastError:     feed.LastError,
 			EntryCount:    entryCount,
 			UnreadCount:   unreadCount,
+			LastEntryAt:   lastEntryAt,
+			ArchivedAt:    feed.ArchivedAt,
 		})
 	}
 	return stats, nil
 }
 
 // GetOverallStats retrieves overall statistics.
-func (s *MarkdownStore) GetOverallStats() (*OverallStats, error) {
-	feedEntries, err := s.readFeeds()
+func (s *MarkdownStore) GetOverallStats(ctx context.Context) (*OverallStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	feedEntries, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -412,8 +740,11 @@ func (s *MarkdownStore) GetOverallStats() (*OverallStats, error) {
 	}
 
 	for _, fe := range feedEntries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		feedDir := s.feedDirPath(fe.Slug)
-		entries, _ := readAllEntries(feedDir)
+		entries, _ := s.readEntries(feedDir)
 		stats.TotalEntries += len(entries)
 		for _, e := range entries {
 			if !e.Read {
@@ -427,13 +758,13 @@ func (s *MarkdownStore) GetOverallStats() (*OverallStats, error) {
 
 // GetEntryByIDOrPrefix tries to get an entry by exact ID first,
 // then falls back to prefix matching if not found.
-func (s *MarkdownStore) GetEntryByIDOrPrefix(ref string) (*models.Entry, error) {
-	entry, err := s.GetEntry(ref)
+func (s *MarkdownStore) GetEntryByIDOrPrefix(ctx context.Context, ref string) (*models.Entry, error) {
+	entry, err := s.GetEntry(ctx, ref)
 	if err == nil {
 		return entry, nil
 	}
 
-	entry, err = s.GetEntryByPrefix(ref)
+	entry, err = s.GetEntryByPrefix(ctx, ref)
 	if err != nil {
 		return nil, fmt.Errorf("entry not found: %s", ref)
 	}
@@ -442,27 +773,68 @@ func (s *MarkdownStore) GetEntryByIDOrPrefix(ref string) (*models.Entry, error)
 
 // GetFeedByURLOrPrefix tries to get a feed by exact URL first,
 // then falls back to prefix matching if not found.
-func (s *MarkdownStore) GetFeedByURLOrPrefix(ref string) (*models.Feed, error) {
-	feed, err := s.GetFeedByURL(ref)
+func (s *MarkdownStore) GetFeedByURLOrPrefix(ctx context.Context, ref string) (*models.Feed, error) {
+	feed, err := s.GetFeedByURL(ctx, ref)
 	if err == nil {
 		return feed, nil
 	}
 
-	feed, err = s.GetFeedByPrefix(ref)
+	feed, err = s.GetFeedByPrefix(ctx, ref)
 	if err != nil {
 		return nil, fmt.Errorf("feed not found: %s", ref)
 	}
 	return feed, nil
 }
 
-// Compact is a no-op for markdown storage.
-func (s *MarkdownStore) Compact() error {
-	return nil
+// Compact drops this machine's superseded read state journal events in
+// sync-safe mode and is otherwise a no-op for markdown storage.
+func (s *MarkdownStore) Compact(ctx context.Context) error {
+	if err := ctx.Err(); err != nil || s.journal == nil {
+		return err
+	}
+	_, err := s.journal.compact()
+	return err
+}
+
+// EntryFootprint counts the files of entries and their sizes. Markdown
+// storage has no search index, so it has no FTS rows.
+func (s *MarkdownStore) EntryFootprint(ctx context.Context, entries []*models.Entry) (*Footprint, error) {
+	byFeed := make(map[string]map[string]bool)
+	for _, e := range entries {
+		if byFeed[e.FeedID] == nil {
+			byFeed[e.FeedID] = make(map[string]bool)
+		}
+		byFeed[e.FeedID][e.ID] = true
+	}
+
+	fp := &Footprint{}
+	for feedID, ids := range byFeed {
+		slug, err := s.feedSlugByID(ctx, feedID)
+		if err != nil {
+			return nil, err
+		}
+		err = walkEntryFiles(s.feedDirPath(slug), func(path string, entry *models.Entry) {
+			if !ids[entry.ID] {
+				return
+			}
+			if info, err := os.Stat(path); err == nil {
+				fp.Files++
+				fp.Bytes += info.Size()
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fp, nil
 }
 
 // Search performs case-insensitive string matching on entry title and content.
-func (s *MarkdownStore) Search(query string, limit int) ([]*models.Entry, error) {
-	feeds, err := s.readFeeds()
+func (s *MarkdownStore) Search(ctx context.Context, query string, limit int) ([]*models.Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	feeds, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -471,8 +843,11 @@ func (s *MarkdownStore) Search(query string, limit int) ([]*models.Entry, error)
 	var results []*models.Entry
 
 	for _, fe := range feeds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		feedDir := s.feedDirPath(fe.Slug)
-		entries, err := readAllEntries(feedDir)
+		entries, err := s.readEntries(feedDir)
 		if err != nil {
 			continue
 		}
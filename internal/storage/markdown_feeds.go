@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -15,9 +16,9 @@ import (
 )
 
 // CreateFeed stores a new feed.
-func (s *MarkdownStore) CreateFeed(feed *models.Feed) error {
+func (s *MarkdownStore) CreateFeed(ctx context.Context, feed *models.Feed) error {
 	return mdstore.WithLock(s.dataDir, func() error {
-		entries, err := s.readFeeds()
+		entries, err := s.readFeeds(ctx)
 		if err != nil {
 			return err
 		}
@@ -46,8 +47,8 @@ func (s *MarkdownStore) CreateFeed(feed *models.Feed) error {
 }
 
 // GetFeed retrieves a feed by ID.
-func (s *MarkdownStore) GetFeed(id string) (*models.Feed, error) {
-	entries, err := s.readFeeds()
+func (s *MarkdownStore) GetFeed(ctx context.Context, id string) (*models.Feed, error) {
+	entries, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -65,8 +66,8 @@ func (s *MarkdownStore) GetFeed(id string) (*models.Feed, error) {
 }
 
 // GetFeedByURL finds a feed by its URL.
-func (s *MarkdownStore) GetFeedByURL(url string) (*models.Feed, error) {
-	entries, err := s.readFeeds()
+func (s *MarkdownStore) GetFeedByURL(ctx context.Context, url string) (*models.Feed, error) {
+	entries, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -84,12 +85,12 @@ func (s *MarkdownStore) GetFeedByURL(url string) (*models.Feed, error) {
 }
 
 // GetFeedByPrefix finds a feed by ID prefix (min 6 chars).
-func (s *MarkdownStore) GetFeedByPrefix(prefix string) (*models.Feed, error) {
+func (s *MarkdownStore) GetFeedByPrefix(ctx context.Context, prefix string) (*models.Feed, error) {
 	if len(prefix) < 6 {
 		return nil, fmt.Errorf("prefix must be at least 6 characters")
 	}
 
-	entries, err := s.readFeeds()
+	entries, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -115,8 +116,8 @@ func (s *MarkdownStore) GetFeedByPrefix(prefix string) (*models.Feed, error) {
 }
 
 // ListFeeds returns all feeds, sorted by creation date (newest first).
-func (s *MarkdownStore) ListFeeds() ([]*models.Feed, error) {
-	entries, err := s.readFeeds()
+func (s *MarkdownStore) ListFeeds(ctx context.Context) ([]*models.Feed, error) {
+	entries, err := s.readFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -137,9 +138,9 @@ func (s *MarkdownStore) ListFeeds() ([]*models.Feed, error) {
 }
 
 // UpdateFeed updates an existing feed.
-func (s *MarkdownStore) UpdateFeed(feed *models.Feed) error {
+func (s *MarkdownStore) UpdateFeed(ctx context.Context, feed *models.Feed) error {
 	return mdstore.WithLock(s.dataDir, func() error {
-		entries, err := s.readFeeds()
+		entries, err := s.readFeeds(ctx)
 		if err != nil {
 			return err
 		}
@@ -162,9 +163,9 @@ func (s *MarkdownStore) UpdateFeed(feed *models.Feed) error {
 }
 
 // DeleteFeed removes a feed and all its entries (cascade).
-func (s *MarkdownStore) DeleteFeed(id string) error {
+func (s *MarkdownStore) DeleteFeed(ctx context.Context, id string) error {
 	return mdstore.WithLock(s.dataDir, func() error {
-		entries, err := s.readFeeds()
+		entries, err := s.readFeeds(ctx)
 		if err != nil {
 			return err
 		}
@@ -189,20 +190,24 @@ func (s *MarkdownStore) DeleteFeed(id string) error {
 			return fmt.Errorf("write feeds: %w", err)
 		}
 
-		// Remove feed directory and all entry files
+		// Remove feed directory and all entry files, recording the entries
+		// for change feeds
 		feedDir := s.feedDirPath(slug)
+		removed, err := readAllEntries(feedDir)
+		if err != nil {
+			return err
+		}
 		if err := os.RemoveAll(feedDir); err != nil {
 			return fmt.Errorf("remove feed directory: %w", err)
 		}
-
-		return nil
+		return s.recordDeletions(removed...)
 	})
 }
 
 // UpdateFeedFetchState updates feed caching headers and clears errors.
-func (s *MarkdownStore) UpdateFeedFetchState(feedID string, etag, lastModified *string, fetchedAt time.Time) error {
+func (s *MarkdownStore) UpdateFeedFetchState(ctx context.Context, feedID string, etag, lastModified *string, fetchedAt time.Time) error {
 	return mdstore.WithLock(s.dataDir, func() error {
-		entries, err := s.readFeeds()
+		entries, err := s.readFeeds(ctx)
 		if err != nil {
 			return err
 		}
@@ -230,9 +235,9 @@ func (s *MarkdownStore) UpdateFeedFetchState(feedID string, etag, lastModified *
 }
 
 // UpdateFeedError records a fetch error for a feed.
-func (s *MarkdownStore) UpdateFeedError(feedID string, errMsg string) error {
+func (s *MarkdownStore) UpdateFeedError(ctx context.Context, feedID string, errMsg string) error {
 	return mdstore.WithLock(s.dataDir, func() error {
-		entries, err := s.readFeeds()
+		entries, err := s.readFeeds(ctx)
 		if err != nil {
 			return err
 		}
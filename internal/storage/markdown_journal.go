@@ -0,0 +1,280 @@
+// ABOUTME: Append-only read state journal that makes a MarkdownStore safe to share through file sync
+// ABOUTME: Each machine appends to its own file; loading merges every journal with a deterministic last-write-wins
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harperreed/mdstore"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// journalDirName is the directory under the data directory that holds one
+// read state journal per machine.
+const journalDirName = "_state"
+
+// journalEvent is one line of a journal: an entry, identified by feed ID and
+// GUID so duplicates fetched on different machines share it, was marked read
+// or unread at a time.
+type journalEvent struct {
+	Time   time.Time  `json:"t"`
+	FeedID string     `json:"feed"`
+	GUID   string     `json:"guid"`
+	Read   bool       `json:"read"`
+	ReadAt *time.Time `json:"read_at,omitempty"`
+
+	device string // Journal the event came from, for tie-breaking
+	seq    int    // Line number within that journal
+}
+
+// after reports whether e wins over o. Later events win; ties go to the
+// greater device ID and then to the later line, so every machine that sees
+// the same journals picks the same winner.
+func (e *journalEvent) after(o *journalEvent) bool {
+	if !e.Time.Equal(o.Time) {
+		return e.Time.After(o.Time)
+	}
+	if e.device != o.device {
+		return e.device > o.device
+	}
+	return e.seq > o.seq
+}
+
+// journalKey identifies an entry across machines.
+func journalKey(feedID, guid string) string {
+	return feedID + "\x00" + guid
+}
+
+// stateJournal merges every machine's journal and appends to this machine's.
+// Loads are cached until a journal file changes size or modification time.
+type stateJournal struct {
+	dir    string
+	device string
+
+	mu     sync.Mutex
+	stamps map[string]string // File name to size and mtime at last load
+	state  map[string]*journalEvent
+}
+
+func newStateJournal(dataDir, device string) (*stateJournal, error) {
+	if strings.TrimSpace(device) == "" {
+		return nil, fmt.Errorf("sync-safe markdown storage needs a device ID")
+	}
+	device = mdstore.Slugify(device)
+	dir := filepath.Join(dataDir, journalDirName)
+	if err := mdstore.EnsureDir(dir); err != nil {
+		return nil, fmt.Errorf("create state journal directory: %w", err)
+	}
+	return &stateJournal{dir: dir, device: device}, nil
+}
+
+// path returns this machine's journal file.
+func (j *stateJournal) path() string {
+	return filepath.Join(j.dir, j.device+".jsonl")
+}
+
+// load returns the merged state, rereading the journals if any changed.
+func (j *stateJournal) load() (map[string]*journalEvent, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(j.dir, "*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("list state journals: %w", err)
+	}
+	stamps := make(map[string]string, len(files))
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		stamps[filepath.Base(f)] = fmt.Sprintf("%d/%d", info.Size(), info.ModTime().UnixNano())
+	}
+	if j.state != nil && sameStamps(stamps, j.stamps) {
+		return j.state, nil
+	}
+
+	state := make(map[string]*journalEvent)
+	for _, f := range files {
+		name := filepath.Base(f)
+		if isSyncConflict(name) {
+			// Only this machine writes its journal, so a conflict copy is a
+			// stale duplicate of lines the real file already has.
+			continue
+		}
+		events, err := readJournal(f, strings.TrimSuffix(name, ".jsonl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			key := journalKey(e.FeedID, e.GUID)
+			if cur := state[key]; cur == nil || e.after(cur) {
+				state[key] = e
+			}
+		}
+	}
+	j.state, j.stamps = state, stamps
+	return state, nil
+}
+
+func sameStamps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// readJournal parses one journal file. Lines that don't parse, such as a
+// last line still being written or synced, are skipped.
+func readJournal(path, device string) ([]*journalEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open state journal: %w", err)
+	}
+	defer f.Close()
+
+	var events []*journalEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for seq := 0; scanner.Scan(); seq++ {
+		var e journalEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.FeedID == "" || e.GUID == "" {
+			continue
+		}
+		e.device, e.seq = device, seq
+		events = append(events, &e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read state journal %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// record appends read state changes to this machine's journal.
+func (j *stateJournal) record(entries ...*models.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	now := changeTime()
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		entry.UpdatedAt = now
+		e := journalEvent{Time: now, FeedID: entry.FeedID, GUID: entry.GUID, Read: entry.Read}
+		if entry.Read && entry.ReadAt != nil {
+			readAt := entry.ReadAt.UTC()
+			e.ReadAt = &readAt
+		}
+		line, err := json.Marshal(&e)
+		if err != nil {
+			return fmt.Errorf("encode state journal event: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	f, err := os.OpenFile(j.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("open state journal: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("append to state journal: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("append to state journal: %w", err)
+	}
+	j.state = nil // Reload on next use
+	return nil
+}
+
+// apply overlays the journal's read state on entries read from files. An
+// event later than the file's last write becomes the entry's change time.
+func (j *stateJournal) apply(entries ...*models.Entry) error {
+	state, err := j.load()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if e := state[journalKey(entry.FeedID, entry.GUID)]; e != nil {
+			entry.Read = e.Read
+			entry.ReadAt = nil
+			if e.Read && e.ReadAt != nil {
+				readAt := *e.ReadAt
+				entry.ReadAt = &readAt
+			}
+			if e.Time.After(entry.UpdatedAt) {
+				entry.UpdatedAt = e.Time
+			}
+		}
+	}
+	return nil
+}
+
+// compact rewrites this machine's journal keeping only its events that
+// still decide an entry's state. Other machines' journals are left alone so
+// each file keeps a single writer.
+func (j *stateJournal) compact() (removed int, err error) {
+	state, err := j.load()
+	if err != nil {
+		return 0, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	own, err := readJournal(j.path(), j.device)
+	if err != nil {
+		return 0, err
+	}
+	var keep []*journalEvent
+	for _, e := range own {
+		if w := state[journalKey(e.FeedID, e.GUID)]; w != nil && w.device == e.device && w.seq == e.seq {
+			keep = append(keep, e)
+		}
+	}
+	if len(keep) == len(own) {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	for _, e := range keep {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return 0, fmt.Errorf("encode state journal event: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := mdstore.AtomicWrite(j.path(), buf.Bytes()); err != nil {
+		return 0, fmt.Errorf("rewrite state journal: %w", err)
+	}
+	j.state = nil
+	return len(own) - len(keep), nil
+}
+
+// isSyncConflict reports whether a file name is a conflict copy left by a
+// file sync tool, such as Syncthing's "x.sync-conflict-20240102-150405-ABCDEFG.md"
+// or Dropbox's "x (conflicted copy 2024-01-02).md".
+func isSyncConflict(name string) bool {
+	return strings.Contains(name, ".sync-conflict-") || strings.Contains(name, "conflicted copy")
+}
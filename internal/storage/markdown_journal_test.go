@@ -0,0 +1,188 @@
+// ABOUTME: Tests for the sync-safe markdown read state journal
+// ABOUTME: Simulates two machines sharing one data directory and checks merging, conflict copies, and compaction
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// newSyncSafePair opens two sync-safe stores on one directory, as two
+// machines sharing it through a file sync tool would.
+func newSyncSafePair(t *testing.T) (*MarkdownStore, *MarkdownStore, string) {
+	t.Helper()
+	dir := t.TempDir()
+	a, err := NewMarkdownStoreWithOptions(dir, MarkdownOptions{SyncSafe: true, DeviceID: "laptop"})
+	if err != nil {
+		t.Fatalf("open laptop store: %v", err)
+	}
+	b, err := NewMarkdownStoreWithOptions(dir, MarkdownOptions{SyncSafe: true, DeviceID: "desktop"})
+	if err != nil {
+		t.Fatalf("open desktop store: %v", err)
+	}
+	return a, b, dir
+}
+
+func TestSyncSafeRequiresDeviceID(t *testing.T) {
+	if _, err := NewMarkdownStoreWithOptions(t.TempDir(), MarkdownOptions{SyncSafe: true}); err == nil {
+		t.Error("expected an error without a device ID")
+	}
+}
+
+func TestSyncSafeReadStateSharedWithoutRewritingEntries(t *testing.T) {
+	ctx := context.Background()
+	laptop, desktop, dir := newSyncSafePair(t)
+
+	feed := models.NewFeed("https://example.com/feed")
+	mustNoErr(t, laptop.CreateFeed(ctx, feed))
+	entry := models.NewEntry(feed.ID, "guid-1", "Post")
+	mustNoErr(t, laptop.CreateEntry(ctx, entry))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.md"))
+	if len(files) != 1 {
+		t.Fatalf("expected one entry file, got %v", files)
+	}
+	before, _ := os.ReadFile(files[0])
+
+	mustNoErr(t, laptop.MarkEntryRead(ctx, entry.ID))
+	got, err := desktop.GetEntry(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("GetEntry: %v", err)
+	}
+	if !got.Read || got.ReadAt == nil {
+		t.Errorf("expected the desktop to see the entry read, got read=%v at %v", got.Read, got.ReadAt)
+	}
+
+	// A later change on the other machine wins
+	mustNoErr(t, desktop.MarkEntryUnread(ctx, entry.ID))
+	got, _ = laptop.GetEntry(ctx, entry.ID)
+	if got.Read {
+		t.Error("expected the laptop to see the desktop's later unread")
+	}
+	if n, _ := laptop.CountUnreadEntries(ctx, nil); n != 1 {
+		t.Errorf("expected 1 unread, got %d", n)
+	}
+
+	after, _ := os.ReadFile(files[0])
+	if !bytes.Equal(before, after) {
+		t.Error("read state changes should not rewrite the entry file")
+	}
+	for _, device := range []string{"laptop", "desktop"} {
+		if _, err := os.Stat(filepath.Join(dir, journalDirName, device+".jsonl")); err != nil {
+			t.Errorf("expected a journal for %s: %v", device, err)
+		}
+	}
+
+	// Other edits still reach the file, keeping its original read state
+	got.Title = ptr("Renamed")
+	got.Read = true
+	mustNoErr(t, laptop.UpdateEntry(ctx, got))
+	onDisk, err := readEntryFile(files[0])
+	if err != nil {
+		t.Fatalf("readEntryFile: %v", err)
+	}
+	if onDisk.GetTitle() != "Renamed" || onDisk.Read {
+		t.Errorf("expected the new title and the file's old read state, got %q read=%v", onDisk.GetTitle(), onDisk.Read)
+	}
+	if got, _ := desktop.GetEntry(ctx, entry.ID); !got.Read {
+		t.Error("expected the read change in the same update to be journaled")
+	}
+}
+
+func TestSyncSafeDuplicatesShareStateByGUID(t *testing.T) {
+	ctx := context.Background()
+	laptop, desktop, _ := newSyncSafePair(t)
+
+	feed := models.NewFeed("https://example.com/feed")
+	mustNoErr(t, laptop.CreateFeed(ctx, feed))
+	old := time.Now().Add(-48 * time.Hour)
+	fromLaptop := models.NewEntry(feed.ID, "guid-1", "Post")
+	fromLaptop.PublishedAt = &old
+	mustNoErr(t, laptop.CreateEntry(ctx, fromLaptop))
+	// Both machines fetched the same item before syncing
+	fromDesktop := models.NewEntry(feed.ID, "guid-1", "Post")
+	fromDesktop.PublishedAt = &old
+	mustNoErr(t, desktop.CreateEntry(ctx, fromDesktop))
+
+	count, err := desktop.MarkEntriesReadBefore(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("MarkEntriesReadBefore: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 marked, got %d", count)
+	}
+	for _, id := range []string{fromLaptop.ID, fromDesktop.ID} {
+		if got, _ := laptop.GetEntry(ctx, id); !got.Read {
+			t.Errorf("expected entry %s read on the laptop", id)
+		}
+	}
+}
+
+func TestSyncSafeToleratesPartialLinesAndConflictCopies(t *testing.T) {
+	ctx := context.Background()
+	laptop, desktop, dir := newSyncSafePair(t)
+
+	feed := models.NewFeed("https://example.com/feed")
+	mustNoErr(t, laptop.CreateFeed(ctx, feed))
+	entry := models.NewEntry(feed.ID, "guid-1", "Post")
+	mustNoErr(t, laptop.CreateEntry(ctx, entry))
+	mustNoErr(t, laptop.MarkEntryRead(ctx, entry.ID))
+
+	// A line cut short mid-sync is skipped
+	journal := filepath.Join(dir, journalDirName, "laptop.jsonl")
+	f, err := os.OpenFile(journal, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	_, _ = f.WriteString(`{"t":"2099-01-01T00:00:00Z","feed":"` + feed.ID + `","guid":"guid-1","re`)
+	f.Close()
+
+	// A conflict copy of an entry file is ignored rather than listed twice
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.md"))
+	data, _ := os.ReadFile(files[0])
+	conflict := files[0][:len(files[0])-len(".md")] + ".sync-conflict-20250101-120000-ABCDEFG.md"
+	mustNoErr(t, os.WriteFile(conflict, data, 0600))
+
+	entries, err := desktop.ListEntries(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].Read {
+		t.Errorf("expected one read entry, got %d entries", len(entries))
+	}
+}
+
+func TestSyncSafeCompactDropsSupersededEvents(t *testing.T) {
+	ctx := context.Background()
+	laptop, desktop, dir := newSyncSafePair(t)
+
+	feed := models.NewFeed("https://example.com/feed")
+	mustNoErr(t, laptop.CreateFeed(ctx, feed))
+	entry := models.NewEntry(feed.ID, "guid-1", "Post")
+	mustNoErr(t, laptop.CreateEntry(ctx, entry))
+	mustNoErr(t, laptop.MarkEntryRead(ctx, entry.ID))
+	mustNoErr(t, laptop.MarkEntryUnread(ctx, entry.ID))
+	mustNoErr(t, laptop.MarkEntryRead(ctx, entry.ID))
+	mustNoErr(t, desktop.MarkEntryUnread(ctx, entry.ID))
+
+	mustNoErr(t, laptop.Compact(ctx))
+	events, err := readJournal(filepath.Join(dir, journalDirName, "laptop.jsonl"), "laptop")
+	if err != nil {
+		t.Fatalf("readJournal: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected the laptop's superseded events dropped, %d left", len(events))
+	}
+	if got, _ := laptop.GetEntry(ctx, entry.ID); got.Read {
+		t.Error("compaction changed the merged state")
+	}
+}
+
+func ptr(s string) *string { return &s }
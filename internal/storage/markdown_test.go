@@ -4,8 +4,11 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -62,12 +65,12 @@ func TestMarkdownFeedCRUD(t *testing.T) {
 	feed.Title = &title
 	feed.Folder = "Tech"
 
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Get by ID
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -82,7 +85,7 @@ func TestMarkdownFeedCRUD(t *testing.T) {
 	}
 
 	// Get by URL
-	got, err = store.GetFeedByURL("https://example.com/feed.xml")
+	got, err = store.GetFeedByURL(context.Background(), "https://example.com/feed.xml")
 	if err != nil {
 		t.Fatalf("GetFeedByURL failed: %v", err)
 	}
@@ -92,7 +95,7 @@ func TestMarkdownFeedCRUD(t *testing.T) {
 
 	// Get by prefix
 	prefix := feed.ID[:8]
-	got, err = store.GetFeedByPrefix(prefix)
+	got, err = store.GetFeedByPrefix(context.Background(), prefix)
 	if err != nil {
 		t.Fatalf("GetFeedByPrefix failed: %v", err)
 	}
@@ -103,11 +106,11 @@ func TestMarkdownFeedCRUD(t *testing.T) {
 	// Update feed
 	newTitle := "Updated Feed"
 	feed.Title = &newTitle
-	if err := store.UpdateFeed(feed); err != nil {
+	if err := store.UpdateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("UpdateFeed failed: %v", err)
 	}
 
-	got, err = store.GetFeed(feed.ID)
+	got, err = store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed after update failed: %v", err)
 	}
@@ -116,7 +119,7 @@ func TestMarkdownFeedCRUD(t *testing.T) {
 	}
 
 	// List feeds
-	feeds, err := store.ListFeeds()
+	feeds, err := store.ListFeeds(context.Background())
 	if err != nil {
 		t.Fatalf("ListFeeds failed: %v", err)
 	}
@@ -125,11 +128,11 @@ func TestMarkdownFeedCRUD(t *testing.T) {
 	}
 
 	// Delete feed
-	if err := store.DeleteFeed(feed.ID); err != nil {
+	if err := store.DeleteFeed(context.Background(), feed.ID); err != nil {
 		t.Fatalf("DeleteFeed failed: %v", err)
 	}
 
-	_, err = store.GetFeed(feed.ID)
+	_, err = store.GetFeed(context.Background(), feed.ID)
 	if err == nil {
 		t.Error("expected error getting deleted feed")
 	}
@@ -141,7 +144,7 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 
 	// Create a feed first
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -156,12 +159,12 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 	entry.Content = &content
 	entry.PublishedAt = &pubTime
 
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Get by ID
-	got, err := store.GetEntry(entry.ID)
+	got, err := store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry failed: %v", err)
 	}
@@ -186,7 +189,7 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 
 	// Get by prefix
 	prefix := entry.ID[:8]
-	got, err = store.GetEntryByPrefix(prefix)
+	got, err = store.GetEntryByPrefix(context.Background(), prefix)
 	if err != nil {
 		t.Fatalf("GetEntryByPrefix failed: %v", err)
 	}
@@ -195,7 +198,7 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 	}
 
 	// Entry exists
-	exists, err := store.EntryExists(feed.ID, "guid-123")
+	exists, err := store.EntryExists(context.Background(), feed.ID, "guid-123")
 	if err != nil {
 		t.Fatalf("EntryExists failed: %v", err)
 	}
@@ -203,7 +206,7 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 		t.Error("expected entry to exist")
 	}
 
-	exists, err = store.EntryExists(feed.ID, "nonexistent-guid")
+	exists, err = store.EntryExists(context.Background(), feed.ID, "nonexistent-guid")
 	if err != nil {
 		t.Fatalf("EntryExists for nonexistent failed: %v", err)
 	}
@@ -212,11 +215,11 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 	}
 
 	// Mark as read
-	if err := store.MarkEntryRead(entry.ID); err != nil {
+	if err := store.MarkEntryRead(context.Background(), entry.ID); err != nil {
 		t.Fatalf("MarkEntryRead failed: %v", err)
 	}
 
-	got, err = store.GetEntry(entry.ID)
+	got, err = store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry after mark read failed: %v", err)
 	}
@@ -228,11 +231,11 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 	}
 
 	// Mark as unread
-	if err := store.MarkEntryUnread(entry.ID); err != nil {
+	if err := store.MarkEntryUnread(context.Background(), entry.ID); err != nil {
 		t.Fatalf("MarkEntryUnread failed: %v", err)
 	}
 
-	got, err = store.GetEntry(entry.ID)
+	got, err = store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry after mark unread failed: %v", err)
 	}
@@ -248,11 +251,27 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 	entry.Title = &newEntryTitle
 	newContent := "Updated content"
 	entry.Content = &newContent
-	if err := store.UpdateEntry(entry); err != nil {
+	archiveURL := "https://web.archive.org/web/20240101000000/https://example.com/post"
+	entry.ArchiveURL = &archiveURL
+	claimed := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry.ClaimedPublishedAt = &claimed
+	image := "https://example.com/hero.jpg"
+	entry.ImageURL = &image
+	thread := "https://news.ycombinator.com/item?id=1"
+	entry.DiscussionURL = &thread
+	extensions := models.Extensions{
+		"itunes": {"duration": {{Name: "duration", Value: "42:00"}}},
+		"media": {"group": {{Name: "group", Children: map[string][]models.Extension{
+			"content": {{Name: "content", Attrs: map[string]string{"url": "https://example.com/a.mp4", "medium": "video"}}},
+		}}}},
+	}
+	entry.Extensions = extensions
+	entry.ContentHash = "0123456789abcdef"
+	if err := store.UpdateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("UpdateEntry failed: %v", err)
 	}
 
-	got, err = store.GetEntry(entry.ID)
+	got, err = store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry after update failed: %v", err)
 	}
@@ -262,13 +281,31 @@ func TestMarkdownEntryCRUD(t *testing.T) {
 	if got.Content == nil || *got.Content != newContent {
 		t.Errorf("Content mismatch: got %v, want %q", got.Content, newContent)
 	}
+	if got.ArchiveURL == nil || *got.ArchiveURL != archiveURL {
+		t.Errorf("ArchiveURL mismatch: got %v, want %q", got.ArchiveURL, archiveURL)
+	}
+	if got.ClaimedPublishedAt == nil || !got.ClaimedPublishedAt.Equal(claimed) {
+		t.Errorf("ClaimedPublishedAt mismatch: got %v, want %v", got.ClaimedPublishedAt, claimed)
+	}
+	if got.ImageURL == nil || *got.ImageURL != image {
+		t.Errorf("ImageURL mismatch: got %v, want %q", got.ImageURL, image)
+	}
+	if got.DiscussionURL == nil || *got.DiscussionURL != thread {
+		t.Errorf("DiscussionURL mismatch: got %v, want %q", got.DiscussionURL, thread)
+	}
+	if !reflect.DeepEqual(got.Extensions, extensions) {
+		t.Errorf("Extensions mismatch: got %+v, want %+v", got.Extensions, extensions)
+	}
+	if got.ContentHash != "0123456789abcdef" {
+		t.Errorf("ContentHash mismatch: got %q", got.ContentHash)
+	}
 
 	// Delete entry
-	if err := store.DeleteEntry(entry.ID); err != nil {
+	if err := store.DeleteEntry(context.Background(), entry.ID); err != nil {
 		t.Fatalf("DeleteEntry failed: %v", err)
 	}
 
-	_, err = store.GetEntry(entry.ID)
+	_, err = store.GetEntry(context.Background(), entry.ID)
 	if err == nil {
 		t.Error("expected error getting deleted entry")
 	}
@@ -280,7 +317,7 @@ func TestMarkdownListEntriesFilter(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -301,11 +338,11 @@ func TestMarkdownListEntriesFilter(t *testing.T) {
 	for _, e := range entries {
 		entry := models.NewEntry(feed.ID, e.guid, e.title)
 		entry.PublishedAt = &e.pub
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 		if e.read {
-			if err := store.MarkEntryRead(entry.ID); err != nil {
+			if err := store.MarkEntryRead(context.Background(), entry.ID); err != nil {
 				t.Fatalf("MarkEntryRead failed: %v", err)
 			}
 		}
@@ -314,7 +351,7 @@ func TestMarkdownListEntriesFilter(t *testing.T) {
 	// Test unread only filter
 	unreadOnly := true
 	filter := &EntryFilter{UnreadOnly: &unreadOnly}
-	result, err := store.ListEntries(filter)
+	result, err := store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries unread failed: %v", err)
 	}
@@ -325,7 +362,7 @@ func TestMarkdownListEntriesFilter(t *testing.T) {
 	// Test since filter
 	since := now.Add(-4 * time.Hour)
 	filter = &EntryFilter{Since: &since}
-	result, err = store.ListEntries(filter)
+	result, err = store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries since failed: %v", err)
 	}
@@ -336,7 +373,7 @@ func TestMarkdownListEntriesFilter(t *testing.T) {
 	// Test limit
 	limit := 2
 	filter = &EntryFilter{Limit: &limit}
-	result, err = store.ListEntries(filter)
+	result, err = store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries limit failed: %v", err)
 	}
@@ -347,7 +384,7 @@ func TestMarkdownListEntriesFilter(t *testing.T) {
 	// Test offset
 	offset := 1
 	filter = &EntryFilter{Limit: &limit, Offset: &offset}
-	result, err = store.ListEntries(filter)
+	result, err = store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries offset failed: %v", err)
 	}
@@ -357,7 +394,7 @@ func TestMarkdownListEntriesFilter(t *testing.T) {
 
 	// Test feed filter
 	filter = &EntryFilter{FeedID: &feed.ID}
-	result, err = store.ListEntries(filter)
+	result, err = store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries feed filter failed: %v", err)
 	}
@@ -372,7 +409,7 @@ func TestMarkdownListEntriesUntil(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -382,20 +419,20 @@ func TestMarkdownListEntriesUntil(t *testing.T) {
 
 	entry1 := models.NewEntry(feed.ID, "guid-1", "Old Article")
 	entry1.PublishedAt = &old
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry2 := models.NewEntry(feed.ID, "guid-2", "Recent Article")
 	entry2.PublishedAt = &recent
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Test until filter
 	cutoff := now.Add(-24 * time.Hour)
 	filter := &EntryFilter{Until: &cutoff}
-	result, err := store.ListEntries(filter)
+	result, err := store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries until failed: %v", err)
 	}
@@ -404,45 +441,63 @@ func TestMarkdownListEntriesUntil(t *testing.T) {
 	}
 }
 
+func TestMarkdownListEntriesFirstSeen(t *testing.T) {
+	store := newTestMarkdownStore(t)
+	defer store.Close()
+	checkFirstSeenListing(t, store)
+}
+
+func TestMarkdownListEntriesByScore(t *testing.T) {
+	store := newTestMarkdownStore(t)
+	defer store.Close()
+	checkScoreListing(t, store)
+}
+
+func TestMarkdownKeepUnread(t *testing.T) {
+	store := newTestMarkdownStore(t)
+	defer store.Close()
+	checkKeepUnread(t, store)
+}
+
 func TestMarkdownListEntriesMultipleFeedIDs(t *testing.T) {
 	store := newTestMarkdownStore(t)
 	defer store.Close()
 
 	// Create feeds
 	feed1 := models.NewFeed("https://example1.com/feed.xml")
-	if err := store.CreateFeed(feed1); err != nil {
+	if err := store.CreateFeed(context.Background(), feed1); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	feed2 := models.NewFeed("https://example2.com/feed.xml")
-	if err := store.CreateFeed(feed2); err != nil {
+	if err := store.CreateFeed(context.Background(), feed2); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	feed3 := models.NewFeed("https://example3.com/feed.xml")
-	if err := store.CreateFeed(feed3); err != nil {
+	if err := store.CreateFeed(context.Background(), feed3); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Create entries
 	entry1 := models.NewEntry(feed1.ID, "guid-1", "Article 1")
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry2 := models.NewEntry(feed2.ID, "guid-2", "Article 2")
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry3 := models.NewEntry(feed3.ID, "guid-3", "Article 3")
-	if err := store.CreateEntry(entry3); err != nil {
+	if err := store.CreateEntry(context.Background(), entry3); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Filter by multiple feed IDs
 	filter := &EntryFilter{FeedIDs: []string{feed1.ID, feed2.ID}}
-	result, err := store.ListEntries(filter)
+	result, err := store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries with FeedIDs failed: %v", err)
 	}
@@ -457,7 +512,7 @@ func TestMarkdownMarkEntriesReadBefore(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -467,14 +522,14 @@ func TestMarkdownMarkEntriesReadBefore(t *testing.T) {
 		entry := models.NewEntry(feed.ID, "guid-"+string(rune('0'+i)), "Article")
 		pub := now.Add(time.Duration(-i) * 24 * time.Hour)
 		entry.PublishedAt = &pub
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	// Mark entries older than 3 days as read
 	cutoff := now.Add(-2 * 24 * time.Hour)
-	count, err := store.MarkEntriesReadBefore(cutoff)
+	count, err := store.MarkEntriesReadBefore(context.Background(), cutoff)
 	if err != nil {
 		t.Fatalf("MarkEntriesReadBefore failed: %v", err)
 	}
@@ -483,7 +538,7 @@ func TestMarkdownMarkEntriesReadBefore(t *testing.T) {
 	}
 
 	// Verify unread count
-	unreadCount, err := store.CountUnreadEntries(nil)
+	unreadCount, err := store.CountUnreadEntries(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("CountUnreadEntries failed: %v", err)
 	}
@@ -498,7 +553,7 @@ func TestMarkdownFeedFetchState(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -506,11 +561,11 @@ func TestMarkdownFeedFetchState(t *testing.T) {
 	etag := "abc123"
 	lastMod := "Wed, 01 Jan 2025 00:00:00 GMT"
 	fetchedAt := time.Now()
-	if err := store.UpdateFeedFetchState(feed.ID, &etag, &lastMod, fetchedAt); err != nil {
+	if err := store.UpdateFeedFetchState(context.Background(), feed.ID, &etag, &lastMod, fetchedAt); err != nil {
 		t.Fatalf("UpdateFeedFetchState failed: %v", err)
 	}
 
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -523,11 +578,11 @@ func TestMarkdownFeedFetchState(t *testing.T) {
 
 	// Update with error
 	errMsg := "connection timeout"
-	if err := store.UpdateFeedError(feed.ID, errMsg); err != nil {
+	if err := store.UpdateFeedError(context.Background(), feed.ID, errMsg); err != nil {
 		t.Fatalf("UpdateFeedError failed: %v", err)
 	}
 
-	got, err = store.GetFeed(feed.ID)
+	got, err = store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -539,11 +594,11 @@ func TestMarkdownFeedFetchState(t *testing.T) {
 	}
 
 	// Update with another error
-	if err := store.UpdateFeedError(feed.ID, "another error"); err != nil {
+	if err := store.UpdateFeedError(context.Background(), feed.ID, "another error"); err != nil {
 		t.Fatalf("UpdateFeedError failed: %v", err)
 	}
 
-	got, err = store.GetFeed(feed.ID)
+	got, err = store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -558,19 +613,19 @@ func TestMarkdownCascadeDelete(t *testing.T) {
 
 	// Create feed with entries
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	for i := 0; i < 5; i++ {
 		entry := models.NewEntry(feed.ID, "guid-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	// Verify entries exist
-	entries, err := store.ListEntries(&EntryFilter{FeedID: &feed.ID})
+	entries, err := store.ListEntries(context.Background(), &EntryFilter{FeedID: &feed.ID})
 	if err != nil {
 		t.Fatalf("ListEntries failed: %v", err)
 	}
@@ -579,12 +634,12 @@ func TestMarkdownCascadeDelete(t *testing.T) {
 	}
 
 	// Delete feed
-	if err := store.DeleteFeed(feed.ID); err != nil {
+	if err := store.DeleteFeed(context.Background(), feed.ID); err != nil {
 		t.Fatalf("DeleteFeed failed: %v", err)
 	}
 
 	// Verify entries are gone
-	entries, err = store.ListEntries(nil)
+	entries, err = store.ListEntries(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("ListEntries after delete failed: %v", err)
 	}
@@ -593,6 +648,47 @@ func TestMarkdownCascadeDelete(t *testing.T) {
 	}
 }
 
+func TestMarkdownMoveEntry(t *testing.T) {
+	store := newTestMarkdownStore(t)
+	defer store.Close()
+
+	src := models.NewFeed("https://old.example.com/feed.xml")
+	dst := models.NewFeed("https://new.example.com/feed.xml")
+	for _, feed := range []*models.Feed{src, dst} {
+		if err := store.CreateFeed(context.Background(), feed); err != nil {
+			t.Fatalf("CreateFeed failed: %v", err)
+		}
+	}
+	entry := models.NewEntry(src.ID, "guid-move", "Moving")
+	entry.MarkRead()
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	if err := store.MoveEntry(context.Background(), entry.ID, dst.ID); err != nil {
+		t.Fatalf("MoveEntry failed: %v", err)
+	}
+
+	got, err := store.GetEntry(context.Background(), entry.ID)
+	if err != nil {
+		t.Fatalf("GetEntry after move failed: %v", err)
+	}
+	if got.FeedID != dst.ID || !got.Read {
+		t.Errorf("expected the entry under the new feed with read state kept, got feed %s read %v", got.FeedID, got.Read)
+	}
+	left, err := store.ListEntries(context.Background(), &EntryFilter{FeedID: &src.ID})
+	if err != nil {
+		t.Fatalf("ListEntries failed: %v", err)
+	}
+	if len(left) != 0 {
+		t.Errorf("expected no entries left in the source feed, got %d", len(left))
+	}
+
+	if err := store.MoveEntry(context.Background(), "missing", dst.ID); err == nil {
+		t.Error("expected error moving a missing entry")
+	}
+}
+
 func TestMarkdownStats(t *testing.T) {
 	store := newTestMarkdownStore(t)
 	defer store.Close()
@@ -601,39 +697,39 @@ func TestMarkdownStats(t *testing.T) {
 	feed1 := models.NewFeed("https://example1.com/feed.xml")
 	title1 := "Feed 1"
 	feed1.Title = &title1
-	if err := store.CreateFeed(feed1); err != nil {
+	if err := store.CreateFeed(context.Background(), feed1); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	feed2 := models.NewFeed("https://example2.com/feed.xml")
 	title2 := "Feed 2"
 	feed2.Title = &title2
-	if err := store.CreateFeed(feed2); err != nil {
+	if err := store.CreateFeed(context.Background(), feed2); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Create entries
 	for i := 0; i < 3; i++ {
 		entry := models.NewEntry(feed1.ID, "guid-1-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	for i := 0; i < 2; i++ {
 		entry := models.NewEntry(feed2.ID, "guid-2-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 		if i == 0 {
-			if err := store.MarkEntryRead(entry.ID); err != nil {
+			if err := store.MarkEntryRead(context.Background(), entry.ID); err != nil {
 				t.Fatalf("MarkEntryRead failed: %v", err)
 			}
 		}
 	}
 
 	// Check overall stats
-	overall, err := store.GetOverallStats()
+	overall, err := store.GetOverallStats(context.Background())
 	if err != nil {
 		t.Fatalf("GetOverallStats failed: %v", err)
 	}
@@ -648,13 +744,18 @@ func TestMarkdownStats(t *testing.T) {
 	}
 
 	// Check feed stats
-	feedStats, err := store.GetFeedStats()
+	feedStats, err := store.GetFeedStats(context.Background())
 	if err != nil {
 		t.Fatalf("GetFeedStats failed: %v", err)
 	}
 	if len(feedStats) != 2 {
 		t.Errorf("expected 2 feed stats, got %d", len(feedStats))
 	}
+	for _, row := range feedStats {
+		if row.LastEntryAt == nil {
+			t.Errorf("expected LastEntryAt for %s", row.FeedURL)
+		}
+	}
 }
 
 func TestMarkdownSearch(t *testing.T) {
@@ -663,7 +764,7 @@ func TestMarkdownSearch(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -671,26 +772,26 @@ func TestMarkdownSearch(t *testing.T) {
 	entry1 := models.NewEntry(feed.ID, "guid-1", "Golang Tutorial")
 	content1 := "Learn how to build web applications with Go"
 	entry1.Content = &content1
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry2 := models.NewEntry(feed.ID, "guid-2", "Python Basics")
 	content2 := "Introduction to Python programming"
 	entry2.Content = &content2
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry3 := models.NewEntry(feed.ID, "guid-3", "Web Development")
 	content3 := "Building modern web apps with golang"
 	entry3.Content = &content3
-	if err := store.CreateEntry(entry3); err != nil {
+	if err := store.CreateEntry(context.Background(), entry3); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Search for "golang" (case insensitive)
-	results, err := store.Search("golang", 10)
+	results, err := store.Search(context.Background(), "golang", 10)
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -699,7 +800,7 @@ func TestMarkdownSearch(t *testing.T) {
 	}
 
 	// Search for "python"
-	results, err = store.Search("python", 10)
+	results, err = store.Search(context.Background(), "python", 10)
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -708,7 +809,7 @@ func TestMarkdownSearch(t *testing.T) {
 	}
 
 	// Search for title match (case insensitive)
-	results, err = store.Search("GOLANG", 10)
+	results, err = store.Search(context.Background(), "GOLANG", 10)
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -722,7 +823,7 @@ func TestMarkdownCompact(t *testing.T) {
 	defer store.Close()
 
 	// Should be a no-op but not error
-	if err := store.Compact(); err != nil {
+	if err := store.Compact(context.Background()); err != nil {
 		t.Fatalf("Compact failed: %v", err)
 	}
 }
@@ -733,12 +834,12 @@ func TestMarkdownGetByIDOrPrefix(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Test GetFeedByURLOrPrefix with URL
-	got, err := store.GetFeedByURLOrPrefix("https://example.com/feed.xml")
+	got, err := store.GetFeedByURLOrPrefix(context.Background(), "https://example.com/feed.xml")
 	if err != nil {
 		t.Fatalf("GetFeedByURLOrPrefix with URL failed: %v", err)
 	}
@@ -747,7 +848,7 @@ func TestMarkdownGetByIDOrPrefix(t *testing.T) {
 	}
 
 	// Test GetFeedByURLOrPrefix with prefix
-	got, err = store.GetFeedByURLOrPrefix(feed.ID[:8])
+	got, err = store.GetFeedByURLOrPrefix(context.Background(), feed.ID[:8])
 	if err != nil {
 		t.Fatalf("GetFeedByURLOrPrefix with prefix failed: %v", err)
 	}
@@ -757,12 +858,12 @@ func TestMarkdownGetByIDOrPrefix(t *testing.T) {
 
 	// Create entry
 	entry := models.NewEntry(feed.ID, "guid-1", "Test")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Test GetEntryByIDOrPrefix with full ID
-	gotEntry, err := store.GetEntryByIDOrPrefix(entry.ID)
+	gotEntry, err := store.GetEntryByIDOrPrefix(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntryByIDOrPrefix with ID failed: %v", err)
 	}
@@ -771,7 +872,7 @@ func TestMarkdownGetByIDOrPrefix(t *testing.T) {
 	}
 
 	// Test GetEntryByIDOrPrefix with prefix
-	gotEntry, err = store.GetEntryByIDOrPrefix(entry.ID[:8])
+	gotEntry, err = store.GetEntryByIDOrPrefix(context.Background(), entry.ID[:8])
 	if err != nil {
 		t.Fatalf("GetEntryByIDOrPrefix with prefix failed: %v", err)
 	}
@@ -784,12 +885,12 @@ func TestMarkdownPrefixTooShort(t *testing.T) {
 	store := newTestMarkdownStore(t)
 	defer store.Close()
 
-	_, err := store.GetFeedByPrefix("abc")
+	_, err := store.GetFeedByPrefix(context.Background(), "abc")
 	if err == nil {
 		t.Error("expected error for prefix too short")
 	}
 
-	_, err = store.GetEntryByPrefix("abc")
+	_, err = store.GetEntryByPrefix(context.Background(), "abc")
 	if err == nil {
 		t.Error("expected error for prefix too short")
 	}
@@ -801,32 +902,32 @@ func TestMarkdownCountUnreadEntriesByFeed(t *testing.T) {
 
 	// Create feeds
 	feed1 := models.NewFeed("https://example1.com/feed.xml")
-	if err := store.CreateFeed(feed1); err != nil {
+	if err := store.CreateFeed(context.Background(), feed1); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	feed2 := models.NewFeed("https://example2.com/feed.xml")
-	if err := store.CreateFeed(feed2); err != nil {
+	if err := store.CreateFeed(context.Background(), feed2); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Create entries
 	for i := 0; i < 3; i++ {
 		entry := models.NewEntry(feed1.ID, "guid-1-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	for i := 0; i < 2; i++ {
 		entry := models.NewEntry(feed2.ID, "guid-2-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	// Count unread for specific feed
-	count, err := store.CountUnreadEntries(&feed1.ID)
+	count, err := store.CountUnreadEntries(context.Background(), &feed1.ID)
 	if err != nil {
 		t.Fatalf("CountUnreadEntries failed: %v", err)
 	}
@@ -834,13 +935,25 @@ func TestMarkdownCountUnreadEntriesByFeed(t *testing.T) {
 		t.Errorf("expected 3 unread for feed1, got %d", count)
 	}
 
-	count, err = store.CountUnreadEntries(&feed2.ID)
+	count, err = store.CountUnreadEntries(context.Background(), &feed2.ID)
 	if err != nil {
 		t.Fatalf("CountUnreadEntries failed: %v", err)
 	}
 	if count != 2 {
 		t.Errorf("expected 2 unread for feed2, got %d", count)
 	}
+
+	// CountEntries counts read entries too
+	entries, _ := store.ListEntries(context.Background(), &EntryFilter{FeedID: &feed1.ID})
+	if err := store.MarkEntryRead(context.Background(), entries[0].ID); err != nil {
+		t.Fatalf("MarkEntryRead failed: %v", err)
+	}
+	if count, err := store.CountEntries(context.Background(), &feed1.ID); err != nil || count != 3 {
+		t.Errorf("CountEntries(feed1) = %d, %v; want 3", count, err)
+	}
+	if count, err := store.CountEntries(context.Background(), nil); err != nil || count != 5 {
+		t.Errorf("CountEntries(nil) = %d, %v; want 5", count, err)
+	}
 }
 
 func TestMarkdown_FeedLocalNetwork(t *testing.T) {
@@ -850,11 +963,11 @@ func TestMarkdown_FeedLocalNetwork(t *testing.T) {
 	feed := NewFeed("http://192.168.1.50:8080/feed.xml")
 	feed.LocalNetwork = true
 
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("create feed: %v", err)
 	}
 
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("get feed: %v", err)
 	}
@@ -870,11 +983,11 @@ func TestMarkdown_FeedLocalNetworkDefaultFalse(t *testing.T) {
 
 	feed := NewFeed("https://example.com/feed.xml")
 
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("create feed: %v", err)
 	}
 
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("get feed: %v", err)
 	}
@@ -889,12 +1002,12 @@ func TestMarkdownDuplicateFeedURL(t *testing.T) {
 	defer store.Close()
 
 	feed1 := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed1); err != nil {
+	if err := store.CreateFeed(context.Background(), feed1); err != nil {
 		t.Fatalf("First CreateFeed failed: %v", err)
 	}
 
 	feed2 := models.NewFeed("https://example.com/feed.xml")
-	err := store.CreateFeed(feed2)
+	err := store.CreateFeed(context.Background(), feed2)
 	if err == nil {
 		t.Error("expected error creating feed with duplicate URL")
 	}
@@ -907,7 +1020,7 @@ func TestMarkdownFeedDirectoryCreated(t *testing.T) {
 	feed := models.NewFeed("https://example.com/feed.xml")
 	title := "My Test Feed"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -925,7 +1038,7 @@ func TestMarkdownFeedDirectoryRemovedOnDelete(t *testing.T) {
 	feed := models.NewFeed("https://example.com/feed.xml")
 	title := "Delete Me"
 	feed.Title = &title
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -935,7 +1048,7 @@ func TestMarkdownFeedDirectoryRemovedOnDelete(t *testing.T) {
 		t.Fatal("feed directory should exist")
 	}
 
-	if err := store.DeleteFeed(feed.ID); err != nil {
+	if err := store.DeleteFeed(context.Background(), feed.ID); err != nil {
 		t.Fatalf("DeleteFeed failed: %v", err)
 	}
 
@@ -950,7 +1063,7 @@ func TestMarkdownEntryWithMultilineContent(t *testing.T) {
 	defer store.Close()
 
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -958,11 +1071,11 @@ func TestMarkdownEntryWithMultilineContent(t *testing.T) {
 	content := "# Heading\n\nSome paragraph.\n\n```go\nfunc main() {\n\tprintln(\"hello\")\n}\n```\n\n- list item 1\n- list item 2\n\n> Blockquote here"
 	entry := models.NewEntry(feed.ID, "guid-1", "Markdown Content")
 	entry.Content = &content
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
-	got, err := store.GetEntry(entry.ID)
+	got, err := store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry failed: %v", err)
 	}
@@ -976,7 +1089,7 @@ func TestMarkdownEntryWithYAMLLikeContent(t *testing.T) {
 	defer store.Close()
 
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -984,11 +1097,11 @@ func TestMarkdownEntryWithYAMLLikeContent(t *testing.T) {
 	content := "---\ntitle: Something\nauthor: Someone\n---\nActual content here."
 	entry := models.NewEntry(feed.ID, "guid-1", "YAML-like content")
 	entry.Content = &content
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
-	got, err := store.GetEntry(entry.ID)
+	got, err := store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry failed: %v", err)
 	}
@@ -1003,11 +1116,11 @@ func TestMarkdownFeedNoTitle(t *testing.T) {
 
 	// Feed without title should use URL hostname for directory name
 	feed := models.NewFeed("https://blog.example.com/rss")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -1048,7 +1161,7 @@ func TestMarkdownDeleteNonexistentFeed(t *testing.T) {
 	store := newTestMarkdownStore(t)
 	defer store.Close()
 
-	err := store.DeleteFeed("nonexistent-id")
+	err := store.DeleteFeed(context.Background(), "nonexistent-id")
 	if err == nil {
 		t.Error("expected error deleting nonexistent feed")
 	}
@@ -1058,18 +1171,41 @@ func TestMarkdownDeleteNonexistentEntry(t *testing.T) {
 	store := newTestMarkdownStore(t)
 	defer store.Close()
 
-	err := store.DeleteEntry("nonexistent-id")
+	err := store.DeleteEntry(context.Background(), "nonexistent-id")
 	if err == nil {
 		t.Error("expected error deleting nonexistent entry")
 	}
 }
 
+func TestMarkdownCancelledContext(t *testing.T) {
+	store := newTestMarkdownStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := store.ListFeeds(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListFeeds with cancelled context: expected context.Canceled, got %v", err)
+	}
+	if _, err := store.ListEntries(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListEntries with cancelled context: expected context.Canceled, got %v", err)
+	}
+	if err := store.CreateFeed(ctx, models.NewFeed("https://example.com/other.xml")); !errors.Is(err, context.Canceled) {
+		t.Errorf("CreateFeed with cancelled context: expected context.Canceled, got %v", err)
+	}
+}
+
 func TestMarkdownUpdateNonexistentFeed(t *testing.T) {
 	store := newTestMarkdownStore(t)
 	defer store.Close()
 
 	feed := models.NewFeed("https://example.com/feed.xml")
-	err := store.UpdateFeed(feed)
+	err := store.UpdateFeed(context.Background(), feed)
 	if err == nil {
 		t.Error("expected error updating nonexistent feed")
 	}
@@ -1080,7 +1216,7 @@ func TestMarkdownSearchLimit(t *testing.T) {
 	defer store.Close()
 
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -1089,13 +1225,13 @@ func TestMarkdownSearchLimit(t *testing.T) {
 		entry := models.NewEntry(feed.ID, "guid-"+string(rune('0'+i)), "Golang Article")
 		content := "Learn golang programming"
 		entry.Content = &content
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	// Search with limit
-	results, err := store.Search("golang", 3)
+	results, err := store.Search(context.Background(), "golang", 3)
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -1109,17 +1245,17 @@ func TestMarkdownEntryNilContent(t *testing.T) {
 	defer store.Close()
 
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Entry with no content
 	entry := models.NewEntry(feed.ID, "guid-1", "Title Only")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
-	got, err := store.GetEntry(entry.ID)
+	got, err := store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry failed: %v", err)
 	}
@@ -1127,3 +1263,71 @@ func TestMarkdownEntryNilContent(t *testing.T) {
 		t.Errorf("Title mismatch: got %v", got.Title)
 	}
 }
+
+func TestMarkdown_FeedSubscriptionSettings(t *testing.T) {
+	store := newTestMarkdownStore(t)
+	defer store.Close()
+
+	feed := NewFeed("https://example.com/private.xml")
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	user, pass := "reader", "hunter2"
+	archivedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	feed.Paused = true
+	feed.ArchivedAt = &archivedAt
+	feed.KeepActive = true
+	feed.Identity = models.IdentityLink
+	feed.SyncInterval = 90 * time.Minute
+	feed.MaxEntries = 25
+	feed.BackfillDays = 14
+	feed.BackfillLimit = 50
+	feed.Browser = true
+	feed.Monitor = true
+	pageText := "Plans\n\nBasic costs $10"
+	feed.PageText = &pageText
+	feed.AuthUsername = &user
+	feed.AuthPassword = &pass
+	if err := store.UpdateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("update feed: %v", err)
+	}
+
+	got, err := store.GetFeed(context.Background(), feed.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if !got.Paused {
+		t.Error("expected Paused=true after round-trip")
+	}
+	if got.ArchivedAt == nil || !got.ArchivedAt.Equal(archivedAt) {
+		t.Errorf("expected ArchivedAt=%v, got %v", archivedAt, got.ArchivedAt)
+	}
+	if !got.KeepActive {
+		t.Error("expected KeepActive=true after round-trip")
+	}
+	if got.Identity != models.IdentityLink {
+		t.Errorf("expected Identity=link, got %q", got.Identity)
+	}
+	if got.SyncInterval != 90*time.Minute {
+		t.Errorf("expected SyncInterval=90m, got %v", got.SyncInterval)
+	}
+	if got.MaxEntries != 25 {
+		t.Errorf("expected MaxEntries=25, got %d", got.MaxEntries)
+	}
+	if got.BackfillDays != 14 || got.BackfillLimit != 50 {
+		t.Errorf("expected backfill 14 days/50 entries, got %d/%d", got.BackfillDays, got.BackfillLimit)
+	}
+	if !got.Browser {
+		t.Error("expected Browser=true after round-trip")
+	}
+	if !got.Monitor || got.PageText == nil || *got.PageText != pageText {
+		t.Errorf("expected Monitor=true and PageText=%q after round-trip, got %v/%v", pageText, got.Monitor, got.PageText)
+	}
+	if got.AuthUsername == nil || *got.AuthUsername != user {
+		t.Errorf("expected AuthUsername=%q, got %v", user, got.AuthUsername)
+	}
+	if got.AuthPassword == nil || *got.AuthPassword != pass {
+		t.Errorf("expected AuthPassword to round-trip")
+	}
+}
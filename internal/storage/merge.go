@@ -0,0 +1,111 @@
+// ABOUTME: Merges one feed's entries into another within a single store
+// ABOUTME: Moves entries, folds duplicates by GUID or link while keeping read state, then deletes the source feed
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// MergeSummary holds counts from a feed merge.
+type MergeSummary struct {
+	Moved      int // Entries reassigned to the destination feed
+	Duplicates int // Source entries already present in the destination
+}
+
+// MergeFeeds moves every entry of feed srcID into feed dstID and deletes the
+// source feed. A source entry whose GUID or link is already in the destination
+// is dropped, but its read state and archive link carry over to the copy that
+// stays, so nothing read shows up as unread again.
+func MergeFeeds(ctx context.Context, s Store, srcID, dstID string) (*MergeSummary, error) {
+	if srcID == dstID {
+		return nil, fmt.Errorf("cannot merge a feed into itself")
+	}
+	if _, err := s.GetFeed(ctx, dstID); err != nil {
+		return nil, fmt.Errorf("get destination feed: %w", err)
+	}
+
+	srcEntries, err := s.ListEntries(ctx, &EntryFilter{FeedID: &srcID})
+	if err != nil {
+		return nil, fmt.Errorf("list source entries: %w", err)
+	}
+	dstEntries, err := s.ListEntries(ctx, &EntryFilter{FeedID: &dstID})
+	if err != nil {
+		return nil, fmt.Errorf("list destination entries: %w", err)
+	}
+	byGUID := make(map[string]*models.Entry, len(dstEntries))
+	byLink := make(map[string]*models.Entry, len(dstEntries))
+	for _, entry := range dstEntries {
+		byGUID[entry.GUID] = entry
+		if entry.Link != nil && *entry.Link != "" {
+			byLink[*entry.Link] = entry
+		}
+	}
+
+	summary := &MergeSummary{}
+	for _, entry := range srcEntries {
+		match := byGUID[entry.GUID]
+		if match == nil && entry.Link != nil && *entry.Link != "" {
+			match = byLink[*entry.Link]
+		}
+		if match == nil {
+			if err := s.MoveEntry(ctx, entry.ID, dstID); err != nil {
+				return summary, fmt.Errorf("move entry %s: %w", entry.ID, err)
+			}
+			summary.Moved++
+			continue
+		}
+
+		changed := false
+		if entry.KeepUnread && !match.KeepUnread {
+			match.KeepUnread, match.Read, match.ReadAt = true, false, nil
+			changed = true
+		}
+		if entry.Read && !match.Read && !match.KeepUnread {
+			match.Read = true
+			match.ReadAt = entry.ReadAt
+			changed = true
+		}
+		if match.ArchiveURL == nil && entry.ArchiveURL != nil {
+			match.ArchiveURL = entry.ArchiveURL
+			changed = true
+		}
+		if match.ImageURL == nil && entry.ImageURL != nil {
+			match.ImageURL = entry.ImageURL
+			changed = true
+		}
+		if match.DiscussionURL == nil && entry.DiscussionURL != nil {
+			match.DiscussionURL = entry.DiscussionURL
+			changed = true
+		}
+		if match.CommentsFeedURL == nil && entry.CommentsFeedURL != nil {
+			match.CommentsFeedURL = entry.CommentsFeedURL
+			changed = true
+		}
+		if match.Extensions == nil && entry.Extensions != nil {
+			match.Extensions = entry.Extensions
+			changed = true
+		}
+		if entry.ScoredAt != nil && (match.ScoredAt == nil || entry.ScoredAt.After(*match.ScoredAt)) {
+			match.Score, match.CommentCount, match.ScoredAt = entry.Score, entry.CommentCount, entry.ScoredAt
+			changed = true
+		}
+		if changed {
+			if err := s.UpdateEntry(ctx, match); err != nil {
+				return summary, fmt.Errorf("update entry %s: %w", match.ID, err)
+			}
+		}
+		if err := s.DeleteEntry(ctx, entry.ID); err != nil {
+			return summary, fmt.Errorf("delete duplicate entry %s: %w", entry.ID, err)
+		}
+		summary.Duplicates++
+	}
+
+	if err := s.DeleteFeed(ctx, srcID); err != nil {
+		return summary, fmt.Errorf("delete source feed: %w", err)
+	}
+	return summary, nil
+}
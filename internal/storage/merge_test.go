@@ -0,0 +1,89 @@
+// ABOUTME: Tests for merging one feed into another on both backends
+// ABOUTME: Verifies entries move, duplicates fold by GUID or link, and read state survives
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestMergeFeeds(t *testing.T) {
+	for name, newStore := range map[string]func(t *testing.T) Store{
+		"sqlite":   func(t *testing.T) Store { return newTestStore(t) },
+		"markdown": func(t *testing.T) Store { return newTestMarkdownStore(t) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+			ctx := context.Background()
+
+			src := models.NewFeed("http://example.com/feed/")
+			dst := models.NewFeed("https://example.com/feed")
+			mustNoErr(t, store.CreateFeed(ctx, src))
+			mustNoErr(t, store.CreateFeed(ctx, dst))
+
+			link := func(s string) *string { return &s }
+
+			// Only in the source: moves over with its read state
+			onlySrc := models.NewEntry(src.ID, "guid-only", "Only Old")
+			onlySrc.MarkRead()
+			mustNoErr(t, store.CreateEntry(ctx, onlySrc))
+
+			// Same GUID in both: read in the source, unread in the destination
+			sameGUIDSrc := models.NewEntry(src.ID, "guid-shared", "Shared")
+			sameGUIDSrc.MarkRead()
+			mustNoErr(t, store.CreateEntry(ctx, sameGUIDSrc))
+			sameGUIDDst := models.NewEntry(dst.ID, "guid-shared", "Shared")
+			mustNoErr(t, store.CreateEntry(ctx, sameGUIDDst))
+
+			// Different GUIDs but the same link
+			sameLinkSrc := models.NewEntry(src.ID, "http-guid", "Linked")
+			sameLinkSrc.Link = link("https://example.com/post")
+			archived := "https://web.archive.org/web/1/https://example.com/post"
+			sameLinkSrc.ArchiveURL = &archived
+			mustNoErr(t, store.CreateEntry(ctx, sameLinkSrc))
+			sameLinkDst := models.NewEntry(dst.ID, "https-guid", "Linked")
+			sameLinkDst.Link = link("https://example.com/post")
+			mustNoErr(t, store.CreateEntry(ctx, sameLinkDst))
+
+			summary, err := MergeFeeds(ctx, store, src.ID, dst.ID)
+			if err != nil {
+				t.Fatalf("MergeFeeds: %v", err)
+			}
+			if summary.Moved != 1 || summary.Duplicates != 2 {
+				t.Errorf("expected 1 moved and 2 duplicates, got %+v", summary)
+			}
+
+			if _, err := store.GetFeed(ctx, src.ID); err == nil {
+				t.Error("expected the source feed to be deleted")
+			}
+			entries, err := store.ListEntries(ctx, &EntryFilter{FeedID: &dst.ID})
+			if err != nil {
+				t.Fatalf("ListEntries: %v", err)
+			}
+			if len(entries) != 3 {
+				t.Fatalf("expected 3 entries in the destination, got %d", len(entries))
+			}
+
+			moved, err := store.GetEntry(ctx, onlySrc.ID)
+			if err != nil || !moved.Read || moved.FeedID != dst.ID {
+				t.Errorf("expected the source-only entry to move with its read state, got %+v (%v)", moved, err)
+			}
+			kept, err := store.GetEntry(ctx, sameGUIDDst.ID)
+			if err != nil || !kept.Read {
+				t.Errorf("expected the destination copy to pick up read state, got %+v (%v)", kept, err)
+			}
+			kept, err = store.GetEntry(ctx, sameLinkDst.ID)
+			if err != nil || kept.ArchiveURL == nil || *kept.ArchiveURL != archived {
+				t.Errorf("expected the destination copy to pick up the archive link, got %+v (%v)", kept, err)
+			}
+
+			if _, err := MergeFeeds(ctx, store, dst.ID, dst.ID); err == nil {
+				t.Error("expected error merging a feed into itself")
+			}
+		})
+	}
+}
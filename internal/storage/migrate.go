@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"os"
 )
@@ -18,22 +19,22 @@ type MigrateSummary struct {
 // It iterates through feeds and entries in order,
 // creating each entity in the destination. The destination should be empty
 // before calling this function.
-func MigrateData(src, dst Store) (*MigrateSummary, error) {
+func MigrateData(ctx context.Context, src, dst Store) (*MigrateSummary, error) {
 	summary := &MigrateSummary{}
 
 	// List all feeds
-	feeds, err := src.ListFeeds()
+	feeds, err := src.ListFeeds(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("list source feeds: %w", err)
 	}
 
 	for _, feed := range feeds {
-		if err := dst.CreateFeed(feed); err != nil {
+		if err := dst.CreateFeed(ctx, feed); err != nil {
 			return nil, fmt.Errorf("create feed %q: %w", feed.URL, err)
 		}
 		summary.Feeds++
 
-		if err := migrateFeedEntries(src, dst, feed.ID, summary); err != nil {
+		if err := migrateFeedEntries(ctx, src, dst, feed.ID, summary); err != nil {
 			return nil, err
 		}
 	}
@@ -42,14 +43,14 @@ func MigrateData(src, dst Store) (*MigrateSummary, error) {
 }
 
 // migrateFeedEntries copies all entries for a single feed.
-func migrateFeedEntries(src, dst Store, feedID string, summary *MigrateSummary) error {
-	entries, err := src.ListEntries(&EntryFilter{FeedID: &feedID})
+func migrateFeedEntries(ctx context.Context, src, dst Store, feedID string, summary *MigrateSummary) error {
+	entries, err := src.ListEntries(ctx, &EntryFilter{FeedID: &feedID})
 	if err != nil {
 		return fmt.Errorf("list entries for feed %s: %w", feedID, err)
 	}
 
 	for _, entry := range entries {
-		if err := dst.CreateEntry(entry); err != nil {
+		if err := dst.CreateEntry(ctx, entry); err != nil {
 			return fmt.Errorf("create entry %s in feed %s: %w", entry.ID, feedID, err)
 		}
 		summary.Entries++
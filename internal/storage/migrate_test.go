@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -28,8 +29,8 @@ func seedDigestTestData(t *testing.T, src Store) (feeds []*models.Feed, entries
 	feed2.Title = &title2
 	feed2.Folder = "Science"
 
-	mustNoErr(t, src.CreateFeed(feed1))
-	mustNoErr(t, src.CreateFeed(feed2))
+	mustNoErr(t, src.CreateFeed(context.Background(), feed1))
+	mustNoErr(t, src.CreateFeed(context.Background(), feed2))
 	feeds = append(feeds, feed1, feed2)
 
 	// Create entries in feed1
@@ -43,17 +44,17 @@ func seedDigestTestData(t *testing.T, src Store) (feeds []*models.Feed, entries
 	entry1.Author = &author1
 	entry1.Content = &content1
 	entry1.PublishedAt = &pub1
-	mustNoErr(t, src.CreateEntry(entry1))
+	mustNoErr(t, src.CreateEntry(context.Background(), entry1))
 
 	entry2 := models.NewEntry(feed1.ID, "guid-1-2", "Understanding Channels")
 	content2 := "Deep dive into Go channel patterns."
 	pub2 := now.Add(-2 * time.Hour)
 	entry2.Content = &content2
 	entry2.PublishedAt = &pub2
-	mustNoErr(t, src.CreateEntry(entry2))
-	mustNoErr(t, src.MarkEntryRead(entry2.ID))
+	mustNoErr(t, src.CreateEntry(context.Background(), entry2))
+	mustNoErr(t, src.MarkEntryRead(context.Background(), entry2.ID))
 	// Re-read to get the read state
-	entry2, _ = src.GetEntry(entry2.ID)
+	entry2, _ = src.GetEntry(context.Background(), entry2.ID)
 
 	// Create entries in feed2
 	entry3 := models.NewEntry(feed2.ID, "guid-2-1", "Dark Matter Discovery")
@@ -61,7 +62,7 @@ func seedDigestTestData(t *testing.T, src Store) (feeds []*models.Feed, entries
 	pub3 := now.Add(-3 * time.Hour)
 	entry3.Content = &content3
 	entry3.PublishedAt = &pub3
-	mustNoErr(t, src.CreateEntry(entry3))
+	mustNoErr(t, src.CreateEntry(context.Background(), entry3))
 
 	entries = append(entries, entry1, entry2, entry3)
 
@@ -81,7 +82,7 @@ func verifyMigratedDigestData(t *testing.T, dst Store, feeds []*models.Feed, ent
 
 	// Verify feeds
 	for _, orig := range feeds {
-		got, err := dst.GetFeed(orig.ID)
+		got, err := dst.GetFeed(context.Background(), orig.ID)
 		if err != nil {
 			t.Errorf("feed %s (%s) not found in destination: %v", orig.URL, orig.ID, err)
 			continue
@@ -102,7 +103,7 @@ func verifyMigratedDigestData(t *testing.T, dst Store, feeds []*models.Feed, ent
 
 	// Verify entries
 	for _, orig := range entries {
-		got, err := dst.GetEntry(orig.ID)
+		got, err := dst.GetEntry(context.Background(), orig.ID)
 		if err != nil {
 			t.Errorf("entry %s not found in destination: %v", orig.ID, err)
 			continue
@@ -151,7 +152,7 @@ func TestMigrateData_SqliteToMarkdown(t *testing.T) {
 	defer dst.Close()
 
 	// Run migration
-	summary, err := MigrateData(src, dst)
+	summary, err := MigrateData(context.Background(), src, dst)
 	if err != nil {
 		t.Fatalf("MigrateData failed: %v", err)
 	}
@@ -188,7 +189,7 @@ func TestMigrateData_MarkdownToSqlite(t *testing.T) {
 	defer dst.Close()
 
 	// Run migration
-	summary, err := MigrateData(src, dst)
+	summary, err := MigrateData(context.Background(), src, dst)
 	if err != nil {
 		t.Fatalf("MigrateData failed: %v", err)
 	}
@@ -222,7 +223,7 @@ func TestMigrateData_EmptySource(t *testing.T) {
 	}
 	defer dst.Close()
 
-	summary, err := MigrateData(src, dst)
+	summary, err := MigrateData(context.Background(), src, dst)
 	if err != nil {
 		t.Fatalf("MigrateData failed: %v", err)
 	}
@@ -251,7 +252,7 @@ func TestMigrateData_SqliteToSqlite(t *testing.T) {
 	}
 	defer dst.Close()
 
-	summary, err := MigrateData(src, dst)
+	summary, err := MigrateData(context.Background(), src, dst)
 	if err != nil {
 		t.Fatalf("MigrateData failed: %v", err)
 	}
@@ -282,7 +283,7 @@ func TestMigrateRoundTrip_SqliteToMarkdownToSqlite(t *testing.T) {
 	}
 	defer mdStore.Close()
 
-	summary1, err := MigrateData(original, mdStore)
+	summary1, err := MigrateData(context.Background(), original, mdStore)
 	if err != nil {
 		t.Fatalf("MigrateData (sqlite->markdown) failed: %v", err)
 	}
@@ -299,7 +300,7 @@ func TestMigrateRoundTrip_SqliteToMarkdownToSqlite(t *testing.T) {
 	}
 	defer final.Close()
 
-	summary2, err := MigrateData(mdStore, final)
+	summary2, err := MigrateData(context.Background(), mdStore, final)
 	if err != nil {
 		t.Fatalf("MigrateData (markdown->sqlite) failed: %v", err)
 	}
@@ -331,7 +332,7 @@ func TestMigrateRoundTrip_MarkdownToSqliteToMarkdown(t *testing.T) {
 	}
 	defer sqlStore.Close()
 
-	_, err = MigrateData(original, sqlStore)
+	_, err = MigrateData(context.Background(), original, sqlStore)
 	if err != nil {
 		t.Fatalf("MigrateData (markdown->sqlite) failed: %v", err)
 	}
@@ -344,7 +345,7 @@ func TestMigrateRoundTrip_MarkdownToSqliteToMarkdown(t *testing.T) {
 	}
 	defer final.Close()
 
-	_, err = MigrateData(sqlStore, final)
+	_, err = MigrateData(context.Background(), sqlStore, final)
 	if err != nil {
 		t.Fatalf("MigrateData (sqlite->markdown) failed: %v", err)
 	}
@@ -4,7 +4,9 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,9 +19,19 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+// feedColumns is the column list shared by every feed SELECT, in scanFeedInto order.
+const feedColumns = `id, url, title, folder, etag, last_modified, last_fetched_at, last_error, error_count, local_network,
+		paused, sync_interval, max_entries, auth_username, auth_password, created_at, archived_at, keep_active, identity,
+		backfill_days, backfill_limit, browser, monitor, page_text, priority`
+
 // SQLiteStore implements the Store interface using SQLite.
 type SQLiteStore struct {
-	db *sql.DB
+	db   *sql.DB
+	path string
+
+	// user is set on a ForUser view, whose read state comes from
+	// entry_states instead of the entries table.
+	user string
 }
 
 // NewSQLiteStore creates a new SQLite storage instance.
@@ -36,7 +48,13 @@ func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	store := &SQLiteStore{db: db}
+	return newSQLiteStoreFromDB(db, dbPath)
+}
+
+// newSQLiteStoreFromDB wraps an open database, creating and migrating the
+// schema. The database is closed on error.
+func newSQLiteStoreFromDB(db *sql.DB, dbPath string) (*SQLiteStore, error) {
+	store := &SQLiteStore{db: db, path: dbPath}
 
 	// Initialize schema
 	if err := store.initSchema(); err != nil {
@@ -67,7 +85,21 @@ func (s *SQLiteStore) initSchema() error {
 			last_error TEXT,
 			error_count INTEGER DEFAULT 0,
 			local_network INTEGER DEFAULT 0,
-			created_at TIMESTAMP NOT NULL
+			paused INTEGER DEFAULT 0,
+			sync_interval INTEGER DEFAULT 0,
+			max_entries INTEGER DEFAULT 0,
+			auth_username TEXT,
+			auth_password TEXT,
+			created_at TIMESTAMP NOT NULL,
+			archived_at TIMESTAMP,
+			keep_active INTEGER DEFAULT 0,
+			identity TEXT DEFAULT '',
+			backfill_days INTEGER DEFAULT 0,
+			backfill_limit INTEGER DEFAULT 0,
+			browser INTEGER DEFAULT 0,
+			monitor INTEGER DEFAULT 0,
+			page_text TEXT,
+			priority TEXT DEFAULT ''
 		);
 
 		CREATE INDEX IF NOT EXISTS idx_feeds_url ON feeds(url);
@@ -85,7 +117,20 @@ func (s *SQLiteStore) initSchema() error {
 			content TEXT,
 			read INTEGER DEFAULT 0,
 			read_at TIMESTAMP,
+			archive_url TEXT,
 			created_at TIMESTAMP NOT NULL,
+			claimed_published_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			image_url TEXT,
+			discussion_url TEXT,
+			comments_feed_url TEXT,
+			score INTEGER,
+			comment_count INTEGER,
+			scored_at TIMESTAMP,
+			keep_unread INTEGER DEFAULT 0,
+			extensions TEXT,
+			content_hash TEXT DEFAULT '',
+			change_seq INTEGER DEFAULT 0,
 			UNIQUE(feed_id, guid)
 		);
 
@@ -94,6 +139,26 @@ func (s *SQLiteStore) initSchema() error {
 		CREATE INDEX IF NOT EXISTS idx_entries_published_at ON entries(published_at);
 		CREATE INDEX IF NOT EXISTS idx_entries_id ON entries(id);
 
+		-- Per-user read state for shared (team) instances; see ForUser
+		CREATE TABLE IF NOT EXISTS entry_states (
+			user_id TEXT NOT NULL,
+			entry_id TEXT NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+			read INTEGER DEFAULT 0,
+			read_at TIMESTAMP,
+			keep_unread INTEGER DEFAULT 0,
+			PRIMARY KEY (user_id, entry_id)
+		);
+
+		-- Entries removed since they were created, for change feeds; see GetChangesSince
+		CREATE TABLE IF NOT EXISTS entry_deletions (
+			id TEXT PRIMARY KEY,
+			feed_id TEXT NOT NULL,
+			deleted_at TIMESTAMP NOT NULL,
+			change_seq INTEGER NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_entry_deletions_change_seq ON entry_deletions(change_seq, id);
+
 		-- FTS5 for content search
 		CREATE VIRTUAL TABLE IF NOT EXISTS entries_fts USING fts5(
 			title,
@@ -125,33 +190,163 @@ func (s *SQLiteStore) initSchema() error {
 	return err
 }
 
+// SchemaVersion is recorded in PRAGMA user_version once migrations have run.
+// Bump it whenever initSchema or the migration list changes.
+const SchemaVersion = 17
+
+// columnMigration is a column added to a table after the initial schema.
+type columnMigration struct {
+	name string
+	def  string
+}
+
+// feedColumnMigrations lists columns added to feeds after the initial schema.
+var feedColumnMigrations = []columnMigration{
+	{"local_network", "INTEGER DEFAULT 0"},
+	{"paused", "INTEGER DEFAULT 0"},
+	{"sync_interval", "INTEGER DEFAULT 0"},
+	{"max_entries", "INTEGER DEFAULT 0"},
+	{"auth_username", "TEXT"},
+	{"auth_password", "TEXT"},
+	{"archived_at", "TIMESTAMP"},
+	{"keep_active", "INTEGER DEFAULT 0"},
+	{"identity", "TEXT DEFAULT ''"},
+	{"backfill_days", "INTEGER DEFAULT 0"},
+	{"backfill_limit", "INTEGER DEFAULT 0"},
+	{"browser", "INTEGER DEFAULT 0"},
+	{"monitor", "INTEGER DEFAULT 0"},
+	{"page_text", "TEXT"},
+	{"priority", "TEXT DEFAULT ''"},
+}
+
+// entryColumnMigrations lists columns added to entries after the initial schema.
+var entryColumnMigrations = []columnMigration{
+	{"archive_url", "TEXT"},
+	{"claimed_published_at", "TIMESTAMP"},
+	{"updated_at", "TIMESTAMP"},
+	{"image_url", "TEXT"},
+	{"discussion_url", "TEXT"},
+	{"comments_feed_url", "TEXT"},
+	{"score", "INTEGER"},
+	{"comment_count", "INTEGER"},
+	{"scored_at", "TIMESTAMP"},
+	{"keep_unread", "INTEGER DEFAULT 0"},
+	{"extensions", "TEXT"},
+	{"content_hash", "TEXT DEFAULT ''"},
+	{"change_seq", "INTEGER DEFAULT 0"},
+}
+
+// nextChangeSeq numbers a write in the change order: one past every change
+// recorded so far. SQLite runs one write at a time and a statement reads the
+// latest data once it writes, so the numbers follow commit order and a
+// reader never sees a number lower than one it has already seen.
+const nextChangeSeq = `(SELECT MAX(seq) + 1 FROM (
+		SELECT COALESCE(MAX(change_seq), 0) AS seq FROM entries
+		UNION ALL SELECT COALESCE(MAX(change_seq), 0) FROM entry_deletions))`
+
+// changeTriggers record deleted entries, whether deleted on their own, with
+// their feed, or by doctor, and forget the deletion if the entry comes back.
+// The deleted row no longer counts towards nextChangeSeq, so a deletion is
+// numbered past it explicitly in case it was the latest change. They need
+// entries.change_seq, so they are created after the migrations.
+const changeTriggers = `
+	CREATE TRIGGER IF NOT EXISTS entries_deleted AFTER DELETE ON entries BEGIN
+		INSERT OR REPLACE INTO entry_deletions (id, feed_id, deleted_at, change_seq)
+		VALUES (old.id, old.feed_id, strftime('%Y-%m-%d %H:%M:%f', 'now'), max(old.change_seq + 1, ` + nextChangeSeq + `));
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS entries_undeleted AFTER INSERT ON entries BEGIN
+		DELETE FROM entry_deletions WHERE id = new.id;
+	END;
+`
+
 // migrate runs schema migrations for existing databases.
 func (s *SQLiteStore) migrate() error {
-	// Add local_network column if it doesn't exist (for databases created before local-network-feeds)
-	_, err := s.db.Exec("ALTER TABLE feeds ADD COLUMN local_network INTEGER DEFAULT 0")
-	if err != nil && !strings.Contains(err.Error(), "duplicate column") {
-		return fmt.Errorf("migrate feeds.local_network: %w", err)
+	// Add columns that don't exist yet (for databases created by older versions)
+	if err := s.addColumns("feeds", feedColumnMigrations); err != nil {
+		return err
+	}
+	if err := s.addColumns("entries", entryColumnMigrations); err != nil {
+		return err
+	}
+
+	// Entries from before change tracking sort first, as changed at the zero time
+	if _, err := s.db.Exec("UPDATE entries SET updated_at = ? WHERE updated_at IS NULL", time.Time{}); err != nil {
+		return fmt.Errorf("migrate entries.updated_at: %w", err)
+	}
+	// Changes are paged by sequence number now, not by time
+	if _, err := s.db.Exec("DROP INDEX IF EXISTS idx_entries_updated_at"); err != nil {
+		return fmt.Errorf("drop updated_at index: %w", err)
+	}
+	if _, err := s.db.Exec("CREATE INDEX IF NOT EXISTS idx_entries_change_seq ON entries(change_seq, id)"); err != nil {
+		return fmt.Errorf("create change_seq index: %w", err)
+	}
+	if _, err := s.db.Exec(changeTriggers); err != nil {
+		return fmt.Errorf("create change triggers: %w", err)
+	}
+
+	// Never lower the version: a newer digest may have migrated this database
+	version, err := s.schemaVersion(context.Background())
+	if err != nil {
+		return err
+	}
+	if version < SchemaVersion {
+		if _, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+			return fmt.Errorf("set schema version: %w", err)
+		}
 	}
 	return nil
 }
 
+// addColumns adds each column to table, skipping ones that already exist.
+func (s *SQLiteStore) addColumns(table string, cols []columnMigration) error {
+	for _, col := range cols {
+		_, err := s.db.Exec("ALTER TABLE " + table + " ADD COLUMN " + col.name + " " + col.def)
+		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			return fmt.Errorf("migrate %s.%s: %w", table, col.name, err)
+		}
+	}
+	return nil
+}
+
+// schemaVersion reads the schema version recorded in PRAGMA user_version.
+func (s *SQLiteStore) schemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, nil
+}
+
 // Close closes the database connection.
 func (s *SQLiteStore) Close() error {
+	if s.user != "" {
+		// A user view shares its parent's database
+		return nil
+	}
 	return s.db.Close()
 }
 
 // Feed Operations
 
 // CreateFeed stores a new feed.
-func (s *SQLiteStore) CreateFeed(feed *models.Feed) error {
+func (s *SQLiteStore) CreateFeed(ctx context.Context, feed *models.Feed) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
-		INSERT INTO feeds (id, url, title, folder, etag, last_modified, last_fetched_at, last_error, error_count, local_network, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO feeds (` + feedColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := s.db.Exec(query,
+	_, err := s.db.ExecContext(ctx, query,
 		feed.ID, feed.URL, feed.Title, feed.Folder,
 		feed.ETag, feed.LastModified, timeToSQL(feed.LastFetchedAt),
-		feed.LastError, feed.ErrorCount, boolToInt(feed.LocalNetwork), feed.CreatedAt,
+		feed.LastError, feed.ErrorCount, boolToInt(feed.LocalNetwork),
+		boolToInt(feed.Paused), int64(feed.SyncInterval/time.Second), feed.MaxEntries,
+		feed.AuthUsername, feed.AuthPassword, feed.CreatedAt,
+		timeToSQL(feed.ArchivedAt), boolToInt(feed.KeepActive), feed.Identity,
+		feed.BackfillDays, feed.BackfillLimit, boolToInt(feed.Browser),
+		boolToInt(feed.Monitor), feed.PageText, feed.Priority,
 	)
 	if err != nil {
 		return fmt.Errorf("insert feed: %w", err)
@@ -160,34 +355,43 @@ func (s *SQLiteStore) CreateFeed(feed *models.Feed) error {
 }
 
 // GetFeed retrieves a feed by ID.
-func (s *SQLiteStore) GetFeed(id string) (*models.Feed, error) {
+func (s *SQLiteStore) GetFeed(ctx context.Context, id string) (*models.Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
-		SELECT id, url, title, folder, etag, last_modified, last_fetched_at, last_error, error_count, local_network, created_at
+		SELECT ` + feedColumns + `
 		FROM feeds WHERE id = ?
 	`
-	return s.scanFeed(s.db.QueryRow(query, id))
+	return s.scanFeed(s.db.QueryRowContext(ctx, query, id))
 }
 
 // GetFeedByURL finds a feed by its URL.
-func (s *SQLiteStore) GetFeedByURL(url string) (*models.Feed, error) {
+func (s *SQLiteStore) GetFeedByURL(ctx context.Context, url string) (*models.Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
-		SELECT id, url, title, folder, etag, last_modified, last_fetched_at, last_error, error_count, local_network, created_at
+		SELECT ` + feedColumns + `
 		FROM feeds WHERE url = ?
 	`
-	return s.scanFeed(s.db.QueryRow(query, url))
+	return s.scanFeed(s.db.QueryRowContext(ctx, query, url))
 }
 
 // GetFeedByPrefix finds a feed by ID prefix (min 6 chars).
-func (s *SQLiteStore) GetFeedByPrefix(prefix string) (*models.Feed, error) {
+func (s *SQLiteStore) GetFeedByPrefix(ctx context.Context, prefix string) (*models.Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	if len(prefix) < 6 {
 		return nil, fmt.Errorf("prefix must be at least 6 characters")
 	}
 
 	query := `
-		SELECT id, url, title, folder, etag, last_modified, last_fetched_at, last_error, error_count, local_network, created_at
+		SELECT ` + feedColumns + `
 		FROM feeds WHERE id LIKE ?
 	`
-	rows, err := s.db.Query(query, prefix+"%")
+	rows, err := s.db.QueryContext(ctx, query, prefix+"%")
 	if err != nil {
 		return nil, fmt.Errorf("query feeds: %w", err)
 	}
@@ -212,12 +416,15 @@ func (s *SQLiteStore) GetFeedByPrefix(prefix string) (*models.Feed, error) {
 }
 
 // ListFeeds returns all feeds, sorted by creation date (newest first).
-func (s *SQLiteStore) ListFeeds() ([]*models.Feed, error) {
+func (s *SQLiteStore) ListFeeds(ctx context.Context) ([]*models.Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
-		SELECT id, url, title, folder, etag, last_modified, last_fetched_at, last_error, error_count, local_network, created_at
+		SELECT ` + feedColumns + `
 		FROM feeds ORDER BY created_at DESC
 	`
-	rows, err := s.db.Query(query)
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("query feeds: %w", err)
 	}
@@ -235,16 +442,27 @@ func (s *SQLiteStore) ListFeeds() ([]*models.Feed, error) {
 }
 
 // UpdateFeed updates an existing feed.
-func (s *SQLiteStore) UpdateFeed(feed *models.Feed) error {
+func (s *SQLiteStore) UpdateFeed(ctx context.Context, feed *models.Feed) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
 		UPDATE feeds SET
 			url = ?, title = ?, folder = ?, etag = ?, last_modified = ?,
-			last_fetched_at = ?, last_error = ?, error_count = ?, local_network = ?
+			last_fetched_at = ?, last_error = ?, error_count = ?, local_network = ?,
+			paused = ?, sync_interval = ?, max_entries = ?, auth_username = ?, auth_password = ?,
+			archived_at = ?, keep_active = ?, identity = ?, backfill_days = ?, backfill_limit = ?,
+			browser = ?, monitor = ?, page_text = ?, priority = ?
 		WHERE id = ?
 	`
-	result, err := s.db.Exec(query,
+	result, err := s.db.ExecContext(ctx, query,
 		feed.URL, feed.Title, feed.Folder, feed.ETag, feed.LastModified,
 		timeToSQL(feed.LastFetchedAt), feed.LastError, feed.ErrorCount, boolToInt(feed.LocalNetwork),
+		boolToInt(feed.Paused), int64(feed.SyncInterval/time.Second), feed.MaxEntries,
+		feed.AuthUsername, feed.AuthPassword,
+		timeToSQL(feed.ArchivedAt), boolToInt(feed.KeepActive), feed.Identity,
+		feed.BackfillDays, feed.BackfillLimit, boolToInt(feed.Browser),
+		boolToInt(feed.Monitor), feed.PageText, feed.Priority,
 		feed.ID,
 	)
 	if err != nil {
@@ -258,8 +476,11 @@ func (s *SQLiteStore) UpdateFeed(feed *models.Feed) error {
 }
 
 // DeleteFeed removes a feed and all its entries (cascade).
-func (s *SQLiteStore) DeleteFeed(id string) error {
-	result, err := s.db.Exec("DELETE FROM feeds WHERE id = ?", id)
+func (s *SQLiteStore) DeleteFeed(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM feeds WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete feed: %w", err)
 	}
@@ -271,14 +492,17 @@ func (s *SQLiteStore) DeleteFeed(id string) error {
 }
 
 // UpdateFeedFetchState updates feed caching headers and clears errors.
-func (s *SQLiteStore) UpdateFeedFetchState(feedID string, etag, lastModified *string, fetchedAt time.Time) error {
+func (s *SQLiteStore) UpdateFeedFetchState(ctx context.Context, feedID string, etag, lastModified *string, fetchedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
 		UPDATE feeds SET
 			etag = ?, last_modified = ?, last_fetched_at = ?,
 			last_error = NULL, error_count = 0
 		WHERE id = ?
 	`
-	result, err := s.db.Exec(query, etag, lastModified, fetchedAt, feedID)
+	result, err := s.db.ExecContext(ctx, query, etag, lastModified, fetchedAt, feedID)
 	if err != nil {
 		return fmt.Errorf("update feed fetch state: %w", err)
 	}
@@ -290,9 +514,12 @@ func (s *SQLiteStore) UpdateFeedFetchState(feedID string, etag, lastModified *st
 }
 
 // UpdateFeedError records a fetch error for a feed.
-func (s *SQLiteStore) UpdateFeedError(feedID string, errMsg string) error {
+func (s *SQLiteStore) UpdateFeedError(ctx context.Context, feedID string, errMsg string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `UPDATE feeds SET last_error = ?, error_count = error_count + 1 WHERE id = ?`
-	result, err := s.db.Exec(query, errMsg, feedID)
+	result, err := s.db.ExecContext(ctx, query, errMsg, feedID)
 	if err != nil {
 		return fmt.Errorf("update feed error: %w", err)
 	}
@@ -306,15 +533,26 @@ func (s *SQLiteStore) UpdateFeedError(feedID string, errMsg string) error {
 // Entry Operations
 
 // CreateEntry stores a new entry.
-func (s *SQLiteStore) CreateEntry(entry *models.Entry) error {
+func (s *SQLiteStore) CreateEntry(ctx context.Context, entry *models.Entry) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
-		INSERT INTO entries (id, feed_id, guid, title, link, author, published_at, content, read, read_at, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO entries (id, feed_id, guid, title, link, author, published_at, content, read, read_at, archive_url, created_at, claimed_published_at, updated_at, image_url, discussion_url, comments_feed_url, score, comment_count, scored_at, keep_unread, extensions, content_hash, change_seq)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ` + nextChangeSeq + `)
 	`
-	_, err := s.db.Exec(query,
+	extensions, err := extensionsToSQL(entry.Extensions)
+	if err != nil {
+		return err
+	}
+	entry.UpdatedAt = changeTime()
+	_, err = s.db.ExecContext(ctx, query,
 		entry.ID, entry.FeedID, entry.GUID, entry.Title, entry.Link, entry.Author,
 		timeToSQL(entry.PublishedAt), entry.Content, boolToInt(entry.Read),
-		timeToSQL(entry.ReadAt), entry.CreatedAt,
+		timeToSQL(entry.ReadAt), entry.ArchiveURL, entry.CreatedAt,
+		timeToSQL(entry.ClaimedPublishedAt), entry.UpdatedAt, entry.ImageURL,
+		entry.DiscussionURL, entry.CommentsFeedURL, entry.Score, entry.CommentCount,
+		timeToSQL(entry.ScoredAt), boolToInt(entry.KeepUnread), extensions, entry.ContentHash,
 	)
 	if err != nil {
 		return fmt.Errorf("insert entry: %w", err)
@@ -323,25 +561,31 @@ func (s *SQLiteStore) CreateEntry(entry *models.Entry) error {
 }
 
 // GetEntry retrieves an entry by ID.
-func (s *SQLiteStore) GetEntry(id string) (*models.Entry, error) {
+func (s *SQLiteStore) GetEntry(ctx context.Context, id string) (*models.Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
-		SELECT id, feed_id, guid, title, link, author, published_at, content, read, read_at, created_at
-		FROM entries WHERE id = ?
+		SELECT id, feed_id, guid, title, link, author, published_at, content, read, read_at, archive_url, created_at, claimed_published_at, updated_at, image_url, discussion_url, comments_feed_url, score, comment_count, scored_at, keep_unread, extensions, content_hash
+		FROM ` + s.entryTable("entries") + ` WHERE id = ?
 	`
-	return s.scanEntry(s.db.QueryRow(query, id))
+	return s.scanEntry(s.db.QueryRowContext(ctx, query, id))
 }
 
 // GetEntryByPrefix finds an entry by ID prefix (min 6 chars).
-func (s *SQLiteStore) GetEntryByPrefix(prefix string) (*models.Entry, error) {
+func (s *SQLiteStore) GetEntryByPrefix(ctx context.Context, prefix string) (*models.Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	if len(prefix) < 6 {
 		return nil, fmt.Errorf("prefix must be at least 6 characters")
 	}
 
 	query := `
-		SELECT id, feed_id, guid, title, link, author, published_at, content, read, read_at, created_at
-		FROM entries WHERE id LIKE ?
+		SELECT id, feed_id, guid, title, link, author, published_at, content, read, read_at, archive_url, created_at, claimed_published_at, updated_at, image_url, discussion_url, comments_feed_url, score, comment_count, scored_at, keep_unread, extensions, content_hash
+		FROM ` + s.entryTable("entries") + ` WHERE id LIKE ?
 	`
-	rows, err := s.db.Query(query, prefix+"%")
+	rows, err := s.db.QueryContext(ctx, query, prefix+"%")
 	if err != nil {
 		return nil, fmt.Errorf("query entries: %w", err)
 	}
@@ -365,16 +609,138 @@ func (s *SQLiteStore) GetEntryByPrefix(prefix string) (*models.Entry, error) {
 	return matches[0], nil
 }
 
+// entryColumns are the columns scanEntry and scanEntryFromRows read, with
+// the content left NULL when noContent is set.
+func entryColumns(noContent bool) string {
+	content := "content"
+	if noContent {
+		content = "NULL"
+	}
+	return "id, feed_id, guid, title, link, author, published_at, " + content + ", read, read_at, archive_url, created_at, claimed_published_at, updated_at, image_url, discussion_url, comments_feed_url, score, comment_count, scored_at, keep_unread, extensions, content_hash"
+}
+
+// eachEntryBatch is how many entries EachEntry loads per query.
+const eachEntryBatch = 500
+
 // ListEntries returns entries matching the filter, sorted by published date.
-func (s *SQLiteStore) ListEntries(filter *EntryFilter) ([]*models.Entry, error) {
+func (s *SQLiteStore) ListEntries(ctx context.Context, filter *EntryFilter) ([]*models.Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	noContent := filter != nil && filter.NoContent
+	query, args := s.listEntriesQuery(entryColumns(noContent), filter)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		entry, err := s.scanEntryFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// EachEntry calls fn with each entry ListEntries would return. It reads
+// the matching IDs first, then loads the entries a batch at a time, so no
+// query is open while fn runs; entries deleted meanwhile are skipped.
+func (s *SQLiteStore) EachEntry(ctx context.Context, filter *EntryFilter, fn func(*models.Entry) error) error {
+	ids, err := s.listEntryIDs(ctx, filter)
+	if err != nil {
+		return err
+	}
+	noContent := filter != nil && filter.NoContent
+	for len(ids) > 0 {
+		batch := ids[:min(eachEntryBatch, len(ids))]
+		ids = ids[len(batch):]
+		entries, err := s.entriesByID(ctx, batch, noContent)
+		if err != nil {
+			return err
+		}
+		for _, id := range batch {
+			if entry, ok := entries[id]; ok {
+				if err := fn(entry); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// listEntryIDs returns the IDs of the entries ListEntries would return, in
+// its order.
+func (s *SQLiteStore) listEntryIDs(ctx context.Context, filter *EntryFilter) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	query, args := s.listEntriesQuery("id", filter)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query entries: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan entry id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// entriesByID loads the entries with the given IDs, keyed by ID.
+func (s *SQLiteStore) entriesByID(ctx context.Context, ids []string, noContent bool) (map[string]*models.Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := "SELECT " + entryColumns(noContent) + " FROM " + s.entryTable("entries") +
+		" WHERE id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + ")"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make(map[string]*models.Entry, len(ids))
+	for rows.Next() {
+		entry, err := s.scanEntryFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries[entry.ID] = entry
+	}
+	return entries, rows.Err()
+}
+
+// listEntriesQuery builds the query selecting columns of the entries
+// matching filter, in ListEntries order.
+func (s *SQLiteStore) listEntriesQuery(columns string, filter *EntryFilter) (string, []interface{}) {
 	query := `
-		SELECT id, feed_id, guid, title, link, author, published_at, content, read, read_at, created_at
-		FROM entries
+		SELECT ` + columns + `
+		FROM ` + s.entryTable("entries") + `
 	`
 
 	var conditions []string
 	var args []interface{}
 
+	dateColumn := "published_at"
+	if filter != nil && filter.FirstSeen {
+		dateColumn = "created_at"
+	}
+
 	if filter != nil {
 		// FeedIDs takes precedence over FeedID
 		if len(filter.FeedIDs) > 0 {
@@ -394,21 +760,44 @@ func (s *SQLiteStore) ListEntries(filter *EntryFilter) ([]*models.Entry, error)
 		}
 
 		if filter.Since != nil {
-			conditions = append(conditions, "published_at >= ?")
+			conditions = append(conditions, dateColumn+" >= ?")
 			args = append(args, *filter.Since)
 		}
 
 		if filter.Until != nil {
-			conditions = append(conditions, "published_at < ?")
+			conditions = append(conditions, dateColumn+" < ?")
 			args = append(args, *filter.Until)
 		}
+
+		if filter.LatestSync {
+			// Entries stored by a sync share its fetch time
+			conditions = append(conditions, "created_at >= (SELECT last_fetched_at FROM feeds WHERE feeds.id = entries.feed_id)")
+		}
+
+		if filter.MinScore != nil {
+			conditions = append(conditions, "score >= ?")
+			args = append(args, *filter.MinScore)
+		}
+
+		if filter.KeptOnly {
+			conditions = append(conditions, "keep_unread = 1")
+		}
 	}
 
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	query += " ORDER BY published_at DESC"
+	// Ties fall back to the ID so every listing agrees on one order
+	query += " ORDER BY "
+	if filter != nil && filter.ByPriority {
+		query += "(SELECT CASE priority WHEN '" + models.PriorityHigh + "' THEN 0 WHEN '" + models.PriorityLow +
+			"' THEN 2 ELSE 1 END FROM feeds WHERE feeds.id = entries.feed_id), "
+	}
+	if filter != nil && filter.ByScore {
+		query += "score IS NULL, score DESC, "
+	}
+	query += dateColumn + " DESC, id DESC"
 
 	if filter != nil {
 		if filter.Limit != nil {
@@ -421,36 +810,38 @@ func (s *SQLiteStore) ListEntries(filter *EntryFilter) ([]*models.Entry, error)
 			query += fmt.Sprintf(" OFFSET %d", *filter.Offset)
 		}
 	}
+	return query, args
+}
 
-	rows, err := s.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("query entries: %w", err)
-	}
-	defer rows.Close()
+// UpdateEntry updates an existing entry.
+func (s *SQLiteStore) UpdateEntry(ctx context.Context, entry *models.Entry) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
 
-	var entries []*models.Entry
-	for rows.Next() {
-		entry, err := s.scanEntryFromRows(rows)
-		if err != nil {
-			return nil, err
-		}
-		entries = append(entries, entry)
+	if s.user != "" {
+		return s.updateUserEntry(ctx, entry)
 	}
-	return entries, nil
-}
 
-// UpdateEntry updates an existing entry.
-func (s *SQLiteStore) UpdateEntry(entry *models.Entry) error {
 	query := `
 		UPDATE entries SET
 			title = ?, link = ?, author = ?, published_at = ?,
-			content = ?, read = ?, read_at = ?
+			content = ?, read = ?, read_at = ?, archive_url = ?, claimed_published_at = ?,
+			image_url = ?, discussion_url = ?, comments_feed_url = ?,
+			score = ?, comment_count = ?, scored_at = ?, keep_unread = ?, extensions = ?, content_hash = ?, updated_at = ?,
+			change_seq = ` + nextChangeSeq + `
 		WHERE id = ?
 	`
-	result, err := s.db.Exec(query,
+	extensions, err := extensionsToSQL(entry.Extensions)
+	if err != nil {
+		return err
+	}
+	entry.UpdatedAt = changeTime()
+	result, err := s.db.ExecContext(ctx, query,
 		entry.Title, entry.Link, entry.Author, timeToSQL(entry.PublishedAt),
 		entry.Content, boolToInt(entry.Read), timeToSQL(entry.ReadAt),
-		entry.ID,
+		entry.ArchiveURL, timeToSQL(entry.ClaimedPublishedAt), entry.ImageURL,
+		entry.DiscussionURL, entry.CommentsFeedURL, entry.Score, entry.CommentCount,
+		timeToSQL(entry.ScoredAt), boolToInt(entry.KeepUnread), extensions, entry.ContentHash, entry.UpdatedAt, entry.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update entry: %w", err)
@@ -463,8 +854,11 @@ func (s *SQLiteStore) UpdateEntry(entry *models.Entry) error {
 }
 
 // DeleteEntry removes an entry.
-func (s *SQLiteStore) DeleteEntry(id string) error {
-	result, err := s.db.Exec("DELETE FROM entries WHERE id = ?", id)
+func (s *SQLiteStore) DeleteEntry(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM entries WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete entry: %w", err)
 	}
@@ -475,11 +869,38 @@ func (s *SQLiteStore) DeleteEntry(id string) error {
 	return nil
 }
 
+// MoveEntry reassigns an entry to another feed, keeping its ID and read state.
+func (s *SQLiteStore) MoveEntry(ctx context.Context, id, feedID string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	query := `UPDATE entries SET feed_id = ?, updated_at = ?, change_seq = ` + nextChangeSeq + ` WHERE id = ?`
+	result, err := s.db.ExecContext(ctx, query, feedID, changeTime(), id)
+	if err != nil {
+		return fmt.Errorf("move entry: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("entry not found: %s", id)
+	}
+	return nil
+}
+
 // MarkEntryRead marks an entry as read.
-func (s *SQLiteStore) MarkEntryRead(id string) error {
+func (s *SQLiteStore) MarkEntryRead(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	now := time.Now()
-	query := `UPDATE entries SET read = 1, read_at = ? WHERE id = ?`
-	result, err := s.db.Exec(query, now, id)
+	if s.user != "" {
+		_, _, keep, err := s.userState(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.setUserState(ctx, id, true, &now, keep)
+	}
+	query := `UPDATE entries SET read = 1, read_at = ?, updated_at = ?, change_seq = ` + nextChangeSeq + ` WHERE id = ?`
+	result, err := s.db.ExecContext(ctx, query, now, changeTime(), id)
 	if err != nil {
 		return fmt.Errorf("mark entry read: %w", err)
 	}
@@ -491,9 +912,19 @@ func (s *SQLiteStore) MarkEntryRead(id string) error {
 }
 
 // MarkEntryUnread marks an entry as unread.
-func (s *SQLiteStore) MarkEntryUnread(id string) error {
-	query := `UPDATE entries SET read = 0, read_at = NULL WHERE id = ?`
-	result, err := s.db.Exec(query, id)
+func (s *SQLiteStore) MarkEntryUnread(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if s.user != "" {
+		_, _, keep, err := s.userState(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.setUserState(ctx, id, false, nil, keep)
+	}
+	query := `UPDATE entries SET read = 0, read_at = NULL, updated_at = ?, change_seq = ` + nextChangeSeq + ` WHERE id = ?`
+	result, err := s.db.ExecContext(ctx, query, changeTime(), id)
 	if err != nil {
 		return fmt.Errorf("mark entry unread: %w", err)
 	}
@@ -504,61 +935,198 @@ func (s *SQLiteStore) MarkEntryUnread(id string) error {
 	return nil
 }
 
-// MarkEntriesReadBefore marks all unread entries before the given time as read.
-func (s *SQLiteStore) MarkEntriesReadBefore(before time.Time) (int64, error) {
+// SetEntryKeepUnread pins an entry unread, marking it unread if it was
+// read, or releases the pin.
+func (s *SQLiteStore) SetEntryKeepUnread(ctx context.Context, id string, keep bool) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if s.user != "" {
+		if keep {
+			return s.setUserState(ctx, id, false, nil, true)
+		}
+		read, readAt, _, err := s.userState(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.setUserState(ctx, id, read, readAt, false)
+	}
+	query := `UPDATE entries SET keep_unread = 0, updated_at = ?, change_seq = ` + nextChangeSeq + ` WHERE id = ?`
+	if keep {
+		query = `UPDATE entries SET keep_unread = 1, read = 0, read_at = NULL, updated_at = ?, change_seq = ` + nextChangeSeq + ` WHERE id = ?`
+	}
+	result, err := s.db.ExecContext(ctx, query, changeTime(), id)
+	if err != nil {
+		return fmt.Errorf("set entry keep unread: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("entry not found: %s", id)
+	}
+	return nil
+}
+
+// MarkEntriesReadBefore marks all unread entries before the given time as
+// read, except those kept unread.
+func (s *SQLiteStore) MarkEntriesReadBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if s.user != "" {
+		return s.markUserEntriesReadBefore(ctx, before)
+	}
 	now := time.Now()
-	query := `UPDATE entries SET read = 1, read_at = ? WHERE read = 0 AND published_at < ?`
-	result, err := s.db.Exec(query, now, before)
+	query := `UPDATE entries SET read = 1, read_at = ?, updated_at = ?, change_seq = ` + nextChangeSeq + ` WHERE read = 0 AND keep_unread = 0 AND published_at < ?`
+	result, err := s.db.ExecContext(ctx, query, now, changeTime(), before)
 	if err != nil {
 		return 0, fmt.Errorf("mark entries read before: %w", err)
 	}
 	return result.RowsAffected()
 }
 
+// GetChangesSince returns entries created, changed or deleted after
+// cursor, up to limit (0 for no limit). An empty cursor starts before every
+// change. Changes are ordered by their sequence number rather than their
+// time, so a write that commits after a page was read always lands past
+// that page's cursor.
+func (s *SQLiteStore) GetChangesSince(ctx context.Context, cursor string, limit int) (*ChangeSet, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var where string
+	var args []interface{}
+	if after != nil {
+		where = " WHERE change_seq > ? OR (change_seq = ? AND id > ?)"
+		args = append(args, after.seq, after.seq, after.id)
+	}
+	order := " ORDER BY change_seq, id"
+	if limit > 0 {
+		// One more than asked shows whether there are more
+		order += fmt.Sprintf(" LIMIT %d", limit+1)
+	}
+
+	query := `
+		SELECT id, feed_id, guid, title, link, author, published_at, content, read, read_at, archive_url, created_at, claimed_published_at, updated_at, image_url, discussion_url, comments_feed_url, score, comment_count, scored_at, keep_unread, extensions, content_hash, change_seq
+		FROM ` + s.entryTable("entries") + where + order
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []change
+	for rows.Next() {
+		var seq int64
+		entry, err := s.scanEntryFromRows(rows, &seq)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change{pos: changeCursor{seq: seq, id: entry.ID}, entry: entry})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query changes: %w", err)
+	}
+	rows.Close()
+
+	query = "SELECT id, feed_id, deleted_at, change_seq FROM entry_deletions" + where + order
+	rows, err = s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query deletions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var deleted DeletedEntry
+		var seq int64
+		if err := rows.Scan(&deleted.ID, &deleted.FeedID, &deleted.DeletedAt, &seq); err != nil {
+			return nil, fmt.Errorf("scan deletion: %w", err)
+		}
+		deleted.DeletedAt = deleted.DeletedAt.UTC()
+		changes = append(changes, change{pos: changeCursor{seq: seq, id: deleted.ID}, deleted: &deleted})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query deletions: %w", err)
+	}
+	return newChangeSet(changes, cursor, limit), nil
+}
+
 // EntryExists checks if an entry exists with the given feed_id and guid.
-func (s *SQLiteStore) EntryExists(feedID, guid string) (bool, error) {
+func (s *SQLiteStore) EntryExists(ctx context.Context, feedID, guid string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	var count int
 	query := `SELECT COUNT(*) FROM entries WHERE feed_id = ? AND guid = ?`
-	if err := s.db.QueryRow(query, feedID, guid).Scan(&count); err != nil {
+	if err := s.db.QueryRowContext(ctx, query, feedID, guid).Scan(&count); err != nil {
 		return false, fmt.Errorf("check entry exists: %w", err)
 	}
 	return count > 0, nil
 }
 
 // CountUnreadEntries counts unread entries, optionally filtered by feedID.
-func (s *SQLiteStore) CountUnreadEntries(feedID *string) (int, error) {
+func (s *SQLiteStore) CountUnreadEntries(ctx context.Context, feedID *string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	var count int
 	var query string
 	var args []interface{}
 
 	if feedID != nil {
-		query = `SELECT COUNT(*) FROM entries WHERE read = 0 AND feed_id = ?`
+		query = `SELECT COUNT(*) FROM ` + s.entryTable("entries") + ` WHERE read = 0 AND feed_id = ?`
 		args = append(args, *feedID)
 	} else {
-		query = `SELECT COUNT(*) FROM entries WHERE read = 0`
+		query = `SELECT COUNT(*) FROM ` + s.entryTable("entries") + ` WHERE read = 0`
 	}
 
-	if err := s.db.QueryRow(query, args...).Scan(&count); err != nil {
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
 		return 0, fmt.Errorf("count unread entries: %w", err)
 	}
 	return count, nil
 }
 
+// CountEntries counts entries, optionally filtered by feedID.
+func (s *SQLiteStore) CountEntries(ctx context.Context, feedID *string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM ` + s.entryTable("entries")
+	var args []interface{}
+	if feedID != nil {
+		query += ` WHERE feed_id = ?`
+		args = append(args, *feedID)
+	}
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count entries: %w", err)
+	}
+	return count, nil
+}
+
 // Statistics
 
 // GetFeedStats retrieves statistics for all feeds.
-func (s *SQLiteStore) GetFeedStats() ([]FeedStatsRow, error) {
+func (s *SQLiteStore) GetFeedStats(ctx context.Context) ([]FeedStatsRow, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	query := `
 		SELECT f.id, f.url, f.title, f.last_fetched_at, f.error_count, f.last_error,
 			   COUNT(e.id) as entry_count,
-			   SUM(CASE WHEN e.read = 0 THEN 1 ELSE 0 END) as unread_count
+			   SUM(CASE WHEN e.read = 0 THEN 1 ELSE 0 END) as unread_count,
+			   (SELECT created_at FROM entries WHERE feed_id = f.id ORDER BY created_at DESC LIMIT 1) as last_entry_at,
+			   f.archived_at
 		FROM feeds f
-		LEFT JOIN entries e ON f.id = e.feed_id
+		LEFT JOIN ` + s.entryTable("e") + ` ON f.id = e.feed_id
 		GROUP BY f.id
 		ORDER BY f.created_at DESC
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("query feed stats: %w", err)
 	}
@@ -567,17 +1135,24 @@ func (s *SQLiteStore) GetFeedStats() ([]FeedStatsRow, error) {
 	var stats []FeedStatsRow
 	for rows.Next() {
 		var row FeedStatsRow
-		var lastFetched sql.NullTime
+		var lastFetched, lastEntry, archivedAt sql.NullTime
 		var unreadCount sql.NullInt64
 		if err := rows.Scan(
 			&row.FeedID, &row.FeedURL, &row.FeedTitle, &lastFetched,
 			&row.ErrorCount, &row.LastError, &row.EntryCount, &unreadCount,
+			&lastEntry, &archivedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan feed stats: %w", err)
 		}
 		if lastFetched.Valid {
 			row.LastFetchedAt = &lastFetched.Time
 		}
+		if lastEntry.Valid {
+			row.LastEntryAt = &lastEntry.Time
+		}
+		if archivedAt.Valid {
+			row.ArchivedAt = &archivedAt.Time
+		}
 		if unreadCount.Valid {
 			row.UnreadCount = int(unreadCount.Int64)
 		}
@@ -587,21 +1162,24 @@ func (s *SQLiteStore) GetFeedStats() ([]FeedStatsRow, error) {
 }
 
 // GetOverallStats retrieves overall statistics.
-func (s *SQLiteStore) GetOverallStats() (*OverallStats, error) {
+func (s *SQLiteStore) GetOverallStats(ctx context.Context) (*OverallStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
 	var stats OverallStats
 
 	// Total feeds
-	if err := s.db.QueryRow(`SELECT COUNT(*) FROM feeds`).Scan(&stats.TotalFeeds); err != nil {
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM feeds`).Scan(&stats.TotalFeeds); err != nil {
 		return nil, fmt.Errorf("count feeds: %w", err)
 	}
 
 	// Total entries
-	if err := s.db.QueryRow(`SELECT COUNT(*) FROM entries`).Scan(&stats.TotalEntries); err != nil {
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM entries`).Scan(&stats.TotalEntries); err != nil {
 		return nil, fmt.Errorf("count entries: %w", err)
 	}
 
 	// Unread count
-	if err := s.db.QueryRow(`SELECT COUNT(*) FROM entries WHERE read = 0`).Scan(&stats.UnreadCount); err != nil {
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+s.entryTable("entries")+` WHERE read = 0`).Scan(&stats.UnreadCount); err != nil {
 		return nil, fmt.Errorf("count unread: %w", err)
 	}
 
@@ -612,14 +1190,14 @@ func (s *SQLiteStore) GetOverallStats() (*OverallStats, error) {
 
 // GetEntryByIDOrPrefix tries to get an entry by exact ID first,
 // then falls back to prefix matching if not found.
-func (s *SQLiteStore) GetEntryByIDOrPrefix(ref string) (*models.Entry, error) {
-	entry, err := s.GetEntry(ref)
+func (s *SQLiteStore) GetEntryByIDOrPrefix(ctx context.Context, ref string) (*models.Entry, error) {
+	entry, err := s.GetEntry(ctx, ref)
 	if err == nil {
 		return entry, nil
 	}
 
 	// Try prefix match
-	entry, err = s.GetEntryByPrefix(ref)
+	entry, err = s.GetEntryByPrefix(ctx, ref)
 	if err != nil {
 		return nil, fmt.Errorf("entry not found: %s", ref)
 	}
@@ -628,14 +1206,14 @@ func (s *SQLiteStore) GetEntryByIDOrPrefix(ref string) (*models.Entry, error) {
 
 // GetFeedByURLOrPrefix tries to get a feed by exact URL first,
 // then falls back to prefix matching if not found.
-func (s *SQLiteStore) GetFeedByURLOrPrefix(ref string) (*models.Feed, error) {
-	feed, err := s.GetFeedByURL(ref)
+func (s *SQLiteStore) GetFeedByURLOrPrefix(ctx context.Context, ref string) (*models.Feed, error) {
+	feed, err := s.GetFeedByURL(ctx, ref)
 	if err == nil {
 		return feed, nil
 	}
 
 	// Try prefix match
-	feed, err = s.GetFeedByPrefix(ref)
+	feed, err = s.GetFeedByPrefix(ctx, ref)
 	if err != nil {
 		return nil, fmt.Errorf("feed not found: %s", ref)
 	}
@@ -645,26 +1223,130 @@ func (s *SQLiteStore) GetFeedByURLOrPrefix(ref string) (*models.Feed, error) {
 // Maintenance
 
 // Compact performs database maintenance (VACUUM).
-func (s *SQLiteStore) Compact() error {
-	_, err := s.db.Exec("VACUUM")
+func (s *SQLiteStore) Compact(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, "VACUUM")
 	if err != nil {
 		return fmt.Errorf("vacuum: %w", err)
 	}
 	return nil
 }
 
-// Search performs full-text search on entries.
-func (s *SQLiteStore) Search(query string, limit int) ([]*models.Entry, error) {
+// Reindex rebuilds and optimizes the FTS index, then checkpoints the WAL and
+// reclaims free pages. Incremental vacuum is used when the database was
+// created with auto_vacuum=INCREMENTAL; otherwise a full VACUUM runs.
+func (s *SQLiteStore) Reindex(ctx context.Context) (*ReindexResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+	defer cancel()
+
+	result := &ReindexResult{BytesBefore: s.fileSize()}
+
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entries").Scan(&result.Entries); err != nil {
+		return nil, fmt.Errorf("count entries: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "INSERT INTO entries_fts(entries_fts) VALUES('rebuild')"); err != nil {
+		return nil, fmt.Errorf("rebuild search index: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "INSERT INTO entries_fts(entries_fts) VALUES('optimize')"); err != nil {
+		return nil, fmt.Errorf("optimize search index: %w", err)
+	}
+
+	var autoVacuum int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&autoVacuum); err != nil {
+		return nil, fmt.Errorf("read auto_vacuum: %w", err)
+	}
+	vacuum := "VACUUM"
+	if autoVacuum == 2 { // INCREMENTAL
+		vacuum = "PRAGMA incremental_vacuum"
+	}
+	if _, err := s.db.ExecContext(ctx, vacuum); err != nil {
+		return nil, fmt.Errorf("vacuum: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return nil, fmt.Errorf("checkpoint: %w", err)
+	}
+
+	result.BytesAfter = s.fileSize()
+	return result, nil
+}
+
+// fileSize returns the combined size of the database file and its WAL.
+func (s *SQLiteStore) fileSize() int64 {
+	var total int64
+	for _, p := range []string{s.path, s.path + "-wal"} {
+		if info, err := os.Stat(p); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// footprintBatch caps the IDs bound into one EntryFootprint query.
+const footprintBatch = 500
+
+// EntryFootprint estimates what deleting entries would reclaim: the text
+// stored in their rows plus their rows in the search index. Pages are only
+// returned to the filesystem by a later 'digest maintenance compact'.
+func (s *SQLiteStore) EntryFootprint(ctx context.Context, entries []*models.Entry) (*Footprint, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	fp := &Footprint{}
+	for start := 0; start < len(entries); start += footprintBatch {
+		batch := entries[start:min(start+footprintBatch, len(entries))]
+		args := make([]interface{}, len(batch))
+		for i, e := range batch {
+			args[i] = e.ID
+		}
+		// Titles and content are stored twice: in the row and in the index
+		query := `
+			SELECT COUNT(*), COALESCE(SUM(
+				LENGTH(CAST(id AS BLOB)) + LENGTH(CAST(feed_id AS BLOB)) + LENGTH(CAST(guid AS BLOB)) +
+				2 * (COALESCE(LENGTH(CAST(title AS BLOB)), 0) + COALESCE(LENGTH(CAST(content AS BLOB)), 0)) +
+				COALESCE(LENGTH(CAST(link AS BLOB)), 0) + COALESCE(LENGTH(CAST(author AS BLOB)), 0) +
+				COALESCE(LENGTH(CAST(extensions AS BLOB)), 0)
+			), 0)
+			FROM entries WHERE id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(batch)), ",") + ")"
+		var rows int
+		var bytes int64
+		if err := s.db.QueryRowContext(ctx, query, args...).Scan(&rows, &bytes); err != nil {
+			return nil, fmt.Errorf("measure entries: %w", err)
+		}
+		fp.FTSRows += rows
+		fp.Bytes += bytes
+	}
+	return fp, nil
+}
+
+// Search performs full-text search on entries, best matches first. The
+// query uses FTS5 syntax, so phrases, OR, NOT and prefix* work; a query
+// that isn't valid FTS5, such as "last-week" or an unbalanced quote, is
+// searched for as a phrase instead of failing.
+func (s *SQLiteStore) Search(ctx context.Context, query string, limit int) ([]*models.Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	entries, err := s.searchMatch(ctx, query, limit)
+	if isFTSQueryError(err) {
+		entries, err = s.searchMatch(ctx, ftsPhrase(query), limit)
+	}
+	return entries, err
+}
+
+// searchMatch returns the entries matching an FTS5 query.
+func (s *SQLiteStore) searchMatch(ctx context.Context, match string, limit int) ([]*models.Entry, error) {
 	sqlQuery := `
-		SELECT e.id, e.feed_id, e.guid, e.title, e.link, e.author, e.published_at, e.content, e.read, e.read_at, e.created_at
-		FROM entries e
+		SELECT e.id, e.feed_id, e.guid, e.title, e.link, e.author, e.published_at, e.content, e.read, e.read_at, e.archive_url, e.created_at, e.claimed_published_at, e.updated_at, e.image_url, e.discussion_url, e.comments_feed_url, e.score, e.comment_count, e.scored_at, e.keep_unread, e.extensions, e.content_hash
+		FROM ` + s.entryTable("e") + `
 		INNER JOIN entries_fts fts ON e.rowid = fts.rowid
 		WHERE entries_fts MATCH ?
 		ORDER BY rank
 		LIMIT ?
 	`
 
-	rows, err := s.db.Query(sqlQuery, query, limit)
+	rows, err := s.db.QueryContext(ctx, sqlQuery, match, limit)
 	if err != nil {
 		return nil, fmt.Errorf("search entries: %w", err)
 	}
@@ -678,58 +1360,102 @@ func (s *SQLiteStore) Search(query string, limit int) ([]*models.Entry, error) {
 		}
 		entries = append(entries, entry)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search entries: %w", err)
+	}
 	return entries, nil
 }
 
+// isFTSQueryError reports whether err is FTS5 rejecting a query's syntax.
+// Bare punctuation is a syntax error, and a word before a colon is read as
+// a column name.
+func isFTSQueryError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "fts5: syntax error") || strings.Contains(msg, "unterminated string") ||
+		strings.Contains(msg, "no such column")
+}
+
+// ftsPhrase quotes query as a single FTS5 phrase, which is always valid.
+func ftsPhrase(query string) string {
+	return `"` + strings.ReplaceAll(query, `"`, `""`) + `"`
+}
+
 // Helper functions
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
 func (s *SQLiteStore) scanFeed(row *sql.Row) (*models.Feed, error) {
-	var feed models.Feed
-	var lastFetched sql.NullTime
-	var localNetworkInt int
-	if err := row.Scan(
-		&feed.ID, &feed.URL, &feed.Title, &feed.Folder,
-		&feed.ETag, &feed.LastModified, &lastFetched,
-		&feed.LastError, &feed.ErrorCount, &localNetworkInt, &feed.CreatedAt,
-	); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("feed not found")
-		}
-		return nil, fmt.Errorf("scan feed: %w", err)
+	feed, err := scanFeedInto(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("feed not found")
 	}
-	if lastFetched.Valid {
-		feed.LastFetchedAt = &lastFetched.Time
+	if err != nil {
+		return nil, fmt.Errorf("scan feed: %w", err)
 	}
-	feed.LocalNetwork = localNetworkInt == 1
-	return &feed, nil
+	return feed, nil
 }
 
 func (s *SQLiteStore) scanFeedFromRows(rows *sql.Rows) (*models.Feed, error) {
+	feed, err := scanFeedInto(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan feed: %w", err)
+	}
+	return feed, nil
+}
+
+// scanFeedInto scans a row selected with feedColumns into a Feed.
+func scanFeedInto(sc rowScanner) (*models.Feed, error) {
 	var feed models.Feed
-	var lastFetched sql.NullTime
-	var localNetworkInt int
-	if err := rows.Scan(
+	var lastFetched, archivedAt sql.NullTime
+	var localNetworkInt, pausedInt, keepActiveInt, browserInt, monitorInt int
+	var syncIntervalSecs int64
+	var identity, priority sql.NullString
+	if err := sc.Scan(
 		&feed.ID, &feed.URL, &feed.Title, &feed.Folder,
 		&feed.ETag, &feed.LastModified, &lastFetched,
-		&feed.LastError, &feed.ErrorCount, &localNetworkInt, &feed.CreatedAt,
+		&feed.LastError, &feed.ErrorCount, &localNetworkInt,
+		&pausedInt, &syncIntervalSecs, &feed.MaxEntries,
+		&feed.AuthUsername, &feed.AuthPassword, &feed.CreatedAt,
+		&archivedAt, &keepActiveInt, &identity,
+		&feed.BackfillDays, &feed.BackfillLimit, &browserInt,
+		&monitorInt, &feed.PageText, &priority,
 	); err != nil {
-		return nil, fmt.Errorf("scan feed: %w", err)
+		return nil, err
 	}
 	if lastFetched.Valid {
 		feed.LastFetchedAt = &lastFetched.Time
 	}
+	if archivedAt.Valid {
+		feed.ArchivedAt = &archivedAt.Time
+	}
 	feed.LocalNetwork = localNetworkInt == 1
+	feed.Paused = pausedInt == 1
+	feed.KeepActive = keepActiveInt == 1
+	feed.Browser = browserInt == 1
+	feed.Monitor = monitorInt == 1
+	feed.Identity = identity.String
+	feed.Priority = priority.String
+	feed.SyncInterval = time.Duration(syncIntervalSecs) * time.Second
 	return &feed, nil
 }
 
 func (s *SQLiteStore) scanEntry(row *sql.Row) (*models.Entry, error) {
 	var entry models.Entry
-	var publishedAt, readAt sql.NullTime
-	var readInt int
+	var publishedAt, readAt, claimedAt, updatedAt, scoredAt sql.NullTime
+	var readInt, keepInt int
+	var extensions, contentHash sql.NullString
 	if err := row.Scan(
 		&entry.ID, &entry.FeedID, &entry.GUID, &entry.Title, &entry.Link,
 		&entry.Author, &publishedAt, &entry.Content, &readInt, &readAt,
-		&entry.CreatedAt,
+		&entry.ArchiveURL, &entry.CreatedAt, &claimedAt, &updatedAt, &entry.ImageURL,
+		&entry.DiscussionURL, &entry.CommentsFeedURL, &entry.Score, &entry.CommentCount,
+		&scoredAt, &keepInt, &extensions, &contentHash,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("entry not found")
@@ -742,19 +1468,39 @@ func (s *SQLiteStore) scanEntry(row *sql.Row) (*models.Entry, error) {
 	if readAt.Valid {
 		entry.ReadAt = &readAt.Time
 	}
+	if claimedAt.Valid {
+		entry.ClaimedPublishedAt = &claimedAt.Time
+	}
+	if scoredAt.Valid {
+		entry.ScoredAt = &scoredAt.Time
+	}
+	entry.UpdatedAt = updatedAt.Time.UTC()
 	entry.Read = readInt == 1
+	entry.KeepUnread = keepInt == 1
+	entry.ContentHash = contentHash.String
+	ext, err := extensionsFromSQL(extensions)
+	if err != nil {
+		return nil, err
+	}
+	entry.Extensions = ext
 	return &entry, nil
 }
 
-func (s *SQLiteStore) scanEntryFromRows(rows *sql.Rows) (*models.Entry, error) {
+// scanEntryFromRows scans an entry's columns, then any extra columns the
+// query selected after them into extra.
+func (s *SQLiteStore) scanEntryFromRows(rows *sql.Rows, extra ...interface{}) (*models.Entry, error) {
 	var entry models.Entry
-	var publishedAt, readAt sql.NullTime
-	var readInt int
-	if err := rows.Scan(
+	var publishedAt, readAt, claimedAt, updatedAt, scoredAt sql.NullTime
+	var readInt, keepInt int
+	var extensions, contentHash sql.NullString
+	dest := []interface{}{
 		&entry.ID, &entry.FeedID, &entry.GUID, &entry.Title, &entry.Link,
 		&entry.Author, &publishedAt, &entry.Content, &readInt, &readAt,
-		&entry.CreatedAt,
-	); err != nil {
+		&entry.ArchiveURL, &entry.CreatedAt, &claimedAt, &updatedAt, &entry.ImageURL,
+		&entry.DiscussionURL, &entry.CommentsFeedURL, &entry.Score, &entry.CommentCount,
+		&scoredAt, &keepInt, &extensions, &contentHash,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
 		return nil, fmt.Errorf("scan entry: %w", err)
 	}
 	if publishedAt.Valid {
@@ -763,7 +1509,21 @@ func (s *SQLiteStore) scanEntryFromRows(rows *sql.Rows) (*models.Entry, error) {
 	if readAt.Valid {
 		entry.ReadAt = &readAt.Time
 	}
+	if claimedAt.Valid {
+		entry.ClaimedPublishedAt = &claimedAt.Time
+	}
+	if scoredAt.Valid {
+		entry.ScoredAt = &scoredAt.Time
+	}
+	entry.UpdatedAt = updatedAt.Time.UTC()
 	entry.Read = readInt == 1
+	entry.KeepUnread = keepInt == 1
+	entry.ContentHash = contentHash.String
+	ext, err := extensionsFromSQL(extensions)
+	if err != nil {
+		return nil, err
+	}
+	entry.Extensions = ext
 	return &entry, nil
 }
 
@@ -774,6 +1534,30 @@ func timeToSQL(t *time.Time) interface{} {
 	return *t
 }
 
+// extensionsToSQL encodes an entry's extension elements as JSON, or NULL
+// when it has none.
+func extensionsToSQL(x models.Extensions) (interface{}, error) {
+	if len(x) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(x)
+	if err != nil {
+		return nil, fmt.Errorf("encode entry extensions: %w", err)
+	}
+	return string(data), nil
+}
+
+func extensionsFromSQL(s sql.NullString) (models.Extensions, error) {
+	if !s.Valid || s.String == "" {
+		return nil, nil
+	}
+	var x models.Extensions
+	if err := json.Unmarshal([]byte(s.String), &x); err != nil {
+		return nil, fmt.Errorf("decode entry extensions: %w", err)
+	}
+	return x, nil
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
@@ -0,0 +1,171 @@
+// ABOUTME: Integrity checks for SQLiteStore: schema version, database integrity, FTS index, orphans
+// ABOUTME: Repairs are limited to re-running migrations, rebuilding FTS, and deleting orphaned entries
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Compile-time check that SQLiteStore implements Doctor.
+var _ Doctor = (*SQLiteStore)(nil)
+
+// Diagnose runs the SQLite integrity checks, repairing what it can when fix is set.
+func (s *SQLiteStore) Diagnose(ctx context.Context, fix bool) ([]CheckResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+	defer cancel()
+
+	checks := []func(context.Context, bool) (CheckResult, error){
+		s.checkSchema,
+		s.checkIntegrity,
+		s.checkFTS,
+		s.checkOrphanedEntries,
+	}
+
+	results := make([]CheckResult, 0, len(checks))
+	for _, check := range checks {
+		result, err := check(ctx, fix)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// checkSchema verifies the recorded schema version and that every migrated column exists.
+func (s *SQLiteStore) checkSchema(ctx context.Context, fix bool) (CheckResult, error) {
+	result := CheckResult{Name: "schema"}
+
+	version, err := s.schemaVersion(ctx)
+	if err != nil {
+		return result, err
+	}
+	if version > SchemaVersion {
+		result.Detail = fmt.Sprintf("database schema v%d is newer than this digest (v%d); upgrade digest", version, SchemaVersion)
+		return result, nil
+	}
+
+	missing, err := s.missingColumns(ctx, "feeds", feedColumnMigrations)
+	if err != nil {
+		return result, err
+	}
+	missingEntries, err := s.missingColumns(ctx, "entries", entryColumnMigrations)
+	if err != nil {
+		return result, err
+	}
+	missing = append(missing, missingEntries...)
+	if version == SchemaVersion && len(missing) == 0 {
+		result.OK = true
+		result.Detail = fmt.Sprintf("v%d", version)
+		return result, nil
+	}
+
+	result.Fixable = true
+	result.Detail = fmt.Sprintf("schema v%d, expected v%d", version, SchemaVersion)
+	if len(missing) > 0 {
+		result.Detail += "; missing columns: " + strings.Join(missing, ", ")
+	}
+	if fix {
+		if err := s.migrate(); err != nil {
+			return result, err
+		}
+		result.OK, result.Fixed = true, true
+	}
+	return result, nil
+}
+
+// missingColumns returns migrated columns absent from table, as table.column.
+func (s *SQLiteStore) missingColumns(ctx context.Context, table string, cols []columnMigration) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+
+	have := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan %s column: %w", table, err)
+		}
+		have[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read %s columns: %w", table, err)
+	}
+
+	var missing []string
+	for _, col := range cols {
+		if !have[col.name] {
+			missing = append(missing, table+"."+col.name)
+		}
+	}
+	return missing, nil
+}
+
+// checkIntegrity runs SQLite's quick_check. Corruption isn't something we can repair.
+func (s *SQLiteStore) checkIntegrity(ctx context.Context, _ bool) (CheckResult, error) {
+	result := CheckResult{Name: "integrity"}
+
+	var status string
+	if err := s.db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&status); err != nil {
+		return result, fmt.Errorf("quick check: %w", err)
+	}
+	result.OK = status == "ok"
+	result.Detail = status
+	return result, nil
+}
+
+// checkFTS verifies the full-text index matches the entries table.
+func (s *SQLiteStore) checkFTS(ctx context.Context, fix bool) (CheckResult, error) {
+	result := CheckResult{Name: "fts"}
+
+	// For external-content tables this compares the index against entries
+	_, err := s.db.ExecContext(ctx, "INSERT INTO entries_fts(entries_fts, rank) VALUES('integrity-check', 1)")
+	if err == nil {
+		result.OK = true
+		result.Detail = "search index consistent"
+		return result, nil
+	}
+
+	result.Fixable = true
+	result.Detail = fmt.Sprintf("search index out of sync: %v", err)
+	if fix {
+		if _, err := s.db.ExecContext(ctx, "INSERT INTO entries_fts(entries_fts) VALUES('rebuild')"); err != nil {
+			return result, fmt.Errorf("rebuild search index: %w", err)
+		}
+		result.OK, result.Fixed = true, true
+		result.Detail = "search index rebuilt"
+	}
+	return result, nil
+}
+
+// checkOrphanedEntries finds entries whose feed no longer exists.
+func (s *SQLiteStore) checkOrphanedEntries(ctx context.Context, fix bool) (CheckResult, error) {
+	result := CheckResult{Name: "orphans"}
+
+	const orphanWhere = "feed_id NOT IN (SELECT id FROM feeds)"
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entries WHERE "+orphanWhere).Scan(&count); err != nil {
+		return result, fmt.Errorf("count orphaned entries: %w", err)
+	}
+	if count == 0 {
+		result.OK = true
+		result.Detail = "no orphaned entries"
+		return result, nil
+	}
+
+	result.Fixable = true
+	result.Detail = fmt.Sprintf("%d entries belong to deleted feeds", count)
+	if fix {
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM entries WHERE "+orphanWhere); err != nil {
+			return result, fmt.Errorf("delete orphaned entries: %w", err)
+		}
+		result.OK, result.Fixed = true, true
+		result.Detail = fmt.Sprintf("deleted %d orphaned entries", count)
+	}
+	return result, nil
+}
@@ -4,8 +4,12 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -38,12 +42,12 @@ func TestFeedCRUD(t *testing.T) {
 	feed.Title = &title
 	feed.Folder = "Tech"
 
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Get by ID
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -58,7 +62,7 @@ func TestFeedCRUD(t *testing.T) {
 	}
 
 	// Get by URL
-	got, err = store.GetFeedByURL("https://example.com/feed.xml")
+	got, err = store.GetFeedByURL(context.Background(), "https://example.com/feed.xml")
 	if err != nil {
 		t.Fatalf("GetFeedByURL failed: %v", err)
 	}
@@ -68,7 +72,7 @@ func TestFeedCRUD(t *testing.T) {
 
 	// Get by prefix
 	prefix := feed.ID[:8]
-	got, err = store.GetFeedByPrefix(prefix)
+	got, err = store.GetFeedByPrefix(context.Background(), prefix)
 	if err != nil {
 		t.Fatalf("GetFeedByPrefix failed: %v", err)
 	}
@@ -79,11 +83,11 @@ func TestFeedCRUD(t *testing.T) {
 	// Update feed
 	newTitle := "Updated Feed"
 	feed.Title = &newTitle
-	if err := store.UpdateFeed(feed); err != nil {
+	if err := store.UpdateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("UpdateFeed failed: %v", err)
 	}
 
-	got, err = store.GetFeed(feed.ID)
+	got, err = store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed after update failed: %v", err)
 	}
@@ -92,7 +96,7 @@ func TestFeedCRUD(t *testing.T) {
 	}
 
 	// List feeds
-	feeds, err := store.ListFeeds()
+	feeds, err := store.ListFeeds(context.Background())
 	if err != nil {
 		t.Fatalf("ListFeeds failed: %v", err)
 	}
@@ -101,11 +105,11 @@ func TestFeedCRUD(t *testing.T) {
 	}
 
 	// Delete feed
-	if err := store.DeleteFeed(feed.ID); err != nil {
+	if err := store.DeleteFeed(context.Background(), feed.ID); err != nil {
 		t.Fatalf("DeleteFeed failed: %v", err)
 	}
 
-	_, err = store.GetFeed(feed.ID)
+	_, err = store.GetFeed(context.Background(), feed.ID)
 	if err == nil {
 		t.Error("expected error getting deleted feed")
 	}
@@ -117,7 +121,7 @@ func TestEntryCRUD(t *testing.T) {
 
 	// Create a feed first
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -132,12 +136,12 @@ func TestEntryCRUD(t *testing.T) {
 	entry.Content = &content
 	entry.PublishedAt = &pubTime
 
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Get by ID
-	got, err := store.GetEntry(entry.ID)
+	got, err := store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry failed: %v", err)
 	}
@@ -153,7 +157,7 @@ func TestEntryCRUD(t *testing.T) {
 
 	// Get by prefix
 	prefix := entry.ID[:8]
-	got, err = store.GetEntryByPrefix(prefix)
+	got, err = store.GetEntryByPrefix(context.Background(), prefix)
 	if err != nil {
 		t.Fatalf("GetEntryByPrefix failed: %v", err)
 	}
@@ -162,7 +166,7 @@ func TestEntryCRUD(t *testing.T) {
 	}
 
 	// Entry exists
-	exists, err := store.EntryExists(feed.ID, "guid-123")
+	exists, err := store.EntryExists(context.Background(), feed.ID, "guid-123")
 	if err != nil {
 		t.Fatalf("EntryExists failed: %v", err)
 	}
@@ -170,7 +174,7 @@ func TestEntryCRUD(t *testing.T) {
 		t.Error("expected entry to exist")
 	}
 
-	exists, err = store.EntryExists(feed.ID, "nonexistent-guid")
+	exists, err = store.EntryExists(context.Background(), feed.ID, "nonexistent-guid")
 	if err != nil {
 		t.Fatalf("EntryExists for nonexistent failed: %v", err)
 	}
@@ -179,11 +183,11 @@ func TestEntryCRUD(t *testing.T) {
 	}
 
 	// Mark as read
-	if err := store.MarkEntryRead(entry.ID); err != nil {
+	if err := store.MarkEntryRead(context.Background(), entry.ID); err != nil {
 		t.Fatalf("MarkEntryRead failed: %v", err)
 	}
 
-	got, err = store.GetEntry(entry.ID)
+	got, err = store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry after mark read failed: %v", err)
 	}
@@ -195,11 +199,11 @@ func TestEntryCRUD(t *testing.T) {
 	}
 
 	// Mark as unread
-	if err := store.MarkEntryUnread(entry.ID); err != nil {
+	if err := store.MarkEntryUnread(context.Background(), entry.ID); err != nil {
 		t.Fatalf("MarkEntryUnread failed: %v", err)
 	}
 
-	got, err = store.GetEntry(entry.ID)
+	got, err = store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry after mark unread failed: %v", err)
 	}
@@ -211,11 +215,11 @@ func TestEntryCRUD(t *testing.T) {
 	}
 
 	// Delete entry
-	if err := store.DeleteEntry(entry.ID); err != nil {
+	if err := store.DeleteEntry(context.Background(), entry.ID); err != nil {
 		t.Fatalf("DeleteEntry failed: %v", err)
 	}
 
-	_, err = store.GetEntry(entry.ID)
+	_, err = store.GetEntry(context.Background(), entry.ID)
 	if err == nil {
 		t.Error("expected error getting deleted entry")
 	}
@@ -227,7 +231,7 @@ func TestListEntriesFilter(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -248,11 +252,11 @@ func TestListEntriesFilter(t *testing.T) {
 	for _, e := range entries {
 		entry := models.NewEntry(feed.ID, e.guid, e.title)
 		entry.PublishedAt = &e.pub
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 		if e.read {
-			if err := store.MarkEntryRead(entry.ID); err != nil {
+			if err := store.MarkEntryRead(context.Background(), entry.ID); err != nil {
 				t.Fatalf("MarkEntryRead failed: %v", err)
 			}
 		}
@@ -261,7 +265,7 @@ func TestListEntriesFilter(t *testing.T) {
 	// Test unread only filter
 	unreadOnly := true
 	filter := &EntryFilter{UnreadOnly: &unreadOnly}
-	result, err := store.ListEntries(filter)
+	result, err := store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries unread failed: %v", err)
 	}
@@ -272,7 +276,7 @@ func TestListEntriesFilter(t *testing.T) {
 	// Test since filter
 	since := now.Add(-4 * time.Hour)
 	filter = &EntryFilter{Since: &since}
-	result, err = store.ListEntries(filter)
+	result, err = store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries since failed: %v", err)
 	}
@@ -283,7 +287,7 @@ func TestListEntriesFilter(t *testing.T) {
 	// Test limit
 	limit := 2
 	filter = &EntryFilter{Limit: &limit}
-	result, err = store.ListEntries(filter)
+	result, err = store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries limit failed: %v", err)
 	}
@@ -294,7 +298,7 @@ func TestListEntriesFilter(t *testing.T) {
 	// Test offset
 	offset := 1
 	filter = &EntryFilter{Limit: &limit, Offset: &offset}
-	result, err = store.ListEntries(filter)
+	result, err = store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries offset failed: %v", err)
 	}
@@ -304,7 +308,7 @@ func TestListEntriesFilter(t *testing.T) {
 
 	// Test feed filter
 	filter = &EntryFilter{FeedID: &feed.ID}
-	result, err = store.ListEntries(filter)
+	result, err = store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries feed filter failed: %v", err)
 	}
@@ -319,7 +323,7 @@ func TestMarkEntriesReadBefore(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -329,14 +333,14 @@ func TestMarkEntriesReadBefore(t *testing.T) {
 		entry := models.NewEntry(feed.ID, "guid-"+string(rune('0'+i)), "Article")
 		pub := now.Add(time.Duration(-i) * 24 * time.Hour)
 		entry.PublishedAt = &pub
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	// Mark entries older than 3 days as read
 	cutoff := now.Add(-2 * 24 * time.Hour)
-	count, err := store.MarkEntriesReadBefore(cutoff)
+	count, err := store.MarkEntriesReadBefore(context.Background(), cutoff)
 	if err != nil {
 		t.Fatalf("MarkEntriesReadBefore failed: %v", err)
 	}
@@ -345,13 +349,22 @@ func TestMarkEntriesReadBefore(t *testing.T) {
 	}
 
 	// Verify unread count
-	unreadCount, err := store.CountUnreadEntries(nil)
+	unreadCount, err := store.CountUnreadEntries(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("CountUnreadEntries failed: %v", err)
 	}
 	if unreadCount != 2 {
 		t.Errorf("expected 2 unread entries, got %d", unreadCount)
 	}
+
+	// CountEntries counts read entries too
+	if count, err := store.CountEntries(context.Background(), &feed.ID); err != nil || count != 5 {
+		t.Errorf("CountEntries = %d, %v; want 5", count, err)
+	}
+	other := "no-such-feed"
+	if count, err := store.CountEntries(context.Background(), &other); err != nil || count != 0 {
+		t.Errorf("CountEntries(other feed) = %d, %v; want 0", count, err)
+	}
 }
 
 func TestFeedFetchState(t *testing.T) {
@@ -360,7 +373,7 @@ func TestFeedFetchState(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -368,11 +381,11 @@ func TestFeedFetchState(t *testing.T) {
 	etag := "abc123"
 	lastMod := "Wed, 01 Jan 2025 00:00:00 GMT"
 	fetchedAt := time.Now()
-	if err := store.UpdateFeedFetchState(feed.ID, &etag, &lastMod, fetchedAt); err != nil {
+	if err := store.UpdateFeedFetchState(context.Background(), feed.ID, &etag, &lastMod, fetchedAt); err != nil {
 		t.Fatalf("UpdateFeedFetchState failed: %v", err)
 	}
 
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -385,11 +398,11 @@ func TestFeedFetchState(t *testing.T) {
 
 	// Update with error
 	errMsg := "connection timeout"
-	if err := store.UpdateFeedError(feed.ID, errMsg); err != nil {
+	if err := store.UpdateFeedError(context.Background(), feed.ID, errMsg); err != nil {
 		t.Fatalf("UpdateFeedError failed: %v", err)
 	}
 
-	got, err = store.GetFeed(feed.ID)
+	got, err = store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -401,11 +414,11 @@ func TestFeedFetchState(t *testing.T) {
 	}
 
 	// Update with another error
-	if err := store.UpdateFeedError(feed.ID, "another error"); err != nil {
+	if err := store.UpdateFeedError(context.Background(), feed.ID, "another error"); err != nil {
 		t.Fatalf("UpdateFeedError failed: %v", err)
 	}
 
-	got, err = store.GetFeed(feed.ID)
+	got, err = store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed failed: %v", err)
 	}
@@ -420,19 +433,19 @@ func TestCascadeDelete(t *testing.T) {
 
 	// Create feed with entries
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	for i := 0; i < 5; i++ {
 		entry := models.NewEntry(feed.ID, "guid-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	// Verify entries exist
-	entries, err := store.ListEntries(&EntryFilter{FeedID: &feed.ID})
+	entries, err := store.ListEntries(context.Background(), &EntryFilter{FeedID: &feed.ID})
 	if err != nil {
 		t.Fatalf("ListEntries failed: %v", err)
 	}
@@ -441,12 +454,12 @@ func TestCascadeDelete(t *testing.T) {
 	}
 
 	// Delete feed
-	if err := store.DeleteFeed(feed.ID); err != nil {
+	if err := store.DeleteFeed(context.Background(), feed.ID); err != nil {
 		t.Fatalf("DeleteFeed failed: %v", err)
 	}
 
 	// Verify entries are gone
-	entries, err = store.ListEntries(nil)
+	entries, err = store.ListEntries(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("ListEntries after delete failed: %v", err)
 	}
@@ -455,6 +468,47 @@ func TestCascadeDelete(t *testing.T) {
 	}
 }
 
+func TestMoveEntry(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	src := models.NewFeed("https://old.example.com/feed.xml")
+	dst := models.NewFeed("https://new.example.com/feed.xml")
+	for _, feed := range []*models.Feed{src, dst} {
+		if err := store.CreateFeed(context.Background(), feed); err != nil {
+			t.Fatalf("CreateFeed failed: %v", err)
+		}
+	}
+	entry := models.NewEntry(src.ID, "guid-move", "Moving")
+	entry.MarkRead()
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
+		t.Fatalf("CreateEntry failed: %v", err)
+	}
+
+	if err := store.MoveEntry(context.Background(), entry.ID, dst.ID); err != nil {
+		t.Fatalf("MoveEntry failed: %v", err)
+	}
+
+	got, err := store.GetEntry(context.Background(), entry.ID)
+	if err != nil {
+		t.Fatalf("GetEntry after move failed: %v", err)
+	}
+	if got.FeedID != dst.ID || !got.Read {
+		t.Errorf("expected the entry under the new feed with read state kept, got feed %s read %v", got.FeedID, got.Read)
+	}
+	left, err := store.ListEntries(context.Background(), &EntryFilter{FeedID: &src.ID})
+	if err != nil {
+		t.Fatalf("ListEntries failed: %v", err)
+	}
+	if len(left) != 0 {
+		t.Errorf("expected no entries left in the source feed, got %d", len(left))
+	}
+
+	if err := store.MoveEntry(context.Background(), "missing", dst.ID); err == nil {
+		t.Error("expected error moving a missing entry")
+	}
+}
+
 func TestStats(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
@@ -463,39 +517,39 @@ func TestStats(t *testing.T) {
 	feed1 := models.NewFeed("https://example1.com/feed.xml")
 	title1 := "Feed 1"
 	feed1.Title = &title1
-	if err := store.CreateFeed(feed1); err != nil {
+	if err := store.CreateFeed(context.Background(), feed1); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	feed2 := models.NewFeed("https://example2.com/feed.xml")
 	title2 := "Feed 2"
 	feed2.Title = &title2
-	if err := store.CreateFeed(feed2); err != nil {
+	if err := store.CreateFeed(context.Background(), feed2); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Create entries
 	for i := 0; i < 3; i++ {
 		entry := models.NewEntry(feed1.ID, "guid-1-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	for i := 0; i < 2; i++ {
 		entry := models.NewEntry(feed2.ID, "guid-2-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 		if i == 0 {
-			if err := store.MarkEntryRead(entry.ID); err != nil {
+			if err := store.MarkEntryRead(context.Background(), entry.ID); err != nil {
 				t.Fatalf("MarkEntryRead failed: %v", err)
 			}
 		}
 	}
 
 	// Check overall stats
-	overall, err := store.GetOverallStats()
+	overall, err := store.GetOverallStats(context.Background())
 	if err != nil {
 		t.Fatalf("GetOverallStats failed: %v", err)
 	}
@@ -510,13 +564,18 @@ func TestStats(t *testing.T) {
 	}
 
 	// Check feed stats
-	feedStats, err := store.GetFeedStats()
+	feedStats, err := store.GetFeedStats(context.Background())
 	if err != nil {
 		t.Fatalf("GetFeedStats failed: %v", err)
 	}
 	if len(feedStats) != 2 {
 		t.Errorf("expected 2 feed stats, got %d", len(feedStats))
 	}
+	for _, row := range feedStats {
+		if row.LastEntryAt == nil {
+			t.Errorf("expected LastEntryAt for %s", row.FeedURL)
+		}
+	}
 }
 
 func TestSearch(t *testing.T) {
@@ -525,7 +584,7 @@ func TestSearch(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -533,26 +592,26 @@ func TestSearch(t *testing.T) {
 	entry1 := models.NewEntry(feed.ID, "guid-1", "Golang Tutorial")
 	content1 := "Learn how to build web applications with Go"
 	entry1.Content = &content1
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry2 := models.NewEntry(feed.ID, "guid-2", "Python Basics")
 	content2 := "Introduction to Python programming"
 	entry2.Content = &content2
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry3 := models.NewEntry(feed.ID, "guid-3", "Web Development")
 	content3 := "Building modern web apps with golang"
 	entry3.Content = &content3
-	if err := store.CreateEntry(entry3); err != nil {
+	if err := store.CreateEntry(context.Background(), entry3); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Search for "golang"
-	results, err := store.Search("golang", 10)
+	results, err := store.Search(context.Background(), "golang", 10)
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -561,7 +620,7 @@ func TestSearch(t *testing.T) {
 	}
 
 	// Search for "python"
-	results, err = store.Search("python", 10)
+	results, err = store.Search(context.Background(), "python", 10)
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -570,12 +629,48 @@ func TestSearch(t *testing.T) {
 	}
 }
 
+func TestSearch_QuerySyntax(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed failed: %v", err)
+	}
+	for _, title := range []string{"Kubernetes in production", "Production kitchens", "Notes from last-week's meetup"} {
+		if err := store.CreateEntry(ctx, models.NewEntry(feed.ID, title, title)); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+
+	for query, want := range map[string]int{
+		"kube*":                     1, // Prefix
+		`"in production"`:           1, // Phrase
+		"kubernetes OR kitchens":    2,
+		"production NOT kitchens":   1,
+		"last-week":                 1, // Not FTS5 syntax: searched as a phrase
+		`"unbalanced (quote* OR`:    0,
+		"meetup: notes":             0,
+		"kubernetes in production.": 1,
+	} {
+		results, err := store.Search(ctx, query, 10)
+		if err != nil {
+			t.Errorf("Search(%q) failed: %v", query, err)
+			continue
+		}
+		if len(results) != want {
+			t.Errorf("Search(%q) found %d, want %d", query, len(results), want)
+		}
+	}
+}
+
 func TestCompact(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
 
 	// Just verify it doesn't error
-	if err := store.Compact(); err != nil {
+	if err := store.Compact(context.Background()); err != nil {
 		t.Fatalf("Compact failed: %v", err)
 	}
 }
@@ -586,12 +681,12 @@ func TestGetByIDOrPrefix(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Test GetFeedByURLOrPrefix with URL
-	got, err := store.GetFeedByURLOrPrefix("https://example.com/feed.xml")
+	got, err := store.GetFeedByURLOrPrefix(context.Background(), "https://example.com/feed.xml")
 	if err != nil {
 		t.Fatalf("GetFeedByURLOrPrefix with URL failed: %v", err)
 	}
@@ -600,7 +695,7 @@ func TestGetByIDOrPrefix(t *testing.T) {
 	}
 
 	// Test GetFeedByURLOrPrefix with prefix
-	got, err = store.GetFeedByURLOrPrefix(feed.ID[:8])
+	got, err = store.GetFeedByURLOrPrefix(context.Background(), feed.ID[:8])
 	if err != nil {
 		t.Fatalf("GetFeedByURLOrPrefix with prefix failed: %v", err)
 	}
@@ -610,12 +705,12 @@ func TestGetByIDOrPrefix(t *testing.T) {
 
 	// Create entry
 	entry := models.NewEntry(feed.ID, "guid-1", "Test")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Test GetEntryByIDOrPrefix with full ID
-	gotEntry, err := store.GetEntryByIDOrPrefix(entry.ID)
+	gotEntry, err := store.GetEntryByIDOrPrefix(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntryByIDOrPrefix with ID failed: %v", err)
 	}
@@ -624,7 +719,7 @@ func TestGetByIDOrPrefix(t *testing.T) {
 	}
 
 	// Test GetEntryByIDOrPrefix with prefix
-	gotEntry, err = store.GetEntryByIDOrPrefix(entry.ID[:8])
+	gotEntry, err = store.GetEntryByIDOrPrefix(context.Background(), entry.ID[:8])
 	if err != nil {
 		t.Fatalf("GetEntryByIDOrPrefix with prefix failed: %v", err)
 	}
@@ -637,12 +732,12 @@ func TestPrefixTooShort(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
 
-	_, err := store.GetFeedByPrefix("abc")
+	_, err := store.GetFeedByPrefix(context.Background(), "abc")
 	if err == nil {
 		t.Error("expected error for prefix too short")
 	}
 
-	_, err = store.GetEntryByPrefix("abc")
+	_, err = store.GetEntryByPrefix(context.Background(), "abc")
 	if err == nil {
 		t.Error("expected error for prefix too short")
 	}
@@ -654,13 +749,13 @@ func TestUpdateEntry(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Create entry
 	entry := models.NewEntry(feed.ID, "guid-123", "Original Title")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
@@ -669,12 +764,26 @@ func TestUpdateEntry(t *testing.T) {
 	entry.Title = &newTitle
 	newContent := "Updated content"
 	entry.Content = &newContent
-	if err := store.UpdateEntry(entry); err != nil {
+	claimed := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry.ClaimedPublishedAt = &claimed
+	image := "https://example.com/hero.jpg"
+	entry.ImageURL = &image
+	thread := "https://news.ycombinator.com/item?id=1"
+	entry.DiscussionURL = &thread
+	extensions := models.Extensions{
+		"itunes": {"duration": {{Name: "duration", Value: "42:00"}}},
+		"media": {"group": {{Name: "group", Children: map[string][]models.Extension{
+			"content": {{Name: "content", Attrs: map[string]string{"url": "https://example.com/a.mp4", "medium": "video"}}},
+		}}}},
+	}
+	entry.Extensions = extensions
+	entry.ContentHash = "0123456789abcdef"
+	if err := store.UpdateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("UpdateEntry failed: %v", err)
 	}
 
 	// Verify update
-	got, err := store.GetEntry(entry.ID)
+	got, err := store.GetEntry(context.Background(), entry.ID)
 	if err != nil {
 		t.Fatalf("GetEntry failed: %v", err)
 	}
@@ -684,6 +793,21 @@ func TestUpdateEntry(t *testing.T) {
 	if got.Content == nil || *got.Content != newContent {
 		t.Errorf("Content mismatch: got %v, want %q", got.Content, newContent)
 	}
+	if got.ClaimedPublishedAt == nil || !got.ClaimedPublishedAt.Equal(claimed) {
+		t.Errorf("ClaimedPublishedAt mismatch: got %v, want %v", got.ClaimedPublishedAt, claimed)
+	}
+	if got.ImageURL == nil || *got.ImageURL != image {
+		t.Errorf("ImageURL mismatch: got %v, want %q", got.ImageURL, image)
+	}
+	if got.DiscussionURL == nil || *got.DiscussionURL != thread {
+		t.Errorf("DiscussionURL mismatch: got %v, want %q", got.DiscussionURL, thread)
+	}
+	if !reflect.DeepEqual(got.Extensions, extensions) {
+		t.Errorf("Extensions mismatch: got %+v, want %+v", got.Extensions, extensions)
+	}
+	if got.ContentHash != "0123456789abcdef" {
+		t.Errorf("ContentHash mismatch: got %q", got.ContentHash)
+	}
 }
 
 func TestNewFeedStorage(t *testing.T) {
@@ -772,7 +896,7 @@ func TestListEntriesUntil(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed("https://example.com/feed.xml")
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
@@ -783,20 +907,20 @@ func TestListEntriesUntil(t *testing.T) {
 
 	entry1 := models.NewEntry(feed.ID, "guid-1", "Old Article")
 	entry1.PublishedAt = &old
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry2 := models.NewEntry(feed.ID, "guid-2", "Recent Article")
 	entry2.PublishedAt = &recent
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Test until filter
 	cutoff := now.Add(-24 * time.Hour)
 	filter := &EntryFilter{Until: &cutoff}
-	result, err := store.ListEntries(filter)
+	result, err := store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries until failed: %v", err)
 	}
@@ -811,32 +935,32 @@ func TestCountUnreadEntriesByFeed(t *testing.T) {
 
 	// Create feeds
 	feed1 := models.NewFeed("https://example1.com/feed.xml")
-	if err := store.CreateFeed(feed1); err != nil {
+	if err := store.CreateFeed(context.Background(), feed1); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	feed2 := models.NewFeed("https://example2.com/feed.xml")
-	if err := store.CreateFeed(feed2); err != nil {
+	if err := store.CreateFeed(context.Background(), feed2); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Create entries
 	for i := 0; i < 3; i++ {
 		entry := models.NewEntry(feed1.ID, "guid-1-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	for i := 0; i < 2; i++ {
 		entry := models.NewEntry(feed2.ID, "guid-2-"+string(rune('0'+i)), "Article")
-		if err := store.CreateEntry(entry); err != nil {
+		if err := store.CreateEntry(context.Background(), entry); err != nil {
 			t.Fatalf("CreateEntry failed: %v", err)
 		}
 	}
 
 	// Count unread for specific feed
-	count, err := store.CountUnreadEntries(&feed1.ID)
+	count, err := store.CountUnreadEntries(context.Background(), &feed1.ID)
 	if err != nil {
 		t.Fatalf("CountUnreadEntries failed: %v", err)
 	}
@@ -844,7 +968,7 @@ func TestCountUnreadEntriesByFeed(t *testing.T) {
 		t.Errorf("expected 3 unread for feed1, got %d", count)
 	}
 
-	count, err = store.CountUnreadEntries(&feed2.ID)
+	count, err = store.CountUnreadEntries(context.Background(), &feed2.ID)
 	if err != nil {
 		t.Fatalf("CountUnreadEntries failed: %v", err)
 	}
@@ -853,45 +977,234 @@ func TestCountUnreadEntriesByFeed(t *testing.T) {
 	}
 }
 
+func TestListEntriesFirstSeen(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	checkFirstSeenListing(t, store)
+}
+
+// checkFirstSeenListing stores a recent post and a backfilled one fetched
+// later, then checks that first-seen listing and the latest-sync filter
+// follow fetch times rather than publish dates.
+func checkFirstSeenListing(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed failed: %v", err)
+	}
+
+	now := time.Now()
+	firstSync := now.Add(-2 * time.Hour)
+	published := now.Add(-3 * time.Hour)
+	backfilled := now.AddDate(-2, 0, 0)
+
+	recent := models.NewEntry(feed.ID, "guid-recent", "Recent Post")
+	recent.PublishedAt = &published
+	recent.CreatedAt = firstSync
+	old := models.NewEntry(feed.ID, "guid-old", "Backfilled Post")
+	old.PublishedAt = &backfilled
+	old.CreatedAt = now
+	for _, e := range []*models.Entry{recent, old} {
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+	if err := store.UpdateFeedFetchState(ctx, feed.ID, nil, nil, now); err != nil {
+		t.Fatalf("UpdateFeedFetchState failed: %v", err)
+	}
+
+	byPublished, err := store.ListEntries(ctx, &EntryFilter{})
+	if err != nil || len(byPublished) != 2 || byPublished[0].ID != recent.ID {
+		t.Fatalf("expected the recent post first by publish date, got %v (%v)", byPublished, err)
+	}
+	bySeen, err := store.ListEntries(ctx, &EntryFilter{FirstSeen: true})
+	if err != nil || len(bySeen) != 2 || bySeen[0].ID != old.ID {
+		t.Fatalf("expected the backfilled post first by first seen, got %v (%v)", bySeen, err)
+	}
+
+	hourAgo := now.Add(-time.Hour)
+	seenLately, err := store.ListEntries(ctx, &EntryFilter{Since: &hourAgo, FirstSeen: true})
+	if err != nil || len(seenLately) != 1 || seenLately[0].ID != old.ID {
+		t.Errorf("expected only the backfilled post first seen in the last hour, got %v (%v)", seenLately, err)
+	}
+
+	latest, err := store.ListEntries(ctx, &EntryFilter{LatestSync: true})
+	if err != nil || len(latest) != 1 || latest[0].ID != old.ID {
+		t.Errorf("expected only the post from the latest sync, got %v (%v)", latest, err)
+	}
+}
+
+func TestListEntriesByScore(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	checkScoreListing(t, store)
+}
+
+// checkScoreListing verifies MinScore filtering and ByScore ordering, with
+// unscored entries after scored ones.
+func checkScoreListing(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	feed := models.NewFeed("https://news.ycombinator.com/rss")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	newest, older, oldest := now.Add(-time.Hour), now.Add(-2*time.Hour), now.Add(-3*time.Hour)
+	points := func(n int) *int { return &n }
+
+	unscored := models.NewEntry(feed.ID, "guid-unscored", "Unscored")
+	unscored.PublishedAt = &newest
+	low := models.NewEntry(feed.ID, "guid-low", "Low")
+	low.PublishedAt = &older
+	low.Score, low.CommentCount, low.ScoredAt = points(12), points(3), &now
+	high := models.NewEntry(feed.ID, "guid-high", "High")
+	high.PublishedAt = &oldest
+	high.Score, high.CommentCount, high.ScoredAt = points(480), points(211), &now
+	for _, e := range []*models.Entry{unscored, low, high} {
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+
+	got, err := store.GetEntry(ctx, high.ID)
+	if err != nil {
+		t.Fatalf("GetEntry failed: %v", err)
+	}
+	if got.Score == nil || *got.Score != 480 || got.CommentCount == nil || *got.CommentCount != 211 {
+		t.Errorf("expected score 480 with 211 comments, got %v and %v", got.Score, got.CommentCount)
+	}
+	if got.ScoredAt == nil || !got.ScoredAt.Equal(now) {
+		t.Errorf("ScoredAt mismatch: got %v, want %v", got.ScoredAt, now)
+	}
+
+	byScore, err := store.ListEntries(ctx, &EntryFilter{ByScore: true})
+	if err != nil {
+		t.Fatalf("ListEntries failed: %v", err)
+	}
+	var order []string
+	for _, e := range byScore {
+		order = append(order, e.GetTitle())
+	}
+	if strings.Join(order, ",") != "High,Low,Unscored" {
+		t.Errorf("expected High,Low,Unscored by score, got %v", order)
+	}
+
+	popular, err := store.ListEntries(ctx, &EntryFilter{MinScore: points(100)})
+	if err != nil || len(popular) != 1 || popular[0].ID != high.ID {
+		t.Errorf("expected only the high scoring entry, got %v (%v)", popular, err)
+	}
+}
+
+func TestKeepUnread(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	checkKeepUnread(t, store)
+}
+
+// checkKeepUnread verifies that keeping an entry marks it unread, that bulk
+// marking skips it until it's released, and KeptOnly listing.
+func checkKeepUnread(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed failed: %v", err)
+	}
+
+	published := time.Now().UTC().Add(-48 * time.Hour)
+	kept := models.NewEntry(feed.ID, "guid-kept", "Kept")
+	kept.PublishedAt = &published
+	other := models.NewEntry(feed.ID, "guid-other", "Other")
+	other.PublishedAt = &published
+	for _, e := range []*models.Entry{kept, other} {
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+	}
+	if err := store.MarkEntryRead(ctx, kept.ID); err != nil {
+		t.Fatalf("MarkEntryRead failed: %v", err)
+	}
+
+	if err := store.SetEntryKeepUnread(ctx, kept.ID, true); err != nil {
+		t.Fatalf("SetEntryKeepUnread failed: %v", err)
+	}
+	got, err := store.GetEntry(ctx, kept.ID)
+	if err != nil {
+		t.Fatalf("GetEntry failed: %v", err)
+	}
+	if !got.KeepUnread || got.Read || got.ReadAt != nil {
+		t.Errorf("expected a kept, unread entry, got keep=%v read=%v", got.KeepUnread, got.Read)
+	}
+
+	listed, err := store.ListEntries(ctx, &EntryFilter{KeptOnly: true})
+	if err != nil || len(listed) != 1 || listed[0].ID != kept.ID {
+		t.Errorf("expected only the kept entry, got %v (%v)", listed, err)
+	}
+
+	count, err := store.MarkEntriesReadBefore(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("MarkEntriesReadBefore failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected bulk marking to skip the kept entry, marked %d", count)
+	}
+	if got, _ := store.GetEntry(ctx, kept.ID); got.Read {
+		t.Error("expected the kept entry to stay unread")
+	}
+
+	if err := store.SetEntryKeepUnread(ctx, kept.ID, false); err != nil {
+		t.Fatalf("SetEntryKeepUnread failed: %v", err)
+	}
+	if got, _ := store.GetEntry(ctx, kept.ID); got.KeepUnread || got.Read {
+		t.Errorf("expected a released, still unread entry, got keep=%v read=%v", got.KeepUnread, got.Read)
+	}
+	if count, err := store.MarkEntriesReadBefore(ctx, time.Now()); err != nil || count != 1 {
+		t.Errorf("expected the released entry to be bulk marked, got %d (%v)", count, err)
+	}
+}
+
 func TestListEntriesMultipleFeedIDs(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
 
 	// Create feeds
 	feed1 := models.NewFeed("https://example1.com/feed.xml")
-	if err := store.CreateFeed(feed1); err != nil {
+	if err := store.CreateFeed(context.Background(), feed1); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	feed2 := models.NewFeed("https://example2.com/feed.xml")
-	if err := store.CreateFeed(feed2); err != nil {
+	if err := store.CreateFeed(context.Background(), feed2); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	feed3 := models.NewFeed("https://example3.com/feed.xml")
-	if err := store.CreateFeed(feed3); err != nil {
+	if err := store.CreateFeed(context.Background(), feed3); err != nil {
 		t.Fatalf("CreateFeed failed: %v", err)
 	}
 
 	// Create entries
 	entry1 := models.NewEntry(feed1.ID, "guid-1", "Article 1")
-	if err := store.CreateEntry(entry1); err != nil {
+	if err := store.CreateEntry(context.Background(), entry1); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry2 := models.NewEntry(feed2.ID, "guid-2", "Article 2")
-	if err := store.CreateEntry(entry2); err != nil {
+	if err := store.CreateEntry(context.Background(), entry2); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	entry3 := models.NewEntry(feed3.ID, "guid-3", "Article 3")
-	if err := store.CreateEntry(entry3); err != nil {
+	if err := store.CreateEntry(context.Background(), entry3); err != nil {
 		t.Fatalf("CreateEntry failed: %v", err)
 	}
 
 	// Filter by multiple feed IDs
 	filter := &EntryFilter{FeedIDs: []string{feed1.ID, feed2.ID}}
-	result, err := store.ListEntries(filter)
+	result, err := store.ListEntries(context.Background(), filter)
 	if err != nil {
 		t.Fatalf("ListEntries with FeedIDs failed: %v", err)
 	}
@@ -916,11 +1229,11 @@ func TestSQLite_FeedLocalNetwork(t *testing.T) {
 	feed := NewFeed("http://192.168.1.50:8080/feed.xml")
 	feed.LocalNetwork = true
 
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("create feed: %v", err)
 	}
 
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("get feed: %v", err)
 	}
@@ -936,11 +1249,11 @@ func TestSQLite_FeedLocalNetworkDefaultFalse(t *testing.T) {
 
 	feed := NewFeed("https://example.com/feed.xml")
 
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("create feed: %v", err)
 	}
 
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("get feed: %v", err)
 	}
@@ -962,11 +1275,11 @@ func TestMigrateIdempotent(t *testing.T) {
 	// Verify the column still works by round-tripping a local_network feed
 	feed := NewFeed("http://10.0.0.1/feed.xml")
 	feed.LocalNetwork = true
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed after double-migrate: %v", err)
 	}
 
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed after double-migrate: %v", err)
 	}
@@ -975,6 +1288,28 @@ func TestMigrateIdempotent(t *testing.T) {
 	}
 }
 
+func TestSQLite_CancelledContext(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	if err := store.CreateFeed(context.Background(), NewFeed("https://example.com/feed.xml")); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := store.ListFeeds(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListFeeds with cancelled context: expected context.Canceled, got %v", err)
+	}
+	if _, err := store.ListEntries(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListEntries with cancelled context: expected context.Canceled, got %v", err)
+	}
+	if err := store.Compact(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Compact with cancelled context: expected context.Canceled, got %v", err)
+	}
+}
+
 func newTestStore(t *testing.T) *SQLiteStore {
 	t.Helper()
 	tmpDir := t.TempDir()
@@ -986,3 +1321,71 @@ func newTestStore(t *testing.T) *SQLiteStore {
 	}
 	return store
 }
+
+func TestSQLite_FeedSubscriptionSettings(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := NewFeed("https://example.com/private.xml")
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	user, pass := "reader", "hunter2"
+	archivedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	feed.Paused = true
+	feed.ArchivedAt = &archivedAt
+	feed.KeepActive = true
+	feed.Identity = models.IdentityLink
+	feed.SyncInterval = 90 * time.Minute
+	feed.MaxEntries = 25
+	feed.BackfillDays = 14
+	feed.BackfillLimit = 50
+	feed.Browser = true
+	feed.Monitor = true
+	pageText := "Plans\n\nBasic costs $10"
+	feed.PageText = &pageText
+	feed.AuthUsername = &user
+	feed.AuthPassword = &pass
+	if err := store.UpdateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("update feed: %v", err)
+	}
+
+	got, err := store.GetFeed(context.Background(), feed.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if !got.Paused {
+		t.Error("expected Paused=true after round-trip")
+	}
+	if got.ArchivedAt == nil || !got.ArchivedAt.Equal(archivedAt) {
+		t.Errorf("expected ArchivedAt=%v, got %v", archivedAt, got.ArchivedAt)
+	}
+	if !got.KeepActive {
+		t.Error("expected KeepActive=true after round-trip")
+	}
+	if got.Identity != models.IdentityLink {
+		t.Errorf("expected Identity=link, got %q", got.Identity)
+	}
+	if got.SyncInterval != 90*time.Minute {
+		t.Errorf("expected SyncInterval=90m, got %v", got.SyncInterval)
+	}
+	if got.MaxEntries != 25 {
+		t.Errorf("expected MaxEntries=25, got %d", got.MaxEntries)
+	}
+	if got.BackfillDays != 14 || got.BackfillLimit != 50 {
+		t.Errorf("expected backfill 14 days/50 entries, got %d/%d", got.BackfillDays, got.BackfillLimit)
+	}
+	if !got.Browser {
+		t.Error("expected Browser=true after round-trip")
+	}
+	if !got.Monitor || got.PageText == nil || *got.PageText != pageText {
+		t.Errorf("expected Monitor=true and PageText=%q after round-trip, got %v/%v", pageText, got.Monitor, got.PageText)
+	}
+	if got.AuthUsername == nil || *got.AuthUsername != user {
+		t.Errorf("expected AuthUsername=%q, got %v", user, got.AuthUsername)
+	}
+	if got.AuthPassword == nil || *got.AuthPassword != pass {
+		t.Errorf("expected AuthPassword to round-trip")
+	}
+}
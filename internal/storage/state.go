@@ -0,0 +1,179 @@
+// ABOUTME: Exports and imports read state alone, keyed by feed URL and entry GUID
+// ABOUTME: Lets read progress move between machines without copying the whole store
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/harper/digest/internal/feedurl"
+	"github.com/harper/digest/internal/models"
+)
+
+// StateVersion is the version of the read state file format.
+const StateVersion = 1
+
+// StateFile is the read state of every entry in a store. Entries are
+// grouped by feed URL and keyed by GUID, which stay the same across
+// machines while entry IDs don't.
+type StateFile struct {
+	Version    int                              `json:"version"`
+	ExportedAt time.Time                        `json:"exported_at"`
+	Feeds      map[string]map[string]EntryState `json:"feeds"`
+}
+
+// EntryState is the read state of one entry. ReadAt is a Unix timestamp in
+// seconds, omitted for unread entries.
+type EntryState struct {
+	Read   bool  `json:"read"`
+	ReadAt int64 `json:"read_at,omitempty"`
+}
+
+// StateImportOptions controls ImportState.
+type StateImportOptions struct {
+	// Exact also marks entries unread when the file says so. By default an
+	// import only marks entries read, so it never undoes local progress.
+	Exact bool
+
+	// DryRun counts the changes without making them.
+	DryRun bool
+}
+
+// StateImportSummary holds counts from a read state import.
+type StateImportSummary struct {
+	MarkedRead   int // Entries marked read
+	MarkedUnread int // Entries marked unread (Exact only)
+	Unchanged    int // Entries whose state already matched
+	Missing      int // Entries in the file that aren't in the store
+	MissingFeeds int // Feeds in the file that aren't followed here
+}
+
+// ExportState collects the read state of every entry in the store.
+func ExportState(ctx context.Context, s Store) (*StateFile, error) {
+	feeds, err := s.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list feeds: %w", err)
+	}
+
+	state := &StateFile{
+		Version:    StateVersion,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Feeds:      make(map[string]map[string]EntryState, len(feeds)),
+	}
+	for _, feed := range feeds {
+		feedID := feed.ID
+		entries, err := s.ListEntries(ctx, &EntryFilter{FeedID: &feedID})
+		if err != nil {
+			return nil, fmt.Errorf("list entries for %s: %w", feed.URL, err)
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		byGUID := make(map[string]EntryState, len(entries))
+		for _, entry := range entries {
+			byGUID[entry.GUID] = entryState(entry)
+		}
+		state.Feeds[feed.URL] = byGUID
+	}
+	return state, nil
+}
+
+func entryState(entry *models.Entry) EntryState {
+	es := EntryState{Read: entry.Read}
+	if entry.Read && entry.ReadAt != nil {
+		es.ReadAt = entry.ReadAt.Unix()
+	}
+	return es
+}
+
+// WriteState writes a read state file as JSON.
+func WriteState(w io.Writer, state *StateFile) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(state); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	return nil
+}
+
+// ReadState parses a read state file.
+func ReadState(r io.Reader) (*StateFile, error) {
+	var state StateFile
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("parse state file: %w", err)
+	}
+	if state.Version != StateVersion {
+		return nil, fmt.Errorf("unsupported state file version %d (expected %d)", state.Version, StateVersion)
+	}
+	return &state, nil
+}
+
+// ImportState applies a read state file to the store. Feeds are matched by
+// URL, tolerating http/https, www, and trailing-slash differences, and
+// entries by GUID. Entries not fetched here yet are counted as missing;
+// import again after a sync to pick them up.
+func ImportState(ctx context.Context, s Store, state *StateFile, opts StateImportOptions) (*StateImportSummary, error) {
+	feeds, err := s.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list feeds: %w", err)
+	}
+	byKey := make(map[string]*models.Feed, len(feeds))
+	for _, feed := range feeds {
+		byKey[feedurl.Key(feed.URL)] = feed
+	}
+
+	summary := &StateImportSummary{}
+	for url, entries := range state.Feeds {
+		feed := byKey[feedurl.Key(url)]
+		if feed == nil {
+			summary.MissingFeeds++
+			summary.Missing += len(entries)
+			continue
+		}
+
+		feedID := feed.ID
+		local, err := s.ListEntries(ctx, &EntryFilter{FeedID: &feedID})
+		if err != nil {
+			return summary, fmt.Errorf("list entries for %s: %w", feed.URL, err)
+		}
+		byGUID := make(map[string]*models.Entry, len(local))
+		for _, entry := range local {
+			byGUID[entry.GUID] = entry
+		}
+
+		for guid, es := range entries {
+			entry := byGUID[guid]
+			if entry == nil {
+				summary.Missing++
+				continue
+			}
+
+			switch {
+			case es.Read && !entry.Read:
+				entry.MarkRead()
+				if es.ReadAt > 0 {
+					readAt := time.Unix(es.ReadAt, 0)
+					entry.ReadAt = &readAt
+				}
+				summary.MarkedRead++
+			case !es.Read && entry.Read && opts.Exact:
+				entry.MarkUnread()
+				summary.MarkedUnread++
+			default:
+				summary.Unchanged++
+				continue
+			}
+
+			if opts.DryRun {
+				continue
+			}
+			if err := s.UpdateEntry(ctx, entry); err != nil {
+				return summary, fmt.Errorf("update entry %s: %w", entry.ID, err)
+			}
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Tests for exporting and importing read state between stores
+// ABOUTME: Verifies GUID matching across feed URL variants, read-only merging, and exact mode
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestExportImportState(t *testing.T) {
+	for name, newStore := range map[string]func(t *testing.T) Store{
+		"sqlite":   func(t *testing.T) Store { return newTestStore(t) },
+		"markdown": func(t *testing.T) Store { return newTestMarkdownStore(t) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// The machine the state comes from
+			src := newStore(t)
+			defer src.Close()
+			srcFeed := models.NewFeed("https://example.com/feed")
+			mustNoErr(t, src.CreateFeed(ctx, srcFeed))
+			readAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+			read := models.NewEntry(srcFeed.ID, "guid-read", "Read")
+			read.Read = true
+			read.ReadAt = &readAt
+			mustNoErr(t, src.CreateEntry(ctx, read))
+			mustNoErr(t, src.CreateEntry(ctx, models.NewEntry(srcFeed.ID, "guid-unread", "Unread")))
+			mustNoErr(t, src.CreateEntry(ctx, models.NewEntry(srcFeed.ID, "guid-elsewhere", "Only There")))
+
+			state, err := ExportState(ctx, src)
+			if err != nil {
+				t.Fatalf("ExportState: %v", err)
+			}
+			var buf bytes.Buffer
+			mustNoErr(t, WriteState(&buf, state))
+			state, err = ReadState(&buf)
+			if err != nil {
+				t.Fatalf("ReadState: %v", err)
+			}
+			if got := state.Feeds["https://example.com/feed"]["guid-read"]; !got.Read || got.ReadAt != readAt.Unix() {
+				t.Errorf("unexpected exported state %+v", got)
+			}
+
+			// The machine the state goes to follows the feed under another URL
+			dst := newStore(t)
+			defer dst.Close()
+			dstFeed := models.NewFeed("http://www.example.com/feed/")
+			mustNoErr(t, dst.CreateFeed(ctx, dstFeed))
+			toRead := models.NewEntry(dstFeed.ID, "guid-read", "Read")
+			mustNoErr(t, dst.CreateEntry(ctx, toRead))
+			readHere := models.NewEntry(dstFeed.ID, "guid-unread", "Unread")
+			readHere.MarkRead()
+			mustNoErr(t, dst.CreateEntry(ctx, readHere))
+
+			summary, err := ImportState(ctx, dst, state, StateImportOptions{DryRun: true})
+			if err != nil {
+				t.Fatalf("ImportState dry run: %v", err)
+			}
+			if summary.MarkedRead != 1 || summary.Missing != 1 {
+				t.Errorf("unexpected dry run summary %+v", summary)
+			}
+			if got, _ := dst.GetEntry(ctx, toRead.ID); got.Read {
+				t.Error("dry run should not change entries")
+			}
+
+			summary, err = ImportState(ctx, dst, state, StateImportOptions{})
+			if err != nil {
+				t.Fatalf("ImportState: %v", err)
+			}
+			if summary.MarkedRead != 1 || summary.MarkedUnread != 0 || summary.Unchanged != 1 || summary.Missing != 1 {
+				t.Errorf("unexpected summary %+v", summary)
+			}
+			got, _ := dst.GetEntry(ctx, toRead.ID)
+			if !got.Read || got.ReadAt == nil || !got.ReadAt.Equal(readAt) {
+				t.Errorf("expected entry read at %v, got read=%v at %v", readAt, got.Read, got.ReadAt)
+			}
+			if got, _ := dst.GetEntry(ctx, readHere.ID); !got.Read {
+				t.Error("a default import should not mark entries unread")
+			}
+
+			summary, err = ImportState(ctx, dst, state, StateImportOptions{Exact: true})
+			if err != nil {
+				t.Fatalf("ImportState exact: %v", err)
+			}
+			if summary.MarkedUnread != 1 || summary.MarkedRead != 0 {
+				t.Errorf("unexpected exact summary %+v", summary)
+			}
+			if got, _ := dst.GetEntry(ctx, readHere.ID); got.Read {
+				t.Error("an exact import should mark the entry unread")
+			}
+		})
+	}
+}
+
+func TestReadStateRejectsUnknownVersion(t *testing.T) {
+	if _, err := ReadState(strings.NewReader(`{"version": 99, "feeds": {}}`)); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+	if _, err := ReadState(strings.NewReader(`not json`)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
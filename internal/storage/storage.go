@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"context"
 	"time"
 
 	"github.com/harper/digest/internal/models"
@@ -18,8 +19,44 @@ type EntryFilter struct {
 	Until      *time.Time
 	Limit      *int
 	Offset     *int
+
+	// FirstSeen filters and orders by when entries were first stored
+	// rather than when they claim to be published, so posts a feed
+	// backfills don't flood recent views.
+	FirstSeen bool
+
+	// LatestSync keeps only entries stored by their feed's most recent
+	// successful sync.
+	LatestSync bool
+
+	// MinScore keeps only entries with an aggregator score of at least
+	// this many points.
+	MinScore *int
+
+	// ByScore orders entries by aggregator score, highest first, with
+	// unscored entries last; ties fall back to the date order.
+	ByScore bool
+
+	// ByPriority orders entries by their feed's priority, high first and
+	// low last, ahead of the date or score order.
+	ByPriority bool
+
+	// KeptOnly keeps only entries pinned with KeepUnread.
+	KeptOnly bool
+
+	// NoContent leaves entries' Content nil, for callers that only need
+	// their metadata and would rather not hold every body in memory.
+	NoContent bool
 }
 
+// QueryTimeout bounds a single store operation so a hung query or a huge
+// scan can't wedge a long-running caller such as the MCP server.
+const QueryTimeout = 30 * time.Second
+
+// MaintenanceTimeout bounds VACUUM, index rebuilds, and integrity checks,
+// which legitimately take longer than ordinary queries.
+const MaintenanceTimeout = 10 * time.Minute
+
 // FeedStatsRow represents statistics for a single feed.
 type FeedStatsRow struct {
 	FeedID        string
@@ -30,6 +67,8 @@ type FeedStatsRow struct {
 	LastError     *string
 	EntryCount    int
 	UnreadCount   int
+	LastEntryAt   *time.Time // When the newest stored entry was first seen
+	ArchivedAt    *time.Time
 }
 
 // OverallStats represents overall statistics.
@@ -40,6 +79,8 @@ type OverallStats struct {
 }
 
 // Store defines the storage interface for digest data.
+// Every operation takes a context; implementations bound each call with
+// QueryTimeout and return the context's error if it is cancelled.
 type Store interface {
 	// Close closes the store and releases resources.
 	Close() error
@@ -47,90 +88,108 @@ type Store interface {
 	// Feed Operations
 
 	// CreateFeed stores a new feed.
-	CreateFeed(feed *models.Feed) error
+	CreateFeed(ctx context.Context, feed *models.Feed) error
 
 	// GetFeed retrieves a feed by ID.
-	GetFeed(id string) (*models.Feed, error)
+	GetFeed(ctx context.Context, id string) (*models.Feed, error)
 
 	// GetFeedByURL finds a feed by its URL.
-	GetFeedByURL(url string) (*models.Feed, error)
+	GetFeedByURL(ctx context.Context, url string) (*models.Feed, error)
 
 	// GetFeedByPrefix finds a feed by ID prefix (min 6 chars).
-	GetFeedByPrefix(prefix string) (*models.Feed, error)
+	GetFeedByPrefix(ctx context.Context, prefix string) (*models.Feed, error)
 
 	// ListFeeds returns all feeds, sorted by creation date (newest first).
-	ListFeeds() ([]*models.Feed, error)
+	ListFeeds(ctx context.Context) ([]*models.Feed, error)
 
 	// UpdateFeed updates an existing feed.
-	UpdateFeed(feed *models.Feed) error
+	UpdateFeed(ctx context.Context, feed *models.Feed) error
 
 	// DeleteFeed removes a feed and all its entries (cascade).
-	DeleteFeed(id string) error
+	DeleteFeed(ctx context.Context, id string) error
 
 	// UpdateFeedFetchState updates feed caching headers and clears errors.
-	UpdateFeedFetchState(feedID string, etag, lastModified *string, fetchedAt time.Time) error
+	UpdateFeedFetchState(ctx context.Context, feedID string, etag, lastModified *string, fetchedAt time.Time) error
 
 	// UpdateFeedError records a fetch error for a feed.
-	UpdateFeedError(feedID string, errMsg string) error
+	UpdateFeedError(ctx context.Context, feedID string, errMsg string) error
 
 	// Entry Operations
 
 	// CreateEntry stores a new entry.
-	CreateEntry(entry *models.Entry) error
+	CreateEntry(ctx context.Context, entry *models.Entry) error
 
 	// GetEntry retrieves an entry by ID.
-	GetEntry(id string) (*models.Entry, error)
+	GetEntry(ctx context.Context, id string) (*models.Entry, error)
 
 	// GetEntryByPrefix finds an entry by ID prefix (min 6 chars).
-	GetEntryByPrefix(prefix string) (*models.Entry, error)
+	GetEntryByPrefix(ctx context.Context, prefix string) (*models.Entry, error)
 
 	// ListEntries returns entries matching the filter, sorted by published date.
-	ListEntries(filter *EntryFilter) ([]*models.Entry, error)
+	ListEntries(ctx context.Context, filter *EntryFilter) ([]*models.Entry, error)
+
+	// EachEntry calls fn with each entry ListEntries would return, in the
+	// same order, without holding them all in memory, and stops at the
+	// first error fn returns. fn may use the store. Each query it makes is
+	// bounded by QueryTimeout rather than the whole walk.
+	EachEntry(ctx context.Context, filter *EntryFilter, fn func(*models.Entry) error) error
 
 	// UpdateEntry updates an existing entry.
-	UpdateEntry(entry *models.Entry) error
+	UpdateEntry(ctx context.Context, entry *models.Entry) error
 
 	// DeleteEntry removes an entry.
-	DeleteEntry(id string) error
+	DeleteEntry(ctx context.Context, id string) error
+
+	// MoveEntry reassigns an entry to another feed, keeping its ID and read state.
+	MoveEntry(ctx context.Context, id, feedID string) error
 
 	// MarkEntryRead marks an entry as read.
-	MarkEntryRead(id string) error
+	MarkEntryRead(ctx context.Context, id string) error
 
 	// MarkEntryUnread marks an entry as unread.
-	MarkEntryUnread(id string) error
+	MarkEntryUnread(ctx context.Context, id string) error
+
+	// SetEntryKeepUnread pins an entry unread, marking it unread if it was
+	// read, or releases the pin.
+	SetEntryKeepUnread(ctx context.Context, id string, keep bool) error
 
-	// MarkEntriesReadBefore marks all unread entries before the given time as read.
-	MarkEntriesReadBefore(before time.Time) (int64, error)
+	// MarkEntriesReadBefore marks all unread entries before the given time
+	// as read, except those kept unread.
+	MarkEntriesReadBefore(ctx context.Context, before time.Time) (int64, error)
 
 	// EntryExists checks if an entry exists with the given feed_id and guid.
-	EntryExists(feedID, guid string) (bool, error)
+	EntryExists(ctx context.Context, feedID, guid string) (bool, error)
 
 	// CountUnreadEntries counts unread entries, optionally filtered by feedID.
-	CountUnreadEntries(feedID *string) (int, error)
+	CountUnreadEntries(ctx context.Context, feedID *string) (int, error)
+
+	// GetChangesSince returns entries created or changed after cursor, up to
+	// limit (0 for no limit). An empty cursor starts before every change.
+	GetChangesSince(ctx context.Context, cursor string, limit int) (*ChangeSet, error)
 
 	// Statistics
 
 	// GetFeedStats retrieves statistics for all feeds.
-	GetFeedStats() ([]FeedStatsRow, error)
+	GetFeedStats(ctx context.Context) ([]FeedStatsRow, error)
 
 	// GetOverallStats retrieves overall statistics.
-	GetOverallStats() (*OverallStats, error)
+	GetOverallStats(ctx context.Context) (*OverallStats, error)
 
 	// Retrieval helpers
 
 	// GetEntryByIDOrPrefix tries to get an entry by exact ID first,
 	// then falls back to prefix matching if not found.
-	GetEntryByIDOrPrefix(ref string) (*models.Entry, error)
+	GetEntryByIDOrPrefix(ctx context.Context, ref string) (*models.Entry, error)
 
 	// GetFeedByURLOrPrefix tries to get a feed by exact URL first,
 	// then falls back to prefix matching if not found.
-	GetFeedByURLOrPrefix(ref string) (*models.Feed, error)
+	GetFeedByURLOrPrefix(ctx context.Context, ref string) (*models.Feed, error)
 
 	// Maintenance
 
 	// Compact performs database maintenance (VACUUM).
-	Compact() error
+	Compact(ctx context.Context) error
 
 	// Search performs full-text search on entries.
-	Search(query string, limit int) ([]*models.Entry, error)
+	Search(ctx context.Context, query string, limit int) ([]*models.Entry, error)
 }
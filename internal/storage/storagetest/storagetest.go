@@ -0,0 +1,698 @@
+// ABOUTME: Contract suite every storage backend must pass, so backends can't drift apart
+// ABOUTME: Backend tests call Run with a function opening an empty store; failures name the broken rule
+
+// Package storagetest checks that a storage.Store behaves the way the rest
+// of digest relies on. A backend's tests call Run:
+//
+//	func TestContract(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.Store {
+//			return openEmptyStore(t)
+//		})
+//	}
+//
+// and 'go test -run TestContract/<backend>' narrows a run to one backend.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// Opener opens an empty store for one test. Run closes it.
+type Opener func(t *testing.T) storage.Store
+
+// contract is one rule of the suite.
+type contract struct {
+	name string
+	run  func(t *testing.T, s storage.Store)
+}
+
+var contracts = []contract{
+	{"Feeds", testFeeds},
+	{"FeedFetchState", testFeedFetchState},
+	{"DeleteFeedCascades", testDeleteFeedCascades},
+	{"Entries", testEntries},
+	{"ListEntriesOrder", testListEntriesOrder},
+	{"ListEntriesFilters", testListEntriesFilters},
+	{"ListEntriesTies", testListEntriesTies},
+	{"ListEntriesByPriority", testListEntriesByPriority},
+	{"NoContent", testNoContent},
+	{"EachEntry", testEachEntry},
+	{"EachEntryStops", testEachEntryStops},
+	{"ReadState", testReadState},
+	{"KeepUnread", testKeepUnread},
+	{"MarkEntriesReadBefore", testMarkEntriesReadBefore},
+	{"MoveEntry", testMoveEntry},
+	{"Stats", testStats},
+	{"Search", testSearch},
+	{"CancelledContext", testCancelledContext},
+}
+
+// Run runs every contract against a fresh store from open.
+func Run(t *testing.T, open Opener) {
+	t.Helper()
+	for _, c := range contracts {
+		t.Run(c.name, func(t *testing.T) {
+			s := open(t)
+			defer s.Close()
+			c.run(t, s)
+		})
+	}
+}
+
+// base is a fixed time well in the past, so entries' dates are exact and
+// never in the future.
+var base = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func ptr[T any](v T) *T { return &v }
+
+func addFeed(t *testing.T, s storage.Store, url string, created time.Time) *models.Feed {
+	t.Helper()
+	f := models.NewFeed(url)
+	f.CreatedAt = created
+	if err := s.CreateFeed(context.Background(), f); err != nil {
+		t.Fatalf("CreateFeed(%s): %v", url, err)
+	}
+	return f
+}
+
+// addEntry stores an entry published and first seen at published.
+func addEntry(t *testing.T, s storage.Store, feedID, guid, title string, published time.Time) *models.Entry {
+	t.Helper()
+	e := models.NewEntry(feedID, guid, title)
+	e.PublishedAt = ptr(published)
+	e.CreatedAt = published
+	if err := s.CreateEntry(context.Background(), e); err != nil {
+		t.Fatalf("CreateEntry(%s): %v", guid, err)
+	}
+	return e
+}
+
+func mustEntry(t *testing.T, s storage.Store, id string) *models.Entry {
+	t.Helper()
+	e, err := s.GetEntry(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetEntry(%s): %v", id, err)
+	}
+	return e
+}
+
+func ids(entries []*models.Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.GUID
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func list(t *testing.T, s storage.Store, filter *storage.EntryFilter) []string {
+	t.Helper()
+	entries, err := s.ListEntries(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	return ids(entries)
+}
+
+func testFeeds(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	older := addFeed(t, s, "https://example.com/older.xml", base)
+	newer := addFeed(t, s, "https://example.com/newer.xml", base.Add(time.Hour))
+
+	got, err := s.GetFeed(ctx, older.ID)
+	if err != nil || got.URL != older.URL {
+		t.Fatalf("GetFeed = %+v, %v", got, err)
+	}
+	if got, err := s.GetFeedByURL(ctx, newer.URL); err != nil || got.ID != newer.ID {
+		t.Errorf("GetFeedByURL = %+v, %v", got, err)
+	}
+	if got, err := s.GetFeedByPrefix(ctx, older.ID[:8]); err != nil || got.ID != older.ID {
+		t.Errorf("GetFeedByPrefix = %+v, %v", got, err)
+	}
+	if got, err := s.GetFeedByURLOrPrefix(ctx, newer.URL); err != nil || got.ID != newer.ID {
+		t.Errorf("GetFeedByURLOrPrefix(url) = %+v, %v", got, err)
+	}
+	if _, err := s.GetFeed(ctx, "no-such-feed"); err == nil {
+		t.Error("GetFeed of a missing feed should fail")
+	}
+	if _, err := s.GetFeedByURL(ctx, "https://example.com/missing.xml"); err == nil {
+		t.Error("GetFeedByURL of a missing feed should fail")
+	}
+	if err := s.CreateFeed(ctx, models.NewFeed(older.URL)); err == nil {
+		t.Error("CreateFeed with a duplicate URL should fail")
+	}
+
+	feeds, err := s.ListFeeds(ctx)
+	if err != nil {
+		t.Fatalf("ListFeeds: %v", err)
+	}
+	if len(feeds) != 2 || feeds[0].ID != newer.ID || feeds[1].ID != older.ID {
+		t.Errorf("ListFeeds should be newest first, got %d feed(s)", len(feeds))
+	}
+
+	got.Title = ptr("Renamed")
+	got.Paused = true
+	got.MaxEntries = 5
+	got.Priority = models.PriorityHigh
+	if err := s.UpdateFeed(ctx, got); err != nil {
+		t.Fatalf("UpdateFeed: %v", err)
+	}
+	got, _ = s.GetFeed(ctx, older.ID)
+	if got.Title == nil || *got.Title != "Renamed" || !got.Paused || got.MaxEntries != 5 || got.Priority != models.PriorityHigh {
+		t.Errorf("UpdateFeed didn't persist, got %+v", got)
+	}
+}
+
+func testFeedFetchState(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+
+	if err := s.UpdateFeedError(ctx, f.ID, "boom"); err != nil {
+		t.Fatalf("UpdateFeedError: %v", err)
+	}
+	if err := s.UpdateFeedError(ctx, f.ID, "boom again"); err != nil {
+		t.Fatalf("UpdateFeedError: %v", err)
+	}
+	got, _ := s.GetFeed(ctx, f.ID)
+	if got.LastError == nil || *got.LastError != "boom again" || got.ErrorCount != 2 {
+		t.Errorf("UpdateFeedError should record the error and count, got %v, %d", got.LastError, got.ErrorCount)
+	}
+
+	fetched := base.Add(time.Hour)
+	if err := s.UpdateFeedFetchState(ctx, f.ID, ptr(`"etag"`), ptr("Fri, 01 Mar 2024 13:00:00 GMT"), fetched); err != nil {
+		t.Fatalf("UpdateFeedFetchState: %v", err)
+	}
+	got, _ = s.GetFeed(ctx, f.ID)
+	if got.ETag == nil || *got.ETag != `"etag"` || got.LastModified == nil || got.LastFetchedAt == nil || !got.LastFetchedAt.Equal(fetched) {
+		t.Errorf("UpdateFeedFetchState should store the cache headers and time, got %+v", got)
+	}
+	if got.LastError != nil || got.ErrorCount != 0 {
+		t.Errorf("UpdateFeedFetchState should clear errors, got %v, %d", got.LastError, got.ErrorCount)
+	}
+}
+
+func testDeleteFeedCascades(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	gone := addFeed(t, s, "https://example.com/gone.xml", base)
+	kept := addFeed(t, s, "https://example.com/kept.xml", base)
+	e := addEntry(t, s, gone.ID, "gone-1", "Gone", base)
+	addEntry(t, s, kept.ID, "kept-1", "Kept", base)
+
+	if err := s.DeleteFeed(ctx, gone.ID); err != nil {
+		t.Fatalf("DeleteFeed: %v", err)
+	}
+	if _, err := s.GetFeed(ctx, gone.ID); err == nil {
+		t.Error("a deleted feed should be gone")
+	}
+	if _, err := s.GetEntry(ctx, e.ID); err == nil {
+		t.Error("a deleted feed's entries should be gone")
+	}
+	if got := list(t, s, nil); !equal(got, []string{"kept-1"}) {
+		t.Errorf("other feeds' entries should stay, got %v", got)
+	}
+}
+
+func testEntries(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	e := models.NewEntry(f.ID, "guid-1", "Title")
+	e.Link = ptr("https://example.com/1")
+	e.Author = ptr("Ada")
+	e.Content = ptr("<p>Body</p>")
+	e.PublishedAt = ptr(base)
+	e.CreatedAt = base
+	if err := s.CreateEntry(ctx, e); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+
+	got := mustEntry(t, s, e.ID)
+	if got.FeedID != f.ID || got.GUID != "guid-1" || got.GetTitle() != "Title" || got.Link == nil || *got.Link != "https://example.com/1" ||
+		got.Author == nil || *got.Author != "Ada" || got.Content == nil || *got.Content != "<p>Body</p>" ||
+		got.PublishedAt == nil || !got.PublishedAt.Equal(base) || got.Read {
+		t.Errorf("GetEntry should return the entry as stored, got %+v", got)
+	}
+	if got, err := s.GetEntryByPrefix(ctx, e.ID[:8]); err != nil || got.ID != e.ID {
+		t.Errorf("GetEntryByPrefix = %+v, %v", got, err)
+	}
+	if got, err := s.GetEntryByIDOrPrefix(ctx, e.ID); err != nil || got.ID != e.ID {
+		t.Errorf("GetEntryByIDOrPrefix = %+v, %v", got, err)
+	}
+	if _, err := s.GetEntry(ctx, "no-such-entry"); err == nil {
+		t.Error("GetEntry of a missing entry should fail")
+	}
+
+	if ok, err := s.EntryExists(ctx, f.ID, "guid-1"); err != nil || !ok {
+		t.Errorf("EntryExists(guid-1) = %v, %v", ok, err)
+	}
+	if ok, err := s.EntryExists(ctx, f.ID, "guid-2"); err != nil || ok {
+		t.Errorf("EntryExists(guid-2) = %v, %v", ok, err)
+	}
+
+	got.Title = ptr("New Title")
+	if err := s.UpdateEntry(ctx, got); err != nil {
+		t.Fatalf("UpdateEntry: %v", err)
+	}
+	if got := mustEntry(t, s, e.ID); got.GetTitle() != "New Title" {
+		t.Errorf("UpdateEntry didn't persist, got %q", got.GetTitle())
+	}
+
+	if err := s.DeleteEntry(ctx, e.ID); err != nil {
+		t.Fatalf("DeleteEntry: %v", err)
+	}
+	if _, err := s.GetEntry(ctx, e.ID); err == nil {
+		t.Error("a deleted entry should be gone")
+	}
+	if ok, _ := s.EntryExists(ctx, f.ID, "guid-1"); ok {
+		t.Error("EntryExists should be false after DeleteEntry")
+	}
+}
+
+func testListEntriesOrder(t *testing.T, s storage.Store) {
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	addEntry(t, s, f.ID, "middle", "Middle", base.Add(time.Hour))
+	addEntry(t, s, f.ID, "oldest", "Oldest", base)
+	addEntry(t, s, f.ID, "newest", "Newest", base.Add(2*time.Hour))
+
+	if got := list(t, s, nil); !equal(got, []string{"newest", "middle", "oldest"}) {
+		t.Errorf("ListEntries should be newest published first, got %v", got)
+	}
+	if got := list(t, s, &storage.EntryFilter{Limit: ptr(1), Offset: ptr(1)}); !equal(got, []string{"middle"}) {
+		t.Errorf("Limit and Offset should page the newest-first order, got %v", got)
+	}
+}
+
+func testListEntriesFilters(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	a := addFeed(t, s, "https://example.com/a.xml", base)
+	b := addFeed(t, s, "https://example.com/b.xml", base)
+	c := addFeed(t, s, "https://example.com/c.xml", base)
+	addEntry(t, s, a.ID, "a-old", "A old", base)
+	read := addEntry(t, s, a.ID, "a-new", "A new", base.Add(2*time.Hour))
+	addEntry(t, s, b.ID, "b-1", "B", base.Add(time.Hour))
+	addEntry(t, s, c.ID, "c-1", "C", base.Add(3*time.Hour))
+	if err := s.MarkEntryRead(ctx, read.ID); err != nil {
+		t.Fatalf("MarkEntryRead: %v", err)
+	}
+
+	if got := list(t, s, &storage.EntryFilter{FeedID: &a.ID}); !equal(got, []string{"a-new", "a-old"}) {
+		t.Errorf("FeedID should keep one feed's entries, got %v", got)
+	}
+	if got := list(t, s, &storage.EntryFilter{FeedIDs: []string{a.ID, b.ID}}); !equal(got, []string{"a-new", "b-1", "a-old"}) {
+		t.Errorf("FeedIDs should keep those feeds' entries, got %v", got)
+	}
+	if got := list(t, s, &storage.EntryFilter{FeedID: &c.ID, FeedIDs: []string{b.ID}}); !equal(got, []string{"b-1"}) {
+		t.Errorf("FeedIDs should take precedence over FeedID, got %v", got)
+	}
+	if got := list(t, s, &storage.EntryFilter{UnreadOnly: ptr(true)}); !equal(got, []string{"c-1", "b-1", "a-old"}) {
+		t.Errorf("UnreadOnly should drop read entries, got %v", got)
+	}
+	since, until := base.Add(time.Hour), base.Add(3*time.Hour)
+	if got := list(t, s, &storage.EntryFilter{Since: &since, Until: &until}); !equal(got, []string{"a-new", "b-1"}) {
+		t.Errorf("Since should be inclusive and Until exclusive, got %v", got)
+	}
+}
+
+func testListEntriesTies(t *testing.T, s storage.Store) {
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	var want []*models.Entry
+	for _, guid := range []string{"t1", "t2", "t3", "t4"} {
+		want = append(want, addEntry(t, s, f.ID, guid, guid, base))
+	}
+	// Entries published together fall back to the highest ID first
+	slices.SortFunc(want, func(a, b *models.Entry) int { return strings.Compare(b.ID, a.ID) })
+	for range 3 {
+		if got := list(t, s, nil); !equal(got, ids(want)) {
+			t.Fatalf("ties should be ordered by ID, descending: got %v, want %v", got, ids(want))
+		}
+	}
+}
+
+func testListEntriesByPriority(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	high := addFeed(t, s, "https://example.com/high.xml", base)
+	normal := addFeed(t, s, "https://example.com/normal.xml", base)
+	low := addFeed(t, s, "https://example.com/low.xml", base)
+	high.Priority, low.Priority = models.PriorityHigh, models.PriorityLow
+	for _, f := range []*models.Feed{high, low} {
+		if err := s.UpdateFeed(ctx, f); err != nil {
+			t.Fatalf("UpdateFeed: %v", err)
+		}
+	}
+	addEntry(t, s, low.ID, "low", "Low", base.Add(3*time.Hour))
+	addEntry(t, s, normal.ID, "normal", "Normal", base.Add(2*time.Hour))
+	addEntry(t, s, high.ID, "high-old", "High old", base)
+	addEntry(t, s, high.ID, "high-new", "High new", base.Add(time.Hour))
+
+	if got := list(t, s, &storage.EntryFilter{ByPriority: true}); !equal(got, []string{"high-new", "high-old", "normal", "low"}) {
+		t.Errorf("ByPriority should order by feed priority, then newest first, got %v", got)
+	}
+	if got := list(t, s, nil); !equal(got, []string{"low", "normal", "high-new", "high-old"}) {
+		t.Errorf("without ByPriority the order should be by date alone, got %v", got)
+	}
+}
+
+func testNoContent(t *testing.T, s storage.Store) {
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	e := models.NewEntry(f.ID, "bodied", "Bodied")
+	e.Content = ptr("a long body")
+	e.PublishedAt = ptr(base)
+	if err := s.CreateEntry(context.Background(), e); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+
+	entries, err := s.ListEntries(context.Background(), &storage.EntryFilter{NoContent: true})
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Content != nil || entries[0].GetTitle() != "Bodied" {
+		t.Fatalf("NoContent should drop only the content, got %+v", entries)
+	}
+	if got := mustEntry(t, s, e.ID); got.Content == nil || *got.Content != "a long body" {
+		t.Error("NoContent listing should leave the stored content alone")
+	}
+}
+
+// each collects what EachEntry passes fn.
+func each(t *testing.T, s storage.Store, filter *storage.EntryFilter) []*models.Entry {
+	t.Helper()
+	var entries []*models.Entry
+	err := s.EachEntry(context.Background(), filter, func(e *models.Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachEntry: %v", err)
+	}
+	return entries
+}
+
+func testEachEntry(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	a := addFeed(t, s, "https://example.com/a.xml", base)
+	b := addFeed(t, s, "https://example.com/b.xml", base)
+	for i := range 12 {
+		feed := a
+		if i%3 == 0 {
+			feed = b
+		}
+		e := addEntry(t, s, feed.ID, fmt.Sprintf("e%02d", i), fmt.Sprintf("Entry %d", i), base.Add(time.Duration(i%5)*time.Hour))
+		e.Content = ptr(fmt.Sprintf("body %d", i))
+		if err := s.UpdateEntry(ctx, e); err != nil {
+			t.Fatalf("UpdateEntry: %v", err)
+		}
+	}
+
+	filters := map[string]*storage.EntryFilter{
+		"nil":      nil,
+		"feed":     {FeedID: &a.ID},
+		"page":     {Limit: ptr(4), Offset: ptr(3)},
+		"window":   {Since: ptr(base.Add(time.Hour)), Until: ptr(base.Add(4 * time.Hour))},
+		"unread":   {UnreadOnly: ptr(true), FeedIDs: []string{a.ID, b.ID}},
+		"noBodies": {NoContent: true},
+	}
+	for name, filter := range filters {
+		listed, err := s.ListEntries(ctx, filter)
+		if err != nil {
+			t.Fatalf("%s: ListEntries: %v", name, err)
+		}
+		walked := each(t, s, filter)
+		if !equal(ids(walked), ids(listed)) {
+			t.Errorf("%s: EachEntry should match ListEntries, got %v, want %v", name, ids(walked), ids(listed))
+			continue
+		}
+		for i := range walked {
+			if !reflect.DeepEqual(walked[i].Content, listed[i].Content) {
+				t.Errorf("%s: %s content = %v, want %v", name, walked[i].GUID, walked[i].Content, listed[i].Content)
+			}
+		}
+	}
+
+	// fn may change the store as it goes
+	err := s.EachEntry(ctx, &storage.EntryFilter{UnreadOnly: ptr(true)}, func(e *models.Entry) error {
+		return s.MarkEntryRead(ctx, e.ID)
+	})
+	if err != nil {
+		t.Fatalf("EachEntry marking read: %v", err)
+	}
+	if n, _ := s.CountUnreadEntries(ctx, nil); n != 0 {
+		t.Errorf("marking every entry read from fn left %d unread", n)
+	}
+}
+
+func testEachEntryStops(t *testing.T, s storage.Store) {
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	for i := range 3 {
+		addEntry(t, s, f.ID, fmt.Sprintf("s%d", i), "S", base.Add(time.Duration(i)*time.Hour))
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err := s.EachEntry(context.Background(), nil, func(*models.Entry) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("EachEntry should stop at fn's first error, got %v after %d call(s)", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.EachEntry(ctx, nil, func(*models.Entry) error { return nil }); err == nil {
+		t.Error("EachEntry with a cancelled context should fail")
+	}
+}
+
+func testReadState(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	e := addEntry(t, s, f.ID, "guid", "Entry", base)
+
+	if err := s.MarkEntryRead(ctx, e.ID); err != nil {
+		t.Fatalf("MarkEntryRead: %v", err)
+	}
+	got := mustEntry(t, s, e.ID)
+	if !got.Read || got.ReadAt == nil {
+		t.Errorf("MarkEntryRead should set Read and ReadAt, got %v, %v", got.Read, got.ReadAt)
+	}
+	if n, _ := s.CountUnreadEntries(ctx, nil); n != 0 {
+		t.Errorf("CountUnreadEntries after reading = %d, want 0", n)
+	}
+
+	if err := s.MarkEntryUnread(ctx, e.ID); err != nil {
+		t.Fatalf("MarkEntryUnread: %v", err)
+	}
+	got = mustEntry(t, s, e.ID)
+	if got.Read || got.ReadAt != nil {
+		t.Errorf("MarkEntryUnread should clear Read and ReadAt, got %v, %v", got.Read, got.ReadAt)
+	}
+	if n, _ := s.CountUnreadEntries(ctx, &f.ID); n != 1 {
+		t.Errorf("CountUnreadEntries(feed) = %d, want 1", n)
+	}
+	if err := s.MarkEntryRead(ctx, "no-such-entry"); err == nil {
+		t.Error("MarkEntryRead of a missing entry should fail")
+	}
+}
+
+func testKeepUnread(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	e := addEntry(t, s, f.ID, "guid", "Entry", base)
+	if err := s.MarkEntryRead(ctx, e.ID); err != nil {
+		t.Fatalf("MarkEntryRead: %v", err)
+	}
+
+	if err := s.SetEntryKeepUnread(ctx, e.ID, true); err != nil {
+		t.Fatalf("SetEntryKeepUnread: %v", err)
+	}
+	got := mustEntry(t, s, e.ID)
+	if !got.KeepUnread || got.Read {
+		t.Errorf("keeping a read entry should pin it unread, got keep=%v read=%v", got.KeepUnread, got.Read)
+	}
+
+	// Marking it read doesn't release the pin; callers release it first
+	if err := s.MarkEntryRead(ctx, e.ID); err != nil {
+		t.Fatalf("MarkEntryRead: %v", err)
+	}
+	got = mustEntry(t, s, e.ID)
+	if !got.KeepUnread || !got.Read {
+		t.Errorf("MarkEntryRead should read the entry and leave the pin, got keep=%v read=%v", got.KeepUnread, got.Read)
+	}
+
+	if err := s.MarkEntryUnread(ctx, e.ID); err != nil {
+		t.Fatalf("MarkEntryUnread: %v", err)
+	}
+	if err := s.SetEntryKeepUnread(ctx, e.ID, false); err != nil {
+		t.Fatalf("SetEntryKeepUnread(false): %v", err)
+	}
+	got = mustEntry(t, s, e.ID)
+	if got.KeepUnread || got.Read {
+		t.Errorf("releasing the pin should leave the entry unread, got keep=%v read=%v", got.KeepUnread, got.Read)
+	}
+}
+
+func testMarkEntriesReadBefore(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	addEntry(t, s, f.ID, "old", "Old", base)
+	kept := addEntry(t, s, f.ID, "old-kept", "Old kept", base.Add(time.Minute))
+	addEntry(t, s, f.ID, "new", "New", base.Add(2*time.Hour))
+	if err := s.SetEntryKeepUnread(ctx, kept.ID, true); err != nil {
+		t.Fatalf("SetEntryKeepUnread: %v", err)
+	}
+
+	n, err := s.MarkEntriesReadBefore(ctx, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("MarkEntriesReadBefore: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("MarkEntriesReadBefore marked %d, want 1", n)
+	}
+	if got := list(t, s, &storage.EntryFilter{UnreadOnly: ptr(true)}); !equal(got, []string{"new", "old-kept"}) {
+		t.Errorf("only unkept entries before the time should be read, unread now %v", got)
+	}
+}
+
+func testMoveEntry(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	from := addFeed(t, s, "https://example.com/from.xml", base)
+	to := addFeed(t, s, "https://example.com/to.xml", base)
+	e := addEntry(t, s, from.ID, "guid", "Entry", base)
+	if err := s.MarkEntryRead(ctx, e.ID); err != nil {
+		t.Fatalf("MarkEntryRead: %v", err)
+	}
+
+	if err := s.MoveEntry(ctx, e.ID, to.ID); err != nil {
+		t.Fatalf("MoveEntry: %v", err)
+	}
+	got := mustEntry(t, s, e.ID)
+	if got.FeedID != to.ID || !got.Read {
+		t.Errorf("MoveEntry should keep the ID and read state, got feed=%s read=%v", got.FeedID, got.Read)
+	}
+	if got := list(t, s, &storage.EntryFilter{FeedID: &from.ID}); len(got) != 0 {
+		t.Errorf("the old feed should have no entries, got %v", got)
+	}
+	if ok, _ := s.EntryExists(ctx, to.ID, "guid"); !ok {
+		t.Error("EntryExists should find the entry under its new feed")
+	}
+}
+
+func testStats(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	a := addFeed(t, s, "https://example.com/a.xml", base)
+	b := addFeed(t, s, "https://example.com/b.xml", base.Add(time.Hour))
+	addFeed(t, s, "https://example.com/empty.xml", base.Add(2*time.Hour))
+	read := addEntry(t, s, a.ID, "a-1", "A1", base)
+	addEntry(t, s, a.ID, "a-2", "A2", base)
+	addEntry(t, s, b.ID, "b-1", "B1", base)
+	if err := s.MarkEntryRead(ctx, read.ID); err != nil {
+		t.Fatalf("MarkEntryRead: %v", err)
+	}
+
+	overall, err := s.GetOverallStats(ctx)
+	if err != nil {
+		t.Fatalf("GetOverallStats: %v", err)
+	}
+	if overall.TotalFeeds != 3 || overall.TotalEntries != 3 || overall.UnreadCount != 2 {
+		t.Errorf("GetOverallStats = %+v, want 3 feeds, 3 entries, 2 unread", overall)
+	}
+
+	rows, err := s.GetFeedStats(ctx)
+	if err != nil {
+		t.Fatalf("GetFeedStats: %v", err)
+	}
+	byURL := make(map[string]storage.FeedStatsRow)
+	for _, r := range rows {
+		byURL[r.FeedURL] = r
+	}
+	if len(rows) != 3 {
+		t.Errorf("GetFeedStats should include feeds without entries, got %d row(s)", len(rows))
+	}
+	if r := byURL[a.URL]; r.EntryCount != 2 || r.UnreadCount != 1 {
+		t.Errorf("stats for a = %d entries, %d unread; want 2, 1", r.EntryCount, r.UnreadCount)
+	}
+	if r := byURL[b.URL]; r.EntryCount != 1 || r.UnreadCount != 1 {
+		t.Errorf("stats for b = %d entries, %d unread; want 1, 1", r.EntryCount, r.UnreadCount)
+	}
+	if n, _ := s.CountUnreadEntries(ctx, &a.ID); n != 1 {
+		t.Errorf("CountUnreadEntries(a) = %d, want 1", n)
+	}
+}
+
+func testSearch(t *testing.T, s storage.Store) {
+	f := addFeed(t, s, "https://example.com/feed.xml", base)
+	addEntry(t, s, f.ID, "title", "Kubernetes in Production", base)
+	body := models.NewEntry(f.ID, "body", "Unrelated")
+	body.Content = ptr("<p>We moved our KUBERNETES clusters last week.</p>")
+	body.PublishedAt = ptr(base.Add(time.Hour))
+	body.CreatedAt = base.Add(time.Hour)
+	if err := s.CreateEntry(context.Background(), body); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+	addEntry(t, s, f.ID, "other", "Gardening tips", base)
+
+	search := func(query string, limit int) []string {
+		t.Helper()
+		entries, err := s.Search(context.Background(), query, limit)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", query, err)
+		}
+		got := ids(entries)
+		// Ranking differs between backends; the set must not
+		if len(got) == 2 && got[0] > got[1] {
+			got[0], got[1] = got[1], got[0]
+		}
+		return got
+	}
+
+	for _, query := range []string{"kubernetes", "Kubernetes", "KUBERNETES", "kUbErNeTeS"} {
+		if got := search(query, 10); !equal(got, []string{"body", "title"}) {
+			t.Errorf("Search(%q) should match titles and content whatever the case, got %v", query, got)
+		}
+	}
+	if got := search("production", 10); !equal(got, []string{"title"}) {
+		t.Errorf("Search(production) = %v, want [title]", got)
+	}
+	if got := search("last week.", 10); !equal(got, []string{"body"}) {
+		t.Errorf("Search should find text with punctuation, got %v", got)
+	}
+	if got := search(`"unbalanced (quote* OR`, 10); len(got) != 0 {
+		t.Errorf("Search for unmatched text should find nothing, got %v", got)
+	}
+	if got := search("kubernetes", 1); len(got) != 1 {
+		t.Errorf("Search should honour its limit, got %v", got)
+	}
+	if got := search("nothing-like-this", 10); len(got) != 0 {
+		t.Errorf("Search for a missing word should find nothing, got %v", got)
+	}
+}
+
+func testCancelledContext(t *testing.T, s storage.Store) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.ListFeeds(ctx); err == nil {
+		t.Error("ListFeeds with a cancelled context should fail")
+	}
+	if _, err := s.ListEntries(ctx, nil); err == nil {
+		t.Error("ListEntries with a cancelled context should fail")
+	}
+}
@@ -0,0 +1,181 @@
+// ABOUTME: Per-user read state for stores shared by a team
+// ABOUTME: A user view sees the shared feeds and entries with its own read and keep-unread flags
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// ErrNoUserState is returned by ForUser for stores that can't keep read
+// state per user.
+var ErrNoUserState = errors.New("per-user read state needs the sqlite backend")
+
+// userNamePattern is what user names may look like: they end up in SQL and
+// secret names, so they're kept plain.
+var userNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ValidUserName reports whether name can be used as a user name.
+func ValidUserName(name string) bool {
+	return userNamePattern.MatchString(name)
+}
+
+// UserStore is implemented by stores that keep read state per user.
+type UserStore interface {
+	// ForUser returns a view of the store as user sees it. Feeds and entry
+	// contents are shared; read, read-at, and keep-unread are the user's
+	// own, and start out unread. Closing the view leaves the store open.
+	ForUser(user string) (Store, error)
+
+	// DeleteUserState forgets everything user has read or kept.
+	DeleteUserState(ctx context.Context, user string) error
+}
+
+// ForUser returns s as user sees it, or ErrNoUserState if s can't keep
+// per-user state.
+func ForUser(s Store, user string) (Store, error) {
+	us, ok := s.(UserStore)
+	if !ok {
+		return nil, ErrNoUserState
+	}
+	return us.ForUser(user)
+}
+
+// ForUser returns a view of the store with user's own read state.
+func (s *SQLiteStore) ForUser(user string) (Store, error) {
+	if !ValidUserName(user) {
+		return nil, fmt.Errorf("invalid user name %q", user)
+	}
+	return &SQLiteStore{db: s.db, path: s.path, user: user}, nil
+}
+
+// DeleteUserState forgets everything user has read or kept.
+func (s *SQLiteStore) DeleteUserState(ctx context.Context, user string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM entry_states WHERE user_id = ?", user); err != nil {
+		return fmt.Errorf("delete user state: %w", err)
+	}
+	return nil
+}
+
+// entryTable returns the table entry queries read from, named alias: the
+// entries table itself, or in a user view, entries with the user's read
+// state in place of the shared columns.
+func (s *SQLiteStore) entryTable(alias string) string {
+	if s.user == "" {
+		if alias == "entries" {
+			return "entries"
+		}
+		return "entries " + alias
+	}
+	// The user name is validated by ForUser, so it is safe to inline
+	return `(SELECT e.rowid AS rowid, e.id, e.feed_id, e.guid, e.title, e.link, e.author, e.published_at, e.content,
+			COALESCE(u.read, 0) AS read, u.read_at, e.archive_url, e.created_at, e.claimed_published_at, e.updated_at,
+			e.image_url, e.discussion_url, e.comments_feed_url, e.score, e.comment_count, e.scored_at,
+			COALESCE(u.keep_unread, 0) AS keep_unread, e.extensions, e.content_hash, e.change_seq
+		FROM entries e LEFT JOIN entry_states u ON u.entry_id = e.id AND u.user_id = '` + s.user + `') ` + alias
+}
+
+// setUserState writes the view's read state for entry id and bumps the
+// entry's change time so change feeds pick it up.
+func (s *SQLiteStore) setUserState(ctx context.Context, id string, read bool, readAt *time.Time, keep bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stamp := `UPDATE entries SET updated_at = ?, change_seq = ` + nextChangeSeq + ` WHERE id = ?`
+	result, err := tx.ExecContext(ctx, stamp, changeTime(), id)
+	if err != nil {
+		return fmt.Errorf("update entry: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("entry not found: %s", id)
+	}
+	query := `
+		INSERT INTO entry_states (user_id, entry_id, read, read_at, keep_unread) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, entry_id) DO UPDATE SET read = excluded.read, read_at = excluded.read_at, keep_unread = excluded.keep_unread
+	`
+	if _, err := tx.ExecContext(ctx, query, s.user, id, boolToInt(read), timeToSQL(readAt), boolToInt(keep)); err != nil {
+		return fmt.Errorf("set user state: %w", err)
+	}
+	return tx.Commit()
+}
+
+// userState reads the view's current read state for entry id.
+func (s *SQLiteStore) userState(ctx context.Context, id string) (read bool, readAt *time.Time, keep bool, err error) {
+	entry, err := s.GetEntry(ctx, id)
+	if err != nil {
+		return false, nil, false, fmt.Errorf("entry not found: %s", id)
+	}
+	return entry.Read, entry.ReadAt, entry.KeepUnread, nil
+}
+
+// updateUserEntry is UpdateEntry for a user view: the entry's contents are
+// shared, and its read state is the user's.
+func (s *SQLiteStore) updateUserEntry(ctx context.Context, entry *models.Entry) error {
+	query := `
+		UPDATE entries SET
+			title = ?, link = ?, author = ?, published_at = ?,
+			content = ?, archive_url = ?, claimed_published_at = ?,
+			image_url = ?, discussion_url = ?, comments_feed_url = ?,
+			score = ?, comment_count = ?, scored_at = ?, extensions = ?, content_hash = ?
+		WHERE id = ?
+	`
+	extensions, err := extensionsToSQL(entry.Extensions)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, query,
+		entry.Title, entry.Link, entry.Author, timeToSQL(entry.PublishedAt),
+		entry.Content, entry.ArchiveURL, timeToSQL(entry.ClaimedPublishedAt), entry.ImageURL,
+		entry.DiscussionURL, entry.CommentsFeedURL, entry.Score, entry.CommentCount,
+		timeToSQL(entry.ScoredAt), extensions, entry.ContentHash, entry.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update entry: %w", err)
+	}
+	entry.UpdatedAt = changeTime()
+	return s.setUserState(ctx, entry.ID, entry.Read, entry.ReadAt, entry.KeepUnread)
+}
+
+// markUserEntriesReadBefore is MarkEntriesReadBefore for a user view.
+func (s *SQLiteStore) markUserEntriesReadBefore(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	query := `
+		INSERT INTO entry_states (user_id, entry_id, read, read_at, keep_unread)
+		SELECT ?, e.id, 1, ?, 0 FROM entries e
+		LEFT JOIN entry_states u ON u.entry_id = e.id AND u.user_id = ?
+		WHERE COALESCE(u.read, 0) = 0 AND COALESCE(u.keep_unread, 0) = 0 AND e.published_at < ?
+		ON CONFLICT(user_id, entry_id) DO UPDATE SET read = 1, read_at = excluded.read_at
+	`
+	result, err := tx.ExecContext(ctx, query, s.user, now, s.user, before)
+	if err != nil {
+		return 0, fmt.Errorf("mark entries read before: %w", err)
+	}
+	marked, _ := result.RowsAffected()
+	stamp := `UPDATE entries SET updated_at = ?, change_seq = ` + nextChangeSeq + `
+		WHERE id IN (SELECT entry_id FROM entry_states WHERE user_id = ? AND read_at = ?)`
+	if _, err := tx.ExecContext(ctx, stamp, changeTime(), s.user, now); err != nil {
+		return 0, fmt.Errorf("mark entries read before: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return marked, nil
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Tests for per-user read state on shared stores
+// ABOUTME: Checks that users' reads, keeps, and counts stay apart from each other and the owner's
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestForUser(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed failed: %v", err)
+	}
+	var entries []*models.Entry
+	for i, guid := range []string{"one", "two", "three"} {
+		entry := models.NewEntry(feed.ID, guid, "Post "+guid)
+		published := time.Now().Add(-time.Duration(i+1) * time.Hour)
+		entry.PublishedAt = &published
+		if err := store.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry failed: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	// The owner's read state isn't a user's
+	if err := store.MarkEntryRead(ctx, entries[2].ID); err != nil {
+		t.Fatalf("MarkEntryRead failed: %v", err)
+	}
+
+	alice, err := ForUser(store, "alice")
+	if err != nil {
+		t.Fatalf("ForUser failed: %v", err)
+	}
+	bob, err := ForUser(store, "bob")
+	if err != nil {
+		t.Fatalf("ForUser failed: %v", err)
+	}
+
+	unread := func(s Store) int {
+		t.Helper()
+		n, err := s.CountUnreadEntries(ctx, nil)
+		if err != nil {
+			t.Fatalf("CountUnreadEntries failed: %v", err)
+		}
+		return n
+	}
+	if got := unread(alice); got != 3 {
+		t.Errorf("a new user should start with everything unread, got %d", got)
+	}
+
+	if err := alice.MarkEntryRead(ctx, entries[0].ID); err != nil {
+		t.Fatalf("MarkEntryRead failed: %v", err)
+	}
+	if err := alice.SetEntryKeepUnread(ctx, entries[1].ID, true); err != nil {
+		t.Fatalf("SetEntryKeepUnread failed: %v", err)
+	}
+	if a, b, owner := unread(alice), unread(bob), unread(store); a != 2 || b != 3 || owner != 2 {
+		t.Errorf("unread counts alice=%d bob=%d owner=%d, want 2, 3, 2", a, b, owner)
+	}
+
+	unreadOnly := true
+	list, err := alice.ListEntries(ctx, &EntryFilter{UnreadOnly: &unreadOnly})
+	if err != nil {
+		t.Fatalf("ListEntries failed: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != entries[1].ID || !list[0].KeepUnread {
+		t.Errorf("expected alice's kept entry first among 2 unread, got %d", len(list))
+	}
+	got, err := alice.GetEntry(ctx, entries[0].ID)
+	if err != nil || !got.Read || got.ReadAt == nil {
+		t.Fatalf("GetEntry = %+v, %v; want read", got, err)
+	}
+	if got, _ := bob.GetEntry(ctx, entries[0].ID); got.Read {
+		t.Error("alice's read leaked to bob")
+	}
+
+	// Updating contents through a view keeps everyone else's state
+	edited := "Edited"
+	got.Title = &edited
+	got.MarkUnread()
+	if err := alice.UpdateEntry(ctx, got); err != nil {
+		t.Fatalf("UpdateEntry failed: %v", err)
+	}
+	if owner, _ := store.GetEntry(ctx, entries[0].ID); owner.GetTitle() != "Edited" || owner.Read {
+		t.Errorf("owner sees %q read=%v, want the edit and its own state", owner.GetTitle(), owner.Read)
+	}
+
+	marked, err := bob.MarkEntriesReadBefore(ctx, time.Now())
+	if err != nil || marked != 3 {
+		t.Fatalf("MarkEntriesReadBefore = %d, %v; want 3", marked, err)
+	}
+	stats, err := bob.GetOverallStats(ctx)
+	if err != nil || stats.UnreadCount != 0 || stats.TotalEntries != 3 {
+		t.Errorf("bob's stats = %+v, %v", stats, err)
+	}
+	feedStats, err := alice.GetFeedStats(ctx)
+	if err != nil || len(feedStats) != 1 || feedStats[0].UnreadCount != 3 {
+		t.Errorf("alice's feed stats = %+v, %v", feedStats, err)
+	}
+	if found, err := bob.Search(ctx, "Edited", 10); err != nil || len(found) != 1 || !found[0].Read {
+		t.Errorf("bob's search = %v, %v; want the entry, read", found, err)
+	}
+
+	if err := store.DeleteUserState(ctx, "bob"); err != nil {
+		t.Fatalf("DeleteUserState failed: %v", err)
+	}
+	if got := unread(bob); got != 3 {
+		t.Errorf("expected bob's state forgotten, got %d unread", got)
+	}
+	if err := bob.Close(); err != nil {
+		t.Fatalf("closing a view: %v", err)
+	}
+	if got := unread(store); got != 2 {
+		t.Errorf("closing a view should leave the store open, got %d", got)
+	}
+	if err := alice.MarkEntryRead(ctx, "missing"); err == nil {
+		t.Error("expected an error for a missing entry")
+	}
+
+	if _, err := ForUser(store, "Robert'); --"); err == nil {
+		t.Error("expected an invalid user name to be refused")
+	}
+	md := newTestMarkdownStore(t)
+	if _, err := ForUser(md, "alice"); !errors.Is(err, ErrNoUserState) {
+		t.Errorf("expected ErrNoUserState for markdown, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+// ABOUTME: Groups entries from different feeds whose titles are near-identical into one story
+// ABOUTME: Lets digests and briefings show a story once with every feed that covered it
+
+package stories
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// minSimilarity is the share of title words two entries must have in
+// common, out of all the words in either title, to be the same story.
+const minSimilarity = 0.75
+
+// minWords keeps short titles like "Weekly Links" from matching on
+// similarity alone; they must be identical once normalized.
+const minWords = 4
+
+// Group collapses entries with near-identical titles from different feeds,
+// keeping the order in which each story first appears. Each group's first
+// entry is the one that came first; a feed contributes at most one entry to
+// a group, so repeats within a feed stay separate. Untitled entries are
+// never grouped.
+func Group(entries []*models.Entry) [][]*models.Entry {
+	type story struct {
+		words   map[string]bool
+		key     string
+		feeds   map[string]bool
+		entries []*models.Entry
+	}
+	var groups []*story
+	for _, e := range entries {
+		var key string
+		var set map[string]bool
+		if e.Title != nil {
+			key, set = normalize(*e.Title)
+		}
+
+		var match *story
+		for _, g := range groups {
+			if !g.feeds[e.FeedID] && same(key, set, g.key, g.words) {
+				match = g
+				break
+			}
+		}
+		if match == nil {
+			match = &story{words: set, key: key, feeds: map[string]bool{}}
+			groups = append(groups, match)
+		}
+		match.feeds[e.FeedID] = true
+		match.entries = append(match.entries, e)
+	}
+
+	result := make([][]*models.Entry, len(groups))
+	for i, g := range groups {
+		result[i] = g.entries
+	}
+	return result
+}
+
+// same reports whether two normalized titles are the same story.
+func same(keyA string, a map[string]bool, keyB string, b map[string]bool) bool {
+	if keyA == "" || keyB == "" {
+		return false
+	}
+	if keyA == keyB {
+		return true
+	}
+	if len(a) < minWords || len(b) < minWords {
+		return false
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	union := len(a) + len(b) - common
+	return float64(common)/float64(union) >= minSimilarity
+}
+
+// normalize returns a title's words joined into a comparison key, and as a
+// set.
+func normalize(title string) (string, map[string]bool) {
+	words := titleWords(title)
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return strings.Join(words, " "), set
+}
+
+// titleWords lowercases a title and splits it into words, dropping
+// punctuation so "Go 1.26 Released!" and "go 1.26 released" match.
+func titleWords(title string) []string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	}) {
+		if w = strings.Trim(w, "."); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Tests for grouping entries from different feeds into stories
+// ABOUTME: Covers title normalization, the similarity threshold, and same-feed repeats
+
+package stories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestGroup(t *testing.T) {
+	entry := func(feed, title string) *models.Entry {
+		return models.NewEntry(feed, feed+title, title)
+	}
+	untitled := models.NewEntry("d", "untitled-d", "")
+	untitled.Title = nil
+	otherUntitled := models.NewEntry("e", "untitled-e", "")
+	otherUntitled.Title = nil
+
+	entries := []*models.Entry{
+		entry("a", "Go 1.26 Released with Generic Methods"),
+		entry("b", "Weekly Links"),
+		entry("b", "Go 1.26 released, with generic methods!"),
+		entry("c", "Go 1.26 released with generic methods."),
+		entry("a", "Go 1.26 Released with Generic Methods"),
+		entry("c", "Weekly Links"),
+		entry("d", "Weekly Links Roundup"),
+		entry("e", "Rust 1.90 released with generic methods"),
+		untitled,
+		otherUntitled,
+	}
+
+	var got [][]string
+	for _, group := range Group(entries) {
+		var titles []string
+		for _, e := range group {
+			titles = append(titles, e.FeedID+":"+e.GetTitle())
+		}
+		got = append(got, titles)
+	}
+	want := [][]string{
+		{"a:Go 1.26 Released with Generic Methods", "b:Go 1.26 released, with generic methods!", "c:Go 1.26 released with generic methods."},
+		{"b:Weekly Links", "c:Weekly Links"},
+		{"a:Go 1.26 Released with Generic Methods"},
+		{"d:Weekly Links Roundup"},
+		{"e:Rust 1.90 released with generic methods"},
+		{"d:" + models.DefaultEntryTitle},
+		{"e:" + models.DefaultEntryTitle},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Group() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestSameStoryThreshold(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Apple announces the M5 chip", "Apple announces M5 chip", true},
+		{"OpenSSL patches a critical bug in TLS", "OpenSSL patches critical TLS bug", false},
+		{"New Release", "new release!", true},
+		{"New Release", "New Release Notes", false},
+	}
+	for _, tt := range tests {
+		keyA, a := normalize(tt.a)
+		keyB, b := normalize(tt.b)
+		if got := same(keyA, a, keyB, b); got != tt.want {
+			t.Errorf("same(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
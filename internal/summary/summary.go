@@ -0,0 +1,81 @@
+// ABOUTME: On-disk cache of entry summaries produced by the MCP client's model
+// ABOUTME: Stores one JSON file per entry, invalidated when the entry content changes
+
+package summary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harperreed/mdstore"
+)
+
+// Summary is a cached summary of one entry.
+type Summary struct {
+	EntryID     string    `json:"entry_id"`
+	ContentHash string    `json:"content_hash"`
+	MaxWords    int       `json:"max_words"`
+	Model       string    `json:"model,omitempty"`
+	Text        string    `json:"text"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CacheDir returns the summary cache directory inside a profile data directory.
+func CacheDir(profileDir string) string {
+	return filepath.Join(profileDir, "summaries")
+}
+
+// HashContent returns a stable hash of entry content, used to detect when a
+// cached summary no longer matches the article.
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func path(dir, entryID string) string {
+	return filepath.Join(dir, entryID+".json")
+}
+
+// Load returns the cached summary for an entry if it was made from the same
+// content with the same length limit.
+func Load(dir, entryID, contentHash string, maxWords int) (*Summary, bool) {
+	s, ok := Latest(dir, entryID)
+	if !ok || s.ContentHash != contentHash || s.MaxWords != maxWords {
+		return nil, false
+	}
+	return s, true
+}
+
+// Latest returns the cached summary for an entry whatever content or length
+// limit it was made from, for uses where a slightly stale summary will do.
+func Latest(dir, entryID string) (*Summary, bool) {
+	data, err := os.ReadFile(path(dir, entryID))
+	if err != nil {
+		return nil, false
+	}
+	var s Summary
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, false
+	}
+	return &s, true
+}
+
+// Save writes a summary to the cache, replacing any earlier one for the entry.
+func Save(dir string, s *Summary) error {
+	if err := mdstore.EnsureDir(dir); err != nil {
+		return fmt.Errorf("create summary cache: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode summary: %w", err)
+	}
+	if err := mdstore.AtomicWrite(path(dir, s.EntryID), data); err != nil {
+		return fmt.Errorf("write summary: %w", err)
+	}
+	return nil
+}
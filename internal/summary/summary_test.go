@@ -0,0 +1,43 @@
+// ABOUTME: Tests for the entry summary cache
+// ABOUTME: Covers round-tripping and invalidation on content or length changes
+
+package summary
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := CacheDir(t.TempDir())
+	hash := HashContent("original article")
+
+	s := &Summary{EntryID: "abc123", ContentHash: hash, MaxWords: 100, Model: "test-model", Text: "Short version.", CreatedAt: time.Now()}
+	if err := Save(dir, s); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, ok := Load(dir, "abc123", hash, 100)
+	if !ok {
+		t.Fatal("expected a cached summary")
+	}
+	if got.Text != "Short version." || got.Model != "test-model" {
+		t.Errorf("unexpected summary: %+v", got)
+	}
+
+	if _, ok := Load(dir, "abc123", HashContent("edited article"), 100); ok {
+		t.Error("expected changed content to miss the cache")
+	}
+	if _, ok := Load(dir, "abc123", hash, 50); ok {
+		t.Error("expected a different length limit to miss the cache")
+	}
+	if got, ok := Latest(dir, "abc123"); !ok || got.Text != "Short version." {
+		t.Errorf("Latest() = %+v, %v; want the cached summary", got, ok)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	if _, ok := Load(CacheDir(t.TempDir()), "nope", HashContent(""), 100); ok {
+		t.Error("expected no summary in an empty cache")
+	}
+}
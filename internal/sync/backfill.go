@@ -0,0 +1,39 @@
+// ABOUTME: First-sync backfill limits for newly added feeds
+// ABOUTME: Keeps a feed's history from arriving as a pile of unread entries on its first sync
+
+package sync
+
+import (
+	"sort"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// backfill applies a feed's first-sync limits to the entries that sync
+// brought in: only the newest BackfillLimit are kept, and those published
+// more than BackfillDays before now are marked read. Undated entries count
+// as the oldest for the limit and are never marked read.
+func backfill(feed *models.Feed, entries []*models.Entry, now time.Time) []*models.Entry {
+	if feed.BackfillLimit > 0 && len(entries) > feed.BackfillLimit {
+		published := func(e *models.Entry) time.Time {
+			if e.PublishedAt == nil {
+				return time.Time{}
+			}
+			return *e.PublishedAt
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return published(entries[i]).After(published(entries[j]))
+		})
+		entries = entries[:feed.BackfillLimit]
+	}
+	if feed.BackfillDays > 0 {
+		cutoff := now.AddDate(0, 0, -feed.BackfillDays)
+		for _, e := range entries {
+			if e.PublishedAt != nil && e.PublishedAt.Before(cutoff) {
+				e.MarkRead()
+			}
+		}
+	}
+	return entries
+}
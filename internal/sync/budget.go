@@ -0,0 +1,95 @@
+// ABOUTME: Per-folder unread budgets that bound a folder's backlog
+// ABOUTME: Marks the oldest unread entries of a folder read once it has more unread than its budget
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+	"github.com/harper/digest/internal/storage"
+)
+
+// Budget is the most unread entries an OPML folder's feeds may have.
+type Budget struct {
+	Folder   string
+	Max      int
+	FeedURLs []string
+}
+
+// BudgetResult is what enforcing one folder's budget marked read.
+type BudgetResult struct {
+	Folder string
+	Max    int
+	// Unread is how many unread entries the folder had before.
+	Unread int
+	// Marked are the entries marked read, oldest first.
+	Marked []*models.Entry
+}
+
+// Budgets pairs configured limits, keyed by folder name ("" for feeds
+// outside any folder), with the feeds in each folder. Limits of zero or
+// less are ignored. Budgets come back sorted by folder.
+func Budgets(doc *opml.Document, limits map[string]int) []Budget {
+	var budgets []Budget
+	for folder, limit := range limits {
+		if limit <= 0 {
+			continue
+		}
+		b := Budget{Folder: folder, Max: limit}
+		for _, f := range doc.FeedsInFolder(folder) {
+			b.FeedURLs = append(b.FeedURLs, f.URL)
+		}
+		budgets = append(budgets, b)
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Folder < budgets[j].Folder })
+	return budgets
+}
+
+// EnforceBudgets marks the oldest unread entries of each over-budget folder
+// read until it is back within budget. Entries kept unread count toward the
+// budget but are never marked, so a folder of kept entries can stay over.
+// Only folders where something was marked are reported.
+func EnforceBudgets(ctx context.Context, store storage.Store, budgets []Budget) ([]BudgetResult, error) {
+	var results []BudgetResult
+	for _, b := range budgets {
+		var feedIDs []string
+		for _, url := range b.FeedURLs {
+			if feed, err := store.GetFeedByURL(ctx, url); err == nil {
+				feedIDs = append(feedIDs, feed.ID)
+			}
+		}
+		if len(feedIDs) == 0 {
+			continue
+		}
+
+		unread := true
+		entries, err := store.ListEntries(ctx, &storage.EntryFilter{FeedIDs: feedIDs, UnreadOnly: &unread})
+		if err != nil {
+			return results, fmt.Errorf("failed to list unread entries in %q: %w", b.Folder, err)
+		}
+		over := len(entries) - b.Max
+		if over <= 0 {
+			continue
+		}
+
+		// ListEntries returns newest first, so walk back from the oldest
+		result := BudgetResult{Folder: b.Folder, Max: b.Max, Unread: len(entries)}
+		for i := len(entries) - 1; i >= 0 && len(result.Marked) < over; i-- {
+			if entries[i].KeepUnread {
+				continue
+			}
+			if err := store.MarkEntryRead(ctx, entries[i].ID); err != nil {
+				return results, fmt.Errorf("failed to mark entry read: %w", err)
+			}
+			result.Marked = append(result.Marked, entries[i])
+		}
+		if len(result.Marked) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for per-folder unread budgets
+// ABOUTME: Verifies folders are resolved from OPML and trimmed oldest first, sparing kept entries
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/opml"
+)
+
+func TestBudgets(t *testing.T) {
+	doc := opml.NewDocument("test")
+	for _, f := range []struct{ url, folder string }{
+		{"https://a.example.com/feed", "News"},
+		{"https://b.example.com/feed", "News"},
+		{"https://c.example.com/feed", "Tech"},
+		{"https://d.example.com/feed", ""},
+	} {
+		if err := doc.AddFeed(f.url, "", f.folder); err != nil {
+			t.Fatalf("AddFeed: %v", err)
+		}
+	}
+
+	budgets := Budgets(doc, map[string]int{"Tech": 50, "News": 200, "": 10, "Off": 0})
+	if len(budgets) != 3 {
+		t.Fatalf("expected budgets for the three limited folders, got %+v", budgets)
+	}
+	if budgets[0].Folder != "" || len(budgets[0].FeedURLs) != 1 {
+		t.Errorf("expected root-level feeds first, got %+v", budgets[0])
+	}
+	if budgets[1].Folder != "News" || budgets[1].Max != 200 || len(budgets[1].FeedURLs) != 2 {
+		t.Errorf("unexpected News budget %+v", budgets[1])
+	}
+}
+
+func TestEnforceBudgets(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	defer store.Close()
+
+	busy := models.NewFeed("https://busy.example.com/feed")
+	other := models.NewFeed("https://other.example.com/feed")
+	for _, f := range []*models.Feed{busy, other} {
+		if err := store.CreateFeed(ctx, f); err != nil {
+			t.Fatalf("CreateFeed: %v", err)
+		}
+	}
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var entries []*models.Entry
+	for i := range 6 {
+		published := base.Add(time.Duration(i) * time.Hour)
+		e := models.NewEntry(busy.ID, fmt.Sprintf("busy-%d", i), fmt.Sprintf("Busy %d", i))
+		e.PublishedAt = &published
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	outside := models.NewEntry(other.ID, "outside", "Outside")
+	if err := store.CreateEntry(ctx, outside); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+	// The oldest entry is kept, so the next two oldest go instead
+	if err := store.SetEntryKeepUnread(ctx, entries[0].ID, true); err != nil {
+		t.Fatalf("SetEntryKeepUnread: %v", err)
+	}
+
+	budgets := []Budget{{Folder: "News", Max: 4, FeedURLs: []string{busy.URL}}}
+	results, err := EnforceBudgets(ctx, store, budgets)
+	if err != nil {
+		t.Fatalf("EnforceBudgets: %v", err)
+	}
+	if len(results) != 1 || results[0].Unread != 6 || len(results[0].Marked) != 2 {
+		t.Fatalf("expected 2 of 6 unread marked in News, got %+v", results)
+	}
+	if results[0].Marked[0].ID != entries[1].ID || results[0].Marked[1].ID != entries[2].ID {
+		t.Errorf("expected the oldest unkept entries marked, got %s and %s",
+			results[0].Marked[0].GetTitle(), results[0].Marked[1].GetTitle())
+	}
+	for _, e := range []*models.Entry{entries[0], entries[5], outside} {
+		got, err := store.GetEntry(ctx, e.ID)
+		if err != nil {
+			t.Fatalf("GetEntry: %v", err)
+		}
+		if got.Read {
+			t.Errorf("expected %s to stay unread", got.GetTitle())
+		}
+	}
+
+	// Within budget now, so nothing more is marked
+	results, err = EnforceBudgets(ctx, store, budgets)
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected no further trimming, got %+v (%v)", results, err)
+	}
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Publish date validation applied when entries are first stored
+// ABOUTME: Replaces future-dated and pre-web timestamps so they can't pin date-sorted listings
+
+package sync
+
+import (
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// DefaultMaxFuture is how far past its fetch time an entry may be dated
+// before the date counts as implausible. It leaves room for time zone
+// mistakes in feeds.
+const DefaultMaxFuture = 24 * time.Hour
+
+// DefaultEarliest is the oldest plausible publish date: nothing was
+// published on the web before 1990, so earlier dates are zero values or
+// epoch timestamps.
+var DefaultEarliest = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// DatePolicy bounds the publish dates accepted from feeds. The zero value
+// uses the defaults.
+type DatePolicy struct {
+	// MaxFuture is how far past the fetch time a date may be.
+	MaxFuture time.Duration
+
+	// Earliest is the oldest plausible date.
+	Earliest time.Time
+
+	// Keep stores feed dates unchanged.
+	Keep bool
+}
+
+// Implausible reports whether published is too far ahead of now, or too
+// old, to be a real publish date.
+func (p DatePolicy) Implausible(published, now time.Time) bool {
+	if p.Keep {
+		return false
+	}
+	maxFuture := p.MaxFuture
+	if maxFuture <= 0 {
+		maxFuture = DefaultMaxFuture
+	}
+	earliest := p.Earliest
+	if earliest.IsZero() {
+		earliest = DefaultEarliest
+	}
+	return published.After(now.Add(maxFuture)) || published.Before(earliest)
+}
+
+// Apply replaces an implausible publish date on a new entry with the time
+// it was fetched, keeping the feed's date in ClaimedPublishedAt.
+func (p DatePolicy) Apply(entry *models.Entry) {
+	if entry.PublishedAt == nil || !p.Implausible(*entry.PublishedAt, entry.CreatedAt) {
+		return
+	}
+	claimed := *entry.PublishedAt
+	fetched := entry.CreatedAt
+	entry.ClaimedPublishedAt = &claimed
+	entry.PublishedAt = &fetched
+}
@@ -0,0 +1,117 @@
+// ABOUTME: Tests for publish date validation at ingest
+// ABOUTME: Verifies future and epoch dates are replaced with the fetch time and the feed's date is kept
+
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestDatePolicy_Implausible(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		policy    DatePolicy
+		published time.Time
+		want      bool
+	}{
+		{"recent", DatePolicy{}, now.Add(-time.Hour), false},
+		{"slightly ahead", DatePolicy{}, now.Add(6 * time.Hour), false},
+		{"years ahead", DatePolicy{}, now.AddDate(3, 0, 0), true},
+		{"epoch", DatePolicy{}, time.Unix(0, 0), true},
+		{"old but real", DatePolicy{}, time.Date(1999, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"tighter future", DatePolicy{MaxFuture: time.Hour}, now.Add(6 * time.Hour), true},
+		{"later earliest", DatePolicy{Earliest: time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC)}, time.Date(1999, 3, 1, 0, 0, 0, 0, time.UTC), true},
+		{"keep", DatePolicy{Keep: true}, time.Unix(0, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Implausible(tt.published, now); got != tt.want {
+				t.Errorf("Implausible() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatePolicy_Apply(t *testing.T) {
+	entry := models.NewEntry("feed", "guid", "Title")
+	future := entry.CreatedAt.AddDate(10, 0, 0)
+	entry.PublishedAt = &future
+
+	DatePolicy{}.Apply(entry)
+	if entry.PublishedAt == nil || !entry.PublishedAt.Equal(entry.CreatedAt) {
+		t.Errorf("expected publish date replaced with fetch time, got %v", entry.PublishedAt)
+	}
+	if entry.ClaimedPublishedAt == nil || !entry.ClaimedPublishedAt.Equal(future) {
+		t.Errorf("expected claimed date kept, got %v", entry.ClaimedPublishedAt)
+	}
+
+	plain := models.NewEntry("feed", "guid-2", "Title")
+	published := plain.CreatedAt.Add(-time.Hour)
+	plain.PublishedAt = &published
+	DatePolicy{}.Apply(plain)
+	if !plain.PublishedAt.Equal(published) || plain.ClaimedPublishedAt != nil {
+		t.Error("expected a plausible date left alone")
+	}
+}
+
+func TestSyncFeed_ClampsFutureDates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Dates</title>
+<item><title>From the future</title><guid>future</guid><pubDate>Fri, 01 Jan 2100 00:00:00 GMT</pubDate></item>
+<item><title>Epoch</title><guid>epoch</guid><pubDate>Thu, 01 Jan 1970 00:00:00 GMT</pubDate></item>
+<item><title>Normal</title><guid>normal</guid><pubDate>Mon, 05 Jan 2026 10:00:00 GMT</pubDate></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name string
+		opts Options
+		want int
+	}{
+		{"default", Options{}, 2},
+		{"keep", Options{Dates: DatePolicy{Keep: true}}, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			defer store.Close()
+			feed := models.NewFeed(server.URL)
+			feed.LocalNetwork = true
+			if err := store.CreateFeed(ctx, feed); err != nil {
+				t.Fatalf("create feed: %v", err)
+			}
+
+			before := time.Now()
+			if _, err := SyncFeedWith(ctx, store, feed, tt.opts); err != nil {
+				t.Fatalf("SyncFeedWith: %v", err)
+			}
+			entries, err := store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID})
+			if err != nil {
+				t.Fatalf("list entries: %v", err)
+			}
+			clamped := 0
+			for _, e := range entries {
+				if e.ClaimedPublishedAt == nil {
+					continue
+				}
+				clamped++
+				if e.PublishedAt.Before(before.Add(-time.Second)) || e.PublishedAt.After(time.Now()) {
+					t.Errorf("%s: expected fetch time, got %v", e.GUID, e.PublishedAt)
+				}
+			}
+			if clamped != tt.want {
+				t.Errorf("expected %d clamped entries, got %d", tt.want, clamped)
+			}
+		})
+	}
+}
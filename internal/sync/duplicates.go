@@ -0,0 +1,89 @@
+// ABOUTME: Suppresses entries a feed republishes unchanged under a new GUID
+// ABOUTME: Fingerprints entry content and skips new items matching a recently stored entry
+
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// DefaultDuplicateWindow is how long after an entry was first stored a
+// republished copy of it is still recognized.
+const DefaultDuplicateWindow = 30 * 24 * time.Hour
+
+// DuplicatePolicy decides which new entries are republished copies of
+// stored ones. The zero value uses the defaults.
+type DuplicatePolicy struct {
+	// Window is how recently the stored entry must have been first stored.
+	Window time.Duration
+
+	// Keep stores republished copies like any other new entry.
+	Keep bool
+}
+
+// ContentHash fingerprints entry content. Differences in whitespace alone
+// don't change it.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(content), " ")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// filter drops the unseen entries with the same title, link, and content
+// as an entry of the feed first stored within the window, or as an earlier
+// unseen entry. Entries with neither a link nor content are always kept,
+// since a title alone says too little, and so is everything from a feed
+// whose identity is pinned to GUIDs. It returns the entries kept and how
+// many were dropped.
+func (p DuplicatePolicy) filter(ctx context.Context, store storage.Store, feed *models.Feed, unseen []keyedEntry, now time.Time) ([]keyedEntry, int, error) {
+	if p.Keep || feed.Identity == models.IdentityGUID || len(unseen) == 0 {
+		return unseen, 0, nil
+	}
+	window := p.Window
+	if window <= 0 {
+		window = DefaultDuplicateWindow
+	}
+	since := now.Add(-window)
+	stored, err := store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID, Since: &since, FirstSeen: true})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list recent entries: %w", err)
+	}
+
+	known := make(map[string]bool, len(stored))
+	for _, e := range stored {
+		hash := e.ContentHash
+		if hash == "" {
+			// Stored before content hashes were kept
+			hash = ContentHash(derefString(e.Content))
+		}
+		known[duplicateKey(derefString(e.Title), derefString(e.Link), hash)] = true
+	}
+
+	kept := make([]keyedEntry, 0, len(unseen))
+	dropped := 0
+	for _, ke := range unseen {
+		if ke.entry.Link == "" && strings.TrimSpace(ke.entry.Content) == "" {
+			kept = append(kept, ke)
+			continue
+		}
+		key := duplicateKey(ke.entry.Title, ke.entry.Link, ContentHash(ke.entry.Content))
+		if known[key] {
+			dropped++
+			continue
+		}
+		known[key] = true
+		kept = append(kept, ke)
+	}
+	return kept, dropped, nil
+}
+
+func duplicateKey(title, link, hash string) string {
+	return title + "\x00" + link + "\x00" + hash
+}
@@ -0,0 +1,70 @@
+// ABOUTME: Tests for skipping entries a feed republishes unchanged under a new GUID
+// ABOUTME: Verifies copies are skipped within the window and changed or kept ones are stored
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestSyncFeed_SkipsRepublishedDuplicates(t *testing.T) {
+	requests := 0
+	body := "Same words as before."
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// The second item gets a fresh GUID on every fetch; the third repeats it
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Churn</title>
+<item><title>Stable</title><link>https://example.com/stable</link><guid>stable</guid><description>Fixed</description></item>
+<item><title>Churning</title><link>https://example.com/churn</link><guid>churn-%[1]d</guid><description>%[2]s</description></item>
+<item><title>Churning</title><link>https://example.com/churn</link><guid>copy-%[1]d</guid><description>%[2]s</description></item>
+</channel></rss>`, requests, body)
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	check := func(opts Options, wantNew, wantDuplicates int) {
+		t.Helper()
+		opts.Force = true
+		result, err := SyncFeedWith(ctx, store, feed, opts)
+		if err != nil {
+			t.Fatalf("SyncFeedWith: %v", err)
+		}
+		if result.NewEntries != wantNew || result.Duplicates != wantDuplicates {
+			t.Errorf("expected %d new and %d duplicates, got %d and %d", wantNew, wantDuplicates, result.NewEntries, result.Duplicates)
+		}
+	}
+
+	// The copy in the same fetch is skipped too
+	check(Options{}, 2, 1)
+	entries, _ := store.ListEntries(ctx, nil)
+	for _, e := range entries {
+		if e.ContentHash != ContentHash(*e.Content) {
+			t.Errorf("expected %q stored with its content hash, got %q", e.GetTitle(), e.ContentHash)
+		}
+	}
+
+	check(Options{}, 0, 2)
+
+	// Whitespace alone doesn't make it new, but changed content does
+	body = "Same   words\nas before."
+	check(Options{}, 0, 2)
+	body = "Corrected words."
+	check(Options{}, 1, 1)
+
+	// Outside the window, or with duplicates kept, the copies are stored
+	check(Options{Duplicates: DuplicatePolicy{Window: time.Nanosecond}}, 1, 1)
+	check(Options{Duplicates: DuplicatePolicy{Keep: true}}, 2, 0)
+}
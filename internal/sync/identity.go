@@ -0,0 +1,162 @@
+// ABOUTME: Entry identity strategies that keep feeds with missing or unstable GUIDs from duplicating
+// ABOUTME: Keys entries by GUID, link, or a title+date hash, and detects feeds that regenerate GUIDs
+
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/storage"
+)
+
+// minUnstable is the fewest new-looking entries in one fetch that can mark a
+// feed's GUIDs as unstable, so one re-published post doesn't.
+const minUnstable = 2
+
+// keyedEntry is a parsed entry with its identity key.
+type keyedEntry struct {
+	key   string
+	entry parse.ParsedEntry
+}
+
+// EntryKey returns the key that identifies a parsed entry under strategy,
+// falling back from GUID to link to a hash of title and publish date when a
+// field is empty.
+func EntryKey(e parse.ParsedEntry, strategy string) string {
+	switch strategy {
+	case models.IdentityHash:
+		return hashKey(e.Title, e.PublishedAt)
+	case models.IdentityLink:
+		if e.Link != "" {
+			return e.Link
+		}
+	}
+	if e.GUID != "" {
+		return e.GUID
+	}
+	if e.Link != "" {
+		return e.Link
+	}
+	return hashKey(e.Title, e.PublishedAt)
+}
+
+// storedKey returns the key a stored entry has under strategy, computed from
+// its fields rather than the key it was stored under.
+func storedKey(e *models.Entry, strategy string) string {
+	switch strategy {
+	case models.IdentityHash:
+		title := ""
+		if e.Title != nil {
+			title = *e.Title
+		}
+		return hashKey(title, e.PublishedAt)
+	case models.IdentityLink:
+		if e.Link != nil && *e.Link != "" {
+			return *e.Link
+		}
+	}
+	return e.GUID
+}
+
+// hashKey identifies an entry by title and publish date.
+func hashKey(title string, published *time.Time) string {
+	h := sha256.New()
+	h.Write([]byte(title))
+	h.Write([]byte{0})
+	if published != nil {
+		h.Write([]byte(published.UTC().Format(time.RFC3339)))
+	}
+	return "hash:" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// unseenEntries returns the parsed entries not yet stored for feedID under
+// strategy, keyed and without repeats. GUID lookups go straight to the
+// store; link and hash compare against every stored entry of the feed.
+func unseenEntries(ctx context.Context, store storage.Store, feedID string, entries []parse.ParsedEntry, strategy string) ([]keyedEntry, error) {
+	var known map[string]bool
+	if strategy == models.IdentityLink || strategy == models.IdentityHash {
+		stored, err := store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feedID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list entries: %w", err)
+		}
+		known = indexEntries(stored, strategy)
+	}
+
+	seen := make(map[string]bool, len(entries))
+	var unseen []keyedEntry
+	for _, e := range entries {
+		key := EntryKey(e, strategy)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		exists := known[key]
+		if known == nil {
+			var err error
+			if exists, err = store.EntryExists(ctx, feedID, key); err != nil {
+				return nil, fmt.Errorf("failed to check entry existence: %w", err)
+			}
+		}
+		if !exists {
+			unseen = append(unseen, keyedEntry{key: key, entry: e})
+		}
+	}
+	return unseen, nil
+}
+
+// indexEntries returns the keys stored entries have under strategy, along
+// with the keys they were stored under.
+func indexEntries(stored []*models.Entry, strategy string) map[string]bool {
+	index := make(map[string]bool, 2*len(stored))
+	for _, e := range stored {
+		index[e.GUID] = true
+		index[storedKey(e, strategy)] = true
+	}
+	return index
+}
+
+// detectIdentity checks whether a fetch where most entries look new is really
+// a feed regenerating its GUIDs: when most of them are already stored under
+// the same link, or failing that the same title and date, it returns that
+// strategy and the entries that are new under it. It returns "" when the
+// GUIDs look fine.
+func detectIdentity(ctx context.Context, store storage.Store, feedID string, total int, fresh []keyedEntry) (string, []keyedEntry, error) {
+	if len(fresh) < minUnstable || 2*len(fresh) < total {
+		return "", nil, nil
+	}
+	stored, err := store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feedID})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+	if len(stored) == 0 {
+		return "", nil, nil
+	}
+
+	for _, strategy := range []string{models.IdentityLink, models.IdentityHash} {
+		known := indexEntries(stored, strategy)
+		keys := make(map[string]bool, len(fresh))
+		var unseen []keyedEntry
+		for _, ke := range fresh {
+			key := EntryKey(ke.entry, strategy)
+			keys[key] = true
+			if !known[key] {
+				unseen = append(unseen, keyedEntry{key: key, entry: ke.entry})
+			}
+		}
+		// A strategy that would fold distinct entries together is no fix
+		if len(keys) < len(fresh) {
+			continue
+		}
+		if 2*(len(fresh)-len(unseen)) >= len(fresh) {
+			return strategy, unseen, nil
+		}
+	}
+	return "", nil, nil
+}
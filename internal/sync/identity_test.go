@@ -0,0 +1,154 @@
+// ABOUTME: Tests for entry identity strategies and GUID instability detection
+// ABOUTME: Verifies key fallbacks and that feeds regenerating GUIDs stop duplicating entries
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestEntryKey(t *testing.T) {
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	full := parse.ParsedEntry{GUID: "guid-1", Link: "https://example.com/1", Title: "One", PublishedAt: &published}
+	noLink := parse.ParsedEntry{GUID: "guid-1", Title: "One", PublishedAt: &published}
+	bare := parse.ParsedEntry{Title: "One", PublishedAt: &published}
+	hash := hashKey("One", &published)
+
+	tests := []struct {
+		name     string
+		entry    parse.ParsedEntry
+		strategy string
+		want     string
+	}{
+		{"guid", full, models.IdentityGUID, "guid-1"},
+		{"auto uses guid", full, models.IdentityAuto, "guid-1"},
+		{"link", full, models.IdentityLink, "https://example.com/1"},
+		{"link falls back to guid", noLink, models.IdentityLink, "guid-1"},
+		{"guid falls back to hash", bare, models.IdentityGUID, hash},
+		{"hash", full, models.IdentityHash, hash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EntryKey(tt.entry, tt.strategy); got != tt.want {
+				t.Errorf("EntryKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	later := published.Add(time.Hour)
+	if hashKey("One", &later) == hash || hashKey("Two", &published) == hash {
+		t.Error("expected hash to depend on title and publish date")
+	}
+}
+
+// regeneratingFeed serves the same items with fresh GUIDs on every request.
+func regeneratingFeed(t *testing.T, withLinks bool) *httptest.Server {
+	t.Helper()
+	requests := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var items strings.Builder
+		for i := 1; i <= 3; i++ {
+			link := ""
+			if withLinks {
+				link = fmt.Sprintf("<link>https://example.com/post-%d</link>", i)
+			}
+			fmt.Fprintf(&items, `<item><title>Post %d</title>%s<guid>gen-%d-%d</guid><pubDate>Mon, 0%d Jan 2026 10:00:00 GMT</pubDate></item>`,
+				i, link, requests, i, i)
+		}
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Unstable</title>` + items.String() + `</channel></rss>`))
+	}))
+}
+
+func syncTwice(t *testing.T, store storage.Store, feed *models.Feed) *SyncResult {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := SyncFeed(ctx, store, feed, true); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	// The store sets LastFetchedAt; reload so the second sync sees it
+	reloaded, err := store.GetFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("reload feed: %v", err)
+	}
+	*feed = *reloaded
+	result, err := SyncFeed(ctx, store, feed, true)
+	if err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	return result
+}
+
+func TestSyncFeed_DetectsUnstableGUIDs(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		withLinks bool
+		want      string
+	}{
+		{"by link", true, models.IdentityLink},
+		{"by hash without links", false, models.IdentityHash},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := regeneratingFeed(t, tt.withLinks)
+			defer server.Close()
+			store := newTestStore(t)
+			defer store.Close()
+
+			feed := models.NewFeed(server.URL)
+			feed.LocalNetwork = true
+			if err := store.CreateFeed(context.Background(), feed); err != nil {
+				t.Fatalf("create feed: %v", err)
+			}
+
+			result := syncTwice(t, store, feed)
+			if result.NewEntries != 0 {
+				t.Errorf("expected no duplicates, got %d new entries", result.NewEntries)
+			}
+			if result.Identity != tt.want {
+				t.Errorf("expected switch to %q, got %q", tt.want, result.Identity)
+			}
+			stored, _ := store.GetFeed(context.Background(), feed.ID)
+			if stored.Identity != tt.want {
+				t.Errorf("expected stored identity %q, got %q", tt.want, stored.Identity)
+			}
+
+			// Later syncs keep matching under the new strategy
+			result, err := SyncFeed(context.Background(), store, stored, true)
+			if err != nil {
+				t.Fatalf("third sync: %v", err)
+			}
+			if result.NewEntries != 0 {
+				t.Errorf("expected no duplicates on third sync, got %d", result.NewEntries)
+			}
+		})
+	}
+}
+
+func TestSyncFeed_ExplicitGUIDIdentityNeverSwitches(t *testing.T) {
+	server := regeneratingFeed(t, true)
+	defer server.Close()
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed(server.URL)
+	feed.LocalNetwork = true
+	feed.Identity = models.IdentityGUID
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	result := syncTwice(t, store, feed)
+	if result.NewEntries != 3 || result.Identity != "" {
+		t.Errorf("expected 3 new entries and no switch, got %d and %q", result.NewEntries, result.Identity)
+	}
+}
@@ -0,0 +1,39 @@
+// ABOUTME: Lead image handling for entries as they are first stored
+// ABOUTME: Falls back to the article's og:image and optionally caches the image on disk
+
+package sync
+
+import (
+	"context"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/thumbnail"
+)
+
+// ImageOptions controls lead images of new entries. The zero value stores
+// the image the feed gives, if any, without fetching anything.
+type ImageOptions struct {
+	// OpenGraph fetches the article page of entries the feed gives no
+	// image for and uses its og:image. It costs a request per new entry.
+	OpenGraph bool
+
+	// CacheDir, when set, is where lead images are downloaded to so they
+	// can be shown offline.
+	CacheDir string
+}
+
+// apply sets a new entry's lead image from the feed's, falling back to the
+// article's share image, and caches it. Images are cosmetic, so failures
+// leave the entry without one rather than failing the sync.
+func (o ImageOptions) apply(ctx context.Context, entry *models.Entry, image string, allowLocalNetwork bool) {
+	if image == "" && o.OpenGraph && entry.Link != nil && *entry.Link != "" {
+		image, _ = thumbnail.OpenGraph(ctx, *entry.Link, allowLocalNetwork)
+	}
+	if image == "" {
+		return
+	}
+	entry.ImageURL = &image
+	if o.CacheDir != "" {
+		_, _ = thumbnail.Cache(ctx, o.CacheDir, entry.ID, image, allowLocalNetwork)
+	}
+}
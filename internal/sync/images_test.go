@@ -0,0 +1,80 @@
+// ABOUTME: Tests for lead images of newly synced entries
+// ABOUTME: Verifies feed images are kept, og:image is an opt-in fallback, and images can be cached
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/thumbnail"
+)
+
+func TestSyncFeed_LeadImages(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"><channel><title>Pics</title>
+<item><title>Feed image</title><guid>feed</guid><link>%[1]s/post/feed</link><media:thumbnail url="%[1]s/thumb.png"/></item>
+<item><title>Page image</title><guid>page</guid><link>%[1]s/post/page</link><description>No pictures</description></item>
+</channel></rss>`, server.URL)
+		case "/post/page":
+			w.Write([]byte(`<html><head><meta property="og:image" content="/share.png"></head></html>`))
+		case "/thumb.png", "/share.png":
+			w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name      string
+		images    ImageOptions
+		pageImage string
+		cached    bool
+	}{
+		{"feed images only", ImageOptions{}, "", false},
+		{"open graph fallback", ImageOptions{OpenGraph: true}, server.URL + "/share.png", false},
+		{"cached", ImageOptions{OpenGraph: true, CacheDir: filepath.Join(t.TempDir(), "thumbnails")}, server.URL + "/share.png", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			defer store.Close()
+			feed := models.NewFeed(server.URL + "/feed.xml")
+			feed.LocalNetwork = true
+			if err := store.CreateFeed(ctx, feed); err != nil {
+				t.Fatalf("create feed: %v", err)
+			}
+
+			if _, err := SyncFeedWith(ctx, store, feed, Options{Images: tt.images}); err != nil {
+				t.Fatalf("SyncFeedWith: %v", err)
+			}
+			entries, err := store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID})
+			if err != nil {
+				t.Fatalf("list entries: %v", err)
+			}
+			want := map[string]string{"feed": server.URL + "/thumb.png", "page": tt.pageImage}
+			for _, e := range entries {
+				got := ""
+				if e.ImageURL != nil {
+					got = *e.ImageURL
+				}
+				if got != want[e.GUID] {
+					t.Errorf("%s: ImageURL = %q, want %q", e.GUID, got, want[e.GUID])
+				}
+				if cached := thumbnail.Path(tt.images.CacheDir, e.ID) != ""; tt.images.CacheDir != "" && cached != tt.cached {
+					t.Errorf("%s: cached = %v, want %v", e.GUID, cached, tt.cached)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// ABOUTME: Dead feed detection for feeds that stopped publishing or keep failing
+// ABOUTME: Finds inactive feeds from feed stats and archives them so syncs skip them
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// DefaultInactiveAfter is how long a feed can be quiet or failing before it
+// counts as inactive, when no other limit is configured.
+const DefaultInactiveAfter = 90 * 24 * time.Hour
+
+// InactiveFeed is a feed found inactive and why.
+type InactiveFeed struct {
+	Feed   *models.Feed
+	Reason string
+}
+
+// InactiveReason returns why a feed counts as dead, or "" if it doesn't.
+// A feed is dead when it has failed every sync for after, or when it has
+// been fetched but produced no new entries for after. lastEntryAt is when
+// its newest entry was first stored, if it has any. Archived, paused, and
+// bookmarks feeds are never reported.
+func InactiveReason(feed *models.Feed, lastEntryAt *time.Time, after time.Duration, now time.Time) string {
+	if after <= 0 || feed.IsArchived() || feed.Paused || feed.IsBookmarks() {
+		return ""
+	}
+
+	if feed.ErrorCount > 0 {
+		lastOK := feed.CreatedAt
+		if feed.LastFetchedAt != nil {
+			lastOK = *feed.LastFetchedAt
+		}
+		if now.Sub(lastOK) >= after {
+			return fmt.Sprintf("failing for %s", days(now.Sub(lastOK)))
+		}
+	}
+
+	if feed.LastFetchedAt == nil {
+		return ""
+	}
+	since := feed.CreatedAt
+	if lastEntryAt != nil && lastEntryAt.After(since) {
+		since = *lastEntryAt
+	}
+	if now.Sub(since) >= after {
+		return fmt.Sprintf("no new entries for %s", days(now.Sub(since)))
+	}
+	return ""
+}
+
+// FindInactive returns the feeds that are inactive after the given time and
+// not marked to be kept active.
+func FindInactive(ctx context.Context, store storage.Store, after time.Duration, now time.Time) ([]InactiveFeed, error) {
+	if after <= 0 {
+		return nil, nil
+	}
+	stats, err := store.GetFeedStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed stats: %w", err)
+	}
+	lastEntries := make(map[string]*time.Time, len(stats))
+	for _, row := range stats {
+		lastEntries[row.FeedID] = row.LastEntryAt
+	}
+
+	feeds, err := store.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	var inactive []InactiveFeed
+	for _, feed := range feeds {
+		if feed.KeepActive {
+			continue
+		}
+		if reason := InactiveReason(feed, lastEntries[feed.ID], after, now); reason != "" {
+			inactive = append(inactive, InactiveFeed{Feed: feed, Reason: reason})
+		}
+	}
+	return inactive, nil
+}
+
+// ArchiveFeed archives feed so bulk syncs skip it.
+func ArchiveFeed(ctx context.Context, store storage.Store, feed *models.Feed, now time.Time) error {
+	feed.ArchivedAt = &now
+	feed.KeepActive = false
+	if err := store.UpdateFeed(ctx, feed); err != nil {
+		return fmt.Errorf("failed to archive feed: %w", err)
+	}
+	return nil
+}
+
+// UnarchiveFeed returns feed to syncing and exempts it from automatic
+// archival, since someone chose to keep it.
+func UnarchiveFeed(ctx context.Context, store storage.Store, feed *models.Feed) error {
+	feed.ArchivedAt = nil
+	feed.KeepActive = true
+	if err := store.UpdateFeed(ctx, feed); err != nil {
+		return fmt.Errorf("failed to unarchive feed: %w", err)
+	}
+	return nil
+}
+
+// days renders a duration as a whole number of days.
+func days(d time.Duration) string {
+	n := int(d / (24 * time.Hour))
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
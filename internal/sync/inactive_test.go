@@ -0,0 +1,117 @@
+// ABOUTME: Tests for dead feed detection and archival
+// ABOUTME: Verifies quiet and failing feeds are found, kept feeds are spared, and archiving round-trips
+
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestInactiveReason(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	after := 90 * 24 * time.Hour
+	ago := func(days int) *time.Time {
+		ts := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &ts
+	}
+	errMsg := "404 Not Found"
+
+	tests := []struct {
+		name      string
+		feed      models.Feed
+		lastEntry *time.Time
+		want      string
+	}{
+		{"fresh entries", models.Feed{CreatedAt: *ago(400), LastFetchedAt: ago(1)}, ago(3), ""},
+		{"quiet", models.Feed{CreatedAt: *ago(400), LastFetchedAt: ago(1)}, ago(120), "no new entries for 120 days"},
+		{"never had entries", models.Feed{CreatedAt: *ago(100), LastFetchedAt: ago(1)}, nil, "no new entries for 100 days"},
+		{"new feed without entries", models.Feed{CreatedAt: *ago(10), LastFetchedAt: ago(1)}, nil, ""},
+		{"never fetched", models.Feed{CreatedAt: *ago(400)}, nil, ""},
+		{"failing", models.Feed{CreatedAt: *ago(400), LastFetchedAt: ago(95), ErrorCount: 40, LastError: &errMsg}, ago(95), "failing for 95 days"},
+		{"failing briefly", models.Feed{CreatedAt: *ago(400), LastFetchedAt: ago(5), ErrorCount: 3, LastError: &errMsg}, ago(5), ""},
+		{"failing since added", models.Feed{CreatedAt: *ago(91), ErrorCount: 90, LastError: &errMsg}, nil, "failing for 91 days"},
+		{"paused", models.Feed{CreatedAt: *ago(400), LastFetchedAt: ago(200), Paused: true}, ago(300), ""},
+		{"already archived", models.Feed{CreatedAt: *ago(400), LastFetchedAt: ago(200), ArchivedAt: ago(10)}, ago(300), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InactiveReason(&tt.feed, tt.lastEntry, after, now); got != tt.want {
+				t.Errorf("InactiveReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	quiet := models.Feed{CreatedAt: *ago(400), LastFetchedAt: ago(1)}
+	if got := InactiveReason(&quiet, ago(300), 0, now); got != "" {
+		t.Errorf("expected detection off with a zero limit, got %q", got)
+	}
+}
+
+func TestFindInactiveAndArchive(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	defer store.Close()
+
+	now := time.Now()
+	old := now.Add(-200 * 24 * time.Hour)
+
+	newFeed := func(url string, lastEntry time.Time, keep bool) *models.Feed {
+		feed := models.NewFeed(url)
+		feed.CreatedAt = old
+		feed.LastFetchedAt = &now
+		feed.KeepActive = keep
+		if err := store.CreateFeed(ctx, feed); err != nil {
+			t.Fatalf("create feed: %v", err)
+		}
+		entry := models.NewEntry(feed.ID, url+"#1", "Post")
+		entry.CreatedAt = lastEntry
+		if err := store.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+		return feed
+	}
+	active := newFeed("https://example.com/active.xml", now.Add(-time.Hour), false)
+	dead := newFeed("https://example.com/dead.xml", old, false)
+	newFeed("https://example.com/kept.xml", old, true)
+
+	inactive, err := FindInactive(ctx, store, DefaultInactiveAfter, now)
+	if err != nil {
+		t.Fatalf("FindInactive: %v", err)
+	}
+	if len(inactive) != 1 || inactive[0].Feed.ID != dead.ID {
+		t.Fatalf("expected only the dead feed, got %+v", inactive)
+	}
+
+	if err := ArchiveFeed(ctx, store, inactive[0].Feed, now); err != nil {
+		t.Fatalf("ArchiveFeed: %v", err)
+	}
+	got, err := store.GetFeed(ctx, dead.ID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if SkipReason(got, true, now) != "archived" {
+		t.Error("expected archived feed to be skipped")
+	}
+	if inactive, _ := FindInactive(ctx, store, DefaultInactiveAfter, now); len(inactive) != 0 {
+		t.Errorf("expected no inactive feeds after archiving, got %d", len(inactive))
+	}
+
+	if err := UnarchiveFeed(ctx, store, got); err != nil {
+		t.Fatalf("UnarchiveFeed: %v", err)
+	}
+	got, _ = store.GetFeed(ctx, dead.ID)
+	if got.IsArchived() || !got.KeepActive {
+		t.Errorf("expected unarchived feed kept active, got archived=%v keep=%v", got.IsArchived(), got.KeepActive)
+	}
+	if inactive, _ := FindInactive(ctx, store, DefaultInactiveAfter, now); len(inactive) != 0 {
+		t.Errorf("expected unarchived feed to stay active, got %d inactive", len(inactive))
+	}
+
+	if SkipReason(active, false, now) != "" {
+		t.Error("expected active feed to sync")
+	}
+}
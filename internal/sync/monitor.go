@@ -0,0 +1,154 @@
+// ABOUTME: Syncs monitor feeds, which watch a web page rather than read a feed
+// ABOUTME: Compares the page's text with the last check and adds an entry showing the diff when it changes
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	xhtml "golang.org/x/net/html"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/textdiff"
+)
+
+// MonitorOptions controls what counts as a change to a monitored page.
+type MonitorOptions struct {
+	// MinWords is how many words must be added or removed for a change to
+	// be reported. Smaller changes add up until they reach it. Zero means 1.
+	MinWords int
+}
+
+// diffContext is how many unchanged lines are shown around each change.
+const diffContext = 2
+
+// maxTitleLength caps the changed line used as a change entry's title.
+const maxTitleLength = 80
+
+// syncMonitor handles a fetched page of a monitor feed. The first check
+// only records the page's text; later ones add an entry with the diff once
+// the text has changed by at least opts.Monitor.MinWords words.
+func syncMonitor(ctx context.Context, store storage.Store, feed *models.Feed, result *fetch.Result, opts Options) (*SyncResult, error) {
+	page := string(result.Body)
+	text := content.ToText(page)
+	fetchedAt := time.Now()
+
+	feedUpdated := false
+	if feed.Title == nil || *feed.Title == "" {
+		if title := pageTitle(page); title != "" {
+			feed.Title = &title
+			feedUpdated = true
+		}
+	}
+
+	newCount, filtered := 0, 0
+	switch {
+	case feed.PageText == nil:
+		feed.PageText = &text
+		feedUpdated = true
+	case *feed.PageText != text:
+		diff := textdiff.Lines(*feed.PageText, text)
+		added, removed := textdiff.Words(diff)
+		if !textdiff.Changed(diff) || max(added, removed) < max(opts.Monitor.MinWords, 1) {
+			break
+		}
+		entry := changeEntry(feed, diff, added, removed, fetchedAt)
+		feed.PageText = &text
+		feedUpdated = true
+		b := &Batch{Store: store, Feed: feed, Opts: opts, FetchedAt: fetchedAt, Entries: []*models.Entry{entry}}
+		if err := b.run(ctx, opts.entryStages()); err != nil {
+			return nil, err
+		}
+		newCount, filtered = b.Result.NewEntries, b.Result.Filtered
+	}
+
+	// Record the check as the finish stage would
+	defer opts.lockStore()()
+	ctx = context.WithoutCancel(ctx)
+
+	if err := store.UpdateFeedFetchState(ctx, feed.ID, &result.ETag, &result.LastModified, fetchedAt); err != nil {
+		return nil, fmt.Errorf("failed to update feed state: %w", err)
+	}
+	feed.ETag, feed.LastModified = &result.ETag, &result.LastModified
+	feed.LastFetchedAt = &fetchedAt
+	feed.LastError, feed.ErrorCount = nil, 0
+
+	if feedUpdated {
+		if err := store.UpdateFeed(ctx, feed); err != nil {
+			return nil, fmt.Errorf("failed to update feed: %w", err)
+		}
+	}
+	if err := PruneEntries(ctx, store, feed); err != nil {
+		return nil, err
+	}
+	return &SyncResult{NewEntries: newCount, Filtered: filtered}, nil
+}
+
+// changeEntry builds the entry reporting a change to a monitored page. It
+// is titled with the first added line, or the first removed one if nothing
+// was added, and its content is the rendered diff.
+func changeEntry(feed *models.Feed, diff []textdiff.Line, added, removed int, fetchedAt time.Time) *models.Entry {
+	var first string
+	for _, op := range []textdiff.Op{textdiff.Insert, textdiff.Delete} {
+		for _, l := range diff {
+			if l.Op == op {
+				first = l.Text
+				break
+			}
+		}
+		if first != "" {
+			break
+		}
+	}
+	if r := []rune(first); len(r) > maxTitleLength {
+		first = strings.TrimSpace(string(r[:maxTitleLength])) + "..."
+	}
+
+	// The fetch time tells versions apart, even when a page changes back
+	guid := "monitor:" + fetchedAt.UTC().Format(time.RFC3339Nano)
+	entry := storage.NewEntry(feed.ID, guid, "Changed: "+first)
+	entry.CreatedAt = fetchedAt
+	link := feed.URL
+	entry.Link = &link
+	entry.PublishedAt = &fetchedAt
+	body := fmt.Sprintf(`<p><a href="%s">%s</a> changed: %d %s added, %d removed.</p>`+"\n%s",
+		html.EscapeString(feed.URL), html.EscapeString(feed.GetDisplayName()),
+		added, plural(added, "word", "words"), removed, textdiff.HTML(diff, diffContext))
+	entry.Content = &body
+	return entry
+}
+
+// plural picks the form of a word for n of it.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// pageTitle returns the text of an HTML page's <title>, or "".
+func pageTitle(page string) string {
+	z := xhtml.NewTokenizer(strings.NewReader(page))
+	for {
+		switch z.Next() {
+		case xhtml.ErrorToken:
+			return ""
+		case xhtml.StartTagToken:
+			name, _ := z.TagName()
+			if string(name) != "title" {
+				continue
+			}
+			if z.Next() != xhtml.TextToken {
+				return ""
+			}
+			return strings.Join(strings.Fields(string(z.Text())), " ")
+		}
+	}
+}
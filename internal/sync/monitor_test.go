@@ -0,0 +1,91 @@
+// ABOUTME: Tests for syncing monitor feeds, which watch a web page for changes
+// ABOUTME: Verifies the first check only records the page and later changes add a diff entry
+
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestSyncFeed_Monitor(t *testing.T) {
+	page := `<html><head><title>Pricing</title><script>var t = 1;</script></head>
+<body><h1>Plans</h1><p>Basic costs $10 a month.</p><p>Pro costs $20 a month.</p></body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	feed := models.NewFeed(server.URL)
+	feed.Monitor = true
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	opts := Options{Force: true, Monitor: MonitorOptions{MinWords: 2}}
+	check := func() int {
+		t.Helper()
+		result, err := SyncFeedWith(ctx, store, feed, opts)
+		if err != nil {
+			t.Fatalf("SyncFeedWith: %v", err)
+		}
+		return result.NewEntries
+	}
+
+	// The first check records the page without reporting it
+	if n := check(); n != 0 {
+		t.Errorf("expected no entry on the first check, got %d", n)
+	}
+	got, err := store.GetFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	if got.GetTitle() != "Pricing" || got.PageText == nil || !strings.Contains(*got.PageText, "Basic costs $10") {
+		t.Fatalf("expected the page title and text recorded, got %q %v", got.GetTitle(), got.PageText)
+	}
+
+	// Markup and script changes don't count, and one changed word is below MinWords
+	page = strings.Replace(page, "var t = 1", "var t = 2", 1)
+	page = strings.Replace(page, "<p>Basic", `<p class="new">Basic`, 1)
+	if n := check(); n != 0 {
+		t.Errorf("expected a markup-only change to be ignored, got %d entries", n)
+	}
+	page = strings.Replace(page, "$20", "$25", 1)
+	if n := check(); n != 0 {
+		t.Errorf("expected a one-word change to be below the threshold, got %d entries", n)
+	}
+
+	// A second change adds up with the first
+	page = strings.Replace(page, "$10", "$12", 1)
+	if n := check(); n != 1 {
+		t.Fatalf("expected the accumulated change to be reported, got %d entries", n)
+	}
+	entries, _ := store.ListEntries(ctx, nil)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.GetTitle() != "Changed: Basic costs $12 a month." {
+		t.Errorf("unexpected title %q", entry.GetTitle())
+	}
+	if entry.Link == nil || *entry.Link != server.URL {
+		t.Errorf("expected the entry to link to the page, got %v", entry.Link)
+	}
+	for _, want := range []string{"- Basic costs $10 a month.", "+ Basic costs $12 a month.", "+ Pro costs $25 a month.", "  Plans"} {
+		if !strings.Contains(*entry.Content, want) {
+			t.Errorf("expected the diff to contain %q, got:\n%s", want, *entry.Content)
+		}
+	}
+
+	// The reported text becomes the new baseline
+	if n := check(); n != 0 {
+		t.Errorf("expected an unchanged page to add nothing, got %d entries", n)
+	}
+}
@@ -0,0 +1,341 @@
+// ABOUTME: The ingest pipeline a sync runs a feed through: fetch, parse, dedupe, normalize, filter, enrich, store
+// ABOUTME: Stages share a Batch, and callers add their own enrich stages through Options.Stages
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/storage"
+)
+
+// Names of the built-in pipeline stages, in the order they run.
+const (
+	StageFetch     = "fetch"
+	StageParse     = "parse"
+	StageDedupe    = "dedupe"
+	StageNormalize = "normalize"
+	StageFilter    = "filter"
+	StageEnrich    = "enrich"
+	StageStore     = "store"
+	StageFinish    = "finish"
+)
+
+// Stage is one step of the ingest pipeline. Run may change the batch, such
+// as rewriting or dropping its entries, or stop it; an error fails the sync.
+type Stage struct {
+	Name string
+	Run  func(ctx context.Context, b *Batch) error
+
+	// store marks the built-in stages that read or write the store. They
+	// run holding Options.StoreLock, on a context canceling doesn't reach,
+	// so a fetched batch is never half stored. Every other stage runs
+	// unlocked on the caller's context, which can cut downloads short.
+	store bool
+}
+
+// EntryStage returns a stage that calls fn on each new entry of the batch
+// in turn, dropping the entries it returns false for.
+func EntryStage(name string, fn func(ctx context.Context, feed *models.Feed, entry *models.Entry) bool) Stage {
+	return Stage{Name: name, Run: func(ctx context.Context, b *Batch) error {
+		b.Keep(func(entry *models.Entry) bool { return fn(ctx, b.Feed, entry) })
+		return nil
+	}}
+}
+
+// Batch is what one pass through the pipeline works on: a feed, what was
+// fetched and parsed for it, and the new entries on their way to the store.
+type Batch struct {
+	Store storage.Store
+	Feed  *models.Feed
+	Opts  Options
+
+	// Fetched and Parsed are set by the fetch and parse stages.
+	Fetched *fetch.Result
+	Parsed  *parse.ParsedFeed
+
+	// FetchedAt stamps the new entries, so the feed's last fetch picks out
+	// what this sync brought in.
+	FetchedAt time.Time
+
+	// Entries are the new entries, from the normalize stage on. Stages
+	// before store may change or drop them.
+	Entries []*models.Entry
+
+	// Result is the outcome reported once the pipeline finishes or stops.
+	Result SyncResult
+
+	unseen      []keyedEntry
+	images      map[*models.Entry]string
+	feedUpdated bool
+	stopped     bool
+}
+
+// Stop ends the pipeline after the current stage, leaving Result as it is.
+func (b *Batch) Stop() {
+	b.stopped = true
+}
+
+// Keep drops the entries keep returns false for, counting them as
+// filtered.
+func (b *Batch) Keep(keep func(entry *models.Entry) bool) {
+	kept := b.Entries[:0]
+	for _, entry := range b.Entries {
+		if keep(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	b.Result.Filtered += len(b.Entries) - len(kept)
+	b.Entries = kept
+}
+
+// run runs stages over the batch in order until one fails or stops it.
+// Canceling ctx can cut the fetch and enrichment short, but store stages
+// still run to the end; see Stage.store.
+func (b *Batch) run(ctx context.Context, stages []Stage) error {
+	storeCtx := context.WithoutCancel(ctx)
+	for _, s := range stages {
+		var err error
+		if s.store {
+			unlock := b.Opts.lockStore()
+			err = s.Run(storeCtx, b)
+			unlock()
+		} else {
+			err = s.Run(ctx, b)
+		}
+		if err != nil {
+			return err
+		}
+		if b.stopped {
+			break
+		}
+	}
+	return nil
+}
+
+// pipeline returns the stages a sync runs a feed through.
+func (o Options) pipeline() []Stage {
+	stages := []Stage{
+		{Name: StageFetch, Run: fetchStage},
+		{Name: StageParse, Run: parseStage, store: true},
+		{Name: StageDedupe, Run: dedupeStage, store: true},
+		{Name: StageNormalize, Run: normalizeStage},
+	}
+	stages = append(stages, o.entryStages()...)
+	return append(stages, Stage{Name: StageFinish, Run: finishStage, store: true})
+}
+
+// lockStore takes StoreLock, if set, and returns the func releasing it.
+func (o Options) lockStore() func() {
+	if o.StoreLock == nil {
+		return func() {}
+	}
+	o.StoreLock.Lock()
+	return o.StoreLock.Unlock
+}
+
+// entryStages returns the stages that take a batch's new entries from
+// normalized to stored: Filter, lead images, Options.Stages, NewEntry,
+// then the store.
+func (o Options) entryStages() []Stage {
+	var stages []Stage
+	if o.Filter != nil {
+		stages = append(stages, EntryStage(StageFilter, o.Filter))
+	}
+	stages = append(stages, Stage{Name: StageEnrich, Run: imageStage})
+	stages = append(stages, o.Stages...)
+	if o.NewEntry != nil {
+		stages = append(stages, Stage{Name: StageEnrich, Run: func(ctx context.Context, b *Batch) error {
+			for _, entry := range b.Entries {
+				o.NewEntry(ctx, b.Feed, entry)
+			}
+			return nil
+		}})
+	}
+	return append(stages, Stage{Name: StageStore, Run: storeStage, store: true})
+}
+
+// fetchStage fetches the feed, recording a failure on it. It stops the
+// batch when the feed is unchanged, and hands monitor feeds, which are
+// web pages, to syncMonitor. Only its store writes hold StoreLock.
+func fetchStage(ctx context.Context, b *Batch) error {
+	store, feed, opts := b.Store, b.Feed, b.Opts
+
+	// Get cache headers (skip if force)
+	var etag, lastModified *string
+	if !opts.Force {
+		etag = feed.ETag
+		lastModified = feed.LastModified
+	}
+
+	creds, err := credentials(feed, opts.Secrets)
+	if err != nil {
+		defer opts.lockStore()()
+		if updateErr := store.UpdateFeedError(ctx, feed.ID, err.Error()); updateErr != nil {
+			return fmt.Errorf("password lookup failed (%v) and error update failed: %w", err, updateErr)
+		}
+		return err
+	}
+
+	// Monitor feeds are web pages, which can't be cut at an item
+	var read func(io.Reader) ([]byte, bool, error)
+	if !feed.Monitor {
+		read = opts.readFeed
+	}
+	result, err := fetch.FetchWith(ctx, feed.URL, etag, lastModified, feed.LocalNetwork, fetch.RequestOptions{
+		Credentials: creds,
+		Browser:     feed.Browser,
+		ReadBody:    read,
+	})
+	if err != nil {
+		// A fetch cut short by the caller says nothing about the feed
+		if ctx.Err() != nil {
+			return err
+		}
+		defer opts.lockStore()()
+		errMsg := err.Error()
+		if updateErr := store.UpdateFeedError(ctx, feed.ID, errMsg); updateErr != nil {
+			return fmt.Errorf("fetch failed (%v) and error update failed: %w", err, updateErr)
+		}
+		return err
+	}
+
+	// Handle 304 Not Modified
+	if result.NotModified {
+		b.Result = SyncResult{NewEntries: 0, WasCached: true}
+		b.Stop()
+		return nil
+	}
+	b.Fetched = result
+	b.Result.Truncated = result.Truncated
+	opts.Snapshots.save(feed.ID, result.Body, time.Now())
+	if feed.Monitor {
+		monitored, err := syncMonitor(ctx, store, feed, result, opts)
+		if err != nil {
+			return err
+		}
+		b.Result = *monitored
+		b.Stop()
+	}
+	return nil
+}
+
+// parseStage parses the fetched feed, recording a failure on it, and
+// titles the feed from it if it has no title yet.
+func parseStage(ctx context.Context, b *Batch) error {
+	parsed, err := parse.Parse(b.Fetched.Body)
+	if err != nil {
+		err = fmt.Errorf("failed to parse feed: %w", err)
+		// Some challenge pages come back as 200 OK
+		if provider := fetch.ChallengePage(b.Fetched.Body); provider != "" {
+			err = fmt.Errorf("%w (%s)", fetch.ErrBotProtection, provider)
+		}
+		if updateErr := b.Store.UpdateFeedError(ctx, b.Feed.ID, err.Error()); updateErr != nil {
+			return fmt.Errorf("parse failed (%v) and error update failed: %w", err, updateErr)
+		}
+		return err
+	}
+	b.Parsed = parsed
+
+	// Update feed title if empty
+	if b.Feed.Title == nil || *b.Feed.Title == "" {
+		b.Feed.Title = &parsed.Title
+		b.feedUpdated = true
+	}
+	return nil
+}
+
+// dedupeStage finds the parsed items not stored yet, switching away from
+// GUIDs if they were regenerated since the last fetch, and skips
+// republished copies of recent entries.
+func dedupeStage(ctx context.Context, b *Batch) error {
+	feed := b.Feed
+	strategy := feed.Identity
+	if strategy == models.IdentityAuto {
+		strategy = models.IdentityGUID
+	}
+	unseen, err := unseenEntries(ctx, b.Store, feed.ID, b.Parsed.Entries, strategy)
+	if err != nil {
+		return err
+	}
+	if feed.Identity == models.IdentityAuto && feed.LastFetchedAt != nil {
+		detected, rest, err := detectIdentity(ctx, b.Store, feed.ID, len(b.Parsed.Entries), unseen)
+		if err != nil {
+			return err
+		}
+		if detected != "" {
+			feed.Identity = detected
+			b.feedUpdated = true
+			b.Result.Identity = detected
+			unseen = rest
+		}
+	}
+
+	b.FetchedAt = time.Now()
+	unseen, duplicates, err := b.Opts.Duplicates.filter(ctx, b.Store, feed, unseen, b.FetchedAt)
+	if err != nil {
+		return err
+	}
+	b.unseen = unseen
+	b.Result.Duplicates = duplicates
+	return nil
+}
+
+// normalizeStage turns the unseen items into new entries, cut to the
+// feed's backfill on its first sync.
+func normalizeStage(_ context.Context, b *Batch) error {
+	b.Entries, b.images = buildEntries(b.Feed, b.unseen, b.Opts, b.FetchedAt)
+	if b.Feed.LastFetchedAt == nil {
+		b.Entries = backfill(b.Feed, b.Entries, b.FetchedAt)
+	}
+	return nil
+}
+
+// imageStage sets new entries' lead images. Batches that didn't come from
+// parsed items, such as a monitor feed's change, have none to set.
+func imageStage(ctx context.Context, b *Batch) error {
+	if b.images == nil {
+		return nil
+	}
+	for _, entry := range b.Entries {
+		b.Opts.Images.apply(ctx, entry, b.images[entry], b.Feed.LocalNetwork)
+	}
+	return nil
+}
+
+// storeStage saves the new entries.
+func storeStage(ctx context.Context, b *Batch) error {
+	for _, entry := range b.Entries {
+		if err := b.Store.CreateEntry(ctx, entry); err != nil {
+			return fmt.Errorf("failed to create entry: %w", err)
+		}
+		b.Result.NewEntries++
+	}
+	return nil
+}
+
+// finishStage records the fetch on the feed and prunes it to its limit.
+func finishStage(ctx context.Context, b *Batch) error {
+	store, feed, result := b.Store, b.Feed, b.Fetched
+	if err := store.UpdateFeedFetchState(ctx, feed.ID, &result.ETag, &result.LastModified, b.FetchedAt); err != nil {
+		return fmt.Errorf("failed to update feed state: %w", err)
+	}
+	// Mirror the stored fetch state so UpdateFeed below doesn't write back stale values
+	feed.ETag, feed.LastModified = &result.ETag, &result.LastModified
+	feed.LastFetchedAt = &b.FetchedAt
+	feed.LastError, feed.ErrorCount = nil, 0
+
+	// Update feed if title or identity changed
+	if b.feedUpdated {
+		if err := store.UpdateFeed(ctx, feed); err != nil {
+			return fmt.Errorf("failed to update feed: %w", err)
+		}
+	}
+	return PruneEntries(ctx, store, feed)
+}
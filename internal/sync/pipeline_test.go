@@ -0,0 +1,205 @@
+// ABOUTME: Tests for the ingest pipeline
+// ABOUTME: Checks stage order, custom stages that enrich or drop entries, stage failures, cancellation, and locking
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+const pipelineFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Pipeline Feed</title>
+    <item><title>First</title><guid>g1</guid></item>
+    <item><title>Second</title><guid>g2</guid></item>
+    <item><title>Sponsored: third</title><guid>g3</guid></item>
+  </channel>
+</rss>`
+
+func pipelineServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pipelineFeed))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPipeline_CustomStages(t *testing.T) {
+	server := pipelineServer(t)
+	store := newTestStore(t)
+	defer store.Close()
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	var order []string
+	opts := Options{
+		Filter: func(_ context.Context, _ *models.Feed, e *models.Entry) bool {
+			order = append(order, "filter")
+			return !strings.HasPrefix(e.GetTitle(), "Sponsored")
+		},
+		Stages: []Stage{
+			{Name: "tag", Run: func(_ context.Context, b *Batch) error {
+				order = append(order, "tag")
+				if b.Parsed == nil || b.Parsed.Title != "Pipeline Feed" {
+					t.Errorf("stage saw parsed feed %+v", b.Parsed)
+				}
+				for _, e := range b.Entries {
+					e.SetTags([]string{"pipeline"})
+				}
+				return nil
+			}},
+			EntryStage("drop-second", func(_ context.Context, _ *models.Feed, e *models.Entry) bool {
+				return e.GUID != "g2"
+			}),
+		},
+		NewEntry: func(_ context.Context, _ *models.Feed, e *models.Entry) {
+			order = append(order, "hook")
+			if len(e.Tags()) == 0 {
+				t.Errorf("NewEntry saw %s before the tag stage", e.GUID)
+			}
+		},
+	}
+	result, err := SyncFeedWith(context.Background(), store, feed, opts)
+	if err != nil {
+		t.Fatalf("SyncFeedWith: %v", err)
+	}
+	if result.NewEntries != 1 || result.Filtered != 2 {
+		t.Errorf("result = %+v, want 1 new and 2 filtered", result)
+	}
+	if got := strings.Join(order, ","); got != "filter,filter,filter,tag,hook" {
+		t.Errorf("order = %s", got)
+	}
+
+	entries, err := store.ListEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].GUID != "g1" {
+		t.Fatalf("stored %d entries, want only g1", len(entries))
+	}
+	if tags := entries[0].Tags(); len(tags) != 1 || tags[0] != "pipeline" {
+		t.Errorf("stored tags = %v, want [pipeline]", tags)
+	}
+}
+
+func TestPipeline_StageErrorFailsSync(t *testing.T) {
+	server := pipelineServer(t)
+	store := newTestStore(t)
+	defer store.Close()
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	boom := errors.New("enrichment unavailable")
+	opts := Options{Stages: []Stage{{Name: "boom", Run: func(context.Context, *Batch) error { return boom }}}}
+	if _, err := SyncFeedWith(context.Background(), store, feed, opts); !errors.Is(err, boom) {
+		t.Fatalf("SyncFeedWith error = %v, want the stage's", err)
+	}
+
+	entries, err := store.ListEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("stored %d entries after a failed stage, want none", len(entries))
+	}
+	got, err := store.GetFeed(context.Background(), feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	if got.LastFetchedAt != nil {
+		t.Error("a failed sync shouldn't record a fetch")
+	}
+}
+
+func TestPipeline_CancelAfterFetchStoresBatch(t *testing.T) {
+	server := pipelineServer(t)
+	store := newTestStore(t)
+	defer store.Close()
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hooked := 0
+	opts := Options{
+		Stages: []Stage{{Name: "interrupt", Run: func(context.Context, *Batch) error {
+			cancel()
+			return nil
+		}}},
+		// Hooks and downloads after the cancel see it and can give up
+		NewEntry: func(ctx context.Context, _ *models.Feed, _ *models.Entry) {
+			if ctx.Err() != nil {
+				hooked++
+			}
+		},
+	}
+	result, err := SyncFeedWith(ctx, store, feed, opts)
+	if err != nil {
+		t.Fatalf("SyncFeedWith: %v", err)
+	}
+	if result.NewEntries != 3 {
+		t.Errorf("stored %d entries after a cancel past fetch, want all 3", result.NewEntries)
+	}
+	if hooked != 3 {
+		t.Errorf("NewEntry saw the cancel for %d entries, want 3", hooked)
+	}
+}
+
+func TestPipeline_StoreLockOnlyAroundStore(t *testing.T) {
+	server := pipelineServer(t)
+	store := newTestStore(t)
+	defer store.Close()
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	var mu gosync.Mutex
+	unlocked := func(what string) {
+		if !mu.TryLock() {
+			t.Errorf("%s ran holding StoreLock", what)
+			return
+		}
+		mu.Unlock()
+	}
+	opts := Options{
+		StoreLock: &mu,
+		Filter: func(context.Context, *models.Feed, *models.Entry) bool {
+			unlocked("Filter")
+			return true
+		},
+		Stages: []Stage{{Name: "enrich", Run: func(context.Context, *Batch) error {
+			unlocked("a stage")
+			return nil
+		}}},
+		NewEntry: func(context.Context, *models.Feed, *models.Entry) {
+			unlocked("NewEntry")
+		},
+	}
+	result, err := SyncFeedWith(context.Background(), store, feed, opts)
+	if err != nil {
+		t.Fatalf("SyncFeedWith: %v", err)
+	}
+	if result.NewEntries != 3 {
+		t.Errorf("stored %d entries, want 3", result.NewEntries)
+	}
+	if !mu.TryLock() {
+		t.Error("StoreLock still held after the sync")
+	}
+}
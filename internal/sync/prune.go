@@ -0,0 +1,143 @@
+// ABOUTME: Retention pruning that keeps each feed to its max_entries
+// ABOUTME: Plans what a prune would delete, with the room it frees, so it can be reviewed before it runs
+
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// PrunePlan is what pruning feeds to their max_entries would delete. Only
+// feeds with something to delete are listed.
+type PrunePlan struct {
+	Feeds []FeedPrune `json:"feeds"`
+
+	// Totals over Feeds
+	Entries int   `json:"entries"`
+	Files   int   `json:"files"`
+	FTSRows int   `json:"fts_rows"`
+	Bytes   int64 `json:"bytes"`
+	// Measured is false when the store can't tell the room entries take,
+	// leaving Files, FTSRows, and Bytes at zero.
+	Measured bool `json:"measured"`
+}
+
+// FeedPrune is one feed's part of a PrunePlan.
+type FeedPrune struct {
+	FeedID     string `json:"feed_id"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	MaxEntries int    `json:"max_entries"`
+	// Stored is how many entries the feed has now.
+	Stored int `json:"stored"`
+	// KeptUnread counts entries past the limit that are kept unread and
+	// so stay.
+	KeptUnread int `json:"kept_unread,omitempty"`
+
+	Entries int   `json:"entries"`
+	Files   int   `json:"files"`
+	FTSRows int   `json:"fts_rows"`
+	Bytes   int64 `json:"bytes"`
+
+	// Pruned are the entries to delete, newest first.
+	Pruned []*models.Entry `json:"-"`
+}
+
+// PlanPrune works out what pruning feeds to their max_entries would
+// delete, without deleting anything. Feeds without a limit are left out.
+func PlanPrune(ctx context.Context, store storage.Store, feeds []*models.Feed) (*PrunePlan, error) {
+	plan := &PrunePlan{Feeds: []FeedPrune{}}
+	for _, feed := range feeds {
+		fp, err := planFeedPrune(ctx, store, feed)
+		if err != nil {
+			return nil, err
+		}
+		if fp == nil || len(fp.Pruned) == 0 {
+			continue
+		}
+		plan.Feeds = append(plan.Feeds, *fp)
+		plan.Entries += fp.Entries
+	}
+	return plan, nil
+}
+
+// planFeedPrune returns what pruning feed would delete, or nil if it has
+// no limit.
+func planFeedPrune(ctx context.Context, store storage.Store, feed *models.Feed) (*FeedPrune, error) {
+	if feed.MaxEntries <= 0 {
+		return nil, nil
+	}
+
+	// ListEntries returns newest first, so everything past the limit is oldest
+	entries, err := store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries for pruning: %w", err)
+	}
+	fp := &FeedPrune{
+		FeedID:     feed.ID,
+		Title:      feed.GetDisplayName(),
+		URL:        feed.URL,
+		MaxEntries: feed.MaxEntries,
+		Stored:     len(entries),
+	}
+	if len(entries) <= feed.MaxEntries {
+		return fp, nil
+	}
+	for _, entry := range entries[feed.MaxEntries:] {
+		if entry.KeepUnread {
+			fp.KeptUnread++
+			continue
+		}
+		fp.Pruned = append(fp.Pruned, entry)
+	}
+	fp.Entries = len(fp.Pruned)
+	return fp, nil
+}
+
+// Measure fills in the files, search index rows, and bytes the plan's
+// entries take up in a store.
+func (p *PrunePlan) Measure(ctx context.Context, store storage.Footprinter) error {
+	p.Files, p.FTSRows, p.Bytes = 0, 0, 0
+	for i := range p.Feeds {
+		f := &p.Feeds[i]
+		size, err := store.EntryFootprint(ctx, f.Pruned)
+		if err != nil {
+			return fmt.Errorf("failed to measure entries of %s: %w", f.URL, err)
+		}
+		f.Files, f.FTSRows, f.Bytes = size.Files, size.FTSRows, size.Bytes
+		p.Files += f.Files
+		p.FTSRows += f.FTSRows
+		p.Bytes += f.Bytes
+	}
+	p.Measured = true
+	return nil
+}
+
+// Apply deletes the plan's entries and returns how many were deleted.
+func (p *PrunePlan) Apply(ctx context.Context, store storage.Store) (int, error) {
+	deleted := 0
+	for _, f := range p.Feeds {
+		for _, entry := range f.Pruned {
+			if err := store.DeleteEntry(ctx, entry.ID); err != nil {
+				return deleted, fmt.Errorf("failed to prune entry: %w", err)
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// PruneEntries deletes the oldest entries of a feed beyond its MaxEntries
+// limit. Entries kept unread are never pruned.
+func PruneEntries(ctx context.Context, store storage.Store, feed *models.Feed) error {
+	fp, err := planFeedPrune(ctx, store, feed)
+	if err != nil || fp == nil {
+		return err
+	}
+	_, err = (&PrunePlan{Feeds: []FeedPrune{*fp}}).Apply(ctx, store)
+	return err
+}
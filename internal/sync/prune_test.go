@@ -0,0 +1,103 @@
+// ABOUTME: Tests for retention prune plans
+// ABOUTME: Checks per-feed counts, measured room on both backends, and that only Apply deletes
+
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestPlanPrune(t *testing.T) {
+	for name, open := range map[string]func(dir string) (storage.Store, error){
+		"sqlite": func(dir string) (storage.Store, error) {
+			return storage.NewSQLiteStore(filepath.Join(dir, "digest.db"))
+		},
+		"markdown": func(dir string) (storage.Store, error) {
+			return storage.NewMarkdownStore(dir)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store, err := open(t.TempDir())
+			if err != nil {
+				t.Fatalf("open store: %v", err)
+			}
+			defer store.Close()
+
+			limited := models.NewFeed("https://example.com/limited.xml")
+			limited.MaxEntries = 1
+			unlimited := models.NewFeed("https://example.com/unlimited.xml")
+			var entries []*models.Entry
+			for _, feed := range []*models.Feed{limited, unlimited} {
+				if err := store.CreateFeed(ctx, feed); err != nil {
+					t.Fatalf("CreateFeed: %v", err)
+				}
+				for i, guid := range []string{"g1", "g2", "g3"} {
+					published := time.Date(2006, 1, 2+i, 15, 4, 5, 0, time.UTC)
+					e := models.NewEntry(feed.ID, guid, "Entry "+guid)
+					e.PublishedAt = &published
+					content := "Some content worth measuring"
+					e.Content = &content
+					if err := store.CreateEntry(ctx, e); err != nil {
+						t.Fatalf("CreateEntry: %v", err)
+					}
+					if feed == limited {
+						entries = append(entries, e)
+					}
+				}
+			}
+			// The oldest is kept unread, leaving only g2 to prune
+			if err := store.SetEntryKeepUnread(ctx, entries[0].ID, true); err != nil {
+				t.Fatalf("SetEntryKeepUnread: %v", err)
+			}
+
+			plan, err := PlanPrune(ctx, store, []*models.Feed{limited, unlimited})
+			if err != nil {
+				t.Fatalf("PlanPrune: %v", err)
+			}
+			if len(plan.Feeds) != 1 || plan.Entries != 1 {
+				t.Fatalf("plan = %+v, want one entry of one feed", plan)
+			}
+			f := plan.Feeds[0]
+			if f.FeedID != limited.ID || f.Stored != 3 || f.KeptUnread != 1 || f.Pruned[0].GUID != "g2" {
+				t.Errorf("feed plan = %+v", f)
+			}
+
+			sizer, ok := store.(storage.Footprinter)
+			if !ok {
+				t.Fatal("store doesn't implement Footprinter")
+			}
+			if err := plan.Measure(ctx, sizer); err != nil {
+				t.Fatalf("Measure: %v", err)
+			}
+			if !plan.Measured || plan.Bytes <= 0 || plan.Bytes != plan.Feeds[0].Bytes {
+				t.Errorf("measured %d bytes in total and %d for the feed, want the same nonzero count", plan.Bytes, plan.Feeds[0].Bytes)
+			}
+			wantFiles, wantRows := 0, 1
+			if name == "markdown" {
+				wantFiles, wantRows = 1, 0
+			}
+			if plan.Files != wantFiles || plan.FTSRows != wantRows {
+				t.Errorf("files %d, fts rows %d; want %d and %d", plan.Files, plan.FTSRows, wantFiles, wantRows)
+			}
+
+			// Planning and measuring delete nothing
+			if stored, _ := store.ListEntries(ctx, nil); len(stored) != 6 {
+				t.Fatalf("%d entries after planning, want 6", len(stored))
+			}
+			deleted, err := plan.Apply(ctx, store)
+			if err != nil || deleted != 1 {
+				t.Fatalf("Apply = %d, %v; want 1 deleted", deleted, err)
+			}
+			if _, err := store.GetEntry(ctx, f.Pruned[0].ID); err == nil {
+				t.Error("the pruned entry is still stored")
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// ABOUTME: Refreshes one stored entry from its feed, or from its article page once the feed has dropped it
+// ABOUTME: Updates the entry in place and reports which fields changed and how its text changed
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/textdiff"
+)
+
+// Where RefreshEntry found an entry's current version.
+const (
+	RefreshFromFeed = "feed"
+	RefreshFromPage = "page"
+)
+
+// RefreshResult says what RefreshEntry changed.
+type RefreshResult struct {
+	// Source is RefreshFromFeed or RefreshFromPage.
+	Source string
+	// Changed names the fields that changed by their JSON names.
+	Changed []string
+	// WordsAdded and WordsRemoved count the changes to the entry's text.
+	WordsAdded   int
+	WordsRemoved int
+	// Diff is a unified diff of the entry's text, or "" if it didn't change.
+	Diff string
+}
+
+// ErrMonitorEntry is returned for entries of monitor feeds, which record a
+// page's changes and have nothing to refresh.
+var ErrMonitorEntry = errors.New("entries of monitor feeds can't be refreshed")
+
+// RefreshEntry refetches the feed of entry and updates the entry in place
+// from its item, as Reprocess does. If the feed no longer has the item, the
+// entry's content is replaced with the article of the page it links to.
+// Read state, archive links, and scores are kept. Nothing is written if
+// nothing changed. Only opts.Secrets and opts.Dates are used.
+func RefreshEntry(ctx context.Context, store storage.Store, feed *models.Feed, entry *models.Entry, opts Options) (*RefreshResult, error) {
+	if feed.Monitor {
+		return nil, ErrMonitorEntry
+	}
+	before := *entry
+
+	result := &RefreshResult{Source: RefreshFromFeed}
+	item, err := currentItem(ctx, feed, entry, opts)
+	switch {
+	case item != nil:
+		setParsed(entry, *item, opts.Dates)
+		if image := item.Image; image != "" {
+			entry.ImageURL = &image
+		}
+	case derefString(entry.Link) == "":
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("the feed no longer has the entry and it has no link")
+	default:
+		// The feed was unreachable or has moved on; go to the article itself
+		article, pageErr := pageArticle(ctx, feed, *entry.Link)
+		if pageErr != nil {
+			if err != nil {
+				return nil, fmt.Errorf("%w; fetching the article also failed: %v", err, pageErr)
+			}
+			return nil, pageErr
+		}
+		result.Source = RefreshFromPage
+		entry.Content = &article
+		entry.ContentHash = ContentHash(article)
+	}
+
+	result.Changed = changedFields(&before, entry)
+	if len(result.Changed) == 0 {
+		return result, nil
+	}
+	diff := textdiff.Lines(content.ToText(derefString(before.Content)), content.ToText(derefString(entry.Content)))
+	if textdiff.Changed(diff) {
+		result.WordsAdded, result.WordsRemoved = textdiff.Words(diff)
+		result.Diff = textdiff.Unified(diff, diffContext)
+	}
+	if err := store.UpdateEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to update entry: %w", err)
+	}
+	return result, nil
+}
+
+// currentItem fetches feed and returns its item for entry, or nil if the
+// feed no longer has it. Items are matched by identity key, then by link,
+// since a corrected title changes the key of a feed identified by hashes.
+// The fetch skips the cache so a corrected item is seen even if the feed
+// claims nothing changed.
+func currentItem(ctx context.Context, feed *models.Feed, entry *models.Entry, opts Options) (*parse.ParsedEntry, error) {
+	creds, err := credentials(feed, opts.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	result, err := fetch.FetchWith(ctx, feed.URL, nil, nil, feed.LocalNetwork, fetch.RequestOptions{
+		Credentials: creds,
+		Browser:     feed.Browser,
+		ReadBody:    opts.readFeed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parse.Parse(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	strategy := feed.Identity
+	if strategy == models.IdentityAuto {
+		strategy = models.IdentityGUID
+	}
+	stored := storedKey(entry, strategy)
+	for i, item := range parsed.Entries {
+		if key := EntryKey(item, strategy); key == entry.GUID || key == stored {
+			return &parsed.Entries[i], nil
+		}
+	}
+	if link := derefString(entry.Link); link != "" {
+		for i, item := range parsed.Entries {
+			if item.Link == link {
+				return &parsed.Entries[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// articleSkip are elements left out of an article: scripts and page chrome.
+var articleSkip = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+}
+
+// pageArticle fetches an article page and returns the HTML of its <article>,
+// or of <main> or <body> when it has none, without scripts and page chrome.
+func pageArticle(ctx context.Context, feed *models.Feed, link string) (string, error) {
+	result, err := fetch.FetchPage(ctx, link, fetch.PageOptions{
+		AllowLocalNetwork: feed.LocalNetwork,
+		Browser:           feed.Browser,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch article: %w", err)
+	}
+	doc, err := xhtml.Parse(strings.NewReader(string(result.Body)))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse article: %w", err)
+	}
+
+	var root *xhtml.Node
+	for _, tag := range []string{"article", "main", "body"} {
+		if root = findElement(doc, tag); root != nil {
+			break
+		}
+	}
+	if root == nil {
+		return "", fmt.Errorf("article page has no body")
+	}
+	pruneElements(root)
+	var b strings.Builder
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if err := xhtml.Render(&b, c); err != nil {
+			return "", fmt.Errorf("failed to render article: %w", err)
+		}
+	}
+	article := strings.TrimSpace(b.String())
+	if content.ToText(article) == "" {
+		return "", fmt.Errorf("article page has no text")
+	}
+	return article, nil
+}
+
+// findElement returns the first element named tag under n, depth first.
+func findElement(n *xhtml.Node, tag string) *xhtml.Node {
+	if n.Type == xhtml.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// pruneElements removes the articleSkip elements under n.
+func pruneElements(n *xhtml.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == xhtml.ElementNode && articleSkip[c.Data] {
+			n.RemoveChild(c)
+		} else {
+			pruneElements(c)
+		}
+		c = next
+	}
+}
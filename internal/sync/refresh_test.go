@@ -0,0 +1,109 @@
+// ABOUTME: Tests for refreshing a single stored entry
+// ABOUTME: Covers corrections picked up from the feed and the article page fallback
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+)
+
+func TestRefreshEntry(t *testing.T) {
+	feedBody := `<?xml version="1.0"?><rss version="2.0"><channel><title>Refresh</title>
+<item><title>Fixed title</title><guid>r1</guid><description>The corrected text of the post.</description></item>
+</channel></rss>`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feedBody))
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><nav>Home</nav><article><p>The article as it reads now.</p><script>track()</script></article></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	feed := models.NewFeed(server.URL + "/feed.xml")
+	feed.LocalNetwork = true
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	t.Run("from feed", func(t *testing.T) {
+		entry := models.NewEntry(feed.ID, "r1", "Typo title")
+		old := "The original text of the post."
+		entry.Content = &old
+		entry.MarkRead()
+		if err := store.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+
+		result, err := RefreshEntry(ctx, store, feed, entry, Options{})
+		if err != nil {
+			t.Fatalf("RefreshEntry: %v", err)
+		}
+		if result.Source != RefreshFromFeed {
+			t.Errorf("Source = %q, want feed", result.Source)
+		}
+		if !slices.Contains(result.Changed, "title") || !slices.Contains(result.Changed, "content") {
+			t.Errorf("Changed = %v, want title and content", result.Changed)
+		}
+		if result.WordsAdded != 1 || result.WordsRemoved != 1 {
+			t.Errorf("words +%d -%d, want +1 -1", result.WordsAdded, result.WordsRemoved)
+		}
+		if !strings.Contains(result.Diff, "+ The corrected text") || !strings.Contains(result.Diff, "- The original text") {
+			t.Errorf("Diff = %q", result.Diff)
+		}
+
+		stored, err := store.GetEntry(ctx, entry.ID)
+		if err != nil {
+			t.Fatalf("GetEntry: %v", err)
+		}
+		if *stored.Title != "Fixed title" || !stored.Read {
+			t.Errorf("stored entry = %q read %v, want the fixed title and still read", *stored.Title, stored.Read)
+		}
+
+		again, err := RefreshEntry(ctx, store, feed, stored, Options{})
+		if err != nil || len(again.Changed) != 0 {
+			t.Errorf("second refresh = %+v, %v; want no changes", again, err)
+		}
+	})
+
+	t.Run("from page", func(t *testing.T) {
+		entry := models.NewEntry(feed.ID, "dropped", "Dropped")
+		link := server.URL + "/gone"
+		entry.Link = &link
+		if err := store.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+
+		result, err := RefreshEntry(ctx, store, feed, entry, Options{})
+		if err != nil {
+			t.Fatalf("RefreshEntry: %v", err)
+		}
+		if result.Source != RefreshFromPage || !slices.Equal(result.Changed, []string{"content"}) {
+			t.Errorf("result = %+v, want content changed from the page", result)
+		}
+		if got := derefString(entry.Content); got != "<p>The article as it reads now.</p>" {
+			t.Errorf("Content = %q", got)
+		}
+	})
+
+	t.Run("monitor feed", func(t *testing.T) {
+		monitor := models.NewFeed(server.URL + "/gone")
+		monitor.Monitor = true
+		entry := models.NewEntry(monitor.ID, "monitor:1", "Changed: x")
+		if _, err := RefreshEntry(ctx, store, monitor, entry, Options{}); !errors.Is(err, ErrMonitorEntry) {
+			t.Errorf("err = %v, want ErrMonitorEntry", err)
+		}
+	})
+}
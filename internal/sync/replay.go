@@ -0,0 +1,100 @@
+// ABOUTME: Reprocesses a stored raw feed response as if it had just been fetched
+// ABOUTME: Reports which of its items are stored and can import the ones that are missing
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/storage"
+)
+
+// ReplayResult describes a feed response as the current parser reads it.
+type ReplayResult struct {
+	Title   string
+	Entries []ReplayEntry
+	// NewEntries is how many missing entries the replay stored; always 0
+	// unless it was asked to import them.
+	NewEntries int
+}
+
+// ReplayEntry is one item of a replayed response.
+type ReplayEntry struct {
+	// Key is what the feed's identity strategy matches the item by.
+	Key         string
+	Title       string
+	Link        string
+	PublishedAt *time.Time
+	// Stored reports whether the item was already stored before the replay.
+	Stored bool
+}
+
+// Missing returns how many of the response's items weren't stored.
+func (r *ReplayResult) Missing() int {
+	n := 0
+	for _, e := range r.Entries {
+		if !e.Stored {
+			n++
+		}
+	}
+	return n
+}
+
+// Replay parses body, a response fetched for feed earlier, and matches its
+// items against the stored entries using the feed's identity strategy. With
+// apply it stores the missing items as a sync would, except that the feed's
+// fetch state, first-sync backfill, and identity are left alone.
+func Replay(ctx context.Context, store storage.Store, feed *models.Feed, body []byte, opts Options, apply bool) (*ReplayResult, error) {
+	parsed, err := parse.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	strategy := feed.Identity
+	if strategy == models.IdentityAuto {
+		strategy = models.IdentityGUID
+	}
+	unseen, err := unseenEntries(ctx, store, feed.ID, parsed.Entries, strategy)
+	if err != nil {
+		return nil, err
+	}
+	missing := make(map[string]bool, len(unseen))
+	for _, ke := range unseen {
+		missing[ke.key] = true
+	}
+
+	result := &ReplayResult{Title: parsed.Title}
+	seen := make(map[string]bool, len(parsed.Entries))
+	for _, e := range parsed.Entries {
+		key := EntryKey(e, strategy)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result.Entries = append(result.Entries, ReplayEntry{
+			Key:         key,
+			Title:       e.Title,
+			Link:        e.Link,
+			PublishedAt: e.PublishedAt,
+			Stored:      !missing[key],
+		})
+	}
+
+	if !apply || len(unseen) == 0 {
+		return result, nil
+	}
+	b := &Batch{Store: store, Feed: feed, Opts: opts, Parsed: parsed, FetchedAt: time.Now()}
+	b.Entries, b.images = buildEntries(feed, unseen, opts, b.FetchedAt)
+	err = b.run(ctx, opts.entryStages())
+	result.NewEntries = b.Result.NewEntries
+	if err != nil {
+		return result, err
+	}
+	if err := PruneEntries(ctx, store, feed); err != nil {
+		return result, err
+	}
+	return result, nil
+}
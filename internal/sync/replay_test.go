@@ -0,0 +1,178 @@
+// ABOUTME: Tests for raw response snapshots taken during sync, replaying them, and reprocessing entries
+// ABOUTME: Verifies snapshots rotate, replay imports missing entries, and reprocess updates stored ones
+
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/snapshot"
+)
+
+const replayFeed = `<?xml version="1.0"?><rss version="2.0"><channel><title>Replay</title>
+<item><title>One</title><guid>r1</guid></item>
+<item><title>Two</title><guid>r2</guid></item>
+</channel></rss>`
+
+func TestSyncFeed_SavesSnapshots(t *testing.T) {
+	body := replayFeed
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	dir := t.TempDir()
+	opts := Options{Force: true, Snapshots: SnapshotOptions{Dir: dir, Keep: 2}}
+	for _, title := range []string{"A", "B", "C"} {
+		body = `<?xml version="1.0"?><rss version="2.0"><channel><title>` + title + `</title></channel></rss>`
+		if _, err := SyncFeedWith(ctx, store, feed, opts); err != nil {
+			t.Fatalf("SyncFeedWith: %v", err)
+		}
+	}
+
+	snapshots, err := snapshot.List(dir, feed.ID)
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots kept, got %d (%v)", len(snapshots), err)
+	}
+	newest, err := snapshot.Read(snapshots[0].Path)
+	if err != nil || string(newest) != body {
+		t.Errorf("expected the newest snapshot to hold the last response, got %q (%v)", newest, err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if err := store.CreateEntry(ctx, models.NewEntry(feed.ID, "r1", "One")); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+
+	result, err := Replay(ctx, store, feed, []byte(replayFeed), Options{}, false)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if result.Title != "Replay" || len(result.Entries) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if !result.Entries[0].Stored || result.Entries[1].Stored || result.Missing() != 1 {
+		t.Errorf("expected only r2 missing, got %+v", result.Entries)
+	}
+	if result.NewEntries != 0 {
+		t.Errorf("expected a dry replay to store nothing, got %d", result.NewEntries)
+	}
+	if exists, _ := store.EntryExists(ctx, feed.ID, "r2"); exists {
+		t.Error("expected r2 not stored by a dry replay")
+	}
+
+	result, err = Replay(ctx, store, feed, []byte(replayFeed), Options{}, true)
+	if err != nil {
+		t.Fatalf("Replay apply: %v", err)
+	}
+	if result.NewEntries != 1 {
+		t.Errorf("expected 1 entry imported, got %d", result.NewEntries)
+	}
+	if exists, _ := store.EntryExists(ctx, feed.ID, "r2"); !exists {
+		t.Error("expected r2 stored by the replay")
+	}
+
+	got, err := store.GetFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	if got.LastFetchedAt != nil {
+		t.Error("expected replay to leave the fetch state alone")
+	}
+
+	if _, err := Replay(ctx, store, feed, []byte("<html>not a feed</html>"), Options{}, false); err == nil {
+		t.Error("expected an error replaying a body that isn't a feed")
+	}
+}
+
+func TestReprocess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Reprocess</title>
+<item><title>Old title</title><guid>p1</guid><pubDate>Mon, 02 Mar 2026 10:00:00 GMT</pubDate></item>
+<item><title>Untouched</title><guid>p2</guid><pubDate>Mon, 02 Mar 2026 11:00:00 GMT</pubDate><description>&lt;img src="https://example.com/hero.jpg"&gt;</description></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if _, err := SyncFeed(ctx, store, feed, false); err != nil {
+		t.Fatalf("SyncFeed: %v", err)
+	}
+	entries, _ := store.ListEntries(ctx, nil)
+	for _, e := range entries {
+		// Simulate an entry stored before lead images were taken from content
+		e.ImageURL = nil
+		e.MarkRead()
+		if err := store.UpdateEntry(ctx, e); err != nil {
+			t.Fatalf("UpdateEntry: %v", err)
+		}
+	}
+
+	fixed := []byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Reprocess</title>
+<item><title>Fixed title</title><guid>p1</guid><pubDate>Mon, 02 Mar 2026 10:00:00 GMT</pubDate></item>
+</channel></rss>`)
+	bodies := [][]byte{[]byte("not a feed"), fixed}
+
+	result, err := Reprocess(ctx, store, feed, bodies, DatePolicy{}, false)
+	if err != nil {
+		t.Fatalf("Reprocess dry run: %v", err)
+	}
+	if result.Snapshots != 1 || result.Failed != 1 || result.Matched != 1 || result.Updated != 2 {
+		t.Errorf("unexpected dry run result %+v", result)
+	}
+	if entries, _ := store.ListEntries(ctx, nil); entriesByGUID(entries)["p1"].GetTitle() != "Old title" {
+		t.Error("expected a dry run to change nothing")
+	}
+
+	if _, err := Reprocess(ctx, store, feed, bodies, DatePolicy{}, true); err != nil {
+		t.Fatalf("Reprocess: %v", err)
+	}
+	entries, _ = store.ListEntries(ctx, nil)
+	byGUID := entriesByGUID(entries)
+	if byGUID["p1"].GetTitle() != "Fixed title" || !byGUID["p1"].Read {
+		t.Errorf("expected p1 retitled and still read, got %q read=%v", byGUID["p1"].GetTitle(), byGUID["p1"].Read)
+	}
+	if img := byGUID["p2"].ImageURL; img == nil || *img != "https://example.com/hero.jpg" {
+		t.Errorf("expected p2's lead image taken from its content, got %v", img)
+	}
+
+	// Reprocessing again finds nothing left to change
+	result, err = Reprocess(ctx, store, feed, bodies, DatePolicy{}, true)
+	if err != nil || result.Updated != 0 {
+		t.Errorf("expected a second run to change nothing, got %+v (%v)", result, err)
+	}
+}
+
+func entriesByGUID(entries []*models.Entry) map[string]*models.Entry {
+	byGUID := make(map[string]*models.Entry, len(entries))
+	for _, e := range entries {
+		byGUID[e.GUID] = e
+	}
+	return byGUID
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Re-derives stored entries from raw feed snapshots or their stored content
+// ABOUTME: Lets parser fixes reach existing entries without refetching, keeping read state and scores
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/storage"
+	"github.com/harper/digest/internal/thumbnail"
+)
+
+// ReprocessResult counts what Reprocess found and changed.
+type ReprocessResult struct {
+	// Snapshots is how many raw responses parsed.
+	Snapshots int
+	// Failed is how many raw responses couldn't be parsed.
+	Failed int
+	// Matched is how many stored entries appeared in a response.
+	Matched int
+	// Updated is how many entries changed, or would change on a dry run.
+	Updated int
+}
+
+// Reprocess parses bodies, earlier responses of feed ordered oldest first,
+// and updates the stored entries they contain from the newest item seen
+// for each: title, link, author, content, dates, comment links, lead image,
+// and extensions. Read state, archive links, and scores are kept. Entries in
+// none of the responses only get a lead image from their stored content if
+// they have none. Without apply nothing is written, but Updated still counts
+// the entries that would change.
+func Reprocess(ctx context.Context, store storage.Store, feed *models.Feed, bodies [][]byte, dates DatePolicy, apply bool) (*ReprocessResult, error) {
+	strategy := feed.Identity
+	if strategy == models.IdentityAuto {
+		strategy = models.IdentityGUID
+	}
+
+	result := &ReprocessResult{}
+	items := make(map[string]parse.ParsedEntry)
+	for _, body := range bodies {
+		parsed, err := parse.Parse(body)
+		if err != nil {
+			result.Failed++
+			continue
+		}
+		result.Snapshots++
+		for _, item := range parsed.Entries {
+			items[EntryKey(item, strategy)] = item
+		}
+	}
+
+	entries, err := store.ListEntries(ctx, &storage.EntryFilter{FeedID: &feed.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+	for _, entry := range entries {
+		before := *entry
+		item, ok := items[entry.GUID]
+		if !ok {
+			item, ok = items[storedKey(entry, strategy)]
+		}
+		if ok {
+			result.Matched++
+			setParsed(entry, item, dates)
+			if image := item.Image; image != "" {
+				entry.ImageURL = &image
+			}
+		}
+		if entry.ImageURL == nil && entry.Content != nil {
+			if image := thumbnail.FromHTML(*entry.Content, derefString(entry.Link)); image != "" {
+				entry.ImageURL = &image
+			}
+		}
+		if !entryChanged(&before, entry) {
+			continue
+		}
+		result.Updated++
+		if apply {
+			if err := store.UpdateEntry(ctx, entry); err != nil {
+				return result, fmt.Errorf("failed to update entry: %w", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// entryChanged reports whether reprocessing changed any field it sets.
+func entryChanged(a, b *models.Entry) bool {
+	return len(changedFields(a, b)) > 0
+}
+
+// changedFields names the fields set from a feed item that differ between
+// a and b, as they are named in JSON. Times are compared as instants, since
+// stored ones lose their zone.
+func changedFields(a, b *models.Entry) []string {
+	var changed []string
+	for _, f := range []struct {
+		name    string
+		changed bool
+	}{
+		{"title", derefString(a.Title) != derefString(b.Title)},
+		{"link", derefString(a.Link) != derefString(b.Link)},
+		{"author", derefString(a.Author) != derefString(b.Author)},
+		{"content", derefString(a.Content) != derefString(b.Content)},
+		{"discussion_url", derefString(a.DiscussionURL) != derefString(b.DiscussionURL)},
+		{"comments_feed_url", derefString(a.CommentsFeedURL) != derefString(b.CommentsFeedURL)},
+		{"image_url", derefString(a.ImageURL) != derefString(b.ImageURL)},
+		{"published_at", !sameTime(a.PublishedAt, b.PublishedAt)},
+		{"claimed_published_at", !sameTime(a.ClaimedPublishedAt, b.ClaimedPublishedAt)},
+		{"extensions", !reflect.DeepEqual(a.Extensions, b.Extensions)},
+	} {
+		if f.changed {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
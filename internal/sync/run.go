@@ -0,0 +1,152 @@
+// ABOUTME: Summary of the most recent sync run, kept in the profile data directory
+// ABOUTME: Lets 'digest sync status' and MCP agents see what the last sync did without running another
+
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harperreed/mdstore"
+
+	"github.com/harper/digest/internal/models"
+)
+
+// RunFileName is the last sync run's summary inside a profile data directory.
+const RunFileName = "last_sync.json"
+
+// Feed outcomes in a run, matching the statuses of 'digest fetch --porcelain'.
+const (
+	RunOK      = "ok"
+	RunCached  = "cached"
+	RunSkipped = "skipped"
+	RunError   = "error"
+)
+
+// Run summarizes one sync of a profile's feeds.
+type Run struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMS int64     `json:"duration_ms"`
+	// Source is what ran the sync: "cli" or "mcp".
+	Source string `json:"source"`
+	Force  bool   `json:"force,omitempty"`
+
+	Feeds []RunFeed `json:"feeds"`
+
+	// Synced counts the feeds attempted, that is all but the skipped ones.
+	Synced     int `json:"synced"`
+	NewEntries int `json:"new_entries"`
+	Cached     int `json:"cached"`
+	Skipped    int `json:"skipped"`
+	Errors     int `json:"errors"`
+	// Archived counts the feeds archived as inactive after the sync.
+	Archived int `json:"archived,omitempty"`
+}
+
+// RunFeed is one feed's outcome in a run.
+type RunFeed struct {
+	FeedID     string `json:"feed_id"`
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+	NewEntries int    `json:"new_entries"`
+	// Duplicates counts republished copies skipped.
+	Duplicates int `json:"duplicates,omitempty"`
+	// Truncated is set when the feed was too large to read whole.
+	Truncated bool `json:"truncated,omitempty"`
+	// Filtered counts new entries ingest filters dropped.
+	Filtered int `json:"filtered,omitempty"`
+	// Detail is the skip reason or error message.
+	Detail     string `json:"detail,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+}
+
+// NewRun starts the summary of a sync begun at started.
+func NewRun(source string, force bool, started time.Time) *Run {
+	return &Run{StartedAt: started, Source: source, Force: force, Feeds: []RunFeed{}}
+}
+
+// Skip records a feed left out of the run for reason.
+func (r *Run) Skip(feed *models.Feed, reason string) {
+	r.Skipped++
+	r.Feeds = append(r.Feeds, RunFeed{
+		FeedID: feed.ID,
+		URL:    feed.URL,
+		Title:  feed.GetDisplayName(),
+		Status: RunSkipped,
+		Detail: reason,
+	})
+}
+
+// Record records the outcome of syncing a feed, which took took.
+func (r *Run) Record(feed *models.Feed, result *SyncResult, err error, took time.Duration) {
+	r.Synced++
+	f := RunFeed{
+		FeedID:     feed.ID,
+		URL:        feed.URL,
+		Title:      feed.GetDisplayName(),
+		Status:     RunOK,
+		DurationMS: took.Milliseconds(),
+	}
+	switch {
+	case err != nil:
+		f.Status, f.Detail = RunError, err.Error()
+		r.Errors++
+	case result.WasCached:
+		f.Status = RunCached
+		r.Cached++
+	default:
+		f.NewEntries, f.Duplicates, f.Truncated, f.Filtered = result.NewEntries, result.Duplicates, result.Truncated, result.Filtered
+		r.NewEntries += result.NewEntries
+	}
+	r.Feeds = append(r.Feeds, f)
+}
+
+// Finish stamps the run as finished at at.
+func (r *Run) Finish(at time.Time) {
+	r.FinishedAt = at
+	r.DurationMS = at.Sub(r.StartedAt).Milliseconds()
+}
+
+// Duration returns how long the run took.
+func (r *Run) Duration() time.Duration {
+	return time.Duration(r.DurationMS) * time.Millisecond
+}
+
+// RunPath returns the last sync summary file for a profile data directory.
+func RunPath(profileDir string) string {
+	return filepath.Join(profileDir, RunFileName)
+}
+
+// SaveRun writes r as the last sync run, replacing the previous one.
+func SaveRun(path string, r *Run) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode sync summary: %w", err)
+	}
+	if err := mdstore.AtomicWrite(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write sync summary: %w", err)
+	}
+	return nil
+}
+
+// LoadRun reads the last sync run, or returns nil if no sync has been
+// recorded.
+func LoadRun(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read sync summary: %w", err)
+	}
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse sync summary: %w", err)
+	}
+	return &r, nil
+}
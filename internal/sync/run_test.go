@@ -0,0 +1,81 @@
+// ABOUTME: Tests for the last sync run summary
+// ABOUTME: Covers recording feed outcomes and saving and loading the summary
+
+package sync
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestRun_RecordAndSave(t *testing.T) {
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	run := NewRun("cli", true, started)
+
+	ok := storage.NewFeed("https://example.com/ok.xml")
+	cached := storage.NewFeed("https://example.com/cached.xml")
+	broken := storage.NewFeed("https://example.com/broken.xml")
+	paused := storage.NewFeed("https://example.com/paused.xml")
+
+	run.Record(ok, &SyncResult{NewEntries: 3, Duplicates: 1, Truncated: true}, nil, 250*time.Millisecond)
+	run.Record(cached, &SyncResult{WasCached: true}, nil, 10*time.Millisecond)
+	run.Record(broken, nil, errors.New("HTTP 500"), time.Second)
+	run.Skip(paused, "paused")
+	run.Finish(started.Add(2 * time.Second))
+
+	if run.Synced != 3 || run.NewEntries != 3 || run.Cached != 1 || run.Errors != 1 || run.Skipped != 1 {
+		t.Errorf("totals = synced %d, new %d, cached %d, errors %d, skipped %d",
+			run.Synced, run.NewEntries, run.Cached, run.Errors, run.Skipped)
+	}
+	if run.Duration() != 2*time.Second {
+		t.Errorf("Duration() = %v, want 2s", run.Duration())
+	}
+
+	path := RunPath(t.TempDir())
+	if got, err := LoadRun(path); err != nil || got != nil {
+		t.Fatalf("LoadRun before any sync = %v, %v; want nil, nil", got, err)
+	}
+	if err := SaveRun(path, run); err != nil {
+		t.Fatalf("SaveRun: %v", err)
+	}
+	if filepath.Base(path) != RunFileName {
+		t.Errorf("RunPath = %q", path)
+	}
+
+	got, err := LoadRun(path)
+	if err != nil {
+		t.Fatalf("LoadRun: %v", err)
+	}
+	if !got.StartedAt.Equal(started) || got.Source != "cli" || !got.Force {
+		t.Errorf("loaded run = %+v", got)
+	}
+	want := []struct {
+		status string
+		detail string
+		new    int
+	}{
+		{RunOK, "", 3},
+		{RunCached, "", 0},
+		{RunError, "HTTP 500", 0},
+		{RunSkipped, "paused", 0},
+	}
+	if len(got.Feeds) != len(want) {
+		t.Fatalf("got %d feeds, want %d", len(got.Feeds), len(want))
+	}
+	for i, w := range want {
+		f := got.Feeds[i]
+		if f.Status != w.status || f.Detail != w.detail || f.NewEntries != w.new {
+			t.Errorf("feed %d = %+v, want status %q detail %q new %d", i, f, w.status, w.detail, w.new)
+		}
+	}
+	if got.Feeds[0].Duplicates != 1 {
+		t.Errorf("duplicates = %d, want 1", got.Feeds[0].Duplicates)
+	}
+	if !got.Feeds[0].Truncated {
+		t.Error("truncated not kept")
+	}
+}
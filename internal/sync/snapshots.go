@@ -0,0 +1,29 @@
+// ABOUTME: Keeps the raw responses of feed fetches on disk when configured
+// ABOUTME: Gives 'digest debug replay' something to reprocess without re-fetching
+
+package sync
+
+import (
+	"time"
+
+	"github.com/harper/digest/internal/snapshot"
+)
+
+// SnapshotOptions controls raw response snapshots. The zero value keeps
+// none.
+type SnapshotOptions struct {
+	// Dir is where snapshots are stored, one directory per feed.
+	Dir string
+
+	// Keep is how many of each feed's newest responses are kept.
+	Keep int
+}
+
+// save stores a fetched body. Snapshots are a debugging aid, so failures
+// are ignored rather than failing the sync.
+func (o SnapshotOptions) save(feedID string, body []byte, at time.Time) {
+	if o.Dir == "" || o.Keep <= 0 {
+		return
+	}
+	_, _ = snapshot.Save(o.Dir, feedID, body, at, o.Keep)
+}
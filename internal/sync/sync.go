@@ -5,12 +5,15 @@ package sync
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"io"
+	gosync "sync"
 	"time"
 
 	"github.com/harper/digest/internal/fetch"
 	"github.com/harper/digest/internal/models"
 	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/secrets"
 	"github.com/harper/digest/internal/storage"
 )
 
@@ -18,85 +21,171 @@ import (
 type SyncResult struct {
 	NewEntries int
 	WasCached  bool
+	// Identity is the strategy the feed switched to because its GUIDs
+	// proved unstable during this sync, or "".
+	Identity string
+	// Duplicates is how many new-looking entries were skipped as copies
+	// of recently stored ones republished under a new GUID.
+	Duplicates int
+	// Truncated reports that the feed was too large to read whole, so only
+	// its first items, up to Options.Stream, were synced.
+	Truncated bool
+	// Filtered is how many new entries Options.Filter or a pipeline stage
+	// dropped.
+	Filtered int
 }
 
+// SkipReason returns why a feed should be left out of a bulk sync, or "" if it should be synced.
+// Paused, archived, and bookmarks feeds are always skipped; force overrides the per-feed sync interval.
+func SkipReason(feed *models.Feed, force bool, now time.Time) string {
+	if feed.Paused {
+		return "paused"
+	}
+	if feed.IsArchived() {
+		return "archived"
+	}
+	if feed.IsBookmarks() {
+		return "bookmarks"
+	}
+	if !force && !feed.IsDue(now) {
+		return "not due"
+	}
+	return ""
+}
+
+// Options adjusts how SyncFeedWith fetches and stores a feed.
+type Options struct {
+	// Force ignores cache headers and re-fetches unconditionally.
+	Force bool
+
+	// Secrets looks up a feed password stored as a secret reference.
+	Secrets secrets.Provider
+
+	// Dates bounds the publish dates accepted from the feed.
+	Dates DatePolicy
+
+	// Images controls how new entries' lead images are found and kept.
+	Images ImageOptions
+
+	// Snapshots keeps the raw responses fetched, for replaying later.
+	Snapshots SnapshotOptions
+
+	// Duplicates decides which new entries are skipped as republished
+	// copies of stored ones.
+	Duplicates DuplicatePolicy
+
+	// Monitor decides which page changes monitor feeds report.
+	Monitor MonitorOptions
+
+	// Stream caps how much of a large feed is read; the zero value is
+	// parse.DefaultStreamLimits.
+	Stream parse.StreamLimits
+
+	// Filter, when set, is called with each new entry before anything
+	// else sees it. It may change the entry, and returns false to drop it
+	// unstored.
+	Filter func(ctx context.Context, feed *models.Feed, entry *models.Entry) bool
+
+	// Stages are extra pipeline stages, run on each batch of new entries
+	// after lead images are set and before NewEntry and the store.
+	Stages []Stage
+
+	// StoreLock, when set, is held around the sync's store reads and
+	// writes, but not while it fetches, downloads images, or runs Filter,
+	// Stages and NewEntry, so concurrent syncs sharing a store take turns.
+	StoreLock gosync.Locker
+
+	// NewEntry, when set, is called with each new entry just before it is
+	// stored, and may change it, such as to mark it read.
+	NewEntry func(ctx context.Context, feed *models.Feed, entry *models.Entry)
+}
+
+// ErrBookmarksFeed is returned for feeds of imported bookmarks, which have
+// nothing to fetch.
+var ErrBookmarksFeed = errors.New("bookmarks feeds aren't fetched; update them with 'digest bookmarks import'")
+
 // SyncFeed fetches and processes a single feed, storing new entries.
 // If force is true, ignores cache headers and re-fetches unconditionally.
 func SyncFeed(ctx context.Context, store storage.Store, feed *models.Feed, force bool) (*SyncResult, error) {
-	// Get cache headers (skip if force)
-	var etag, lastModified *string
-	if !force {
-		etag = feed.ETag
-		lastModified = feed.LastModified
-	}
+	return SyncFeedWith(ctx, store, feed, Options{Force: force})
+}
 
-	// Fetch the feed
-	result, err := fetch.Fetch(ctx, feed.URL, etag, lastModified, feed.LocalNetwork)
-	if err != nil {
-		errMsg := err.Error()
-		if updateErr := store.UpdateFeedError(feed.ID, errMsg); updateErr != nil {
-			return nil, fmt.Errorf("fetch failed (%v) and error update failed: %w", err, updateErr)
-		}
-		return nil, err
+// SyncFeedWith is like SyncFeed with the given options. It runs the feed
+// through the ingest pipeline; see Stage.
+func SyncFeedWith(ctx context.Context, store storage.Store, feed *models.Feed, opts Options) (*SyncResult, error) {
+	if feed.IsBookmarks() {
+		return nil, ErrBookmarksFeed
 	}
 
-	// Handle 304 Not Modified
-	if result.NotModified {
-		return &SyncResult{NewEntries: 0, WasCached: true}, nil
+	b := &Batch{Store: store, Feed: feed, Opts: opts}
+	if err := b.run(ctx, opts.pipeline()); err != nil {
+		return nil, err
 	}
+	return &b.Result, nil
+}
 
-	// Parse the feed
-	parsed, err := parse.Parse(result.Body)
-	if err != nil {
-		errMsg := fmt.Sprintf("failed to parse feed: %v", err)
-		if updateErr := store.UpdateFeedError(feed.ID, errMsg); updateErr != nil {
-			return nil, fmt.Errorf("parse failed (%v) and error update failed: %w", err, updateErr)
-		}
-		return nil, fmt.Errorf("failed to parse feed: %w", err)
+// readFeed reads a fetched feed body, keeping only its first items when
+// it is too large to hold whole.
+func (o Options) readFeed(r io.Reader) ([]byte, bool, error) {
+	limits := o.Stream
+	if limits == (parse.StreamLimits{}) {
+		limits = parse.DefaultStreamLimits
 	}
+	return parse.ReadFeed(r, limits)
+}
 
-	// Update feed title if empty
-	titleUpdated := false
-	if feed.Title == nil || *feed.Title == "" {
-		feed.Title = &parsed.Title
-		titleUpdated = true
+// credentials returns the credentials a feed is fetched with, or nil if it
+// needs none.
+func credentials(feed *models.Feed, provider secrets.Provider) (*fetch.Credentials, error) {
+	if !feed.HasAuth() {
+		return nil, nil
 	}
-
-	// Process entries
-	newCount := 0
-	for _, parsedEntry := range parsed.Entries {
-		exists, err := store.EntryExists(feed.ID, parsedEntry.GUID)
+	creds := &fetch.Credentials{Username: *feed.AuthUsername}
+	if feed.AuthPassword != nil {
+		password, err := secrets.Resolve(provider, *feed.AuthPassword)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check entry existence: %w", err)
+			return nil, err
 		}
-		if exists {
-			continue
-		}
-
-		entry := storage.NewEntry(feed.ID, parsedEntry.GUID, parsedEntry.Title)
-		entry.Link = &parsedEntry.Link
-		entry.Author = &parsedEntry.Author
-		entry.PublishedAt = parsedEntry.PublishedAt
-		entry.Content = &parsedEntry.Content
-
-		if err := store.CreateEntry(entry); err != nil {
-			return nil, fmt.Errorf("failed to create entry: %w", err)
-		}
-		newCount++
+		creds.Password = password
 	}
+	return creds, nil
+}
 
-	// Update feed fetch state
-	fetchedAt := time.Now()
-	if err := store.UpdateFeedFetchState(feed.ID, &result.ETag, &result.LastModified, fetchedAt); err != nil {
-		return nil, fmt.Errorf("failed to update feed state: %w", err)
+// buildEntries turns feed items not stored yet into new entries stamped
+// with fetchedAt, along with the lead image each item gave.
+func buildEntries(feed *models.Feed, unseen []keyedEntry, opts Options, fetchedAt time.Time) ([]*models.Entry, map[*models.Entry]string) {
+	fresh := make([]*models.Entry, 0, len(unseen))
+	images := make(map[*models.Entry]string, len(unseen))
+	for _, ke := range unseen {
+		entry := storage.NewEntry(feed.ID, ke.key, ke.entry.Title)
+		entry.CreatedAt = fetchedAt
+		setParsed(entry, ke.entry, opts.Dates)
+		fresh = append(fresh, entry)
+		images[entry] = ke.entry.Image
 	}
+	return fresh, images
+}
 
-	// Update feed if title changed
-	if titleUpdated {
-		if err := store.UpdateFeed(feed); err != nil {
-			return nil, fmt.Errorf("failed to update feed title: %w", err)
-		}
+// setParsed sets the fields of an entry that come from its feed item. The
+// publish date is checked against the entry's CreatedAt, its fetch time.
+func setParsed(entry *models.Entry, item parse.ParsedEntry, dates DatePolicy) {
+	title, link, author, content := item.Title, item.Link, item.Author, item.Content
+	entry.Title = &title
+	entry.Link = &link
+	entry.Author = &author
+	entry.Content = &content
+	entry.ContentHash = ContentHash(content)
+	entry.PublishedAt = item.PublishedAt
+	entry.ClaimedPublishedAt = nil
+	dates.Apply(entry)
+	entry.DiscussionURL, entry.CommentsFeedURL = nil, nil
+	if item.Discussion != "" {
+		discussion := item.Discussion
+		entry.DiscussionURL = &discussion
 	}
-
-	return &SyncResult{NewEntries: newCount, WasCached: false}, nil
+	if item.CommentsFeed != "" {
+		commentsFeed := item.CommentsFeed
+		entry.CommentsFeedURL = &commentsFeed
+	}
+	entry.Extensions = item.Extensions
 }
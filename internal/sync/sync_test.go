@@ -5,13 +5,20 @@ package sync
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/harper/digest/internal/fetch"
 	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/parse"
+	"github.com/harper/digest/internal/secrets"
 	"github.com/harper/digest/internal/storage"
 )
 
@@ -57,7 +64,7 @@ func TestSyncFeed_Fresh(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -75,7 +82,7 @@ func TestSyncFeed_Fresh(t *testing.T) {
 	}
 
 	// Verify entry was created
-	entries, err := store.ListEntries(nil)
+	entries, err := store.ListEntries(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("ListEntries: %v", err)
 	}
@@ -103,7 +110,7 @@ func TestSyncFeed_Cached(t *testing.T) {
 	// Create feed with etag
 	feed := models.NewFeed(server.URL)
 	feed.ETag = &etag
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -151,7 +158,7 @@ func TestSyncFeed_Force(t *testing.T) {
 	etag := `"force123"`
 	feed := models.NewFeed(server.URL)
 	feed.ETag = &etag
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -177,7 +184,7 @@ func TestSyncFeed_FetchError(t *testing.T) {
 	defer store.Close()
 
 	feed := models.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -188,7 +195,7 @@ func TestSyncFeed_FetchError(t *testing.T) {
 	}
 
 	// Check error was recorded
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed: %v", err)
 	}
@@ -212,7 +219,7 @@ func TestSyncFeed_ParseError(t *testing.T) {
 	defer store.Close()
 
 	feed := models.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -223,7 +230,7 @@ func TestSyncFeed_ParseError(t *testing.T) {
 	}
 
 	// Check error was recorded
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed: %v", err)
 	}
@@ -232,6 +239,48 @@ func TestSyncFeed_ParseError(t *testing.T) {
 	}
 }
 
+func TestSyncFeed_BotProtection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("User-Agent"), "Mozilla/") {
+			// Some challenges come back as 200 OK
+			w.Write([]byte(`<html><head><title>Just a moment...</title></head></html>`))
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Protected</title>
+<item><title>One</title><guid>1</guid></item></channel></rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	_, err := SyncFeed(ctx, store, feed, false)
+	if !errors.Is(err, fetch.ErrBotProtection) {
+		t.Fatalf("expected ErrBotProtection, got %v", err)
+	}
+	got, err := store.GetFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed: %v", err)
+	}
+	if got.LastError == nil || !strings.Contains(*got.LastError, "bot protection") {
+		t.Errorf("expected the recorded error to name bot protection, got %v", got.LastError)
+	}
+
+	got.Browser = true
+	result, err := SyncFeed(ctx, store, got, false)
+	if err != nil {
+		t.Fatalf("expected browser headers to get through, got %v", err)
+	}
+	if result.NewEntries != 1 {
+		t.Errorf("expected 1 new entry, got %d", result.NewEntries)
+	}
+}
+
 func TestSyncFeed_TitleUpdate(t *testing.T) {
 	// Create test server with feed that has a title
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -251,7 +300,7 @@ func TestSyncFeed_TitleUpdate(t *testing.T) {
 
 	// Create feed without title
 	feed := models.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
@@ -262,7 +311,7 @@ func TestSyncFeed_TitleUpdate(t *testing.T) {
 	}
 
 	// Check title was updated
-	got, err := store.GetFeed(feed.ID)
+	got, err := store.GetFeed(context.Background(), feed.ID)
 	if err != nil {
 		t.Fatalf("GetFeed: %v", err)
 	}
@@ -297,13 +346,13 @@ func TestSyncFeed_ExistingEntries(t *testing.T) {
 
 	// Create feed
 	feed := models.NewFeed(server.URL)
-	if err := store.CreateFeed(feed); err != nil {
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
 		t.Fatalf("CreateFeed: %v", err)
 	}
 
 	// Create existing entry
 	entry := storage.NewEntry(feed.ID, "existing-guid", "Existing Article")
-	if err := store.CreateEntry(entry); err != nil {
+	if err := store.CreateEntry(context.Background(), entry); err != nil {
 		t.Fatalf("CreateEntry: %v", err)
 	}
 
@@ -319,13 +368,22 @@ func TestSyncFeed_ExistingEntries(t *testing.T) {
 	}
 
 	// Verify 2 total entries
-	entries, err := store.ListEntries(nil)
+	entries, err := store.ListEntries(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("ListEntries: %v", err)
 	}
 	if len(entries) != 2 {
 		t.Errorf("expected 2 entries total, got %d", len(entries))
 	}
+
+	// Only the entry this sync stored counts as new since the last sync
+	latest, err := store.ListEntries(context.Background(), &storage.EntryFilter{LatestSync: true})
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(latest) != 1 || latest[0].GUID != "new-guid" {
+		t.Errorf("expected only new-guid from the latest sync, got %d entries", len(latest))
+	}
 }
 
 func newTestStore(t *testing.T) storage.Store {
@@ -347,3 +405,415 @@ func newTestStore(t *testing.T) storage.Store {
 
 	return store
 }
+
+func TestSyncFeed_PrunesToMaxEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Busy Feed</title>
+    <item><title>One</title><guid>g1</guid><pubDate>Mon, 02 Jan 2006 15:04:05 GMT</pubDate></item>
+    <item><title>Two</title><guid>g2</guid><pubDate>Tue, 03 Jan 2006 15:04:05 GMT</pubDate></item>
+    <item><title>Three</title><guid>g3</guid><pubDate>Wed, 04 Jan 2006 15:04:05 GMT</pubDate></item>
+  </channel>
+</rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed(server.URL)
+	feed.MaxEntries = 2
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	if _, err := SyncFeed(context.Background(), store, feed, false); err != nil {
+		t.Fatalf("SyncFeed: %v", err)
+	}
+
+	entries, err := store.ListEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries after pruning, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.GUID == "g1" {
+			t.Error("expected oldest entry to be pruned")
+		}
+	}
+}
+
+func TestSyncFeed_NewEntryHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Hooked Feed</title>
+    <item><title>Keep me</title><guid>g1</guid></item>
+    <item><title>Sponsored: buy this</title><guid>g2</guid></item>
+  </channel>
+</rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	var seen []string
+	opts := Options{NewEntry: func(_ context.Context, f *models.Feed, e *models.Entry) {
+		if f.ID != feed.ID {
+			t.Errorf("hook got feed %s, want %s", f.ID, feed.ID)
+		}
+		seen = append(seen, e.GetTitle())
+		if strings.HasPrefix(e.GetTitle(), "Sponsored") {
+			e.MarkRead()
+		}
+	}}
+	if _, err := SyncFeedWith(context.Background(), store, feed, opts); err != nil {
+		t.Fatalf("SyncFeedWith: %v", err)
+	}
+	if len(seen) != 2 {
+		t.Errorf("hook saw %v, want both entries", seen)
+	}
+
+	entries, err := store.ListEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	for _, e := range entries {
+		if want := e.GUID == "g2"; e.Read != want {
+			t.Errorf("entry %s read = %v, want %v", e.GUID, e.Read, want)
+		}
+	}
+}
+
+func TestSyncFeed_FilterDropsEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Filtered Feed</title>
+    <item><title>Keep me</title><guid>g1</guid></item>
+    <item><title>Sponsored: buy this</title><guid>g2</guid></item>
+  </channel>
+</rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	var hooked []string
+	opts := Options{
+		Filter: func(_ context.Context, _ *models.Feed, e *models.Entry) bool {
+			return !strings.HasPrefix(e.GetTitle(), "Sponsored")
+		},
+		NewEntry: func(_ context.Context, _ *models.Feed, e *models.Entry) {
+			hooked = append(hooked, e.GUID)
+		},
+	}
+	result, err := SyncFeedWith(context.Background(), store, feed, opts)
+	if err != nil {
+		t.Fatalf("SyncFeedWith: %v", err)
+	}
+	if result.NewEntries != 1 || result.Filtered != 1 {
+		t.Errorf("result = %+v, want 1 new and 1 filtered", result)
+	}
+	if len(hooked) != 1 || hooked[0] != "g1" {
+		t.Errorf("NewEntry saw %v, want only the kept entry", hooked)
+	}
+
+	entries, err := store.ListEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].GUID != "g1" {
+		t.Errorf("stored %d entries, want only g1", len(entries))
+	}
+}
+
+func TestSyncFeed_TruncatesHugeFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>Huge</title>`)
+		for i := range 50 {
+			fmt.Fprintf(w, "<item><title>Item %d</title><guid>g%d</guid></item>\n", i, i)
+		}
+		fmt.Fprint(w, `</channel></rss>`)
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	result, err := SyncFeedWith(context.Background(), store, feed, Options{Stream: parse.StreamLimits{MaxItems: 10}})
+	if err != nil {
+		t.Fatalf("SyncFeedWith: %v", err)
+	}
+	if !result.Truncated || result.NewEntries != 10 {
+		t.Errorf("truncated = %v, new = %d; want the first 10 entries, truncated", result.Truncated, result.NewEntries)
+	}
+
+	// A feed within the caps isn't reported as truncated
+	feed2 := models.NewFeed(server.URL + "/whole")
+	if err := store.CreateFeed(context.Background(), feed2); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	result, err = SyncFeed(context.Background(), store, feed2, false)
+	if err != nil {
+		t.Fatalf("SyncFeed: %v", err)
+	}
+	if result.Truncated {
+		t.Error("feed within the default caps reported as truncated")
+	}
+}
+
+func TestSyncFeed_StoresExtensions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel><title>Podcast</title>
+<item><title>Episode</title><guid>ep1</guid><itunes:duration>42:00</itunes:duration></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	feed := models.NewFeed(server.URL)
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if _, err := SyncFeed(ctx, store, feed, false); err != nil {
+		t.Fatalf("SyncFeed: %v", err)
+	}
+
+	entries, err := store.ListEntries(ctx, nil)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListEntries: %d entries (%v)", len(entries), err)
+	}
+	if d, ok := entries[0].Extensions.Get("itunes", "duration"); !ok || d.Value != "42:00" {
+		t.Errorf("expected itunes:duration stored with the entry, got %+v", entries[0].Extensions)
+	}
+}
+
+func TestSyncFeed_FirstSyncBackfill(t *testing.T) {
+	now := time.Now()
+	items := ""
+	for i, age := range []int{1, 3, 30, 60} {
+		items += fmt.Sprintf("<item><title>Item %d</title><guid>g%d</guid><pubDate>%s</pubDate></item>",
+			i, i, now.AddDate(0, 0, -age).Format(time.RFC1123Z))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Old Feed</title>` + items + `</channel></rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	feed := models.NewFeed(server.URL)
+	feed.BackfillDays = 7
+	feed.BackfillLimit = 3
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	result, err := SyncFeed(ctx, store, feed, false)
+	if err != nil {
+		t.Fatalf("SyncFeed: %v", err)
+	}
+	if result.NewEntries != 3 {
+		t.Errorf("expected the newest 3 entries imported, got %d", result.NewEntries)
+	}
+	entries, err := store.ListEntries(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	read := map[string]bool{}
+	for _, e := range entries {
+		read[e.GUID] = e.Read
+	}
+	if _, ok := read["g3"]; ok {
+		t.Error("expected the oldest entry to be left out by the limit")
+	}
+	if read["g0"] || read["g1"] || !read["g2"] {
+		t.Errorf("expected only the entry older than 7 days marked read, got %v", read)
+	}
+
+	// Later syncs bring everything in unread
+	if _, err := SyncFeed(ctx, store, feed, true); err != nil {
+		t.Fatalf("SyncFeed: %v", err)
+	}
+	entries, err = store.ListEntries(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected the oldest entry on the second sync, got %d entries", len(entries))
+	}
+	for _, e := range entries {
+		if e.GUID == "g3" && e.Read {
+			t.Error("expected backfill to apply to the first sync only")
+		}
+	}
+}
+
+func TestPruneEntries_SkipsKeptEntries(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	feed.MaxEntries = 1
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	var oldest *models.Entry
+	for i, guid := range []string{"g1", "g2", "g3"} {
+		published := time.Date(2006, 1, 2+i, 15, 4, 5, 0, time.UTC)
+		e := models.NewEntry(feed.ID, guid, guid)
+		e.PublishedAt = &published
+		if err := store.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+		if oldest == nil {
+			oldest = e
+		}
+	}
+	if err := store.SetEntryKeepUnread(ctx, oldest.ID, true); err != nil {
+		t.Fatalf("SetEntryKeepUnread: %v", err)
+	}
+
+	if err := PruneEntries(ctx, store, feed); err != nil {
+		t.Fatalf("PruneEntries: %v", err)
+	}
+
+	entries, err := store.ListEntries(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	var guids []string
+	for _, e := range entries {
+		guids = append(guids, e.GUID)
+	}
+	if strings.Join(guids, ",") != "g3,g1" {
+		t.Errorf("expected the newest and the kept entry to survive, got %v", guids)
+	}
+}
+
+func TestSyncFeed_BasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "reader" || pass != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Private</title><item><title>A</title><guid>a</guid></item></channel></rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+
+	feed := models.NewFeed(server.URL)
+	user, pass := "reader", "hunter2"
+	feed.AuthUsername = &user
+	feed.AuthPassword = &pass
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	result, err := SyncFeed(context.Background(), store, feed, false)
+	if err != nil {
+		t.Fatalf("SyncFeed: %v", err)
+	}
+	if result.NewEntries != 1 {
+		t.Errorf("expected 1 new entry, got %d", result.NewEntries)
+	}
+}
+
+func TestSyncFeed_BasicAuthFromSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "reader" || pass != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Private</title><item><title>A</title><guid>a</guid></item></channel></rss>`))
+	}))
+	defer server.Close()
+
+	store := newTestStore(t)
+	defer store.Close()
+
+	provider := secrets.NewFile(filepath.Join(t.TempDir(), "secrets.json"))
+	if err := provider.Set("feed/private", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	feed := models.NewFeed(server.URL)
+	user, ref := "reader", secrets.Ref("feed/private")
+	feed.AuthUsername = &user
+	feed.AuthPassword = &ref
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	if _, err := SyncFeed(context.Background(), store, feed, false); err == nil {
+		t.Error("expected a secret reference to fail without a provider")
+	}
+	result, err := SyncFeedWith(context.Background(), store, feed, Options{Secrets: provider})
+	if err != nil {
+		t.Fatalf("SyncFeedWith: %v", err)
+	}
+	if result.NewEntries != 1 {
+		t.Errorf("expected 1 new entry, got %d", result.NewEntries)
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-10 * time.Minute)
+
+	tests := []struct {
+		name  string
+		feed  models.Feed
+		force bool
+		want  string
+	}{
+		{"default", models.Feed{}, false, ""},
+		{"paused", models.Feed{Paused: true}, false, "paused"},
+		{"paused even when forced", models.Feed{Paused: true}, true, "paused"},
+		{"archived", models.Feed{ArchivedAt: &recent}, false, "archived"},
+		{"archived even when forced", models.Feed{ArchivedAt: &recent}, true, "archived"},
+		{"bookmarks even when forced", models.Feed{URL: "bookmarks:linkding"}, true, "bookmarks"},
+		{"not due", models.Feed{SyncInterval: time.Hour, LastFetchedAt: &recent}, false, "not due"},
+		{"force overrides interval", models.Feed{SyncInterval: time.Hour, LastFetchedAt: &recent}, true, ""},
+		{"due", models.Feed{SyncInterval: 5 * time.Minute, LastFetchedAt: &recent}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SkipReason(&tt.feed, tt.force, now); got != tt.want {
+				t.Errorf("SkipReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// ABOUTME: The sync engine shared by the CLI and MCP server for syncing a profile's feeds
+// ABOUTME: Picks the feeds to sync, runs them through sync with bounded concurrency, and reports progress and totals
+
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/harper/digest/internal/favicon"
+	"github.com/harper/digest/internal/fetch"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+	feedsync "github.com/harper/digest/internal/sync"
+)
+
+// ErrNoFeeds is returned by Sync when the profile has no feeds at all.
+var ErrNoFeeds = errors.New("no feeds found")
+
+// Interrupted is the skip reason of feeds left unsynced because the sync's
+// context was canceled.
+const Interrupted = "interrupted"
+
+// Engine syncs a profile's feeds. The zero value of each option is a
+// sequential sync of every due feed.
+type Engine struct {
+	Store storage.Store
+
+	// Options returns the options a feed is synced with, such as its
+	// secrets. Its Force and StoreLock are set by the engine.
+	Options func(feed *models.Feed) (feedsync.Options, error)
+
+	// Force ignores cache headers and sync intervals.
+	Force bool
+
+	// Concurrency is how many feeds are fetched at once; 0 or 1 syncs them
+	// one at a time. Store writes always take turns.
+	Concurrency int
+
+	// URL, when set, syncs only the feed with this URL, even if it is
+	// paused or not due.
+	URL string
+
+	// Filter, when set, leaves out the feeds it returns false for.
+	Filter func(feed *models.Feed) bool
+
+	// IconDir, when set, is where favicons of synced feeds are refreshed.
+	IconDir string
+
+	// Source names what started the sync in its Run, such as "cli".
+	Source string
+
+	// Progress, when set, is told about each feed as it starts and ends.
+	// Calls never overlap.
+	Progress func(Event)
+}
+
+// Outcome is how syncing one feed went.
+type Outcome struct {
+	Feed *models.Feed
+	// Skipped is why the feed was left out, or "" if it was synced.
+	Skipped string
+	// Result is set when the sync succeeded, and Err when it failed.
+	Result *feedsync.SyncResult
+	Err    error
+}
+
+// Event reports progress: once with Done false when a feed starts
+// syncing, and once with Done set when it is skipped or finished.
+type Event struct {
+	Outcome
+	Done bool
+}
+
+// Report sums up a sync.
+type Report struct {
+	// Run is the summary saved for 'digest sync-status' and post_sync hooks.
+	Run *feedsync.Run
+	// Outcomes are every selected feed's, in the order the store lists them.
+	Outcomes []Outcome
+
+	New     int
+	Cached  int
+	Skipped int
+	Errors  int
+	// Interrupted counts the skipped feeds that were left unsynced because
+	// the context was canceled, whether or not they had started.
+	Interrupted int
+	// Failed are the IDs of the feeds that failed.
+	Failed []string
+	// Cache is how the HTTP caches did during the sync.
+	Cache fetch.CacheStats
+	// Started is when the sync began, for checks like sync intervals.
+	Started time.Time
+}
+
+// Attempted is how many feeds were synced, that is all but the skipped.
+func (r *Report) Attempted() int {
+	return len(r.Outcomes) - r.Skipped
+}
+
+// Sync syncs the selected feeds and reports how it went. It fails only
+// when the feeds can't be listed or selected; feeds that fail to sync are
+// counted in the report. Once ctx is canceled no more feeds start, and
+// feeds whose fetch is cut short are skipped rather than failed; a feed
+// already fetched is still stored whole.
+func (e *Engine) Sync(ctx context.Context) (*Report, error) {
+	feeds, err := e.Store.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	if len(feeds) == 0 {
+		return nil, ErrNoFeeds
+	}
+	feeds, err = e.selectFeeds(feeds)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &Report{
+		Run:      feedsync.NewRun(e.Source, e.Force, now),
+		Outcomes: make([]Outcome, len(feeds)),
+		Started:  now,
+	}
+	cacheBefore := fetch.Stats()
+
+	var mu sync.Mutex // guards report and Progress
+	var storeLock sync.Locker
+	if e.Concurrency > 1 {
+		storeLock = &sync.Mutex{}
+	}
+	sem := make(chan struct{}, max(e.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i, feed := range feeds {
+		// Honor pause and sync interval unless this feed was requested explicitly
+		var reason string
+		if e.URL == "" {
+			reason = feedsync.SkipReason(feed, e.Force, now)
+		}
+		// Taking a slot even for skipped feeds keeps a sequential sync's
+		// events in order
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			reason = Interrupted
+		}
+		if reason != "" {
+			mu.Lock()
+			report.skip(i, feed, reason)
+			e.progress(Event{Outcome: report.Outcomes[i], Done: true})
+			mu.Unlock()
+			<-sem
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			mu.Lock()
+			e.progress(Event{Outcome: Outcome{Feed: feed}})
+			mu.Unlock()
+
+			started := time.Now()
+			result, err := e.syncFeed(ctx, feed, storeLock)
+			took := time.Since(started)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && ctx.Err() != nil && errors.Is(err, context.Canceled) {
+				report.skip(i, feed, Interrupted)
+				e.progress(Event{Outcome: report.Outcomes[i], Done: true})
+				return
+			}
+			report.Run.Record(feed, result, err, took)
+			outcome := Outcome{Feed: feed, Result: result, Err: err}
+			if err != nil {
+				report.Errors++
+				report.Failed = append(report.Failed, feed.ID)
+			} else {
+				report.New += result.NewEntries
+				if result.WasCached {
+					report.Cached++
+				}
+			}
+			report.Outcomes[i] = outcome
+			e.progress(Event{Outcome: outcome, Done: true})
+		}()
+	}
+	wg.Wait()
+
+	report.Cache = fetch.Stats().Sub(cacheBefore)
+	return report, nil
+}
+
+// skip records the i'th feed as skipped for reason.
+func (r *Report) skip(i int, feed *models.Feed, reason string) {
+	r.Skipped++
+	if reason == Interrupted {
+		r.Interrupted++
+	}
+	r.Run.Skip(feed, reason)
+	r.Outcomes[i] = Outcome{Feed: feed, Skipped: reason}
+}
+
+// selectFeeds returns the feeds the engine's URL and Filter pick out.
+func (e *Engine) selectFeeds(feeds []*models.Feed) ([]*models.Feed, error) {
+	if e.URL != "" {
+		for _, feed := range feeds {
+			if feed.URL == e.URL {
+				return []*models.Feed{feed}, nil
+			}
+		}
+		return nil, fmt.Errorf("feed not found: %s", e.URL)
+	}
+	if e.Filter == nil {
+		return feeds, nil
+	}
+	var selected []*models.Feed
+	for _, feed := range feeds {
+		if e.Filter(feed) {
+			selected = append(selected, feed)
+		}
+	}
+	return selected, nil
+}
+
+// syncFeed syncs one feed and refreshes its icon.
+func (e *Engine) syncFeed(ctx context.Context, feed *models.Feed, storeLock sync.Locker) (*feedsync.SyncResult, error) {
+	var opts feedsync.Options
+	if e.Options != nil {
+		var err error
+		if opts, err = e.Options(feed); err != nil {
+			return nil, err
+		}
+	}
+	opts.Force, opts.StoreLock = e.Force, storeLock
+	result, err := feedsync.SyncFeedWith(ctx, e.Store, feed, opts)
+	if err != nil {
+		return nil, err
+	}
+	if e.IconDir != "" && ctx.Err() == nil {
+		// Icons are cosmetic; a failed refresh shouldn't fail the sync
+		_, _ = favicon.Refresh(ctx, e.IconDir, feed.ID, feed.URL, feed.LocalNetwork)
+	}
+	return result, nil
+}
+
+func (e *Engine) progress(ev Event) {
+	if e.Progress != nil {
+		e.Progress(ev)
+	}
+}
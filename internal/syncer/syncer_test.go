@@ -0,0 +1,229 @@
+// ABOUTME: Tests for the shared sync engine
+// ABOUTME: Covers feed selection, skips, progress order, failures, and concurrent syncs
+
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+func newStore(t *testing.T) storage.Store {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// feedServer serves a two-item feed at every path but /broken, counting
+// the requests in flight at once.
+func feedServer(t *testing.T, delay time.Duration, peak *atomic.Int32) *httptest.Server {
+	t.Helper()
+	var inFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		if peak != nil {
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+		}
+		time.Sleep(delay)
+		if r.URL.Path == "/broken" {
+			http.Error(w, "gone", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>%s</title>
+<item><title>One</title><guid>%s-1</guid></item>
+<item><title>Two</title><guid>%s-2</guid></item>
+</channel></rss>`, r.URL.Path, r.URL.Path, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func addFeed(t *testing.T, store storage.Store, url string) *models.Feed {
+	t.Helper()
+	feed := models.NewFeed(url)
+	if err := store.CreateFeed(context.Background(), feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	return feed
+}
+
+func TestSync(t *testing.T) {
+	server := feedServer(t, 0, nil)
+	store := newStore(t)
+	addFeed(t, store, server.URL+"/a")
+	paused := addFeed(t, store, server.URL+"/paused")
+	paused.Paused = true
+	if err := store.UpdateFeed(context.Background(), paused); err != nil {
+		t.Fatalf("UpdateFeed: %v", err)
+	}
+	addFeed(t, store, server.URL+"/broken")
+
+	var events []string
+	e := &Engine{Store: store, Source: "test", Progress: func(ev Event) {
+		name := strings.TrimPrefix(ev.Feed.URL, server.URL+"/")
+		switch {
+		case !ev.Done:
+			events = append(events, "start "+name)
+		case ev.Skipped != "":
+			events = append(events, "skip "+name)
+		case ev.Err != nil:
+			events = append(events, "fail "+name)
+		default:
+			events = append(events, "done "+name)
+		}
+	}}
+	report, err := e.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if report.New != 2 || report.Skipped != 1 || report.Errors != 1 || report.Attempted() != 2 {
+		t.Errorf("report = new %d, skipped %d, errors %d, attempted %d", report.New, report.Skipped, report.Errors, report.Attempted())
+	}
+	if len(report.Failed) != 1 || report.Run.Source != "test" || len(report.Run.Feeds) != 3 {
+		t.Errorf("failed = %v, run = %+v", report.Failed, report.Run)
+	}
+
+	// Feeds list in the store's order; a sequential sync reports each in turn
+	var want []string
+	for _, o := range report.Outcomes {
+		name := strings.TrimPrefix(o.Feed.URL, server.URL+"/")
+		switch {
+		case o.Skipped != "":
+			want = append(want, "skip "+name)
+		case o.Err != nil:
+			want = append(want, "start "+name, "fail "+name)
+		default:
+			want = append(want, "start "+name, "done "+name)
+		}
+	}
+	if got := strings.Join(events, ", "); got != strings.Join(want, ", ") {
+		t.Errorf("events = %s, want %s", got, strings.Join(want, ", "))
+	}
+}
+
+func TestSync_URL(t *testing.T) {
+	server := feedServer(t, 0, nil)
+	store := newStore(t)
+
+	e := &Engine{Store: store}
+	if _, err := e.Sync(context.Background()); !errors.Is(err, ErrNoFeeds) {
+		t.Fatalf("Sync with no feeds = %v, want ErrNoFeeds", err)
+	}
+
+	addFeed(t, store, server.URL+"/a")
+	paused := addFeed(t, store, server.URL+"/paused")
+	paused.Paused = true
+	if err := store.UpdateFeed(context.Background(), paused); err != nil {
+		t.Fatalf("UpdateFeed: %v", err)
+	}
+
+	// A feed asked for by URL is synced even when paused
+	e.URL = paused.URL
+	report, err := e.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(report.Outcomes) != 1 || report.Skipped != 0 || report.New != 2 {
+		t.Errorf("report = %+v", report)
+	}
+
+	e.URL = server.URL + "/missing"
+	if _, err := e.Sync(context.Background()); err == nil || !strings.Contains(err.Error(), "feed not found") {
+		t.Errorf("Sync of an unknown URL = %v", err)
+	}
+}
+
+func TestSync_Concurrency(t *testing.T) {
+	var peak atomic.Int32
+	server := feedServer(t, 50*time.Millisecond, &peak)
+	store := newStore(t)
+	for i := range 6 {
+		addFeed(t, store, fmt.Sprintf("%s/f%d", server.URL, i))
+	}
+
+	e := &Engine{Store: store, Concurrency: 3, Force: true}
+	report, err := e.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if report.New != 12 || report.Errors != 0 {
+		t.Errorf("report = new %d, errors %d; want 12 and 0", report.New, report.Errors)
+	}
+	if p := peak.Load(); p < 2 || p > 3 {
+		t.Errorf("peak concurrent fetches = %d, want 2 or 3", p)
+	}
+	entries, err := store.ListEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 12 {
+		t.Errorf("stored %d entries, want 12", len(entries))
+	}
+}
+
+func TestSync_Interrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	store := newStore(t)
+	for i := range 3 {
+		addFeed(t, store, fmt.Sprintf("%s/f%d", server.URL, i))
+	}
+
+	// Interrupt while the first feed is fetching
+	go func() {
+		<-started
+		cancel()
+	}()
+	e := &Engine{Store: store}
+	report, err := e.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if report.Interrupted != 3 || report.Skipped != 3 || report.Errors != 0 || report.Attempted() != 0 {
+		t.Errorf("report = interrupted %d, skipped %d, errors %d, attempted %d; want 3, 3, 0, 0",
+			report.Interrupted, report.Skipped, report.Errors, report.Attempted())
+	}
+	for _, o := range report.Outcomes {
+		if o.Skipped != Interrupted {
+			t.Errorf("%s skipped = %q, want %q", o.Feed.URL, o.Skipped, Interrupted)
+		}
+	}
+
+	// An interrupted fetch isn't held against the feed
+	feeds, err := store.ListFeeds(context.Background())
+	if err != nil {
+		t.Fatalf("ListFeeds: %v", err)
+	}
+	for _, f := range feeds {
+		if f.LastError != nil || f.ErrorCount != 0 {
+			t.Errorf("%s recorded an error (count %d)", f.URL, f.ErrorCount)
+		}
+	}
+}
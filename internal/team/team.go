@@ -0,0 +1,134 @@
+// ABOUTME: Team mode: named users sharing one digest instance, each with an API token
+// ABOUTME: Authenticates tokens and reports each user's read statistics from their own view
+
+package team
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// tokenBytes is how much randomness goes into an API token.
+const tokenBytes = 24
+
+// Role is what a team user is allowed to do.
+type Role string
+
+const (
+	// RoleReader reads entries and marks them read, unread, or kept.
+	RoleReader Role = "reader"
+	// RoleAdmin can also add and remove feeds.
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole parses a role name.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleReader, RoleAdmin:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q (want reader or admin)", s)
+}
+
+// CanManageFeeds reports whether the role may add and remove feeds.
+func (r Role) CanManageFeeds() bool {
+	return r == RoleAdmin
+}
+
+// Config lists the users sharing this instance's subscriptions.
+type Config struct {
+	Users []string `json:"users"`
+
+	// Roles gives users a role other than the default, reader.
+	Roles map[string]Role `json:"roles,omitempty"`
+
+	// Tokens maps user names to API tokens, filled in from the secrets
+	// store rather than config.json.
+	Tokens map[string]string `json:"-"`
+}
+
+// Enabled reports whether any users are set up, which turns on token
+// checks in 'digest serve'.
+func (c Config) Enabled() bool {
+	return len(c.Users) > 0
+}
+
+// Has reports whether user is on the team.
+func (c Config) Has(user string) bool {
+	return slices.Contains(c.Users, user)
+}
+
+// RoleOf returns user's role.
+func (c Config) RoleOf(user string) Role {
+	if c.Roles[user] == RoleAdmin {
+		return RoleAdmin
+	}
+	return RoleReader
+}
+
+// Authenticate returns the user whose token this is.
+func (c Config) Authenticate(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	found := ""
+	for _, user := range c.Users {
+		want := c.Tokens[user]
+		if want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			found = user
+		}
+	}
+	return found, found != ""
+}
+
+// NewToken returns a fresh random API token.
+func NewToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Stats is one user's reading at a glance.
+type Stats struct {
+	User   string `json:"user"`
+	Total  int    `json:"total"`
+	Unread int    `json:"unread"`
+	Read   int    `json:"read"`
+	Kept   int    `json:"kept"`
+}
+
+// UserStats counts user's read, unread, and kept entries in store.
+func UserStats(ctx context.Context, store storage.Store, user string) (Stats, error) {
+	view, err := storage.ForUser(store, user)
+	if err != nil {
+		return Stats{}, err
+	}
+	overall, err := view.GetOverallStats(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("stats for %s: %w", user, err)
+	}
+	kept := 0
+	err = view.EachEntry(ctx, &storage.EntryFilter{KeptOnly: true, NoContent: true}, func(*models.Entry) error {
+		kept++
+		return nil
+	})
+	if err != nil {
+		return Stats{}, fmt.Errorf("kept entries for %s: %w", user, err)
+	}
+	return Stats{
+		User:   user,
+		Total:  overall.TotalEntries,
+		Unread: overall.UnreadCount,
+		Read:   overall.TotalEntries - overall.UnreadCount,
+		Kept:   kept,
+	}, nil
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Tests for team users and their API tokens
+// ABOUTME: Covers token authentication, including users without a stored token, and per-user stats
+
+package team
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+func TestAuthenticate(t *testing.T) {
+	c := Config{Users: []string{"alice", "bob", "carol"}, Tokens: map[string]string{"alice": "a-token", "bob": "b-token"}}
+
+	for token, want := range map[string]string{"a-token": "alice", "b-token": "bob", "": "", "nope": ""} {
+		got, ok := c.Authenticate(token)
+		if got != want || ok != (want != "") {
+			t.Errorf("Authenticate(%q) = %q, %v; want %q", token, got, ok, want)
+		}
+	}
+	if !c.Has("carol") || c.Has("dave") {
+		t.Error("Has should report team membership")
+	}
+
+	c.Roles = map[string]Role{"alice": RoleAdmin, "bob": "superuser"}
+	if c.RoleOf("alice") != RoleAdmin || c.RoleOf("bob") != RoleReader || c.RoleOf("carol") != RoleReader {
+		t.Errorf("roles = %s, %s, %s; want admin, reader, reader", c.RoleOf("alice"), c.RoleOf("bob"), c.RoleOf("carol"))
+	}
+	if !RoleAdmin.CanManageFeeds() || RoleReader.CanManageFeeds() {
+		t.Error("only admins should manage feeds")
+	}
+	if _, err := ParseRole("owner"); err == nil {
+		t.Error("expected an unknown role to be refused")
+	}
+
+	a, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	b, _ := NewToken()
+	if len(a) != 2*tokenBytes || a == b {
+		t.Errorf("expected distinct %d-character tokens, got %q and %q", 2*tokenBytes, a, b)
+	}
+}
+
+func TestUserStats(t *testing.T) {
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "digest.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	feed := models.NewFeed("https://example.com/feed.xml")
+	if err := store.CreateFeed(ctx, feed); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	var ids []string
+	for _, guid := range []string{"one", "two", "three"} {
+		entry := models.NewEntry(feed.ID, guid, guid)
+		if err := store.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("CreateEntry: %v", err)
+		}
+		ids = append(ids, entry.ID)
+	}
+	alice, err := storage.ForUser(store, "alice")
+	if err != nil {
+		t.Fatalf("ForUser: %v", err)
+	}
+	if err := alice.MarkEntryRead(ctx, ids[0]); err != nil {
+		t.Fatalf("MarkEntryRead: %v", err)
+	}
+	if err := alice.SetEntryKeepUnread(ctx, ids[1], true); err != nil {
+		t.Fatalf("SetEntryKeepUnread: %v", err)
+	}
+
+	got, err := UserStats(ctx, store, "alice")
+	if err != nil {
+		t.Fatalf("UserStats: %v", err)
+	}
+	want := Stats{User: "alice", Total: 3, Unread: 2, Read: 1, Kept: 1}
+	if got != want {
+		t.Errorf("UserStats(alice) = %+v, want %+v", got, want)
+	}
+	if got, _ := UserStats(ctx, store, "bob"); got.Kept != 0 || got.Unread != 3 {
+		t.Errorf("expected bob's stats untouched by alice's, got %+v", got)
+	}
+}
@@ -0,0 +1,196 @@
+// ABOUTME: Line diffs of plain text, used to spot and show changes to monitored web pages
+// ABOUTME: Matches lines by longest common subsequence and renders the changes as a unified diff
+
+package textdiff
+
+import (
+	"html"
+	"strings"
+)
+
+// Op says what happened to a line going from the old text to the new one.
+type Op int
+
+const (
+	Equal  Op = iota // In both texts
+	Delete           // Only in the old text
+	Insert           // Only in the new text
+)
+
+// Line is one line of a diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// maxCells bounds the table used to match the lines between the common
+// prefix and suffix. Past it the whole middle is reported as replaced.
+const maxCells = 4_000_000
+
+// Lines diffs old and new line by line. Blank lines are dropped first, so
+// changes in paragraph spacing don't show up.
+func Lines(old, new string) []Line {
+	a, b := splitLines(old), splitLines(new)
+
+	var diff []Line
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		diff = append(diff, Line{Equal, a[0]})
+		a, b = a[1:], b[1:]
+	}
+	var suffix []Line
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append(suffix, Line{Equal, a[len(a)-1]})
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	diff = append(diff, middle(a, b)...)
+	for i := len(suffix) - 1; i >= 0; i-- {
+		diff = append(diff, suffix[i])
+	}
+	return diff
+}
+
+// middle diffs the lines between the common prefix and suffix.
+func middle(a, b []string) []Line {
+	var diff []Line
+	if len(a)*len(b) > maxCells {
+		for _, s := range a {
+			diff = append(diff, Line{Delete, s})
+		}
+		for _, s := range b {
+			diff = append(diff, Line{Insert, s})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, Line{Equal, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, Line{Delete, a[i]})
+			i++
+		default:
+			diff = append(diff, Line{Insert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, Line{Delete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, Line{Insert, b[j]})
+	}
+	return diff
+}
+
+// splitLines returns the non-blank lines of s with surrounding space trimmed.
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Words counts the words a diff adds and removes. Words that only moved
+// between a deleted line and an inserted one cancel out, so a one-word edit
+// to a long paragraph counts as one word added and one removed.
+func Words(diff []Line) (added, removed int) {
+	counts := make(map[string]int)
+	for _, l := range diff {
+		for _, w := range strings.Fields(l.Text) {
+			switch l.Op {
+			case Insert:
+				counts[w]++
+			case Delete:
+				counts[w]--
+			}
+		}
+	}
+	for _, n := range counts {
+		if n > 0 {
+			added += n
+		} else {
+			removed -= n
+		}
+	}
+	return added, removed
+}
+
+// Changed reports whether a diff has any inserted or deleted lines.
+func Changed(diff []Line) bool {
+	for _, l := range diff {
+		if l.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// HTML renders a diff as Unified does, in a <pre> block.
+func HTML(diff []Line, context int) string {
+	return `<pre><code class="language-diff">` + html.EscapeString(Unified(diff, context)) + "</code></pre>"
+}
+
+// Unified renders a diff as a unified diff, keeping context unchanged lines
+// around each change and marking skipped stretches with "...".
+func Unified(diff []Line, context int) string {
+	// Keep the lines within context of a change
+	keep := make([]bool, len(diff))
+	for i, l := range diff {
+		if l.Op == Equal {
+			continue
+		}
+		for k := max(0, i-context); k <= min(len(diff)-1, i+context); k++ {
+			keep[k] = true
+		}
+	}
+
+	var b strings.Builder
+	skipped := false
+	for i, l := range diff {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			b.WriteString("...\n")
+		}
+		skipped = false
+		switch l.Op {
+		case Insert:
+			b.WriteString("+ ")
+		case Delete:
+			b.WriteString("- ")
+		default:
+			b.WriteString("  ")
+		}
+		b.WriteString(l.Text)
+		b.WriteString("\n")
+	}
+	if skipped {
+		b.WriteString("...\n")
+	}
+	return b.String()
+}
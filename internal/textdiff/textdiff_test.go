@@ -0,0 +1,57 @@
+// ABOUTME: Tests for line diffs of plain text
+// ABOUTME: Verifies lines are matched, word changes are counted, and diffs render with context
+
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	diff := Lines("a\nb\n\nc\nd", "a\nB\nc\n\nd\ne")
+	want := []Line{
+		{Equal, "a"},
+		{Delete, "b"},
+		{Insert, "B"},
+		{Equal, "c"},
+		{Equal, "d"},
+		{Insert, "e"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("Lines = %+v, want %+v", diff, want)
+	}
+	if Changed(Lines("same\n\ntext", "  same\ntext  ")) {
+		t.Error("expected blank lines and surrounding space to be ignored")
+	}
+}
+
+func TestWords(t *testing.T) {
+	diff := Lines("The price is $10 today", "The price is $12 today\nSale ends soon")
+	added, removed := Words(diff)
+	if added != 4 || removed != 1 {
+		t.Errorf("Words = %d added, %d removed; want 4, 1", added, removed)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var old, cur []string
+	for i := range 10 {
+		line := strings.Repeat("x", i+1)
+		old = append(old, line)
+		cur = append(cur, line)
+	}
+	cur[5] = "<changed>"
+	got := HTML(Lines(strings.Join(old, "\n"), strings.Join(cur, "\n")), 1)
+	want := `<pre><code class="language-diff">...
+  xxxxx
+- xxxxxx
++ &lt;changed&gt;
+  xxxxxxx
+...
+</code></pre>`
+	if got != want {
+		t.Errorf("HTML =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,224 @@
+// ABOUTME: Lead image discovery and on-disk caching for entries
+// ABOUTME: Finds an article's first real image or og:image, and stores downloads per entry ID
+
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harperreed/mdstore"
+	"golang.org/x/net/html"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+// ErrNotImage is returned when a download isn't an image digest can cache.
+var ErrNotImage = errors.New("not an image")
+
+// imageExtensions maps detected content types to file extensions.
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+	"image/avif": ".avif",
+}
+
+// ogProperties are the page metadata naming a share image, most specific first.
+var ogProperties = []string{"og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src"}
+
+// FromHTML returns the first image in an HTML fragment worth showing as a
+// thumbnail, resolved against base, or "" if there is none. Inline data
+// images and 1x1 tracking pixels are skipped.
+func FromHTML(fragment, base string) string {
+	if !strings.Contains(fragment, "<img") {
+		return ""
+	}
+	doc, err := html.Parse(strings.NewReader(fragment))
+	if err != nil {
+		return ""
+	}
+
+	var found string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if found != "" {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "img" {
+			var src string
+			pixel := false
+			for _, attr := range n.Attr {
+				switch attr.Key {
+				case "src":
+					src = strings.TrimSpace(attr.Val)
+				case "width", "height":
+					if attr.Val == "1" || attr.Val == "0" {
+						pixel = true
+					}
+				}
+			}
+			if !pixel {
+				found = Resolve(src, base)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return found
+}
+
+// Resolve makes an image reference absolute against base, which may be empty.
+// It returns "" for references that aren't http(s) images, such as data URIs.
+func Resolve(ref, base string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if b, err := url.Parse(base); err == nil && base != "" {
+		u = b.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// OpenGraph fetches an article page and returns the share image it declares
+// with og:image or twitter:image, or "" if it declares none. The page is
+// fetched politely, so a page robots.txt disallows returns
+// fetch.ErrDisallowedByRobots without being requested.
+func OpenGraph(ctx context.Context, pageURL string, allowLocalNetwork bool) (string, error) {
+	result, err := fetch.FetchPage(ctx, pageURL, fetch.PageOptions{AllowLocalNetwork: allowLocalNetwork})
+	if err != nil {
+		return "", err
+	}
+	return metaImage(result.Body, pageURL), nil
+}
+
+// metaImage returns the share image named in a page's <meta> tags.
+func metaImage(page []byte, base string) string {
+	doc, err := html.Parse(strings.NewReader(string(page)))
+	if err != nil {
+		return ""
+	}
+
+	declared := make(map[string]string)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "meta" {
+			var name, value string
+			for _, attr := range n.Attr {
+				switch attr.Key {
+				case "property", "name":
+					name = strings.ToLower(attr.Val)
+				case "content":
+					value = attr.Val
+				}
+			}
+			if _, seen := declared[name]; !seen && value != "" {
+				declared[name] = value
+			}
+		}
+		// Share images are declared in <head>; don't walk the article body
+		if n.Type == html.ElementNode && n.Data == "body" {
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	for _, property := range ogProperties {
+		if image := Resolve(declared[property], base); image != "" {
+			return image
+		}
+	}
+	return ""
+}
+
+// CacheDir returns the thumbnail cache directory inside a profile data directory.
+func CacheDir(profileDir string) string {
+	return filepath.Join(profileDir, "thumbnails")
+}
+
+// Path returns the cached image file for an entry, or "" if none is cached.
+func Path(dir, entryID string) string {
+	matches, err := filepath.Glob(filepath.Join(dir, entryID+".*"))
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	return matches[0]
+}
+
+// Cache downloads an entry's image and stores it as <dir>/<entryID>.<ext>,
+// returning the file's path. It returns ErrNotImage when the download isn't
+// an image.
+func Cache(ctx context.Context, dir, entryID, imageURL string, allowLocalNetwork bool) (string, error) {
+	result, err := fetch.Fetch(ctx, imageURL, nil, nil, allowLocalNetwork)
+	if err != nil {
+		return "", err
+	}
+	ext, ok := imageExtensions[http.DetectContentType(result.Body)]
+	if !ok {
+		return "", ErrNotImage
+	}
+
+	if err := mdstore.EnsureDir(dir); err != nil {
+		return "", fmt.Errorf("failed to create thumbnail directory: %w", err)
+	}
+	Remove(dir, entryID)
+	path := filepath.Join(dir, entryID+ext)
+	if err := mdstore.AtomicWrite(path, result.Body); err != nil {
+		return "", fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	return path, nil
+}
+
+// Remove deletes any cached image for an entry.
+func Remove(dir, entryID string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, entryID+".*"))
+	for _, m := range matches {
+		_ = os.Remove(m)
+	}
+}
+
+// Prune deletes cached images of entries for which keep returns false, such
+// as entries pruned or removed with their feed. It returns how many it
+// deleted.
+func Prune(dir string, keep func(entryID string) bool) (int, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read thumbnail directory: %w", err)
+	}
+	removed := 0
+	for _, f := range files {
+		name := f.Name()
+		id := strings.TrimSuffix(name, filepath.Ext(name))
+		if f.IsDir() || keep(id) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return removed, fmt.Errorf("failed to remove thumbnail: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Tests for lead image discovery and caching
+// ABOUTME: Covers content images, og:image lookup via httptest, and the per-entry cache
+
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+// pngImage is a minimal PNG header, enough for content sniffing.
+var pngImage = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestFromHTML(t *testing.T) {
+	base := "https://example.com/posts/1"
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{"none", "<p>No pictures</p>", ""},
+		{"absolute", `<p><img src="https://cdn.example.com/a.jpg"></p>`, "https://cdn.example.com/a.jpg"},
+		{"relative", `<img src="/img/a.png" alt="">`, "https://example.com/img/a.png"},
+		{"skips pixel", `<img src="https://t.example.com/p.gif" width="1" height="1"><img src="b.jpg">`, "https://example.com/posts/b.jpg"},
+		{"skips data", `<img src="data:image/png;base64,AAAA">`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromHTML(tt.fragment, base); got != tt.want {
+				t.Errorf("FromHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenGraph(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/og":
+			w.Write([]byte(`<html><head>
+<meta name="twitter:image" content="https://example.com/twitter.png">
+<meta property="og:image" content="/share.png">
+</head><body><meta property="og:image" content="/ignored.png"></body></html>`))
+		case "/twitter":
+			w.Write([]byte(`<html><head><meta name="twitter:image" content="https://example.com/twitter.png"></head></html>`))
+		default:
+			w.Write([]byte(`<html><head><title>Plain</title></head></html>`))
+		}
+	}))
+	defer server.Close()
+
+	for path, want := range map[string]string{
+		"/og":      server.URL + "/share.png",
+		"/twitter": "https://example.com/twitter.png",
+		"/plain":   "",
+	} {
+		got, err := OpenGraph(context.Background(), server.URL+path, true)
+		if err != nil {
+			t.Fatalf("OpenGraph(%s): %v", path, err)
+		}
+		if got != want {
+			t.Errorf("OpenGraph(%s) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestOpenGraphHonorsRobots(t *testing.T) {
+	var pageHits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		pageHits.Add(1)
+		w.Write([]byte(`<html><head><meta property="og:image" content="/share.png"></head></html>`))
+	}))
+	defer server.Close()
+
+	got, err := OpenGraph(context.Background(), server.URL+"/private/post", true)
+	if !errors.Is(err, fetch.ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots, got %q, %v", got, err)
+	}
+	if hits := pageHits.Load(); hits != 0 {
+		t.Errorf("expected no request for the disallowed page, got %d", hits)
+	}
+}
+
+func TestCacheAndPrune(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/a.png" {
+			w.Write(pngImage)
+			return
+		}
+		w.Write([]byte("<html>not an image</html>"))
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "thumbnails")
+	path, err := Cache(context.Background(), dir, "entry-1", server.URL+"/a.png", true)
+	if err != nil {
+		t.Fatalf("Cache: %v", err)
+	}
+	if filepath.Base(path) != "entry-1.png" || Path(dir, "entry-1") != path {
+		t.Errorf("unexpected cached path %q", path)
+	}
+
+	if _, err := Cache(context.Background(), dir, "entry-2", server.URL+"/page", true); !errors.Is(err, ErrNotImage) {
+		t.Errorf("expected ErrNotImage, got %v", err)
+	}
+	if Path(dir, "entry-2") != "" {
+		t.Error("expected nothing cached for a non-image")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "gone.jpg"), pngImage, 0644); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := Prune(dir, func(id string) bool { return id == "entry-1" })
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if removed != 1 || Path(dir, "gone") != "" || Path(dir, "entry-1") == "" {
+		t.Errorf("expected only gone.jpg pruned, removed %d", removed)
+	}
+
+	if removed, err := Prune(filepath.Join(t.TempDir(), "missing"), func(string) bool { return false }); err != nil || removed != 0 {
+		t.Errorf("expected a missing directory to prune nothing, got %d, %v", removed, err)
+	}
+}
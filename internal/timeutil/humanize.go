@@ -0,0 +1,48 @@
+// ABOUTME: Humanized relative timestamps for terminal output
+// ABOUTME: Renders recent times as "5m ago" or "3d ago" and older ones as short dates
+
+package timeutil
+
+import (
+	"fmt"
+	"time"
+)
+
+// Ago describes t relative to now the way a person would glance at it:
+// "just now", "5m ago", "2h ago", "3d ago", "2w ago", then a short date
+// ("Jan 2") for anything older than four weeks, with the year once it is
+// over a year old. Times in the future read "in 2h".
+func Ago(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < 0 {
+		if -d < time.Minute {
+			return "just now"
+		}
+		return "in " + shortDuration(-d)
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+	if d < 28*24*time.Hour {
+		return shortDuration(d) + " ago"
+	}
+	t = t.In(now.Location())
+	if d < 365*24*time.Hour {
+		return t.Format("Jan 2")
+	}
+	return t.Format("Jan 2, 2006")
+}
+
+// shortDuration renders d in its largest whole unit, up to weeks.
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	default:
+		return fmt.Sprintf("%dw", int(d/(7*24*time.Hour)))
+	}
+}
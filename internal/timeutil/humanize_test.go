@@ -0,0 +1,45 @@
+// ABOUTME: Tests for humanized relative timestamps
+// ABOUTME: Covers each unit boundary, future times, and the switch to dates
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgo(t *testing.T) {
+	now := time.Date(2025, 3, 12, 15, 30, 0, 0, time.UTC)
+	cases := []struct {
+		t    time.Time
+		want string
+	}{
+		{now, "just now"},
+		{now.Add(-59 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{now.Add(-59 * time.Minute), "59m ago"},
+		{now.Add(-2 * time.Hour), "2h ago"},
+		{now.Add(-23*time.Hour - 59*time.Minute), "23h ago"},
+		{now.Add(-3 * 24 * time.Hour), "3d ago"},
+		{now.Add(-15 * 24 * time.Hour), "2w ago"},
+		{now.Add(-40 * 24 * time.Hour), "Jan 31"},
+		{now.Add(-400 * 24 * time.Hour), "Feb 6, 2024"},
+		{now.Add(30 * time.Second), "just now"},
+		{now.Add(2 * time.Hour), "in 2h"},
+	}
+	for _, tc := range cases {
+		if got := Ago(tc.t, now); got != tc.want {
+			t.Errorf("Ago(%v) = %q, want %q", tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestAgo_UsesNowLocation(t *testing.T) {
+	tokyo, _ := LoadLocation("Asia/Tokyo")
+	now := time.Date(2025, 3, 12, 9, 0, 0, 0, tokyo)
+	// 20:00 UTC on Jan 31 is already Feb 1 in Tokyo
+	then := time.Date(2025, 1, 31, 20, 0, 0, 0, time.UTC)
+	if got := Ago(then, now); got != "Feb 1" {
+		t.Errorf("expected the date in Tokyo, got %q", got)
+	}
+}
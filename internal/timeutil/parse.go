@@ -0,0 +1,171 @@
+// ABOUTME: Parses date expressions used by filters: periods, ISO dates, relative phrases, and ranges
+// ABOUTME: Accepts "3 days ago", "2 weeks", "last monday", and "2024-01-01..2024-02-01" in a given timezone
+
+package timeutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormats describes the accepted date expressions for error messages
+// and help text.
+const DateFormats = "today, yesterday, week, month, last week, last month, YYYY-MM-DD, '3 days ago', '2 weeks', or 'last monday'"
+
+// Range is a span of time from Since (inclusive) to Until (exclusive).
+// A zero Since or Until leaves that side open.
+type Range struct {
+	Since time.Time
+	Until time.Time
+}
+
+// ParseDate resolves a date expression to the instant it starts. Periods,
+// dates, and weekdays start at midnight in now's location; relative phrases
+// like "3 days ago" count back from now itself. Supported forms:
+//
+//	today, yesterday, week, month    start of the current period
+//	last week, last month            start of the previous period
+//	2024-01-15, RFC 3339             that date or instant
+//	3 days ago, 2 weeks, 1 month     that long before now (units: minutes,
+//	                                 hours, days, weeks, months, years)
+//	monday, last monday              the most recent Monday, today included
+//	                                 for "monday" and excluded for "last monday"
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	loc := now.Location()
+	expr := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	if expr == "" {
+		return time.Time{}, fmt.Errorf("empty date: use %s", DateFormats)
+	}
+
+	if t, ok := periodAt(expr, now); ok {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", expr, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(s)); err == nil {
+		return t, nil
+	}
+	if t, ok := relativeAgo(expr, now); ok {
+		return t, nil
+	}
+	if t, ok := weekdayAt(expr, now); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse date %q: use %s", s, DateFormats)
+}
+
+// ParseRange resolves either a single date expression, which opens a range
+// at that date, or "from..until" where either side may be left empty:
+// "2024-01-01..2024-02-01" is January, "..week" is everything before this
+// week. Until is exclusive, so a date on the right ends at its midnight.
+func ParseRange(s string, now time.Time) (Range, error) {
+	from, until, isRange := strings.Cut(s, "..")
+	if !isRange {
+		since, err := ParseDate(s, now)
+		if err != nil {
+			return Range{}, err
+		}
+		return Range{Since: since}, nil
+	}
+
+	var r Range
+	var err error
+	from, until = strings.TrimSpace(from), strings.TrimSpace(until)
+	if from == "" && until == "" {
+		return Range{}, fmt.Errorf("empty range %q: give a start, an end, or both", s)
+	}
+	if from != "" {
+		if r.Since, err = ParseDate(from, now); err != nil {
+			return Range{}, err
+		}
+	}
+	if until != "" {
+		if r.Until, err = ParseDate(until, now); err != nil {
+			return Range{}, err
+		}
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && !r.Until.After(r.Since) {
+		return Range{}, fmt.Errorf("range %q ends before it starts", s)
+	}
+	return r, nil
+}
+
+// periodAt resolves the named periods relative to now.
+func periodAt(expr string, now time.Time) (time.Time, bool) {
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	switch expr {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "week", "this week":
+		return today.AddDate(0, 0, -int(today.Weekday())), true
+	case "last week":
+		return today.AddDate(0, 0, -int(today.Weekday())-7), true
+	case "month", "this month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc), true
+	case "last month":
+		return time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, loc), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// relativeAgo resolves "N unit ago" and the bare "N unit".
+func relativeAgo(expr string, now time.Time) (time.Time, bool) {
+	fields := strings.Fields(strings.TrimSuffix(expr, " ago"))
+	if len(fields) == 1 {
+		// Allow the compact "3d" / "2w" forms
+		i := strings.IndexFunc(fields[0], func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return time.Time{}, false
+		}
+		fields = []string{fields[0][:i], fields[0][i:]}
+	}
+	if len(fields) != 2 {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+
+	switch strings.TrimSuffix(fields[1], "s") {
+	case "minute", "min", "m":
+		return now.Add(-time.Duration(n) * time.Minute), true
+	case "hour", "hr", "h":
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case "day", "d":
+		return now.AddDate(0, 0, -n), true
+	case "week", "wk", "w":
+		return now.AddDate(0, 0, -7*n), true
+	case "month", "mo":
+		return now.AddDate(0, -n, 0), true
+	case "year", "yr", "y":
+		return now.AddDate(-n, 0, 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// weekdayAt resolves "monday" and "last monday" to the midnight that day began.
+func weekdayAt(expr string, now time.Time) (time.Time, bool) {
+	name, last := strings.CutPrefix(expr, "last ")
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name != full && name != full[:3] {
+			continue
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		back := (int(today.Weekday()) - int(d) + 7) % 7
+		if back == 0 && last {
+			back = 7
+		}
+		return today.AddDate(0, 0, -back), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,110 @@
+// ABOUTME: Tests for date expression parsing
+// ABOUTME: Covers periods, relative phrases, weekdays, ranges, and timezone handling
+
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// Wednesday, 2025-03-12 15:30 in New York
+	loc, err := LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	now := time.Date(2025, 3, 12, 15, 30, 0, 0, loc)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, loc) }
+
+	cases := map[string]time.Time{
+		"today":                day(2025, 3, 12),
+		"Yesterday":            day(2025, 3, 11),
+		"week":                 day(2025, 3, 9),
+		"last week":            day(2025, 3, 2),
+		"month":                day(2025, 3, 1),
+		"last month":           day(2025, 2, 1),
+		"2024-01-15":           day(2024, 1, 15),
+		"2024-01-15T10:00:00Z": time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		"3 days ago":           time.Date(2025, 3, 9, 15, 30, 0, 0, loc),
+		"2 weeks":              time.Date(2025, 2, 26, 15, 30, 0, 0, loc),
+		"1 month ago":          time.Date(2025, 2, 12, 15, 30, 0, 0, loc),
+		"6 hours ago":          now.Add(-6 * time.Hour),
+		"90  minutes  ago":     now.Add(-90 * time.Minute),
+		"1 year":               time.Date(2024, 3, 12, 15, 30, 0, 0, loc),
+		"3d":                   time.Date(2025, 3, 9, 15, 30, 0, 0, loc),
+		"2w":                   time.Date(2025, 2, 26, 15, 30, 0, 0, loc),
+		"monday":               day(2025, 3, 10),
+		"last monday":          day(2025, 3, 10),
+		"wednesday":            day(2025, 3, 12),
+		"last wednesday":       day(2025, 3, 5),
+		"last fri":             day(2025, 3, 7),
+	}
+	for in, want := range cases {
+		got, err := ParseDate(in, now)
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, want %v", in, got, want)
+		}
+	}
+
+	for _, bad := range []string{"", "not-a-date", "2024-12", "3 fortnights ago", "-2 days", "next monday", "a..b"} {
+		if _, err := ParseDate(bad, now); err == nil {
+			t.Errorf("expected ParseDate(%q) to fail", bad)
+		}
+	}
+}
+
+func TestParseDate_SpringForward(t *testing.T) {
+	// DST began on 2025-03-09 in New York; day arithmetic stays on midnight
+	loc, _ := LoadLocation("America/New_York")
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, loc)
+	got, err := ParseDate("last week", now)
+	if err != nil {
+		t.Fatalf("ParseDate: %v", err)
+	}
+	if got.Hour() != 0 || got.Day() != 2 {
+		t.Errorf("expected midnight on March 2, got %v", got)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	now := time.Date(2025, 3, 12, 15, 30, 0, 0, time.UTC)
+
+	r, err := ParseRange("2024-01-01..2024-02-01", now)
+	if err != nil {
+		t.Fatalf("ParseRange: %v", err)
+	}
+	if !r.Since.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !r.Until.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected range %+v", r)
+	}
+
+	r, err = ParseRange("last week..week", now)
+	if err != nil || !r.Since.Equal(time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)) || !r.Until.Equal(time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected range %+v (%v)", r, err)
+	}
+
+	r, err = ParseRange("3 days ago", now)
+	if err != nil || !r.Since.Equal(now.AddDate(0, 0, -3)) || !r.Until.IsZero() {
+		t.Errorf("expected an open-ended range from a single date, got %+v (%v)", r, err)
+	}
+
+	r, err = ParseRange("..2024-06-01", now)
+	if err != nil || !r.Since.IsZero() || r.Until.IsZero() {
+		t.Errorf("expected a range open at the start, got %+v (%v)", r, err)
+	}
+
+	r, err = ParseRange("2024-06-01..", now)
+	if err != nil || r.Since.IsZero() || !r.Until.IsZero() {
+		t.Errorf("expected a range open at the end, got %+v (%v)", r, err)
+	}
+
+	for _, bad := range []string{"..", "2024-02-01..2024-01-01", "2024-01-01..soon"} {
+		if _, err := ParseRange(bad, now); err == nil {
+			t.Errorf("expected ParseRange(%q) to fail", bad)
+		}
+	}
+}
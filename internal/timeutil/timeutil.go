@@ -1,19 +1,48 @@
 // ABOUTME: Time utility functions for date range calculations
-// ABOUTME: Provides helpers for smart views like today, yesterday, this week
+// ABOUTME: Provides helpers for smart views like today, yesterday, this week, in any timezone
 
 package timeutil
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	// Embed the timezone database so named zones resolve on hosts without one
+	_ "time/tzdata"
+)
+
+// LoadLocation resolves an IANA timezone name such as "America/New_York".
+// An empty name is the local timezone.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: use an IANA name like America/New_York or UTC", name)
+	}
+	return loc, nil
+}
 
 // StartOfToday returns midnight (00:00:00) of the current day in local time
 func StartOfToday() time.Time {
-	now := time.Now()
-	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return StartOfTodayIn(time.Local)
+}
+
+// StartOfTodayIn returns midnight of the current day in loc
+func StartOfTodayIn(loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
 }
 
 // StartOfYesterday returns midnight (00:00:00) of yesterday in local time
 func StartOfYesterday() time.Time {
-	return StartOfToday().AddDate(0, 0, -1)
+	return StartOfYesterdayIn(time.Local)
+}
+
+// StartOfYesterdayIn returns midnight of yesterday in loc
+func StartOfYesterdayIn(loc *time.Location) time.Time {
+	return StartOfTodayIn(loc).AddDate(0, 0, -1)
 }
 
 // EndOfYesterday returns the last moment of yesterday (start of today) in local time
@@ -24,30 +53,46 @@ func EndOfYesterday() time.Time {
 // StartOfWeek returns midnight of the most recent Sunday in local time
 // Note: Week starts on Sunday
 func StartOfWeek() time.Time {
-	today := StartOfToday()
+	return StartOfWeekIn(time.Local)
+}
+
+// StartOfWeekIn returns midnight of the most recent Sunday in loc
+func StartOfWeekIn(loc *time.Location) time.Time {
+	today := StartOfTodayIn(loc)
 	weekday := int(today.Weekday())
 	return today.AddDate(0, 0, -weekday)
 }
 
 // StartOfMonth returns midnight of the first day of the current month in local time
 func StartOfMonth() time.Time {
-	now := time.Now()
-	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return StartOfMonthIn(time.Local)
+}
+
+// StartOfMonthIn returns midnight of the first day of the current month in loc
+func StartOfMonthIn(loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
 }
 
 // ParsePeriod converts a period string to a time.Time representing the cutoff
 // Supported values: "today", "yesterday", "week", "month"
 // Returns the start of that period (articles before this time would be marked)
 func ParsePeriod(period string) (time.Time, bool) {
+	return ParsePeriodIn(period, time.Local)
+}
+
+// ParsePeriodIn is ParsePeriod with period boundaries at midnight in loc,
+// so "today" means the user's today rather than the server's.
+func ParsePeriodIn(period string, loc *time.Location) (time.Time, bool) {
 	switch period {
 	case "today":
-		return StartOfToday(), true
+		return StartOfTodayIn(loc), true
 	case "yesterday":
-		return StartOfYesterday(), true
+		return StartOfYesterdayIn(loc), true
 	case "week":
-		return StartOfWeek(), true
+		return StartOfWeekIn(loc), true
 	case "month":
-		return StartOfMonth(), true
+		return StartOfMonthIn(loc), true
 	default:
 		return time.Time{}, false
 	}
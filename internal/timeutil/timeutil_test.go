@@ -409,3 +409,48 @@ func TestMonthLengthVariations(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePeriodIn_Timezone(t *testing.T) {
+	for _, name := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago", "UTC"} {
+		loc, err := LoadLocation(name)
+		if err != nil {
+			t.Fatalf("LoadLocation(%q): %v", name, err)
+		}
+		now := time.Now().In(loc)
+		for _, period := range []string{"today", "yesterday", "week", "month"} {
+			got, ok := ParsePeriodIn(period, loc)
+			if !ok {
+				t.Fatalf("ParsePeriodIn(%q) not recognized", period)
+			}
+			local := got.In(loc)
+			if local.Hour() != 0 || local.Minute() != 0 {
+				t.Errorf("ParsePeriodIn(%q, %s) = %v, expected midnight in that zone", period, name, local)
+			}
+			if got.After(now) {
+				t.Errorf("ParsePeriodIn(%q, %s) = %v is in the future", period, name, got)
+			}
+		}
+		today, _ := ParsePeriodIn("today", loc)
+		if today.In(loc).Day() != now.Day() {
+			t.Errorf("today in %s = %v, expected day %d", name, today.In(loc), now.Day())
+		}
+	}
+
+	// UTC+14 and UTC-11 are 25 hours apart, so their todays never coincide
+	east, _ := LoadLocation("Pacific/Kiritimati")
+	west, _ := LoadLocation("Pacific/Pago_Pago")
+	a, _ := ParsePeriodIn("today", east)
+	b, _ := ParsePeriodIn("today", west)
+	if a.Equal(b) {
+		t.Errorf("expected different starts of today across zones, got %v for both", a)
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	if loc, err := LoadLocation(""); err != nil || loc != time.Local {
+		t.Errorf("LoadLocation(\"\") = %v, %v; expected local time", loc, err)
+	}
+	if _, err := LoadLocation("Mars/Olympus_Mons"); err == nil {
+		t.Error("expected an error for an unknown timezone")
+	}
+}
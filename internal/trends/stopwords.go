@@ -0,0 +1,40 @@
+// ABOUTME: English stopword list for trending topic extraction
+// ABOUTME: Common function words and web boilerplate that never make a useful topic
+
+package trends
+
+import "strings"
+
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range []string{
+		"a", "about", "above", "after", "again", "against", "all", "also", "am", "an", "and", "any", "are",
+		"aren't", "as", "at", "be", "because", "been", "before", "being", "below", "between", "both", "but",
+		"by", "can", "can't", "could", "did", "didn't", "do", "does", "doesn't", "doing", "don't", "down",
+		"during", "each", "even", "every", "few", "first", "for", "from", "further", "get", "gets", "getting",
+		"going", "good", "got", "great", "had", "has", "have", "having", "he", "her", "here", "hers", "him",
+		"his", "how", "however", "i", "if", "in", "into", "is", "isn't", "it", "it's", "its", "itself",
+		"just", "know", "last", "let", "like", "made", "make", "makes", "many", "may", "me", "might", "more",
+		"most", "much", "must", "my", "new", "no", "nor", "not", "now", "of", "off", "often", "on", "once",
+		"one", "only", "or", "other", "our", "ours", "out", "over", "own", "people", "really", "right",
+		"said", "same", "say", "says", "see", "she", "should", "since", "so", "some", "still", "such",
+		"take", "than", "that", "that's", "the", "their", "them", "then", "there", "these", "they", "thing",
+		"things", "think", "this", "those", "through", "time", "to", "too", "two", "under", "until", "up",
+		"use", "used", "using", "very", "want", "was", "wasn't", "way", "we", "well", "were", "what",
+		"when", "where", "which", "while", "who", "why", "will", "with", "without", "won't", "work",
+		"would", "year", "years", "yet", "you", "your", "yours",
+		// Feed and web boilerplate
+		"article", "blog", "click", "comments", "continue", "post", "posted", "read", "reading", "share",
+		"subscribe", "via",
+		// Calendar words that mark dates rather than topics
+		"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "today", "yesterday",
+		"week", "month",
+	} {
+		stopwords[w] = true
+	}
+}
+
+func isStopword(w string) bool {
+	return stopwords[strings.ReplaceAll(w, "’", "'")]
+}
@@ -0,0 +1,297 @@
+// ABOUTME: Trending topic extraction over stored entries using TF-IDF
+// ABOUTME: Scores keywords and capitalized entity phrases in a date range against a baseline of earlier entries
+
+package trends
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/harper/digest/internal/content"
+	"github.com/harper/digest/internal/models"
+	"github.com/harper/digest/internal/storage"
+)
+
+// Topic kinds.
+const (
+	KindKeyword = "keyword"
+	KindEntity  = "entity"
+)
+
+// baselineFactor sets how far back the baseline reaches, in multiples of the
+// range length. A week of trends is measured against the four weeks before it.
+const baselineFactor = 4
+
+// maxExampleEntries caps the entry IDs listed with each topic.
+const maxExampleEntries = 3
+
+// Document is one entry's text, tagged with its folder. Baseline documents
+// only inform how common a term usually is; they are never reported.
+type Document struct {
+	ID       string
+	Folder   string
+	Text     string
+	Baseline bool
+}
+
+// Topic is a trending term and how widely it appeared in the range.
+type Topic struct {
+	Term     string   `json:"term"`
+	Kind     string   `json:"kind"`
+	Score    float64  `json:"score"`
+	Entries  int      `json:"entries"`
+	EntryIDs []string `json:"entry_ids"`
+}
+
+// FolderTopics is the trend breakdown for one OPML folder ("" is the root level).
+type FolderTopics struct {
+	Folder  string  `json:"folder"`
+	Entries int     `json:"entries"`
+	Topics  []Topic `json:"topics"`
+}
+
+// Report holds overall and per-folder trends.
+type Report struct {
+	Entries int            `json:"entries"`
+	Topics  []Topic        `json:"topics"`
+	Folders []FolderTopics `json:"folders,omitempty"`
+}
+
+// Options tune Analyze.
+type Options struct {
+	// Limit is the number of topics reported overall and per folder.
+	Limit int
+	// MinEntries is how many in-range entries must mention a term. Zero
+	// means 2, or 1 when the range holds a single entry.
+	MinEntries int
+	// ByFolder adds a per-folder breakdown.
+	ByFolder bool
+}
+
+// Collect loads the entries published in [since, until) plus the baseline
+// window before since, and turns them into documents. folderOf maps a feed
+// URL to its OPML folder.
+func Collect(ctx context.Context, s storage.Store, folderOf func(url string) string, since, until time.Time) ([]Document, error) {
+	feeds, err := s.ListFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	folders := make(map[string]string, len(feeds))
+	for _, feed := range feeds {
+		folders[feed.ID] = folderOf(feed.URL)
+	}
+
+	baselineStart := since.Add(-baselineFactor * until.Sub(since))
+	entries, err := s.ListEntries(ctx, &storage.EntryFilter{Since: &baselineStart, Until: &until})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	docs := make([]Document, 0, len(entries))
+	for _, entry := range entries {
+		docs = append(docs, Document{
+			ID:       entry.ID,
+			Folder:   folders[entry.FeedID],
+			Text:     entryText(entry),
+			Baseline: entryTime(entry).Before(since),
+		})
+	}
+	return docs, nil
+}
+
+// Analyze scores every term in the in-range documents. A term's score sums
+// 1+ln(tf) over the range documents that use it, weighted by its smoothed
+// inverse document frequency across range and baseline together, so words
+// that are always common score low and words that spiked score high.
+func Analyze(docs []Document, opts Options) Report {
+	type docTerms struct {
+		doc   Document
+		terms map[string]int
+	}
+
+	parsed := make([]docTerms, 0, len(docs))
+	df := make(map[string]int)
+	display := make(map[string]string)
+	kinds := make(map[string]string)
+	inRange := 0
+	for _, doc := range docs {
+		terms := extractTerms(doc.Text, display, kinds)
+		for key := range terms {
+			df[key]++
+		}
+		parsed = append(parsed, docTerms{doc: doc, terms: terms})
+		if !doc.Baseline {
+			inRange++
+		}
+	}
+
+	total := len(docs)
+	score := func(folder *string) []Topic {
+		scores := make(map[string]float64)
+		ids := make(map[string][]string)
+		for _, p := range parsed {
+			if p.doc.Baseline || (folder != nil && p.doc.Folder != *folder) {
+				continue
+			}
+			for key, tf := range p.terms {
+				idf := math.Log(float64(total+1)/float64(df[key]+1)) + 1
+				scores[key] += (1 + math.Log(float64(tf))) * idf
+				ids[key] = append(ids[key], p.doc.ID)
+			}
+		}
+
+		count := 0
+		for _, p := range parsed {
+			if !p.doc.Baseline && (folder == nil || p.doc.Folder == *folder) {
+				count++
+			}
+		}
+		minEntries := opts.MinEntries
+		if minEntries <= 0 {
+			minEntries = 2
+			if count < 2 {
+				minEntries = 1
+			}
+		}
+
+		topics := make([]Topic, 0, len(scores))
+		for key, sc := range scores {
+			if len(ids[key]) < minEntries {
+				continue
+			}
+			examples := ids[key]
+			if len(examples) > maxExampleEntries {
+				examples = examples[:maxExampleEntries]
+			}
+			topics = append(topics, Topic{
+				Term:     display[key],
+				Kind:     kinds[key],
+				Score:    math.Round(sc*100) / 100,
+				Entries:  len(ids[key]),
+				EntryIDs: examples,
+			})
+		}
+		sort.Slice(topics, func(i, j int) bool {
+			if topics[i].Score != topics[j].Score {
+				return topics[i].Score > topics[j].Score
+			}
+			return topics[i].Term < topics[j].Term
+		})
+		if opts.Limit > 0 && len(topics) > opts.Limit {
+			topics = topics[:opts.Limit]
+		}
+		return topics
+	}
+
+	report := Report{Entries: inRange, Topics: score(nil)}
+	if opts.ByFolder {
+		counts := make(map[string]int)
+		for _, p := range parsed {
+			if !p.doc.Baseline {
+				counts[p.doc.Folder]++
+			}
+		}
+		names := make([]string, 0, len(counts))
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			report.Folders = append(report.Folders, FolderTopics{Folder: name, Entries: counts[name], Topics: score(&name)})
+		}
+	}
+	return report
+}
+
+var (
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}+#'’-]*`)
+	// Two to four capitalized words in a row: "Rust Foundation", "New York Times"
+	entityPattern = regexp.MustCompile(`\b\p{Lu}[\p{L}\p{N}]+(?:\s+\p{Lu}[\p{L}\p{N}]+){1,3}\b`)
+)
+
+// extractTerms counts keyword and entity occurrences in text. Keys are
+// lowercased; display and kinds record how each key is shown and classified.
+func extractTerms(text string, display, kinds map[string]string) map[string]int {
+	terms := make(map[string]int)
+
+	for _, m := range entityPattern.FindAllString(text, -1) {
+		words := strings.Fields(m)
+		// Drop leading and trailing stopwords: "The Rust Foundation" → "Rust Foundation"
+		for len(words) > 0 && isStopword(strings.ToLower(words[0])) {
+			words = words[1:]
+		}
+		for len(words) > 0 && isStopword(strings.ToLower(words[len(words)-1])) {
+			words = words[:len(words)-1]
+		}
+		if len(words) < 2 {
+			continue
+		}
+		phrase := strings.Join(words, " ")
+		key := strings.ToLower(phrase)
+		terms[key]++
+		if _, ok := display[key]; !ok {
+			display[key] = phrase
+			kinds[key] = KindEntity
+		}
+	}
+
+	for _, w := range wordPattern.FindAllString(text, -1) {
+		key := normalizeWord(w)
+		if key == "" {
+			continue
+		}
+		terms[key]++
+		if _, ok := display[key]; !ok {
+			display[key] = key
+			kinds[key] = KindKeyword
+		}
+	}
+	return terms
+}
+
+// normalizeWord lowercases a word and strips possessives and edge
+// punctuation, returning "" for stopwords, numbers, and very short words.
+func normalizeWord(w string) string {
+	w = strings.ToLower(w)
+	w = strings.TrimSuffix(strings.TrimSuffix(w, "'s"), "’s")
+	w = strings.TrimRight(w, "'’-")
+	if len([]rune(w)) < 3 || isStopword(w) {
+		return ""
+	}
+	hasLetter := false
+	for _, r := range w {
+		if unicode.IsLetter(r) {
+			hasLetter = true
+			break
+		}
+	}
+	if !hasLetter {
+		return ""
+	}
+	return w
+}
+
+func entryText(entry *models.Entry) string {
+	var b strings.Builder
+	if entry.Title != nil {
+		b.WriteString(*entry.Title)
+		b.WriteString(".\n")
+	}
+	if entry.Content != nil {
+		b.WriteString(content.ToText(*entry.Content))
+	}
+	return b.String()
+}
+
+func entryTime(entry *models.Entry) time.Time {
+	if entry.PublishedAt != nil {
+		return *entry.PublishedAt
+	}
+	return entry.CreatedAt
+}
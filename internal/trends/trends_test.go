@@ -0,0 +1,90 @@
+// ABOUTME: Tests for trending topic extraction
+// ABOUTME: Covers term normalization, entity phrases, baseline weighting, and per-folder breakdowns
+
+package trends
+
+import (
+	"testing"
+)
+
+func TestExtractTerms(t *testing.T) {
+	display := map[string]string{}
+	kinds := map[string]string{}
+	terms := extractTerms("The Rust Foundation said Rust's compiler is faster. The 2026 release is out.", display, kinds)
+
+	if terms["rust foundation"] != 1 || kinds["rust foundation"] != KindEntity || display["rust foundation"] != "Rust Foundation" {
+		t.Errorf("expected the entity 'Rust Foundation', got terms %v", terms)
+	}
+	if terms["rust"] != 2 {
+		t.Errorf("expected possessive to normalize, got rust=%d", terms["rust"])
+	}
+	for _, skipped := range []string{"the", "said", "2026", "is"} {
+		if _, ok := terms[skipped]; ok {
+			t.Errorf("expected %q to be skipped", skipped)
+		}
+	}
+}
+
+func TestAnalyzePrefersSpikesOverBaseline(t *testing.T) {
+	docs := []Document{
+		{ID: "b1", Text: "Kubernetes tips for clusters", Baseline: true},
+		{ID: "b2", Text: "More Kubernetes clusters", Baseline: true},
+		{ID: "b3", Text: "Kubernetes operators", Baseline: true},
+		{ID: "r1", Folder: "Tech", Text: "WebAssembly components land in Kubernetes"},
+		{ID: "r2", Folder: "Tech", Text: "Why WebAssembly matters for Kubernetes"},
+		{ID: "r3", Folder: "News", Text: "WebAssembly everywhere, says Bytecode Alliance"},
+		{ID: "r4", Folder: "News", Text: "Bytecode Alliance ships new WebAssembly tooling"},
+	}
+
+	report := Analyze(docs, Options{Limit: 5, ByFolder: true})
+	if report.Entries != 4 {
+		t.Errorf("expected 4 in-range entries, got %d", report.Entries)
+	}
+	if len(report.Topics) == 0 || report.Topics[0].Term != "webassembly" {
+		t.Fatalf("expected webassembly to trend first, got %+v", report.Topics)
+	}
+	if report.Topics[0].Entries != 4 || len(report.Topics[0].EntryIDs) != maxExampleEntries {
+		t.Errorf("expected 4 entries with %d examples, got %+v", maxExampleEntries, report.Topics[0])
+	}
+
+	rank := map[string]int{}
+	for i, topic := range report.Topics {
+		rank[topic.Term] = i
+	}
+	if k, ok := rank["kubernetes"]; ok && k < rank["Bytecode Alliance"] {
+		t.Errorf("expected the baseline-heavy term to rank below the new entity: %+v", report.Topics)
+	}
+
+	if len(report.Folders) != 2 || report.Folders[0].Folder != "News" || report.Folders[1].Folder != "Tech" {
+		t.Fatalf("expected News and Tech folders, got %+v", report.Folders)
+	}
+	news := report.Folders[0]
+	if news.Entries != 2 {
+		t.Errorf("expected 2 News entries, got %d", news.Entries)
+	}
+	found := false
+	for _, topic := range news.Topics {
+		if topic.Term == "Bytecode Alliance" && topic.Kind == KindEntity {
+			found = true
+		}
+		if topic.Term == "kubernetes" {
+			t.Errorf("expected Tech-only terms to stay out of News")
+		}
+	}
+	if !found {
+		t.Errorf("expected Bytecode Alliance in News topics, got %+v", news.Topics)
+	}
+}
+
+func TestAnalyzeMinEntries(t *testing.T) {
+	docs := []Document{
+		{ID: "r1", Text: "Zig compiler news"},
+		{ID: "r2", Text: "Gleam language release"},
+	}
+	if report := Analyze(docs, Options{}); len(report.Topics) != 0 {
+		t.Errorf("expected no topics shared by two entries, got %+v", report.Topics)
+	}
+	if report := Analyze(docs[:1], Options{}); len(report.Topics) == 0 {
+		t.Errorf("expected a single entry range to report its own terms")
+	}
+}
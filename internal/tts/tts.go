@@ -0,0 +1,261 @@
+// ABOUTME: Text-to-speech backends for audio briefings
+// ABOUTME: Runs a local command or calls an OpenAI-compatible speech endpoint to write audio files
+
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Backend names accepted in Config.Backend.
+const (
+	BackendCommand = "command"
+	BackendOpenAI  = "openai"
+)
+
+// Defaults for the OpenAI-compatible backend.
+const (
+	DefaultURL       = "https://api.openai.com/v1/audio/speech"
+	DefaultModel     = "tts-1"
+	DefaultVoice     = "alloy"
+	DefaultAPIKeyEnv = "OPENAI_API_KEY"
+)
+
+// outputPlaceholder is replaced with the output path in command arguments.
+const outputPlaceholder = "{output}"
+
+// maxRequestChars is the most text sent in one speech API request. OpenAI's
+// endpoint rejects input over 4096 characters, so longer scripts are split.
+const maxRequestChars = 4000
+
+// requestTimeout bounds a single speech API request.
+const requestTimeout = 2 * time.Minute
+
+// Config selects and configures a TTS backend.
+type Config struct {
+	// Backend is "command" or "openai".
+	Backend string `json:"backend,omitempty"`
+
+	// Command is the program and arguments for the command backend. The text
+	// is written to its stdin and "{output}" in any argument is replaced with
+	// the audio file path. Example: ["piper", "--model", "en_US", "--output_file", "{output}"]
+	Command []string `json:"command,omitempty"`
+
+	// URL, Model, and Voice configure the openai backend. Any server that
+	// implements OpenAI's /v1/audio/speech API works.
+	URL   string `json:"url,omitempty"`
+	Model string `json:"model,omitempty"`
+	Voice string `json:"voice,omitempty"`
+
+	// APIKeyEnv names the environment variable holding the API key, so the
+	// key itself never lands in config.json.
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+
+	// APIKey is the key itself when the caller found it elsewhere, such as
+	// a keychain. It takes precedence over APIKeyEnv and is never saved.
+	APIKey string `json:"-"`
+}
+
+// KeyEnv returns the environment variable the API key is read from.
+func (c Config) KeyEnv() string {
+	if c.APIKeyEnv != "" {
+		return c.APIKeyEnv
+	}
+	return DefaultAPIKeyEnv
+}
+
+// Synthesizer turns text into an audio file.
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text, outPath string) error
+}
+
+// New returns the Synthesizer described by cfg.
+func New(cfg Config) (Synthesizer, error) {
+	switch cfg.Backend {
+	case BackendCommand:
+		if len(cfg.Command) == 0 {
+			return nil, fmt.Errorf("tts command backend needs a command in config.json")
+		}
+		return &commandBackend{argv: cfg.Command}, nil
+	case BackendOpenAI:
+		key := cfg.APIKey
+		if key == "" {
+			key = os.Getenv(cfg.KeyEnv())
+		}
+		if key == "" {
+			return nil, fmt.Errorf("tts openai backend needs an API key in $%s or the %q secret", cfg.KeyEnv(), "tts/api-key")
+		}
+		return &openAIBackend{
+			url:    withDefault(cfg.URL, DefaultURL),
+			model:  withDefault(cfg.Model, DefaultModel),
+			voice:  withDefault(cfg.Voice, DefaultVoice),
+			apiKey: key,
+			client: &http.Client{Timeout: requestTimeout},
+		}, nil
+	case "":
+		return nil, fmt.Errorf("no tts backend configured; set \"tts\" in config.json (backend %q or %q)", BackendCommand, BackendOpenAI)
+	default:
+		return nil, fmt.Errorf("unknown tts backend: %s (use %s or %s)", cfg.Backend, BackendCommand, BackendOpenAI)
+	}
+}
+
+func withDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// commandBackend pipes text to a local TTS program.
+type commandBackend struct {
+	argv []string
+}
+
+func (b *commandBackend) Synthesize(ctx context.Context, text, outPath string) error {
+	args := make([]string, len(b.argv))
+	for i, arg := range b.argv {
+		args[i] = strings.ReplaceAll(arg, outputPlaceholder, outPath)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return fmt.Errorf("tts command %s failed: %w: %s", args[0], err, msg)
+		}
+		return fmt.Errorf("tts command %s failed: %w", args[0], err)
+	}
+	if _, err := os.Stat(outPath); err != nil {
+		return fmt.Errorf("tts command %s wrote no audio to %s (does it use %s?)", args[0], outPath, outputPlaceholder)
+	}
+	return nil
+}
+
+// openAIBackend calls an OpenAI-compatible speech endpoint, which returns MP3.
+type openAIBackend struct {
+	url    string
+	model  string
+	voice  string
+	apiKey string
+	client *http.Client
+}
+
+func (b *openAIBackend) Synthesize(ctx context.Context, text, outPath string) error {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create audio file: %w", err)
+	}
+
+	// MP3 frames are self-contained, so per-chunk responses can be appended
+	for _, chunk := range SplitText(text, maxRequestChars) {
+		if err := b.speak(ctx, chunk, f); err != nil {
+			f.Close()
+			os.Remove(outPath)
+			return err
+		}
+	}
+	return f.Close()
+}
+
+func (b *openAIBackend) speak(ctx context.Context, text string, w io.Writer) error {
+	body, err := json.Marshal(map[string]string{
+		"model":           b.model,
+		"voice":           b.voice,
+		"input":           text,
+		"response_format": "mp3",
+	})
+	if err != nil {
+		return fmt.Errorf("encode speech request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create speech request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+b.apiKey)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("speech request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("speech request failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("write audio: %w", err)
+	}
+	return nil
+}
+
+// SplitText breaks text into pieces of at most limit characters, splitting
+// between paragraphs where possible, then between sentences, then words.
+func SplitText(text string, limit int) []string {
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		current.Reset()
+	}
+	add := func(piece, sep string) {
+		if current.Len() > 0 && current.Len()+len(sep)+len(piece) > limit {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(sep)
+		}
+		current.WriteString(piece)
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		if len(para) <= limit {
+			add(para, "\n\n")
+			continue
+		}
+		for _, sentence := range splitSentences(para) {
+			if len(sentence) <= limit {
+				add(sentence, " ")
+				continue
+			}
+			for _, word := range strings.Fields(sentence) {
+				add(word, " ")
+			}
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitSentences splits a paragraph after sentence-ending punctuation.
+func splitSentences(para string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(para)-1; i++ {
+		if strings.ContainsRune(".!?", rune(para[i])) && para[i+1] == ' ' {
+			sentences = append(sentences, strings.TrimSpace(para[start:i+1]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(para[start:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
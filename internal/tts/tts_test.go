@@ -0,0 +1,109 @@
+// ABOUTME: Tests for text-to-speech backends
+// ABOUTME: Uses a shell command and a fake speech server instead of real TTS engines
+
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandBackend(t *testing.T) {
+	synth, err := New(Config{Backend: BackendCommand, Command: []string{"sh", "-c", "cat > \"$0\"", "{output}"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "briefing.mp3")
+	if err := synth.Synthesize(context.Background(), "Hello listener.", out); err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Hello listener." {
+		t.Errorf("expected text on stdin to reach the output, got %q", data)
+	}
+}
+
+func TestCommandBackendWithoutOutput(t *testing.T) {
+	synth, err := New(Config{Backend: BackendCommand, Command: []string{"true"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	err = synth.Synthesize(context.Background(), "text", filepath.Join(t.TempDir(), "out.mp3"))
+	if err == nil || !strings.Contains(err.Error(), "wrote no audio") {
+		t.Errorf("expected a missing output error, got %v", err)
+	}
+}
+
+func TestOpenAIBackend(t *testing.T) {
+	var inputs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		inputs = append(inputs, body["input"])
+		_, _ = w.Write([]byte("MP3[" + body["voice"] + "]"))
+	}))
+	defer srv.Close()
+
+	t.Setenv("TEST_TTS_KEY", "test-key")
+	synth, err := New(Config{Backend: BackendOpenAI, URL: srv.URL, Voice: "nova", APIKeyEnv: "TEST_TTS_KEY"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	text := strings.Repeat("A sentence of words. ", 300) + "\n\nThe end."
+	out := filepath.Join(t.TempDir(), "briefing.mp3")
+	if err := synth.Synthesize(context.Background(), text, out); err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+
+	if len(inputs) < 2 {
+		t.Errorf("expected long text to be split across requests, got %d", len(inputs))
+	}
+	data, _ := os.ReadFile(out)
+	if string(data) != strings.Repeat("MP3[nova]", len(inputs)) {
+		t.Errorf("expected concatenated audio, got %q", data)
+	}
+}
+
+func TestNewRequiresConfig(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Error("expected an error with no backend")
+	}
+	if _, err := New(Config{Backend: "espeak"}); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+	t.Setenv("MISSING_TTS_KEY", "")
+	if _, err := New(Config{Backend: BackendOpenAI, APIKeyEnv: "MISSING_TTS_KEY"}); err == nil {
+		t.Error("expected an error without an API key")
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	chunks := SplitText("First para.\n\nSecond one here. And more.", 20)
+	want := []string{"First para.", "Second one here.", "And more."}
+	if strings.Join(chunks, "|") != strings.Join(want, "|") {
+		t.Errorf("SplitText() = %q, want %q", chunks, want)
+	}
+	for _, c := range SplitText(strings.Repeat("word ", 100), 30) {
+		if len(c) > 30 {
+			t.Errorf("chunk over limit: %q", c)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// ABOUTME: Built-in pager for reading long articles in the terminal.
+// ABOUTME: Bubbletea viewport with less-style scrolling, / search, and keys to step through and open links.
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// pagerHelp lists the pager keys in the status bar.
+const pagerHelp = "/ search • n/N next/prev • [/] links • o open • q quit"
+
+var (
+	linkPattern      = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+	statusStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	selectedLinkText = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+)
+
+// pagerLink is a URL found in the paged text and the line it is on.
+type pagerLink struct {
+	url  string
+	line int
+}
+
+// openedMsg reports the result of opening a link.
+type openedMsg struct {
+	url string
+	err error
+}
+
+// PagerModel is the bubbletea model for the built-in pager.
+type PagerModel struct {
+	title    string
+	lines    []string // Text as shown, possibly styled
+	plain    []string // Lines with styling removed, for search and links
+	links    []pagerLink
+	primary  string // Link opened by o when none is selected
+	open     func(string) error
+	viewport viewport.Model
+	ready    bool
+
+	searching bool
+	input     textinput.Model
+	query     string
+	matches   []int // Lines matching query
+	match     int
+	link      int // Selected link, -1 for none
+	status    string
+}
+
+// NewPagerModel creates a pager over text. primary is the link o opens when
+// no link is selected (usually the article itself), and open launches a link.
+func NewPagerModel(title, text, primary string, open func(string) error) PagerModel {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	plain := make([]string, len(lines))
+	var links []pagerLink
+	seen := make(map[string]bool)
+	for i, line := range lines {
+		plain[i] = ansi.Strip(line)
+		for _, url := range linkPattern.FindAllString(plain[i], -1) {
+			url = strings.TrimRight(url, ".,;:!?")
+			if !seen[url] {
+				seen[url] = true
+				links = append(links, pagerLink{url: url, line: i})
+			}
+		}
+	}
+
+	input := textinput.New()
+	input.Prompt = "/"
+
+	return PagerModel{
+		title:   title,
+		lines:   lines,
+		plain:   plain,
+		links:   links,
+		primary: primary,
+		open:    open,
+		input:   input,
+		link:    -1,
+	}
+}
+
+// Init implements tea.Model.
+func (m PagerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m PagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		height := max(msg.Height-1, 1) // Leave a line for the status bar
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, height)
+			m.viewport.SetContent(strings.Join(m.lines, "\n"))
+			m.ready = true
+		} else {
+			m.viewport.Width = msg.Width
+			m.viewport.Height = height
+		}
+		return m, nil
+
+	case openedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("could not open %s: %v", msg.url, msg.err)
+		} else {
+			m.status = "opened " + msg.url
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "/":
+			m.searching = true
+			m.input.SetValue("")
+			return m, m.input.Focus()
+		case "n":
+			m.stepMatch(1)
+			return m, nil
+		case "N":
+			m.stepMatch(-1)
+			return m, nil
+		case "]":
+			m.stepLink(1)
+			return m, nil
+		case "[":
+			m.stepLink(-1)
+			return m, nil
+		case "o", "enter":
+			return m, m.openLink()
+		case "g", "home":
+			m.viewport.GotoTop()
+			return m, nil
+		case "G", "end":
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// updateSearch handles keys while the search prompt is open.
+func (m PagerModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+		m.input.Blur()
+		m.search(m.input.Value())
+		return m, nil
+	case tea.KeyEscape, tea.KeyCtrlC:
+		m.searching = false
+		m.input.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// search finds the lines containing query, case-insensitively, and jumps to
+// the first match at or below the current position.
+func (m *PagerModel) search(query string) {
+	m.query = query
+	m.matches = nil
+	if query == "" {
+		m.status = ""
+		return
+	}
+	needle := strings.ToLower(query)
+	for i, line := range m.plain {
+		if strings.Contains(strings.ToLower(line), needle) {
+			m.matches = append(m.matches, i)
+		}
+	}
+	if len(m.matches) == 0 {
+		m.status = fmt.Sprintf("pattern not found: %s", query)
+		return
+	}
+	m.match = 0
+	for i, line := range m.matches {
+		if line >= m.viewport.YOffset {
+			m.match = i
+			break
+		}
+	}
+	m.showMatch()
+}
+
+// stepMatch moves to the next (1) or previous (-1) search match, wrapping.
+func (m *PagerModel) stepMatch(dir int) {
+	if len(m.matches) == 0 {
+		if m.query != "" {
+			m.status = fmt.Sprintf("pattern not found: %s", m.query)
+		}
+		return
+	}
+	m.match = (m.match + dir + len(m.matches)) % len(m.matches)
+	m.showMatch()
+}
+
+func (m *PagerModel) showMatch() {
+	m.viewport.SetYOffset(m.matches[m.match])
+	m.status = fmt.Sprintf("match %d/%d: %s", m.match+1, len(m.matches), m.query)
+}
+
+// stepLink selects the next (1) or previous (-1) link, wrapping, and
+// scrolls it into view.
+func (m *PagerModel) stepLink(dir int) {
+	if len(m.links) == 0 {
+		m.status = "no links"
+		return
+	}
+	if m.link < 0 && dir < 0 {
+		m.link = len(m.links) - 1
+	} else {
+		m.link = (m.link + dir + len(m.links)) % len(m.links)
+	}
+	link := m.links[m.link]
+	if link.line < m.viewport.YOffset || link.line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(link.line)
+	}
+	m.status = fmt.Sprintf("link %d/%d: %s", m.link+1, len(m.links), selectedLinkText.Render(link.url))
+}
+
+// openLink opens the selected link, or the primary link if none is selected.
+func (m PagerModel) openLink() tea.Cmd {
+	url := m.primary
+	if m.link >= 0 {
+		url = m.links[m.link].url
+	}
+	if url == "" || m.open == nil {
+		return func() tea.Msg { return openedMsg{err: fmt.Errorf("no link selected")} }
+	}
+	open := m.open
+	return func() tea.Msg { return openedMsg{url: url, err: open(url)} }
+}
+
+// View implements tea.Model.
+func (m PagerModel) View() string {
+	if !m.ready {
+		return ""
+	}
+	var bar string
+	switch {
+	case m.searching:
+		bar = m.input.View()
+	case m.status != "":
+		bar = statusStyle.Render(m.status)
+	default:
+		bar = statusStyle.Render(fmt.Sprintf("%s  %3.0f%%  %s", m.title, m.viewport.ScrollPercent()*100, pagerHelp))
+	}
+	return m.viewport.View() + "\n" + ansi.Truncate(bar, m.viewport.Width, "…")
+}
+
+// Page shows text in the built-in pager until the user quits.
+func Page(title, text, primary string, open func(string) error) error {
+	if _, err := tea.NewProgram(NewPagerModel(title, text, primary, open), tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("pager failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// ABOUTME: Unit tests for the built-in pager bubbletea model.
+// ABOUTME: Drives search, link stepping, and link opening with synthetic key messages.
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pagerTestText() string {
+	var b strings.Builder
+	for i := 0; i < 50; i++ {
+		switch i {
+		case 10:
+			b.WriteString("See \x1b[36mhttps://example.com/one\x1b[0m for details.\n")
+		case 30:
+			b.WriteString("The Needle is here, and https://example.com/two.\n")
+		case 40:
+			b.WriteString("Another needle.\n")
+		default:
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+	}
+	return b.String()
+}
+
+func sendKeys(t *testing.T, m PagerModel, keys ...string) PagerModel {
+	t.Helper()
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		wasSearching := m.searching
+		updated, cmd := m.Update(msg)
+		m = updated.(PagerModel)
+		// Only opening a link returns a command worth running here
+		if cmd != nil && !wasSearching && (key == "o" || key == "enter") {
+			if opened, ok := cmd().(openedMsg); ok {
+				updated, _ = m.Update(opened)
+				m = updated.(PagerModel)
+			}
+		}
+	}
+	return m
+}
+
+func newTestPager(open func(string) error) PagerModel {
+	m := NewPagerModel("Article", pagerTestText(), "https://example.com/article", open)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 11})
+	return updated.(PagerModel)
+}
+
+func TestPagerModel_Search(t *testing.T) {
+	m := newTestPager(nil)
+	m = sendKeys(t, m, "/", "n", "e", "e", "d", "l", "e", "enter")
+	if m.viewport.YOffset != 30 {
+		t.Errorf("expected to jump to the first match on line 30, got offset %d", m.viewport.YOffset)
+	}
+	if !strings.Contains(m.status, "match 1/2") {
+		t.Errorf("expected match status, got %q", m.status)
+	}
+
+	m = sendKeys(t, m, "n")
+	if m.viewport.YOffset != 40 {
+		t.Errorf("expected n to move to line 40, got offset %d", m.viewport.YOffset)
+	}
+	m = sendKeys(t, m, "n")
+	if m.viewport.YOffset != 30 {
+		t.Errorf("expected n to wrap to line 30, got offset %d", m.viewport.YOffset)
+	}
+
+	m = sendKeys(t, m, "/", "z", "z", "z", "enter")
+	if !strings.Contains(m.status, "not found") {
+		t.Errorf("expected not-found status, got %q", m.status)
+	}
+}
+
+func TestPagerModel_Links(t *testing.T) {
+	var opened []string
+	m := newTestPager(func(url string) error {
+		opened = append(opened, url)
+		return nil
+	})
+	if len(m.links) != 2 || m.links[0].url != "https://example.com/one" || m.links[1].url != "https://example.com/two" {
+		t.Fatalf("expected two links with styling and punctuation removed, got %+v", m.links)
+	}
+
+	// With no link selected, o opens the article itself
+	m = sendKeys(t, m, "o")
+	m = sendKeys(t, m, "]", "]", "o")
+	if m.viewport.YOffset != 30 {
+		t.Errorf("expected the second link scrolled into view, got offset %d", m.viewport.YOffset)
+	}
+	m = sendKeys(t, m, "[", "enter")
+
+	want := []string{"https://example.com/article", "https://example.com/two", "https://example.com/one"}
+	if fmt.Sprint(opened) != fmt.Sprint(want) {
+		t.Errorf("opened %v, want %v", opened, want)
+	}
+	if !strings.HasPrefix(m.status, "opened ") {
+		t.Errorf("expected opened status, got %q", m.status)
+	}
+}
+
+func TestPagerModel_Quit(t *testing.T) {
+	m := newTestPager(nil)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if cmd == nil {
+		t.Fatal("expected q to quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("expected a quit message")
+	}
+}
@@ -0,0 +1,238 @@
+// ABOUTME: Interactive fuzzy picker for choosing one item from a list.
+// ABOUTME: Bubbletea model with a filter input, fuzzy ranking, and keyboard navigation.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ErrPickerCancelled is returned when the user exits the picker without choosing.
+var ErrPickerCancelled = errors.New("selection cancelled")
+
+// pickerVisibleRows is the number of matches shown at once.
+const pickerVisibleRows = 10
+
+var (
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	itemStyle     = lipgloss.NewStyle()
+	detailStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// PickerItem is a single choice in the picker.
+type PickerItem struct {
+	ID     string // Value returned when chosen
+	Title  string // Primary text, matched against the filter
+	Detail string // Secondary text shown dimmed, also matched
+	Icon   string // Feed icon color shown before the title (see IconMark)
+}
+
+// PickerModel is the bubbletea model for the fuzzy picker.
+type PickerModel struct {
+	title    string
+	items    []PickerItem
+	matches  []PickerItem
+	cursor   int
+	input    textinput.Model
+	chosen   *PickerItem
+	quitting bool
+}
+
+// NewPickerModel creates a picker over items with the given heading.
+func NewPickerModel(title string, items []PickerItem) PickerModel {
+	input := textinput.New()
+	input.Placeholder = "type to filter"
+	input.Focus()
+	input.Width = 50
+
+	return PickerModel{
+		title:   title,
+		items:   items,
+		matches: items,
+		input:   input,
+	}
+}
+
+// Init implements tea.Model.
+func (m PickerModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model.
+func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEscape:
+			m.quitting = true
+			return m, tea.Quit
+		case tea.KeyEnter:
+			if len(m.matches) > 0 {
+				chosen := m.matches[m.cursor]
+				m.chosen = &chosen
+			}
+			m.quitting = true
+			return m, tea.Quit
+		case tea.KeyUp, tea.KeyCtrlP:
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case tea.KeyDown, tea.KeyCtrlN:
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	prev := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != prev {
+		m.matches = FilterItems(m.items, m.input.Value())
+		m.cursor = 0
+	}
+	return m, cmd
+}
+
+// View implements tea.Model.
+func (m PickerModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(m.title))
+	b.WriteString("\n\n")
+	b.WriteString(m.input.View())
+	b.WriteString("\n\n")
+
+	if len(m.matches) == 0 {
+		b.WriteString(detailStyle.Render("  no matches"))
+		b.WriteString("\n")
+	}
+
+	// Scroll the window so the cursor stays visible
+	start := 0
+	if m.cursor >= pickerVisibleRows {
+		start = m.cursor - pickerVisibleRows + 1
+	}
+	end := min(start+pickerVisibleRows, len(m.matches))
+
+	for i := start; i < end; i++ {
+		item := m.matches[i]
+		prefix, style := "  ", itemStyle
+		if i == m.cursor {
+			prefix, style = "> ", selectedStyle
+		}
+		b.WriteString(style.Render(prefix) + IconMark(item.Icon) + style.Render(item.Title))
+		if item.Detail != "" {
+			b.WriteString("  " + detailStyle.Render(item.Detail))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(promptStyle.Render(fmt.Sprintf("%d/%d  ↑/↓ move • enter select • esc cancel", len(m.matches), len(m.items))))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Chosen returns the selected item, or nil if the picker was cancelled.
+func (m PickerModel) Chosen() *PickerItem {
+	return m.chosen
+}
+
+// Pick runs the picker and returns the ID of the chosen item.
+func Pick(title string, items []PickerItem) (string, error) {
+	if len(items) == 0 {
+		return "", fmt.Errorf("nothing to choose from")
+	}
+
+	final, err := tea.NewProgram(NewPickerModel(title, items)).Run()
+	if err != nil {
+		return "", fmt.Errorf("picker failed: %w", err)
+	}
+
+	chosen := final.(PickerModel).Chosen()
+	if chosen == nil {
+		return "", ErrPickerCancelled
+	}
+	return chosen.ID, nil
+}
+
+// FilterItems returns the items matching query, best matches first.
+// An empty query returns all items in their original order.
+func FilterItems(items []PickerItem, query string) []PickerItem {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return items
+	}
+
+	type scored struct {
+		item  PickerItem
+		score int
+	}
+	var results []scored
+	for _, item := range items {
+		score, ok := FuzzyScore(item.Title+" "+item.Detail, query)
+		if ok {
+			results = append(results, scored{item, score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	out := make([]PickerItem, len(results))
+	for i, r := range results {
+		out[i] = r.item
+	}
+	return out
+}
+
+// FuzzyScore reports whether every rune of query appears in text in order
+// (case-insensitively) and scores the match. Consecutive runs and matches at
+// word starts score higher, so "gorel" ranks "Go release notes" above "good morel".
+func FuzzyScore(text, query string) (int, bool) {
+	textRunes := []rune(strings.ToLower(text))
+	queryRunes := []rune(strings.ToLower(query))
+
+	score := 0
+	ti := 0
+	prevMatch := -2
+	for _, q := range queryRunes {
+		if unicode.IsSpace(q) {
+			continue
+		}
+		found := false
+		for ; ti < len(textRunes); ti++ {
+			if textRunes[ti] != q {
+				continue
+			}
+			score++
+			if ti == prevMatch+1 {
+				score += 3
+			}
+			if ti == 0 || !unicode.IsLetter(textRunes[ti-1]) && !unicode.IsDigit(textRunes[ti-1]) {
+				score += 2
+			}
+			prevMatch = ti
+			ti++
+			found = true
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score, true
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Unit tests for the fuzzy picker bubbletea model and matcher.
+// ABOUTME: Drives the model with synthetic key messages and checks ranking.
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pickerTestItems() []PickerItem {
+	return []PickerItem{
+		{ID: "1", Title: "Good morel recipes", Detail: "Food Blog"},
+		{ID: "2", Title: "Go release notes", Detail: "Go Blog"},
+		{ID: "3", Title: "Rust 2024 edition", Detail: "Rust Blog"},
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := FuzzyScore("Go release notes", "grn"); !ok {
+		t.Error("expected subsequence to match")
+	}
+	if _, ok := FuzzyScore("Go release notes", "xyz"); ok {
+		t.Error("expected missing runes not to match")
+	}
+	if _, ok := FuzzyScore("Go release notes", "GO REL"); !ok {
+		t.Error("expected case-insensitive match ignoring spaces")
+	}
+
+	tight, _ := FuzzyScore("Go release notes", "gorel")
+	loose, _ := FuzzyScore("Good morel recipes", "gorel")
+	if tight <= loose {
+		t.Errorf("expected word-start match to outrank scattered match (%d <= %d)", tight, loose)
+	}
+}
+
+func TestFilterItems(t *testing.T) {
+	items := pickerTestItems()
+
+	if got := FilterItems(items, ""); len(got) != len(items) {
+		t.Errorf("expected empty query to return all items, got %d", len(got))
+	}
+
+	got := FilterItems(items, "gorel")
+	if len(got) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(got))
+	}
+	if got[0].ID != "2" {
+		t.Errorf("expected 'Go release notes' first, got %q", got[0].Title)
+	}
+
+	// Detail text is searchable too
+	got = FilterItems(items, "rust blog")
+	if len(got) != 1 || got[0].ID != "3" {
+		t.Errorf("expected detail match on Rust Blog, got %v", got)
+	}
+}
+
+func TestPickerModel_SelectWithArrows(t *testing.T) {
+	m := NewPickerModel("Pick", pickerTestItems())
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(PickerModel)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(PickerModel)
+
+	if m.Chosen() == nil || m.Chosen().ID != "2" {
+		t.Errorf("expected second item chosen, got %v", m.Chosen())
+	}
+}
+
+func TestPickerModel_TypeToFilter(t *testing.T) {
+	m := NewPickerModel("Pick", pickerTestItems())
+
+	for _, r := range "rust" {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(PickerModel)
+	}
+	if len(m.matches) != 1 {
+		t.Fatalf("expected 1 match after typing, got %d", len(m.matches))
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(PickerModel)
+	if m.Chosen() == nil || m.Chosen().ID != "3" {
+		t.Errorf("expected Rust item chosen, got %v", m.Chosen())
+	}
+}
+
+func TestPickerModel_Cancel(t *testing.T) {
+	m := NewPickerModel("Pick", pickerTestItems())
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	m = updated.(PickerModel)
+	if m.Chosen() != nil {
+		t.Error("expected no selection after escape")
+	}
+	if m.View() != "" {
+		t.Error("expected empty view after quitting")
+	}
+}
+
+func TestPickerModel_ViewMarksIcons(t *testing.T) {
+	items := pickerTestItems()
+	items[1].Icon = "#00add8"
+	view := NewPickerModel("Pick", items).View()
+	if strings.Count(view, "■") != 1 {
+		t.Errorf("expected one icon mark for the one item with an icon:\n%s", view)
+	}
+}
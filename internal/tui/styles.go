@@ -0,0 +1,95 @@
+// ABOUTME: Shared Lip Gloss styles for non-interactive CLI output
+// ABOUTME: Renders bordered tables, unread count badges, and glamour-formatted Markdown articles
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// defaultArticleWidth wraps articles when the terminal width is unknown.
+const defaultArticleWidth = 80
+
+var (
+	headerStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("99")).Padding(0, 1)
+	cellStyle   = lipgloss.NewStyle().Padding(0, 1)
+	borderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	badgeStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62")).Padding(0, 1)
+	quietBadge  = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Padding(0, 1)
+
+	// TitleStyle heads a section of output.
+	TitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("99"))
+	// DimStyle is for secondary details such as URLs and IDs.
+	DimStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	// ErrorStyle marks failures such as feeds with fetch errors.
+	ErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	// UnreadStyle highlights unread entries.
+	UnreadStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// IconMark renders a feed's icon color ("#rrggbb", see favicon.Color) as a
+// colored square to set before the feed's name, or "" when there is none.
+func IconMark(color string) string {
+	if color == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("■") + " "
+}
+
+// Table renders rows under headers with a rounded border. Cells may already
+// contain styled text. Columns listed in rightAlign (by index) are
+// right-aligned, which suits counts.
+func Table(headers []string, rows [][]string, rightAlign ...int) string {
+	right := make(map[int]bool, len(rightAlign))
+	for _, col := range rightAlign {
+		right[col] = true
+	}
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(borderStyle).
+		Headers(headers...).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			style := cellStyle
+			if row == table.HeaderRow {
+				style = headerStyle
+			}
+			if right[col] {
+				style = style.Align(lipgloss.Right)
+			}
+			return style
+		})
+	return t.Render()
+}
+
+// Badge renders an unread count as a highlighted pill, dimmed when zero.
+func Badge(unread int) string {
+	if unread == 0 {
+		return quietBadge.Render("0")
+	}
+	return badgeStyle.Render(fmt.Sprintf("%d", unread))
+}
+
+// RenderMarkdown formats Markdown for the terminal with glamour, picking a
+// light or dark theme to match the terminal and wrapping at width
+// (defaultArticleWidth when width is not positive).
+func RenderMarkdown(markdown string, width int) (string, error) {
+	if width <= 0 {
+		width = defaultArticleWidth
+	}
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create markdown renderer: %w", err)
+	}
+	out, err := renderer.Render(markdown)
+	if err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+	return out, nil
+}
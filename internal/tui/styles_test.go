@@ -0,0 +1,58 @@
+// ABOUTME: Unit tests for the shared CLI output styles.
+// ABOUTME: Checks table layout, badges, and Markdown rendering without a terminal.
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	out := Table([]string{"Feed", "Unread"}, [][]string{{"Go Blog", "3"}, {"Rust Blog", "12"}}, 1)
+	lines := strings.Split(out, "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected border, header, divider, two rows, border; got %d lines:\n%s", len(lines), out)
+	}
+	for _, want := range []string{"Feed", "Unread", "Go Blog", "Rust Blog"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q:\n%s", want, out)
+		}
+	}
+	// Counts are right-aligned
+	if !strings.Contains(lines[3], "  3 ") {
+		t.Errorf("expected the count to be right-aligned, got %q", lines[3])
+	}
+}
+
+func TestBadge(t *testing.T) {
+	if got := strings.TrimSpace(Badge(12)); got != "12" {
+		t.Errorf("Badge(12) = %q", got)
+	}
+	if got := strings.TrimSpace(Badge(0)); got != "0" {
+		t.Errorf("Badge(0) = %q", got)
+	}
+}
+
+func TestIconMark(t *testing.T) {
+	if got := IconMark(""); got != "" {
+		t.Errorf("IconMark(\"\") = %q, want nothing", got)
+	}
+	if got := IconMark("#ff6600"); !strings.Contains(got, "■") {
+		t.Errorf("IconMark(\"#ff6600\") = %q, want a square", got)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	out, err := RenderMarkdown("# Title\n\nSome **bold** text that goes on for a while.", 20)
+	if err != nil {
+		t.Fatalf("RenderMarkdown: %v", err)
+	}
+	if !strings.Contains(out, "Title") || !strings.Contains(out, "bold") {
+		t.Errorf("expected rendered text, got %q", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) > 20 {
+			t.Errorf("expected lines wrapped at 20 columns, got %q", line)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// ABOUTME: Interactive triage that walks unread headlines one at a time with single-key actions
+// ABOUTME: Bubbletea model calling back to read, star, open, snooze, mute, or skip each headline
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// triageUpcoming is how many of the following headlines are previewed.
+const triageUpcoming = 5
+
+// TriageAction is what triage does with a headline.
+type TriageAction int
+
+// Triage actions, in the order the summary lists them.
+const (
+	TriageRead TriageAction = iota
+	TriageStar
+	TriageOpen
+	TriageSnooze
+	TriageMute
+	TriageSkip
+)
+
+// TriageActions lists every action, in summary order.
+var TriageActions = []TriageAction{TriageRead, TriageStar, TriageOpen, TriageSnooze, TriageMute, TriageSkip}
+
+// String names the action in the past tense, for summaries.
+func (a TriageAction) String() string {
+	switch a {
+	case TriageRead:
+		return "read"
+	case TriageStar:
+		return "starred"
+	case TriageOpen:
+		return "opened"
+	case TriageSnooze:
+		return "snoozed"
+	case TriageMute:
+		return "muted"
+	case TriageSkip:
+		return "skipped"
+	}
+	return "unknown"
+}
+
+// triageKeys maps keys to actions.
+var triageKeys = map[string]TriageAction{
+	"r":     TriageRead,
+	"enter": TriageRead,
+	"s":     TriageStar,
+	"o":     TriageOpen,
+	"z":     TriageSnooze,
+	"m":     TriageMute,
+	" ":     TriageSkip,
+	"n":     TriageSkip,
+	"right": TriageSkip,
+}
+
+// TriageItem is one headline in triage.
+type TriageItem struct {
+	ID     string
+	Title  string
+	Feed   string
+	Author string
+	Date   string
+	// FeedIcon is the feed's icon color for IconMark, or "" for none.
+	FeedIcon string
+}
+
+// TriageFunc applies action to item. It returns the IDs of other items the
+// action took care of too, such as the rest of a muted author's entries,
+// which triage then passes over.
+type TriageFunc func(item TriageItem, action TriageAction) ([]string, error)
+
+// TriageModel is the bubbletea model for triage.
+type TriageModel struct {
+	items    []TriageItem
+	cursor   int
+	done     map[string]bool
+	apply    TriageFunc
+	counts   map[TriageAction]int
+	others   int
+	status   string
+	quitting bool
+}
+
+// NewTriageModel creates a triage over items that applies each action with apply.
+func NewTriageModel(items []TriageItem, apply TriageFunc) TriageModel {
+	return TriageModel{
+		items:  items,
+		done:   make(map[string]bool),
+		apply:  apply,
+		counts: make(map[TriageAction]int),
+	}
+}
+
+// Init implements tea.Model.
+func (m TriageModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m TriageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "q", "esc", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	}
+	action, ok := triageKeys[key.String()]
+	if !ok || m.cursor >= len(m.items) {
+		return m, nil
+	}
+
+	item := m.items[m.cursor]
+	others, err := m.apply(item, action)
+	if err != nil {
+		m.status = ErrorStyle.Render(err.Error())
+		return m, nil
+	}
+	m.counts[action]++
+	m.done[item.ID] = true
+	m.status = fmt.Sprintf("%s: %s", action, item.Title)
+	more := 0
+	for _, id := range others {
+		if !m.done[id] {
+			m.done[id] = true
+			more++
+		}
+	}
+	if more > 0 {
+		m.others += more
+		m.status += fmt.Sprintf(" (and %d more)", more)
+	}
+
+	m.cursor = m.nextOpen(m.cursor + 1)
+	if m.cursor >= len(m.items) {
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// nextOpen returns the index of the first item from i on not dealt with yet.
+func (m TriageModel) nextOpen(i int) int {
+	for i < len(m.items) && m.done[m.items[i].ID] {
+		i++
+	}
+	return i
+}
+
+// View implements tea.Model.
+func (m TriageModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Triage · %d of %d", m.cursor+1, len(m.items))))
+	b.WriteString("  " + detailStyle.Render(m.tally()))
+	b.WriteString("\n\n")
+
+	item := m.items[m.cursor]
+	b.WriteString(selectedStyle.Render("> " + item.Title))
+	b.WriteString("\n")
+	var details []string
+	for _, d := range []string{item.Feed, item.Author, item.Date} {
+		if d != "" {
+			details = append(details, d)
+		}
+	}
+	b.WriteString("  " + IconMark(item.FeedIcon) + detailStyle.Render(strings.Join(details, " · ")))
+	b.WriteString("\n\n")
+
+	shown := 0
+	for i := m.cursor + 1; i < len(m.items) && shown < triageUpcoming; i++ {
+		if m.done[m.items[i].ID] {
+			continue
+		}
+		b.WriteString(detailStyle.Render("  " + m.items[i].Title))
+		b.WriteString("\n")
+		shown++
+	}
+
+	b.WriteString("\n")
+	b.WriteString(promptStyle.Render("r read • s star • o open • z snooze • m mute author • space skip • q quit"))
+	b.WriteString("\n")
+	if m.status != "" {
+		b.WriteString(detailStyle.Render(m.status))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// tally summarizes the actions taken so far, such as "12 read, 1 starred".
+func (m TriageModel) tally() string {
+	var parts []string
+	for _, a := range TriageActions {
+		if n := m.counts[a]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, a))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Counts returns how many headlines each action was taken on.
+func (m TriageModel) Counts() map[TriageAction]int {
+	return m.counts
+}
+
+// Others returns how many more headlines actions took care of, such as the
+// rest of a muted author's entries.
+func (m TriageModel) Others() int {
+	return m.others
+}
+
+// Triage runs triage over items and returns the final model, for its counts.
+func Triage(items []TriageItem, apply TriageFunc) (TriageModel, error) {
+	if len(items) == 0 {
+		return TriageModel{}, fmt.Errorf("nothing to triage")
+	}
+
+	final, err := tea.NewProgram(NewTriageModel(items, apply)).Run()
+	if err != nil {
+		return TriageModel{}, fmt.Errorf("triage failed: %w", err)
+	}
+	return final.(TriageModel), nil
+}
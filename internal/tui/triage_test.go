@@ -0,0 +1,94 @@
+// ABOUTME: Unit tests for the triage bubbletea model
+// ABOUTME: Drives it with synthetic key messages and checks actions, skipping, and counts
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func triageTestItems() []TriageItem {
+	return []TriageItem{
+		{ID: "1", Title: "Go release notes", Feed: "Go Blog", Author: "Gopher"},
+		{ID: "2", Title: "Spam one", Feed: "Aggregator", Author: "Spam Bot"},
+		{ID: "3", Title: "Rust 2024 edition", Feed: "Rust Blog"},
+		{ID: "4", Title: "Spam two", Feed: "Aggregator", Author: "Spam Bot"},
+		{ID: "5", Title: "Weekly links", Feed: "Newsletter"},
+	}
+}
+
+func triageKey(m TriageModel, key tea.KeyMsg) (TriageModel, tea.Cmd) {
+	updated, cmd := m.Update(key)
+	return updated.(TriageModel), cmd
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestTriageModel_Actions(t *testing.T) {
+	var applied []string
+	apply := func(item TriageItem, action TriageAction) ([]string, error) {
+		applied = append(applied, item.ID+":"+action.String())
+		if action == TriageMute {
+			return []string{"2", "4"}, nil
+		}
+		return nil, nil
+	}
+	m := NewTriageModel(triageTestItems(), apply)
+
+	m, _ = triageKey(m, runes("r"))
+	m, _ = triageKey(m, runes("m"))
+	// Muting took care of item 4 as well, so it's passed over
+	m, _ = triageKey(m, tea.KeyMsg{Type: tea.KeySpace})
+	m, cmd := triageKey(m, runes("s"))
+
+	want := []string{"1:read", "2:muted", "3:skipped", "5:starred"}
+	if len(applied) != len(want) {
+		t.Fatalf("applied %v, want %v", applied, want)
+	}
+	for i := range want {
+		if applied[i] != want[i] {
+			t.Errorf("applied %v, want %v", applied, want)
+			break
+		}
+	}
+	if cmd == nil || m.View() != "" {
+		t.Error("expected triage to finish after the last headline")
+	}
+	counts := m.Counts()
+	if counts[TriageRead] != 1 || counts[TriageMute] != 1 || counts[TriageSkip] != 1 || counts[TriageStar] != 1 || m.Others() != 1 {
+		t.Errorf("unexpected counts %v, others %d", counts, m.Others())
+	}
+}
+
+func TestTriageModel_ErrorKeepsHeadline(t *testing.T) {
+	apply := func(item TriageItem, action TriageAction) ([]string, error) {
+		if action == TriageOpen {
+			return nil, errors.New("entry has no link")
+		}
+		return nil, nil
+	}
+	m := NewTriageModel(triageTestItems(), apply)
+
+	m, _ = triageKey(m, runes("o"))
+	if m.cursor != 0 || m.Counts()[TriageOpen] != 0 {
+		t.Errorf("a failed action should stay on the headline, cursor %d", m.cursor)
+	}
+	if view := m.View(); !strings.Contains(view, "entry has no link") || !strings.Contains(view, "Go release notes") {
+		t.Errorf("expected the error and headline shown, got:\n%s", view)
+	}
+
+	// Unbound keys do nothing; q quits
+	m, _ = triageKey(m, runes("x"))
+	if m.cursor != 0 {
+		t.Error("an unbound key should not move on")
+	}
+	m, cmd := triageKey(m, runes("q"))
+	if cmd == nil || m.View() != "" {
+		t.Error("expected q to quit")
+	}
+}
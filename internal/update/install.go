@@ -0,0 +1,226 @@
+// ABOUTME: Verifies and installs a downloaded release: checksums, ed25519 signature, extraction
+// ABOUTME: Replaces the running binary by renaming a new file over it in the same directory
+
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ChecksumsFile is the release asset listing each archive's SHA-256.
+const ChecksumsFile = "checksums.txt"
+
+// SignatureFile is the release asset holding the ed25519 signature of
+// ChecksumsFile, base64 encoded.
+const SignatureFile = ChecksumsFile + ".sig"
+
+// AssetName returns the release archive for a platform, as the release
+// build names them: digest_1.4.0_Linux_x86_64.tar.gz.
+func AssetName(tag, goos, goarch string) string {
+	arch := goarch
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "386":
+		arch = "i386"
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	osName := goos
+	if osName != "" {
+		osName = strings.ToUpper(osName[:1]) + osName[1:]
+	}
+	return fmt.Sprintf("digest_%s_%s_%s%s", strings.TrimPrefix(tag, "v"), osName, arch, ext)
+}
+
+// BinaryName returns the name of the digest binary on goos.
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return "digest.exe"
+	}
+	return "digest"
+}
+
+// ParseChecksums reads a checksums file of "<sha256>  <name>" lines into
+// a map from name to hex digest.
+func ParseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// VerifyChecksum checks data against the checksum listed for name.
+func VerifyChecksum(data []byte, name string, sums map[string]string) error {
+	want, ok := sums[name]
+	if !ok {
+		return fmt.Errorf("%s has no checksum in %s", name, ChecksumsFile)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	return nil
+}
+
+// VerifySignature checks sig, a base64 ed25519 signature, over data
+// against publicKey, a base64 ed25519 public key.
+func VerifySignature(data, sig []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release public key")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature in %s", SignatureFile)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, raw) {
+		return fmt.Errorf("%s signature does not verify", ChecksumsFile)
+	}
+	return nil
+}
+
+// Sign returns the signature VerifySignature checks: data signed with
+// privateKey, a base64 ed25519 private key or seed, base64 encoded.
+func Sign(data []byte, privateKey string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(privateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release signing key")
+	}
+	var key ed25519.PrivateKey
+	switch len(raw) {
+	case ed25519.SeedSize:
+		key = ed25519.NewKeyFromSeed(raw)
+	case ed25519.PrivateKeySize:
+		key = ed25519.PrivateKey(raw)
+	default:
+		return nil, fmt.Errorf("invalid release signing key")
+	}
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)) + "\n"), nil
+}
+
+// ExtractBinary returns the digest binary inside a release archive for
+// goos: a zip on Windows, a gzipped tar elsewhere.
+func ExtractBinary(archive []byte, goos string) ([]byte, error) {
+	name := BinaryName(goos)
+	if goos == "windows" {
+		return extractZip(archive, name)
+	}
+	return extractTarGz(archive, name)
+}
+
+func extractTarGz(archive []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s not found in archive", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == name {
+			return readLimited(tr)
+		}
+	}
+}
+
+func extractZip(archive []byte, name string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || path.Base(f.Name) != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		defer rc.Close()
+		return readLimited(rc)
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+// readLimited reads an archive member, refusing one over maxDownload.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("binary in archive is over %d MB", maxDownload>>20)
+	}
+	return data, nil
+}
+
+// Replace swaps the binary at exe for binary. The new file is written
+// next to it and renamed over it, so a failure leaves the old binary in
+// place. Windows can't overwrite a running program, so there the old one
+// is first moved aside to exe.old.
+func Replace(exe string, binary []byte) error {
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(exe); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".digest-update-*")
+	if err != nil {
+		return fmt.Errorf("can't write next to %s: %w", exe, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write new binary: %w", err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("make new binary executable: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("move old binary aside: %w", err)
+		}
+	}
+	if err := os.Rename(tmpPath, exe); err != nil {
+		return fmt.Errorf("replace %s: %w", exe, err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// ABOUTME: Finds digest releases on GitHub for self-update, on the stable or edge channel
+// ABOUTME: Lists releases through the GitHub API and downloads their assets with a size cap
+
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/harper/digest/internal/fetch"
+)
+
+// Repo is the GitHub repository digest releases come from.
+const Repo = "harperreed/digest"
+
+// DefaultAPI is the GitHub API base URL.
+const DefaultAPI = "https://api.github.com"
+
+// Release channels.
+const (
+	// ChannelStable follows full releases only.
+	ChannelStable = "stable"
+	// ChannelEdge also follows prereleases.
+	ChannelEdge = "edge"
+)
+
+// Channels lists the release channels.
+var Channels = []string{ChannelStable, ChannelEdge}
+
+// requestTimeout bounds each call to GitHub, downloads included.
+const requestTimeout = 5 * time.Minute
+
+// maxDownload caps a downloaded asset; release archives are a few MB.
+const maxDownload = 200 << 20
+
+// ParseChannel validates a release channel; "" is stable.
+func ParseChannel(s string) (string, error) {
+	switch s {
+	case "":
+		return ChannelStable, nil
+	case ChannelStable, ChannelEdge:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown channel %q (want %s)", s, strings.Join(Channels, " or "))
+}
+
+// Release is a GitHub release.
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	URL        string  `json:"html_url"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Asset returns the release's asset called name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Client talks to the GitHub releases API.
+type Client struct {
+	// API is the GitHub API base URL, DefaultAPI unless testing.
+	API  string
+	HTTP *http.Client
+}
+
+// NewClient returns a client for the GitHub API.
+func NewClient() *Client {
+	return &Client{
+		API:  DefaultAPI,
+		HTTP: &http.Client{Timeout: requestTimeout, Transport: fetch.Transport()},
+	}
+}
+
+// Latest returns the newest release on channel: the highest version among
+// published releases, leaving out prereleases on the stable channel.
+func (c *Client) Latest(ctx context.Context, channel string) (*Release, error) {
+	var releases []Release
+	url := fmt.Sprintf("%s/repos/%s/releases?per_page=30", strings.TrimRight(c.API, "/"), Repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	body, err := c.get(req)
+	if err != nil {
+		return nil, fmt.Errorf("list releases: %w", err)
+	}
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("decode releases: %w", err)
+	}
+
+	var latest *Release
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || (r.Prerelease && channel != ChannelEdge) || !IsRelease(r.Tag) {
+			continue
+		}
+		if latest == nil || Compare(r.Tag, latest.Tag) > 0 {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no releases on the %s channel", channel)
+	}
+	return latest, nil
+}
+
+// Download returns the contents of a release asset.
+func (c *Client) Download(ctx context.Context, a Asset) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.get(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", a.Name, err)
+	}
+	return body, nil
+}
+
+// get sends req and returns the response body, failing on non-200 answers
+// and bodies over maxDownload.
+func (c *Client) get(req *http.Request) ([]byte, error) {
+	req.Header.Set("User-Agent", "digest/1.0 (self-update)")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxDownload {
+		return nil, fmt.Errorf("%s is over %d MB", req.URL.Path, maxDownload>>20)
+	}
+	return body, nil
+}
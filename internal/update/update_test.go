@@ -0,0 +1,212 @@
+// ABOUTME: Tests for self-update release lookup, version ordering, verification, and installation
+// ABOUTME: Serves fake GitHub releases from httptest and builds archives in memory
+
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	ordered := []string{"v0.9.0", "v1.0.0-alpha", "v1.0.0-alpha.1", "v1.0.0-beta", "v1.0.0-rc.1", "v1.0.0-rc.2", "v1.0.0-rc.10", "v1.0.0", "1.0.1", "v1.2.0", "v1.10.0", "v2.0.0"}
+	for i := range ordered {
+		for j := range ordered {
+			want := compareInt(i, j)
+			if got := Compare(ordered[i], ordered[j]); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	if Compare("v1.0.0+build.5", "v1.0.0") != 0 {
+		t.Error("build metadata should not affect ordering")
+	}
+	if !Newer("v1.0.0", "dev") || Newer("dev", "v1.0.0") {
+		t.Error("a release should be newer than a development build")
+	}
+	for _, v := range []string{"dev", "", "v1.0", "v1.x.0", "v1.0.0-"} {
+		if IsRelease(v) {
+			t.Errorf("IsRelease(%q) = true", v)
+		}
+	}
+}
+
+func TestParseChannel(t *testing.T) {
+	if c, err := ParseChannel(""); err != nil || c != ChannelStable {
+		t.Errorf("ParseChannel(\"\") = %q, %v", c, err)
+	}
+	if c, err := ParseChannel("edge"); err != nil || c != ChannelEdge {
+		t.Errorf("ParseChannel(edge) = %q, %v", c, err)
+	}
+	if _, err := ParseChannel("nightly"); err == nil {
+		t.Error("expected an error for an unknown channel")
+	}
+}
+
+func TestLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/"+Repo+"/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"tag_name": "v1.3.0", "draft": true},
+			{"tag_name": "v1.3.0-rc.1", "prerelease": true},
+			{"tag_name": "nightly", "prerelease": true},
+			{"tag_name": "v1.1.0"},
+			{"tag_name": "v1.2.0", "assets": [{"name": "checksums.txt", "browser_download_url": "x"}]}
+		]`))
+	}))
+	defer srv.Close()
+	c := &Client{API: srv.URL, HTTP: srv.Client()}
+
+	stable, err := c.Latest(context.Background(), ChannelStable)
+	if err != nil {
+		t.Fatalf("Latest(stable): %v", err)
+	}
+	if stable.Tag != "v1.2.0" {
+		t.Errorf("stable = %s, want v1.2.0", stable.Tag)
+	}
+	if _, ok := stable.Asset(ChecksumsFile); !ok {
+		t.Error("expected the checksums asset")
+	}
+
+	edge, err := c.Latest(context.Background(), ChannelEdge)
+	if err != nil {
+		t.Fatalf("Latest(edge): %v", err)
+	}
+	if edge.Tag != "v1.3.0-rc.1" {
+		t.Errorf("edge = %s, want v1.3.0-rc.1", edge.Tag)
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	cases := map[[3]string]string{
+		{"v1.4.0", "linux", "amd64"}:    "digest_1.4.0_Linux_x86_64.tar.gz",
+		{"v1.4.0", "darwin", "arm64"}:   "digest_1.4.0_Darwin_arm64.tar.gz",
+		{"1.4.0", "windows", "amd64"}:   "digest_1.4.0_Windows_x86_64.zip",
+		{"v1.5.0-rc.1", "linux", "386"}: "digest_1.5.0-rc.1_Linux_i386.tar.gz",
+	}
+	for in, want := range cases {
+		if got := AssetName(in[0], in[1], in[2]); got != want {
+			t.Errorf("AssetName%v = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	archive := []byte("archive bytes")
+	sum := sha256.Sum256(archive)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  digest_1.0.0_Linux_x86_64.tar.gz\n" +
+		"0000  digest_1.0.0_Darwin_arm64.tar.gz\n")
+	sums := ParseChecksums(checksums)
+
+	if err := VerifyChecksum(archive, "digest_1.0.0_Linux_x86_64.tar.gz", sums); err != nil {
+		t.Errorf("VerifyChecksum: %v", err)
+	}
+	if err := VerifyChecksum([]byte("tampered"), "digest_1.0.0_Linux_x86_64.tar.gz", sums); err == nil {
+		t.Error("expected a checksum mismatch")
+	}
+	if err := VerifyChecksum(archive, "digest_1.0.0_Windows_x86_64.zip", sums); err == nil {
+		t.Error("expected an error for an unlisted asset")
+	}
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, checksums)) + "\n")
+	if err := VerifySignature(checksums, sig, key); err != nil {
+		t.Errorf("VerifySignature: %v", err)
+	}
+	if err := VerifySignature(append(checksums, 'x'), sig, key); err == nil {
+		t.Error("expected a bad signature over changed checksums")
+	}
+	if err := VerifySignature(checksums, []byte("junk"), key); err == nil {
+		t.Error("expected an error for a malformed signature")
+	}
+	if err := VerifySignature(checksums, sig, "junk"); err == nil {
+		t.Error("expected an error for a malformed key")
+	}
+
+	// What the release signer writes verifies, from the key or its seed
+	for _, signingKey := range [][]byte{priv, priv.Seed()} {
+		signed, err := Sign(checksums, base64.StdEncoding.EncodeToString(signingKey))
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		if err := VerifySignature(checksums, signed, key); err != nil {
+			t.Errorf("VerifySignature of Sign: %v", err)
+		}
+	}
+	if _, err := Sign(checksums, "junk"); err == nil {
+		t.Error("expected an error for a malformed signing key")
+	}
+}
+
+func TestExtractBinary(t *testing.T) {
+	var tgz bytes.Buffer
+	gz := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gz)
+	for name, body := range map[string]string{"LICENSE": "MIT", "digest": "elf"} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(body)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(body))
+	}
+	tw.Close()
+	gz.Close()
+
+	got, err := ExtractBinary(tgz.Bytes(), "linux")
+	if err != nil || string(got) != "elf" {
+		t.Errorf("ExtractBinary(tar.gz) = %q, %v", got, err)
+	}
+	if _, err := ExtractBinary(tgz.Bytes(), "windows"); err == nil {
+		t.Error("expected an error reading a tarball as a zip")
+	}
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, _ := zw.Create("digest.exe")
+	w.Write([]byte("pe"))
+	zw.Close()
+	got, err = ExtractBinary(zipped.Bytes(), "windows")
+	if err != nil || string(got) != "pe" {
+		t.Errorf("ExtractBinary(zip) = %q, %v", got, err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "digest")
+	if err := os.WriteFile(exe, []byte("old"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := Replace(exe, []byte("new")); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	data, _ := os.ReadFile(exe)
+	if string(data) != "new" {
+		t.Errorf("binary = %q, want new", data)
+	}
+	info, _ := os.Stat(exe)
+	if info.Mode().Perm() != 0o700 {
+		t.Errorf("mode = %v, want the old binary's 0700", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("left %d file(s) behind, want just the binary", len(entries))
+	}
+}
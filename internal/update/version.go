@@ -0,0 +1,116 @@
+// ABOUTME: Semantic version parsing and comparison for release tags like v1.4.0 and v1.5.0-rc.1
+// ABOUTME: Orders prereleases before their release, as semver does; build metadata is ignored
+
+package update
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a parsed semantic version.
+type version struct {
+	core [3]int
+	pre  []string
+}
+
+// parseVersion parses tags like "v1.2.3", "1.2.3", and "v1.2.3-rc.1".
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var v version
+	core, pre, hasPre := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return version{}, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.core[i] = n
+	}
+	if hasPre {
+		if pre == "" {
+			return version{}, false
+		}
+		v.pre = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+// IsRelease reports whether v is a release version, as opposed to a
+// development build such as "dev".
+func IsRelease(v string) bool {
+	_, ok := parseVersion(v)
+	return ok
+}
+
+// Compare returns -1, 0, or 1 as version a is older than, the same as, or
+// newer than b. Versions that don't parse sort before those that do.
+func Compare(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := range va.core {
+		if c := compareInt(va.core[i], vb.core[i]); c != 0 {
+			return c
+		}
+	}
+	// A release is newer than its prereleases
+	switch {
+	case len(va.pre) == 0 && len(vb.pre) == 0:
+		return 0
+	case len(va.pre) == 0:
+		return 1
+	case len(vb.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(va.pre) && i < len(vb.pre); i++ {
+		if c := comparePre(va.pre[i], vb.pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(va.pre), len(vb.pre))
+}
+
+// Newer reports whether latest is newer than current.
+func Newer(latest, current string) bool {
+	return Compare(latest, current) > 0
+}
+
+// comparePre compares prerelease identifiers: numbers numerically and
+// below words, words lexically.
+func comparePre(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
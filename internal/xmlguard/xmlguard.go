@@ -0,0 +1,258 @@
+// ABOUTME: Cheap structural checks that keep hostile XML away from the OPML and feed parsers
+// ABOUTME: Bounds size, nesting depth, and element count, refuses entity declarations, and hardens decoders
+
+package xmlguard
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrUnsafe is wrapped by every error Check reports.
+var ErrUnsafe = errors.New("unsafe XML rejected")
+
+// Limits bounds a document's shape. A zero field is unlimited.
+type Limits struct {
+	MaxBytes    int
+	MaxDepth    int
+	MaxElements int
+}
+
+// NewDecoder returns a decoder for a document that has passed Check.
+// encoding/xml never reads DTDs or resolves external entities; the
+// decoder is pinned to strict mode with only the five predefined entities
+// so that stays true whatever a caller changes elsewhere.
+func NewDecoder(r io.Reader) *xml.Decoder {
+	d := xml.NewDecoder(r)
+	d.Strict = true
+	d.Entity = nil
+	d.AutoClose = nil
+	return d
+}
+
+// voidElements are HTML elements that parsers decoding leniently close
+// on their own, so they don't nest.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "basefont": true, "br": true, "col": true, "frame": true,
+	"hr": true, "img": true, "input": true, "isindex": true, "link": true, "meta": true, "param": true,
+}
+
+// Check scans data without decoding it and reports the first limit it
+// breaks. Entity declarations are refused outright: nothing digest reads
+// needs them, and they are how entity expansion attacks start. Check
+// reads markup only as far as it needs to, so malformed documents pass
+// and are left to the real parser to reject.
+func Check(data []byte, l Limits) error {
+	if l.MaxBytes > 0 && len(data) > l.MaxBytes {
+		return fmt.Errorf("%w: %d bytes, more than %d", ErrUnsafe, len(data), l.MaxBytes)
+	}
+	var open Stack
+	elements := 0
+	for i := 0; ; {
+		m, ok := Next(data, i)
+		if !ok {
+			return nil
+		}
+		switch m.Kind {
+		case Directive:
+			if bytes.Contains(bytes.ToUpper(data[m.Start:m.End]), []byte("<!ENTITY")) {
+				return fmt.Errorf("%w: entity declarations aren't allowed", ErrUnsafe)
+			}
+		case EndTag:
+			open.Pop(m.Name)
+		case StartTag, EmptyTag:
+			elements++
+			if l.MaxElements > 0 && elements > l.MaxElements {
+				return fmt.Errorf("%w: more than %d elements", ErrUnsafe, l.MaxElements)
+			}
+			if m.Nests() {
+				open.Push(m.Name)
+				if l.MaxDepth > 0 && open.Depth() > l.MaxDepth {
+					return fmt.Errorf("%w: nested more than %d elements deep", ErrUnsafe, l.MaxDepth)
+				}
+			}
+		}
+		i = m.End
+	}
+}
+
+// Kind says what a piece of markup is.
+type Kind int
+
+const (
+	StartTag  Kind = iota // <name ...>
+	EndTag                // </name>
+	EmptyTag              // <name .../>
+	Comment               // <!-- ... -->
+	CDATA                 // <![CDATA[ ... ]]>
+	ProcInst              // <? ... ?>
+	Directive             // <! ... >, such as a DOCTYPE
+)
+
+// Markup is one piece of markup in a document: data[Start:End].
+type Markup struct {
+	Kind       Kind
+	Start, End int
+	// Name is a tag's qualified name, such as "item" or "rdf:RDF".
+	Name string
+}
+
+// Nests reports whether m opens an element that stays open until its end
+// tag, which void HTML elements don't.
+func (m Markup) Nests() bool {
+	return m.Kind == StartTag && !isVoid(m.Name)
+}
+
+// isVoid reports whether name is a void HTML element, whatever its
+// namespace prefix or case.
+func isVoid(name string) bool {
+	if n := strings.IndexByte(name, ':'); n >= 0 {
+		name = name[n+1:]
+	}
+	return voidElements[strings.ToLower(name)]
+}
+
+// Stack tracks the elements open at a point in a document the way a
+// lenient parser does: void elements are never open, and an end tag closes
+// its innermost open element along with any left unclosed inside it. An
+// end tag matching nothing open is ignored, so stray ones can't shrink
+// the depth.
+type Stack struct {
+	names []string
+}
+
+// Push opens an element named name.
+func (s *Stack) Push(name string) {
+	s.names = append(s.names, name)
+}
+
+// Pop closes the innermost open element named name.
+func (s *Stack) Pop(name string) {
+	if isVoid(name) {
+		return
+	}
+	for i := len(s.names) - 1; i >= 0; i-- {
+		if s.names[i] == name {
+			s.names = s.names[:i]
+			return
+		}
+	}
+}
+
+// Depth returns how many elements are open.
+func (s *Stack) Depth() int {
+	return len(s.names)
+}
+
+// Names returns the open elements' qualified names, outermost first. The
+// slice is only valid until the next Push or Pop.
+func (s *Stack) Names() []string {
+	return s.names
+}
+
+// Next finds the first markup at or after i. ok is false when there is
+// none, or data ends before the markup does; a caller reading a document
+// in pieces can call Next again from the same i once it has more.
+func Next(data []byte, i int) (m Markup, ok bool) {
+	lt := bytes.IndexByte(data[i:], '<')
+	if lt < 0 {
+		return Markup{}, false
+	}
+	m.Start = i + lt
+	rest := data[m.Start:]
+	switch {
+	case bytes.HasPrefix(rest, []byte("<!--")):
+		m.Kind, m.End = Comment, skipPast(data, m.Start+4, "-->")
+	case bytes.HasPrefix(rest, []byte("<![CDATA[")):
+		m.Kind, m.End = CDATA, skipPast(data, m.Start+9, "]]>")
+	case bytes.HasPrefix(rest, []byte("<?")):
+		m.Kind, m.End = ProcInst, skipPast(data, m.Start+2, "?>")
+	case bytes.HasPrefix(rest, []byte("<!")):
+		m.Kind, m.End = Directive, directiveEnd(data, m.Start+2)
+	case bytes.HasPrefix(rest, []byte("</")):
+		m.Kind, m.End = EndTag, skipPast(data, m.Start+2, ">")
+	default:
+		m.Kind, m.End = StartTag, tagEnd(data, m.Start+1)
+	}
+	if m.End < 0 {
+		return Markup{}, false
+	}
+	if m.Kind == StartTag || m.Kind == EndTag {
+		tag := data[m.Start:m.End]
+		if m.Kind == StartTag && bytes.HasSuffix(tag, []byte("/>")) {
+			m.Kind = EmptyTag
+		}
+		m.Name = tagName(bytes.TrimLeft(tag, "</"))
+	}
+	return m, true
+}
+
+// skipPast returns the index just after the next end at or after i, or
+// -1 if there is none.
+func skipPast(data []byte, i int, end string) int {
+	if i > len(data) {
+		return -1
+	}
+	if n := bytes.Index(data[i:], []byte(end)); n >= 0 {
+		return i + n + len(end)
+	}
+	return -1
+}
+
+// directiveEnd returns the index just after the '>' closing a directive
+// that starts before i, counting a DOCTYPE's internal subset as part of
+// it, or -1 if data ends first.
+func directiveEnd(data []byte, i int) int {
+	nested, quote := 0, byte(0)
+	for ; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '<':
+			nested++
+		case c == '>':
+			if nested == 0 {
+				return i + 1
+			}
+			nested--
+		}
+	}
+	return -1
+}
+
+// tagEnd returns the index just after the '>' closing the tag that starts
+// before i, skipping quoted attribute values, or -1 if data ends first.
+func tagEnd(data []byte, i int) int {
+	quote := byte(0)
+	for ; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// tagName returns the qualified name at the start of a tag's text.
+func tagName(tag []byte) string {
+	if n := bytes.IndexAny(tag, " \t\r\n/>"); n >= 0 {
+		tag = tag[:n]
+	}
+	return string(tag)
+}
